@@ -61,3 +61,15 @@ func CheckError(resp *http.Response) error {
 	line, _ := buf.ReadString('\n')
 	return errors.New(strings.TrimSuffix(line, "\n"))
 }
+
+// WriteError is the error of a write that the server rejected, with the
+// status code of its response.
+type WriteError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the body of the response of the server.
+func (e *WriteError) Error() string {
+	return e.Message
+}
@@ -65,6 +65,14 @@ type Query struct {
 	// is greater than the replication factor, it is expected that setting this option
 	// will only retrieve partial data.
 	NodeID int
+
+	// Parameters are the values bound to the $param placeholders in Command.
+	// This option is only effective when querying a server of version 1.5.0 or later.
+	Parameters map[string]interface{}
+
+	// TimeZone sets the default time zone of the SELECT statements in Command
+	// that do not have a tz() clause, such as "America/New_York".
+	TimeZone string
 }
 
 // SplitPath gets the path of a url
@@ -223,41 +231,7 @@ func (c *Client) Query(q Query) (*Response, error) {
 // QueryContext sends a command to the server and returns the Response
 // It uses a context that can be cancelled by the command line client
 func (c *Client) QueryContext(ctx context.Context, q Query) (*Response, error) {
-	u := c.url
-	u.Path = path.Join(u.Path, "query")
-
-	values := u.Query()
-	values.Set("q", q.Command)
-	values.Set("db", q.Database)
-	if q.RetentionPolicy != "" {
-		values.Set("rp", q.RetentionPolicy)
-	}
-	if q.Chunked {
-		values.Set("chunked", "true")
-		if q.ChunkSize > 0 {
-			values.Set("chunk_size", strconv.Itoa(q.ChunkSize))
-		}
-	}
-	if q.NodeID > 0 {
-		values.Set("node_id", strconv.Itoa(q.NodeID))
-	}
-	if c.precision != "" {
-		values.Set("epoch", c.precision)
-	}
-	u.RawQuery = values.Encode()
-
-	req, err := http.NewRequest("POST", u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("User-Agent", c.userAgent)
-	if c.username != "" {
-		req.SetBasicAuth(c.username, c.password)
-	}
-
-	req = req.WithContext(ctx)
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.query(ctx, q)
 	if err != nil {
 		return nil, err
 	}
@@ -302,6 +276,94 @@ func (c *Client) QueryContext(ctx context.Context, q Query) (*Response, error) {
 	return &response, nil
 }
 
+// QueryChunks sends a command to the server with a chunked response and calls
+// fn with each of its chunks as they are read, so that the results don't have
+// to fit in memory. It stops at the first error of fn, or at the first error
+// of the response, which it returns.
+func (c *Client) QueryChunks(ctx context.Context, q Query, fn func(*Response) error) error {
+	q.Chunked = true
+	resp, err := c.query(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var response Response
+		dec := json.NewDecoder(resp.Body)
+		dec.UseNumber()
+		if err := dec.Decode(&response); err == nil && response.Error() != nil {
+			return response.Error()
+		}
+		return fmt.Errorf("received status code %d from server", resp.StatusCode)
+	}
+
+	cr := NewChunkedResponse(resp.Body)
+	for {
+		r, err := cr.NextResponse()
+		if err != nil {
+			return err
+		} else if r == nil {
+			return nil
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+		if err := r.Error(); err != nil {
+			return err
+		}
+	}
+}
+
+// query sends a command to the server and returns its response.
+func (c *Client) query(ctx context.Context, q Query) (*http.Response, error) {
+	u := c.url
+	u.Path = path.Join(u.Path, "query")
+
+	values := u.Query()
+	values.Set("q", q.Command)
+	values.Set("db", q.Database)
+	if q.RetentionPolicy != "" {
+		values.Set("rp", q.RetentionPolicy)
+	}
+	if q.Chunked {
+		values.Set("chunked", "true")
+		if q.ChunkSize > 0 {
+			values.Set("chunk_size", strconv.Itoa(q.ChunkSize))
+		}
+	}
+	if q.NodeID > 0 {
+		values.Set("node_id", strconv.Itoa(q.NodeID))
+	}
+	if len(q.Parameters) > 0 {
+		params, err := json.Marshal(q.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		values.Set("params", string(params))
+	}
+	if q.TimeZone != "" {
+		values.Set("tz", q.TimeZone)
+	}
+	if c.precision != "" {
+		values.Set("epoch", c.precision)
+	}
+	u.RawQuery = values.Encode()
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	req = req.WithContext(ctx)
+
+	return c.httpClient.Do(req)
+}
+
 // QueryContext sends a command to the server and returns the Response
 // It uses a context that can be cancelled by the command line client
 func (c *Client) QueryFlux(ctx context.Context, query *fluxClient.QueryRequest) (flux.ResultIterator, error) {
@@ -454,7 +516,7 @@ func (c *Client) WriteLineProtocol(data, database, retentionPolicy, precision, w
 	}
 
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-		err := fmt.Errorf(string(body))
+		err := &WriteError{StatusCode: resp.StatusCode, Message: string(body)}
 		response.Err = err
 		return &response, err
 	}
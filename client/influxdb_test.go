@@ -202,6 +202,41 @@ func TestClient_Query_RP(t *testing.T) {
 	}
 }
 
+func TestClient_Query_Parameters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		if got, exp := params.Get("q"), "SELECT * FROM cpu WHERE host = $host AND value > $value"; got != exp {
+			t.Errorf("unexpected q query parameter: %s != %s", exp, got)
+		}
+		if got, exp := params.Get("params"), `{"host":"server01","value":2}`; got != exp {
+			t.Errorf("unexpected params query parameter: %s != %s", exp, got)
+		}
+		var data client.Response
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(data)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	config := client.Config{URL: *u}
+	c, err := client.NewClient(config)
+	if err != nil {
+		t.Fatalf("unexpected error.  expected %v, actual %v", nil, err)
+	}
+
+	query := client.Query{
+		Command: "SELECT * FROM cpu WHERE host = $host AND value > $value",
+		Parameters: map[string]interface{}{
+			"host":  "server01",
+			"value": 2,
+		},
+	}
+	_, err = c.Query(query)
+	if err != nil {
+		t.Fatalf("unexpected error.  expected %v, actual %v", nil, err)
+	}
+}
+
 func TestClient_ChunkedQuery(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var data client.Response
@@ -226,6 +261,40 @@ func TestClient_ChunkedQuery(t *testing.T) {
 	}
 }
 
+func TestClient_QueryChunks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, exp := r.URL.Query().Get("chunked"), "true"; got != exp {
+			t.Errorf("unexpected chunked query parameter: %s != %s", exp, got)
+		}
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		_ = enc.Encode(client.Response{Results: []client.Result{{}}})
+		_ = enc.Encode(client.Response{Results: []client.Result{{}}})
+		_ = enc.Encode(client.Response{Results: []client.Result{{Err: errors.New("chunk failed")}}})
+		_ = enc.Encode(client.Response{Results: []client.Result{{}}})
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	config := client.Config{URL: *u}
+	c, err := client.NewClient(config)
+	if err != nil {
+		t.Fatalf("unexpected error.  expected %v, actual %v", nil, err)
+	}
+
+	var chunks int
+	err = c.QueryChunks(context.Background(), client.Query{}, func(*client.Response) error {
+		chunks++
+		return nil
+	})
+	if err == nil || err.Error() != "chunk failed" {
+		t.Fatalf("unexpected error.  expected %v, actual %v", "chunk failed", err)
+	}
+	if chunks != 3 {
+		t.Fatalf("unexpected chunks.  expected %d, actual %d", 3, chunks)
+	}
+}
+
 func TestClient_QueryContext(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var data client.Response
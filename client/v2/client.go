@@ -528,6 +528,11 @@ type Result struct {
 	Series   []models.Row
 	Messages []*Message
 	Err      string `json:"error,omitempty"`
+
+	// Next is the continuation token of a paginated SHOW SERIES or
+	// SHOW TAG KEYS query. It is passed to the AFTER clause of the query
+	// to request the next page.
+	Next string `json:"next,omitempty"`
 }
 
 // Query sends a command to the server and returns the Response.
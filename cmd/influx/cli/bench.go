@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxql"
+)
+
+// The defaults of the options of the bench command.
+const (
+	defaultBenchN           = 10
+	defaultBenchConcurrency = 1
+)
+
+var (
+	benchLeadingOption  = regexp.MustCompile(`^-(n|c)\s+(\S+)\s*`)
+	benchTrailingOption = regexp.MustCompile(`\s+-(n|c)\s+(\S+)\s*$`)
+
+	// benchTiming matches the timings of the trace of EXPLAIN ANALYZE.
+	benchTiming = regexp.MustCompile(`(planning_time|execution_time|total_time): (\S+)`)
+)
+
+// benchOptions are the options of the bench command.
+type benchOptions struct {
+	query       string
+	n           int // number of executions of the query
+	concurrency int // number of executions at once
+}
+
+// parseBench parses the arguments of the bench command: a query, optionally
+// in quotes, with the options -n and -c before or after it.
+func parseBench(cmd string) (*benchOptions, error) {
+	rest := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(cmd), ";"))
+	rest = strings.TrimSpace(rest[len("bench"):])
+	o := &benchOptions{n: defaultBenchN, concurrency: defaultBenchConcurrency}
+
+	set := func(m []string) error {
+		v, err := strconv.Atoi(m[2])
+		if err != nil || v < 1 {
+			return fmt.Errorf("invalid -%s %q, expected a positive integer", m[1], m[2])
+		}
+		switch m[1] {
+		case "n":
+			o.n = v
+		case "c":
+			o.concurrency = v
+		default:
+		}
+		return nil
+	}
+	for {
+		if m := benchLeadingOption.FindStringSubmatch(rest); m != nil {
+			if err := set(m); err != nil {
+				return nil, err
+			}
+			rest = rest[len(m[0]):]
+		} else if m := benchTrailingOption.FindStringSubmatch(rest); m != nil {
+			if err := set(m); err != nil {
+				return nil, err
+			}
+			rest = rest[:len(rest)-len(m[0])]
+		} else {
+			break
+		}
+	}
+
+	if len(rest) >= 2 && (rest[0] == '"' || rest[0] == '\'') && rest[len(rest)-1] == rest[0] {
+		rest = strings.TrimSpace(rest[1 : len(rest)-1])
+	}
+	if rest == "" {
+		return nil, errors.New(`Usage: bench "<query>" [-n <executions>] [-c <concurrency>]`)
+	}
+	o.query = rest
+	if o.concurrency > o.n {
+		o.concurrency = o.n
+	}
+	return o, nil
+}
+
+// benchResult is the result of a benchmark.
+type benchResult struct {
+	latencies []time.Duration // of the successful executions, sorted
+	errors    int
+	err       error // first error
+	elapsed   time.Duration
+	timings   [][2]string // server timings of EXPLAIN ANALYZE, if any
+}
+
+// bench executes a query repeatedly with concurrency and reports the
+// percentiles of its latency, its throughput and the server timings of
+// EXPLAIN ANALYZE for a SELECT statement. It stops early if it's
+// interrupted.
+func (c *CommandLine) bench(cmd string) error {
+	o, err := parseBench(cmd)
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	query, err := c.rewriteSources(o.query)
+	if err != nil {
+		fmt.Printf("ERR: %s\n", err)
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-done:
+		case <-c.osSignals:
+			cancel()
+		}
+	}()
+
+	result := c.runBench(ctx, query, o)
+	if ctx.Err() == nil {
+		result.timings = c.benchTimings(ctx, query)
+	}
+	writeBench(os.Stdout, o, result)
+	if len(result.latencies) == 0 && result.err != nil {
+		return result.err
+	}
+	return nil
+}
+
+// runBench executes the query of the options and measures its latencies.
+func (c *CommandLine) runBench(ctx context.Context, query string, o *benchOptions) *benchResult {
+	var mu sync.Mutex
+	result := &benchResult{}
+	next := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < o.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range next {
+				start := time.Now()
+				response, err := c.Client.QueryContext(ctx, c.query(query))
+				latency := time.Since(start)
+				if err == nil {
+					err = response.Error()
+				}
+
+				mu.Lock()
+				if err != nil {
+					result.errors++
+					if result.err == nil {
+						result.err = err
+					}
+				} else {
+					result.latencies = append(result.latencies, latency)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	start := time.Now()
+	func() {
+		defer close(next)
+		for i := 0; i < o.n; i++ {
+			select {
+			case next <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	result.elapsed = time.Since(start)
+	sort.Slice(result.latencies, func(i, j int) bool { return result.latencies[i] < result.latencies[j] })
+	return result
+}
+
+// benchTimings returns the server timings of EXPLAIN ANALYZE of a SELECT
+// statement, or nil for other statements.
+func (c *CommandLine) benchTimings(ctx context.Context, query string) [][2]string {
+	q, err := influxql.ParseQuery(query)
+	if err != nil || len(q.Statements) != 1 {
+		return nil
+	} else if _, ok := q.Statements[0].(*influxql.SelectStatement); !ok {
+		return nil
+	}
+
+	response, err := c.Client.QueryContext(ctx, c.query("EXPLAIN ANALYZE "+query))
+	if err != nil || response.Error() != nil {
+		return nil
+	}
+	var timings [][2]string
+	seen := make(map[string]bool)
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			for _, v := range row.Values {
+				if len(v) == 0 {
+					continue
+				}
+				// The first timings are the ones of the whole statement.
+				if m := benchTiming.FindStringSubmatch(interfaceToString(v[0])); m != nil && !seen[m[1]] {
+					seen[m[1]] = true
+					timings = append(timings, [2]string{m[1], m[2]})
+				}
+			}
+		}
+	}
+	return timings
+}
+
+// writeBench writes the report of a benchmark.
+func writeBench(w io.Writer, o *benchOptions, result *benchResult) {
+	n := len(result.latencies) + result.errors
+	rate := 0.0
+	if result.elapsed > 0 {
+		rate = float64(n) / result.elapsed.Seconds()
+	}
+	fmt.Fprintf(w, "Executed %d of %d queries with a concurrency of %d in %s: %.2f queries/s, %d errors\n",
+		n, o.n, o.concurrency, result.elapsed.Round(time.Millisecond), rate, result.errors)
+	if result.err != nil {
+		fmt.Fprintf(w, "First error: %s\n", result.err)
+	}
+	if len(result.latencies) == 0 {
+		return
+	}
+
+	var sum time.Duration
+	for _, d := range result.latencies {
+		sum += d
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Latency\t")
+	fmt.Fprintln(tw, "-------\t")
+	fmt.Fprintf(tw, "min\t%s\n", result.latencies[0])
+	fmt.Fprintf(tw, "mean\t%s\n", sum/time.Duration(len(result.latencies)))
+	for _, p := range []float64{50, 90, 95, 99} {
+		fmt.Fprintf(tw, "p%v\t%s\n", p, percentile(result.latencies, p))
+	}
+	fmt.Fprintf(tw, "max\t%s\n", result.latencies[len(result.latencies)-1])
+	if len(result.timings) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Server (EXPLAIN ANALYZE)\t")
+		fmt.Fprintln(tw, "------------------------\t")
+		for _, t := range result.timings {
+			fmt.Fprintf(tw, "%s\t%s\n", t[0], t[1])
+		}
+	}
+	tw.Flush()
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
@@ -29,6 +29,7 @@ import (
 	fluxClient "github.com/influxdata/influxdb/flux/client"
 	v8 "github.com/influxdata/influxdb/importer/v8"
 	"github.com/influxdata/influxdb/models"
+	influxquery "github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxql"
 	"github.com/peterh/liner"
 	"golang.org/x/term"
@@ -47,22 +48,33 @@ type CommandLine struct {
 	Database        string
 	Type            QueryLanguage
 	Ssl             bool
+	CACert          string // path of the CA certificate of the server
+	ClientCert      string // path of the client certificate
+	ClientKey       string // path of the key of the client certificate
+	Profile         string // name of the connection profile
 	RetentionPolicy string
 	ClientVersion   string
 	ServerVersion   string
 	Pretty          bool   // controls pretty print for json
-	Format          string // controls the output format.  Valid values are json, csv, or column
+	Format          string // controls the output format.  Valid values are json, csv, column, table, ndjson or parquet
 	Execute         string
+	Output          string     // path of the file to export the results of the queries to
+	Script          string     // path of the script to execute, or - for STDIN
+	ScriptVars      ScriptVars // variables of the script
 	ShowVersion     bool
 	Import          bool
 	Chunked         bool
 	ChunkSize       int
 	NodeID          int
+	Params          QueryParams // values bound to $param placeholders in queries
+	TimeZone        string      // default time zone of queries
 	Quit            chan struct{}
 	IgnoreSignals   bool // Ignore signals normally caught by this process (used primarily for testing)
 	ForceTTY        bool // Force the CLI to act as if it were connected to a TTY
 	osSignals       chan os.Signal
 	historyFilePath string
+	exporter        *exporter
+	schemaCache     *schemaCache
 
 	Client         *client.Client
 	ClientConfig   client.Config // Client config options.
@@ -118,6 +130,15 @@ func (c *CommandLine) Run() error {
 		c.ClientConfig.Password = os.Getenv("INFLUX_PASSWORD")
 	}
 
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return err
+	} else if tlsConfig != nil {
+		// A certificate is only of use over https.
+		c.Ssl = true
+		c.ClientConfig.TLS = tlsConfig
+	}
+
 	addr := fmt.Sprintf("%s:%d/%s", c.Host, c.Port, c.PathPrefix)
 	url, err := client.ParseConnectionString(addr, c.Ssl)
 	if err != nil {
@@ -152,6 +173,14 @@ func (c *CommandLine) Run() error {
 	// Modify precision.
 	c.SetPrecision(c.ClientConfig.Precision)
 
+	if c.Output != "" {
+		return c.runExport()
+	}
+
+	if c.Script != "" {
+		return c.ExecuteScript(c.Script)
+	}
+
 	if c.Execute != "" {
 		switch c.Type {
 		case QueryLanguageFlux:
@@ -222,6 +251,7 @@ func (c *CommandLine) Run() error {
 	defer c.Line.Close()
 
 	c.Line.SetMultiLineMode(true)
+	c.Line.SetWordCompleter(c.completeWord)
 
 	// Only load/write history if HOME environment variable is set.
 	var historyDir string
@@ -313,6 +343,15 @@ func (c *CommandLine) ParseCommand(cmd string) error {
 			}
 		case "chunk":
 			c.SetChunkSize(cmd)
+		case "params":
+			c.SetParams(cmd)
+		case "set":
+			// Only the time zone of the session is kept by the client.
+			// Other SET statements are sent to the server.
+			if len(tokens) > 3 && tokens[1] == "time" && tokens[2] == "zone" && tokens[3] != "for" {
+				return c.SetTimeZone(cmd)
+			}
+			return c.ExecuteQuery(cmd)
 		case "pretty":
 			c.Pretty = !c.Pretty
 			if c.Pretty {
@@ -328,6 +367,12 @@ func (c *CommandLine) ParseCommand(cmd string) error {
 			return c.Insert(cmd)
 		case "clear":
 			c.clear(cmd)
+		case "watch":
+			return c.watch(cmd)
+		case "schema":
+			return c.schema(cmd)
+		case "bench":
+			return c.bench(cmd)
 		default:
 			return c.ExecuteQuery(cmd)
 		}
@@ -423,6 +468,10 @@ func (c *CommandLine) clear(cmd string) {
 		c.RetentionPolicy = ""
 		fmt.Println("retention policy context cleared")
 		return
+	case "params":
+		c.Params = nil
+		fmt.Println("query parameters cleared")
+		return
 	default:
 		if len(args) > 1 {
 			fmt.Printf("invalid command %q.\n", v)
@@ -435,6 +484,9 @@ func (c *CommandLine) clear(cmd string) {
     # Clear the retention policy context
     clear retention policy
     clear rp
+
+    # Clear the query parameters
+    clear params
 		`)
 	}
 }
@@ -594,6 +646,59 @@ func (c *CommandLine) SetChunkSize(cmd string) {
 	}
 }
 
+// SetParams sets the values bound to the parameters of queries from a JSON
+// object. Without an object, the current parameters are printed.
+func (c *CommandLine) SetParams(cmd string) {
+	// Remove the "params" keyword
+	cmd = strings.TrimSuffix(strings.TrimSpace(cmd), ";")
+	cmd = strings.TrimSpace(cmd[len("params"):])
+
+	if cmd == "" {
+		if len(c.Params) == 0 {
+			fmt.Println("no query parameters set")
+		} else {
+			fmt.Printf("query parameters: %s\n", c.Params.String())
+		}
+		return
+	}
+
+	var params QueryParams
+	if err := params.Set(cmd); err != nil {
+		fmt.Printf("unable to parse query parameters from %q: %s\n", cmd, err)
+		return
+	}
+	c.Params = params
+	fmt.Printf("query parameters set to %s\n", c.Params.String())
+}
+
+// SetTimeZone sets the default time zone of the queries in this session
+// from a SET TIME ZONE statement.
+func (c *CommandLine) SetTimeZone(cmd string) error {
+	q, err := influxquery.ParseQuery(cmd)
+	if err != nil {
+		fmt.Printf("ERR: %s\n", err)
+		return err
+	}
+
+	var stmt *influxquery.SetTimeZoneStatement
+	if len(q.Statements) == 1 {
+		stmt, _ = q.Statements[0].(*influxquery.SetTimeZoneStatement)
+	}
+	if stmt == nil {
+		// Let the server handle the statement along with any others.
+		return c.ExecuteQuery(cmd)
+	}
+
+	if stmt.Location == nil {
+		c.TimeZone = ""
+		fmt.Println("time zone reset to the default")
+	} else {
+		c.TimeZone = stmt.Location.String()
+		fmt.Printf("time zone set to %s\n", c.TimeZone)
+	}
+	return nil
+}
+
 // SetPrecision sets client precision.
 func (c *CommandLine) SetPrecision(cmd string) {
 	// normalize cmd
@@ -622,10 +727,10 @@ func (c *CommandLine) SetFormat(cmd string) {
 	cmd = strings.TrimSpace(strings.Replace(cmd, "format", "", -1))
 
 	switch cmd {
-	case "json", "csv", "column":
+	case "json", "csv", "column", "table", "ndjson", "parquet":
 		c.Format = cmd
 	default:
-		fmt.Printf("Unknown format %q. Please use json, csv, or column.\n", cmd)
+		fmt.Printf("Unknown format %q. Please use json, csv, column, table, ndjson or parquet.\n", cmd)
 	}
 }
 
@@ -769,33 +874,17 @@ func (c *CommandLine) query(query string) client.Query {
 		Chunked:         c.Chunked,
 		ChunkSize:       c.ChunkSize,
 		NodeID:          c.NodeID,
+		Parameters:      c.Params,
+		TimeZone:        c.TimeZone,
 	}
 }
 
 // ExecuteQuery runs any query statement.
 func (c *CommandLine) ExecuteQuery(query string) error {
-	// If we have a retention policy, we need to rewrite the statement sources
-	if c.RetentionPolicy != "" {
-		pq, err := influxql.NewParser(strings.NewReader(query)).ParseQuery()
-		if err != nil {
-			fmt.Printf("ERR: %s\n", err)
-			return err
-		}
-		for _, stmt := range pq.Statements {
-			if selectStatement, ok := stmt.(*influxql.SelectStatement); ok {
-				influxql.WalkFunc(selectStatement.Sources, func(n influxql.Node) {
-					if t, ok := n.(*influxql.Measurement); ok {
-						if t.Database == "" && c.Database != "" {
-							t.Database = c.Database
-						}
-						if t.RetentionPolicy == "" && c.RetentionPolicy != "" {
-							t.RetentionPolicy = c.RetentionPolicy
-						}
-					}
-				})
-			}
-		}
-		query = pq.String()
+	query, err := c.rewriteSources(query)
+	if err != nil {
+		fmt.Printf("ERR: %s\n", err)
+		return err
 	}
 
 	ctx := context.Background()
@@ -814,6 +903,17 @@ func (c *CommandLine) ExecuteQuery(query string) error {
 		}()
 	}
 
+	if c.exporter != nil {
+		if err := c.exporter.export(ctx, c, query); err != nil {
+			if ctx.Err() == context.Canceled {
+				err = errors.New("aborted by user")
+			}
+			fmt.Printf("ERR: %s\n", err)
+			return err
+		}
+		return nil
+	}
+
 	response, err := c.Client.QueryContext(ctx, c.query(query))
 	if err != nil {
 		if err.Error() == "" {
@@ -827,7 +927,11 @@ func (c *CommandLine) ExecuteQuery(query string) error {
 		fmt.Printf("ERR: %s\n", err)
 		return err
 	}
-	c.FormatResponse(response, os.Stdout)
+	if c.Format == "table" {
+		c.writePaged(response)
+	} else {
+		c.FormatResponse(response, os.Stdout)
+	}
 	if err := response.Error(); err != nil {
 		fmt.Printf("ERR: %s\n", response.Error())
 		if c.Database == "" {
@@ -839,6 +943,36 @@ func (c *CommandLine) ExecuteQuery(query string) error {
 	return nil
 }
 
+// rewriteSources sets the database and the retention policy of the sources of
+// the SELECT statements of a query to the current ones, if there is a current
+// retention policy.
+func (c *CommandLine) rewriteSources(query string) (string, error) {
+	if c.RetentionPolicy != "" {
+		p := influxquery.NewParser(strings.NewReader(query))
+		p.SetParams(c.Params)
+		pq, err := p.ParseQuery()
+		if err != nil {
+			return "", err
+		}
+		for _, stmt := range pq.Statements {
+			if selectStatement, ok := stmt.(*influxql.SelectStatement); ok {
+				influxql.WalkFunc(selectStatement.Sources, func(n influxql.Node) {
+					if t, ok := n.(*influxql.Measurement); ok {
+						if t.Database == "" && c.Database != "" {
+							t.Database = c.Database
+						}
+						if t.RetentionPolicy == "" && c.RetentionPolicy != "" {
+							t.RetentionPolicy = c.RetentionPolicy
+						}
+					}
+				})
+			}
+		}
+		query = pq.String()
+	}
+	return query, nil
+}
+
 // FormatResponse formats output to the previously chosen format.
 func (c *CommandLine) FormatResponse(response *client.Response, w io.Writer) {
 	switch c.Format {
@@ -848,6 +982,12 @@ func (c *CommandLine) FormatResponse(response *client.Response, w io.Writer) {
 		c.writeCSV(response, w)
 	case "column":
 		c.writeColumns(response, w)
+	case "table":
+		c.writeTable(response, w)
+	case "ndjson":
+		c.writeNDJSON(response, w)
+	case "parquet":
+		c.writeParquet(response, w)
 	default:
 		fmt.Fprintf(w, "Unknown output format %q.\n", c.Format)
 	}
@@ -1041,6 +1181,7 @@ func (c *CommandLine) Settings() {
 	fmt.Fprintln(w, "Setting\tValue")
 	fmt.Fprintln(w, "--------\t--------")
 	fmt.Fprintf(w, "URL\t%s\n", c.URL.String())
+	fmt.Fprintf(w, "Profile\t%s\n", c.Profile)
 	fmt.Fprintf(w, "Username\t%s\n", c.ClientConfig.Username)
 	fmt.Fprintf(w, "Database\t%s\n", c.Database)
 	fmt.Fprintf(w, "RetentionPolicy\t%s\n", c.RetentionPolicy)
@@ -1049,6 +1190,8 @@ func (c *CommandLine) Settings() {
 	fmt.Fprintf(w, "Write Consistency\t%s\n", c.ClientConfig.WriteConsistency)
 	fmt.Fprintf(w, "Chunked\t%v\n", c.Chunked)
 	fmt.Fprintf(w, "Chunk Size\t%d\n", c.ChunkSize)
+	fmt.Fprintf(w, "Params\t%s\n", c.Params.String())
+	fmt.Fprintf(w, "Time Zone\t%s\n", c.TimeZone)
 	fmt.Fprintln(w)
 	w.Flush()
 }
@@ -1061,10 +1204,15 @@ func (c *CommandLine) help() {
         chunked               turns on chunked responses from server
         chunk size <size>     sets the size of the chunked responses.  Set to 0 to reset to the default chunked size
         use <db_name>         sets current database
-        format <format>       specifies the format of the server responses: json, csv, or column
+        params <json>         sets the values of $param placeholders in queries from a JSON object
+        set time zone <tz>    sets the default time zone of queries, such as 'America/New_York', or DEFAULT
+        format <format>       specifies the format of the server responses: json, csv, column, table, ndjson or parquet
         precision <format>    specifies the format of the timestamp: rfc3339, h, m, s, ms, u or ns
         consistency <level>   sets write consistency level: any, one, quorum, or all
         history               displays command history
+        watch <interval> <q>  executes a query on an interval and shows its latest result until ctrl+c
+        bench "<q>" -n -c     executes a query -n times, -c at once, and reports its latency and throughput
+        schema [db [m [key]]] browses the databases, measurements, tag keys and values and field types
         settings              outputs the current settings for the shell
         clear                 clears settings such as database or retention policy.  run 'clear' for help
         exit/quit/ctrl+d      quits the influx shell
@@ -1236,6 +1384,37 @@ func (l *QueryLanguage) String() string {
 	return fmt.Sprintf("QueryLanguage(%d)", uint8(*l))
 }
 
+// QueryParams holds the values bound to the $param placeholders of InfluxQL
+// queries. It is set from a JSON object.
+type QueryParams map[string]interface{}
+
+func (p *QueryParams) Set(s string) error {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var params map[string]interface{}
+	if err := dec.Decode(&params); err != nil {
+		return err
+	} else if params == nil {
+		return errors.New("query parameters must be a JSON object")
+	} else if dec.More() {
+		return errors.New("unexpected data after the JSON object")
+	}
+	*p = params
+	return nil
+}
+
+func (p *QueryParams) String() string {
+	if p == nil || len(*p) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(*p)
+	if err != nil {
+		return fmt.Sprintf("QueryParams(%v)", map[string]interface{}(*p))
+	}
+	return string(b)
+}
+
 // Below is a copy and trimmed version of the execute/format.go file from flux.
 // It is copied here to avoid requiring a dependency on the execute package which
 // may pull in the flux runtime as a dependency.
@@ -1,6 +1,21 @@
 package cli
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/client"
+)
 
 func TestParseCommand_InsertInto(t *testing.T) {
 	t.Parallel()
@@ -56,3 +71,227 @@ func TestParseCommand_InsertInto(t *testing.T) {
 		}
 	}
 }
+
+func TestWatch(t *testing.T) {
+	c := CommandLine{Format: "column", osSignals: make(chan os.Signal, 1)}
+
+	// Interrupt the watch once the query was executed 3 times.
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		if len(queries) == 3 {
+			c.osSignals <- os.Interrupt
+		}
+		io.WriteString(w, `{"results":[{}]}`)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	cl, err := client.NewClient(client.Config{URL: *u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Client = cl
+
+	if err := c.ParseCommand("watch 100ms SELECT count(*) FROM cpu;"); err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"SELECT count(*) FROM cpu", "SELECT count(*) FROM cpu", "SELECT count(*) FROM cpu"}
+	if !reflect.DeepEqual(queries, exp) {
+		t.Fatalf("unexpected queries: got=%q exp=%q", queries, exp)
+	}
+
+	if err := c.ParseCommand("watch 1ms SELECT 1"); err == nil {
+		t.Fatal("expected error for a too short interval")
+	}
+}
+
+func TestWriteWatch(t *testing.T) {
+	var buf bytes.Buffer
+	writeWatch(&buf, "Every 1s: SHOW DATABASES", []string{"name", "----", "db0", "db2"}, []string{"name", "----", "db0", "db1"}, true)
+	exp := ansiClear + "Every 1s: SHOW DATABASES\n\nname\n----\ndb0\n" + ansiReverse + "db2" + ansiReset + "\n"
+	if got := buf.String(); got != exp {
+		t.Fatalf("unexpected output: got=%q exp=%q", got, exp)
+	}
+}
+
+// schemaTestServer answers the SHOW statements of the schema of a database db0
+// with a measurement cpu.
+func schemaTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch q := r.URL.Query().Get("q"); q {
+		case "SHOW DATABASES":
+			io.WriteString(w, `{"results":[{"series":[{"name":"databases","columns":["name"],"values":[["db0"],["my db"]]}]}]}`)
+		case "SHOW MEASUREMENTS ON db0":
+			io.WriteString(w, `{"results":[{"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["mem"]]}]}]}`)
+		case "SHOW SERIES CARDINALITY ON db0 FROM /.*/":
+			io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["cardinality estimation"],"values":[[12]]}]}]}`)
+		case "SHOW TAG KEYS ON db0 FROM cpu":
+			io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["tagKey"],"values":[["host"],["region"]]}]}]}`)
+		case "SHOW FIELD KEYS ON db0 FROM cpu":
+			io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["value","float"]]}]}]}`)
+		case `SHOW TAG VALUES CARDINALITY ON db0 FROM cpu WITH KEY = host`:
+			io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["count"],"values":[[10]]}]}]}`)
+		case `SHOW TAG VALUES CARDINALITY ON db0 FROM cpu WITH KEY = region`:
+			io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["count"],"values":[[2]]}]}]}`)
+		default:
+			t.Errorf("unexpected query: %s", q)
+			io.WriteString(w, `{"results":[{}]}`)
+		}
+	}))
+}
+
+func TestSchema(t *testing.T) {
+	ts := schemaTestServer(t)
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	cl, err := client.NewClient(client.Config{URL: *u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := CommandLine{Client: cl}
+
+	var buf bytes.Buffer
+	if err := c.schemaMeasurements(&buf, "db0"); err != nil {
+		t.Fatal(err)
+	}
+	exp := "Measurement  Series\n-----------  ------\ncpu          12\nmem          -\n"
+	if got := buf.String(); got != exp {
+		t.Errorf("unexpected measurements:\ngot=%q\nexp=%q", got, exp)
+	}
+
+	buf.Reset()
+	if err := c.schemaKeys(&buf, "db0", "cpu"); err != nil {
+		t.Fatal(err)
+	}
+	exp = "Tag Key  Values\n-------  ------\nhost     10\nregion   2\n\nField Key  Type\n---------  ----\nvalue      float\n"
+	if got := buf.String(); got != exp {
+		t.Errorf("unexpected keys:\ngot=%q\nexp=%q", got, exp)
+	}
+}
+
+func TestCompleteWord(t *testing.T) {
+	ts := schemaTestServer(t)
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	cl, err := client.NewClient(client.Config{URL: *u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := CommandLine{Client: cl, Database: "db0"}
+
+	for _, tt := range []struct {
+		line        string
+		head        string
+		completions []string
+	}{
+		{line: "sch", head: "", completions: []string{"schema"}},
+		{line: "use ", head: "use ", completions: []string{"\"my db\"", "db0"}},
+		{line: "SELECT * FROM c", head: "SELECT * FROM ", completions: []string{"cpu"}},
+		{line: "SELECT * FROM cpu WHERE h", head: "SELECT * FROM cpu WHERE ", completions: []string{"host"}},
+		{line: "schema db0 cpu ", head: "schema db0 cpu ", completions: []string{"host", "region", "value"}},
+	} {
+		head, completions, tail := c.completeWord(tt.line, len(tt.line))
+		if head != tt.head || tail != "" || !reflect.DeepEqual(completions, tt.completions) {
+			t.Errorf("%q: unexpected completion: head=%q completions=%q tail=%q", tt.line, head, completions, tail)
+		}
+	}
+}
+
+func TestParseBench(t *testing.T) {
+	for _, tt := range []struct {
+		cmd         string
+		query       string
+		n           int
+		concurrency int
+		err         bool
+	}{
+		{cmd: `bench "SELECT * FROM cpu" -n 50 -c 4`, query: "SELECT * FROM cpu", n: 50, concurrency: 4},
+		{cmd: `bench -c 2 SELECT mean(value) FROM "cpu";`, query: `SELECT mean(value) FROM "cpu"`, n: defaultBenchN, concurrency: 2},
+		{cmd: `bench 'SHOW DATABASES' -n 2 -c 8`, query: "SHOW DATABASES", n: 2, concurrency: 2},
+		{cmd: `bench "SELECT 1" -n 0`, err: true},
+		{cmd: `bench -n 5`, err: true},
+	} {
+		o, err := parseBench(tt.cmd)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.cmd)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.cmd, err)
+			continue
+		}
+		if o.query != tt.query || o.n != tt.n || o.concurrency != tt.concurrency {
+			t.Errorf("%s: unexpected options: %+v", tt.cmd, o)
+		}
+	}
+}
+
+func TestBench(t *testing.T) {
+	var mu sync.Mutex
+	var queries, explains int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasPrefix(r.URL.Query().Get("q"), "EXPLAIN ANALYZE ") {
+			explains++
+			io.WriteString(w, `{"results":[{"series":[{"columns":["EXPLAIN ANALYZE"],"values":[["."],["└── select"],["    ├── execution_time: 2.5ms"],["    ├── planning_time: 1.2ms"],["    ├── total_time: 3.7ms"],["    └── create_iterator"],["        ├── planning_time: 1ms"]]}]}]}`)
+			return
+		}
+		queries++
+		if queries == 3 {
+			io.WriteString(w, `{"results":[{"error":"query failed"}]}`)
+			return
+		}
+		io.WriteString(w, `{"results":[{}]}`)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	cl, err := client.NewClient(client.Config{URL: *u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := CommandLine{Client: cl}
+
+	o := &benchOptions{query: "SELECT * FROM cpu", n: 20, concurrency: 4}
+	result := c.runBench(context.Background(), o.query, o)
+	if len(result.latencies) != 19 || result.errors != 1 || result.err == nil || result.err.Error() != "query failed" {
+		t.Fatalf("unexpected result: latencies=%d errors=%d err=%v", len(result.latencies), result.errors, result.err)
+	}
+	result.timings = c.benchTimings(context.Background(), o.query)
+	exp := [][2]string{{"execution_time", "2.5ms"}, {"planning_time", "1.2ms"}, {"total_time", "3.7ms"}}
+	if !reflect.DeepEqual(result.timings, exp) {
+		t.Fatalf("unexpected timings: got=%v exp=%v", result.timings, exp)
+	}
+	if queries != 20 || explains != 1 {
+		t.Fatalf("unexpected queries: queries=%d explains=%d", queries, explains)
+	}
+
+	if c.benchTimings(context.Background(), "SHOW DATABASES") != nil || explains != 1 {
+		t.Fatal("unexpected EXPLAIN ANALYZE of a SHOW statement")
+	}
+
+	var buf bytes.Buffer
+	writeBench(&buf, o, result)
+	for _, s := range []string{"Executed 20 of 20 queries with a concurrency of 4", "1 errors", "First error: query failed", "p99", "planning_time             1.2ms"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("report without %q:\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var d []time.Duration
+	for i := 1; i <= 100; i++ {
+		d = append(d, time.Duration(i)*time.Millisecond)
+	}
+	for p, exp := range map[float64]time.Duration{50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond, 0: time.Millisecond} {
+		if got := percentile(d, p); got != exp {
+			t.Errorf("p%v: got=%s exp=%s", p, got, exp)
+		}
+	}
+}
@@ -3,18 +3,38 @@ package cli_test
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/apache/arrow/go/v7/arrow/memory"
+	"github.com/apache/arrow/go/v7/parquet/file"
+	"github.com/apache/arrow/go/v7/parquet/pqarrow"
 	"github.com/influxdata/influxdb/client"
 	"github.com/influxdata/influxdb/cmd/influx/cli"
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxql"
 	"github.com/peterh/liner"
 )
@@ -96,6 +116,261 @@ func TestRunCLI_ExecuteInsert_WithSignals(t *testing.T) {
 	}
 }
 
+func TestRunCLI_Output(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var epochs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Influxdb-Version", SERVER_VERSION)
+		if r.URL.Path == "/ping" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		q := r.URL.Query()
+		switch cmd := q.Get("q"); {
+		case strings.HasPrefix(cmd, "SHOW TAG KEYS"):
+			io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["tagKey"],"values":[["host"]]}]}]}`)
+		case strings.HasPrefix(cmd, "SHOW FIELD KEYS"):
+			io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["count","integer"],["value","float"]]}]}]}`)
+		default:
+			if q.Get("chunked") != "true" {
+				t.Errorf("expected a chunked query")
+			}
+			mu.Lock()
+			epochs = append(epochs, q.Get("epoch"))
+			mu.Unlock()
+			io.WriteString(w, `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count","host","value"],"values":[[1,1,"server01",2]]}],"partial":true}]}`+"\n")
+			io.WriteString(w, `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count","host","value"],"values":[[2,3,"server02",4.5]]}]}]}`+"\n")
+		}
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	h, p, _ := net.SplitHostPort(u.Host)
+	dir := t.TempDir()
+	run := func(output string) {
+		c := cli.New(CLIENT_VERSION)
+		c.Host = h
+		c.Port, _ = strconv.Atoi(p)
+		c.Database = "db"
+		c.ClientConfig.Precision = "rfc3339"
+		c.Execute = "SELECT * FROM cpu"
+		c.Output = filepath.Join(dir, output)
+		c.IgnoreSignals = true
+		c.ForceTTY = true
+		if err := c.Run(); err != nil {
+			t.Fatalf("Run failed with error: %s", err)
+		}
+	}
+
+	run("cpu.lp.gz")
+	f, err := os.Open(filepath.Join(dir, "cpu.lp.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := string(b), "cpu,host=server01 count=1i,value=2 1\ncpu,host=server02 count=3i,value=4.5 2\n"; got != exp {
+		t.Errorf("unexpected line protocol:\ngot=%q\nexp=%q", got, exp)
+	}
+
+	run("cpu.csv")
+	b, err = os.ReadFile(filepath.Join(dir, "cpu.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := string(b), "name,time,count,host,value\ncpu,1,1,server01,2\ncpu,2,3,server02,4.5\n"; got != exp {
+		t.Errorf("unexpected csv:\ngot=%q\nexp=%q", got, exp)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The line protocol is exported in nanoseconds, the csv in the precision
+	// of the CLI.
+	if got, exp := epochs, []string{"ns", ""}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected epochs: got=%v exp=%v", got, exp)
+	}
+}
+
+func TestRunCLI_ClientCert(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	ca, caKey := newTestCert(t, dir, "ca", nil, nil)
+	newTestCert(t, dir, "server", ca, caKey)
+	newTestCert(t, dir, "client", ca, caKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var clients []string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		clients = append(clients, r.TLS.PeerCertificates[0].Subject.CommonName)
+		mu.Unlock()
+		w.Header().Set("X-Influxdb-Version", SERVER_VERSION)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	h, p, _ := net.SplitHostPort(u.Host)
+	c := cli.New(CLIENT_VERSION)
+	c.Host = h
+	c.Port, _ = strconv.Atoi(p)
+	c.ClientConfig.Precision = "ms"
+	c.CACert = filepath.Join(dir, "ca.pem")
+	c.ClientCert = filepath.Join(dir, "client.pem")
+	c.ClientKey = filepath.Join(dir, "client-key.pem")
+	c.Execute = "INSERT sensor,floor=1 value=2"
+	c.IgnoreSignals = true
+	c.ForceTTY = true
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run failed with error: %s", err)
+	}
+	if !c.Ssl {
+		t.Error("expected the certificates to imply ssl")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(clients) == 0 || clients[0] != "client" {
+		t.Errorf("unexpected client certificates: %v", clients)
+	}
+
+	// Without the client certificate, the server rejects the connection.
+	c = cli.New(CLIENT_VERSION)
+	c.Host = h
+	c.Port, _ = strconv.Atoi(p)
+	c.CACert = filepath.Join(dir, "ca.pem")
+	c.Execute = "INSERT sensor,floor=1 value=2"
+	c.IgnoreSignals = true
+	c.ForceTTY = true
+	if err := c.Run(); err == nil {
+		t.Error("expected an error without a client certificate")
+	}
+}
+
+// newTestCert writes a certificate for 127.0.0.1 and its key to name.pem and
+// name-key.pem in dir, signed by parent, or self-signed if it's nil.
+func newTestCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestLoadProfile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config")
+	config := `default = "local"
+
+[profiles.local]
+host = "localhost"
+
+[profiles.prod]
+host = "influxdb.example.com"
+port = 8443
+ssl = true
+ca-cert = "~/.influx/ca.pem"
+username = "ops"
+database = "metrics"
+`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := cli.LoadProfile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	} else if p == nil || p.Name != "local" || p.Host != "localhost" {
+		t.Fatalf("unexpected default profile: %+v", p)
+	}
+
+	p, err = cli.LoadProfile(path, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, _ := os.UserHomeDir()
+	if got, exp := p.CACert, filepath.Join(home, ".influx", "ca.pem"); got != exp {
+		t.Errorf("unexpected CA certificate: got=%s exp=%s", got, exp)
+	}
+
+	// The flags that are set take precedence over the profile.
+	c := cli.New(CLIENT_VERSION)
+	c.Host = "localhost"
+	c.Port = 8086
+	c.Database = "telegraf"
+	c.ApplyProfile(p, func(flag string) bool { return flag == "database" })
+	if c.Host != "influxdb.example.com" || c.Port != 8443 || !c.Ssl || c.ClientConfig.Username != "ops" {
+		t.Errorf("profile not applied: host=%s port=%d ssl=%v username=%s", c.Host, c.Port, c.Ssl, c.ClientConfig.Username)
+	}
+	if got, exp := c.Database, "telegraf"; got != exp {
+		t.Errorf("unexpected database: got=%s exp=%s", got, exp)
+	}
+	if got, exp := c.Profile, "prod"; got != exp {
+		t.Errorf("unexpected profile: got=%s exp=%s", got, exp)
+	}
+
+	if _, err := cli.LoadProfile(path, "staging"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+	if p, err := cli.LoadProfile(filepath.Join(t.TempDir(), "missing"), ""); err != nil || p != nil {
+		t.Errorf("unexpected profile without a config file: %+v, %v", p, err)
+	}
+}
+
 func TestSetAuth(t *testing.T) {
 	t.Parallel()
 	c := cli.New(CLIENT_VERSION)
@@ -178,6 +453,188 @@ func TestSetFormat(t *testing.T) {
 	}
 }
 
+// testResponse returns a response with two series, as decoded by the client.
+func testResponse() *client.Response {
+	return &client.Response{Results: []client.Result{{
+		Series: []models.Row{
+			{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "server01"},
+				Columns: []string{"time", "value", "region"},
+				Values: [][]interface{}{
+					{"2020-01-01T00:00:00Z", json.Number("1"), "us-west"},
+					{"2020-01-01T00:00:10Z", json.Number("10.5"), nil},
+				},
+			},
+			{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "server02"},
+				Columns: []string{"time", "value", "region"},
+				Values: [][]interface{}{
+					{"2020-01-01T00:00:00Z", json.Number("100"), "us-east"},
+				},
+			},
+		},
+	}}}
+}
+
+func TestFormatResponse_Table(t *testing.T) {
+	c := cli.CommandLine{Format: "table"}
+	var buf bytes.Buffer
+	c.FormatResponse(testResponse(), &buf)
+	exp := `name: cpu
+tags: host=server01
++----------------------+-------+---------+
+| time                 | value | region  |
++----------------------+-------+---------+
+| 2020-01-01T00:00:00Z |     1 | us-west |
+| 2020-01-01T00:00:10Z |  10.5 |         |
++----------------------+-------+---------+
+
+name: cpu
+tags: host=server02
++----------------------+-------+---------+
+| time                 | value | region  |
++----------------------+-------+---------+
+| 2020-01-01T00:00:00Z |   100 | us-east |
++----------------------+-------+---------+
+`
+	if got := buf.String(); got != exp {
+		t.Fatalf("unexpected output:\n%s\nexp:\n%s", got, exp)
+	}
+}
+
+func TestFormatResponse_NDJSON(t *testing.T) {
+	c := cli.CommandLine{Format: "ndjson"}
+	var buf bytes.Buffer
+	c.FormatResponse(testResponse(), &buf)
+	exp := `{"name":"cpu","host":"server01","time":"2020-01-01T00:00:00Z","value":1,"region":"us-west"}
+{"name":"cpu","host":"server01","time":"2020-01-01T00:00:10Z","value":10.5,"region":null}
+{"name":"cpu","host":"server02","time":"2020-01-01T00:00:00Z","value":100,"region":"us-east"}
+`
+	if got := buf.String(); got != exp {
+		t.Fatalf("unexpected output:\n%s\nexp:\n%s", got, exp)
+	}
+}
+
+func TestFormatResponse_Parquet(t *testing.T) {
+	c := cli.CommandLine{Format: "parquet"}
+	var buf bytes.Buffer
+	c.FormatResponse(testResponse(), &buf)
+
+	r, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	fr, err := pqarrow.NewFileReader(r, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Release()
+
+	if got, exp := tbl.NumRows(), int64(3); got != exp {
+		t.Fatalf("unexpected rows: got=%d exp=%d", got, exp)
+	}
+	var got []string
+	for _, f := range tbl.Schema().Fields() {
+		got = append(got, f.Name+":"+fmt.Sprint(f.Type))
+	}
+	exp := []string{"name:utf8", "host:utf8", "time:timestamp[ns, tz=UTC]", "value:float64", "region:utf8"}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected schema: got=%v exp=%v", got, exp)
+	}
+}
+
+func TestExecuteScript(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Influxdb-Version", SERVER_VERSION)
+		q := r.URL.Query().Get("q")
+		switch q {
+		case "SHOW DATABASES":
+			io.WriteString(w, `{"results":[{"series":[{"name":"databases","columns":["name"],"values":[["db0"],["_internal"],["db1"]]}]}]}`)
+		case `SHOW MEASUREMENTS ON "db0"`:
+			io.WriteString(w, `{"results":[{"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["mem"]]}]}]}`)
+		case `SHOW MEASUREMENTS ON "db1"`:
+			io.WriteString(w, `{"results":[{}]}`)
+		default:
+			mu.Lock()
+			queries = append(queries, q)
+			mu.Unlock()
+			io.WriteString(w, `{"results":[{}]}`)
+		}
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	c, err := client.NewClient(client.Config{URL: *u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := cli.CommandLine{Client: c, Format: "column", IgnoreSignals: true}
+	m.ScriptVars.Set("since=1h")
+
+	path := filepath.Join(t.TempDir(), "count.influx")
+	script := `# Count the recent points of each measurement.
+LET field = "value"
+FOR db IN SHOW DATABASES
+  IF ${db} =~ /^_/
+    -- Skip the internal databases.
+  ELSE
+    FOR m IN SHOW MEASUREMENTS ON "${db}"
+      SELECT count(${field}) FROM "${db}".."${m}" \
+        WHERE time > now() - ${since}
+    END
+  END
+END
+FOR rp IN (autogen, "one week")
+  DROP RETENTION POLICY "${rp}" ON db1
+END
+exit
+SELECT * FROM never
+`
+	if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExecuteScript(path); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{
+		`SELECT count(value) FROM "db0".."cpu" WHERE time > now() - 1h`,
+		`SELECT count(value) FROM "db0".."mem" WHERE time > now() - 1h`,
+		`DROP RETENTION POLICY "autogen" ON db1`,
+		`DROP RETENTION POLICY "one week" ON db1`,
+	}
+	if !reflect.DeepEqual(queries, exp) {
+		t.Fatalf("unexpected queries:\ngot=%q\nexp=%q", queries, exp)
+	}
+
+	for _, tt := range []struct {
+		script string
+		err    string
+	}{
+		{script: "FOR db IN SHOW DATABASES\nSELECT 1\n", err: ":1: missing END"},
+		{script: "SELECT 1\nEND\n", err: ":2: END without FOR or IF"},
+		{script: "LET x\n", err: ":1: invalid LET, expected LET name = value"},
+		{script: "\nSELECT ${undefined}\n", err: ":2: undefined variable undefined"},
+		{script: "FOR a, b IN SHOW DATABASES\nEND\n", err: ":1: FOR has 2 variables but the rows have 1 columns"},
+	} {
+		if err := os.WriteFile(path, []byte(tt.script), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.ExecuteScript(path); err == nil || err.Error() != path+tt.err {
+			t.Errorf("unexpected error for %q: got=%v exp=%s", tt.script, err, path+tt.err)
+		}
+	}
+}
+
 func Test_SetChunked(t *testing.T) {
 	t.Parallel()
 	c := cli.New(CLIENT_VERSION)
@@ -510,6 +967,110 @@ func TestParseCommand_Consistency(t *testing.T) {
 	}
 }
 
+func TestParseCommand_Params(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Influxdb-Version", SERVER_VERSION)
+		values := r.URL.Query()
+		if got, exp := values.Get("q"), `SELECT value FROM db0.rp0.cpu WHERE host = 'serverA'`; got != exp {
+			t.Errorf("unexpected q query parameter: %s != %s", exp, got)
+		}
+		if got, exp := values.Get("params"), `{"Host":"server01","host":"serverA"}`; got != exp {
+			t.Errorf("unexpected params query parameter: %s != %s", exp, got)
+		}
+		io.WriteString(w, `{"results":[{}]}`)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	config := client.Config{URL: *u}
+	c, err := client.NewClient(config)
+	if err != nil {
+		t.Fatalf("unexpected error.  expected %v, actual %v", nil, err)
+	}
+	m := cli.CommandLine{Client: c, Database: "db0", RetentionPolicy: "rp0", Format: "column", IgnoreSignals: true}
+
+	// Invalid parameters leave the current parameters unchanged.
+	for _, cmd := range []string{`params {"host":`, `params ["server01"]`, `params {} {}`} {
+		if err := m.ParseCommand(cmd); err != nil {
+			t.Fatalf(`Got error %v for command %q, expected nil.`, err, cmd)
+		} else if len(m.Params) != 0 {
+			t.Fatalf(`Command %q set parameters to %v. Expected none`, cmd, m.Params)
+		}
+	}
+
+	if err := m.ParseCommand(`Params {"Host": "server01", "host": "serverA"};`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if got, exp := m.Params.String(), `{"Host":"server01","host":"serverA"}`; got != exp {
+		t.Fatalf("unexpected parameters: %s != %s", exp, got)
+	}
+
+	// The query is parsed by the client to set the retention policy so the
+	// parameters are bound there as well.
+	if err := m.ParseCommand("SELECT value FROM cpu WHERE host = $host"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := m.ParseCommand("clear params"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if m.Params != nil {
+		t.Fatalf("unexpected parameters: %v", m.Params)
+	}
+}
+
+func TestParseCommand_TimeZone(t *testing.T) {
+	t.Parallel()
+	var tz string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Influxdb-Version", SERVER_VERSION)
+		if got, exp := r.URL.Query().Get("tz"), tz; got != exp {
+			t.Errorf("unexpected tz query parameter: %s != %s", exp, got)
+		}
+		io.WriteString(w, `{"results":[{}]}`)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	config := client.Config{URL: *u}
+	c, err := client.NewClient(config)
+	if err != nil {
+		t.Fatalf("unexpected error.  expected %v, actual %v", nil, err)
+	}
+	m := cli.CommandLine{Client: c, Format: "column", IgnoreSignals: true}
+
+	if err := m.ParseCommand("SET TIME ZONE 'Mars/Olympus_Mons'"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	for _, tt := range []struct {
+		cmd string
+		tz  string
+	}{
+		{cmd: "SET TIME ZONE 'America/New_York'", tz: "America/New_York"},
+		{cmd: "set time zone 'Europe/Paris';", tz: "Europe/Paris"},
+		{cmd: "SET TIME ZONE DEFAULT", tz: ""},
+	} {
+		if err := m.ParseCommand(tt.cmd); err != nil {
+			t.Fatalf(`Got error %v for command %q, expected nil.`, err, tt.cmd)
+		} else if m.TimeZone != tt.tz {
+			t.Fatalf(`Command %q set time zone to %q. Expected %q`, tt.cmd, m.TimeZone, tt.tz)
+		}
+
+		// The time zone is sent with every query of the session.
+		tz = tt.tz
+		if err := m.ParseCommand("SELECT count(value) FROM cpu GROUP BY time(1d)"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// The time zone of a user is set by the server.
+	if err := m.ParseCommand("SET TIME ZONE FOR bob = 'Europe/Paris'"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if m.TimeZone != "" {
+		t.Fatalf("unexpected time zone: %q", m.TimeZone)
+	}
+}
+
 func TestParseCommand_Insert(t *testing.T) {
 	t.Parallel()
 	ts := emptyTestServer()
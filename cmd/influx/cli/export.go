@@ -0,0 +1,364 @@
+package cli
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/client"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxql"
+)
+
+// An exporter streams the results of the queries to a file, chunk by chunk,
+// so that they don't have to fit in memory. The format of the file is given
+// by its extension: .lp for line protocol, .csv for the layout of the csv
+// format and .ndjson for the layout of the ndjson format. The file is
+// compressed with gzip if it ends with .gz, such as export.csv.gz.
+type exporter struct {
+	path   string
+	format string
+
+	f    *os.File
+	gz   *gzip.Writer
+	w    *bufio.Writer
+	csvw *csv.Writer
+
+	previous  models.Row                    // headers of the last csv rows
+	databases map[string]string             // databases of the measurements of the queries
+	schemas   map[string]*measurementSchema // schemas of the line protocol measurements
+	rows      int64
+}
+
+// measurementSchema holds the tag keys and the types of the fields of a
+// measurement, to write its rows as line protocol.
+type measurementSchema struct {
+	tags   map[string]bool
+	fields map[string]string
+}
+
+// exportFormat returns the format of an export file from its extension.
+func exportFormat(path string) (string, error) {
+	ext := filepath.Ext(strings.TrimSuffix(path, ".gz"))
+	switch ext {
+	case ".lp", ".line":
+		return "lp", nil
+	case ".csv":
+		return "csv", nil
+	case ".ndjson", ".jsonl":
+		return "ndjson", nil
+	default:
+		return "", fmt.Errorf("unknown format of output file %s, expected a .lp, .csv or .ndjson extension, optionally followed by .gz", path)
+	}
+}
+
+// newExporter creates the export file at path.
+func newExporter(path string) (*exporter, error) {
+	format, err := exportFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &exporter{
+		path:      path,
+		format:    format,
+		f:         f,
+		databases: make(map[string]string),
+		schemas:   make(map[string]*measurementSchema),
+	}
+	var w io.Writer = f
+	if strings.HasSuffix(path, ".gz") {
+		e.gz = gzip.NewWriter(f)
+		w = e.gz
+	}
+	e.w = bufio.NewWriterSize(w, 1<<20)
+	e.csvw = csv.NewWriter(e.w)
+	return e, nil
+}
+
+// Close flushes and closes the export file.
+func (e *exporter) Close() error {
+	e.csvw.Flush()
+	err := e.csvw.Error()
+	if ferr := e.w.Flush(); err == nil {
+		err = ferr
+	}
+	if e.gz != nil {
+		if gerr := e.gz.Close(); err == nil {
+			err = gerr
+		}
+	}
+	if cerr := e.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// runExport executes the script or the commands of -execute and writes the
+// results of their queries to the output file.
+func (c *CommandLine) runExport() error {
+	if c.Type == QueryLanguageFlux {
+		return errors.New("-output is not supported with -type flux")
+	} else if c.Execute == "" && c.Script == "" {
+		return errors.New("-output requires -execute or -script")
+	}
+
+	e, err := newExporter(c.Output)
+	if err != nil {
+		return err
+	}
+	c.exporter = e
+
+	if c.Script != "" {
+		err = c.ExecuteScript(c.Script)
+	} else {
+		for _, line := range strings.Split(c.Execute, "\n") {
+			if err = c.ParseCommand(line); err != nil {
+				break
+			}
+		}
+	}
+	c.exporter = nil
+	if cerr := e.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d rows to %s.\n", e.rows, e.path)
+	return nil
+}
+
+// export executes a query with a chunked response and writes its rows to the
+// export file as they are read.
+func (e *exporter) export(ctx context.Context, c *CommandLine, query string) error {
+	q := c.query(query)
+	if e.format == "lp" {
+		// The line protocol needs the timestamps in nanoseconds.
+		c.Client.SetPrecision("ns")
+		defer c.Client.SetPrecision(c.ClientConfig.Precision)
+		e.sources(query, c.Database)
+	}
+
+	return c.Client.QueryChunks(ctx, q, func(response *client.Response) error {
+		switch e.format {
+		case "lp":
+			return e.writeLineProtocol(c, response)
+		case "csv":
+			e.writeCSV(response)
+		case "ndjson":
+			c.writeNDJSON(response, e.w)
+		default:
+			return fmt.Errorf("unknown export format %s", e.format)
+		}
+		for _, result := range response.Results {
+			for _, row := range result.Series {
+				e.rows += int64(len(row.Values))
+			}
+		}
+		return e.csvw.Error()
+	})
+}
+
+// writeCSV writes the rows of a chunk with the layout of the csv format: the
+// name of the series, its tags and its columns. The headers are written when
+// they differ from the ones of the previous rows.
+func (e *exporter) writeCSV(response *client.Response) {
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			tags := sortedTags(row.Tags, "=")
+			var prefix []string
+			if row.Name != "" {
+				prefix = append(prefix, row.Name)
+			}
+			if len(tags) > 0 {
+				prefix = append(prefix, strings.Join(tags, ","))
+			}
+
+			if !headersEqual(e.previous, row) {
+				var header []string
+				if row.Name != "" {
+					header = append(header, "name")
+				}
+				if len(tags) > 0 {
+					header = append(header, "tags")
+				}
+				e.csvw.Write(append(header, row.Columns...))
+				e.previous = models.Row{Name: row.Name, Tags: row.Tags, Columns: row.Columns}
+			}
+
+			for _, values := range row.Values {
+				record := append([]string(nil), prefix...)
+				for _, v := range values {
+					record = append(record, interfaceToString(v))
+				}
+				e.csvw.Write(record)
+			}
+		}
+	}
+}
+
+// sources records the databases of the measurements of the sources of a
+// query, to look up their schemas.
+func (e *exporter) sources(query, database string) {
+	q, err := influxql.ParseQuery(query)
+	if err != nil {
+		return
+	}
+	for _, stmt := range q.Statements {
+		influxql.WalkFunc(stmt, func(n influxql.Node) {
+			if m, ok := n.(*influxql.Measurement); ok && m.Name != "" {
+				if m.Database != "" {
+					e.databases[m.Name] = m.Database
+				} else if _, ok := e.databases[m.Name]; !ok {
+					e.databases[m.Name] = database
+				}
+			}
+		})
+	}
+}
+
+// schema returns the schema of a measurement. If it can't be looked up, the
+// schema is empty: the columns are written as fields, with the numbers as
+// floats.
+func (e *exporter) schema(c *CommandLine, name string) *measurementSchema {
+	if s, ok := e.schemas[name]; ok {
+		return s
+	}
+	database, ok := e.databases[name]
+	if !ok {
+		database = c.Database
+	}
+	s := &measurementSchema{tags: make(map[string]bool), fields: make(map[string]string)}
+	e.schemas[name] = s
+	if database == "" {
+		return s
+	}
+
+	from := " ON " + influxql.QuoteIdent(database) + " FROM " + influxql.QuoteIdent(name)
+	tags, _ := c.showNames("SHOW TAG KEYS"+from, database)
+	for _, k := range tags {
+		s.tags[k] = true
+	}
+	rows, _ := c.showRows("SHOW FIELD KEYS"+from, database)
+	for _, row := range rows {
+		for _, v := range row.Values {
+			if len(v) > 1 {
+				s.fields[interfaceToString(v[0])] = interfaceToString(v[1])
+			}
+		}
+	}
+	return s
+}
+
+// writeLineProtocol writes the rows of a chunk as line protocol. The columns
+// that are tag keys of the measurement are written as tags, and the others as
+// fields of the type of the schema.
+func (e *exporter) writeLineProtocol(c *CommandLine, response *client.Response) error {
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			if row.Name == "" {
+				return errors.New("the line protocol requires series with a measurement name")
+			}
+			s := e.schema(c, row.Name)
+			for _, values := range row.Values {
+				tags := make(map[string]string, len(row.Tags))
+				for k, v := range row.Tags {
+					tags[k] = v
+				}
+				fields := make(models.Fields, len(row.Columns))
+				var t time.Time
+				for i, name := range row.Columns {
+					if i >= len(values) || values[i] == nil {
+						continue
+					}
+					v := values[i]
+					switch {
+					case name == "time":
+						ts, err := exportTime(v)
+						if err != nil {
+							return err
+						}
+						t = ts
+					case s.tags[name]:
+						tags[name] = interfaceToString(v)
+					default:
+						fields[name] = exportField(v, s.fields[name])
+					}
+				}
+				if len(fields) == 0 {
+					continue
+				}
+
+				pt, err := models.NewPoint(row.Name, models.NewTags(tags), fields, t)
+				if err != nil {
+					return err
+				}
+				e.w.WriteString(pt.String())
+				e.w.WriteByte('\n')
+				e.rows++
+			}
+		}
+	}
+	return nil
+}
+
+// exportTime returns the time of a row, in nanoseconds or in RFC3339.
+func exportTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %s", v)
+		}
+		return time.Unix(0, n).UTC(), nil
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	default:
+		return time.Time{}, fmt.Errorf("invalid time %v", v)
+	}
+}
+
+// exportField returns the value of a field of a type of SHOW FIELD KEYS, or
+// of the type of the value if it's unknown.
+func exportField(v interface{}, typ string) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		switch typ {
+		case "integer":
+			if n, err := v.Int64(); err == nil {
+				return n
+			}
+		case "unsigned":
+			if n, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+				return n
+			}
+		case "string":
+			return v.String()
+		default:
+		}
+		f, _ := v.Float64()
+		return f
+	case bool, string:
+		if typ == "string" {
+			return interfaceToString(v)
+		}
+		return v
+	default:
+		return interfaceToString(v)
+	}
+}
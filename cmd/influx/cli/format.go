@@ -0,0 +1,424 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/apache/arrow/go/v7/arrow"
+	"github.com/apache/arrow/go/v7/arrow/array"
+	"github.com/apache/arrow/go/v7/arrow/memory"
+	"github.com/apache/arrow/go/v7/parquet"
+	"github.com/apache/arrow/go/v7/parquet/compress"
+	"github.com/apache/arrow/go/v7/parquet/pqarrow"
+	"github.com/influxdata/influxdb/client"
+	"github.com/influxdata/influxdb/models"
+	"golang.org/x/term"
+)
+
+// writeTable writes the series of the response as tables with borders, with
+// the numbers aligned to the right.
+func (c *CommandLine) writeTable(response *client.Response, w io.Writer) {
+	first := true
+	for _, result := range response.Results {
+		for _, m := range result.Messages {
+			fmt.Fprintf(w, "%s: %s.\n", m.Level, m.Text)
+		}
+		for _, row := range result.Series {
+			if !first {
+				fmt.Fprintln(w)
+			}
+			first = false
+
+			if row.Name != "" {
+				fmt.Fprintf(w, "name: %s\n", row.Name)
+			}
+			if len(row.Tags) > 0 {
+				fmt.Fprintf(w, "tags: %s\n", strings.Join(sortedTags(row.Tags, "="), ", "))
+			}
+			writeTableRows(w, row)
+		}
+	}
+}
+
+// writeTableRows writes the columns and values of a series as a table.
+func writeTableRows(w io.Writer, row models.Row) {
+	widths := make([]int, len(row.Columns))
+	for i, name := range row.Columns {
+		widths[i] = utf8.RuneCountInString(name)
+	}
+	cells := make([][]string, len(row.Values))
+	numeric := make([]bool, len(row.Columns))
+	for i, values := range row.Values {
+		cells[i] = make([]string, len(row.Columns))
+		for j := range row.Columns {
+			if j >= len(values) {
+				continue
+			}
+			s := interfaceToString(values[j])
+			if n := utf8.RuneCountInString(s); n > widths[j] {
+				widths[j] = n
+			}
+			if isNumber(values[j]) {
+				numeric[j] = true
+			}
+			cells[i][j] = s
+		}
+	}
+
+	separator := "+"
+	for _, width := range widths {
+		separator += strings.Repeat("-", width+2) + "+"
+	}
+	line := func(cells []string, right []bool) {
+		var b strings.Builder
+		b.WriteString("|")
+		for i, s := range cells {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(s))
+			if right != nil && right[i] {
+				b.WriteString(" " + pad + s + " |")
+			} else {
+				b.WriteString(" " + s + pad + " |")
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+
+	fmt.Fprintln(w, separator)
+	line(row.Columns, nil)
+	fmt.Fprintln(w, separator)
+	for _, values := range cells {
+		line(values, numeric)
+	}
+	if len(cells) > 0 {
+		fmt.Fprintln(w, separator)
+	}
+}
+
+// writePaged writes a response in the table format to STDOUT, through the
+// pager if it doesn't fit on the terminal. The pager is $PAGER, or
+// "less -FRSX" by default.
+func (c *CommandLine) writePaged(response *client.Response) {
+	var buf bytes.Buffer
+	c.FormatResponse(response, &buf)
+
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil || bytes.Count(buf.Bytes(), []byte("\n")) < height {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -FRSX"
+		if runtime.GOOS == "windows" {
+			pager = "more"
+		}
+	}
+	args := strings.Fields(pager)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = &buf
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	cmd.Wait()
+}
+
+// flatRow returns the names and values of a row of a series: the name of the
+// series as "name", its tags and its columns, in this order. A column takes
+// precedence over a tag or the name with the same name.
+func flatRow(row models.Row, values []interface{}) ([]string, map[string]interface{}) {
+	var names []string
+	m := make(map[string]interface{}, 1+len(row.Tags)+len(row.Columns))
+	set := func(name string, v interface{}) {
+		if _, ok := m[name]; !ok {
+			names = append(names, name)
+		}
+		m[name] = v
+	}
+
+	if row.Name != "" {
+		set("name", row.Name)
+	}
+	keys := make([]string, 0, len(row.Tags))
+	for k := range row.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		set(k, row.Tags[k])
+	}
+	for i, name := range row.Columns {
+		var v interface{}
+		if i < len(values) {
+			v = values[i]
+		}
+		set(name, v)
+	}
+	return names, m
+}
+
+// writeNDJSON writes each row of the response as a JSON object on its own
+// line, with the name of its series, its tags and its columns as keys. The
+// errors of the statements are written as objects with an "error" key.
+func (c *CommandLine) writeNDJSON(response *client.Response, w io.Writer) {
+	var buf bytes.Buffer
+	for _, result := range response.Results {
+		if result.Err != nil {
+			b, _ := json.Marshal(map[string]string{"error": result.Err.Error()})
+			fmt.Fprintln(w, string(b))
+			continue
+		}
+		for _, row := range result.Series {
+			for _, values := range row.Values {
+				names, m := flatRow(row, values)
+				buf.Reset()
+				buf.WriteByte('{')
+				for i, name := range names {
+					if i > 0 {
+						buf.WriteByte(',')
+					}
+					k, _ := json.Marshal(name)
+					v, err := json.Marshal(m[name])
+					if err != nil {
+						v, _ = json.Marshal(interfaceToString(m[name]))
+					}
+					buf.Write(k)
+					buf.WriteByte(':')
+					buf.Write(v)
+				}
+				buf.WriteString("}\n")
+				w.Write(buf.Bytes())
+			}
+		}
+	}
+}
+
+// writeParquet writes the rows of the response as a Parquet file, with a
+// column for the name of the series, each of their tags and each of their
+// columns, like the ndjson format. The type of a column is the type of its
+// values: an integer, a float, a boolean or a string if they have different
+// types. The time column is a timestamp if it's in the RFC3339 format.
+func (c *CommandLine) writeParquet(response *client.Response, w io.Writer) {
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprintln(w, "The parquet format can only be written to a file, redirect the output to one.")
+		return
+	}
+	if err := writeParquet(response, w); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to write parquet: %s\n", err)
+	}
+}
+
+// parquet column types, from the most to the least specific.
+const (
+	parquetNull = iota
+	parquetBool
+	parquetInt
+	parquetFloat
+	parquetTimestamp
+	parquetString
+)
+
+func writeParquet(response *client.Response, w io.Writer) error {
+	var names []string
+	types := make(map[string]int)
+	var rows []map[string]interface{}
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			for _, values := range row.Values {
+				rowNames, m := flatRow(row, values)
+				for _, name := range rowNames {
+					t, ok := types[name]
+					if !ok {
+						names = append(names, name)
+					}
+					types[name] = mergeParquetType(t, parquetType(name, m[name]))
+				}
+				rows = append(rows, m)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return errors.New("no rows to write")
+	}
+
+	fields := make([]arrow.Field, len(names))
+	for i, name := range names {
+		var dt arrow.DataType
+		switch types[name] {
+		case parquetBool:
+			dt = arrow.FixedWidthTypes.Boolean
+		case parquetInt:
+			dt = arrow.PrimitiveTypes.Int64
+		case parquetFloat:
+			dt = arrow.PrimitiveTypes.Float64
+		case parquetTimestamp:
+			dt = &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}
+		default:
+			dt = arrow.BinaryTypes.String
+		}
+		fields[i] = arrow.Field{Name: name, Type: dt, Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	for _, m := range rows {
+		for i, name := range names {
+			appendParquetValue(b.Field(i), types[name], m[name])
+		}
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	fw, err := pqarrow.NewFileWriter(schema, w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return err
+	}
+	if err := fw.Write(rec); err != nil {
+		fw.Close()
+		return err
+	}
+	return fw.Close()
+}
+
+// parquetType returns the parquet column type of a value.
+func parquetType(name string, v interface{}) int {
+	switch v := v.(type) {
+	case nil:
+		return parquetNull
+	case bool:
+		return parquetBool
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return parquetInt
+		}
+		return parquetFloat
+	case int, int64, uint, uint64:
+		return parquetInt
+	case float32, float64:
+		return parquetFloat
+	case string:
+		if name == "time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return parquetTimestamp
+			}
+		}
+		return parquetString
+	default:
+		return parquetString
+	}
+}
+
+// mergeParquetType returns the type of a column with the values of types a
+// and b.
+func mergeParquetType(a, b int) int {
+	switch {
+	case a == b, b == parquetNull:
+		return a
+	case a == parquetNull:
+		return b
+	case (a == parquetInt && b == parquetFloat) || (a == parquetFloat && b == parquetInt):
+		return parquetFloat
+	default:
+		return parquetString
+	}
+}
+
+// appendParquetValue appends a value to the builder of a column of a type.
+func appendParquetValue(b array.Builder, typ int, v interface{}) {
+	if v == nil {
+		b.AppendNull()
+		return
+	}
+	switch typ {
+	case parquetBool:
+		b.(*array.BooleanBuilder).Append(v.(bool))
+	case parquetInt:
+		b.(*array.Int64Builder).Append(toInt64(v))
+	case parquetFloat:
+		n, _ := toNumber(v)
+		b.(*array.Float64Builder).Append(n)
+	case parquetTimestamp:
+		t, _ := time.Parse(time.RFC3339Nano, v.(string))
+		b.(*array.TimestampBuilder).Append(arrow.Timestamp(t.UnixNano()))
+	default:
+		b.(*array.StringBuilder).Append(interfaceToString(v))
+	}
+}
+
+// toNumber returns the value of a number as a float.
+func toNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// toInt64 returns the value of an integer.
+func toInt64(v interface{}) int64 {
+	switch v := v.(type) {
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case uint:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+// isNumber returns true if a value is a number.
+func isNumber(v interface{}) bool {
+	_, ok := toNumber(v)
+	return ok
+}
+
+// sortedTags returns the tags as key and value joined by sep, sorted.
+func sortedTags(tags map[string]string, sep string) []string {
+	s := make([]string, 0, len(tags))
+	for k, v := range tags {
+		s = append(s, k+sep+v)
+	}
+	sort.Strings(s)
+	return s
+}
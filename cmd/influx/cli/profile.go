@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Profile is a named set of connection settings in the config file of the
+// CLI, like:
+//
+//	default = "prod"
+//
+//	[profiles.prod]
+//	host = "influxdb.example.com"
+//	ssl = true
+//	ca-cert = "~/.influx/ca.pem"
+//	cert = "~/.influx/client.pem"
+//	key = "~/.influx/client-key.pem"
+//	username = "ops"
+//	database = "telegraf"
+type Profile struct {
+	Name       string `toml:"-"`
+	Host       string `toml:"host"`
+	Port       int    `toml:"port"`
+	PathPrefix string `toml:"path-prefix"`
+	Socket     string `toml:"socket"`
+	Ssl        bool   `toml:"ssl"`
+	UnsafeSsl  bool   `toml:"unsafe-ssl"`
+	CACert     string `toml:"ca-cert"`
+	Cert       string `toml:"cert"`
+	Key        string `toml:"key"`
+	Username   string `toml:"username"`
+	Password   string `toml:"password"`
+	Database   string `toml:"database"`
+}
+
+// profilesConfig is the config file of the CLI.
+type profilesConfig struct {
+	Default  string             `toml:"default"`
+	Profiles map[string]Profile `toml:"profiles"`
+}
+
+// DefaultConfigPath returns the path of the config file of the CLI,
+// ~/.influx/config, or an empty path if there is no home directory.
+func DefaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".influx", "config")
+}
+
+// LoadProfile returns the profile of a config file with a name, or its default
+// profile if the name is empty. It returns nil if the name is empty and there
+// is no config file or no default profile.
+func LoadProfile(path, name string) (*Profile, error) {
+	var config profilesConfig
+	if path != "" {
+		if _, err := toml.DecodeFile(path, &config); os.IsNotExist(err) {
+			if name != "" {
+				return nil, fmt.Errorf("profile %q not found: no config file %s", name, path)
+			}
+			return nil, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to parse config file %s: %s", path, err)
+		}
+	}
+
+	if name == "" {
+		if config.Default == "" {
+			return nil, nil
+		}
+		name = config.Default
+	}
+	p, ok := config.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+
+	if p.Password != "" {
+		if fi, err := os.Stat(path); err == nil && fi.Mode().Perm()&0077 != 0 {
+			fmt.Fprintf(os.Stderr, "WARN: %s holds a password but can be read by other users.\n", path)
+		}
+	}
+	p.Name = name
+	p.CACert, p.Cert, p.Key = expandHome(p.CACert), expandHome(p.Cert), expandHome(p.Key)
+	return &p, nil
+}
+
+// ApplyProfile sets the settings of a profile that are set in it, except for
+// the ones that are set explicitly, as reported by isSet with the name of
+// their flag.
+func (c *CommandLine) ApplyProfile(p *Profile, isSet func(flag string) bool) {
+	setString := func(flag string, dst *string, v string) {
+		if v != "" && !isSet(flag) {
+			*dst = v
+		}
+	}
+	c.Profile = p.Name
+	setString("host", &c.Host, p.Host)
+	setString("path-prefix", &c.PathPrefix, p.PathPrefix)
+	setString("socket", &c.ClientConfig.UnixSocket, p.Socket)
+	setString("ca-cert", &c.CACert, p.CACert)
+	setString("cert", &c.ClientCert, p.Cert)
+	setString("key", &c.ClientKey, p.Key)
+	setString("username", &c.ClientConfig.Username, p.Username)
+	setString("password", &c.ClientConfig.Password, p.Password)
+	setString("database", &c.Database, p.Database)
+	if p.Port != 0 && !isSet("port") {
+		c.Port = p.Port
+	}
+	if p.Ssl && !isSet("ssl") {
+		c.Ssl = true
+	}
+	if p.UnsafeSsl && !isSet("unsafeSsl") {
+		c.ClientConfig.UnsafeSsl = true
+	}
+}
+
+// tlsConfig returns the TLS configuration with the CA certificate and the
+// client certificate of the CLI, or nil if none is set.
+func (c *CommandLine) tlsConfig() (*tls.Config, error) {
+	if c.CACert == "" && c.ClientCert == "" && c.ClientKey == "" {
+		return nil, nil
+	}
+
+	config := &tls.Config{}
+	if c.CACert != "" {
+		pem, err := os.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA certificate: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", c.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if c.ClientCert != "" || c.ClientKey != "" {
+		if c.ClientCert == "" || c.ClientKey == "" {
+			return nil, errors.New("a client certificate requires both -cert and -key")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// expandHome replaces the leading ~ of a path with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
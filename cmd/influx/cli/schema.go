@@ -0,0 +1,368 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/client"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxql"
+)
+
+// maxSchemaTagValues is the number of tag values the schema command lists.
+const maxSchemaTagValues = 100
+
+// schemaCache caches the names of the schema of the server for the tab
+// completion. The names are looked up the first time they are completed and
+// refreshed by the schema command.
+type schemaCache struct {
+	databases    []string
+	measurements map[string][]string // by database
+	keys         map[string][]string // tag and field keys, by database and measurement
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{
+		measurements: make(map[string][]string),
+		keys:         make(map[string][]string),
+	}
+}
+
+// schema browses the schema of the server with the arguments:
+//
+//	schema                                 the databases
+//	schema <database>                      the measurements of a database
+//	schema <database> <measurement>        the tag keys and field keys of a measurement
+//	schema <database> <measurement> <key>  the values of a tag key
+//
+// with their cardinality.
+func (c *CommandLine) schema(cmd string) error {
+	args := splitSchemaArgs(strings.TrimSuffix(strings.TrimSpace(cmd), ";"))[1:]
+	if len(args) > 3 {
+		fmt.Println("Usage: schema [database [measurement [tag key]]]")
+		return nil
+	}
+
+	var err error
+	switch len(args) {
+	case 0:
+		err = c.schemaDatabases(os.Stdout)
+	case 1:
+		err = c.schemaMeasurements(os.Stdout, args[0])
+	case 2:
+		err = c.schemaKeys(os.Stdout, args[0], args[1])
+	default:
+		err = c.schemaTagValues(os.Stdout, args[0], args[1], args[2])
+	}
+	if err != nil {
+		fmt.Printf("ERR: %s\n", err)
+	}
+	return err
+}
+
+// schemaDatabases writes the databases with the cardinality of their
+// measurements and series.
+func (c *CommandLine) schemaDatabases(w io.Writer) error {
+	databases, err := c.showNames("SHOW DATABASES")
+	if err != nil {
+		return err
+	}
+	c.completions().databases = databases
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "Database\tMeasurements\tSeries")
+	fmt.Fprintln(tw, "--------\t------------\t------")
+	for _, db := range databases {
+		on := influxql.QuoteIdent(db)
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", db,
+			c.showCount("SHOW MEASUREMENT CARDINALITY ON "+on, db),
+			c.showCount("SHOW SERIES CARDINALITY ON "+on, db))
+	}
+	return tw.Flush()
+}
+
+// schemaMeasurements writes the measurements of a database with the estimated
+// cardinality of their series.
+func (c *CommandLine) schemaMeasurements(w io.Writer, database string) error {
+	on := influxql.QuoteIdent(database)
+	measurements, err := c.showNames("SHOW MEASUREMENTS ON " + on)
+	if err != nil {
+		return err
+	}
+	c.completions().measurements[database] = measurements
+
+	series := make(map[string]string)
+	if rows, err := c.showRows("SHOW SERIES CARDINALITY ON "+on+" FROM /.*/", database); err == nil {
+		for _, row := range rows {
+			if len(row.Values) > 0 && len(row.Values[0]) > 0 {
+				series[row.Name] = interfaceToString(row.Values[0][0])
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "Measurement\tSeries")
+	fmt.Fprintln(tw, "-----------\t------")
+	for _, m := range measurements {
+		n, ok := series[m]
+		if !ok {
+			n = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", m, n)
+	}
+	return tw.Flush()
+}
+
+// schemaKeys writes the tag keys of a measurement with the cardinality of
+// their values, and its field keys with their types.
+func (c *CommandLine) schemaKeys(w io.Writer, database, measurement string) error {
+	from := " ON " + influxql.QuoteIdent(database) + " FROM " + influxql.QuoteIdent(measurement)
+	tagKeys, err := c.showNames("SHOW TAG KEYS"+from, database)
+	if err != nil {
+		return err
+	}
+	rows, err := c.showRows("SHOW FIELD KEYS"+from, database)
+	if err != nil {
+		return err
+	}
+
+	keys := append([]string(nil), tagKeys...)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "Tag Key\tValues")
+	fmt.Fprintln(tw, "-------\t------")
+	for _, k := range tagKeys {
+		fmt.Fprintf(tw, "%s\t%s\n", k, c.showCount("SHOW TAG VALUES CARDINALITY"+from+" WITH KEY = "+influxql.QuoteIdent(k), database))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Field Key\tType")
+	fmt.Fprintln(tw, "---------\t----")
+	for _, row := range rows {
+		for _, v := range row.Values {
+			if len(v) > 1 {
+				k := interfaceToString(v[0])
+				keys = append(keys, k)
+				fmt.Fprintf(tw, "%s\t%s\n", k, interfaceToString(v[1]))
+			}
+		}
+	}
+	c.completions().keys[database+"."+measurement] = keys
+	return tw.Flush()
+}
+
+// schemaTagValues writes the first values of a tag key of a measurement.
+func (c *CommandLine) schemaTagValues(w io.Writer, database, measurement, key string) error {
+	from := " ON " + influxql.QuoteIdent(database) + " FROM " + influxql.QuoteIdent(measurement)
+	rows, err := c.showRows(fmt.Sprintf("SHOW TAG VALUES%s WITH KEY = %s LIMIT %d", from, influxql.QuoteIdent(key), maxSchemaTagValues), database)
+	if err != nil {
+		return err
+	}
+
+	var n int
+	fmt.Fprintln(w, "Tag Value")
+	fmt.Fprintln(w, "---------")
+	for _, row := range rows {
+		for _, v := range row.Values {
+			if len(v) > 1 {
+				fmt.Fprintln(w, interfaceToString(v[1]))
+				n++
+			}
+		}
+	}
+	if n == maxSchemaTagValues {
+		fmt.Fprintf(w, "\nShowing the first %d of %s values.\n", n, c.showCount("SHOW TAG VALUES CARDINALITY"+from+" WITH KEY = "+influxql.QuoteIdent(key), database))
+	}
+	return nil
+}
+
+// showRows executes a SHOW statement in a database, if any, and returns the
+// rows of its results.
+func (c *CommandLine) showRows(stmt string, database ...string) ([]models.Row, error) {
+	q := client.Query{Command: stmt}
+	if len(database) > 0 {
+		q.Database = database[0]
+	}
+	response, err := c.Client.QueryContext(context.Background(), q)
+	if err != nil {
+		return nil, err
+	} else if err := response.Error(); err != nil {
+		return nil, err
+	}
+
+	var rows []models.Row
+	for _, result := range response.Results {
+		rows = append(rows, result.Series...)
+	}
+	return rows, nil
+}
+
+// showNames returns the values of the first column of the rows of a SHOW
+// statement.
+func (c *CommandLine) showNames(stmt string, database ...string) ([]string, error) {
+	rows, err := c.showRows(stmt, database...)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, row := range rows {
+		for _, v := range row.Values {
+			if len(v) > 0 {
+				names = append(names, interfaceToString(v[0]))
+			}
+		}
+	}
+	return names, nil
+}
+
+// showCount returns the count of a cardinality statement, or "-" if it fails.
+func (c *CommandLine) showCount(stmt, database string) string {
+	rows, err := c.showRows(stmt, database)
+	if err != nil || len(rows) == 0 || len(rows[0].Values) == 0 || len(rows[0].Values[0]) == 0 {
+		return "-"
+	}
+	return interfaceToString(rows[0].Values[0][0])
+}
+
+// splitSchemaArgs splits the arguments of a command on spaces, except the
+// ones in double quotes, which are removed.
+func splitSchemaArgs(s string) []string {
+	var args []string
+	var b strings.Builder
+	quoted, started := false, false
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\' && quoted && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case ch == '"':
+			quoted, started = !quoted, true
+		case (ch == ' ' || ch == '\t') && !quoted:
+			if started {
+				args = append(args, b.String())
+				b.Reset()
+				started = false
+			}
+		default:
+			b.WriteByte(ch)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, b.String())
+	}
+	return args
+}
+
+// completions returns the cache of the names of the tab completion.
+func (c *CommandLine) completions() *schemaCache {
+	if c.schemaCache == nil {
+		c.schemaCache = newSchemaCache()
+	}
+	return c.schemaCache
+}
+
+// The commands of the CLI, for the tab completion of the first word.
+var completionCommands = []string{
+	"auth", "bench", "chunk size", "chunked", "clear", "connect", "consistency", "exit",
+	"format", "help", "history", "insert", "node", "params", "precision",
+	"pretty", "quit", "schema", "select", "set time zone", "settings", "show",
+	"use", "watch",
+}
+
+// completionFrom matches the measurement of a FROM clause.
+var completionFrom = regexp.MustCompile(`(?i)\bFROM\s+("(?:[^"\\]|\\.)*"|[A-Za-z_][A-Za-z0-9_]*)`)
+
+// completeWord completes the word of the line before pos with the commands of
+// the CLI, or the names of the schema: the databases after USE, ON and
+// schema, the measurements after FROM and schema <database>, and the tag and
+// field keys of the measurement of the FROM clause elsewhere.
+func (c *CommandLine) completeWord(line string, pos int) (string, []string, string) {
+	if pos > len(line) {
+		pos = len(line)
+	}
+	before, tail := line[:pos], line[pos:]
+	start := strings.LastIndexAny(before, " \t(,=") + 1
+	head, word := before[:start], before[start:]
+	prefix := strings.TrimPrefix(word, `"`)
+
+	fields := splitSchemaArgs(head)
+	var names []string
+	switch {
+	case len(fields) == 0:
+		names = completionCommands
+	case strings.EqualFold(fields[0], "use") && len(fields) == 1:
+		names = c.completeDatabases()
+	case strings.EqualFold(fields[0], "schema"):
+		switch len(fields) {
+		case 1:
+			names = c.completeDatabases()
+		case 2:
+			names = c.completeMeasurements(fields[1])
+		case 3:
+			names = c.completeKeys(fields[1], fields[2])
+		default:
+		}
+	case strings.EqualFold(fields[len(fields)-1], "on"):
+		names = c.completeDatabases()
+	case strings.EqualFold(fields[len(fields)-1], "from"), strings.EqualFold(fields[len(fields)-1], "measurement"):
+		names = c.completeMeasurements(c.Database)
+	default:
+		if m := completionFrom.FindStringSubmatch(line); m != nil && c.Database != "" {
+			name := m[1]
+			if strings.HasPrefix(name, `"`) {
+				name = splitSchemaArgs(name)[0]
+			}
+			names = c.completeKeys(c.Database, name)
+		}
+	}
+
+	var completions []string
+	for _, name := range names {
+		if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			continue
+		}
+		if len(fields) > 0 {
+			name = influxql.QuoteIdent(name)
+		}
+		completions = append(completions, name)
+	}
+	sort.Strings(completions)
+	return head, completions, tail
+}
+
+func (c *CommandLine) completeDatabases() []string {
+	cache := c.completions()
+	if cache.databases == nil && c.Client != nil {
+		cache.databases, _ = c.showNames("SHOW DATABASES")
+	}
+	return cache.databases
+}
+
+func (c *CommandLine) completeMeasurements(database string) []string {
+	cache := c.completions()
+	names, ok := cache.measurements[database]
+	if !ok && database != "" && c.Client != nil {
+		names, _ = c.showNames("SHOW MEASUREMENTS ON " + influxql.QuoteIdent(database))
+		cache.measurements[database] = names
+	}
+	return names
+}
+
+func (c *CommandLine) completeKeys(database, measurement string) []string {
+	cache := c.completions()
+	key := database + "." + measurement
+	names, ok := cache.keys[key]
+	if !ok && database != "" && c.Client != nil {
+		from := " ON " + influxql.QuoteIdent(database) + " FROM " + influxql.QuoteIdent(measurement)
+		names, _ = c.showNames("SHOW TAG KEYS"+from, database)
+		fields, _ := c.showNames("SHOW FIELD KEYS"+from, database)
+		names = append(names, fields...)
+		cache.keys[key] = names
+	}
+	return names
+}
@@ -0,0 +1,392 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A script is a file of commands of the CLI, one per line, that are executed
+// in order with the directives:
+//
+//	LET name = value
+//	FOR name[, name...] IN SHOW ... | SELECT ... | (value, value...)
+//	IF left == right | left != right | left =~ /regex/ | left !~ /regex/
+//	ELSE
+//	END
+//
+// The FOR loops and the IF conditions end with END. A FOR loop over a query
+// binds its variables to the columns of each of the rows of the results, in
+// order. The variables are interpolated into the commands and the directives
+// as ${name}. The lines starting with # or -- are comments, and a line ending
+// with \ continues on the next one.
+
+// ScriptVars are the variables of a script set with -var name=value.
+type ScriptVars map[string]string
+
+// String returns the variables as name=value pairs.
+func (v ScriptVars) String() string {
+	pairs := make([]string, 0, len(v))
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set sets a variable from a name=value pair.
+func (v *ScriptVars) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i < 0 || !scriptName.MatchString(s[:i]) {
+		return fmt.Errorf("invalid variable %q, expected name=value", s)
+	}
+	if *v == nil {
+		*v = make(ScriptVars)
+	}
+	(*v)[s[:i]] = s[i+1:]
+	return nil
+}
+
+var (
+	scriptName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	scriptVar  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+	scriptLet = regexp.MustCompile(`(?is)^LET\s+([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+	scriptFor = regexp.MustCompile(`(?is)^FOR\s+([A-Za-z_][A-Za-z0-9_]*(?:\s*,\s*[A-Za-z_][A-Za-z0-9_]*)*)\s+IN\s+(.+)$`)
+	scriptIf  = regexp.MustCompile(`(?is)^IF\s+(.+?)\s*(==|!=|=~|!~)\s*(.+)$`)
+)
+
+// errScriptExit is returned by a script that exits.
+var errScriptExit = errors.New("exit")
+
+// The kinds of statements of a script.
+const (
+	scriptCommand = iota
+	scriptLetStmt
+	scriptForStmt
+	scriptIfStmt
+)
+
+// scriptStmt is a statement of a script: a command or a directive.
+type scriptStmt struct {
+	line int
+	kind int
+
+	// text is the command, the value of a LET, the query of a FOR or the
+	// left side of an IF.
+	text  string
+	names []string // Variables of a LET or a FOR.
+	list  []string // Values of a FOR over a list.
+	op    string   // Operator of an IF.
+	right string   // Right side of an IF.
+
+	body []*scriptStmt
+	alt  []*scriptStmt // ELSE of an IF.
+}
+
+// ExecuteScript executes the script at path, or read from STDIN if path is
+// "-". It stops at the first command that fails.
+func (c *CommandLine) ExecuteScript(path string) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	stmts, err := parseScript(r)
+	if err != nil {
+		return fmt.Errorf("%s:%s", path, err)
+	}
+
+	vars := make(map[string]string, len(c.ScriptVars))
+	for name, value := range c.ScriptVars {
+		vars[name] = value
+	}
+	if err := c.executeScript(stmts, vars); err != nil && err != errScriptExit {
+		return fmt.Errorf("%s:%s", path, err)
+	}
+	return nil
+}
+
+// parseScript parses the statements of a script. Its errors start with the
+// number of the line.
+func parseScript(r io.Reader) ([]*scriptStmt, error) {
+	type block struct {
+		stmt   *scriptStmt
+		inElse bool
+	}
+	var root []*scriptStmt
+	var blocks []*block
+	add := func(s *scriptStmt) {
+		if len(blocks) == 0 {
+			root = append(root, s)
+			return
+		}
+		b := blocks[len(blocks)-1]
+		if b.inElse {
+			b.stmt.alt = append(b.stmt.alt, s)
+		} else {
+			b.stmt.body = append(b.stmt.body, s)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var n, start int
+	var text string
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			start = n
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "--") {
+				continue
+			}
+		}
+		if strings.HasSuffix(line, `\`) {
+			text += strings.TrimSpace(strings.TrimSuffix(line, `\`)) + " "
+			continue
+		}
+		text += line
+		line, text = strings.TrimSpace(text), ""
+
+		keyword := strings.ToUpper(strings.Fields(line)[0])
+		switch keyword {
+		case "LET":
+			m := scriptLet.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("%d: invalid LET, expected LET name = value", start)
+			}
+			add(&scriptStmt{line: start, kind: scriptLetStmt, names: []string{m[1]}, text: m[2]})
+		case "FOR":
+			m := scriptFor.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("%d: invalid FOR, expected FOR name IN query or (values)", start)
+			}
+			s := &scriptStmt{line: start, kind: scriptForStmt}
+			for _, name := range strings.Split(m[1], ",") {
+				s.names = append(s.names, strings.TrimSpace(name))
+			}
+			if in := strings.TrimSpace(m[2]); strings.HasPrefix(in, "(") && strings.HasSuffix(in, ")") {
+				s.list = []string{}
+				for _, v := range strings.Split(in[1:len(in)-1], ",") {
+					if v = unquoteScriptValue(strings.TrimSpace(v)); v != "" {
+						s.list = append(s.list, v)
+					}
+				}
+			} else {
+				s.text = in
+			}
+			add(s)
+			blocks = append(blocks, &block{stmt: s})
+		case "IF":
+			m := scriptIf.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("%d: invalid IF, expected IF left ==, !=, =~ or !~ right", start)
+			}
+			s := &scriptStmt{line: start, kind: scriptIfStmt, text: m[1], op: m[2], right: m[3]}
+			add(s)
+			blocks = append(blocks, &block{stmt: s})
+		case "ELSE":
+			if len(blocks) == 0 || blocks[len(blocks)-1].stmt.kind != scriptIfStmt || blocks[len(blocks)-1].inElse {
+				return nil, fmt.Errorf("%d: ELSE without IF", start)
+			}
+			blocks[len(blocks)-1].inElse = true
+		case "END":
+			if len(blocks) == 0 {
+				return nil, fmt.Errorf("%d: END without FOR or IF", start)
+			}
+			blocks = blocks[:len(blocks)-1]
+		default:
+			add(&scriptStmt{line: start, kind: scriptCommand, text: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%d: %s", n, err)
+	}
+	if len(blocks) > 0 {
+		s := blocks[len(blocks)-1].stmt
+		return nil, fmt.Errorf("%d: missing END", s.line)
+	}
+	return root, nil
+}
+
+// scriptError is the error of a statement of a script.
+type scriptError struct {
+	line int
+	err  error
+}
+
+func (e *scriptError) Error() string {
+	return fmt.Sprintf("%d: %s", e.line, e.err)
+}
+
+// executeScript executes statements with variables.
+func (c *CommandLine) executeScript(stmts []*scriptStmt, vars map[string]string) error {
+	for _, s := range stmts {
+		err := c.executeScriptStmt(s, vars)
+		if _, ok := err.(*scriptError); ok || err == errScriptExit {
+			return err
+		} else if err != nil {
+			return &scriptError{line: s.line, err: err}
+		}
+	}
+	return nil
+}
+
+func (c *CommandLine) executeScriptStmt(s *scriptStmt, vars map[string]string) error {
+	switch s.kind {
+	case scriptLetStmt:
+		value, err := interpolate(s.text, vars)
+		if err != nil {
+			return err
+		}
+		vars[s.names[0]] = unquoteScriptValue(value)
+		return nil
+
+	case scriptForStmt:
+		rows := make([][]string, 0, len(s.list))
+		for _, v := range s.list {
+			v, err := interpolate(v, vars)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, []string{v})
+		}
+		if s.text != "" {
+			query, err := interpolate(s.text, vars)
+			if err != nil {
+				return err
+			}
+			if rows, err = c.scriptRows(query); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			if len(row) < len(s.names) {
+				return fmt.Errorf("FOR has %d variables but the rows have %d columns", len(s.names), len(row))
+			}
+			for i, name := range s.names {
+				vars[name] = row[i]
+			}
+			if err := c.executeScript(s.body, vars); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case scriptIfStmt:
+		ok, err := evalScriptCondition(s, vars)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return c.executeScript(s.body, vars)
+		}
+		return c.executeScript(s.alt, vars)
+
+	default:
+		cmd, err := interpolate(s.text, vars)
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(strings.Fields(cmd)[0]) {
+		case "exit", "quit":
+			return errScriptExit
+		default:
+			return c.ParseCommand(cmd)
+		}
+	}
+}
+
+// scriptRows executes a query and returns the values of the rows of its
+// results as strings.
+func (c *CommandLine) scriptRows(query string) ([][]string, error) {
+	response, err := c.Client.QueryContext(context.Background(), c.query(query))
+	if err != nil {
+		return nil, err
+	} else if err := response.Error(); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			for _, values := range series.Values {
+				row := make([]string, len(values))
+				for i, v := range values {
+					row[i] = interfaceToString(v)
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// evalScriptCondition evaluates the condition of an IF.
+func evalScriptCondition(s *scriptStmt, vars map[string]string) (bool, error) {
+	left, err := interpolate(s.text, vars)
+	if err != nil {
+		return false, err
+	}
+	right, err := interpolate(s.right, vars)
+	if err != nil {
+		return false, err
+	}
+	left, right = unquoteScriptValue(left), strings.TrimSpace(right)
+
+	switch s.op {
+	case "==":
+		return left == unquoteScriptValue(right), nil
+	case "!=":
+		return left != unquoteScriptValue(right), nil
+	case "=~", "!~":
+		if len(right) < 2 || right[0] != '/' || right[len(right)-1] != '/' {
+			return false, fmt.Errorf("invalid regex %s, expected /regex/", right)
+		}
+		re, err := regexp.Compile(right[1 : len(right)-1])
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(left) == (s.op == "=~"), nil
+	default:
+		return false, fmt.Errorf("invalid operator %s", s.op)
+	}
+}
+
+// interpolate replaces the variables of a text by their values.
+func interpolate(text string, vars map[string]string) (string, error) {
+	var err error
+	out := scriptVar.ReplaceAllStringFunc(text, func(s string) string {
+		name := s[2 : len(s)-1]
+		v, ok := vars[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("undefined variable %s", name)
+		}
+		return v
+	})
+	return out, err
+}
+
+// unquoteScriptValue removes the double quotes around a value, if any.
+func unquoteScriptValue(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if v, err := strconv.Unquote(s); err == nil {
+			return v
+		}
+	}
+	return s
+}
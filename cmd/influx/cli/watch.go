@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxql"
+	"golang.org/x/term"
+)
+
+// minWatchInterval is the shortest interval a query can be watched at.
+const minWatchInterval = 100 * time.Millisecond
+
+// The ANSI escape sequences used to redraw the terminal.
+const (
+	ansiClear   = "\033[H\033[2J"
+	ansiReverse = "\033[7m"
+	ansiReset   = "\033[0m"
+)
+
+// watch executes a query on an interval and redraws its latest result in place
+// until it's interrupted, like watch(1). The lines that changed since the
+// previous result are highlighted. If STDOUT isn't a terminal, the results are
+// written one after the other instead.
+func (c *CommandLine) watch(cmd string) error {
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(cmd), ";"))
+	if len(fields) < 3 {
+		fmt.Println("Usage: watch <interval> <query>, such as: watch 5s SELECT count(*) FROM cpu")
+		return nil
+	}
+	interval, err := influxql.ParseDuration(fields[1])
+	if err != nil {
+		fmt.Printf("ERR: invalid interval %q: %s\n", fields[1], err)
+		return err
+	} else if interval < minWatchInterval {
+		err := fmt.Errorf("the interval must be at least %s", minWatchInterval)
+		fmt.Printf("ERR: %s\n", err)
+		return err
+	}
+	if c.Format == "parquet" {
+		err := errors.New("the parquet format can't be watched")
+		fmt.Printf("ERR: %s\n", err)
+		return err
+	}
+
+	// Keep the query as it was typed, after the interval.
+	query := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(cmd), ";"))
+	query = strings.TrimSpace(query[strings.Index(query, fields[1])+len(fields[1]):])
+	if query, err = c.rewriteSources(query); err != nil {
+		fmt.Printf("ERR: %s\n", err)
+		return err
+	}
+
+	terminal := term.IsTerminal(int(os.Stdout.Fd()))
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	var previous []string
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		interrupted := make(chan struct{})
+		go func() {
+			select {
+			case <-done:
+			case <-c.osSignals:
+				close(interrupted)
+				cancel()
+			}
+		}()
+
+		now := time.Now()
+		var buf bytes.Buffer
+		response, err := c.Client.QueryContext(ctx, c.query(query))
+		close(done)
+		cancel()
+		select {
+		case <-interrupted:
+			return nil
+		default:
+		}
+		if err != nil {
+			fmt.Fprintf(&buf, "ERR: %s\n", err)
+		} else {
+			c.FormatResponse(response, &buf)
+			if err := response.Error(); err != nil {
+				fmt.Fprintf(&buf, "ERR: %s\n", err)
+			}
+		}
+
+		header := fmt.Sprintf("Every %s: %s  %s", influxql.FormatDuration(interval), query, now.Format(time.RFC3339))
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if terminal {
+			writeWatch(os.Stdout, header, lines, previous, true)
+		} else {
+			if previous != nil {
+				fmt.Println()
+			}
+			writeWatch(os.Stdout, header, lines, nil, false)
+		}
+		previous = lines
+
+		select {
+		case <-tick.C:
+		case <-c.osSignals:
+			return nil
+		}
+	}
+}
+
+// writeWatch writes the header and the lines of a result, with the lines that
+// changed since the previous one highlighted, clearing the terminal first if
+// redraw is set.
+func writeWatch(w io.Writer, header string, lines, previous []string, redraw bool) {
+	var b strings.Builder
+	if redraw {
+		b.WriteString(ansiClear)
+	}
+	b.WriteString(header + "\n\n")
+	for i, line := range lines {
+		if previous != nil && (i >= len(previous) || previous[i] != line) && line != "" {
+			b.WriteString(ansiReverse + line + ansiReset + "\n")
+		} else {
+			b.WriteString(line + "\n")
+		}
+	}
+	io.WriteString(w, b.String())
+}
@@ -0,0 +1,557 @@
+// Package copier implements the copy subcommand of the influx command, which
+// copies the points of measurements from one server to another.
+package copier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/client"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxql"
+)
+
+// The defaults of the options of the copy.
+const (
+	DefaultWindow        = 24 * time.Hour
+	DefaultChunkSize     = 10000
+	DefaultBatchSize     = 5000
+	DefaultRetries       = 5
+	DefaultRetryInterval = time.Second
+
+	// maxRetryInterval is the longest time between two retries of a batch.
+	maxRetryInterval = time.Minute
+)
+
+// Command represents the program execution for "influx copy".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	src, dest                   string
+	srcUsername, srcPassword    string
+	destUsername, destPassword  string
+	unsafeSSL                   bool
+	database, retentionPolicy   string
+	destDatabase, destRetention string
+	measurements                []string
+	start, end                  time.Time
+	window                      time.Duration
+	chunkSize, batchSize        int
+	pps                         int
+	retries                     int
+	retryInterval               time.Duration
+	checkpoint                  string
+
+	srcClient, destClient *client.Client
+	fieldTypes            map[string]string // of the measurement being copied
+	startTime             time.Time
+	throttled             int
+	points                int64
+}
+
+// NewCommand returns a new instance of Command with default settings.
+func NewCommand() *Command {
+	return &Command{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+// checkpoint is the position where a copy resumes: the start of the first
+// window of a measurement that wasn't copied yet.
+type checkpoint struct {
+	Source      string `json:"source"`
+	Measurement string `json:"measurement"`
+	Time        int64  `json:"time"`
+}
+
+// Run executes the program.
+func (cmd *Command) Run(args ...string) error {
+	if err := cmd.parseFlags(args); err == flag.ErrHelp {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var err error
+	if cmd.srcClient, err = cmd.newClient(cmd.src, cmd.srcUsername, cmd.srcPassword); err != nil {
+		return fmt.Errorf("invalid -src: %s", err)
+	}
+	if cmd.destClient, err = cmd.newClient(cmd.dest, cmd.destUsername, cmd.destPassword); err != nil {
+		return fmt.Errorf("invalid -dest: %s", err)
+	}
+	// The points are queried and written with timestamps in nanoseconds.
+	cmd.srcClient.SetPrecision("ns")
+
+	ctx := context.Background()
+	if len(cmd.measurements) == 0 {
+		rows, err := cmd.show(ctx, "SHOW MEASUREMENTS ON "+influxql.QuoteIdent(cmd.database))
+		if err != nil {
+			return err
+		}
+		for _, v := range rows {
+			cmd.measurements = append(cmd.measurements, fmt.Sprint(v[0]))
+		}
+	}
+	sort.Strings(cmd.measurements)
+
+	resume, err := cmd.loadCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	cmd.startTime = time.Now()
+	for _, m := range cmd.measurements {
+		start := cmd.start
+		if resume != nil {
+			if m < resume.Measurement {
+				continue
+			} else if m == resume.Measurement {
+				start = time.Unix(0, resume.Time).UTC()
+				fmt.Fprintf(cmd.Stdout, "Resuming the copy of %s at %s\n", m, start.Format(time.RFC3339Nano))
+			}
+		}
+		if err := cmd.copyMeasurement(ctx, m, start); err != nil {
+			return fmt.Errorf("error copying %s: %s; run it again with -checkpoint to resume", m, err)
+		}
+	}
+
+	if cmd.checkpoint != "" {
+		if err := os.Remove(cmd.checkpoint); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	fmt.Fprintf(cmd.Stdout, "Copied %d points in %s\n", cmd.points, time.Since(cmd.startTime).Round(time.Second))
+	return nil
+}
+
+// parseFlags parses the flags of the command.
+func (cmd *Command) parseFlags(args []string) error {
+	fs := flag.NewFlagSet("copy", flag.ContinueOnError)
+	fs.SetOutput(cmd.Stderr)
+	fs.Usage = cmd.printUsage
+	fs.StringVar(&cmd.src, "src", "", "")
+	fs.StringVar(&cmd.srcUsername, "src-username", "", "")
+	fs.StringVar(&cmd.srcPassword, "src-password", "", "")
+	fs.StringVar(&cmd.dest, "dest", "", "")
+	fs.StringVar(&cmd.destUsername, "dest-username", "", "")
+	fs.StringVar(&cmd.destPassword, "dest-password", "", "")
+	fs.BoolVar(&cmd.unsafeSSL, "unsafeSsl", false, "")
+	fs.StringVar(&cmd.database, "database", "", "")
+	fs.StringVar(&cmd.retentionPolicy, "retention-policy", "", "")
+	fs.StringVar(&cmd.destDatabase, "dest-database", "", "")
+	fs.StringVar(&cmd.destRetention, "dest-retention-policy", "", "")
+	measurements := fs.String("measurement", "", "")
+	start := fs.String("start", "", "")
+	end := fs.String("end", "", "")
+	fs.DurationVar(&cmd.window, "window", DefaultWindow, "")
+	fs.IntVar(&cmd.chunkSize, "chunk-size", DefaultChunkSize, "")
+	fs.IntVar(&cmd.batchSize, "batch-size", DefaultBatchSize, "")
+	fs.IntVar(&cmd.pps, "pps", 0, "")
+	fs.IntVar(&cmd.retries, "retries", DefaultRetries, "")
+	fs.DurationVar(&cmd.retryInterval, "retry-interval", DefaultRetryInterval, "")
+	fs.StringVar(&cmd.checkpoint, "checkpoint", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	switch {
+	case cmd.src == "" || cmd.dest == "":
+		return errors.New("-src and -dest are required")
+	case cmd.database == "":
+		return errors.New("-database is required")
+	case cmd.window <= 0:
+		return errors.New("-window must be positive")
+	case cmd.chunkSize <= 0 || cmd.batchSize <= 0:
+		return errors.New("-chunk-size and -batch-size must be positive")
+	case cmd.retries < 0:
+		return errors.New("-retries must not be negative")
+	default:
+	}
+	if cmd.destDatabase == "" {
+		cmd.destDatabase = cmd.database
+	}
+	if cmd.destRetention == "" {
+		cmd.destRetention = cmd.retentionPolicy
+	}
+	if *measurements != "" {
+		for _, m := range strings.Split(*measurements, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				cmd.measurements = append(cmd.measurements, m)
+			}
+		}
+	}
+
+	var err error
+	if *start != "" {
+		if cmd.start, err = time.Parse(time.RFC3339Nano, *start); err != nil {
+			return fmt.Errorf("invalid -start: %s", err)
+		}
+	}
+	cmd.end = time.Now().UTC()
+	if *end != "" {
+		if cmd.end, err = time.Parse(time.RFC3339Nano, *end); err != nil {
+			return fmt.Errorf("invalid -end: %s", err)
+		}
+	}
+	if !cmd.start.IsZero() && !cmd.start.Before(cmd.end) {
+		return errors.New("-start must be before -end")
+	}
+	return nil
+}
+
+// newClient returns a client of the server at addr, a URL or host:port.
+func (cmd *Command) newClient(addr, username, password string) (*client.Client, error) {
+	var u url.URL
+	if strings.Contains(addr, "://") {
+		p, err := url.Parse(addr)
+		if err != nil {
+			return nil, err
+		}
+		u = *p
+	} else {
+		p, err := client.ParseConnectionString(addr, false)
+		if err != nil {
+			return nil, err
+		}
+		u = p
+	}
+
+	c, err := client.NewClient(client.Config{
+		URL:       u,
+		Username:  username,
+		Password:  password,
+		UserAgent: "InfluxDBCopy",
+		UnsafeSsl: cmd.unsafeSSL,
+		Proxy:     http.ProxyFromEnvironment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := c.Ping(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// source returns the quoted source of a measurement in the source database.
+func (cmd *Command) source(measurement string) string {
+	return influxql.QuoteIdent(cmd.database, cmd.retentionPolicy, measurement)
+}
+
+// copyMeasurement copies the points of a measurement from start to the end of
+// the copy, window by window. The checkpoint is saved after each window.
+func (cmd *Command) copyMeasurement(ctx context.Context, measurement string, start time.Time) error {
+	cmd.fieldTypes = make(map[string]string)
+	rows, err := cmd.show(ctx, "SHOW FIELD KEYS ON "+influxql.QuoteIdent(cmd.database)+" FROM "+influxql.QuoteIdent(measurement))
+	if err != nil {
+		return err
+	}
+	for _, v := range rows {
+		if len(v) > 1 {
+			cmd.fieldTypes[fmt.Sprint(v[0])] = fmt.Sprint(v[1])
+		}
+	}
+
+	if start.IsZero() {
+		// Start at the first point of the measurement.
+		rows, err := cmd.show(ctx, "SELECT * FROM "+cmd.source(measurement)+" ORDER BY time ASC LIMIT 1")
+		if err != nil {
+			return err
+		} else if len(rows) == 0 {
+			fmt.Fprintf(cmd.Stdout, "%s: no points\n", measurement)
+			return nil
+		}
+		n, err := rows[0][0].(json.Number).Int64()
+		if err != nil {
+			return fmt.Errorf("invalid time %v", rows[0][0])
+		}
+		start = time.Unix(0, n).UTC()
+	}
+
+	for t := start; t.Before(cmd.end); t = t.Add(cmd.window) {
+		end := t.Add(cmd.window)
+		if end.After(cmd.end) {
+			end = cmd.end
+		}
+		n, err := cmd.copyWindow(ctx, measurement, t, end)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.Stdout, "%s: %s - %s: %d points\n", measurement, t.Format(time.RFC3339), end.Format(time.RFC3339), n)
+		if err := cmd.saveCheckpoint(measurement, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyWindow copies the points of a measurement from start to end, its chunks
+// as they are read, and returns the number of points copied.
+func (cmd *Command) copyWindow(ctx context.Context, measurement string, start, end time.Time) (int, error) {
+	q := client.Query{
+		Command: fmt.Sprintf("SELECT * FROM %s WHERE time >= %d AND time < %d GROUP BY *",
+			cmd.source(measurement), start.UnixNano(), end.UnixNano()),
+		Database:  cmd.database,
+		ChunkSize: cmd.chunkSize,
+	}
+
+	var n int
+	lines := make([]string, 0, cmd.batchSize)
+	flush := func() error {
+		if len(lines) == 0 {
+			return nil
+		}
+		cmd.throttle(len(lines))
+		if err := cmd.write(lines); err != nil {
+			return err
+		}
+		n += len(lines)
+		cmd.points += int64(len(lines))
+		lines = lines[:0]
+		return nil
+	}
+
+	err := cmd.srcClient.QueryChunks(ctx, q, func(response *client.Response) error {
+		for _, result := range response.Results {
+			for _, row := range result.Series {
+				for _, values := range row.Values {
+					line, err := cmd.line(measurement, row, values)
+					if err != nil {
+						return err
+					} else if line == "" {
+						continue
+					}
+					if lines = append(lines, line); len(lines) == cmd.batchSize {
+						if err := flush(); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, flush()
+}
+
+// line returns a row as line protocol, with the type of the fields of SHOW
+// FIELD KEYS, or an empty line if it has no fields.
+func (cmd *Command) line(measurement string, row models.Row, values []interface{}) (string, error) {
+	fields := make(models.Fields, len(row.Columns))
+	var t time.Time
+	for i, name := range row.Columns {
+		if i >= len(values) || values[i] == nil {
+			continue
+		}
+		if name == "time" {
+			n, err := values[i].(json.Number).Int64()
+			if err != nil {
+				return "", fmt.Errorf("invalid time %v", values[i])
+			}
+			t = time.Unix(0, n).UTC()
+			continue
+		}
+		fields[name] = fieldValue(values[i], cmd.fieldTypes[name])
+	}
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	pt, err := models.NewPoint(measurement, models.NewTags(row.Tags), fields, t)
+	if err != nil {
+		return "", err
+	}
+	return pt.String(), nil
+}
+
+// fieldValue returns the value of a field of a type of SHOW FIELD KEYS.
+func fieldValue(v interface{}, typ string) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	switch typ {
+	case "integer":
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+	case "unsigned":
+		if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+			return u
+		}
+	default:
+	}
+	f, _ := n.Float64()
+	return f
+}
+
+// write writes a batch of lines to the destination. It's retried with an
+// exponential backoff on network and server errors, since a retry of the same
+// points is harmless.
+func (cmd *Command) write(lines []string) error {
+	data := strings.Join(lines, "\n")
+	interval := cmd.retryInterval
+	for retry := 0; ; retry++ {
+		_, err := cmd.destClient.WriteLineProtocol(data, cmd.destDatabase, cmd.destRetention, "ns", "")
+		if err == nil {
+			return nil
+		}
+		var werr *client.WriteError
+		if errors.As(err, &werr) && werr.StatusCode != http.StatusTooManyRequests && werr.StatusCode < 500 {
+			return err
+		} else if retry == cmd.retries {
+			return fmt.Errorf("error writing batch after %d retries: %s", retry, err)
+		}
+
+		fmt.Fprintf(cmd.Stderr, "error writing batch, retrying in %s: %s\n", interval, err)
+		time.Sleep(interval)
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
+
+// throttle waits until n more points can be written without exceeding the
+// points per second of the copy.
+func (cmd *Command) throttle(n int) {
+	if cmd.pps <= 0 {
+		return
+	}
+	cmd.throttled += n
+	if wait := time.Duration(float64(cmd.throttled)/float64(cmd.pps)*float64(time.Second)) - time.Since(cmd.startTime); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// show executes a statement on the source and returns the values of its rows.
+func (cmd *Command) show(ctx context.Context, stmt string) ([][]interface{}, error) {
+	response, err := cmd.srcClient.QueryContext(ctx, client.Query{Command: stmt, Database: cmd.database})
+	if err != nil {
+		return nil, err
+	} else if err := response.Error(); err != nil {
+		return nil, err
+	}
+	var values [][]interface{}
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			values = append(values, row.Values...)
+		}
+	}
+	return values, nil
+}
+
+// sourceKey identifies the source of a copy in its checkpoint.
+func (cmd *Command) sourceKey() string {
+	return cmd.srcClient.Addr() + "/" + cmd.database + "/" + cmd.retentionPolicy
+}
+
+// loadCheckpoint loads the position to resume the copy from, if its
+// checkpoint file exists.
+func (cmd *Command) loadCheckpoint() (*checkpoint, error) {
+	if cmd.checkpoint == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(cmd.checkpoint)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %s", cmd.checkpoint, err)
+	} else if c.Source != cmd.sourceKey() {
+		return nil, fmt.Errorf("checkpoint %s is for the copy of %s", cmd.checkpoint, c.Source)
+	}
+	return &c, nil
+}
+
+// saveCheckpoint saves the start of the next window of a measurement to the
+// checkpoint file.
+func (cmd *Command) saveCheckpoint(measurement string, t time.Time) error {
+	if cmd.checkpoint == "" {
+		return nil
+	}
+	b, err := json.Marshal(checkpoint{Source: cmd.sourceKey(), Measurement: measurement, Time: t.UnixNano()})
+	if err != nil {
+		return err
+	}
+
+	// Replace the checkpoint at once, so it's never partially written.
+	tmp := cmd.checkpoint + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cmd.checkpoint)
+}
+
+// printUsage prints the usage message to STDERR.
+func (cmd *Command) printUsage() {
+	fmt.Fprintf(cmd.Stderr, `Copies the points of measurements from one server to another.
+
+Usage: influx copy -src <url> -dest <url> -database <name> [options]
+
+The points are queried with chunked responses, window by window, and written
+in batches, so that the copy doesn't have to fit in memory.
+
+Options:
+    -src <url>
+            Server to copy from, such as http://localhost:8086.
+    -src-username <name>, -src-password <password>
+            Credentials of the server to copy from.
+    -dest <url>
+            Server to copy to.
+    -dest-username <name>, -dest-password <password>
+            Credentials of the server to copy to.
+    -unsafeSsl
+            Don't verify the certificates of the servers.
+    -database <name>
+            Database to copy from.
+    -retention-policy <name>
+            Retention policy to copy from.  Defaults to the default one.
+    -dest-database <name>
+            Database to copy to.  Defaults to -database.
+    -dest-retention-policy <name>
+            Retention policy to copy to.  Defaults to -retention-policy.
+    -measurement <name,...>
+            Measurements to copy.  Defaults to all the measurements of the database.
+    -start <RFC3339 time>
+            Time to copy from.  Defaults to the first point of each measurement.
+    -end <RFC3339 time>
+            Time to copy until, excluded.  Defaults to now.
+    -window <duration>
+            Time range queried at once.  Defaults to %s.
+    -chunk-size <rows>
+            Rows of each chunk of the responses.  Defaults to %d.
+    -batch-size <points>
+            Points written at once.  Defaults to %d.
+    -pps <points>
+            Points written per second at most.  Defaults to 0, without limit.
+    -retries <n>
+            Retries of a batch on a network or server error.  Defaults to %d.
+    -retry-interval <duration>
+            Time before the first retry of a batch, doubling on every retry.  Defaults to %s.
+    -checkpoint <path>
+            File the copy saves its progress to after each window.  If the copy stops on an
+            error, running it again with the same checkpoint resumes where it stopped.
+`, DefaultWindow, DefaultChunkSize, DefaultBatchSize, DefaultRetries, DefaultRetryInterval)
+}
@@ -0,0 +1,158 @@
+package copier
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hour is the time of the first point of the source, 2024-01-01T00:00:00Z.
+var hour = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newSource returns a server with a point of cpu every 10 minutes for two
+// hours, queried in chunks of a point.
+func newSource(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		q := r.URL.Query().Get("q")
+		switch {
+		case q == "SHOW MEASUREMENTS ON db0":
+			io.WriteString(w, `{"results":[{"series":[{"name":"measurements","columns":["name"],"values":[["cpu"]]}]}]}`)
+		case q == "SHOW FIELD KEYS ON db0 FROM cpu":
+			io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["count","integer"],["value","float"]]}]}]}`)
+		case q == `SELECT * FROM "db0"..cpu ORDER BY time ASC LIMIT 1`:
+			fmt.Fprintf(w, `{"results":[{"series":[{"name":"cpu","columns":["time","count","host","value"],"values":[[%d,1,"server01",2]]}]}]}`, hour.UnixNano())
+		case strings.HasPrefix(q, `SELECT * FROM "db0"..cpu WHERE time >= `):
+			var start, end int64
+			if _, err := fmt.Sscanf(q, `SELECT * FROM "db0"..cpu WHERE time >= %d AND time < %d GROUP BY *`, &start, &end); err != nil {
+				t.Errorf("unexpected query: %s", q)
+			}
+			for ts := hour; ts.Before(hour.Add(2 * time.Hour)); ts = ts.Add(10 * time.Minute) {
+				if n := ts.UnixNano(); n >= start && n < end {
+					fmt.Fprintf(w, `{"results":[{"series":[{"name":"cpu","tags":{"host":"server01"},"columns":["time","count","value"],"values":[[%d,1,2]]}],"partial":true}]}`+"\n", n)
+				}
+			}
+			io.WriteString(w, `{"results":[{}]}`+"\n")
+		default:
+			t.Errorf("unexpected query: %s", q)
+			io.WriteString(w, `{"results":[{}]}`)
+		}
+	}))
+}
+
+// destination records the lines written to it. The writes fail with the
+// status code fail returns for them, unless it's zero.
+type destination struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	writes int
+	lines  []string
+	fail   func(writes int) int
+}
+
+func newDestination() *destination {
+	d := &destination{fail: func(int) int { return 0 }}
+	d.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.writes++
+		if code := d.fail(d.writes); code != 0 {
+			http.Error(w, `{"error":"write failed"}`, code)
+			return
+		}
+		d.lines = append(d.lines, strings.Split(string(body), "\n")...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return d
+}
+
+func newTestCommand() *Command {
+	cmd := NewCommand()
+	cmd.Stdout = io.Discard
+	cmd.Stderr = io.Discard
+	return cmd
+}
+
+func TestCommand_Run(t *testing.T) {
+	src := newSource(t)
+	defer src.Close()
+	dest := newDestination()
+	defer dest.Close()
+
+	var stdout bytes.Buffer
+	cmd := newTestCommand()
+	cmd.Stdout = &stdout
+	if err := cmd.Run("-src", src.URL, "-dest", dest.URL, "-database", "db0", "-end", "2024-01-01T02:00:00Z", "-window", "1h", "-batch-size", "4"); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(dest.lines), 12; got != exp {
+		t.Fatalf("unexpected lines: got=%d exp=%d", got, exp)
+	}
+	if got, exp := dest.lines[0], "cpu,host=server01 count=1i,value=2 "+strconv.FormatInt(hour.UnixNano(), 10); got != exp {
+		t.Errorf("unexpected line: got=%s exp=%s", got, exp)
+	}
+	// Each of the two windows is written in two batches, of 4 and 2 points.
+	if got, exp := dest.writes, 4; got != exp {
+		t.Errorf("unexpected writes: got=%d exp=%d", got, exp)
+	}
+	if !strings.Contains(stdout.String(), "Copied 12 points") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
+
+func TestCommand_Run_Resume(t *testing.T) {
+	src := newSource(t)
+	defer src.Close()
+	dest := newDestination()
+	defer dest.Close()
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint")
+	args := []string{"-src", src.URL, "-dest", dest.URL, "-database", "db0", "-measurement", "cpu",
+		"-start", "2024-01-01T00:00:00Z", "-end", "2024-01-01T02:00:00Z", "-window", "1h",
+		"-retries", "1", "-retry-interval", "1ms", "-checkpoint", checkpoint}
+
+	// The destination goes down after the first window.
+	dest.fail = func(writes int) int {
+		if writes > 1 {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	}
+	if err := newTestCommand().Run(args...); err == nil || !strings.Contains(err.Error(), "run it again with -checkpoint") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Fatalf("checkpoint not saved: %s", err)
+	}
+
+	// Resume once it's back: only the second window is copied.
+	dest.mu.Lock()
+	dest.fail = func(int) int { return 0 }
+	dest.mu.Unlock()
+	if err := newTestCommand().Run(args...); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(dest.lines), 12; got != exp {
+		t.Fatalf("unexpected lines: got=%d exp=%d", got, exp)
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed: %v", err)
+	}
+}
@@ -0,0 +1,413 @@
+// Package lint implements the lint subcommand of the influx command, which
+// validates and formats files of line protocol.
+package lint
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/client"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxql"
+)
+
+// The rules of the problems.
+const (
+	RuleSyntax         = "syntax"          // the line doesn't parse, such as bad escaping or duplicate tags
+	RuleTypeConflict   = "type-conflict"   // a field has different types in the files
+	RuleSchemaConflict = "schema-conflict" // a field has another type on the server
+	RulePrecision      = "precision"       // a timestamp looks like it's in another precision
+)
+
+// The severities of the problems.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is a problem of a line of a file.
+type Problem struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// String returns the problem as path:line: severity: message (rule).
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s (%s)", p.File, p.Line, p.Severity, p.Message, p.Rule)
+}
+
+// Command represents the program execution for "influx lint".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	precision string
+	format    string
+	write     bool
+
+	host      string
+	username  string
+	password  string
+	database  string
+	unsafeSSL bool
+
+	// fields are the types of the fields of the files by measurement, and
+	// the line they were first seen on.
+	fields map[string]map[string]fieldType
+	// schema are the types of the fields of the server by measurement.
+	schema map[string]map[string]string
+
+	errors, warnings int
+}
+
+// fieldType is the type of a field, where it was first seen.
+type fieldType struct {
+	typ  string
+	file string
+	line int
+}
+
+// NewCommand returns a new instance of Command with default settings.
+func NewCommand() *Command {
+	return &Command{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+// Run executes the program.
+func (cmd *Command) Run(args ...string) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(cmd.Stderr)
+	fs.Usage = cmd.printUsage
+	fs.StringVar(&cmd.precision, "precision", "n", "")
+	fs.StringVar(&cmd.format, "format", "text", "")
+	fs.BoolVar(&cmd.write, "w", false, "")
+	fs.StringVar(&cmd.host, "host", "", "")
+	fs.StringVar(&cmd.username, "username", "", "")
+	fs.StringVar(&cmd.password, "password", "", "")
+	fs.StringVar(&cmd.database, "database", "", "")
+	fs.BoolVar(&cmd.unsafeSSL, "unsafeSsl", false, "")
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	paths := fs.Args()
+	switch {
+	case len(paths) == 0:
+		cmd.printUsage()
+		return errors.New("no files to lint")
+	case cmd.format != "text" && cmd.format != "json":
+		return fmt.Errorf("unknown format %q, expected text or json", cmd.format)
+	case (cmd.host == "") != (cmd.database == ""):
+		return errors.New("-host and -database must be set together")
+	default:
+	}
+	switch cmd.precision {
+	case "ns":
+		cmd.precision = "n"
+	case "us":
+		cmd.precision = "u"
+	case "n", "u", "ms", "s", "m", "h":
+	default:
+		return fmt.Errorf("unknown precision %q, expected n, u, ms, s, m or h", cmd.precision)
+	}
+
+	if cmd.database != "" {
+		if err := cmd.loadSchema(); err != nil {
+			return fmt.Errorf("unable to load the schema of %s: %s", cmd.database, err)
+		}
+	}
+
+	cmd.fields = make(map[string]map[string]fieldType)
+	for _, path := range paths {
+		if err := cmd.lintFile(path); err != nil {
+			return err
+		}
+	}
+	if cmd.errors > 0 {
+		return fmt.Errorf("%d errors, %d warnings", cmd.errors, cmd.warnings)
+	}
+	return nil
+}
+
+// lintFile lints a file, or STDIN if path is "-", and rewrites it with the
+// normalized lines with -w.
+func (cmd *Command) lintFile(path string) error {
+	var buf []byte
+	var err error
+	if path == "-" {
+		if cmd.write {
+			return errors.New("-w can't rewrite STDIN")
+		}
+		buf, err = io.ReadAll(cmd.Stdin)
+	} else {
+		buf, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The points without a timestamp keep none when they are normalized.
+	points, lines, err := models.ParsePointsWithLines(buf, time.Time{}, cmd.precision)
+	var perr *models.ParseError
+	if errors.As(err, &perr) {
+		for _, l := range perr.Lines {
+			cmd.report(Problem{File: path, Line: l.Line, Severity: SeverityError, Rule: RuleSyntax, Message: l.Err.Error()})
+		}
+	} else if err != nil {
+		return err
+	}
+
+	normalized := make(map[int]string, len(points))
+	for i, pt := range points {
+		if s := cmd.lintPoint(path, lines[i], pt); s != "" {
+			normalized[lines[i]] = s
+		}
+	}
+
+	if cmd.write {
+		return rewrite(path, buf, normalized)
+	}
+	return nil
+}
+
+// lintPoint checks the types of the fields and the timestamp of a point, and
+// returns it normalized, or an empty string if it can't be.
+func (cmd *Command) lintPoint(path string, line int, pt models.Point) string {
+	name := string(pt.Name())
+	seen := cmd.fields[name]
+	if seen == nil {
+		seen = make(map[string]fieldType)
+		cmd.fields[name] = seen
+	}
+
+	iter := pt.FieldIterator()
+	for iter.Next() {
+		key := string(iter.FieldKey())
+		typ := fieldTypeName(iter.Type())
+		if t, ok := seen[key]; !ok {
+			seen[key] = fieldType{typ: typ, file: path, line: line}
+		} else if t.typ != typ {
+			cmd.report(Problem{File: path, Line: line, Severity: SeverityError, Rule: RuleTypeConflict,
+				Message: fmt.Sprintf("field %q of %q is %s, but %s at %s:%d", key, name, typ, t.typ, t.file, t.line)})
+		}
+		if t, ok := cmd.schema[name][key]; ok && t != typ {
+			cmd.report(Problem{File: path, Line: line, Severity: SeverityError, Rule: RuleSchemaConflict,
+				Message: fmt.Sprintf("field %q of %q is %s, but %s in database %q", key, name, typ, t, cmd.database)})
+		}
+	}
+
+	if !pt.Time().IsZero() {
+		if guess := guessPrecision(pt.UnixNano()/models.GetPrecisionMultiplier(cmd.precision), cmd.precision); guess != "" {
+			cmd.report(Problem{File: path, Line: line, Severity: SeverityWarning, Rule: RulePrecision,
+				Message: fmt.Sprintf("timestamp is %s in precision %s, it looks like a timestamp in precision %s",
+					pt.Time().UTC().Format(time.RFC3339Nano), cmd.precision, guess)})
+		}
+	}
+
+	fields, err := pt.Fields()
+	if err != nil {
+		return ""
+	}
+	np, err := models.NewPoint(name, pt.Tags(), fields, pt.Time())
+	if err != nil {
+		return ""
+	}
+	return np.PrecisionString(cmd.precision)
+}
+
+// plausibleTimes are the times a timestamp is expected in.
+var plausibleTimes = [2]time.Time{time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), time.Now().AddDate(10, 0, 0)}
+
+// guessPrecision returns the precision a timestamp looks like it's in if it's
+// implausible in its precision, or an empty string.
+func guessPrecision(ts int64, precision string) string {
+	plausible := func(precision string) bool {
+		m := models.GetPrecisionMultiplier(precision)
+		if ts > models.MaxNanoTime/m || ts < models.MinNanoTime/m {
+			return false
+		}
+		t := time.Unix(0, ts*m)
+		return t.After(plausibleTimes[0]) && t.Before(plausibleTimes[1])
+	}
+	if plausible(precision) {
+		return ""
+	}
+	for _, p := range []string{"n", "u", "ms", "s"} {
+		if p != precision && plausible(p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// fieldTypeName returns the name of a field type in SHOW FIELD KEYS.
+func fieldTypeName(t models.FieldType) string {
+	switch t {
+	case models.Integer:
+		return "integer"
+	case models.Float:
+		return "float"
+	case models.Boolean:
+		return "boolean"
+	case models.String:
+		return "string"
+	case models.Unsigned:
+		return "unsigned"
+	default:
+		return "unknown"
+	}
+}
+
+// report writes a problem.
+func (cmd *Command) report(p Problem) {
+	if p.Severity == SeverityError {
+		cmd.errors++
+	} else {
+		cmd.warnings++
+	}
+	if cmd.format == "json" {
+		b, _ := json.Marshal(p)
+		fmt.Fprintln(cmd.Stdout, string(b))
+		return
+	}
+	fmt.Fprintln(cmd.Stdout, p.String())
+}
+
+// rewrite rewrites a file with the normalized lines of its points, by the
+// number of their first line. The other lines are kept as is.
+func rewrite(path string, buf []byte, normalized map[int]string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	w := bufio.NewWriter(f)
+	lines := strings.SplitAfter(string(buf), "\n")
+	for i := 0; i < len(lines); i++ {
+		s, ok := normalized[i+1]
+		if !ok {
+			w.WriteString(lines[i])
+			continue
+		}
+		// A string field may span several lines.
+		i += strings.Count(s, "\n")
+		w.WriteString(s)
+		if strings.HasSuffix(lines[i], "\n") {
+			w.WriteString("\n")
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadSchema loads the types of the fields of the database of the server.
+func (cmd *Command) loadSchema() error {
+	var u url.URL
+	if strings.Contains(cmd.host, "://") {
+		p, err := url.Parse(cmd.host)
+		if err != nil {
+			return err
+		}
+		u = *p
+	} else {
+		p, err := client.ParseConnectionString(cmd.host, false)
+		if err != nil {
+			return err
+		}
+		u = p
+	}
+	c, err := client.NewClient(client.Config{
+		URL:       u,
+		Username:  cmd.username,
+		Password:  cmd.password,
+		UserAgent: "InfluxDBLint",
+		UnsafeSsl: cmd.unsafeSSL,
+		Proxy:     http.ProxyFromEnvironment,
+	})
+	if err != nil {
+		return err
+	}
+
+	response, err := c.Query(client.Query{Command: "SHOW FIELD KEYS ON " + influxql.QuoteIdent(cmd.database), Database: cmd.database})
+	if err != nil {
+		return err
+	} else if err := response.Error(); err != nil {
+		return err
+	}
+	cmd.schema = make(map[string]map[string]string)
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			fields := make(map[string]string, len(row.Values))
+			for _, v := range row.Values {
+				if len(v) > 1 {
+					fields[fmt.Sprint(v[0])] = fmt.Sprint(v[1])
+				}
+			}
+			cmd.schema[row.Name] = fields
+		}
+	}
+	return nil
+}
+
+// printUsage prints the usage message to STDERR.
+func (cmd *Command) printUsage() {
+	fmt.Fprintln(cmd.Stderr, `Validates and formats files of line protocol.
+
+Usage: influx lint [options] <path>...
+
+The problems are written as path:line: severity: message (rule), and the
+command fails if there are errors.  The rules are:
+
+    syntax           the line doesn't parse, such as bad escaping or duplicate tags
+    type-conflict    a field has different types in the files
+    schema-conflict  a field has another type in the database of -host
+    precision        a timestamp looks like it's in another precision (warning)
+
+Options:
+    -precision <n|u|ms|s|m|h>
+            Precision of the timestamps.  Defaults to n.
+    -format <text|json>
+            Format of the problems: text, or json for a JSON object per problem.
+    -w
+            Rewrite the files with the lines normalized: the tags sorted, the
+            fields formatted and the timestamps in -precision.  The lines
+            with errors are kept as is.
+    -host <url>
+            Server to check the types of the fields against, with -database.
+    -username <name>, -password <password>
+            Credentials of the server.
+    -database <name>
+            Database to check the types of the fields against.
+    -unsafeSsl
+            Don't verify the certificate of the server.`)
+}
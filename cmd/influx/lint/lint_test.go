@@ -0,0 +1,156 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// run runs the command with args and returns its output.
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := NewCommand()
+	cmd.Stdin = strings.NewReader("")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run(args...)
+	return stdout.String(), err
+}
+
+// writeFile writes a file to a temporary directory and returns its path.
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.lp")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommand_Run(t *testing.T) {
+	path := writeFile(t, `# comment
+cpu,host=a value=1 1704067200000000000
+cpu,host=a,host=b value=1 1704067200000000000
+cpu,host=a value="one" 1704067200000000000
+cpu,host=a value=1 1704067200
+`)
+	out, err := run(t, path)
+	if err == nil || err.Error() != "2 errors, 1 warnings" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("unexpected problems:\n%s", out)
+	}
+	for i, want := range []string{
+		path + ":3: error: ",
+		path + `:4: error: field "value" of "cpu" is string, but float at ` + path + ":2 (type-conflict)",
+		path + ":5: warning: timestamp is 1970-01-01T00:00:01.7040672Z in precision n, it looks like a timestamp in precision s (precision)",
+	} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("unexpected problem %d:\ngot  %s\nwant %s", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[0], "duplicate tags") || !strings.HasSuffix(lines[0], "(syntax)") {
+		t.Errorf("unexpected syntax problem: %s", lines[0])
+	}
+}
+
+func TestCommand_Run_Valid(t *testing.T) {
+	path := writeFile(t, "cpu,host=a value=1 1704067200\nmem free=2i\n")
+	if out, err := run(t, "-precision", "s", path); err != nil || out != "" {
+		t.Fatalf("unexpected problems: %v\n%s", err, out)
+	}
+}
+
+func TestCommand_Run_JSON(t *testing.T) {
+	path := writeFile(t, "cpu value=1\ncpu value=true\n")
+	out, err := run(t, "-format", "json", path)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var p Problem
+	if err := json.Unmarshal([]byte(out), &p); err != nil {
+		t.Fatalf("invalid json %q: %s", out, err)
+	}
+	if p.File != path || p.Line != 2 || p.Severity != SeverityError || p.Rule != RuleTypeConflict {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+}
+
+func TestCommand_Run_Schema(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("q"); q != "SHOW FIELD KEYS ON db0" {
+			t.Errorf("unexpected query: %s", q)
+		}
+		io.WriteString(w, `{"results":[{"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["value","integer"]]}]}]}`)
+	}))
+	defer s.Close()
+
+	path := writeFile(t, "cpu value=1i\ncpu value=1.5\nmem value=1.5\n")
+	out, err := run(t, "-host", s.URL, "-database", "db0", path)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := path + `:2: error: field "value" of "cpu" is float, but integer in database "db0" (schema-conflict)`
+	if !strings.Contains(out, want) {
+		t.Fatalf("unexpected problems:\n%s\nwant %s", out, want)
+	}
+}
+
+func TestCommand_Run_Write(t *testing.T) {
+	path := writeFile(t, `# comment
+cpu,zone=b,host=a   value=1.0,ok=t 1704067200
+
+cpu,host=a,host=b value=1
+msg text="two
+lines" 1704067201
+mem free=2i
+`)
+	if _, err := run(t, "-precision", "s", "-w", path); err == nil || err.Error() != "1 errors, 0 warnings" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# comment
+cpu,host=a,zone=b ok=true,value=1 1704067200
+
+cpu,host=a,host=b value=1
+msg text="two
+lines" 1704067201
+mem free=2i
+`
+	if string(buf) != want {
+		t.Fatalf("unexpected file:\n%s\nwant:\n%s", buf, want)
+	}
+}
+
+func TestGuessPrecision(t *testing.T) {
+	for _, tt := range []struct {
+		ts        int64
+		precision string
+		want      string
+	}{
+		{ts: 1704067200000000000, precision: "n", want: ""},
+		{ts: 1704067200, precision: "n", want: "s"},
+		{ts: 1704067200000, precision: "n", want: "ms"},
+		{ts: 1704067200000000, precision: "n", want: "u"},
+		{ts: 1704067200000, precision: "s", want: "ms"},
+		{ts: 1704067200000000000, precision: "s", want: "n"},
+		{ts: 42, precision: "s", want: ""},
+	} {
+		if got := guessPrecision(tt.ts, tt.precision); got != tt.want {
+			t.Errorf("guessPrecision(%d, %s) = %q, want %q", tt.ts, tt.precision, got, tt.want)
+		}
+	}
+}
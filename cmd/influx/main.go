@@ -9,6 +9,9 @@ import (
 
 	"github.com/influxdata/influxdb/client"
 	"github.com/influxdata/influxdb/cmd/influx/cli"
+	"github.com/influxdata/influxdb/cmd/influx/copier"
+	"github.com/influxdata/influxdb/cmd/influx/lint"
+	v8 "github.com/influxdata/influxdb/importer/v8"
 )
 
 // These variables are populated via the Go linker.
@@ -36,6 +39,24 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		var run func(args ...string) error
+		switch os.Args[1] {
+		case "copy":
+			run = copier.NewCommand().Run
+		case "lint":
+			run = lint.NewCommand().Run
+		default:
+		}
+		if run != nil {
+			if err := run(os.Args[2:]...); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	c := cli.New(version)
 
 	fs := flag.NewFlagSet("InfluxDB shell version "+version, flag.ExitOnError)
@@ -49,21 +70,39 @@ func main() {
 	fs.Var(&c.Type, "type", "query language for executing commands or invoking the REPL: influxql, flux")
 	fs.BoolVar(&c.Ssl, "ssl", false, "Use https for connecting to cluster.")
 	fs.BoolVar(&c.ClientConfig.UnsafeSsl, "unsafeSsl", false, "Set this when connecting to the cluster using https and not use SSL verification.")
-	fs.StringVar(&c.Format, "format", defaultFormat, "Format specifies the format of the server responses:  json, csv, or column.")
+	fs.StringVar(&c.CACert, "ca-cert", "", "Path of the CA certificate to verify the server with.")
+	fs.StringVar(&c.ClientCert, "cert", "", "Path of the client certificate to authenticate with.")
+	fs.StringVar(&c.ClientKey, "key", "", "Path of the key of the client certificate.")
+	fs.StringVar(&c.Profile, "profile", "", "Connection profile of the config file to use.")
+	configPath := fs.String("config", cli.DefaultConfigPath(), "Path of the config file of the connection profiles.")
+	fs.StringVar(&c.Format, "format", defaultFormat, "Format specifies the format of the server responses:  json, csv, column, table, ndjson or parquet.")
 	fs.StringVar(&c.ClientConfig.Precision, "precision", defaultPrecision, "Precision specifies the format of the timestamp:  rfc3339,h,m,s,ms,u or ns.")
 	fs.StringVar(&c.ClientConfig.WriteConsistency, "consistency", "all", "Set write consistency level: any, one, quorum, or all.")
 	fs.BoolVar(&c.Pretty, "pretty", false, "Turns on pretty print for the json format.")
 	fs.IntVar(&c.NodeID, "node", 0, "Specify the node that data should be retrieved from (enterprise only).")
 	fs.StringVar(&c.Execute, "execute", c.Execute, "Execute command and quit.")
+	fs.StringVar(&c.Output, "output", "", "Export the results of the queries of -execute or -script to the file: .lp, .csv or .ndjson, optionally compressed with .gz.")
+	fs.StringVar(&c.Script, "script", "", "Execute the script at the path, or read from STDIN if it's -, and quit.")
+	fs.Var(&c.ScriptVars, "var", "Variable of the script as name=value. It may be repeated.")
+	fs.Var(&c.Params, "params", "JSON object of the values bound to $param placeholders in queries.")
 	fs.BoolVar(&c.ShowVersion, "version", false, "Displays the InfluxDB version.")
 	fs.BoolVar(&c.Import, "import", false, "Import a previous database.")
 	fs.IntVar(&c.ImporterConfig.PPS, "pps", defaultPPS, "How many points per second the import will allow.  By default it is zero and will not throttle importing.")
 	fs.StringVar(&c.ImporterConfig.Path, "path", "", "path to the file to import")
 	fs.BoolVar(&c.ImporterConfig.Compressed, "compressed", false, "set to true if the import file is compressed")
+	fs.IntVar(&c.ImporterConfig.Workers, "workers", v8.DefaultWorkers, "How many batches the import writes in parallel.")
+	fs.IntVar(&c.ImporterConfig.BatchSize, "batch-size", v8.DefaultBatchSize, "How many points the import writes at once.")
+	fs.IntVar(&c.ImporterConfig.Retries, "retries", v8.DefaultRetries, "How many times the import retries a batch on a network or server error.")
+	fs.DurationVar(&c.ImporterConfig.RetryInterval, "retry-interval", v8.DefaultRetryInterval, "How long the import waits before the first retry of a batch. It doubles on every retry.")
+	fs.StringVar(&c.ImporterConfig.Checkpoint, "checkpoint", "", "path to the file the import saves its progress to and resumes from")
 
 	// Define our own custom usage to print
 	fs.Usage = func() {
 		fmt.Println(`Usage of influx:
+  copy
+			Copy the points of measurements from one server to another.  Run 'influx copy -h' for its options.
+  lint
+			Validate and format files of line protocol.  Run 'influx lint -h' for its options.
   -version
 			Display the version and exit.
   -path-prefix 'url path'
@@ -84,12 +123,37 @@ func main() {
 			Use https for requests.
   -unsafeSsl
 			Set this when connecting to the cluster using https and not use SSL verification.
+  -ca-cert 'path'
+			CA certificate to verify the server with.  Implies -ssl.
+  -cert 'path'
+			Client certificate to authenticate with, with -key.  Implies -ssl.
+  -key 'path'
+			Key of the client certificate.
+  -profile 'name'
+			Connection profile of the config file to use.  Defaults to the profile named by the
+			"default" key of the config file, if any.  The flags take precedence over the profile.
+  -config 'path'
+			Config file of the connection profiles.  Defaults to ~/.influx/config.
   -execute 'command'
 			Execute command and quit.
+  -output 'path'
+			Export the results of the queries of -execute or -script to the file, streaming them
+			chunk by chunk.  The format is given by its extension:  .lp for line protocol, .csv or
+			.ndjson, compressed with gzip if followed by .gz.
+  -script 'path'
+			Execute the script at the path, or read from STDIN if it's -, and quit.  A script runs
+			the commands of its lines with the directives LET, FOR ... IN, IF, ELSE and END, and
+			interpolates the variables as ${name}.
+  -var 'name=value'
+			Variable of the script.  It may be repeated.
+  -params 'json object'
+			Values bound to the $param placeholders in queries, such as '{"host": "server01"}'.
   -type 'influxql|flux'
 			Type specifies the query language for executing commands or when invoking the REPL.
-  -format 'json|csv|column'
-			Format specifies the format of the server responses:  json, csv, or column.
+  -format 'json|csv|column|table|ndjson|parquet'
+			Format specifies the format of the server responses:  json, csv, column, table, ndjson or parquet.
+			The table format is paged if it doesn't fit on the terminal.  The parquet format must be
+			redirected to a file.
   -precision 'rfc3339|h|m|s|ms|u|ns'
 			Precision specifies the format of the timestamp:  rfc3339, h, m, s, ms, u or ns.
   -consistency 'any|one|quorum|all'
@@ -104,12 +168,63 @@ func main() {
 			Path to file to import
   -compressed
 			Set to true if the import file is compressed
+  -workers
+			How many batches the import writes in parallel.  Defaults to 1.
+  -batch-size
+			How many points the import writes at once.  Defaults to 5000.
+  -retries
+			How many times the import retries a batch on a network or server error.  Defaults to 5.
+  -retry-interval
+			How long the import waits before the first retry of a batch, doubling on every retry.  Defaults to 1s.
+  -checkpoint
+			Path to the file the import saves its progress to.  If the import stops on an error,
+			running it again with the same checkpoint resumes where it stopped.
 
 Examples:
 
     # Use influx in a non-interactive mode to query the database "metrics" and pretty print json:
     $ influx -database 'metrics' -execute 'select * from cpu' -format 'json' -pretty
 
+    # Use bound parameters instead of formatting values into a query:
+    $ influx -database 'metrics' -params '{"host": "server01"}' -execute 'select * from cpu where host = $host'
+
+    # Export a measurement as compressed line protocol:
+    $ influx -database 'metrics' -execute 'select * from cpu' -output 'cpu.lp.gz'
+
+    # Import a large export with 4 parallel writers, resuming where a previous run stopped:
+    $ influx -import -path 'export.gz' -compressed -workers 4 -checkpoint 'export.checkpoint'
+
+    # Copy a day of a measurement to another server, resuming where a previous run stopped:
+    $ influx copy -src 'http://old:8086' -dest 'http://new:8086' -database 'metrics' -measurement 'cpu' \
+        -start '2024-01-01T00:00:00Z' -end '2024-01-02T00:00:00Z' -checkpoint 'cpu.checkpoint'
+
+    # Check files of line protocol in seconds against the schema of a database in CI:
+    $ influx lint -precision s -host 'http://localhost:8086' -database 'metrics' -format json data/*.lp
+
+    # Count the points of each measurement of each database with a script:
+    $ cat count.influx
+    FOR db IN SHOW DATABASES
+      FOR m IN SHOW MEASUREMENTS ON "${db}"
+        SELECT count(*) FROM "${db}".."${m}"
+      END
+    END
+    $ influx -script 'count.influx'
+
+    # Connect with the "prod" profile of ~/.influx/config:
+    $ cat ~/.influx/config
+    default = "local"
+
+    [profiles.local]
+    host = "localhost"
+
+    [profiles.prod]
+    host = "influxdb.example.com"
+    ca-cert = "~/.influx/ca.pem"
+    cert = "~/.influx/client.pem"
+    key = "~/.influx/client-key.pem"
+    database = "metrics"
+    $ influx -profile 'prod'
+
     # Connect to a specific database on startup and set database context:
     $ influx -database 'metrics' -host 'localhost' -port '8086'`)
 	}
@@ -127,6 +242,17 @@ Examples:
 		os.Exit(0)
 	}
 
+	profile, err := cli.LoadProfile(*configPath, c.Profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+	if profile != nil {
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		c.ApplyProfile(profile, func(name string) bool { return set[name] })
+	}
+
 	if err := c.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
@@ -12,7 +12,9 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
@@ -26,6 +28,10 @@ import (
 
 const defaultBatchSize = 10000
 
+// checkpointFile is the name of the file, next to the partial index of a shard,
+// listing the TSM files already indexed so an interrupted run can resume.
+const checkpointFile = ".index.checkpoint"
+
 // Command represents the program execution for "influx_inspect buildtsi".
 type Command struct {
 	Stderr  io.Writer
@@ -33,7 +39,7 @@ type Command struct {
 	Verbose bool
 	Logger  *zap.Logger
 
-	concurrency       int // Number of goroutines to dedicate to shard index building.
+	concurrency       int // Number of goroutines to dedicate to shard index building and to the partitions of each index.
 	databaseFilter    string
 	retentionFilter   string
 	shardFilter       string
@@ -41,6 +47,8 @@ type Command struct {
 	maxLogFileSize    int64
 	maxCacheSize      uint64
 	batchSize         int
+
+	progress *progress
 }
 
 // NewCommand returns a new instance of Command.
@@ -59,7 +67,7 @@ func (cmd *Command) Run(args ...string) error {
 	fs := flag.NewFlagSet("buildtsi", flag.ExitOnError)
 	dataDir := fs.String("datadir", "", "data directory")
 	walDir := fs.String("waldir", "", "WAL directory")
-	fs.IntVar(&cmd.concurrency, "concurrency", runtime.GOMAXPROCS(0), "Number of workers to dedicate to shard index building. Defaults to GOMAXPROCS")
+	fs.IntVar(&cmd.concurrency, "concurrency", runtime.GOMAXPROCS(0), "Number of workers to dedicate to shard index building, and to the partitions of each shard index. Defaults to GOMAXPROCS")
 	fs.StringVar(&cmd.databaseFilter, "database", "", "optional: database name")
 	fs.StringVar(&cmd.retentionFilter, "retention", "", "optional: retention policy")
 	fs.StringVar(&cmd.shardFilter, "shard", "", "optional: shard id")
@@ -107,6 +115,8 @@ func (cmd *Command) run(dataDir, walDir string) error {
 		return err
 	}
 
+	var shards []shard
+	var indexed int
 	for _, fi := range fis {
 		name := fi.Name()
 		if !fi.IsDir() {
@@ -122,9 +132,30 @@ func (cmd *Command) run(dataDir, walDir string) error {
 			continue
 		}
 
-		if err := cmd.processDatabase(name, filepath.Join(dataDir, name), filepath.Join(walDir, name)); err != nil {
+		s, n, err := cmd.collectShards(name, filepath.Join(dataDir, name), filepath.Join(walDir, name))
+		if err != nil {
+			return err
+		}
+		shards, indexed = append(shards, s...), indexed+n
+	}
+	if cmd.compactSeriesFile {
+		return nil
+	}
+
+	fmt.Fprintf(cmd.Stdout, "Building TSI for %d shards (%d already indexed)\n", len(shards), indexed)
+	cmd.progress = newProgress(cmd.Stdout, len(shards))
+
+	// The shards are sorted by database, and each database has its own
+	// series file.
+	for i := 0; i < len(shards); {
+		j := i + 1
+		for j < len(shards) && shards[j].db == shards[i].db {
+			j++
+		}
+		if err := cmd.processDatabase(shards[i].db, filepath.Join(dataDir, shards[i].db), shards[i:j]); err != nil {
 			return err
 		}
+		i = j
 	}
 
 	return nil
@@ -248,21 +279,24 @@ func (cmd *Command) seriesFilePartitionPaths(path string) ([]string, error) {
 	return paths, nil
 }
 
-func (cmd *Command) processDatabase(dbName, dataDir, walDir string) error {
-	cmd.Logger.Info("Rebuilding database", zap.String("name", dbName))
-
-	sfile := tsdb.NewSeriesFile(filepath.Join(dataDir, tsdb.SeriesFileDirectory))
-	sfile.Logger = cmd.Logger
-	if err := sfile.Open(); err != nil {
-		return err
-	}
-	defer sfile.Close()
+// shard is a shard to index.
+type shard struct {
+	db, rp   string
+	id       uint64
+	dataPath string
+	walPath  string
+}
 
+// collectShards returns the shards of a database without a TSI index, and the
+// number of shards already indexed.
+func (cmd *Command) collectShards(dbName, dataDir, walDir string) ([]shard, int, error) {
 	fis, err := os.ReadDir(dataDir)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
+	var shards []shard
+	var indexed int
 	for _, fi := range fis {
 		rpName := fi.Name()
 		if !fi.IsDir() {
@@ -273,43 +307,48 @@ func (cmd *Command) processDatabase(dbName, dataDir, walDir string) error {
 			continue
 		}
 
-		if err := cmd.processRetentionPolicy(sfile, dbName, rpName, filepath.Join(dataDir, rpName), filepath.Join(walDir, rpName)); err != nil {
-			return err
+		sfis, err := os.ReadDir(filepath.Join(dataDir, rpName))
+		if err != nil {
+			return nil, 0, err
 		}
-	}
-
-	return nil
-}
-
-func (cmd *Command) processRetentionPolicy(sfile *tsdb.SeriesFile, dbName, rpName, dataDir, walDir string) error {
-	cmd.Logger.Info("Rebuilding retention policy", logger.Database(dbName), logger.RetentionPolicy(rpName))
-
-	fis, err := os.ReadDir(dataDir)
-	if err != nil {
-		return err
-	}
-
-	type shard struct {
-		ID   uint64
-		Path string
-	}
+		for _, sfi := range sfis {
+			if !sfi.IsDir() {
+				continue
+			} else if cmd.shardFilter != "" && sfi.Name() != cmd.shardFilter {
+				continue
+			}
 
-	var shards []shard
+			shardID, err := strconv.ParseUint(sfi.Name(), 10, 64)
+			if err != nil {
+				continue
+			}
 
-	for _, fi := range fis {
-		if !fi.IsDir() {
-			continue
-		} else if cmd.shardFilter != "" && fi.Name() != cmd.shardFilter {
-			continue
+			sh := shard{
+				db:       dbName,
+				rp:       rpName,
+				id:       shardID,
+				dataPath: filepath.Join(dataDir, rpName, sfi.Name()),
+				walPath:  filepath.Join(walDir, rpName, sfi.Name()),
+			}
+			if _, err := os.Stat(filepath.Join(sh.dataPath, "index")); !os.IsNotExist(err) {
+				indexed++
+				continue
+			}
+			shards = append(shards, sh)
 		}
+	}
+	return shards, indexed, nil
+}
 
-		shardID, err := strconv.ParseUint(fi.Name(), 10, 64)
-		if err != nil {
-			continue
-		}
+func (cmd *Command) processDatabase(dbName, dataDir string, shards []shard) error {
+	cmd.Logger.Info("Rebuilding database", zap.String("name", dbName))
 
-		shards = append(shards, shard{shardID, fi.Name()})
+	sfile := tsdb.NewSeriesFile(filepath.Join(dataDir, tsdb.SeriesFileDirectory))
+	sfile.Logger = cmd.Logger
+	if err := sfile.Open(); err != nil {
+		return err
 	}
+	defer sfile.Close()
 
 	errC := make(chan error, len(shards))
 	var maxi uint32 // index of maximum shard being worked on.
@@ -321,9 +360,14 @@ func (cmd *Command) processRetentionPolicy(sfile *tsdb.SeriesFile, dbName, rpNam
 					return // No more work.
 				}
 
-				id, name := shards[i].ID, shards[i].Path
-				log := cmd.Logger.With(logger.Database(dbName), logger.RetentionPolicy(rpName), logger.Shard(id))
-				errC <- IndexShard(sfile, filepath.Join(dataDir, name), filepath.Join(walDir, name), cmd.maxLogFileSize, cmd.maxCacheSize, cmd.batchSize, log, cmd.Verbose)
+				sh := shards[i]
+				log := cmd.Logger.With(logger.Database(dbName), logger.RetentionPolicy(sh.rp), logger.Shard(sh.id))
+				start := time.Now()
+				err := IndexShard(sfile, sh.dataPath, sh.walPath, cmd.maxLogFileSize, cmd.maxCacheSize, cmd.batchSize, cmd.concurrency, log, cmd.Verbose)
+				if err == nil {
+					cmd.progress.done(sh, time.Since(start))
+				}
+				errC <- err
 			}
 		}()
 	}
@@ -337,7 +381,37 @@ func (cmd *Command) processRetentionPolicy(sfile *tsdb.SeriesFile, dbName, rpNam
 	return nil
 }
 
-func IndexShard(sfile *tsdb.SeriesFile, dataDir, walDir string, maxLogFileSize int64, maxCacheSize uint64, batchSize int, log *zap.Logger, verboseLogging bool) error {
+// progress reports the shards indexed and the estimated time to index the
+// remaining ones.
+type progress struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+	n     int // number of shards indexed
+	total int
+}
+
+func newProgress(w io.Writer, total int) *progress {
+	return &progress{w: w, start: time.Now(), total: total}
+}
+
+// done reports that a shard was indexed in d.
+func (p *progress) done(sh shard, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.n++
+	elapsed := time.Since(p.start)
+	eta := elapsed * time.Duration(p.total-p.n) / time.Duration(p.n)
+	fmt.Fprintf(p.w, "[%d/%d] %s/%s/%d indexed in %s, elapsed %s, ETA %s\n",
+		p.n, p.total, sh.db, sh.rp, sh.id, d.Round(time.Millisecond), elapsed.Round(time.Second), eta.Round(time.Second))
+}
+
+// IndexShard builds the TSI index of a shard from its TSM and WAL files, using
+// up to concurrency goroutines for the partitions of the index. The TSM files
+// indexed are checkpointed so an interrupted run resumes with the partial
+// index instead of restarting.
+func IndexShard(sfile *tsdb.SeriesFile, dataDir, walDir string, maxLogFileSize int64, maxCacheSize uint64, batchSize, concurrency int, log *zap.Logger, verboseLogging bool) error {
 	log.Info("Rebuilding shard")
 
 	// Check if shard already has a TSI index.
@@ -350,30 +424,46 @@ func IndexShard(sfile *tsdb.SeriesFile, dataDir, walDir string, maxLogFileSize i
 
 	log.Info("Opening shard")
 
-	// Remove temporary index files if this is being re-run.
+	// Resume the partial index of a previous run from its checkpoint, or
+	// remove temporary index files if this is being re-run.
 	tmpPath := filepath.Join(dataDir, ".index")
-	log.Info("Cleaning up partial index from previous run, if any")
-	if err := os.RemoveAll(tmpPath); err != nil {
+	checkpointPath := filepath.Join(dataDir, checkpointFile)
+	checkpoint, err := readCheckpoint(checkpointPath)
+	if err != nil {
 		return err
+	} else if _, err := os.Stat(tmpPath); os.IsNotExist(err) {
+		checkpoint = nil // The partial index is gone.
+	}
+	if len(checkpoint) > 0 {
+		log.Info("Resuming partial index from previous run", zap.Int("tsm_files", len(checkpoint)))
+	} else {
+		log.Info("Cleaning up partial index from previous run, if any")
+		if err := os.RemoveAll(tmpPath); err != nil {
+			return err
+		}
 	}
 
 	// Open TSI index in temporary path.
-	tsiIndex := tsi1.NewIndex(sfile, "",
-		tsi1.WithPath(tmpPath),
-		tsi1.WithMaximumLogFileSize(maxLogFileSize),
-		tsi1.DisableFsync(),
-		// Each new series entry in a log file is ~12 bytes so this should
-		// roughly equate to one flush to the file for every batch.
-		tsi1.WithLogFileBufferSize(12*batchSize),
-	)
-
-	tsiIndex.WithLogger(log)
+	newIndex := func() (*tsi1.Index, error) {
+		tsiIndex := tsi1.NewIndex(sfile, "",
+			tsi1.WithPath(tmpPath),
+			tsi1.WithMaximumLogFileSize(maxLogFileSize),
+			tsi1.WithMaximumConcurrency(concurrency),
+			tsi1.DisableFsync(),
+			// Each new series entry in a log file is ~12 bytes so this should
+			// roughly equate to one flush to the file for every batch.
+			tsi1.WithLogFileBufferSize(12*batchSize),
+		)
+		tsiIndex.WithLogger(log)
+		return tsiIndex, tsiIndex.Open()
+	}
 
 	log.Info("Opening tsi index in temporary location", zap.String("path", tmpPath))
-	if err := tsiIndex.Open(); err != nil {
+	tsiIndex, err := newIndex()
+	if err != nil {
 		return err
 	}
-	defer tsiIndex.Close()
+	defer func() { tsiIndex.Close() }()
 
 	// Write out tsm1 files.
 	// Find shard files.
@@ -384,10 +474,25 @@ func IndexShard(sfile *tsdb.SeriesFile, dataDir, walDir string, maxLogFileSize i
 
 	log.Info("Iterating over tsm files")
 	for _, path := range tsmPaths {
+		if checkpoint[filepath.Base(path)] {
+			log.Info("Skipping tsm file indexed by previous run", zap.String("path", path))
+			continue
+		}
 		log.Info("Processing tsm file", zap.String("path", path))
 		if err := IndexTSMFile(tsiIndex, path, batchSize, log, verboseLogging); err != nil {
 			return err
 		}
+
+		// The index doesn't flush its log files, so close it before
+		// checkpointing the file.
+		if err := tsiIndex.Close(); err != nil {
+			return err
+		} else if err := appendCheckpoint(checkpointPath, filepath.Base(path)); err != nil {
+			return err
+		}
+		if tsiIndex, err = newIndex(); err != nil {
+			return err
+		}
 	}
 
 	// Write out wal files.
@@ -460,6 +565,7 @@ func IndexShard(sfile *tsdb.SeriesFile, dataDir, walDir string, maxLogFileSize i
 	compactingIndex := tsi1.NewIndex(sfile, "",
 		tsi1.WithPath(tmpPath),
 		tsi1.WithMaximumLogFileSize(1),
+		tsi1.WithMaximumConcurrency(concurrency),
 	)
 	if err := compactingIndex.Open(); err != nil {
 		return err
@@ -472,11 +578,50 @@ func IndexShard(sfile *tsdb.SeriesFile, dataDir, walDir string, maxLogFileSize i
 		return err
 	}
 
+	// Remove the checkpoint first: a run interrupted before the rename then
+	// rebuilds the index from scratch.
+	if err := os.Remove(checkpointPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
 	// Rename TSI to standard path.
 	log.Info("Moving tsi to permanent location")
 	return os.Rename(tmpPath, indexPath)
 }
 
+// readCheckpoint returns the names of the TSM files in a checkpoint. It
+// returns an empty set if there is no checkpoint.
+func readCheckpoint(path string) (map[string]bool, error) {
+	buf, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, name := range strings.Split(string(buf), "\n") {
+		// A line cut short by an interruption names no file.
+		if name != "" {
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+// appendCheckpoint adds the name of a TSM file to a checkpoint.
+func appendCheckpoint(path, name string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, name); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func IndexTSMFile(index *tsi1.Index, path string, batchSize int, log *zap.Logger, verboseLogging bool) error {
 	f, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,119 @@
+package buildtsi
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+	"github.com/influxdata/influxdb/tsdb/index/tsi1"
+	"go.uber.org/zap"
+)
+
+// writeTSM writes a TSM file with a value for each key.
+func writeTSM(t *testing.T, path string, keys ...string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if err := w.Write([]byte(k), []tsm1.Value{tsm1.NewValue(1, 1.5)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexShard_Resume(t *testing.T) {
+	dir := t.TempDir()
+	shardPath := filepath.Join(dir, "db0", "autogen", "1")
+	if err := os.MkdirAll(shardPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTSM(t, filepath.Join(shardPath, "000000001-000000001.tsm"), "mem,host=a#!~#free")
+	writeTSM(t, filepath.Join(shardPath, "000000002-000000001.tsm"), "cpu,host=a#!~#value")
+
+	// A previous run was interrupted after indexing the first file. Its
+	// partial index is empty, so the series of the first file are only in
+	// the index if the file is indexed again.
+	if err := os.Mkdir(filepath.Join(shardPath, ".index"), 0755); err != nil {
+		t.Fatal(err)
+	} else if err := appendCheckpoint(filepath.Join(shardPath, checkpointFile), "000000001-000000001.tsm"); err != nil {
+		t.Fatal(err)
+	}
+
+	sfile := tsdb.NewSeriesFile(filepath.Join(dir, "db0", tsdb.SeriesFileDirectory))
+	if err := sfile.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer sfile.Close()
+
+	if err := IndexShard(sfile, shardPath, "", tsdb.DefaultMaxIndexLogFileSize, tsdb.DefaultCacheMaxMemorySize, defaultBatchSize, 1, zap.NewNop(), false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(shardPath, checkpointFile)); !os.IsNotExist(err) {
+		t.Fatalf("checkpoint wasn't removed: %v", err)
+	}
+
+	idx := tsi1.NewIndex(sfile, "db0", tsi1.WithPath(filepath.Join(shardPath, "index")))
+	if err := idx.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	for name, exp := range map[string]bool{"cpu": true, "mem": false} {
+		if ok, err := idx.MeasurementExists([]byte(name)); err != nil {
+			t.Fatal(err)
+		} else if ok != exp {
+			t.Errorf("measurement %s exists: got %v, expected %v", name, ok, exp)
+		}
+	}
+}
+
+func TestReadCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), checkpointFile)
+	if names, err := readCheckpoint(path); err != nil || len(names) != 0 {
+		t.Fatalf("got %v, %v for a missing checkpoint", names, err)
+	}
+
+	// The last line was cut short by an interruption.
+	if err := os.WriteFile(path, []byte("000000001-000000001.tsm\n000000002-0000"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	names, err := readCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(names) != 2 || !names["000000001-000000001.tsm"] {
+		t.Fatalf("unexpected checkpoint: %v", names)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgress(&out, 2)
+	p.done(shard{db: "db0", rp: "autogen", id: 1}, 1500*time.Millisecond)
+	p.done(shard{db: "db0", rp: "autogen", id: 2}, time.Second)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if !strings.HasPrefix(lines[0], "[1/2] db0/autogen/1 indexed in 1.5s, elapsed ") || !strings.Contains(lines[0], ", ETA ") {
+		t.Errorf("unexpected first line: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[2/2] db0/autogen/2 indexed in 1s") || !strings.HasSuffix(lines[1], "ETA 0s") {
+		t.Errorf("unexpected last line: %s", lines[1])
+	}
+}
@@ -1,4 +1,5 @@
-// Package deletetsm bulk deletes a measurement from a raw tsm file.
+// Package deletetsm bulk deletes data from raw tsm files by measurement, tag
+// predicate and time range.
 package deletetsm
 
 import (
@@ -7,12 +8,17 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/influxdata/influxdb/cmd/influx_inspect/internal/seriesfilter"
 	"github.com/influxdata/influxdb/models"
 	errors2 "github.com/influxdata/influxdb/pkg/errors"
+	"github.com/influxdata/influxdb/pkg/file"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+	"github.com/influxdata/influxql"
 )
 
 // Command represents the program execution for "influxd deletetsm".
@@ -21,9 +27,12 @@ type Command struct {
 	Stderr io.Writer
 	Stdout io.Writer
 
-	measurement string // measurement to delete
-	sanitize    bool   // remove all keys with non-printable unicode
-	verbose     bool   // verbose logging
+	filter    *seriesfilter.Filter // series to delete, nil without -measurement and -where
+	startTime int64                // start of the time range to delete
+	endTime   int64                // end of the time range to delete
+	sanitize  bool                 // remove all keys with non-printable unicode
+	dryRun    bool                 // report the deletions without rewriting the files
+	verbose   bool                 // verbose logging
 }
 
 // NewCommand returns a new instance of Command.
@@ -36,9 +45,14 @@ func NewCommand() *Command {
 
 // Run executes the command.
 func (cmd *Command) Run(args ...string) (err error) {
+	var measurement, where, start, end string
 	fs := flag.NewFlagSet("deletetsm", flag.ExitOnError)
-	fs.StringVar(&cmd.measurement, "measurement", "", "")
+	fs.StringVar(&measurement, "measurement", "", "")
+	fs.StringVar(&where, "where", "", "")
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
 	fs.BoolVar(&cmd.sanitize, "sanitize", false, "")
+	fs.BoolVar(&cmd.dryRun, "dry-run", false, "")
 	fs.BoolVar(&cmd.verbose, "v", false, "")
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = cmd.printUsage
@@ -54,13 +68,65 @@ func (cmd *Command) Run(args ...string) (err error) {
 		log.SetOutput(io.Discard)
 	}
 
-	// Validate measurement or sanitize flag.
-	if cmd.measurement == "" && !cmd.sanitize {
-		return fmt.Errorf("-measurement or -sanitize flag required")
+	// Validate measurement, where or sanitize flag.
+	if measurement == "" && where == "" && !cmd.sanitize {
+		return fmt.Errorf("-measurement, -where or -sanitize flag required")
 	}
 
-	// Process each TSM file.
+	cmd.startTime, cmd.endTime = math.MinInt64, math.MaxInt64
+	if start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return err
+		}
+		cmd.startTime = t.UnixNano()
+	}
+	if end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return err
+		}
+		cmd.endTime = t.UnixNano()
+	}
+	if cmd.endTime < cmd.startTime {
+		return fmt.Errorf("end time before start time")
+	}
+
+	if measurement != "" || where != "" {
+		var measurements map[string]bool
+		if measurement != "" {
+			measurements = map[string]bool{measurement: true}
+		}
+		var whereExpr influxql.Expr
+		if where != "" {
+			expr, err := seriesfilter.ParseWhere(where)
+			if err != nil {
+				return err
+			}
+			whereExpr = expr
+		}
+		cmd.filter = seriesfilter.New(measurements, whereExpr)
+	}
+
+	// A directory, such as a shard, stands for its TSM files.
+	var paths []string
 	for _, path := range fs.Args() {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		} else if !fi.IsDir() {
+			paths = append(paths, path)
+			continue
+		}
+		tsmPaths, err := filepath.Glob(filepath.Join(path, "*."+tsm1.TSMFileExtension))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, tsmPaths...)
+	}
+
+	// Process each TSM file.
+	for _, path := range paths {
 		log.Printf("processing: %s", path)
 		if err := cmd.process(path); err != nil {
 			return err
@@ -70,6 +136,17 @@ func (cmd *Command) Run(args ...string) (err error) {
 	return nil
 }
 
+// matchSeries returns true if the data of the series is deleted.
+func (cmd *Command) matchSeries(seriesKey []byte) bool {
+	if cmd.sanitize {
+		measurement, tags := models.ParseKeyBytes(seriesKey)
+		if !models.ValidKeyTokens(string(measurement), tags) {
+			return true
+		}
+	}
+	return cmd.filter != nil && cmd.filter.Match(seriesKey)
+}
+
 func (cmd *Command) process(path string) (retErr error) {
 	// Remove previous temporary files.
 	outputPath := path + ".rewriting.tmp"
@@ -87,6 +164,7 @@ func (cmd *Command) process(path string) (retErr error) {
 		return fmt.Errorf("unable to read %s: %w", path, err)
 	}
 	// Nested function to ensure all the deferred close operations happen before final deletion or rename
+	var deleted struct{ blocks, points int }
 	size, err := func() (size uint32, fRetErr error) {
 		// This will close the input file
 		defer errors2.Capture(&retErr, r.Close)()
@@ -123,20 +201,51 @@ func (cmd *Command) process(path string) (retErr error) {
 				return 0, err
 			}
 
-			// Skip block if this is the measurement and time range we are deleting.
+			// Keep the block if this isn't a series or a time range we are deleting.
 			series, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
-			measurement, tags := models.ParseKey(series)
-			if string(measurement) == cmd.measurement || (cmd.sanitize && !models.ValidKeyTokens(measurement, tags)) {
+			if !cmd.matchSeries(series) || maxTime < cmd.startTime || minTime > cmd.endTime {
+				if err := w.WriteBlock(key, minTime, maxTime, block); err != nil {
+					return 0, err
+				}
+				blockWritten = true
+				continue
+			}
+
+			// Skip the block if it is entirely in the time range, otherwise
+			// rewrite it without the values in the time range.
+			if minTime >= cmd.startTime && maxTime <= cmd.endTime {
 				log.Printf("deleting block: %s (%s-%s) sz=%d",
 					key,
 					time.Unix(0, minTime).UTC().Format(time.RFC3339Nano),
 					time.Unix(0, maxTime).UTC().Format(time.RFC3339Nano),
 					len(block),
 				)
+				n, err := tsm1.BlockCount(block)
+				if err != nil {
+					return 0, fmt.Errorf("unable to read block %s in %s: %w", key, path, err)
+				}
+				deleted.blocks++
+				deleted.points += n
 				continue
 			}
 
-			if err := w.WriteBlock(key, minTime, maxTime, block); err != nil {
+			values, err := tsm1.DecodeBlock(block, nil)
+			if err != nil {
+				return 0, fmt.Errorf("unable to decode block %s in %s: %w", key, path, err)
+			}
+			kept := tsm1.Values(values).Exclude(cmd.startTime, cmd.endTime)
+			log.Printf("deleting %d values of block: %s (%s-%s)",
+				len(values)-len(kept),
+				key,
+				time.Unix(0, minTime).UTC().Format(time.RFC3339Nano),
+				time.Unix(0, maxTime).UTC().Format(time.RFC3339Nano),
+			)
+			deleted.points += len(values) - len(kept)
+			if len(kept) == 0 {
+				deleted.blocks++
+				continue
+			}
+			if err := w.Write(key, kept); err != nil {
 				return 0, err
 			}
 			blockWritten = true
@@ -154,9 +263,20 @@ func (cmd *Command) process(path string) (retErr error) {
 		return err
 	}
 
-	if size > 0 {
+	if deleted.points > 0 || deleted.blocks > 0 {
+		verb := "deleted"
+		if cmd.dryRun {
+			verb = "would delete"
+		}
+		fmt.Fprintf(cmd.Stdout, "%s: %s %d points, %d blocks\n", path, verb, deleted.points, deleted.blocks)
+	}
+
+	if cmd.dryRun || (deleted.points == 0 && deleted.blocks == 0) {
+		// Leave the original file untouched.
+		return os.RemoveAll(outputPath)
+	} else if size > 0 {
 		// Replace original file with new file.
-		return os.Rename(outputPath, path)
+		return file.RenameFile(outputPath, path)
 	} else {
 		// Empty TSM file of size == 0, remove it
 		if err = os.RemoveAll(path); err != nil {
@@ -171,14 +291,28 @@ func (cmd *Command) process(path string) (retErr error) {
 }
 
 func (cmd *Command) printUsage() {
-	fmt.Print(`Deletes a measurement from a raw tsm file.
+	fmt.Print(`Deletes data from raw tsm files by measurement, tag predicate and time range.
+
+The files are rewritten in place, so stop influxd or detach the shard first.
+The series stay in the index of the shard: rebuild it with buildtsi to drop them.
 
 Usage: influx_inspect deletetsm [flags] path...
 
+    path
+            A tsm file, or a shard directory to process all of its tsm files.
     -measurement NAME
             The name of the measurement to remove.
+    -where PREDICATE
+            The tag predicate of the series to remove, for example
+            "host = 'a' AND region =~ /^us-/".
+    -start TIME
+            The start time of the data to remove (RFC3339 format).
+    -end TIME
+            The end time of the data to remove (RFC3339 format).
     -sanitize
             Remove all keys with non-printable unicode characters.
+    -dry-run
+            Report the data to remove without rewriting the files.
     -v
             Enable verbose logging.`)
 }
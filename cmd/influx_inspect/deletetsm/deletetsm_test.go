@@ -0,0 +1,122 @@
+package deletetsm_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/deletetsm"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+var t0 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+
+// writeTSM writes a TSM file with the values at t0, t0+1h and t0+2h for each
+// key.
+func writeTSM(t *testing.T, path string, keys ...string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		var values []tsm1.Value
+		for i := int64(0); i < 3; i++ {
+			values = append(values, tsm1.NewValue(t0+i*int64(time.Hour), float64(i)))
+		}
+		if err := w.Write([]byte(k), values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readTSM returns the number of values of each key of a TSM file.
+func readTSM(t *testing.T, path string) map[string]int {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	n := make(map[string]int)
+	for i := 0; i < r.KeyCount(); i++ {
+		key, _ := r.KeyAt(i)
+		values, err := r.ReadAll(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n[string(key)] = len(values)
+	}
+	return n
+}
+
+func TestCommand_Run_Where(t *testing.T) {
+	shard := t.TempDir()
+	first := filepath.Join(shard, "000000001-000000001.tsm")
+	writeTSM(t, first, "cpu,host=a#!~#value", "cpu,host=b#!~#value", "mem,host=a#!~#free")
+	second := filepath.Join(shard, "000000002-000000001.tsm")
+	writeTSM(t, second, "cpu,host=a#!~#value")
+
+	run := func(args ...string) string {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := deletetsm.NewCommand()
+		cmd.Stdout = &stdout
+		if err := cmd.Run(args...); err != nil {
+			t.Fatal(err)
+		}
+		return stdout.String()
+	}
+	args := []string{"-measurement", "cpu", "-where", "host = 'a'", "-start", "2020-01-01T01:00:00Z"}
+
+	// A dry run leaves the files untouched.
+	out := run(append(append([]string{"-dry-run"}, args...), shard)...)
+	if !strings.Contains(out, first+": would delete 2 points, 0 blocks") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	if n := readTSM(t, first); n["cpu,host=a#!~#value"] != 3 {
+		t.Fatalf("dry run rewrote the file: %v", n)
+	}
+
+	out = run(append(args, shard)...)
+	if !strings.Contains(out, second+": deleted 2 points, 0 blocks") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	exp := map[string]int{"cpu,host=a#!~#value": 1, "cpu,host=b#!~#value": 3, "mem,host=a#!~#free": 3}
+	for k, v := range readTSM(t, first) {
+		if exp[k] != v {
+			t.Errorf("got %d values for %s, expected %d", v, k, exp[k])
+		}
+	}
+
+	// The file without data left is removed.
+	run("-where", "host = 'a'", second)
+	if _, err := os.Stat(second); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed: %v", second, err)
+	}
+}
+
+func TestCommand_Run_InvalidWhere(t *testing.T) {
+	cmd := deletetsm.NewCommand()
+	cmd.Stdout = &bytes.Buffer{}
+	if err := cmd.Run("-where", "value > 1", "000000001-000000001.tsm"); err == nil || !strings.Contains(err.Error(), "unsupported operator") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
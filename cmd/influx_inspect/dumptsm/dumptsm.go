@@ -3,6 +3,7 @@ package dumptsm
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -26,6 +27,8 @@ type Command struct {
 	dumpIndex  bool
 	dumpBlocks bool
 	dumpAll    bool
+	summary    bool
+	format     string
 	filterKey  string
 	path       string
 }
@@ -44,6 +47,8 @@ func (cmd *Command) Run(args ...string) error {
 	fs.BoolVar(&cmd.dumpIndex, "index", false, "Dump raw index data")
 	fs.BoolVar(&cmd.dumpBlocks, "blocks", false, "Dump raw block data")
 	fs.BoolVar(&cmd.dumpAll, "all", false, "Dump all data. Caution: This may print a lot of information")
+	fs.BoolVar(&cmd.summary, "summary", false, "Only dump the summary and the statistics, by block type")
+	fs.StringVar(&cmd.format, "format", "text", "Output format: text or json")
 	fs.StringVar(&cmd.filterKey, "filter-key", "", "Only display index and block data match this key substring")
 
 	fs.SetOutput(cmd.Stdout)
@@ -54,59 +59,140 @@ func (cmd *Command) Run(args ...string) error {
 	}
 
 	if fs.Arg(0) == "" {
-		fmt.Printf("TSM file not specified\n\n")
+		fmt.Fprintf(cmd.Stdout, "TSM file not specified\n\n")
 		fs.Usage()
 		return nil
 	}
+	if cmd.format != "text" && cmd.format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", cmd.format)
+	}
 	cmd.path = fs.Args()[0]
-	cmd.dumpBlocks = cmd.dumpBlocks || cmd.dumpAll || cmd.filterKey != ""
-	cmd.dumpIndex = cmd.dumpIndex || cmd.dumpAll || cmd.filterKey != ""
+	cmd.dumpBlocks = !cmd.summary && (cmd.dumpBlocks || cmd.dumpAll || cmd.filterKey != "")
+	cmd.dumpIndex = !cmd.summary && (cmd.dumpIndex || cmd.dumpAll || cmd.filterKey != "")
 	return cmd.dump()
 }
 
+// fileDump holds the details of a TSM file.
+type fileDump struct {
+	File     string        `json:"file"`
+	MinTime  time.Time     `json:"minTime"`
+	MaxTime  time.Time     `json:"maxTime"`
+	Duration time.Duration `json:"duration"`
+	Series   int           `json:"series"`
+	Size     int64         `json:"size"`
+
+	Index      []indexDump `json:"index,omitempty"`
+	Blocks     []blockDump `json:"blocks,omitempty"`
+	Statistics statistics  `json:"statistics"`
+}
+
+type indexDump struct {
+	Pos         int       `json:"pos"`
+	MinTime     time.Time `json:"minTime"`
+	MaxTime     time.Time `json:"maxTime"`
+	Offset      int64     `json:"offset"`
+	Size        uint32    `json:"size"`
+	Measurement string    `json:"measurement"`
+	Field       string    `json:"field"`
+}
+
+type blockDump struct {
+	Block             int64     `json:"block"`
+	Checksum          uint32    `json:"checksum"`
+	Offset            int64     `json:"offset"`
+	Length            int       `json:"length"`
+	Type              string    `json:"type"`
+	Key               string    `json:"key"`
+	MinTime           time.Time `json:"minTime"`
+	MaxTime           time.Time `json:"maxTime"`
+	Points            int       `json:"points"`
+	TimestampEncoding string    `json:"timestampEncoding"`
+	ValueEncoding     string    `json:"valueEncoding"`
+	TimestampLength   int       `json:"timestampLength"`
+	ValueLength       int       `json:"valueLength"`
+}
+
+type statistics struct {
+	Blocks struct {
+		Total int64 `json:"total"`
+		Size  int64 `json:"size"`
+		Min   int   `json:"min"`
+		Max   int   `json:"max"`
+		Avg   int64 `json:"avg"`
+	} `json:"blocks"`
+	Index struct {
+		Total int64  `json:"total"`
+		Size  uint32 `json:"size"`
+	} `json:"index"`
+	Points int64 `json:"points"`
+
+	// Encodings are the number of blocks by encoding, by field type.
+	Encodings map[string]map[string]int `json:"encodings"`
+	// Types are the statistics of the blocks by block type.
+	Types map[string]*typeStatistics `json:"types"`
+
+	// BytesPerBlockPoint is the size of the blocks by point, and
+	// BytesPerPoint the size of the file by point.
+	BytesPerBlockPoint float64 `json:"bytesPerBlockPoint"`
+	BytesPerPoint      float64 `json:"bytesPerPoint"`
+}
+
+type typeStatistics struct {
+	Blocks        int64     `json:"blocks"`
+	Points        int64     `json:"points"`
+	Size          int64     `json:"size"`
+	MinTime       time.Time `json:"minTime"`
+	MaxTime       time.Time `json:"maxTime"`
+	BytesPerPoint float64   `json:"bytesPerPoint"`
+}
+
 func (cmd *Command) dump() error {
-	f, err := os.Open(cmd.path)
+	d, err := cmd.read()
 	if err != nil {
 		return err
 	}
+	if cmd.format == "json" {
+		enc := json.NewEncoder(cmd.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	cmd.printText(d)
+	return nil
+}
+
+// read reads the details of the file.
+func (cmd *Command) read() (*fileDump, error) {
+	f, err := os.Open(cmd.path)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get the file size
 	stat, err := f.Stat()
 	if err != nil {
-		return err
+		f.Close()
+		return nil, err
 	}
-	b := make([]byte, 8)
 
 	r, err := tsm1.NewTSMReader(f)
 	if err != nil {
-		return fmt.Errorf("Error opening TSM files: %s", err.Error())
+		f.Close()
+		return nil, fmt.Errorf("Error opening TSM files: %s", err.Error())
 	}
 	defer r.Close()
 
 	minTime, maxTime := r.TimeRange()
 	keyCount := r.KeyCount()
-
-	blockStats := &blockStats{}
-
-	println("Summary:")
-	fmt.Printf("  File: %s\n", cmd.path)
-	fmt.Printf("  Time Range: %s - %s\n",
-		time.Unix(0, minTime).UTC().Format(time.RFC3339Nano),
-		time.Unix(0, maxTime).UTC().Format(time.RFC3339Nano),
-	)
-	fmt.Printf("  Duration: %s ", time.Unix(0, maxTime).Sub(time.Unix(0, minTime)))
-	fmt.Printf("  Series: %d ", keyCount)
-	fmt.Printf("  File Size: %d\n", stat.Size())
-	println()
-
-	tw := tabwriter.NewWriter(cmd.Stdout, 8, 8, 1, '\t', 0)
+	d := &fileDump{
+		File:     cmd.path,
+		MinTime:  time.Unix(0, minTime).UTC(),
+		MaxTime:  time.Unix(0, maxTime).UTC(),
+		Duration: time.Unix(0, maxTime).Sub(time.Unix(0, minTime)),
+		Series:   keyCount,
+		Size:     stat.Size(),
+	}
 
 	if cmd.dumpIndex {
-		println("Index:")
-		tw.Flush()
-		println()
-
-		fmt.Fprintln(tw, "  "+strings.Join([]string{"Pos", "Min Time", "Max Time", "Ofs", "Size", "Key", "Field"}, "\t"))
 		var pos int
 		for i := 0; i < keyCount; i++ {
 			key, _ := r.KeyAt(i)
@@ -121,46 +207,37 @@ func (cmd *Command) dump() error {
 				if cmd.filterKey != "" && !strings.Contains(string(key), cmd.filterKey) {
 					continue
 				}
-				fmt.Fprintln(tw, "  "+strings.Join([]string{
-					strconv.FormatInt(int64(pos), 10),
-					time.Unix(0, e.MinTime).UTC().Format(time.RFC3339Nano),
-					time.Unix(0, e.MaxTime).UTC().Format(time.RFC3339Nano),
-					strconv.FormatInt(int64(e.Offset), 10),
-					strconv.FormatInt(int64(e.Size), 10),
-					measurement,
-					field,
-				}, "\t"))
-				tw.Flush()
+				d.Index = append(d.Index, indexDump{
+					Pos:         pos,
+					MinTime:     time.Unix(0, e.MinTime).UTC(),
+					MaxTime:     time.Unix(0, e.MaxTime).UTC(),
+					Offset:      e.Offset,
+					Size:        e.Size,
+					Measurement: measurement,
+					Field:       field,
+				})
 			}
 		}
 	}
 
-	tw = tabwriter.NewWriter(cmd.Stdout, 8, 8, 1, '\t', 0)
-	fmt.Fprintln(tw, "  "+strings.Join([]string{"Blk", "Chk", "Ofs", "Len", "Type", "Min Time", "Points", "Enc [T/V]", "Len [T/V]"}, "\t"))
-
-	// Starting at 5 because the magic number is 4 bytes + 1 byte version
-	i := int64(5)
-	var blockCount, pointCount, blockSize int64
-	indexSize := r.IndexSize()
+	blockStats := &blockStats{}
+	st := &d.Statistics
+	st.Encodings = make(map[string]map[string]int)
+	st.Types = make(map[string]*typeStatistics)
 
 	// Start at the beginning and read every block
 	for j := 0; j < keyCount; j++ {
 		key, _ := r.KeyAt(j)
 		for _, e := range r.Entries(key) {
+			chksum, buf, err := r.ReadBytes(&e, nil)
+			if err != nil {
+				return nil, err
+			}
 
-			f.Seek(int64(e.Offset), 0)
-			f.Read(b[:4])
-
-			chksum := binary.BigEndian.Uint32(b[:4])
-
-			buf := make([]byte, e.Size-4)
-			f.Read(buf)
-
-			blockSize += int64(e.Size)
+			st.Blocks.Size += int64(e.Size)
 
 			if cmd.filterKey != "" && !strings.Contains(string(key), cmd.filterKey) {
-				i += blockSize
-				blockCount++
+				st.Blocks.Total++
 				continue
 			}
 
@@ -169,13 +246,12 @@ func (cmd *Command) dump() error {
 			encoded := buf[1:]
 
 			var v []tsm1.Value
-			v, err := tsm1.DecodeBlock(buf, v)
+			v, err = tsm1.DecodeBlock(buf, v)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			startTime := time.Unix(0, v[0].UnixNano())
 
-			pointCount += int64(len(v))
+			st.Points += int64(len(v))
 
 			// Length of the timestamp block
 			tsLen, j := binary.Uvarint(encoded)
@@ -195,61 +271,170 @@ func (cmd *Command) dump() error {
 			blockStats.inc(int(blockType+1), values[0]>>4)
 			blockStats.size(len(buf))
 
+			ty, ok := st.Types[typeDesc]
+			if !ok {
+				ty = &typeStatistics{MinTime: time.Unix(0, e.MinTime).UTC(), MaxTime: time.Unix(0, e.MaxTime).UTC()}
+				st.Types[typeDesc] = ty
+			}
+			ty.Blocks++
+			ty.Points += int64(len(v))
+			ty.Size += int64(e.Size)
+			if t := time.Unix(0, e.MinTime).UTC(); t.Before(ty.MinTime) {
+				ty.MinTime = t
+			}
+			if t := time.Unix(0, e.MaxTime).UTC(); t.After(ty.MaxTime) {
+				ty.MaxTime = t
+			}
+
 			if cmd.dumpBlocks {
-				fmt.Fprintln(tw, "  "+strings.Join([]string{
-					strconv.FormatInt(blockCount, 10),
-					strconv.FormatUint(uint64(chksum), 10),
-					strconv.FormatInt(i, 10),
-					strconv.FormatInt(int64(len(buf)), 10),
-					typeDesc,
-					startTime.UTC().Format(time.RFC3339Nano),
-					strconv.FormatInt(int64(len(v)), 10),
-					fmt.Sprintf("%s/%s", tsEncoding, vEncoding),
-					fmt.Sprintf("%d/%d", len(ts), len(values)),
-				}, "\t"))
+				d.Blocks = append(d.Blocks, blockDump{
+					Block:             st.Blocks.Total,
+					Checksum:          chksum,
+					Offset:            e.Offset,
+					Length:            len(buf),
+					Type:              typeDesc,
+					Key:               string(key),
+					MinTime:           time.Unix(0, v[0].UnixNano()).UTC(),
+					MaxTime:           time.Unix(0, v[len(v)-1].UnixNano()).UTC(),
+					Points:            len(v),
+					TimestampEncoding: tsEncoding,
+					ValueEncoding:     vEncoding,
+					TimestampLength:   len(ts),
+					ValueLength:       len(values),
+				})
 			}
 
-			i += blockSize
-			blockCount++
+			st.Blocks.Total++
 		}
 	}
 
-	if cmd.dumpBlocks {
-		println("Blocks:")
-		tw.Flush()
-		println()
+	if st.Blocks.Total > 0 {
+		st.Blocks.Avg = st.Blocks.Size / st.Blocks.Total
 	}
-
-	var blockSizeAvg int64
-	if blockCount > 0 {
-		blockSizeAvg = blockSize / blockCount
-	}
-	fmt.Printf("Statistics\n")
-	fmt.Printf("  Blocks:\n")
-	fmt.Printf("    Total: %d Size: %d Min: %d Max: %d Avg: %d\n",
-		blockCount, blockSize, blockStats.min, blockStats.max, blockSizeAvg)
-	fmt.Printf("  Index:\n")
-	fmt.Printf("    Total: %d Size: %d\n", blockCount, indexSize)
-	fmt.Printf("  Points:\n")
-	fmt.Printf("    Total: %d", pointCount)
-	println()
-
-	println("  Encoding:")
+	st.Blocks.Min, st.Blocks.Max = blockStats.min, blockStats.max
+	st.Index.Total, st.Index.Size = st.Blocks.Total, r.IndexSize()
 	for i, counts := range blockStats.counts {
 		if len(counts) == 0 {
 			continue
 		}
-		fmt.Printf("    %s: ", cases.Title(language.Und, cases.NoLower).String(fieldType[i]))
+		encodings := make(map[string]int)
 		for j, v := range counts {
-			fmt.Printf("\t%s: %d (%d%%) ", encDescs[i][j], v, int(float64(v)/float64(blockCount)*100))
+			encodings[encDescs[i][j]] = v
 		}
-		println()
+		st.Encodings[fieldType[i]] = encodings
+	}
+	for _, ty := range st.Types {
+		ty.BytesPerPoint = float64(ty.Size) / float64(ty.Points)
 	}
-	fmt.Printf("  Compression:\n")
-	fmt.Printf("    Per block: %0.2f bytes/point\n", float64(blockSize)/float64(pointCount))
-	fmt.Printf("    Total: %0.2f bytes/point\n", float64(stat.Size())/float64(pointCount))
+	if st.Points > 0 {
+		st.BytesPerBlockPoint = float64(st.Blocks.Size) / float64(st.Points)
+		st.BytesPerPoint = float64(d.Size) / float64(st.Points)
+	}
+	return d, nil
+}
 
-	return nil
+// printText prints the details of the file as text.
+func (cmd *Command) printText(d *fileDump) {
+	w := cmd.Stdout
+	fmt.Fprintln(w, "Summary:")
+	fmt.Fprintf(w, "  File: %s\n", d.File)
+	fmt.Fprintf(w, "  Time Range: %s - %s\n", d.MinTime.Format(time.RFC3339Nano), d.MaxTime.Format(time.RFC3339Nano))
+	fmt.Fprintf(w, "  Duration: %s ", d.Duration)
+	fmt.Fprintf(w, "  Series: %d ", d.Series)
+	fmt.Fprintf(w, "  File Size: %d\n", d.Size)
+	fmt.Fprintln(w)
+
+	if cmd.dumpIndex {
+		fmt.Fprintln(w, "Index:")
+		fmt.Fprintln(w)
+
+		tw := tabwriter.NewWriter(w, 8, 8, 1, '\t', 0)
+		fmt.Fprintln(tw, "  "+strings.Join([]string{"Pos", "Min Time", "Max Time", "Ofs", "Size", "Key", "Field"}, "\t"))
+		for _, e := range d.Index {
+			fmt.Fprintln(tw, "  "+strings.Join([]string{
+				strconv.FormatInt(int64(e.Pos), 10),
+				e.MinTime.Format(time.RFC3339Nano),
+				e.MaxTime.Format(time.RFC3339Nano),
+				strconv.FormatInt(e.Offset, 10),
+				strconv.FormatInt(int64(e.Size), 10),
+				e.Measurement,
+				e.Field,
+			}, "\t"))
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	if cmd.dumpBlocks {
+		fmt.Fprintln(w, "Blocks:")
+		tw := tabwriter.NewWriter(w, 8, 8, 1, '\t', 0)
+		fmt.Fprintln(tw, "  "+strings.Join([]string{"Blk", "Chk", "Ofs", "Len", "Type", "Min Time", "Points", "Enc [T/V]", "Len [T/V]"}, "\t"))
+		for _, b := range d.Blocks {
+			fmt.Fprintln(tw, "  "+strings.Join([]string{
+				strconv.FormatInt(b.Block, 10),
+				strconv.FormatUint(uint64(b.Checksum), 10),
+				strconv.FormatInt(b.Offset, 10),
+				strconv.FormatInt(int64(b.Length), 10),
+				b.Type,
+				b.MinTime.Format(time.RFC3339Nano),
+				strconv.FormatInt(int64(b.Points), 10),
+				fmt.Sprintf("%s/%s", b.TimestampEncoding, b.ValueEncoding),
+				fmt.Sprintf("%d/%d", b.TimestampLength, b.ValueLength),
+			}, "\t"))
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	st := d.Statistics
+	fmt.Fprintf(w, "Statistics\n")
+	fmt.Fprintf(w, "  Blocks:\n")
+	fmt.Fprintf(w, "    Total: %d Size: %d Min: %d Max: %d Avg: %d\n",
+		st.Blocks.Total, st.Blocks.Size, st.Blocks.Min, st.Blocks.Max, st.Blocks.Avg)
+	fmt.Fprintf(w, "  Index:\n")
+	fmt.Fprintf(w, "    Total: %d Size: %d\n", st.Index.Total, st.Index.Size)
+	fmt.Fprintf(w, "  Points:\n")
+	fmt.Fprintf(w, "    Total: %d\n", st.Points)
+
+	fmt.Fprintln(w, "  Encoding:")
+	for i, typ := range fieldType {
+		encodings, ok := st.Encodings[typ]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "    %s: ", cases.Title(language.Und, cases.NoLower).String(typ))
+		for _, enc := range encDescs[i] {
+			if v, ok := encodings[enc]; ok {
+				fmt.Fprintf(w, "\t%s: %d (%d%%) ", enc, v, int(float64(v)/float64(st.Blocks.Total)*100))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "  Compression:\n")
+	fmt.Fprintf(w, "    Per block: %0.2f bytes/point\n", st.BytesPerBlockPoint)
+	fmt.Fprintf(w, "    Total: %0.2f bytes/point\n", st.BytesPerPoint)
+
+	if cmd.summary {
+		fmt.Fprintf(w, "  Types:\n")
+		tw := tabwriter.NewWriter(w, 8, 8, 1, '\t', 0)
+		fmt.Fprintln(tw, "    "+strings.Join([]string{"Type", "Blocks", "Points", "Size", "Bytes/Point", "Min Time", "Max Time"}, "\t"))
+		for _, typ := range blockTypes {
+			ty, ok := st.Types[typ]
+			if !ok {
+				continue
+			}
+			fmt.Fprintln(tw, "    "+strings.Join([]string{
+				typ,
+				strconv.FormatInt(ty.Blocks, 10),
+				strconv.FormatInt(ty.Points, 10),
+				strconv.FormatInt(ty.Size, 10),
+				fmt.Sprintf("%0.2f", ty.BytesPerPoint),
+				ty.MinTime.Format(time.RFC3339Nano),
+				ty.MaxTime.Format(time.RFC3339Nano),
+			}, "\t"))
+		}
+		tw.Flush()
+	}
 }
 
 // printUsage prints the usage message to STDERR.
@@ -264,6 +449,11 @@ Usage: influx_inspect dumptsm [flags] <path
             Dump raw block data
     -all
             Dump all data. Caution: This may print a lot of information
+    -summary
+            Only dump the summary and the statistics, with the blocks,
+            points, sizes, compression and time range of each block type
+    -format <text|json>
+            Output format. Defaults to text
     -filter-key <name>
             Only display index and block data match this key substring
 `
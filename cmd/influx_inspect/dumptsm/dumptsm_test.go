@@ -1,3 +1,102 @@
 package dumptsm_test
 
-// TODO: write some tests
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/dumptsm"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+// writeTSM writes a TSM file with a float and an integer field.
+func writeTSM(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "000000001-000000001.tsm")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]byte("cpu,host=a#!~#value"), []tsm1.Value{tsm1.NewValue(1, 1.5), tsm1.NewValue(2, 2.5)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]byte("mem,host=a#!~#free"), []tsm1.Value{tsm1.NewValue(3, int64(1)), tsm1.NewValue(4, int64(2))}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func run(t *testing.T, args ...string) string {
+	t.Helper()
+	var stdout bytes.Buffer
+	cmd := dumptsm.NewCommand()
+	cmd.Stdout = &stdout
+	if err := cmd.Run(args...); err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String()
+}
+
+func TestCommand_Run_JSON(t *testing.T) {
+	path := writeTSM(t)
+	var d struct {
+		Series int `json:"series"`
+		Index  []struct {
+			Measurement string `json:"measurement"`
+			Field       string `json:"field"`
+		} `json:"index"`
+		Blocks []struct {
+			Key           string `json:"key"`
+			Type          string `json:"type"`
+			Points        int    `json:"points"`
+			ValueEncoding string `json:"valueEncoding"`
+		} `json:"blocks"`
+		Statistics struct {
+			Points    int64                     `json:"points"`
+			Encodings map[string]map[string]int `json:"encodings"`
+			Types     map[string]struct {
+				Blocks int64 `json:"blocks"`
+				Points int64 `json:"points"`
+			} `json:"types"`
+		} `json:"statistics"`
+	}
+	out := run(t, "-format", "json", "-all", path)
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatalf("invalid json: %s\n%s", err, out)
+	}
+
+	if d.Series != 2 || len(d.Index) != 2 || d.Index[0].Measurement != "cpu,host=a" || d.Index[0].Field != "value" {
+		t.Fatalf("unexpected index: %+v", d)
+	}
+	if len(d.Blocks) != 2 || d.Blocks[0].Type != "float64" || d.Blocks[0].Points != 2 || d.Blocks[0].ValueEncoding != "gor" {
+		t.Fatalf("unexpected blocks: %+v", d.Blocks)
+	}
+	if d.Statistics.Points != 4 || d.Statistics.Encodings["float"]["gor"] != 1 || d.Statistics.Types["int64"].Points != 2 {
+		t.Fatalf("unexpected statistics: %+v", d.Statistics)
+	}
+}
+
+func TestCommand_Run_Summary(t *testing.T) {
+	path := writeTSM(t)
+	out := run(t, "-summary", "-all", path)
+	if strings.Contains(out, "\nBlocks:\n") || strings.Contains(out, "\nIndex:\n") {
+		t.Fatalf("unexpected blocks or index in summary:\n%s", out)
+	}
+	for _, exp := range []string{"Series: 2", "Points:\n    Total: 4", "Types:", "float64", "int64"} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected %q in output:\n%s", exp, out)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package dumptsmwal
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -20,6 +21,7 @@ type Command struct {
 	Stdout io.Writer
 
 	showDuplicates bool
+	format         string // text or json
 }
 
 // NewCommand returns a new instance of Command.
@@ -35,15 +37,22 @@ func (cmd *Command) Run(args ...string) (err error) {
 	fs := flag.NewFlagSet("dumptsmwal", flag.ExitOnError)
 	fs.SetOutput(cmd.Stdout)
 	fs.BoolVar(&cmd.showDuplicates, "show-duplicates", false, "prints keys with out-of-order or duplicate values")
+	fs.StringVar(&cmd.format, "format", "text", "output format: text or json")
 	fs.Usage = cmd.printUsage
 	if err := fs.Parse(args); err != nil {
 		return err
 	} else if fs.NArg() == 0 {
-		fmt.Printf("path required\n\n")
+		fmt.Fprintf(cmd.Stdout, "path required\n\n")
 		fs.Usage()
 		return nil
 	}
 
+	switch cmd.format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("invalid format %q, expected text or json", cmd.format)
+	}
+
 	// Process each TSM WAL file.
 	for _, path := range fs.Args() {
 		if err := cmd.process(path); err != nil {
@@ -72,6 +81,7 @@ func (cmd *Command) process(path string) error {
 	r := tsm1.NewWALSegmentReader(f)
 
 	// Iterate over the WAL entries.
+	enc := json.NewEncoder(cmd.Stdout)
 	for r.Next() {
 		entry, err := r.Read()
 		if err != nil {
@@ -80,58 +90,85 @@ func (cmd *Command) process(path string) error {
 
 		switch entry := entry.(type) {
 		case *tsm1.WriteWALEntry:
-			if !cmd.showDuplicates {
-				fmt.Printf("[write] sz=%d\n", entry.MarshalSize())
-			}
-
 			keys := make([]string, 0, len(entry.Values))
 			for k := range entry.Values {
 				keys = append(keys, k)
 			}
 			sort.Strings(keys)
 
+			// Check for duplicate/out of order keys.
 			for _, k := range keys {
 				for _, v := range entry.Values[k] {
 					t := v.UnixNano()
-
-					// Check for duplicate/out of order keys.
 					if min, ok := minTimestampByKey[k]; ok && t <= min {
 						duplicateKeys[k] = struct{}{}
 					}
 					minTimestampByKey[k] = t
+				}
+			}
+
+			// Skip printing if we are only showing duplicate keys.
+			if cmd.showDuplicates {
+				continue
+			}
 
-					// Skip printing if we are only showing duplicate keys.
-					if cmd.showDuplicates {
-						continue
+			if cmd.format == "json" {
+				e := jsonEntry{File: path, Type: "write", Size: entry.MarshalSize()}
+				for _, k := range keys {
+					for _, v := range entry.Values[k] {
+						e.Values = append(e.Values, jsonValue{Key: k, Type: valueType(v), Value: v.Value(), Time: v.UnixNano()})
 					}
+				}
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+				continue
+			}
 
+			fmt.Fprintf(cmd.Stdout, "[write] sz=%d\n", entry.MarshalSize())
+			for _, k := range keys {
+				for _, v := range entry.Values[k] {
+					t := v.UnixNano()
 					switch v := v.(type) {
 					case tsm1.IntegerValue:
-						fmt.Printf("%s %vi %d\n", k, v.Value(), t)
+						fmt.Fprintf(cmd.Stdout, "%s %vi %d\n", k, v.Value(), t)
 					case tsm1.UnsignedValue:
-						fmt.Printf("%s %vu %d\n", k, v.Value(), t)
+						fmt.Fprintf(cmd.Stdout, "%s %vu %d\n", k, v.Value(), t)
 					case tsm1.FloatValue:
-						fmt.Printf("%s %v %d\n", k, v.Value(), t)
+						fmt.Fprintf(cmd.Stdout, "%s %v %d\n", k, v.Value(), t)
 					case tsm1.BooleanValue:
-						fmt.Printf("%s %v %d\n", k, v.Value(), t)
+						fmt.Fprintf(cmd.Stdout, "%s %v %d\n", k, v.Value(), t)
 					case tsm1.StringValue:
-						fmt.Printf("%s %q %d\n", k, v.Value(), t)
+						fmt.Fprintf(cmd.Stdout, "%s %q %d\n", k, v.Value(), t)
 					default:
-						fmt.Printf("%s EMPTY\n", k)
+						fmt.Fprintf(cmd.Stdout, "%s EMPTY\n", k)
 					}
 				}
 			}
 
 		case *tsm1.DeleteWALEntry:
-			fmt.Printf("[delete] sz=%d\n", entry.MarshalSize())
+			if cmd.format == "json" {
+				if err := enc.Encode(jsonEntry{File: path, Type: "delete", Size: entry.MarshalSize(), Keys: stringKeys(entry.Keys)}); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Fprintf(cmd.Stdout, "[delete] sz=%d\n", entry.MarshalSize())
 			for _, k := range entry.Keys {
-				fmt.Printf("%s\n", string(k))
+				fmt.Fprintf(cmd.Stdout, "%s\n", string(k))
 			}
 
 		case *tsm1.DeleteRangeWALEntry:
-			fmt.Printf("[delete-range] min=%d max=%d sz=%d\n", entry.Min, entry.Max, entry.MarshalSize())
+			if cmd.format == "json" {
+				min, max := entry.Min, entry.Max
+				if err := enc.Encode(jsonEntry{File: path, Type: "delete-range", Size: entry.MarshalSize(), Keys: stringKeys(entry.Keys), Min: &min, Max: &max}); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Fprintf(cmd.Stdout, "[delete-range] min=%d max=%d sz=%d\n", entry.Min, entry.Max, entry.MarshalSize())
 			for _, k := range entry.Keys {
-				fmt.Printf("%s\n", string(k))
+				fmt.Fprintf(cmd.Stdout, "%s\n", string(k))
 			}
 
 		default:
@@ -147,16 +184,69 @@ func (cmd *Command) process(path string) error {
 		}
 		sort.Strings(keys)
 
+		if cmd.format == "json" {
+			return enc.Encode(jsonEntry{File: path, Type: "duplicates", Keys: keys})
+		}
 		for _, k := range keys {
-			fmt.Println(k)
+			fmt.Fprintln(cmd.Stdout, k)
 		}
 	}
 
 	return nil
 }
 
+// jsonEntry is the JSON output of a WAL entry, one per line.
+type jsonEntry struct {
+	File   string      `json:"file"`
+	Type   string      `json:"type"`
+	Size   int         `json:"size,omitempty"`
+	Values []jsonValue `json:"values,omitempty"`
+	Keys   []string    `json:"keys,omitempty"`
+	Min    *int64      `json:"min,omitempty"`
+	Max    *int64      `json:"max,omitempty"`
+}
+
+type jsonValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+	Time  int64       `json:"time"`
+}
+
+// valueType returns the name of the type of a value.
+func valueType(v tsm1.Value) string {
+	switch v.(type) {
+	case tsm1.IntegerValue:
+		return "integer"
+	case tsm1.UnsignedValue:
+		return "unsigned"
+	case tsm1.FloatValue:
+		return "float"
+	case tsm1.BooleanValue:
+		return "boolean"
+	case tsm1.StringValue:
+		return "string"
+	default:
+		return "empty"
+	}
+}
+
+func stringKeys(keys [][]byte) []string {
+	a := make([]string, len(keys))
+	for i, k := range keys {
+		a[i] = string(k)
+	}
+	return a
+}
+
 func (cmd *Command) printUsage() {
-	fmt.Print(`Dumps all entries from one or more TSM WAL files.
+	fmt.Fprint(cmd.Stdout, `Dumps all entries from one or more TSM WAL files.
+
+Usage: influx_inspect dumptsmwal [flags] path...
 
-Usage: influx_inspect dumptsmwal path...`)
+    -format text|json
+            The output format. The json format prints an object per entry
+            and per line.
+    -show-duplicates
+            Only print the keys with out-of-order or duplicate values.`)
 }
@@ -0,0 +1,75 @@
+package dumptsmwal_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/dumptsmwal"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+func TestCommand_Run_JSON(t *testing.T) {
+	dir := t.TempDir()
+	wal := tsm1.NewWAL(dir)
+	if err := wal.Open(); err != nil {
+		t.Fatal(err)
+	} else if _, err := wal.WriteMulti(map[string][]tsm1.Value{
+		"cpu,host=a#!~#value": {tsm1.NewValue(1, 1.5)},
+		"cpu,host=a#!~#count": {tsm1.NewValue(2, int64(3))},
+	}); err != nil {
+		t.Fatal(err)
+	} else if _, err := wal.DeleteRange([][]byte{[]byte("cpu,host=a#!~#value")}, 0, 10); err != nil {
+		t.Fatal(err)
+	} else if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := dumptsmwal.NewCommand()
+	cmd.Stdout, cmd.Stderr = &stdout, &bytes.Buffer{}
+	if err := cmd.Run(append([]string{"-format", "json"}, paths...)...); err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		Type   string
+		Values []struct {
+			Key   string
+			Type  string
+			Value interface{}
+			Time  int64
+		}
+		Keys     []string
+		Min, Max *int64
+	}
+	var entries []entry
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		var e entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid line %q: %s", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, expected 2:\n%s", len(entries), stdout.String())
+	}
+	if e := entries[0]; e.Type != "write" || len(e.Values) != 2 ||
+		e.Values[0].Key != "cpu,host=a#!~#count" || e.Values[0].Type != "integer" || e.Values[0].Value != 3.0 || e.Values[0].Time != 2 ||
+		e.Values[1].Key != "cpu,host=a#!~#value" || e.Values[1].Type != "float" || e.Values[1].Value != 1.5 {
+		t.Errorf("unexpected write entry: %+v", e)
+	}
+	if e := entries[1]; e.Type != "delete-range" || len(e.Keys) != 1 || e.Min == nil || *e.Min != 0 || e.Max == nil || *e.Max != 10 {
+		t.Errorf("unexpected delete-range entry: %+v", e)
+	}
+
+	if err := cmd.Run("-format", "xml", paths[0]); err == nil {
+		t.Error("expected an error for an invalid format")
+	}
+}
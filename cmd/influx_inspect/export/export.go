@@ -2,8 +2,6 @@
 package export
 
 import (
-	"bufio"
-	"compress/gzip"
 	"flag"
 	"fmt"
 	"io"
@@ -11,13 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/influxdata/influxdb/cmd/influx_inspect/internal/seriesfilter"
 	"github.com/influxdata/influxdb/models"
-	"github.com/influxdata/influxdb/pkg/escape"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
 	"github.com/influxdata/influxql"
 )
@@ -37,6 +34,10 @@ type Command struct {
 	endTime         int64
 	compress        bool
 	lponly          bool
+	format          string
+	precision       string
+	filter          *seriesfilter.Filter // series of -measurement and -where
+	chunk           time.Duration
 
 	manifest map[string]struct{}
 	tsmFiles map[string][]string
@@ -64,7 +65,7 @@ func (cmd *Command) usingStdOut() bool {
 
 // Run executes the command.
 func (cmd *Command) Run(args ...string) error {
-	var start, end string
+	var start, end, measurements, where string
 	fs := flag.NewFlagSet("export", flag.ExitOnError)
 	fs.StringVar(&cmd.dataDir, "datadir", os.Getenv("HOME")+"/.influxdb/data", "Data storage path")
 	fs.StringVar(&cmd.walDir, "waldir", os.Getenv("HOME")+"/.influxdb/wal", "WAL storage path")
@@ -75,10 +76,15 @@ func (cmd *Command) Run(args ...string) error {
 	fs.StringVar(&end, "end", "", "Optional: the end time to export (RFC3339 format)")
 	fs.BoolVar(&cmd.lponly, "lponly", false, "Only export line protocol")
 	fs.BoolVar(&cmd.compress, "compress", false, "Compress the output")
+	fs.StringVar(&cmd.format, "format", formatLine, "Optional: the format of the output: line, csv or parquet")
+	fs.StringVar(&cmd.precision, "precision", "n", "Optional: the precision of the timestamps of line protocol: n, u, ms, s, m or h")
+	fs.StringVar(&measurements, "measurement", "", "Optional: the measurements to export, separated by commas")
+	fs.StringVar(&where, "where", "", "Optional: the tag predicates of the series to export, such as \"host = 'a' AND region =~ /^us-/\"")
+	fs.DurationVar(&cmd.chunk, "chunk", 0, "Optional: write an output file per window of time of this duration, such as 24h")
 
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = func() {
-		fmt.Fprintf(cmd.Stdout, "Exports TSM files into InfluxDB line protocol format.\n\n")
+		fmt.Fprintf(cmd.Stdout, "Exports TSM files into InfluxDB line protocol, csv or parquet format.\n\n")
 		fmt.Fprintf(cmd.Stdout, "Usage: %s export [flags]\n\n", filepath.Base(os.Args[0]))
 		fs.PrintDefaults()
 	}
@@ -107,6 +113,22 @@ func (cmd *Command) Run(args ...string) error {
 		// set end time to max if it is not set.
 		cmd.endTime = math.MaxInt64
 	}
+	var measurementSet map[string]bool
+	if measurements != "" {
+		measurementSet = make(map[string]bool)
+		for _, m := range strings.Split(measurements, ",") {
+			measurementSet[strings.TrimSpace(m)] = true
+		}
+	}
+	var whereExpr influxql.Expr
+	if where != "" {
+		expr, err := seriesfilter.ParseWhere(where)
+		if err != nil {
+			return err
+		}
+		whereExpr = expr
+	}
+	cmd.filter = seriesfilter.New(measurementSet, whereExpr)
 
 	if err := cmd.validate(); err != nil {
 		return err
@@ -122,6 +144,21 @@ func (cmd *Command) validate() error {
 	if cmd.startTime != 0 && cmd.endTime != 0 && cmd.endTime < cmd.startTime {
 		return fmt.Errorf("end time before start time")
 	}
+	switch cmd.format {
+	case formatLine, formatCSV, formatParquet:
+	default:
+		return fmt.Errorf("unknown format %q, expected line, csv or parquet", cmd.format)
+	}
+	if !models.ValidPrecision(cmd.precision) {
+		return fmt.Errorf("unknown precision %q, expected n, u, ms, s, m or h", cmd.precision)
+	} else if cmd.format != formatLine && cmd.precision != "" && cmd.precision != "n" {
+		return fmt.Errorf("precision requires the line format")
+	}
+	if cmd.chunk < 0 {
+		return fmt.Errorf("chunk must be positive")
+	} else if cmd.chunk > 0 && cmd.usingStdOut() {
+		return fmt.Errorf("chunk requires an output file")
+	}
 	return nil
 }
 
@@ -222,8 +259,7 @@ func (cmd *Command) writeDDL(mw io.Writer, w io.Writer) error {
 	return nil
 }
 
-func (cmd *Command) writeDML(mw io.Writer, w io.Writer) error {
-	fmt.Fprintln(mw, "# DML")
+func (cmd *Command) writeDML(w valueWriter) error {
 	var msgOut io.Writer
 	if cmd.usingStdOut() {
 		msgOut = cmd.Stderr
@@ -232,18 +268,19 @@ func (cmd *Command) writeDML(mw io.Writer, w io.Writer) error {
 	}
 	for key := range cmd.manifest {
 		keys := strings.Split(key, string(os.PathSeparator))
-		fmt.Fprintf(mw, "# CONTEXT-DATABASE:%s\n", keys[0])
-		fmt.Fprintf(mw, "# CONTEXT-RETENTION-POLICY:%s\n", keys[1])
+		if err := w.setContext(keys[0], keys[1]); err != nil {
+			return err
+		}
 		if files, ok := cmd.tsmFiles[key]; ok {
 			fmt.Fprintf(msgOut, "writing out tsm file data for %s...", key)
-			if err := cmd.writeTsmFiles(mw, w, files); err != nil {
+			if err := cmd.writeTsmFiles(w, files); err != nil {
 				return err
 			}
 			fmt.Fprintln(msgOut, "complete.")
 		}
 		if _, ok := cmd.walFiles[key]; ok {
 			fmt.Fprintf(msgOut, "writing out wal file data for %s...", key)
-			if err := cmd.writeWALFiles(mw, w, cmd.walFiles[key], key); err != nil {
+			if err := cmd.writeWALFiles(w, cmd.walFiles[key], key); err != nil {
 				return err
 			}
 			fmt.Fprintln(msgOut, "complete.")
@@ -253,15 +290,16 @@ func (cmd *Command) writeDML(mw io.Writer, w io.Writer) error {
 	return nil
 }
 
-// writeFull writes the full DML and DDL to the supplied io.Writers.  mw is the
-// "meta" writer where comments and other informational writes go and w is for
-// the actual payload of the writes -- DML and DDL.
+// writeFull writes the header and the DDL of the line protocol of the values
+// between start and end to the supplied io.Writers.  mw is the "meta" writer
+// where comments and other informational writes go and w is for the actual
+// payload of the writes -- DML and DDL.
 //
 // Typically mw and w are the same but if we'd like to, for example, filter out
 // comments and other meta data, we can pass io.Discard to mw to only
 // include the raw data that writeFull() generates.
-func (cmd *Command) writeFull(mw io.Writer, w io.Writer) error {
-	s, e := time.Unix(0, cmd.startTime).Format(time.RFC3339), time.Unix(0, cmd.endTime).Format(time.RFC3339)
+func (cmd *Command) writeFull(mw io.Writer, w io.Writer, start, end int64) error {
+	s, e := time.Unix(0, start).Format(time.RFC3339), time.Unix(0, end).Format(time.RFC3339)
 
 	fmt.Fprintf(mw, "# INFLUXDB EXPORT: %s - %s\n", s, e)
 
@@ -271,58 +309,32 @@ func (cmd *Command) writeFull(mw io.Writer, w io.Writer) error {
 		}
 	}
 
-	if err := cmd.writeDML(mw, w); err != nil {
-		return err
-	}
-
+	fmt.Fprintln(mw, "# DML")
 	return nil
 }
 
+// write writes the values to the output file, or to an output file per
+// window of time with -chunk.
 func (cmd *Command) write() error {
-	var w io.Writer
-	if cmd.usingStdOut() {
-		w = cmd.Stdout
+	var w valueWriter
+	if cmd.chunk > 0 {
+		w = newChunkedWriter(cmd, cmd.chunk)
 	} else {
-		// open our output file and create an output buffer
-		f, err := os.Create(cmd.out)
-		if err != nil {
+		var err error
+		if w, err = cmd.create(cmd.out, cmd.startTime, cmd.endTime); err != nil {
 			return err
 		}
-		defer f.Close()
-		w = f
-	}
-	// Because calling (*os.File).Write is relatively expensive,
-	// and we don't *need* to sync to disk on every written line of export,
-	// use a sized buffered writer so that we only sync the file every megabyte.
-	bw := bufio.NewWriterSize(w, 1024*1024)
-	defer bw.Flush()
-	w = bw
-
-	if cmd.compress {
-		gzw := gzip.NewWriter(w)
-		defer gzw.Close()
-		w = gzw
-	}
-
-	// mw is our "meta writer" -- the io.Writer to which meta/out-of-band data
-	// like comments will be sent.  If the lponly flag is set, mw will be
-	// io.Discard which effectively filters out comments and any other
-	// non-line protocol data.
-	//
-	// Otherwise, mw is set to the same writer as the actual DDL and line
-	// protocol DML which will cause the comments to be intermixed with the
-	// data..
-	//
-	mw := w
-	if cmd.lponly {
-		mw = io.Discard
-	}
-
-	return cmd.writeFull(mw, w)
+	}
+
+	err := cmd.writeDML(w)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
-func (cmd *Command) writeTsmFiles(mw io.Writer, w io.Writer, files []string) error {
-	fmt.Fprintln(mw, "# writing tsm data")
+func (cmd *Command) writeTsmFiles(w valueWriter, files []string) error {
+	w.comment("# writing tsm data")
 
 	// we need to make sure we write the same order that the files were written
 	sort.Strings(files)
@@ -336,7 +348,7 @@ func (cmd *Command) writeTsmFiles(mw io.Writer, w io.Writer, files []string) err
 	return nil
 }
 
-func (cmd *Command) exportTSMFile(tsmFilePath string, w io.Writer) error {
+func (cmd *Command) exportTSMFile(tsmFilePath string, w valueWriter) error {
 	f, err := os.Open(tsmFilePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -366,7 +378,6 @@ func (cmd *Command) exportTSMFile(tsmFilePath string, w io.Writer) error {
 			continue
 		}
 		measurement, field := tsm1.SeriesAndFieldFromCompositeKey(key)
-		field = escape.Bytes(field)
 
 		if err := cmd.writeValues(w, measurement, string(field), values); err != nil {
 			// An error from writeValues indicates an IO error, which should be returned.
@@ -376,8 +387,8 @@ func (cmd *Command) exportTSMFile(tsmFilePath string, w io.Writer) error {
 	return nil
 }
 
-func (cmd *Command) writeWALFiles(mw io.Writer, w io.Writer, files []string, key string) error {
-	fmt.Fprintln(mw, "# writing wal data")
+func (cmd *Command) writeWALFiles(w valueWriter, files []string, key string) error {
+	w.comment("# writing wal data")
 
 	// we need to make sure we write the same order that the wal received the data
 	sort.Strings(files)
@@ -404,7 +415,7 @@ or manually editing the exported file.
 }
 
 // exportWAL reads every WAL entry from r and exports it to w.
-func (cmd *Command) exportWALFile(walFilePath string, w io.Writer, warnDelete func()) error {
+func (cmd *Command) exportWALFile(walFilePath string, w valueWriter, warnDelete func()) error {
 	f, err := os.Open(walFilePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -432,9 +443,9 @@ func (cmd *Command) exportWALFile(walFilePath string, w io.Writer, warnDelete fu
 			continue
 		case *tsm1.WriteWALEntry:
 			for key, values := range t.Values {
+				// measurements are stored escaped, field names are escaped by
+				// the line protocol writer
 				measurement, field := tsm1.SeriesAndFieldFromCompositeKey([]byte(key))
-				// measurements are stored escaped, field names are not
-				field = escape.Bytes(field)
 
 				if err := cmd.writeValues(w, measurement, string(field), values); err != nil {
 					// An error from writeValues indicates an IO error, which should be returned.
@@ -446,52 +457,30 @@ func (cmd *Command) exportWALFile(walFilePath string, w io.Writer, warnDelete fu
 	return nil
 }
 
-// writeValues writes every value in values to w, using the given series key and field name.
-// If any call to w.Write fails, that error is returned.
-func (cmd *Command) writeValues(w io.Writer, seriesKey []byte, field string, values []tsm1.Value) error {
-	buf := []byte(string(seriesKey) + " " + field + "=")
-	prefixLen := len(buf)
-
-	for _, value := range values {
-		ts := value.UnixNano()
-		if (ts < cmd.startTime) || (ts > cmd.endTime) {
-			continue
-		}
-
-		// Re-slice buf to be "<series_key> <field>=".
-		buf = buf[:prefixLen]
-
-		// Append the correct representation of the value.
-		switch v := value.Value().(type) {
-		case float64:
-			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
-		case int64:
-			buf = strconv.AppendInt(buf, v, 10)
-			buf = append(buf, 'i')
-		case uint64:
-			buf = strconv.AppendUint(buf, v, 10)
-			buf = append(buf, 'u')
-		case bool:
-			buf = strconv.AppendBool(buf, v)
-		case string:
-			buf = append(buf, '"')
-			buf = append(buf, models.EscapeStringField(v)...)
-			buf = append(buf, '"')
-		default:
-			// This shouldn't be possible, but we'll format it anyway.
-			buf = append(buf, fmt.Sprintf("%v", v)...)
-		}
+// writeValues writes the values of a field of a series key between the start
+// and end times to w, if the series key matches the -measurement and -where
+// filters. If w fails, that error is returned.
+func (cmd *Command) writeValues(w valueWriter, seriesKey []byte, field string, values []tsm1.Value) error {
+	if cmd.filter != nil && !cmd.filter.Match(seriesKey) {
+		return nil
+	}
 
-		// Now buf has "<series_key> <field>=<value>".
-		// Append the timestamp and a newline, then write it.
-		buf = append(buf, ' ')
-		buf = strconv.AppendInt(buf, ts, 10)
-		buf = append(buf, '\n')
-		if _, err := w.Write(buf); err != nil {
-			// Underlying IO error needs to be returned.
-			return err
+	for i, value := range values {
+		if ts := value.UnixNano(); ts < cmd.startTime || ts > cmd.endTime {
+			// Copy the values in the time range, the values may belong to
+			// a WAL entry.
+			filtered := append(make([]tsm1.Value, 0, len(values)), values[:i]...)
+			for _, value := range values[i+1:] {
+				if ts := value.UnixNano(); ts >= cmd.startTime && ts <= cmd.endTime {
+					filtered = append(filtered, value)
+				}
+			}
+			values = filtered
+			break
 		}
 	}
-
-	return nil
+	if len(values) == 0 {
+		return nil
+	}
+	return w.writeValues(seriesKey, field, values)
 }
@@ -2,17 +2,26 @@ package export
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/apache/arrow/go/v7/arrow/memory"
+	"github.com/apache/arrow/go/v7/parquet/file"
+	"github.com/apache/arrow/go/v7/parquet/pqarrow"
 	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
 )
 
@@ -82,7 +91,7 @@ func Test_exportWALFile(t *testing.T) {
 		defer os.Remove(walFile.Name())
 
 		var out bytes.Buffer
-		if err := newCommand().exportWALFile(walFile.Name(), &out, func() {}); err != nil {
+		if err := newCommand().exportWALFile(walFile.Name(), &lineWriter{w: &out, mw: io.Discard}, func() {}); err != nil {
 			t.Fatal(err)
 		}
 
@@ -104,7 +113,7 @@ func Test_exportWALFile(t *testing.T) {
 
 	// Missing .wal file should not cause a failure.
 	var out bytes.Buffer
-	if err := newCommand().exportWALFile("file-that-does-not-exist.wal", &out, func() {}); err != nil {
+	if err := newCommand().exportWALFile("file-that-does-not-exist.wal", &lineWriter{w: &out, mw: io.Discard}, func() {}); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -121,7 +130,7 @@ func Test_exportTSMFile(t *testing.T) {
 		defer os.Remove(tsmFile.Name())
 
 		var out bytes.Buffer
-		if err := newCommand().exportTSMFile(tsmFile.Name(), &out); err != nil {
+		if err := newCommand().exportTSMFile(tsmFile.Name(), &lineWriter{w: &out, mw: io.Discard}); err != nil {
 			t.Fatal(err)
 		}
 
@@ -143,11 +152,172 @@ func Test_exportTSMFile(t *testing.T) {
 
 	// Missing .tsm file should not cause a failure.
 	var out bytes.Buffer
-	if err := newCommand().exportTSMFile("file-that-does-not-exist.tsm", &out); err != nil {
+	if err := newCommand().exportTSMFile("file-that-does-not-exist.tsm", &lineWriter{w: &out, mw: io.Discard}); err != nil {
 		t.Fatal(err)
 	}
 }
 
+// day is a day in nanoseconds.
+const day = int64(24 * time.Hour)
+
+// filterCorpus has series of two measurements over two days.
+var filterCorpus = corpus{
+	tsm1.SeriesFieldKey("cpu,host=a,region=us-east", "value"): []tsm1.Value{
+		tsm1.NewValue(1, float64(1)),
+		tsm1.NewValue(day+1, float64(2)),
+	},
+	tsm1.SeriesFieldKey("cpu,host=b,region=eu-west", "value"): []tsm1.Value{
+		tsm1.NewValue(2, float64(3)),
+	},
+	tsm1.SeriesFieldKey("cpu,host=c", "value"): []tsm1.Value{
+		tsm1.NewValue(3, float64(4)),
+	},
+	tsm1.SeriesFieldKey("mem,host=a", "free"): []tsm1.Value{
+		tsm1.NewValue(4, int64(5)),
+	},
+}
+
+// runExport exports the corpus as a shard of db0 with args and returns the
+// path of the output file.
+func runExport(t *testing.T, c corpus, args ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	shard := filepath.Join(dir, "data", "db0", "autogen", "1")
+	for _, d := range []string{shard, filepath.Join(dir, "wal")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f := writeCorpusToTSMFile(c)
+	defer os.Remove(f.Name())
+	buf, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(shard, "000000001-000000001.tsm"), buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "export")
+	cmd := NewCommand()
+	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+	args = append([]string{"-datadir", filepath.Join(dir, "data"), "-waldir", filepath.Join(dir, "wal"), "-out", out}, args...)
+	if err := cmd.Run(args...); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestCommand_Run_Filters(t *testing.T) {
+	out := runExport(t, filterCorpus, "-lponly", "-measurement", "cpu", "-where", "host != 'b' AND (region =~ /^us-/ OR region = '')")
+	buf, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := string(buf), fmt.Sprintf("cpu,host=a,region=us-east value=1 1\ncpu,host=a,region=us-east value=2 %d\ncpu,host=c value=4 3\n", day+1); got != exp {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", got, exp)
+	}
+}
+
+func TestCommand_Run_Precision(t *testing.T) {
+	out := runExport(t, corpus{
+		tsm1.SeriesFieldKey("cpu,host=a", "value"): []tsm1.Value{
+			tsm1.NewValue(-int64(time.Minute), float64(1)),
+			tsm1.NewValue(2*int64(time.Second), float64(2)),
+		},
+	}, "-lponly", "-precision", "s")
+	buf, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := string(buf), "cpu,host=a value=1 -60\ncpu,host=a value=2 2\n"; got != exp {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", got, exp)
+	}
+
+	// The timestamps are never truncated.
+	var lp bytes.Buffer
+	w := &lineWriter{w: &lp, mw: io.Discard, precision: "ms"}
+	if err := w.writeValues([]byte("cpu"), "value", []tsm1.Value{tsm1.NewValue(1, float64(1))}); !errors.Is(err, models.ErrPrecisionLoss) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCommand_Run_CSV_Chunk(t *testing.T) {
+	out := runExport(t, filterCorpus, "-format", "csv", "-chunk", "24h", "-compress", "-measurement", "cpu", "-where", "host = 'a'")
+	for _, c := range []struct {
+		start int64
+		exp   string
+	}{
+		{start: 0, exp: "database,retention_policy,measurement,tags,field,value,time\ndb0,autogen,cpu,\"host=a,region=us-east\",value,1,1\n"},
+		{start: day, exp: fmt.Sprintf("database,retention_policy,measurement,tags,field,value,time\ndb0,autogen,cpu,\"host=a,region=us-east\",value,2,%d\n", day+1)},
+	} {
+		f, err := os.Open(chunkPath(out, c.start))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, err := io.ReadAll(gz)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != c.exp {
+			t.Fatalf("unexpected output of %d:\n%s\nexpected:\n%s", c.start, buf, c.exp)
+		}
+	}
+}
+
+func TestCommand_Run_Parquet(t *testing.T) {
+	out := runExport(t, basicCorpus, "-format", "parquet")
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := file.NewParquetReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	fr, err := pqarrow.NewFileReader(r, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Release()
+
+	if got, exp := tbl.NumRows(), int64(len(basicCorpusExpLines)); got != exp {
+		t.Fatalf("unexpected rows: got=%d exp=%d", got, exp)
+	}
+	// The values are in the column of their type.
+	for i, exp := range map[int]int{5: 2, 6: 2, 7: 2, 8: 2, 9: 2} {
+		col := tbl.Column(i)
+		if got := col.Len() - col.NullN(); got != exp {
+			t.Errorf("unexpected values in %s: got=%d exp=%d", col.Name(), got, exp)
+		}
+	}
+}
+
+func TestChunkPath(t *testing.T) {
+	for _, c := range []struct {
+		path, exp string
+	}{
+		{path: "/tmp/export", exp: "/tmp/export-19700102T000000Z"},
+		{path: "/tmp/export.csv", exp: "/tmp/export-19700102T000000Z.csv"},
+		{path: "/tmp/export.lp.gz", exp: "/tmp/export-19700102T000000Z.lp.gz"},
+	} {
+		if got := chunkPath(c.path, day); got != c.exp {
+			t.Errorf("chunkPath(%q) = %q, expected %q", c.path, got, c.exp)
+		}
+	}
+}
+
 var sink interface{}
 
 func benchmarkExportTSM(c corpus, b *testing.B) {
@@ -162,7 +332,7 @@ func benchmarkExportTSM(c corpus, b *testing.B) {
 	b.ResetTimer()
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		if err := cmd.exportTSMFile(f.Name(), &out); err != nil {
+		if err := cmd.exportTSMFile(f.Name(), &lineWriter{w: &out, mw: io.Discard}); err != nil {
 			b.Fatal(err)
 		}
 
@@ -199,7 +369,7 @@ func benchmarkExportWAL(c corpus, b *testing.B) {
 	b.ResetTimer()
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		if err := cmd.exportWALFile(f.Name(), &out, func() {}); err != nil {
+		if err := cmd.exportWALFile(f.Name(), &lineWriter{w: &out, mw: io.Discard}, func() {}); err != nil {
 			b.Fatal(err)
 		}
 
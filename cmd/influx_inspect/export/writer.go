@@ -0,0 +1,498 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v7/arrow"
+	"github.com/apache/arrow/go/v7/arrow/array"
+	"github.com/apache/arrow/go/v7/arrow/memory"
+	"github.com/apache/arrow/go/v7/parquet"
+	"github.com/apache/arrow/go/v7/parquet/compress"
+	"github.com/apache/arrow/go/v7/parquet/pqarrow"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/escape"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+// The output formats.
+const (
+	formatLine    = "line"
+	formatCSV     = "csv"
+	formatParquet = "parquet"
+)
+
+// A valueWriter writes the values of the fields of series in a format.
+type valueWriter interface {
+	// comment writes an informational comment, if the format has them.
+	comment(s string)
+	// setContext sets the database and retention policy of the next values.
+	setContext(database, retentionPolicy string) error
+	// writeValues writes the values of a field of a series key. The field
+	// isn't escaped.
+	writeValues(seriesKey []byte, field string, values []tsm1.Value) error
+	Close() error
+}
+
+// create creates the output file at path, or uses standard out, with the
+// values between start and end.
+func (cmd *Command) create(path string, start, end int64) (valueWriter, error) {
+	fw := &fileWriter{}
+	var w io.Writer
+	if path == stdoutMark {
+		w = cmd.Stdout
+	} else {
+		// open our output file and create an output buffer
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		fw.f = f
+		w = f
+	}
+	// Because calling (*os.File).Write is relatively expensive,
+	// and we don't *need* to sync to disk on every written line of export,
+	// use a sized buffered writer so that we only sync the file every megabyte.
+	fw.bw = bufio.NewWriterSize(w, 1024*1024)
+	w = fw.bw
+
+	// The parquet files are compressed by their own codec.
+	if cmd.compress && cmd.format != formatParquet {
+		fw.gz = gzip.NewWriter(w)
+		w = fw.gz
+	}
+
+	switch cmd.format {
+	case formatCSV:
+		fw.valueWriter = newCSVWriter(w)
+	case formatParquet:
+		pw, err := newParquetWriter(w, cmd.compress)
+		if err != nil {
+			fw.Close()
+			return nil, err
+		}
+		fw.valueWriter = pw
+	default:
+		// mw is our "meta writer" -- the io.Writer to which meta/out-of-band data
+		// like comments will be sent.  If the lponly flag is set, mw will be
+		// io.Discard which effectively filters out comments and any other
+		// non-line protocol data.
+		//
+		// Otherwise, mw is set to the same writer as the actual DDL and line
+		// protocol DML which will cause the comments to be intermixed with the
+		// data..
+		//
+		mw := w
+		if cmd.lponly {
+			mw = io.Discard
+		}
+		if err := cmd.writeFull(mw, w, start, end); err != nil {
+			fw.Close()
+			return nil, err
+		}
+		fw.valueWriter = &lineWriter{w: w, mw: mw, precision: cmd.precision}
+	}
+	return fw, nil
+}
+
+// fileWriter closes the writers of an output file after its valueWriter.
+type fileWriter struct {
+	valueWriter
+	f  *os.File
+	bw *bufio.Writer
+	gz *gzip.Writer
+}
+
+func (w *fileWriter) Close() error {
+	var err error
+	if w.valueWriter != nil {
+		err = w.valueWriter.Close()
+	}
+	if w.gz != nil {
+		if e := w.gz.Close(); err == nil {
+			err = e
+		}
+	}
+	if e := w.bw.Flush(); err == nil {
+		err = e
+	}
+	if w.f != nil {
+		if e := w.f.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// lineWriter writes the values as line protocol to w, and the comments to mw.
+// The timestamps are in precision, or in nanoseconds if it's empty.
+type lineWriter struct {
+	w         io.Writer
+	mw        io.Writer
+	precision string
+}
+
+func (w *lineWriter) comment(s string) {
+	fmt.Fprintln(w.mw, s)
+}
+
+func (w *lineWriter) setContext(database, retentionPolicy string) error {
+	fmt.Fprintf(w.mw, "# CONTEXT-DATABASE:%s\n", database)
+	fmt.Fprintf(w.mw, "# CONTEXT-RETENTION-POLICY:%s\n", retentionPolicy)
+	return nil
+}
+
+// writeValues writes every value in values to w, using the given series key and field name.
+// If any call to w.Write fails, that error is returned. A timestamp that the precision
+// would truncate is an error as well.
+func (w *lineWriter) writeValues(seriesKey []byte, field string, values []tsm1.Value) error {
+	buf := []byte(string(seriesKey) + " " + escape.String(field) + "=")
+	prefixLen := len(buf)
+
+	for _, value := range values {
+		// Re-slice buf to be "<series_key> <field>=".
+		buf = buf[:prefixLen]
+
+		// Append the correct representation of the value.
+		switch v := value.Value().(type) {
+		case float64:
+			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+		case int64:
+			buf = strconv.AppendInt(buf, v, 10)
+			buf = append(buf, 'i')
+		case uint64:
+			buf = strconv.AppendUint(buf, v, 10)
+			buf = append(buf, 'u')
+		case bool:
+			buf = strconv.AppendBool(buf, v)
+		case string:
+			buf = append(buf, '"')
+			buf = append(buf, models.EscapeStringField(v)...)
+			buf = append(buf, '"')
+		default:
+			// This shouldn't be possible, but we'll format it anyway.
+			buf = append(buf, fmt.Sprintf("%v", v)...)
+		}
+
+		// Now buf has "<series_key> <field>=<value>".
+		// Append the timestamp and a newline, then write it.
+		buf = append(buf, ' ')
+		var err error
+		if buf, err = models.AppendTimestamp(buf, value.UnixNano(), w.precision); err != nil {
+			return fmt.Errorf("%s %s at %d: %w", seriesKey, field, value.UnixNano(), err)
+		}
+		buf = append(buf, '\n')
+		if _, err := w.w.Write(buf); err != nil {
+			// Underlying IO error needs to be returned.
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *lineWriter) Close() error { return nil }
+
+// seriesContext holds the context of the values of the tabular formats, and
+// the measurement and the tags of their last series key.
+type seriesContext struct {
+	database        string
+	retentionPolicy string
+
+	seriesKey   string
+	measurement string
+	tags        string // key=value pairs separated by commas
+}
+
+func (c *seriesContext) setContext(database, retentionPolicy string) error {
+	c.database, c.retentionPolicy = database, retentionPolicy
+	return nil
+}
+
+// setSeries parses a series key if it's not the last one.
+func (c *seriesContext) setSeries(seriesKey []byte) {
+	if string(seriesKey) == c.seriesKey {
+		return
+	}
+	c.seriesKey = string(seriesKey)
+	name, tags := models.ParseKeyBytes(seriesKey)
+	c.measurement = string(name)
+	pairs := make([]string, len(tags))
+	for i, t := range tags {
+		pairs[i] = string(t.Key) + "=" + string(t.Value)
+	}
+	c.tags = strings.Join(pairs, ",")
+}
+
+// csvColumns are the columns of the csv format, a row per value.
+var csvColumns = []string{"database", "retention_policy", "measurement", "tags", "field", "value", "time"}
+
+// csvWriter writes the values as csv, with the time in nanoseconds.
+type csvWriter struct {
+	seriesContext
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	cw.w.Write(csvColumns)
+	return cw
+}
+
+func (w *csvWriter) comment(string) {}
+
+func (w *csvWriter) writeValues(seriesKey []byte, field string, values []tsm1.Value) error {
+	w.setSeries(seriesKey)
+	record := []string{w.database, w.retentionPolicy, w.measurement, w.tags, field, "", ""}
+	for _, value := range values {
+		switch v := value.Value().(type) {
+		case float64:
+			record[5] = strconv.FormatFloat(v, 'g', -1, 64)
+		case int64:
+			record[5] = strconv.FormatInt(v, 10)
+		case uint64:
+			record[5] = strconv.FormatUint(v, 10)
+		case bool:
+			record[5] = strconv.FormatBool(v)
+		case string:
+			record[5] = v
+		default:
+			record[5] = fmt.Sprintf("%v", v)
+		}
+		record[6] = strconv.FormatInt(value.UnixNano(), 10)
+		if err := w.w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// parquetBatchSize is the number of values of a row group of the parquet
+// files.
+const parquetBatchSize = 64 * 1024
+
+// parquetSchema is the schema of the parquet format, a row per value. The
+// value is in the column of its type, the other ones are null.
+var parquetSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "database", Type: arrow.BinaryTypes.String},
+	{Name: "retention_policy", Type: arrow.BinaryTypes.String},
+	{Name: "measurement", Type: arrow.BinaryTypes.String},
+	{Name: "tags", Type: arrow.BinaryTypes.String},
+	{Name: "field", Type: arrow.BinaryTypes.String},
+	{Name: "value_float", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	{Name: "value_integer", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	{Name: "value_unsigned", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+	{Name: "value_boolean", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	{Name: "value_string", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}},
+}, nil)
+
+// parquetWriter writes the values as parquet, in row groups of
+// parquetBatchSize values.
+type parquetWriter struct {
+	seriesContext
+	fw *pqarrow.FileWriter
+	b  *array.RecordBuilder
+	n  int
+}
+
+func newParquetWriter(w io.Writer, gzip bool) (*parquetWriter, error) {
+	codec := compress.Codecs.Snappy
+	if gzip {
+		codec = compress.Codecs.Gzip
+	}
+	props := parquet.NewWriterProperties(parquet.WithCompression(codec))
+	fw, err := pqarrow.NewFileWriter(parquetSchema, w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, err
+	}
+	return &parquetWriter{fw: fw, b: array.NewRecordBuilder(memory.DefaultAllocator, parquetSchema)}, nil
+}
+
+func (w *parquetWriter) comment(string) {}
+
+func (w *parquetWriter) writeValues(seriesKey []byte, field string, values []tsm1.Value) error {
+	w.setSeries(seriesKey)
+	for _, value := range values {
+		w.b.Field(0).(*array.StringBuilder).Append(w.database)
+		w.b.Field(1).(*array.StringBuilder).Append(w.retentionPolicy)
+		w.b.Field(2).(*array.StringBuilder).Append(w.measurement)
+		w.b.Field(3).(*array.StringBuilder).Append(w.tags)
+		w.b.Field(4).(*array.StringBuilder).Append(field)
+
+		// The value goes in the column of its type, the others are null.
+		col := 9
+		switch v := value.Value().(type) {
+		case float64:
+			col = 5
+			w.b.Field(col).(*array.Float64Builder).Append(v)
+		case int64:
+			col = 6
+			w.b.Field(col).(*array.Int64Builder).Append(v)
+		case uint64:
+			col = 7
+			w.b.Field(col).(*array.Uint64Builder).Append(v)
+		case bool:
+			col = 8
+			w.b.Field(col).(*array.BooleanBuilder).Append(v)
+		case string:
+			w.b.Field(col).(*array.StringBuilder).Append(v)
+		default:
+			w.b.Field(col).(*array.StringBuilder).Append(fmt.Sprintf("%v", v))
+		}
+		for i := 5; i <= 9; i++ {
+			if i != col {
+				w.b.Field(i).AppendNull()
+			}
+		}
+		w.b.Field(10).(*array.TimestampBuilder).Append(arrow.Timestamp(value.UnixNano()))
+
+		if w.n++; w.n == parquetBatchSize {
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flush writes the buffered values as a row group.
+func (w *parquetWriter) flush() error {
+	rec := w.b.NewRecord()
+	defer rec.Release()
+	w.n = 0
+	return w.fw.Write(rec)
+}
+
+func (w *parquetWriter) Close() error {
+	defer w.b.Release()
+	var err error
+	if w.n > 0 {
+		err = w.flush()
+	}
+	if e := w.fw.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// chunkedWriter writes the values to an output file per window of time of
+// d, created when it receives its first value. All the files are kept open
+// until it's closed.
+type chunkedWriter struct {
+	cmd     *Command
+	d       int64
+	writers map[int64]*contextWriter
+
+	database        string
+	retentionPolicy string
+}
+
+// contextWriter is a valueWriter with its current context.
+type contextWriter struct {
+	valueWriter
+	database        string
+	retentionPolicy string
+}
+
+func newChunkedWriter(cmd *Command, d time.Duration) *chunkedWriter {
+	return &chunkedWriter{cmd: cmd, d: int64(d), writers: make(map[int64]*contextWriter)}
+}
+
+// The comments would have to be written to all the files.
+func (w *chunkedWriter) comment(string) {}
+
+func (w *chunkedWriter) setContext(database, retentionPolicy string) error {
+	w.database, w.retentionPolicy = database, retentionPolicy
+	return nil
+}
+
+func (w *chunkedWriter) writeValues(seriesKey []byte, field string, values []tsm1.Value) error {
+	for len(values) > 0 {
+		start := w.window(values[0].UnixNano())
+		n := 1
+		for n < len(values) && w.window(values[n].UnixNano()) == start {
+			n++
+		}
+
+		cw, err := w.writer(start)
+		if err != nil {
+			return err
+		}
+		if err := cw.writeValues(seriesKey, field, values[:n]); err != nil {
+			return err
+		}
+		values = values[n:]
+	}
+	return nil
+}
+
+// window returns the start of the window of a timestamp.
+func (w *chunkedWriter) window(ts int64) int64 {
+	start := ts - ts%w.d
+	if ts < 0 && ts%w.d != 0 {
+		start -= w.d
+	}
+	return start
+}
+
+// writer returns the writer of the window starting at start, in the current
+// context.
+func (w *chunkedWriter) writer(start int64) (*contextWriter, error) {
+	cw, ok := w.writers[start]
+	if !ok {
+		end := start + w.d - 1
+		if end > w.cmd.endTime || end < start {
+			end = w.cmd.endTime
+		}
+		vw, err := w.cmd.create(chunkPath(w.cmd.out, start), start, end)
+		if err != nil {
+			return nil, err
+		}
+		cw = &contextWriter{valueWriter: vw}
+		w.writers[start] = cw
+	}
+	if cw.database != w.database || cw.retentionPolicy != w.retentionPolicy {
+		if err := cw.setContext(w.database, w.retentionPolicy); err != nil {
+			return nil, err
+		}
+		cw.database, cw.retentionPolicy = w.database, w.retentionPolicy
+	}
+	return cw, nil
+}
+
+func (w *chunkedWriter) Close() error {
+	var err error
+	for _, cw := range w.writers {
+		if e := cw.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// chunkPath returns the path of the output file of the window starting at
+// start: the time is inserted before the extensions of path, such as
+// export-20240101T000000Z.csv.gz for export.csv.gz.
+func chunkPath(path string, start int64) string {
+	dir, base := filepath.Split(path)
+	ext := filepath.Ext(base)
+	if ext == ".gz" {
+		ext = filepath.Ext(strings.TrimSuffix(base, ext)) + ext
+	}
+	base = strings.TrimSuffix(base, ext)
+	return filepath.Join(dir, base+"-"+time.Unix(0, start).UTC().Format("20060102T150405Z")+ext)
+}
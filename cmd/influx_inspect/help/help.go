@@ -32,17 +32,23 @@ Usage: influx_inspect [[command] [arguments]]
 The commands are:
 
     check-schema         check for conflicts in the types between shards
-    deletetsm            bulk measurement deletion of raw tsm file
+    deletetsm            bulk deletion of raw tsm file data by measurement, tags and time
     dumptsi              dumps low-level details about tsi1 files
     dumptsm              dumps low-level details about tsm1 files
+    dumptsmwal           dumps all data from a WAL file as text or JSON
     export               exports raw data from a shard to line protocol
     buildtsi             generates tsi1 indexes from tsm1 data
     help                 display this help message
     merge-schema         merge a set of schema files from the check-schema command
+    merge-shards         merges adjacent shards into a single shard offline
+    replaywal            replays WAL segments into a shard or to line protocol
     report               displays a shard level cardinality report
     report-db            estimates cloud 2 cardinality for a database
+    report-cardinality   reports exact series cardinality by measurement, tag key and tag value
     report-disk          displays a shard level disk usage report
     reporttsi            reports series cardinality in one or more TSI indexes.
+    reshard              rewrites the shards of a retention policy for a new shard duration offline
+    split-shard          splits a shard by time or series hash offline
     verify               verifies integrity of TSM files
     verify-seriesfile    verifies integrity of the Series file
 
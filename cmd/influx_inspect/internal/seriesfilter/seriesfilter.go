@@ -0,0 +1,103 @@
+// Package seriesfilter matches the series keys of TSM and WAL files against
+// the measurements and the tag predicates given to the influx_inspect tools.
+package seriesfilter
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxql"
+)
+
+// Filter matches series keys by measurement and by tag predicate. The result
+// of the last series key is kept, since the keys of the fields of a series
+// are next to each other.
+type Filter struct {
+	measurements map[string]bool
+	where        influxql.Expr
+	whereTags    []string // tag keys of where
+
+	lastSeriesKey string
+	lastMatch     bool
+}
+
+// New returns a filter of the series of measurements that match where. A nil
+// measurements or where matches all the series.
+func New(measurements map[string]bool, where influxql.Expr) *Filter {
+	f := &Filter{measurements: measurements, where: where}
+	if where != nil {
+		influxql.WalkFunc(where, func(n influxql.Node) {
+			if ref, ok := n.(*influxql.VarRef); ok {
+				f.whereTags = append(f.whereTags, ref.Val)
+			}
+		})
+	}
+	return f
+}
+
+// Match returns whether a series key matches the filter.
+func (f *Filter) Match(seriesKey []byte) bool {
+	if f.measurements == nil && f.where == nil {
+		return true
+	} else if string(seriesKey) == f.lastSeriesKey {
+		return f.lastMatch
+	}
+	f.lastSeriesKey = string(seriesKey)
+
+	name, tags := models.ParseKeyBytes(seriesKey)
+	f.lastMatch = f.measurements == nil || f.measurements[string(name)]
+	if f.lastMatch && f.where != nil {
+		// The missing tags are empty, like in the queries.
+		m := make(map[string]interface{}, len(f.whereTags))
+		for _, k := range f.whereTags {
+			m[k] = ""
+		}
+		for _, t := range tags {
+			m[string(t.Key)] = string(t.Value)
+		}
+		f.lastMatch = influxql.EvalBool(f.where, m)
+	}
+	return f.lastMatch
+}
+
+// ParseWhere parses the tag predicates of -where: comparisons of tags with
+// strings or regular expressions, combined with AND and OR.
+func ParseWhere(where string) (influxql.Expr, error) {
+	expr, err := influxql.ParseExpr(where)
+	if err != nil {
+		return nil, fmt.Errorf("invalid where: %s", err)
+	}
+	var check func(expr influxql.Expr) error
+	check = func(expr influxql.Expr) error {
+		switch expr := expr.(type) {
+		case *influxql.ParenExpr:
+			return check(expr.Expr)
+		case *influxql.BinaryExpr:
+			switch expr.Op {
+			case influxql.AND, influxql.OR:
+				if err := check(expr.LHS); err != nil {
+					return err
+				}
+				return check(expr.RHS)
+			case influxql.EQ, influxql.NEQ, influxql.EQREGEX, influxql.NEQREGEX:
+				if _, ok := expr.LHS.(*influxql.VarRef); !ok {
+					return fmt.Errorf("invalid where: expected a tag key on the left of %s", expr)
+				}
+				switch expr.RHS.(type) {
+				case *influxql.StringLiteral, *influxql.RegexLiteral:
+					return nil
+				default:
+					return fmt.Errorf("invalid where: expected a string or a regular expression on the right of %s", expr)
+				}
+			default:
+				return fmt.Errorf("invalid where: unsupported operator %s in %s", expr.Op, expr)
+			}
+		default:
+			return fmt.Errorf("invalid where: expected tag predicates, got %s", expr)
+		}
+	}
+	if err := check(expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
@@ -0,0 +1,42 @@
+package seriesfilter_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/internal/seriesfilter"
+)
+
+func TestFilter_Match(t *testing.T) {
+	where, err := seriesfilter.ParseWhere("host != 'b' AND (region =~ /^us-/ OR region = '')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := seriesfilter.New(map[string]bool{"cpu": true}, where)
+	for _, tt := range []struct {
+		key   string
+		match bool
+	}{
+		{"cpu,host=a,region=us-east", true},
+		{"cpu,host=a,region=us-east", true},
+		{"cpu,host=b,region=us-east", false},
+		{"cpu,host=c,region=eu-west", false},
+		{"cpu,host=c", true},
+		{"mem,host=a", false},
+	} {
+		if got := f.Match([]byte(tt.key)); got != tt.match {
+			t.Errorf("%s: got %v, exp %v", tt.key, got, tt.match)
+		}
+	}
+
+	if !seriesfilter.New(nil, nil).Match([]byte("mem,host=a")) {
+		t.Error("expected an empty filter to match all the series")
+	}
+}
+
+func TestParseWhere(t *testing.T) {
+	for _, where := range []string{"value > 1", "host = 1", "host", "1 = host"} {
+		if _, err := seriesfilter.ParseWhere(where); err == nil {
+			t.Errorf("expected an error for %q", where)
+		}
+	}
+}
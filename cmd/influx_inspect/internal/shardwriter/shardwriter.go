@@ -0,0 +1,103 @@
+// Package shardwriter writes the TSM files of the shards that the
+// influx_inspect tools create from existing shards.
+package shardwriter
+
+import (
+	"errors"
+	"os"
+
+	"github.com/influxdata/influxdb/pkg/file"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+// IndexBatchSize is the number of series written at a time to the TSI index
+// of a shard.
+const IndexBatchSize = 10000
+
+// Writer writes a TSM file to each of several shards. The file of a shard is
+// created on its first values, at the path returned by path.
+type Writer struct {
+	path    func(id uint64) string
+	paths   map[uint64]string
+	writers map[uint64]tsm1.TSMWriter
+}
+
+// New returns a Writer of the TSM files at the paths returned by path. It
+// is called once per shard.
+func New(path func(id uint64) string) *Writer {
+	return &Writer{
+		path:    path,
+		paths:   make(map[uint64]string),
+		writers: make(map[uint64]tsm1.TSMWriter),
+	}
+}
+
+// writer returns the writer of a shard.
+func (w *Writer) writer(id uint64) (tsm1.TSMWriter, error) {
+	if tw, ok := w.writers[id]; ok {
+		return tw, nil
+	}
+	path := w.path(id)
+	f, err := os.Create(path + "." + tsm1.TmpTSMFileExtension)
+	if err != nil {
+		return nil, err
+	}
+	tw, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.paths[id] = path
+	w.writers[id] = tw
+	return tw, nil
+}
+
+// WriteBlock writes an encoded block of a key to the file of a shard.
+func (w *Writer) WriteBlock(id uint64, key []byte, minTime, maxTime int64, block []byte) error {
+	tw, err := w.writer(id)
+	if err != nil {
+		return err
+	}
+	return tw.WriteBlock(key, minTime, maxTime, block)
+}
+
+// Write writes the values of a key to the file of a shard.
+func (w *Writer) Write(id uint64, key []byte, values tsm1.Values) error {
+	tw, err := w.writer(id)
+	if err != nil {
+		return err
+	}
+	return tw.Write(key, values)
+}
+
+// Close completes the TSM files of the shards. The file of a shard without
+// values, such as when all of them were tombstoned, is removed.
+func (w *Writer) Close() error {
+	for id, tw := range w.writers {
+		tmpPath := w.paths[id] + "." + tsm1.TmpTSMFileExtension
+		err := tw.WriteIndex()
+		if err != nil && !errors.Is(err, tsm1.ErrNoValues) {
+			return err
+		} else if err := tw.Close(); err != nil {
+			return err
+		}
+		delete(w.writers, id)
+		if errors.Is(err, tsm1.ErrNoValues) {
+			if err := os.Remove(tmpPath); err != nil {
+				return err
+			}
+		} else if err := file.RenameFile(tmpPath, w.paths[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove removes the TSM files of the shards which weren't completed.
+func (w *Writer) Remove() {
+	for id, tw := range w.writers {
+		tw.Close()
+		tw.Remove()
+		os.Remove(w.paths[id] + "." + tsm1.TmpTSMFileExtension)
+	}
+}
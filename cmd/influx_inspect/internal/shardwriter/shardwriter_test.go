@@ -0,0 +1,49 @@
+package shardwriter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/internal/shardwriter"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+func TestWriter(t *testing.T) {
+	dir := t.TempDir()
+	w := shardwriter.New(func(id uint64) string {
+		return filepath.Join(dir, tsm1.DefaultFormatFileName(int(id), 1)+"."+tsm1.TSMFileExtension)
+	})
+	if err := w.Write(1, []byte("cpu#!~#value"), tsm1.Values{tsm1.NewValue(1, 1.0)}); err != nil {
+		t.Fatal(err)
+	}
+	// The values of shard 2 were all tombstoned.
+	if err := w.Write(2, []byte("cpu#!~#value"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := filepath.Join(dir, tsm1.DefaultFormatFileName(1, 1)+"."+tsm1.TSMFileExtension); len(paths) != 1 || paths[0] != exp {
+		t.Fatalf("unexpected files: %v", paths)
+	}
+
+	f, err := os.Open(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if values, err := r.ReadAll([]byte("cpu#!~#value")); err != nil {
+		t.Fatal(err)
+	} else if len(values) != 1 || values[0].Value() != 1.0 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
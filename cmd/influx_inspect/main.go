@@ -16,9 +16,14 @@ import (
 	"github.com/influxdata/influxdb/cmd/influx_inspect/dumptsmwal"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/export"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/help"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/mergeshards"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/replaywal"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/report"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/reportcardinality"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/reportdisk"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/reporttsi"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/reshard"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/splitshard"
 	typecheck "github.com/influxdata/influxdb/cmd/influx_inspect/type_conflicts"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/verify/seriesfile"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/verify/tombstone"
@@ -101,11 +106,26 @@ func (m *Main) Run(args ...string) error {
 		if err := name.Run(args...); err != nil {
 			return fmt.Errorf("buildtsi: %w", err)
 		}
+	case "merge-shards":
+		name := mergeshards.NewCommand()
+		if err := name.Run(args...); err != nil {
+			return fmt.Errorf("merge-shards: %w", err)
+		}
+	case "replaywal":
+		name := replaywal.NewCommand()
+		if err := name.Run(args...); err != nil {
+			return fmt.Errorf("replaywal: %w", err)
+		}
 	case "report":
 		name := report.NewCommand()
 		if err := name.Run(args...); err != nil {
 			return fmt.Errorf("report: %w", err)
 		}
+	case "report-cardinality":
+		name := reportcardinality.NewCommand()
+		if err := name.Run(args...); err != nil {
+			return fmt.Errorf("report-cardinality: %w", err)
+		}
 	case "report-disk":
 		name := reportdisk.NewCommand()
 		if err := name.Run(args...); err != nil {
@@ -116,6 +136,16 @@ func (m *Main) Run(args ...string) error {
 		if err := name.Run(args...); err != nil {
 			return fmt.Errorf("reporttsi: %w", err)
 		}
+	case "reshard":
+		name := reshard.NewCommand()
+		if err := name.Run(args...); err != nil {
+			return fmt.Errorf("reshard: %w", err)
+		}
+	case "split-shard":
+		name := splitshard.NewCommand()
+		if err := name.Run(args...); err != nil {
+			return fmt.Errorf("split-shard: %w", err)
+		}
 	case "check-schema":
 		name := typecheck.NewTypeConflictCheckerCommand()
 		if err := name.Run(args...); err != nil {
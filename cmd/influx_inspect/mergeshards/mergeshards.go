@@ -0,0 +1,421 @@
+// Package mergeshards merges adjacent shards of a retention policy into a
+// single shard offline.
+package mergeshards
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/buildtsi"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/internal/shardwriter"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+	"go.uber.org/zap"
+)
+
+// Command represents the program execution for "influx_inspect merge-shards".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stderr io.Writer
+	Stdout io.Writer
+
+	dbPath    string
+	walPath   string // optional. The WAL of the shards isn't merged if empty
+	metaDir   string // optional. The meta changes aren't checked if empty
+	backupDir string
+	rp        string
+	shards    []uint64
+	target    uint64
+
+	logger *zap.Logger
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	var shards string
+	var target int64
+	fs := flag.NewFlagSet("merge-shards", flag.ExitOnError)
+	fs.StringVar(&cmd.dbPath, "db-path", "", "Path to the database. Required.")
+	fs.StringVar(&cmd.walPath, "wal-path", "", "Optional path to the WAL of the database, to merge the data of the WAL of the shards")
+	fs.StringVar(&cmd.metaDir, "meta-dir", "", "Optional path to the meta directory, to check the shard groups are adjacent")
+	fs.StringVar(&cmd.backupDir, "backup-dir", "", "Directory to move the merged shards to. Required.")
+	fs.StringVar(&cmd.rp, "retention", "", "Retention policy of the shards. Required.")
+	fs.StringVar(&shards, "shards", "", "Comma-separated IDs of the shards to merge. Required.")
+	fs.Int64Var(&target, "target", -1, "Optional ID of the merged shard, one of -shards. Defaults to the lowest ID")
+	fs.SetOutput(cmd.Stdout)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.dbPath == "" || cmd.rp == "" || cmd.backupDir == "" {
+		return errors.New("-db-path, -retention and -backup-dir are required")
+	}
+	for _, s := range strings.Split(shards, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid shard ID %q", s)
+		}
+		cmd.shards = append(cmd.shards, id)
+	}
+	if len(cmd.shards) < 2 {
+		return errors.New("-shards must list at least two shards")
+	}
+	sort.Slice(cmd.shards, func(i, j int) bool { return cmd.shards[i] < cmd.shards[j] })
+	for i := 1; i < len(cmd.shards); i++ {
+		if cmd.shards[i] == cmd.shards[i-1] {
+			return fmt.Errorf("shard %d is listed twice", cmd.shards[i])
+		}
+	}
+
+	cmd.target = cmd.shards[0]
+	if target >= 0 {
+		cmd.target = uint64(target)
+		if i := sort.Search(len(cmd.shards), func(i int) bool { return cmd.shards[i] >= cmd.target }); i == len(cmd.shards) || cmd.shards[i] != cmd.target {
+			return fmt.Errorf("target shard %d isn't one of -shards", cmd.target)
+		}
+	}
+	cmd.logger = logger.New(cmd.Stderr)
+
+	return cmd.run()
+}
+
+func (cmd *Command) run() error {
+	var groups []meta.ShardGroupInfo
+	if cmd.metaDir != "" {
+		var err error
+		if groups, err = cmd.shardGroups(); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range cmd.shards {
+		if fi, err := os.Stat(cmd.shardPath(id)); err != nil {
+			return err
+		} else if !fi.IsDir() {
+			return fmt.Errorf("%s isn't a shard directory", cmd.shardPath(id))
+		}
+		if _, err := os.Stat(filepath.Join(cmd.backupDir, cmd.rp, strconv.FormatUint(id, 10))); !os.IsNotExist(err) {
+			return fmt.Errorf("shard %d already has a backup in %s", id, cmd.backupDir)
+		}
+	}
+
+	// The merged shard is built next to the others and swapped in once
+	// complete.
+	tmpPath := cmd.shardPath(cmd.target) + ".merging"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	} else if err := os.MkdirAll(tmpPath, 0777); err != nil {
+		return err
+	}
+
+	min, max, err := cmd.mergeTSM(tmpPath)
+	if err != nil {
+		return err
+	}
+	if err := cmd.buildIndex(tmpPath); err != nil {
+		return err
+	}
+	if err := cmd.swap(tmpPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Stdout, "Merged shards %s into shard %d, originals in %s\n", cmd.shardList(cmd.shards), cmd.target, cmd.backupDir)
+	cmd.printMetaChanges(groups, min, max)
+	return nil
+}
+
+// shardPath returns the path to the directory of a shard.
+func (cmd *Command) shardPath(id uint64) string {
+	return filepath.Join(cmd.dbPath, cmd.rp, strconv.FormatUint(id, 10))
+}
+
+// shardGroups returns the shard groups of the shards, sorted by time, and
+// checks they are adjacent and hold no other shard.
+func (cmd *Command) shardGroups() ([]meta.ShardGroupInfo, error) {
+	buf, err := os.ReadFile(filepath.Join(cmd.metaDir, "meta.db"))
+	if err != nil {
+		return nil, err
+	}
+	var data meta.Data
+	if err := data.UnmarshalBinary(buf); err != nil {
+		return nil, fmt.Errorf("cannot read meta data: %w", err)
+	}
+	all, err := data.ShardGroups(filepath.Base(cmd.dbPath), cmd.rp)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[uint64]bool, len(cmd.shards))
+	for _, id := range cmd.shards {
+		merged[id] = true
+	}
+	var groups []meta.ShardGroupInfo
+	for _, g := range all {
+		var n int
+		for _, sh := range g.Shards {
+			if merged[sh.ID] {
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		} else if n != len(g.Shards) {
+			return nil, fmt.Errorf("shard group %d holds shards which aren't merged", g.ID)
+		}
+		groups = append(groups, g)
+	}
+	var n int
+	for _, g := range groups {
+		n += len(g.Shards)
+	}
+	if n != len(cmd.shards) {
+		return nil, fmt.Errorf("shards %s aren't all in the meta data of %s", cmd.shardList(cmd.shards), cmd.rp)
+	}
+
+	sort.Sort(meta.ShardGroupInfos(groups))
+	for i := 1; i < len(groups); i++ {
+		if !groups[i].StartTime.Equal(groups[i-1].EndTime) {
+			return nil, fmt.Errorf("shard groups %d and %d aren't adjacent", groups[i-1].ID, groups[i].ID)
+		}
+	}
+	return groups, nil
+}
+
+// mergeTSM compacts the TSM files and the WAL of the shards into the TSM
+// files of dir, and returns the time range of their data.
+func (cmd *Command) mergeTSM(dir string) (min, max int64, err error) {
+	store := &fileStore{}
+	var paths []string
+	for _, id := range cmd.shards {
+		tsmPaths, err := filepath.Glob(filepath.Join(cmd.shardPath(id), "*."+tsm1.TSMFileExtension))
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, path := range tsmPaths {
+			gen, _, err := tsm1.DefaultParseFileName(path)
+			if err != nil {
+				return 0, 0, err
+			} else if int64(gen) > store.gen.Load() {
+				store.gen.Store(int64(gen))
+			}
+		}
+		paths = append(paths, tsmPaths...)
+	}
+	defer store.close()
+
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = store
+	compactor.Open()
+	defer compactor.Close()
+
+	// The data of the WAL of each shard is snapshotted, newer than the data
+	// of the TSM files.
+	var snapshots []string
+	if cmd.walPath != "" {
+		for _, id := range cmd.shards {
+			walPaths, err := filepath.Glob(filepath.Join(cmd.walPath, cmd.rp, strconv.FormatUint(id, 10), "*."+tsm1.WALFileExtension))
+			if err != nil {
+				return 0, 0, err
+			} else if len(walPaths) == 0 {
+				continue
+			}
+			cache := tsm1.NewCache(0)
+			loader := tsm1.NewCacheLoader(walPaths)
+			loader.WithLogger(cmd.logger)
+			if err := loader.Load(cache); err != nil {
+				return 0, 0, err
+			}
+			files, err := compactor.WriteSnapshot(cache, cmd.logger)
+			if err != nil {
+				return 0, 0, err
+			}
+			for _, f := range files {
+				path := strings.TrimSuffix(f, "."+tsm1.TmpTSMFileExtension)
+				if err := os.Rename(f, path); err != nil {
+					return 0, 0, err
+				}
+				snapshots = append(snapshots, path)
+			}
+		}
+		paths = append(paths, snapshots...)
+	}
+	if len(paths) == 0 {
+		return 0, 0, errors.New("no TSM files to merge")
+	}
+
+	fmt.Fprintf(cmd.Stdout, "Compacting %d TSM files\n", len(paths))
+	files, err := compactor.CompactFull(paths, cmd.logger)
+	if err != nil {
+		return 0, 0, err
+	}
+	store.close()
+	for _, path := range snapshots {
+		if err := os.Remove(path); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	min, max = math.MaxInt64, math.MinInt64
+	for _, f := range files {
+		path := strings.TrimSuffix(f, "."+tsm1.TmpTSMFileExtension)
+		if err := os.Rename(f, path); err != nil {
+			return 0, 0, err
+		}
+		r, err := store.TSMReader(path)
+		if err != nil {
+			return 0, 0, err
+		}
+		tmin, tmax := r.TimeRange()
+		r.Unref()
+		if tmin < min {
+			min = tmin
+		}
+		if tmax > max {
+			max = tmax
+		}
+	}
+	return min, max, nil
+}
+
+// buildIndex builds the TSI index of the shard in dir.
+func (cmd *Command) buildIndex(dir string) error {
+	sfile := tsdb.NewSeriesFile(filepath.Join(cmd.dbPath, tsdb.SeriesFileDirectory))
+	sfile.Logger = cmd.logger
+	if err := sfile.Open(); err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	fmt.Fprintln(cmd.Stdout, "Building TSI index")
+	return buildtsi.IndexShard(sfile, dir, "", tsdb.DefaultMaxIndexLogFileSize, tsdb.DefaultCacheMaxMemorySize, shardwriter.IndexBatchSize, 0, cmd.logger, false)
+}
+
+// swap moves the shards and their WAL to the backup directory, and the merged
+// shard of dir in place of the target shard.
+func (cmd *Command) swap(dir string) error {
+	move := func(src, dst string) error {
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+			return err
+		}
+		return os.Rename(src, dst)
+	}
+	for _, id := range cmd.shards {
+		name := strconv.FormatUint(id, 10)
+		if err := move(cmd.shardPath(id), filepath.Join(cmd.backupDir, cmd.rp, name)); err != nil {
+			return err
+		}
+		if cmd.walPath != "" {
+			if err := move(filepath.Join(cmd.walPath, cmd.rp, name), filepath.Join(cmd.backupDir, "wal", cmd.rp, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(dir, cmd.shardPath(cmd.target))
+}
+
+// printMetaChanges prints the changes to the meta data needed by the merged
+// shard: the shard group of the target shard covers the time range of the
+// others, which are dropped.
+func (cmd *Command) printMetaChanges(groups []meta.ShardGroupInfo, min, max int64) {
+	var dropped []uint64
+	for _, id := range cmd.shards {
+		if id != cmd.target {
+			dropped = append(dropped, id)
+		}
+	}
+
+	fmt.Fprintln(cmd.Stdout, "\nMeta changes, before restarting influxd:")
+	if len(groups) == 0 {
+		if min <= max {
+			fmt.Fprintf(cmd.Stdout, "  The shard group of shard %d must cover the shard groups of shards %s, and at least the data from %s to %s.\n",
+				cmd.target, cmd.shardList(dropped), time.Unix(0, min).UTC().Format(time.RFC3339Nano), time.Unix(0, max).UTC().Format(time.RFC3339Nano))
+		} else {
+			fmt.Fprintf(cmd.Stdout, "  The shard group of shard %d must cover the shard groups of shards %s.\n", cmd.target, cmd.shardList(dropped))
+		}
+	} else {
+		start, end := groups[0].StartTime, groups[len(groups)-1].EndTime
+		for _, g := range groups {
+			for _, sh := range g.Shards {
+				if sh.ID == cmd.target {
+					fmt.Fprintf(cmd.Stdout, "  Shard group %d of shard %d: time range from %s to %s (was %s to %s).\n",
+						g.ID, cmd.target, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339),
+						g.StartTime.UTC().Format(time.RFC3339), g.EndTime.UTC().Format(time.RFC3339))
+				}
+			}
+		}
+	}
+	fmt.Fprintln(cmd.Stdout, "  The shards dropped, which deletes their shard groups:")
+	for _, id := range dropped {
+		fmt.Fprintf(cmd.Stdout, "    DROP SHARD %d\n", id)
+	}
+}
+
+func (cmd *Command) shardList(ids []uint64) string {
+	a := make([]string, len(ids))
+	for i, id := range ids {
+		a[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(a, ", ")
+}
+
+// fileStore provides the generations and the readers of the TSM files to the
+// compactor. Like the readers of tsm1.FileStore, the readers are referenced
+// until they are unreferenced by their user.
+type fileStore struct {
+	gen     atomic.Int64
+	readers []*tsm1.TSMReader
+}
+
+func (s *fileStore) NextGeneration() int {
+	return int(s.gen.Add(1))
+}
+
+func (s *fileStore) TSMReader(path string) (*tsm1.TSMReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.Ref()
+	s.readers = append(s.readers, r)
+	return r, nil
+}
+
+// close closes the readers.
+func (s *fileStore) close() {
+	for _, r := range s.readers {
+		r.Close()
+	}
+	s.readers = nil
+}
@@ -0,0 +1,159 @@
+package mergeshards_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/mergeshards"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+var t0 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeTSM writes a TSM file with a value at ts for each key.
+func writeTSM(t *testing.T, path string, ts time.Time, keys ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if err := w.Write([]byte(k), []tsm1.Value{tsm1.NewValue(ts.UnixNano(), 1.5)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeMeta writes the meta data of a shard group per day from t0, holding
+// the shards 1, 2 and 3.
+func writeMeta(t *testing.T, dir string) {
+	t.Helper()
+	var data meta.Data
+	rpi := meta.DefaultRetentionPolicyInfo()
+	rpi.ShardGroupDuration = 24 * time.Hour
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateRetentionPolicy("db0", rpi, true); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := data.CreateShardGroup("db0", "autogen", t0.Add(time.Duration(i)*24*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	} else if err := os.MkdirAll(dir, 0777); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(dir, "meta.db"), buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommand_Run(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "data", "db0")
+	walPath := filepath.Join(dir, "wal", "db0")
+	writeTSM(t, filepath.Join(dbPath, "autogen", "1", "000000001-000000001.tsm"), t0, "cpu,host=a#!~#value")
+	writeTSM(t, filepath.Join(dbPath, "autogen", "2", "000000002-000000001.tsm"), t0.Add(24*time.Hour), "cpu,host=a#!~#value", "mem,host=a#!~#free")
+
+	// The data of shard 2 is also in its WAL.
+	wal := tsm1.NewWAL(filepath.Join(walPath, "autogen", "2"))
+	if err := wal.Open(); err != nil {
+		t.Fatal(err)
+	} else if _, err := wal.WriteMulti(map[string][]tsm1.Value{
+		"disk,host=a#!~#used": {tsm1.NewValue(t0.Add(25*time.Hour).UnixNano(), int64(10))},
+	}); err != nil {
+		t.Fatal(err)
+	} else if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeMeta(t, filepath.Join(dir, "meta"))
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		cmd := mergeshards.NewCommand()
+		cmd.Stdout, cmd.Stderr = &stdout, &bytes.Buffer{}
+		err := cmd.Run(append([]string{"-db-path", dbPath, "-retention", "autogen", "-meta-dir", filepath.Join(dir, "meta")}, args...)...)
+		return stdout.String(), err
+	}
+
+	// The shard groups of shards 1 and 3 aren't adjacent.
+	if _, err := run("-backup-dir", filepath.Join(dir, "backup"), "-shards", "1,3"); err == nil || !strings.Contains(err.Error(), "aren't adjacent") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := run("-backup-dir", filepath.Join(dir, "backup"), "-wal-path", walPath, "-shards", "2,1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n%s", err, out)
+	}
+	for _, exp := range []string{
+		"Merged shards 1, 2 into shard 1",
+		"Shard group 1 of shard 1: time range from 2020-01-01T00:00:00Z to 2020-01-03T00:00:00Z (was 2020-01-01T00:00:00Z to 2020-01-02T00:00:00Z).",
+		"DROP SHARD 2",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected %q in output:\n%s", exp, out)
+		}
+	}
+
+	// The merged shard holds the data of both shards, and has an index.
+	shardPath := filepath.Join(dbPath, "autogen", "1")
+	paths, err := filepath.Glob(filepath.Join(shardPath, "*.tsm"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(paths) != 1 {
+		t.Fatalf("got TSM files %v, expected one", paths)
+	}
+	f, err := os.Open(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for key, n := range map[string]int{"cpu,host=a#!~#value": 2, "disk,host=a#!~#used": 1, "mem,host=a#!~#free": 1} {
+		if values, err := r.ReadAll([]byte(key)); err != nil {
+			t.Fatal(err)
+		} else if len(values) != n {
+			t.Errorf("got %d values for %s, expected %d", len(values), key, n)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(shardPath, "index")); err != nil {
+		t.Errorf("missing index: %s", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(dbPath, "autogen", "2"),
+		filepath.Join(walPath, "autogen", "2"),
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s wasn't moved: %v", path, err)
+		}
+	}
+	for _, path := range []string{"autogen/1", "autogen/2", "wal/autogen/2"} {
+		if _, err := os.Stat(filepath.Join(dir, "backup", path)); err != nil {
+			t.Errorf("missing backup: %s", err)
+		}
+	}
+}
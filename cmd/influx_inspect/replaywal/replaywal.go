@@ -0,0 +1,368 @@
+// Package replaywal replays the writes of WAL segments, filtered by time and
+// measurement, into a shard or out to line protocol.
+package replaywal
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/escape"
+	"github.com/influxdata/influxdb/pkg/file"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+// Command represents the program execution for "influx_inspect replaywal".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stderr io.Writer
+	Stdout io.Writer
+
+	out             string // line protocol output, stdout if empty
+	shardPath       string // target shard, instead of line protocol
+	database        string // optional. Context of the line protocol
+	retentionPolicy string // optional. Context of the line protocol
+	measurements    map[string]bool
+	startTime       int64
+	endTime         int64
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	var start, end, measurements string
+	fs := flag.NewFlagSet("replaywal", flag.ExitOnError)
+	fs.StringVar(&cmd.out, "out", "", "")
+	fs.StringVar(&cmd.shardPath, "shard-path", "", "")
+	fs.StringVar(&cmd.database, "database", "", "")
+	fs.StringVar(&cmd.retentionPolicy, "retention", "", "")
+	fs.StringVar(&measurements, "measurement", "", "")
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
+	fs.SetOutput(cmd.Stdout)
+	fs.Usage = cmd.printUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		fmt.Fprintf(cmd.Stdout, "path required\n\n")
+		fs.Usage()
+		return nil
+	}
+
+	if cmd.out != "" && cmd.shardPath != "" {
+		return errors.New("-out and -shard-path are exclusive")
+	} else if cmd.retentionPolicy != "" && cmd.database == "" {
+		return errors.New("-retention requires -database")
+	}
+
+	cmd.startTime, cmd.endTime = math.MinInt64, math.MaxInt64
+	if start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return err
+		}
+		cmd.startTime = t.UnixNano()
+	}
+	if end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return err
+		}
+		cmd.endTime = t.UnixNano()
+	}
+	if cmd.endTime < cmd.startTime {
+		return errors.New("end time before start time")
+	}
+	if measurements != "" {
+		cmd.measurements = make(map[string]bool)
+		for _, m := range strings.Split(measurements, ",") {
+			cmd.measurements[strings.TrimSpace(m)] = true
+		}
+	}
+
+	// A directory stands for its WAL segments.
+	var paths []string
+	for _, path := range fs.Args() {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		} else if !fi.IsDir() {
+			paths = append(paths, path)
+			continue
+		}
+		segments, err := filepath.Glob(filepath.Join(path, "*."+tsm1.WALFileExtension))
+		if err != nil {
+			return err
+		}
+		sort.Strings(segments)
+		paths = append(paths, segments...)
+	}
+
+	cache, err := cmd.load(paths)
+	if err != nil {
+		return err
+	}
+	if cmd.shardPath != "" {
+		return cmd.writeShard(cache)
+	}
+	return cmd.writeLineProtocol(cache)
+}
+
+// load returns the values of the writes of the segments selected by the
+// filters. The deletes of the segments apply to these values only.
+func (cmd *Command) load(paths []string) (*tsm1.Cache, error) {
+	cache := tsm1.NewCache(0)
+	var deletes int
+	for _, path := range paths {
+		if filepath.Ext(path) != "."+tsm1.WALFileExtension {
+			fmt.Fprintf(cmd.Stderr, "invalid wal filename, skipping %s\n", path)
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		r := tsm1.NewWALSegmentReader(f)
+		for r.Next() {
+			entry, err := r.Read()
+			if err != nil {
+				// The end of the last segment may be cut short by a crash.
+				fmt.Fprintf(cmd.Stderr, "cannot read entry of %s, skipping the rest of the segment: %s\n", path, err)
+				break
+			}
+
+			switch entry := entry.(type) {
+			case *tsm1.WriteWALEntry:
+				values := make(map[string][]tsm1.Value)
+				for k, vs := range entry.Values {
+					if !cmd.matchKey(k) {
+						continue
+					}
+					for _, v := range vs {
+						if t := v.UnixNano(); t >= cmd.startTime && t <= cmd.endTime {
+							values[k] = append(values[k], v)
+						}
+					}
+				}
+				if err := cache.WriteMulti(values); err != nil {
+					f.Close()
+					return nil, err
+				}
+			case *tsm1.DeleteWALEntry:
+				cache.DeleteRange(entry.Keys, math.MinInt64, math.MaxInt64)
+				deletes++
+			case *tsm1.DeleteRangeWALEntry:
+				cache.DeleteRange(entry.Keys, entry.Min, entry.Max)
+				deletes++
+			default:
+				f.Close()
+				return nil, fmt.Errorf("invalid wal entry: %#v", entry)
+			}
+		}
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if deletes > 0 {
+		fmt.Fprintf(cmd.Stderr, "Applied %d deletes to the replayed writes only\n", deletes)
+	}
+	return cache, nil
+}
+
+// matchKey returns true if the measurement of a composite key is replayed.
+func (cmd *Command) matchKey(key string) bool {
+	if cmd.measurements == nil {
+		return true
+	}
+	series, _ := tsm1.SeriesAndFieldFromCompositeKey([]byte(key))
+	name, _ := models.ParseKeyBytes(series)
+	return cmd.measurements[string(name)]
+}
+
+// writeLineProtocol writes the values of the cache as line protocol.
+func (cmd *Command) writeLineProtocol(cache *tsm1.Cache) (err error) {
+	var w io.Writer = cmd.Stdout
+	if cmd.out != "" {
+		f, err := os.Create(cmd.out)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if e := f.Close(); err == nil {
+				err = e
+			}
+		}()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+
+	if cmd.database != "" {
+		fmt.Fprintln(bw, "# DML")
+		fmt.Fprintf(bw, "# CONTEXT-DATABASE:%s\n", cmd.database)
+		if cmd.retentionPolicy != "" {
+			fmt.Fprintf(bw, "# CONTEXT-RETENTION-POLICY:%s\n", cmd.retentionPolicy)
+		}
+	}
+
+	var n int
+	for _, key := range cache.Keys() {
+		series, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+		buf := []byte(string(series) + " " + escape.String(string(field)) + "=")
+		prefixLen := len(buf)
+
+		for _, value := range cache.Values(key) {
+			// Re-slice buf to be "<series_key> <field>=".
+			buf = buf[:prefixLen]
+
+			switch v := value.Value().(type) {
+			case float64:
+				buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+			case int64:
+				buf = strconv.AppendInt(buf, v, 10)
+				buf = append(buf, 'i')
+			case uint64:
+				buf = strconv.AppendUint(buf, v, 10)
+				buf = append(buf, 'u')
+			case bool:
+				buf = strconv.AppendBool(buf, v)
+			case string:
+				buf = append(buf, '"')
+				buf = append(buf, models.EscapeStringField(v)...)
+				buf = append(buf, '"')
+			default:
+				// This shouldn't be possible, but we'll format it anyway.
+				buf = append(buf, fmt.Sprintf("%v", v)...)
+			}
+
+			buf = append(buf, ' ')
+			buf = strconv.AppendInt(buf, value.UnixNano(), 10)
+			buf = append(buf, '\n')
+			if _, err := bw.Write(buf); err != nil {
+				return err
+			}
+			n++
+		}
+	}
+	fmt.Fprintf(cmd.Stderr, "Replayed %d values\n", n)
+	return bw.Flush()
+}
+
+// writeShard writes the values of the cache to a new TSM file of the shard,
+// newer than its other TSM files.
+func (cmd *Command) writeShard(cache *tsm1.Cache) error {
+	tsmPaths, err := filepath.Glob(filepath.Join(cmd.shardPath, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	var maxGeneration int
+	for _, path := range tsmPaths {
+		gen, _, err := tsm1.DefaultParseFileName(path)
+		if err != nil {
+			return err
+		} else if gen > maxGeneration {
+			maxGeneration = gen
+		}
+	}
+	path := filepath.Join(cmd.shardPath, tsm1.DefaultFormatFileName(maxGeneration+1, 1)+"."+tsm1.TSMFileExtension)
+	tmpPath := path + "." + tsm1.TmpTSMFileExtension
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	var n int
+	for _, key := range cache.Keys() {
+		values := cache.Values(key)
+		n += len(values)
+		for len(values) > 0 {
+			size := tsdb.DefaultMaxPointsPerBlock
+			if size > len(values) {
+				size = len(values)
+			}
+			if err := w.Write(key, values[:size]); err != nil {
+				w.Close()
+				os.Remove(tmpPath)
+				return err
+			}
+			values = values[size:]
+		}
+	}
+	if err := w.WriteIndex(); errors.Is(err, tsm1.ErrNoValues) {
+		w.Close()
+		fmt.Fprintln(cmd.Stderr, "No values to replay")
+		return os.Remove(tmpPath)
+	} else if err != nil {
+		w.Close()
+		os.Remove(tmpPath)
+		return err
+	} else if err := w.Close(); err != nil {
+		return err
+	} else if err := file.RenameFile(tmpPath, path); err != nil {
+		return err
+	}
+
+	// The engine rebuilds the fields index of the shard from its TSM files
+	// when it is missing, which also adds the new series to the index.
+	for _, name := range []string{"fields.idx", tsdb.FieldsChangeFile} {
+		if err := os.Remove(filepath.Join(cmd.shardPath, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	fmt.Fprintf(cmd.Stdout, "Replayed %d values into %s\n", n, path)
+	return nil
+}
+
+func (cmd *Command) printUsage() {
+	fmt.Fprint(cmd.Stdout, `Replays the writes of TSM WAL segments, filtered by time and measurement,
+into a shard or out to line protocol.
+
+The deletes of the segments apply to the replayed writes only.
+
+Usage: influx_inspect replaywal [flags] path...
+
+    path
+            A WAL segment, or a directory to replay all of its segments
+            in order.
+    -out PATH
+            The line protocol file to write, stdout by default.
+    -shard-path PATH
+            The shard to replay the writes into, as a new TSM file, instead
+            of line protocol. Stop influxd first.
+    -database NAME
+            The database of the line protocol context, to import it with
+            influx -import.
+    -retention NAME
+            The retention policy of the line protocol context.
+    -measurement NAMES
+            Comma-separated measurements to replay, all by default.
+    -start TIME
+            The start time of the writes to replay (RFC3339 format).
+    -end TIME
+            The end time of the writes to replay (RFC3339 format).
+`)
+}
@@ -0,0 +1,109 @@
+package replaywal_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/replaywal"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+var t0 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeWAL writes the WAL segments of a shard.
+func writeWAL(t *testing.T, dir string) {
+	t.Helper()
+	wal := tsm1.NewWAL(dir)
+	if err := wal.Open(); err != nil {
+		t.Fatal(err)
+	} else if _, err := wal.WriteMulti(map[string][]tsm1.Value{
+		"cpu,host=a#!~#value": {tsm1.NewValue(t0.UnixNano(), 1.5), tsm1.NewValue(t0.Add(2*time.Hour).UnixNano(), 2.5)},
+		"cpu,host=b#!~#value": {tsm1.NewValue(t0.UnixNano(), 3.5)},
+		"mem,host=a#!~#free":  {tsm1.NewValue(t0.UnixNano(), int64(10))},
+		"mem,host=a#!~#label": {tsm1.NewValue(t0.UnixNano(), `a "b"`)},
+	}); err != nil {
+		t.Fatal(err)
+	} else if _, err := wal.Delete([][]byte{[]byte("cpu,host=b#!~#value")}); err != nil {
+		t.Fatal(err)
+	} else if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func run(t *testing.T, args ...string) string {
+	t.Helper()
+	var stdout bytes.Buffer
+	cmd := replaywal.NewCommand()
+	cmd.Stdout, cmd.Stderr = &stdout, &bytes.Buffer{}
+	if err := cmd.Run(args...); err != nil {
+		t.Fatalf("unexpected error: %s\n%s", err, stdout.String())
+	}
+	return stdout.String()
+}
+
+func TestCommand_Run_LineProtocol(t *testing.T) {
+	dir := t.TempDir()
+	writeWAL(t, dir)
+
+	out := run(t, "-database", "db0", "-retention", "autogen", dir)
+	exp := `# DML
+# CONTEXT-DATABASE:db0
+# CONTEXT-RETENTION-POLICY:autogen
+cpu,host=a value=1.5 1577836800000000000
+cpu,host=a value=2.5 1577844000000000000
+mem,host=a free=10i 1577836800000000000
+mem,host=a label="a \"b\"" 1577836800000000000
+`
+	if out != exp {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out, exp)
+	}
+
+	// Only the writes of cpu before 01:00 are replayed.
+	outPath := filepath.Join(dir, "out.txt")
+	run(t, "-measurement", "cpu", "-end", "2020-01-01T01:00:00Z", "-out", outPath, dir)
+	if buf, err := os.ReadFile(outPath); err != nil {
+		t.Fatal(err)
+	} else if string(buf) != "cpu,host=a value=1.5 1577836800000000000\n" {
+		t.Errorf("unexpected output:\n%s", buf)
+	}
+}
+
+func TestCommand_Run_Shard(t *testing.T) {
+	dir := t.TempDir()
+	walPath := filepath.Join(dir, "wal")
+	shardPath := filepath.Join(dir, "shard")
+	writeWAL(t, walPath)
+	if err := os.MkdirAll(shardPath, 0777); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(shardPath, "000000004-000000002.tsm"), nil, 0666); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(shardPath, "fields.idx"), nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	out := run(t, "-measurement", "mem", "-shard-path", shardPath, walPath)
+	path := filepath.Join(shardPath, "000000005-000000001.tsm")
+	if !strings.Contains(out, "Replayed 2 values into "+path) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if n := r.KeyCount(); n != 2 {
+		t.Errorf("got %d keys, expected 2", n)
+	}
+	if _, err := os.Stat(filepath.Join(shardPath, "fields.idx")); !os.IsNotExist(err) {
+		t.Errorf("fields.idx wasn't removed: %v", err)
+	}
+}
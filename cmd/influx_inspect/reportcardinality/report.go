@@ -0,0 +1,315 @@
+// Package reportcardinality provides a report about the series cardinality of
+// a database or a shard by measurement, tag key and tag key set.
+package reportcardinality
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/index/tsi1"
+)
+
+// Command represents the program execution for "influx_inspect report-cardinality".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stderr io.Writer
+	Stdout io.Writer
+
+	dbPath         string
+	seriesFilePath string // optional. Defaults to dbPath/_series
+	shardID        int64  // optional. Defaults to all the shards
+	measurement    string // optional. Defaults to all the measurements
+	topN           int
+
+	shardPaths map[uint64]string
+}
+
+// NewCommand returns a new instance of Command with default setting applied.
+func NewCommand() *Command {
+	return &Command{
+		Stderr:     os.Stderr,
+		Stdout:     os.Stdout,
+		shardID:    -1,
+		topN:       10,
+		shardPaths: map[uint64]string{},
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	fs := flag.NewFlagSet("report-cardinality", flag.ExitOnError)
+	fs.StringVar(&cmd.dbPath, "db-path", "", "Path to database. Required.")
+	fs.StringVar(&cmd.seriesFilePath, "series-file", "", "Optional path to series file. Defaults /path/to/db-path/_series")
+	fs.Int64Var(&cmd.shardID, "shard", -1, "Optional ID of the shard to report. Defaults to all the shards of the database")
+	fs.StringVar(&cmd.measurement, "measurement", "", "Optional measurement to report. Defaults to all the measurements")
+	fs.IntVar(&cmd.topN, "top", 10, "Number of tag values with the most series reported by tag key, 0 for none")
+
+	fs.SetOutput(cmd.Stdout)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.dbPath == "" {
+		return errors.New("path to database must be provided")
+	}
+
+	if cmd.seriesFilePath == "" {
+		cmd.seriesFilePath = filepath.Join(cmd.dbPath, tsdb.SeriesFileDirectory)
+	}
+
+	// Walk database directory to get shards.
+	if err := filepath.Walk(cmd.dbPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if info.Name() == tsdb.SeriesFileDirectory || info.Name() == "index" {
+			return filepath.SkipDir
+		}
+
+		id, err := strconv.ParseUint(info.Name(), 10, 64)
+		if err != nil {
+			return nil
+		}
+		if cmd.shardID < 0 || uint64(cmd.shardID) == id {
+			cmd.shardPaths[id] = path
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(cmd.shardPaths) == 0 {
+		if cmd.shardID >= 0 {
+			return fmt.Errorf("no shard %d under %s", cmd.shardID, cmd.dbPath)
+		}
+		fmt.Fprintf(cmd.Stderr, "No shards under %s\n", cmd.dbPath)
+		return nil
+	}
+
+	return cmd.run()
+}
+
+func (cmd *Command) run() error {
+	sfile := tsdb.NewSeriesFile(cmd.seriesFilePath)
+	sfile.Logger = logger.New(cmd.Stderr)
+	if err := sfile.Open(); err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	// The series of the shards, from their indexes.
+	ids := tsdb.NewSeriesIDSet()
+	for _, pth := range cmd.shardPaths {
+		pth = filepath.Join(pth, "index")
+		// Verify directory is an index before opening it.
+		if ok, err := tsi1.IsIndexDir(pth); err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("not a TSI index directory: %q", pth)
+		}
+
+		idx := tsi1.NewIndex(sfile, "", tsi1.WithPath(pth), tsi1.DisableCompactions())
+		if err := idx.Open(); err != nil {
+			return err
+		}
+		ids.Merge(idx.SeriesIDSet())
+		if err := idx.Close(); err != nil {
+			return err
+		}
+	}
+
+	report := newReport()
+	ids.ForEach(func(id uint64) {
+		if sfile.IsDeleted(id) {
+			return
+		}
+		name, tags := sfile.Series(id)
+		if name == nil || (cmd.measurement != "" && string(name) != cmd.measurement) {
+			return
+		}
+		report.add(string(name), tags)
+	})
+	return cmd.print(report)
+}
+
+// report holds the exact series counts of the measurements.
+type report struct {
+	series       int
+	measurements map[string]*measurementCardinality
+}
+
+// measurementCardinality holds the series counts of a measurement and of its
+// tag keys and tag key sets.
+type measurementCardinality struct {
+	name    string
+	series  int
+	tagKeys map[string]*tagKeyCardinality
+	tagSets map[string]int // tag keys joined by commas
+}
+
+// tagKeyCardinality holds the series counts of a tag key and of its values.
+type tagKeyCardinality struct {
+	key    string
+	series int
+	values map[string]int
+}
+
+func newReport() *report {
+	return &report{measurements: make(map[string]*measurementCardinality)}
+}
+
+// add counts a series.
+func (r *report) add(name string, tags models.Tags) {
+	m, ok := r.measurements[name]
+	if !ok {
+		m = &measurementCardinality{
+			name:    name,
+			tagKeys: make(map[string]*tagKeyCardinality),
+			tagSets: make(map[string]int),
+		}
+		r.measurements[name] = m
+	}
+	r.series++
+	m.series++
+
+	keys := make([]string, len(tags))
+	for i, t := range tags {
+		k, ok := m.tagKeys[string(t.Key)]
+		if !ok {
+			k = &tagKeyCardinality{key: string(t.Key), values: make(map[string]int)}
+			m.tagKeys[k.key] = k
+		}
+		k.series++
+		k.values[string(t.Value)]++
+		keys[i] = k.key
+	}
+	m.tagSets[strings.Join(keys, ",")]++
+}
+
+// print prints the report: the series of the measurements, then for each
+// measurement the series and the values of its tag keys, the series of its
+// top tag values and the series of its tag key sets.
+func (cmd *Command) print(r *report) error {
+	measurements := make([]*measurementCardinality, 0, len(r.measurements))
+	for _, m := range r.measurements {
+		measurements = append(measurements, m)
+	}
+	sort.Slice(measurements, func(i, j int) bool {
+		if measurements[i].series != measurements[j].series {
+			return measurements[i].series > measurements[j].series
+		}
+		return measurements[i].name < measurements[j].name
+	})
+
+	shards := make([]uint64, 0, len(cmd.shardPaths))
+	for id := range cmd.shardPaths {
+		shards = append(shards, id)
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i] < shards[j] })
+	ids := make([]string, len(shards))
+	for i, id := range shards {
+		ids[i] = strconv.FormatUint(id, 10)
+	}
+
+	tw := tabwriter.NewWriter(cmd.Stdout, 4, 4, 1, '\t', 0)
+	fmt.Fprintf(tw, "Summary\nDatabase Path: %s\nShards: %s\nCardinality (exact): %d\n\n", cmd.dbPath, strings.Join(ids, ", "), r.series)
+	fmt.Fprint(tw, "Measurement\tCardinality (exact)\n\n")
+	for _, m := range measurements {
+		fmt.Fprintf(tw, "%q\t\t%d\n", m.name, m.series)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, m := range measurements {
+		fmt.Fprint(cmd.Stdout, "\n\n")
+		if err := cmd.printMeasurement(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printMeasurement prints the tag keys of a measurement, by their number of
+// values, and its tag key sets, by their series.
+func (cmd *Command) printMeasurement(m *measurementCardinality) error {
+	keys := make([]*tagKeyCardinality, 0, len(m.tagKeys))
+	for _, k := range m.tagKeys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i].values) != len(keys[j].values) {
+			return len(keys[i].values) > len(keys[j].values)
+		}
+		return keys[i].key < keys[j].key
+	})
+
+	tw := tabwriter.NewWriter(cmd.Stdout, 4, 4, 1, '\t', 0)
+	fmt.Fprintf(tw, "===============\nMeasurement: %q\nCardinality (exact): %d\n\n", m.name, m.series)
+	fmt.Fprint(tw, "Tag Key\tValues\tSeries\n\n")
+	for _, k := range keys {
+		fmt.Fprintf(tw, "%q\t%d\t%d\n", k.key, len(k.values), k.series)
+		for _, v := range topValues(k.values, cmd.topN) {
+			fmt.Fprintf(tw, "    %q\t\t%d\n", v.value, v.series)
+		}
+	}
+
+	sets := make([]tagValueCardinality, 0, len(m.tagSets))
+	for set, n := range m.tagSets {
+		sets = append(sets, tagValueCardinality{value: set, series: n})
+	}
+	sortByCardinality(sets)
+	fmt.Fprint(tw, "\nTag Key Set\t\tSeries\n\n")
+	for _, s := range sets {
+		fmt.Fprintf(tw, "[%s]\t\t%d\n", s.value, s.series)
+	}
+	fmt.Fprint(tw, "===============\n")
+	return tw.Flush()
+}
+
+// tagValueCardinality is the series count of a tag value or of a tag key set.
+type tagValueCardinality struct {
+	value  string
+	series int
+}
+
+// topValues returns the n tag values with the most series.
+func topValues(values map[string]int, n int) []tagValueCardinality {
+	if n <= 0 {
+		return nil
+	}
+	top := make([]tagValueCardinality, 0, len(values))
+	for v, series := range values {
+		top = append(top, tagValueCardinality{value: v, series: series})
+	}
+	sortByCardinality(top)
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+func sortByCardinality(a []tagValueCardinality) {
+	sort.Slice(a, func(i, j int) bool {
+		if a[i].series != a[j].series {
+			return a[i].series > a[j].series
+		}
+		return a[i].value < a[j].value
+	})
+}
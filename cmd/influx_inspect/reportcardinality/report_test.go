@@ -0,0 +1,94 @@
+package reportcardinality
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/index/tsi1"
+)
+
+// createShard creates the TSI index of a shard of db0 with the series of
+// keys.
+func createShard(t *testing.T, sfile *tsdb.SeriesFile, dir string, id int, keys ...string) {
+	t.Helper()
+	idx := tsi1.NewIndex(sfile, "db0", tsi1.WithPath(filepath.Join(dir, "autogen", fmt.Sprint(id), "index")))
+	if err := idx.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	var names [][]byte
+	var tags []models.Tags
+	var series [][]byte
+	for _, k := range keys {
+		name, t := models.ParseKeyBytes([]byte(k))
+		names, tags, series = append(names, name), append(tags, t), append(series, []byte(k))
+	}
+	if err := idx.CreateSeriesListIfNotExists(series, names, tags, tsdb.NoopStatsTracker()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommand_Run(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db0")
+	sfile := tsdb.NewSeriesFile(filepath.Join(dir, tsdb.SeriesFileDirectory))
+	if err := sfile.Open(); err != nil {
+		t.Fatal(err)
+	}
+	createShard(t, sfile, dir, 1,
+		"cpu,host=a,region=us",
+		"cpu,host=b,region=us",
+		"cpu,host=c,region=eu",
+		"mem,host=a",
+	)
+	// The series of shard 1 are counted once.
+	createShard(t, sfile, dir, 2,
+		"cpu,host=a,region=us",
+		"cpu,host=d",
+	)
+	if err := sfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := NewCommand()
+		cmd.Stdout, cmd.Stderr = &stdout, &bytes.Buffer{}
+		if err := cmd.Run(append([]string{"-db-path", dir}, args...)...); err != nil {
+			t.Fatal(err)
+		}
+		// Ignore the alignment of the columns.
+		return strings.Join(strings.Fields(stdout.String()), " ")
+	}
+
+	out := run("-top", "1")
+	for _, exp := range []string{
+		`Shards: 1, 2 Cardinality (exact): 5`,
+		`Measurement Cardinality (exact) "cpu" 4 "mem" 1`,
+		`Measurement: "cpu" Cardinality (exact): 4 Tag Key Values Series "host" 4 4 "a" 1 "region" 2 3 "us" 2`,
+		`Tag Key Set Series [host,region] 3 [host] 1`,
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected %q in output:\n%s", exp, out)
+		}
+	}
+
+	out = run("-shard", "2", "-measurement", "cpu", "-top", "0")
+	for _, exp := range []string{
+		`Shards: 2 Cardinality (exact): 2`,
+		`Tag Key Values Series "host" 2 2 "region" 1 1 Tag Key Set`,
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected %q in output:\n%s", exp, out)
+		}
+	}
+	if strings.Contains(out, `"mem"`) {
+		t.Errorf("unexpected measurement mem in output:\n%s", out)
+	}
+}
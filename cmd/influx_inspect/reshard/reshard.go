@@ -0,0 +1,354 @@
+// Package reshard rewrites the shards of a retention policy offline into the
+// layout of a new shard group duration.
+package reshard
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/buildtsi"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/internal/shardwriter"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/pkg/file"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+)
+
+// Command represents the program execution for "influx_inspect reshard".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stderr io.Writer
+	Stdout io.Writer
+
+	dbPath   string
+	walPath  string // optional. The WAL of the shards isn't rewritten if empty
+	metaDir  string
+	outDir   string
+	rp       string
+	duration time.Duration
+
+	data   *meta.Data
+	shards map[int64]uint64 // new shard by shard group start time
+	gens   map[uint64]int   // last generation of the TSM files of new shards
+
+	logger *zap.Logger
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	var duration string
+	fs := flag.NewFlagSet("reshard", flag.ExitOnError)
+	fs.StringVar(&cmd.dbPath, "db-path", "", "Path to the database. Required.")
+	fs.StringVar(&cmd.walPath, "wal-path", "", "Optional path to the WAL of the database, to rewrite the data of the WAL of the shards")
+	fs.StringVar(&cmd.metaDir, "meta-dir", "", "Path to the meta directory. Required.")
+	fs.StringVar(&cmd.outDir, "out", "", "Directory to write the new shards and meta data to. Required.")
+	fs.StringVar(&cmd.rp, "retention", "", "Retention policy to reshard. Required.")
+	fs.StringVar(&duration, "duration", "", "New shard group duration, such as 1d or 2w. Required.")
+	fs.SetOutput(cmd.Stdout)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.dbPath == "" || cmd.metaDir == "" || cmd.outDir == "" || cmd.rp == "" || duration == "" {
+		return errors.New("-db-path, -meta-dir, -out, -retention and -duration are required")
+	}
+	d, err := influxql.ParseDuration(duration)
+	if err != nil {
+		return err
+	} else if d < meta.MinRetentionPolicyDuration {
+		return fmt.Errorf("-duration must be at least %s", meta.MinRetentionPolicyDuration)
+	}
+	cmd.duration = d
+	cmd.logger = logger.New(cmd.Stderr)
+
+	return cmd.run()
+}
+
+func (cmd *Command) run() error {
+	buf, err := os.ReadFile(filepath.Join(cmd.metaDir, "meta.db"))
+	if err != nil {
+		return err
+	}
+	cmd.data = &meta.Data{}
+	if err := cmd.data.UnmarshalBinary(buf); err != nil {
+		return fmt.Errorf("cannot read meta data: %w", err)
+	}
+	db := filepath.Base(cmd.dbPath)
+	groups, err := cmd.data.ShardGroups(db, cmd.rp)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(cmd.outDir, cmd.rp)); !os.IsNotExist(err) {
+		return fmt.Errorf("%s already exists", filepath.Join(cmd.outDir, cmd.rp))
+	}
+
+	// The old shard groups are deleted and the new ones are created as their
+	// data is written, so that no shard is empty.
+	if err := cmd.data.UpdateRetentionPolicy(db, cmd.rp, &meta.RetentionPolicyUpdate{ShardGroupDuration: &cmd.duration}, false); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if err := cmd.data.DeleteShardGroup(db, cmd.rp, g.ID); err != nil {
+			return err
+		}
+	}
+	cmd.shards = make(map[int64]uint64)
+	cmd.gens = make(map[uint64]int)
+
+	// Shard groups are sorted by time, so the TSM files of the new shards
+	// are in the order of the data they hold.
+	var n int
+	for _, g := range groups {
+		for _, sh := range g.Shards {
+			shardPath := filepath.Join(cmd.dbPath, cmd.rp, strconv.FormatUint(sh.ID, 10))
+			if _, err := os.Stat(shardPath); os.IsNotExist(err) {
+				fmt.Fprintf(cmd.Stdout, "Skipping shard %d: no data\n", sh.ID)
+				continue
+			}
+			fmt.Fprintf(cmd.Stdout, "Rewriting shard %d\n", sh.ID)
+			if err := cmd.rewriteShard(shardPath); err != nil {
+				return err
+			}
+			if cmd.walPath != "" {
+				if err := cmd.rewriteWAL(filepath.Join(cmd.walPath, cmd.rp, strconv.FormatUint(sh.ID, 10))); err != nil {
+					return err
+				}
+			}
+			n++
+		}
+	}
+
+	if err := cmd.buildIndexes(); err != nil {
+		return err
+	}
+
+	buf, err = cmd.data.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	metaPath := filepath.Join(cmd.outDir, "meta.db")
+	if err := os.WriteFile(metaPath+".tmp", buf, 0666); err != nil {
+		return err
+	} else if err := file.RenameFile(metaPath+".tmp", metaPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Stdout, "Resharded %d shards of %s into %d shards of %s in %s\n", n, cmd.rp, len(cmd.shards), cmd.duration, cmd.outDir)
+	fmt.Fprintln(cmd.Stdout, "\nTo apply, with influxd stopped:")
+	fmt.Fprintf(cmd.Stdout, "  Move %s away and %s to it.\n", filepath.Join(cmd.dbPath, cmd.rp), filepath.Join(cmd.outDir, cmd.rp))
+	if cmd.walPath != "" {
+		fmt.Fprintf(cmd.Stdout, "  Move %s away.\n", filepath.Join(cmd.walPath, cmd.rp))
+	}
+	fmt.Fprintf(cmd.Stdout, "  Replace %s with %s.\n", filepath.Join(cmd.metaDir, "meta.db"), metaPath)
+	return nil
+}
+
+// start returns the start time of the new shard group of a timestamp.
+func (cmd *Command) start(t int64) int64 {
+	return time.Unix(0, t).Truncate(cmd.duration).UnixNano()
+}
+
+// shard returns the new shard of the shard group starting at start, creating
+// it if needed.
+func (cmd *Command) shard(start int64) (uint64, error) {
+	if id, ok := cmd.shards[start]; ok {
+		return id, nil
+	}
+	db := filepath.Base(cmd.dbPath)
+	if err := cmd.data.CreateShardGroup(db, cmd.rp, time.Unix(0, start)); err != nil {
+		return 0, err
+	}
+	g, err := cmd.data.ShardGroupByTimestamp(db, cmd.rp, time.Unix(0, start))
+	if err != nil {
+		return 0, err
+	}
+	id := g.Shards[0].ID
+	if err := os.MkdirAll(cmd.newShardPath(id), 0777); err != nil {
+		return 0, err
+	}
+	cmd.shards[start] = id
+	return id, nil
+}
+
+// newShardPath returns the path to the directory of a new shard.
+func (cmd *Command) newShardPath(id uint64) string {
+	return filepath.Join(cmd.outDir, cmd.rp, strconv.FormatUint(id, 10))
+}
+
+// rewriteShard writes the data of the TSM files of a shard to the new shards.
+func (cmd *Command) rewriteShard(shardPath string) error {
+	tsmPaths, err := filepath.Glob(filepath.Join(shardPath, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	for _, path := range tsmPaths {
+		if err := cmd.rewriteTSM(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteTSM writes the data of a TSM file to a TSM file of each new shard it
+// has data for.
+func (cmd *Command) rewriteTSM(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	defer r.Close()
+
+	w := cmd.newShardWriter()
+	defer w.Remove()
+
+	itr := r.BlockIterator()
+	for itr.Next() {
+		key, minTime, maxTime, _, _, block, err := itr.Read()
+		if err != nil {
+			return err
+		}
+
+		// The tombstoned values are still in the blocks.
+		var tombstones []tsm1.TimeRange
+		for _, t := range r.TombstoneRange(key) {
+			if t.Min <= maxTime && t.Max >= minTime {
+				tombstones = append(tombstones, t)
+			}
+		}
+
+		// Copy the block as is when it belongs to a single shard.
+		if start := cmd.start(minTime); len(tombstones) == 0 && start == cmd.start(maxTime) {
+			id, err := cmd.shard(start)
+			if err != nil {
+				return err
+			} else if err := w.WriteBlock(id, key, minTime, maxTime, block); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values, err := tsm1.DecodeBlock(block, nil)
+		if err != nil {
+			return fmt.Errorf("unable to decode block %s in %s: %w", key, path, err)
+		}
+		v := tsm1.Values(values)
+		for _, t := range tombstones {
+			v = v.Exclude(t.Min, t.Max)
+		}
+		if err := cmd.writeValues(w, key, v); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// rewriteWAL writes the data of the WAL of a shard to a TSM file of each new
+// shard it has data for.
+func (cmd *Command) rewriteWAL(walPath string) error {
+	walPaths, err := filepath.Glob(filepath.Join(walPath, "*."+tsm1.WALFileExtension))
+	if err != nil {
+		return err
+	} else if len(walPaths) == 0 {
+		return nil
+	}
+
+	cache := tsm1.NewCache(0)
+	loader := tsm1.NewCacheLoader(walPaths)
+	loader.WithLogger(cmd.logger)
+	if err := loader.Load(cache); err != nil {
+		return err
+	}
+
+	w := cmd.newShardWriter()
+	defer w.Remove()
+	for _, key := range cache.Keys() {
+		values := cache.Values(key)
+		for len(values) > 0 {
+			n := tsdb.DefaultMaxPointsPerBlock
+			if n > len(values) {
+				n = len(values)
+			}
+			if err := cmd.writeValues(w, key, values[:n]); err != nil {
+				return err
+			}
+			values = values[n:]
+		}
+	}
+	return w.Close()
+}
+
+// writeValues writes the values of a key, sorted by time, to their new
+// shards.
+func (cmd *Command) writeValues(w *shardwriter.Writer, key []byte, values tsm1.Values) error {
+	for len(values) > 0 {
+		start := cmd.start(values[0].UnixNano())
+		n := len(values)
+		if end := start + int64(cmd.duration); end > start {
+			n = sort.Search(len(values), func(i int) bool { return values[i].UnixNano() >= end })
+		}
+		id, err := cmd.shard(start)
+		if err != nil {
+			return err
+		} else if err := w.Write(id, key, values[:n]); err != nil {
+			return err
+		}
+		values = values[n:]
+	}
+	return nil
+}
+
+// buildIndexes builds the TSI index of each new shard.
+func (cmd *Command) buildIndexes() error {
+	sfile := tsdb.NewSeriesFile(filepath.Join(cmd.dbPath, tsdb.SeriesFileDirectory))
+	sfile.Logger = cmd.logger
+	if err := sfile.Open(); err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	ids := make([]uint64, 0, len(cmd.shards))
+	for _, id := range cmd.shards {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		fmt.Fprintf(cmd.Stdout, "Building TSI index of shard %d\n", id)
+		if err := buildtsi.IndexShard(sfile, cmd.newShardPath(id), "", tsdb.DefaultMaxIndexLogFileSize, tsdb.DefaultCacheMaxMemorySize, shardwriter.IndexBatchSize, 0, cmd.logger, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newShardWriter returns a writer of a TSM file to each new shard, with the
+// next generation of the shard.
+func (cmd *Command) newShardWriter() *shardwriter.Writer {
+	return shardwriter.New(func(id uint64) string {
+		cmd.gens[id]++
+		return filepath.Join(cmd.newShardPath(id), tsm1.DefaultFormatFileName(cmd.gens[id], 1)+"."+tsm1.TSMFileExtension)
+	})
+}
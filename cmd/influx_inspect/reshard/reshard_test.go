@@ -0,0 +1,205 @@
+package reshard_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/reshard"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+var t0 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeTSM writes a TSM file with the values of each key at the given hours
+// from t0.
+func writeTSM(t *testing.T, path string, values map[string][]int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var vs []tsm1.Value
+		for _, h := range values[k] {
+			vs = append(vs, tsm1.NewValue(t0.Add(time.Duration(h)*time.Hour).UnixNano(), float64(h)))
+		}
+		if err := w.Write([]byte(k), vs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readShard returns the hours from t0 of the values of each key of the TSM
+// files of a shard.
+func readShard(t *testing.T, dir string) map[string][]int {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tsm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hours := make(map[string][]int)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := tsm1.NewTSMReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < r.KeyCount(); i++ {
+			key, _ := r.KeyAt(i)
+			values, err := r.ReadAll(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range values {
+				hours[string(key)] = append(hours[string(key)], int(time.Duration(v.UnixNano()-t0.UnixNano())/time.Hour))
+			}
+		}
+		r.Close()
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index")); err != nil {
+		t.Errorf("missing index: %s", err)
+	}
+	return hours
+}
+
+func TestCommand_Run(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "data", "db0")
+	walPath := filepath.Join(dir, "wal", "db0")
+	metaDir := filepath.Join(dir, "meta")
+
+	// Shards 1 and 2 of daily shard groups.
+	var data meta.Data
+	rpi := meta.DefaultRetentionPolicyInfo()
+	rpi.ShardGroupDuration = 24 * time.Hour
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateRetentionPolicy("db0", rpi, true); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := data.CreateShardGroup("db0", "autogen", t0.Add(time.Duration(i)*24*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	} else if err := os.MkdirAll(metaDir, 0777); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(metaDir, "meta.db"), buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTSM(t, filepath.Join(dbPath, "autogen", "1", "000000001-000000001.tsm"), map[string][]int{
+		"cpu,host=a#!~#value": {0, 6, 18},
+	})
+	writeTSM(t, filepath.Join(dbPath, "autogen", "2", "000000001-000000001.tsm"), map[string][]int{
+		"cpu,host=a#!~#value": {30},
+	})
+	wal := tsm1.NewWAL(filepath.Join(walPath, "autogen", "2"))
+	if err := wal.Open(); err != nil {
+		t.Fatal(err)
+	} else if _, err := wal.WriteMulti(map[string][]tsm1.Value{
+		"mem,host=a#!~#free": {tsm1.NewValue(t0.Add(40*time.Hour).UnixNano(), 1.0)},
+	}); err != nil {
+		t.Fatal(err)
+	} else if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := reshard.NewCommand()
+	cmd.Stdout, cmd.Stderr = &stdout, &bytes.Buffer{}
+	outDir := filepath.Join(dir, "out")
+	if err := cmd.Run("-db-path", dbPath, "-wal-path", walPath, "-meta-dir", metaDir, "-retention", "autogen", "-duration", "12h", "-out", outDir); err != nil {
+		t.Fatalf("unexpected error: %s\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "Resharded 2 shards of autogen into 4 shards of 12h0m0s") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	// The new meta data holds a shard group per 12 hours with data.
+	buf, err = os.ReadFile(filepath.Join(outDir, "meta.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var newData meta.Data
+	if err := newData.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if rpi, err := newData.RetentionPolicy("db0", "autogen"); err != nil {
+		t.Fatal(err)
+	} else if rpi.ShardGroupDuration != 12*time.Hour {
+		t.Errorf("got shard group duration %s, expected 12h", rpi.ShardGroupDuration)
+	}
+	groups, err := newData.ShardGroups("db0", "autogen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []struct {
+		start  int
+		values map[string][]int
+	}{
+		{0, map[string][]int{"cpu,host=a#!~#value": {0, 6}}},
+		{12, map[string][]int{"cpu,host=a#!~#value": {18}}},
+		{24, map[string][]int{"cpu,host=a#!~#value": {30}}},
+		{36, map[string][]int{"mem,host=a#!~#free": {40}}},
+	}
+	if len(groups) != len(exp) {
+		t.Fatalf("got %d shard groups, expected %d", len(groups), len(exp))
+	}
+	for i, g := range groups {
+		if !g.StartTime.Equal(t0.Add(time.Duration(exp[i].start)*time.Hour)) || g.EndTime.Sub(g.StartTime) != 12*time.Hour {
+			t.Errorf("unexpected shard group %d: %s to %s", g.ID, g.StartTime, g.EndTime)
+		}
+		id := g.Shards[0].ID
+		if id <= 2 {
+			t.Errorf("shard %d reuses an old ID", id)
+		}
+		hours := readShard(t, filepath.Join(outDir, "autogen", strconv.FormatUint(id, 10)))
+		for k, v := range exp[i].values {
+			if got := hours[k]; len(got) != len(v) || len(hours) != 1 {
+				t.Errorf("unexpected shard %d: %v, expected %v", id, hours, exp[i].values)
+			} else {
+				for j := range v {
+					if got[j] != v[j] {
+						t.Errorf("unexpected shard %d: %v, expected %v", id, hours, exp[i].values)
+					}
+				}
+			}
+		}
+	}
+
+	// The old shards are left in place.
+	if _, err := os.Stat(filepath.Join(dbPath, "autogen", "1", "000000001-000000001.tsm")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,486 @@
+// Package splitshard splits a shard into several shards offline, by time
+// boundaries or by series hash.
+package splitshard
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/buildtsi"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/internal/shardwriter"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/file"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+	"go.uber.org/zap"
+)
+
+// Command represents the program execution for "influx_inspect split-shard".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stderr io.Writer
+	Stdout io.Writer
+
+	dbPath    string
+	walPath   string // optional. The WAL of the shard isn't split if empty
+	metaDir   string // optional. The meta changes aren't checked if empty
+	backupDir string
+	rp        string
+	shard     uint64
+	ids       []uint64 // IDs of the parts, the shard first
+	at        []int64  // time boundaries of the parts, if split by time
+	hash      bool     // split by series hash
+
+	logger *zap.Logger
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	var at, newShards string
+	var shard int64
+	var hashN int
+	fs := flag.NewFlagSet("split-shard", flag.ExitOnError)
+	fs.StringVar(&cmd.dbPath, "db-path", "", "Path to the database. Required.")
+	fs.StringVar(&cmd.walPath, "wal-path", "", "Optional path to the WAL of the database, to split the data of the WAL of the shard")
+	fs.StringVar(&cmd.metaDir, "meta-dir", "", "Optional path to the meta directory, to check the time boundaries are in the shard group")
+	fs.StringVar(&cmd.backupDir, "backup-dir", "", "Directory to move the files of the shard to as they are split. Required.")
+	fs.StringVar(&cmd.rp, "retention", "", "Retention policy of the shard. Required.")
+	fs.Int64Var(&shard, "shard", -1, "ID of the shard to split. Required.")
+	fs.StringVar(&newShards, "new-shards", "", "Comma-separated IDs of the new shards, one per part after the first. Required.")
+	fs.StringVar(&at, "at", "", "Comma-separated time boundaries of the parts (RFC3339 format)")
+	fs.IntVar(&hashN, "hash", 0, "Number of parts to split the series into by hash")
+	fs.SetOutput(cmd.Stdout)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.dbPath == "" || cmd.rp == "" || cmd.backupDir == "" || shard < 0 {
+		return errors.New("-db-path, -retention, -shard and -backup-dir are required")
+	} else if (at == "") == (hashN == 0) {
+		return errors.New("exactly one of -at and -hash is required")
+	}
+	cmd.shard = uint64(shard)
+
+	n := hashN
+	if at != "" {
+		for _, s := range strings.Split(at, ",") {
+			t, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+			if err != nil {
+				return err
+			} else if len(cmd.at) > 0 && t.UnixNano() <= cmd.at[len(cmd.at)-1] {
+				return errors.New("-at boundaries must be in ascending order")
+			}
+			cmd.at = append(cmd.at, t.UnixNano())
+		}
+		n = len(cmd.at) + 1
+	} else if hashN < 2 {
+		return errors.New("-hash must be at least 2")
+	}
+	cmd.hash = hashN > 0
+
+	cmd.ids = []uint64{cmd.shard}
+	for _, s := range strings.Split(newShards, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid shard ID %q", s)
+		}
+		for _, other := range cmd.ids {
+			if id == other {
+				return fmt.Errorf("shard %d is listed twice", id)
+			}
+		}
+		cmd.ids = append(cmd.ids, id)
+	}
+	if len(cmd.ids) != n {
+		return fmt.Errorf("-new-shards must list %d shards, one per part after the first", n-1)
+	}
+	cmd.logger = logger.New(cmd.Stderr)
+
+	return cmd.run()
+}
+
+func (cmd *Command) run() error {
+	var group *meta.ShardGroupInfo
+	if cmd.metaDir != "" {
+		var err error
+		if group, err = cmd.shardGroup(); err != nil {
+			return err
+		}
+	}
+
+	shardPath := cmd.shardPath(cmd.shard)
+	if fi, err := os.Stat(shardPath); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s isn't a shard directory", shardPath)
+	}
+	for _, id := range cmd.ids[1:] {
+		if _, err := os.Stat(cmd.shardPath(id)); !os.IsNotExist(err) {
+			return fmt.Errorf("shard %d already exists", id)
+		}
+	}
+
+	// The parts are built next to the shard, and are kept by an interrupted
+	// run: the files of the shard already split are in the backup and the
+	// others are split again.
+	for _, id := range cmd.ids {
+		if err := os.MkdirAll(cmd.partPath(id), 0777); err != nil {
+			return err
+		}
+	}
+
+	tsmPaths, err := filepath.Glob(filepath.Join(shardPath, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	var maxGeneration int
+	for _, path := range tsmPaths {
+		gen, _, err := tsm1.DefaultParseFileName(path)
+		if err != nil {
+			return err
+		} else if gen > maxGeneration {
+			maxGeneration = gen
+		}
+	}
+
+	for i, path := range tsmPaths {
+		fmt.Fprintf(cmd.Stdout, "Splitting %s (%d/%d)\n", path, i+1, len(tsmPaths))
+		if err := cmd.splitTSM(path); err != nil {
+			return err
+		}
+		// Moving the file frees its space if the backup is on another disk.
+		if err := cmd.backup(path, filepath.Join(cmd.rp, strconv.FormatUint(cmd.shard, 10), filepath.Base(path))); err != nil {
+			return err
+		}
+	}
+
+	walPath := filepath.Join(cmd.walPath, cmd.rp, strconv.FormatUint(cmd.shard, 10))
+	if cmd.walPath != "" {
+		if err := cmd.splitWAL(walPath, tsm1.DefaultFormatFileName(maxGeneration+1, 1)+"."+tsm1.TSMFileExtension); err != nil {
+			return err
+		}
+	}
+
+	if err := cmd.buildIndexes(); err != nil {
+		return err
+	}
+
+	// Swap the parts in.
+	if err := cmd.backup(shardPath, filepath.Join(cmd.rp, strconv.FormatUint(cmd.shard, 10))); err != nil {
+		return err
+	}
+	if cmd.walPath != "" {
+		if _, err := os.Stat(walPath); err == nil {
+			if err := cmd.backup(walPath, filepath.Join("wal", cmd.rp, strconv.FormatUint(cmd.shard, 10))); err != nil {
+				return err
+			}
+		}
+	}
+	for _, id := range cmd.ids {
+		if err := os.Rename(cmd.partPath(id), cmd.shardPath(id)); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(filepath.Dir(cmd.partPath(cmd.shard))); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Stdout, "Split shard %d into shards %s, original in %s\n", cmd.shard, shardList(cmd.ids), cmd.backupDir)
+	cmd.printMetaChanges(group)
+	return nil
+}
+
+// shardPath returns the path to the directory of a shard.
+func (cmd *Command) shardPath(id uint64) string {
+	return filepath.Join(cmd.dbPath, cmd.rp, strconv.FormatUint(id, 10))
+}
+
+// partPath returns the path to the directory of a part while it is built.
+func (cmd *Command) partPath(id uint64) string {
+	return filepath.Join(cmd.shardPath(cmd.shard)+".split", strconv.FormatUint(id, 10))
+}
+
+// backup moves the file or the directory of path to rel under the backup
+// directory. The files are copied if the backup is on another disk.
+func (cmd *Command) backup(path, rel string) error {
+	dst := filepath.Join(cmd.backupDir, rel)
+	fis, err := os.ReadDir(path)
+	if err != nil {
+		if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+			return err
+		}
+		return file.RenameFile(path, dst)
+	}
+	for _, fi := range fis {
+		if err := cmd.backup(filepath.Join(path, fi.Name()), filepath.Join(rel, fi.Name())); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dst, 0777); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// shardGroup returns the shard group of the shard, and checks the time
+// boundaries are within it.
+func (cmd *Command) shardGroup() (*meta.ShardGroupInfo, error) {
+	buf, err := os.ReadFile(filepath.Join(cmd.metaDir, "meta.db"))
+	if err != nil {
+		return nil, err
+	}
+	var data meta.Data
+	if err := data.UnmarshalBinary(buf); err != nil {
+		return nil, fmt.Errorf("cannot read meta data: %w", err)
+	}
+	groups, err := data.ShardGroups(filepath.Base(cmd.dbPath), cmd.rp)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		g := &groups[i]
+		for _, sh := range g.Shards {
+			if sh.ID != cmd.shard {
+				continue
+			}
+			for _, t := range cmd.at {
+				if t <= g.StartTime.UnixNano() || t >= g.EndTime.UnixNano() {
+					return nil, fmt.Errorf("time boundary %s isn't within shard group %d", time.Unix(0, t).UTC().Format(time.RFC3339), g.ID)
+				}
+			}
+			if cmd.hash && len(g.Shards) > 1 {
+				return nil, fmt.Errorf("shard group %d holds other shards", g.ID)
+			}
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("shard %d isn't in the meta data of %s", cmd.shard, cmd.rp)
+}
+
+// part returns the part of the series of a hash split.
+func (cmd *Command) part(seriesKey []byte) int {
+	h := models.NewInlineFNV64a()
+	h.Write(seriesKey)
+	return int(h.Sum64() % uint64(len(cmd.ids)))
+}
+
+// timeRange returns the time range of a part of a time split.
+func (cmd *Command) timeRange(i int) (min, max int64) {
+	min, max = math.MinInt64, math.MaxInt64
+	if i > 0 {
+		min = cmd.at[i-1]
+	}
+	if i < len(cmd.at) {
+		max = cmd.at[i] - 1
+	}
+	return min, max
+}
+
+// splitTSM writes the data of a TSM file to the TSM files of the same name
+// of the parts.
+func (cmd *Command) splitTSM(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	defer r.Close()
+
+	w := cmd.newPartWriter(filepath.Base(path))
+	defer w.Remove()
+
+	itr := r.BlockIterator()
+	for itr.Next() {
+		key, minTime, maxTime, _, _, block, err := itr.Read()
+		if err != nil {
+			return err
+		}
+		series, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+
+		// The tombstoned values are still in the blocks.
+		var tombstones []tsm1.TimeRange
+		for _, t := range r.TombstoneRange(key) {
+			if t.Min <= maxTime && t.Max >= minTime {
+				tombstones = append(tombstones, t)
+			}
+		}
+
+		// Copy the block as is when it belongs to a single part.
+		if len(tombstones) == 0 {
+			if cmd.hash {
+				if err := w.WriteBlock(uint64(cmd.part(series)), key, minTime, maxTime, block); err != nil {
+					return err
+				}
+				continue
+			}
+			if i := sort.Search(len(cmd.at), func(i int) bool { return cmd.at[i] > minTime }); i == len(cmd.at) || cmd.at[i] > maxTime {
+				if err := w.WriteBlock(uint64(i), key, minTime, maxTime, block); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		values, err := tsm1.DecodeBlock(block, nil)
+		if err != nil {
+			return fmt.Errorf("unable to decode block %s in %s: %w", key, path, err)
+		}
+		v := tsm1.Values(values)
+		for _, t := range tombstones {
+			v = v.Exclude(t.Min, t.Max)
+		}
+		if err := cmd.writeValues(w, series, key, v); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// splitWAL writes the data of the WAL of the shard to a TSM file of name in
+// the parts.
+func (cmd *Command) splitWAL(walPath, name string) error {
+	walPaths, err := filepath.Glob(filepath.Join(walPath, "*."+tsm1.WALFileExtension))
+	if err != nil {
+		return err
+	} else if len(walPaths) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(cmd.Stdout, "Splitting the WAL of shard %d\n", cmd.shard)
+	cache := tsm1.NewCache(0)
+	loader := tsm1.NewCacheLoader(walPaths)
+	loader.WithLogger(cmd.logger)
+	if err := loader.Load(cache); err != nil {
+		return err
+	}
+
+	w := cmd.newPartWriter(name)
+	defer w.Remove()
+	for _, key := range cache.Keys() {
+		series, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+		values := cache.Values(key)
+		for len(values) > 0 {
+			n := tsdb.DefaultMaxPointsPerBlock
+			if n > len(values) {
+				n = len(values)
+			}
+			if err := cmd.writeValues(w, series, key, values[:n]); err != nil {
+				return err
+			}
+			values = values[n:]
+		}
+	}
+	return w.Close()
+}
+
+// writeValues writes the values of a key to their parts.
+func (cmd *Command) writeValues(w *shardwriter.Writer, series, key []byte, values tsm1.Values) error {
+	if len(values) == 0 {
+		return nil
+	} else if cmd.hash {
+		return w.Write(uint64(cmd.part(series)), key, values)
+	}
+	for i := range cmd.ids {
+		min, max := cmd.timeRange(i)
+		if v := values.Include(min, max); len(v) > 0 {
+			if err := w.Write(uint64(i), key, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buildIndexes builds the TSI index of each part.
+func (cmd *Command) buildIndexes() error {
+	sfile := tsdb.NewSeriesFile(filepath.Join(cmd.dbPath, tsdb.SeriesFileDirectory))
+	sfile.Logger = cmd.logger
+	if err := sfile.Open(); err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	for _, id := range cmd.ids {
+		fmt.Fprintf(cmd.Stdout, "Building TSI index of shard %d\n", id)
+		if err := buildtsi.IndexShard(sfile, cmd.partPath(id), "", tsdb.DefaultMaxIndexLogFileSize, tsdb.DefaultCacheMaxMemorySize, shardwriter.IndexBatchSize, 0, cmd.logger, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printMetaChanges prints the changes to the meta data needed by the parts.
+func (cmd *Command) printMetaChanges(group *meta.ShardGroupInfo) {
+	format := func(t int64) string { return time.Unix(0, t).UTC().Format(time.RFC3339) }
+
+	fmt.Fprintln(cmd.Stdout, "\nMeta changes, before restarting influxd:")
+	if cmd.hash {
+		name := fmt.Sprintf("The shard group of shard %d", cmd.shard)
+		if group != nil {
+			name = fmt.Sprintf("Shard group %d", group.ID)
+		}
+		fmt.Fprintf(cmd.Stdout, "  %s must hold the shards %s, in this order: the writes are routed by series hash.\n", name, shardList(cmd.ids))
+		return
+	}
+
+	start, end := "its start", "its end"
+	if group != nil {
+		start, end = format(group.StartTime.UnixNano()), format(group.EndTime.UnixNano())
+	}
+	for i, id := range cmd.ids {
+		from, to := start, end
+		if i > 0 {
+			from = format(cmd.at[i-1])
+		}
+		if i < len(cmd.at) {
+			to = format(cmd.at[i])
+		}
+		if i == 0 {
+			fmt.Fprintf(cmd.Stdout, "  The shard group of shard %d: time range from %s to %s.\n", id, from, to)
+		} else {
+			fmt.Fprintf(cmd.Stdout, "  A new shard group holding shard %d: time range from %s to %s.\n", id, from, to)
+		}
+	}
+}
+
+func shardList(ids []uint64) string {
+	a := make([]string, len(ids))
+	for i, id := range ids {
+		a[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(a, ", ")
+}
+
+// newPartWriter returns a writer of the TSM files of name in the parts,
+// keyed by the index of their part.
+func (cmd *Command) newPartWriter(name string) *shardwriter.Writer {
+	return shardwriter.New(func(i uint64) string {
+		return filepath.Join(cmd.partPath(cmd.ids[i]), name)
+	})
+}
@@ -0,0 +1,201 @@
+package splitshard_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/splitshard"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+var t0 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeTSM writes a TSM file with the values of each key at the given hours
+// from t0.
+func writeTSM(t *testing.T, path string, values map[string][]int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	values2 := make(map[string][]tsm1.Value)
+	for k, hours := range values {
+		for _, h := range hours {
+			values2[k] = append(values2[k], tsm1.NewValue(t0.Add(time.Duration(h)*time.Hour).UnixNano(), float64(h)))
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(values2))
+	for k := range values2 {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := w.Write([]byte(k), values2[k]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readShard returns the hours from t0 of the values of each key of the TSM
+// files of a shard.
+func readShard(t *testing.T, dir string) map[string][]int {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tsm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hours := make(map[string][]int)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := tsm1.NewTSMReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < r.KeyCount(); i++ {
+			key, _ := r.KeyAt(i)
+			values, err := r.ReadAll(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range values {
+				hours[string(key)] = append(hours[string(key)], int(time.Duration(v.UnixNano()-t0.UnixNano())/time.Hour))
+			}
+		}
+		r.Close()
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index")); err != nil {
+		t.Errorf("missing index: %s", err)
+	}
+	return hours
+}
+
+func run(t *testing.T, args ...string) string {
+	t.Helper()
+	var stdout bytes.Buffer
+	cmd := splitshard.NewCommand()
+	cmd.Stdout, cmd.Stderr = &stdout, &bytes.Buffer{}
+	if err := cmd.Run(args...); err != nil {
+		t.Fatalf("unexpected error: %s\n%s", err, stdout.String())
+	}
+	return stdout.String()
+}
+
+func TestCommand_Run_Time(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "data", "db0")
+	path := filepath.Join(dbPath, "autogen", "1", "000000001-000000001.tsm")
+	writeTSM(t, path, map[string][]int{
+		"cpu,host=a#!~#value": {0, 6, 18},
+		"mem,host=a#!~#free":  {20},
+	})
+
+	// The last value of cpu is deleted.
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeleteRange([][]byte{[]byte("cpu,host=a#!~#value")}, t0.Add(18*time.Hour).UnixNano(), t0.Add(18*time.Hour).UnixNano()); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+
+	var data meta.Data
+	rpi := meta.DefaultRetentionPolicyInfo()
+	rpi.ShardGroupDuration = 24 * time.Hour
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateRetentionPolicy("db0", rpi, true); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateShardGroup("db0", "autogen", t0); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(dir, "meta.db"), buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	out := run(t, "-db-path", dbPath, "-retention", "autogen", "-shard", "1", "-meta-dir", dir,
+		"-backup-dir", filepath.Join(dir, "backup"), "-at", "2020-01-01T12:00:00Z", "-new-shards", "2")
+	for _, exp := range []string{
+		"Split shard 1 into shards 1, 2",
+		"The shard group of shard 1: time range from 2020-01-01T00:00:00Z to 2020-01-01T12:00:00Z.",
+		"A new shard group holding shard 2: time range from 2020-01-01T12:00:00Z to 2020-01-02T00:00:00Z.",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected %q in output:\n%s", exp, out)
+		}
+	}
+
+	if hours := readShard(t, filepath.Join(dbPath, "autogen", "1")); len(hours) != 1 || len(hours["cpu,host=a#!~#value"]) != 2 {
+		t.Errorf("unexpected shard 1: %v", hours)
+	}
+	if hours := readShard(t, filepath.Join(dbPath, "autogen", "2")); len(hours) != 1 || len(hours["mem,host=a#!~#free"]) != 1 {
+		t.Errorf("unexpected shard 2: %v", hours)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "backup", "autogen", "1", "000000001-000000001.tsm")); err != nil {
+		t.Errorf("missing backup: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dbPath, "autogen", "1.split")); !os.IsNotExist(err) {
+		t.Errorf("parts directory wasn't removed: %v", err)
+	}
+}
+
+func TestCommand_Run_Hash(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "data", "db0")
+	values := make(map[string][]int)
+	for _, host := range []string{"a", "b", "c", "d", "e", "f"} {
+		values["cpu,host="+host+"#!~#value"] = []int{1}
+	}
+	writeTSM(t, filepath.Join(dbPath, "autogen", "1", "000000001-000000001.tsm"), values)
+
+	out := run(t, "-db-path", dbPath, "-retention", "autogen", "-shard", "1",
+		"-backup-dir", filepath.Join(dir, "backup"), "-hash", "2", "-new-shards", "7")
+	if !strings.Contains(out, "must hold the shards 1, 7, in this order") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	// The series are in the shard the writes are routed to.
+	parts := []map[string][]int{readShard(t, filepath.Join(dbPath, "autogen", "1")), readShard(t, filepath.Join(dbPath, "autogen", "7"))}
+	for key := range values {
+		series, _ := tsm1.SeriesAndFieldFromCompositeKey([]byte(key))
+		pt, err := models.NewPoint(strings.SplitN(string(series), ",", 2)[0], models.ParseTags(series), models.Fields{"value": 1.0}, t0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i := pt.HashID() % 2
+		if _, ok := parts[i][key]; !ok {
+			t.Errorf("%s isn't in part %d", key, i)
+		} else if _, ok := parts[1-i][key]; ok {
+			t.Errorf("%s is also in part %d", key, 1-i)
+		}
+	}
+}
@@ -2,12 +2,15 @@
 package tsm
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"hash/crc32"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
 	"text/tabwriter"
 	"time"
 	"unicode/utf8"
@@ -39,20 +42,38 @@ func (cmd *Command) Run(args ...string) error {
 	var checkUTF8 bool
 	fs.BoolVar(&checkUTF8, "check-utf8", false, "Verify series keys are valid UTF-8")
 
+	var workers int
+	fs.IntVar(&workers, "workers", runtime.GOMAXPROCS(0), "Number of files verified in parallel")
+
+	var repair bool
+	fs.BoolVar(&repair, "repair", false, "Remove the corrupt blocks and quarantine the unreadable files")
+
+	var backupDir string
+	fs.StringVar(&backupDir, "backup-dir", "", "Directory the original files are moved to by -repair. [<dir>/verify-backup]")
+
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = cmd.printUsage
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if workers < 1 {
+		return errors.New("-workers must be at least 1")
+	}
+	if repair && checkUTF8 {
+		return errors.New("-repair can't be used with -check-utf8")
+	}
+	if backupDir == "" {
+		backupDir = filepath.Join(path, defaultBackupDir)
+	}
 
 	tw := tabwriter.NewWriter(cmd.Stdout, 16, 8, 0, '\t', 0)
 
 	var runner verifier
 	if checkUTF8 {
-		runner = &verifyUTF8{}
+		runner = &verifyUTF8{verifyTSM: verifyTSM{workers: workers, backupDir: backupDir}}
 	} else {
-		runner = &verifyChecksums{}
+		runner = &verifyChecksums{verifyTSM: verifyTSM{workers: workers, backupDir: backupDir}, repair: repair}
 	}
 	err := runner.Run(tw, path)
 	tw.Flush()
@@ -72,23 +93,47 @@ Usage: influx_inspect verify [flags]
     -check-utf8 
             Verify series keys are valid UTF-8.
             This check skips verification of block checksums.
- `, os.Getenv("HOME"))
+    -workers <n>
+            The number of files verified in parallel.
+            Defaults to the number of CPUs.
+    -repair
+            Rewrite the files with corrupt blocks without them, and quarantine
+            the files that can't be read, such as truncated ones after an
+            unclean shutdown.  The original files are moved to -backup-dir.
+            The server must be stopped.
+    -backup-dir <path>
+            The directory the original files are moved to by -repair, at
+            their path relative to -dir.
+            Defaults to "<dir>/%[2]s".
+ `, os.Getenv("HOME"), defaultBackupDir)
 
 	fmt.Fprintf(cmd.Stdout, usage)
 }
 
+// defaultBackupDir is the directory in the root storage path the original
+// files are moved to by -repair.
+const defaultBackupDir = "verify-backup"
+
 type verifyTSM struct {
-	files []string
-	f     string
-	start time.Time
-	err   error
+	files     []string
+	dataPath  string
+	backupDir string
+	workers   int
+	start     time.Time
+
+	mu  sync.Mutex
+	err error
 }
 
 func (v *verifyTSM) loadFiles(dataPath string) error {
+	v.dataPath = dataPath
 	err := filepath.Walk(dataPath, func(path string, f os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if f.IsDir() && path == v.backupDir {
+			return filepath.SkipDir
+		}
 		if filepath.Ext(path) == "."+tsm1.TSMFileExtension {
 			v.files = append(v.files, path)
 		}
@@ -102,30 +147,71 @@ func (v *verifyTSM) loadFiles(dataPath string) error {
 	return nil
 }
 
-func (v *verifyTSM) Next() bool {
-	if len(v.files) == 0 {
-		return false
+// each calls fn for the files with the workers. The output of each file is
+// written to w at once, in the order the files are done. The first error of
+// fn is returned by Err.
+func (v *verifyTSM) each(w io.Writer, fn func(w io.Writer, path string) error) {
+	files := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < v.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf bytes.Buffer
+			for f := range files {
+				buf.Reset()
+				err := fn(&buf, f)
+
+				v.mu.Lock()
+				w.Write(buf.Bytes())
+				if err != nil && v.err == nil {
+					v.err = err
+				}
+				v.mu.Unlock()
+			}
+		}()
 	}
-
-	v.f, v.files = v.files[0], v.files[1:]
-	return true
+	for _, f := range v.files {
+		files <- f
+	}
+	close(files)
+	wg.Wait()
 }
 
-func (v *verifyTSM) TSMReader() (string, *tsm1.TSMReader) {
-	file, err := os.OpenFile(v.f, os.O_RDONLY, 0600)
+func (v *verifyTSM) TSMReader(path string) (*tsm1.TSMReader, error) {
+	file, err := os.OpenFile(path, os.O_RDONLY, 0600)
 	if err != nil {
-		v.err = err
-		return "", nil
+		return nil, err
 	}
 
 	reader, err := tsm1.NewTSMReader(file)
 	if err != nil {
 		file.Close()
-		v.err = err
-		return "", nil
+		return nil, err
 	}
 
-	return v.f, reader
+	return reader, nil
+}
+
+// backup moves a file to its path relative to the root storage path in the
+// backup directory.
+func (v *verifyTSM) backup(path string) (string, error) {
+	rel, err := filepath.Rel(v.dataPath, path)
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(v.backupDir, rel)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(path, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+func (v *verifyTSM) Err() error {
+	return v.err
 }
 
 func (v *verifyTSM) Start() {
@@ -138,8 +224,10 @@ func (v *verifyTSM) Elapsed() time.Duration {
 
 type verifyChecksums struct {
 	verifyTSM
+	repair      bool
 	totalErrors int
 	total       int
+	repaired    int
 }
 
 func (v *verifyChecksums) Run(w io.Writer, dataPath string) error {
@@ -149,38 +237,152 @@ func (v *verifyChecksums) Run(w io.Writer, dataPath string) error {
 
 	v.Start()
 
-	for v.Next() {
-		f, reader := v.TSMReader()
-		if reader == nil {
-			break
+	v.each(w, v.verifyFile)
+
+	fmt.Fprintf(w, "Broken Blocks: %d / %d, in %vs\n", v.totalErrors, v.total, v.Elapsed().Seconds())
+	if v.repair {
+		fmt.Fprintf(w, "Repaired Files: %d, originals in %s\n", v.repaired, v.backupDir)
+	}
+
+	return v.Err()
+}
+
+// verifyFile verifies the checksums of the blocks of a file, and repairs it
+// with -repair.
+func (v *verifyChecksums) verifyFile(w io.Writer, f string) error {
+	reader, err := v.TSMReader(f)
+	if err != nil {
+		fmt.Fprintf(w, "%s: could not open file due to error: %q\n", f, err)
+		if !v.repair {
+			return err
 		}
+		return v.quarantine(w, f)
+	}
 
-		blockItr := reader.BlockIterator()
-		fileErrors := 0
-		count := 0
-		for blockItr.Next() {
-			v.total++
-			key, _, _, _, checksum, buf, err := blockItr.Read()
+	// broken are the indexes of the broken blocks, by key.
+	broken := make(map[int]map[int]bool)
+	var entries []tsm1.IndexEntry
+	fileErrors := 0
+	count := 0
+	for i, n := 0, reader.KeyCount(); i < n; i++ {
+		var key []byte
+		key, _, entries = reader.Key(i, &entries)
+		for j := range entries {
+			bad := true
+			checksum, buf, err := reader.ReadBytes(&entries[j], nil)
 			if err != nil {
-				v.totalErrors++
-				fileErrors++
 				fmt.Fprintf(w, "%s: could not get checksum for key %v block %d due to error: %q\n", f, key, count, err)
 			} else if expected := crc32.ChecksumIEEE(buf); checksum != expected {
-				v.totalErrors++
-				fileErrors++
 				fmt.Fprintf(w, "%s: got %d but expected %d for key %v, block %d\n", f, checksum, expected, key, count)
+			} else {
+				bad = false
+			}
+			if bad {
+				fileErrors++
+				if broken[i] == nil {
+					broken[i] = make(map[int]bool)
+				}
+				broken[i][j] = true
 			}
 			count++
 		}
-		if fileErrors == 0 {
-			fmt.Fprintf(w, "%s: healthy\n", f)
-		}
+	}
+
+	v.mu.Lock()
+	v.total += count
+	v.totalErrors += fileErrors
+	v.mu.Unlock()
+
+	if fileErrors == 0 {
+		reader.Close()
+		fmt.Fprintf(w, "%s: healthy\n", f)
+		return nil
+	} else if !v.repair {
 		reader.Close()
+		return nil
 	}
 
-	fmt.Fprintf(w, "Broken Blocks: %d / %d, in %vs\n", v.totalErrors, v.total, v.Elapsed().Seconds())
+	err = v.rewrite(reader, broken)
+	reader.Close()
+	if err == tsm1.ErrNoValues {
+		return v.quarantine(w, f)
+	} else if err != nil {
+		return fmt.Errorf("%s: could not repair: %s", f, err)
+	}
 
-	return v.err
+	dst, err := v.backup(f)
+	if err != nil {
+		os.Remove(f + "." + tsm1.TmpTSMFileExtension)
+		return fmt.Errorf("%s: could not back up: %s", f, err)
+	}
+	if err := os.Rename(f+"."+tsm1.TmpTSMFileExtension, f); err != nil {
+		return fmt.Errorf("%s: could not repair: %s", f, err)
+	}
+	fmt.Fprintf(w, "%s: repaired, removed %d / %d blocks, original moved to %s\n", f, fileErrors, count, dst)
+
+	v.mu.Lock()
+	v.repaired++
+	v.mu.Unlock()
+	return nil
+}
+
+// rewrite writes the blocks of a file but the broken ones to a temporary
+// file next to it. It returns tsm1.ErrNoValues if all the blocks are broken.
+func (v *verifyChecksums) rewrite(reader *tsm1.TSMReader, broken map[int]map[int]bool) error {
+	tmp := reader.Path() + "." + tsm1.TmpTSMFileExtension
+	fd, err := os.OpenFile(tmp, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	tw, err := tsm1.NewTSMWriter(fd)
+	if err != nil {
+		fd.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	err = func() error {
+		var entries []tsm1.IndexEntry
+		for i, n := 0, reader.KeyCount(); i < n; i++ {
+			var key []byte
+			key, _, entries = reader.Key(i, &entries)
+			for j := range entries {
+				if broken[i][j] {
+					continue
+				}
+				_, buf, err := reader.ReadBytes(&entries[j], nil)
+				if err != nil {
+					return err
+				}
+				if err := tw.WriteBlock(key, entries[j].MinTime, entries[j].MaxTime, buf); err != nil {
+					return err
+				}
+			}
+		}
+		return tw.WriteIndex()
+	}()
+	if cerr := tw.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		tw.Remove()
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// quarantine moves a file that can't be repaired to the backup directory.
+func (v *verifyChecksums) quarantine(w io.Writer, f string) error {
+	dst, err := v.backup(f)
+	if err != nil {
+		return fmt.Errorf("%s: could not quarantine: %s", f, err)
+	}
+	fmt.Fprintf(w, "%s: quarantined, moved to %s\n", f, dst)
+
+	v.mu.Lock()
+	v.repaired++
+	v.mu.Unlock()
+	return nil
 }
 
 type verifyUTF8 struct {
@@ -196,34 +398,43 @@ func (v *verifyUTF8) Run(w io.Writer, dataPath string) error {
 
 	v.Start()
 
-	for v.Next() {
-		f, reader := v.TSMReader()
-		if reader == nil {
-			break
-		}
-
-		n := reader.KeyCount()
-		fileErrors := 0
-		v.total += n
-		for i := 0; i < n; i++ {
-			key, _ := reader.KeyAt(i)
-			if !utf8.Valid(key) {
-				v.totalErrors++
-				fileErrors++
-				fmt.Fprintf(w, "%s: key #%d is not valid UTF-8\n", f, i)
-			}
-		}
-		if fileErrors == 0 {
-			fmt.Fprintf(w, "%s: healthy\n", f)
-		}
-	}
+	v.each(w, v.verifyFile)
 
 	fmt.Fprintf(w, "Invalid Keys: %d / %d, in %vs\n", v.totalErrors, v.total, v.Elapsed().Seconds())
 	if v.totalErrors > 0 && v.err == nil {
 		v.err = errors.New("check-utf8: failed")
 	}
 
-	return v.err
+	return v.Err()
+}
+
+// verifyFile verifies the keys of a file are valid UTF-8.
+func (v *verifyUTF8) verifyFile(w io.Writer, f string) error {
+	reader, err := v.TSMReader(f)
+	if err != nil {
+		fmt.Fprintf(w, "%s: could not open file due to error: %q\n", f, err)
+		return err
+	}
+	defer reader.Close()
+
+	n := reader.KeyCount()
+	fileErrors := 0
+	for i := 0; i < n; i++ {
+		key, _ := reader.KeyAt(i)
+		if !utf8.Valid(key) {
+			fileErrors++
+			fmt.Fprintf(w, "%s: key #%d is not valid UTF-8\n", f, i)
+		}
+	}
+	if fileErrors == 0 {
+		fmt.Fprintf(w, "%s: healthy\n", f)
+	}
+
+	v.mu.Lock()
+	v.total += n
+	v.totalErrors += fileErrors
+	v.mu.Unlock()
+	return nil
 }
 
 type verifier interface {
@@ -1,3 +1,138 @@
 package tsm_test
 
-// TODO: write some tests
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/verify/tsm"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+// writeTSM writes a TSM file with a block for each key and returns the
+// offset of the block of the first key.
+func writeTSM(t *testing.T, path string, keys ...string) int64 {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if err := w.Write([]byte(k), []tsm1.Value{tsm1.NewValue(1, 1.5), tsm1.NewValue(2, 2.5)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err = os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	return r.Entries([]byte(keys[0]))[0].Offset
+}
+
+func TestCommand_Run_Repair(t *testing.T) {
+	dir := t.TempDir()
+	shard := filepath.Join(dir, "data", "db0", "autogen", "1")
+
+	healthy := filepath.Join(shard, "000000001-000000001.tsm")
+	writeTSM(t, healthy, "cpu#!~#value")
+
+	// The first block of the file is corrupt.
+	corrupt := filepath.Join(shard, "000000002-000000001.tsm")
+	offset := writeTSM(t, corrupt, "cpu#!~#value", "mem#!~#value")
+	buf, err := os.ReadFile(corrupt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf[offset+6] ^= 0xff
+	if err := os.WriteFile(corrupt, buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file was truncated, so its index is gone.
+	truncated := filepath.Join(shard, "000000003-000000001.tsm")
+	writeTSM(t, truncated, "cpu#!~#value")
+	if err := os.Truncate(truncated, 20); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := tsm.NewCommand()
+	cmd.Stdout = &stdout
+	if err := cmd.Run("-dir", dir, "-workers", "2"); err == nil {
+		t.Fatalf("expected an error for the truncated file:\n%s", stdout.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "Broken Blocks: 1 / 3") || !strings.Contains(out, healthy+": healthy") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	stdout.Reset()
+	if err := cmd.Run("-dir", dir, "-repair"); err != nil {
+		t.Fatalf("unexpected error: %s\n%s", err, stdout.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		corrupt + ": repaired, removed 1 / 2 blocks",
+		truncated + ": quarantined",
+		"Repaired Files: 2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+
+	// The repaired file holds the healthy block, and the originals are in
+	// the backup directory.
+	f, err := os.Open(corrupt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := r.KeyCount(); n != 1 {
+		t.Errorf("got %d keys, expected 1", n)
+	} else if key, _ := r.KeyAt(0); string(key) != "mem#!~#value" {
+		t.Errorf("got key %s, expected mem#!~#value", key)
+	}
+	r.Close()
+
+	if _, err := os.Stat(truncated); !os.IsNotExist(err) {
+		t.Errorf("truncated file wasn't quarantined: %v", err)
+	}
+	for _, path := range []string{corrupt, truncated} {
+		rel, _ := filepath.Rel(dir, path)
+		if _, err := os.Stat(filepath.Join(dir, "verify-backup", rel)); err != nil {
+			t.Errorf("missing backup of %s: %s", rel, err)
+		}
+	}
+
+	// The backups aren't verified again.
+	stdout.Reset()
+	if err := cmd.Run("-dir", dir); err != nil {
+		t.Fatalf("unexpected error: %s\n%s", err, stdout.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "Broken Blocks: 0 / 2") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,178 @@
+// Package debug implements the debug subcommand of the influxd command.
+package debug
+
+import (
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultHost is the address of the HTTP API of the server.
+const DefaultHost = "localhost:8086"
+
+// Command represents the program execution for "influxd debug".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	host      string
+	username  string
+	password  string
+	ssl       bool
+	unsafeSSL bool
+
+	cpu time.Duration
+	out string
+}
+
+// NewCommand returns a new instance of Command with default settings.
+func NewCommand() *Command {
+	return &Command{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+// Run executes the program.
+func (cmd *Command) Run(args ...string) error {
+	var name string
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "dump":
+		return cmd.dump(args)
+	case "", "-h", "-help", "--help":
+		cmd.printUsage()
+		return nil
+	default:
+		cmd.printUsage()
+		return fmt.Errorf("unknown debug command %q", name)
+	}
+}
+
+// parseFlags parses the flags of the sub-commands.
+func (cmd *Command) parseFlags(name string, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(cmd.Stderr)
+	fs.Usage = cmd.printUsage
+	fs.StringVar(&cmd.host, "host", DefaultHost, "")
+	fs.StringVar(&cmd.username, "username", "", "")
+	fs.StringVar(&cmd.password, "password", "", "")
+	fs.BoolVar(&cmd.ssl, "ssl", false, "")
+	fs.BoolVar(&cmd.unsafeSSL, "unsafeSsl", false, "")
+	fs.DurationVar(&cmd.cpu, "cpu", 0, "")
+	fs.StringVar(&cmd.out, "out", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	} else if cmd.cpu < 0 {
+		return errors.New("-cpu must not be negative")
+	}
+	return nil
+}
+
+// dump downloads the debug dump of the server to a file.
+func (cmd *Command) dump(args []string) error {
+	if err := cmd.parseFlags("debug dump", args); err != nil {
+		return err
+	}
+
+	u := url.URL{Scheme: "http", Host: cmd.host, Path: "/debug/dump"}
+	if cmd.ssl {
+		u.Scheme = "https"
+	}
+	if cmd.cpu > 0 {
+		u.RawQuery = url.Values{"cpu": []string{cmd.cpu.String()}}.Encode()
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if cmd.username != "" {
+		req.SetBasicAuth(cmd.username, cmd.password)
+	}
+
+	client := &http.Client{
+		// The collection of the CPU profile takes its whole duration.
+		Timeout: time.Minute + cmd.cpu,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: cmd.unsafeSSL},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	out := cmd.out
+	if out == "" {
+		out = "influxdb-dump-" + time.Now().UTC().Format("20060102T150405Z") + ".tar.gz"
+	}
+	f, err := os.OpenFile(out, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, resp.Body)
+	if err != nil {
+		f.Close()
+		os.Remove(out)
+		return err
+	} else if err := f.Close(); err != nil {
+		os.Remove(out)
+		return err
+	}
+
+	fmt.Fprintf(cmd.Stdout, "Wrote %d bytes to %s\n", n, out)
+	return nil
+}
+
+// printUsage prints the usage message to STDOUT.
+func (cmd *Command) printUsage() {
+	fmt.Fprintf(cmd.Stdout, `
+Collects diagnostics of a server for support.
+
+Usage: influxd debug dump [options]
+
+"dump" downloads an archive of the goroutine, heap and (optionally) CPU
+profiles of the server, the results of SHOW SHARDS, SHOW STATS and SHOW
+DIAGNOSTICS, its configuration with the secrets redacted and its recent
+logs. It requires pprof-enabled in the [http] section of the configuration.
+
+Options:
+    -host <host:port>
+            The address of the HTTP API of the server. Defaults to %s.
+    -username <name>
+            The name of an admin user if authentication is enabled.
+    -password <password>
+            The password of the admin user.
+    -ssl
+            Use HTTPS to connect to the server.
+    -unsafeSsl
+            Do not verify the certificate of the server.
+    -cpu <duration>
+            Also collect a CPU profile for a duration, like 30s. It's capped
+            at 5m.
+    -out <path>
+            The file to write the archive to. Defaults to
+            influxdb-dump-<time>.tar.gz in the current directory.
+`, DefaultHost)
+}
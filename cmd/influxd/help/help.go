@@ -35,9 +35,12 @@ The commands are:
 
     backup               downloads a snapshot of a data node and saves it to disk
     config               display the default configuration
+    debug                collects diagnostics of a server for support
     help                 display this help message
     restore              uses a snapshot of a data node to rebuild a cluster
     run                  run node with existing configuration
+    schema               exports or applies the schema of the meta data
+    shard-groups         lists, creates or truncates the shard groups
     version              displays the InfluxDB version
 
 "run" is the default command.
@@ -13,9 +13,12 @@ import (
 
 	"github.com/influxdata/influxdb/cmd"
 	"github.com/influxdata/influxdb/cmd/influxd/backup"
+	"github.com/influxdata/influxdb/cmd/influxd/debug"
 	"github.com/influxdata/influxdb/cmd/influxd/help"
 	"github.com/influxdata/influxdb/cmd/influxd/restore"
 	"github.com/influxdata/influxdb/cmd/influxd/run"
+	"github.com/influxdata/influxdb/cmd/influxd/schema"
+	"github.com/influxdata/influxdb/cmd/influxd/shardgroups"
 )
 
 // These variables are populated via the Go linker.
@@ -118,6 +121,18 @@ func (m *Main) Run(args ...string) error {
 		if err := name.Run(args...); err != nil {
 			return fmt.Errorf("restore: %s", err)
 		}
+	case "schema":
+		if err := schema.NewCommand().Run(args...); err != nil {
+			return fmt.Errorf("schema: %s", err)
+		}
+	case "shard-groups":
+		if err := shardgroups.NewCommand().Run(args...); err != nil {
+			return fmt.Errorf("shard-groups: %s", err)
+		}
+	case "debug":
+		if err := debug.NewCommand().Run(args...); err != nil {
+			return fmt.Errorf("debug: %s", err)
+		}
 	case "config":
 		if err := run.NewPrintConfigCommand().Run(args...); err != nil {
 			return fmt.Errorf("config: %s", err)
@@ -47,6 +47,9 @@ type Command struct {
 	Stderr io.Writer
 	Logger *zap.Logger
 
+	// RecentLogs keeps the recent logs of the server for the debug dumps.
+	RecentLogs *logger.RecentLogs
+
 	Server *Server
 
 	// How to get environment variables. Normally set to os.Getenv, except for tests.
@@ -93,6 +96,8 @@ func (cmd *Command) Run(args ...string) error {
 		// assign the default logger
 		cmd.Logger = logger.New(cmd.Stderr)
 	}
+	cmd.RecentLogs = logger.NewRecentLogs(logger.DefaultRecentLogsSize)
+	cmd.Logger = cmd.RecentLogs.Tee(cmd.Logger, config.Logging.Level)
 
 	// Attempt to run pprof on :6060 before startup if debug pprof enabled.
 	if config.HTTPD.DebugPprofEnabled {
@@ -151,6 +156,7 @@ func (cmd *Command) Run(args ...string) error {
 		return fmt.Errorf("create server: %s", err)
 	}
 	s.Logger = cmd.Logger
+	s.RecentLogs = cmd.RecentLogs
 	s.CPUProfile = options.CPUProfile
 	s.MemProfile = options.MemProfile
 	if err := s.Open(); err != nil {
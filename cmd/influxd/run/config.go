@@ -16,15 +16,19 @@ import (
 	"github.com/influxdata/influxdb/monitor/diagnostics"
 	"github.com/influxdata/influxdb/pkg/tlsconfig"
 	"github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/services/audit"
 	"github.com/influxdata/influxdb/services/collectd"
 	"github.com/influxdata/influxdb/services/continuous_querier"
 	"github.com/influxdata/influxdb/services/graphite"
 	"github.com/influxdata/influxdb/services/httpd"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/metamirror"
 	"github.com/influxdata/influxdb/services/opentsdb"
 	"github.com/influxdata/influxdb/services/precreator"
 	"github.com/influxdata/influxdb/services/retention"
+	"github.com/influxdata/influxdb/services/storage"
 	"github.com/influxdata/influxdb/services/subscriber"
+	"github.com/influxdata/influxdb/services/udf"
 	"github.com/influxdata/influxdb/services/udp"
 	itoml "github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
@@ -45,10 +49,14 @@ type Config struct {
 	FluxController control.Config     `toml:"flux-controller"`
 	Retention      retention.Config   `toml:"retention"`
 	Precreator     precreator.Config  `toml:"shard-precreation"`
+	UDF            udf.Config         `toml:"udf"`
+	Audit          audit.Config       `toml:"audit"`
+	MetaMirror     metamirror.Config  `toml:"meta-mirror"`
 
 	Monitor        monitor.Config    `toml:"monitor"`
 	Subscriber     subscriber.Config `toml:"subscriber"`
 	HTTPD          httpd.Config      `toml:"http"`
+	Storage        storage.Config    `toml:"storage"`
 	Logging        logger.Config     `toml:"logging"`
 	GraphiteInputs []graphite.Config `toml:"graphite"`
 	CollectdInputs []collectd.Config `toml:"collectd"`
@@ -75,10 +83,14 @@ func NewConfig() *Config {
 	c.Coordinator = coordinator.NewConfig()
 	c.FluxController = control.NewConfig()
 	c.Precreator = precreator.NewConfig()
+	c.UDF = udf.NewConfig()
+	c.Audit = audit.NewConfig()
+	c.MetaMirror = metamirror.NewConfig()
 
 	c.Monitor = monitor.NewConfig()
 	c.Subscriber = subscriber.NewConfig()
 	c.HTTPD = httpd.NewConfig()
+	c.Storage = storage.NewConfig()
 	c.Logging = logger.NewConfig()
 
 	c.GraphiteInputs = []graphite.Config{graphite.NewConfig()}
@@ -111,6 +123,9 @@ func NewDemoConfig() (*Config, error) {
 	c.Meta.Dir = filepath.Join(homeDir, ".influxdb/meta")
 	c.Data.Dir = filepath.Join(homeDir, ".influxdb/data")
 	c.Data.WALDir = filepath.Join(homeDir, ".influxdb/wal")
+	c.Coordinator.ReplicationDir = filepath.Join(homeDir, ".influxdb/replication")
+	c.Coordinator.WriteQueueDir = filepath.Join(homeDir, ".influxdb/write-queue")
+	c.Subscriber.HintedHandoffDir = filepath.Join(homeDir, ".influxdb/hh")
 
 	return c, nil
 }
@@ -159,6 +174,10 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.Coordinator.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Monitor.Validate(); err != nil {
 		return err
 	}
@@ -175,10 +194,26 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.UDF.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Audit.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.MetaMirror.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Subscriber.Validate(); err != nil {
 		return err
 	}
 
+	if err := c.Storage.Validate(); err != nil {
+		return fmt.Errorf("invalid storage config: %v", err)
+	}
+
 	for _, graphite := range c.GraphiteInputs {
 		if err := graphite.Validate(); err != nil {
 			return fmt.Errorf("invalid graphite config: %v", err)
@@ -221,10 +256,14 @@ func (c *Config) diagnosticsClients() map[string]diagnostics.Client {
 		"config-coordinator": c.Coordinator,
 		"config-retention":   c.Retention,
 		"config-precreator":  c.Precreator,
+		"config-udf":         c.UDF,
+		"config-audit":       c.Audit,
+		"config-meta-mirror": c.MetaMirror,
 
 		"config-monitor":    c.Monitor,
 		"config-subscriber": c.Subscriber,
 		"config-httpd":      c.HTTPD,
+		"config-storage":    c.Storage,
 
 		"config-cqs": c.ContinuousQuery,
 	}
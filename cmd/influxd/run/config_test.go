@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -519,3 +520,38 @@ enabled = true
 		t.Fatalf("unexpected continuous query enabled: %v", c.ContinuousQuery.Enabled)
 	}
 }
+
+// Ensure the secrets are redacted from the configuration of the debug dumps.
+func TestConfig_Redacted(t *testing.T) {
+	c := run.NewConfig()
+	c.HTTPD.SharedSecret = "super secret key"
+	c.Monitor.OTLPHeaders = map[string]string{"Authorization": "Bearer abc"}
+	c.HTTPD.BindAddress = ":8087"
+
+	b, err := c.Redacted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, secret := range []string{"super secret key", "Bearer abc"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %q not redacted:\n%s", secret, out)
+		}
+	}
+	for _, exp := range []string{
+		`shared-secret = "[REDACTED]"`,
+		`Authorization = "[REDACTED]"`,
+		`bind-address = ":8087"`,
+		`bind-password = ""`,
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("missing %q in:\n%s", exp, out)
+		}
+	}
+
+	// The redacted configuration is still valid.
+	var parsed run.Config
+	if err := parsed.FromToml(out); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+package run
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/logger"
+)
+
+// redactedValue replaces the secrets of the configuration in the debug dumps.
+const redactedValue = `"[REDACTED]"`
+
+var (
+	// redactedKey matches the keys of the settings that hold secrets.
+	redactedKey = regexp.MustCompile(`(?i)(password|secret|token|webhook)`)
+
+	configTable   = regexp.MustCompile(`^\s*\[\[?\s*([^\]]+?)\s*\]\]?\s*$`)
+	configSetting = regexp.MustCompile(`^(\s*)("[^"]*"|[A-Za-z0-9_-]+)(\s*=\s*)(.*)$`)
+)
+
+// serverDump provides the configuration and the recent logs of a server to
+// the debug dumps.
+type serverDump struct {
+	config *Config
+	logs   *logger.RecentLogs
+}
+
+// Config returns the configuration of the server in TOML, with its secrets
+// redacted.
+func (d *serverDump) Config() ([]byte, error) {
+	return d.config.Redacted()
+}
+
+// Logs returns the recent logs of the server.
+func (d *serverDump) Logs() []byte {
+	if d.logs == nil {
+		return nil
+	}
+	return d.logs.Bytes()
+}
+
+// Redacted returns the configuration in TOML with the values of the settings
+// that hold secrets, like passwords and the headers sent to other services,
+// replaced. The settings that are not set are left empty.
+func (c *Config) Redacted() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	var table string
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(make([]byte, 0, 64*1024), buf.Len()+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := configTable.FindStringSubmatch(line); m != nil {
+			table = m[1]
+		} else if m := configSetting.FindStringSubmatch(line); m != nil && m[4] != `""` {
+			// The headers are redacted as a whole since any of them may
+			// hold credentials.
+			if redactedKey.MatchString(m[2]) || strings.HasSuffix(table, "headers") {
+				line = m[1] + m[2] + m[3] + redactedValue
+			}
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -25,17 +25,20 @@ import (
 	prometheus2 "github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/services/audit"
 	"github.com/influxdata/influxdb/services/collectd"
 	"github.com/influxdata/influxdb/services/continuous_querier"
 	"github.com/influxdata/influxdb/services/graphite"
 	"github.com/influxdata/influxdb/services/httpd"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/metamirror"
 	"github.com/influxdata/influxdb/services/opentsdb"
 	"github.com/influxdata/influxdb/services/precreator"
 	"github.com/influxdata/influxdb/services/retention"
 	"github.com/influxdata/influxdb/services/snapshotter"
 	"github.com/influxdata/influxdb/services/storage"
 	"github.com/influxdata/influxdb/services/subscriber"
+	"github.com/influxdata/influxdb/services/udf"
 	"github.com/influxdata/influxdb/services/udp"
 	reads "github.com/influxdata/influxdb/storage/flux"
 	"github.com/influxdata/influxdb/tcp"
@@ -85,7 +88,13 @@ type Server struct {
 	TSDBStore     *tsdb.Store
 	QueryExecutor *query.Executor
 	PointsWriter  *coordinator.PointsWriter
+	QueryCache    *coordinator.QueryCache
+	Replicator    *coordinator.Replicator
+	ShadowWriter  *coordinator.ShadowWriter
+	WriteQueue    *coordinator.WriteQueue
 	Subscriber    *subscriber.Service
+	UDF           *udf.Service
+	Audit         *audit.Service
 
 	Services []Service
 
@@ -96,6 +105,9 @@ type Server struct {
 
 	Monitor *monitor.Monitor
 
+	// RecentLogs keeps the recent logs for the debug dumps, if not nil.
+	RecentLogs *logger.RecentLogs
+
 	// Server reporting and registration
 	reportingDisabled bool
 
@@ -134,6 +146,7 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	// not already specified (set the default).
 	updateTLSConfig(&c.HTTPD.TLS, tlsConfig)
 	updateTLSConfig(&c.Subscriber.TLS, tlsConfig)
+	updateTLSConfig(&c.Storage.TLS, tlsConfig)
 	for i := range c.OpenTSDBInputs {
 		updateTLSConfig(&c.OpenTSDBInputs[i].TLS, tlsConfig)
 	}
@@ -209,10 +222,47 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	// Create the Subscriber service
 	s.Subscriber = subscriber.NewService(c.Subscriber)
 
+	// Initialize the query cache that is shared by the points writer and
+	// the query executor.
+	s.QueryCache = coordinator.NewQueryCache(c.Coordinator)
+
 	// Initialize points writer.
 	s.PointsWriter = coordinator.NewPointsWriter()
 	s.PointsWriter.WriteTimeout = time.Duration(c.Coordinator.WriteTimeout)
+	s.PointsWriter.MaxFutureWrite = time.Duration(c.Coordinator.MaxFutureWrite)
+	s.PointsWriter.DedupWindow = time.Duration(c.Coordinator.DedupWindow)
+	s.PointsWriter.DedupMaxPoints = c.Coordinator.DedupMaxPoints
+	s.PointsWriter.LogWritesAfter = time.Duration(c.Coordinator.LogWritesAfter)
 	s.PointsWriter.TSDBStore = s.TSDBStore
+	s.PointsWriter.QueryCache = s.QueryCache
+
+	// Initialize the replication of the writes to remote servers.
+	s.Replicator = coordinator.NewReplicator(c.Coordinator)
+	s.Replicator.TLS = tlsConfig
+
+	// Initialize the shadow writes to a secondary server.
+	s.ShadowWriter = coordinator.NewShadowWriter(c.Coordinator)
+	s.ShadowWriter.TLS = tlsConfig
+
+	// Initialize the queue of the writes acknowledged before they are applied.
+	s.WriteQueue = coordinator.NewWriteQueue(c.Coordinator)
+	s.WriteQueue.PointsWriter = s.PointsWriter
+
+	// Create the service of the user-defined functions. The functions are
+	// loaded when the service is opened.
+	var functions query.UserFunctions
+	if c.UDF.Enabled {
+		s.UDF = udf.NewService(c.UDF)
+		functions = s.UDF
+	}
+
+	// Create the service that records the administrative changes.
+	var auditLog interface{ Record(audit.Event) }
+	if c.Audit.Enabled {
+		s.Audit = audit.NewService(c.Audit)
+		s.Audit.Monitor = s.Monitor
+		auditLog = s.Audit
+	}
 
 	// Initialize query executor.
 	s.QueryExecutor = query.NewExecutor()
@@ -230,11 +280,18 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 		MaxSelectPointN:     c.Coordinator.MaxSelectPointN,
 		MaxSelectSeriesN:    c.Coordinator.MaxSelectSeriesN,
 		MaxSelectBucketsN:   c.Coordinator.MaxSelectBucketsN,
+		QueryCache:          s.QueryCache,
+		UserFunctions:       functions,
+		AuditLog:            auditLog,
 	}
 	s.QueryExecutor.TaskManager.QueryTimeout = time.Duration(c.Coordinator.QueryTimeout)
 	s.QueryExecutor.TaskManager.LogQueriesAfter = time.Duration(c.Coordinator.LogQueriesAfter)
 	s.QueryExecutor.TaskManager.MaxConcurrentQueries = c.Coordinator.MaxConcurrentQueries
 	s.QueryExecutor.TaskManager.LogTimedoutQueries = c.Coordinator.LogTimedOutQueries
+	s.QueryExecutor.TaskManager.Policies = c.Coordinator.Policies()
+	s.QueryExecutor.TaskManager.OnPolicyViolation = s.recordKilledQuery
+	s.QueryExecutor.TaskManager.PriorityClasses = c.Coordinator.PriorityClasses()
+	s.QueryExecutor.TaskManager.DefaultPriority = c.Coordinator.DefaultQueryPriority
 
 	// Initialize the monitor
 	s.Monitor.Version = s.buildInfo.Version
@@ -251,6 +308,10 @@ func (s *Server) Statistics(tags map[string]string) []models.Statistic {
 	statistics = append(statistics, s.QueryExecutor.Statistics(tags)...)
 	statistics = append(statistics, s.TSDBStore.Statistics(tags)...)
 	statistics = append(statistics, s.PointsWriter.Statistics(tags)...)
+	statistics = append(statistics, s.QueryCache.Statistics(tags)...)
+	statistics = append(statistics, s.Replicator.Statistics(tags)...)
+	statistics = append(statistics, s.ShadowWriter.Statistics(tags)...)
+	statistics = append(statistics, s.WriteQueue.Statistics(tags)...)
 	statistics = append(statistics, s.Subscriber.Statistics(tags)...)
 	for _, srv := range s.Services {
 		if m, ok := srv.(monitor.Reporter); ok {
@@ -268,10 +329,20 @@ func (s *Server) appendSnapshotterService() {
 	srv := snapshotter.NewService()
 	srv.TSDBStore = s.TSDBStore
 	srv.MetaClient = s.MetaClient
+	srv.AcceptMetaMirror = s.config.MetaMirror.Standby
 	s.Services = append(s.Services, srv)
 	s.SnapshotterService = srv
 }
 
+func (s *Server) appendMetaMirrorService(c metamirror.Config) {
+	if !c.Enabled {
+		return
+	}
+	srv := metamirror.NewService(c)
+	srv.MetaClient = s.MetaClient
+	s.Services = append(s.Services, srv)
+}
+
 // SetLogOutput sets the logger used for all messages. It must not be called
 // after the Open method has been called.
 func (s *Server) SetLogOutput(w io.Writer) {
@@ -305,11 +376,15 @@ func (s *Server) appendHTTPDService(c httpd.Config) error {
 	srv.Handler.WriteAuthorizer = meta.NewWriteAuthorizer(s.MetaClient)
 	srv.Handler.QueryExecutor = s.QueryExecutor
 	srv.Handler.Monitor = s.Monitor
+	srv.Handler.Dump = &serverDump{config: s.config, logs: s.RecentLogs}
 	srv.Handler.PointsWriter = s.PointsWriter
 	srv.Handler.Version = s.buildInfo.Version
 	srv.Handler.BuildType = "OSS"
 	ss := storage.NewStore(s.TSDBStore, s.MetaClient)
 	srv.Handler.Store = ss
+	if s.Audit != nil {
+		srv.Handler.AuditLog = s.Audit
+	}
 	if s.config.HTTPD.FluxEnabled {
 		storageDep, err := influxdb2.NewDependencies(s.MetaClient, reads.NewReader(ss), authorizer, c.AuthEnabled, s.PointsWriter)
 		if err != nil {
@@ -336,6 +411,20 @@ func (s *Server) appendHTTPDService(c httpd.Config) error {
 	return nil
 }
 
+// appendStorageService serves the reads of the storage engine to external
+// query engines, with the authentication of the HTTP API.
+func (s *Server) appendStorageService(c storage.Config) {
+	if !c.Enabled {
+		return
+	}
+	srv := storage.NewService(c)
+	srv.Store = storage.NewStore(s.TSDBStore, s.MetaClient)
+	srv.MetaClient = s.MetaClient
+	srv.Authorizer = meta.NewQueryAuthorizer(s.MetaClient)
+	srv.AuthEnabled = s.config.HTTPD.AuthEnabled
+	s.Services = append(s.Services, srv)
+}
+
 func (s *Server) appendCollectdService(c collectd.Config) {
 	if !c.Enabled {
 		return
@@ -386,6 +475,24 @@ func (s *Server) appendPrecreatorService(c precreator.Config) error {
 	return nil
 }
 
+// appendUDFService loads the user-defined functions before the services that
+// accept queries are opened.
+func (s *Server) appendUDFService() {
+	if s.UDF == nil {
+		return
+	}
+	s.Services = append(s.Services, s.UDF)
+}
+
+// appendAuditService opens the audit log before the services that accept
+// queries are opened.
+func (s *Server) appendAuditService() {
+	if s.Audit == nil {
+		return
+	}
+	s.Services = append(s.Services, s.Audit)
+}
+
 func (s *Server) appendUDPService(c udp.Config) {
 	if !c.Enabled {
 		return
@@ -430,12 +537,16 @@ func (s *Server) Open() error {
 
 	// Append services.
 	s.appendMonitorService()
+	s.appendUDFService()
+	s.appendAuditService()
 	s.appendPrecreatorService(s.config.Precreator)
 	s.appendSnapshotterService()
+	s.appendMetaMirrorService(s.config.MetaMirror)
 	s.appendContinuousQueryService(s.config.ContinuousQuery)
 	if err := s.appendHTTPDService(s.config.HTTPD); err != nil {
 		return err
 	}
+	s.appendStorageService(s.config.Storage)
 	s.appendRetentionPolicyService(s.config.Retention)
 	for _, i := range s.config.GraphiteInputs {
 		if err := s.appendGraphiteService(i); err != nil {
@@ -474,6 +585,9 @@ func (s *Server) Open() error {
 		s.QueryExecutor.TaskManager.Logger = s.Logger
 	}
 	s.PointsWriter.WithLogger(s.Logger)
+	s.Replicator.WithLogger(s.Logger)
+	s.ShadowWriter.WithLogger(s.Logger)
+	s.WriteQueue.WithLogger(s.Logger)
 	s.Subscriber.WithLogger(s.Logger)
 	for _, svc := range s.Services {
 		svc.WithLogger(s.Logger)
@@ -494,11 +608,33 @@ func (s *Server) Open() error {
 		return fmt.Errorf("open subscriber: %s", err)
 	}
 
+	// Open the replication queues before the PointsWriter replicates to them
+	if err := s.Replicator.Open(); err != nil {
+		return fmt.Errorf("open replicator: %s", err)
+	}
+	s.PointsWriter.Replicator = s.Replicator
+
+	// Open the shadow writer before the PointsWriter mirrors writes to it.
+	if err := s.ShadowWriter.Open(); err != nil {
+		return fmt.Errorf("open shadow writer: %s", err)
+	}
+	if s.config.Coordinator.ShadowWrite.URL != "" {
+		s.PointsWriter.ShadowWriter = s.ShadowWriter
+	}
+
 	// Open the points writer service
 	if err := s.PointsWriter.Open(); err != nil {
 		return fmt.Errorf("open points writer: %s", err)
 	}
 
+	// Open the write queue once the PointsWriter applies its writes.
+	if s.config.Coordinator.WriteQueueEnabled {
+		if err := s.WriteQueue.Open(); err != nil {
+			return fmt.Errorf("open write queue: %s", err)
+		}
+		s.PointsWriter.WriteQueue = s.WriteQueue
+	}
+
 	for _, service := range s.Services {
 		if err := service.Open(); err != nil {
 			return fmt.Errorf("open service: %s", err)
@@ -530,10 +666,24 @@ func (s *Server) Close() error {
 
 	s.config.deregisterDiagnostics(s.Monitor)
 
+	// Stop applying the queued writes before the PointsWriter is closed.
+	// The writes left are applied once the server is restarted.
+	if s.WriteQueue != nil {
+		s.WriteQueue.Close()
+	}
+
 	if s.PointsWriter != nil {
 		s.PointsWriter.Close()
 	}
 
+	if s.Replicator != nil {
+		s.Replicator.Close()
+	}
+
+	if s.ShadowWriter != nil {
+		s.ShadowWriter.Close()
+	}
+
 	if s.QueryExecutor != nil {
 		s.QueryExecutor.Close()
 	}
@@ -704,6 +854,34 @@ func (pw *monitorPointsWriter) WritePoints(database, retentionPolicy string, poi
 	return (*coordinator.PointsWriter)(pw).WritePointsPrivileged(writeCtx, database, retentionPolicy, models.ConsistencyLevelAny, points)
 }
 
+// recordKilledQuery writes a query that was killed by a query policy into the
+// killed_queries measurement of the monitor database.
+func (s *Server) recordKilledQuery(k query.KilledQuery) {
+	if !s.Monitor.Enabled() {
+		return
+	}
+
+	tags := map[string]string{"policy": k.Policy}
+	if k.Database != "" {
+		tags["database"] = k.Database
+	}
+	if k.User != "" {
+		tags["user"] = k.User
+	}
+	pt, err := models.NewPoint("killed_queries", models.NewTags(tags), models.Fields{
+		"qid":        int64(k.ID),
+		"query":      k.Query,
+		"reason":     k.Reason,
+		"durationNs": k.Duration.Nanoseconds(),
+	}, time.Now())
+	if err == nil {
+		err = (*monitorPointsWriter)(s.PointsWriter).WritePoints(s.config.Monitor.StoreDatabase, monitor.MonitorRetentionPolicy, models.Points{pt})
+	}
+	if err != nil {
+		s.Logger.Info("Failed to record killed query", zap.Uint64("qid", k.ID), zap.Error(err))
+	}
+}
+
 func raftDBExists(dir string) error {
 	// Check to see if there is a raft db, if so, error out with a message
 	// to downgrade, export, and then import the meta data
@@ -0,0 +1,240 @@
+// Package schema implements the schema subcommand of the influxd command.
+package schema
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultHost is the address of the HTTP API of the server.
+const DefaultHost = "localhost:8086"
+
+// Command represents the program execution for "influxd schema".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	host      string
+	username  string
+	password  string
+	ssl       bool
+	unsafeSSL bool
+}
+
+// NewCommand returns a new instance of Command with default settings.
+func NewCommand() *Command {
+	return &Command{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+// Run executes the program.
+func (cmd *Command) Run(args ...string) error {
+	var name string
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "export":
+		return cmd.export(args)
+	case "apply":
+		return cmd.apply(args)
+	case "", "-h", "-help", "--help":
+		cmd.printUsage()
+		return nil
+	default:
+		cmd.printUsage()
+		return fmt.Errorf("unknown schema command %q", name)
+	}
+}
+
+// flagSet returns the flags shared by the sub-commands.
+func (cmd *Command) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(cmd.Stderr)
+	fs.Usage = cmd.printUsage
+	fs.StringVar(&cmd.host, "host", DefaultHost, "")
+	fs.StringVar(&cmd.username, "username", "", "")
+	fs.StringVar(&cmd.password, "password", "", "")
+	fs.BoolVar(&cmd.ssl, "ssl", false, "")
+	fs.BoolVar(&cmd.unsafeSSL, "unsafeSsl", false, "")
+	return fs
+}
+
+// export writes the schema of the server to a file or STDOUT.
+func (cmd *Command) export(args []string) error {
+	fs := cmd.flagSet("schema export")
+	out := fs.String("out", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	resp, err := cmd.do("GET", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Err string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Err == "" {
+			return fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+		return errors.New(e.Err)
+	}
+
+	if *out == "" {
+		_, err := io.Copy(cmd.Stdout, resp.Body)
+		return err
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// apply changes the meta data of the server to match the schema in a file
+// or STDIN and prints the executed statements.
+func (cmd *Command) apply(args []string) error {
+	fs := cmd.flagSet("schema apply")
+	prune := fs.Bool("prune", false, "")
+	dryRun := fs.Bool("dry-run", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return errors.New("exactly one schema file is required")
+	}
+
+	var body []byte
+	var err error
+	if path := fs.Arg(0); path == "-" {
+		body, err = ioutil.ReadAll(cmd.Stdin)
+	} else {
+		body, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	if *prune {
+		params.Set("prune", "true")
+	}
+	if *dryRun {
+		params.Set("dry_run", "true")
+	}
+	resp, err := cmd.do("POST", params, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The statements that were executed are returned with the error of the
+	// statement that failed.
+	var result struct {
+		Statements []string `json:"statements"`
+		Err        string   `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	for _, stmt := range result.Statements {
+		fmt.Fprintln(cmd.Stdout, stmt)
+	}
+	if result.Err != "" {
+		return errors.New(result.Err)
+	} else if len(result.Statements) == 0 {
+		fmt.Fprintln(cmd.Stderr, "schema is up to date")
+	}
+	return nil
+}
+
+// do sends a request to the schema endpoint of the server.
+func (cmd *Command) do(method string, params url.Values, body io.Reader) (*http.Response, error) {
+	u := url.URL{Scheme: "http", Host: cmd.host, Path: "/schema", RawQuery: params.Encode()}
+	if cmd.ssl {
+		u.Scheme = "https"
+	}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.username != "" {
+		req.SetBasicAuth(cmd.username, cmd.password)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{
+		Timeout: time.Minute,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: cmd.unsafeSSL},
+		},
+	}
+	return client.Do(req)
+}
+
+// printUsage prints the usage message to STDOUT.
+func (cmd *Command) printUsage() {
+	fmt.Fprintf(cmd.Stdout, `
+Exports the databases, retention policies, continuous queries, subscriptions
+and users of a server as a JSON schema, or changes them to match a schema.
+
+Usage: influxd schema export [options]
+       influxd schema apply [options] PATH
+
+Options:
+    -host <host:port>
+            The address of the HTTP API of the server. Defaults to %s.
+    -username <name>
+            The name of an admin user if authentication is enabled.
+    -password <password>
+            The password of the admin user.
+    -ssl
+            Use HTTPS to connect to the server.
+    -unsafeSsl
+            Do not verify the certificate of the server.
+
+Export options:
+    -out <path>
+            The file to write the schema to. Defaults to STDOUT.
+
+Apply options:
+    -prune
+            Drop the databases, retention policies, continuous queries,
+            subscriptions and users that are not in the schema.
+    -dry-run
+            Print the statements that would be executed without executing them.
+
+The schema is read from STDIN if PATH is "-". Passwords are never exported.
+A user that does not exist requires a "password" in the schema, and the
+password of an existing user is changed if it is given and differs.
+`, DefaultHost)
+}
@@ -0,0 +1,247 @@
+// Package shardgroups implements the shard-groups subcommand of the influxd
+// command.
+package shardgroups
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+// DefaultHost is the address of the HTTP API of the server.
+const DefaultHost = "localhost:8086"
+
+// Command represents the program execution for "influxd shard-groups".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	host      string
+	username  string
+	password  string
+	ssl       bool
+	unsafeSSL bool
+
+	database        string
+	retentionPolicy string
+	time            string
+}
+
+// NewCommand returns a new instance of Command with default settings.
+func NewCommand() *Command {
+	return &Command{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+// Run executes the program.
+func (cmd *Command) Run(args ...string) error {
+	var name string
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "list":
+		return cmd.list(args)
+	case "create":
+		return cmd.create(args)
+	case "truncate":
+		return cmd.truncate(args)
+	case "", "-h", "-help", "--help":
+		cmd.printUsage()
+		return nil
+	default:
+		cmd.printUsage()
+		return fmt.Errorf("unknown shard-groups command %q", name)
+	}
+}
+
+// parseFlags parses the flags shared by the sub-commands.
+func (cmd *Command) parseFlags(name string, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(cmd.Stderr)
+	fs.Usage = cmd.printUsage
+	fs.StringVar(&cmd.host, "host", DefaultHost, "")
+	fs.StringVar(&cmd.username, "username", "", "")
+	fs.StringVar(&cmd.password, "password", "", "")
+	fs.BoolVar(&cmd.ssl, "ssl", false, "")
+	fs.BoolVar(&cmd.unsafeSSL, "unsafeSsl", false, "")
+	fs.StringVar(&cmd.database, "database", "", "")
+	fs.StringVar(&cmd.retentionPolicy, "retention", "", "")
+	fs.StringVar(&cmd.time, "time", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	} else if cmd.retentionPolicy != "" && cmd.database == "" {
+		return errors.New("-retention requires -database")
+	}
+	return nil
+}
+
+// parseTime returns the time of the -time flag, or zero if it is not set.
+func (cmd *Command) parseTime() (time.Time, error) {
+	if cmd.time == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, cmd.time)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC3339 format", cmd.time)
+	}
+	return t, nil
+}
+
+// list prints the shard groups of the server.
+func (cmd *Command) list(args []string) error {
+	if err := cmd.parseFlags("shard-groups list", args); err != nil {
+		return err
+	}
+	return cmd.execute(&query.ShowShardGroupsStatement{Database: cmd.database, RetentionPolicy: cmd.retentionPolicy})
+}
+
+// create creates the shard group that contains a time and prints it.
+func (cmd *Command) create(args []string) error {
+	if err := cmd.parseFlags("shard-groups create", args); err != nil {
+		return err
+	} else if cmd.database == "" {
+		return errors.New("-database is required")
+	}
+	t, err := cmd.parseTime()
+	if err != nil {
+		return err
+	}
+	return cmd.execute(&query.CreateShardGroupStatement{Database: cmd.database, RetentionPolicy: cmd.retentionPolicy, Time: t})
+}
+
+// truncate truncates the shard groups that could contain times after a time.
+func (cmd *Command) truncate(args []string) error {
+	if err := cmd.parseFlags("shard-groups truncate", args); err != nil {
+		return err
+	}
+	t, err := cmd.parseTime()
+	if err != nil {
+		return err
+	}
+	return cmd.execute(&query.TruncateShardGroupsStatement{Database: cmd.database, RetentionPolicy: cmd.retentionPolicy, Time: t})
+}
+
+// execute executes a statement on the server and prints its results as a
+// table.
+func (cmd *Command) execute(stmt influxql.Statement) error {
+	u := url.URL{Scheme: "http", Host: cmd.host, Path: "/query"}
+	if cmd.ssl {
+		u.Scheme = "https"
+	}
+	form := url.Values{"q": []string{stmt.String()}}
+	req, err := http.NewRequest("POST", u.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cmd.username != "" {
+		req.SetBasicAuth(cmd.username, cmd.password)
+	}
+
+	client := &http.Client{
+		Timeout: time.Minute,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: cmd.unsafeSSL},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Results []struct {
+			Series []struct {
+				Columns []string        `json:"columns"`
+				Values  [][]interface{} `json:"values"`
+			} `json:"series"`
+			Err string `json:"error"`
+		} `json:"results"`
+		Err string `json:"error"`
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&response); err != nil {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	} else if response.Err != "" {
+		return errors.New(response.Err)
+	}
+
+	w := tabwriter.NewWriter(cmd.Stdout, 0, 8, 2, ' ', 0)
+	for _, result := range response.Results {
+		if result.Err != "" {
+			return errors.New(result.Err)
+		}
+		for _, series := range result.Series {
+			fmt.Fprintln(w, strings.Join(series.Columns, "\t"))
+			for _, values := range series.Values {
+				fields := make([]string, len(values))
+				for i, v := range values {
+					fields[i] = fmt.Sprint(v)
+				}
+				fmt.Fprintln(w, strings.Join(fields, "\t"))
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// printUsage prints the usage message to STDOUT.
+func (cmd *Command) printUsage() {
+	fmt.Fprintf(cmd.Stdout, `
+Lists, creates or truncates the shard groups of a server.
+
+Usage: influxd shard-groups list [options]
+       influxd shard-groups create -database <name> [options]
+       influxd shard-groups truncate [options]
+
+"list" prints the shard groups with their time bounds. "create" creates the
+shard group that contains a time ahead of the writes. "truncate" ends the
+shard groups that could contain times after a time, so the points written
+after it go to new shard groups, for example before a schema change.
+
+Options:
+    -host <host:port>
+            The address of the HTTP API of the server. Defaults to %s.
+    -username <name>
+            The name of an admin user if authentication is enabled.
+    -password <password>
+            The password of the admin user.
+    -ssl
+            Use HTTPS to connect to the server.
+    -unsafeSsl
+            Do not verify the certificate of the server.
+    -database <name>
+            The database of the shard groups. Defaults to all the databases,
+            except for "create".
+    -retention <name>
+            The retention policy of the shard groups. Defaults to all the
+            retention policies of the database, or to its default retention
+            policy for "create".
+    -time <time>
+            The time in RFC3339 format the shard group contains for "create",
+            or the shard groups are truncated at for "truncate". Defaults to
+            the current time.
+`, DefaultHost)
+}
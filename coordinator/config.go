@@ -3,6 +3,11 @@
 package coordinator
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
@@ -25,11 +30,66 @@ const (
 	// DefaultMaxSelectSeriesN is the maximum number of series a SELECT can run.
 	// A value of zero will make the maximum series count unlimited.
 	DefaultMaxSelectSeriesN = 0
+
+	// DefaultQueryCacheMaxEntries is the maximum number of results held by the query cache.
+	DefaultQueryCacheMaxEntries = 1000
+
+	// DefaultQueryCacheMaxRows is the maximum number of rows in a result held by the query cache.
+	DefaultQueryCacheMaxRows = 10000
+
+	// DefaultQueryCacheTTL is the maximum amount of time a result is held by the query cache.
+	DefaultQueryCacheTTL = 10 * time.Minute
+
+	// DefaultReplicationMaxQueueSize is the maximum size of the queue of the
+	// writes to replicate to a target.
+	DefaultReplicationMaxQueueSize = 1 << 30
+
+	// DefaultReplicationBatchSize is the maximum size of the line protocol
+	// sent to a target in a request.
+	DefaultReplicationBatchSize = 1 << 20
+
+	// DefaultReplicationTimeout is the timeout of a request to a target.
+	DefaultReplicationTimeout = 30 * time.Second
+
+	// DefaultReplicationRetryInterval is the time to wait before sending the
+	// writes to a target again after a failure.
+	DefaultReplicationRetryInterval = 5 * time.Second
+
+	// DefaultDedupMaxPoints is the maximum number of points remembered to
+	// drop their duplicates in each half of the dedup window.
+	DefaultDedupMaxPoints = 1000000
+
+	// DefaultShadowWritePercent is the percentage of the writes mirrored to
+	// the secondary server of the shadow writes.
+	DefaultShadowWritePercent = 100.0
+
+	// DefaultShadowWriteConcurrency is the number of mirrored writes sent to
+	// the secondary server at the same time.
+	DefaultShadowWriteConcurrency = 4
+
+	// DefaultShadowWriteMaxPendingWrites is the maximum number of mirrored
+	// writes waiting to be sent.
+	DefaultShadowWriteMaxPendingWrites = 1000
+
+	// DefaultShadowWriteTimeout is the timeout of a mirrored write.
+	DefaultShadowWriteTimeout = 10 * time.Second
+
+	// DefaultWriteQueueMaxSize is the maximum size of the writes waiting in
+	// the write queue to be applied.
+	DefaultWriteQueueMaxSize = 1 << 30
+
+	// DefaultQueryPriority is the priority class of the queries that don't
+	// select one.
+	DefaultQueryPriority = query.PriorityInteractive
 )
 
 // Config represents the configuration for the coordinator service.
 type Config struct {
 	WriteTimeout         toml.Duration `toml:"write-timeout"`
+	MaxFutureWrite       toml.Duration `toml:"max-future-write"`
+	DedupWindow          toml.Duration `toml:"dedup-window"`
+	DedupMaxPoints       int           `toml:"dedup-max-points"`
+	LogWritesAfter       toml.Duration `toml:"log-writes-after"`
 	MaxConcurrentQueries int           `toml:"max-concurrent-queries"`
 	QueryTimeout         toml.Duration `toml:"query-timeout"`
 	LogQueriesAfter      toml.Duration `toml:"log-queries-after"`
@@ -38,31 +98,286 @@ type Config struct {
 	MaxSelectSeriesN     int           `toml:"max-select-series"`
 	MaxSelectBucketsN    int           `toml:"max-select-buckets"`
 	TerminationQueryLog  bool          `toml:"termination-query-log"`
+
+	QueryCacheDatabases  []string      `toml:"query-cache-databases"`
+	QueryCacheMaxEntries int           `toml:"query-cache-max-entries"`
+	QueryCacheMaxRows    int           `toml:"query-cache-max-rows"`
+	QueryCacheTTL        toml.Duration `toml:"query-cache-ttl"`
+
+	QueryPolicies []QueryPolicyConfig `toml:"query-policy"`
+
+	DefaultQueryPriority string                `toml:"default-query-priority"`
+	QueryPriorities      []QueryPriorityConfig `toml:"query-priority"`
+
+	// Directory of the queues of the writes to replicate. It is required if
+	// there is any replication target.
+	ReplicationDir string              `toml:"replication-dir"`
+	Replications   []ReplicationConfig `toml:"replication"`
+
+	ShadowWrite ShadowWriteConfig `toml:"shadow-write"`
+
+	// The write queue acknowledges the writes that request it once they are
+	// queued in its directory, before they are applied.
+	WriteQueueEnabled bool      `toml:"write-queue-enabled"`
+	WriteQueueDir     string    `toml:"write-queue-dir"`
+	WriteQueueMaxSize toml.Size `toml:"write-queue-max-size"`
+}
+
+// QueryPolicyConfig represents the configuration of a policy that kills the
+// queries of a user or a database that exceed its limits.
+type QueryPolicyConfig struct {
+	Name       string        `toml:"name"`
+	User       string        `toml:"user"`
+	Database   string        `toml:"database"`
+	MaxRuntime toml.Duration `toml:"max-runtime"`
+	MaxSeriesN int           `toml:"max-series"`
+	MaxPointN  int           `toml:"max-points"`
+}
+
+// QueryPriorityConfig represents the limits of the queries of a priority
+// class. The queries of a class that is not configured are not limited.
+type QueryPriorityConfig struct {
+	Name                 string        `toml:"name"`
+	MaxConcurrentQueries int           `toml:"max-concurrent-queries"`
+	MaxQueuedQueries     int           `toml:"max-queued-queries"`
+	QueueTimeout         toml.Duration `toml:"queue-timeout"`
+}
+
+// ReplicationConfig represents the configuration of a remote InfluxDB the
+// accepted writes are replicated to. A zero limit or duration is the default.
+type ReplicationConfig struct {
+	Name string `toml:"name"`
+
+	// Base URL of the HTTP API of the remote InfluxDB, such as
+	// "https://dr.example.com:8086".
+	URL      string `toml:"url"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+
+	// Databases and measurements of the writes that are replicated. An
+	// empty list replicates all of them.
+	Databases    []string `toml:"databases"`
+	Measurements []string `toml:"measurements"`
+
+	// PEM encoded CA certificates of the remote InfluxDB. The system
+	// certificates are used if it is empty.
+	CaCerts            string `toml:"ca-certs"`
+	InsecureSkipVerify bool   `toml:"insecure-skip-verify"`
+
+	MaxQueueSize  toml.Size     `toml:"max-queue-size"`
+	BatchSize     toml.Size     `toml:"batch-size"`
+	Timeout       toml.Duration `toml:"timeout"`
+	RetryInterval toml.Duration `toml:"retry-interval"`
+
+	// Maximum number of points sent per second, so that a target that is
+	// back isn't overwhelmed by the writes it missed. Zero is unlimited.
+	MaxDrainRate int `toml:"max-drain-rate"`
+}
+
+// ShadowWriteConfig represents the configuration of the shadow writes, which
+// mirror a percentage of the writes to a secondary InfluxDB to compare its
+// latency and errors with the ones of this server. The shadow writes are
+// disabled if the URL is empty. A zero limit or duration is the default.
+type ShadowWriteConfig struct {
+	URL      string `toml:"url"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+
+	// Databases of the writes that are mirrored. An empty list mirrors all
+	// of them.
+	Databases []string `toml:"databases"`
+
+	// Percentage of the writes that are mirrored, from 0 to 100.
+	Percent float64 `toml:"percent"`
+
+	CaCerts            string `toml:"ca-certs"`
+	InsecureSkipVerify bool   `toml:"insecure-skip-verify"`
+
+	Concurrency      int           `toml:"concurrency"`
+	MaxPendingWrites int           `toml:"max-pending-writes"`
+	Timeout          toml.Duration `toml:"timeout"`
+}
+
+// Validate validates that the configuration is acceptable.
+func (c Config) Validate() error {
+	if c.MaxFutureWrite < 0 {
+		return errors.New("max-future-write must not be negative")
+	}
+	if c.DedupWindow < 0 {
+		return errors.New("dedup-window must not be negative")
+	} else if c.DedupMaxPoints < 0 {
+		return errors.New("dedup-max-points must not be negative")
+	} else if c.LogWritesAfter < 0 {
+		return errors.New("log-writes-after must not be negative")
+	}
+	if err := c.validateReplications(); err != nil {
+		return err
+	}
+	if err := c.ShadowWrite.validate(); err != nil {
+		return err
+	}
+	if c.WriteQueueEnabled && c.WriteQueueDir == "" {
+		return errors.New("write-queue-dir must be specified")
+	}
+
+	names := make(map[string]struct{}, len(c.QueryPolicies))
+	for _, p := range c.QueryPolicies {
+		if p.Name == "" {
+			return errors.New("query-policy name must be set")
+		} else if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicate query-policy name: %s", p.Name)
+		} else if p.MaxRuntime < 0 || p.MaxSeriesN < 0 || p.MaxPointN < 0 {
+			return fmt.Errorf("query-policy %s: limits must not be negative", p.Name)
+		}
+		names[p.Name] = struct{}{}
+	}
+	return c.validateQueryPriorities()
+}
+
+func (c Config) validateQueryPriorities() error {
+	if c.DefaultQueryPriority != "" && !query.ValidPriority(c.DefaultQueryPriority) {
+		return fmt.Errorf("unknown default-query-priority: %s", c.DefaultQueryPriority)
+	}
+
+	names := make(map[string]struct{}, len(c.QueryPriorities))
+	for _, p := range c.QueryPriorities {
+		if !query.ValidPriority(p.Name) {
+			return fmt.Errorf("unknown query-priority name: %q, expected one of %s", p.Name, strings.Join(query.Priorities, ", "))
+		} else if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicate query-priority name: %s", p.Name)
+		} else if p.MaxConcurrentQueries < 0 || p.MaxQueuedQueries < 0 || p.QueueTimeout < 0 {
+			return fmt.Errorf("query-priority %s: limits must not be negative", p.Name)
+		}
+		names[p.Name] = struct{}{}
+	}
+	return nil
+}
+
+func (c Config) validateReplications() error {
+	if len(c.Replications) > 0 && c.ReplicationDir == "" {
+		return errors.New("replication-dir must be specified")
+	}
+
+	names := make(map[string]struct{}, len(c.Replications))
+	for _, r := range c.Replications {
+		if r.Name == "" {
+			return errors.New("replication name must be set")
+		} else if strings.ContainsAny(r.Name, `/\`) || r.Name == "." || r.Name == ".." {
+			return fmt.Errorf("invalid replication name: %s", r.Name)
+		} else if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("duplicate replication name: %s", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		if u, err := url.Parse(r.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("replication %s: invalid url %q", r.Name, r.URL)
+		} else if r.Timeout < 0 || r.RetryInterval < 0 {
+			return fmt.Errorf("replication %s: durations must not be negative", r.Name)
+		} else if r.MaxDrainRate < 0 {
+			return fmt.Errorf("replication %s: max-drain-rate must not be negative", r.Name)
+		} else if r.CaCerts != "" {
+			if _, err := os.Stat(r.CaCerts); err != nil {
+				return fmt.Errorf("replication %s: %s", r.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c ShadowWriteConfig) validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("shadow-write: invalid url %q", c.URL)
+	} else if c.Percent < 0 || c.Percent > 100 {
+		return fmt.Errorf("shadow-write: percent must be between 0 and 100: %v", c.Percent)
+	} else if c.Concurrency < 0 || c.MaxPendingWrites < 0 || c.Timeout < 0 {
+		return errors.New("shadow-write: limits must not be negative")
+	} else if c.CaCerts != "" {
+		if _, err := os.Stat(c.CaCerts); err != nil {
+			return fmt.Errorf("shadow-write: %s", err)
+		}
+	}
+	return nil
+}
+
+// Policies returns the query policies of the configuration.
+func (c Config) Policies() []query.QueryPolicy {
+	policies := make([]query.QueryPolicy, 0, len(c.QueryPolicies))
+	for _, p := range c.QueryPolicies {
+		policies = append(policies, query.QueryPolicy{
+			Name:       p.Name,
+			User:       p.User,
+			Database:   p.Database,
+			MaxRuntime: time.Duration(p.MaxRuntime),
+			MaxSeriesN: p.MaxSeriesN,
+			MaxPointN:  p.MaxPointN,
+		})
+	}
+	return policies
+}
+
+// PriorityClasses returns the limits of the query priority classes of the
+// configuration.
+func (c Config) PriorityClasses() map[string]query.PriorityClass {
+	classes := make(map[string]query.PriorityClass, len(c.QueryPriorities))
+	for _, p := range c.QueryPriorities {
+		classes[p.Name] = query.PriorityClass{
+			MaxConcurrentQueries: p.MaxConcurrentQueries,
+			MaxQueuedQueries:     p.MaxQueuedQueries,
+			QueueTimeout:         time.Duration(p.QueueTimeout),
+		}
+	}
+	return classes
 }
 
 // NewConfig returns an instance of Config with defaults.
 func NewConfig() Config {
 	return Config{
 		WriteTimeout:         toml.Duration(DefaultWriteTimeout),
+		DedupMaxPoints:       DefaultDedupMaxPoints,
 		QueryTimeout:         toml.Duration(query.DefaultQueryTimeout),
 		MaxConcurrentQueries: DefaultMaxConcurrentQueries,
 		MaxSelectPointN:      DefaultMaxSelectPointN,
 		MaxSelectSeriesN:     DefaultMaxSelectSeriesN,
 		TerminationQueryLog:  false,
 		LogTimedOutQueries:   false,
+		QueryCacheMaxEntries: DefaultQueryCacheMaxEntries,
+		QueryCacheMaxRows:    DefaultQueryCacheMaxRows,
+		QueryCacheTTL:        toml.Duration(DefaultQueryCacheTTL),
+		DefaultQueryPriority: DefaultQueryPriority,
+		ShadowWrite: ShadowWriteConfig{
+			Percent: DefaultShadowWritePercent,
+		},
 	}
 }
 
 // Diagnostics returns a diagnostics representation of a subset of the Config.
 func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
-		"write-timeout":          c.WriteTimeout,
-		"max-concurrent-queries": c.MaxConcurrentQueries,
-		"query-timeout":          c.QueryTimeout,
-		"log-queries-after":      c.LogQueriesAfter,
-		"log-timedout-queries":   c.LogTimedOutQueries,
-		"max-select-point":       c.MaxSelectPointN,
-		"max-select-series":      c.MaxSelectSeriesN,
-		"max-select-buckets":     c.MaxSelectBucketsN,
+		"write-timeout":           c.WriteTimeout,
+		"max-future-write":        c.MaxFutureWrite,
+		"dedup-window":            c.DedupWindow,
+		"dedup-max-points":        c.DedupMaxPoints,
+		"log-writes-after":        c.LogWritesAfter,
+		"max-concurrent-queries":  c.MaxConcurrentQueries,
+		"query-timeout":           c.QueryTimeout,
+		"log-queries-after":       c.LogQueriesAfter,
+		"log-timedout-queries":    c.LogTimedOutQueries,
+		"max-select-point":        c.MaxSelectPointN,
+		"max-select-series":       c.MaxSelectSeriesN,
+		"max-select-buckets":      c.MaxSelectBucketsN,
+		"query-cache-databases":   c.QueryCacheDatabases,
+		"query-cache-max-entries": c.QueryCacheMaxEntries,
+		"query-cache-max-rows":    c.QueryCacheMaxRows,
+		"query-cache-ttl":         c.QueryCacheTTL,
+		"query-policies":          len(c.QueryPolicies),
+		"default-query-priority":  c.DefaultQueryPriority,
+		"query-priorities":        len(c.QueryPriorities),
+		"replications":            len(c.Replications),
+		"shadow-write-enabled":    c.ShadowWrite.URL != "",
+		"write-queue-enabled":     c.WriteQueueEnabled,
+		"write-queue-max-size":    c.WriteQueueMaxSize,
 	}), nil
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/query"
 )
 
 func TestConfig_Parse(t *testing.T) {
@@ -13,6 +14,9 @@ func TestConfig_Parse(t *testing.T) {
 	var c coordinator.Config
 	if _, err := toml.Decode(`
 write-timeout = "20s"
+dedup-window = "1m"
+dedup-max-points = 1000
+log-writes-after = "500ms"
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -20,5 +24,234 @@ write-timeout = "20s"
 	// Validate configuration.
 	if time.Duration(c.WriteTimeout) != 20*time.Second {
 		t.Fatalf("unexpected write timeout s: %s", c.WriteTimeout)
+	} else if time.Duration(c.DedupWindow) != time.Minute || c.DedupMaxPoints != 1000 {
+		t.Fatalf("unexpected dedup window: %s %d", c.DedupWindow, c.DedupMaxPoints)
+	} else if time.Duration(c.LogWritesAfter) != 500*time.Millisecond {
+		t.Fatalf("unexpected log writes after: %s", c.LogWritesAfter)
+	}
+
+	c.DedupWindow = -1
+	if err := c.Validate(); err == nil || err.Error() != "dedup-window must not be negative" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.DedupWindow = 0
+	c.WriteQueueEnabled = true
+	if err := c.Validate(); err == nil || err.Error() != "write-queue-dir must be specified" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestConfig_Parse_QueryPolicies(t *testing.T) {
+	var c coordinator.Config
+	if _, err := toml.Decode(`
+[[query-policy]]
+name = "adhoc"
+user = "bob"
+max-runtime = "30s"
+
+[[query-policy]]
+name = "dashboards"
+database = "telegraf"
+max-series = 1000
+max-points = 100000
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	policies := c.Policies()
+	if len(policies) != 2 {
+		t.Fatalf("unexpected policies: %+v", policies)
+	} else if p := policies[0]; p.Name != "adhoc" || p.User != "bob" || p.MaxRuntime != 30*time.Second {
+		t.Fatalf("unexpected policy: %+v", p)
+	} else if p := policies[1]; p.Name != "dashboards" || p.Database != "telegraf" || p.MaxSeriesN != 1000 || p.MaxPointN != 100000 {
+		t.Fatalf("unexpected policy: %+v", p)
+	}
+}
+
+func TestConfig_Validate_QueryPolicies(t *testing.T) {
+	for _, tt := range []struct {
+		policies []coordinator.QueryPolicyConfig
+		err      string
+	}{
+		{
+			policies: []coordinator.QueryPolicyConfig{{MaxSeriesN: 1}},
+			err:      "query-policy name must be set",
+		},
+		{
+			policies: []coordinator.QueryPolicyConfig{{Name: "a"}, {Name: "a"}},
+			err:      "duplicate query-policy name: a",
+		},
+		{
+			policies: []coordinator.QueryPolicyConfig{{Name: "a", MaxPointN: -1}},
+			err:      "query-policy a: limits must not be negative",
+		},
+	} {
+		c := coordinator.NewConfig()
+		c.QueryPolicies = tt.policies
+		if err := c.Validate(); err == nil || err.Error() != tt.err {
+			t.Errorf("unexpected error: got=%v want=%s", err, tt.err)
+		}
+	}
+}
+
+func TestConfig_Parse_QueryPriorities(t *testing.T) {
+	c := coordinator.NewConfig()
+	if _, err := toml.Decode(`
+default-query-priority = "dashboard"
+
+[[query-priority]]
+name = "batch"
+max-concurrent-queries = 2
+max-queued-queries = 10
+queue-timeout = "1m"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	} else if c.DefaultQueryPriority != query.PriorityDashboard {
+		t.Fatalf("unexpected default priority: %s", c.DefaultQueryPriority)
+	}
+
+	classes := c.PriorityClasses()
+	if len(classes) != 1 {
+		t.Fatalf("unexpected priority classes: %+v", classes)
+	} else if p := classes[query.PriorityBatch]; p.MaxConcurrentQueries != 2 || p.MaxQueuedQueries != 10 || p.QueueTimeout != time.Minute {
+		t.Fatalf("unexpected priority class: %+v", p)
+	}
+}
+
+func TestConfig_Validate_QueryPriorities(t *testing.T) {
+	for _, tt := range []struct {
+		defaultPriority string
+		priorities      []coordinator.QueryPriorityConfig
+		err             string
+	}{
+		{
+			defaultPriority: "urgent",
+			err:             "unknown default-query-priority: urgent",
+		},
+		{
+			priorities: []coordinator.QueryPriorityConfig{{Name: "urgent"}},
+			err:        `unknown query-priority name: "urgent", expected one of interactive, dashboard, batch`,
+		},
+		{
+			priorities: []coordinator.QueryPriorityConfig{{Name: "batch"}, {Name: "batch"}},
+			err:        "duplicate query-priority name: batch",
+		},
+		{
+			priorities: []coordinator.QueryPriorityConfig{{Name: "batch", MaxQueuedQueries: -1}},
+			err:        "query-priority batch: limits must not be negative",
+		},
+	} {
+		c := coordinator.NewConfig()
+		if tt.defaultPriority != "" {
+			c.DefaultQueryPriority = tt.defaultPriority
+		}
+		c.QueryPriorities = tt.priorities
+		if err := c.Validate(); err == nil || err.Error() != tt.err {
+			t.Errorf("unexpected error: got=%v want=%s", err, tt.err)
+		}
+	}
+}
+
+func TestConfig_Parse_Replications(t *testing.T) {
+	var c coordinator.Config
+	if _, err := toml.Decode(`
+replication-dir = "/var/lib/influxdb/replication"
+
+[[replication]]
+name = "dr"
+url = "https://dr.example.com:8086"
+databases = ["db0"]
+max-queue-size = "10m"
+retry-interval = "1s"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	} else if len(c.Replications) != 1 {
+		t.Fatalf("unexpected replications: %+v", c.Replications)
+	} else if r := c.Replications[0]; r.Name != "dr" || r.URL != "https://dr.example.com:8086" || len(r.Databases) != 1 || r.MaxQueueSize != 10<<20 || time.Duration(r.RetryInterval) != time.Second {
+		t.Fatalf("unexpected replication: %+v", r)
+	}
+}
+
+func TestConfig_Validate_Replications(t *testing.T) {
+	for _, tt := range []struct {
+		dir          string
+		replications []coordinator.ReplicationConfig
+		err          string
+	}{
+		{
+			replications: []coordinator.ReplicationConfig{{Name: "a", URL: "http://a:8086"}},
+			err:          "replication-dir must be specified",
+		},
+		{
+			dir:          "/tmp",
+			replications: []coordinator.ReplicationConfig{{URL: "http://a:8086"}},
+			err:          "replication name must be set",
+		},
+		{
+			dir:          "/tmp",
+			replications: []coordinator.ReplicationConfig{{Name: "../a", URL: "http://a:8086"}},
+			err:          "invalid replication name: ../a",
+		},
+		{
+			dir:          "/tmp",
+			replications: []coordinator.ReplicationConfig{{Name: "a", URL: "http://a:8086"}, {Name: "a", URL: "http://b:8086"}},
+			err:          "duplicate replication name: a",
+		},
+		{
+			dir:          "/tmp",
+			replications: []coordinator.ReplicationConfig{{Name: "a", URL: "udp://a:8089"}},
+			err:          `replication a: invalid url "udp://a:8089"`,
+		},
+		{
+			dir:          "/tmp",
+			replications: []coordinator.ReplicationConfig{{Name: "a", URL: "http://a:8086", Timeout: -1}},
+			err:          "replication a: durations must not be negative",
+		},
+	} {
+		c := coordinator.NewConfig()
+		c.ReplicationDir = tt.dir
+		c.Replications = tt.replications
+		if err := c.Validate(); err == nil || err.Error() != tt.err {
+			t.Errorf("unexpected error: got=%v want=%s", err, tt.err)
+		}
+	}
+}
+
+func TestConfig_Validate_ShadowWrite(t *testing.T) {
+	for _, tt := range []struct {
+		config coordinator.ShadowWriteConfig
+		err    string
+	}{
+		{
+			config: coordinator.ShadowWriteConfig{URL: "dr.example.com:8086", Percent: 10},
+			err:    `shadow-write: invalid url "dr.example.com:8086"`,
+		},
+		{
+			config: coordinator.ShadowWriteConfig{URL: "http://dr.example.com:8086", Percent: 101},
+			err:    "shadow-write: percent must be between 0 and 100: 101",
+		},
+		{
+			config: coordinator.ShadowWriteConfig{URL: "http://dr.example.com:8086", Concurrency: -1},
+			err:    "shadow-write: limits must not be negative",
+		},
+	} {
+		c := coordinator.NewConfig()
+		c.ShadowWrite = tt.config
+		if err := c.Validate(); err == nil || err.Error() != tt.err {
+			t.Errorf("unexpected error: got=%v want=%s", err, tt.err)
+		}
 	}
 }
@@ -0,0 +1,110 @@
+package coordinator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/influxdata/influxdb/models"
+)
+
+// pointDeduplicator drops the points that are exact duplicates, with the
+// same series key, timestamp and field values, of points written within a
+// window, like the batches that at-least-once pipelines send again after a
+// timeout.
+//
+// The points are remembered by a hash in two generations of at most a
+// window each, so the memory is bounded by the points written in two
+// windows, and by maxPoints per generation.
+type pointDeduplicator struct {
+	window    time.Duration
+	maxPoints int
+
+	mu       sync.Mutex
+	start    time.Time        // start of the current generation
+	current  map[uint64]int64 // time each point was written at, by hash
+	previous map[uint64]int64
+}
+
+func newPointDeduplicator(window time.Duration, maxPoints int) *pointDeduplicator {
+	return &pointDeduplicator{
+		window:    window,
+		maxPoints: maxPoints,
+		current:   make(map[uint64]int64),
+	}
+}
+
+// Filter returns the points that are not duplicates of points written
+// within the window, or of other points of the same write, and their hashes
+// that are passed to Add once the points are written. The points are not
+// copied if there are no duplicates.
+func (d *pointDeduplicator) Filter(database, retentionPolicy string, points []models.Point) ([]models.Point, []uint64) {
+	var (
+		buf    []byte
+		hashes = make([]uint64, 0, len(points))
+		seen   = make(map[uint64]struct{}, len(points))
+		kept   = points
+	)
+
+	now := time.Now().UnixNano()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, p := range points {
+		buf = append(buf[:0], database...)
+		buf = append(buf, 0)
+		buf = append(buf, retentionPolicy...)
+		buf = append(buf, 0)
+		buf = p.AppendString(buf)
+		h := xxhash.Sum64(buf)
+
+		_, dup := seen[h]
+		dup = dup || d.seen(h, now)
+		if dup && len(kept) == len(points) {
+			// Copy the points kept so far on the first duplicate.
+			kept = append(make([]models.Point, 0, len(points)), points[:i]...)
+		}
+		if dup {
+			continue
+		} else if len(kept) < len(points) {
+			kept = append(kept, p)
+		}
+		seen[h] = struct{}{}
+		hashes = append(hashes, h)
+	}
+	return kept, hashes
+}
+
+// seen returns true if the point with the hash was written within the
+// window. It must be called under the lock.
+func (d *pointDeduplicator) seen(h uint64, now int64) bool {
+	t, ok := d.current[h]
+	if !ok {
+		t, ok = d.previous[h]
+	}
+	return ok && now-t <= int64(d.window)
+}
+
+// Add records that the points with the hashes are written.
+func (d *pointDeduplicator) Add(hashes []uint64) {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Start a new generation once the current one spans the window or holds
+	// the maximum number of points. The points of the current generation
+	// are all older than the window if it started two windows ago.
+	if now.Sub(d.start) >= d.window || (d.maxPoints > 0 && len(d.current)+len(hashes) > d.maxPoints) {
+		if now.Sub(d.start) >= 2*d.window {
+			d.previous = nil
+		} else {
+			d.previous = d.current
+		}
+		d.current = make(map[uint64]int64, len(d.previous))
+		d.start = now
+	}
+
+	for _, h := range hashes {
+		d.current[h] = now.UnixNano()
+	}
+}
@@ -0,0 +1,256 @@
+package coordinator
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// The keys for the statistics of a hinted handoff queue, which are reported
+// by the modules that forward writes through one.
+const (
+	statHandoffQueueBytes   = "queueBytes"   // Size of the writes that are not sent yet
+	statHandoffQueueFull    = "queueFull"    // Number of writes dropped because the queue was full
+	statHandoffWriteOK      = "writeOk"      // Number of batches of writes sent to the destination
+	statHandoffWriteErr     = "writeError"   // Number of batches that failed and are retried
+	statHandoffWriteDropped = "writeDropped" // Number of batches the destination rejected
+	statHandoffPointsSent   = "pointsSent"   // Number of points sent to the destination
+	statHandoffLagNs        = "lagNs"        // Age of the oldest write that is not sent yet
+)
+
+// ErrHandoffRejected is matched by the errors of the writes that a destination
+// rejects as invalid. They are dropped rather than retried.
+var ErrHandoffRejected = errors.New("write rejected by the destination")
+
+// HandoffConfig is the configuration of a hinted handoff queue. A zero limit
+// or duration is the default.
+type HandoffConfig struct {
+	// Dir is the directory of the queue.
+	Dir string
+
+	// MaxSize is the maximum size of the writes that are not sent yet. The
+	// new writes are dropped once it is reached.
+	MaxSize int64
+
+	// BatchSize is the maximum size of the writes sent at once.
+	BatchSize int
+
+	// RetryInterval is the time to wait before sending the writes again
+	// after a failure.
+	RetryInterval time.Duration
+
+	// MaxDrainRate is the maximum number of points sent per second, so a
+	// destination that is back isn't overwhelmed by the writes it missed.
+	// Zero means no limit.
+	MaxDrainRate int
+}
+
+// HandoffWrite is a write queued in a hinted handoff queue.
+type HandoffWrite struct {
+	// Time the write was queued at, in nanoseconds.
+	Time int64
+
+	Database        string
+	RetentionPolicy string
+
+	// Number of points and their line protocol with timestamps in
+	// nanoseconds.
+	N    int
+	Data []byte
+}
+
+// HandoffQueue is a hinted handoff queue of the writes forwarded to a
+// destination, like a replication target or a subscription. The writes are
+// queued durably on disk, so an outage of the destination is absorbed
+// locally up to the maximum size of the queue, and they are sent once it is
+// back, also after a restart.
+type HandoffQueue struct {
+	config  HandoffConfig
+	queue   *replicationQueue
+	limiter *rate.Limiter
+	logger  *zap.Logger
+
+	// oldest is the time the oldest unsent write was queued at, or zero if
+	// all the writes are sent.
+	oldest int64
+
+	// full is set while appends fail because the queue is full, so that
+	// only the first failure is logged.
+	full int32
+
+	stats handoffStatistics
+}
+
+type handoffStatistics struct {
+	QueueFull    int64
+	WriteOK      int64
+	WriteErr     int64
+	WriteDropped int64
+	PointsSent   int64
+}
+
+// OpenHandoffQueue opens the hinted handoff queue of a configuration,
+// creating its directory if it doesn't exist.
+func OpenHandoffQueue(c HandoffConfig, log *zap.Logger) (*HandoffQueue, error) {
+	if c.MaxSize == 0 {
+		c.MaxSize = DefaultReplicationMaxQueueSize
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = DefaultReplicationBatchSize
+	}
+	if c.RetryInterval == 0 {
+		c.RetryInterval = DefaultReplicationRetryInterval
+	}
+
+	q, err := openReplicationQueue(c.Dir, c.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &HandoffQueue{config: c, queue: q, logger: log}
+	if c.MaxDrainRate > 0 {
+		h.limiter = rate.NewLimiter(rate.Limit(c.MaxDrainRate), c.MaxDrainRate)
+	}
+	return h, nil
+}
+
+// Close closes the queue. The unsent writes are sent once it is opened again.
+func (h *HandoffQueue) Close() error {
+	return h.queue.Close()
+}
+
+// Append queues n points of line protocol with timestamps in nanoseconds. The
+// write is dropped if the queue is full.
+func (h *HandoffQueue) Append(database, retentionPolicy string, n int, data []byte) error {
+	now := time.Now().UnixNano()
+	rec := replicationRecord{Time: now, Database: database, RetentionPolicy: retentionPolicy, N: n, Data: data}
+	if err := h.queue.Append(&rec); err != nil {
+		atomic.AddInt64(&h.stats.QueueFull, 1)
+		if atomic.CompareAndSwapInt32(&h.full, 0, 1) {
+			h.logger.Warn("Dropping writes to forward", zap.Error(err))
+		}
+		return err
+	}
+	atomic.CompareAndSwapInt64(&h.oldest, 0, now)
+	atomic.StoreInt32(&h.full, 0)
+	return nil
+}
+
+// Drain sends the queued writes in batches with send until closing is
+// closed. A batch that fails is sent again after the retry interval, unless
+// the error matches ErrHandoffRejected, in which case it is dropped.
+func (h *HandoffQueue) Drain(closing <-chan struct{}, send func([]HandoffWrite) error) {
+	for {
+		recs, pos, err := h.queue.Peek(h.config.BatchSize)
+		if err != nil {
+			h.logger.Info("Failed to read the writes to forward", zap.Error(err))
+			if !wait(closing, h.config.RetryInterval) {
+				return
+			}
+			continue
+		} else if len(recs) == 0 {
+			atomic.StoreInt64(&h.oldest, 0)
+			select {
+			case <-closing:
+				return
+			case <-h.queue.notify:
+			}
+			continue
+		}
+		atomic.StoreInt64(&h.oldest, recs[0].Time)
+
+		writes := make([]HandoffWrite, len(recs))
+		n := 0
+		for i, rec := range recs {
+			writes[i] = HandoffWrite{Time: rec.Time, Database: rec.Database, RetentionPolicy: rec.RetentionPolicy, N: rec.N, Data: rec.Data}
+			n += rec.N
+		}
+		if !h.throttle(closing, n) {
+			return
+		}
+
+		if err := send(writes); err == nil {
+			atomic.AddInt64(&h.stats.WriteOK, 1)
+			atomic.AddInt64(&h.stats.PointsSent, int64(n))
+		} else if errors.Is(err, ErrHandoffRejected) {
+			// Sending the points again would be rejected again.
+			atomic.AddInt64(&h.stats.WriteDropped, 1)
+			h.logger.Warn("Dropping writes rejected by the destination", zap.Error(err))
+		} else {
+			atomic.AddInt64(&h.stats.WriteErr, 1)
+			h.logger.Info("Failed to forward writes", zap.Error(err))
+			if !wait(closing, h.config.RetryInterval) {
+				return
+			}
+			continue
+		}
+
+		if err := h.queue.Advance(pos); err != nil {
+			h.logger.Info("Failed to save the position of the forwarded writes", zap.Error(err))
+		}
+	}
+}
+
+// throttle waits until n points can be sent within the maximum drain rate.
+// It returns false if closing is closed meanwhile.
+func (h *HandoffQueue) throttle(closing <-chan struct{}, n int) bool {
+	if h.limiter == nil {
+		return true
+	}
+	for n > 0 {
+		// A reservation can't exceed the burst, which is a second worth of
+		// points.
+		k := n
+		if burst := h.limiter.Burst(); k > burst {
+			k = burst
+		}
+		if d := h.limiter.ReserveN(time.Now(), k).Delay(); d > 0 && !wait(closing, d) {
+			return false
+		}
+		n -= k
+	}
+	return true
+}
+
+// Size returns the size of the writes that are not sent yet.
+func (h *HandoffQueue) Size() int64 {
+	return h.queue.Size()
+}
+
+// Statistic returns the statistics of the queue under a name.
+func (h *HandoffQueue) Statistic(name string, tags map[string]string) models.Statistic {
+	var lag int64
+	if oldest, now := atomic.LoadInt64(&h.oldest), time.Now().UnixNano(); oldest > 0 && now > oldest {
+		lag = now - oldest
+	}
+	return models.Statistic{
+		Name: name,
+		Tags: tags,
+		Values: map[string]interface{}{
+			statHandoffQueueBytes:   h.queue.Size(),
+			statHandoffQueueFull:    atomic.LoadInt64(&h.stats.QueueFull),
+			statHandoffWriteOK:      atomic.LoadInt64(&h.stats.WriteOK),
+			statHandoffWriteErr:     atomic.LoadInt64(&h.stats.WriteErr),
+			statHandoffWriteDropped: atomic.LoadInt64(&h.stats.WriteDropped),
+			statHandoffPointsSent:   atomic.LoadInt64(&h.stats.PointsSent),
+			statHandoffLagNs:        lag,
+		},
+	}
+}
+
+// wait waits for d and returns false if closing is closed meanwhile.
+func wait(closing <-chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-closing:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,57 @@
+package coordinator_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"go.uber.org/zap"
+)
+
+// Ensure the writes of a hinted handoff queue are sent at most at the
+// maximum drain rate.
+func TestHandoffQueue_MaxDrainRate(t *testing.T) {
+	h, err := coordinator.OpenHandoffQueue(coordinator.HandoffConfig{
+		Dir:          t.TempDir(),
+		BatchSize:    1,
+		MaxDrainRate: 200,
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	data := bytes.Repeat([]byte("cpu value=1 1\n"), 100)
+	for i := 0; i < 3; i++ {
+		if err := h.Append("db0", "", 100, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	closing := make(chan struct{})
+	defer close(closing)
+	sent := make(chan time.Time, 3)
+	start := time.Now()
+	go h.Drain(closing, func(writes []coordinator.HandoffWrite) error {
+		if len(writes) != 1 || writes[0].Database != "db0" || writes[0].N != 100 {
+			t.Errorf("unexpected writes: %+v", writes)
+		}
+		sent <- time.Now()
+		return nil
+	})
+
+	// The burst is a second worth of points, the third write waits for the
+	// points of half a second.
+	var last time.Time
+	for i := 0; i < 3; i++ {
+		select {
+		case last = <-sent:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the writes")
+		}
+	}
+	if d := last.Sub(start); d < 400*time.Millisecond {
+		t.Fatalf("writes sent too fast: %s", d)
+	}
+}
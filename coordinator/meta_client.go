@@ -9,11 +9,15 @@ import (
 
 // MetaClient is an interface for accessing meta data.
 type MetaClient interface {
+	AddUserToRole(username, role string) error
 	CreateContinuousQuery(database, name, query string) error
 	CreateDatabase(name string) (*meta.DatabaseInfo, error)
 	CreateDatabaseWithRetentionPolicy(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
 	CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
+	CreateRole(name string) error
+	CreateShardGroup(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
 	CreateSubscription(database, rp, name, mode string, destinations []string) error
+	CreateToken(username string, ttl time.Duration, database, rp string) (string, *meta.TokenInfo, error)
 	CreateUser(name, password string, admin bool) (meta.User, error)
 	Database(name string) *meta.DatabaseInfo
 	Databases() []meta.DatabaseInfo
@@ -21,16 +25,33 @@ type MetaClient interface {
 	DropContinuousQuery(database, name string) error
 	DropDatabase(name string) error
 	DropRetentionPolicy(database, name string) error
+	DropRole(name string) error
 	DropSubscription(database, rp, name string) error
+	DropToken(id string) error
 	DropUser(name string) error
+	RemoveUserFromRole(username, role string) error
+	RenameDatabase(oldName, newName string) error
+	RenameMeasurement(database, oldName, newName string) error
 	RetentionPolicy(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
+	RolePrivileges(role string) (map[string]influxql.Privilege, error)
+	Roles() []meta.RoleInfo
 	SetAdminPrivilege(username string, admin bool) error
+	SetDatabaseQuota(name string, q meta.QuotaInfo) error
+	SetMeasurementPrivilege(username string, mp meta.MeasurementPrivilege) error
 	SetPrivilege(username, database string, p influxql.Privilege) error
+	SetUserQueryPriority(username, priority string) error
+	SetRolePrivilege(role, database string, p influxql.Privilege) error
+	SetUserTimeZone(username, tz string) error
+	SetUserWriteTarget(username, database, rp string) error
 	ShardGroupsByTimeRange(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
+	Tokens() []meta.TokenInfo
+	TruncateDatabaseShardGroups(database, policy string, t time.Time) error
 	TruncateShardGroups(t time.Time) error
 	UpdateRetentionPolicy(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
 	UpdateUser(name, password string) error
+	UserMeasurementPrivileges(username string) ([]meta.MeasurementPrivilege, error)
 	UserPrivilege(username, database string) (*influxql.Privilege, error)
 	UserPrivileges(username string) (map[string]influxql.Privilege, error)
+	UserRoles(username string) ([]string, error)
 	Users() []meta.UserInfo
 }
@@ -30,14 +30,35 @@ type MetaClient struct {
 	MetaNodesFn                         func() ([]meta.NodeInfo, error)
 	RetentionPolicyFn                   func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
 	SetAdminPrivilegeFn                 func(username string, admin bool) error
+	SetMeasurementPrivilegeFn           func(username string, mp meta.MeasurementPrivilege) error
 	SetPrivilegeFn                      func(username, database string, p influxql.Privilege) error
+	SetUserTimeZoneFn                   func(username, tz string) error
 	ShardGroupsByTimeRangeFn            func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
 	TruncateShardGroupsFn               func(t time.Time) error
 	UpdateRetentionPolicyFn             func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
 	UpdateUserFn                        func(name, password string) error
+	UserMeasurementPrivilegesFn         func(username string) ([]meta.MeasurementPrivilege, error)
 	UserPrivilegeFn                     func(username, database string) (*influxql.Privilege, error)
 	UserPrivilegesFn                    func(username string) (map[string]influxql.Privilege, error)
 	UsersFn                             func() []meta.UserInfo
+	AddUserToRoleFn                     func(username, role string) error
+	CreateRoleFn                        func(name string) error
+	DropRoleFn                          func(name string) error
+	RemoveUserFromRoleFn                func(username, role string) error
+	RolePrivilegesFn                    func(role string) (map[string]influxql.Privilege, error)
+	RolesFn                             func() []meta.RoleInfo
+	SetRolePrivilegeFn                  func(role, database string, p influxql.Privilege) error
+	UserRolesFn                         func(username string) ([]string, error)
+	CreateTokenFn                       func(username string, ttl time.Duration, database, rp string) (string, *meta.TokenInfo, error)
+	DropTokenFn                         func(id string) error
+	TokensFn                            func() []meta.TokenInfo
+	SetDatabaseQuotaFn                  func(name string, q meta.QuotaInfo) error
+	CreateShardGroupFn                  func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
+	TruncateDatabaseShardGroupsFn       func(database, policy string, t time.Time) error
+	RenameDatabaseFn                    func(oldName, newName string) error
+	RenameMeasurementFn                 func(database, oldName, newName string) error
+	SetUserWriteTargetFn                func(username, database, rp string) error
+	SetUserQueryPriorityFn              func(username, priority string) error
 }
 
 func (c *MetaClient) CreateContinuousQuery(database, name, query string) error {
@@ -124,10 +145,18 @@ func (c *MetaClient) SetAdminPrivilege(username string, admin bool) error {
 	return c.SetAdminPrivilegeFn(username, admin)
 }
 
+func (c *MetaClient) SetMeasurementPrivilege(username string, mp meta.MeasurementPrivilege) error {
+	return c.SetMeasurementPrivilegeFn(username, mp)
+}
+
 func (c *MetaClient) SetPrivilege(username, database string, p influxql.Privilege) error {
 	return c.SetPrivilegeFn(username, database, p)
 }
 
+func (c *MetaClient) SetUserTimeZone(username, tz string) error {
+	return c.SetUserTimeZoneFn(username, tz)
+}
+
 func (c *MetaClient) ShardGroupsByTimeRange(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
 	return c.ShardGroupsByTimeRangeFn(database, policy, min, max)
 }
@@ -144,6 +173,10 @@ func (c *MetaClient) UpdateUser(name, password string) error {
 	return c.UpdateUserFn(name, password)
 }
 
+func (c *MetaClient) UserMeasurementPrivileges(username string) ([]meta.MeasurementPrivilege, error) {
+	return c.UserMeasurementPrivilegesFn(username)
+}
+
 func (c *MetaClient) UserPrivilege(username, database string) (*influxql.Privilege, error) {
 	return c.UserPrivilegeFn(username, database)
 }
@@ -164,3 +197,75 @@ func DefaultMetaClientDatabaseFn(name string) *meta.DatabaseInfo {
 		DefaultRetentionPolicy: DefaultRetentionPolicy,
 	}
 }
+
+func (c *MetaClient) AddUserToRole(username, role string) error {
+	return c.AddUserToRoleFn(username, role)
+}
+
+func (c *MetaClient) CreateRole(name string) error {
+	return c.CreateRoleFn(name)
+}
+
+func (c *MetaClient) DropRole(name string) error {
+	return c.DropRoleFn(name)
+}
+
+func (c *MetaClient) RemoveUserFromRole(username, role string) error {
+	return c.RemoveUserFromRoleFn(username, role)
+}
+
+func (c *MetaClient) RolePrivileges(role string) (map[string]influxql.Privilege, error) {
+	return c.RolePrivilegesFn(role)
+}
+
+func (c *MetaClient) Roles() []meta.RoleInfo {
+	return c.RolesFn()
+}
+
+func (c *MetaClient) SetRolePrivilege(role, database string, p influxql.Privilege) error {
+	return c.SetRolePrivilegeFn(role, database, p)
+}
+
+func (c *MetaClient) UserRoles(username string) ([]string, error) {
+	return c.UserRolesFn(username)
+}
+
+func (c *MetaClient) CreateToken(username string, ttl time.Duration, database, rp string) (string, *meta.TokenInfo, error) {
+	return c.CreateTokenFn(username, ttl, database, rp)
+}
+
+func (c *MetaClient) DropToken(id string) error {
+	return c.DropTokenFn(id)
+}
+
+func (c *MetaClient) Tokens() []meta.TokenInfo {
+	return c.TokensFn()
+}
+
+func (c *MetaClient) SetDatabaseQuota(name string, q meta.QuotaInfo) error {
+	return c.SetDatabaseQuotaFn(name, q)
+}
+
+func (c *MetaClient) CreateShardGroup(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error) {
+	return c.CreateShardGroupFn(database, policy, timestamp)
+}
+
+func (c *MetaClient) TruncateDatabaseShardGroups(database, policy string, t time.Time) error {
+	return c.TruncateDatabaseShardGroupsFn(database, policy, t)
+}
+
+func (c *MetaClient) RenameDatabase(oldName, newName string) error {
+	return c.RenameDatabaseFn(oldName, newName)
+}
+
+func (c *MetaClient) RenameMeasurement(database, oldName, newName string) error {
+	return c.RenameMeasurementFn(database, oldName, newName)
+}
+
+func (c *MetaClient) SetUserWriteTarget(username, database, rp string) error {
+	return c.SetUserWriteTargetFn(username, database, rp)
+}
+
+func (c *MetaClient) SetUserQueryPriority(username, priority string) error {
+	return c.SetUserQueryPriorityFn(username, priority)
+}
@@ -9,10 +9,13 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 // The keys for statistics generated by the "write" module.
@@ -25,6 +28,17 @@ const (
 	statWriteTimeout       = "writeTimeout"
 	statWriteErr           = "writeError"
 	statSubWriteOK         = "subWriteOk"
+	statWriteQuotaExceeded = "writeQuotaExceeded"
+	statPointWriteDedup    = "pointReqDeduplicated"
+	statWriteSlow          = "writeSlow"
+)
+
+// The keys for statistics generated by the "writeThrottle" module, one per
+// database with a write rate or concurrent writes quota.
+const (
+	statWriteThrottleActive              = "activeWrites"
+	statWriteThrottleRateThrottled       = "rateThrottled"
+	statWriteThrottleConcurrentThrottled = "concurrentThrottled"
 )
 
 var (
@@ -46,6 +60,20 @@ type PointsWriter struct {
 	WriteTimeout time.Duration
 	Logger       *zap.Logger
 
+	// MaxFutureWrite is how far in the future the points can be. The points
+	// beyond it are dropped. Zero disables the limit.
+	MaxFutureWrite time.Duration
+
+	// DedupWindow is how long the written points are remembered to drop
+	// their exact duplicates, and DedupMaxPoints how many of them at most.
+	// A zero window disables the deduplication.
+	DedupWindow    time.Duration
+	DedupMaxPoints int
+
+	// LogWritesAfter is the time after which a write is logged as slow with
+	// the time it spent in each stage. Zero disables the logging.
+	LogWritesAfter time.Duration
+
 	Node *influxdb.Node
 
 	MetaClient interface {
@@ -63,8 +91,37 @@ type PointsWriter struct {
 		Send(*WritePointsRequest)
 	}
 
+	// Replicator queues the written points for replication to remote servers.
+	Replicator interface {
+		Replicate(database, retentionPolicy string, points []models.Point)
+	}
+
+	// ShadowWriter mirrors some of the writes to a secondary server with
+	// the time they took and their error.
+	ShadowWriter interface {
+		Shadow(database, retentionPolicy string, points []models.Point, d time.Duration, err error)
+	}
+
+	// WriteQueue queues the writes that are acknowledged before they are
+	// applied.
+	WriteQueue interface {
+		Enqueue(writeCtx tsdb.WriteContext, database, retentionPolicy string, points []models.Point) error
+	}
+
+	// QueryCache drops the cached query results that the written points change.
+	QueryCache interface {
+		Invalidate(database, retentionPolicy string, min, max int64)
+	}
+
 	subPoints chan<- *WritePointsRequest
 
+	// Throttles of the writes of the databases with a write rate or
+	// concurrent writes quota.
+	throttlesMu sync.Mutex
+	throttles   map[string]*writeThrottle
+
+	dedup *pointDeduplicator
+
 	stats *WriteStatistics
 }
 
@@ -91,6 +148,7 @@ func NewPointsWriter() *PointsWriter {
 	return &PointsWriter{
 		WriteTimeout: DefaultWriteTimeout,
 		Logger:       zap.NewNop(),
+		throttles:    make(map[string]*writeThrottle),
 		stats:        &WriteStatistics{},
 	}
 }
@@ -100,7 +158,8 @@ type ShardMapping struct {
 	n       int
 	Points  map[uint64][]models.Point  // The points associated with a shard ID
 	Shards  map[uint64]*meta.ShardInfo // The shards that have been mapped, keyed by shard ID
-	Dropped []models.Point             // Points that were dropped
+	Dropped []models.Point             // Points that were dropped for being beyond the retention policy
+	Future  []models.Point             // Points that were dropped for being too far in the future
 }
 
 // NewShardMapping creates an empty ShardMapping.
@@ -124,6 +183,9 @@ func (s *ShardMapping) MapPoint(shardInfo *meta.ShardInfo, p models.Point) {
 // Open opens the communication channel with the point writer.
 func (w *PointsWriter) Open() error {
 	w.closing = make(chan struct{})
+	if w.DedupWindow > 0 {
+		w.dedup = newPointDeduplicator(w.DedupWindow, w.DedupMaxPoints)
+	}
 	return nil
 }
 
@@ -150,11 +212,14 @@ type WriteStatistics struct {
 	WriteTimeout       int64
 	WriteErr           int64
 	SubWriteOK         int64
+	WriteQuotaExceeded int64
+	PointWriteDedup    int64
+	WriteSlow          int64
 }
 
 // Statistics returns statistics for periodic monitoring.
 func (w *PointsWriter) Statistics(tags map[string]string) []models.Statistic {
-	return []models.Statistic{{
+	statistics := []models.Statistic{{
 		Name: "write",
 		Tags: tags,
 		Values: map[string]interface{}{
@@ -166,8 +231,26 @@ func (w *PointsWriter) Statistics(tags map[string]string) []models.Statistic {
 			statWriteTimeout:       atomic.LoadInt64(&w.stats.WriteTimeout),
 			statWriteErr:           atomic.LoadInt64(&w.stats.WriteErr),
 			statSubWriteOK:         atomic.LoadInt64(&w.stats.SubWriteOK),
+			statWriteQuotaExceeded: atomic.LoadInt64(&w.stats.WriteQuotaExceeded),
+			statPointWriteDedup:    atomic.LoadInt64(&w.stats.PointWriteDedup),
+			statWriteSlow:          atomic.LoadInt64(&w.stats.WriteSlow),
 		},
 	}}
+
+	w.throttlesMu.Lock()
+	defer w.throttlesMu.Unlock()
+	for database, t := range w.throttles {
+		statistics = append(statistics, models.Statistic{
+			Name: "writeThrottle",
+			Tags: models.StatisticTags{"database": database}.Merge(tags),
+			Values: map[string]interface{}{
+				statWriteThrottleActive:              t.active,
+				statWriteThrottleRateThrottled:       t.rateThrottled,
+				statWriteThrottleConcurrentThrottled: t.concurrentThrottled,
+			},
+		})
+	}
+	return statistics
 }
 
 // MapShards maps the points contained in wp to a ShardMapping.  If a point
@@ -183,15 +266,18 @@ func (w *PointsWriter) MapShards(wp *WritePointsRequest) (*ShardMapping, error)
 
 	// Holds all the shard groups and shards that are required for writes.
 	list := sgList{items: make(meta.ShardGroupInfos, 0, 8)}
-	min := time.Unix(0, models.MinNanoTime)
+	min, max := time.Unix(0, models.MinNanoTime), time.Unix(0, models.MaxNanoTime)
 	if rp.Duration > 0 {
 		min = time.Now().Add(-rp.Duration)
 	}
+	if w.MaxFutureWrite > 0 {
+		max = time.Now().Add(w.MaxFutureWrite)
+	}
 
 	for _, p := range wp.Points {
-		// Either the point is outside the scope of the RP, or we already have
-		// a suitable shard group for the point.
-		if p.Time().Before(min) || list.Covers(p.Time()) {
+		// Either the point is outside the scope of the RP or too far in the
+		// future, or we already have a suitable shard group for the point.
+		if p.Time().Before(min) || p.Time().After(max) || list.Covers(p.Time()) {
 			continue
 		}
 
@@ -210,6 +296,12 @@ func (w *PointsWriter) MapShards(wp *WritePointsRequest) (*ShardMapping, error)
 
 	mapping := NewShardMapping(len(wp.Points))
 	for _, p := range wp.Points {
+		if p.Time().After(max) {
+			mapping.Future = append(mapping.Future, p)
+			atomic.AddInt64(&w.stats.WriteDropped, 1)
+			continue
+		}
+
 		sg := list.ShardGroupAt(p.Time())
 		if sg == nil {
 			// We didn't create a shard group because the point was outside the
@@ -316,8 +408,13 @@ func (l *sgList) Add(sgi meta.ShardGroupInfo) {
 
 // WritePointsInto is a copy of WritePoints that uses a tsdb structure instead of
 // a cluster structure for information. This is to avoid a circular dependency.
-// It is used for 'SELECT INTO' statements
+// It is used for 'SELECT INTO' statements, and for the to() function of Flux
+// which writes the points as the user of the request like WritePoints.
 func (w *PointsWriter) WritePointsInto(p *IntoWriteRequest) error {
+	if p.User != nil {
+		return w.WritePointsWithContext(tsdb.WriteContext{}, p.Database, p.RetentionPolicy, models.ConsistencyLevelOne, p.User, p.Points)
+	}
+
 	// TODO: assign the correct original user for select into statements
 	writeCtx := tsdb.WriteContext{
 		UserId: tsdb.SelectIntoUser,
@@ -327,40 +424,119 @@ func (w *PointsWriter) WritePointsInto(p *IntoWriteRequest) error {
 
 // A wrapper for WritePointsPrivileged() - user is only required for clustering
 func (w *PointsWriter) WritePoints(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, user meta.User, points []models.Point) error {
+	return w.WritePointsWithContext(tsdb.WriteContext{}, database, retentionPolicy, consistencyLevel, user, points)
+}
+
+// WritePointsWithContext writes the points of a user like WritePoints, with
+// the request ID and the trace of the write context. A write acknowledged
+// once queued is only queued if there is a write queue, and is applied right
+// away otherwise.
+func (w *PointsWriter) WritePointsWithContext(writeCtx tsdb.WriteContext, database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, user meta.User, points []models.Point) error {
 	userID := tsdb.UnknownUser
 	if user != nil {
 		userID = user.ID()
+
+		// Users with privileges on measurements are only authorized to write
+		// some of the points.
+		if !user.IsOpen() {
+			for _, p := range points {
+				if !user.AuthorizeSeriesWrite(database, p.Name(), p.Tags()) {
+					return &meta.ErrAuthorize{
+						Database: database,
+						Message:  fmt.Sprintf("%q user is not authorized to write to measurement %q in database %q", userID, p.Name(), database),
+					}
+				}
+			}
+		}
 	}
-	writeCtx := tsdb.WriteContext{
-		UserId: userID,
+	writeCtx.UserId = userID
+	if writeCtx.Ack == tsdb.WriteAckQueued && w.WriteQueue != nil {
+		return w.WriteQueue.Enqueue(writeCtx, database, retentionPolicy, points)
 	}
 	return w.WritePointsPrivileged(writeCtx, database, retentionPolicy, consistencyLevel, points)
 }
 
 func (w *PointsWriter) WritePointsPrivileged(writeCtx tsdb.WriteContext, database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+	if w.ShadowWriter == nil {
+		return w.writePointsPrivileged(writeCtx, database, retentionPolicy, points)
+	}
+
+	// Mirror the write once it is done, with its result to compare.
+	start := time.Now()
+	err := w.writePointsPrivileged(writeCtx, database, retentionPolicy, points)
+	w.ShadowWriter.Shadow(database, retentionPolicy, points, time.Since(start), err)
+	return err
+}
+
+func (w *PointsWriter) writePointsPrivileged(writeCtx tsdb.WriteContext, database, retentionPolicy string, points []models.Point) error {
 	atomic.AddInt64(&w.stats.WriteReq, 1)
 	atomic.AddInt64(&w.stats.PointWriteReq, int64(len(points)))
 
+	if w.LogWritesAfter > 0 {
+		if writeCtx.Trace == nil {
+			writeCtx.Trace = new(tsdb.WriteTrace)
+		}
+		defer w.logSlowWrite(writeCtx, database, retentionPolicy, len(points), time.Now())
+	}
+
+	db := w.MetaClient.Database(database)
 	if retentionPolicy == "" {
-		db := w.MetaClient.Database(database)
 		if db == nil {
 			return influxdb.ErrDatabaseNotFound(database)
 		}
 		retentionPolicy = db.DefaultRetentionPolicy
 	}
 
+	// Reject the writes beyond the write rate or the concurrent writes of
+	// the database. The other limits of its quota are enforced by the store.
+	if db != nil {
+		release, err := w.admitWrite(database, db.Quota, len(points))
+		if err != nil {
+			atomic.AddInt64(&w.stats.WriteQuotaExceeded, 1)
+			return err
+		}
+		defer release()
+		writeCtx.Quota = tsdb.Quota{MaxSeries: db.Quota.MaxSeries, MaxMeasurements: db.Quota.MaxMeasurements}
+	}
+
+	// Drop the exact duplicates of the points written recently. They are
+	// only remembered once they are written, so a write that failed can be
+	// retried.
+	var dedupHashes []uint64
+	if w.dedup != nil {
+		n := len(points)
+		points, dedupHashes = w.dedup.Filter(database, retentionPolicy, points)
+		if dropped := n - len(points); dropped > 0 {
+			atomic.AddInt64(&w.stats.PointWriteDedup, int64(dropped))
+			if len(points) == 0 {
+				return nil
+			}
+		}
+	}
+
+	mapStart := time.Now()
 	shardMappings, err := w.MapShards(&WritePointsRequest{Database: database, RetentionPolicy: retentionPolicy, Points: points})
 	if err != nil {
 		return err
 	}
+	writeCtx.Trace.Observe(tsdb.WriteStageMapShards, time.Since(mapStart))
 
 	// Write each shard in it's own goroutine and return as soon as one fails.
 	ch := make(chan error, len(shardMappings.Points))
 	for shardID, points := range shardMappings.Points {
 		go func(writeCtx tsdb.WriteContext, shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) {
 			err := w.writeToShard(writeCtx, shard, database, retentionPolicy, points)
+			if w.QueryCache != nil {
+				// Some points may have been written even if the write failed.
+				min, max := pointsTimeRange(points)
+				w.QueryCache.Invalidate(database, retentionPolicy, min, max)
+			}
 			if err == tsdb.ErrShardDeletion {
-				err = tsdb.PartialWriteError{Reason: fmt.Sprintf("shard %d is pending deletion", shard.ID), Dropped: len(points)}
+				var perr tsdb.PartialWriteError
+				for _, p := range points {
+					perr.DropPoint(p, tsdb.DropReasonShardDeletion, fmt.Sprintf("shard %d is pending deletion", shard.ID))
+				}
+				err = perr
 			}
 			ch <- err
 		}(writeCtx, shardMappings.Shards[shardID], database, retentionPolicy, points)
@@ -374,10 +550,20 @@ func (w *PointsWriter) WritePointsPrivileged(writeCtx tsdb.WriteContext, databas
 	w.Subscriber.Send(pts)
 	atomic.AddInt64(&w.stats.SubWriteOK, 1)
 
-	if err == nil && len(shardMappings.Dropped) > 0 {
-		err = tsdb.PartialWriteError{Reason: "points beyond retention policy", Dropped: len(shardMappings.Dropped)}
+	if err == nil && len(shardMappings.Dropped)+len(shardMappings.Future) > 0 {
+		var perr tsdb.PartialWriteError
+		for _, p := range shardMappings.Dropped {
+			perr.DropPoint(p, tsdb.DropReasonRetentionPolicy, "points beyond retention policy")
+		}
+		for _, p := range shardMappings.Future {
+			perr.DropPoint(p, tsdb.DropReasonFutureTimestamp, "points too far in the future")
+		}
+		err = perr
 	}
 
+	// Wait for all the shards so that the points dropped by each of them
+	// are reported, unless one of them fails.
+	var shardErr error
 	for range shardMappings.Points {
 		select {
 		case <-w.closing:
@@ -387,12 +573,158 @@ func (w *PointsWriter) WritePointsPrivileged(writeCtx tsdb.WriteContext, databas
 			// return timeout error to caller
 			return ErrTimeout
 		case err := <-ch:
-			if err != nil {
+			if _, ok := err.(tsdb.PartialWriteError); err != nil && !ok {
 				return err
 			}
+			shardErr = tsdb.MergePartialWriteErrors(shardErr, err)
 		}
 	}
-	return err
+
+	// Only the points written locally are replicated, even if the write is
+	// partial.
+	if w.Replicator != nil {
+		if written := writtenPoints(points, tsdb.MergePartialWriteErrors(shardErr, err)); len(written) > 0 {
+			w.Replicator.Replicate(database, retentionPolicy, written)
+		}
+	}
+	if shardErr == nil && err == nil && w.dedup != nil {
+		w.dedup.Add(dedupHashes)
+	}
+	return tsdb.MergePartialWriteErrors(shardErr, err)
+}
+
+// writtenPoints returns the points of a write that aren't dropped by err, a
+// tsdb.PartialWriteError or nil.
+func writtenPoints(points []models.Point, err error) []models.Point {
+	if err == nil {
+		return points
+	}
+	perr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		return nil
+	}
+	dropped := make(map[models.Point]struct{}, len(perr.DroppedPoints))
+	for _, d := range perr.DroppedPoints {
+		dropped[d.Point] = struct{}{}
+	}
+	written := make([]models.Point, 0, len(points)-len(dropped))
+	for _, p := range points {
+		if _, ok := dropped[p]; !ok {
+			written = append(written, p)
+		}
+	}
+	return written
+}
+
+// logSlowWrite logs a write that took longer than LogWritesAfter, including
+// the time its points took to parse, with the time it spent in each stage.
+func (w *PointsWriter) logSlowWrite(writeCtx tsdb.WriteContext, database, retentionPolicy string, n int, start time.Time) {
+	total := time.Since(start) + writeCtx.Trace.Duration(tsdb.WriteStageParse)
+	if total < w.LogWritesAfter {
+		return
+	}
+	atomic.AddInt64(&w.stats.WriteSlow, 1)
+
+	fields := []zap.Field{
+		zap.String("request_id", writeCtx.RequestID),
+		logger.Database(database),
+		logger.RetentionPolicy(retentionPolicy),
+		zap.String("user", writeCtx.UserId),
+		zap.Int("points", n),
+		zap.Duration("total", total),
+	}
+	for _, stage := range tsdb.WriteStages {
+		fields = append(fields, zap.Duration(stage.String(), writeCtx.Trace.Duration(stage)))
+	}
+	w.Logger.Warn("Detected slow write", fields...)
+}
+
+// writeThrottle limits the write rate and the concurrent writes of a
+// database.
+type writeThrottle struct {
+	limiter *rate.Limiter // nil without a write rate
+	active  int64         // writes in progress
+
+	rateThrottled       int64
+	concurrentThrottled int64
+}
+
+// concurrentWriteRetryAfter is how long a client is told to wait before
+// retrying a write rejected for exceeding the concurrent writes.
+const concurrentWriteRetryAfter = time.Second
+
+// admitWrite checks that a write of n points to the database doesn't exceed
+// its write rate in points per second or its concurrent writes. The returned
+// function must be called once the write is done. A write of more points
+// than the rate is never allowed.
+func (w *PointsWriter) admitWrite(database string, q meta.QuotaInfo, n int) (func(), error) {
+	w.throttlesMu.Lock()
+	defer w.throttlesMu.Unlock()
+
+	if q.MaxWriteRate <= 0 && q.MaxConcurrentWrites <= 0 {
+		delete(w.throttles, database)
+		return func() {}, nil
+	}
+
+	t := w.throttles[database]
+	if t == nil {
+		t = &writeThrottle{}
+		w.throttles[database] = t
+	}
+
+	if q.MaxConcurrentWrites > 0 && t.active >= q.MaxConcurrentWrites {
+		t.concurrentThrottled++
+		return nil, &tsdb.QuotaExceededError{Database: database, Quota: tsdb.QuotaMaxConcurrentWrites, Limit: q.MaxConcurrentWrites, RetryAfter: concurrentWriteRetryAfter}
+	}
+
+	if q.MaxWriteRate <= 0 {
+		t.limiter = nil
+	} else {
+		// The burst is the write rate, so a database can write a second
+		// worth of points at once.
+		burst := int(q.MaxWriteRate)
+		if t.limiter == nil {
+			t.limiter = rate.NewLimiter(rate.Limit(q.MaxWriteRate), burst)
+		} else if t.limiter.Burst() != burst {
+			t.limiter.SetLimit(rate.Limit(q.MaxWriteRate))
+			t.limiter.SetBurst(burst)
+		}
+
+		now := time.Now()
+		r := t.limiter.ReserveN(now, n)
+		if !r.OK() {
+			t.rateThrottled++
+			return nil, &tsdb.QuotaExceededError{Database: database, Quota: tsdb.QuotaMaxWriteRate, Limit: q.MaxWriteRate}
+		} else if delay := r.DelayFrom(now); delay > 0 {
+			// Give the points back, the write is rejected rather than
+			// delayed.
+			r.CancelAt(now)
+			t.rateThrottled++
+			return nil, &tsdb.QuotaExceededError{Database: database, Quota: tsdb.QuotaMaxWriteRate, Limit: q.MaxWriteRate, RetryAfter: delay}
+		}
+	}
+
+	t.active++
+	return func() {
+		w.throttlesMu.Lock()
+		t.active--
+		w.throttlesMu.Unlock()
+	}, nil
+}
+
+// pointsTimeRange returns the minimum and maximum time of the points.
+func pointsTimeRange(points []models.Point) (min, max int64) {
+	min, max = influxql.MaxTime, influxql.MinTime
+	for _, p := range points {
+		t := p.UnixNano()
+		if t < min {
+			min = t
+		}
+		if t > max {
+			max = t
+		}
+	}
+	return min, max
 }
 
 // writeToShards writes points to a shard.
@@ -1,8 +1,11 @@
 package coordinator_test
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -13,6 +16,9 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // TODO(benbjohnson): Rewrite tests to use cluster_test.MetaClient.
@@ -428,6 +434,458 @@ func TestPointsWriter_WritePoints_Dropped(t *testing.T) {
 	}
 }
 
+// Ensure the points dropped by the shards and by the points writer are all
+// reported with their reasons.
+func TestPointsWriter_WritePoints_DroppedPoints(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return nil
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	store := &fakeStore{
+		WriteFn: func(_ tsdb.WriteContext, shardID uint64, points []models.Point) error {
+			if points[0].Time().After(time.Now().Add(time.Hour)) {
+				return tsdb.ErrShardDeletion
+			}
+			var err tsdb.PartialWriteError
+			err.DropPoint(points[0], tsdb.DropReasonTypeConflict, "field type conflict")
+			return err
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Subscriber = Subscriber{SendFn: func(*coordinator.WritePointsRequest) {}}
+	c.Node = &influxdb.Node{ID: 1}
+	c.MaxFutureWrite = 2 * time.Hour
+
+	c.Open()
+	defer c.Close()
+
+	pr := &coordinator.WritePointsRequest{Database: "mydb", RetentionPolicy: "myrp"}
+	pr.AddPoint("cpu", 1.0, time.Now(), nil)
+	pr.AddPoint("cpu", 2.0, time.Now().Add(90*time.Minute), nil)
+	pr.AddPoint("cpu", 3.0, time.Now().Add(3*time.Hour), nil)
+	pr.AddPoint("cpu", 4.0, time.Now().Add(-24*time.Hour), nil)
+
+	err := c.WritePointsPrivileged(tsdb.WriteContext{}, pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
+	perr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if perr.Dropped != 4 || len(perr.DroppedPoints) != 4 {
+		t.Fatalf("unexpected dropped points: %+v", perr)
+	}
+
+	reasons := make(map[models.Point]tsdb.DropReason)
+	for _, d := range perr.DroppedPoints {
+		reasons[d.Point] = d.Reason
+	}
+	if exp := map[models.Point]tsdb.DropReason{
+		pr.Points[0]: tsdb.DropReasonTypeConflict,
+		pr.Points[1]: tsdb.DropReasonShardDeletion,
+		pr.Points[2]: tsdb.DropReasonFutureTimestamp,
+		pr.Points[3]: tsdb.DropReasonRetentionPolicy,
+	}; !reflect.DeepEqual(reasons, exp) {
+		t.Fatalf("unexpected reasons: %v", reasons)
+	}
+}
+
+// Ensure exactly the points written locally are replicated, when the write is
+// partial too.
+func TestPointsWriter_WritePoints_Replicate(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return nil
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	store := &fakeStore{
+		WriteFn: func(_ tsdb.WriteContext, shardID uint64, points []models.Point) error {
+			var err tsdb.PartialWriteError
+			for _, p := range points {
+				if fields, _ := p.Fields(); fields["value"] == 1.0 {
+					err.DropPoint(p, tsdb.DropReasonTypeConflict, "field type conflict")
+				}
+			}
+			if err.Dropped > 0 {
+				return err
+			}
+			return nil
+		},
+	}
+
+	var replicated []models.Point
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Subscriber = Subscriber{SendFn: func(*coordinator.WritePointsRequest) {}}
+	c.Replicator = replicator(func(database, retentionPolicy string, points []models.Point) {
+		replicated = append(replicated, points...)
+	})
+	c.Node = &influxdb.Node{ID: 1}
+	c.MaxFutureWrite = 2 * time.Hour
+
+	c.Open()
+	defer c.Close()
+
+	pr := &coordinator.WritePointsRequest{Database: "mydb", RetentionPolicy: "myrp"}
+	pr.AddPoint("cpu", 1.0, time.Now(), nil)
+	pr.AddPoint("cpu", 2.0, time.Now(), nil)
+	pr.AddPoint("cpu", 3.0, time.Now().Add(3*time.Hour), nil)
+	pr.AddPoint("cpu", 4.0, time.Now().Add(-24*time.Hour), nil)
+
+	if err := c.WritePointsPrivileged(tsdb.WriteContext{}, pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points); err == nil {
+		t.Fatal("expected a partial write")
+	}
+	if len(replicated) != 1 || replicated[0] != pr.Points[1] {
+		t.Fatalf("unexpected replicated points: %v", replicated)
+	}
+
+	// None of the points are replicated if they are all dropped.
+	replicated = nil
+	if err := c.WritePointsPrivileged(tsdb.WriteContext{}, pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points[:1]); err == nil {
+		t.Fatal("expected a partial write")
+	}
+	if len(replicated) != 0 {
+		t.Fatalf("unexpected replicated points: %v", replicated)
+	}
+}
+
+// replicator is a Replicator of a function.
+type replicator func(database, retentionPolicy string, points []models.Point)
+
+func (r replicator) Replicate(database, retentionPolicy string, points []models.Point) {
+	r(database, retentionPolicy, points)
+}
+
+// Ensure the write rate of a database is limited and the other limits of its
+// quota are passed to the store.
+func TestPointsWriter_WritePoints_Quota(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{
+			Name:                   database,
+			DefaultRetentionPolicy: "myrp",
+			Quota:                  meta.QuotaInfo{MaxSeries: 100, MaxMeasurements: 10, MaxWriteRate: 3},
+		}
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	var mu sync.Mutex
+	var quotas []tsdb.Quota
+	store := &fakeStore{
+		WriteFn: func(ctx tsdb.WriteContext, shardID uint64, points []models.Point) error {
+			mu.Lock()
+			defer mu.Unlock()
+			quotas = append(quotas, ctx.Quota)
+			return nil
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Subscriber = Subscriber{SendFn: func(*coordinator.WritePointsRequest) {}}
+	c.Node = &influxdb.Node{ID: 1}
+
+	c.Open()
+	defer c.Close()
+
+	pr := &coordinator.WritePointsRequest{Database: "mydb", RetentionPolicy: "myrp"}
+	pr.AddPoint("cpu", 1.0, time.Now(), nil)
+	pr.AddPoint("cpu", 2.0, time.Now(), nil)
+
+	// The first write is within the write rate and the second exceeds it.
+	if err := c.WritePointsPrivileged(tsdb.WriteContext{}, pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points); err != nil {
+		t.Fatal(err)
+	}
+	err := c.WritePointsPrivileged(tsdb.WriteContext{}, pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
+	if qerr, ok := err.(*tsdb.QuotaExceededError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if qerr.Quota != tsdb.QuotaMaxWriteRate || qerr.Limit != 3 {
+		t.Fatalf("unexpected error: %+v", qerr)
+	}
+
+	if exp := []tsdb.Quota{{MaxSeries: 100, MaxMeasurements: 10}}; !reflect.DeepEqual(quotas, exp) {
+		t.Fatalf("unexpected quotas: %v", quotas)
+	}
+	if got := c.Statistics(nil)[0].Values["writeQuotaExceeded"]; got != int64(1) {
+		t.Fatalf("unexpected writeQuotaExceeded: %v", got)
+	}
+}
+
+// Ensure the points written into a database for a user are written as the
+// user, and only to the measurements it may write.
+func TestPointsWriter_WritePointsInto_User(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: database, DefaultRetentionPolicy: "myrp"}
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	var mu sync.Mutex
+	var users []string
+	store := &fakeStore{
+		WriteFn: func(ctx tsdb.WriteContext, shardID uint64, points []models.Point) error {
+			mu.Lock()
+			defer mu.Unlock()
+			users = append(users, ctx.UserId)
+			return nil
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Subscriber = Subscriber{SendFn: func(*coordinator.WritePointsRequest) {}}
+	c.Node = &influxdb.Node{ID: 1}
+
+	c.Open()
+	defer c.Close()
+
+	user := &meta.UserInfo{
+		Name: "flux",
+		MeasurementPrivileges: []meta.MeasurementPrivilege{
+			{Database: "mydb", Name: "cpu", Privilege: influxql.WritePrivilege},
+		},
+	}
+	pr := &coordinator.WritePointsRequest{Database: "mydb", RetentionPolicy: "myrp"}
+	pr.AddPoint("cpu", 1.0, time.Now(), nil)
+	if err := c.WritePointsInto(&coordinator.IntoWriteRequest{Database: "mydb", RetentionPolicy: "myrp", Points: pr.Points, User: user}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WritePointsInto(&coordinator.IntoWriteRequest{Database: "mydb", RetentionPolicy: "myrp", Points: pr.Points}); err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"flux", tsdb.SelectIntoUser}; !reflect.DeepEqual(users, exp) {
+		t.Fatalf("unexpected users: %v", users)
+	}
+
+	pr.AddPoint("mem", 1.0, time.Now(), nil)
+	err := c.WritePointsInto(&coordinator.IntoWriteRequest{Database: "mydb", RetentionPolicy: "myrp", Points: pr.Points, User: user})
+	if _, ok := err.(*meta.ErrAuthorize); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure the concurrent writes of a database are limited and the throttled
+// writes can be retried later.
+func TestPointsWriter_WritePoints_ConcurrentWrites(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{
+			Name:                   database,
+			DefaultRetentionPolicy: "myrp",
+			Quota:                  meta.QuotaInfo{MaxWriteRate: 10, MaxConcurrentWrites: 1},
+		}
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	started, unblock := make(chan struct{}), make(chan struct{})
+	store := &fakeStore{
+		WriteFn: func(_ tsdb.WriteContext, shardID uint64, points []models.Point) error {
+			if points[0].Name()[0] == 'b' {
+				close(started)
+				<-unblock
+			}
+			return nil
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Subscriber = Subscriber{SendFn: func(*coordinator.WritePointsRequest) {}}
+	c.Node = &influxdb.Node{ID: 1}
+
+	c.Open()
+	defer c.Close()
+
+	write := func(name string, n int) error {
+		pr := &coordinator.WritePointsRequest{Database: "mydb", RetentionPolicy: "myrp"}
+		for i := 0; i < n; i++ {
+			pr.AddPoint(name, float64(i), time.Now(), nil)
+		}
+		return c.WritePointsPrivileged(tsdb.WriteContext{}, pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
+	}
+
+	// A write is rejected while another one is in progress.
+	done := make(chan error)
+	go func() { done <- write("blocked", 1) }()
+	<-started
+	if qerr, ok := write("cpu", 1).(*tsdb.QuotaExceededError); !ok || qerr.Quota != tsdb.QuotaMaxConcurrentWrites || qerr.Limit != 1 || qerr.RetryAfter <= 0 {
+		t.Fatalf("unexpected error: %+v", qerr)
+	}
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// The writes beyond the write rate can be retried once the points are
+	// available again, unless they have more points than the rate.
+	if err := write("cpu", 9); err != nil {
+		t.Fatal(err)
+	} else if qerr, ok := write("cpu", 5).(*tsdb.QuotaExceededError); !ok || qerr.Quota != tsdb.QuotaMaxWriteRate || qerr.RetryAfter <= 0 || qerr.RetryAfter > time.Second {
+		t.Fatalf("unexpected error: %+v", qerr)
+	} else if qerr, ok := write("cpu", 11).(*tsdb.QuotaExceededError); !ok || qerr.RetryAfter != 0 {
+		t.Fatalf("unexpected error: %+v", qerr)
+	}
+
+	stats := c.Statistics(map[string]string{"host": "h"})
+	if len(stats) != 2 {
+		t.Fatalf("unexpected statistics: %v", stats)
+	} else if s := stats[1]; s.Name != "writeThrottle" || s.Tags["database"] != "mydb" || s.Tags["host"] != "h" {
+		t.Fatalf("unexpected statistics: %v", s)
+	} else if exp := map[string]interface{}{"activeWrites": int64(0), "rateThrottled": int64(2), "concurrentThrottled": int64(1)}; !reflect.DeepEqual(s.Values, exp) {
+		t.Fatalf("unexpected statistics: %v", s.Values)
+	}
+}
+
+func TestPointsWriter_WritePoints_Dedup(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: database, DefaultRetentionPolicy: "myrp"}
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	var (
+		mu      sync.Mutex
+		written []string
+	)
+	store := &fakeStore{
+		WriteFn: func(_ tsdb.WriteContext, shardID uint64, points []models.Point) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, p := range points {
+				written = append(written, p.String())
+			}
+			if string(points[0].Name()) == "fail" {
+				return errors.New("write failed")
+			}
+			return nil
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Subscriber = Subscriber{SendFn: func(*coordinator.WritePointsRequest) {}}
+	c.Node = &influxdb.Node{ID: 1}
+	c.DedupWindow = 100 * time.Millisecond
+
+	c.Open()
+	defer c.Close()
+
+	now := time.Now()
+	write := func(lines ...string) []string {
+		t.Helper()
+		mu.Lock()
+		written = nil
+		mu.Unlock()
+
+		var points []models.Point
+		for _, line := range lines {
+			points = append(points, models.MustNewPoint(line, nil, models.Fields{"value": 1.0}, now))
+		}
+		if err := c.WritePointsPrivileged(tsdb.WriteContext{}, "mydb", "myrp", models.ConsistencyLevelOne, points); err != nil && lines[0] != "fail" {
+			t.Fatal(err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		var names []string
+		for _, p := range written {
+			names = append(names, strings.SplitN(p, " ", 2)[0])
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// The duplicates within a write and of the previous writes are dropped.
+	if got := write("cpu", "cpu", "mem"); !reflect.DeepEqual(got, []string{"cpu", "mem"}) {
+		t.Fatalf("unexpected points written: %v", got)
+	} else if got := write("cpu", "mem"); got != nil {
+		t.Fatalf("unexpected points written: %v", got)
+	} else if got := write("cpu", "disk"); !reflect.DeepEqual(got, []string{"disk"}) {
+		t.Fatalf("unexpected points written: %v", got)
+	}
+
+	// The points of a failed write are written again.
+	if got := write("fail"); !reflect.DeepEqual(got, []string{"fail"}) {
+		t.Fatalf("unexpected points written: %v", got)
+	} else if got := write("fail"); !reflect.DeepEqual(got, []string{"fail"}) {
+		t.Fatalf("unexpected points written: %v", got)
+	}
+
+	// The points are written again once the window is over.
+	time.Sleep(150 * time.Millisecond)
+	if got := write("cpu"); !reflect.DeepEqual(got, []string{"cpu"}) {
+		t.Fatalf("unexpected points written: %v", got)
+	}
+
+	if got := c.Statistics(nil)[0].Values["pointReqDeduplicated"]; got != int64(4) {
+		t.Fatalf("unexpected deduplicated points: %v", got)
+	}
+}
+
+// Ensure a slow write is logged with its request ID and the time of its
+// stages, and counted.
+func TestPointsWriter_WritePoints_SlowWrite(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: database, DefaultRetentionPolicy: "myrp"}
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	store := &fakeStore{
+		WriteFn: func(writeCtx tsdb.WriteContext, shardID uint64, points []models.Point) error {
+			writeCtx.Trace.Observe(tsdb.WriteStageWALFsync, 20*time.Millisecond)
+			if string(points[0].Name()) == "slow" {
+				time.Sleep(20 * time.Millisecond)
+			}
+			return nil
+		},
+	}
+
+	core, logs := observer.New(zap.WarnLevel)
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Subscriber = Subscriber{SendFn: func(*coordinator.WritePointsRequest) {}}
+	c.Node = &influxdb.Node{ID: 1}
+	c.LogWritesAfter = 10 * time.Millisecond
+	c.Logger = zap.New(core)
+
+	c.Open()
+	defer c.Close()
+
+	for _, name := range []string{"fast", "slow"} {
+		points := []models.Point{models.MustNewPoint(name, nil, models.Fields{"value": 1.0}, time.Now())}
+		if err := c.WritePointsWithContext(tsdb.WriteContext{RequestID: name + "-id"}, "mydb", "myrp", models.ConsistencyLevelOne, nil, points); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("unexpected logs: %v", entries)
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != "slow-id" || fields["db_instance"] != "mydb" || fields["points"] != int64(1) {
+		t.Fatalf("unexpected log fields: %v", fields)
+	} else if fields["wal_fsync"] != 20*time.Millisecond {
+		t.Fatalf("unexpected wal fsync time: %v", fields["wal_fsync"])
+	} else if _, ok := fields["map_shards"]; !ok {
+		t.Fatalf("missing map shards time: %v", fields)
+	}
+
+	if got := c.Statistics(nil)[0].Values["writeSlow"]; got != int64(1) {
+		t.Fatalf("unexpected slow writes: %v", got)
+	}
+}
+
 type fakePointsWriter struct {
 	WritePointsIntoFn func(*coordinator.IntoWriteRequest) error
 }
@@ -497,7 +955,7 @@ func TestBufferedPointsWriter(t *testing.T) {
 		req.AddPoint("cpu", float64(i), time.Now().Add(time.Duration(i)*time.Second), nil)
 	}
 
-	r := coordinator.IntoWriteRequest(req)
+	r := coordinator.IntoWriteRequest{Database: req.Database, RetentionPolicy: req.RetentionPolicy, Points: req.Points}
 	if err := w.WritePointsInto(&r); err != nil {
 		t.Fatal(err)
 	} else if writePointsIntoCnt != 5 {
@@ -0,0 +1,368 @@
+package coordinator
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+// The keys for statistics generated by the "queryCache" module.
+const (
+	statQueryCacheHits          = "hits"
+	statQueryCacheMisses        = "misses"
+	statQueryCacheInvalidations = "invalidations"
+	statQueryCacheEvictions     = "evictions"
+	statQueryCacheEntries       = "entries"
+)
+
+// QueryCache holds the rows produced by SELECT statements so that a statement
+// that is run again over the same time range can be answered without reading
+// the shards. A cached result is dropped when points are written into the
+// time range it covers or when data is deleted from one of its databases.
+type QueryCache struct {
+	databases  map[string]struct{}
+	maxEntries int
+	maxRows    int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+
+	// pending holds the entries of each database that are cached or are
+	// being recorded so they can be invalidated by a write.
+	pending map[string]map[*queryCacheEntry]struct{}
+
+	stats *QueryCacheStatistics
+}
+
+// NewQueryCache returns a new instance of QueryCache configured by c.
+func NewQueryCache(c Config) *QueryCache {
+	databases := make(map[string]struct{}, len(c.QueryCacheDatabases))
+	for _, name := range c.QueryCacheDatabases {
+		databases[name] = struct{}{}
+	}
+	return &QueryCache{
+		databases:  databases,
+		maxEntries: c.QueryCacheMaxEntries,
+		maxRows:    c.QueryCacheMaxRows,
+		ttl:        time.Duration(c.QueryCacheTTL),
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		pending:    make(map[string]map[*queryCacheEntry]struct{}),
+		stats:      &QueryCacheStatistics{},
+	}
+}
+
+// QueryCacheStatistics keeps statistics related to the QueryCache.
+type QueryCacheStatistics struct {
+	Hits          int64
+	Misses        int64
+	Invalidations int64
+	Evictions     int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (c *QueryCache) Statistics(tags map[string]string) []models.Statistic {
+	c.mu.Lock()
+	n := c.lru.Len()
+	c.mu.Unlock()
+
+	return []models.Statistic{{
+		Name: "queryCache",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statQueryCacheHits:          atomic.LoadInt64(&c.stats.Hits),
+			statQueryCacheMisses:        atomic.LoadInt64(&c.stats.Misses),
+			statQueryCacheInvalidations: atomic.LoadInt64(&c.stats.Invalidations),
+			statQueryCacheEvictions:     atomic.LoadInt64(&c.stats.Evictions),
+			statQueryCacheEntries:       int64(n),
+		},
+	}}
+}
+
+// Enabled returns true if results from the database may be cached.
+func (c *QueryCache) Enabled(database string) bool {
+	_, ok := c.databases[database]
+	return ok
+}
+
+// Invalidate drops the results that read from the retention policy of the
+// database within the time range. It is called after points are written.
+func (c *QueryCache) Invalidate(database, retentionPolicy string, min, max int64) {
+	if !c.Enabled(database) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for entry := range c.pending[database] {
+		if entry.covers(database, retentionPolicy, min, max) {
+			c.invalidate(entry)
+		}
+	}
+}
+
+// InvalidateDatabase drops all of the results that read from the database.
+// An empty name drops the results of every database.
+func (c *QueryCache) InvalidateDatabase(database string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, entries := range c.pending {
+		if database != "" && name != database {
+			continue
+		}
+		for entry := range entries {
+			c.invalidate(entry)
+		}
+	}
+}
+
+// invalidate removes the entry from the cache. Entries that are still being
+// recorded are marked so they will not be added once they are complete.
+// The lock must be held when calling this method.
+func (c *QueryCache) invalidate(entry *queryCacheEntry) {
+	if !entry.invalid {
+		entry.invalid = true
+		atomic.AddInt64(&c.stats.Invalidations, 1)
+	}
+	c.remove(entry)
+}
+
+// remove removes the entry from the cache and its indexes.
+// The lock must be held when calling this method.
+func (c *QueryCache) remove(entry *queryCacheEntry) {
+	if elem, ok := c.entries[entry.key]; ok && elem.Value == entry {
+		c.lru.Remove(elem)
+		delete(c.entries, entry.key)
+	}
+	for _, src := range entry.sources {
+		if entries := c.pending[src.Database]; entries != nil {
+			delete(entries, entry)
+			if len(entries) == 0 {
+				delete(c.pending, src.Database)
+			}
+		}
+	}
+}
+
+// get returns the entry for the key if it has not expired.
+func (c *QueryCache) get(key string, now time.Time) *queryCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok {
+		if entry := elem.Value.(*queryCacheEntry); c.ttl > 0 && now.Sub(entry.created) > c.ttl {
+			c.remove(entry)
+			ok = false
+		}
+	}
+	if !ok {
+		atomic.AddInt64(&c.stats.Misses, 1)
+		return nil
+	}
+	atomic.AddInt64(&c.stats.Hits, 1)
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*queryCacheEntry)
+}
+
+// begin starts recording a result. Writes that happen while the result is
+// recorded invalidate it, which prevents results that were read before the
+// write from being cached.
+func (c *QueryCache) begin(key string, sources []Source, t influxql.TimeRange, now time.Time) *queryCacheEntry {
+	entry := &queryCacheEntry{
+		key:     key,
+		sources: sources,
+		min:     t.MinTimeNano(),
+		max:     t.MaxTimeNano(),
+		created: now,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, src := range sources {
+		entries := c.pending[src.Database]
+		if entries == nil {
+			entries = make(map[*queryCacheEntry]struct{})
+			c.pending[src.Database] = entries
+		}
+		entries[entry] = struct{}{}
+	}
+	return entry
+}
+
+// commit adds the recorded entry to the cache unless it was invalidated
+// while it was being recorded.
+func (c *QueryCache) commit(entry *queryCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry.invalid {
+		return
+	}
+	if elem, ok := c.entries[entry.key]; ok {
+		// Replace the result of a statement that ran concurrently.
+		c.remove(elem.Value.(*queryCacheEntry))
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back().Value.(*queryCacheEntry))
+		atomic.AddInt64(&c.stats.Evictions, 1)
+	}
+}
+
+// abort stops recording the entry without adding it to the cache.
+func (c *QueryCache) abort(entry *queryCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(entry)
+}
+
+// queryCacheEntry is a result held by the QueryCache.
+type queryCacheEntry struct {
+	key      string
+	sources  []Source
+	min, max int64
+	created  time.Time
+
+	columns []influxql.VarRef
+	rows    []query.Row
+
+	// invalid is set when the entry is invalidated while it is recorded.
+	invalid bool
+}
+
+// covers returns true if the entry read from the retention policy of the
+// database within the time range. An empty retention policy in a source
+// matches every retention policy.
+func (e *queryCacheEntry) covers(database, retentionPolicy string, min, max int64) bool {
+	if max < e.min || min > e.max {
+		return false
+	}
+	for _, src := range e.sources {
+		if src.Database == database && (src.RetentionPolicy == "" || src.RetentionPolicy == retentionPolicy) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryCacheKey returns the key of the result of the statement over the time range.
+func queryCacheKey(stmt *influxql.SelectStatement, t influxql.TimeRange) string {
+	return fmt.Sprintf("%d:%d:%s", t.MinTimeNano(), t.MaxTimeNano(), stmt.String())
+}
+
+// queryCacheSources returns the databases and retention policies that the
+// statement reads from.
+func queryCacheSources(sources influxql.Sources) []Source {
+	var a []Source
+	add := func(src Source) {
+		for _, other := range a {
+			if other == src {
+				return
+			}
+		}
+		a = append(a, src)
+	}
+	for _, s := range sources {
+		switch s := s.(type) {
+		case *influxql.Measurement:
+			add(Source{Database: s.Database, RetentionPolicy: s.RetentionPolicy})
+		case *influxql.SubQuery:
+			for _, src := range queryCacheSources(s.Statement.Sources) {
+				add(src)
+			}
+		}
+	}
+	return a
+}
+
+// timeRangeShardMapper records the time range the statement maps shards for.
+type timeRangeShardMapper struct {
+	query.ShardMapper
+	timeRange influxql.TimeRange
+}
+
+func (m *timeRangeShardMapper) MapShards(sources influxql.Sources, t influxql.TimeRange, opt query.SelectOptions) (query.ShardGroup, error) {
+	m.timeRange = t
+	return m.ShardMapper.MapShards(sources, t, opt)
+}
+
+// cachedCursor replays the rows of a cached result.
+type cachedCursor struct {
+	entry *queryCacheEntry
+	i     int
+}
+
+func (cur *cachedCursor) Scan(row *query.Row) bool {
+	if cur.i >= len(cur.entry.rows) {
+		return false
+	}
+	r := cur.entry.rows[cur.i]
+	row.Time, row.Series = r.Time, r.Series
+	row.Values = make([]interface{}, len(r.Values))
+	copy(row.Values, r.Values)
+	cur.i++
+	return true
+}
+
+func (cur *cachedCursor) Stats() query.IteratorStats { return query.IteratorStats{} }
+func (cur *cachedCursor) Err() error                 { return nil }
+func (cur *cachedCursor) Columns() []influxql.VarRef { return cur.entry.columns }
+func (cur *cachedCursor) Close() error               { return nil }
+
+// recordingCursor records the rows it reads from the underlying cursor and
+// adds them to the cache once the cursor has been read completely.
+type recordingCursor struct {
+	query.Cursor
+	ctx   context.Context
+	cache *QueryCache
+	entry *queryCacheEntry
+	done  bool
+}
+
+func (cur *recordingCursor) Scan(row *query.Row) bool {
+	if !cur.Cursor.Scan(row) {
+		if !cur.done {
+			cur.done = true
+			// An interrupted query stops without an error.
+			if cur.Cursor.Err() != nil || cur.ctx.Err() != nil {
+				cur.cache.abort(cur.entry)
+			} else {
+				cur.cache.commit(cur.entry)
+			}
+		}
+		return false
+	}
+
+	if !cur.done {
+		if cur.cache.maxRows > 0 && len(cur.entry.rows) >= cur.cache.maxRows {
+			cur.done = true
+			cur.entry.rows = nil
+			cur.cache.abort(cur.entry)
+		} else {
+			values := make([]interface{}, len(row.Values))
+			copy(values, row.Values)
+			cur.entry.rows = append(cur.entry.rows, query.Row{Time: row.Time, Series: row.Series, Values: values})
+		}
+	}
+	return true
+}
+
+func (cur *recordingCursor) Close() error {
+	if !cur.done {
+		// The cursor was not read to the end so the result is incomplete.
+		cur.done = true
+		cur.cache.abort(cur.entry)
+	}
+	return cur.Cursor.Close()
+}
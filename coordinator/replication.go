@@ -0,0 +1,314 @@
+package coordinator
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"go.uber.org/zap"
+)
+
+// Replicator queues the accepted writes durably and forwards them to remote
+// InfluxDB servers. Each target has its own hinted handoff queue, so a target
+// that is down or slow doesn't delay the others, and its writes are sent once
+// it is back. The statistics of the "replication" module are the ones of the
+// queues of the targets.
+type Replicator struct {
+	Logger *zap.Logger
+
+	// TLS is the base TLS configuration of the HTTPS targets.
+	TLS *tls.Config
+
+	dir     string
+	configs []ReplicationConfig
+	targets []*replicationTarget
+
+	wg      sync.WaitGroup
+	closing chan struct{}
+}
+
+// replicationTarget is a remote InfluxDB and the queue of its writes.
+type replicationTarget struct {
+	config  ReplicationConfig
+	url     *url.URL
+	client  *http.Client
+	handoff *HandoffQueue
+
+	databases    map[string]struct{}
+	measurements map[string]struct{}
+}
+
+// NewReplicator returns a new instance of Replicator for the replication
+// targets of a configuration.
+func NewReplicator(c Config) *Replicator {
+	return &Replicator{
+		Logger:  zap.NewNop(),
+		dir:     c.ReplicationDir,
+		configs: c.Replications,
+	}
+}
+
+// WithLogger sets the Logger on r.
+func (r *Replicator) WithLogger(log *zap.Logger) {
+	r.Logger = log.With(zap.String("service", "replication"))
+}
+
+// Open opens the queues of the targets and starts sending their writes.
+func (r *Replicator) Open() error {
+	if len(r.configs) == 0 || r.closing != nil {
+		return nil
+	}
+
+	for _, c := range r.configs {
+		t, err := r.openTarget(c)
+		if err != nil {
+			r.closeTargets()
+			return fmt.Errorf("replication %s: %s", c.Name, err)
+		}
+		r.targets = append(r.targets, t)
+	}
+	r.Logger.Info("Starting replication", zap.Int("targets", len(r.targets)))
+
+	r.closing = make(chan struct{})
+	for _, t := range r.targets {
+		r.wg.Add(1)
+		go r.run(t)
+	}
+	return nil
+}
+
+func (r *Replicator) openTarget(c ReplicationConfig) (*replicationTarget, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(c.Timeout)
+	if timeout == 0 {
+		timeout = DefaultReplicationTimeout
+	}
+
+	client, err := newRemoteClient(r.TLS, c.CaCerts, c.InsecureSkipVerify, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := OpenHandoffQueue(HandoffConfig{
+		Dir:           filepath.Join(r.dir, c.Name),
+		MaxSize:       int64(c.MaxQueueSize),
+		BatchSize:     int(c.BatchSize),
+		RetryInterval: time.Duration(c.RetryInterval),
+		MaxDrainRate:  c.MaxDrainRate,
+	}, r.Logger.With(zap.String("target", c.Name)))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &replicationTarget{
+		config:  c,
+		url:     u,
+		client:  client,
+		handoff: h,
+	}
+	if len(c.Databases) > 0 {
+		t.databases = make(map[string]struct{}, len(c.Databases))
+		for _, name := range c.Databases {
+			t.databases[name] = struct{}{}
+		}
+	}
+	if len(c.Measurements) > 0 {
+		t.measurements = make(map[string]struct{}, len(c.Measurements))
+		for _, name := range c.Measurements {
+			t.measurements[name] = struct{}{}
+		}
+	}
+	return t, nil
+}
+
+// Close stops sending the writes and closes the queues. The unsent writes
+// are sent when the replicator is opened again.
+func (r *Replicator) Close() error {
+	if r.closing == nil {
+		return nil
+	}
+	close(r.closing)
+	r.wg.Wait()
+	r.closing = nil
+	return r.closeTargets()
+}
+
+func (r *Replicator) closeTargets() error {
+	var err error
+	for _, t := range r.targets {
+		if e := t.handoff.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	r.targets = nil
+	return err
+}
+
+// Replicate queues the points of an accepted write for the targets whose
+// filters they match. A target whose queue is full drops the write.
+func (r *Replicator) Replicate(database, retentionPolicy string, points []models.Point) {
+	for _, t := range r.targets {
+		if t.databases != nil {
+			if _, ok := t.databases[database]; !ok {
+				continue
+			}
+		}
+
+		var (
+			data []byte
+			n    int
+		)
+		for _, p := range points {
+			if t.measurements != nil {
+				if _, ok := t.measurements[string(p.Name())]; !ok {
+					continue
+				}
+			}
+			data = p.AppendString(data)
+			data = append(data, '\n')
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		t.handoff.Append(database, retentionPolicy, n, data)
+	}
+}
+
+// run sends the queued writes of a target until the replicator is closed.
+func (r *Replicator) run(t *replicationTarget) {
+	defer r.wg.Done()
+	t.handoff.Drain(r.closing, t.send)
+}
+
+// replicationRejectedError is returned when a target rejects the points of
+// a write, like points with a field type conflict.
+type replicationRejectedError struct {
+	msg string
+}
+
+func (e *replicationRejectedError) Error() string {
+	return e.msg
+}
+
+// Is reports that the write is rejected to the hinted handoff queue.
+func (e *replicationRejectedError) Is(target error) bool {
+	return target == ErrHandoffRejected
+}
+
+// send writes the records to the target. The consecutive records of the same
+// database and retention policy are written together.
+func (t *replicationTarget) send(recs []HandoffWrite) error {
+	for len(recs) > 0 {
+		var (
+			body bytes.Buffer
+			i    int
+		)
+		for i = 0; i < len(recs) && recs[i].Database == recs[0].Database && recs[i].RetentionPolicy == recs[0].RetentionPolicy; i++ {
+			body.Write(recs[i].Data)
+		}
+		if err := t.write(recs[0].Database, recs[0].RetentionPolicy, &body); err != nil {
+			return err
+		}
+		recs = recs[i:]
+	}
+	return nil
+}
+
+// write writes line protocol to a database and retention policy of the
+// target.
+func (t *replicationTarget) write(database, retentionPolicy string, body io.Reader) error {
+	return writeRemote(t.client, t.url, t.config.Username, t.config.Password, database, retentionPolicy, body)
+}
+
+// newRemoteClient returns an HTTP client for a remote InfluxDB. The TLS
+// configuration is based on base, if any, with the CA certificates of the
+// PEM file caCerts if it is set.
+func newRemoteClient(base *tls.Config, caCerts string, insecureSkipVerify bool, timeout time.Duration) (*http.Client, error) {
+	tlsConfig := new(tls.Config)
+	if base != nil {
+		tlsConfig = base.Clone()
+	}
+	tlsConfig.InsecureSkipVerify = insecureSkipVerify
+	if caCerts != "" {
+		pem, err := os.ReadFile(caCerts)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", caCerts)
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// writeRemote writes line protocol with nanosecond timestamps to a database
+// and retention policy of the remote InfluxDB at base. The points rejected as
+// invalid are reported by a *replicationRejectedError.
+func writeRemote(client *http.Client, base *url.URL, username, password, database, retentionPolicy string, body io.Reader) error {
+	u := *base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/write"
+	params := url.Values{"db": []string{database}, "precision": []string{"n"}}
+	if retentionPolicy != "" {
+		params.Set("rp", retentionPolicy)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest("POST", u.String(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusBadRequest:
+		return &replicationRejectedError{msg: fmt.Sprintf("%s: %s", resp.Status, bytes.TrimSpace(msg))}
+	default:
+		// The other errors, like a database that doesn't exist yet or
+		// invalid credentials, are retried until they are fixed.
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (r *Replicator) Statistics(tags map[string]string) []models.Statistic {
+	statistics := make([]models.Statistic, 0, len(r.targets))
+	for _, t := range r.targets {
+		statistics = append(statistics, t.handoff.Statistic("replication", models.StatisticTags{"target": t.config.Name}.Merge(tags)))
+	}
+	return statistics
+}
@@ -0,0 +1,426 @@
+package coordinator
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb/pkg/file"
+)
+
+// ErrReplicationQueueFull is returned when appending a write to a queue that
+// has reached its maximum size.
+var ErrReplicationQueueFull = errors.New("replication queue full")
+
+const (
+	// replicationSegmentSize is the size above which a new segment file is
+	// started.
+	replicationSegmentSize = 16 << 20
+
+	// replicationHeaderSize is the size of the length and checksum that
+	// precede every record.
+	replicationHeaderSize = 8
+
+	// replicationCursorFile is the name of the file holding the position of
+	// the next record to send.
+	replicationCursorFile = "cursor"
+
+	replicationSegmentExt = ".seg"
+
+	// replicationUserFlag is set in the length of the records that have a
+	// user, which were added after the first version of the format.
+	replicationUserFlag = 1 << 31
+)
+
+// replicationRecord is a write queued for replication, or queued to be
+// applied by the write queue.
+type replicationRecord struct {
+	// Time the write was queued at, in nanoseconds.
+	Time int64
+
+	Database        string
+	RetentionPolicy string
+
+	// User the write is applied as, if any.
+	User string
+
+	// Number of points and their line protocol with timestamps in
+	// nanoseconds.
+	N    int
+	Data []byte
+}
+
+func (r *replicationRecord) marshal() []byte {
+	b := make([]byte, replicationHeaderSize, replicationHeaderSize+len(r.Database)+len(r.RetentionPolicy)+len(r.User)+len(r.Data)+5*binary.MaxVarintLen64)
+	b = binary.AppendVarint(b, r.Time)
+	b = binary.AppendUvarint(b, uint64(len(r.Database)))
+	b = append(b, r.Database...)
+	b = binary.AppendUvarint(b, uint64(len(r.RetentionPolicy)))
+	b = append(b, r.RetentionPolicy...)
+	if r.User != "" {
+		b = binary.AppendUvarint(b, uint64(len(r.User)))
+		b = append(b, r.User...)
+	}
+	b = binary.AppendUvarint(b, uint64(r.N))
+	b = append(b, r.Data...)
+
+	body := b[replicationHeaderSize:]
+	length := uint32(len(body))
+	if r.User != "" {
+		length |= replicationUserFlag
+	}
+	binary.BigEndian.PutUint32(b[0:4], length)
+	binary.BigEndian.PutUint32(b[4:8], crc32.ChecksumIEEE(body))
+	return b
+}
+
+func (r *replicationRecord) unmarshal(body []byte, hasUser bool) error {
+	t, n := binary.Varint(body)
+	if n <= 0 {
+		return errors.New("invalid time")
+	}
+	r.Time, body = t, body[n:]
+
+	s := make([]string, 2, 3)
+	if hasUser {
+		s = s[:3]
+	}
+	for i := range s {
+		l, n := binary.Uvarint(body)
+		if n <= 0 || l > uint64(len(body)-n) {
+			return errors.New("invalid name")
+		}
+		s[i], body = string(body[n:n+int(l)]), body[n+int(l):]
+	}
+	r.Database, r.RetentionPolicy = s[0], s[1]
+	if hasUser {
+		r.User = s[2]
+	}
+
+	count, n := binary.Uvarint(body)
+	if n <= 0 {
+		return errors.New("invalid point count")
+	}
+	r.N, r.Data = int(count), body[n:]
+	return nil
+}
+
+// readReplicationRecord reads the record at the current position of r. It
+// returns io.EOF at the end of the file and io.ErrUnexpectedEOF if the
+// record is incomplete or corrupt, like a record being written when the
+// server crashed.
+func readReplicationRecord(r io.Reader) (replicationRecord, int64, error) {
+	var hdr [replicationHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err == io.EOF {
+		return replicationRecord{}, 0, io.EOF
+	} else if err != nil {
+		return replicationRecord{}, 0, io.ErrUnexpectedEOF
+	}
+
+	length := binary.BigEndian.Uint32(hdr[0:4])
+	body := make([]byte, length&^replicationUserFlag)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return replicationRecord{}, 0, io.ErrUnexpectedEOF
+	} else if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return replicationRecord{}, 0, io.ErrUnexpectedEOF
+	}
+
+	var rec replicationRecord
+	if err := rec.unmarshal(body, length&replicationUserFlag != 0); err != nil {
+		return replicationRecord{}, 0, io.ErrUnexpectedEOF
+	}
+	return rec, int64(len(hdr) + len(body)), nil
+}
+
+// replicationPosition is the position of a record in a queue.
+type replicationPosition struct {
+	Segment uint64
+	Offset  int64
+}
+
+// replicationSegment is a file of a queue.
+type replicationSegment struct {
+	id   uint64
+	size int64
+}
+
+// replicationQueue is a durable FIFO queue of the writes to replicate to a
+// target, which also backs the write queue. The records are appended to segment files in a directory and the
+// position of the next record to send is saved in a cursor file, so the
+// queued writes survive a restart. The segments are removed once all of
+// their records are sent.
+type replicationQueue struct {
+	mu       sync.Mutex
+	dir      string
+	maxSize  int64
+	segments []replicationSegment // oldest first, the last one is appended to
+	w        *os.File
+	pos      replicationPosition // position of the next record to send
+
+	segmentSize int64
+
+	// notify is signaled when a record is appended.
+	notify chan struct{}
+}
+
+// openReplicationQueue opens the queue in a directory, creating it if it
+// doesn't exist.
+func openReplicationQueue(dir string, maxSize int64) (*replicationQueue, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	q := &replicationQueue{
+		dir:         dir,
+		maxSize:     maxSize,
+		segmentSize: replicationSegmentSize,
+		notify:      make(chan struct{}, 1),
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "*"+replicationSegmentExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		id, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), replicationSegmentExt), 16, 64)
+		if err != nil {
+			continue
+		}
+		fi, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		q.segments = append(q.segments, replicationSegment{id: id, size: fi.Size()})
+	}
+	sort.Slice(q.segments, func(i, j int) bool { return q.segments[i].id < q.segments[j].id })
+
+	if err := q.readCursor(); err != nil {
+		return nil, err
+	}
+
+	// Start with a new segment rather than appending to a segment that may
+	// end with an incomplete record.
+	id := uint64(1)
+	if n := len(q.segments); n > 0 {
+		id = q.segments[n-1].id + 1
+	}
+	if err := q.openSegment(id); err != nil {
+		return nil, err
+	}
+
+	// Start from the oldest segment if the cursor refers to a segment that
+	// doesn't exist anymore. Sending a write again is harmless.
+	found := false
+	for _, s := range q.segments {
+		found = found || s.id == q.pos.Segment
+	}
+	if !found {
+		q.pos = replicationPosition{Segment: q.segments[0].id}
+	}
+	return q, nil
+}
+
+// readCursor reads the position of the next record to send.
+func (q *replicationQueue) readCursor() error {
+	b, err := os.ReadFile(filepath.Join(q.dir, replicationCursorFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if len(b) != 16 {
+		return fmt.Errorf("invalid replication cursor in %s", q.dir)
+	}
+	q.pos = replicationPosition{
+		Segment: binary.BigEndian.Uint64(b[0:8]),
+		Offset:  int64(binary.BigEndian.Uint64(b[8:16])),
+	}
+	return nil
+}
+
+// writeCursor saves the position of the next record to send.
+func (q *replicationQueue) writeCursor() error {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[0:8], q.pos.Segment)
+	binary.BigEndian.PutUint64(b[8:16], uint64(q.pos.Offset))
+
+	path := filepath.Join(q.dir, replicationCursorFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b[:], 0666); err != nil {
+		return err
+	}
+	return file.RenameFile(tmp, path)
+}
+
+// openSegment creates the segment that records are appended to.
+func (q *replicationQueue) openSegment(id uint64) error {
+	f, err := os.OpenFile(q.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	q.w = f
+	q.segments = append(q.segments, replicationSegment{id: id})
+	return nil
+}
+
+func (q *replicationQueue) segmentPath(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%016x%s", id, replicationSegmentExt))
+}
+
+// Close closes the queue. The queued records are kept.
+func (q *replicationQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.w == nil {
+		return nil
+	}
+	err := q.w.Close()
+	q.w = nil
+	return err
+}
+
+// Size returns the size of the records that are not sent yet.
+func (q *replicationQueue) Size() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size()
+}
+
+func (q *replicationQueue) size() int64 {
+	var n int64
+	for _, s := range q.segments {
+		if s.id >= q.pos.Segment {
+			n += s.size
+		}
+	}
+	return n - q.pos.Offset
+}
+
+// Append adds a record to the end of the queue and syncs it to disk. It
+// returns ErrReplicationQueueFull if the queue would exceed its maximum
+// size.
+func (q *replicationQueue) Append(rec *replicationRecord) error {
+	b := rec.marshal()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.w == nil {
+		return errors.New("replication queue closed")
+	} else if q.maxSize > 0 && q.size()+int64(len(b)) > q.maxSize {
+		return ErrReplicationQueueFull
+	}
+
+	// Start a new segment once the current one is full.
+	tail := &q.segments[len(q.segments)-1]
+	if tail.size >= q.segmentSize {
+		if err := q.w.Close(); err != nil {
+			return err
+		} else if err := q.openSegment(tail.id + 1); err != nil {
+			q.w = nil
+			return err
+		}
+		tail = &q.segments[len(q.segments)-1]
+	}
+
+	if _, err := q.w.Write(b); err != nil {
+		return err
+	} else if err := q.w.Sync(); err != nil {
+		return err
+	}
+	tail.size += int64(len(b))
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Peek returns the records at the head of the queue, up to about maxBytes
+// of them but at least one if the queue is not empty, and the position
+// after them that is passed to Advance once they are sent.
+func (q *replicationQueue) Peek(maxBytes int) ([]replicationRecord, replicationPosition, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var (
+		recs []replicationRecord
+		n    int
+		pos  = q.pos
+	)
+	for _, s := range q.segments {
+		if s.id < pos.Segment {
+			continue
+		} else if s.id > pos.Segment {
+			pos = replicationPosition{Segment: s.id}
+		}
+		if pos.Offset >= s.size {
+			continue
+		}
+
+		f, err := os.Open(q.segmentPath(s.id))
+		if err != nil {
+			return nil, q.pos, err
+		}
+		if _, err := f.Seek(pos.Offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, q.pos, err
+		}
+		r := bufio.NewReader(io.LimitReader(f, s.size-pos.Offset))
+		for n < maxBytes || len(recs) == 0 {
+			rec, size, err := readReplicationRecord(r)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				// Skip the rest of a segment that ends with an incomplete
+				// record.
+				pos.Offset = s.size
+				break
+			}
+			recs = append(recs, rec)
+			pos.Offset += size
+			n += int(size)
+		}
+		f.Close()
+
+		if n >= maxBytes && len(recs) > 0 {
+			break
+		}
+	}
+	return recs, pos, nil
+}
+
+// Advance moves the head of the queue to a position returned by Peek and
+// removes the segments before it.
+func (q *replicationQueue) Advance(pos replicationPosition) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pos = pos
+	if err := q.writeCursor(); err != nil {
+		return err
+	}
+
+	segments := q.segments[:0]
+	for _, s := range q.segments {
+		if s.id < pos.Segment {
+			if err := os.Remove(q.segmentPath(s.id)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		segments = append(segments, s)
+	}
+	q.segments = segments
+	return nil
+}
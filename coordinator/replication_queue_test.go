@@ -0,0 +1,139 @@
+package coordinator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplicationQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := openReplicationQueue(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.segmentSize = 100
+
+	for i := 0; i < 10; i++ {
+		rec := &replicationRecord{Time: int64(i), Database: "db0", RetentionPolicy: "rp0", N: 1, Data: []byte(fmt.Sprintf("cpu value=%d %d\n", i, i))}
+		if err := q.Append(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if names, _ := filepath.Glob(filepath.Join(dir, "*.seg")); len(names) < 2 {
+		t.Fatalf("expected several segments: %v", names)
+	}
+
+	// The records are read in order across the segments.
+	recs, pos, err := q.Peek(90)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(recs) != 3 || recs[0].Time != 0 || recs[2].Time != 2 {
+		t.Fatalf("unexpected records: %+v", recs)
+	} else if rec := recs[1]; rec.Database != "db0" || rec.RetentionPolicy != "rp0" || rec.N != 1 || string(rec.Data) != "cpu value=1 1\n" {
+		t.Fatalf("unexpected record: %+v", rec)
+	} else if err := q.Advance(pos); err != nil {
+		t.Fatal(err)
+	}
+
+	// The position of the next record survives a restart.
+	size := q.Size()
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	} else if q, err = openReplicationQueue(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	if got := q.Size(); got != size {
+		t.Fatalf("unexpected size: got=%d want=%d", got, size)
+	}
+
+	recs, pos, err = q.Peek(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(recs) != 7 || recs[0].Time != 3 || recs[6].Time != 9 {
+		t.Fatalf("unexpected records: %+v", recs)
+	} else if err := q.Advance(pos); err != nil {
+		t.Fatal(err)
+	} else if recs, _, err := q.Peek(1 << 20); err != nil || len(recs) != 0 {
+		t.Fatalf("unexpected records: %+v %v", recs, err)
+	} else if q.Size() != 0 {
+		t.Fatalf("unexpected size: %d", q.Size())
+	}
+
+	// The segments are removed once they are sent.
+	if names, _ := filepath.Glob(filepath.Join(dir, "*.seg")); len(names) != 1 {
+		t.Fatalf("unexpected segments: %v", names)
+	}
+}
+
+func TestReplicationQueue_Full(t *testing.T) {
+	q, err := openReplicationQueue(t.TempDir(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	rec := &replicationRecord{Database: "db0", N: 1, Data: []byte("cpu value=1 0\n")}
+	var n int
+	for ; n < 10; n++ {
+		if err := q.Append(rec); err == ErrReplicationQueueFull {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n == 0 || n == 10 {
+		t.Fatalf("unexpected number of records before the queue is full: %d", n)
+	}
+
+	// Sending the records makes room for new ones.
+	if _, pos, err := q.Peek(1 << 20); err != nil {
+		t.Fatal(err)
+	} else if err := q.Advance(pos); err != nil {
+		t.Fatal(err)
+	} else if err := q.Append(rec); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplicationQueue_IncompleteRecord(t *testing.T) {
+	dir := t.TempDir()
+	q, err := openReplicationQueue(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := q.Append(&replicationRecord{Time: int64(i), Database: "db0", N: 1, Data: []byte("cpu value=1 0\n")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Truncate the last record like a crash while it was written.
+	path := q.segmentPath(q.segments[0].id)
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := os.Truncate(path, fi.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+
+	if q, err = openReplicationQueue(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	if err := q.Append(&replicationRecord{Time: 2, Database: "db0", N: 1, Data: []byte("cpu value=2 0\n")}); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, _, err := q.Peek(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(recs) != 2 || recs[0].Time != 0 || recs[1].Time != 2 {
+		t.Fatalf("unexpected records: %+v", recs)
+	}
+}
@@ -0,0 +1,167 @@
+package coordinator_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/toml"
+)
+
+// replicationTarget is a remote server that records the writes it receives.
+type replicationTarget struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	writes []string
+	status int
+}
+
+func newReplicationTarget() *replicationTarget {
+	t := &replicationTarget{status: http.StatusNoContent}
+	t.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.status == http.StatusNoContent {
+			u, p, _ := r.BasicAuth()
+			t.writes = append(t.writes, r.URL.Path+"?"+r.URL.RawQuery+" "+u+":"+p+" "+string(b))
+		}
+		w.WriteHeader(t.status)
+	}))
+	return t
+}
+
+func (t *replicationTarget) setStatus(status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = status
+}
+
+// waitWrites waits for the target to receive n writes and returns them.
+func (t *replicationTarget) waitWrites(tb testing.TB, n int) []string {
+	tb.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		t.mu.Lock()
+		writes := append([]string(nil), t.writes...)
+		t.mu.Unlock()
+		if len(writes) >= n {
+			return writes
+		}
+	}
+	tb.Fatalf("timed out waiting for %d writes", n)
+	return nil
+}
+
+func TestReplicator(t *testing.T) {
+	target := newReplicationTarget()
+	defer target.Close()
+
+	c := coordinator.NewConfig()
+	c.ReplicationDir = t.TempDir()
+	c.Replications = []coordinator.ReplicationConfig{{
+		Name:          "dr",
+		URL:           target.URL,
+		Username:      "bob",
+		Password:      "secret",
+		Databases:     []string{"db0"},
+		Measurements:  []string{"cpu"},
+		RetryInterval: toml.Duration(10 * time.Millisecond),
+	}}
+
+	r := coordinator.NewReplicator(c)
+	if err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	points := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, time.Unix(0, 1)),
+		models.MustNewPoint("mem", nil, models.Fields{"value": 2.0}, time.Unix(0, 2)),
+	}
+	r.Replicate("db0", "rp0", points)
+	r.Replicate("db1", "", points)
+
+	// Only the points of the filtered databases and measurements are sent.
+	if writes := target.waitWrites(t, 1); writes[0] != "/write?db=db0&precision=n&rp=rp0 bob:secret cpu,host=a value=1 1\n" {
+		t.Fatalf("unexpected write: %q", writes[0])
+	}
+
+	// The writes are queued while the target is down and sent once it is
+	// back, also after a restart.
+	target.setStatus(http.StatusServiceUnavailable)
+	r.Replicate("db0", "", points[:1])
+	time.Sleep(50 * time.Millisecond)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := r.Statistics(nil)
+	if len(stats) != 0 {
+		t.Fatalf("unexpected statistics after close: %v", stats)
+	}
+
+	r = coordinator.NewReplicator(c)
+	if err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if stats := r.Statistics(map[string]string{"host": "h"}); len(stats) != 1 {
+		t.Fatalf("unexpected statistics: %v", stats)
+	} else if s := stats[0]; s.Name != "replication" || s.Tags["target"] != "dr" || s.Tags["host"] != "h" || s.Values["queueBytes"].(int64) == 0 {
+		t.Fatalf("unexpected statistics: %v", s)
+	}
+
+	target.setStatus(http.StatusNoContent)
+	if writes := target.waitWrites(t, 2); writes[1] != "/write?db=db0&precision=n bob:secret cpu,host=a value=1 1\n" {
+		t.Fatalf("unexpected write: %q", writes[1])
+	}
+
+	// The writes rejected by the target are dropped rather than retried.
+	target.setStatus(http.StatusBadRequest)
+	r.Replicate("db0", "", points[:1])
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		s := r.Statistics(nil)[0]
+		if s.Values["writeDropped"].(int64) == 1 && s.Values["queueBytes"].(int64) == 0 {
+			if got := s.Values["pointsSent"].(int64); got != 1 {
+				t.Fatalf("unexpected points sent: %d", got)
+			}
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("unexpected statistics: %v", s)
+		}
+	}
+}
+
+func TestReplicator_QueueFull(t *testing.T) {
+	target := newReplicationTarget()
+	defer target.Close()
+	target.setStatus(http.StatusServiceUnavailable)
+
+	c := coordinator.NewConfig()
+	c.ReplicationDir = t.TempDir()
+	c.Replications = []coordinator.ReplicationConfig{{
+		Name:          "dr",
+		URL:           target.URL,
+		MaxQueueSize:  100,
+		RetryInterval: toml.Duration(time.Hour),
+	}}
+
+	r := coordinator.NewReplicator(c)
+	if err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	points := []models.Point{models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0}, time.Unix(0, 1))}
+	for i := 0; i < 10; i++ {
+		r.Replicate("db0", "", points)
+	}
+	if s := r.Statistics(nil)[0]; s.Values["queueFull"].(int64) == 0 {
+		t.Fatalf("unexpected statistics: %v", s)
+	}
+}
@@ -0,0 +1,245 @@
+package coordinator
+
+import (
+	"bytes"
+	"crypto/tls"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"go.uber.org/zap"
+)
+
+// The keys for statistics generated by the "shadowWrite" module.
+const (
+	statShadowWriteReq         = "writeReq"          // Number of writes mirrored to the secondary server
+	statShadowWriteDropped     = "writeDropped"      // Number of writes not mirrored because too many were pending
+	statShadowWriteOK          = "writeOk"           // Number of mirrored writes that succeeded on the secondary server
+	statShadowWriteErr         = "writeError"        // Number of mirrored writes that failed on the secondary server
+	statShadowPrimaryWriteErr  = "primaryWriteError" // Number of mirrored writes that failed on this server
+	statShadowWriteMismatch    = "writeMismatch"     // Number of mirrored writes that failed on only one of the servers
+	statShadowPointsSent       = "pointsSent"        // Number of points sent to the secondary server
+	statShadowPrimaryDuration  = "primaryDurationNs" // Total time of the mirrored writes on this server
+	statShadowWriteDuration    = "writeDurationNs"   // Total time of the mirrored writes on the secondary server
+	statShadowWriteSlowerCount = "writeSlower"       // Number of mirrored writes that were slower on the secondary server
+)
+
+// ShadowWriter mirrors a percentage of the writes to a secondary InfluxDB,
+// such as a new storage tier to validate, and records how its latency and
+// errors diverge from the ones of this server. The writes are mirrored once
+// they are acknowledged and in the background, so they never delay or fail
+// the writes to this server.
+type ShadowWriter struct {
+	Logger *zap.Logger
+
+	// TLS is the base TLS configuration of an HTTPS secondary server.
+	TLS *tls.Config
+
+	config    ShadowWriteConfig
+	url       *url.URL
+	client    *http.Client
+	databases map[string]struct{}
+
+	writes  chan *shadowWrite
+	wg      sync.WaitGroup
+	closing chan struct{}
+
+	stats shadowWriteStatistics
+}
+
+// shadowWrite is a write to mirror and its result on this server.
+type shadowWrite struct {
+	database        string
+	retentionPolicy string
+	data            []byte
+	n               int
+	duration        time.Duration
+	failed          bool
+}
+
+type shadowWriteStatistics struct {
+	WriteReq         int64
+	WriteDropped     int64
+	WriteOK          int64
+	WriteErr         int64
+	PrimaryWriteErr  int64
+	WriteMismatch    int64
+	PointsSent       int64
+	PrimaryDuration  int64
+	WriteDuration    int64
+	WriteSlowerCount int64
+}
+
+// NewShadowWriter returns a new instance of ShadowWriter for the shadow write
+// configuration of c.
+func NewShadowWriter(c Config) *ShadowWriter {
+	return &ShadowWriter{
+		Logger: zap.NewNop(),
+		config: c.ShadowWrite,
+	}
+}
+
+// WithLogger sets the Logger on s.
+func (s *ShadowWriter) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "shadow-write"))
+}
+
+// Open starts mirroring the writes if a secondary server is configured.
+func (s *ShadowWriter) Open() error {
+	if s.config.URL == "" || s.closing != nil {
+		return nil
+	}
+
+	u, err := url.Parse(s.config.URL)
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(s.config.Timeout)
+	if timeout == 0 {
+		timeout = DefaultShadowWriteTimeout
+	}
+	if s.client, err = newRemoteClient(s.TLS, s.config.CaCerts, s.config.InsecureSkipVerify, timeout); err != nil {
+		return err
+	}
+	s.url = u
+
+	if len(s.config.Databases) > 0 {
+		s.databases = make(map[string]struct{}, len(s.config.Databases))
+		for _, name := range s.config.Databases {
+			s.databases[name] = struct{}{}
+		}
+	}
+
+	concurrency, maxPending := s.config.Concurrency, s.config.MaxPendingWrites
+	if concurrency == 0 {
+		concurrency = DefaultShadowWriteConcurrency
+	}
+	if maxPending == 0 {
+		maxPending = DefaultShadowWriteMaxPendingWrites
+	}
+	s.Logger.Info("Starting shadow writes", zap.String("url", u.Redacted()), zap.Float64("percent", s.config.Percent))
+
+	s.writes = make(chan *shadowWrite, maxPending)
+	s.closing = make(chan struct{})
+	for i := 0; i < concurrency; i++ {
+		s.wg.Add(1)
+		go s.run()
+	}
+	return nil
+}
+
+// Close stops mirroring the writes. The pending writes are dropped.
+func (s *ShadowWriter) Close() error {
+	if s.closing == nil {
+		return nil
+	}
+	close(s.closing)
+	s.wg.Wait()
+	s.closing = nil
+	return nil
+}
+
+// Shadow mirrors a write with a probability of the configured percentage.
+// The write took d on this server and failed if err is not nil. The write is
+// dropped if too many writes are pending.
+func (s *ShadowWriter) Shadow(database, retentionPolicy string, points []models.Point, d time.Duration, err error) {
+	if s.writes == nil || len(points) == 0 {
+		return
+	} else if s.databases != nil {
+		if _, ok := s.databases[database]; !ok {
+			return
+		}
+	}
+	if s.config.Percent < 100 && rand.Float64()*100 >= s.config.Percent {
+		return
+	}
+
+	// The points are serialized right away as their buffers may be reused
+	// once the write returns.
+	w := &shadowWrite{
+		database:        database,
+		retentionPolicy: retentionPolicy,
+		n:               len(points),
+		duration:        d,
+		failed:          err != nil,
+	}
+	for _, p := range points {
+		w.data = p.AppendString(w.data)
+		w.data = append(w.data, '\n')
+	}
+
+	select {
+	case s.writes <- w:
+		atomic.AddInt64(&s.stats.WriteReq, 1)
+	default:
+		atomic.AddInt64(&s.stats.WriteDropped, 1)
+	}
+}
+
+// run sends the mirrored writes until the shadow writer is closed.
+func (s *ShadowWriter) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.closing:
+			return
+		case w := <-s.writes:
+			s.send(w)
+		}
+	}
+}
+
+// send writes a mirrored write to the secondary server and records how its
+// result diverges from the one of this server.
+func (s *ShadowWriter) send(w *shadowWrite) {
+	start := time.Now()
+	err := writeRemote(s.client, s.url, s.config.Username, s.config.Password, w.database, w.retentionPolicy, bytes.NewReader(w.data))
+	d := time.Since(start)
+
+	if err != nil {
+		atomic.AddInt64(&s.stats.WriteErr, 1)
+		s.Logger.Debug("Shadow write failed", zap.String("db", w.database), zap.Error(err))
+	} else {
+		atomic.AddInt64(&s.stats.WriteOK, 1)
+		atomic.AddInt64(&s.stats.PointsSent, int64(w.n))
+	}
+	if w.failed {
+		atomic.AddInt64(&s.stats.PrimaryWriteErr, 1)
+	}
+	if w.failed != (err != nil) {
+		atomic.AddInt64(&s.stats.WriteMismatch, 1)
+	}
+	atomic.AddInt64(&s.stats.PrimaryDuration, int64(w.duration))
+	atomic.AddInt64(&s.stats.WriteDuration, int64(d))
+	if d > w.duration {
+		atomic.AddInt64(&s.stats.WriteSlowerCount, 1)
+	}
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (s *ShadowWriter) Statistics(tags map[string]string) []models.Statistic {
+	if s.writes == nil {
+		return nil
+	}
+	return []models.Statistic{{
+		Name: "shadowWrite",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statShadowWriteReq:         atomic.LoadInt64(&s.stats.WriteReq),
+			statShadowWriteDropped:     atomic.LoadInt64(&s.stats.WriteDropped),
+			statShadowWriteOK:          atomic.LoadInt64(&s.stats.WriteOK),
+			statShadowWriteErr:         atomic.LoadInt64(&s.stats.WriteErr),
+			statShadowPrimaryWriteErr:  atomic.LoadInt64(&s.stats.PrimaryWriteErr),
+			statShadowWriteMismatch:    atomic.LoadInt64(&s.stats.WriteMismatch),
+			statShadowPointsSent:       atomic.LoadInt64(&s.stats.PointsSent),
+			statShadowPrimaryDuration:  atomic.LoadInt64(&s.stats.PrimaryDuration),
+			statShadowWriteDuration:    atomic.LoadInt64(&s.stats.WriteDuration),
+			statShadowWriteSlowerCount: atomic.LoadInt64(&s.stats.WriteSlowerCount),
+		},
+	}}
+}
@@ -0,0 +1,87 @@
+package coordinator_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestShadowWriter(t *testing.T) {
+	target := newReplicationTarget()
+	defer target.Close()
+
+	c := coordinator.NewConfig()
+	c.ShadowWrite.URL = target.URL
+	c.ShadowWrite.Username = "bob"
+	c.ShadowWrite.Password = "secret"
+	c.ShadowWrite.Databases = []string{"db0"}
+
+	s := coordinator.NewShadowWriter(c)
+	if stats := s.Statistics(nil); len(stats) != 0 {
+		t.Fatalf("unexpected statistics before open: %v", stats)
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	points := []models.Point{models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, time.Unix(0, 1))}
+	s.Shadow("db1", "", points, time.Millisecond, nil)
+	s.Shadow("db0", "rp0", points, time.Millisecond, nil)
+	if writes := target.waitWrites(t, 1); writes[0] != "/write?db=db0&precision=n&rp=rp0 bob:secret cpu,host=a value=1 1\n" {
+		t.Fatalf("unexpected write: %q", writes[0])
+	}
+
+	// A write that failed on this server but not on the secondary one is a
+	// mismatch.
+	s.Shadow("db0", "", points, time.Millisecond, errors.New("write failed"))
+	target.waitWrites(t, 2)
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		stats := s.Statistics(map[string]string{"host": "h"})
+		if len(stats) != 1 {
+			t.Fatalf("unexpected statistics: %v", stats)
+		}
+		st := stats[0]
+		if st.Values["writeOk"].(int64) < 2 {
+			if time.Now().After(deadline) {
+				t.Fatalf("unexpected statistics: %v", st)
+			}
+			continue
+		}
+
+		if st.Name != "shadowWrite" || st.Tags["host"] != "h" ||
+			st.Values["writeReq"].(int64) != 2 || st.Values["writeError"].(int64) != 0 ||
+			st.Values["primaryWriteError"].(int64) != 1 || st.Values["writeMismatch"].(int64) != 1 ||
+			st.Values["pointsSent"].(int64) != 2 || st.Values["primaryDurationNs"].(int64) != int64(2*time.Millisecond) {
+			t.Fatalf("unexpected statistics: %v", st)
+		}
+		break
+	}
+}
+
+func TestShadowWriter_Percent(t *testing.T) {
+	target := newReplicationTarget()
+	defer target.Close()
+
+	c := coordinator.NewConfig()
+	c.ShadowWrite.URL = target.URL
+	c.ShadowWrite.Percent = 0
+
+	s := coordinator.NewShadowWriter(c)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	points := []models.Point{models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0}, time.Unix(0, 1))}
+	for i := 0; i < 10; i++ {
+		s.Shadow("db0", "", points, time.Millisecond, nil)
+	}
+	if st := s.Statistics(nil)[0]; st.Values["writeReq"].(int64) != 0 {
+		t.Fatalf("unexpected statistics: %v", st)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/influxdb/pkg/tracing"
 	"github.com/influxdata/influxdb/pkg/tracing/fields"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/audit"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
@@ -58,6 +59,18 @@ type StatementExecutor struct {
 	MaxSelectPointN   int
 	MaxSelectSeriesN  int
 	MaxSelectBucketsN int
+
+	// Holds the results of SELECT statements. If nil, results are not cached.
+	QueryCache *QueryCache
+
+	// User-defined functions that can be called in SELECT statements.
+	UserFunctions query.UserFunctions
+
+	// Records the statements that change the meta data. If nil, they are
+	// not recorded.
+	AuditLog interface {
+		Record(audit.Event)
+	}
 }
 
 // ExecuteStatement executes the given statement with the given execution context.
@@ -154,6 +167,11 @@ func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt i
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeGrantStatement(stmt)
+	case *query.GrantMeasurementStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeGrantMeasurementStatement(stmt)
 	case *influxql.GrantAdminStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
@@ -164,11 +182,86 @@ func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt i
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeRevokeStatement(stmt)
+	case *query.RevokeMeasurementStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeRevokeMeasurementStatement(stmt)
 	case *influxql.RevokeAdminStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeRevokeAdminStatement(stmt)
+	case *query.CreateRoleStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeCreateRoleStatement(stmt)
+	case *query.DropRoleStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeDropRoleStatement(stmt)
+	case *query.GrantRoleStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeGrantRoleStatement(stmt)
+	case *query.RevokeRoleStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeRevokeRoleStatement(stmt)
+	case *query.GrantRolePrivilegeStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeGrantRolePrivilegeStatement(stmt)
+	case *query.RevokeRolePrivilegeStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeRevokeRolePrivilegeStatement(stmt)
+	case *query.CreateTokenStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		rows, err = e.executeCreateTokenStatement(stmt)
+	case *query.DropTokenStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeDropTokenStatement(stmt)
+	case *query.CreateShardGroupStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		rows, err = e.executeCreateShardGroupStatement(stmt)
+	case *query.TruncateShardGroupsStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeTruncateShardGroupsStatement(stmt)
+	case *query.SetQuotaStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeSetQuotaStatement(stmt)
+	case *query.DropQuotaStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeDropQuotaStatement(stmt)
+	case *query.RenameDatabaseStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeRenameDatabaseStatement(stmt)
+	case *query.RenameMeasurementStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeRenameMeasurementStatement(stmt, ctx.Database)
 	case *influxql.ShowContinuousQueriesStatement:
 		rows, err = e.executeShowContinuousQueriesStatement(ctx, stmt)
 	case *influxql.ShowDatabasesStatement:
@@ -177,22 +270,32 @@ func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt i
 		rows, err = e.executeShowDiagnosticsStatement(stmt)
 	case *influxql.ShowGrantsForUserStatement:
 		rows, err = e.executeShowGrantsForUserStatement(stmt)
+	case *query.ShowGrantsForRoleStatement:
+		rows, err = e.executeShowGrantsForRoleStatement(stmt)
 	case *influxql.ShowMeasurementsStatement:
 		return e.executeShowMeasurementsStatement(ctx, stmt)
 	case *influxql.ShowMeasurementCardinalityStatement:
 		rows, err = e.executeShowMeasurementCardinalityStatement(ctx, stmt)
 	case *influxql.ShowRetentionPoliciesStatement:
 		rows, err = e.executeShowRetentionPoliciesStatement(stmt)
+	case *query.ShowQuotasStatement:
+		rows, err = e.executeShowQuotasStatement(stmt)
+	case *query.ShowRolesStatement:
+		rows, err = e.executeShowRolesStatement(stmt)
 	case *influxql.ShowSeriesCardinalityStatement:
 		rows, err = e.executeShowSeriesCardinalityStatement(ctx, stmt)
 	case *influxql.ShowShardsStatement:
 		rows, err = e.executeShowShardsStatement(stmt)
 	case *influxql.ShowShardGroupsStatement:
+		rows, err = e.executeShowShardGroupsStatement(&query.ShowShardGroupsStatement{})
+	case *query.ShowShardGroupsStatement:
 		rows, err = e.executeShowShardGroupsStatement(stmt)
 	case *influxql.ShowStatsStatement:
 		rows, err = e.executeShowStatsStatement(stmt)
 	case *influxql.ShowSubscriptionsStatement:
 		rows, err = e.executeShowSubscriptionsStatement(stmt)
+	case *query.ShowTokensStatement:
+		rows, err = e.executeShowTokensStatement(stmt)
 	case *influxql.ShowTagKeysStatement:
 		return e.executeShowTagKeys(ctx, stmt)
 	case *influxql.ShowTagValuesStatement:
@@ -204,6 +307,21 @@ func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt i
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeSetPasswordUserStatement(stmt)
+	case *query.SetUserTimeZoneStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeSetUserTimeZoneStatement(stmt)
+	case *query.SetUserWriteTargetStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeSetUserWriteTargetStatement(stmt)
+	case *query.SetUserQueryPriorityStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeSetUserQueryPriorityStatement(stmt)
 	case *influxql.ShowQueriesStatement, *influxql.KillQueryStatement:
 		// Send query related statements to the task manager.
 		return e.TaskManager.ExecuteStatement(ctx, stmt)
@@ -211,16 +329,147 @@ func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt i
 		return query.ErrInvalidQuery
 	}
 
+	// Drop the cached results that may contain removed data. This is done
+	// even if the statement failed as some of the data may have been removed.
+	if e.QueryCache != nil {
+		e.invalidateQueryCache(stmt, ctx.Database)
+	}
+
 	if err != nil {
 		return err
 	}
 
+	if e.AuditLog != nil {
+		e.recordAuditEvent(ctx, stmt)
+	}
+
 	return ctx.Send(&query.Result{
 		Series:   rows,
 		Messages: messages,
 	})
 }
 
+// recordAuditEvent records the user and the client that executed a
+// statement that changed the meta data.
+func (e *StatementExecutor) recordAuditEvent(ctx *query.ExecutionContext, stmt influxql.Statement) {
+	action, database := auditAction(stmt)
+	if action == "" {
+		return
+	}
+	e.AuditLog.Record(audit.Event{
+		User:      ctx.User,
+		Source:    ctx.Source,
+		Action:    action,
+		Database:  database,
+		Statement: stmt.String(),
+	})
+}
+
+// auditAction returns the action and the database of a statement that
+// changes the meta data. The action is empty for the other statements.
+func auditAction(stmt influxql.Statement) (action, database string) {
+	switch stmt := stmt.(type) {
+	case *influxql.AlterRetentionPolicyStatement:
+		return "ALTER RETENTION POLICY", stmt.Database
+	case *influxql.CreateContinuousQueryStatement:
+		return "CREATE CONTINUOUS QUERY", stmt.Database
+	case *influxql.CreateDatabaseStatement:
+		return "CREATE DATABASE", stmt.Name
+	case *influxql.CreateRetentionPolicyStatement:
+		return "CREATE RETENTION POLICY", stmt.Database
+	case *influxql.CreateSubscriptionStatement:
+		return "CREATE SUBSCRIPTION", stmt.Database
+	case *influxql.CreateUserStatement:
+		return "CREATE USER", ""
+	case *influxql.DropContinuousQueryStatement:
+		return "DROP CONTINUOUS QUERY", stmt.Database
+	case *influxql.DropDatabaseStatement:
+		return "DROP DATABASE", stmt.Name
+	case *influxql.DropRetentionPolicyStatement:
+		return "DROP RETENTION POLICY", stmt.Database
+	case *influxql.DropShardStatement:
+		return "DROP SHARD", ""
+	case *influxql.DropSubscriptionStatement:
+		return "DROP SUBSCRIPTION", stmt.Database
+	case *influxql.DropUserStatement:
+		return "DROP USER", ""
+	case *influxql.GrantStatement:
+		return "GRANT", stmt.On
+	case *influxql.GrantAdminStatement:
+		return "GRANT ADMIN", ""
+	case *influxql.RevokeStatement:
+		return "REVOKE", stmt.On
+	case *influxql.RevokeAdminStatement:
+		return "REVOKE ADMIN", ""
+	case *influxql.SetPasswordUserStatement:
+		return "SET PASSWORD", ""
+	case *query.GrantMeasurementStatement:
+		return "GRANT", stmt.On
+	case *query.RevokeMeasurementStatement:
+		return "REVOKE", stmt.On
+	case *query.SetUserTimeZoneStatement:
+		return "SET TIME ZONE", ""
+	case *query.SetUserWriteTargetStatement:
+		return "SET WRITE TARGET", stmt.Database
+	case *query.SetUserQueryPriorityStatement:
+		return "SET QUERY PRIORITY", ""
+	case *query.CreateRoleStatement:
+		return "CREATE ROLE", ""
+	case *query.DropRoleStatement:
+		return "DROP ROLE", ""
+	case *query.GrantRoleStatement:
+		return "GRANT ROLE", ""
+	case *query.RevokeRoleStatement:
+		return "REVOKE ROLE", ""
+	case *query.GrantRolePrivilegeStatement:
+		return "GRANT", stmt.On
+	case *query.RevokeRolePrivilegeStatement:
+		return "REVOKE", stmt.On
+	case *query.CreateTokenStatement:
+		return "CREATE TOKEN", ""
+	case *query.DropTokenStatement:
+		return "DROP TOKEN", ""
+	case *query.CreateShardGroupStatement:
+		return "CREATE SHARD GROUP", stmt.Database
+	case *query.TruncateShardGroupsStatement:
+		return "TRUNCATE SHARD GROUPS", stmt.Database
+	case *query.SetQuotaStatement:
+		return "SET QUOTA", stmt.Database
+	case *query.DropQuotaStatement:
+		return "DROP QUOTA", stmt.Database
+	case *query.RenameDatabaseStatement:
+		return "RENAME DATABASE", stmt.OldName
+	case *query.RenameMeasurementStatement:
+		return "RENAME MEASUREMENT", stmt.Database
+	default:
+		return "", ""
+	}
+}
+
+// invalidateQueryCache drops the cached results of the database that are
+// affected by a statement that removes data.
+func (e *StatementExecutor) invalidateQueryCache(stmt influxql.Statement, database string) {
+	switch stmt := stmt.(type) {
+	case *influxql.DeleteSeriesStatement, *influxql.DropMeasurementStatement, *influxql.DropSeriesStatement:
+		e.QueryCache.InvalidateDatabase(database)
+	case *influxql.DropDatabaseStatement:
+		e.QueryCache.InvalidateDatabase(stmt.Name)
+	case *influxql.DropRetentionPolicyStatement:
+		e.QueryCache.InvalidateDatabase(stmt.Database)
+	case *influxql.DropShardStatement:
+		// The database of the shard is not known so drop every result.
+		e.QueryCache.InvalidateDatabase("")
+	case *query.RenameDatabaseStatement:
+		e.QueryCache.InvalidateDatabase(stmt.OldName)
+	case *query.RenameMeasurementStatement:
+		if stmt.Database != "" {
+			database = stmt.Database
+		}
+		e.QueryCache.InvalidateDatabase(database)
+	default:
+	}
+}
+
 func (e *StatementExecutor) executeAlterRetentionPolicyStatement(stmt *influxql.AlterRetentionPolicyStatement) error {
 	rpu := &meta.RetentionPolicyUpdate{
 		Duration:           stmt.Duration,
@@ -413,10 +662,11 @@ func (e *StatementExecutor) executeDropUserStatement(q *influxql.DropUserStateme
 
 func (e *StatementExecutor) executeExplainStatement(ctx *query.ExecutionContext, q *influxql.ExplainStatement) (models.Rows, error) {
 	opt := query.SelectOptions{
-		NodeID:      ctx.ExecutionOptions.NodeID,
-		MaxSeriesN:  e.MaxSelectSeriesN,
-		MaxBucketsN: e.MaxSelectBucketsN,
-		Authorizer:  ctx.Authorizer,
+		NodeID:        ctx.ExecutionOptions.NodeID,
+		MaxSeriesN:    e.MaxSelectSeriesN,
+		MaxBucketsN:   e.MaxSelectBucketsN,
+		Authorizer:    ctx.Authorizer,
+		UserFunctions: e.UserFunctions,
 	}
 
 	// Prepare the query for execution, but do not actually execute it.
@@ -513,6 +763,10 @@ func (e *StatementExecutor) executeGrantStatement(stmt *influxql.GrantStatement)
 	return e.MetaClient.SetPrivilege(stmt.User, stmt.On, stmt.Privilege)
 }
 
+func (e *StatementExecutor) executeGrantMeasurementStatement(stmt *query.GrantMeasurementStatement) error {
+	return e.MetaClient.SetMeasurementPrivilege(stmt.User, measurementPrivilege(stmt.On, stmt.Measurement, stmt.Regex, stmt.Privilege))
+}
+
 func (e *StatementExecutor) executeGrantAdminStatement(stmt *influxql.GrantAdminStatement) error {
 	return e.MetaClient.SetAdminPrivilege(stmt.User, true)
 }
@@ -533,16 +787,234 @@ func (e *StatementExecutor) executeRevokeStatement(stmt *influxql.RevokeStatemen
 	return e.MetaClient.SetPrivilege(stmt.User, stmt.On, priv)
 }
 
+func (e *StatementExecutor) executeRevokeMeasurementStatement(stmt *query.RevokeMeasurementStatement) error {
+	mp := measurementPrivilege(stmt.On, stmt.Measurement, stmt.Regex, influxql.NoPrivileges)
+
+	// Revoking all privileges means there's no need to look at existing user privileges.
+	if stmt.Privilege != influxql.AllPrivileges {
+		privileges, err := e.MetaClient.UserMeasurementPrivileges(stmt.User)
+		if err != nil {
+			return err
+		}
+		for _, p := range privileges {
+			if p.SameMeasurements(&mp) {
+				// Bit clear (AND NOT) the user's privilege with the revoked privilege.
+				mp.Privilege = p.Privilege &^ stmt.Privilege
+				break
+			}
+		}
+	}
+
+	return e.MetaClient.SetMeasurementPrivilege(stmt.User, mp)
+}
+
+// measurementPrivilege returns the privilege on the measurements of a GRANT
+// or REVOKE statement.
+func measurementPrivilege(database, name string, re *influxql.RegexLiteral, p influxql.Privilege) meta.MeasurementPrivilege {
+	mp := meta.MeasurementPrivilege{Database: database, Name: name, Privilege: p}
+	if re != nil {
+		mp.Name, mp.Regex = "", re.Val
+	}
+	return mp
+}
+
 func (e *StatementExecutor) executeRevokeAdminStatement(stmt *influxql.RevokeAdminStatement) error {
 	return e.MetaClient.SetAdminPrivilege(stmt.User, false)
 }
 
+func (e *StatementExecutor) executeCreateRoleStatement(stmt *query.CreateRoleStatement) error {
+	return e.MetaClient.CreateRole(stmt.Name)
+}
+
+func (e *StatementExecutor) executeDropRoleStatement(stmt *query.DropRoleStatement) error {
+	return e.MetaClient.DropRole(stmt.Name)
+}
+
+func (e *StatementExecutor) executeGrantRoleStatement(stmt *query.GrantRoleStatement) error {
+	return e.MetaClient.AddUserToRole(stmt.User, stmt.Role)
+}
+
+func (e *StatementExecutor) executeRevokeRoleStatement(stmt *query.RevokeRoleStatement) error {
+	return e.MetaClient.RemoveUserFromRole(stmt.User, stmt.Role)
+}
+
+func (e *StatementExecutor) executeGrantRolePrivilegeStatement(stmt *query.GrantRolePrivilegeStatement) error {
+	return e.MetaClient.SetRolePrivilege(stmt.Role, stmt.On, stmt.Privilege)
+}
+
+func (e *StatementExecutor) executeRevokeRolePrivilegeStatement(stmt *query.RevokeRolePrivilegeStatement) error {
+	priv := influxql.NoPrivileges
+
+	// Revoking all privileges means there's no need to look at existing role privileges.
+	if stmt.Privilege != influxql.AllPrivileges {
+		privileges, err := e.MetaClient.RolePrivileges(stmt.Role)
+		if err != nil {
+			return err
+		}
+		// Bit clear (AND NOT) the role's privilege with the revoked privilege.
+		priv = privileges[stmt.On] &^ stmt.Privilege
+	}
+
+	return e.MetaClient.SetRolePrivilege(stmt.Role, stmt.On, priv)
+}
+
+func (e *StatementExecutor) executeCreateTokenStatement(stmt *query.CreateTokenStatement) (models.Rows, error) {
+	token, ti, err := e.MetaClient.CreateToken(stmt.User, stmt.Duration, stmt.Database, stmt.RetentionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	// This is the only time the token is returned.
+	return []*models.Row{{
+		Columns: []string{"id", "token", "expires"},
+		Values:  [][]interface{}{{ti.ID, token, formatTokenTime(ti.ExpiresAt)}},
+	}}, nil
+}
+
+func (e *StatementExecutor) executeDropTokenStatement(stmt *query.DropTokenStatement) error {
+	return e.MetaClient.DropToken(stmt.ID)
+}
+
+func (e *StatementExecutor) executeCreateShardGroupStatement(stmt *query.CreateShardGroupStatement) (models.Rows, error) {
+	di := e.MetaClient.Database(stmt.Database)
+	if di == nil {
+		return nil, influxdb.ErrDatabaseNotFound(stmt.Database)
+	}
+	rp := stmt.RetentionPolicy
+	if rp == "" {
+		if rp = di.DefaultRetentionPolicy; rp == "" {
+			return nil, fmt.Errorf("no default retention policy on database %s", stmt.Database)
+		}
+	}
+
+	t := stmt.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	sgi, err := e.MetaClient.CreateShardGroup(stmt.Database, rp, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*models.Row{{
+		Columns: []string{"id", "database", "retention_policy", "start_time", "end_time"},
+		Values: [][]interface{}{{
+			sgi.ID,
+			stmt.Database,
+			rp,
+			sgi.StartTime.UTC().Format(time.RFC3339),
+			sgi.EndTime.UTC().Format(time.RFC3339),
+		}},
+	}}, nil
+}
+
+func (e *StatementExecutor) executeTruncateShardGroupsStatement(stmt *query.TruncateShardGroupsStatement) error {
+	t := stmt.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	if stmt.Database == "" {
+		return e.MetaClient.TruncateShardGroups(t)
+	}
+	return e.MetaClient.TruncateDatabaseShardGroups(stmt.Database, stmt.RetentionPolicy, t)
+}
+
+// executeRenameDatabaseStatement renames the database in the store first, so
+// the writes that still go to the old name in the meantime are written to
+// the renamed shards. The store is renamed back if the meta data can't be
+// changed.
+func (e *StatementExecutor) executeRenameDatabaseStatement(stmt *query.RenameDatabaseStatement) error {
+	if !meta.ValidName(stmt.NewName) {
+		return meta.ErrInvalidName
+	} else if e.MetaClient.Database(stmt.OldName) == nil {
+		return influxdb.ErrDatabaseNotFound(stmt.OldName)
+	} else if e.MetaClient.Database(stmt.NewName) != nil {
+		return meta.ErrDatabaseExists
+	}
+
+	if err := e.TSDBStore.RenameDatabase(stmt.OldName, stmt.NewName); err != nil {
+		return err
+	}
+	if err := e.MetaClient.RenameDatabase(stmt.OldName, stmt.NewName); err != nil {
+		if rerr := e.TSDBStore.RenameDatabase(stmt.NewName, stmt.OldName); rerr != nil {
+			return fmt.Errorf("%s, and failed to rename the database back: %s", err, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeRenameMeasurementStatement(stmt *query.RenameMeasurementStatement, database string) error {
+	if stmt.Database != "" {
+		database = stmt.Database
+	}
+	if database == "" {
+		return ErrDatabaseNameRequired
+	} else if e.MetaClient.Database(database) == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
+
+	if err := e.TSDBStore.RenameMeasurement(database, stmt.OldName, stmt.NewName); err != nil {
+		return err
+	}
+	return e.MetaClient.RenameMeasurement(database, stmt.OldName, stmt.NewName)
+}
+
+func (e *StatementExecutor) executeSetQuotaStatement(stmt *query.SetQuotaStatement) error {
+	di := e.MetaClient.Database(stmt.Database)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(stmt.Database)
+	}
+
+	// Only change the limits that are set by the statement.
+	q := di.Quota
+	if stmt.MaxSeries != nil {
+		q.MaxSeries = *stmt.MaxSeries
+	}
+	if stmt.MaxMeasurements != nil {
+		q.MaxMeasurements = *stmt.MaxMeasurements
+	}
+	if stmt.MaxWriteRate != nil {
+		q.MaxWriteRate = *stmt.MaxWriteRate
+	}
+	if stmt.MaxConcurrentWrites != nil {
+		q.MaxConcurrentWrites = *stmt.MaxConcurrentWrites
+	}
+	return e.MetaClient.SetDatabaseQuota(stmt.Database, q)
+}
+
+func (e *StatementExecutor) executeDropQuotaStatement(stmt *query.DropQuotaStatement) error {
+	return e.MetaClient.SetDatabaseQuota(stmt.Database, meta.QuotaInfo{})
+}
+
 func (e *StatementExecutor) executeSetPasswordUserStatement(q *influxql.SetPasswordUserStatement) error {
 	return e.MetaClient.UpdateUser(q.Name, q.Password)
 }
 
+func (e *StatementExecutor) executeSetUserTimeZoneStatement(q *query.SetUserTimeZoneStatement) error {
+	var tz string
+	if q.Location != nil {
+		tz = q.Location.String()
+	}
+	return e.MetaClient.SetUserTimeZone(q.Name, tz)
+}
+
+func (e *StatementExecutor) executeSetUserWriteTargetStatement(q *query.SetUserWriteTargetStatement) error {
+	return e.MetaClient.SetUserWriteTarget(q.Name, q.Database, q.RetentionPolicy)
+}
+
+func (e *StatementExecutor) executeSetUserQueryPriorityStatement(q *query.SetUserQueryPriorityStatement) error {
+	return e.MetaClient.SetUserQueryPriority(q.Name, q.Priority)
+}
+
 func (e *StatementExecutor) executeSelectStatement(ctx *query.ExecutionContext, stmt *influxql.SelectStatement) error {
-	cur, err := e.createIterators(ctx, stmt, ctx.ExecutionOptions)
+	if err := e.resolveScalarSubqueries(ctx, stmt); err != nil {
+		return err
+	} else if err := e.normalizeAsofJoin(ctx, stmt); err != nil {
+		return err
+	}
+
+	cur, err := e.createCachedIterators(ctx, stmt)
 	if err != nil {
 		return err
 	}
@@ -560,6 +1032,12 @@ func (e *StatementExecutor) executeSelectStatement(ctx *query.ExecutionContext,
 		pointsWriter = NewBufferedPointsWriter(e.PointsWriter, stmt.Target.Measurement.Database, stmt.Target.Measurement.RetentionPolicy, 10000)
 	}
 
+	// A SHOW SERIES statement with a limit returns a continuation token
+	// for the last series key once a full page has been emitted.
+	paginated := stmt.Limit > 0 && isShowSeriesStatement(stmt)
+	var seriesN int
+	var lastKey string
+
 	for {
 		row, partial, err := em.Emit()
 		if err != nil {
@@ -584,6 +1062,11 @@ func (e *StatementExecutor) executeSelectStatement(ctx *query.ExecutionContext,
 			continue
 		}
 
+		if paginated && len(row.Values) > 0 {
+			seriesN += len(row.Values)
+			lastKey, _ = row.Values[len(row.Values)-1][0].(string)
+		}
+
 		result := &query.Result{
 			Series:  []*models.Row{row},
 			Partial: partial,
@@ -625,26 +1108,268 @@ func (e *StatementExecutor) executeSelectStatement(ctx *query.ExecutionContext,
 		})
 	}
 
+	if paginated && seriesN >= stmt.Limit && lastKey != "" {
+		return ctx.Send(&query.Result{
+			Next: query.EncodeContinuationToken(lastKey),
+		})
+	}
 	return nil
 }
 
-func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions) (query.Cursor, error) {
-	sopt := query.SelectOptions{
-		NodeID:      opt.NodeID,
-		MaxSeriesN:  e.MaxSelectSeriesN,
-		MaxPointN:   e.MaxSelectPointN,
-		MaxBucketsN: e.MaxSelectBucketsN,
-		Authorizer:  opt.Authorizer,
+// resolveScalarSubqueries executes the scalar subqueries within the
+// conditions of the statement and its subqueries and replaces them with the
+// value they return. Parser lowers the subqueries into scalar() calls.
+func (e *StatementExecutor) resolveScalarSubqueries(ctx *query.ExecutionContext, stmt *influxql.SelectStatement) (err error) {
+	influxql.WalkFunc(stmt, func(n influxql.Node) {
+		s, ok := n.(*influxql.SelectStatement)
+		if !ok || err != nil || s.Condition == nil {
+			return
+		}
+		s.Condition = influxql.RewriteExpr(s.Condition, func(expr influxql.Expr) influxql.Expr {
+			// A comparison with a subquery that returned no value is false.
+			if be, ok := expr.(*influxql.BinaryExpr); ok {
+				switch be.Op {
+				case influxql.EQ, influxql.NEQ, influxql.LT, influxql.LTE, influxql.GT, influxql.GTE:
+					if isNilLiteral(be.LHS) || isNilLiteral(be.RHS) {
+						return &influxql.BooleanLiteral{Val: false}
+					}
+				default:
+				}
+				return expr
+			}
+
+			call, ok := expr.(*influxql.Call)
+			if !ok || call.Name != "scalar" || len(call.Args) != 1 || err != nil {
+				return expr
+			}
+			lit, ok := call.Args[0].(*influxql.StringLiteral)
+			if !ok {
+				return expr
+			}
+
+			var value influxql.Expr
+			if value, err = e.executeScalarSubquery(ctx, lit.Val, stmt.Location); err != nil {
+				return expr
+			}
+			return value
+		})
+	})
+	return err
+}
+
+// normalizeAsofJoin sets the default database and retention policy of the
+// measurement of an ASOF JOIN and authorizes reading it. Parser lowers the
+// ASOF JOIN clause into an asof_join() call holding a statement that selects
+// the measurement.
+func (e *StatementExecutor) normalizeAsofJoin(ctx *query.ExecutionContext, stmt *influxql.SelectStatement) (err error) {
+	if stmt.Condition == nil {
+		return nil
+	}
+	stmt.Condition = influxql.RewriteExpr(stmt.Condition, func(expr influxql.Expr) influxql.Expr {
+		call, ok := expr.(*influxql.Call)
+		if !ok || call.Name != "asof_join" || len(call.Args) != 1 || err != nil {
+			return expr
+		}
+		lit, ok := call.Args[0].(*influxql.StringLiteral)
+		if !ok {
+			return expr
+		}
+
+		var join *influxql.SelectStatement
+		if s, perr := influxql.ParseStatement(lit.Val); perr != nil {
+			err = fmt.Errorf("invalid ASOF JOIN: %s", perr)
+			return expr
+		} else if join, ok = s.(*influxql.SelectStatement); !ok {
+			err = fmt.Errorf("invalid ASOF JOIN: %s", lit.Val)
+			return expr
+		} else if err = e.NormalizeStatement(join, ctx.Database, ctx.RetentionPolicy); err != nil {
+			return expr
+		}
+
+		// The measurement was not visible when the query was authorized.
+		if a := ctx.CoarseAuthorizer; a != nil {
+			for _, m := range influxql.Sources(join.Sources).Measurements() {
+				if !a.AuthorizeDatabase(influxql.ReadPrivilege, m.Database) {
+					err = fmt.Errorf("ASOF JOIN not authorized to read database %s", m.Database)
+					return expr
+				}
+			}
+		}
+		return &influxql.Call{
+			Name: call.Name,
+			Args: []influxql.Expr{&influxql.StringLiteral{Val: join.String()}},
+		}
+	})
+	return err
+}
+
+// isNilLiteral returns true if expr is a nil literal.
+func isNilLiteral(expr influxql.Expr) bool {
+	_, ok := expr.(*influxql.NilLiteral)
+	return ok
+}
+
+// executeScalarSubquery executes the SELECT statement of a scalar subquery
+// and returns the single value it returns as a literal. A nil literal is
+// returned if the statement returns no value.
+func (e *StatementExecutor) executeScalarSubquery(ctx *query.ExecutionContext, s string, loc *time.Location) (influxql.Expr, error) {
+	q, err := query.ParseQuery(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scalar subquery: %s", err)
+	}
+	var stmt *influxql.SelectStatement
+	if len(q.Statements) == 1 {
+		stmt, _ = q.Statements[0].(*influxql.SelectStatement)
+	}
+	if stmt == nil || stmt.Target != nil {
+		return nil, errors.New("scalar subquery must be a single SELECT statement without INTO")
+	} else if stmt.Location == nil {
+		stmt.Location = loc
+	}
+
+	// The subquery was not visible when the query was authorized.
+	if err := e.NormalizeStatement(stmt, ctx.Database, ctx.RetentionPolicy); err != nil {
+		return nil, err
+	}
+	if a := ctx.CoarseAuthorizer; a != nil {
+		for _, m := range influxql.Sources(stmt.Sources).Measurements() {
+			if !a.AuthorizeDatabase(influxql.ReadPrivilege, m.Database) {
+				return nil, fmt.Errorf("scalar subquery not authorized to read database %s", m.Database)
+			}
+		}
+	}
+	if err := e.resolveScalarSubqueries(ctx, stmt); err != nil {
+		return nil, err
+	}
+
+	cur, err := e.createIterators(ctx, stmt, ctx.ExecutionOptions)
+	if err != nil {
+		return nil, err
+	}
+	em := query.NewEmitter(cur, ctx.ChunkSize)
+	defer em.Close()
+
+	var value interface{}
+	var valueN int
+	for {
+		row, _, err := em.Emit()
+		if err != nil {
+			return nil, err
+		} else if row == nil {
+			break
+		}
+
+		// Every column but the time is a value.
+		for _, values := range row.Values {
+			for i, v := range values {
+				if i == 0 && !stmt.OmitTime {
+					continue
+				}
+				value = v
+				valueN++
+			}
+		}
+		if valueN > 1 {
+			return nil, errors.New("scalar subquery returned more than one value")
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return &influxql.NumberLiteral{Val: v}, nil
+	case int64:
+		return &influxql.IntegerLiteral{Val: v}, nil
+	case uint64:
+		return &influxql.UnsignedLiteral{Val: v}, nil
+	case string:
+		return &influxql.StringLiteral{Val: v}, nil
+	case bool:
+		return &influxql.BooleanLiteral{Val: v}, nil
+	case nil:
+		return &influxql.NilLiteral{}, nil
+	default:
+		return nil, fmt.Errorf("scalar subquery returned unsupported value: %v", v)
+	}
+}
+
+// isShowSeriesStatement returns true if the statement is a SHOW SERIES
+// statement that was rewritten to read the series of the index.
+func isShowSeriesStatement(stmt *influxql.SelectStatement) bool {
+	if len(stmt.Sources) == 0 {
+		return false
+	}
+	for _, src := range stmt.Sources {
+		if mm, ok := src.(*influxql.Measurement); !ok || mm.SystemIterator != "_series" {
+			return false
+		}
 	}
+	return true
+}
 
+func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions) (query.Cursor, error) {
 	// Create a set of iterators from a selection.
-	cur, err := query.Select(ctx, stmt, e.ShardMapper, sopt)
+	cur, err := query.Select(ctx, stmt, e.ShardMapper, e.selectOptions(opt))
 	if err != nil {
 		return nil, err
 	}
 	return cur, nil
 }
 
+// createCachedIterators creates the cursor for the statement the same way as
+// createIterators but answers the statement from the query cache if it can.
+// If the result is not cached, the returned cursor adds it to the cache once
+// it has been read.
+func (e *StatementExecutor) createCachedIterators(ctx *query.ExecutionContext, stmt *influxql.SelectStatement) (query.Cursor, error) {
+	// Results that depend on the user cannot be shared.
+	if e.QueryCache == nil || stmt.Target != nil || !query.AuthorizerIsOpen(ctx.ExecutionOptions.Authorizer) {
+		return e.createIterators(ctx, stmt, ctx.ExecutionOptions)
+	}
+	sources := queryCacheSources(stmt.Sources)
+	if len(sources) == 0 {
+		return e.createIterators(ctx, stmt, ctx.ExecutionOptions)
+	}
+	for _, src := range sources {
+		if !e.QueryCache.Enabled(src.Database) {
+			return e.createIterators(ctx, stmt, ctx.ExecutionOptions)
+		}
+	}
+
+	// Prepare the statement to find the time range it reads.
+	mapper := &timeRangeShardMapper{ShardMapper: e.ShardMapper}
+	s, err := query.Prepare(stmt, mapper, e.selectOptions(ctx.ExecutionOptions))
+	if err != nil {
+		return nil, err
+	}
+	// Must be deferred so it runs after Select.
+	defer s.Close()
+
+	now := time.Now()
+	key := queryCacheKey(stmt, mapper.timeRange)
+	if entry := e.QueryCache.get(key, now); entry != nil {
+		return &cachedCursor{entry: entry}, nil
+	}
+
+	entry := e.QueryCache.begin(key, sources, mapper.timeRange, now)
+	cur, err := s.Select(ctx)
+	if err != nil {
+		e.QueryCache.abort(entry)
+		return nil, err
+	}
+	entry.columns = cur.Columns()
+	return &recordingCursor{Cursor: cur, ctx: ctx, cache: e.QueryCache, entry: entry}, nil
+}
+
+func (e *StatementExecutor) selectOptions(opt query.ExecutionOptions) query.SelectOptions {
+	return query.SelectOptions{
+		NodeID:        opt.NodeID,
+		MaxSeriesN:    e.MaxSelectSeriesN,
+		MaxPointN:     e.MaxSelectPointN,
+		MaxBucketsN:   e.MaxSelectBucketsN,
+		Authorizer:    opt.Authorizer,
+		UserFunctions: e.UserFunctions,
+	}
+}
+
 func (e *StatementExecutor) executeShowContinuousQueriesStatement(ctx *query.ExecutionContext, stmt *influxql.ShowContinuousQueriesStatement) (models.Rows, error) {
 	dis := e.MetaClient.Databases()
 	a := ctx.ExecutionOptions.CoarseAuthorizer
@@ -711,6 +1436,45 @@ func (e *StatementExecutor) executeShowGrantsForUserStatement(q *influxql.ShowGr
 		return nil, err
 	}
 
+	row := &models.Row{Columns: []string{"database", "privilege"}}
+	for d, p := range priv {
+		row.Values = append(row.Values, []interface{}{d, p.String()})
+	}
+	rows := []*models.Row{row}
+
+	// The privileges on measurements are only listed if the user has any.
+	mps, err := e.MetaClient.UserMeasurementPrivileges(q.Name)
+	if err != nil {
+		return nil, err
+	} else if len(mps) > 0 {
+		row := &models.Row{Columns: []string{"database", "measurement", "privilege"}}
+		for _, mp := range mps {
+			row.Values = append(row.Values, []interface{}{mp.Database, mp.Measurement(), mp.Privilege.String()})
+		}
+		rows = append(rows, row)
+	}
+
+	// The privileges above include the ones of the roles of the user, which
+	// are only listed if the user is a member of any.
+	roles, err := e.MetaClient.UserRoles(q.Name)
+	if err != nil {
+		return nil, err
+	} else if len(roles) > 0 {
+		row := &models.Row{Columns: []string{"role"}}
+		for _, role := range roles {
+			row.Values = append(row.Values, []interface{}{role})
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (e *StatementExecutor) executeShowGrantsForRoleStatement(q *query.ShowGrantsForRoleStatement) (models.Rows, error) {
+	priv, err := e.MetaClient.RolePrivileges(q.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	row := &models.Row{Columns: []string{"database", "privilege"}}
 	for d, p := range priv {
 		row.Values = append(row.Values, []interface{}{d, p.String()})
@@ -917,12 +1681,25 @@ func (e *StatementExecutor) executeShowSeriesCardinalityStatement(ctx *query.Exe
 	}}, nil
 }
 
-func (e *StatementExecutor) executeShowShardGroupsStatement(stmt *influxql.ShowShardGroupsStatement) (models.Rows, error) {
+func (e *StatementExecutor) executeShowShardGroupsStatement(stmt *query.ShowShardGroupsStatement) (models.Rows, error) {
 	dis := e.MetaClient.Databases()
+	if stmt.Database != "" {
+		di := e.MetaClient.Database(stmt.Database)
+		if di == nil {
+			return nil, influxdb.ErrDatabaseNotFound(stmt.Database)
+		} else if stmt.RetentionPolicy != "" && di.RetentionPolicy(stmt.RetentionPolicy) == nil {
+			return nil, influxdb.ErrRetentionPolicyNotFound(stmt.RetentionPolicy)
+		}
+		dis = []meta.DatabaseInfo{*di}
+	}
 
-	row := &models.Row{Columns: []string{"id", "database", "retention_policy", "start_time", "end_time", "expiry_time"}, Name: "shard groups"}
+	row := &models.Row{Columns: []string{"id", "database", "retention_policy", "start_time", "end_time", "expiry_time", "truncated_time"}, Name: "shard groups"}
 	for _, di := range dis {
 		for _, rpi := range di.RetentionPolicies {
+			if stmt.RetentionPolicy != "" && rpi.Name != stmt.RetentionPolicy {
+				continue
+			}
+
 			for _, sgi := range rpi.ShardGroups {
 				// Shards associated with deleted shard groups are effectively deleted.
 				// Don't list them.
@@ -930,6 +1707,11 @@ func (e *StatementExecutor) executeShowShardGroupsStatement(stmt *influxql.ShowS
 					continue
 				}
 
+				var truncatedTime string
+				if sgi.Truncated() {
+					truncatedTime = sgi.TruncatedAt.UTC().Format(time.RFC3339)
+				}
+
 				row.Values = append(row.Values, []interface{}{
 					sgi.ID,
 					di.Name,
@@ -937,6 +1719,7 @@ func (e *StatementExecutor) executeShowShardGroupsStatement(stmt *influxql.ShowS
 					sgi.StartTime.UTC().Format(time.RFC3339),
 					sgi.EndTime.UTC().Format(time.RFC3339),
 					sgi.EndTime.Add(rpi.Duration).UTC().Format(time.RFC3339),
+					truncatedTime,
 				})
 			}
 		}
@@ -1015,8 +1798,12 @@ func (e *StatementExecutor) executeShowTagKeys(ctx *query.ExecutionContext, q *i
 
 	// Determine appropriate time range. If one or fewer time boundaries provided
 	// then min/max possible time should be used instead.
+	after, cond, err := query.SplitAfter(q.Condition, 2)
+	if err != nil {
+		return err
+	}
 	valuer := &influxql.NowValuer{Now: time.Now()}
-	cond, timeRange, err := influxql.ConditionExpr(q.Condition, valuer)
+	cond, timeRange, err := influxql.ConditionExpr(cond, valuer)
 	if err != nil {
 		return err
 	}
@@ -1049,6 +1836,15 @@ func (e *StatementExecutor) executeShowTagKeys(ctx *query.ExecutionContext, q *i
 	for _, m := range tagKeys {
 		keys := m.Keys
 
+		// Skip the keys up to the position of the continuation token.
+		if after != nil {
+			if m.Measurement < after[0] {
+				continue
+			} else if m.Measurement == after[0] {
+				keys = keys[sort.SearchStrings(keys, after[1]+"\x00"):]
+			}
+		}
+
 		if q.Offset > 0 {
 			if q.Offset >= len(keys) {
 				keys = nil
@@ -1056,8 +1852,13 @@ func (e *StatementExecutor) executeShowTagKeys(ctx *query.ExecutionContext, q *i
 				keys = keys[q.Offset:]
 			}
 		}
+
+		// The page ends at the first measurement the limit cuts short so
+		// the continuation token resumes without skipping any keys.
+		var next string
 		if q.Limit > 0 && q.Limit < len(keys) {
 			keys = keys[:q.Limit]
+			next = query.EncodeContinuationToken(m.Measurement, keys[len(keys)-1])
 		}
 
 		if len(keys) == 0 {
@@ -1075,10 +1876,15 @@ func (e *StatementExecutor) executeShowTagKeys(ctx *query.ExecutionContext, q *i
 
 		if err := ctx.Send(&query.Result{
 			Series: []*models.Row{row},
+			Next:   next,
 		}); err != nil {
 			return err
 		}
 		emitted = true
+
+		if next != "" {
+			break
+		}
 	}
 
 	// Ensure at least one result is emitted.
@@ -1197,6 +2003,68 @@ func (e *StatementExecutor) executeShowTagValues(ctx *query.ExecutionContext, q
 	return nil
 }
 
+func (e *StatementExecutor) executeShowQuotasStatement(q *query.ShowQuotasStatement) (models.Rows, error) {
+	row := &models.Row{Columns: []string{"database", "max_series", "max_measurements", "max_write_rate", "max_concurrent_writes"}}
+	for _, di := range e.MetaClient.Databases() {
+		if di.Quota.IsZero() {
+			continue
+		}
+		row.Values = append(row.Values, []interface{}{di.Name, di.Quota.MaxSeries, di.Quota.MaxMeasurements, di.Quota.MaxWriteRate, di.Quota.MaxConcurrentWrites})
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeShowRolesStatement(q *query.ShowRolesStatement) (models.Rows, error) {
+	members := make(map[string][]string)
+	for _, ui := range e.MetaClient.Users() {
+		for _, role := range ui.Roles {
+			members[role] = append(members[role], ui.Name)
+		}
+	}
+
+	row := &models.Row{Columns: []string{"role", "users"}}
+	for _, ri := range e.MetaClient.Roles() {
+		row.Values = append(row.Values, []interface{}{ri.Name, strings.Join(members[ri.Name], ",")})
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeShowTokensStatement(q *query.ShowTokensStatement) (models.Rows, error) {
+	row := &models.Row{Columns: []string{"id", "user", "created", "expires", "last_used", "write_target"}}
+	for _, ti := range e.MetaClient.Tokens() {
+		if q.User != "" && ti.User != q.User {
+			continue
+		}
+		row.Values = append(row.Values, []interface{}{
+			ti.ID,
+			ti.User,
+			formatTokenTime(ti.CreatedAt),
+			formatTokenTime(ti.ExpiresAt),
+			formatTokenTime(ti.LastUsedAt),
+			formatWriteTarget(ti.WriteDatabase, ti.WriteRetentionPolicy),
+		})
+	}
+	return []*models.Row{row}, nil
+}
+
+// formatTokenTime formats a time of a token. Unset times, like the expiry of
+// a token that doesn't expire, are empty.
+func formatTokenTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatWriteTarget formats a write target in the db[.rp] form. It is empty
+// if there is no write target.
+func formatWriteTarget(database, rp string) string {
+	if rp == "" {
+		return database
+	}
+	return database + "." + rp
+}
+
 func (e *StatementExecutor) executeShowUsersStatement(q *influxql.ShowUsersStatement) (models.Rows, error) {
 	row := &models.Row{Columns: []string{"user", "admin"}}
 	for _, ui := range e.MetaClient.Users() {
@@ -1212,6 +2080,9 @@ type BufferedPointsWriter struct {
 	buf             []models.Point
 	database        string
 	retentionPolicy string
+
+	// User is the user the buffered points are written as, if any.
+	User meta.User
 }
 
 // NewBufferedPointsWriter returns a new BufferedPointsWriter.
@@ -1268,6 +2139,7 @@ func (w *BufferedPointsWriter) Flush() error {
 		Database:        w.database,
 		RetentionPolicy: w.retentionPolicy,
 		Points:          w.buf,
+		User:            w.User,
 	}); err != nil {
 		return err
 	}
@@ -1453,6 +2325,10 @@ type IntoWriteRequest struct {
 	Database        string
 	RetentionPolicy string
 	Points          []models.Point
+
+	// User is the user the points are written as. The points are written
+	// privileged if it is nil.
+	User meta.User
 }
 
 // TSDBStore is an interface for accessing the time series data store.
@@ -1469,6 +2345,9 @@ type TSDBStore interface {
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
 	DeleteShard(id uint64) error
 
+	RenameDatabase(oldName, newName string) error
+	RenameMeasurement(database, oldName, newName string) error
+
 	MeasurementNames(ctx context.Context, auth query.FineAuthorizer, database string, retentionPolicy string, cond influxql.Expr) ([][]byte, error)
 	TagKeys(ctx context.Context, auth query.FineAuthorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
 	TagValues(ctx context.Context, auth query.FineAuthorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
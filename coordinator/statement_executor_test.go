@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/audit"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
@@ -130,6 +131,80 @@ func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	}
 }
 
+// Ensure query executor answers repeated statements from the query cache
+// until points are written into the time range of the statement.
+func TestQueryExecutor_ExecuteQuery_QueryCache(t *testing.T) {
+	e := DefaultQueryExecutor()
+	config := coordinator.NewConfig()
+	config.QueryCacheDatabases = []string{"db0"}
+	cache := coordinator.NewQueryCache(config)
+	e.StatementExecutor.QueryCache = cache
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	var n int
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			n++
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(0 * time.Second), Aux: []interface{}{float64(100)}},
+				{Name: "cpu", Time: int64(1 * time.Second), Aux: []interface{}{float64(200)}},
+			}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "cpu",
+				Columns: []string{"time", "value"},
+				Values: [][]interface{}{
+					{time.Unix(0, 0).UTC(), float64(100)},
+					{time.Unix(1, 0).UTC(), float64(200)},
+				},
+			}},
+		},
+	}
+	q := `SELECT * FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-01T00:01:00Z'`
+	for i, want := range []int{1, 1, 2} {
+		// Write a point into the time range before the last query.
+		if i == 2 {
+			cache.Invalidate("db0", "rp0", int64(30*time.Second), int64(30*time.Second))
+		}
+
+		if a := ReadAllResults(e.ExecuteQuery(q, "db0", 0)); !reflect.DeepEqual(a, exp) {
+			t.Fatalf("%d. unexpected results: %s", i, spew.Sdump(a))
+		} else if n != want {
+			t.Fatalf("%d. unexpected number of iterators: got=%d want=%d", i, n, want)
+		}
+	}
+
+	// Writes outside of the time range or into other databases keep the result.
+	cache.Invalidate("db0", "rp0", int64(time.Hour), int64(time.Hour))
+	cache.Invalidate("db1", "rp0", 0, 0)
+	ReadAllResults(e.ExecuteQuery(q, "db0", 0))
+	if n != 2 {
+		t.Fatalf("unexpected number of iterators: got=%d want=2", n)
+	}
+
+	stats := cache.Statistics(nil)[0].Values
+	if stats["hits"] != int64(2) || stats["misses"] != int64(2) || stats["invalidations"] != int64(1) || stats["entries"] != int64(1) {
+		t.Fatalf("unexpected statistics: %v", stats)
+	}
+}
+
 func TestStatementExecutor_ExecuteQuery_WriteInto(t *testing.T) {
 	for _, tt := range []struct {
 		name    string
@@ -514,6 +589,49 @@ func TestQueryExecutor_ExecuteQuery_ShowContinuousQueries(t *testing.T) {
 	}
 }
 
+type auditLog struct {
+	events []audit.Event
+}
+
+func (l *auditLog) Record(e audit.Event) {
+	l.events = append(l.events, e)
+}
+
+// Ensure the statements that change the meta data are recorded in the audit log.
+func TestStatementExecutor_AuditLog(t *testing.T) {
+	e := DefaultQueryExecutor()
+	var l auditLog
+	e.StatementExecutor.AuditLog = &l
+
+	e.TSDBStore.DeleteDatabaseFn = func(name string) error { return nil }
+	e.MetaClient.DropDatabaseFn = func(name string) error {
+		if name != DefaultDatabase {
+			return errors.New("database not found")
+		}
+		return nil
+	}
+	e.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: name}
+	}
+	e.MetaClient.DatabasesFn = func() []meta.DatabaseInfo { return nil }
+
+	opt := query.ExecutionOptions{User: "admin", Source: "127.0.0.1:5000"}
+	for _, s := range []string{"DROP DATABASE db0", "DROP DATABASE db1", "SHOW DATABASES"} {
+		ReadAllResults(e.Executor.ExecuteQuery(MustParseQuery(s), opt, make(chan struct{})))
+	}
+
+	exp := []audit.Event{{
+		User:      "admin",
+		Source:    "127.0.0.1:5000",
+		Action:    "DROP DATABASE",
+		Database:  "db0",
+		Statement: "DROP DATABASE db0",
+	}}
+	if !reflect.DeepEqual(l.events, exp) {
+		t.Fatalf("unexpected events: exp %s, got %s", spew.Sdump(exp), spew.Sdump(l.events))
+	}
+}
+
 // QueryExecutor is a test wrapper for coordinator.QueryExecutor.
 type QueryExecutor struct {
 	*query.Executor
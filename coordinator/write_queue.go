@@ -0,0 +1,240 @@
+package coordinator
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// The keys for statistics generated by the "writeQueue" module.
+const (
+	statWriteQueueBytes         = "queueBytes"    // Size of the writes that are not applied yet
+	statWriteQueueReq           = "writeReq"      // Number of writes acknowledged once queued
+	statWriteQueueFull          = "queueFull"     // Number of writes applied right away because the queue was full
+	statWriteQueueOK            = "writeOk"       // Number of queued writes that were applied
+	statWriteQueueErr           = "writeError"    // Number of failures to apply a queued write that are retried
+	statWriteQueueDropped       = "writeDropped"  // Number of queued writes dropped because they can't be applied
+	statWriteQueuePointsApplied = "pointsApplied" // Number of points of the queued writes that were applied
+	statWriteQueueLagNs         = "lagNs"         // Age of the oldest write that is not applied yet
+)
+
+// writeQueueRetryInterval is the time to wait before applying a queued write
+// again after a failure that may be temporary.
+const writeQueueRetryInterval = time.Second
+
+// WriteQueue acknowledges the writes once they are durably queued on disk and
+// applies them in the background, in order. It smooths the latency spikes of
+// the senders that don't need to know that their points are written to the
+// shards. The queued writes survive a restart and are applied once the
+// server is back.
+type WriteQueue struct {
+	Logger *zap.Logger
+
+	PointsWriter interface {
+		WritePointsPrivileged(writeCtx tsdb.WriteContext, database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+	}
+
+	dir     string
+	maxSize int64
+	queue   *replicationQueue
+
+	wg      sync.WaitGroup
+	closing chan struct{}
+
+	// oldest is the time the oldest queued write was queued at, or zero if
+	// all the writes are applied.
+	oldest int64
+
+	stats writeQueueStatistics
+}
+
+type writeQueueStatistics struct {
+	WriteReq      int64
+	QueueFull     int64
+	WriteOK       int64
+	WriteErr      int64
+	WriteDropped  int64
+	PointsApplied int64
+}
+
+// NewWriteQueue returns a new instance of WriteQueue for the write queue
+// configuration of c.
+func NewWriteQueue(c Config) *WriteQueue {
+	return &WriteQueue{
+		Logger:  zap.NewNop(),
+		dir:     c.WriteQueueDir,
+		maxSize: int64(c.WriteQueueMaxSize),
+	}
+}
+
+// WithLogger sets the Logger on q.
+func (q *WriteQueue) WithLogger(log *zap.Logger) {
+	q.Logger = log.With(zap.String("service", "write-queue"))
+}
+
+// Open opens the queue and starts applying its writes, including the ones
+// queued before a restart.
+func (q *WriteQueue) Open() error {
+	if q.closing != nil {
+		return nil
+	}
+
+	maxSize := q.maxSize
+	if maxSize == 0 {
+		maxSize = DefaultWriteQueueMaxSize
+	}
+	queue, err := openReplicationQueue(q.dir, maxSize)
+	if err != nil {
+		return err
+	}
+	q.queue = queue
+	q.Logger.Info("Starting write queue", zap.String("path", q.dir), zap.Int64("queued_bytes", queue.Size()))
+
+	q.closing = make(chan struct{})
+	q.wg.Add(1)
+	go q.run()
+	return nil
+}
+
+// Close stops applying the writes and closes the queue. The queued writes are
+// applied when the queue is opened again.
+func (q *WriteQueue) Close() error {
+	if q.closing == nil {
+		return nil
+	}
+	close(q.closing)
+	q.wg.Wait()
+	q.closing = nil
+	return q.queue.Close()
+}
+
+// Enqueue queues a write of a user to apply it later. The write is applied
+// right away if the queue is full.
+func (q *WriteQueue) Enqueue(writeCtx tsdb.WriteContext, database, retentionPolicy string, points []models.Point) error {
+	now := time.Now().UnixNano()
+	rec := replicationRecord{Time: now, Database: database, RetentionPolicy: retentionPolicy, User: writeCtx.UserId, N: len(points)}
+	for _, p := range points {
+		rec.Data = p.AppendString(rec.Data)
+		rec.Data = append(rec.Data, '\n')
+	}
+
+	if err := q.queue.Append(&rec); err == ErrReplicationQueueFull {
+		atomic.AddInt64(&q.stats.QueueFull, 1)
+		writeCtx.Ack = tsdb.WriteAckApplied
+		return q.PointsWriter.WritePointsPrivileged(writeCtx, database, retentionPolicy, models.ConsistencyLevelOne, points)
+	} else if err != nil {
+		return err
+	}
+	atomic.AddInt64(&q.stats.WriteReq, 1)
+	atomic.CompareAndSwapInt64(&q.oldest, 0, now)
+	return nil
+}
+
+// run applies the queued writes until the queue is closed.
+func (q *WriteQueue) run() {
+	defer q.wg.Done()
+
+	for {
+		// The writes are applied one at a time, as they were received.
+		recs, pos, err := q.queue.Peek(1)
+		if err != nil {
+			q.Logger.Info("Failed to read the queued writes", zap.Error(err))
+			if !wait(q.closing, writeQueueRetryInterval) {
+				return
+			}
+			continue
+		} else if len(recs) == 0 {
+			atomic.StoreInt64(&q.oldest, 0)
+			select {
+			case <-q.closing:
+				return
+			case <-q.queue.notify:
+			}
+			continue
+		}
+		atomic.StoreInt64(&q.oldest, recs[0].Time)
+
+		if err := q.apply(&recs[0]); err != nil {
+			atomic.AddInt64(&q.stats.WriteErr, 1)
+			q.Logger.Info("Failed to apply a queued write", logger.Database(recs[0].Database), zap.Error(err))
+			if !wait(q.closing, writeQueueRetryInterval) {
+				return
+			}
+			continue
+		}
+
+		if err := q.queue.Advance(pos); err != nil {
+			q.Logger.Info("Failed to save the position of the applied writes", zap.Error(err))
+		}
+	}
+}
+
+// apply writes the points of a queued write. It returns an error if the
+// write may succeed later, like a write that timed out or exceeded the write
+// rate of its database. The writes that can't succeed are dropped.
+func (q *WriteQueue) apply(rec *replicationRecord) error {
+	points, err := models.ParsePointsWithPrecision(rec.Data, time.Now().UTC(), "n")
+	if err != nil {
+		atomic.AddInt64(&q.stats.WriteDropped, 1)
+		q.Logger.Warn("Dropping a queued write that can't be parsed", logger.Database(rec.Database), zap.Error(err))
+		return nil
+	}
+
+	writeCtx := tsdb.WriteContext{UserId: rec.User}
+	err = q.PointsWriter.WritePointsPrivileged(writeCtx, rec.Database, rec.RetentionPolicy, models.ConsistencyLevelOne, points)
+
+	var (
+		perr tsdb.PartialWriteError
+		qerr *tsdb.QuotaExceededError
+	)
+	switch {
+	case err == nil:
+	case err == ErrTimeout || err == ErrWriteFailed || (errors.As(err, &qerr) && qerr.RetryAfter > 0):
+		return err
+	case errors.As(err, &perr):
+		// Writing the dropped points again would drop them again.
+		q.Logger.Info("Dropped points of a queued write", logger.Database(rec.Database), zap.Error(err))
+		atomic.AddInt64(&q.stats.PointsApplied, int64(len(points)-perr.Dropped))
+		atomic.AddInt64(&q.stats.WriteOK, 1)
+		return nil
+	default:
+		atomic.AddInt64(&q.stats.WriteDropped, 1)
+		q.Logger.Warn("Dropping a queued write", logger.Database(rec.Database), zap.Error(err))
+		return nil
+	}
+	atomic.AddInt64(&q.stats.PointsApplied, int64(len(points)))
+	atomic.AddInt64(&q.stats.WriteOK, 1)
+	return nil
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (q *WriteQueue) Statistics(tags map[string]string) []models.Statistic {
+	if q.queue == nil {
+		return nil
+	}
+
+	var lag int64
+	if oldest, now := atomic.LoadInt64(&q.oldest), time.Now().UnixNano(); oldest > 0 && now > oldest {
+		lag = now - oldest
+	}
+	return []models.Statistic{{
+		Name: "writeQueue",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statWriteQueueBytes:         q.queue.Size(),
+			statWriteQueueReq:           atomic.LoadInt64(&q.stats.WriteReq),
+			statWriteQueueFull:          atomic.LoadInt64(&q.stats.QueueFull),
+			statWriteQueueOK:            atomic.LoadInt64(&q.stats.WriteOK),
+			statWriteQueueErr:           atomic.LoadInt64(&q.stats.WriteErr),
+			statWriteQueueDropped:       atomic.LoadInt64(&q.stats.WriteDropped),
+			statWriteQueuePointsApplied: atomic.LoadInt64(&q.stats.PointsApplied),
+			statWriteQueueLagNs:         lag,
+		},
+	}}
+}
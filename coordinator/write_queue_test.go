@@ -0,0 +1,151 @@
+package coordinator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// queuedWrite is a write applied by a write queue.
+type queuedWrite struct {
+	user     string
+	database string
+	points   []models.Point
+}
+
+type fakeQueuePointsWriter struct {
+	WritePointsPrivilegedFn func(writeCtx tsdb.WriteContext, database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+}
+
+func (w *fakeQueuePointsWriter) WritePointsPrivileged(writeCtx tsdb.WriteContext, database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+	return w.WritePointsPrivilegedFn(writeCtx, database, retentionPolicy, consistencyLevel, points)
+}
+
+// Ensure a write acknowledged once queued is applied in the background by
+// the points writer, as the user that wrote it.
+func TestPointsWriter_WritePoints_Queued(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: database, DefaultRetentionPolicy: "myrp"}
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	release := make(chan struct{})
+	writes := make(chan tsdb.WriteContext, 1)
+	store := &fakeStore{
+		WriteFn: func(writeCtx tsdb.WriteContext, shardID uint64, points []models.Point) error {
+			<-release
+			writes <- writeCtx
+			return nil
+		},
+	}
+
+	pw := coordinator.NewPointsWriter()
+	pw.MetaClient = ms
+	pw.TSDBStore = store
+	pw.Subscriber = Subscriber{SendFn: func(*coordinator.WritePointsRequest) {}}
+	pw.Node = &influxdb.Node{ID: 1}
+	pw.Open()
+	defer pw.Close()
+
+	c := coordinator.NewConfig()
+	c.WriteQueueDir = t.TempDir()
+	q := coordinator.NewWriteQueue(c)
+	q.PointsWriter = pw
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	pw.WriteQueue = q
+
+	// The write returns while the store is blocked.
+	points := []models.Point{models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0}, time.Now())}
+	if err := pw.WritePointsWithContext(tsdb.WriteContext{Ack: tsdb.WriteAckQueued}, "mydb", "myrp", models.ConsistencyLevelOne, nil, points); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+
+	select {
+	case writeCtx := <-writes:
+		if writeCtx.UserId != tsdb.UnknownUser {
+			t.Fatalf("unexpected user: %q", writeCtx.UserId)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the queued write")
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		stats := q.Statistics(nil)
+		if len(stats) != 1 {
+			t.Fatalf("unexpected statistics: %v", stats)
+		}
+		st := stats[0]
+		if st.Values["writeOk"].(int64) < 1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("unexpected statistics: %v", st)
+			}
+			continue
+		}
+
+		if st.Name != "writeQueue" || st.Values["writeReq"].(int64) != 1 ||
+			st.Values["pointsApplied"].(int64) != 1 || st.Values["queueBytes"].(int64) != 0 {
+			t.Fatalf("unexpected statistics: %v", st)
+		}
+		break
+	}
+}
+
+// Ensure the writes that are not applied yet are applied in order once the
+// write queue is opened again.
+func TestWriteQueue_Restart(t *testing.T) {
+	c := coordinator.NewConfig()
+	c.WriteQueueDir = t.TempDir()
+
+	q := coordinator.NewWriteQueue(c)
+	q.PointsWriter = &fakeQueuePointsWriter{
+		WritePointsPrivilegedFn: func(tsdb.WriteContext, string, string, models.ConsistencyLevel, []models.Point) error {
+			return coordinator.ErrTimeout
+		},
+	}
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for i, user := range []string{"alice", "bob"} {
+		points := []models.Point{models.MustNewPoint("cpu", nil, models.Fields{"value": float64(i)}, time.Unix(0, int64(i)))}
+		if err := q.Enqueue(tsdb.WriteContext{UserId: user}, "db0", "", points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := make(chan queuedWrite, 2)
+	q = coordinator.NewWriteQueue(c)
+	q.PointsWriter = &fakeQueuePointsWriter{
+		WritePointsPrivilegedFn: func(writeCtx tsdb.WriteContext, database, _ string, _ models.ConsistencyLevel, points []models.Point) error {
+			applied <- queuedWrite{user: writeCtx.UserId, database: database, points: points}
+			return nil
+		},
+	}
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	for i, user := range []string{"alice", "bob"} {
+		select {
+		case w := <-applied:
+			if w.user != user || w.database != "db0" || len(w.points) != 1 || w.points[0].UnixNano() != int64(i) {
+				t.Fatalf("unexpected write: %+v", w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the queued writes")
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/flux/values"
 	"github.com/influxdata/influxdb/coordinator"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxql"
 )
 
@@ -229,6 +230,12 @@ func NewToTransformation(ctx context.Context, d execute.Dataset, cache execute.T
 			return toSpec.Spec.TagColumns[i] == column.Label
 		}
 	}
+	buf := coordinator.NewBufferedPointsWriter(deps.PointsWriter, db, rp, DefaultBufferSize)
+	if deps.AuthEnabled {
+		// The points are written as the user of the query, so its privileges
+		// on measurements apply as they do to the writes of /write.
+		buf.User = meta.UserFromContext(ctx)
+	}
 	return &ToTransformation{
 		Ctx:         ctx,
 		DB:          db,
@@ -239,7 +246,7 @@ func NewToTransformation(ctx context.Context, d execute.Dataset, cache execute.T
 		d:           d,
 		cache:       cache,
 		deps:        deps,
-		buf:         coordinator.NewBufferedPointsWriter(deps.PointsWriter, db, rp, DefaultBufferSize),
+		buf:         buf,
 	}, nil
 }
 
@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/flux/stdlib/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -760,10 +761,84 @@ m,tag1=c _value=4 41`),
 	}
 }
 
+// Ensure the points of to() are written as the user of the query when
+// authentication is enabled.
+func TestTo_Process_User(t *testing.T) {
+	spec := &influxdb.ToProcedureSpec{
+		Spec: &influxdb.ToOpSpec{
+			Bucket:            "my_db",
+			TimeColumn:        "_time",
+			MeasurementColumn: "_measurement",
+		},
+	}
+	user := &meta.UserInfo{Name: "flux"}
+	pw := &mockPointsWriter{db: "my_db", rp: "autogen"}
+	deps := influxdb.StorageDependencies{
+		MetaClient: new(mockMetaClient),
+		Authorizer: &mockAuthorizer{
+			AuthorizeDatabaseFn: func(u meta.User, priv influxql.Privilege, database string) error {
+				if u != user || priv != influxql.WritePrivilege || database != "my_db" {
+					return fmt.Errorf("unexpected authorization of %v for %v on %s", u, priv, database)
+				}
+				return nil
+			},
+		},
+		AuthEnabled:  true,
+		PointsWriter: pw,
+	}
+
+	// The database is only written by an authorized user.
+	if _, err := influxdb.NewToTransformation(context.Background(), nil, nil, spec, deps); err == nil {
+		t.Fatal("expected error without a user")
+	}
+
+	data := []flux.Table{executetest.MustCopyTable(&executetest.Table{
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_measurement", Type: flux.TString},
+			{Label: "_field", Type: flux.TString},
+			{Label: "_value", Type: flux.TFloat},
+		},
+		Data: [][]interface{}{
+			{execute.Time(11), "a", "_value", 2.0},
+		},
+	})}
+	want := []*executetest.Table{{
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_measurement", Type: flux.TString},
+			{Label: "_field", Type: flux.TString},
+			{Label: "_value", Type: flux.TFloat},
+		},
+		Data: [][]interface{}{
+			{execute.Time(11), "a", "_value", 2.0},
+		},
+	}}
+	executetest.ProcessTestHelper(
+		t,
+		data,
+		want,
+		nil,
+		func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+			ctx, span := dependency.Inject(meta.NewContextWithUser(context.Background(), user))
+			defer span.Finish()
+			newT, err := influxdb.NewToTransformation(ctx, d, c, spec, deps)
+			if err != nil {
+				t.Error(err)
+			}
+			return newT
+		},
+	)
+
+	assert.Equal(t, "a _value=2 11\n", pointsToStr(pw.points))
+	assert.Equal(t, meta.User(user), pw.user)
+}
+
 type mockPointsWriter struct {
 	points models.Points
 	db     string
 	rp     string
+	user   meta.User
 }
 
 func (m *mockPointsWriter) WritePointsInto(request *coordinator.IntoWriteRequest) error {
@@ -774,9 +849,18 @@ func (m *mockPointsWriter) WritePointsInto(request *coordinator.IntoWriteRequest
 		return fmt.Errorf("Wrong retention policy - %s != %s", m.rp, request.RetentionPolicy)
 	}
 	m.points = append(m.points, request.Points...)
+	m.user = request.User
 	return nil
 }
 
+type mockAuthorizer struct {
+	AuthorizeDatabaseFn func(u meta.User, priv influxql.Privilege, database string) error
+}
+
+func (m *mockAuthorizer) AuthorizeDatabase(u meta.User, priv influxql.Privilege, database string) error {
+	return m.AuthorizeDatabaseFn(u, priv, database)
+}
+
 type mockMetaClient struct {
 }
 
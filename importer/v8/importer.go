@@ -4,17 +4,50 @@ package v8 // import "github.com/influxdata/influxdb/importer/v8"
 import (
 	"bufio"
 	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/influxdb/client"
+	isatty "github.com/mattn/go-isatty"
 )
 
-const batchSize = 5000
+const (
+	// DefaultBatchSize is the default number of points written at once.
+	DefaultBatchSize = 5000
+
+	// DefaultWorkers is the default number of batches written in parallel.
+	DefaultWorkers = 1
+
+	// DefaultRetries is the default number of times the write of a batch is
+	// retried on a network or server error.
+	DefaultRetries = 5
+
+	// DefaultRetryInterval is the default time before the first retry of a
+	// batch. It doubles on every retry, up to maxRetryInterval.
+	DefaultRetryInterval = time.Second
+)
+
+const (
+	maxRetryInterval = time.Minute
+
+	// progressInterval is the interval the progress bar is drawn and the
+	// checkpoint is saved at. The progress is only logged every
+	// logProgressInterval if the output isn't a terminal.
+	progressInterval    = time.Second
+	logProgressInterval = 10 * time.Second
+
+	progressBarWidth = 40
+)
 
 // Config is the config used to initialize a Importer importer
 type Config struct {
@@ -23,29 +56,88 @@ type Config struct {
 	Compressed bool // Whether import data is gzipped.
 	PPS        int  // points per second importer imports with.
 
+	Workers       int           // Number of batches written in parallel.
+	BatchSize     int           // Number of points written at once.
+	Retries       int           // Number of retries of a batch on a network or server error.
+	RetryInterval time.Duration // Time before the first retry of a batch.
+	Checkpoint    string        // Path of the file the progress is saved to and resumed from.
+
 	client.Config
 }
 
 // NewConfig returns an initialized *Config
 func NewConfig() Config {
-	return Config{Config: client.NewConfig()}
+	return Config{
+		Workers:       DefaultWorkers,
+		BatchSize:     DefaultBatchSize,
+		Retries:       DefaultRetries,
+		RetryInterval: DefaultRetryInterval,
+		Config:        client.NewConfig(),
+	}
+}
+
+// batch is points of the input written at once. Its sequence is its position
+// in the input and end the position of the end of its last line.
+type batch struct {
+	seq             int
+	database        string
+	retentionPolicy string
+	lines           []string
+	end             int64
+}
+
+// checkpoint is the progress of an import saved to its checkpoint file. The
+// input up to offset, in bytes of the uncompressed data, has been imported.
+type checkpoint struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
 }
 
 // Importer is the importer used for importing 0.8 data
 type Importer struct {
-	client                *client.Client
-	database              string
-	retentionPolicy       string
-	config                Config
-	batch                 []string
-	totalInserts          int
-	failedInserts         int
-	totalCommands         int
-	throttlePointsWritten int
-	startTime             time.Time
-	lastWrite             time.Time
-	throttle              *time.Ticker
-
+	client          *client.Client
+	database        string
+	retentionPolicy string
+	config          Config
+	batch           []string
+	totalCommands   int
+	startTime       time.Time
+
+	// The points written and failed by the writers.
+	totalInserts  int64
+	failedInserts int64
+
+	// offset is the position of the end of the last line read, and resume the
+	// one the import resumes from. read is the number of bytes of the file
+	// read, of size.
+	offset int64
+	resume int64
+	read   int64
+	size   int64
+
+	// throttled is the number of points sent to the writers since the start.
+	throttled int
+
+	seq     int
+	batches chan *batch
+	wg      sync.WaitGroup
+
+	// The batches are written out of order, so imported is the end of the
+	// first batches that are all written, and written holds the ends of the
+	// batches written after them by sequence.
+	mu       sync.Mutex
+	next     int
+	written  map[int]int64
+	imported int64
+	saved    int64
+
+	// abort is closed when the write of a batch fails, with its error.
+	abort     chan struct{}
+	abortOnce sync.Once
+	err       error
+
+	stdout       io.Writer
+	terminal     bool
 	stderrLogger *log.Logger
 	stdoutLogger *log.Logger
 }
@@ -53,15 +145,35 @@ type Importer struct {
 // NewImporter will return an intialized Importer struct
 func NewImporter(config Config) *Importer {
 	config.UserAgent = fmt.Sprintf("influxDB importer/%s", config.Version)
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = DefaultRetryInterval
+	}
 	return &Importer{
 		config:       config,
-		batch:        make([]string, 0, batchSize),
+		batch:        make([]string, 0, config.BatchSize),
+		written:      make(map[int]int64),
+		abort:        make(chan struct{}),
+		stdout:       os.Stdout,
+		terminal:     isatty.IsTerminal(os.Stdout.Fd()),
 		stdoutLogger: log.New(os.Stdout, "", log.LstdFlags),
 		stderrLogger: log.New(os.Stderr, "", log.LstdFlags),
 	}
 }
 
-// Import processes the specified file in the Config and writes the data to the databases in chunks specified by batchSize
+// Import processes the specified file in the Config and writes the data to
+// the databases in batches of BatchSize points, with Workers batches written
+// in parallel. If a batch still can't be written after its retries, the
+// import stops, and it resumes where it stopped if it's run again with the
+// same checkpoint file.
 func (i *Importer) Import() error {
 	// Create a client and try to connect.
 	cl, err := client.NewClient(i.config.Config)
@@ -78,6 +190,10 @@ func (i *Importer) Import() error {
 		return fmt.Errorf("file argument required")
 	}
 
+	if err := i.loadCheckpoint(); err != nil {
+		return err
+	}
+
 	defer func() {
 		if i.totalInserts > 0 {
 			i.stdoutLogger.Printf("Processed %d commands\n", i.totalCommands)
@@ -92,21 +208,21 @@ func (i *Importer) Import() error {
 		return err
 	}
 	defer f.Close()
+	if fi, err := f.Stat(); err == nil {
+		i.size = fi.Size()
+	}
 
-	var r io.Reader
+	var r io.Reader = &countingReader{r: f, n: &i.read}
 
 	// If gzipped, wrap in a gzip reader
 	if i.config.Compressed {
-		gr, err := gzip.NewReader(f)
+		gr, err := gzip.NewReader(r)
 		if err != nil {
 			return err
 		}
 		defer gr.Close()
 		// Set the reader to the gzip reader
 		r = gr
-	} else {
-		// Standard text file so our reader can just be the file
-		r = f
 	}
 
 	// Get our reader
@@ -117,17 +233,49 @@ func (i *Importer) Import() error {
 		return fmt.Errorf("reading standard input: %s", err)
 	}
 
-	// Set up our throttle channel.  Since there is effectively no other activity at this point
-	// the smaller resolution gets us much closer to the requested PPS
-	i.throttle = time.NewTicker(time.Microsecond)
-	defer i.throttle.Stop()
-
-	// Prime the last write
-	i.lastWrite = time.Now()
+	// Start the writers and the progress report.
+	i.imported = i.offset
+	if i.resume > i.imported {
+		i.imported = i.resume
+	}
+	i.saved = i.imported
+	i.batches = make(chan *batch, i.config.Workers)
+	for n := 0; n < i.config.Workers; n++ {
+		i.wg.Add(1)
+		go i.writeBatches()
+	}
+	done := make(chan struct{})
+	reported := make(chan struct{})
+	go func() {
+		defer close(reported)
+		i.reportProgress(done)
+	}()
 
 	// Process the DML
-	if err := i.processDML(scanner); err != nil {
-		return fmt.Errorf("reading standard input: %s", err)
+	err = i.processDML(scanner)
+	close(i.batches)
+	i.wg.Wait()
+	close(done)
+	<-reported
+
+	if err == nil {
+		err = i.err
+	}
+	if err != nil {
+		if i.config.Checkpoint != "" {
+			if err := i.saveCheckpoint(); err != nil {
+				i.stderrLogger.Printf("error saving checkpoint: %s\n", err)
+			}
+			return fmt.Errorf("%s; import stopped at byte %d, run it again with -checkpoint %s to resume", err, i.imported, i.config.Checkpoint)
+		}
+		return err
+	}
+
+	// The import is complete, so it can't be resumed anymore.
+	if i.config.Checkpoint != "" {
+		if err := os.Remove(i.config.Checkpoint); err != nil && !os.IsNotExist(err) {
+			i.stderrLogger.Printf("error removing checkpoint: %s\n", err)
+		}
 	}
 
 	// If there were any failed inserts then return an error so that a non-zero
@@ -152,6 +300,7 @@ func (i *Importer) processDDL(scanner *bufio.Reader) error {
 		} else if err == io.EOF {
 			return nil
 		}
+		i.offset += int64(len(line))
 		// If we find the DML token, we are done with DDL
 		if strings.HasPrefix(line, "# DML") {
 			return nil
@@ -163,6 +312,10 @@ func (i *Importer) processDDL(scanner *bufio.Reader) error {
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
+		// Skip the commands that were executed before the checkpoint.
+		if i.offset <= i.resume {
+			continue
+		}
 		i.queryExecutor(line)
 	}
 }
@@ -175,15 +328,19 @@ func (i *Importer) processDML(scanner *bufio.Reader) error {
 			return err
 		} else if err == io.EOF {
 			// Call batchWrite one last time to flush anything out in the batch
-			i.batchWrite()
-			return nil
+			return i.batchWrite()
 		}
+		i.offset += int64(len(line))
 		if strings.HasPrefix(line, "# CONTEXT-DATABASE:") {
-			i.batchWrite()
+			if err := i.batchWrite(); err != nil {
+				return err
+			}
 			i.database = strings.TrimSpace(strings.Split(line, ":")[1])
 		}
 		if strings.HasPrefix(line, "# CONTEXT-RETENTION-POLICY:") {
-			i.batchWrite()
+			if err := i.batchWrite(); err != nil {
+				return err
+			}
 			i.retentionPolicy = strings.TrimSpace(strings.Split(line, ":")[1])
 		}
 		if strings.HasPrefix(line, "#") {
@@ -193,7 +350,15 @@ func (i *Importer) processDML(scanner *bufio.Reader) error {
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
-		i.batchAccumulator(line)
+		// Skip the points that were written before the checkpoint. The
+		// context lines above are still read, so the points after it go to
+		// the right database and retention policy.
+		if i.offset <= i.resume {
+			continue
+		}
+		if err := i.batchAccumulator(line); err != nil {
+			return err
+		}
 	}
 }
 
@@ -213,62 +378,286 @@ func (i *Importer) queryExecutor(command string) {
 	i.execute(command)
 }
 
-func (i *Importer) batchAccumulator(line string) {
-	i.batch = append(i.batch, line)
-	if len(i.batch) == batchSize {
-		i.batchWrite()
+func (i *Importer) batchAccumulator(line string) error {
+	i.batch = append(i.batch, strings.TrimRight(line, "\r\n"))
+	if len(i.batch) == i.config.BatchSize {
+		return i.batchWrite()
 	}
+	return nil
 }
 
-func (i *Importer) batchWrite() {
+// batchWrite sends the batch to the writers. It returns the error of the
+// import if it stopped.
+func (i *Importer) batchWrite() error {
 	// Exit early if there are no points in the batch.
 	if len(i.batch) == 0 {
+		return nil
+	}
+
+	i.throttle(len(i.batch))
+
+	b := &batch{
+		seq:             i.seq,
+		database:        i.database,
+		retentionPolicy: i.retentionPolicy,
+		lines:           i.batch,
+		end:             i.offset,
+	}
+	i.seq++
+	i.batch = make([]string, 0, i.config.BatchSize)
+
+	select {
+	case i.batches <- b:
+		return nil
+	case <-i.abort:
+		return i.err
+	}
+}
+
+// throttle waits until n more points can be sent to the writers without
+// exceeding the points per second of the import.
+func (i *Importer) throttle(n int) {
+	if i.config.PPS <= 0 {
+		return
+	}
+	i.throttled += n
+	wait := time.Duration(float64(i.throttled)/float64(i.config.PPS)*float64(time.Second)) - time.Since(i.startTime)
+	if wait <= 0 {
 		return
 	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-i.abort:
+	}
+}
+
+// writeBatches writes the batches sent by the reader until there are no more.
+// The batches are discarded once the import stopped.
+func (i *Importer) writeBatches() {
+	defer i.wg.Done()
+	for b := range i.batches {
+		select {
+		case <-i.abort:
+			continue
+		default:
+		}
+		if err := i.writeBatch(b); err != nil {
+			i.stop(err)
+			continue
+		}
+		i.batchWritten(b)
+	}
+}
 
-	// Accumulate the batch size to see how many points we have written this second
-	i.throttlePointsWritten += len(i.batch)
+// writeBatch writes a batch. It's retried with an exponential backoff on
+// network and server errors, since a retry of the same points is harmless.
+// The points of a batch that the server rejects are counted as failed and
+// logged instead.
+func (i *Importer) writeBatch(b *batch) error {
+	data := strings.Join(b.lines, "\n")
+	interval := i.config.RetryInterval
+	for retry := 0; ; retry++ {
+		_, err := i.client.WriteLineProtocol(data, b.database, b.retentionPolicy, i.config.Precision, i.config.WriteConsistency)
+		if err == nil {
+			atomic.AddInt64(&i.totalInserts, int64(len(b.lines)))
+			return nil
+		} else if !retryable(err) {
+			i.stderrLogger.Println("error writing batch: ", err)
+			i.stderrLogger.Println(data)
+			atomic.AddInt64(&i.failedInserts, int64(len(b.lines)))
+			return nil
+		} else if retry == i.config.Retries {
+			return fmt.Errorf("error writing batch after %d retries: %s", retry, err)
+		}
 
-	// Find out when we last wrote data
-	since := time.Since(i.lastWrite)
+		i.stderrLogger.Printf("error writing batch, retrying in %s: %s\n", interval, err)
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-i.abort:
+			timer.Stop()
+			return err
+		}
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
 
-	// Check to see if we've exceeded our points per second for the current timeframe
-	var currentPPS int
-	if since.Seconds() > 0 {
-		currentPPS = int(float64(i.throttlePointsWritten) / since.Seconds())
-	} else {
-		currentPPS = i.throttlePointsWritten
+// retryable returns true if the write of a batch failed because of the
+// network or the server rather than its points.
+func retryable(err error) bool {
+	var werr *client.WriteError
+	if errors.As(err, &werr) {
+		return werr.StatusCode == http.StatusTooManyRequests || werr.StatusCode >= 500
 	}
+	return true
+}
 
-	// If our currentPPS is greater than the PPS specified, then we wait and retry
-	if int(currentPPS) > i.config.PPS && i.config.PPS != 0 {
-		// Wait for the next tick
-		<-i.throttle.C
+// stop stops the import with the error of the first writer that failed.
+func (i *Importer) stop(err error) {
+	i.abortOnce.Do(func() {
+		i.err = err
+		close(i.abort)
+	})
+}
 
-		// Decrement the batch size back out as it is going to get called again
-		i.throttlePointsWritten -= len(i.batch)
-		i.batchWrite()
-		return
+// batchWritten moves the end of the imported input past the batch and the
+// written batches that follow it.
+func (i *Importer) batchWritten(b *batch) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.written[b.seq] = b.end
+	for {
+		end, ok := i.written[i.next]
+		if !ok {
+			return
+		}
+		delete(i.written, i.next)
+		i.next++
+		i.imported = end
+	}
+}
+
+// reportProgress draws the progress bar and saves the checkpoint on every
+// interval until done is closed.
+func (i *Importer) reportProgress(done <-chan struct{}) {
+	tick := time.NewTicker(progressInterval)
+	defer tick.Stop()
+
+	lastLog := time.Now()
+	for {
+		select {
+		case now := <-tick.C:
+			if i.terminal {
+				i.drawProgress()
+			} else if now.Sub(lastLog) >= logProgressInterval {
+				i.logProgress()
+				lastLog = now
+			}
+			if err := i.saveCheckpoint(); err != nil {
+				i.stderrLogger.Printf("error saving checkpoint: %s\n", err)
+			}
+		case <-done:
+			if i.terminal {
+				i.drawProgress()
+				fmt.Fprintln(i.stdout)
+			}
+			return
+		}
+	}
+}
+
+// progress returns the points processed since the start, the rate they are
+// processed at and the part of the file read.
+func (i *Importer) progress() (processed int64, pps float64, ratio float64) {
+	processed = atomic.LoadInt64(&i.totalInserts) + atomic.LoadInt64(&i.failedInserts)
+	if since := time.Since(i.startTime).Seconds(); since > 0 {
+		pps = float64(processed) / since
+	}
+	if i.size > 0 {
+		ratio = float64(atomic.LoadInt64(&i.read)) / float64(i.size)
+		if ratio > 1 {
+			ratio = 1
+		}
+	}
+	return processed, pps, ratio
+}
+
+// drawProgress redraws the progress bar on the terminal.
+func (i *Importer) drawProgress() {
+	processed, pps, ratio := i.progress()
+	filled := int(ratio * progressBarWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	line := fmt.Sprintf("\r[%s] %5.1f%%  %d points  %d points/sec", bar, ratio*100, processed, int64(pps))
+	if failed := atomic.LoadInt64(&i.failedInserts); failed > 0 {
+		line += fmt.Sprintf("  %d failed", failed)
 	}
+	fmt.Fprint(i.stdout, line)
+}
+
+// logProgress logs the progress.
+func (i *Importer) logProgress() {
+	processed, pps, ratio := i.progress()
+	i.stdoutLogger.Printf("Processed %d lines (%.1f%%).  Time elapsed: %s.  Points per second (PPS): %d", processed, ratio*100, time.Since(i.startTime).Round(time.Second), int64(pps))
+}
+
+// loadCheckpoint loads the position to resume the import from, if its
+// checkpoint file exists.
+func (i *Importer) loadCheckpoint() error {
+	if i.config.Checkpoint == "" {
+		return nil
+	}
+	path, err := filepath.Abs(i.config.Path)
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(i.config.Checkpoint)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(b, &c); err != nil {
+		return fmt.Errorf("invalid checkpoint %s: %s", i.config.Checkpoint, err)
+	} else if c.Path != path {
+		return fmt.Errorf("checkpoint %s is for the import of %s", i.config.Checkpoint, c.Path)
+	}
+
+	i.resume = c.Offset
+	i.stdoutLogger.Printf("Resuming the import at byte %d of %s\n", c.Offset, path)
+	return nil
+}
 
-	_, e := i.client.WriteLineProtocol(strings.Join(i.batch, "\n"), i.database, i.retentionPolicy, i.config.Precision, i.config.WriteConsistency)
-	if e != nil {
-		i.stderrLogger.Println("error writing batch: ", e)
-		i.stderrLogger.Println(strings.Join(i.batch, "\n"))
-		i.failedInserts += len(i.batch)
-	} else {
-		i.totalInserts += len(i.batch)
+// saveCheckpoint saves the end of the imported input to the checkpoint file,
+// if it moved since it was last saved.
+func (i *Importer) saveCheckpoint() error {
+	if i.config.Checkpoint == "" {
+		return nil
+	}
+	i.mu.Lock()
+	imported := i.imported
+	i.mu.Unlock()
+	if imported == i.saved {
+		return nil
 	}
-	i.throttlePointsWritten = 0
-	i.lastWrite = time.Now()
 
-	// Clear the batch and record the number of processed points.
-	i.batch = i.batch[:0]
-	// Give some status feedback every 100000 lines processed
-	processed := i.totalInserts + i.failedInserts
-	if processed%100000 == 0 {
-		since := time.Since(i.startTime)
-		pps := float64(processed) / since.Seconds()
-		i.stdoutLogger.Printf("Processed %d lines.  Time elapsed: %s.  Points per second (PPS): %d", processed, since.String(), int64(pps))
+	path, err := filepath.Abs(i.config.Path)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(checkpoint{Path: path, Offset: imported})
+	if err != nil {
+		return err
 	}
+
+	// Replace the checkpoint at once, so it's never partially written.
+	tmp := i.config.Checkpoint + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	} else if err := os.Rename(tmp, i.config.Checkpoint); err != nil {
+		return err
+	}
+	i.saved = imported
+	return nil
+}
+
+// countingReader counts the bytes read from a reader.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	atomic.AddInt64(r.n, int64(n))
+	return n, err
 }
@@ -0,0 +1,182 @@
+package v8
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer counts the points written to each retention policy. A write fails
+// with the status code fail returns for it, unless it's zero.
+type testServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	writes int
+	points map[string]int
+	fail   func(writes int) int
+}
+
+func newTestServer(fail func(writes int) int) *testServer {
+	s := &testServer{points: make(map[string]int), fail: fail}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ping":
+			w.WriteHeader(http.StatusNoContent)
+		case "/query":
+			w.Write([]byte(`{"results":[{}]}`))
+		case "/write":
+			body, _ := io.ReadAll(r.Body)
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.writes++
+			if code := s.fail(s.writes); code != 0 {
+				http.Error(w, `{"error":"write failed"}`, code)
+				return
+			}
+			s.points[r.URL.Query().Get("db")+"."+r.URL.Query().Get("rp")] += len(strings.Split(string(body), "\n"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return s
+}
+
+// writeExport writes an export with n points in each of two databases.
+func writeExport(t *testing.T, n int) string {
+	var b strings.Builder
+	b.WriteString("# DDL\nCREATE DATABASE db0\nCREATE DATABASE db1\n\n# DML\n")
+	for _, db := range []string{"db0", "db1"} {
+		fmt.Fprintf(&b, "# CONTEXT-DATABASE:%s\n# CONTEXT-RETENTION-POLICY:autogen\n", db)
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "cpu,host=server%d value=%d %d\n", i%10, i, i)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "export")
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestImporter(t *testing.T, s *testServer, path string, workers int) *Importer {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := NewConfig()
+	config.URL = *u
+	config.Path = path
+	config.Workers = workers
+	config.BatchSize = 100
+	config.Retries = 2
+	config.RetryInterval = time.Millisecond
+
+	i := NewImporter(config)
+	i.stdout = io.Discard
+	i.terminal = false
+	i.stdoutLogger = log.New(io.Discard, "", 0)
+	i.stderrLogger = log.New(io.Discard, "", 0)
+	return i
+}
+
+func TestImporter_Import_Parallel(t *testing.T) {
+	// Two writes fail with a server error and are retried.
+	s := newTestServer(func(writes int) int {
+		if writes == 2 || writes == 5 {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	})
+	defer s.Close()
+
+	i := newTestImporter(t, s, writeExport(t, 1050), 4)
+	if err := i.Import(); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := s.points["db0.autogen"], 1050; got != exp {
+		t.Errorf("unexpected points in db0: got=%d exp=%d", got, exp)
+	}
+	if got, exp := s.points["db1.autogen"], 1050; got != exp {
+		t.Errorf("unexpected points in db1: got=%d exp=%d", got, exp)
+	}
+	if i.totalInserts != 2100 || i.failedInserts != 0 {
+		t.Errorf("unexpected inserts: total=%d failed=%d", i.totalInserts, i.failedInserts)
+	}
+}
+
+func TestImporter_Import_Rejected(t *testing.T) {
+	// The batches the server rejects are not retried.
+	s := newTestServer(func(writes int) int {
+		if writes == 1 {
+			return http.StatusBadRequest
+		}
+		return 0
+	})
+	defer s.Close()
+
+	i := newTestImporter(t, s, writeExport(t, 500), 1)
+	if err := i.Import(); err == nil || err.Error() != "100 points were not inserted" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, exp := s.writes, 10; got != exp {
+		t.Errorf("unexpected writes: got=%d exp=%d", got, exp)
+	}
+}
+
+func TestImporter_Import_Resume(t *testing.T) {
+	path := writeExport(t, 500)
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint")
+
+	// The server goes down after 7 writes, so the import stops once the
+	// retries of the 8th batch are exhausted.
+	s := newTestServer(func(writes int) int {
+		if writes > 7 {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	})
+	defer s.Close()
+
+	i := newTestImporter(t, s, path, 1)
+	i.config.Checkpoint = checkpoint
+	if err := i.Import(); err == nil || !strings.Contains(err.Error(), "run it again with -checkpoint") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Fatalf("checkpoint not saved: %s", err)
+	}
+
+	// Resume once the server is back.
+	s.mu.Lock()
+	s.fail = func(int) int { return 0 }
+	s.mu.Unlock()
+
+	i = newTestImporter(t, s, path, 1)
+	i.config.Checkpoint = checkpoint
+	if err := i.Import(); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := i.totalInserts, int64(300); got != exp {
+		t.Errorf("unexpected inserts on resume: got=%d exp=%d", got, exp)
+	}
+	if got, exp := s.points["db0.autogen"], 500; got != exp {
+		t.Errorf("unexpected points in db0: got=%d exp=%d", got, exp)
+	}
+	if got, exp := s.points["db1.autogen"], 500; got != exp {
+		t.Errorf("unexpected points in db1: got=%d exp=%d", got, exp)
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed: %v", err)
+	}
+}
@@ -32,25 +32,48 @@ type MetaClientMock struct {
 
 	OpenFn func() error
 
-	PrecreateShardGroupsFn func(from, to time.Time) error
-	PruneShardGroupsFn     func() error
+	PrecreateShardGroupsFn        func(from, to time.Time) error
+	PruneShardGroupsFn            func() error
+	TruncateDatabaseShardGroupsFn func(database, policy string, t time.Time) error
 
 	RetentionPolicyFn func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
 
-	AuthenticateFn           func(username, password string) (ui meta.User, err error)
-	AdminUserExistsFn        func() bool
-	SetAdminPrivilegeFn      func(username string, admin bool) error
-	SetDataFn                func(*meta.Data) error
-	SetPrivilegeFn           func(username, database string, p influxql.Privilege) error
-	ShardGroupsByTimeRangeFn func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
-	ShardOwnerFn             func(shardID uint64) (database, policy string, sgi *meta.ShardGroupInfo)
-	TruncateShardGroupsFn    func(t time.Time) error
-	UpdateRetentionPolicyFn  func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
-	UpdateUserFn             func(name, password string) error
-	UserPrivilegeFn          func(username, database string) (*influxql.Privilege, error)
-	UserPrivilegesFn         func(username string) (map[string]influxql.Privilege, error)
-	UserFn                   func(username string) (meta.User, error)
-	UsersFn                  func() []meta.UserInfo
+	AuthenticateFn              func(username, password string) (ui meta.User, err error)
+	AdminUserExistsFn           func() bool
+	SetAdminPrivilegeFn         func(username string, admin bool) error
+	SetDataFn                   func(*meta.Data) error
+	SetMeasurementPrivilegeFn   func(username string, mp meta.MeasurementPrivilege) error
+	SetPrivilegeFn              func(username, database string, p influxql.Privilege) error
+	SetUserTimeZoneFn           func(username, tz string) error
+	ShardGroupsByTimeRangeFn    func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
+	ShardOwnerFn                func(shardID uint64) (database, policy string, sgi *meta.ShardGroupInfo)
+	TruncateShardGroupsFn       func(t time.Time) error
+	UpdateRetentionPolicyFn     func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
+	UpdateUserFn                func(name, password string) error
+	UserMeasurementPrivilegesFn func(username string) ([]meta.MeasurementPrivilege, error)
+	UserPrivilegeFn             func(username, database string) (*influxql.Privilege, error)
+	UserPrivilegesFn            func(username string) (map[string]influxql.Privilege, error)
+	UserFn                      func(username string) (meta.User, error)
+	UsersFn                     func() []meta.UserInfo
+	AddUserToRoleFn             func(username, role string) error
+	CreateRoleFn                func(name string) error
+	DropRoleFn                  func(name string) error
+	RemoveUserFromRoleFn        func(username, role string) error
+	RolePrivilegesFn            func(role string) (map[string]influxql.Privilege, error)
+	RolesFn                     func() []meta.RoleInfo
+	SetRolePrivilegeFn          func(role, database string, p influxql.Privilege) error
+	UserRolesFn                 func(username string) ([]string, error)
+	AuthenticateTokenFn         func(token string) (meta.User, error)
+	CreateTokenFn               func(username string, ttl time.Duration, database, rp string) (string, *meta.TokenInfo, error)
+	DropTokenFn                 func(id string) error
+	TokensFn                    func() []meta.TokenInfo
+	SchemaFn                    func() *meta.Schema
+	SchemaStatementsFn          func(s *meta.Schema, prune bool) ([]influxql.Statement, error)
+	SetDatabaseQuotaFn          func(name string, q meta.QuotaInfo) error
+	RenameDatabaseFn            func(oldName, newName string) error
+	RenameMeasurementFn         func(database, oldName, newName string) error
+	SetUserWriteTargetFn        func(username, database, rp string) error
+	SetUserQueryPriorityFn      func(username, priority string) error
 }
 
 func (c *MetaClientMock) Close() error {
@@ -129,10 +152,18 @@ func (c *MetaClientMock) SetAdminPrivilege(username string, admin bool) error {
 	return c.SetAdminPrivilegeFn(username, admin)
 }
 
+func (c *MetaClientMock) SetMeasurementPrivilege(username string, mp meta.MeasurementPrivilege) error {
+	return c.SetMeasurementPrivilegeFn(username, mp)
+}
+
 func (c *MetaClientMock) SetPrivilege(username, database string, p influxql.Privilege) error {
 	return c.SetPrivilegeFn(username, database, p)
 }
 
+func (c *MetaClientMock) SetUserTimeZone(username, tz string) error {
+	return c.SetUserTimeZoneFn(username, tz)
+}
+
 func (c *MetaClientMock) ShardGroupsByTimeRange(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
 	return c.ShardGroupsByTimeRangeFn(database, policy, min, max)
 }
@@ -153,6 +184,10 @@ func (c *MetaClientMock) UpdateUser(name, password string) error {
 	return c.UpdateUserFn(name, password)
 }
 
+func (c *MetaClientMock) UserMeasurementPrivileges(username string) ([]meta.MeasurementPrivilege, error) {
+	return c.UserMeasurementPrivilegesFn(username)
+}
+
 func (c *MetaClientMock) UserPrivilege(username, database string) (*influxql.Privilege, error) {
 	return c.UserPrivilegeFn(username, database)
 }
@@ -177,3 +212,83 @@ func (c *MetaClientMock) PrecreateShardGroups(from, to time.Time) error {
 	return c.PrecreateShardGroupsFn(from, to)
 }
 func (c *MetaClientMock) PruneShardGroups() error { return c.PruneShardGroupsFn() }
+
+func (c *MetaClientMock) AddUserToRole(username, role string) error {
+	return c.AddUserToRoleFn(username, role)
+}
+
+func (c *MetaClientMock) CreateRole(name string) error {
+	return c.CreateRoleFn(name)
+}
+
+func (c *MetaClientMock) DropRole(name string) error {
+	return c.DropRoleFn(name)
+}
+
+func (c *MetaClientMock) RemoveUserFromRole(username, role string) error {
+	return c.RemoveUserFromRoleFn(username, role)
+}
+
+func (c *MetaClientMock) RolePrivileges(role string) (map[string]influxql.Privilege, error) {
+	return c.RolePrivilegesFn(role)
+}
+
+func (c *MetaClientMock) Roles() []meta.RoleInfo {
+	return c.RolesFn()
+}
+
+func (c *MetaClientMock) SetRolePrivilege(role, database string, p influxql.Privilege) error {
+	return c.SetRolePrivilegeFn(role, database, p)
+}
+
+func (c *MetaClientMock) UserRoles(username string) ([]string, error) {
+	return c.UserRolesFn(username)
+}
+
+func (c *MetaClientMock) AuthenticateToken(token string) (meta.User, error) {
+	return c.AuthenticateTokenFn(token)
+}
+
+func (c *MetaClientMock) CreateToken(username string, ttl time.Duration, database, rp string) (string, *meta.TokenInfo, error) {
+	return c.CreateTokenFn(username, ttl, database, rp)
+}
+
+func (c *MetaClientMock) DropToken(id string) error {
+	return c.DropTokenFn(id)
+}
+
+func (c *MetaClientMock) Tokens() []meta.TokenInfo {
+	return c.TokensFn()
+}
+
+func (c *MetaClientMock) Schema() *meta.Schema {
+	return c.SchemaFn()
+}
+
+func (c *MetaClientMock) SchemaStatements(s *meta.Schema, prune bool) ([]influxql.Statement, error) {
+	return c.SchemaStatementsFn(s, prune)
+}
+
+func (c *MetaClientMock) SetDatabaseQuota(name string, q meta.QuotaInfo) error {
+	return c.SetDatabaseQuotaFn(name, q)
+}
+
+func (c *MetaClientMock) TruncateDatabaseShardGroups(database, policy string, t time.Time) error {
+	return c.TruncateDatabaseShardGroupsFn(database, policy, t)
+}
+
+func (c *MetaClientMock) RenameDatabase(oldName, newName string) error {
+	return c.RenameDatabaseFn(oldName, newName)
+}
+
+func (c *MetaClientMock) RenameMeasurement(database, oldName, newName string) error {
+	return c.RenameMeasurementFn(database, oldName, newName)
+}
+
+func (c *MetaClientMock) SetUserWriteTarget(username, database, rp string) error {
+	return c.SetUserWriteTargetFn(username, database, rp)
+}
+
+func (c *MetaClientMock) SetUserQueryPriority(username, priority string) error {
+	return c.SetUserQueryPriorityFn(username, priority)
+}
@@ -34,6 +34,8 @@ type TSDBStoreMock struct {
 	MeasurementNamesFn          func(auth query.FineAuthorizer, database string, retentionPolicy string, cond influxql.Expr) ([][]byte, error)
 	OpenFn                      func() error
 	PathFn                      func() string
+	RenameDatabaseFn            func(oldName, newName string) error
+	RenameMeasurementFn         func(database, oldName, newName string) error
 	RestoreShardFn              func(id uint64, r io.Reader) error
 	SeriesCardinalityFn         func(database string) (int64, error)
 	SetShardEnabledFn           func(shardID uint64, enabled bool) error
@@ -110,6 +112,12 @@ func (s *TSDBStoreMock) Open() error {
 func (s *TSDBStoreMock) Path() string {
 	return s.PathFn()
 }
+func (s *TSDBStoreMock) RenameDatabase(oldName, newName string) error {
+	return s.RenameDatabaseFn(oldName, newName)
+}
+func (s *TSDBStoreMock) RenameMeasurement(database, oldName, newName string) error {
+	return s.RenameMeasurementFn(database, oldName, newName)
+}
 func (s *TSDBStoreMock) RestoreShard(id uint64, r io.Reader) error {
 	return s.RestoreShardFn(id, r)
 }
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultRecentLogsSize is the size of the recent logs kept for the debug
+// dumps.
+const DefaultRecentLogsSize = 1 << 20
+
+// RecentLogs keeps the most recent logs written to it, up to a size, so they
+// can be collected without access to the log output of the process.
+type RecentLogs struct {
+	mu   sync.Mutex
+	buf  []byte
+	size int
+}
+
+// NewRecentLogs returns a new instance of RecentLogs that keeps up to size
+// bytes of logs.
+func NewRecentLogs(size int) *RecentLogs {
+	return &RecentLogs{size: size}
+}
+
+// Write appends logs, dropping the oldest lines past the size.
+func (r *RecentLogs) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf = append(r.buf, p...)
+	// Trim once the buffer is twice the size, so the logs aren't copied on
+	// every write.
+	if len(r.buf) > 2*r.size {
+		tail := r.buf[len(r.buf)-r.size:]
+		if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+			tail = tail[i+1:]
+		}
+		r.buf = append(r.buf[:0], tail...)
+	}
+	return len(p), nil
+}
+
+// Bytes returns a copy of the recent logs, up to the size.
+func (r *RecentLogs) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.buf
+	if len(b) > r.size {
+		b = b[len(b)-r.size:]
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			b = b[i+1:]
+		}
+	}
+	return append([]byte(nil), b...)
+}
+
+// Tee returns a logger that also writes the logs of log enabled at a level to
+// the recent logs, in logfmt.
+func (r *RecentLogs) Tee(log *zap.Logger, level zapcore.LevelEnabler) *zap.Logger {
+	encoder, _ := newEncoder("logfmt")
+	core := zapcore.NewCore(encoder, zapcore.AddSync(r), level)
+	return log.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, core)
+	}))
+}
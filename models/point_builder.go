@@ -0,0 +1,263 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// fieldKeyEscapeCodes are the characters escaped in field keys, as by
+// escape.String.
+var fieldKeyEscapeCodes = [...]escapeSet{
+	{k: [1]byte{','}, esc: [2]byte{'\\', ','}},
+	{k: [1]byte{'"'}, esc: [2]byte{'\\', '"'}},
+	{k: [1]byte{' '}, esc: [2]byte{'\\', ' '}},
+	{k: [1]byte{'='}, esc: [2]byte{'\\', '='}},
+}
+
+var pointBuilderPool = sync.Pool{
+	New: func() interface{} { return NewPointBuilder() },
+}
+
+// GetPointBuilder returns a reset PointBuilder from a pool. It should be
+// returned with PutPointBuilder once done.
+func GetPointBuilder() *PointBuilder {
+	b := pointBuilderPool.Get().(*PointBuilder)
+	b.Reset()
+	return b
+}
+
+// PutPointBuilder returns a PointBuilder to the pool. The points it built
+// are still valid.
+func PutPointBuilder(b *PointBuilder) {
+	pointBuilderPool.Put(b)
+}
+
+// PointBuilder builds points without the tag and field maps of NewPoint.
+// The tags are kept sorted as they are added and the fields are encoded as
+// they are added. A builder reuses its buffers from one point to the next,
+// and a point only allocates its own key and fields.
+//
+//	b := models.GetPointBuilder()
+//	defer models.PutPointBuilder(b)
+//	b.SetName("cpu")
+//	b.AddTag("host", "a")
+//	b.AddFloatField("value", 1.5)
+//	b.SetTime(t)
+//	pt, err := b.Point()
+type PointBuilder struct {
+	name []byte
+
+	// tags are sorted by key, and refer to tagBuf.
+	tags   []builderTag
+	tagBuf []byte
+
+	fields         []byte // encoded fields
+	maxFieldKeyLen int
+	time           time.Time
+
+	// err is the first error of the fields.
+	err error
+}
+
+// builderTag is a tag of a PointBuilder, as offsets in its tagBuf.
+type builderTag struct {
+	key, value [2]int
+}
+
+// NewPointBuilder returns a new PointBuilder.
+func NewPointBuilder() *PointBuilder {
+	return &PointBuilder{}
+}
+
+// Reset resets the builder to build a new point.
+func (b *PointBuilder) Reset() {
+	b.name = b.name[:0]
+	b.tags = b.tags[:0]
+	b.tagBuf = b.tagBuf[:0]
+	b.fields = b.fields[:0]
+	b.maxFieldKeyLen = 0
+	b.time = time.Time{}
+	b.err = nil
+}
+
+// SetName sets the measurement of the point.
+func (b *PointBuilder) SetName(name string) {
+	b.name = append(b.name[:0], name...)
+}
+
+// SetTime sets the time of the point.
+func (b *PointBuilder) SetTime(t time.Time) {
+	b.time = t
+}
+
+// AddTag adds a tag to the point, replacing the value of a tag of the same
+// key. Tags with an empty value are ignored, like in a key.
+func (b *PointBuilder) AddTag(key, value string) {
+	if value == "" {
+		return
+	}
+
+	i := sort.Search(len(b.tags), func(i int) bool {
+		return bytes.Compare(b.tagBytes(b.tags[i].key), []byte(key)) >= 0
+	})
+	var t builderTag
+	t.value[0] = len(b.tagBuf)
+	b.tagBuf = append(b.tagBuf, value...)
+	t.value[1] = len(b.tagBuf)
+	if i < len(b.tags) && string(b.tagBytes(b.tags[i].key)) == key {
+		b.tags[i].value = t.value
+		return
+	}
+	t.key[0] = len(b.tagBuf)
+	b.tagBuf = append(b.tagBuf, key...)
+	t.key[1] = len(b.tagBuf)
+
+	b.tags = append(b.tags, builderTag{})
+	copy(b.tags[i+1:], b.tags[i:])
+	b.tags[i] = t
+}
+
+func (b *PointBuilder) tagBytes(r [2]int) []byte {
+	return b.tagBuf[r[0]:r[1]]
+}
+
+// appendFieldKey starts the encoding of a field of key.
+func (b *PointBuilder) appendFieldKey(key string) bool {
+	if b.err != nil {
+		return false
+	} else if len(key) == 0 {
+		b.err = errors.New("all fields must have non-empty names")
+		return false
+	}
+	if len(b.fields) > 0 {
+		b.fields = append(b.fields, ',')
+	}
+	for i := 0; i < len(key); i++ {
+		b.fields = appendEscapedByte(b.fields, key[i], fieldKeyEscapeCodes[:])
+	}
+	if len(key) > b.maxFieldKeyLen {
+		b.maxFieldKeyLen = len(key)
+	}
+	b.fields = append(b.fields, '=')
+	return true
+}
+
+// AddFloatField adds a float field to the point. NaN and infinite values
+// are rejected by Point.
+func (b *PointBuilder) AddFloatField(key string, value float64) {
+	if b.err != nil {
+		return
+	} else if math.IsInf(value, 0) {
+		b.err = fmt.Errorf("+/-Inf is an unsupported value for field %s", key)
+		return
+	} else if math.IsNaN(value) {
+		b.err = fmt.Errorf("NaN is an unsupported value for field %s", key)
+		return
+	}
+	if b.appendFieldKey(key) {
+		b.fields = strconv.AppendFloat(b.fields, value, 'f', -1, 64)
+	}
+}
+
+// AddIntegerField adds an integer field to the point.
+func (b *PointBuilder) AddIntegerField(key string, value int64) {
+	if b.appendFieldKey(key) {
+		b.fields = strconv.AppendInt(b.fields, value, 10)
+		b.fields = append(b.fields, 'i')
+	}
+}
+
+// AddUnsignedField adds an unsigned field to the point.
+func (b *PointBuilder) AddUnsignedField(key string, value uint64) {
+	if b.appendFieldKey(key) {
+		b.fields = strconv.AppendUint(b.fields, value, 10)
+		b.fields = append(b.fields, 'u')
+	}
+}
+
+// AddStringField adds a string field to the point.
+func (b *PointBuilder) AddStringField(key string, value string) {
+	if b.appendFieldKey(key) {
+		b.fields = append(b.fields, '"')
+		for i := 0; i < len(value); i++ {
+			if c := value[i]; c == '"' || c == '\\' {
+				b.fields = append(b.fields, '\\')
+			}
+			b.fields = append(b.fields, value[i])
+		}
+		b.fields = append(b.fields, '"')
+	}
+}
+
+// AddBooleanField adds a boolean field to the point.
+func (b *PointBuilder) AddBooleanField(key string, value bool) {
+	if b.appendFieldKey(key) {
+		b.fields = strconv.AppendBool(b.fields, value)
+	}
+}
+
+// Point returns a new point of the name, tags, fields and time of the
+// builder, with the same checks as NewPoint. The builder can then be reset
+// to build another point.
+func (b *PointBuilder) Point() (Point, error) {
+	if b.err != nil {
+		return nil, b.err
+	} else if len(b.fields) == 0 {
+		return nil, ErrPointMustHaveAField
+	}
+	if !b.time.IsZero() {
+		if err := CheckTime(b.time); err != nil {
+			return nil, err
+		}
+	}
+
+	// The key and the fields of the point share a single allocation.
+	n := len(b.name) + len(b.fields)
+	for _, t := range b.tags {
+		n += 2 + (t.key[1] - t.key[0]) + (t.value[1] - t.value[0])
+	}
+	buf := make([]byte, 0, n+n/8)
+	buf = append(buf, EscapeMeasurement(unescapeMeasurement(b.name))...)
+	for _, t := range b.tags {
+		buf = append(buf, ',')
+		buf = appendEscaped(buf, b.tagBytes(t.key), tagEscapeCodes[:])
+		buf = append(buf, '=')
+		buf = appendEscaped(buf, b.tagBytes(t.value), tagEscapeCodes[:])
+	}
+	keyLen := len(buf)
+	// 4 is the length of the tsm1.fieldKeySeparator constant, as in seriesKeySize.
+	if sz := keyLen + 4 + b.maxFieldKeyLen; sz > MaxKeyLength {
+		return nil, fmt.Errorf("max key length exceeded: %v > %v", sz, MaxKeyLength)
+	}
+	buf = append(buf, b.fields...)
+
+	return &point{
+		key:    buf[:keyLen:keyLen],
+		fields: buf[keyLen:],
+		time:   b.time,
+	}, nil
+}
+
+// appendEscaped appends src to dst, escaping the characters of codes.
+func appendEscaped(dst, src []byte, codes []escapeSet) []byte {
+	for _, c := range src {
+		dst = appendEscapedByte(dst, c, codes)
+	}
+	return dst
+}
+
+// appendEscapedByte appends c to dst, escaped if it is one of codes.
+func appendEscapedByte(dst []byte, c byte, codes []escapeSet) []byte {
+	for i := range codes {
+		if codes[i].k[0] == c {
+			return append(dst, codes[i].esc[:]...)
+		}
+	}
+	return append(dst, c)
+}
@@ -0,0 +1,173 @@
+package models_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestPointBuilder(t *testing.T) {
+	ts := time.Unix(0, 1000000000)
+	b := models.NewPointBuilder()
+
+	for _, tt := range []struct {
+		name   string
+		tags   [][2]string
+		build  func(b *models.PointBuilder)
+		tagMap map[string]string
+		fields models.Fields
+	}{
+		{
+			name: "cpu",
+			tags: [][2]string{{"region", "us"}, {"host", "a"}, {"az", "1"}},
+			build: func(b *models.PointBuilder) {
+				b.AddBooleanField("b", true)
+				b.AddFloatField("f", 1.5)
+				b.AddIntegerField("i", -2)
+				b.AddStringField("s", `a "quoted" \ value`)
+				b.AddUnsignedField("u", 3)
+			},
+			tagMap: map[string]string{"region": "us", "host": "a", "az": "1"},
+			fields: models.Fields{"b": true, "f": 1.5, "i": int64(-2), "s": `a "quoted" \ value`, "u": uint64(3)},
+		},
+		{
+			name: `cp u,x`,
+			tags: [][2]string{{"a b", "c=d"}, {"a!", "e,f"}, {"empty", ""}},
+			build: func(b *models.PointBuilder) {
+				b.AddFloatField(`v a"l=,`, 1)
+			},
+			tagMap: map[string]string{"a b": "c=d", "a!": "e,f", "empty": ""},
+			fields: models.Fields{`v a"l=,`: 1.0},
+		},
+		{
+			name: "dup",
+			tags: [][2]string{{"host", "a"}, {"host", "b"}},
+			build: func(b *models.PointBuilder) {
+				b.AddIntegerField("value", 1)
+			},
+			tagMap: map[string]string{"host": "b"},
+			fields: models.Fields{"value": int64(1)},
+		},
+	} {
+		b.Reset()
+		b.SetName(tt.name)
+		for _, tag := range tt.tags {
+			b.AddTag(tag[0], tag[1])
+		}
+		tt.build(b)
+		b.SetTime(ts)
+		pt, err := b.Point()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.name, err)
+		}
+
+		exp := models.MustNewPoint(tt.name, models.NewTags(tt.tagMap), tt.fields, ts)
+		if got, exp := pt.String(), exp.String(); got != exp {
+			t.Errorf("%s: unexpected point:\ngot  %s\nexp  %s", tt.name, got, exp)
+		}
+		fields, err := pt.Fields()
+		if err != nil {
+			t.Fatalf("%s: unexpected fields error: %s", tt.name, err)
+		} else if len(fields) != len(tt.fields) {
+			t.Errorf("%s: unexpected fields: %v", tt.name, fields)
+		}
+	}
+}
+
+func TestPointBuilder_Errors(t *testing.T) {
+	b := models.GetPointBuilder()
+	defer models.PutPointBuilder(b)
+
+	b.SetName("cpu")
+	if _, err := b.Point(); err != models.ErrPointMustHaveAField {
+		t.Errorf("unexpected error without fields: %v", err)
+	}
+
+	b.Reset()
+	b.SetName("cpu")
+	b.AddFloatField("value", math.NaN())
+	b.AddIntegerField("", 1)
+	if _, err := b.Point(); err == nil || !strings.Contains(err.Error(), "NaN") {
+		t.Errorf("unexpected error of a NaN field: %v", err)
+	}
+
+	b.Reset()
+	b.SetName("cpu")
+	b.AddIntegerField("", 1)
+	if _, err := b.Point(); err == nil || !strings.Contains(err.Error(), "non-empty names") {
+		t.Errorf("unexpected error of an empty field key: %v", err)
+	}
+
+	b.Reset()
+	b.SetName("cpu")
+	b.AddTag("host", strings.Repeat("a", models.MaxKeyLength))
+	b.AddIntegerField("value", 1)
+	if _, err := b.Point(); err == nil || !strings.Contains(err.Error(), "max key length exceeded") {
+		t.Errorf("unexpected error of a long key: %v", err)
+	}
+
+	b.Reset()
+	b.SetName("cpu")
+	b.AddIntegerField("value", 1)
+	b.SetTime(time.Unix(0, models.MinNanoTime-1))
+	if _, err := b.Point(); err != models.ErrTimeOutOfRange {
+		t.Errorf("unexpected error of a time out of range: %v", err)
+	}
+}
+
+func TestPointBuilder_Allocs(t *testing.T) {
+	b := models.NewPointBuilder()
+	ts := time.Unix(0, 1000000000)
+	build := func() {
+		b.Reset()
+		b.SetName("cpu")
+		b.AddTag("region", "us-west")
+		b.AddTag("host", "server a")
+		b.AddFloatField("value", 1.5)
+		b.AddIntegerField("count", 2)
+		b.SetTime(ts)
+		if _, err := b.Point(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	build()
+
+	// The point and its buffer.
+	if n := testing.AllocsPerRun(100, build); n > 2 {
+		t.Errorf("unexpected allocations: %v", n)
+	}
+}
+
+func BenchmarkPointBuilder(b *testing.B) {
+	ts := time.Unix(0, 1000000000)
+	b.Run("NewPoint", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tags := map[string]string{"region": "us-west", "host": "serverA", "dc": "1"}
+			fields := map[string]interface{}{"value": 1.5, "count": int64(2)}
+			if _, err := models.NewPoint("cpu", models.NewTags(tags), fields, ts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("PointBuilder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			pb := models.GetPointBuilder()
+			pb.SetName("cpu")
+			pb.AddTag("region", "us-west")
+			pb.AddTag("host", "serverA")
+			pb.AddTag("dc", "1")
+			pb.AddFloatField("value", 1.5)
+			pb.AddIntegerField("count", 2)
+			pb.SetTime(ts)
+			if _, err := pb.Point(); err != nil {
+				b.Fatal(err)
+			}
+			models.PutPointBuilder(pb)
+		}
+	})
+}
@@ -57,6 +57,9 @@ var (
 	// ErrInvalidPoint is returned when a point cannot be parsed correctly.
 	ErrInvalidPoint = errors.New("point is invalid")
 
+	// errInvalidFloat is returned for a NaN or out of range float.
+	errInvalidFloat = errors.New("invalid float")
+
 	// ErrInvalidKevValuePairs is returned when the number of key, value pairs
 	// is odd, indicating a missing value.
 	ErrInvalidKevValuePairs = errors.New("key/value pairs is an odd length")
@@ -134,6 +137,12 @@ type Point interface {
 	// given unit.
 	PrecisionString(precision string) string
 
+	// AppendPrecisionString appends the line protocol of the point to buf,
+	// with its timestamp in the given precision. It returns ErrPrecisionLoss
+	// if the timestamp would be truncated, so parsing the result with the
+	// same precision always returns the same point.
+	AppendPrecisionString(buf []byte, precision string) ([]byte, error)
+
 	// RoundedString returns a string representation of the point. If there
 	// is a timestamp associated with the point, then it will be rounded to the
 	// given duration.
@@ -341,15 +350,124 @@ func ParseName(buf []byte) []byte {
 // NOTE: to minimize heap allocations, the returned Points will refer to subslices of buf.
 // This can have the unintended effect preventing buf from being garbage collected.
 func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision string) ([]Point, error) {
-	points := make([]Point, 0, bytes.Count(buf, []byte{'\n'})+1)
+	points, _, err := parsePoints(nil, buf, defaultTime, precision, false, false)
+	return points, err
+}
+
+// ParsePointsWithLines is similar to ParsePointsWithPrecision, but also
+// returns the line number of each point in buf, starting at 1. The error
+// is a *ParseError if some of the lines fail to parse.
+func ParsePointsWithLines(buf []byte, defaultTime time.Time, precision string) ([]Point, []int, error) {
+	return parsePoints(nil, buf, defaultTime, precision, true, false)
+}
+
+// ParsePointsStrict is similar to ParsePointsWithLines, but parses the
+// points in strict mode, see PointsParser.Strict.
+func ParsePointsStrict(buf []byte, defaultTime time.Time, precision string) ([]Point, []int, error) {
+	return parsePoints(nil, buf, defaultTime, precision, true, true)
+}
+
+// ParsePointsAppend is similar to ParsePointsWithPrecision, but appends the
+// points to dst, so that the caller can reuse the slice between calls. The
+// points are allocated together, once per call.
+//
+// NOTE: to minimize heap allocations, the returned Points will refer to subslices of buf.
+// This can have the unintended effect preventing buf from being garbage collected.
+func ParsePointsAppend(dst []Point, buf []byte, defaultTime time.Time, precision string) ([]Point, error) {
+	points, _, err := parsePoints(dst, buf, defaultTime, precision, false, false)
+	return points, err
+}
+
+func parsePoints(dst []Point, buf []byte, defaultTime time.Time, precision string, withLines, strict bool) ([]Point, []int, error) {
+	// A line holds at most one point.
+	n := bytes.Count(buf, []byte{'\n'}) + 1
+	if dst == nil {
+		dst = make([]Point, 0, n)
+	}
+	pts := make([]point, 0, n)
 	var (
-		pos    int
-		block  []byte
-		failed []string
+		lines  []int
+		failed []*LineError
 	)
-	for pos < len(buf) {
-		pos, block = scanLine(buf, pos)
-		pos++
+	if withLines {
+		lines = make([]int, 0, n)
+	}
+
+	p := PointsParser{Strict: strict}
+	p.Reset(buf, defaultTime, precision)
+	for p.Next() {
+		if p.err != nil {
+			failed = append(failed, p.err)
+			continue
+		}
+		pts = append(pts, p.pt)
+		dst = append(dst, &pts[len(pts)-1])
+		if withLines {
+			lines = append(lines, p.line)
+		}
+	}
+	if len(failed) > 0 {
+		return dst, lines, &ParseError{Lines: failed}
+	}
+	return dst, lines, nil
+}
+
+// PointsParser parses the points of line protocol one line at a time. The
+// point of each line is parsed in place, refers to subslices of the buffer
+// and is only valid until the next line is parsed, so that parsing doesn't
+// allocate. A parser can be reused with Reset.
+//
+// Points whose tags aren't sorted still allocate their sorted key.
+type PointsParser struct {
+	// Strict also rejects the lines with invalid UTF-8 and the keys with
+	// control characters. It is kept by Reset.
+	Strict bool
+
+	buf         []byte
+	pos         int
+	next        int // line of pos, starting at 1
+	defaultTime time.Time
+	precision   string
+
+	line int
+	pt   point
+	err  *LineError
+}
+
+// NewPointsParser returns a parser of the points of buf.
+func NewPointsParser(buf []byte, defaultTime time.Time, precision string) *PointsParser {
+	p := &PointsParser{}
+	p.Reset(buf, defaultTime, precision)
+	return p
+}
+
+// Reset resets the parser to parse the points of buf.
+func (p *PointsParser) Reset(buf []byte, defaultTime time.Time, precision string) {
+	*p = PointsParser{
+		Strict:      p.Strict,
+		buf:         buf,
+		next:        1,
+		defaultTime: defaultTime,
+		precision:   precision,
+	}
+}
+
+// Next parses the next line holding a point, skipping empty lines and
+// comments. It returns false once all the lines are parsed.
+func (p *PointsParser) Next() bool {
+	p.pt, p.err = point{}, nil
+	for p.pos < len(p.buf) {
+		offset := p.pos
+		var block []byte
+		p.pos, block = scanLine(p.buf, p.pos)
+		p.pos++
+
+		// A block may span several lines if a string field contains newlines.
+		p.line = p.next
+		p.next += bytes.Count(block, []byte{'\n'})
+		if p.pos <= len(p.buf) {
+			p.next++
+		}
 
 		if len(block) == 0 {
 			continue
@@ -372,46 +490,194 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 			block = block[:len(block)-1]
 		}
 
-		pt, err := parsePoint(block[start:], defaultTime, precision)
-		if err != nil {
-			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
-		} else {
-			points = append(points, pt)
+		line := block[start:]
+		offset += start
+		if err := parsePointTo(&p.pt, line, p.defaultTime, p.precision); err != nil {
+			p.err = &LineError{Line: p.line, Offset: offset, Code: parseErrorCode(err), Text: string(line), Err: err}
+		} else if p.Strict {
+			if i, code, err := validateStrict(line, len(p.pt.key)); err != nil {
+				p.err = &LineError{Line: p.line, Offset: offset + i, Code: code, Text: string(line), Err: err}
+			}
 		}
+		return true
+	}
+	return false
+}
 
+// Point returns the point of the current line, or nil if the line failed to
+// parse. The point is only valid until the next call to Next.
+func (p *PointsParser) Point() Point {
+	if p.err != nil {
+		return nil
 	}
-	if len(failed) > 0 {
-		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
+	return &p.pt
+}
+
+// Err returns the *LineError of the current line if it failed to parse.
+func (p *PointsParser) Err() error {
+	if p.err == nil {
+		return nil
+	}
+	return p.err
+}
+
+// Line returns the number of the current line of the buffer, starting at 1.
+func (p *PointsParser) Line() int {
+	return p.line
+}
+
+// LineError is the error of a line of line protocol that failed to parse.
+type LineError struct {
+	// Line is the number of the line, starting at 1.
+	Line int
+
+	// Offset is the offset in the buffer of the invalid byte, or of the
+	// start of the line if it isn't known.
+	Offset int
+
+	Code ParseErrorCode
+	Text string
+	Err  error
+}
+
+// ParseErrorCode is the machine-readable reason a line fails to parse.
+type ParseErrorCode string
+
+const (
+	// ParseErrorSyntax is a line that isn't valid line protocol.
+	ParseErrorSyntax ParseErrorCode = "syntax"
+
+	// ParseErrorInvalidFloat is a NaN or out of range float.
+	ParseErrorInvalidFloat ParseErrorCode = "invalid_float"
+
+	// ParseErrorTimeOutOfRange is a timestamp out of the range of
+	// MinNanoTime to MaxNanoTime.
+	ParseErrorTimeOutOfRange ParseErrorCode = "time_out_of_range"
+
+	// ParseErrorInvalidUTF8 is a line with invalid UTF-8, in strict mode.
+	ParseErrorInvalidUTF8 ParseErrorCode = "invalid_utf8"
+
+	// ParseErrorControlCharacter is a measurement, tag or field key with a
+	// control character, in strict mode.
+	ParseErrorControlCharacter ParseErrorCode = "control_character"
+)
+
+// parseErrorCode returns the code of an error of parsePointTo.
+func parseErrorCode(err error) ParseErrorCode {
+	switch {
+	case errors.Is(err, errInvalidFloat):
+		return ParseErrorInvalidFloat
+	case errors.Is(err, ErrTimeOutOfRange):
+		return ParseErrorTimeOutOfRange
+	default:
+		return ParseErrorSyntax
 	}
-	return points, nil
+}
+
+// validateStrict returns the offset in a parsed line of the first byte
+// rejected by strict mode, with the reason. keyLen is the length of the
+// measurement and tags of the line.
+func validateStrict(line []byte, keyLen int) (int, ParseErrorCode, error) {
+	if !utf8.Valid(line) {
+		for i := 0; i < len(line); {
+			r, n := utf8.DecodeRune(line[i:])
+			if r == utf8.RuneError && n <= 1 {
+				return i, ParseErrorInvalidUTF8, fmt.Errorf("invalid UTF-8 at byte %d", i)
+			}
+			i += n
+		}
+	}
+
+	// Sorting the tags doesn't change the length of the key, so the key is
+	// the start of the line either way.
+	for i := 0; i < keyLen; i++ {
+		if isControl(line[i]) {
+			return i, ParseErrorControlCharacter, fmt.Errorf("control character %#x in key", line[i])
+		}
+	}
+
+	// The field keys are the bytes of the fields outside of the values.
+	inKey, quoted := true, false
+	for i := skipWhitespace(line, keyLen); i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\':
+			// An escaped control character is still in the key.
+			if i++; inKey && !quoted && i < len(line) && isControl(line[i]) {
+				return i, ParseErrorControlCharacter, fmt.Errorf("control character %#x in field key", line[i])
+			}
+		case quoted:
+			quoted = c != '"'
+		case c == ' ':
+			return 0, "", nil // the timestamp
+		case inKey && c == '=':
+			inKey = false
+		case inKey && isControl(c):
+			return i, ParseErrorControlCharacter, fmt.Errorf("control character %#x in field key", c)
+		case !inKey && c == '"':
+			quoted = true
+		case !inKey && c == ',':
+			inKey = true
+		default:
+		}
+	}
+	return 0, "", nil
+}
+
+// isControl returns true if c is an ASCII control character.
+func isControl(c byte) bool {
+	return c < 0x20 || c == 0x7f
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("unable to parse '%s': %v", e.Text, e.Err)
+}
 
+// Unwrap returns the reason the line failed to parse.
+func (e *LineError) Unwrap() error {
+	return e.Err
 }
 
-func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, error) {
+// ParseError is returned when some of the lines of line protocol fail to
+// parse. The points of the other lines are still returned.
+type ParseError struct {
+	Lines []*LineError
+}
+
+func (e *ParseError) Error() string {
+	failed := make([]string, len(e.Lines))
+	for i, l := range e.Lines {
+		failed[i] = l.Error()
+	}
+	return strings.Join(failed, "\n")
+}
+
+// parsePointTo parses the point of buf into pt.
+func parsePointTo(pt *point, buf []byte, defaultTime time.Time, precision string) error {
 	// scan the first block which is measurement[,tag1=value1,tag2=value2...]
 	pos, key, err := scanKey(buf, 0)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// measurement name is required
 	if len(key) == 0 {
-		return nil, fmt.Errorf("missing measurement")
+		return fmt.Errorf("missing measurement")
 	}
 
 	if len(key) > MaxKeyLength {
-		return nil, fmt.Errorf("max key length exceeded: %v > %v", len(key), MaxKeyLength)
+		return fmt.Errorf("max key length exceeded: %v > %v", len(key), MaxKeyLength)
 	}
 
 	// scan the second block is which is field1=value1[,field2=value2,...]
 	pos, fields, err := scanFields(buf, pos)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// at least one field is required
 	if len(fields) == 0 {
-		return nil, fmt.Errorf("missing fields")
+		return fmt.Errorf("missing fields")
 	}
 
 	var maxKeyErr error
@@ -424,20 +690,20 @@ func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, err
 	})
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if maxKeyErr != nil {
-		return nil, maxKeyErr
+		return maxKeyErr
 	}
 
 	// scan the last block which is an optional integer timestamp
 	pos, ts, err := scanTime(buf, pos)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	pt := &point{
+	*pt = point{
 		key:    key,
 		fields: fields,
 		ts:     ts,
@@ -449,23 +715,23 @@ func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, err
 	} else {
 		ts, err := parseIntBytes(ts, 10, 64)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		pt.time, err = SafeCalcTime(ts, precision)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		// Determine if there are illegal non-whitespace characters after the
 		// timestamp block.
 		for pos < len(buf) {
 			if buf[pos] != ' ' {
-				return nil, ErrInvalidPoint
+				return ErrInvalidPoint
 			}
 			pos++
 		}
 	}
-	return pt, nil
+	return nil
 }
 
 // GetPrecisionMultiplier will return a multiplier for the precision specified.
@@ -957,7 +1223,7 @@ func scanNumber(buf []byte, i int) (int, error) {
 
 		// NaN is an unsupported value
 		if i+2 < len(buf) && (buf[i] == 'N' || buf[i] == 'n') {
-			return i, ErrInvalidNumber
+			return i, errInvalidFloat
 		}
 
 		if !isNumeric(buf[i]) {
@@ -1025,7 +1291,7 @@ func scanNumber(buf []byte, i int) (int, error) {
 		// Parse the float to check bounds if it's scientific or the number of digits could be larger than the max range
 		if scientific || len(buf[start:i]) >= maxFloat64Digits || len(buf[start:i]) >= minFloat64Digits {
 			if _, err := parseFloatBytes(buf[start:i], 10); err != nil {
-				return i, fmt.Errorf("invalid float")
+				return i, errInvalidFloat
 			}
 		}
 	}
@@ -1811,6 +2077,25 @@ func (p *point) PrecisionString(precision string) string {
 		p.UnixNano()/GetPrecisionMultiplier(precision))
 }
 
+// AppendPrecisionString appends the line protocol of the point to buf, with
+// its timestamp in the given precision, or returns ErrPrecisionLoss.
+func (p *point) AppendPrecisionString(buf []byte, precision string) ([]byte, error) {
+	if p.time.IsZero() {
+		return p.AppendString(buf), nil
+	}
+
+	n := len(buf)
+	buf = append(buf, p.key...)
+	buf = append(buf, ' ')
+	buf = append(buf, p.fields...)
+	buf = append(buf, ' ')
+	buf, err := AppendTimestamp(buf, p.UnixNano(), precision)
+	if err != nil {
+		return buf[:n], err
+	}
+	return buf, nil
+}
+
 // RoundedString returns a string representation of the point. If there
 // is a timestamp associated with the point, then it will be rounded to the
 // given duration.
@@ -251,6 +251,23 @@ func BenchmarkParsePointNoTags5000(b *testing.B) {
 	}
 }
 
+func BenchmarkPointsParserNoTags5000(b *testing.B) {
+	var batch [5000]string
+	for i := 0; i < len(batch); i++ {
+		batch[i] = `cpu value=1i 1000000000`
+	}
+	lines := []byte(strings.Join(batch[:], "\n"))
+	var p models.PointsParser
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Reset(lines, time.Time{}, "n")
+		for p.Next() {
+		}
+		b.SetBytes(int64(len(lines)))
+	}
+}
+
 func BenchmarkParsePointNoTags(b *testing.B) {
 	line := `cpu value=1i 1000000000`
 	for i := 0; i < b.N; i++ {
@@ -2085,6 +2102,90 @@ func TestPrecisionString(t *testing.T) {
 	}
 }
 
+func TestAppendPrecisionString(t *testing.T) {
+	tags := models.NewTags(map[string]string{"host key": "a,b", "k=v": `c\d`, "region": "\u00e9t\u00e9"})
+	fields := models.Fields{
+		"value":     1.25,
+		"big":       math.MaxFloat64,
+		"int":       int64(math.MinInt64),
+		"field key": `say "hi" \ bye`,
+		"ok":        true,
+	}
+
+	precisions := []string{"", "n", "ns", "u", "ms", "s", "m", "h"}
+	for _, ts := range []int64{
+		models.MinNanoTime,
+		models.MaxNanoTime,
+		0,
+		-int64(time.Hour),
+		int64(time.Hour) * 24 * 365 * 30,
+		1234567890123456789,
+	} {
+		for _, name := range []string{"cpu", "cp u,x=y", `c\pu`} {
+			pt := models.MustNewPoint(name, tags, fields, time.Unix(0, ts))
+			for _, precision := range precisions {
+				buf, err := pt.AppendPrecisionString([]byte("prefix "), precision)
+				if ts%models.GetPrecisionMultiplier(precision) != 0 {
+					if err != models.ErrPrecisionLoss {
+						t.Fatalf("%d %q: expected precision loss: %v", ts, precision, err)
+					} else if string(buf) != "prefix " {
+						t.Fatalf("%d %q: unexpected buffer: %q", ts, precision, buf)
+					}
+					continue
+				} else if err != nil {
+					t.Fatalf("%d %q: unexpected error: %s", ts, precision, err)
+				}
+
+				// The point parsed with the same precision is the same point.
+				points, err := models.ParsePointsWithPrecision(buf[len("prefix "):], time.Now(), precision)
+				if err != nil {
+					t.Fatalf("%d %q: unexpected parse error: %s\n%s", ts, precision, err, buf)
+				} else if len(points) != 1 {
+					t.Fatalf("%d %q: unexpected points: %v", ts, precision, points)
+				}
+				if got, exp := points[0].String(), pt.String(); got != exp {
+					t.Fatalf("%d %q: round trip mismatch:\ngot  %s\nexp  %s", ts, precision, got, exp)
+				}
+				got, err := points[0].Fields()
+				if err != nil {
+					t.Fatal(err)
+				} else if !reflect.DeepEqual(got, fields) {
+					t.Fatalf("%d %q: unexpected fields: %v", ts, precision, got)
+				}
+			}
+		}
+	}
+
+	pt := models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0}, time.Unix(0, 0))
+	if _, err := pt.AppendPrecisionString(nil, "us"); err == nil || err.Error() != `invalid precision "us"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf, err := pt.AppendPrecisionString(nil, "h"); err != nil || string(buf) != "cpu value=1 0" {
+		t.Fatalf("unexpected result: %q %v", buf, err)
+	}
+}
+
+func TestLosslessPrecision(t *testing.T) {
+	pt := func(d time.Duration) models.Point {
+		return models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0}, time.Unix(0, int64(d)))
+	}
+	for _, tt := range []struct {
+		points []models.Point
+		exp    string
+	}{
+		{points: []models.Point{pt(0), pt(3 * time.Hour), pt(-time.Hour)}, exp: "h"},
+		{points: []models.Point{pt(time.Hour), pt(90 * time.Second)}, exp: "s"},
+		{points: []models.Point{pt(time.Minute), pt(-time.Millisecond)}, exp: "ms"},
+		{points: []models.Point{pt(time.Hour), pt(time.Microsecond)}, exp: "u"},
+		{points: []models.Point{pt(time.Hour), pt(time.Nanosecond), pt(time.Hour)}, exp: "n"},
+		{points: []models.Point{pt(time.Duration(models.MaxNanoTime))}, exp: "n"},
+	} {
+		if got := models.LosslessPrecision(tt.points); got != tt.exp {
+			t.Errorf("unexpected precision of %v: got %q, exp %q", tt.points, got, tt.exp)
+		}
+	}
+}
+
 func TestRoundedString(t *testing.T) {
 	tags := map[string]interface{}{"value": float64(1)}
 	tm, _ := time.Parse(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z")
@@ -2141,6 +2242,139 @@ func TestRoundedString(t *testing.T) {
 	}
 }
 
+func TestParsePointsWithLines(t *testing.T) {
+	batch := "# comment\n" +
+		"cpu value=1 1\n" +
+		"\n" +
+		"cpu value=\"a\nb\" 2\n" +
+		"cpu value= 3\n" +
+		"cpu value=4 4\n" +
+		"cpu,host 5"
+	pts, lines, err := models.ParsePointsWithLines([]byte(batch), time.Now().UTC(), "n")
+
+	var perr *models.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(perr.Lines) != 2 || perr.Lines[0].Line != 6 || perr.Lines[0].Text != "cpu value= 3" || perr.Lines[1].Line != 8 {
+		t.Fatalf("unexpected line errors: %+v", perr.Lines)
+	} else if exp := "unable to parse 'cpu value= 3': missing field value\nunable to parse 'cpu,host 5': missing tag value"; err.Error() != exp {
+		t.Fatalf("unexpected error message:\n got %s\n exp %s", err, exp)
+	}
+
+	if len(pts) != 3 || !reflect.DeepEqual(lines, []int{2, 4, 7}) {
+		t.Fatalf("unexpected points: %v %v", pts, lines)
+	} else if pts[2].Time().UnixNano() != 4 {
+		t.Fatalf("unexpected point: %v", pts[2])
+	}
+}
+
+func TestPointsParser(t *testing.T) {
+	batch := "# comment\n" +
+		"cpu value=1 1\n" +
+		"\n" +
+		"cpu value=\"a\nb\" 2\n" +
+		"cpu value= 3\n" +
+		"cpu,host=a,region=b value=4 4\n" +
+		"cpu,host 5"
+	p := models.NewPointsParser([]byte(batch), time.Now().UTC(), "n")
+
+	var lines, failed []int
+	var times []int64
+	for p.Next() {
+		if err := p.Err(); err != nil {
+			var lerr *models.LineError
+			if !errors.As(err, &lerr) || lerr.Line != p.Line() || p.Point() != nil {
+				t.Fatalf("unexpected error of line %d: %v", p.Line(), err)
+			}
+			failed = append(failed, p.Line())
+			continue
+		}
+		lines = append(lines, p.Line())
+		times = append(times, p.Point().UnixNano())
+	}
+	if !reflect.DeepEqual(lines, []int{2, 4, 7}) || !reflect.DeepEqual(times, []int64{1, 2, 4}) || !reflect.DeepEqual(failed, []int{6, 8}) {
+		t.Fatalf("unexpected lines %v, times %v and failed lines %v", lines, times, failed)
+	}
+
+	// Parsing sorted points doesn't allocate.
+	buf := []byte(strings.Repeat("cpu,host=a,region=b value=1,count=2i 1\n", 100))
+	if n := testing.AllocsPerRun(10, func() {
+		p.Reset(buf, time.Time{}, "n")
+		for p.Next() {
+			if err := p.Err(); err != nil {
+				t.Fatal(err)
+			} else if len(p.Point().Key()) == 0 {
+				t.Fatal("missing key")
+			}
+		}
+	}); n != 0 {
+		t.Fatalf("got %v allocations, expected none", n)
+	}
+}
+
+func TestParsePointsStrict(t *testing.T) {
+	for _, tt := range []struct {
+		line   string
+		code   models.ParseErrorCode
+		offset int
+	}{
+		{line: `cpu,host=a value=1 1`},
+		{line: "cpu value=\"a\tb\" 1"},
+		{line: "c\x01pu value=1 1", code: models.ParseErrorControlCharacter, offset: 1},
+		{line: "cpu,host=a\x7f value=1 1", code: models.ParseErrorControlCharacter, offset: 10},
+		{line: "cpu,b=1,a=\t value=1 1", code: models.ParseErrorControlCharacter, offset: 10},
+		{line: "cpu a=1,v\x00alue=1 1", code: models.ParseErrorControlCharacter, offset: 9},
+		{line: "cpu v\\\x00=1 1", code: models.ParseErrorControlCharacter, offset: 6},
+		{line: "cpu value=\"\xff\" 1", code: models.ParseErrorInvalidUTF8, offset: 11},
+		{line: "cpu value=NaN 1", code: models.ParseErrorInvalidFloat},
+		{line: "cpu value=1e400 1", code: models.ParseErrorInvalidFloat},
+		{line: "cpu value=1 9223372036854775807", code: models.ParseErrorTimeOutOfRange},
+		{line: "cpu value=", code: models.ParseErrorSyntax},
+	} {
+		_, _, err := models.ParsePointsStrict([]byte("\n"+tt.line), time.Now().UTC(), "n")
+		var perr *models.ParseError
+		if tt.code == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.line, err)
+			}
+			continue
+		} else if !errors.As(err, &perr) || len(perr.Lines) != 1 {
+			t.Errorf("%q: unexpected error: %v", tt.line, err)
+			continue
+		}
+		// The line starts at offset 1.
+		if l := perr.Lines[0]; l.Line != 2 || l.Code != tt.code || (tt.offset > 0 && l.Offset != tt.offset+1) {
+			t.Errorf("%q: got line %d, code %s and offset %d, expected code %s and offset %d", tt.line, l.Line, l.Code, l.Offset, tt.code, tt.offset+1)
+		}
+	}
+
+	// Control characters and invalid UTF-8 are only rejected in strict mode.
+	if _, err := models.ParsePointsString("c\x01pu value=\"\xff\" 1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParsePointsAppend(t *testing.T) {
+	dst := make([]models.Point, 0, 10)
+	pts, err := models.ParsePointsAppend(dst, []byte("cpu value=1 1\ncpu,host=b,dc=a value=2 2\ncpu value=\n"), time.Now().UTC(), "n")
+	var perr *models.ParseError
+	if !errors.As(err, &perr) || len(perr.Lines) != 1 || perr.Lines[0].Line != 3 {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(pts) != 2 || &pts[0] != &dst[:1][0] {
+		t.Fatalf("points weren't appended to dst: %v", pts)
+	} else if exp := "cpu,dc=a,host=b value=2 2"; pts[1].String() != exp {
+		t.Fatalf("unexpected point:\n got %s\n exp %s", pts[1].String(), exp)
+	}
+
+	// The points are distinct once parsed.
+	pts, err = models.ParsePointsAppend(pts, []byte("mem free=3i 3"), time.Now().UTC(), "n")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(pts) != 3 || pts[0].UnixNano() != 1 || pts[1].UnixNano() != 2 || string(pts[2].Name()) != "mem" {
+		t.Fatalf("unexpected points: %v", pts)
+	}
+}
+
 func TestParsePointsStringWithExtraBuffer(t *testing.T) {
 	b := make([]byte, 70*5000)
 	buf := bytes.NewBuffer(b)
@@ -4,8 +4,10 @@ package models
 // specific time range.
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 )
 
@@ -39,8 +41,56 @@ var (
 
 	// ErrTimeOutOfRange gets returned when time is out of the representable range using int64 nanoseconds since the epoch.
 	ErrTimeOutOfRange = fmt.Errorf("time outside range %d - %d", MinNanoTime, MaxNanoTime)
+
+	// ErrPrecisionLoss gets returned when a timestamp is not a whole number of units of a
+	// precision, so it would be truncated in that precision.
+	ErrPrecisionLoss = errors.New("timestamp is not a whole number of units of the precision")
+
+	// precisions are the precisions of line protocol, coarsest first.
+	precisions = []string{"h", "m", "s", "ms", "u", "n"}
 )
 
+// ValidPrecision returns true if precision is a precision of line protocol. The empty
+// precision is nanoseconds.
+func ValidPrecision(precision string) bool {
+	switch precision {
+	case "", "n", "ns", "u", "ms", "s", "m", "h":
+		return true
+	default:
+		return false
+	}
+}
+
+// AppendTimestamp appends timestamp t, in nanoseconds, in the given precision to buf. It
+// returns ErrPrecisionLoss rather than truncate t, so parsing the result with the same
+// precision always returns t.
+func AppendTimestamp(buf []byte, t int64, precision string) ([]byte, error) {
+	if !ValidPrecision(precision) {
+		return buf, fmt.Errorf("invalid precision %q", precision)
+	}
+	mult := GetPrecisionMultiplier(precision)
+	if t%mult != 0 {
+		return buf, ErrPrecisionLoss
+	}
+	return strconv.AppendInt(buf, t/mult, 10), nil
+}
+
+// LosslessPrecision returns the coarsest precision that represents all the timestamps
+// of points exactly, to encode them in as few bytes as possible.
+func LosslessPrecision(points []Point) string {
+	i := 0
+	for _, p := range points {
+		t := p.UnixNano()
+		for t%GetPrecisionMultiplier(precisions[i]) != 0 {
+			i++
+		}
+		if precisions[i] == "n" {
+			break
+		}
+	}
+	return precisions[i]
+}
+
 // SafeCalcTime safely calculates the time given. Will return error if the time is outside the
 // supported range.
 func SafeCalcTime(timestamp int64, precision string) (time.Time, error) {
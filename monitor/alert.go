@@ -0,0 +1,252 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/influxdata/influxdb/logger"
+	"go.uber.org/zap"
+)
+
+// alertWebhookTimeout is the maximum time a post of an alert event may take.
+const alertWebhookTimeout = 10 * time.Second
+
+// The states of an alert.
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// AlertEvent is an alert that fires or resolves. It's posted as JSON to the
+// webhook of its rule.
+type AlertEvent struct {
+	Rule  string `json:"rule"`
+	State string `json:"state"`
+
+	Host      string            `json:"host"`
+	Statistic string            `json:"statistic"`
+	Field     string            `json:"field"`
+	Tags      map[string]string `json:"tags,omitempty"`
+
+	Value     float64 `json:"value"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+
+	Time time.Time `json:"time"`
+}
+
+// alertOperator returns true if a value crosses a threshold.
+type alertOperator func(value, threshold float64) bool
+
+// parseAlertOperator returns the operator of a rule. An empty operator is
+// ">".
+func parseAlertOperator(s string) (alertOperator, error) {
+	switch s {
+	case "", ">":
+		return func(v, t float64) bool { return v > t }, nil
+	case ">=":
+		return func(v, t float64) bool { return v >= t }, nil
+	case "<":
+		return func(v, t float64) bool { return v < t }, nil
+	case "<=":
+		return func(v, t float64) bool { return v <= t }, nil
+	default:
+		return nil, fmt.Errorf("invalid operator %q (use >, >=, < or <=)", s)
+	}
+}
+
+type alertRule struct {
+	AlertRule
+	crosses alertOperator
+}
+
+// matches returns true if the rule applies to a statistic.
+func (r *alertRule) matches(s *Statistic) bool {
+	if s.Name != r.Statistic {
+		return false
+	}
+	for k, v := range r.Tags {
+		if s.Tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// alerter evaluates the alert rules on the statistics. An alert is tracked
+// for each statistic a rule applies to, so a rule on the series of the
+// databases fires for each database that has too many of them.
+type alerter struct {
+	interval time.Duration
+	webhook  string
+	rules    []alertRule
+	client   *http.Client
+
+	// firing holds the alerts that are firing by rule and by tags of their
+	// statistic.
+	firing map[string]map[string]struct{}
+}
+
+func newAlerter(c Config) *alerter {
+	a := &alerter{
+		interval: time.Duration(c.AlertInterval),
+		webhook:  c.AlertWebhook,
+		client:   &http.Client{Timeout: alertWebhookTimeout},
+		firing:   make(map[string]map[string]struct{}),
+	}
+	for _, r := range c.Alerts {
+		op, _ := parseAlertOperator(r.Operator)
+		if r.Operator == "" {
+			r.Operator = ">"
+		}
+		a.rules = append(a.rules, alertRule{AlertRule: r, crosses: op})
+	}
+	return a
+}
+
+// evaluateAlerts evaluates the alert rules on every interval.
+func (m *Monitor) evaluateAlerts() {
+	defer m.wg.Done()
+	m.Logger.Info("Evaluating alert rules", zap.Int("rules", len(m.alerts.rules)), logger.DurationLiteral("interval", m.alerts.interval))
+
+	tick := time.NewTicker(m.alerts.interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case now := <-tick.C:
+			stats, err := m.Statistics(nil)
+			if err != nil {
+				m.Logger.Info("Failed to retrieve registered statistics", zap.Error(err))
+				continue
+			}
+			for _, e := range m.alerts.evaluate(stats, now) {
+				m.notifyAlert(e)
+			}
+		case <-m.done:
+			m.Logger.Info("Terminating evaluation of alert rules")
+			return
+		}
+	}
+}
+
+// evaluate returns the events of the alerts that fire or resolve with the
+// statistics gathered at now. The alerts of the statistics that are gone are
+// forgotten without an event.
+func (a *alerter) evaluate(stats []*Statistic, now time.Time) []AlertEvent {
+	hostname, _ := os.Hostname()
+
+	var events []AlertEvent
+	for i := range a.rules {
+		r := &a.rules[i]
+		firing := a.firing[r.Name]
+		seen := make(map[string]struct{})
+		for _, s := range stats {
+			if !r.matches(s) {
+				continue
+			}
+			value, ok := statisticValue(s.Values[r.Field])
+			if !ok {
+				continue
+			}
+
+			key := tagsKey(s.Tags)
+			seen[key] = struct{}{}
+			_, wasFiring := firing[key]
+			isFiring := r.crosses(value, r.Threshold)
+			if isFiring == wasFiring {
+				continue
+			}
+
+			e := AlertEvent{
+				Rule:      r.Name,
+				State:     AlertFiring,
+				Host:      hostname,
+				Statistic: s.Name,
+				Field:     r.Field,
+				Tags:      s.Tags,
+				Value:     value,
+				Operator:  r.Operator,
+				Threshold: r.Threshold,
+				Time:      now.UTC(),
+			}
+			if isFiring {
+				if firing == nil {
+					firing = make(map[string]struct{})
+					a.firing[r.Name] = firing
+				}
+				firing[key] = struct{}{}
+			} else {
+				e.State = AlertResolved
+				delete(firing, key)
+			}
+			events = append(events, e)
+		}
+
+		for key := range firing {
+			if _, ok := seen[key]; !ok {
+				delete(firing, key)
+			}
+		}
+	}
+	return events
+}
+
+// notifyAlert logs an alert event and posts it to the webhook of its rule.
+func (m *Monitor) notifyAlert(e AlertEvent) {
+	fields := []zap.Field{
+		zap.String("alert", e.Rule),
+		zap.String("state", e.State),
+		zap.String("statistic", e.Statistic),
+		zap.String("field", e.Field),
+		zap.Any("tags", e.Tags),
+		zap.Float64("value", e.Value),
+		zap.String("operator", e.Operator),
+		zap.Float64("threshold", e.Threshold),
+	}
+	if e.State == AlertFiring {
+		m.Logger.Warn("Alert firing", fields...)
+	} else {
+		m.Logger.Info("Alert resolved", fields...)
+	}
+
+	webhook := m.alerts.webhook
+	for _, r := range m.alerts.rules {
+		if r.Name == e.Rule && r.Webhook != "" {
+			webhook = r.Webhook
+		}
+	}
+	if webhook == "" {
+		return
+	}
+	if err := m.alerts.post(webhook, e); err != nil {
+		m.Logger.Info("Failed to post alert", zap.String("alert", e.Rule), zap.Error(err))
+	}
+}
+
+// post posts an alert event to a webhook.
+func (a *alerter) post(webhook string, e AlertEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,78 @@
+package monitor_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/monitor"
+	"github.com/influxdata/influxdb/toml"
+)
+
+// Ensure an alert fires once a value crosses the threshold of its rule and
+// resolves once it's back, for each statistic the rule applies to.
+func TestMonitor_Alerts(t *testing.T) {
+	var series int64 = 100
+	reporter := ReporterFunc(func(tags map[string]string) []models.Statistic {
+		return []models.Statistic{
+			{Name: "database", Tags: map[string]string{"database": "db0"}, Values: map[string]interface{}{"numSeries": atomic.LoadInt64(&series)}},
+			{Name: "database", Tags: map[string]string{"database": "db1"}, Values: map[string]interface{}{"numSeries": int64(1)}},
+		}
+	})
+
+	events := make(chan monitor.AlertEvent, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e monitor.AlertEvent
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		events <- e
+	}))
+	defer srv.Close()
+
+	config := monitor.NewConfig()
+	config.StoreEnabled = false
+	config.AlertInterval = toml.Duration(10 * time.Millisecond)
+	config.AlertWebhook = srv.URL
+	config.Alerts = []monitor.AlertRule{{Name: "series", Statistic: "database", Field: "numSeries", Threshold: 1000}}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	s := monitor.New(reporter, config)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	next := func() monitor.AlertEvent {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an alert")
+		}
+		return monitor.AlertEvent{}
+	}
+
+	atomic.StoreInt64(&series, 2000)
+	if e := next(); e.Rule != "series" || e.State != monitor.AlertFiring || e.Tags["database"] != "db0" ||
+		e.Value != 2000 || e.Operator != ">" || e.Threshold != 1000 {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	atomic.StoreInt64(&series, 500)
+	if e := next(); e.Rule != "series" || e.State != monitor.AlertResolved || e.Tags["database"] != "db0" || e.Value != 500 {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event: %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -2,6 +2,8 @@ package monitor
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
@@ -18,6 +20,26 @@ const (
 
 	// DefaultStoreInterval is the period between storing gathered information.
 	DefaultStoreInterval = 10 * time.Second
+
+	// DefaultStoreRetention is the duration of the retention policy of the
+	// gathered information.
+	DefaultStoreRetention = MonitorRetentionPolicyDuration
+
+	// DefaultStoreDownsampleRetention is the duration of the retention
+	// policy of the downsampled information.
+	DefaultStoreDownsampleRetention = 90 * 24 * time.Hour
+
+	// DefaultOTLPInterval is the period between exports of gathered
+	// information to an OpenTelemetry collector.
+	DefaultOTLPInterval = 10 * time.Second
+
+	// DefaultOTLPTimeout is the maximum time an export to an OpenTelemetry
+	// collector may take.
+	DefaultOTLPTimeout = 10 * time.Second
+
+	// DefaultAlertInterval is the period between evaluations of the alert
+	// rules.
+	DefaultAlertInterval = 10 * time.Second
 )
 
 // Config represents the configuration for the monitor service.
@@ -25,14 +47,84 @@ type Config struct {
 	StoreEnabled  bool          `toml:"store-enabled"`
 	StoreDatabase string        `toml:"store-database"`
 	StoreInterval toml.Duration `toml:"store-interval"`
+
+	// StoreRetention is the duration of the monitor retention policy, which
+	// is updated if it changes. Zero keeps the information forever.
+	StoreRetention toml.Duration `toml:"store-retention"`
+
+	// StoreIntervals overrides the interval of the statistics of some
+	// modules, like "shard", to record them less often. The intervals must
+	// be multiples of the store interval.
+	StoreIntervals map[string]toml.Duration `toml:"store-intervals"`
+
+	// StoreDownsampleInterval enables the downsampling of the gathered
+	// information into the monitor_downsampled retention policy, with a
+	// continuous query that records the mean of the values over each
+	// interval. Zero disables the downsampling.
+	StoreDownsampleInterval  toml.Duration `toml:"store-downsample-interval"`
+	StoreDownsampleRetention toml.Duration `toml:"store-downsample-retention"`
+
+	// OTLPEnabled pushes the statistics to an OpenTelemetry collector with
+	// OTLP over HTTP, for the nodes that can't be scraped.
+	OTLPEnabled bool `toml:"otlp-enabled"`
+
+	// OTLPEndpoint is the URL the statistics are pushed to, like
+	// "https://collector:4318/v1/metrics".
+	OTLPEndpoint string `toml:"otlp-endpoint"`
+
+	// OTLPHeaders are the headers of the requests to the collector, like
+	// the credentials it requires.
+	OTLPHeaders map[string]string `toml:"otlp-headers"`
+
+	OTLPInterval toml.Duration `toml:"otlp-interval"`
+	OTLPTimeout  toml.Duration `toml:"otlp-timeout"`
+
+	// AlertInterval is the period between evaluations of the alert rules.
+	AlertInterval toml.Duration `toml:"alert-interval"`
+
+	// AlertWebhook is the URL the alert events are posted to, unless the
+	// rule of an event has a webhook of its own. The events are only logged
+	// if it is empty.
+	AlertWebhook string      `toml:"alert-webhook"`
+	Alerts       []AlertRule `toml:"alert"`
+}
+
+// AlertRule represents a threshold on a value of the statistics. An alert
+// fires once the value of a statistic crosses the threshold and it resolves
+// once the value is back.
+type AlertRule struct {
+	Name string `toml:"name"`
+
+	// Statistic and Field are the module and the key of the value, like
+	// "database" and "numSeries".
+	Statistic string `toml:"statistic"`
+	Field     string `toml:"field"`
+
+	// Tags restrict the rule to the statistics that have them, like a
+	// database. The statistics with other tags are alerted on separately.
+	Tags map[string]string `toml:"tags"`
+
+	// Operator compares the value with the threshold: ">", ">=", "<" or
+	// "<=". It defaults to ">".
+	Operator  string  `toml:"operator"`
+	Threshold float64 `toml:"threshold"`
+
+	// Webhook overrides the webhook of the monitor for the events of the
+	// rule.
+	Webhook string `toml:"webhook"`
 }
 
 // NewConfig returns an instance of Config with defaults.
 func NewConfig() Config {
 	return Config{
-		StoreEnabled:  DefaultStoreEnabled,
-		StoreDatabase: DefaultStoreDatabase,
-		StoreInterval: toml.Duration(DefaultStoreInterval),
+		StoreEnabled:             DefaultStoreEnabled,
+		StoreDatabase:            DefaultStoreDatabase,
+		StoreInterval:            toml.Duration(DefaultStoreInterval),
+		StoreRetention:           toml.Duration(DefaultStoreRetention),
+		StoreDownsampleRetention: toml.Duration(DefaultStoreDownsampleRetention),
+		OTLPInterval:             toml.Duration(DefaultOTLPInterval),
+		OTLPTimeout:              toml.Duration(DefaultOTLPTimeout),
+		AlertInterval:            toml.Duration(DefaultAlertInterval),
 	}
 }
 
@@ -44,20 +136,108 @@ func (c Config) Validate() error {
 	if c.StoreDatabase == "" {
 		return errors.New("monitor store database name must not be empty")
 	}
+	if err := c.validateStoreRetention(); err != nil {
+		return err
+	}
+	for name, d := range c.StoreIntervals {
+		if d <= 0 || d%c.StoreInterval != 0 {
+			return fmt.Errorf("monitor store interval of %s must be a multiple of the store interval: %s", name, d)
+		}
+	}
+	if c.OTLPEnabled {
+		if c.OTLPInterval <= 0 {
+			return errors.New("monitor otlp interval must be positive")
+		}
+		if c.OTLPTimeout < 0 {
+			return errors.New("monitor otlp timeout must not be negative")
+		}
+		if u, err := url.Parse(c.OTLPEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("monitor otlp endpoint must be an http or https URL")
+		}
+	}
+	return c.validateAlerts()
+}
+
+func (c Config) validateStoreRetention() error {
+	// The retention policies are at least an hour long.
+	if c.StoreRetention != 0 && time.Duration(c.StoreRetention) < time.Hour {
+		return errors.New("monitor store retention must be at least 1h or 0")
+	}
+	if c.StoreDownsampleInterval < 0 {
+		return errors.New("monitor store downsample interval must not be negative")
+	} else if c.StoreDownsampleInterval == 0 {
+		return nil
+	}
+	if c.StoreDownsampleInterval <= c.StoreInterval {
+		return errors.New("monitor store downsample interval must be greater than the store interval")
+	}
+	if c.StoreDownsampleRetention != 0 && time.Duration(c.StoreDownsampleRetention) < time.Hour {
+		return errors.New("monitor store downsample retention must be at least 1h or 0")
+	}
+	return nil
+}
+
+func (c Config) validateAlerts() error {
+	if len(c.Alerts) == 0 {
+		return nil
+	}
+	if c.AlertInterval <= 0 {
+		return errors.New("monitor alert interval must be positive")
+	}
+	if c.AlertWebhook != "" && !validWebhook(c.AlertWebhook) {
+		return fmt.Errorf("monitor alert webhook must be an http or https URL: %q", c.AlertWebhook)
+	}
+
+	names := make(map[string]struct{}, len(c.Alerts))
+	for _, r := range c.Alerts {
+		if r.Name == "" {
+			return errors.New("monitor alert name must be set")
+		} else if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("duplicate monitor alert name: %s", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		if r.Statistic == "" || r.Field == "" {
+			return fmt.Errorf("monitor alert %s: statistic and field must be set", r.Name)
+		} else if _, err := parseAlertOperator(r.Operator); err != nil {
+			return fmt.Errorf("monitor alert %s: %s", r.Name, err)
+		} else if r.Webhook != "" && !validWebhook(r.Webhook) {
+			return fmt.Errorf("monitor alert %s: webhook must be an http or https URL: %q", r.Name, r.Webhook)
+		}
+	}
 	return nil
 }
 
+func validWebhook(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 // Diagnostics returns a diagnostics representation of a subset of the Config.
 func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
-	if !c.StoreEnabled {
-		return diagnostics.RowFromMap(map[string]interface{}{
-			"store-enabled": false,
-		}), nil
+	m := map[string]interface{}{
+		"store-enabled": false,
+		"otlp-enabled":  false,
 	}
-
-	return diagnostics.RowFromMap(map[string]interface{}{
-		"store-enabled":  true,
-		"store-database": c.StoreDatabase,
-		"store-interval": c.StoreInterval,
-	}), nil
+	if c.StoreEnabled {
+		m["store-enabled"] = true
+		m["store-database"] = c.StoreDatabase
+		m["store-interval"] = c.StoreInterval
+		m["store-retention"] = c.StoreRetention
+		if c.StoreDownsampleInterval > 0 {
+			m["store-downsample-interval"] = c.StoreDownsampleInterval
+			m["store-downsample-retention"] = c.StoreDownsampleRetention
+		}
+	}
+	if c.OTLPEnabled {
+		m["otlp-enabled"] = true
+		m["otlp-endpoint"] = c.OTLPEndpoint
+		m["otlp-interval"] = c.OTLPInterval
+		m["otlp-timeout"] = c.OTLPTimeout
+	}
+	if len(c.Alerts) > 0 {
+		m["alert-interval"] = c.AlertInterval
+		m["alert-rules"] = len(c.Alerts)
+	}
+	return diagnostics.RowFromMap(m), nil
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/influxdata/influxdb/monitor"
+	itoml "github.com/influxdata/influxdb/toml"
 )
 
 func TestConfig_Parse(t *testing.T) {
@@ -49,4 +50,59 @@ func TestConfig_Validate(t *testing.T) {
 	if err := c.Validate(); err == nil {
 		t.Fatalf("unexpected successful validation for %#v", c)
 	}
+
+	// An OTLP export without a valid endpoint is invalid.
+	c = monitor.NewConfig()
+	c.OTLPEnabled = true
+	if err := c.Validate(); err == nil {
+		t.Fatalf("unexpected successful validation for %#v", c)
+	}
+	c.OTLPEndpoint = "collector:4318"
+	if err := c.Validate(); err == nil {
+		t.Fatalf("unexpected successful validation for %#v", c)
+	}
+	c.OTLPEndpoint = "http://collector:4318/v1/metrics"
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	// An alert rule must have a name, a statistic, a field and a valid
+	// operator.
+	for _, r := range []monitor.AlertRule{
+		{Statistic: "database", Field: "numSeries"},
+		{Name: "series", Field: "numSeries"},
+		{Name: "series", Statistic: "database", Field: "numSeries", Operator: "=="},
+		{Name: "series", Statistic: "database", Field: "numSeries", Webhook: "localhost"},
+	} {
+		c = monitor.NewConfig()
+		c.Alerts = []monitor.AlertRule{r}
+		if err := c.Validate(); err == nil {
+			t.Fatalf("unexpected successful validation for %#v", c)
+		}
+	}
+
+	// The intervals of the modules must be multiples of the store interval.
+	c = monitor.NewConfig()
+	c.StoreIntervals = map[string]itoml.Duration{"shard": itoml.Duration(15 * time.Second)}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("unexpected successful validation for %#v", c)
+	}
+	c.StoreIntervals = map[string]itoml.Duration{"shard": itoml.Duration(time.Minute)}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	// A retention policy is at least an hour long.
+	c = monitor.NewConfig()
+	c.StoreRetention = itoml.Duration(time.Minute)
+	if err := c.Validate(); err == nil {
+		t.Fatalf("unexpected successful validation for %#v", c)
+	}
+
+	// The downsample interval must exceed the store interval.
+	c = monitor.NewConfig()
+	c.StoreDownsampleInterval = c.StoreInterval
+	if err := c.Validate(); err == nil {
+		t.Fatalf("unexpected successful validation for %#v", c)
+	}
 }
@@ -0,0 +1,127 @@
+package monitor
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// gaugeStatistics are the statistic keys whose values go up and down. The
+// keys of the modules that end with "Active" or "Queue" are gauges too. The
+// other values are counters.
+var gaugeStatistics = map[string]bool{
+	"activeWrites":            true,
+	"cacheAgeMs":              true,
+	"cacheBytes":              true,
+	"cachedBytes":             true,
+	"compactionsQueued":       true,
+	"connsActive":             true,
+	"currentSegmentDiskBytes": true,
+	"diskBytes":               true,
+	"entries":                 true,
+	"freeBytes":               true,
+	"lagNs":                   true,
+	"memBytes":                true,
+	"memUsage":                true,
+	"numFiles":                true,
+	"numMeasurements":         true,
+	"numSeries":               true,
+	"numShards":               true,
+	"oldSegmentsDiskBytes":    true,
+	"queriesActive":           true,
+	"queriesQueued":           true,
+	"queriesRunning":          true,
+	"queueBytes":              true,
+	"reqActive":               true,
+	"tlConnsActive":           true,
+	"totalBytes":              true,
+	"tsmBytes":                true,
+	"walBytes":                true,
+	"writeReqActive":          true,
+}
+
+// runtimeCounters are the values of the "runtime" statistics that
+// are counters. Its other values are gauges.
+var runtimeCounters = map[string]bool{
+	"TotalAlloc":   true,
+	"Lookups":      true,
+	"Mallocs":      true,
+	"Frees":        true,
+	"PauseTotalNs": true,
+	"NumGC":        true,
+}
+
+// isGaugeStatistic returns true if the value of a statistic goes up and
+// down.
+func isGaugeStatistic(name, key string) bool {
+	if name == "runtime" {
+		return !runtimeCounters[key]
+	}
+	return gaugeStatistics[key] || strings.HasSuffix(key, "Active") || strings.HasSuffix(key, "Queue")
+}
+
+// statisticValue returns the value of a statistic as a float.
+func statisticValue(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// snakeCaseName returns a name in snake case with only the characters that
+// are valid in the metric and label names, like "point_req_local" for
+// "pointReqLocal" or "wal_compaction_time_ms" for "WALCompactionTimeMs".
+func snakeCaseName(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// Start a word at an upper case letter that follows a lower case
+			// letter or a digit, or that starts a word after an acronym.
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// tagsKey returns a key that identifies a set of tags.
+func tagsKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(tags[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
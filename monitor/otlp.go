@@ -0,0 +1,271 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/logger"
+	"go.uber.org/zap"
+)
+
+// otlpExporter pushes the statistics to an OpenTelemetry collector with OTLP
+// over HTTP, encoded as JSON. A value is exported as the metric
+// "influxdb.<module>.<key>" with its key in snake case, as a cumulative sum
+// if it only goes up and as a gauge otherwise, and the tags of its statistic
+// are its attributes.
+type otlpExporter struct {
+	endpoint string
+	headers  map[string]string
+	interval time.Duration
+	client   *http.Client
+
+	// start is the time the exporter started at, which is the start of the
+	// cumulative sums.
+	start time.Time
+}
+
+func newOTLPExporter(c Config) *otlpExporter {
+	return &otlpExporter{
+		endpoint: c.OTLPEndpoint,
+		headers:  c.OTLPHeaders,
+		interval: time.Duration(c.OTLPInterval),
+		client:   &http.Client{Timeout: time.Duration(c.OTLPTimeout)},
+	}
+}
+
+// The types of the JSON encoding of an OTLP metrics export request. The
+// 64-bit integers are encoded as strings.
+type (
+	otlpRequest struct {
+		ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+	}
+
+	otlpResourceMetrics struct {
+		Resource     otlpResource       `json:"resource"`
+		ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+
+	otlpScopeMetrics struct {
+		Scope   otlpScope    `json:"scope"`
+		Metrics []otlpMetric `json:"metrics"`
+	}
+
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+
+	otlpMetric struct {
+		Name        string     `json:"name"`
+		Description string     `json:"description,omitempty"`
+		Gauge       *otlpGauge `json:"gauge,omitempty"`
+		Sum         *otlpSum   `json:"sum,omitempty"`
+	}
+
+	otlpGauge struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	}
+
+	otlpSum struct {
+		DataPoints             []otlpDataPoint `json:"dataPoints"`
+		AggregationTemporality int             `json:"aggregationTemporality"`
+		IsMonotonic            bool            `json:"isMonotonic"`
+	}
+
+	otlpDataPoint struct {
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano,omitempty"`
+		TimeUnixNano      string          `json:"timeUnixNano"`
+		AsDouble          *float64        `json:"asDouble,omitempty"`
+		AsInt             string          `json:"asInt,omitempty"`
+	}
+
+	otlpAttribute struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+
+	otlpAnyValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// otlpTemporalityCumulative is the aggregation temporality of the sums, which
+// are the totals since the start.
+const otlpTemporalityCumulative = 2
+
+// exportStatistics pushes the statistics to the collector on every interval.
+func (m *Monitor) exportStatistics() {
+	defer m.wg.Done()
+	m.Logger.Info("Exporting statistics", zap.String("endpoint", m.otlp.endpoint), logger.DurationLiteral("interval", m.otlp.interval))
+	m.otlp.start = time.Now()
+
+	tick := time.NewTicker(m.otlp.interval)
+	defer tick.Stop()
+
+	// The failures are only logged once until an export succeeds again, so
+	// an unreachable collector doesn't flood the log.
+	failing := false
+	for {
+		select {
+		case now := <-tick.C:
+			stats, err := m.Statistics(nil)
+			if err != nil {
+				m.Logger.Info("Failed to retrieve registered statistics", zap.Error(err))
+				continue
+			}
+
+			if err := m.otlp.export(m.done, m.otlpRequest(stats, now)); err != nil {
+				if !failing {
+					m.Logger.Info("Failed to export statistics", zap.String("endpoint", m.otlp.endpoint), zap.Error(err))
+				}
+				failing = true
+			} else {
+				failing = false
+			}
+		case <-m.done:
+			m.Logger.Info("Terminating export of statistics")
+			return
+		}
+	}
+}
+
+// otlpRequest returns the export request of the statistics gathered at now.
+func (m *Monitor) otlpRequest(stats []*Statistic, now time.Time) *otlpRequest {
+	hostname, _ := os.Hostname()
+	resource := map[string]string{
+		"service.name":    "influxdb",
+		"service.version": m.Version,
+		"host.name":       hostname,
+	}
+	for k, v := range m.globalTags.Tags() {
+		if _, ok := resource[k]; !ok {
+			resource[k] = v
+		}
+	}
+
+	ts := strconv.FormatInt(now.UnixNano(), 10)
+	start := strconv.FormatInt(m.otlp.start.UnixNano(), 10)
+
+	metrics := make(map[string]*otlpMetric)
+	for _, s := range stats {
+		attrs := otlpAttributes(s.Tags)
+		for k, v := range s.Values {
+			p := otlpDataPoint{Attributes: attrs, TimeUnixNano: ts}
+			switch v := v.(type) {
+			case int64:
+				p.AsInt = strconv.FormatInt(v, 10)
+			case int:
+				p.AsInt = strconv.Itoa(v)
+			case uint64:
+				p.AsInt = strconv.FormatUint(v, 10)
+			default:
+				f, ok := statisticValue(v)
+				if !ok {
+					continue
+				}
+				p.AsDouble = &f
+			}
+
+			name := "influxdb." + snakeCaseName(s.Name) + "." + snakeCaseName(k)
+			metric := metrics[name]
+			if metric == nil {
+				metric = &otlpMetric{Name: name, Description: "Statistic " + k + " of the " + s.Name + " module."}
+				if isGaugeStatistic(s.Name, k) {
+					metric.Gauge = &otlpGauge{}
+				} else {
+					metric.Sum = &otlpSum{AggregationTemporality: otlpTemporalityCumulative, IsMonotonic: true}
+				}
+				metrics[name] = metric
+			}
+			if metric.Gauge != nil {
+				metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, p)
+			} else {
+				p.StartTimeUnixNano = start
+				metric.Sum.DataPoints = append(metric.Sum.DataPoints, p)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scope := otlpScopeMetrics{Scope: otlpScope{Name: "github.com/influxdata/influxdb/monitor", Version: m.Version}}
+	for _, name := range names {
+		scope.Metrics = append(scope.Metrics, *metrics[name])
+	}
+	return &otlpRequest{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     otlpResource{Attributes: otlpAttributes(resource)},
+		ScopeMetrics: []otlpScopeMetrics{scope},
+	}}}
+}
+
+// export sends an export request to the collector. It's canceled if done is
+// closed meanwhile.
+func (e *otlpExporter) export(done <-chan struct{}, r *otlpRequest) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+// otlpAttributes returns the attributes of tags, sorted by key.
+func otlpAttributes(tags map[string]string) []otlpAttribute {
+	if len(tags) == 0 {
+		return nil
+	}
+	attrs := make([]otlpAttribute, 0, len(tags))
+	for k, v := range tags {
+		attrs = append(attrs, otlpAttribute{Key: k, Value: otlpAnyValue{StringValue: v}})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs
+}
@@ -0,0 +1,95 @@
+package monitor_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/monitor"
+	"github.com/influxdata/influxdb/toml"
+)
+
+// Ensure the statistics are pushed to the collector as OTLP metrics.
+func TestMonitor_ExportStatistics(t *testing.T) {
+	reporter := ReporterFunc(func(tags map[string]string) []models.Statistic {
+		return []models.Statistic{{
+			Name:   "write",
+			Tags:   map[string]string{"db": "db0"},
+			Values: map[string]interface{}{"pointReq": int64(10), "writeReqActive": int64(2)},
+		}}
+	})
+
+	type metric struct {
+		Name  string
+		Gauge *struct {
+			DataPoints []map[string]interface{}
+		}
+		Sum *struct {
+			DataPoints             []map[string]interface{}
+			AggregationTemporality int
+			IsMonotonic            bool
+		}
+	}
+	type request struct {
+		ResourceMetrics []struct {
+			ScopeMetrics []struct {
+				Metrics []metric
+			}
+		}
+	}
+
+	requests := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected request: %s %v", r.URL, r.Header)
+		}
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		select {
+		case requests <- req:
+		default:
+		}
+	}))
+	defer srv.Close()
+
+	config := monitor.NewConfig()
+	config.StoreEnabled = false
+	config.OTLPEnabled = true
+	config.OTLPEndpoint = srv.URL + "/v1/metrics"
+	config.OTLPHeaders = map[string]string{"Authorization": "Bearer token"}
+	config.OTLPInterval = toml.Duration(10 * time.Millisecond)
+	s := monitor.New(reporter, config)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var req request
+	select {
+	case req = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the statistics to be exported")
+	}
+
+	metrics := make(map[string]metric)
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				metrics[m.Name] = m
+			}
+		}
+	}
+
+	if m := metrics["influxdb.write.point_req"]; m.Sum == nil || !m.Sum.IsMonotonic || m.Sum.AggregationTemporality != 2 ||
+		len(m.Sum.DataPoints) != 1 || m.Sum.DataPoints[0]["asInt"] != "10" {
+		t.Fatalf("unexpected metric: %+v", m)
+	}
+	if m := metrics["influxdb.write.write_req_active"]; m.Gauge == nil || len(m.Gauge.DataPoints) != 1 || m.Gauge.DataPoints[0]["asInt"] != "2" {
+		t.Fatalf("unexpected metric: %+v", m)
+	}
+}
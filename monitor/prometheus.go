@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatisticsSource returns the statistics of the modules.
+type StatisticsSource interface {
+	Statistics(tags map[string]string) ([]*Statistic, error)
+}
+
+// NewPrometheusCollector returns a collector of the statistics of a source,
+// which are the ones written to the _internal database, as Prometheus
+// metrics. A value is named "influxdb_<module>_<key>" in snake case, with
+// the "_total" suffix for a counter, and its tags are its labels.
+func NewPrometheusCollector(source StatisticsSource) prometheus.Collector {
+	return &prometheusCollector{source: source}
+}
+
+type prometheusCollector struct {
+	source StatisticsSource
+}
+
+// prometheusSample is a value of a statistic with its labels.
+type prometheusSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// prometheusFamily is the values of a metric across the statistics.
+type prometheusFamily struct {
+	help      string
+	valueType prometheus.ValueType
+	labels    map[string]struct{}
+	samples   []prometheusSample
+	seen      map[string]struct{} // label sets of the samples
+}
+
+// Describe sends no descriptors, the metrics are only known once they are
+// collected.
+func (c *prometheusCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect collects the statistics of the source as metrics.
+func (c *prometheusCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.source.Statistics(nil)
+	if err != nil {
+		return
+	}
+
+	families := make(map[string]*prometheusFamily)
+	for _, s := range stats {
+		labels := make(map[string]string, len(s.Tags))
+		for k, v := range s.Tags {
+			labels[snakeCaseName(k)] = v
+		}
+		key := tagsKey(labels)
+
+		for k, v := range s.Values {
+			value, ok := statisticValue(v)
+			if !ok {
+				continue
+			}
+
+			name := "influxdb_" + snakeCaseName(s.Name) + "_" + snakeCaseName(k)
+			valueType := prometheus.CounterValue
+			if isGaugeStatistic(s.Name, k) {
+				valueType = prometheus.GaugeValue
+			} else {
+				name += "_total"
+			}
+
+			f := families[name]
+			if f == nil {
+				f = &prometheusFamily{
+					help:      "Statistic " + k + " of the " + s.Name + " module.",
+					valueType: valueType,
+					labels:    make(map[string]struct{}),
+					seen:      make(map[string]struct{}),
+				}
+				families[name] = f
+			}
+			// Two statistics with the same labels can't be told apart.
+			if _, ok := f.seen[key]; ok {
+				continue
+			}
+			f.seen[key] = struct{}{}
+			for l := range labels {
+				f.labels[l] = struct{}{}
+			}
+			f.samples = append(f.samples, prometheusSample{labels: labels, value: value})
+		}
+	}
+
+	// The samples of a metric must all have the same labels, so the labels
+	// that a sample doesn't have are empty, which is the same as missing.
+	for name, f := range families {
+		labelNames := make([]string, 0, len(f.labels))
+		for l := range f.labels {
+			labelNames = append(labelNames, l)
+		}
+		sort.Strings(labelNames)
+
+		desc := prometheus.NewDesc(name, f.help, labelNames, nil)
+		for _, sample := range f.samples {
+			values := make([]string, len(labelNames))
+			for i, l := range labelNames {
+				values[i] = sample.labels[l]
+			}
+			m, err := prometheus.NewConstMetric(desc, f.valueType, sample.value, values...)
+			if err != nil {
+				continue
+			}
+			ch <- m
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package monitor_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/monitor"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type statisticsSource []*monitor.Statistic
+
+func (s statisticsSource) Statistics(map[string]string) ([]*monitor.Statistic, error) {
+	return s, nil
+}
+
+func newStatistic(name string, tags map[string]string, values map[string]interface{}) models.Statistic {
+	return models.Statistic{Name: name, Tags: tags, Values: values}
+}
+
+// Ensure the statistics are collected as metrics named after their modules
+// and keys, with their tags as labels.
+func TestPrometheusCollector(t *testing.T) {
+	source := statisticsSource{
+		{Statistic: newStatistic("write", map[string]string{"hostname": "host0"}, map[string]interface{}{
+			"pointReq":       int64(10),
+			"writeReqActive": int64(2),
+			"description":    "not a number",
+		})},
+		{Statistic: newStatistic("shard", map[string]string{"id": "1", "database": "db0"}, map[string]interface{}{
+			"diskBytes": int64(100),
+		})},
+		{Statistic: newStatistic("shard", map[string]string{"id": "2"}, map[string]interface{}{
+			"diskBytes": int64(200),
+		})},
+		{Statistic: newStatistic("runtime", nil, map[string]interface{}{
+			"HeapAlloc": uint64(1024),
+			"NumGC":     int64(3),
+		})},
+		{Statistic: newStatistic("tsm1_wal", nil, map[string]interface{}{
+			"WALCompactionTimeMs": int64(5),
+		})},
+	}
+
+	exp := `
+# HELP influxdb_runtime_heap_alloc Statistic HeapAlloc of the runtime module.
+# TYPE influxdb_runtime_heap_alloc gauge
+influxdb_runtime_heap_alloc 1024
+# HELP influxdb_runtime_num_gc_total Statistic NumGC of the runtime module.
+# TYPE influxdb_runtime_num_gc_total counter
+influxdb_runtime_num_gc_total 3
+# HELP influxdb_shard_disk_bytes Statistic diskBytes of the shard module.
+# TYPE influxdb_shard_disk_bytes gauge
+influxdb_shard_disk_bytes{database="db0",id="1"} 100
+influxdb_shard_disk_bytes{database="",id="2"} 200
+# HELP influxdb_tsm1_wal_wal_compaction_time_ms_total Statistic WALCompactionTimeMs of the tsm1_wal module.
+# TYPE influxdb_tsm1_wal_wal_compaction_time_ms_total counter
+influxdb_tsm1_wal_wal_compaction_time_ms_total 5
+# HELP influxdb_write_point_req_total Statistic pointReq of the write module.
+# TYPE influxdb_write_point_req_total counter
+influxdb_write_point_req_total{hostname="host0"} 10
+# HELP influxdb_write_write_req_active Statistic writeReqActive of the write module.
+# TYPE influxdb_write_write_req_active gauge
+influxdb_write_write_req_active{hostname="host0"} 2
+`
+	if err := testutil.CollectAndCompare(monitor.NewPrometheusCollector(source), strings.NewReader(exp)); err != nil {
+		t.Fatal(err)
+	}
+}
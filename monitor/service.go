@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/monitor/diagnostics"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
 )
 
@@ -30,6 +31,12 @@ const (
 
 	// Default replication factor to set on the monitor retention policy.
 	MonitorRetentionPolicyReplicaN = 1
+
+	// Name of the retention policy of the downsampled statistics.
+	MonitorDownsampledRetentionPolicy = "monitor_downsampled"
+
+	// Name of the continuous query that downsamples the statistics.
+	MonitorDownsampleQuery = "monitor_downsample"
 )
 
 // tags provides thread-safe tag handling
@@ -87,9 +94,24 @@ type Monitor struct {
 	storeDatabase        string
 	storeRetentionPolicy string
 	storeInterval        time.Duration
+	storeRetention       time.Duration
+	storeIntervals       map[string]time.Duration
+
+	downsampleInterval  time.Duration
+	downsampleRetention time.Duration
+
+	// otlp pushes the statistics to an OpenTelemetry collector, if enabled.
+	otlp *otlpExporter
+
+	// alerts evaluates the alert rules on the statistics, if any.
+	alerts *alerter
 
 	MetaClient interface {
 		CreateDatabaseWithRetentionPolicy(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
+		CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
+		UpdateRetentionPolicy(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
+		CreateContinuousQuery(database, name, query string) error
+		DropContinuousQuery(database, name string) error
 		Database(name string) *meta.DatabaseInfo
 	}
 
@@ -106,16 +128,32 @@ type PointsWriter interface {
 
 // New returns a new instance of the monitor system.
 func New(r Reporter, c Config) *Monitor {
-	return &Monitor{
+	m := &Monitor{
 		globalTags:           newTags(),
 		diagRegistrations:    make(map[string]diagnostics.Client),
 		reporter:             r,
 		storeEnabled:         c.StoreEnabled,
 		storeDatabase:        c.StoreDatabase,
 		storeInterval:        time.Duration(c.StoreInterval),
+		storeRetention:       time.Duration(c.StoreRetention),
 		storeRetentionPolicy: MonitorRetentionPolicy,
+		downsampleInterval:   time.Duration(c.StoreDownsampleInterval),
+		downsampleRetention:  time.Duration(c.StoreDownsampleRetention),
 		Logger:               zap.NewNop(),
 	}
+	if len(c.StoreIntervals) > 0 {
+		m.storeIntervals = make(map[string]time.Duration, len(c.StoreIntervals))
+		for name, d := range c.StoreIntervals {
+			m.storeIntervals[name] = time.Duration(d)
+		}
+	}
+	if c.OTLPEnabled {
+		m.otlp = newOTLPExporter(c)
+	}
+	if len(c.Alerts) > 0 {
+		m.alerts = newAlerter(c)
+	}
+	return m
 }
 
 // open returns whether the monitor service is open.
@@ -160,6 +198,18 @@ func (m *Monitor) Open() error {
 		go m.storeStatistics()
 	}
 
+	// If enabled, push stats to an OpenTelemetry collector.
+	if m.otlp != nil {
+		m.wg.Add(1)
+		go m.exportStatistics()
+	}
+
+	// If any, evaluate the alert rules on the stats.
+	if m.alerts != nil {
+		m.wg.Add(1)
+		go m.evaluateAlerts()
+	}
+
 	return nil
 }
 
@@ -415,8 +465,9 @@ func (m *Monitor) createInternalStorage() {
 		return
 	}
 
-	if di := m.MetaClient.Database(m.storeDatabase); di == nil {
-		duration := MonitorRetentionPolicyDuration
+	di := m.MetaClient.Database(m.storeDatabase)
+	if di == nil {
+		duration := m.storeRetention
 		replicaN := MonitorRetentionPolicyReplicaN
 		spec := meta.RetentionPolicySpec{
 			Name:     MonitorRetentionPolicy,
@@ -424,16 +475,95 @@ func (m *Monitor) createInternalStorage() {
 			ReplicaN: &replicaN,
 		}
 
-		if _, err := m.MetaClient.CreateDatabaseWithRetentionPolicy(m.storeDatabase, &spec); err != nil {
+		var err error
+		if di, err = m.MetaClient.CreateDatabaseWithRetentionPolicy(m.storeDatabase, &spec); err != nil {
 			m.Logger.Info("Failed to create storage", logger.Database(m.storeDatabase), zap.Error(err))
 			return
 		}
+	} else if err := m.ensureRetentionPolicy(di, MonitorRetentionPolicy, m.storeRetention); err != nil {
+		m.Logger.Info("Failed to update storage", logger.Database(m.storeDatabase), zap.Error(err))
+		return
+	}
+
+	if err := m.ensureDownsampling(di); err != nil {
+		m.Logger.Info("Failed to set up downsampling", logger.Database(m.storeDatabase), zap.Error(err))
+		return
 	}
 
 	// Mark storage creation complete.
 	m.storeCreated = true
 }
 
+// ensureRetentionPolicy updates the duration of a retention policy of the
+// store database if it changed. The retention policy is created if it
+// doesn't exist, except the monitor one, since the database was created
+// without it.
+func (m *Monitor) ensureRetentionPolicy(di *meta.DatabaseInfo, name string, duration time.Duration) error {
+	rpi := di.RetentionPolicy(name)
+	if rpi == nil {
+		if name == MonitorRetentionPolicy {
+			return nil
+		}
+		replicaN := MonitorRetentionPolicyReplicaN
+		spec := meta.RetentionPolicySpec{Name: name, Duration: &duration, ReplicaN: &replicaN}
+		_, err := m.MetaClient.CreateRetentionPolicy(di.Name, &spec, false)
+		return err
+	}
+	if rpi.Duration == duration {
+		return nil
+	}
+
+	m.Logger.Info("Updating retention policy duration", logger.Database(di.Name), logger.RetentionPolicy(name), logger.DurationLiteral("duration", duration))
+	return m.MetaClient.UpdateRetentionPolicy(di.Name, name, &meta.RetentionPolicyUpdate{Duration: &duration}, false)
+}
+
+// ensureDownsampling creates the retention policy and the continuous query
+// that downsample the statistics, if enabled. The continuous query is
+// replaced if its interval changed.
+func (m *Monitor) ensureDownsampling(di *meta.DatabaseInfo) error {
+	var existing *meta.ContinuousQueryInfo
+	for i := range di.ContinuousQueries {
+		if di.ContinuousQueries[i].Name == MonitorDownsampleQuery {
+			existing = &di.ContinuousQueries[i]
+		}
+	}
+
+	if m.downsampleInterval == 0 {
+		// The downsampled statistics are kept until their retention
+		// policy drops them.
+		if existing != nil {
+			return m.MetaClient.DropContinuousQuery(di.Name, MonitorDownsampleQuery)
+		}
+		return nil
+	}
+
+	if err := m.ensureRetentionPolicy(di, MonitorDownsampledRetentionPolicy, m.downsampleRetention); err != nil {
+		return err
+	}
+
+	q := m.downsampleQuery()
+	if existing != nil {
+		if existing.Query == q {
+			return nil
+		}
+		if err := m.MetaClient.DropContinuousQuery(di.Name, MonitorDownsampleQuery); err != nil {
+			return err
+		}
+	}
+	return m.MetaClient.CreateContinuousQuery(di.Name, MonitorDownsampleQuery, q)
+}
+
+// downsampleQuery returns the continuous query that records the mean of the
+// statistics over each downsample interval.
+func (m *Monitor) downsampleQuery() string {
+	db := influxql.QuoteIdent(m.storeDatabase)
+	return fmt.Sprintf(`CREATE CONTINUOUS QUERY %s ON %s BEGIN SELECT mean(*) INTO %s.%s.:MEASUREMENT FROM %s.%s./.*/ GROUP BY time(%s), * END`,
+		influxql.QuoteIdent(MonitorDownsampleQuery), db,
+		db, influxql.QuoteIdent(MonitorDownsampledRetentionPolicy),
+		db, influxql.QuoteIdent(m.storeRetentionPolicy),
+		influxql.FormatDuration(m.downsampleInterval))
+}
+
 // waitUntilInterval waits until we are on an even interval for the duration.
 func (m *Monitor) waitUntilInterval(d time.Duration) error {
 	now := time.Now()
@@ -482,6 +612,10 @@ func (m *Monitor) storeStatistics() {
 			// Write all stats in batches
 			batch := make(models.Points, 0, 5000)
 			for _, s := range stats {
+				// Skip the stats recorded less often until their interval.
+				if d, ok := m.storeIntervals[s.Name]; ok && !now.Truncate(d).Equal(now) {
+					continue
+				}
 				pt, err := models.NewPoint(s.Name, models.NewTags(s.Tags), s.Values, now)
 				if err != nil {
 					m.Logger.Info("Dropping point", zap.String("name", s.Name), zap.Error(err))
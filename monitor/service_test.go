@@ -243,6 +243,132 @@ func TestMonitor_Reporter(t *testing.T) {
 	}
 }
 
+// Ensure the retention policy of the statistics is updated and their
+// downsampling is set up on an existing store database.
+func TestMonitor_StoreStatistics_Downsample(t *testing.T) {
+	created := make(chan string, 3)
+
+	var mc MetaClient
+	mc.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{
+			Name: name,
+			RetentionPolicies: []meta.RetentionPolicyInfo{
+				{Name: monitor.MonitorRetentionPolicy, Duration: monitor.MonitorRetentionPolicyDuration},
+			},
+		}
+	}
+	mc.UpdateRetentionPolicyFn = func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error {
+		if name != monitor.MonitorRetentionPolicy || rpu.Duration == nil || *rpu.Duration != 24*time.Hour {
+			t.Errorf("unexpected retention policy update: %s %+v", name, rpu)
+		}
+		created <- "update " + name
+		return nil
+	}
+	mc.CreateRetentionPolicyFn = func(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error) {
+		if spec.Name != monitor.MonitorDownsampledRetentionPolicy || spec.Duration == nil || *spec.Duration != monitor.DefaultStoreDownsampleRetention || makeDefault {
+			t.Errorf("unexpected retention policy: %+v", spec)
+		}
+		created <- "create " + spec.Name
+		return &meta.RetentionPolicyInfo{Name: spec.Name}, nil
+	}
+	mc.CreateContinuousQueryFn = func(database, name, query string) error {
+		exp := `CREATE CONTINUOUS QUERY monitor_downsample ON _internal BEGIN SELECT mean(*) INTO _internal.monitor_downsampled.:MEASUREMENT FROM _internal.monitor./.*/ GROUP BY time(1m), * END`
+		if name != monitor.MonitorDownsampleQuery || query != exp {
+			t.Errorf("unexpected continuous query %s: %s", name, query)
+		}
+		created <- "create " + name
+		return nil
+	}
+
+	var pw PointsWriter
+	pw.WritePointsFn = func(database, policy string, points models.Points) error { return nil }
+
+	config := monitor.NewConfig()
+	config.StoreInterval = toml.Duration(10 * time.Millisecond)
+	config.StoreRetention = toml.Duration(24 * time.Hour)
+	config.StoreDownsampleInterval = toml.Duration(time.Minute)
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	s := monitor.New(nil, config)
+	s.MetaClient = &mc
+	s.PointsWriter = &pw
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, exp := range []string{"update monitor", "create monitor_downsampled", "create monitor_downsample"} {
+		select {
+		case got := <-created:
+			if got != exp {
+				t.Fatalf("unexpected change: got=%q want=%q", got, exp)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %q", exp)
+		}
+	}
+}
+
+// Ensure the statistics with an interval of their own are recorded less
+// often.
+func TestMonitor_StoreStatistics_Intervals(t *testing.T) {
+	reporter := ReporterFunc(func(tags map[string]string) []models.Statistic {
+		return []models.Statistic{
+			{Name: "foo", Tags: tags, Values: map[string]interface{}{"value": int64(1)}},
+			{Name: "bar", Tags: tags, Values: map[string]interface{}{"value": int64(1)}},
+		}
+	})
+
+	var mc MetaClient
+	mc.CreateDatabaseWithRetentionPolicyFn = func(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+
+	ch := make(chan models.Points, 10)
+	var pw PointsWriter
+	pw.WritePointsFn = func(database, policy string, points models.Points) error {
+		select {
+		case ch <- points:
+		default:
+		}
+		return nil
+	}
+
+	config := monitor.NewConfig()
+	config.StoreInterval = toml.Duration(10 * time.Millisecond)
+	config.StoreIntervals = map[string]toml.Duration{"foo": toml.Duration(time.Hour)}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	s := monitor.New(reporter, config)
+	s.MetaClient = &mc
+	s.PointsWriter = &pw
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case points := <-ch:
+			found := false
+			for _, pt := range points {
+				if name := string(pt.Name()); name == "foo" {
+					t.Fatal("unexpected foo statistic")
+				} else if name == "bar" {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatal("unable to find bar statistic")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout while waiting for statistics to be written")
+		}
+	}
+}
+
 func expvarMap(name string, tags map[string]string, fields map[string]interface{}) *expvar.Map {
 	m := new(expvar.Map).Init()
 	eName := new(expvar.String)
@@ -467,6 +593,10 @@ func (pw *PointsWriter) WritePoints(database, policy string, points models.Point
 
 type MetaClient struct {
 	CreateDatabaseWithRetentionPolicyFn func(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
+	CreateRetentionPolicyFn             func(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
+	UpdateRetentionPolicyFn             func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
+	CreateContinuousQueryFn             func(database, name, query string) error
+	DropContinuousQueryFn               func(database, name string) error
 	DatabaseFn                          func(name string) *meta.DatabaseInfo
 }
 
@@ -474,6 +604,22 @@ func (m *MetaClient) CreateDatabaseWithRetentionPolicy(name string, spec *meta.R
 	return m.CreateDatabaseWithRetentionPolicyFn(name, spec)
 }
 
+func (m *MetaClient) CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error) {
+	return m.CreateRetentionPolicyFn(database, spec, makeDefault)
+}
+
+func (m *MetaClient) UpdateRetentionPolicy(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error {
+	return m.UpdateRetentionPolicyFn(database, name, rpu, makeDefault)
+}
+
+func (m *MetaClient) CreateContinuousQuery(database, name, query string) error {
+	return m.CreateContinuousQueryFn(database, name, query)
+}
+
+func (m *MetaClient) DropContinuousQuery(database, name string) error {
+	return m.DropContinuousQueryFn(database, name)
+}
+
 func (m *MetaClient) Database(name string) *meta.DatabaseInfo {
 	if m.DatabaseFn != nil {
 		return m.DatabaseFn(name)
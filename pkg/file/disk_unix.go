@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd
+
+package file
+
+import "golang.org/x/sys/unix"
+
+// DiskSpace returns the free space available to unprivileged users and the
+// total space, in bytes, of the file system containing path.
+func DiskSpace(path string) (free, total uint64, err error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), uint64(st.Blocks) * uint64(st.Bsize), nil
+}
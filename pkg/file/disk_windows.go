@@ -0,0 +1,16 @@
+package file
+
+import "golang.org/x/sys/windows"
+
+// DiskSpace returns the free space available to the user and the total
+// space, in bytes, of the volume containing path.
+func DiskSpace(path string) (free, total uint64, err error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := windows.GetDiskFreeSpaceEx(p, &free, &total, nil); err != nil {
+		return 0, 0, err
+	}
+	return free, total, nil
+}
@@ -0,0 +1,262 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Classes of BER identifiers.
+const (
+	ClassUniversal   = 0x00
+	ClassApplication = 0x40
+	ClassContext     = 0x80
+)
+
+// Tags of the universal types used by LDAP.
+const (
+	TagBoolean     = 0x01
+	TagInteger     = 0x02
+	TagOctetString = 0x04
+	TagNull        = 0x05
+	TagEnumerated  = 0x0A
+	TagSequence    = 0x10
+	TagSet         = 0x11
+)
+
+// maxPacketSize is the largest packet that is read from a connection.
+const maxPacketSize = 16 << 20
+
+// Packet is an element of a BER encoded LDAP message. Only the definite
+// length form with tag numbers below 31 is supported, which is all LDAP
+// uses.
+type Packet struct {
+	Class       byte
+	Constructed bool
+	Tag         byte
+
+	// Value is the contents of a primitive packet.
+	Value []byte
+
+	// Children are the elements of a constructed packet.
+	Children []*Packet
+}
+
+// NewPrimitive returns a primitive packet with the value.
+func NewPrimitive(class, tag byte, value []byte) *Packet {
+	return &Packet{Class: class, Tag: tag, Value: value}
+}
+
+// NewConstructed returns a constructed packet with the children.
+func NewConstructed(class, tag byte, children ...*Packet) *Packet {
+	return &Packet{Class: class, Constructed: true, Tag: tag, Children: children}
+}
+
+// NewString returns an OCTET STRING packet.
+func NewString(s string) *Packet {
+	return NewPrimitive(ClassUniversal, TagOctetString, []byte(s))
+}
+
+// NewInteger returns an INTEGER packet.
+func NewInteger(v int64) *Packet {
+	return NewPrimitive(ClassUniversal, TagInteger, encodeInt(v))
+}
+
+// NewEnumerated returns an ENUMERATED packet.
+func NewEnumerated(v int64) *Packet {
+	return NewPrimitive(ClassUniversal, TagEnumerated, encodeInt(v))
+}
+
+// NewBoolean returns a BOOLEAN packet.
+func NewBoolean(v bool) *Packet {
+	if v {
+		return NewPrimitive(ClassUniversal, TagBoolean, []byte{0xFF})
+	}
+	return NewPrimitive(ClassUniversal, TagBoolean, []byte{0x00})
+}
+
+// NewSequence returns a SEQUENCE packet.
+func NewSequence(children ...*Packet) *Packet {
+	return NewConstructed(ClassUniversal, TagSequence, children...)
+}
+
+// Is returns true if the packet has the class and tag.
+func (p *Packet) Is(class, tag byte) bool {
+	return p.Class == class && p.Tag == tag
+}
+
+// Child returns the i-th child of the packet or nil if it does not exist.
+func (p *Packet) Child(i int) *Packet {
+	if i < 0 || i >= len(p.Children) {
+		return nil
+	}
+	return p.Children[i]
+}
+
+// String returns the value of the packet as a string.
+func (p *Packet) String() string {
+	return string(p.Value)
+}
+
+// Int returns the value of an INTEGER or ENUMERATED packet.
+func (p *Packet) Int() (int64, error) {
+	if p.Constructed || len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, errors.New("ldap: invalid integer")
+	}
+	v := int64(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// Bytes returns the BER encoding of the packet.
+func (p *Packet) Bytes() []byte {
+	return p.appendTo(nil)
+}
+
+func (p *Packet) appendTo(b []byte) []byte {
+	id := p.Class | p.Tag
+	if p.Constructed {
+		id |= 0x20
+	}
+	b = append(b, id)
+
+	value := p.Value
+	if p.Constructed {
+		value = nil
+		for _, child := range p.Children {
+			value = child.appendTo(value)
+		}
+	}
+	b = appendLength(b, len(value))
+	return append(b, value...)
+}
+
+func appendLength(b []byte, n int) []byte {
+	if n < 0x80 {
+		return append(b, byte(n))
+	}
+	var buf [8]byte
+	i := len(buf)
+	for ; n > 0; n >>= 8 {
+		i--
+		buf[i] = byte(n)
+	}
+	b = append(b, 0x80|byte(len(buf)-i))
+	return append(b, buf[i:]...)
+}
+
+func encodeInt(v int64) []byte {
+	n := 1
+	for x := v; x > 127 || x < -128; x >>= 8 {
+		n++
+	}
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+// ReadPacket reads a BER encoded packet.
+func ReadPacket(r *bufio.Reader) (*Packet, error) {
+	id, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	n, err := readLength(r)
+	if err != nil {
+		return nil, err
+	} else if n > maxPacketSize {
+		return nil, fmt.Errorf("ldap: packet of %d bytes is too large", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return decodePacket(id, b)
+}
+
+// DecodePacket decodes a BER encoded packet that is contained in b.
+func DecodePacket(b []byte) (*Packet, error) {
+	p, rest, err := decode(b)
+	if err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("ldap: trailing data after packet")
+	}
+	return p, nil
+}
+
+func decode(b []byte) (*Packet, []byte, error) {
+	if len(b) < 2 {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	id, n := b[0], int(b[1])
+	b = b[2:]
+	if n&0x80 != 0 {
+		size := n & 0x7F
+		if size == 0 || size > 4 || size > len(b) {
+			return nil, nil, errors.New("ldap: invalid length")
+		}
+		n = 0
+		for _, c := range b[:size] {
+			n = n<<8 | int(c)
+		}
+		b = b[size:]
+	}
+	if n > len(b) {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	p, err := decodePacket(id, b[:n])
+	return p, b[n:], err
+}
+
+func decodePacket(id byte, value []byte) (*Packet, error) {
+	if id&0x1F == 0x1F {
+		return nil, errors.New("ldap: high tag numbers are not supported")
+	}
+	p := &Packet{Class: id & 0xC0, Constructed: id&0x20 != 0, Tag: id & 0x1F}
+	if !p.Constructed {
+		p.Value = value
+		return p, nil
+	}
+	for len(value) > 0 {
+		child, rest, err := decode(value)
+		if err != nil {
+			return nil, err
+		}
+		p.Children = append(p.Children, child)
+		value = rest
+	}
+	return p, nil
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	} else if b&0x80 == 0 {
+		return int(b), nil
+	}
+
+	size := int(b & 0x7F)
+	if size == 0 || size > 4 {
+		return 0, errors.New("ldap: invalid length")
+	}
+	var n int
+	for i := 0; i < size; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n = n<<8 | int(b)
+	}
+	return n, nil
+}
@@ -0,0 +1,200 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tags of the filter choices of a search request.
+const (
+	FilterAnd            = 0
+	FilterOr             = 1
+	FilterNot            = 2
+	FilterEqualityMatch  = 3
+	FilterSubstrings     = 4
+	FilterGreaterOrEqual = 5
+	FilterLessOrEqual    = 6
+	FilterPresent        = 7
+	FilterApproxMatch    = 8
+)
+
+// Tags of the components of a substrings filter.
+const (
+	SubstringInitial = 0
+	SubstringAny     = 1
+	SubstringFinal   = 2
+)
+
+// EscapeFilter escapes the special characters of a value that is placed in a
+// search filter as described in RFC 4515.
+func EscapeFilter(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&buf, "\\%02x", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+// ParseFilter parses the string representation of a search filter as
+// described in RFC 4515. Extensible matches are not supported.
+func ParseFilter(s string) (*Packet, error) {
+	p, rest, err := parseFilter(s)
+	if err != nil {
+		return nil, err
+	} else if rest != "" {
+		return nil, fmt.Errorf("ldap: unexpected %q after filter", rest)
+	}
+	return p, nil
+}
+
+func parseFilter(s string) (*Packet, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", errors.New("ldap: filter must start with (")
+	}
+	s = s[1:]
+	if s == "" {
+		return nil, "", errors.New("ldap: unexpected end of filter")
+	}
+
+	var p *Packet
+	switch s[0] {
+	case '&', '|':
+		tag := byte(FilterAnd)
+		if s[0] == '|' {
+			tag = FilterOr
+		}
+		p = NewConstructed(ClassContext, tag)
+		s = s[1:]
+		for strings.HasPrefix(s, "(") {
+			child, rest, err := parseFilter(s)
+			if err != nil {
+				return nil, "", err
+			}
+			p.Children = append(p.Children, child)
+			s = rest
+		}
+	case '!':
+		child, rest, err := parseFilter(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		p, s = NewConstructed(ClassContext, FilterNot, child), rest
+	default:
+		i := strings.IndexByte(s, ')')
+		if i < 0 {
+			return nil, "", errors.New("ldap: unexpected end of filter")
+		}
+		var err error
+		if p, err = parseItem(s[:i]); err != nil {
+			return nil, "", err
+		}
+		s = s[i:]
+	}
+
+	if !strings.HasPrefix(s, ")") {
+		return nil, "", errors.New("ldap: filter must end with )")
+	}
+	return p, s[1:], nil
+}
+
+// parseItem parses a simple, presence or substrings filter.
+func parseItem(s string) (*Packet, error) {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return nil, fmt.Errorf("ldap: invalid filter item %q", s)
+	}
+	attr, value := s[:i], s[i+1:]
+
+	tag := byte(FilterEqualityMatch)
+	switch attr[len(attr)-1] {
+	case '>':
+		tag, attr = FilterGreaterOrEqual, attr[:len(attr)-1]
+	case '<':
+		tag, attr = FilterLessOrEqual, attr[:len(attr)-1]
+	case '~':
+		tag, attr = FilterApproxMatch, attr[:len(attr)-1]
+	default:
+	}
+	if attr == "" {
+		return nil, fmt.Errorf("ldap: invalid filter item %q", s)
+	}
+
+	if tag == FilterEqualityMatch {
+		if value == "*" {
+			return NewPrimitive(ClassContext, FilterPresent, []byte(attr)), nil
+		} else if strings.Contains(value, "*") {
+			return parseSubstrings(attr, value)
+		}
+	}
+
+	v, err := unescapeFilter(value)
+	if err != nil {
+		return nil, err
+	}
+	return NewConstructed(ClassContext, tag, NewString(attr), NewString(v)), nil
+}
+
+func parseSubstrings(attr, value string) (*Packet, error) {
+	parts := strings.Split(value, "*")
+	substrings := NewSequence()
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		v, err := unescapeFilter(part)
+		if err != nil {
+			return nil, err
+		}
+
+		tag := byte(SubstringAny)
+		if i == 0 {
+			tag = SubstringInitial
+		} else if i == len(parts)-1 {
+			tag = SubstringFinal
+		}
+		substrings.Children = append(substrings.Children, NewPrimitive(ClassContext, tag, []byte(v)))
+	}
+	return NewConstructed(ClassContext, FilterSubstrings, NewString(attr), substrings), nil
+}
+
+func unescapeFilter(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			buf.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("ldap: invalid escape in filter value %q", s)
+		}
+		hi, lo := unhex(s[i+1]), unhex(s[i+2])
+		if hi < 0 || lo < 0 {
+			return "", fmt.Errorf("ldap: invalid escape in filter value %q", s)
+		}
+		buf.WriteByte(byte(hi<<4 | lo))
+		i += 2
+	}
+	return buf.String(), nil
+}
+
+func unhex(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'F':
+		return int(c-'A') + 10
+	default:
+		return -1
+	}
+}
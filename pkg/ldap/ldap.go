@@ -0,0 +1,306 @@
+// Package ldap implements the subset of the LDAPv3 protocol (RFC 4511) that
+// is needed to authenticate users against a directory: simple binds,
+// searches and StartTLS.
+package ldap // import "github.com/influxdata/influxdb/pkg/ldap"
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Tags of the protocol operations.
+const (
+	OpBindRequest       = 0
+	OpBindResponse      = 1
+	OpUnbindRequest     = 2
+	OpSearchRequest     = 3
+	OpSearchResultEntry = 4
+	OpSearchResultDone  = 5
+	OpSearchResultRef   = 19
+	OpExtendedRequest   = 23
+	OpExtendedResponse  = 24
+)
+
+// StartTLSOID is the name of the StartTLS extended operation.
+const StartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+const (
+	protocolVersion      = 3
+	defaultTimeout       = 10 * time.Second
+	scopeWholeSubtree    = 2
+	derefAliasesNever    = 0
+	authenticationSimple = 0
+	requestNameTag       = 0
+)
+
+// Result codes of the operations.
+const (
+	ResultSuccess            = 0
+	ResultInvalidCredentials = 49
+)
+
+// Error is the result of an operation that did not succeed.
+type Error struct {
+	ResultCode int64
+	Message    string
+}
+
+// Error returns the text of the error.
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.ResultCode)
+	}
+	return fmt.Sprintf("ldap: result code %d: %s", e.ResultCode, e.Message)
+}
+
+// IsInvalidCredentials returns true if the error is the result of a bind
+// with invalid credentials.
+func IsInvalidCredentials(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.ResultCode == ResultInvalidCredentials
+}
+
+// Entry is an entry returned by a search.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Attribute returns the values of the attribute with the name. Attribute
+// names are case-insensitive.
+func (e *Entry) Attribute(name string) []string {
+	for k, v := range e.Attributes {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// Conn is a connection to an LDAP server. Operations are sent one at a time
+// so a Conn must not be used by several goroutines concurrently.
+type Conn struct {
+	conn  net.Conn
+	r     *bufio.Reader
+	msgID int64
+
+	// Timeout bounds the duration of each operation.
+	Timeout time.Duration
+}
+
+// Dial connects to the LDAP server at the address. The connection uses TLS
+// if config is not nil.
+func Dial(addr string, config *tls.Config, timeout time.Duration) (*Conn, error) {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	var err error
+	if config != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, config)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(conn, timeout), nil
+}
+
+// NewConn returns an LDAP connection over conn.
+func NewConn(conn net.Conn, timeout time.Duration) *Conn {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Conn{conn: conn, r: bufio.NewReader(conn), Timeout: timeout}
+}
+
+// Close sends an unbind request and closes the connection.
+func (c *Conn) Close() error {
+	_ = c.conn.SetDeadline(time.Now().Add(c.Timeout))
+	c.msgID++
+	msg := NewSequence(NewInteger(c.msgID), NewPrimitive(ClassApplication, OpUnbindRequest, nil))
+	_, _ = c.conn.Write(msg.Bytes())
+	return c.conn.Close()
+}
+
+// StartTLS upgrades the connection to TLS with the StartTLS extended
+// operation.
+func (c *Conn) StartTLS(config *tls.Config) error {
+	req := NewConstructed(ClassApplication, OpExtendedRequest,
+		NewPrimitive(ClassContext, requestNameTag, []byte(StartTLSOID)),
+	)
+	resp, err := c.roundTrip(req, OpExtendedResponse)
+	if err != nil {
+		return err
+	} else if err := resultError(resp[0]); err != nil {
+		return err
+	}
+
+	conn := tls.Client(c.conn, config)
+	_ = conn.SetDeadline(time.Now().Add(c.Timeout))
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	c.conn, c.r = conn, bufio.NewReader(conn)
+	return nil
+}
+
+// Bind authenticates the connection with the DN and password of an entry.
+// An empty password is rejected as it would be an unauthenticated bind that
+// most servers accept for any DN.
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		return &Error{ResultCode: ResultInvalidCredentials, Message: "empty password"}
+	}
+	req := NewConstructed(ClassApplication, OpBindRequest,
+		NewInteger(protocolVersion),
+		NewString(dn),
+		NewPrimitive(ClassContext, authenticationSimple, []byte(password)),
+	)
+	resp, err := c.roundTrip(req, OpBindResponse)
+	if err != nil {
+		return err
+	}
+	return resultError(resp[0])
+}
+
+// SearchRequest represents a search of the subtree of a base entry.
+type SearchRequest struct {
+	BaseDN     string
+	Filter     string
+	Attributes []string
+
+	// SizeLimit is the maximum number of entries that are returned. Zero
+	// means no limit.
+	SizeLimit int
+}
+
+// Search returns the entries of the subtree of the base entry that match
+// the filter.
+func (c *Conn) Search(req *SearchRequest) ([]*Entry, error) {
+	filter, err := ParseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	attributes := NewSequence()
+	for _, attr := range req.Attributes {
+		attributes.Children = append(attributes.Children, NewString(attr))
+	}
+
+	op := NewConstructed(ClassApplication, OpSearchRequest,
+		NewString(req.BaseDN),
+		NewEnumerated(scopeWholeSubtree),
+		NewEnumerated(derefAliasesNever),
+		NewInteger(int64(req.SizeLimit)),
+		NewInteger(int64(c.Timeout/time.Second)),
+		NewBoolean(false),
+		filter,
+		attributes,
+	)
+	resp, err := c.roundTrip(op, OpSearchResultDone)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for _, p := range resp {
+		switch p.Tag {
+		case OpSearchResultEntry:
+			entry, err := decodeEntry(p)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case OpSearchResultDone:
+			if err := resultError(p); err != nil {
+				return nil, err
+			}
+		default:
+			// Referrals to other servers are not followed.
+		}
+	}
+	return entries, nil
+}
+
+// roundTrip sends the operation and returns the responses to it up to and
+// including the response with the tag done.
+func (c *Conn) roundTrip(op *Packet, done byte) ([]*Packet, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(c.Timeout)); err != nil {
+		return nil, err
+	}
+	defer c.conn.SetDeadline(time.Time{})
+
+	c.msgID++
+	msg := NewSequence(NewInteger(c.msgID), op)
+	if _, err := c.conn.Write(msg.Bytes()); err != nil {
+		return nil, err
+	}
+
+	var resp []*Packet
+	for {
+		p, err := ReadPacket(c.r)
+		if err != nil {
+			return nil, err
+		} else if !p.Is(ClassUniversal, TagSequence) || len(p.Children) < 2 {
+			return nil, errors.New("ldap: invalid message")
+		}
+		if id, err := p.Children[0].Int(); err != nil {
+			return nil, err
+		} else if id == 0 {
+			// An unsolicited notification, such as a notice of
+			// disconnection, terminates the connection.
+			return nil, errors.New("ldap: connection closed by server")
+		} else if id != c.msgID {
+			return nil, fmt.Errorf("ldap: unexpected message id %d", id)
+		}
+
+		op := p.Children[1]
+		if op.Class != ClassApplication {
+			return nil, errors.New("ldap: invalid protocol operation")
+		}
+		resp = append(resp, op)
+		if op.Tag == done {
+			return resp, nil
+		}
+	}
+}
+
+// resultError returns an error if the result of the operation is not
+// successful.
+func resultError(p *Packet) error {
+	if len(p.Children) < 3 {
+		return errors.New("ldap: invalid result")
+	}
+	code, err := p.Children[0].Int()
+	if err != nil {
+		return err
+	} else if code != ResultSuccess {
+		return &Error{ResultCode: code, Message: p.Children[2].String()}
+	}
+	return nil
+}
+
+func decodeEntry(p *Packet) (*Entry, error) {
+	if len(p.Children) < 2 {
+		return nil, errors.New("ldap: invalid search result entry")
+	}
+	entry := &Entry{DN: p.Children[0].String(), Attributes: make(map[string][]string)}
+	for _, attr := range p.Children[1].Children {
+		if len(attr.Children) < 2 {
+			return nil, errors.New("ldap: invalid attribute")
+		}
+		name := attr.Children[0].String()
+		for _, v := range attr.Children[1].Children {
+			entry.Attributes[name] = append(entry.Attributes[name], v.String())
+		}
+	}
+	return entry, nil
+}
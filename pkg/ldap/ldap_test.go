@@ -0,0 +1,179 @@
+package ldap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/ldap"
+	"github.com/influxdata/influxdb/pkg/ldap/ldaptest"
+)
+
+func TestPacket_Bytes(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		p    *ldap.Packet
+		exp  []byte
+	}{
+		{name: "Integer", p: ldap.NewInteger(3), exp: []byte{0x02, 0x01, 0x03}},
+		{name: "NegativeInteger", p: ldap.NewInteger(-129), exp: []byte{0x02, 0x02, 0xFF, 0x7F}},
+		{name: "LargeInteger", p: ldap.NewInteger(256), exp: []byte{0x02, 0x02, 0x01, 0x00}},
+		{name: "String", p: ldap.NewString("cn"), exp: []byte{0x04, 0x02, 'c', 'n'}},
+		{name: "Sequence", p: ldap.NewSequence(ldap.NewBoolean(true)), exp: []byte{0x30, 0x03, 0x01, 0x01, 0xFF}},
+		{
+			name: "LongLength",
+			p:    ldap.NewString(string(bytes.Repeat([]byte{'a'}, 200))),
+			exp:  append([]byte{0x04, 0x81, 200}, bytes.Repeat([]byte{'a'}, 200)...),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.p.Bytes()
+			if !bytes.Equal(b, tt.exp) {
+				t.Fatalf("unexpected encoding: got %x, exp %x", b, tt.exp)
+			}
+
+			p, err := ldap.DecodePacket(b)
+			if err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(p.Bytes(), b) {
+				t.Fatalf("unexpected round trip: got %x, exp %x", p.Bytes(), b)
+			}
+		})
+	}
+}
+
+func TestPacket_Int(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 127, 128, -128, -129, 1 << 40} {
+		p, err := ldap.DecodePacket(ldap.NewInteger(v).Bytes())
+		if err != nil {
+			t.Fatal(err)
+		} else if got, err := p.Int(); err != nil {
+			t.Fatal(err)
+		} else if got != v {
+			t.Fatalf("unexpected value: got %d, exp %d", got, v)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		exp *ldap.Packet
+		err bool
+	}{
+		{
+			s:   "(uid=bob)",
+			exp: ldap.NewConstructed(ldap.ClassContext, ldap.FilterEqualityMatch, ldap.NewString("uid"), ldap.NewString("bob")),
+		},
+		{
+			s:   `(cn=a\2ab)`,
+			exp: ldap.NewConstructed(ldap.ClassContext, ldap.FilterEqualityMatch, ldap.NewString("cn"), ldap.NewString("a*b")),
+		},
+		{
+			s:   "(objectClass=*)",
+			exp: ldap.NewPrimitive(ldap.ClassContext, ldap.FilterPresent, []byte("objectClass")),
+		},
+		{
+			s: "(&(objectClass=person)(!(uid=bob)))",
+			exp: ldap.NewConstructed(ldap.ClassContext, ldap.FilterAnd,
+				ldap.NewConstructed(ldap.ClassContext, ldap.FilterEqualityMatch, ldap.NewString("objectClass"), ldap.NewString("person")),
+				ldap.NewConstructed(ldap.ClassContext, ldap.FilterNot,
+					ldap.NewConstructed(ldap.ClassContext, ldap.FilterEqualityMatch, ldap.NewString("uid"), ldap.NewString("bob")),
+				),
+			),
+		},
+		{
+			s: "(cn=ab*c*d)",
+			exp: ldap.NewConstructed(ldap.ClassContext, ldap.FilterSubstrings, ldap.NewString("cn"), ldap.NewSequence(
+				ldap.NewPrimitive(ldap.ClassContext, ldap.SubstringInitial, []byte("ab")),
+				ldap.NewPrimitive(ldap.ClassContext, ldap.SubstringAny, []byte("c")),
+				ldap.NewPrimitive(ldap.ClassContext, ldap.SubstringFinal, []byte("d")),
+			)),
+		},
+		{
+			s:   "(uidNumber>=1000)",
+			exp: ldap.NewConstructed(ldap.ClassContext, ldap.FilterGreaterOrEqual, ldap.NewString("uidNumber"), ldap.NewString("1000")),
+		},
+		{s: "uid=bob", err: true},
+		{s: "(uid=bob", err: true},
+		{s: "(uid=bob))", err: true},
+		{s: "(=bob)", err: true},
+		{s: `(uid=\4)`, err: true},
+	} {
+		t.Run(tt.s, func(t *testing.T) {
+			p, err := ldap.ParseFilter(tt.s)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p.Bytes(), tt.exp.Bytes()) {
+				t.Fatalf("unexpected filter: got %x, exp %x", p.Bytes(), tt.exp.Bytes())
+			}
+		})
+	}
+}
+
+func TestEscapeFilter(t *testing.T) {
+	if got, exp := ldap.EscapeFilter(`a*(b)\c`), `a\2a\28b\29\5cc`; got != exp {
+		t.Fatalf("unexpected escaped value: got %s, exp %s", got, exp)
+	}
+}
+
+func TestConn(t *testing.T) {
+	s, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.AddEntry(&ldap.Entry{DN: "cn=admin,dc=example,dc=com"}, "secret")
+	s.AddEntry(&ldap.Entry{
+		DN: "uid=bob,ou=people,dc=example,dc=com",
+		Attributes: map[string][]string{
+			"uid":      {"bob"},
+			"memberOf": {"cn=ops,ou=groups,dc=example,dc=com", "cn=dev,ou=groups,dc=example,dc=com"},
+		},
+	}, "hunter2")
+
+	c, err := ldap.Dial(s.Addr(), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Bind("cn=admin,dc=example,dc=com", "wrong"); !ldap.IsInvalidCredentials(err) {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.Bind("cn=admin,dc=example,dc=com", ""); !ldap.IsInvalidCredentials(err) {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.Bind("cn=admin,dc=example,dc=com", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := c.Search(&ldap.SearchRequest{
+		BaseDN:     "dc=example,dc=com",
+		Filter:     "(&(uid=bob)(memberOf=*))",
+		Attributes: []string{"memberof"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 1 {
+		t.Fatalf("unexpected entries: %v", entries)
+	} else if got, exp := entries[0].DN, "uid=bob,ou=people,dc=example,dc=com"; got != exp {
+		t.Fatalf("unexpected dn: got %s, exp %s", got, exp)
+	} else if got := entries[0].Attribute("memberOf"); len(got) != 2 || got[0] != "cn=ops,ou=groups,dc=example,dc=com" {
+		t.Fatalf("unexpected groups: %v", got)
+	}
+
+	if entries, err := c.Search(&ldap.SearchRequest{BaseDN: "dc=example,dc=com", Filter: "(uid=alice)"}); err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 0 {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+
+	if err := c.Bind("uid=bob,ou=people,dc=example,dc=com", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+}
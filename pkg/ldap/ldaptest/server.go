@@ -0,0 +1,234 @@
+// Package ldaptest provides an in-memory LDAP server for tests.
+package ldaptest // import "github.com/influxdata/influxdb/pkg/ldap/ldaptest"
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/influxdb/pkg/ldap"
+)
+
+// Server is an LDAP server that serves a fixed set of entries. It only
+// supports simple binds, and searches with equality, presence, and, or and
+// not filters.
+type Server struct {
+	ln net.Listener
+	wg sync.WaitGroup
+
+	mu        sync.Mutex
+	entries   []*ldap.Entry
+	passwords map[string]string
+	conns     map[net.Conn]struct{}
+
+	binds    int64
+	searches int64
+}
+
+// NewServer returns a server listening on a local port.
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ln:        ln,
+		passwords: make(map[string]string),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address of the server.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// URL returns the URL of the server.
+func (s *Server) URL() string {
+	return "ldap://" + s.Addr()
+}
+
+// AddEntry adds an entry that can bind with the password. An empty password
+// does not allow the entry to bind.
+func (s *Server) AddEntry(entry *ldap.Entry, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	if password != "" {
+		s.passwords[strings.ToLower(entry.DN)] = password
+	}
+}
+
+// SetPassword changes the password of the entry with the DN.
+func (s *Server) SetPassword(dn, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passwords[strings.ToLower(dn)] = password
+}
+
+// Binds returns the number of bind requests that were received.
+func (s *Server) Binds() int {
+	return int(atomic.LoadInt64(&s.binds))
+}
+
+// Searches returns the number of search requests that were received.
+func (s *Server) Searches() int {
+	return int(atomic.LoadInt64(&s.searches))
+}
+
+// Close stops the server and closes the connections of the clients.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := ldap.ReadPacket(r)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, op := msg.Children[0], msg.Children[1]
+
+		var resp []*ldap.Packet
+		switch op.Tag {
+		case ldap.OpBindRequest:
+			atomic.AddInt64(&s.binds, 1)
+			resp = append(resp, s.bind(op))
+		case ldap.OpSearchRequest:
+			atomic.AddInt64(&s.searches, 1)
+			resp = s.search(op)
+		case ldap.OpUnbindRequest:
+			return
+		default:
+			resp = append(resp, result(ldap.OpExtendedResponse, 2, "unsupported operation"))
+		}
+
+		for _, p := range resp {
+			if _, err := conn.Write(ldap.NewSequence(id, p).Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) bind(op *ldap.Packet) *ldap.Packet {
+	dn, password := op.Child(1), op.Child(2)
+	if dn == nil || password == nil {
+		return result(ldap.OpBindResponse, 2, "invalid bind request")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if want, ok := s.passwords[strings.ToLower(dn.String())]; !ok || want != password.String() {
+		return result(ldap.OpBindResponse, ldap.ResultInvalidCredentials, "invalid credentials")
+	}
+	return result(ldap.OpBindResponse, ldap.ResultSuccess, "")
+}
+
+func (s *Server) search(op *ldap.Packet) []*ldap.Packet {
+	if len(op.Children) < 8 {
+		return []*ldap.Packet{result(ldap.OpSearchResultDone, 2, "invalid search request")}
+	}
+	base, filter, attrs := strings.ToLower(op.Children[0].String()), op.Children[6], op.Children[7]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp []*ldap.Packet
+	for _, entry := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.DN), base) || !matches(entry, filter) {
+			continue
+		}
+		attributes := ldap.NewSequence()
+		for _, attr := range attrs.Children {
+			values := ldap.NewConstructed(ldap.ClassUniversal, ldap.TagSet)
+			for _, v := range entry.Attribute(attr.String()) {
+				values.Children = append(values.Children, ldap.NewString(v))
+			}
+			attributes.Children = append(attributes.Children, ldap.NewSequence(ldap.NewString(attr.String()), values))
+		}
+		resp = append(resp, ldap.NewConstructed(ldap.ClassApplication, ldap.OpSearchResultEntry, ldap.NewString(entry.DN), attributes))
+	}
+	return append(resp, result(ldap.OpSearchResultDone, ldap.ResultSuccess, ""))
+}
+
+func matches(entry *ldap.Entry, filter *ldap.Packet) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(entry, child) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matches(entry, child) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return len(filter.Children) == 1 && !matches(entry, filter.Children[0])
+	case ldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, v := range entry.Attribute(filter.Children[0].String()) {
+			if strings.EqualFold(v, filter.Children[1].String()) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(entry.Attribute(filter.String())) > 0
+	default:
+		return false
+	}
+}
+
+func result(tag byte, code int64, message string) *ldap.Packet {
+	return ldap.NewConstructed(ldap.ClassApplication, tag,
+		ldap.NewEnumerated(code),
+		ldap.NewString(""),
+		ldap.NewString(message),
+	)
+}
@@ -0,0 +1,198 @@
+package wasm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Opcodes that have immediates or change the control flow. The numeric
+// instructions are executed by their opcode directly.
+const (
+	opUnreachable  = 0x00
+	opNop          = 0x01
+	opBlock        = 0x02
+	opLoop         = 0x03
+	opIf           = 0x04
+	opElse         = 0x05
+	opEnd          = 0x0B
+	opBr           = 0x0C
+	opBrIf         = 0x0D
+	opBrTable      = 0x0E
+	opReturn       = 0x0F
+	opCall         = 0x10
+	opCallIndirect = 0x11
+	opDrop         = 0x1A
+	opSelect       = 0x1B
+	opSelectT      = 0x1C
+	opLocalGet     = 0x20
+	opLocalSet     = 0x21
+	opLocalTee     = 0x22
+	opGlobalGet    = 0x23
+	opGlobalSet    = 0x24
+	opI32Load      = 0x28
+	opI64Store32   = 0x3E
+	opMemorySize   = 0x3F
+	opMemoryGrow   = 0x40
+	opI32Const     = 0x41
+	opI64Const     = 0x42
+	opF32Const     = 0x43
+	opF64Const     = 0x44
+	opI32Eqz       = 0x45
+	opI64Extend32S = 0xC4
+	opPrefix       = 0xFC
+
+	// The instructions with the 0xFC prefix are stored with the prefix in
+	// the high byte of the opcode.
+	opTruncSat     = opPrefix << 8
+	opMemoryCopy   = opPrefix<<8 | 10
+	opMemoryFill   = opPrefix<<8 | 11
+	opTruncSatLast = opPrefix<<8 | 7
+)
+
+// instr is a decoded instruction. The branch targets of the structured
+// control instructions are resolved when the code is decoded.
+type instr struct {
+	op  uint16
+	imm uint64
+
+	// end is the index of the matching end of block, loop, if and else.
+	// alt is the index of the else of an if, or its end if it has no else.
+	end, alt int
+
+	// arity is the number of results of a block.
+	arity int
+
+	// table holds the labels of br_table. The last label is the default.
+	table []uint32
+}
+
+// compile decodes the instructions of the body of fn.
+func (m *Module) compile(r *reader, fn *function) ([]instr, error) {
+	var code []instr
+	var blocks []int
+	for {
+		if r.err != nil {
+			return nil, r.err
+		} else if r.pos >= len(r.b) {
+			return nil, errors.New("wasm: function body must end with end")
+		}
+
+		in := instr{op: uint16(r.byte())}
+		switch in.op {
+		case opBlock, opLoop, opIf:
+			switch typ := r.byte(); ValueType(typ) {
+			case 0x40:
+			case I32, I64, F32, F64:
+				in.arity = 1
+			default:
+				return nil, fmt.Errorf("wasm: unsupported block type 0x%02x", typ)
+			}
+			if in.op == opLoop {
+				in.arity = 0
+			}
+			blocks = append(blocks, len(code))
+		case opElse:
+			if len(blocks) == 0 || code[blocks[len(blocks)-1]].op != opIf {
+				return nil, errors.New("wasm: else without if")
+			}
+			code[blocks[len(blocks)-1]].alt = len(code)
+		case opEnd:
+			if len(blocks) == 0 {
+				if r.pos != len(r.b) {
+					return nil, errors.New("wasm: instructions after the end of the function")
+				}
+				return append(code, in), nil
+			}
+			start := blocks[len(blocks)-1]
+			blocks = blocks[:len(blocks)-1]
+			code[start].end = len(code)
+			if code[start].op == opIf {
+				if alt := code[start].alt; alt != 0 {
+					code[alt].end = len(code)
+				} else {
+					code[start].alt = len(code)
+				}
+			}
+		case opBr, opBrIf:
+			in.imm = uint64(r.u32())
+			if int(in.imm) > len(blocks) {
+				return nil, fmt.Errorf("wasm: label %d out of range", in.imm)
+			}
+		case opBrTable:
+			n := r.u32()
+			for i := uint32(0); i <= n && r.err == nil; i++ {
+				label := r.u32()
+				if int(label) > len(blocks) {
+					return nil, fmt.Errorf("wasm: label %d out of range", label)
+				}
+				in.table = append(in.table, label)
+			}
+		case opCall:
+			in.imm = uint64(r.u32())
+			if int(in.imm) >= len(m.funcs) {
+				return nil, fmt.Errorf("wasm: function index %d out of range", in.imm)
+			}
+		case opCallIndirect:
+			return nil, errors.New("wasm: call_indirect is not supported")
+		case opSelectT:
+			r.valueTypes()
+			in.op = opSelect
+		case opLocalGet, opLocalSet, opLocalTee:
+			in.imm = uint64(r.u32())
+			if int(in.imm) >= len(fn.locals) {
+				return nil, fmt.Errorf("wasm: local index %d out of range", in.imm)
+			}
+		case opGlobalGet, opGlobalSet:
+			in.imm = uint64(r.u32())
+			if int(in.imm) >= len(m.globals) {
+				return nil, fmt.Errorf("wasm: global index %d out of range", in.imm)
+			} else if in.op == opGlobalSet && !m.globals[in.imm].mutable {
+				return nil, fmt.Errorf("wasm: global %d is immutable", in.imm)
+			}
+		case opMemorySize, opMemoryGrow:
+			r.byte()
+		case opI32Const:
+			in.imm = uint64(uint32(r.s32()))
+		case opI64Const:
+			in.imm = uint64(r.s64())
+		case opF32Const:
+			if b := r.bytes(4); b != nil {
+				in.imm = uint64(binary.LittleEndian.Uint32(b))
+			}
+		case opF64Const:
+			if b := r.bytes(8); b != nil {
+				in.imm = binary.LittleEndian.Uint64(b)
+			}
+		case opPrefix:
+			in.op = opPrefix<<8 | uint16(r.u32())
+			switch in.op {
+			case opMemoryCopy:
+				r.bytes(2)
+			case opMemoryFill:
+				r.byte()
+			default:
+				if in.op > opTruncSatLast {
+					return nil, fmt.Errorf("wasm: unsupported opcode 0xfc %d", in.op&0xFF)
+				}
+			}
+		case opUnreachable, opNop, opReturn, opDrop, opSelect:
+		default:
+			if in.op >= opI32Load && in.op <= opI64Store32 {
+				r.u32() // alignment
+				in.imm = uint64(r.u32())
+			} else if in.op < opI32Eqz || in.op > opI64Extend32S {
+				return nil, fmt.Errorf("wasm: unsupported opcode 0x%02x", in.op)
+			}
+		}
+
+		if isMemoryInstr(in.op) && m.memory == nil {
+			return nil, errors.New("wasm: memory instruction without memory")
+		}
+		code = append(code, in)
+	}
+}
+
+func isMemoryInstr(op uint16) bool {
+	return (op >= opI32Load && op <= opMemoryGrow) || op == opMemoryCopy || op == opMemoryFill
+}
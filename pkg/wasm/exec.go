@@ -0,0 +1,826 @@
+package wasm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Default limits of an instance.
+const (
+	DefaultFuel           = 10000000
+	DefaultMaxMemoryPages = 16
+	DefaultMaxCallDepth   = 1000
+)
+
+// ErrFuelExhausted is returned when a call executes more instructions than
+// the fuel of the instance allows.
+var ErrFuelExhausted = errors.New("wasm: fuel exhausted")
+
+// Trap is the error returned when the execution of a function traps.
+type Trap struct {
+	Reason string
+}
+
+// Error implements the error interface.
+func (t *Trap) Error() string {
+	return "wasm: trap: " + t.Reason
+}
+
+func trap(reason string) *Trap {
+	return &Trap{Reason: reason}
+}
+
+// Limits bound the resources used by an instance. Zero values use the
+// defaults.
+type Limits struct {
+	// Fuel is the maximum number of instructions executed by a call.
+	Fuel int64
+
+	// MaxMemoryPages is the maximum number of pages of the memory.
+	MaxMemoryPages uint32
+
+	// MaxCallDepth is the maximum depth of nested calls.
+	MaxCallDepth int
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.Fuel <= 0 {
+		l.Fuel = DefaultFuel
+	}
+	if l.MaxMemoryPages == 0 {
+		l.MaxMemoryPages = DefaultMaxMemoryPages
+	}
+	if l.MaxCallDepth <= 0 {
+		l.MaxCallDepth = DefaultMaxCallDepth
+	}
+	return l
+}
+
+// Instance is an instantiated module. Values passed to and returned from
+// functions hold the bits of the value: i32 values are zero-extended and
+// floating point values are stored as their IEEE 754 representation.
+//
+// An Instance is not safe for concurrent use.
+type Instance struct {
+	module   *Module
+	limits   Limits
+	memory   []byte
+	maxPages uint32
+	globals  []uint64
+
+	stack []uint64
+	fuel  int64
+	depth int
+}
+
+// NewInstance instantiates the module and runs its start function.
+func NewInstance(m *Module, limits Limits) (*Instance, error) {
+	inst := &Instance{module: m, limits: limits.withDefaults()}
+	if m.memory != nil {
+		inst.maxPages = inst.limits.MaxMemoryPages
+		if m.memory.hasMax && m.memory.max < inst.maxPages {
+			inst.maxPages = m.memory.max
+		}
+		if m.memory.min > inst.maxPages {
+			return nil, fmt.Errorf("wasm: memory of %d pages exceeds the limit of %d pages", m.memory.min, inst.maxPages)
+		}
+		inst.memory = make([]byte, int(m.memory.min)*PageSize)
+	}
+	for _, seg := range m.data {
+		if uint64(seg.offset)+uint64(len(seg.data)) > uint64(len(inst.memory)) {
+			return nil, errors.New("wasm: data segment does not fit in memory")
+		}
+		copy(inst.memory[seg.offset:], seg.data)
+	}
+	for _, g := range m.globals {
+		inst.globals = append(inst.globals, g.init)
+	}
+
+	if m.start >= 0 {
+		if _, err := inst.call(m.funcs[m.start], nil); err != nil {
+			return nil, err
+		}
+	}
+	return inst, nil
+}
+
+// Module returns the module of the instance.
+func (inst *Instance) Module() *Module {
+	return inst.module
+}
+
+// Call calls the exported function with the arguments and returns its
+// result. Zero is returned for functions without a result.
+func (inst *Instance) Call(name string, args ...uint64) (uint64, error) {
+	idx, ok := inst.module.exports[name]
+	if !ok {
+		return 0, fmt.Errorf("wasm: function %q is not exported", name)
+	}
+	fn := inst.module.funcs[idx]
+	if len(args) != len(fn.typ.Params) {
+		return 0, fmt.Errorf("wasm: function %q expects %d arguments, got %d", name, len(fn.typ.Params), len(args))
+	}
+	args = append([]uint64(nil), args...)
+	for i, typ := range fn.typ.Params {
+		if typ == I32 || typ == F32 {
+			args[i] = uint64(uint32(args[i]))
+		}
+	}
+	return inst.call(fn, args)
+}
+
+func (inst *Instance) call(fn *function, args []uint64) (result uint64, err error) {
+	// The code is not type checked when it is decoded, so invalid code can
+	// underflow the stack.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("wasm: invalid code: %v", r)
+		}
+	}()
+
+	inst.stack = append(inst.stack[:0], args...)
+	inst.fuel = inst.limits.Fuel
+	inst.depth = 0
+	if err := inst.exec(fn, 0); err != nil {
+		return 0, err
+	}
+	if len(fn.typ.Results) > 0 {
+		result = inst.stack[0]
+	}
+	return result, nil
+}
+
+// label is the target of a branch.
+type label struct {
+	cont   int
+	height int
+	arity  int
+	loop   bool
+}
+
+// exec executes fn with its arguments on the stack starting at base. The
+// results replace the arguments when it returns.
+func (inst *Instance) exec(fn *function, base int) error {
+	if inst.depth++; inst.depth > inst.limits.MaxCallDepth {
+		return trap("call stack exhausted")
+	}
+	defer func() { inst.depth-- }()
+
+	locals := make([]uint64, len(fn.locals))
+	copy(locals, inst.stack[base:])
+	inst.stack = inst.stack[:base]
+
+	var labels []label
+	code := fn.code
+	for pc := 0; ; {
+		if inst.fuel--; inst.fuel < 0 {
+			return ErrFuelExhausted
+		}
+		in := &code[pc]
+		pc++
+
+		var depth uint64
+		switch in.op {
+		case opUnreachable:
+			return trap("unreachable")
+		case opNop:
+			continue
+		case opBlock:
+			labels = append(labels, label{cont: in.end + 1, height: len(inst.stack), arity: in.arity})
+			continue
+		case opLoop:
+			labels = append(labels, label{cont: pc, height: len(inst.stack), loop: true})
+			continue
+		case opIf:
+			labels = append(labels, label{cont: in.end + 1, height: len(inst.stack) - 1, arity: in.arity})
+			if inst.pop() == 0 {
+				pc = in.alt
+				if code[pc].op == opElse {
+					pc++
+				}
+			}
+			continue
+		case opElse:
+			pc = in.end
+			continue
+		case opEnd:
+			if len(labels) == 0 {
+				return inst.ret(fn, base)
+			}
+			labels = labels[:len(labels)-1]
+			continue
+		case opBr:
+			depth = in.imm
+		case opBrIf:
+			if inst.pop() == 0 {
+				continue
+			}
+			depth = in.imm
+		case opBrTable:
+			i := uint64(uint32(inst.pop()))
+			if i >= uint64(len(in.table)-1) {
+				i = uint64(len(in.table) - 1)
+			}
+			depth = uint64(in.table[i])
+		case opReturn:
+			return inst.ret(fn, base)
+		case opCall:
+			callee := inst.module.funcs[in.imm]
+			if err := inst.exec(callee, len(inst.stack)-len(callee.typ.Params)); err != nil {
+				return err
+			}
+			continue
+		default:
+			if err := inst.step(in, locals); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Branch to the label at depth. The outermost label is the body of
+		// the function.
+		if int(depth) >= len(labels) {
+			return inst.ret(fn, base)
+		}
+		l := labels[len(labels)-1-int(depth)]
+		n := copy(inst.stack[l.height:], inst.stack[len(inst.stack)-l.arity:])
+		inst.stack = inst.stack[:l.height+n]
+		if l.loop {
+			labels = labels[:len(labels)-int(depth)]
+		} else {
+			labels = labels[:len(labels)-1-int(depth)]
+		}
+		pc = l.cont
+	}
+}
+
+// ret moves the results of fn to base.
+func (inst *Instance) ret(fn *function, base int) error {
+	n := copy(inst.stack[base:], inst.stack[len(inst.stack)-len(fn.typ.Results):])
+	inst.stack = inst.stack[:base+n]
+	return nil
+}
+
+func (inst *Instance) push(v uint64) { inst.stack = append(inst.stack, v) }
+
+func (inst *Instance) pop() uint64 {
+	v := inst.stack[len(inst.stack)-1]
+	inst.stack = inst.stack[:len(inst.stack)-1]
+	return v
+}
+
+func (inst *Instance) pushI32(v uint32)  { inst.push(uint64(v)) }
+func (inst *Instance) pushF32(v float32) { inst.push(uint64(math.Float32bits(v))) }
+func (inst *Instance) pushF64(v float64) { inst.push(math.Float64bits(v)) }
+func (inst *Instance) popI32() uint32    { return uint32(inst.pop()) }
+func (inst *Instance) popF32() float32   { return math.Float32frombits(uint32(inst.pop())) }
+func (inst *Instance) popF64() float64   { return math.Float64frombits(inst.pop()) }
+
+func (inst *Instance) pushBool(b bool) {
+	if b {
+		inst.push(1)
+	} else {
+		inst.push(0)
+	}
+}
+
+// step executes an instruction that does not change the control flow.
+func (inst *Instance) step(in *instr, locals []uint64) error {
+	switch op := in.op; {
+	case op >= opI32Load && op <= opI64Store32:
+		return inst.memoryAccess(op, in.imm)
+	case op >= opI32Eqz && op <= 0x66:
+		inst.compare(op)
+		return nil
+	case op >= 0x67 && op <= 0x8A:
+		return inst.integer(op)
+	case op >= 0x8B && op <= 0xA6:
+		inst.float(op)
+		return nil
+	case op >= 0xA7 && op <= opI64Extend32S:
+		return inst.convert(op)
+	case op >= opTruncSat && op <= opTruncSatLast:
+		inst.truncSat(op)
+		return nil
+	default:
+	}
+
+	switch in.op {
+	case opDrop:
+		inst.pop()
+	case opSelect:
+		c := inst.pop()
+		b := inst.pop()
+		if c == 0 {
+			inst.stack[len(inst.stack)-1] = b
+		}
+	case opLocalGet:
+		inst.push(locals[in.imm])
+	case opLocalSet:
+		locals[in.imm] = inst.pop()
+	case opLocalTee:
+		locals[in.imm] = inst.stack[len(inst.stack)-1]
+	case opGlobalGet:
+		inst.push(inst.globals[in.imm])
+	case opGlobalSet:
+		inst.globals[in.imm] = inst.pop()
+	case opMemorySize:
+		inst.pushI32(uint32(len(inst.memory) / PageSize))
+	case opMemoryGrow:
+		n := inst.popI32()
+		pages := uint32(len(inst.memory) / PageSize)
+		if uint64(pages)+uint64(n) > uint64(inst.maxPages) {
+			inst.pushI32(math.MaxUint32)
+			break
+		}
+		inst.memory = append(inst.memory, make([]byte, int(n)*PageSize)...)
+		inst.pushI32(pages)
+	case opMemoryCopy:
+		n, src, dst := uint64(inst.popI32()), uint64(inst.popI32()), uint64(inst.popI32())
+		if src+n > uint64(len(inst.memory)) || dst+n > uint64(len(inst.memory)) {
+			return trap("out of bounds memory access")
+		}
+		copy(inst.memory[dst:dst+n], inst.memory[src:src+n])
+	case opMemoryFill:
+		n, v, dst := uint64(inst.popI32()), byte(inst.popI32()), uint64(inst.popI32())
+		if dst+n > uint64(len(inst.memory)) {
+			return trap("out of bounds memory access")
+		}
+		for i := dst; i < dst+n; i++ {
+			inst.memory[i] = v
+		}
+	case opI32Const, opI64Const, opF32Const, opF64Const:
+		inst.push(in.imm)
+	default:
+		return fmt.Errorf("wasm: unsupported opcode 0x%02x", in.op)
+	}
+	return nil
+}
+
+func (inst *Instance) memoryAccess(op uint16, offset uint64) error {
+	var size uint64
+	switch op {
+	case 0x29, 0x2B, 0x37, 0x39:
+		size = 8
+	case 0x28, 0x2A, 0x34, 0x35, 0x36, 0x38, 0x3E:
+		size = 4
+	case 0x2E, 0x2F, 0x32, 0x33, 0x3B, 0x3D:
+		size = 2
+	default:
+		size = 1
+	}
+
+	var v uint64
+	if op >= 0x36 {
+		v = inst.pop()
+	}
+	addr := uint64(inst.popI32()) + offset
+	if addr+size > uint64(len(inst.memory)) {
+		return trap("out of bounds memory access")
+	}
+	b := inst.memory[addr : addr+size]
+
+	switch op {
+	case 0x28, 0x2A, 0x35: // i32.load, f32.load, i64.load32_u
+		inst.push(uint64(binary.LittleEndian.Uint32(b)))
+	case 0x29, 0x2B: // i64.load, f64.load
+		inst.push(binary.LittleEndian.Uint64(b))
+	case 0x2C: // i32.load8_s
+		inst.pushI32(uint32(int8(b[0])))
+	case 0x2D, 0x31: // i32.load8_u, i64.load8_u
+		inst.push(uint64(b[0]))
+	case 0x2E: // i32.load16_s
+		inst.pushI32(uint32(int16(binary.LittleEndian.Uint16(b))))
+	case 0x2F, 0x33: // i32.load16_u, i64.load16_u
+		inst.push(uint64(binary.LittleEndian.Uint16(b)))
+	case 0x30: // i64.load8_s
+		inst.push(uint64(int8(b[0])))
+	case 0x32: // i64.load16_s
+		inst.push(uint64(int16(binary.LittleEndian.Uint16(b))))
+	case 0x34: // i64.load32_s
+		inst.push(uint64(int32(binary.LittleEndian.Uint32(b))))
+	case 0x36, 0x38, 0x3E: // i32.store, f32.store, i64.store32
+		binary.LittleEndian.PutUint32(b, uint32(v))
+	case 0x37, 0x39: // i64.store, f64.store
+		binary.LittleEndian.PutUint64(b, v)
+	case 0x3A, 0x3C: // i32.store8, i64.store8
+		b[0] = byte(v)
+	case 0x3B, 0x3D: // i32.store16, i64.store16
+		binary.LittleEndian.PutUint16(b, uint16(v))
+	default:
+	}
+	return nil
+}
+
+func (inst *Instance) compare(op uint16) {
+	switch {
+	case op == 0x45: // i32.eqz
+		inst.pushBool(inst.popI32() == 0)
+	case op <= 0x4F:
+		b, a := inst.popI32(), inst.popI32()
+		inst.pushBool(compareInt(op-0x46, uint64(a), uint64(b), int64(int32(a)), int64(int32(b))))
+	case op == 0x50: // i64.eqz
+		inst.pushBool(inst.pop() == 0)
+	case op <= 0x5A:
+		b, a := inst.pop(), inst.pop()
+		inst.pushBool(compareInt(op-0x51, a, b, int64(a), int64(b)))
+	case op <= 0x60:
+		b, a := inst.popF32(), inst.popF32()
+		inst.pushBool(compareFloat(op-0x5B, float64(a), float64(b)))
+	default:
+		b, a := inst.popF64(), inst.popF64()
+		inst.pushBool(compareFloat(op-0x61, a, b))
+	}
+}
+
+// compareInt compares two integers with the comparison at the offset from
+// the eq instruction of its type.
+func compareInt(op uint16, a, b uint64, sa, sb int64) bool {
+	switch op {
+	case 0:
+		return a == b
+	case 1:
+		return a != b
+	case 2:
+		return sa < sb
+	case 3:
+		return a < b
+	case 4:
+		return sa > sb
+	case 5:
+		return a > b
+	case 6:
+		return sa <= sb
+	case 7:
+		return a <= b
+	case 8:
+		return sa >= sb
+	default:
+		return a >= b
+	}
+}
+
+// compareFloat compares two floats with the comparison at the offset from
+// the eq instruction of its type.
+func compareFloat(op uint16, a, b float64) bool {
+	switch op {
+	case 0:
+		return a == b
+	case 1:
+		return a != b
+	case 2:
+		return a < b
+	case 3:
+		return a > b
+	case 4:
+		return a <= b
+	default:
+		return a >= b
+	}
+}
+
+func (inst *Instance) integer(op uint16) error {
+	switch op {
+	case 0x67: // i32.clz
+		inst.pushI32(uint32(bits.LeadingZeros32(inst.popI32())))
+	case 0x68: // i32.ctz
+		inst.pushI32(uint32(bits.TrailingZeros32(inst.popI32())))
+	case 0x69: // i32.popcnt
+		inst.pushI32(uint32(bits.OnesCount32(inst.popI32())))
+	case 0x79: // i64.clz
+		inst.push(uint64(bits.LeadingZeros64(inst.pop())))
+	case 0x7A: // i64.ctz
+		inst.push(uint64(bits.TrailingZeros64(inst.pop())))
+	case 0x7B: // i64.popcnt
+		inst.push(uint64(bits.OnesCount64(inst.pop())))
+	default:
+		if op < 0x79 {
+			b, a := inst.popI32(), inst.popI32()
+			v, err := binary32(op, a, b)
+			if err != nil {
+				return err
+			}
+			inst.pushI32(v)
+		} else {
+			b, a := inst.pop(), inst.pop()
+			v, err := binary64(op, a, b)
+			if err != nil {
+				return err
+			}
+			inst.push(v)
+		}
+	}
+	return nil
+}
+
+func binary32(op uint16, a, b uint32) (uint32, error) {
+	switch op {
+	case 0x6A:
+		return a + b, nil
+	case 0x6B:
+		return a - b, nil
+	case 0x6C:
+		return a * b, nil
+	case 0x6D, 0x6E, 0x6F, 0x70:
+		if b == 0 {
+			return 0, trap("integer divide by zero")
+		}
+		switch op {
+		case 0x6D:
+			if int32(a) == math.MinInt32 && int32(b) == -1 {
+				return 0, trap("integer overflow")
+			}
+			return uint32(int32(a) / int32(b)), nil
+		case 0x6E:
+			return a / b, nil
+		case 0x6F:
+			return uint32(int32(a) % int32(b)), nil
+		default:
+			return a % b, nil
+		}
+	case 0x71:
+		return a & b, nil
+	case 0x72:
+		return a | b, nil
+	case 0x73:
+		return a ^ b, nil
+	case 0x74:
+		return a << (b % 32), nil
+	case 0x75:
+		return uint32(int32(a) >> (b % 32)), nil
+	case 0x76:
+		return a >> (b % 32), nil
+	case 0x77:
+		return bits.RotateLeft32(a, int(b%32)), nil
+	default:
+		return bits.RotateLeft32(a, -int(b%32)), nil
+	}
+}
+
+func binary64(op uint16, a, b uint64) (uint64, error) {
+	switch op {
+	case 0x7C:
+		return a + b, nil
+	case 0x7D:
+		return a - b, nil
+	case 0x7E:
+		return a * b, nil
+	case 0x7F, 0x80, 0x81, 0x82:
+		if b == 0 {
+			return 0, trap("integer divide by zero")
+		}
+		switch op {
+		case 0x7F:
+			if int64(a) == math.MinInt64 && int64(b) == -1 {
+				return 0, trap("integer overflow")
+			}
+			return uint64(int64(a) / int64(b)), nil
+		case 0x80:
+			return a / b, nil
+		case 0x81:
+			return uint64(int64(a) % int64(b)), nil
+		default:
+			return a % b, nil
+		}
+	case 0x83:
+		return a & b, nil
+	case 0x84:
+		return a | b, nil
+	case 0x85:
+		return a ^ b, nil
+	case 0x86:
+		return a << (b % 64), nil
+	case 0x87:
+		return uint64(int64(a) >> (b % 64)), nil
+	case 0x88:
+		return a >> (b % 64), nil
+	case 0x89:
+		return bits.RotateLeft64(a, int(b%64)), nil
+	default:
+		return bits.RotateLeft64(a, -int(b%64)), nil
+	}
+}
+
+func (inst *Instance) float(op uint16) {
+	// The sign operations change the bits directly so they preserve NaNs.
+	switch op {
+	case 0x8B: // f32.abs
+		inst.push(inst.pop() &^ (1 << 31))
+		return
+	case 0x8C: // f32.neg
+		inst.push(inst.pop() ^ (1 << 31))
+		return
+	case 0x98: // f32.copysign
+		b, a := inst.pop(), inst.pop()
+		inst.push(a&^(1<<31) | b&(1<<31))
+		return
+	case 0x99: // f64.abs
+		inst.push(inst.pop() &^ (1 << 63))
+		return
+	case 0x9A: // f64.neg
+		inst.push(inst.pop() ^ (1 << 63))
+		return
+	case 0xA6: // f64.copysign
+		b, a := inst.pop(), inst.pop()
+		inst.push(a&^(1<<63) | b&(1<<63))
+		return
+	default:
+	}
+
+	if op <= 0x98 {
+		if op <= 0x91 {
+			inst.pushF32(float32(unaryFloat(op-0x8B, float64(inst.popF32()))))
+		} else {
+			b, a := inst.popF32(), inst.popF32()
+			switch op {
+			case 0x92:
+				inst.pushF32(a + b)
+			case 0x93:
+				inst.pushF32(a - b)
+			case 0x94:
+				inst.pushF32(a * b)
+			case 0x95:
+				inst.pushF32(a / b)
+			case 0x96:
+				inst.pushF32(min(a, b))
+			default:
+				inst.pushF32(max(a, b))
+			}
+		}
+		return
+	}
+
+	if op <= 0x9F {
+		inst.pushF64(unaryFloat(op-0x99, inst.popF64()))
+	} else {
+		b, a := inst.popF64(), inst.popF64()
+		switch op {
+		case 0xA0:
+			inst.pushF64(a + b)
+		case 0xA1:
+			inst.pushF64(a - b)
+		case 0xA2:
+			inst.pushF64(a * b)
+		case 0xA3:
+			inst.pushF64(a / b)
+		case 0xA4:
+			inst.pushF64(min(a, b))
+		default:
+			inst.pushF64(max(a, b))
+		}
+	}
+}
+
+// unaryFloat applies the unary operation at the offset from the abs
+// instruction of its type. The results are exact for float32 values.
+func unaryFloat(op uint16, v float64) float64 {
+	switch op {
+	case 2:
+		return math.Ceil(v)
+	case 3:
+		return math.Floor(v)
+	case 4:
+		return math.Trunc(v)
+	case 5:
+		return math.RoundToEven(v)
+	default:
+		return math.Sqrt(v)
+	}
+}
+
+func (inst *Instance) convert(op uint16) error {
+	switch op {
+	case 0xA7: // i32.wrap_i64
+		inst.pushI32(uint32(inst.pop()))
+	case 0xA8, 0xA9, 0xAE, 0xAF: // trunc_f32
+		v, err := truncate(op, float64(inst.popF32()))
+		if err != nil {
+			return err
+		}
+		inst.push(v)
+	case 0xAA, 0xAB, 0xB0, 0xB1: // trunc_f64
+		v, err := truncate(op, inst.popF64())
+		if err != nil {
+			return err
+		}
+		inst.push(v)
+	case 0xAC: // i64.extend_i32_s
+		inst.push(uint64(int32(inst.popI32())))
+	case 0xAD: // i64.extend_i32_u
+		inst.push(uint64(inst.popI32()))
+	case 0xB2:
+		inst.pushF32(float32(int32(inst.popI32())))
+	case 0xB3:
+		inst.pushF32(float32(inst.popI32()))
+	case 0xB4:
+		inst.pushF32(float32(int64(inst.pop())))
+	case 0xB5:
+		inst.pushF32(float32(inst.pop()))
+	case 0xB6: // f32.demote_f64
+		inst.pushF32(float32(inst.popF64()))
+	case 0xB7:
+		inst.pushF64(float64(int32(inst.popI32())))
+	case 0xB8:
+		inst.pushF64(float64(inst.popI32()))
+	case 0xB9:
+		inst.pushF64(float64(int64(inst.pop())))
+	case 0xBA:
+		inst.pushF64(float64(inst.pop()))
+	case 0xBB: // f64.promote_f32
+		inst.pushF64(float64(inst.popF32()))
+	case 0xBC, 0xBD, 0xBE, 0xBF:
+		// The reinterpretations do not change the bits of the value.
+	case 0xC0: // i32.extend8_s
+		inst.pushI32(uint32(int8(inst.popI32())))
+	case 0xC1: // i32.extend16_s
+		inst.pushI32(uint32(int16(inst.popI32())))
+	case 0xC2: // i64.extend8_s
+		inst.push(uint64(int8(inst.pop())))
+	case 0xC3: // i64.extend16_s
+		inst.push(uint64(int16(inst.pop())))
+	default: // i64.extend32_s
+		inst.push(uint64(int32(inst.pop())))
+	}
+	return nil
+}
+
+// truncRange is the exclusive range of the floats that can be truncated to
+// an integer type.
+type truncRange struct {
+	lo, hi float64
+	signed bool
+	i32    bool
+}
+
+func truncRangeOf(op uint16) truncRange {
+	switch op {
+	case 0xA8, 0xAA:
+		return truncRange{lo: -2147483649, hi: 2147483648, signed: true, i32: true}
+	case 0xA9, 0xAB:
+		return truncRange{lo: -1, hi: 4294967296, i32: true}
+	case 0xAE, 0xB0:
+		return truncRange{lo: math.Nextafter(-9223372036854775808, math.Inf(-1)), hi: 9223372036854775808, signed: true}
+	default:
+		return truncRange{lo: -1, hi: 18446744073709551616}
+	}
+}
+
+func (r truncRange) convert(v float64) uint64 {
+	switch {
+	case r.signed && r.i32:
+		return uint64(uint32(int32(v)))
+	case r.signed:
+		return uint64(int64(v))
+	default:
+		// Truncate first because negative floats cannot be converted to
+		// unsigned integers.
+		return uint64(math.Trunc(v))
+	}
+}
+
+func truncate(op uint16, v float64) (uint64, error) {
+	r := truncRangeOf(op)
+	if math.IsNaN(v) {
+		return 0, trap("invalid conversion to integer")
+	} else if v <= r.lo || v >= r.hi {
+		return 0, trap("integer overflow")
+	}
+	return r.convert(v), nil
+}
+
+// truncSat executes the saturating truncations.
+func (inst *Instance) truncSat(op uint16) {
+	// Map the instruction to the trapping truncation of the same types.
+	trapping := [...]uint16{0xA8, 0xA9, 0xAA, 0xAB, 0xAE, 0xAF, 0xB0, 0xB1}[op-opTruncSat]
+	var v float64
+	if op-opTruncSat == 0 || op-opTruncSat == 1 || op-opTruncSat == 4 || op-opTruncSat == 5 {
+		v = float64(inst.popF32())
+	} else {
+		v = inst.popF64()
+	}
+
+	r := truncRangeOf(trapping)
+	switch {
+	case math.IsNaN(v):
+		inst.push(0)
+	case v <= r.lo && r.signed && r.i32:
+		inst.pushI32(uint32(1 << 31))
+	case v <= r.lo && r.signed:
+		inst.push(1 << 63)
+	case v <= r.lo:
+		inst.push(0)
+	case v >= r.hi && r.signed && r.i32:
+		inst.pushI32(math.MaxInt32)
+	case v >= r.hi && r.signed:
+		inst.push(math.MaxInt64)
+	case v >= r.hi && r.i32:
+		inst.pushI32(math.MaxUint32)
+	case v >= r.hi:
+		inst.push(math.MaxUint64)
+	default:
+		inst.push(r.convert(v))
+	}
+}
@@ -0,0 +1,459 @@
+// Package wasm implements a sandboxed interpreter for WebAssembly modules.
+//
+// Only the numeric subset of the WebAssembly 1.0 specification is supported:
+// modules cannot import functions, tables, memories or globals, and they
+// cannot use tables. Every call runs with a fuel budget that bounds the
+// number of instructions it executes and memories cannot grow beyond the
+// configured number of pages.
+package wasm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ValueType is the type of a WebAssembly value.
+type ValueType byte
+
+// The value types of WebAssembly.
+const (
+	I32 ValueType = 0x7F
+	I64 ValueType = 0x7E
+	F32 ValueType = 0x7D
+	F64 ValueType = 0x7C
+)
+
+// String returns the name of the value type.
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	case F32:
+		return "f32"
+	case F64:
+		return "f64"
+	default:
+		return fmt.Sprintf("valtype(0x%02x)", byte(t))
+	}
+}
+
+// FuncType is the signature of a function.
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+// PageSize is the size of a page of linear memory.
+const PageSize = 65536
+
+// maxLocals is the maximum number of locals of a function.
+const maxLocals = 50000
+
+// Module is a decoded WebAssembly module.
+type Module struct {
+	types   []FuncType
+	funcs   []*function
+	memory  *limits
+	globals []global
+	exports map[string]uint32
+	start   int
+	data    []segment
+}
+
+type function struct {
+	typ    FuncType
+	locals []ValueType
+	code   []instr
+}
+
+type limits struct {
+	min, max uint32
+	hasMax   bool
+}
+
+type global struct {
+	typ     ValueType
+	mutable bool
+	init    uint64
+}
+
+type segment struct {
+	offset uint32
+	data   []byte
+}
+
+// Decode decodes a module in the WebAssembly binary format.
+func Decode(b []byte) (*Module, error) {
+	r := &reader{b: b}
+	if magic := r.bytes(4); !bytes.Equal(magic, []byte("\x00asm")) {
+		return nil, errors.New("wasm: invalid magic number")
+	} else if version := r.bytes(4); r.err == nil && binary.LittleEndian.Uint32(version) != 1 {
+		return nil, fmt.Errorf("wasm: unsupported version %d", binary.LittleEndian.Uint32(version))
+	}
+
+	m := &Module{exports: make(map[string]uint32), start: -1}
+	var typeIndexes []uint32
+	for r.err == nil && r.pos < len(r.b) {
+		id := r.byte()
+		size := r.u32()
+		body := &reader{b: r.bytes(int(size))}
+		if r.err != nil {
+			break
+		}
+
+		var err error
+		switch id {
+		case 0:
+			// Custom sections do not change the semantics of the module.
+		case 1:
+			err = m.decodeTypes(body)
+		case 2:
+			err = errors.New("wasm: imports are not supported")
+		case 3:
+			typeIndexes, err = decodeFunctions(body, len(m.types))
+		case 4:
+			err = errors.New("wasm: tables are not supported")
+		case 5:
+			err = m.decodeMemory(body)
+		case 6:
+			err = m.decodeGlobals(body)
+		case 7:
+			err = m.decodeExports(body)
+		case 8:
+			m.start = int(body.u32())
+			err = body.err
+		case 9:
+			err = errors.New("wasm: element segments are not supported")
+		case 10:
+			err = m.decodeCode(body, typeIndexes)
+		case 11:
+			err = m.decodeData(body)
+		case 12:
+			body.u32()
+			err = body.err
+		default:
+			err = fmt.Errorf("wasm: unknown section %d", id)
+		}
+		if err != nil {
+			return nil, err
+		} else if id != 0 && body.pos != len(body.b) {
+			return nil, fmt.Errorf("wasm: section %d size mismatch", id)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	} else if len(typeIndexes) != len(m.funcs) {
+		return nil, errors.New("wasm: function and code section have inconsistent lengths")
+	}
+
+	for name, idx := range m.exports {
+		if int(idx) >= len(m.funcs) {
+			return nil, fmt.Errorf("wasm: exported function %q out of range", name)
+		}
+	}
+	if m.start >= len(m.funcs) {
+		return nil, errors.New("wasm: start function out of range")
+	} else if m.start >= 0 && len(m.funcs[m.start].typ.Params)+len(m.funcs[m.start].typ.Results) > 0 {
+		return nil, errors.New("wasm: start function must not have parameters or results")
+	}
+	if len(m.data) > 0 && m.memory == nil {
+		return nil, errors.New("wasm: data segment without memory")
+	}
+	return m, nil
+}
+
+// Functions returns the names of the exported functions.
+func (m *Module) Functions() []string {
+	names := make([]string, 0, len(m.exports))
+	for name := range m.exports {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Func returns the signature of the exported function.
+func (m *Module) Func(name string) (FuncType, bool) {
+	idx, ok := m.exports[name]
+	if !ok {
+		return FuncType{}, false
+	}
+	return m.funcs[idx].typ, true
+}
+
+func (m *Module) decodeTypes(r *reader) error {
+	n := r.u32()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		if form := r.byte(); form != 0x60 {
+			return fmt.Errorf("wasm: invalid function type form 0x%02x", form)
+		}
+		var typ FuncType
+		typ.Params = r.valueTypes()
+		typ.Results = r.valueTypes()
+		if len(typ.Results) > 1 {
+			return errors.New("wasm: multiple results are not supported")
+		}
+		m.types = append(m.types, typ)
+	}
+	return r.err
+}
+
+func decodeFunctions(r *reader, ntypes int) ([]uint32, error) {
+	n := r.u32()
+	var indexes []uint32
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		idx := r.u32()
+		if int(idx) >= ntypes {
+			return nil, fmt.Errorf("wasm: type index %d out of range", idx)
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, r.err
+}
+
+func (m *Module) decodeMemory(r *reader) error {
+	if n := r.u32(); n > 1 {
+		return errors.New("wasm: multiple memories are not supported")
+	} else if n == 0 {
+		return r.err
+	}
+	l := r.limits()
+	if l.min > math.MaxUint16+1 || (l.hasMax && (l.max > math.MaxUint16+1 || l.max < l.min)) {
+		return errors.New("wasm: invalid memory limits")
+	}
+	m.memory = &l
+	return r.err
+}
+
+func (m *Module) decodeGlobals(r *reader) error {
+	n := r.u32()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		var g global
+		g.typ = ValueType(r.byte())
+		g.mutable = r.byte() == 1
+		init, err := m.constExpr(r)
+		if err != nil {
+			return err
+		}
+		g.init = init
+		m.globals = append(m.globals, g)
+	}
+	return r.err
+}
+
+func (m *Module) decodeExports(r *reader) error {
+	n := r.u32()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		name := string(r.bytes(int(r.u32())))
+		kind := r.byte()
+		idx := r.u32()
+		if kind == 0 {
+			m.exports[name] = idx
+		}
+	}
+	return r.err
+}
+
+func (m *Module) decodeCode(r *reader, typeIndexes []uint32) error {
+	n := r.u32()
+	if int(n) != len(typeIndexes) {
+		return errors.New("wasm: function and code section have inconsistent lengths")
+	}
+
+	// Allocate the functions first so calls can be resolved while decoding.
+	m.funcs = make([]*function, n)
+	for i := range m.funcs {
+		m.funcs[i] = &function{typ: m.types[typeIndexes[i]]}
+	}
+	for _, fn := range m.funcs {
+		body := &reader{b: r.bytes(int(r.u32()))}
+		if r.err != nil {
+			return r.err
+		}
+
+		nlocals := len(fn.typ.Params)
+		fn.locals = append(fn.locals, fn.typ.Params...)
+		for j, groups := uint32(0), body.u32(); j < groups && body.err == nil; j++ {
+			count, typ := body.u32(), ValueType(body.byte())
+			if nlocals += int(count); nlocals > maxLocals {
+				return errors.New("wasm: too many locals")
+			}
+			for k := uint32(0); k < count; k++ {
+				fn.locals = append(fn.locals, typ)
+			}
+		}
+		code, err := m.compile(body, fn)
+		if err != nil {
+			return err
+		}
+		fn.code = code
+	}
+	return r.err
+}
+
+func (m *Module) decodeData(r *reader) error {
+	n := r.u32()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		if flags := r.u32(); flags != 0 {
+			return errors.New("wasm: passive data segments are not supported")
+		}
+		offset, err := m.constExpr(r)
+		if err != nil {
+			return err
+		}
+		m.data = append(m.data, segment{offset: uint32(offset), data: r.bytes(int(r.u32()))})
+	}
+	return r.err
+}
+
+// constExpr decodes a constant expression. Only a single constant
+// instruction or the value of a previous immutable global is supported.
+func (m *Module) constExpr(r *reader) (uint64, error) {
+	var v uint64
+	switch op := r.byte(); op {
+	case opI32Const:
+		v = uint64(uint32(r.s32()))
+	case opI64Const:
+		v = uint64(r.s64())
+	case opF32Const:
+		if b := r.bytes(4); b != nil {
+			v = uint64(binary.LittleEndian.Uint32(b))
+		}
+	case opF64Const:
+		if b := r.bytes(8); b != nil {
+			v = binary.LittleEndian.Uint64(b)
+		}
+	case opGlobalGet:
+		idx := r.u32()
+		if int(idx) >= len(m.globals) {
+			return 0, fmt.Errorf("wasm: global index %d out of range", idx)
+		}
+		v = m.globals[idx].init
+	default:
+		return 0, fmt.Errorf("wasm: unsupported constant expression opcode 0x%02x", op)
+	}
+	if op := r.byte(); r.err != nil {
+		return 0, r.err
+	} else if op != opEnd {
+		return 0, errors.New("wasm: constant expression must end after one instruction")
+	}
+	return v, nil
+}
+
+// reader reads the primitive encodings of the binary format. The first
+// error is recorded and subsequent reads return zero values.
+type reader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *reader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	} else if r.pos >= len(r.b) {
+		r.fail(io.ErrUnexpectedEOF)
+		return 0
+	}
+	b := r.b[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	} else if n < 0 || r.pos+n > len(r.b) {
+		r.fail(io.ErrUnexpectedEOF)
+		return nil
+	}
+	b := r.b[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) u32() uint32 {
+	v := r.uleb(32)
+	return uint32(v)
+}
+
+func (r *reader) uleb(bits uint) uint64 {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		} else if shift >= bits {
+			r.fail(errors.New("wasm: integer too large"))
+			return 0
+		}
+		v |= uint64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+}
+
+func (r *reader) sleb(bits uint) int64 {
+	var v int64
+	var shift uint
+	for {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		} else if shift >= bits {
+			r.fail(errors.New("wasm: integer too large"))
+			return 0
+		}
+		v |= int64(b&0x7F) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				v |= -1 << shift
+			}
+			return v
+		}
+	}
+}
+
+func (r *reader) s32() int32 { return int32(r.sleb(32)) }
+func (r *reader) s64() int64 { return r.sleb(64) }
+
+func (r *reader) valueTypes() []ValueType {
+	n := r.u32()
+	var types []ValueType
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		typ := ValueType(r.byte())
+		switch typ {
+		case I32, I64, F32, F64:
+		default:
+			r.fail(fmt.Errorf("wasm: unsupported value type 0x%02x", byte(typ)))
+		}
+		types = append(types, typ)
+	}
+	return types
+}
+
+func (r *reader) limits() limits {
+	var l limits
+	switch flag := r.byte(); flag {
+	case 0:
+		l.min = r.u32()
+	case 1:
+		l.min, l.max, l.hasMax = r.u32(), r.u32(), true
+	default:
+		r.fail(fmt.Errorf("wasm: invalid limits flag 0x%02x", flag))
+	}
+	return l
+}
@@ -0,0 +1,323 @@
+package wasm_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/wasm"
+)
+
+const (
+	i32 = 0x7F
+	i64 = 0x7E
+	f64 = 0x7C
+)
+
+// fn is a function of a test module.
+type fn struct {
+	name    string
+	params  []byte
+	results []byte
+	locals  []byte
+	body    []byte
+}
+
+// leb encodes an unsigned LEB128 integer.
+func leb(v uint64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7F)
+		if v >>= 7; v != 0 {
+			b = append(b, c|0x80)
+			continue
+		}
+		return append(b, c)
+	}
+}
+
+func section(id byte, items ...[]byte) []byte {
+	payload := leb(uint64(len(items)))
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(append([]byte{id}, leb(uint64(len(payload)))...), payload...)
+}
+
+func vec(b []byte) []byte {
+	return append(leb(uint64(len(b))), b...)
+}
+
+// module assembles a module with the functions. The functions are exported
+// with their names and memory is the optional memory section payload.
+func module(memory []byte, fns ...fn) []byte {
+	b := []byte("\x00asm\x01\x00\x00\x00")
+
+	var types, funcs, exports, code [][]byte
+	for i, f := range fns {
+		types = append(types, append(append([]byte{0x60}, vec(f.params)...), vec(f.results)...))
+		funcs = append(funcs, leb(uint64(i)))
+		if f.name != "" {
+			exports = append(exports, append(vec([]byte(f.name)), append([]byte{0x00}, leb(uint64(i))...)...))
+		}
+
+		var body []byte
+		if len(f.locals) > 0 {
+			body = append(body, 1, byte(len(f.locals)), f.locals[0])
+		} else {
+			body = append(body, 0)
+		}
+		body = append(body, f.body...)
+		code = append(code, vec(body))
+	}
+	b = append(b, section(1, types...)...)
+	b = append(b, section(3, funcs...)...)
+	if memory != nil {
+		b = append(b, section(5, memory)...)
+	}
+	b = append(b, section(7, exports...)...)
+	return append(b, section(10, code...)...)
+}
+
+func instantiate(t *testing.T, b []byte, limits wasm.Limits) *wasm.Instance {
+	t.Helper()
+	m, err := wasm.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst, err := wasm.NewInstance(m, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inst
+}
+
+func TestInstance_Call(t *testing.T) {
+	inst := instantiate(t, module(nil,
+		fn{
+			name:    "add",
+			params:  []byte{i32, i32},
+			results: []byte{i32},
+			body:    []byte{0x20, 0, 0x20, 1, 0x6A, 0x0B},
+		},
+		// fac(n) = n == 0 ? 1 : n * fac(n-1)
+		fn{
+			name:    "fac",
+			params:  []byte{i64},
+			results: []byte{i64},
+			body: []byte{
+				0x20, 0, 0x50, // local.get 0; i64.eqz
+				0x04, i64, // if (result i64)
+				0x42, 1, // i64.const 1
+				0x05,    // else
+				0x20, 0, // local.get 0
+				0x20, 0, 0x42, 1, 0x7D, // local.get 0; i64.const 1; i64.sub
+				0x10, 1, // call fac
+				0x7E, // i64.mul
+				0x0B, 0x0B,
+			},
+		},
+		// sum(n) adds the integers from 1 to n with a loop.
+		fn{
+			name:    "sum",
+			params:  []byte{i64},
+			results: []byte{i64},
+			locals:  []byte{i64},
+			body: []byte{
+				0x02, 0x40, // block
+				0x03, 0x40, // loop
+				0x20, 0, 0x50, 0x0D, 1, // local.get 0; i64.eqz; br_if 1
+				0x20, 1, 0x20, 0, 0x7C, 0x21, 1, // local.get 1; local.get 0; i64.add; local.set 1
+				0x20, 0, 0x42, 1, 0x7D, 0x21, 0, // local.get 0; i64.const 1; i64.sub; local.set 0
+				0x0C, 0, // br 0
+				0x0B, 0x0B,
+				0x20, 1, 0x0B,
+			},
+		},
+		// pick(i) returns 10, 20 or 30 for 0, 1 and other values.
+		fn{
+			name:    "pick",
+			params:  []byte{i32},
+			results: []byte{i32},
+			body: []byte{
+				0x02, 0x40, 0x02, 0x40, 0x02, 0x40, // block; block; block
+				0x20, 0, 0x0E, 2, 0, 1, 2, // local.get 0; br_table 0 1 2
+				0x0B, 0x41, 10, 0x0F, // end; i32.const 10; return
+				0x0B, 0x41, 20, 0x0F, // end; i32.const 20; return
+				0x0B, 0x41, 30, 0x0B, // end; i32.const 30
+			},
+		},
+		fn{
+			name:    "hypot",
+			params:  []byte{f64, f64},
+			results: []byte{f64},
+			body: []byte{
+				0x20, 0, 0x20, 0, 0xA2, // x*x
+				0x20, 1, 0x20, 1, 0xA2, // y*y
+				0xA0, 0x9F, 0x0B, // add; sqrt
+			},
+		},
+	), wasm.Limits{})
+
+	for _, tt := range []struct {
+		name string
+		args []uint64
+		exp  uint64
+	}{
+		{name: "add", args: []uint64{2, 3}, exp: 5},
+		{name: "add", args: []uint64{uint64(uint32(math.MaxUint32)), 2}, exp: 1},
+		{name: "fac", args: []uint64{20}, exp: 2432902008176640000},
+		{name: "sum", args: []uint64{100}, exp: 5050},
+		{name: "pick", args: []uint64{0}, exp: 10},
+		{name: "pick", args: []uint64{1}, exp: 20},
+		{name: "pick", args: []uint64{7}, exp: 30},
+		{name: "hypot", args: []uint64{math.Float64bits(3), math.Float64bits(4)}, exp: math.Float64bits(5)},
+	} {
+		if got, err := inst.Call(tt.name, tt.args...); err != nil {
+			t.Errorf("%s%v: unexpected error: %s", tt.name, tt.args, err)
+		} else if got != tt.exp {
+			t.Errorf("%s%v: unexpected result: exp=%d got=%d", tt.name, tt.args, tt.exp, got)
+		}
+	}
+
+	if _, err := inst.Call("add", 1); err == nil || err.Error() != `wasm: function "add" expects 2 arguments, got 1` {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := inst.Call("missing"); err == nil || err.Error() != `wasm: function "missing" is not exported` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestInstance_Call_Trap(t *testing.T) {
+	inst := instantiate(t, module([]byte{0x00, 1},
+		fn{
+			name:    "div",
+			params:  []byte{i32, i32},
+			results: []byte{i32},
+			body:    []byte{0x20, 0, 0x20, 1, 0x6D, 0x0B},
+		},
+		fn{
+			name: "unreachable",
+			body: []byte{0x00, 0x0B},
+		},
+		fn{
+			name:    "load",
+			params:  []byte{i32},
+			results: []byte{i32},
+			body:    []byte{0x20, 0, 0x28, 2, 0, 0x0B},
+		},
+		fn{
+			name:    "trunc",
+			params:  []byte{f64},
+			results: []byte{i32},
+			body:    []byte{0x20, 0, 0xAA, 0x0B},
+		},
+	), wasm.Limits{})
+
+	for _, tt := range []struct {
+		name string
+		args []uint64
+		err  string
+	}{
+		{name: "div", args: []uint64{1, 0}, err: "wasm: trap: integer divide by zero"},
+		{name: "div", args: []uint64{1 << 31, uint64(uint32(math.MaxUint32))}, err: "wasm: trap: integer overflow"},
+		{name: "unreachable", err: "wasm: trap: unreachable"},
+		{name: "load", args: []uint64{wasm.PageSize - 2}, err: "wasm: trap: out of bounds memory access"},
+		{name: "trunc", args: []uint64{math.Float64bits(math.NaN())}, err: "wasm: trap: invalid conversion to integer"},
+		{name: "trunc", args: []uint64{math.Float64bits(1e10)}, err: "wasm: trap: integer overflow"},
+	} {
+		_, err := inst.Call(tt.name, tt.args...)
+		var trap *wasm.Trap
+		if !errors.As(err, &trap) || err.Error() != tt.err {
+			t.Errorf("%s%v: unexpected error: exp=%s got=%v", tt.name, tt.args, tt.err, err)
+		}
+	}
+
+	// The instance can still be used after a trap.
+	if got, err := inst.Call("div", 7, 2); err != nil || got != 3 {
+		t.Errorf("unexpected result: %d, %v", got, err)
+	}
+}
+
+func TestInstance_Limits(t *testing.T) {
+	b := module([]byte{0x00, 1},
+		fn{
+			name: "spin",
+			body: []byte{0x03, 0x40, 0x0C, 0, 0x0B, 0x0B},
+		},
+		fn{
+			name: "recurse",
+			body: []byte{0x10, 1, 0x0B},
+		},
+		fn{
+			name:    "grow",
+			params:  []byte{i32},
+			results: []byte{i32},
+			body:    []byte{0x20, 0, 0x40, 0, 0x0B},
+		},
+	)
+	inst := instantiate(t, b, wasm.Limits{Fuel: 1000, MaxMemoryPages: 4, MaxCallDepth: 100})
+
+	if _, err := inst.Call("spin"); err != wasm.ErrFuelExhausted {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := inst.Call("recurse"); err == nil || err.Error() != "wasm: trap: call stack exhausted" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got, err := inst.Call("grow", 3); err != nil || got != 1 {
+		t.Errorf("unexpected result: %d, %v", got, err)
+	}
+	if got, err := inst.Call("grow", 1); err != nil || got != math.MaxUint32 {
+		t.Errorf("unexpected result: %d, %v", got, err)
+	}
+
+	m, err := wasm.Decode(module([]byte{0x00, 8}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wasm.NewInstance(m, wasm.Limits{MaxMemoryPages: 4}); err == nil || err.Error() != "wasm: memory of 8 pages exceeds the limit of 4 pages" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	m, err := wasm.Decode(module(nil, fn{
+		name:    "add",
+		params:  []byte{i64, f64},
+		results: []byte{f64},
+		body:    []byte{0x20, 1, 0x0B},
+	}, fn{
+		body: []byte{0x0B},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := m.Functions(); len(names) != 1 || names[0] != "add" {
+		t.Errorf("unexpected functions: %v", names)
+	}
+	if typ, ok := m.Func("add"); !ok || len(typ.Params) != 2 || typ.Params[0] != wasm.I64 || typ.Results[0] != wasm.F64 {
+		t.Errorf("unexpected signature: %v", typ)
+	}
+
+	for _, tt := range []struct {
+		b   []byte
+		err string
+	}{
+		{b: []byte("\x00abc\x01\x00\x00\x00"), err: "wasm: invalid magic number"},
+		{b: []byte("\x00asm\x02\x00\x00\x00"), err: "wasm: unsupported version 2"},
+		{b: append([]byte("\x00asm\x01\x00\x00\x00"), section(2)...), err: "wasm: imports are not supported"},
+		{b: module(nil, fn{body: []byte{0x20, 0, 0x0B}}), err: "wasm: local index 0 out of range"},
+		{b: module(nil, fn{body: []byte{0x41, 0, 0x28, 2, 0, 0x0B}}), err: "wasm: memory instruction without memory"},
+		{b: module(nil, fn{body: []byte{0x41, 0}}), err: "wasm: function body must end with end"},
+		{b: module(nil, fn{body: []byte{0x0C, 1, 0x0B}}), err: "wasm: label 1 out of range"},
+		// The constants of the initializers of globals and data are truncated.
+		{b: []byte("\x00asm\x01\x00\x00\x00\x06\b000D0000"), err: "unexpected EOF"},
+		{b: append([]byte("\x00asm\x01\x00\x00\x00"), section(6, []byte{0x7D, 0, 0x43, 0})...), err: "unexpected EOF"},
+		{b: append([]byte("\x00asm\x01\x00\x00\x00"), section(11, []byte{0, 0x44, 0, 0})...), err: "unexpected EOF"},
+	} {
+		if _, err := wasm.Decode(tt.b); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("unexpected error: exp=%s got=%v", tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxql"
+)
+
+// splitAsofJoin separates the asof_join() call in the top level conjunction
+// of the condition from the rest of the condition and returns the statement
+// from it. The asof_join() call is produced by Parser when it lowers an
+// ASOF JOIN clause.
+func splitAsofJoin(cond influxql.Expr) (join *influxql.SelectStatement, rest influxql.Expr, err error) {
+	calls, rest := splitDirectives(cond, "asof_join", 1)
+	if len(calls) == 0 {
+		return nil, rest, nil
+	} else if len(calls) > 1 {
+		return nil, nil, errors.New("ASOF JOIN must only be used once")
+	}
+
+	lit, ok := calls[0].Args[0].(*influxql.StringLiteral)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid ASOF JOIN: %s", calls[0])
+	}
+	stmt, err := influxql.ParseStatement(lit.Val)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ASOF JOIN: %s", err)
+	}
+	join, ok = stmt.(*influxql.SelectStatement)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid ASOF JOIN: %s", lit.Val)
+	}
+	for _, src := range join.Sources {
+		if _, ok := src.(*influxql.Measurement); !ok {
+			return nil, nil, errors.New("ASOF JOIN only supports measurements")
+		}
+	}
+	for _, d := range join.Dimensions {
+		if _, ok := d.Expr.(*influxql.VarRef); !ok {
+			return nil, nil, errors.New("ASOF JOIN ON must only list tag keys")
+		}
+	}
+	return join, rest, nil
+}
+
+// appendTagDimensions adds the tag dimensions that are not already part of
+// the dimensions. The dimensions are not changed if they group by all tags.
+func appendTagDimensions(dimensions, tags influxql.Dimensions) influxql.Dimensions {
+	names := make(map[string]struct{}, len(dimensions))
+	for _, d := range dimensions {
+		switch expr := d.Expr.(type) {
+		case *influxql.Wildcard, *influxql.RegexLiteral:
+			return dimensions
+		case *influxql.VarRef:
+			names[expr.Val] = struct{}{}
+		default:
+		}
+	}
+	for _, d := range tags {
+		if _, ok := names[d.Expr.(*influxql.VarRef).Val]; !ok {
+			dimensions = append(dimensions, &influxql.Dimension{Expr: influxql.CloneExpr(d.Expr)})
+		}
+	}
+	return dimensions
+}
+
+// asofJoin joins the rows of a statement with the most recent rows of the
+// joined measurement that have the same values for the tags of the join.
+type asofJoin struct {
+	stmt PreparedStatement
+
+	// columns are the indexes of the columns that are read from the joined
+	// measurement in the order of the fields of stmt.
+	columns []int
+
+	// dimensions are the tag keys that must match.
+	dimensions []string
+}
+
+// prepareAsofJoin prepares the statement that reads the joined measurement.
+// The fields of stmt that are neither fields nor tags of its own sources are
+// read from the joined measurement. A nil join is returned if there are no
+// such fields.
+func (c *compiledStatement) prepareAsofJoin(stmt *influxql.SelectStatement, mapper influxql.FieldMapper, shardMapper ShardMapper, sopt SelectOptions) (*asofJoin, error) {
+	keys := make(map[string]struct{})
+	for _, m := range stmt.Sources.Measurements() {
+		fields, dimensions, err := mapper.FieldDimensions(m)
+		if err != nil {
+			return nil, err
+		}
+		for k := range fields {
+			keys[k] = struct{}{}
+		}
+		for k := range dimensions {
+			keys[k] = struct{}{}
+		}
+	}
+
+	offset := 1
+	if stmt.OmitTime {
+		offset = 0
+	}
+	join := &asofJoin{}
+	fields := make(influxql.Fields, 0, len(stmt.Fields))
+	for i, f := range stmt.Fields {
+		ref, ok := f.Expr.(*influxql.VarRef)
+		if !ok {
+			continue
+		} else if _, ok := keys[ref.Val]; ok {
+			continue
+		}
+		fields = append(fields, &influxql.Field{Expr: &influxql.VarRef{Val: ref.Val}})
+		join.columns = append(join.columns, i+offset)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	// Points after the end of the time range can never precede a row.
+	other := c.AsofJoin.Clone()
+	other.Fields = fields
+	if max := c.TimeRange.Max; max.UnixNano() < influxql.MaxTime {
+		other.Condition = &influxql.BinaryExpr{
+			Op:  influxql.LTE,
+			LHS: &influxql.VarRef{Val: "time"},
+			RHS: &influxql.TimeLiteral{Val: max},
+		}
+	}
+	for _, d := range other.Dimensions {
+		join.dimensions = append(join.dimensions, d.Expr.(*influxql.VarRef).Val)
+	}
+
+	compiled, err := Compile(other, c.Options)
+	if err != nil {
+		return nil, err
+	}
+	if join.stmt, err = compiled.Prepare(shardMapper, sopt); err != nil {
+		return nil, err
+	}
+	return join, nil
+}
+
+// Select returns a cursor that joins the rows of cur.
+func (j *asofJoin) Select(ctx context.Context, cur Cursor) (Cursor, error) {
+	other, err := j.stmt.Select(ctx)
+	if err != nil {
+		cur.Close()
+		return nil, err
+	}
+	return &asofJoinCursor{
+		Cursor:     cur,
+		other:      other,
+		columns:    j.columns,
+		dimensions: j.dimensions,
+	}, nil
+}
+
+// Close closes the statement of the joined measurement.
+func (j *asofJoin) Close() error {
+	return j.stmt.Close()
+}
+
+// asofRow is a row of the joined measurement.
+type asofRow struct {
+	time   int64
+	values []interface{}
+}
+
+// asofJoinCursor sets the columns of the joined measurement in the rows of
+// the underlying cursor. The rows of the joined measurement are read the
+// first time the cursor is scanned.
+type asofJoinCursor struct {
+	Cursor
+	other      Cursor
+	columns    []int
+	dimensions []string
+
+	rows map[string][]asofRow
+	err  error
+
+	// id and group cache the rows for the series of the last row.
+	id    string
+	group []asofRow
+}
+
+func (cur *asofJoinCursor) Scan(row *Row) bool {
+	if cur.rows == nil {
+		if cur.err = cur.read(); cur.err != nil {
+			return false
+		}
+	}
+	if !cur.Cursor.Scan(row) {
+		return false
+	}
+
+	if id := row.Series.Tags.ID(); cur.group == nil || id != cur.id {
+		cur.id = id
+		cur.group = cur.rows[row.Series.Tags.Subset(cur.dimensions).ID()]
+		if cur.group == nil {
+			cur.group = []asofRow{}
+		}
+	}
+
+	// Use the last row that is not after the row.
+	var values []interface{}
+	if n := sort.Search(len(cur.group), func(i int) bool {
+		return cur.group[i].time > row.Time
+	}); n > 0 {
+		values = cur.group[n-1].values
+	}
+	for i, col := range cur.columns {
+		if values != nil {
+			row.Values[col] = values[i]
+		} else {
+			row.Values[col] = nil
+		}
+	}
+	return true
+}
+
+// read reads all of the rows of the joined measurement grouped by the
+// values of the tags of the join.
+func (cur *asofJoinCursor) read() error {
+	cur.rows = make(map[string][]asofRow)
+
+	var row Row
+	for cur.other.Scan(&row) {
+		id := row.Series.Tags.Subset(cur.dimensions).ID()
+		values := make([]interface{}, len(row.Values)-1)
+		copy(values, row.Values[1:])
+		cur.rows[id] = append(cur.rows[id], asofRow{time: row.Time, values: values})
+	}
+	return cur.other.Err()
+}
+
+func (cur *asofJoinCursor) Stats() IteratorStats {
+	stats := cur.Cursor.Stats()
+	stats.Add(cur.other.Stats())
+	return stats
+}
+
+func (cur *asofJoinCursor) Err() error {
+	if cur.err != nil {
+		return cur.err
+	}
+	return cur.Cursor.Err()
+}
+
+func (cur *asofJoinCursor) Close() error {
+	err := cur.Cursor.Close()
+	if e := cur.other.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
@@ -57,6 +57,8 @@ func NewCallIterator(input Iterator, opt IteratorOptions) (Iterator, error) {
 		return NewSumHllIterator(input, opt)
 	case "merge_hll":
 		return NewMergeHllIterator(input, opt)
+	case "histogram_sum":
+		return NewHistogramSumIterator(input, opt)
 	default:
 		return nil, fmt.Errorf("unsupported function call: %s", name)
 	}
@@ -1598,3 +1600,31 @@ func NewCountHllIterator(input Iterator, opt IteratorOptions) (Iterator, error)
 		return nil, fmt.Errorf("unsupported count_hll iterator type: %T", input)
 	}
 }
+
+// NewHistogramSumIterator returns an iterator for operating on a histogram_sum() call.
+func NewHistogramSumIterator(input Iterator, opt IteratorOptions) (Iterator, error) {
+	switch input := input.(type) {
+	case StringIterator:
+		createFn := func() (StringPointAggregator, StringPointEmitter) {
+			fn := NewStringHistogramSumReducer()
+			return fn, fn
+		}
+		return newStringReduceStringIterator(input, opt, createFn), nil
+	default:
+		return nil, fmt.Errorf("unsupported histogram_sum iterator type: %T", input)
+	}
+}
+
+// newHistogramQuantileIterator returns an iterator for operating on a histogram_quantile() call.
+func newHistogramQuantileIterator(input Iterator, opt IteratorOptions, q float64) (Iterator, error) {
+	switch input := input.(type) {
+	case StringIterator:
+		createFn := func() (StringPointAggregator, FloatPointEmitter) {
+			fn := NewStringHistogramQuantileReducer(q)
+			return fn, fn
+		}
+		return newStringReduceFloatIterator(input, opt, createFn), nil
+	default:
+		return nil, fmt.Errorf("unsupported histogram_quantile iterator type: %T", input)
+	}
+}
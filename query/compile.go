@@ -13,6 +13,10 @@ import (
 // CompileOptions are the customization options for the compiler.
 type CompileOptions struct {
 	Now time.Time
+
+	// UserFunctions are the user-defined functions that can be called in
+	// the fields of the statement.
+	UserFunctions UserFunctions
 }
 
 // Statement is a compiled query statement.
@@ -86,6 +90,18 @@ type compiledStatement struct {
 	// HasTarget is true if this query is being written into a target.
 	HasTarget bool
 
+	// Having is the condition from the HAVING clause. It is evaluated against
+	// the rows produced by the statement and refers to the output columns.
+	Having influxql.Expr
+
+	// MaxStaleness is the maximum staleness duration from a
+	// fill(previous, <duration>) clause.
+	MaxStaleness time.Duration
+
+	// AsofJoin is the statement that selects the measurement of an ASOF JOIN
+	// clause grouped by the tags of the join.
+	AsofJoin *influxql.SelectStatement
+
 	// Options holds the configured compiler options.
 	Options CompileOptions
 
@@ -106,11 +122,27 @@ func newCompiler(opt CompileOptions) *compiledStatement {
 func Compile(stmt *influxql.SelectStatement, opt CompileOptions) (_ Statement, err error) {
 	c := newCompiler(opt)
 	c.stmt = stmt.Clone()
+	c.Having, c.stmt.Condition = splitHaving(c.stmt.Condition)
+	c.MaxStaleness, c.stmt.Condition, err = splitMaxStaleness(c.stmt.Condition)
+	if err != nil {
+		return nil, err
+	} else if c.MaxStaleness > 0 && c.stmt.Fill != influxql.PreviousFill {
+		return nil, errors.New("a maximum staleness duration can only be used with fill(previous)")
+	}
+	c.AsofJoin, c.stmt.Condition, err = splitAsofJoin(c.stmt.Condition)
+	if err != nil {
+		return nil, err
+	} else if c.AsofJoin != nil {
+		// The rows must be grouped by the tags of the join to be matched.
+		c.stmt.Dimensions = appendTagDimensions(c.stmt.Dimensions, c.AsofJoin.Dimensions)
+	}
 	if err := c.preprocess(c.stmt); err != nil {
 		return nil, err
 	}
 	if err := c.compile(c.stmt); err != nil {
 		return nil, err
+	} else if c.AsofJoin != nil && len(c.FunctionCalls) > 0 {
+		return nil, errors.New("ASOF JOIN is only supported with raw queries")
 	}
 	c.stmt.TimeAlias = c.TimeFieldName
 	c.stmt.Condition = c.Condition
@@ -134,6 +166,11 @@ func Compile(stmt *influxql.SelectStatement, opt CompileOptions) (_ Statement, e
 
 	// Rewrite any regex conditions that could make use of the index.
 	c.stmt.RewriteRegexConditions()
+
+	// Resolve the HAVING clause against the output columns.
+	if err := c.compileHaving(c.stmt); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -143,6 +180,14 @@ func (c *compiledStatement) preprocess(stmt *influxql.SelectStatement) error {
 	c.Limit = stmt.Limit
 	c.HasTarget = stmt.Target != nil
 
+	// Compile the patterns of string functions and turn match() predicates
+	// into comparisons before the condition is split.
+	cond, err := compileStringPatterns(stmt.Condition)
+	if err != nil {
+		return err
+	}
+	stmt.Condition = rewriteMatchConditions(cond)
+
 	valuer := influxql.NowValuer{Now: c.Options.Now, Location: stmt.Location}
 	cond, t, err := influxql.ConditionExpr(stmt.Condition, &valuer)
 	if err != nil {
@@ -205,7 +250,7 @@ func (c *compiledStatement) compile(stmt *influxql.SelectStatement) error {
 }
 
 func (c *compiledStatement) compileFields(stmt *influxql.SelectStatement) error {
-	valuer := MathValuer{}
+	valuer := influxql.MultiValuer(MathValuer{}, StringValuer{})
 
 	c.Fields = make([]*compiledField, 0, len(stmt.Fields))
 	for _, f := range stmt.Fields {
@@ -222,7 +267,11 @@ func (c *compiledStatement) compileFields(stmt *influxql.SelectStatement) error
 		}
 
 		// Append this field to the list of processed fields and compile it.
-		f.Expr = influxql.Reduce(f.Expr, &valuer)
+		expr, err := compileStringPatterns(f.Expr)
+		if err != nil {
+			return err
+		}
+		f.Expr = influxql.Reduce(expr, valuer)
 		field := &compiledField{
 			global:        c,
 			Field:         f,
@@ -272,6 +321,10 @@ func (c *compiledField) compileExpr(expr influxql.Expr) error {
 	case *influxql.Call:
 		if isMathFunction(expr) {
 			return c.compileMathFunction(expr)
+		} else if isStringFunction(expr) {
+			return c.compileStringFunction(expr)
+		} else if lookupUserFunction(c.global.Options.UserFunctions, expr) != nil {
+			return c.compileUserFunction(expr)
 		}
 
 		// Register the function call in the list of function calls.
@@ -308,6 +361,8 @@ func (c *compiledField) compileExpr(expr influxql.Expr) error {
 			return c.compileIntegral(expr.Args)
 		case "count_hll":
 			return c.compileCountHll(expr.Args)
+		case "histogram_sum", "histogram_quantile":
+			return c.compileHistogram(expr)
 		case "holt_winters", "holt_winters_with_fit":
 			withFit := expr.Name == "holt_winters_with_fit"
 			return c.compileHoltWinters(expr.Args, withFit)
@@ -797,6 +852,39 @@ func (c *compiledField) compileCountHll(args []influxql.Expr) error {
 	}
 }
 
+func (c *compiledField) compileHistogram(expr *influxql.Call) error {
+	exp := 1
+	if expr.Name == "histogram_quantile" {
+		exp = 2
+	}
+	if got := len(expr.Args); exp != got {
+		return fmt.Errorf("invalid number of arguments for %s, expected %d, got %d", expr.Name, exp, got)
+	}
+
+	if expr.Name == "histogram_quantile" {
+		var q float64
+		switch arg1 := expr.Args[1].(type) {
+		case *influxql.IntegerLiteral:
+			q = float64(arg1.Val)
+		case *influxql.NumberLiteral:
+			q = arg1.Val
+		default:
+			return fmt.Errorf("expected float argument in histogram_quantile()")
+		}
+		if q < 0 || q > 1 {
+			return fmt.Errorf("histogram_quantile() quantile must be between 0 and 1, got %v", q)
+		}
+	}
+	c.global.OnlySelectors = false
+
+	// Wildcards only expand to numeric fields so the argument must be a
+	// reference to the string field holding the histograms.
+	if _, ok := expr.Args[0].(*influxql.VarRef); !ok {
+		return fmt.Errorf("expected field argument in %s()", expr.Name)
+	}
+	return nil
+}
+
 func (c *compiledField) compileHoltWinters(args []influxql.Expr, withFit bool) error {
 	name := "holt_winters"
 	if withFit {
@@ -862,8 +950,6 @@ func (c *compiledField) compileTopBottom(call *influxql.Call) error {
 		return fmt.Errorf("expected integer as last argument in %s(), found %s", call.Name, call.Args[len(call.Args)-1])
 	} else if limit.Val <= 0 {
 		return fmt.Errorf("limit (%d) in %s function must be at least 1", limit.Val, call.Name)
-	} else if c.global.Limit > 0 && int(limit.Val) > c.global.Limit {
-		return fmt.Errorf("limit (%d) in %s function can not be larger than the LIMIT (%d) in the select statement", limit.Val, call.Name, c.global.Limit)
 	}
 
 	if _, ok := call.Args[0].(*influxql.VarRef); !ok {
@@ -919,6 +1005,69 @@ func (c *compiledField) compileMathFunction(expr *influxql.Call) error {
 	return nil
 }
 
+func (c *compiledField) compileStringFunction(expr *influxql.Call) error {
+	if err := validateStringFunction(expr); err != nil {
+		return err
+	}
+
+	// Compile all the argument expressions that are not just literals.
+	for _, arg := range expr.Args {
+		if _, ok := arg.(influxql.Literal); ok {
+			continue
+		}
+		if err := c.compileExpr(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStringFunction verifies the number of arguments of a string
+// function and the arguments that must be literals.
+func validateStringFunction(expr *influxql.Call) error {
+	// How many arguments are we expecting?
+	min, max := 1, 1
+	switch expr.Name {
+	case "match":
+		min, max = 2, 2
+	case "replace":
+		min, max = 3, 3
+	case "substr":
+		min, max = 2, 3
+	default:
+	}
+
+	// Did we get the expected number of args?
+	if got := len(expr.Args); got < min || got > max {
+		if min == max {
+			return fmt.Errorf("invalid number of arguments for %s, expected %d, got %d", expr.Name, min, got)
+		}
+		return fmt.Errorf("invalid number of arguments for %s, expected at least %d but no more than %d, got %d", expr.Name, min, max, got)
+	}
+
+	switch expr.Name {
+	case "match", "replace":
+		switch expr.Args[1].(type) {
+		case *influxql.RegexLiteral, *influxql.StringLiteral:
+		default:
+			return fmt.Errorf("expected pattern argument in %s()", expr.Name)
+		}
+		if expr.Name == "replace" {
+			if _, ok := expr.Args[2].(*influxql.StringLiteral); !ok {
+				return fmt.Errorf("expected string argument in %s()", expr.Name)
+			}
+		}
+	case "substr":
+		for _, arg := range expr.Args[1:] {
+			if _, ok := arg.(*influxql.IntegerLiteral); !ok {
+				return fmt.Errorf("expected integer argument in %s()", expr.Name)
+			}
+		}
+	default:
+	}
+	return nil
+}
+
 func (c *compiledStatement) compileDimensions(stmt *influxql.SelectStatement) error {
 	for _, d := range stmt.Dimensions {
 		// Reduce the expression before attempting anything. Do not evaluate the call.
@@ -936,6 +1085,10 @@ func (c *compiledStatement) compileDimensions(stmt *influxql.SelectStatement) er
 				return errors.New("only time() calls allowed in dimensions")
 			} else if got := len(expr.Args); got < 1 || got > 2 {
 				return errors.New("time dimension expected 1 or 2 arguments")
+			} else if lit, ok := expr.Args[0].(*influxql.StringLiteral); ok {
+				if err := c.compileCalendarInterval(stmt, expr, lit); err != nil {
+					return err
+				}
 			} else if lit, ok := expr.Args[0].(*influxql.DurationLiteral); !ok {
 				return errors.New("time dimension must have duration argument")
 			} else if c.Interval.Duration != 0 {
@@ -989,6 +1142,53 @@ func (c *compiledStatement) compileDimensions(stmt *influxql.SelectStatement) er
 	return nil
 }
 
+// compileCalendarInterval compiles a time dimension with a calendar interval
+// such as time('1mo'). An offset that is given as a time is replaced with the
+// equivalent duration from the start of the calendar window.
+func (c *compiledStatement) compileCalendarInterval(stmt *influxql.SelectStatement, expr *influxql.Call, lit *influxql.StringLiteral) error {
+	interval, ok := parseCalendarInterval(lit.Val)
+	if !ok {
+		return errors.New("time dimension must have duration argument")
+	} else if c.Interval.Duration != 0 {
+		return errors.New("multiple time dimensions not allowed")
+	}
+
+	if len(expr.Args) == 2 {
+		var t time.Time
+		switch arg := expr.Args[1].(type) {
+		case *influxql.DurationLiteral:
+			interval.Offset = arg.Val
+		case *influxql.TimeLiteral:
+			t = arg.Val
+		case *influxql.Call:
+			if arg.Name != "now" {
+				return errors.New("time dimension offset function must be now()")
+			} else if len(arg.Args) != 0 {
+				return errors.New("time dimension offset now() function requires no arguments")
+			}
+			t = c.Options.Now
+		case *influxql.StringLiteral:
+			if !arg.IsTimeLiteral() {
+				return errors.New("time dimension offset must be duration or now()")
+			}
+			lit, err := arg.ToTimeLiteral(stmt.Location)
+			if err != nil {
+				return err
+			}
+			t = lit.Val
+		default:
+			return errors.New("time dimension offset must be duration or now()")
+		}
+
+		if !t.IsZero() {
+			interval.Offset = calendarOffset(interval, t, stmt.Location)
+			expr.Args[1] = &influxql.DurationLiteral{Val: interval.Offset}
+		}
+	}
+	c.Interval = interval
+	return nil
+}
+
 // validateFields validates that the fields are mutually compatible with each other.
 // This runs at the end of compilation but before linking.
 func (c *compiledStatement) validateFields() error {
@@ -1042,7 +1242,14 @@ func (c *compiledStatement) validateCondition(expr influxql.Expr) error {
 		}
 		return nil
 	case *influxql.Call:
-		if !isMathFunction(expr) {
+		if expr.Name == "having" {
+			return fmt.Errorf("HAVING cannot be combined with other conditions using OR: %s", expr)
+		} else if isStringFunction(expr) {
+			if err := validateStringFunction(expr); err != nil {
+				return err
+			}
+			return c.validateCondition(expr.Args[0])
+		} else if !isMathFunction(expr) {
 			return fmt.Errorf("invalid function call in condition: %s", expr)
 		}
 
@@ -1073,6 +1280,14 @@ func (c *compiledStatement) validateCondition(expr influxql.Expr) error {
 // subquery compiles and validates a compiled statement for the subquery using
 // this compiledStatement as the parent.
 func (c *compiledStatement) subquery(stmt *influxql.SelectStatement) error {
+	if having, _ := splitHaving(stmt.Condition); having != nil {
+		return errors.New("HAVING is not supported in subqueries")
+	} else if calls, _ := splitDirectives(stmt.Condition, "fill", 2); len(calls) > 0 {
+		return errors.New("fill(previous, <duration>) is not supported in subqueries")
+	} else if calls, _ := splitDirectives(stmt.Condition, "asof_join", 1); len(calls) > 0 {
+		return errors.New("ASOF JOIN is not supported in subqueries")
+	}
+
 	subquery := newCompiler(c.Options)
 	if err := subquery.preprocess(stmt); err != nil {
 		return err
@@ -1084,6 +1299,7 @@ func (c *compiledStatement) subquery(stmt *influxql.SelectStatement) error {
 	valuer := influxql.MultiValuer(
 		&influxql.NowValuer{Now: c.Options.Now, Location: stmt.Location},
 		&MathValuer{},
+		&StringValuer{},
 	)
 	stmt.Condition = influxql.Reduce(stmt.Condition, valuer)
 
@@ -1119,33 +1335,26 @@ func (c *compiledStatement) Prepare(shardMapper ShardMapper, sopt SelectOptions)
 	// the select statement. Determine the shard time range here.
 	timeRange := c.TimeRange
 	if sopt.MaxBucketsN > 0 && !c.stmt.IsRawQuery && timeRange.MinTimeNano() == influxql.MinTime {
-		interval, err := c.stmt.GroupByInterval()
-		if err != nil {
-			return nil, err
-		}
-
-		offset, err := c.stmt.GroupByOffset()
+		interval, err := groupByInterval(c.stmt)
 		if err != nil {
 			return nil, err
 		}
 
-		if interval > 0 {
+		if !interval.IsZero() {
 			// Determine the last bucket using the end time.
 			opt := IteratorOptions{
-				Interval: Interval{
-					Duration: interval,
-					Offset:   offset,
-				},
+				Interval: interval,
+				Location: c.stmt.Location,
 			}
 			last, _ := opt.Window(c.TimeRange.MaxTimeNano() - 1)
 
 			// Determine the time difference using the number of buckets.
 			// Determine the maximum difference between the buckets based on the end time.
 			maxDiff := last - models.MinNanoTime
-			if maxDiff/int64(interval) > int64(sopt.MaxBucketsN) {
+			if maxDiff/int64(interval.Duration) > int64(sopt.MaxBucketsN) {
 				timeRange.Min = time.Unix(0, models.MinNanoTime)
 			} else {
-				timeRange.Min = time.Unix(0, last-int64(interval)*int64(sopt.MaxBucketsN-1))
+				timeRange.Min = time.Unix(0, last-int64(interval.Duration)*int64(sopt.MaxBucketsN-1))
 			}
 		}
 	}
@@ -1184,7 +1393,7 @@ func (c *compiledStatement) Prepare(shardMapper ShardMapper, sopt SelectOptions)
 	}
 
 	// Validate if the types are correct now that they have been assigned.
-	if err := validateTypes(stmt); err != nil {
+	if err := validateTypes(stmt, c.Options.UserFunctions); err != nil {
 		shards.Close()
 		return nil, err
 	}
@@ -1197,21 +1406,26 @@ func (c *compiledStatement) Prepare(shardMapper ShardMapper, sopt SelectOptions)
 	}
 	opt.StartTime, opt.EndTime = c.TimeRange.MinTimeNano(), c.TimeRange.MaxTimeNano()
 	opt.Ascending = c.Ascending
+	opt.MaxStaleness = c.MaxStaleness
+	opt.UserFunctions = c.Options.UserFunctions
 
 	if sopt.MaxBucketsN > 0 && !stmt.IsRawQuery && c.TimeRange.MinTimeNano() > influxql.MinTime {
-		interval, err := stmt.GroupByInterval()
-		if err != nil {
-			shards.Close()
-			return nil, err
-		}
-
-		if interval > 0 {
+		if interval := opt.Interval; !interval.IsZero() {
 			// Determine the start and end time matched to the interval (may not match the actual times).
 			first, _ := opt.Window(opt.StartTime)
 			last, _ := opt.Window(opt.EndTime - 1)
 
 			// Determine the number of buckets by finding the time span and dividing by the interval.
-			buckets := (last - first + int64(interval)) / int64(interval)
+			buckets := (last - first + int64(interval.Duration)) / int64(interval.Duration)
+			if interval.Months > 0 {
+				// Calendar intervals vary in length so count the months instead.
+				loc := opt.Location
+				if loc == nil {
+					loc = time.UTC
+				}
+				f, l := time.Unix(0, first).In(loc), time.Unix(0, last).In(loc)
+				buckets = int64(((l.Year()-f.Year())*12+int(l.Month()-f.Month()))/interval.Months + 1)
+			}
 			if int(buckets) > sopt.MaxBucketsN {
 				shards.Close()
 				return nil, fmt.Errorf("max-select-buckets limit exceeded: (%d/%d)", buckets, sopt.MaxBucketsN)
@@ -1219,6 +1433,22 @@ func (c *compiledStatement) Prepare(shardMapper ShardMapper, sopt SelectOptions)
 		}
 	}
 
+	// The LIMIT and OFFSET apply to the rows that remain after the HAVING
+	// clause has been evaluated so they cannot be pushed down to the iterators.
+	var limit, offset int
+	if c.Having != nil {
+		limit, offset = opt.Limit, opt.Offset
+		opt.Limit, opt.Offset = 0, 0
+	}
+
+	var join *asofJoin
+	if c.AsofJoin != nil {
+		if join, err = c.prepareAsofJoin(stmt, shards, shardMapper, sopt); err != nil {
+			shards.Close()
+			return nil, err
+		}
+	}
+
 	columns := stmt.ColumnNames()
 	return &preparedStatement{
 		stmt:      stmt,
@@ -1227,5 +1457,134 @@ func (c *compiledStatement) Prepare(shardMapper ShardMapper, sopt SelectOptions)
 		columns:   columns,
 		maxPointN: sopt.MaxPointN,
 		now:       c.Options.Now,
+		having:    c.Having,
+		limit:     limit,
+		offset:    offset,
+		join:      join,
 	}, nil
 }
+
+// compileHaving rewrites the HAVING condition so the function calls within it
+// refer to the output columns that hold their results.
+func (c *compiledStatement) compileHaving(stmt *influxql.SelectStatement) error {
+	if c.Having == nil {
+		return nil
+	}
+
+	// Map each selected expression to the name of its column. The column
+	// names have to be computed the same way as ColumnNames does, which
+	// includes the extra columns added for top() and bottom().
+	columns := stmt.ColumnNames()
+	if !stmt.OmitTime {
+		columns = columns[1:]
+	}
+	names := make(map[string]string, len(columns))
+	i := 0
+	for _, f := range stmt.Fields {
+		if _, ok := names[f.Expr.String()]; !ok {
+			names[f.Expr.String()] = columns[i]
+		}
+		i++
+
+		if call, ok := f.Expr.(*influxql.Call); ok && stmt.Target == nil && (call.Name == "top" || call.Name == "bottom") {
+			for _, arg := range call.Args[1:] {
+				if _, ok := arg.(*influxql.VarRef); ok {
+					i++
+				}
+			}
+		}
+	}
+
+	var err error
+	c.Having = influxql.RewriteExpr(influxql.CloneExpr(c.Having), func(expr influxql.Expr) influxql.Expr {
+		call, ok := expr.(*influxql.Call)
+		if !ok || isMathFunction(call) || isStringFunction(call) {
+			return expr
+		}
+		name, ok := names[call.String()]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("%s must be selected to be used in HAVING", call)
+			}
+			return expr
+		}
+		return &influxql.VarRef{Val: name}
+	})
+	return err
+}
+
+// splitHaving separates the having() calls in the top level conjunction of the
+// condition from the rest of the condition. The having() calls are produced by
+// Parser when it lowers the HAVING clause.
+func splitHaving(cond influxql.Expr) (having, rest influxql.Expr) {
+	calls, rest := splitDirectives(cond, "having", 1)
+	for _, call := range calls {
+		having = conjunction(having, call.Args[0])
+	}
+	return having, rest
+}
+
+// splitMaxStaleness separates the fill() call in the top level conjunction of
+// the condition from the rest of the condition and returns the maximum
+// staleness duration from it. The fill() call is produced by Parser when it
+// lowers a fill(previous, <duration>) clause.
+func splitMaxStaleness(cond influxql.Expr) (d time.Duration, rest influxql.Expr, err error) {
+	calls, rest := splitDirectives(cond, "fill", 2)
+	if len(calls) == 0 {
+		return 0, rest, nil
+	} else if len(calls) > 1 {
+		return 0, nil, errors.New("fill(previous, <duration>) must only be used once")
+	}
+
+	call := calls[0]
+	if ref, ok := call.Args[0].(*influxql.VarRef); !ok || ref.Val != "previous" {
+		return 0, nil, fmt.Errorf("a maximum staleness duration can only be used with fill(previous): %s", call)
+	}
+	lit, ok := call.Args[1].(*influxql.DurationLiteral)
+	if !ok || lit.Val <= 0 {
+		return 0, nil, fmt.Errorf("maximum staleness must be a positive duration literal: %s", call.Args[1])
+	}
+	return lit.Val, rest, nil
+}
+
+// splitDirectives separates the calls to the named function with nargs
+// arguments in the top level conjunction of the condition from the rest of
+// the condition.
+func splitDirectives(cond influxql.Expr, name string, nargs int) (calls []*influxql.Call, rest influxql.Expr) {
+	switch expr := cond.(type) {
+	case *influxql.Call:
+		if expr.Name == name && len(expr.Args) == nargs {
+			return []*influxql.Call{expr}, nil
+		}
+	case *influxql.ParenExpr:
+		calls, rest := splitDirectives(expr.Expr, name, nargs)
+		if len(calls) == 0 {
+			return nil, cond
+		} else if rest != nil {
+			rest = &influxql.ParenExpr{Expr: rest}
+		}
+		return calls, rest
+	case *influxql.BinaryExpr:
+		if expr.Op != influxql.AND {
+			break
+		}
+		lhsCalls, lhsRest := splitDirectives(expr.LHS, name, nargs)
+		rhsCalls, rhsRest := splitDirectives(expr.RHS, name, nargs)
+		if len(lhsCalls) == 0 && len(rhsCalls) == 0 {
+			break
+		}
+		return append(lhsCalls, rhsCalls...), conjunction(lhsRest, rhsRest)
+	}
+	return nil, cond
+}
+
+// conjunction combines the two expressions using AND. Either of the
+// expressions may be nil.
+func conjunction(lhs, rhs influxql.Expr) influxql.Expr {
+	if lhs == nil {
+		return rhs
+	} else if rhs == nil {
+		return lhs
+	}
+	return &influxql.BinaryExpr{Op: influxql.AND, LHS: lhs, RHS: rhs}
+}
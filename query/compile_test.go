@@ -58,6 +58,8 @@ func TestCompile_Success(t *testing.T) {
 		`SELECT top(value, 1) FROM cpu`,
 		`SELECT top(value, host, 1) FROM cpu`,
 		`SELECT top(value, 1), host FROM cpu`,
+		`SELECT top(value, 3) FROM cpu GROUP BY host LIMIT 2`,
+		`SELECT bottom(value, 3) FROM cpu GROUP BY host LIMIT 2`,
 		`SELECT min(top) FROM (SELECT top(value, host, 1) FROM cpu) GROUP BY region`,
 		`SELECT bottom(value, 1) FROM cpu`,
 		`SELECT bottom(value, host, 1) FROM cpu`,
@@ -65,6 +67,9 @@ func TestCompile_Success(t *testing.T) {
 		`SELECT max(bottom) FROM (SELECT bottom(value, host, 1) FROM cpu) GROUP BY region`,
 		`SELECT percentile(value, 75) FROM cpu`,
 		`SELECT percentile(value, 75.0) FROM cpu`,
+		`SELECT histogram_sum(latency) FROM cpu`,
+		`SELECT histogram_quantile(latency, 0.99) FROM cpu`,
+		`SELECT histogram_quantile(latency, 1) FROM cpu GROUP BY time(1m)`,
 		`SELECT sample(value, 2) FROM cpu`,
 		`SELECT sample(*, 2) FROM cpu`,
 		`SELECT sample(/val/, 2) FROM cpu`,
@@ -75,6 +80,10 @@ func TestCompile_Success(t *testing.T) {
 		`SELECT max(value) FROM cpu WHERE time >= now() - 1m GROUP BY time(10s, 5s)`,
 		`SELECT max(value) FROM cpu WHERE time >= now() - 1m GROUP BY time(10s, '2000-01-01T00:00:05Z')`,
 		`SELECT max(value) FROM cpu WHERE time >= now() - 1m GROUP BY time(10s, now())`,
+		`SELECT max(value) FROM cpu WHERE time >= now() - 365d GROUP BY time('1mo')`,
+		`SELECT max(value) FROM cpu WHERE time >= now() - 365d GROUP BY time('1q', 15d)`,
+		`SELECT max(value) FROM cpu WHERE time >= now() - 365d GROUP BY time('1y', '2000-01-15T00:00:00Z')`,
+		`SELECT max(value) FROM cpu WHERE time >= now() - 365d GROUP BY time('3mo', now())`,
 		`SELECT max(mean) FROM (SELECT mean(value) FROM cpu GROUP BY host)`,
 		`SELECT max(derivative) FROM (SELECT derivative(mean(value)) FROM cpu) WHERE time >= now() - 1m GROUP BY time(10s)`,
 		`SELECT max(value) FROM (SELECT value + total FROM cpu) WHERE time >= now() - 1m GROUP BY time(10s)`,
@@ -159,6 +168,8 @@ func TestCompile_Failures(t *testing.T) {
 		{s: `SELECT value FROM cpu GROUP BY time(5m, 30s, 1ms)`, err: `time dimension expected 1 or 2 arguments`},
 		{s: `SELECT value FROM cpu GROUP BY time('unexpected')`, err: `time dimension must have duration argument`},
 		{s: `SELECT value FROM cpu GROUP BY time(5m), time(1m)`, err: `multiple time dimensions not allowed`},
+		{s: `SELECT value FROM cpu GROUP BY time(5m), time('1mo')`, err: `multiple time dimensions not allowed`},
+		{s: `SELECT value FROM cpu GROUP BY time('1mo', 'unexpected')`, err: `time dimension offset must be duration or now()`},
 		{s: `SELECT value FROM cpu GROUP BY time(5m, unexpected())`, err: `time dimension offset function must be now()`},
 		{s: `SELECT value FROM cpu GROUP BY time(5m, now(1m))`, err: `time dimension offset now() function requires no arguments`},
 		{s: `SELECT value FROM cpu GROUP BY time(5m, 'unexpected')`, err: `time dimension offset must be duration or now()`},
@@ -168,13 +179,11 @@ func TestCompile_Failures(t *testing.T) {
 		{s: `SELECT top(value, 'unexpected', 5) FROM cpu`, err: `only fields or tags are allowed in top(), found 'unexpected'`},
 		{s: `SELECT top(value, 2.5) FROM cpu`, err: `expected integer as last argument in top(), found 2.5`},
 		{s: `SELECT top(value, -1) FROM cpu`, err: `limit (-1) in top function must be at least 1`},
-		{s: `SELECT top(value, 3) FROM cpu LIMIT 2`, err: `limit (3) in top function can not be larger than the LIMIT (2) in the select statement`},
 		{s: `SELECT bottom(value) FROM cpu`, err: `invalid number of arguments for bottom, expected at least 2, got 1`},
 		{s: `SELECT bottom('unexpected', 5) FROM cpu`, err: `expected first argument to be a field in bottom(), found 'unexpected'`},
 		{s: `SELECT bottom(value, 'unexpected', 5) FROM cpu`, err: `only fields or tags are allowed in bottom(), found 'unexpected'`},
 		{s: `SELECT bottom(value, 2.5) FROM cpu`, err: `expected integer as last argument in bottom(), found 2.5`},
 		{s: `SELECT bottom(value, -1) FROM cpu`, err: `limit (-1) in bottom function must be at least 1`},
-		{s: `SELECT bottom(value, 3) FROM cpu LIMIT 2`, err: `limit (3) in bottom function can not be larger than the LIMIT (2) in the select statement`},
 		// TODO(jsternberg): This query is wrong, but we cannot enforce this because of previous behavior: https://github.com/influxdata/influxdb/pull/8771
 		//{s: `SELECT value FROM cpu WHERE time >= now() - 10m OR time < now() - 5m`, err: `cannot use OR with time conditions`},
 		{s: `SELECT value FROM cpu WHERE value`, err: `invalid condition expression: value`},
@@ -223,9 +232,18 @@ func TestCompile_Failures(t *testing.T) {
 		{s: `SELECT percentile(field1) FROM myseries`, err: `invalid number of arguments for percentile, expected 2, got 1`},
 		{s: `SELECT percentile(field1, foo) FROM myseries`, err: `expected float argument in percentile()`},
 		{s: `SELECT percentile(max(field1), 75) FROM myseries`, err: `expected field argument in percentile()`},
+		{s: `SELECT histogram_sum() FROM myseries`, err: `invalid number of arguments for histogram_sum, expected 1, got 0`},
+		{s: `SELECT histogram_sum(*) FROM myseries`, err: `expected field argument in histogram_sum()`},
+		{s: `SELECT histogram_quantile(field1) FROM myseries`, err: `invalid number of arguments for histogram_quantile, expected 2, got 1`},
+		{s: `SELECT histogram_quantile(field1, foo) FROM myseries`, err: `expected float argument in histogram_quantile()`},
+		{s: `SELECT histogram_quantile(field1, 99) FROM myseries`, err: `histogram_quantile() quantile must be between 0 and 1, got 99`},
+		{s: `SELECT histogram_quantile(max(field1), 0.5) FROM myseries`, err: `expected field argument in histogram_quantile()`},
 		{s: `SELECT field1 FROM foo group by time(1s)`, err: `GROUP BY requires at least one aggregate function`},
 		{s: `SELECT field1 FROM foo fill(none)`, err: `fill(none) must be used with a function`},
 		{s: `SELECT field1 FROM foo fill(linear)`, err: `fill(linear) must be used with a function`},
+		{s: `SELECT mean(field1) FROM foo WHERE fill(previous, 0s) GROUP BY time(1m) fill(previous)`, err: `maximum staleness must be a positive duration literal: 0s`},
+		{s: `SELECT mean(field1) FROM foo WHERE fill(previous, 5m) GROUP BY time(1m)`, err: `a maximum staleness duration can only be used with fill(previous)`},
+		{s: `SELECT mean(field1) FROM foo WHERE fill(previous, 5m) AND fill(previous, 1m) GROUP BY time(1m) fill(previous)`, err: `fill(previous, <duration>) must only be used once`},
 		{s: `SELECT count(value), value FROM foo`, err: `mixing aggregate and non-aggregate queries is not supported`},
 		{s: `SELECT count(value) FROM foo group by time`, err: `time() is a function and expects at least one argument`},
 		{s: `SELECT count(value) FROM foo group by 'time'`, err: `only time and tag dimensions allowed`},
@@ -144,12 +144,16 @@ type scannerCursorBase struct {
 	columns []influxql.VarRef
 	loc     *time.Location
 
-	scan   scannerFunc
-	valuer influxql.ValuerEval
+	scan      scannerFunc
+	valuer    influxql.ValuerEval
+	functions *userFunctionValuer
 }
 
-func newScannerCursorBase(scan scannerFunc, fields []*influxql.Field, loc *time.Location) scannerCursorBase {
-	typmap := FunctionTypeMapper{}
+func newScannerCursorBase(scan scannerFunc, fields []*influxql.Field, opt IteratorOptions) scannerCursorBase {
+	typmap := influxql.MultiTypeMapper(
+		UserFunctionTypeMapper{Functions: opt.UserFunctions},
+		FunctionTypeMapper{},
+	)
 	exprs := make([]influxql.Expr, len(fields))
 	columns := make([]influxql.VarRef, len(fields))
 	for i, f := range fields {
@@ -159,20 +163,25 @@ func newScannerCursorBase(scan scannerFunc, fields []*influxql.Field, loc *time.
 			Type: influxql.EvalType(f.Expr, nil, typmap),
 		}
 	}
+	loc := opt.Location
 	if loc == nil {
 		loc = time.UTC
 	}
 
 	m := make(map[string]interface{})
+	functions := &userFunctionValuer{functions: opt.UserFunctions}
 	return scannerCursorBase{
-		fields:  exprs,
-		m:       m,
-		columns: columns,
-		loc:     loc,
-		scan:    scan,
+		fields:    exprs,
+		m:         m,
+		columns:   columns,
+		loc:       loc,
+		scan:      scan,
+		functions: functions,
 		valuer: influxql.ValuerEval{
 			Valuer: influxql.MultiValuer(
 				MathValuer{},
+				StringValuer{},
+				functions,
 				influxql.MapValuer(m),
 			),
 			IntegerFloatDivision: true,
@@ -213,7 +222,9 @@ func (cur *scannerCursorBase) Scan(row *Row) bool {
 		}
 		row.Values[i] = v
 	}
-	return true
+
+	// Stop reading when a user-defined function fails.
+	return cur.functions.err == nil
 }
 
 func (cur *scannerCursorBase) Columns() []influxql.VarRef {
@@ -235,7 +246,7 @@ type scannerCursor struct {
 
 func newScannerCursor(s IteratorScanner, fields []*influxql.Field, opt IteratorOptions) *scannerCursor {
 	cur := &scannerCursor{scanner: s}
-	cur.scannerCursorBase = newScannerCursorBase(cur.scan, fields, opt.Location)
+	cur.scannerCursorBase = newScannerCursorBase(cur.scan, fields, opt)
 	return cur
 }
 
@@ -257,6 +268,9 @@ func (cur *scannerCursor) Stats() IteratorStats {
 }
 
 func (cur *scannerCursor) Err() error {
+	if err := cur.functions.err; err != nil {
+		return err
+	}
 	return cur.scanner.Err()
 }
 
@@ -278,7 +292,7 @@ func newMultiScannerCursor(scanners []IteratorScanner, fields []*influxql.Field,
 		scanners:  scanners,
 		ascending: opt.Ascending,
 	}
-	cur.scannerCursorBase = newScannerCursorBase(cur.scan, fields, opt.Location)
+	cur.scannerCursorBase = newScannerCursorBase(cur.scan, fields, opt)
 	return cur
 }
 
@@ -333,6 +347,9 @@ func (cur *multiScannerCursor) Stats() IteratorStats {
 }
 
 func (cur *multiScannerCursor) Err() error {
+	if err := cur.functions.err; err != nil {
+		return err
+	}
 	return cur.err
 }
 
@@ -384,7 +401,13 @@ func newFilterCursor(cur Cursor, filter influxql.Expr) *filterCursor {
 		fields: fields,
 		filter: filter,
 		m:      m,
-		valuer: influxql.ValuerEval{Valuer: influxql.MapValuer(m)},
+		valuer: influxql.ValuerEval{
+			Valuer: influxql.MultiValuer(
+				MathValuer{},
+				StringValuer{},
+				influxql.MapValuer(m),
+			),
+		},
 	}
 }
 
@@ -404,6 +427,41 @@ func (cur *filterCursor) Scan(row *Row) bool {
 	return false
 }
 
+// limitCursor limits the number of rows returned for each series.
+type limitCursor struct {
+	Cursor
+	limit, offset int
+
+	series Series
+	n      int
+}
+
+func newLimitCursor(cur Cursor, limit, offset int) *limitCursor {
+	return &limitCursor{
+		Cursor: cur,
+		limit:  limit,
+		offset: offset,
+	}
+}
+
+func (cur *limitCursor) Scan(row *Row) bool {
+	for cur.Cursor.Scan(row) {
+		if !row.Series.SameSeries(cur.series) {
+			cur.series, cur.n = row.Series, 0
+		}
+		cur.n++
+
+		// Skip the rows before the offset and after the limit.
+		if cur.n <= cur.offset {
+			continue
+		} else if cur.limit > 0 && cur.n-cur.offset > cur.limit {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
 type nullCursor struct {
 	columns []influxql.VarRef
 }
@@ -199,6 +199,21 @@ type ExecutionOptions struct {
 	// Quiet suppresses non-essential output from the query executor.
 	Quiet bool
 
+	// The name of the user running the query. It is empty if
+	// authentication is disabled.
+	User string
+
+	// The address of the client that sent the query, if known.
+	Source string
+
+	// The default time zone of SELECT statements without a tz() clause.
+	// If nil, UTC is used.
+	Location *time.Location
+
+	// The priority class of the query. If empty, the default priority of
+	// the TaskManager is used.
+	Priority string
+
 	// AbortCh is a channel that signals when results are no longer desired by the caller.
 	AbortCh <-chan struct{}
 }
@@ -264,7 +279,7 @@ type Statistics struct {
 
 // Statistics returns statistics for periodic monitoring.
 func (e *Executor) Statistics(tags map[string]string) []models.Statistic {
-	return []models.Statistic{{
+	stats := []models.Statistic{{
 		Name: "queryExecutor",
 		Tags: tags,
 		Values: map[string]interface{}{
@@ -275,6 +290,7 @@ func (e *Executor) Statistics(tags map[string]string) []models.Statistic {
 			statRecoveredPanics:        atomic.LoadInt64(&e.stats.RecoveredPanics),
 		},
 	}}
+	return append(stats, e.TaskManager.Statistics(tags)...)
 }
 
 // Close kills all running queries and prevents new queries from being attached.
@@ -321,12 +337,33 @@ func (e *Executor) executeQuery(query *influxql.Query, opt ExecutionOptions, clo
 	// Setup the execution context that will be used when executing statements.
 	ctx.Results = results
 
+	// The time zone of the statements may be changed by SET TIME ZONE.
+	loc := opt.Location
+
 	var i int
 LOOP:
 	for ; i < len(query.Statements); i++ {
 		ctx.statementID = i
 		stmt := query.Statements[i]
 
+		switch stmt := stmt.(type) {
+		case *SetTimeZoneStatement:
+			loc = stmt.Location
+			if loc == nil {
+				loc = opt.Location
+			}
+			if err := ctx.send(&Result{StatementID: i}); err == ErrQueryAborted {
+				return
+			}
+			continue
+		case *influxql.SelectStatement:
+			// A tz() clause takes precedence over the default time zone.
+			if stmt.Location == nil {
+				stmt.Location = loc
+			}
+		default:
+		}
+
 		// If a default database wasn't passed in by the caller, check the statement.
 		defaultDB := opt.Database
 		if defaultDB == "" {
@@ -470,6 +507,9 @@ func (e *Executor) recover(query *influxql.Query, results chan *Result) {
 type Task struct {
 	query     string
 	database  string
+	user      string
+	policy    *QueryPolicy
+	pool      *priorityPool
 	status    TaskStatus
 	startTime time.Time
 	closing   chan struct{}
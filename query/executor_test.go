@@ -375,6 +375,49 @@ func TestQueryExecutor_Limit_Timeout(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_Policy_MaxRuntime(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+				t.Errorf("policy has not killed the query")
+				return errUnexpected
+			}
+		},
+	}
+	e.TaskManager.Policies = []query.QueryPolicy{
+		{Name: "other", Database: "db1", MaxRuntime: time.Nanosecond},
+		{Name: "adhoc", User: "bob", MaxRuntime: time.Millisecond},
+	}
+	killed := make(chan query.KilledQuery, 1)
+	e.TaskManager.OnPolicyViolation = func(k query.KilledQuery) {
+		killed <- k
+	}
+
+	results := e.ExecuteQuery(q, query.ExecutionOptions{Database: "db0", User: "bob"}, nil)
+	result := <-results
+	if result.Err == nil || result.Err.Error() != "query killed by policy adhoc: max-runtime limit exceeded: (1ms)" {
+		t.Errorf("unexpected error: %s", result.Err)
+	}
+
+	select {
+	case k := <-killed:
+		if k.ID != 1 || k.Database != "db0" || k.User != "bob" || k.Policy != "adhoc" || k.Query != "SELECT count(value) FROM cpu" {
+			t.Errorf("unexpected killed query: %+v", k)
+		}
+	default:
+		t.Error("killed query was not reported")
+	}
+}
+
 func TestQueryExecutor_Limit_ConcurrentQueries(t *testing.T) {
 	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
 	if err != nil {
@@ -414,6 +457,129 @@ func TestQueryExecutor_Limit_ConcurrentQueries(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_Limit_PriorityClasses(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qid := make(chan uint64)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			qid <- ctx.QueryID
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	e.TaskManager.PriorityClasses = map[string]query.PriorityClass{
+		query.PriorityBatch: {MaxConcurrentQueries: 1, MaxQueuedQueries: 1},
+	}
+	e.TaskManager.DefaultPriority = query.PriorityBatch
+	defer e.Close()
+
+	// The first batch query runs and the queries of another class still do.
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{}, nil))
+	first := <-qid
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{Priority: query.PriorityInteractive}, nil))
+	<-qid
+
+	// The second batch query waits for the first one and the third one is
+	// rejected.
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{Priority: query.PriorityBatch}, nil))
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if stats := e.TaskManager.Statistics(nil); len(stats) == 1 && stats[0].Values["queriesQueued"] == int64(1) {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("unexpected statistics: %v", stats)
+		}
+	}
+
+	results := e.ExecuteQuery(q, query.ExecutionOptions{}, nil)
+	select {
+	case result := <-results:
+		if result.Err == nil || !strings.Contains(result.Err.Error(), "max-queued-queries") {
+			t.Errorf("unexpected error: %s", result.Err)
+		}
+	case <-qid:
+		t.Fatal("unexpected statement execution for the rejected query")
+	}
+
+	// The queued query runs once the first one finishes.
+	if err := e.TaskManager.KillQuery(first); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-qid:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued query did not run")
+	}
+
+	stats := e.TaskManager.Statistics(map[string]string{"host": "h"})
+	if len(stats) != 1 {
+		t.Fatalf("unexpected statistics: %v", stats)
+	} else if s := stats[0]; s.Tags["priority"] != query.PriorityBatch || s.Tags["host"] != "h" ||
+		s.Values["queriesRunning"] != int64(1) || s.Values["queriesQueued"] != int64(0) || s.Values["queriesRejected"] != int64(1) {
+		t.Fatalf("unexpected statistics: %v", s)
+	}
+}
+
+func TestQueryExecutor_Limit_QueueTimeout(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qid := make(chan uint64)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			qid <- ctx.QueryID
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	e.TaskManager.PriorityClasses = map[string]query.PriorityClass{
+		query.PriorityBatch: {MaxConcurrentQueries: 1, MaxQueuedQueries: 1, QueueTimeout: 10 * time.Millisecond},
+	}
+	defer e.Close()
+
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{Priority: query.PriorityBatch}, nil))
+	<-qid
+
+	results := e.ExecuteQuery(q, query.ExecutionOptions{Priority: query.PriorityBatch}, nil)
+	select {
+	case result := <-results:
+		if result.Err != query.ErrQueryQueueTimeout {
+			t.Errorf("unexpected error: %s", result.Err)
+		}
+	case <-qid:
+		t.Fatal("unexpected statement execution for the queued query")
+	}
+
+	if s := e.TaskManager.Statistics(nil)[0]; s.Values["queriesTimedOut"] != int64(1) || s.Values["queriesQueued"] != int64(0) {
+		t.Fatalf("unexpected statistics: %v", s)
+	}
+}
+
+func TestLowerPriority(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		exp  string
+	}{
+		{a: query.PriorityInteractive, b: query.PriorityBatch, exp: query.PriorityBatch},
+		{a: query.PriorityBatch, b: query.PriorityDashboard, exp: query.PriorityBatch},
+		{a: query.PriorityDashboard, b: "", exp: query.PriorityDashboard},
+		{a: "", b: query.PriorityInteractive, exp: query.PriorityInteractive},
+	} {
+		if got := query.LowerPriority(tt.a, tt.b); got != tt.exp {
+			t.Errorf("LowerPriority(%q, %q): got %q, exp %q", tt.a, tt.b, got, tt.exp)
+		}
+	}
+}
+
 func TestQueryExecutor_Close(t *testing.T) {
 	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
 	if err != nil {
@@ -595,6 +761,49 @@ func TestQueryExecutor_InvalidSource(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_TimeZone(t *testing.T) {
+	q, err := query.ParseQuery(`SELECT * FROM cpu; SELECT * FROM cpu TZ('Asia/Tokyo'); SET TIME ZONE 'Europe/Paris'; SELECT * FROM cpu; SET TIME ZONE DEFAULT; SELECT * FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var locations []string
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			switch stmt := stmt.(type) {
+			case *influxql.SelectStatement:
+				locations = append(locations, stmt.Location.String())
+			default:
+				t.Errorf("unexpected statement: %s", stmt)
+			}
+			return ctx.Send(&query.Result{})
+		},
+	}
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	for result := range e.ExecuteQuery(q, query.ExecutionOptions{Location: loc}, nil) {
+		if result.Err != nil {
+			t.Fatalf("unexpected error: %s", result.Err)
+		} else if result.StatementID != n {
+			t.Fatalf("unexpected statement id: got %d, exp %d", result.StatementID, n)
+		}
+		n++
+	}
+	if n != len(q.Statements) {
+		t.Fatalf("unexpected number of results: got %d, exp %d", n, len(q.Statements))
+	}
+
+	if got, exp := strings.Join(locations, ","), "America/New_York,Asia/Tokyo,Europe/Paris,America/New_York"; got != exp {
+		t.Fatalf("unexpected time zones: got %s, exp %s", got, exp)
+	}
+}
+
 func discardOutput(results <-chan *query.Result) {
 	for range results {
 		// Read all results and discard.
@@ -94,6 +94,14 @@ func (m FunctionTypeMapper) CallType(name string, args []influxql.DataType) (inf
 		return influxql.Float, nil
 	case "elapsed":
 		return influxql.Integer, nil
+	case "histogram_sum", "histogram_quantile":
+		if arg0 := args[0]; arg0 != influxql.String && arg0 != influxql.Unknown {
+			return influxql.Unknown, fmt.Errorf("invalid argument type for the first argument in %s(): %s", name, arg0)
+		}
+		if name == "histogram_quantile" {
+			return influxql.Float, nil
+		}
+		return influxql.String, nil
 	default:
 		// TODO(jsternberg): Do not use default for this.
 		return args[0], nil
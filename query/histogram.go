@@ -0,0 +1,232 @@
+package query
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// histogram is a pre-aggregated histogram stored in a string field.
+//
+// A histogram is encoded as a comma-separated list of buckets in the form
+// <upper bound>:<count>, such as "0.1:5,0.5:10,1:12,+Inf:15". The counts are
+// cumulative so each bucket counts the observations less than or equal to
+// its upper bound. The bounds must be in increasing order and the last
+// bucket must have an upper bound of +Inf so it counts all observations.
+type histogram struct {
+	bounds []float64
+	counts []float64
+}
+
+// parseHistogram parses the encoded form of a histogram.
+func parseHistogram(s string) (*histogram, error) {
+	if s == "" {
+		return nil, errors.New("histogram must have at least one bucket")
+	}
+
+	n := strings.Count(s, ",") + 1
+	h := &histogram{
+		bounds: make([]float64, 0, n),
+		counts: make([]float64, 0, n),
+	}
+	for _, bucket := range strings.Split(s, ",") {
+		bound, count, ok := strings.Cut(strings.TrimSpace(bucket), ":")
+		if !ok {
+			return nil, errors.New("histogram bucket must be in the form <upper bound>:<count>")
+		}
+		le, err := strconv.ParseFloat(bound, 64)
+		if err != nil || math.IsNaN(le) || math.IsInf(le, -1) {
+			return nil, errors.New("invalid histogram bucket bound: " + bound)
+		}
+		c, err := strconv.ParseFloat(count, 64)
+		if err != nil || math.IsNaN(c) || math.IsInf(c, 0) || c < 0 {
+			return nil, errors.New("invalid histogram bucket count: " + count)
+		}
+
+		if i := len(h.bounds) - 1; i >= 0 {
+			if le <= h.bounds[i] {
+				return nil, errors.New("histogram bucket bounds must be in increasing order")
+			} else if c < h.counts[i] {
+				return nil, errors.New("histogram bucket counts must be cumulative")
+			}
+		}
+		h.bounds = append(h.bounds, le)
+		h.counts = append(h.counts, c)
+	}
+
+	if !math.IsInf(h.bounds[len(h.bounds)-1], 1) {
+		return nil, errors.New("histogram must have a +Inf bucket")
+	}
+	return h, nil
+}
+
+// String returns the encoded form of the histogram.
+func (h *histogram) String() string {
+	var buf strings.Builder
+	for i := range h.bounds {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.FormatFloat(h.bounds[i], 'g', -1, 64))
+		buf.WriteByte(':')
+		buf.WriteString(strconv.FormatFloat(h.counts[i], 'g', -1, 64))
+	}
+	return buf.String()
+}
+
+// count returns the number of observations counted by the buckets with an
+// upper bound less than or equal to le.
+func (h *histogram) count(le float64) float64 {
+	i := sort.Search(len(h.bounds), func(i int) bool { return h.bounds[i] > le })
+	if i == 0 {
+		return 0
+	}
+	return h.counts[i-1]
+}
+
+// merge adds the buckets of other to the histogram.
+//
+// Histograms with different bounds are merged on the union of their bounds.
+// The observations of a histogram are counted at the greatest of its own
+// bounds that does not exceed a bound of the union, so the total count is
+// exact but the counts of the buckets missing from one of the histograms
+// are lower bounds.
+func (h *histogram) merge(other *histogram) {
+	if len(h.bounds) == len(other.bounds) {
+		same := true
+		for i := range h.bounds {
+			if h.bounds[i] != other.bounds[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			for i := range h.counts {
+				h.counts[i] += other.counts[i]
+			}
+			return
+		}
+	}
+
+	bounds := make([]float64, 0, len(h.bounds)+len(other.bounds))
+	for i, j := 0, 0; i < len(h.bounds) || j < len(other.bounds); {
+		switch {
+		case j == len(other.bounds) || (i < len(h.bounds) && h.bounds[i] < other.bounds[j]):
+			bounds = append(bounds, h.bounds[i])
+			i++
+		case i == len(h.bounds) || other.bounds[j] < h.bounds[i]:
+			bounds = append(bounds, other.bounds[j])
+			j++
+		default:
+			bounds = append(bounds, h.bounds[i])
+			i, j = i+1, j+1
+		}
+	}
+
+	counts := make([]float64, len(bounds))
+	for i, le := range bounds {
+		counts[i] = h.count(le) + other.count(le)
+	}
+	h.bounds, h.counts = bounds, counts
+}
+
+// quantile estimates the value of the q-quantile of the observations of the
+// histogram. The value is interpolated linearly within the bucket that
+// contains the quantile assuming the observations of a bucket are spread
+// evenly between its bounds. The lower bound of the first bucket is zero
+// unless its upper bound is negative and the quantiles that fall in the +Inf
+// bucket have the value of the greatest finite bound.
+//
+// It returns false when the histogram has no observations or no finite
+// bounds.
+func (h *histogram) quantile(q float64) (float64, bool) {
+	n := len(h.bounds)
+	total := h.counts[n-1]
+	if total == 0 || n < 2 {
+		return 0, false
+	}
+
+	rank := q * total
+	i := sort.SearchFloat64s(h.counts, rank)
+	if i == n-1 {
+		return h.bounds[n-2], true
+	} else if i == 0 && h.bounds[0] <= 0 {
+		return h.bounds[0], true
+	}
+
+	var start, prev float64
+	if i > 0 {
+		start, prev = h.bounds[i-1], h.counts[i-1]
+	}
+	end, count := h.bounds[i], h.counts[i]-prev
+	if count == 0 {
+		return end, true
+	}
+	return start + (end-start)*((rank-prev)/count), true
+}
+
+// StringHistogramSumReducer merges the histograms of the aggregated points.
+// Points that do not contain a valid histogram are ignored.
+type StringHistogramSumReducer struct {
+	h *histogram
+}
+
+// NewStringHistogramSumReducer creates a new StringHistogramSumReducer.
+func NewStringHistogramSumReducer() *StringHistogramSumReducer {
+	return &StringHistogramSumReducer{}
+}
+
+// AggregateString aggregates a point into the reducer.
+func (r *StringHistogramSumReducer) AggregateString(p *StringPoint) {
+	h, err := parseHistogram(p.Value)
+	if err != nil {
+		return
+	}
+	if r.h == nil {
+		r.h = h
+		return
+	}
+	r.h.merge(h)
+}
+
+// Emit emits the merged histogram. Nothing is emitted if no point contained
+// a valid histogram.
+func (r *StringHistogramSumReducer) Emit() []StringPoint {
+	if r.h == nil {
+		return nil
+	}
+	return []StringPoint{{
+		Time:  ZeroTime,
+		Value: r.h.String(),
+	}}
+}
+
+// StringHistogramQuantileReducer estimates a quantile of the merged histograms
+// of the aggregated points.
+type StringHistogramQuantileReducer struct {
+	StringHistogramSumReducer
+	q float64
+}
+
+// NewStringHistogramQuantileReducer creates a new StringHistogramQuantileReducer.
+func NewStringHistogramQuantileReducer(q float64) *StringHistogramQuantileReducer {
+	return &StringHistogramQuantileReducer{q: q}
+}
+
+// Emit emits the estimated quantile. Nothing is emitted if the histograms do
+// not have any observations.
+func (r *StringHistogramQuantileReducer) Emit() []FloatPoint {
+	if r.h == nil {
+		return nil
+	}
+	v, ok := r.h.quantile(r.q)
+	if !ok {
+		return nil
+	}
+	return []FloatPoint{{
+		Time:  ZeroTime,
+		Value: v,
+	}}
+}
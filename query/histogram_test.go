@@ -0,0 +1,82 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestStringHistogramSumReducer(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		values []string
+		exp    []query.StringPoint
+	}{
+		{
+			name:   "SameBounds",
+			values: []string{"0.1:1,0.5:2,+Inf:2", "0.1:0,0.5:3,+Inf:5"},
+			exp:    []query.StringPoint{{Time: query.ZeroTime, Value: "0.1:1,0.5:5,+Inf:7"}},
+		},
+		{
+			name:   "DifferentBounds",
+			values: []string{"1:1,4:3,+Inf:4", "2:2,4:2,+Inf:3"},
+			exp:    []query.StringPoint{{Time: query.ZeroTime, Value: "1:1,2:3,4:5,+Inf:7"}},
+		},
+		{
+			name: "Invalid",
+			values: []string{
+				"",
+				"1:1,+Inf",
+				"1:1,2:3",
+				"2:1,1:2,+Inf:3",
+				"1:2,2:1,+Inf:3",
+				"-Inf:0,+Inf:1",
+				"1:-1,+Inf:1",
+				"1:NaN,+Inf:1",
+				"1:1, 2:2, +Inf:3",
+			},
+			exp: []query.StringPoint{{Time: query.ZeroTime, Value: "1:1,2:2,+Inf:3"}},
+		},
+		{
+			name:   "Empty",
+			values: []string{"garbage"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := query.NewStringHistogramSumReducer()
+			for _, v := range tt.values {
+				r.AggregateString(&query.StringPoint{Value: v})
+			}
+			if diff := cmp.Diff(tt.exp, r.Emit()); diff != "" {
+				t.Fatalf("unexpected points:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStringHistogramQuantileReducer(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		value string
+		q     float64
+		exp   []query.FloatPoint
+	}{
+		{name: "Interpolate", value: "1:2,2:6,4:8,+Inf:8", q: 0.5, exp: []query.FloatPoint{{Time: query.ZeroTime, Value: 1.5}}},
+		{name: "FirstBucket", value: "2:4,+Inf:4", q: 0.25, exp: []query.FloatPoint{{Time: query.ZeroTime, Value: 0.5}}},
+		{name: "NegativeFirstBucket", value: "-1:4,1:8,+Inf:8", q: 0.25, exp: []query.FloatPoint{{Time: query.ZeroTime, Value: -1}}},
+		{name: "InfBucket", value: "1:2,2:3,+Inf:4", q: 0.99, exp: []query.FloatPoint{{Time: query.ZeroTime, Value: 2}}},
+		{name: "Min", value: "1:0,2:3,+Inf:3", q: 0, exp: []query.FloatPoint{{Time: query.ZeroTime, Value: 1}}},
+		{name: "Max", value: "1:1,2:3,+Inf:3", q: 1, exp: []query.FloatPoint{{Time: query.ZeroTime, Value: 2}}},
+		{name: "NoObservations", value: "1:0,+Inf:0", q: 0.5},
+		{name: "NoFiniteBounds", value: "+Inf:10", q: 0.5},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := query.NewStringHistogramQuantileReducer(tt.q)
+			r.AggregateString(&query.StringPoint{Value: tt.value})
+			if diff := cmp.Diff(tt.exp, r.Emit()); diff != "" {
+				t.Fatalf("unexpected points:\n%s", diff)
+			}
+		})
+	}
+}
@@ -833,6 +833,10 @@ CONSTRUCT:
 					return nil, err
 				} else if next != nil && next.Name == itr.window.name && next.Tags.ID() == itr.window.tags.ID() {
 					interval := int64(itr.opt.Interval.Duration)
+					if itr.opt.Interval.Months > 0 {
+						// Calendar windows vary in length so interpolate using the time.
+						interval = 1
+					}
 					start := itr.window.time / interval
 					p.Value = linearFloat(start, itr.prev.Time/interval, next.Time/interval, itr.prev.Value, next.Value)
 				} else {
@@ -847,7 +851,7 @@ CONSTRUCT:
 		case influxql.NumberFill:
 			p.Value, _ = castToFloat(itr.opt.FillValue)
 		case influxql.PreviousFill:
-			if !itr.prev.Nil {
+			if !itr.prev.Nil && !itr.opt.stale(itr.prev.Time, itr.window.time) {
 				p.Value = itr.prev.Value
 				p.Nil = itr.prev.Nil
 			} else {
@@ -861,6 +865,16 @@ CONSTRUCT:
 	// Advance the expected time. Do not advance to a new window here
 	// as there may be lingering points with the same timestamp in the previous
 	// window.
+	if itr.opt.Interval.Months > 0 {
+		// Calendar windows vary in length and already account for changes
+		// in the zone offset so move to the start of the adjacent window.
+		if itr.opt.Ascending {
+			_, itr.window.time = itr.opt.Window(itr.window.time)
+		} else {
+			itr.window.time, _ = itr.opt.Window(itr.window.time - 1)
+		}
+		return p, nil
+	}
 	if itr.opt.Ascending {
 		itr.window.time += int64(itr.opt.Interval.Duration)
 	} else {
@@ -3497,6 +3511,10 @@ CONSTRUCT:
 					return nil, err
 				} else if next != nil && next.Name == itr.window.name && next.Tags.ID() == itr.window.tags.ID() {
 					interval := int64(itr.opt.Interval.Duration)
+					if itr.opt.Interval.Months > 0 {
+						// Calendar windows vary in length so interpolate using the time.
+						interval = 1
+					}
 					start := itr.window.time / interval
 					p.Value = linearInteger(start, itr.prev.Time/interval, next.Time/interval, itr.prev.Value, next.Value)
 				} else {
@@ -3511,7 +3529,7 @@ CONSTRUCT:
 		case influxql.NumberFill:
 			p.Value, _ = castToInteger(itr.opt.FillValue)
 		case influxql.PreviousFill:
-			if !itr.prev.Nil {
+			if !itr.prev.Nil && !itr.opt.stale(itr.prev.Time, itr.window.time) {
 				p.Value = itr.prev.Value
 				p.Nil = itr.prev.Nil
 			} else {
@@ -3525,6 +3543,16 @@ CONSTRUCT:
 	// Advance the expected time. Do not advance to a new window here
 	// as there may be lingering points with the same timestamp in the previous
 	// window.
+	if itr.opt.Interval.Months > 0 {
+		// Calendar windows vary in length and already account for changes
+		// in the zone offset so move to the start of the adjacent window.
+		if itr.opt.Ascending {
+			_, itr.window.time = itr.opt.Window(itr.window.time)
+		} else {
+			itr.window.time, _ = itr.opt.Window(itr.window.time - 1)
+		}
+		return p, nil
+	}
 	if itr.opt.Ascending {
 		itr.window.time += int64(itr.opt.Interval.Duration)
 	} else {
@@ -6161,6 +6189,10 @@ CONSTRUCT:
 					return nil, err
 				} else if next != nil && next.Name == itr.window.name && next.Tags.ID() == itr.window.tags.ID() {
 					interval := int64(itr.opt.Interval.Duration)
+					if itr.opt.Interval.Months > 0 {
+						// Calendar windows vary in length so interpolate using the time.
+						interval = 1
+					}
 					start := itr.window.time / interval
 					p.Value = linearUnsigned(start, itr.prev.Time/interval, next.Time/interval, itr.prev.Value, next.Value)
 				} else {
@@ -6175,7 +6207,7 @@ CONSTRUCT:
 		case influxql.NumberFill:
 			p.Value, _ = castToUnsigned(itr.opt.FillValue)
 		case influxql.PreviousFill:
-			if !itr.prev.Nil {
+			if !itr.prev.Nil && !itr.opt.stale(itr.prev.Time, itr.window.time) {
 				p.Value = itr.prev.Value
 				p.Nil = itr.prev.Nil
 			} else {
@@ -6189,6 +6221,16 @@ CONSTRUCT:
 	// Advance the expected time. Do not advance to a new window here
 	// as there may be lingering points with the same timestamp in the previous
 	// window.
+	if itr.opt.Interval.Months > 0 {
+		// Calendar windows vary in length and already account for changes
+		// in the zone offset so move to the start of the adjacent window.
+		if itr.opt.Ascending {
+			_, itr.window.time = itr.opt.Window(itr.window.time)
+		} else {
+			itr.window.time, _ = itr.opt.Window(itr.window.time - 1)
+		}
+		return p, nil
+	}
 	if itr.opt.Ascending {
 		itr.window.time += int64(itr.opt.Interval.Duration)
 	} else {
@@ -8825,7 +8867,7 @@ CONSTRUCT:
 		case influxql.NumberFill:
 			p.Value, _ = castToString(itr.opt.FillValue)
 		case influxql.PreviousFill:
-			if !itr.prev.Nil {
+			if !itr.prev.Nil && !itr.opt.stale(itr.prev.Time, itr.window.time) {
 				p.Value = itr.prev.Value
 				p.Nil = itr.prev.Nil
 			} else {
@@ -8839,6 +8881,16 @@ CONSTRUCT:
 	// Advance the expected time. Do not advance to a new window here
 	// as there may be lingering points with the same timestamp in the previous
 	// window.
+	if itr.opt.Interval.Months > 0 {
+		// Calendar windows vary in length and already account for changes
+		// in the zone offset so move to the start of the adjacent window.
+		if itr.opt.Ascending {
+			_, itr.window.time = itr.opt.Window(itr.window.time)
+		} else {
+			itr.window.time, _ = itr.opt.Window(itr.window.time - 1)
+		}
+		return p, nil
+	}
 	if itr.opt.Ascending {
 		itr.window.time += int64(itr.opt.Interval.Duration)
 	} else {
@@ -11475,7 +11527,7 @@ CONSTRUCT:
 		case influxql.NumberFill:
 			p.Value, _ = castToBoolean(itr.opt.FillValue)
 		case influxql.PreviousFill:
-			if !itr.prev.Nil {
+			if !itr.prev.Nil && !itr.opt.stale(itr.prev.Time, itr.window.time) {
 				p.Value = itr.prev.Value
 				p.Nil = itr.prev.Nil
 			} else {
@@ -11489,6 +11541,16 @@ CONSTRUCT:
 	// Advance the expected time. Do not advance to a new window here
 	// as there may be lingering points with the same timestamp in the previous
 	// window.
+	if itr.opt.Interval.Months > 0 {
+		// Calendar windows vary in length and already account for changes
+		// in the zone offset so move to the start of the adjacent window.
+		if itr.opt.Ascending {
+			_, itr.window.time = itr.opt.Window(itr.window.time)
+		} else {
+			itr.window.time, _ = itr.opt.Window(itr.window.time - 1)
+		}
+		return p, nil
+	}
 	if itr.opt.Ascending {
 		itr.window.time += int64(itr.opt.Interval.Duration)
 	} else {
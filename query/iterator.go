@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/influxdata/influxdb/pkg/tracing"
@@ -598,6 +600,10 @@ type IteratorOptions struct {
 	Fill      influxql.FillOption
 	FillValue interface{}
 
+	// Stops carrying a value forward with fill(previous) once it is older
+	// than this duration. Zero means there is no limit.
+	MaxStaleness time.Duration
+
 	// Condition to filter by.
 	Condition influxql.Expr
 
@@ -632,6 +638,9 @@ type IteratorOptions struct {
 
 	// Authorizer can limit access to data
 	Authorizer FineAuthorizer
+
+	// UserFunctions are the user-defined functions called by the fields.
+	UserFunctions UserFunctions
 }
 
 // newIteratorOptionsStmt creates the iterator options from stmt.
@@ -656,20 +665,10 @@ func newIteratorOptionsStmt(stmt *influxql.SelectStatement, sopt SelectOptions)
 	opt.Location = stmt.Location
 
 	// Determine group by interval.
-	interval, err := stmt.GroupByInterval()
+	opt.Interval, err = groupByInterval(stmt)
 	if err != nil {
 		return opt, err
 	}
-	// Set duration to zero if a negative interval has been used.
-	if interval < 0 {
-		interval = 0
-	} else if interval > 0 {
-		opt.Interval.Offset, err = stmt.GroupByOffset()
-		if err != nil {
-			return opt, err
-		}
-	}
-	opt.Interval.Duration = interval
 
 	// Always request an ordered output for the top level iterators.
 	// The emitter will always emit points as ordered.
@@ -713,6 +712,8 @@ func newIteratorOptionsSubstatement(ctx context.Context, stmt *influxql.SelectSt
 		return IteratorOptions{}, err
 	}
 
+	subOpt.UserFunctions = opt.UserFunctions
+
 	if subOpt.StartTime < opt.StartTime {
 		subOpt.StartTime = opt.StartTime
 	}
@@ -771,10 +772,10 @@ func newIteratorOptionsSubstatement(ctx context.Context, stmt *influxql.SelectSt
 
 	// If there is no interval for this subquery, but the outer query has an
 	// interval, inherit the parent interval.
-	interval, err := stmt.GroupByInterval()
+	interval, err := groupByInterval(stmt)
 	if err != nil {
 		return IteratorOptions{}, err
-	} else if interval == 0 {
+	} else if interval.IsZero() {
 		subOpt.Interval = opt.Interval
 	}
 	return subOpt, nil
@@ -807,6 +808,8 @@ func (opt IteratorOptions) StopTime() int64 {
 func (opt IteratorOptions) Window(t int64) (start, end int64) {
 	if opt.Interval.IsZero() {
 		return opt.StartTime, opt.EndTime + 1
+	} else if opt.Interval.Months > 0 {
+		return opt.calendarWindow(t)
 	}
 
 	// Subtract the offset to the time so we calculate the correct base interval.
@@ -886,6 +889,54 @@ func (opt IteratorOptions) Window(t int64) (start, end int64) {
 	return
 }
 
+// calendarWindow returns the calendar window [start,end) that t falls within.
+// The window boundaries are computed from the wall clock in the location so
+// a window always starts at the same local time, whether or not daylight
+// saving time is in effect.
+func (opt IteratorOptions) calendarWindow(t int64) (start, end int64) {
+	loc := opt.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	months := opt.Interval.Months
+
+	// windowStart returns the start of the window that begins n months
+	// after the start of year zero.
+	windowStart := func(n int) time.Time {
+		return time.Date(n/12, time.Month(n%12+1), 1, 0, 0, 0, int(opt.Interval.Offset), loc)
+	}
+
+	// Align the month of the time to the interval and then adjust for the
+	// offset, which may move the time into an adjacent window.
+	tt := time.Unix(0, t).In(loc)
+	n := tt.Year()*12 + int(tt.Month()) - 1
+	n -= n % months
+	for windowStart(n).After(tt) {
+		n -= months
+	}
+	for !windowStart(n + months).After(tt) {
+		n += months
+	}
+
+	if s := windowStart(n); s.After(time.Unix(0, influxql.MinTime)) {
+		start = s.UnixNano()
+	} else {
+		start = influxql.MinTime
+	}
+	if e := windowStart(n + months); e.Before(time.Unix(0, influxql.MaxTime)) {
+		end = e.UnixNano()
+	} else {
+		end = influxql.MaxTime
+	}
+	return start, end
+}
+
+// stale returns true if a value from time prev is older than the maximum
+// staleness at time t and should not be carried forward by fill(previous).
+func (opt IteratorOptions) stale(prev, t int64) bool {
+	return opt.MaxStaleness > 0 && abs(t-prev) > int64(opt.MaxStaleness)
+}
+
 // DerivativeInterval returns the time interval for the derivative function.
 func (opt IteratorOptions) DerivativeInterval() Interval {
 	// Use the interval on the derivative() call, if specified.
@@ -1160,15 +1211,118 @@ func decodeMeasurement(pb *internal.Measurement) (*influxql.Measurement, error)
 type Interval struct {
 	Duration time.Duration
 	Offset   time.Duration
+
+	// Months is the number of calendar months in the interval. Calendar
+	// intervals start on the first day of a month at midnight in the location
+	// of the query and Duration holds the longest the interval can be.
+	Months int
 }
 
 // IsZero returns true if the interval has no duration.
 func (i Interval) IsZero() bool { return i.Duration == 0 }
 
+// calendarMonth is the longest duration of a calendar month.
+const calendarMonth = 31 * 24 * time.Hour
+
+// parseCalendarInterval parses a calendar interval with a month (mo),
+// quarter (q), or year (y) unit. It returns false if s is not a calendar
+// interval.
+func parseCalendarInterval(s string) (Interval, bool) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil || n <= 0 {
+		return Interval{}, false
+	}
+
+	var months int
+	switch s[i:] {
+	case "mo":
+		months = n
+	case "q":
+		months = n * 3
+	case "y":
+		months = n * 12
+	default:
+		return Interval{}, false
+	}
+
+	// The interval must fit within the range of a duration.
+	if months > int(math.MaxInt64/int64(calendarMonth)) {
+		return Interval{}, false
+	}
+	return Interval{Duration: time.Duration(months) * calendarMonth, Months: months}, true
+}
+
+// calendarOffset returns the offset of t from the start of the calendar
+// window that contains it. The offset is measured on the wall clock in the
+// location so that it is not affected by daylight saving time.
+func calendarOffset(interval Interval, t time.Time, loc *time.Location) time.Duration {
+	if loc == nil {
+		loc = time.UTC
+	}
+	opt := IteratorOptions{Interval: Interval{Duration: interval.Duration, Months: interval.Months}, Location: loc}
+	start, _ := opt.Window(t.UnixNano())
+
+	wall := func(t time.Time) time.Time {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return wall(t).Sub(wall(time.Unix(0, start)))
+}
+
+// groupByInterval returns the interval of the time dimension of the
+// statement. Unlike influxql.SelectStatement.GroupByInterval, it supports
+// calendar intervals which are stored as a string argument to time().
+// A negative interval is treated as no interval.
+func groupByInterval(stmt *influxql.SelectStatement) (Interval, error) {
+	for _, d := range stmt.Dimensions {
+		call, ok := d.Expr.(*influxql.Call)
+		if !ok || call.Name != "time" || len(call.Args) == 0 {
+			continue
+		}
+		lit, ok := call.Args[0].(*influxql.StringLiteral)
+		if !ok {
+			break
+		}
+
+		interval, ok := parseCalendarInterval(lit.Val)
+		if !ok {
+			return Interval{}, errors.New("time dimension must have duration argument")
+		}
+		if len(call.Args) == 2 {
+			switch expr := call.Args[1].(type) {
+			case *influxql.DurationLiteral:
+				interval.Offset = expr.Val
+			case *influxql.TimeLiteral:
+				interval.Offset = calendarOffset(interval, expr.Val, stmt.Location)
+			default:
+				return Interval{}, fmt.Errorf("invalid time dimension offset: %s", expr)
+			}
+		}
+		return interval, nil
+	}
+
+	interval, err := stmt.GroupByInterval()
+	if err != nil {
+		return Interval{}, err
+	} else if interval <= 0 {
+		return Interval{}, nil
+	}
+	offset, err := stmt.GroupByOffset()
+	if err != nil {
+		return Interval{}, err
+	}
+	return Interval{Duration: interval, Offset: offset}, nil
+}
+
 func encodeInterval(i Interval) *internal.Interval {
 	return &internal.Interval{
 		Duration: proto.Int64(i.Duration.Nanoseconds()),
 		Offset:   proto.Int64(i.Offset.Nanoseconds()),
+		Months:   proto.Int64(int64(i.Months)),
 	}
 }
 
@@ -1176,6 +1330,7 @@ func decodeInterval(pb *internal.Interval) Interval {
 	return Interval{
 		Duration: time.Duration(pb.GetDuration()),
 		Offset:   time.Duration(pb.GetOffset()),
+		Months:   int(pb.GetMonths()),
 	}
 }
 
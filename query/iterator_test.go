@@ -1478,6 +1478,89 @@ func TestIteratorOptions_Window_Location(t *testing.T) {
 	}
 }
 
+func TestIteratorOptions_Window_Calendar(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		now        time.Time
+		start, end time.Time
+		months     int
+		offset     time.Duration
+		loc        *time.Location
+	}{
+		{
+			name:   "Month",
+			now:    mustParseTime("2000-02-15T12:00:00Z"),
+			start:  mustParseTime("2000-02-01T00:00:00Z"),
+			end:    mustParseTime("2000-03-01T00:00:00Z"),
+			months: 1,
+		},
+		{
+			name:   "Month_Start",
+			now:    mustParseTime("2000-03-01T00:00:00Z"),
+			start:  mustParseTime("2000-03-01T00:00:00Z"),
+			end:    mustParseTime("2000-04-01T00:00:00Z"),
+			months: 1,
+		},
+		{
+			name:   "Quarter",
+			now:    mustParseTime("2000-05-20T00:00:00Z"),
+			start:  mustParseTime("2000-04-01T00:00:00Z"),
+			end:    mustParseTime("2000-07-01T00:00:00Z"),
+			months: 3,
+		},
+		{
+			name:   "Year",
+			now:    mustParseTime("2000-12-31T23:59:59Z"),
+			start:  mustParseTime("2000-01-01T00:00:00Z"),
+			end:    mustParseTime("2001-01-01T00:00:00Z"),
+			months: 12,
+		},
+		{
+			name:   "Offset",
+			now:    mustParseTime("2000-02-10T00:00:00Z"),
+			start:  mustParseTime("2000-01-15T00:00:00Z"),
+			end:    mustParseTime("2000-02-15T00:00:00Z"),
+			months: 1,
+			offset: 14 * 24 * time.Hour,
+		},
+		{
+			name:   "Location",
+			now:    mustParseTime("2000-04-15T12:00:00-07:00"),
+			start:  mustParseTime("2000-04-01T00:00:00-08:00"),
+			end:    mustParseTime("2000-05-01T00:00:00-07:00"),
+			months: 1,
+			loc:    LosAngeles,
+		},
+		{
+			name:   "Location_Offset",
+			now:    mustParseTime("2000-11-01T03:00:00-08:00"),
+			start:  mustParseTime("2000-10-01T06:00:00-07:00"),
+			end:    mustParseTime("2000-11-01T06:00:00-08:00"),
+			months: 1,
+			offset: 6 * time.Hour,
+			loc:    LosAngeles,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := query.IteratorOptions{
+				Location: tt.loc,
+				Interval: query.Interval{
+					Duration: time.Duration(tt.months) * 31 * 24 * time.Hour,
+					Offset:   tt.offset,
+					Months:   tt.months,
+				},
+			}
+			start, end := opt.Window(tt.now.UnixNano())
+			if have, want := time.Unix(0, start), tt.start; !have.Equal(want) {
+				t.Errorf("unexpected start time: %s != %s", have, want)
+			}
+			if have, want := time.Unix(0, end), tt.end; !have.Equal(want) {
+				t.Errorf("unexpected end time: %s != %s", have, want)
+			}
+		})
+	}
+}
+
 func TestIteratorOptions_Window_MinTime(t *testing.T) {
 	opt := query.IteratorOptions{
 		StartTime: influxql.MinTime,
@@ -1647,6 +1730,7 @@ func TestIteratorOptions_MarshalBinary(t *testing.T) {
 		Interval: query.Interval{
 			Duration: 1 * time.Hour,
 			Offset:   20 * time.Minute,
+			Months:   1,
 		},
 		Dimensions: []string{"region", "host"},
 		GroupBy: map[string]struct{}{
@@ -2028,11 +2112,13 @@ func BooleanIterators(inputs []*BooleanIterator) []query.Iterator {
 
 // MustParseSelectStatement parses a select statement. Panic on error.
 func MustParseSelectStatement(s string) *influxql.SelectStatement {
-	stmt, err := influxql.NewParser(strings.NewReader(s)).ParseStatement()
+	q, err := query.ParseQuery(s)
 	if err != nil {
 		panic(err)
+	} else if len(q.Statements) != 1 {
+		panic(fmt.Sprintf("expected 1 statement, got %d", len(q.Statements)))
 	}
-	return stmt.(*influxql.SelectStatement)
+	return q.Statements[0].(*influxql.SelectStatement)
 }
 
 // MustParseExpr parses an expression. Panic on error.
@@ -59,3 +59,72 @@ func TestPointLimitMonitor(t *testing.T) {
 		t.Fatalf("unexpected error: got=%v want=%v", got, want)
 	}
 }
+
+func TestPolicyMonitor(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		policy query.QueryPolicy
+		err    string
+	}{
+		{
+			name:   "MaxSeriesN",
+			policy: query.QueryPolicy{Name: "dashboards", MaxSeriesN: 2},
+			err:    "query killed by policy dashboards: max-series limit exceeded: (3/2)",
+		},
+		{
+			name:   "MaxPointN",
+			policy: query.QueryPolicy{Name: "dashboards", MaxSeriesN: 3, MaxPointN: 5},
+			err:    "query killed by policy dashboards: max-points limit exceeded: (10/5)",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := MustParseSelectStatement(`SELECT mean(value) FROM cpu`)
+
+			taskManager := query.NewTaskManager()
+			taskManager.Policies = []query.QueryPolicy{tt.policy}
+			ctx, detach, err := taskManager.AttachQuery(&influxql.Query{
+				Statements: []influxql.Statement{stmt},
+			}, query.ExecutionOptions{}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer detach()
+
+			shardMapper := ShardMapper{
+				MapShardsFn: func(sources influxql.Sources, t influxql.TimeRange) query.ShardGroup {
+					return &ShardGroup{
+						CreateIteratorFn: func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+							return &FloatIterator{
+								Points: []query.FloatPoint{
+									{Name: "cpu", Value: 35},
+								},
+								Context: ctx,
+								Delay:   2 * time.Second,
+								stats: query.IteratorStats{
+									SeriesN: 3,
+									PointN:  10,
+								},
+							}, nil
+						},
+						Fields: map[string]influxql.DataType{
+							"value": influxql.Float,
+						},
+					}
+				},
+			}
+
+			cur, err := query.Select(ctx, stmt, &shardMapper, query.SelectOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if err := query.DrainCursor(cur); err == nil {
+				t.Fatalf("expected an error")
+			} else if got, want := err.Error(), tt.err; got != want {
+				t.Fatalf("unexpected error: got=%v want=%v", got, want)
+			}
+		})
+	}
+}
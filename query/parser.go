@@ -0,0 +1,1576 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/influxdata/influxql"
+)
+
+// Parser parses InfluxQL queries.
+//
+// It accepts the same grammar as influxql.Parser and, in addition, the
+// clauses that are implemented by this query engine on top of the InfluxQL
+// grammar. Those clauses are lowered into plain InfluxQL before the query is
+// handed to influxql.Parser so that the resulting AST can be formatted with
+// String() and parsed again by influxql.Parser. This matters for statements
+// that are stored as text, such as continuous queries.
+//
+// Statements that cannot be expressed in InfluxQL, such as SET TIME ZONE, are
+// parsed by extending the InfluxQL language instead.
+type Parser struct {
+	r      io.Reader
+	params map[string]interface{}
+}
+
+// NewParser returns a new instance of Parser.
+func NewParser(r io.Reader) *Parser {
+	return &Parser{r: r}
+}
+
+// SetParams sets the parameters that will be used for any bound parameter substitutions.
+func (p *Parser) SetParams(params map[string]interface{}) {
+	p.params = params
+}
+
+// ParseQuery parses a query string and returns its AST representation.
+func (p *Parser) ParseQuery() (*influxql.Query, error) {
+	buf, err := io.ReadAll(p.r)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := lowerQuery(string(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	parser := influxql.NewParser(strings.NewReader(s))
+	parser.SetParams(p.params)
+	return parseQuery(parser)
+}
+
+// ParseQuery parses a query string, including any extended clauses, and
+// returns its AST representation.
+func ParseQuery(s string) (*influxql.Query, error) {
+	return NewParser(strings.NewReader(s)).ParseQuery()
+}
+
+// language is the InfluxQL language extended with the statements that are
+// implemented by this package.
+var language = cloneParseTree(influxql.Language)
+
+func init() {
+	// SET TIME ZONE and SET QUOTA start with an identifier as TIME, ZONE
+	// and QUOTA are not keywords in InfluxQL. SET WRITE TARGET and SET
+	// QUERY PRIORITY start with the WRITE and QUERY keywords.
+	set := language.Group(influxql.SET)
+	set.Handle(influxql.IDENT, parseSetIdentStatement)
+	set.Keys = append(set.Keys[:len(set.Keys)-1], "TIME", "QUOTA")
+	set.Handle(influxql.WRITE, parseSetWriteTargetStatement)
+	set.Handle(influxql.QUERY, parseSetQueryPriorityStatement)
+
+	// GRANT and REVOKE accept an optional MEASUREMENT clause after the
+	// database and roles as grantees. The handlers replace the ones of
+	// InfluxQL, like the one of SHOW GRANTS FOR.
+	language.Handlers[influxql.GRANT] = parseGrantStatement
+	language.Handlers[influxql.REVOKE] = parseRevokeStatement
+	language.Group(influxql.SHOW, influxql.GRANTS).Handlers[influxql.FOR] = parseShowGrantsStatement
+
+	// ROLE, ROLES, TOKEN, TOKENS, QUOTA and QUOTAS are not keywords in
+	// InfluxQL either.
+	for tok, words := range map[influxql.Token][]string{
+		influxql.CREATE: {"ROLE", "TOKEN"},
+		influxql.DROP:   {"ROLE", "TOKEN", "QUOTA"},
+		influxql.SHOW:   {"ROLES", "TOKENS", "QUOTAS"},
+	} {
+		tree := language.Group(tok)
+		tree.Handle(influxql.IDENT, parseIdentStatement(tok, words))
+		tree.Keys = append(tree.Keys[:len(tree.Keys)-1], words...)
+	}
+
+	// SHOW SHARD GROUPS accepts an ON clause to list the shard groups of a
+	// database or a retention policy. CREATE SHARD GROUP and TRUNCATE SHARD
+	// GROUPS manage them.
+	language.Group(influxql.SHOW, influxql.SHARD).Handlers[influxql.GROUPS] = parseShowShardGroupsStatement
+	language.Group(influxql.CREATE).Handle(influxql.SHARD, parseCreateShardGroupStatement)
+
+	// TRUNCATE and RENAME are not keywords in InfluxQL.
+	language.Handle(influxql.IDENT, parseRootIdentStatement)
+	language.Keys = append(language.Keys[:len(language.Keys)-1], "TRUNCATE", "RENAME")
+}
+
+// cloneParseTree returns a deep copy of the parse tree. Unlike
+// influxql.ParseTree.Clone, it copies the keys that are used to report the
+// expected tokens in parse errors.
+func cloneParseTree(t *influxql.ParseTree) *influxql.ParseTree {
+	other := &influxql.ParseTree{Keys: append([]string(nil), t.Keys...)}
+	if t.Handlers != nil {
+		other.Handlers = make(map[influxql.Token]func(*influxql.Parser) (influxql.Statement, error), len(t.Handlers))
+		for tok, handler := range t.Handlers {
+			other.Handlers[tok] = handler
+		}
+	}
+	if t.Tokens != nil {
+		other.Tokens = make(map[influxql.Token]*influxql.ParseTree, len(t.Tokens))
+		for tok, subtree := range t.Tokens {
+			other.Tokens[tok] = cloneParseTree(subtree)
+		}
+	}
+	return other
+}
+
+// parseQuery parses the statements of a query using the extended language.
+// It is equivalent to influxql.Parser.ParseQuery otherwise.
+func parseQuery(p *influxql.Parser) (*influxql.Query, error) {
+	var statements influxql.Statements
+	semi := true
+
+	for {
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok == influxql.EOF {
+			return &influxql.Query{Statements: statements}, nil
+		} else if tok == influxql.SEMICOLON {
+			semi = true
+		} else {
+			if !semi {
+				return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{";"}, Pos: pos}
+			}
+			p.Unscan()
+			s, err := language.Parse(p)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, s)
+			semi = false
+		}
+	}
+}
+
+// parseSetIdentStatement parses a SET TIME ZONE or SET QUOTA statement. This
+// function assumes the SET token and the identifier that follows it have
+// already been consumed.
+func parseSetIdentStatement(p *influxql.Parser) (influxql.Statement, error) {
+	p.Unscan()
+	if tok, _, lit := p.ScanIgnoreWhitespace(); tok == influxql.IDENT && strings.EqualFold(lit, "QUOTA") {
+		return parseSetQuotaStatement(p)
+	}
+	return parseSetTimeZoneStatement(p)
+}
+
+// parseSetTimeZoneStatement parses a SET TIME ZONE statement. This function
+// assumes the SET token and the identifier that follows it have already
+// been consumed.
+func parseSetTimeZoneStatement(p *influxql.Parser) (influxql.Statement, error) {
+	p.Unscan()
+	for _, word := range []string{"TIME", "ZONE"} {
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.IDENT || !strings.EqualFold(lit, word) {
+			return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{word}, Pos: pos}
+		}
+	}
+
+	// Parse the user name if the time zone is set for a user.
+	var name string
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	forUser := tok == influxql.FOR
+	if forUser {
+		var err error
+		if name, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.EQ {
+			return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"="}, Pos: pos}
+		}
+		tok, pos, lit = p.ScanIgnoreWhitespace()
+	}
+
+	var loc *time.Location
+	switch tok {
+	case influxql.STRING:
+		var err error
+		if loc, err = time.LoadLocation(lit); err != nil {
+			return nil, fmt.Errorf("unable to find time zone %s", lit)
+		}
+	case influxql.DEFAULT:
+	default:
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"string", "DEFAULT"}, Pos: pos}
+	}
+
+	if forUser {
+		return &SetUserTimeZoneStatement{Name: name, Location: loc}, nil
+	}
+	return &SetTimeZoneStatement{Location: loc}, nil
+}
+
+// parseSetWriteTargetStatement parses a SET WRITE TARGET FOR statement. This
+// function assumes the SET and WRITE tokens have already been consumed.
+func parseSetWriteTargetStatement(p *influxql.Parser) (influxql.Statement, error) {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.IDENT || !strings.EqualFold(lit, "TARGET") {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"TARGET"}, Pos: pos}
+	}
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.FOR {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"FOR"}, Pos: pos}
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.EQ {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"="}, Pos: pos}
+	}
+
+	stmt := &SetUserWriteTargetStatement{Name: name}
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == influxql.DEFAULT {
+		return stmt, nil
+	}
+	p.Unscan()
+	if stmt.Database, stmt.RetentionPolicy, err = parseDatabaseRetentionPolicy(p); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseSetQueryPriorityStatement parses a SET QUERY PRIORITY FOR statement.
+// This function assumes the SET and QUERY tokens have already been consumed.
+func parseSetQueryPriorityStatement(p *influxql.Parser) (influxql.Statement, error) {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.IDENT || !strings.EqualFold(lit, "PRIORITY") {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"PRIORITY"}, Pos: pos}
+	}
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.FOR {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"FOR"}, Pos: pos}
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.EQ {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"="}, Pos: pos}
+	}
+
+	stmt := &SetUserQueryPriorityStatement{Name: name}
+	switch tok, pos, lit := p.ScanIgnoreWhitespace(); tok {
+	case influxql.STRING:
+		if !ValidPriority(lit) {
+			return nil, fmt.Errorf("unknown query priority %s, expected one of %s", lit, strings.Join(Priorities, ", "))
+		}
+		stmt.Priority = lit
+	case influxql.DEFAULT:
+	default:
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"string", "DEFAULT"}, Pos: pos}
+	}
+	return stmt, nil
+}
+
+// parseDatabaseRetentionPolicy parses a database with an optional retention
+// policy in the db[.rp] form.
+func parseDatabaseRetentionPolicy(p *influxql.Parser) (db, rp string, err error) {
+	if db, err = p.ParseIdent(); err != nil {
+		return "", "", err
+	}
+	if tok, _, _ := p.Scan(); tok != influxql.DOT {
+		p.Unscan()
+		return db, "", nil
+	}
+	if rp, err = p.ParseIdent(); err != nil {
+		return "", "", err
+	}
+	return db, rp, nil
+}
+
+// parseGrantStatement parses a GRANT statement. It accepts the same grammar
+// as InfluxQL, a MEASUREMENT clause that restricts the privilege to the
+// measurements with a name or matching a regular expression, a role as the
+// grantee of a privilege on a database and GRANT ROLE to make a user a member
+// of a role. This function assumes the GRANT token has already been consumed.
+func parseGrantStatement(p *influxql.Parser) (influxql.Statement, error) {
+	if role, user, ok, err := parseRoleMembership(p, influxql.TO); err != nil {
+		return nil, err
+	} else if ok {
+		return &GrantRoleStatement{Role: role, User: user}, nil
+	}
+
+	priv, err := parsePrivilege(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for ON or TO clauses.
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok == influxql.TO {
+		// Admin privilege is only granted on ALL PRIVILEGES.
+		if priv != influxql.AllPrivileges {
+			return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"ON"}, Pos: pos}
+		}
+		user, role, err := parseGrantee(p)
+		if err != nil {
+			return nil, err
+		} else if role {
+			return nil, errors.New("admin privilege cannot be granted to a role")
+		}
+		return &influxql.GrantAdminStatement{User: user}, nil
+	} else if tok != influxql.ON {
+		if priv == influxql.AllPrivileges {
+			return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"ON", "TO"}, Pos: pos}
+		}
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"ON"}, Pos: pos}
+	}
+
+	t, err := parsePrivilegeTarget(p, influxql.TO)
+	if err != nil {
+		return nil, err
+	} else if t.role {
+		return &GrantRolePrivilegeStatement{Privilege: priv, On: t.on, Role: t.grantee}, nil
+	} else if t.measurement == "" && t.regex == nil {
+		return &influxql.GrantStatement{Privilege: priv, On: t.on, User: t.grantee}, nil
+	}
+	return &GrantMeasurementStatement{Privilege: priv, On: t.on, Measurement: t.measurement, Regex: t.regex, User: t.grantee}, nil
+}
+
+// parseRevokeStatement parses a REVOKE statement. It accepts the same
+// grammar as InfluxQL, a MEASUREMENT clause and roles like GRANT. This
+// function assumes the REVOKE token has already been consumed.
+func parseRevokeStatement(p *influxql.Parser) (influxql.Statement, error) {
+	if role, user, ok, err := parseRoleMembership(p, influxql.FROM); err != nil {
+		return nil, err
+	} else if ok {
+		return &RevokeRoleStatement{Role: role, User: user}, nil
+	}
+
+	priv, err := parsePrivilege(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for ON or FROM clauses.
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok == influxql.FROM {
+		// Admin privilege is only revoked on ALL PRIVILEGES.
+		if priv != influxql.AllPrivileges {
+			return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"ON"}, Pos: pos}
+		}
+		user, role, err := parseGrantee(p)
+		if err != nil {
+			return nil, err
+		} else if role {
+			return nil, errors.New("admin privilege cannot be revoked from a role")
+		}
+		return &influxql.RevokeAdminStatement{User: user}, nil
+	} else if tok != influxql.ON {
+		if priv == influxql.AllPrivileges {
+			return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"ON", "FROM"}, Pos: pos}
+		}
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"ON"}, Pos: pos}
+	}
+
+	t, err := parsePrivilegeTarget(p, influxql.FROM)
+	if err != nil {
+		return nil, err
+	} else if t.role {
+		return &RevokeRolePrivilegeStatement{Privilege: priv, On: t.on, Role: t.grantee}, nil
+	} else if t.measurement == "" && t.regex == nil {
+		return &influxql.RevokeStatement{Privilege: priv, On: t.on, User: t.grantee}, nil
+	}
+	return &RevokeMeasurementStatement{Privilege: priv, On: t.on, Measurement: t.measurement, Regex: t.regex, User: t.grantee}, nil
+}
+
+// parseShowGrantsStatement parses a SHOW GRANTS FOR statement for a user or
+// a role. This function assumes the SHOW GRANTS FOR tokens have already been
+// consumed.
+func parseShowGrantsStatement(p *influxql.Parser) (influxql.Statement, error) {
+	name, role, err := parseGrantee(p)
+	if err != nil {
+		return nil, err
+	} else if role {
+		return &ShowGrantsForRoleStatement{Name: name}, nil
+	}
+	return &influxql.ShowGrantsForUserStatement{Name: name}, nil
+}
+
+// parseIdentStatement returns the parser of the statements that start with
+// the given token followed by one of the words. The parser assumes the token
+// and the identifier that follows it have already been consumed.
+func parseIdentStatement(tok influxql.Token, words []string) func(*influxql.Parser) (influxql.Statement, error) {
+	return func(p *influxql.Parser) (influxql.Statement, error) {
+		p.Unscan()
+		t, pos, lit := p.ScanIgnoreWhitespace()
+
+		var word string
+		for _, w := range words {
+			if t == influxql.IDENT && strings.EqualFold(lit, w) {
+				word = w
+			}
+		}
+
+		switch word {
+		case "ROLE", "ROLES":
+			return parseRoleStatement(p, tok)
+		case "TOKEN", "TOKENS":
+			return parseTokenStatement(p, tok)
+		case "QUOTA", "QUOTAS":
+			return parseQuotaStatement(p, tok)
+		default:
+			return nil, &influxql.ParseError{Found: tokstr(t, lit), Expected: words, Pos: pos}
+		}
+	}
+}
+
+// parseRoleStatement parses a CREATE ROLE, DROP ROLE or SHOW ROLES statement
+// that starts with the given token. This function assumes the token and the
+// ROLE or ROLES identifier have already been consumed.
+func parseRoleStatement(p *influxql.Parser, tok influxql.Token) (influxql.Statement, error) {
+	switch tok {
+	case influxql.CREATE, influxql.DROP:
+		name, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		} else if tok == influxql.CREATE {
+			return &CreateRoleStatement{Name: name}, nil
+		}
+		return &DropRoleStatement{Name: name}, nil
+	default:
+		return &ShowRolesStatement{}, nil
+	}
+}
+
+// parseTokenStatement parses a CREATE TOKEN, DROP TOKEN or SHOW TOKENS
+// statement that starts with the given token. This function assumes the
+// token and the TOKEN or TOKENS identifier have already been consumed.
+func parseTokenStatement(p *influxql.Parser, tok influxql.Token) (influxql.Statement, error) {
+	switch tok {
+	case influxql.CREATE:
+		if t, pos, lit := p.ScanIgnoreWhitespace(); t != influxql.FOR {
+			return nil, &influxql.ParseError{Found: tokstr(t, lit), Expected: []string{"FOR"}, Pos: pos}
+		}
+		user, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		stmt := &CreateTokenStatement{User: user}
+
+		// Parse the optional lifetime of the token.
+		if t, _, _ := p.ScanIgnoreWhitespace(); t == influxql.WITH {
+			if t, pos, lit := p.ScanIgnoreWhitespace(); t != influxql.DURATION {
+				return nil, &influxql.ParseError{Found: tokstr(t, lit), Expected: []string{"DURATION"}, Pos: pos}
+			}
+			if stmt.Duration, err = p.ParseDuration(); err != nil {
+				return nil, err
+			}
+		} else {
+			p.Unscan()
+		}
+
+		// Parse the optional write target of the token.
+		if t, _, _ := p.ScanIgnoreWhitespace(); t != influxql.WRITE {
+			p.Unscan()
+			return stmt, nil
+		}
+		if t, pos, lit := p.ScanIgnoreWhitespace(); t != influxql.IDENT || !strings.EqualFold(lit, "TARGET") {
+			return nil, &influxql.ParseError{Found: tokstr(t, lit), Expected: []string{"TARGET"}, Pos: pos}
+		}
+		if stmt.Database, stmt.RetentionPolicy, err = parseDatabaseRetentionPolicy(p); err != nil {
+			return nil, err
+		}
+		return stmt, nil
+	case influxql.DROP:
+		// Token IDs are hexadecimal and may not be valid identifiers, so
+		// they are usually quoted as strings.
+		t, pos, lit := p.ScanIgnoreWhitespace()
+		if t != influxql.STRING && t != influxql.IDENT {
+			return nil, &influxql.ParseError{Found: tokstr(t, lit), Expected: []string{"string"}, Pos: pos}
+		}
+		return &DropTokenStatement{ID: lit}, nil
+	default:
+		stmt := &ShowTokensStatement{}
+		if t, _, _ := p.ScanIgnoreWhitespace(); t != influxql.FOR {
+			p.Unscan()
+			return stmt, nil
+		}
+		user, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		stmt.User = user
+		return stmt, nil
+	}
+}
+
+// parseSetQuotaStatement parses a SET QUOTA statement. This function assumes
+// the SET token and the QUOTA identifier have already been consumed.
+func parseSetQuotaStatement(p *influxql.Parser) (influxql.Statement, error) {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.ON {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"ON"}, Pos: pos}
+	}
+	db, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &SetQuotaStatement{Database: db}
+
+	// Parse the limits, at least one of which is required.
+	for {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if tok != influxql.IDENT || !strings.EqualFold(lit, "MAX") {
+			if stmt.MaxSeries == nil && stmt.MaxMeasurements == nil && stmt.MaxWriteRate == nil && stmt.MaxConcurrentWrites == nil {
+				return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"MAX"}, Pos: pos}
+			}
+			p.Unscan()
+			return stmt, nil
+		}
+
+		var limit **int64
+		switch tok, pos, lit := p.ScanIgnoreWhitespace(); tok {
+		case influxql.SERIES:
+			limit = &stmt.MaxSeries
+		case influxql.MEASUREMENTS:
+			limit = &stmt.MaxMeasurements
+		case influxql.WRITE:
+			if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.IDENT || !strings.EqualFold(lit, "RATE") {
+				return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"RATE"}, Pos: pos}
+			}
+			limit = &stmt.MaxWriteRate
+		case influxql.IDENT:
+			if !strings.EqualFold(lit, "CONCURRENT") {
+				return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"SERIES", "MEASUREMENTS", "WRITE", "CONCURRENT"}, Pos: pos}
+			}
+			if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.IDENT || !strings.EqualFold(lit, "WRITES") {
+				return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"WRITES"}, Pos: pos}
+			}
+			limit = &stmt.MaxConcurrentWrites
+		default:
+			return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"SERIES", "MEASUREMENTS", "WRITE", "CONCURRENT"}, Pos: pos}
+		}
+		if *limit != nil {
+			return nil, errors.New("found duplicate limit in SET QUOTA")
+		}
+
+		n, err := p.ParseUInt64()
+		if err != nil {
+			return nil, err
+		} else if n > math.MaxInt64 {
+			return nil, fmt.Errorf("quota limit %d is out of range", n)
+		}
+		v := int64(n)
+		*limit = &v
+	}
+}
+
+// parseQuotaStatement parses a DROP QUOTA or SHOW QUOTAS statement that
+// starts with the given token. This function assumes the token and the QUOTA
+// or QUOTAS identifier have already been consumed.
+func parseQuotaStatement(p *influxql.Parser, tok influxql.Token) (influxql.Statement, error) {
+	switch tok {
+	case influxql.DROP:
+		if t, pos, lit := p.ScanIgnoreWhitespace(); t != influxql.ON {
+			return nil, &influxql.ParseError{Found: tokstr(t, lit), Expected: []string{"ON"}, Pos: pos}
+		}
+		db, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		return &DropQuotaStatement{Database: db}, nil
+	default:
+		return &ShowQuotasStatement{}, nil
+	}
+}
+
+// parseShowShardGroupsStatement parses a SHOW SHARD GROUPS statement. This
+// function assumes the SHOW, SHARD and GROUPS tokens have already been
+// consumed.
+func parseShowShardGroupsStatement(p *influxql.Parser) (influxql.Statement, error) {
+	db, rp, err := parseShardGroupsTarget(p, false)
+	if err != nil {
+		return nil, err
+	}
+	return &ShowShardGroupsStatement{Database: db, RetentionPolicy: rp}, nil
+}
+
+// parseCreateShardGroupStatement parses a CREATE SHARD GROUP statement. This
+// function assumes the CREATE and SHARD tokens have already been consumed.
+func parseCreateShardGroupStatement(p *influxql.Parser) (influxql.Statement, error) {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.GROUP {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"GROUP"}, Pos: pos}
+	}
+	db, rp, err := parseShardGroupsTarget(p, true)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseShardGroupsTime(p)
+	if err != nil {
+		return nil, err
+	}
+	return &CreateShardGroupStatement{Database: db, RetentionPolicy: rp, Time: t}, nil
+}
+
+// parseRootIdentStatement parses the statements that start with an
+// identifier. The parser assumes the identifier has already been consumed.
+func parseRootIdentStatement(p *influxql.Parser) (influxql.Statement, error) {
+	p.Unscan()
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+
+	switch {
+	case tok == influxql.IDENT && strings.EqualFold(lit, "TRUNCATE"):
+		return parseTruncateShardGroupsStatement(p)
+	case tok == influxql.IDENT && strings.EqualFold(lit, "RENAME"):
+		return parseRenameStatement(p)
+	default:
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: language.Keys, Pos: pos}
+	}
+}
+
+// parseTruncateShardGroupsStatement parses a TRUNCATE SHARD GROUPS statement.
+// This function assumes the TRUNCATE identifier has already been consumed.
+func parseTruncateShardGroupsStatement(p *influxql.Parser) (influxql.Statement, error) {
+	for _, want := range []influxql.Token{influxql.SHARD, influxql.GROUPS} {
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != want {
+			return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{want.String()}, Pos: pos}
+		}
+	}
+
+	db, rp, err := parseShardGroupsTarget(p, false)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseShardGroupsTime(p)
+	if err != nil {
+		return nil, err
+	}
+	return &TruncateShardGroupsStatement{Database: db, RetentionPolicy: rp, Time: t}, nil
+}
+
+// parseShardGroupsTarget parses the ON db[.rp] clause of the shard group
+// statements. It returns an empty database without consuming any token if
+// the clause is not required and missing.
+func parseShardGroupsTarget(p *influxql.Parser, required bool) (db, rp string, err error) {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != influxql.ON {
+		if required {
+			return "", "", &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"ON"}, Pos: pos}
+		}
+		p.Unscan()
+		return "", "", nil
+	}
+	return parseDatabaseRetentionPolicy(p)
+}
+
+// parseShardGroupsTime parses the optional AT 'time' clause of the shard
+// group statements. The time is zero if the clause is missing.
+func parseShardGroupsTime(p *influxql.Parser) (time.Time, error) {
+	if tok, _, lit := p.ScanIgnoreWhitespace(); tok != influxql.IDENT || !strings.EqualFold(lit, "AT") {
+		p.Unscan()
+		return time.Time{}, nil
+	}
+
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != influxql.STRING {
+		return time.Time{}, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"string"}, Pos: pos}
+	}
+	t, err := (&influxql.StringLiteral{Val: lit}).ToTimeLiteral(time.UTC)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Val, nil
+}
+
+// parseRenameStatement parses a RENAME DATABASE or RENAME MEASUREMENT
+// statement. This function assumes the RENAME identifier has already been
+// consumed.
+func parseRenameStatement(p *influxql.Parser) (influxql.Statement, error) {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != influxql.DATABASE && tok != influxql.MEASUREMENT {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"DATABASE", "MEASUREMENT"}, Pos: pos}
+	}
+
+	oldName, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if t, pos, lit := p.ScanIgnoreWhitespace(); t != influxql.TO {
+		return nil, &influxql.ParseError{Found: tokstr(t, lit), Expected: []string{"TO"}, Pos: pos}
+	}
+	newName, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok == influxql.DATABASE {
+		return &RenameDatabaseStatement{OldName: oldName, NewName: newName}, nil
+	}
+
+	stmt := &RenameMeasurementStatement{OldName: oldName, NewName: newName}
+	if t, _, _ := p.ScanIgnoreWhitespace(); t != influxql.ON {
+		p.Unscan()
+		return stmt, nil
+	}
+	if stmt.Database, err = p.ParseIdent(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseRoleMembership parses the ROLE role TO|FROM user clause of a GRANT or
+// REVOKE statement. It returns false without consuming any token if the
+// statement does not start with ROLE.
+func parseRoleMembership(p *influxql.Parser, keyword influxql.Token) (role, user string, ok bool, err error) {
+	if tok, _, lit := p.ScanIgnoreWhitespace(); tok != influxql.IDENT || !strings.EqualFold(lit, "ROLE") {
+		p.Unscan()
+		return "", "", false, nil
+	}
+
+	if role, err = p.ParseIdent(); err != nil {
+		return "", "", false, err
+	}
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != keyword {
+		return "", "", false, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{keyword.String()}, Pos: pos}
+	}
+	if user, err = p.ParseIdent(); err != nil {
+		return "", "", false, err
+	}
+	return role, user, true, nil
+}
+
+// parseGrantee parses the user or the ROLE role that privileges are granted
+// to or revoked from. A user named role can still be used unless it is
+// followed by an identifier.
+func parseGrantee(p *influxql.Parser) (name string, role bool, err error) {
+	if name, err = p.ParseIdent(); err != nil {
+		return "", false, err
+	} else if !strings.EqualFold(name, "ROLE") {
+		return name, false, nil
+	}
+
+	tok, _, lit := p.ScanIgnoreWhitespace()
+	if tok != influxql.IDENT {
+		p.Unscan()
+		return name, false, nil
+	}
+	return lit, true, nil
+}
+
+// parsePrivilege parses READ, WRITE or ALL [PRIVILEGES].
+func parsePrivilege(p *influxql.Parser) (influxql.Privilege, error) {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case influxql.READ:
+		return influxql.ReadPrivilege, nil
+	case influxql.WRITE:
+		return influxql.WritePrivilege, nil
+	case influxql.ALL:
+		// Consume optional PRIVILEGES token.
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != influxql.PRIVILEGES {
+			p.Unscan()
+		}
+		return influxql.AllPrivileges, nil
+	default:
+		return 0, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"READ", "WRITE", "ALL [PRIVILEGES]"}, Pos: pos}
+	}
+}
+
+// privilegeTarget is what the privilege of a GRANT or REVOKE statement
+// applies to.
+type privilegeTarget struct {
+	// Database and, if the privilege is restricted to measurements, the name
+	// of the measurement or the regular expression matching them.
+	on          string
+	measurement string
+	regex       *influxql.RegexLiteral
+
+	// User or role that is granted or revoked the privilege.
+	grantee string
+	role    bool
+}
+
+// parsePrivilegeTarget parses the database, the optional MEASUREMENT clause
+// and the grantee of a GRANT or REVOKE statement. The grantee follows the
+// given keyword. This function assumes the ON token has already been
+// consumed.
+func parsePrivilegeTarget(p *influxql.Parser, keyword influxql.Token) (*privilegeTarget, error) {
+	t := &privilegeTarget{}
+	var err error
+	if t.on, err = p.ParseIdent(); err != nil {
+		return nil, err
+	}
+
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok == influxql.MEASUREMENT {
+		// The measurement is parsed as an expression since a regex has been
+		// lowered into a regex() call by lowerMeasurementRegex.
+		expr, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if ref, ok := expr.(*influxql.VarRef); ok && ref.Type == influxql.Unknown {
+			t.measurement = ref.Val
+		} else if call, ok := expr.(*influxql.Call); ok && call.Name == "regex" && len(call.Args) == 1 {
+			if t.regex, ok = call.Args[0].(*influxql.RegexLiteral); !ok {
+				return nil, fmt.Errorf("invalid measurement %s, expected identifier or regex", call.Args[0])
+			}
+		} else {
+			return nil, fmt.Errorf("invalid measurement %s, expected identifier or regex", expr)
+		}
+		tok, pos, lit = p.ScanIgnoreWhitespace()
+	}
+
+	if tok != keyword {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{keyword.String()}, Pos: pos}
+	}
+	if t.grantee, t.role, err = parseGrantee(p); err != nil {
+		return nil, err
+	} else if t.role && (t.measurement != "" || t.regex != nil) {
+		return nil, errors.New("privileges on measurements are not supported for roles")
+	}
+	return t, nil
+}
+
+func tokstr(tok influxql.Token, lit string) string {
+	if lit != "" {
+		return lit
+	}
+	return tok.String()
+}
+
+// lowerQuery rewrites the extended clauses within the query string into
+// plain InfluxQL.
+func lowerQuery(s string) (string, error) {
+	for _, lower := range []func(string, []lexToken) (string, bool, error){
+		lowerScalarSubquery,
+		lowerIn,
+		lowerHaving,
+		lowerAsofJoin,
+		lowerFill,
+		lowerCalendarInterval,
+		lowerAfter,
+		lowerMeasurementRegex,
+	} {
+		for {
+			out, ok, err := lower(s, lex(s))
+			if err != nil {
+				return "", err
+			} else if !ok {
+				break
+			}
+			s = out
+		}
+	}
+	return s, nil
+}
+
+// lowerScalarSubquery rewrites the first subquery within a condition into a
+// scalar() call with the subquery as a string. The subquery is executed and
+// replaced with the value it returns before the statement is executed.
+//
+// For example:
+//
+//	SELECT value FROM cpu WHERE value > (SELECT mean(value) FROM cpu)
+//
+// becomes:
+//
+//	SELECT value FROM cpu WHERE value > scalar('SELECT mean(value) FROM cpu')
+func lowerScalarSubquery(s string, toks []lexToken) (string, bool, error) {
+	for i := 0; i+1 < len(toks); i++ {
+		if !toks[i].isPunct("(") || !toks[i+1].isWord("SELECT") || !inCondition(toks, i) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(toks) && !(toks[end].depth == toks[i].depth && toks[end].isPunct(")")) {
+			end++
+		}
+		if end == len(toks) {
+			return "", false, errors.New("found EOF, expected ) after subquery")
+		}
+		text := "scalar(" + influxql.QuoteString(s[toks[i+1].pos:toks[end-1].end]) + ")"
+		return applyEdits(s, []edit{{pos: toks[i].pos, end: toks[end].end, text: text}}), true, nil
+	}
+	return s, false, nil
+}
+
+// lowerIn rewrites the first IN or NOT IN list within a condition into a
+// disjunction of equalities or a conjunction of inequalities.
+//
+// For example:
+//
+//	SELECT value FROM cpu WHERE host IN ('a', 'b') AND value::integer NOT IN (1, 2)
+//
+// becomes:
+//
+//	SELECT value FROM cpu WHERE (host = 'a' OR host = 'b') AND (value::integer != 1 AND value::integer != 2)
+func lowerIn(s string, toks []lexToken) (string, bool, error) {
+	for i := 1; i+1 < len(toks); i++ {
+		if !toks[i].isWord("IN") || !toks[i+1].isPunct("(") || !inCondition(toks, i) {
+			continue
+		}
+
+		// Find the operand that is compared to the list.
+		op, join, lhs := "=", " OR ", i-1
+		if toks[lhs].isWord("NOT") {
+			op, join, lhs = "!=", " AND ", lhs-1
+		}
+		end := lhs + 1
+		if lhs >= 3 && toks[lhs].kind == lexWord && toks[lhs-1].isPunct(":") && toks[lhs-2].isPunct(":") {
+			lhs -= 3
+		}
+		if lhs >= 0 && toks[lhs].isPunct(")") {
+			// The operand is a call so include its arguments and name.
+			for j := lhs - 1; j >= 0; j-- {
+				if toks[j].depth == toks[lhs].depth && toks[j].isPunct("(") {
+					lhs = j - 1
+					break
+				}
+			}
+		}
+		if lhs < 0 || (toks[lhs].kind != lexWord && toks[lhs].kind != lexIdent) {
+			return "", false, fmt.Errorf("found %s, expected identifier before IN", toks[i-1].lit)
+		}
+		operand := s[toks[lhs].pos:toks[end-1].end]
+
+		// Split the list into its values.
+		var values []string
+		start, depth := i+2, toks[i+1].depth+1
+		for j := start; j < len(toks); j++ {
+			if toks[j].depth > depth || (toks[j].depth == depth && !toks[j].isPunct(",")) {
+				continue
+			} else if j == start {
+				return "", false, errors.New("found end of list, expected value in IN list")
+			}
+
+			values = append(values, operand+" "+op+" "+s[toks[start].pos:toks[j-1].end])
+			if toks[j].isPunct(")") {
+				text := "(" + strings.Join(values, join) + ")"
+				return applyEdits(s, []edit{{pos: toks[lhs].pos, end: toks[j].end, text: text}}), true, nil
+			}
+			start = j + 1
+		}
+	}
+	return s, false, nil
+}
+
+// inCondition returns true if the token at index i is part of the condition
+// of a WHERE or HAVING clause.
+func inCondition(toks []lexToken, i int) bool {
+	depth := toks[i].depth
+	for j := i - 1; j >= 0; j-- {
+		if toks[j].depth > depth {
+			continue
+		}
+		depth = toks[j].depth
+
+		if toks[j].isPunct(";") {
+			return false
+		} else if toks[j].kind != lexWord {
+			continue
+		}
+		switch strings.ToUpper(toks[j].lit) {
+		case "WHERE":
+			return true
+		case "HAVING":
+			if endsOperand(toks, j-1) {
+				return true
+			}
+		case "SELECT", "FROM", "INTO", "WITH", "GROUP", "ORDER", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET":
+			return false
+		}
+	}
+	return false
+}
+
+// lowerHaving rewrites the first HAVING clause in the query into a having()
+// call that is AND'ed to the WHERE clause of the same statement.
+//
+// For example:
+//
+//	SELECT mean(value) FROM cpu WHERE time > now() - 1h GROUP BY host HAVING mean(value) > 80
+//
+// becomes:
+//
+//	SELECT mean(value) FROM cpu WHERE (time > now() - 1h) AND having(mean(value) > 80) GROUP BY host
+func lowerHaving(s string, toks []lexToken) (string, bool, error) {
+	h, from, where := -1, -1, -1
+	for i := range toks {
+		if !toks[i].isWord("HAVING") || !endsOperand(toks, i-1) {
+			continue
+		}
+
+		// If there is no statement this clause belongs to, this is an
+		// identifier and not a HAVING clause.
+		if from, where = findStatement(toks, i); from >= 0 {
+			h = i
+			break
+		}
+	}
+	if h < 0 {
+		return s, false, nil
+	} else if toks[h].depth > 0 {
+		return "", false, errors.New("HAVING is not supported in subqueries")
+	}
+
+	end := clauseEnd(toks, h)
+	if end == h+1 {
+		return "", false, errors.New("found end of clause, expected expression after HAVING")
+	}
+	cond := "having(" + s[toks[h+1].pos:toks[end-1].end] + ")"
+
+	// Insert the condition into the WHERE clause, creating one if needed,
+	// and remove the HAVING clause.
+	edits := addCondition(toks, from, where, cond)
+	edits = append(edits, edit{pos: toks[h].pos, end: toks[end-1].end})
+	return applyEdits(s, edits), true, nil
+}
+
+// lowerAsofJoin rewrites the first ASOF JOIN clause in the query into an
+// asof_join() call that is AND'ed to the WHERE clause of the same statement.
+// The call holds the statement that selects the joined measurement grouped
+// by the tags of the ON clause.
+//
+// For example:
+//
+//	SELECT value, state FROM events ASOF JOIN status ON host WHERE time > now() - 1h
+//
+// becomes:
+//
+//	SELECT value, state FROM events WHERE (time > now() - 1h) AND asof_join('SELECT * FROM status GROUP BY host')
+func lowerAsofJoin(s string, toks []lexToken) (string, bool, error) {
+	j, from, where := -1, -1, -1
+	for i := 0; i+1 < len(toks); i++ {
+		if !toks[i].isWord("ASOF") || !toks[i+1].isWord("JOIN") || !endsOperand(toks, i-1) {
+			continue
+		}
+		if from, where = findStatement(toks, i); from >= 0 && where < 0 {
+			j = i
+			break
+		}
+	}
+	if j < 0 {
+		return s, false, nil
+	} else if toks[j].depth > 0 {
+		return "", false, errors.New("ASOF JOIN is not supported in subqueries")
+	}
+
+	// Find the joined measurement and the tags of the ON clause.
+	on, end := -1, j+2
+loop:
+	for ; end < len(toks); end++ {
+		tok := toks[end]
+		if tok.depth > toks[j].depth {
+			continue
+		} else if tok.kind == lexEOF || tok.depth < toks[j].depth || tok.isPunct(";") {
+			break
+		} else if tok.kind != lexWord {
+			continue
+		}
+
+		switch strings.ToUpper(tok.lit) {
+		case "ON":
+			if on < 0 {
+				on = end
+			}
+		case "WHERE", "GROUP", "ORDER", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET", "FILL", "TZ":
+			break loop
+		default:
+		}
+	}
+
+	srcEnd := end
+	if on >= 0 {
+		srcEnd = on
+	}
+	if srcEnd == j+2 {
+		return "", false, errors.New("found end of clause, expected measurement after ASOF JOIN")
+	}
+	join := "SELECT * FROM " + s[toks[j+2].pos:toks[srcEnd-1].end]
+	if on >= 0 {
+		if end == on+1 {
+			return "", false, errors.New("found end of clause, expected tag keys after ON")
+		}
+		join += " GROUP BY " + s[toks[on+1].pos:toks[end-1].end]
+	}
+	cond := "asof_join(" + influxql.QuoteString(join) + ")"
+
+	// Remove the ASOF JOIN clause together with the whitespace before it
+	// and insert the condition into the WHERE clause, creating one if
+	// needed. The statement cannot have a WHERE clause before the join.
+	clause := edit{pos: toks[j-1].end, end: toks[end-1].end}
+	if toks[end].isWord("WHERE") {
+		return applyEdits(s, append([]edit{clause}, addCondition(toks, -1, end, cond)...)), true, nil
+	}
+	clause.text = " WHERE " + cond
+	return applyEdits(s, []edit{clause}), true, nil
+}
+
+// lowerFill rewrites the first fill(previous, <duration>) clause in the
+// query into fill(previous) and a fill() call with the original arguments
+// that is AND'ed to the WHERE clause of the same statement.
+//
+// For example:
+//
+//	SELECT mean(value) FROM cpu WHERE time > now() - 1h GROUP BY time(1m) fill(previous, 5m)
+//
+// becomes:
+//
+//	SELECT mean(value) FROM cpu WHERE (time > now() - 1h) AND fill(previous, 5m) GROUP BY time(1m) fill(previous)
+func lowerFill(s string, toks []lexToken) (string, bool, error) {
+	f, comma, rparen, from, where := -1, -1, -1, -1, -1
+	for i := range toks {
+		if !toks[i].isWord("FILL") || !toks[i+1].isPunct("(") || !endsOperand(toks, i-1) {
+			continue
+		}
+
+		// Only the fill options with more than one argument are lowered.
+		comma, rparen = -1, -1
+		for j := i + 2; j < len(toks) && rparen < 0; j++ {
+			switch {
+			case toks[j].depth <= toks[i].depth:
+				rparen = j
+			case toks[j].depth == toks[i].depth+1 && toks[j].isPunct(",") && comma < 0:
+				comma = j
+			}
+		}
+		if comma < 0 || !toks[rparen].isPunct(")") {
+			continue
+		}
+
+		if from, where = findStatement(toks, i); from >= 0 {
+			f = i
+			break
+		}
+	}
+	if f < 0 {
+		return s, false, nil
+	} else if toks[f].depth > 0 {
+		return "", false, errors.New("fill(previous, <duration>) is not supported in subqueries")
+	} else if !toks[f+2].isWord("previous") || f+3 != comma {
+		return "", false, errors.New("a maximum staleness duration can only be used with fill(previous)")
+	}
+
+	// Move the arguments into the WHERE clause and leave only the fill
+	// option in the fill clause.
+	cond := s[toks[f].pos:toks[rparen].end]
+	edits := addCondition(toks, from, where, cond)
+	edits = append(edits, edit{pos: toks[comma].pos, end: toks[rparen].pos})
+	return applyEdits(s, edits), true, nil
+}
+
+// lowerCalendarInterval rewrites the first calendar interval in a time()
+// call into a string literal as InfluxQL durations do not have calendar units.
+//
+// For example:
+//
+//	SELECT sum(value) FROM cpu GROUP BY time(1mo)
+//
+// becomes:
+//
+//	SELECT sum(value) FROM cpu GROUP BY time('1mo')
+func lowerCalendarInterval(s string, toks []lexToken) (string, bool, error) {
+	for i := 0; i+2 < len(toks); i++ {
+		if !toks[i].isWord("time") || !toks[i+1].isPunct("(") || toks[i+2].kind != lexNumber {
+			continue
+		} else if _, ok := parseCalendarInterval(toks[i+2].lit); !ok {
+			continue
+		}
+		lit := toks[i+2]
+		return applyEdits(s, []edit{{pos: lit.pos, end: lit.end, text: "'" + lit.lit + "'"}}), true, nil
+	}
+	return s, false, nil
+}
+
+// lowerAfter rewrites the AFTER clause of a SHOW SERIES or SHOW TAG KEYS
+// statement into an after() call that is AND'ed to the WHERE clause of the
+// statement.
+//
+// For example:
+//
+//	SHOW SERIES FROM cpu LIMIT 100 AFTER 'Y3B1LGhvc3Q9YQ'
+//
+// becomes:
+//
+//	SHOW SERIES FROM cpu WHERE after('Y3B1LGhvc3Q9YQ') LIMIT 100
+func lowerAfter(s string, toks []lexToken) (string, bool, error) {
+	for i := 0; i+1 < len(toks); i++ {
+		if !toks[i].isWord("AFTER") || toks[i].depth > 0 || toks[i+1].kind != lexString {
+			continue
+		}
+
+		where, insert := findShowStatement(toks, i)
+		if where < 0 && insert < 0 {
+			continue
+		}
+
+		// Remove the AFTER clause together with the whitespace before it.
+		clause := edit{pos: toks[i-1].end, end: toks[i+1].end}
+		cond := "after(" + toks[i+1].lit + ")"
+		if where >= 0 {
+			edits := addCondition(toks, -1, where, cond)
+			return applyEdits(s, append(edits, clause)), true, nil
+		} else if insert == i {
+			clause.text = " WHERE " + cond
+			return applyEdits(s, []edit{clause}), true, nil
+		}
+		edits := []edit{{pos: toks[insert-1].end, end: toks[insert-1].end, text: " WHERE " + cond}, clause}
+		return applyEdits(s, edits), true, nil
+	}
+	return s, false, nil
+}
+
+// lowerMeasurementRegex wraps the regex of the MEASUREMENT clause of a GRANT
+// or REVOKE statement in a regex() call. influxql.Parser only scans a regex
+// where its own grammar expects one, but it parses the expression of a call
+// with a regex argument.
+//
+// For example:
+//
+//	GRANT READ ON db0 MEASUREMENT /^cpu/ TO bob
+//
+// becomes:
+//
+//	GRANT READ ON db0 MEASUREMENT regex(/^cpu/) TO bob
+func lowerMeasurementRegex(s string, toks []lexToken) (string, bool, error) {
+	for i := 0; i+1 < len(toks); i++ {
+		if !toks[i].isWord("MEASUREMENT") || toks[i+1].kind != lexRegex {
+			continue
+		}
+
+		// Find the first token of the statement.
+		start := 0
+		for j := i - 1; j >= 0; j-- {
+			if toks[j].depth == 0 && toks[j].isPunct(";") {
+				start = j + 1
+				break
+			}
+		}
+		if !toks[start].isWord("GRANT") && !toks[start].isWord("REVOKE") {
+			continue
+		}
+
+		re := toks[i+1]
+		return applyEdits(s, []edit{{pos: re.pos, end: re.end, text: "regex(" + re.lit + ")"}}), true, nil
+	}
+	return s, false, nil
+}
+
+// findShowStatement finds the WHERE clause of the SHOW SERIES or SHOW TAG
+// KEYS statement that contains the clause starting at index i. If the
+// statement has no WHERE clause, it returns -1 for it along with the index
+// of the token a WHERE clause would be inserted before. It returns -1 for
+// both if the clause is not part of such a statement.
+func findShowStatement(toks []lexToken, i int) (where, insert int) {
+	start := 0
+	for j := i - 1; j >= 0; j-- {
+		if toks[j].depth == 0 && toks[j].isPunct(";") {
+			start = j + 1
+			break
+		}
+	}
+
+	head := start + 2
+	switch {
+	case !toks[start].isWord("SHOW"):
+		return -1, -1
+	case toks[start+1].isWord("SERIES"):
+		if toks[start+2].isWord("CARDINALITY") || toks[start+2].isWord("EXACT") {
+			return -1, -1
+		}
+	case toks[start+1].isWord("TAG") && toks[start+2].isWord("KEYS"):
+		head++
+	default:
+		return -1, -1
+	}
+
+	insert = -1
+	for j := head; j <= i; j++ {
+		if toks[j].depth > 0 || toks[j].kind != lexWord {
+			continue
+		}
+		switch strings.ToUpper(toks[j].lit) {
+		case "WHERE":
+			return j, -1
+		case "ORDER", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET", "AFTER":
+			if insert < 0 {
+				insert = j
+			}
+		}
+	}
+	return -1, insert
+}
+
+// findStatement finds the FROM and WHERE clauses of the SELECT statement
+// that contains the clause starting at index i. It returns -1 for the FROM
+// clause if the clause is not part of a SELECT statement and -1 for the WHERE
+// clause if the statement does not have one.
+func findStatement(toks []lexToken, i int) (from, where int) {
+	from, where = -1, -1
+	for j := i - 1; j >= 0; j-- {
+		if toks[j].depth != toks[i].depth {
+			if toks[j].depth < toks[i].depth {
+				break
+			}
+			continue
+		} else if toks[j].isPunct(";") {
+			break
+		}
+		switch {
+		case toks[j].isWord("SELECT"):
+			return from, where
+		case toks[j].isWord("FROM"):
+			from = j
+		case toks[j].isWord("WHERE"):
+			where = j
+		}
+	}
+	return -1, -1
+}
+
+// edit replaces the bytes between pos and end with text.
+type edit struct {
+	pos, end int
+	text     string
+}
+
+// addCondition returns the edits that AND the condition to the WHERE clause
+// at index where. If the statement has no WHERE clause, one is added after
+// the FROM clause at index from.
+func addCondition(toks []lexToken, from, where int, cond string) []edit {
+	if where < 0 {
+		fe := clauseEnd(toks, from)
+		return []edit{{pos: toks[fe-1].end, end: toks[fe-1].end, text: " WHERE " + cond}}
+	}
+	we := clauseEnd(toks, where)
+	return []edit{
+		{pos: toks[where+1].pos, end: toks[where+1].pos, text: "("},
+		{pos: toks[we-1].end, end: toks[we-1].end, text: ") AND " + cond},
+	}
+}
+
+// applyEdits applies the edits to s. The edits must be sorted by position
+// and must not overlap.
+func applyEdits(s string, edits []edit) string {
+	var buf strings.Builder
+	var pos int
+	for _, e := range edits {
+		buf.WriteString(s[pos:e.pos])
+		buf.WriteString(e.text)
+		pos = e.end
+	}
+	buf.WriteString(s[pos:])
+	return buf.String()
+}
+
+// endsOperand returns true if the token at index i can be the last token of
+// a clause. A keyword that follows such a token starts a new clause while an
+// identifier that follows an operator or a keyword is part of the expression.
+func endsOperand(toks []lexToken, i int) bool {
+	if i < 0 {
+		return false
+	}
+	switch tok := toks[i]; tok.kind {
+	case lexIdent, lexString, lexRegex, lexNumber:
+		return true
+	case lexWord:
+		return influxql.Lookup(tok.lit) == influxql.IDENT
+	case lexPunct:
+		return tok.lit == ")" || tok.lit == "*"
+	default:
+		return false
+	}
+}
+
+// clauseEnd returns the index of the token that terminates the clause
+// started by the keyword at index i.
+func clauseEnd(toks []lexToken, i int) int {
+	depth := toks[i].depth
+	for j := i + 1; j < len(toks); j++ {
+		tok := toks[j]
+		if tok.depth < depth {
+			// A closing parenthesis for an enclosing expression.
+			return j
+		} else if tok.depth > depth {
+			continue
+		}
+
+		switch tok.kind {
+		case lexEOF:
+			return j
+		case lexPunct:
+			if tok.lit == ";" {
+				return j
+			}
+		case lexWord:
+			switch strings.ToUpper(tok.lit) {
+			case "GROUP", "ORDER", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET", "END":
+				return j
+			case "HAVING":
+				if endsOperand(toks, j-1) {
+					return j
+				}
+			case "AFTER":
+				if toks[j+1].kind == lexString && endsOperand(toks, j-1) {
+					return j
+				}
+			case "FILL", "TZ":
+				if toks[j+1].isPunct("(") && endsOperand(toks, j-1) {
+					return j
+				}
+			}
+		}
+	}
+	return len(toks) - 1
+}
+
+type lexKind int
+
+const (
+	lexEOF lexKind = iota
+	lexWord
+	lexIdent
+	lexString
+	lexRegex
+	lexNumber
+	lexPunct
+)
+
+// lexToken is a token produced by lex.
+type lexToken struct {
+	kind lexKind
+	lit  string
+
+	// pos and end are the byte offsets of the token within the query.
+	pos, end int
+
+	// depth is the parenthesis nesting level of the token. Parentheses
+	// have the depth of the expression that encloses them.
+	depth int
+}
+
+func (t lexToken) isWord(s string) bool {
+	return t.kind == lexWord && strings.EqualFold(t.lit, s)
+}
+
+func (t lexToken) isPunct(s string) bool {
+	return t.kind == lexPunct && t.lit == s
+}
+
+// lex splits the query into tokens. It is not a complete InfluxQL scanner.
+// It only distinguishes enough of the grammar to find clause boundaries
+// without being confused by strings, quoted identifiers, regular
+// expressions, or comments. Malformed input is tokenized on a best effort
+// basis and left for influxql.Parser to report.
+func lex(s string) []lexToken {
+	var (
+		toks   []lexToken
+		depth  int
+		clause string
+	)
+	emit := func(kind lexKind, pos, end int) {
+		tok := lexToken{kind: kind, lit: s[pos:end], pos: pos, end: end, depth: depth}
+		switch {
+		case tok.isPunct("("):
+			depth++
+		case tok.isPunct(")"):
+			if depth > 0 {
+				depth--
+			}
+			tok.depth = depth
+		case kind == lexWord:
+			switch w := strings.ToUpper(tok.lit); w {
+			case "SELECT", "FROM", "WHERE", "GROUP", "ORDER":
+				clause = w
+			}
+		}
+		toks = append(toks, tok)
+	}
+
+	// regexAllowed returns true if a slash at the current position starts
+	// a regular expression instead of being the division operator.
+	regexAllowed := func() bool {
+		if len(toks) == 0 {
+			return false
+		}
+		prev := toks[len(toks)-1]
+		switch {
+		case prev.isPunct("=~"), prev.isPunct("!~"), prev.isWord("MEASUREMENT"):
+			return true
+		case clause == "FROM":
+			return prev.isWord("FROM") || prev.isPunct(",")
+		default:
+			return false
+		}
+	}
+
+	for i := 0; i < len(s); {
+		ch, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(ch):
+			i += size
+		case strings.HasPrefix(s[i:], "--"):
+			if n := strings.IndexByte(s[i:], '\n'); n >= 0 {
+				i += n + 1
+			} else {
+				i = len(s)
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			if n := strings.Index(s[i+2:], "*/"); n >= 0 {
+				i += n + 4
+			} else {
+				i = len(s)
+			}
+		case ch == '\'':
+			end := scanQuoted(s, i, '\'')
+			emit(lexString, i, end)
+			i = end
+		case ch == '"':
+			end := scanQuoted(s, i, '"')
+			emit(lexIdent, i, end)
+			i = end
+		case ch == '/' && regexAllowed():
+			end := scanQuoted(s, i, '/')
+			emit(lexRegex, i, end)
+			i = end
+		case ch == '_' || unicode.IsLetter(ch):
+			end := i + size
+			for end < len(s) {
+				ch, size := utf8.DecodeRuneInString(s[end:])
+				if ch != '_' && !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+					break
+				}
+				end += size
+			}
+			emit(lexWord, i, end)
+			i = end
+		case unicode.IsDigit(ch):
+			end := i + size
+			for end < len(s) {
+				ch, size := utf8.DecodeRuneInString(s[end:])
+				if ch != '.' && ch != 'µ' && !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+					break
+				}
+				end += size
+			}
+			emit(lexNumber, i, end)
+			i = end
+		case strings.HasPrefix(s[i:], "=~"), strings.HasPrefix(s[i:], "!~"):
+			emit(lexPunct, i, i+2)
+			i += 2
+		default:
+			emit(lexPunct, i, i+size)
+			i += size
+		}
+	}
+	toks = append(toks, lexToken{kind: lexEOF, pos: len(s), end: len(s), depth: depth})
+	return toks
+}
+
+// scanQuoted returns the offset following the quoted section that starts at
+// offset i. A backslash escapes the character that follows it.
+func scanQuoted(s string, i int, quote byte) int {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		}
+	}
+	return len(s)
+}
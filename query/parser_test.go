@@ -0,0 +1,500 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestParser_ParseQuery(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		s    string
+		want string
+		err  string
+	}{
+		{
+			name: "Plain",
+			s:    `SELECT mean(value) FROM cpu WHERE host = 'server01' GROUP BY host`,
+			want: `SELECT mean(value) FROM cpu WHERE host = 'server01' GROUP BY host`,
+		},
+		{
+			name: "Having",
+			s:    `SELECT mean(value) FROM cpu GROUP BY host HAVING mean(value) > 80`,
+			want: `SELECT mean(value) FROM cpu WHERE having(mean(value) > 80) GROUP BY host`,
+		},
+		{
+			name: "Having_Where",
+			s:    `SELECT mean(value) FROM cpu WHERE host = 'a' OR host = 'b' GROUP BY time(1m), host HAVING mean(value) > 80 fill(none) LIMIT 10`,
+			want: `SELECT mean(value) FROM cpu WHERE (host = 'a' OR host = 'b') AND having(mean(value) > 80) GROUP BY time(1m), host fill(none) LIMIT 10`,
+		},
+		{
+			name: "Having_WithoutGroupBy",
+			s:    `SELECT max(value) FROM cpu WHERE time > now() - 1h having max(value) >= 10 tz('UTC')`,
+			want: `SELECT max(value) FROM cpu WHERE (time > now() - 1h) AND having(max(value) >= 10) TZ('UTC')`,
+		},
+		{
+			name: "Having_Subquery",
+			s:    `SELECT max(m) FROM (SELECT mean(value) AS m FROM cpu GROUP BY host) HAVING max(m) > 1`,
+			want: `SELECT max(m) FROM (SELECT mean(value) AS m FROM cpu GROUP BY host) WHERE having(max(m) > 1)`,
+		},
+		{
+			name: "Having_MultipleStatements",
+			s:    `SELECT count(value) FROM cpu GROUP BY host HAVING count(value) > 1; SELECT value FROM "having" WHERE "having" = 'HAVING x'`,
+			want: "SELECT count(value) FROM cpu WHERE having(count(value) > 1) GROUP BY host;\nSELECT value FROM having WHERE having = 'HAVING x'",
+		},
+		{
+			name: "Having_Identifier",
+			s:    `SELECT having FROM having WHERE having = 'x' GROUP BY having; DROP MEASUREMENT having`,
+			want: "SELECT having FROM having WHERE having = 'x' GROUP BY having;\nDROP MEASUREMENT having",
+		},
+		{
+			name: "Having_Regex",
+			s:    `SELECT count(value) FROM /cpu(a|b)/ WHERE host =~ /server(01|02)/ GROUP BY host HAVING count(value) > 1`,
+			want: `SELECT count(value) FROM /cpu(a|b)/ WHERE (host =~ /server(01|02)/) AND having(count(value) > 1) GROUP BY host`,
+		},
+		{
+			name: "Having_ContinuousQuery",
+			s:    `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h), host HAVING mean(value) > 0 END`,
+			want: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(value) INTO cpu_1h FROM cpu WHERE having(mean(value) > 0) GROUP BY time(1h), host END`,
+		},
+		{
+			name: "Having_InSubquery",
+			s:    `SELECT max(m) FROM (SELECT mean(value) AS m FROM cpu GROUP BY host HAVING mean(value) > 1)`,
+			err:  `HAVING is not supported in subqueries`,
+		},
+		{
+			name: "Having_MissingExpression",
+			s:    `SELECT mean(value) FROM cpu GROUP BY host HAVING LIMIT 1`,
+			err:  `found end of clause, expected expression after HAVING`,
+		},
+		{
+			name: "AsofJoin",
+			s:    `SELECT value, state FROM events ASOF JOIN status ON host, region WHERE time > now() - 1h`,
+			want: `SELECT value, state FROM events WHERE (time > now() - 1h) AND asof_join('SELECT * FROM status GROUP BY host, region')`,
+		},
+		{
+			name: "AsofJoin_WithoutWhere",
+			s:    `SELECT value, state FROM db0.rp0.events ASOF JOIN db1.autogen.status ON host LIMIT 10`,
+			want: `SELECT value, state FROM db0.rp0.events WHERE asof_join('SELECT * FROM db1.autogen.status GROUP BY host') LIMIT 10`,
+		},
+		{
+			name: "AsofJoin_WithoutOn",
+			s:    `SELECT value, state FROM events ASOF JOIN status`,
+			want: `SELECT value, state FROM events WHERE asof_join('SELECT * FROM status')`,
+		},
+		{
+			name: "AsofJoin_InSubquery",
+			s:    `SELECT max(value) FROM (SELECT value, state FROM events ASOF JOIN status ON host)`,
+			err:  `ASOF JOIN is not supported in subqueries`,
+		},
+		{
+			name: "AsofJoin_MissingMeasurement",
+			s:    `SELECT value FROM events ASOF JOIN WHERE time > now() - 1h`,
+			err:  `found end of clause, expected measurement after ASOF JOIN`,
+		},
+		{
+			name: "AsofJoin_MissingTags",
+			s:    `SELECT value FROM events ASOF JOIN status ON`,
+			err:  `found end of clause, expected tag keys after ON`,
+		},
+		{
+			name: "Fill_MaxStaleness",
+			s:    `SELECT mean(value) FROM cpu GROUP BY time(1m) fill(previous, 5m)`,
+			want: `SELECT mean(value) FROM cpu WHERE fill(previous, 5m) GROUP BY time(1m) fill(previous)`,
+		},
+		{
+			name: "Fill_MaxStaleness_Where",
+			s:    `SELECT mean(value) FROM cpu WHERE time > now() - 1h GROUP BY time(1m) HAVING mean(value) > 1 FILL(previous, 5m) LIMIT 2`,
+			want: `SELECT mean(value) FROM cpu WHERE ((time > now() - 1h) AND having(mean(value) > 1)) AND fill(previous, 5m) GROUP BY time(1m) fill(previous) LIMIT 2`,
+		},
+		{
+			name: "Fill_MaxStaleness_Reparse",
+			s:    `SELECT mean(value) FROM cpu WHERE fill(previous, 5m) GROUP BY time(1m) fill(previous)`,
+			want: `SELECT mean(value) FROM cpu WHERE fill(previous, 5m) GROUP BY time(1m) fill(previous)`,
+		},
+		{
+			name: "Fill_MaxStaleness_NotPrevious",
+			s:    `SELECT mean(value) FROM cpu GROUP BY time(1m) fill(linear, 5m)`,
+			err:  `a maximum staleness duration can only be used with fill(previous)`,
+		},
+		{
+			name: "Fill_MaxStaleness_InSubquery",
+			s:    `SELECT max(m) FROM (SELECT mean(value) AS m FROM cpu GROUP BY time(1m) fill(previous, 5m))`,
+			err:  `fill(previous, <duration>) is not supported in subqueries`,
+		},
+		{
+			name: "CalendarInterval",
+			s:    `SELECT sum(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' GROUP BY time(1mo), host`,
+			want: `SELECT sum(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' GROUP BY time('1mo'), host`,
+		},
+		{
+			name: "CalendarInterval_Offset",
+			s:    `SELECT sum(value) FROM (SELECT value FROM cpu GROUP BY time(2q, 15d)) GROUP BY TIME(1y)`,
+			want: `SELECT sum(value) FROM (SELECT value FROM cpu GROUP BY time('2q', 15d)) GROUP BY time('1y')`,
+		},
+		{
+			name: "CalendarInterval_Reparse",
+			s:    `SELECT sum(value) FROM cpu GROUP BY time('1mo') fill(0)`,
+			want: `SELECT sum(value) FROM cpu GROUP BY time('1mo') fill(0)`,
+		},
+		{
+			name: "CalendarInterval_Duration",
+			s:    `SELECT sum(value) FROM cpu GROUP BY time(1m)`,
+			want: `SELECT sum(value) FROM cpu GROUP BY time(1m)`,
+		},
+		{
+			name: "After_ShowSeries",
+			s:    `SHOW SERIES FROM cpu LIMIT 100 AFTER 'Y3B1LGhvc3Q9YQ'`,
+			want: `SHOW SERIES FROM cpu WHERE after('Y3B1LGhvc3Q9YQ') LIMIT 100`,
+		},
+		{
+			name: "After_ShowSeries_Where",
+			s:    `show series on db where host = 'a' or host = 'b' after 'Y3B1LGhvc3Q9YQ' limit 10`,
+			want: `SHOW SERIES ON db WHERE (host = 'a' OR host = 'b') AND after('Y3B1LGhvc3Q9YQ') LIMIT 10`,
+		},
+		{
+			name: "After_ShowSeries_WithoutLimit",
+			s:    `SHOW SERIES AFTER 'Y3B1'`,
+			want: `SHOW SERIES WHERE after('Y3B1')`,
+		},
+		{
+			name: "After_ShowTagKeys",
+			s:    `SHOW TAG KEYS FROM cpu LIMIT 2 AFTER 'Y3B1AGhvc3Q'; SHOW TAG VALUES WITH KEY = after`,
+			want: "SHOW TAG KEYS FROM cpu WHERE after('Y3B1AGhvc3Q') LIMIT 2;\nSHOW TAG VALUES WITH KEY = after",
+		},
+		{
+			name: "After_Identifier",
+			s:    `SELECT after FROM cpu WHERE after = 'x'; SHOW SERIES EXACT CARDINALITY WHERE after = 'x'`,
+			want: "SELECT after FROM cpu WHERE after = 'x';\nSHOW SERIES EXACT CARDINALITY WHERE after = 'x'",
+		},
+		{
+			name: "In",
+			s:    `SELECT value FROM cpu WHERE host IN ('a', 'b') AND value::integer NOT IN (1, -2)`,
+			want: `SELECT value FROM cpu WHERE (host = 'a' OR host = 'b') AND (value::integer != 1 AND value::integer != -2)`,
+		},
+		{
+			name: "In_Subquery",
+			s:    `SELECT max(value) FROM (SELECT value FROM cpu WHERE "host name" in ('a')) GROUP BY host HAVING max(value) IN (1, 2)`,
+			want: `SELECT max(value) FROM (SELECT value FROM cpu WHERE ("host name" = 'a')) WHERE having((max(value) = 1 OR max(value) = 2)) GROUP BY host`,
+		},
+		{
+			name: "In_WithKey",
+			s:    `SHOW TAG VALUES WITH KEY IN (host, region) WHERE host IN ('a', 'b'); DELETE FROM cpu WHERE region IN ('uswest')`,
+			want: "SHOW TAG VALUES WITH KEY IN (host, region) WHERE (host = 'a' OR host = 'b');\nDELETE FROM cpu WHERE (region = 'uswest')",
+		},
+		{
+			name: "In_EmptyList",
+			s:    `SELECT value FROM cpu WHERE host IN ()`,
+			err:  `found end of list, expected value in IN list`,
+		},
+		{
+			name: "ScalarSubquery",
+			s:    `SELECT value FROM cpu WHERE value > (SELECT mean(value) FROM cpu WHERE host = 'a' GROUP BY host HAVING mean(value) > 1) AND host = 'b'`,
+			want: `SELECT value FROM cpu WHERE value > scalar('SELECT mean(value) FROM cpu WHERE host = \'a\' GROUP BY host HAVING mean(value) > 1') AND host = 'b'`,
+		},
+		{
+			name: "ScalarSubquery_Nested",
+			s:    `SELECT value FROM (SELECT value FROM cpu) WHERE value >= (SELECT max(value) FROM cpu WHERE value < (SELECT mean(value) FROM cpu))`,
+			want: `SELECT value FROM (SELECT value FROM cpu) WHERE value >= scalar('SELECT max(value) FROM cpu WHERE value < (SELECT mean(value) FROM cpu)')`,
+		},
+		{
+			name: "SetTimeZone",
+			s:    `SET TIME ZONE 'America/New_York'; SELECT mean(value) FROM cpu GROUP BY time(1d)`,
+			want: "SET TIME ZONE 'America/New_York';\nSELECT mean(value) FROM cpu GROUP BY time(1d)",
+		},
+		{
+			name: "SetTimeZone_Default",
+			s:    `set time zone default`,
+			want: `SET TIME ZONE DEFAULT`,
+		},
+		{
+			name: "SetTimeZone_User",
+			s:    `SET TIME ZONE FOR "bob smith" = 'Europe/Paris'; SET TIME ZONE FOR alice = DEFAULT`,
+			want: "SET TIME ZONE FOR \"bob smith\" = 'Europe/Paris';\nSET TIME ZONE FOR alice = DEFAULT",
+		},
+		{
+			name: "SetTimeZone_SetPassword",
+			s:    `SET PASSWORD FOR bob = 'secret'`,
+			want: `SET PASSWORD FOR bob = [REDACTED]`,
+		},
+		{
+			name: "SetTimeZone_Unknown",
+			s:    `SET TIME ZONE 'Mars/Olympus_Mons'`,
+			err:  `unable to find time zone Mars/Olympus_Mons`,
+		},
+		{
+			name: "SetTimeZone_MissingZone",
+			s:    `SET TIME 'UTC'`,
+			err:  `found UTC, expected ZONE at line 1, char 9`,
+		},
+		{
+			name: "SetTimeZone_MissingTimeZone",
+			s:    `SET TIME ZONE FOR bob = 1`,
+			err:  `found 1, expected string, DEFAULT at line 1, char 25`,
+		},
+		{
+			name: "SetTimeZone_InvalidStatement",
+			s:    `SET 'UTC'`,
+			err:  `found UTC, expected PASSWORD, TIME, QUOTA, WRITE, QUERY at line 1, char 4`,
+		},
+		{
+			name: "SetTimeZone_MissingSemicolon",
+			s:    `SET TIME ZONE 'UTC' SELECT value FROM cpu`,
+			err:  `found SELECT, expected ; at line 1, char 21`,
+		},
+		{
+			name: "SetWriteTarget",
+			s:    `SET WRITE TARGET FOR bob = db0; set write target for "bob smith" = "db 0".rp0; SET WRITE TARGET FOR bob = DEFAULT`,
+			want: "SET WRITE TARGET FOR bob = db0;\nSET WRITE TARGET FOR \"bob smith\" = \"db 0\".rp0;\nSET WRITE TARGET FOR bob = DEFAULT",
+		},
+		{
+			name: "SetWriteTarget_MissingTarget",
+			s:    `SET WRITE FOR bob = db0`,
+			err:  `found FOR, expected TARGET at line 1, char 11`,
+		},
+		{
+			name: "SetWriteTarget_MissingDatabase",
+			s:    `SET WRITE TARGET FOR bob = 'db0'`,
+			err:  `found db0, expected identifier at line 1, char 27`,
+		},
+		{
+			name: "SetQueryPriority",
+			s:    `SET QUERY PRIORITY FOR bob = 'batch'; set query priority for "bob smith" = 'dashboard'; SET QUERY PRIORITY FOR bob = DEFAULT`,
+			want: "SET QUERY PRIORITY FOR bob = 'batch';\nSET QUERY PRIORITY FOR \"bob smith\" = 'dashboard';\nSET QUERY PRIORITY FOR bob = DEFAULT",
+		},
+		{
+			name: "SetQueryPriority_Unknown",
+			s:    `SET QUERY PRIORITY FOR bob = 'urgent'`,
+			err:  `unknown query priority urgent, expected one of interactive, dashboard, batch`,
+		},
+		{
+			name: "SetQueryPriority_MissingPriority",
+			s:    `SET QUERY FOR bob = 'batch'`,
+			err:  `found FOR, expected PRIORITY at line 1, char 11`,
+		},
+		{
+			name: "GrantMeasurement",
+			s:    `GRANT READ ON db0 MEASUREMENT cpu TO bob; GRANT ALL ON db0 MEASUREMENT /^disk_/ TO bob`,
+			want: "GRANT READ ON db0 MEASUREMENT cpu TO bob;\nGRANT ALL PRIVILEGES ON db0 MEASUREMENT /^disk_/ TO bob",
+		},
+		{
+			name: "GrantMeasurement_QuotedName",
+			s:    `GRANT WRITE ON "db 0" MEASUREMENT "cpu load" TO "bob smith"`,
+			want: `GRANT WRITE ON "db 0" MEASUREMENT "cpu load" TO "bob smith"`,
+		},
+		{
+			name: "GrantMeasurement_Database",
+			s:    `GRANT READ ON db0 TO bob; GRANT ALL PRIVILEGES TO alice`,
+			want: "GRANT READ ON db0 TO bob;\nGRANT ALL PRIVILEGES TO alice",
+		},
+		{
+			name: "RevokeMeasurement",
+			s:    `REVOKE WRITE ON db0 MEASUREMENT cpu FROM bob; REVOKE ALL PRIVILEGES ON db0 MEASUREMENT /^disk_/ FROM bob`,
+			want: "REVOKE WRITE ON db0 MEASUREMENT cpu FROM bob;\nREVOKE ALL PRIVILEGES ON db0 MEASUREMENT /^disk_/ FROM bob",
+		},
+		{
+			name: "RevokeMeasurement_Database",
+			s:    `REVOKE READ ON db0 FROM bob; REVOKE ALL FROM alice`,
+			want: "REVOKE READ ON db0 FROM bob;\nREVOKE ALL PRIVILEGES FROM alice",
+		},
+		{
+			name: "GrantMeasurement_InvalidMeasurement",
+			s:    `GRANT READ ON db0 MEASUREMENT 'cpu' TO bob`,
+			err:  `invalid measurement 'cpu', expected identifier or regex`,
+		},
+		{
+			name: "GrantMeasurement_MissingTo",
+			s:    `GRANT READ ON db0 MEASUREMENT cpu FROM bob`,
+			err:  `found FROM, expected TO at line 1, char 35`,
+		},
+		{
+			name: "GrantMeasurement_InvalidPrivilege",
+			s:    `GRANT ADMIN ON db0 TO bob`,
+			err:  `found ADMIN, expected READ, WRITE, ALL [PRIVILEGES] at line 1, char 7`,
+		},
+		{
+			name: "RevokeMeasurement_MissingOn",
+			s:    `REVOKE READ FROM bob`,
+			err:  `found FROM, expected ON at line 1, char 13`,
+		},
+		{
+			name: "Role",
+			s:    `CREATE ROLE ops; create role "db admins"; DROP ROLE ops; SHOW ROLES`,
+			want: "CREATE ROLE ops;\nCREATE ROLE \"db admins\";\nDROP ROLE ops;\nSHOW ROLES",
+		},
+		{
+			name: "Role_Membership",
+			s:    `GRANT ROLE ops TO bob; REVOKE role ops FROM bob`,
+			want: "GRANT ROLE ops TO bob;\nREVOKE ROLE ops FROM bob",
+		},
+		{
+			name: "Role_Privilege",
+			s:    `GRANT READ ON db0 TO ROLE ops; REVOKE ALL ON db0 FROM ROLE ops; SHOW GRANTS FOR ROLE ops`,
+			want: "GRANT READ ON db0 TO ROLE ops;\nREVOKE ALL PRIVILEGES ON db0 FROM ROLE ops;\nSHOW GRANTS FOR ROLE ops",
+		},
+		{
+			name: "Role_UserNamedRole",
+			s:    `GRANT READ ON db0 TO role; SHOW GRANTS FOR role; GRANT ALL PRIVILEGES TO role`,
+			want: "GRANT READ ON db0 TO role;\nSHOW GRANTS FOR role;\nGRANT ALL PRIVILEGES TO role",
+		},
+		{
+			name: "Role_Admin",
+			s:    `GRANT ALL PRIVILEGES TO ROLE ops`,
+			err:  `admin privilege cannot be granted to a role`,
+		},
+		{
+			name: "Role_Measurement",
+			s:    `GRANT READ ON db0 MEASUREMENT cpu TO ROLE ops`,
+			err:  `privileges on measurements are not supported for roles`,
+		},
+		{
+			name: "Role_MissingName",
+			s:    `CREATE ROLE`,
+			err:  `found EOF, expected identifier at line 1, char 13`,
+		},
+		{
+			name: "Role_MissingTo",
+			s:    `GRANT ROLE ops ON bob`,
+			err:  `found ON, expected TO at line 1, char 16`,
+		},
+		{
+			name: "Role_InvalidStatement",
+			s:    `CREATE GROUP ops`,
+			err:  `found GROUP, expected CONTINUOUS, DATABASE, USER, RETENTION, SUBSCRIPTION, ROLE, TOKEN, SHARD at line 1, char 8`,
+		},
+		{
+			name: "Token",
+			s:    `CREATE TOKEN FOR bob; create token for "ci bot" with duration 30d; SHOW TOKENS; SHOW TOKENS FOR bob; DROP TOKEN '0a1b2c3d4e5f6a7b'`,
+			want: "CREATE TOKEN FOR bob;\nCREATE TOKEN FOR \"ci bot\" WITH DURATION 30d;\nSHOW TOKENS;\nSHOW TOKENS FOR bob;\nDROP TOKEN '0a1b2c3d4e5f6a7b'",
+		},
+		{
+			name: "Token_WriteTarget",
+			s:    `CREATE TOKEN FOR bob WRITE TARGET db0; CREATE TOKEN FOR bob WITH DURATION 1h WRITE TARGET db0."rp 0"`,
+			want: "CREATE TOKEN FOR bob WRITE TARGET db0;\nCREATE TOKEN FOR bob WITH DURATION 1h WRITE TARGET db0.\"rp 0\"",
+		},
+		{
+			name: "Token_MissingTarget",
+			s:    `CREATE TOKEN FOR bob WRITE db0`,
+			err:  `found db0, expected TARGET at line 1, char 28`,
+		},
+		{
+			name: "Token_InfiniteDuration",
+			s:    `CREATE TOKEN FOR bob WITH DURATION INF`,
+			want: "CREATE TOKEN FOR bob",
+		},
+		{
+			name: "Token_MissingFor",
+			s:    `CREATE TOKEN bob`,
+			err:  `found bob, expected FOR at line 1, char 14`,
+		},
+		{
+			name: "Token_MissingDuration",
+			s:    `CREATE TOKEN FOR bob WITH TTL 30d`,
+			err:  `found TTL, expected DURATION at line 1, char 27`,
+		},
+		{
+			name: "Token_MissingID",
+			s:    `DROP TOKEN`,
+			err:  `found EOF, expected string at line 1, char 12`,
+		},
+		{
+			name: "Token_InvalidWord",
+			s:    `SHOW TOKEN`,
+			err:  `found TOKEN, expected ROLES, TOKENS, QUOTAS at line 1, char 6`,
+		},
+		{
+			name: "ShardGroups",
+			s:    `SHOW SHARD GROUPS; show shard groups on db0; SHOW SHARD GROUPS ON db0."rp 0"; CREATE SHARD GROUP ON db0.rp0 AT '2026-01-02T03:04:05Z'; create shard group on db0; TRUNCATE SHARD GROUPS; truncate shard groups on db0.rp0 at '2026-01-02'`,
+			want: "SHOW SHARD GROUPS;\nSHOW SHARD GROUPS ON db0;\nSHOW SHARD GROUPS ON db0.\"rp 0\";\nCREATE SHARD GROUP ON db0.rp0 AT '2026-01-02T03:04:05Z';\nCREATE SHARD GROUP ON db0;\nTRUNCATE SHARD GROUPS;\nTRUNCATE SHARD GROUPS ON db0.rp0 AT '2026-01-02T00:00:00Z'",
+		},
+		{
+			name: "ShardGroups_MissingOn",
+			s:    `CREATE SHARD GROUP AT '2026-01-02'`,
+			err:  `found AT, expected ON at line 1, char 20`,
+		},
+		{
+			name: "ShardGroups_MissingGroups",
+			s:    `TRUNCATE SHARDS`,
+			err:  `found SHARDS, expected SHARD at line 1, char 10`,
+		},
+		{
+			name: "ShardGroups_InvalidTime",
+			s:    `TRUNCATE SHARD GROUPS AT now()`,
+			err:  `found now, expected string at line 1, char 26`,
+		},
+		{
+			name: "InvalidStatement",
+			s:    `TRUNC SHARD GROUPS`,
+			err:  `found TRUNC, expected SELECT, DELETE, SHOW, CREATE, DROP, EXPLAIN, GRANT, REVOKE, ALTER, SET, KILL, TRUNCATE, RENAME at line 1, char 1`,
+		},
+		{
+			name: "Rename",
+			s:    `RENAME DATABASE db0 TO "db 1"; rename measurement cpu to load; RENAME MEASUREMENT "cpu 0" TO cpu0 ON db0`,
+			want: "RENAME DATABASE db0 TO \"db 1\";\nRENAME MEASUREMENT cpu TO load;\nRENAME MEASUREMENT \"cpu 0\" TO cpu0 ON db0",
+		},
+		{
+			name: "Rename_InvalidObject",
+			s:    `RENAME USER user0 TO user1`,
+			err:  `found USER, expected DATABASE, MEASUREMENT at line 1, char 8`,
+		},
+		{
+			name: "Rename_MissingTo",
+			s:    `RENAME DATABASE db0 db1`,
+			err:  `found db1, expected TO at line 1, char 21`,
+		},
+		{
+			name: "Quota",
+			s:    `SET QUOTA ON db0 MAX SERIES 1000; set quota on "db 1" max concurrent writes 4 max write rate 5000 max measurements 0; SHOW QUOTAS; DROP QUOTA ON db0`,
+			want: "SET QUOTA ON db0 MAX SERIES 1000;\nSET QUOTA ON \"db 1\" MAX MEASUREMENTS 0 MAX WRITE RATE 5000 MAX CONCURRENT WRITES 4;\nSHOW QUOTAS;\nDROP QUOTA ON db0",
+		},
+		{
+			name: "Quota_MissingLimit",
+			s:    `SET QUOTA ON db0`,
+			err:  `found EOF, expected MAX at line 1, char 18`,
+		},
+		{
+			name: "Quota_InvalidLimit",
+			s:    `SET QUOTA ON db0 MAX SHARDS 10`,
+			err:  `found SHARDS, expected SERIES, MEASUREMENTS, WRITE, CONCURRENT at line 1, char 22`,
+		},
+		{
+			name: "Quota_InvalidConcurrentLimit",
+			s:    `SET QUOTA ON db0 MAX CONCURRENT QUERIES 10`,
+			err:  `found QUERIES, expected WRITES at line 1, char 33`,
+		},
+		{
+			name: "Quota_DuplicateLimit",
+			s:    `SET QUOTA ON db0 MAX SERIES 10 MAX SERIES 20`,
+			err:  `found duplicate limit in SET QUOTA`,
+		},
+		{
+			name: "Quota_NegativeLimit",
+			s:    `SET QUOTA ON db0 MAX SERIES -1`,
+			err:  `found -, expected integer at line 1, char 29`,
+		},
+		{
+			name: "Quota_MissingOn",
+			s:    `DROP QUOTA db0`,
+			err:  `found db0, expected ON at line 1, char 12`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.ParseQuery(tt.s)
+			if err != nil {
+				if tt.err == "" {
+					t.Fatal(err)
+				} else if have, want := err.Error(), tt.err; have != want {
+					t.Fatalf("unexpected error: have=%s want=%s", have, want)
+				}
+				return
+			} else if tt.err != "" {
+				t.Fatal("expected error")
+			}
+
+			if have, want := q.String(), tt.want; have != want {
+				t.Fatalf("unexpected query:\nhave=%s\nwant=%s", have, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// QueryPolicy limits the resources used by the queries of a user or a
+// database. The TaskManager kills the queries that exceed one of the limits.
+type QueryPolicy struct {
+	// Name identifies the policy in errors and in the record of the
+	// queries it killed.
+	Name string
+
+	// User and Database select the queries the policy applies to.
+	// An empty value matches any user or database.
+	User     string
+	Database string
+
+	// MaxRuntime is the maximum amount of time a query may run.
+	MaxRuntime time.Duration
+
+	// MaxSeriesN is the maximum number of series a query may read.
+	MaxSeriesN int
+
+	// MaxPointN is the maximum number of points a query may read.
+	MaxPointN int
+}
+
+// Matches returns true if the policy applies to the queries run by the user
+// against the database.
+func (p *QueryPolicy) Matches(user, database string) bool {
+	return (p.User == "" || p.User == user) && (p.Database == "" || p.Database == database)
+}
+
+// PolicyViolationError is the error of a query killed by a query policy.
+type PolicyViolationError struct {
+	Policy string
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("query killed by policy %s: %s", e.Policy, e.Reason)
+}
+
+// KilledQuery describes a query that was killed by a query policy.
+type KilledQuery struct {
+	ID       uint64
+	Query    string
+	Database string
+	User     string
+	Policy   string
+	Reason   string
+	Duration time.Duration
+}
+
+// PolicyMonitor is a query monitor that exits when the cursor has read more
+// series or points than the policy allows. The series are checked as soon as
+// the monitor starts.
+func PolicyMonitor(cur Cursor, interval time.Duration, policy *QueryPolicy) MonitorFunc {
+	return func(closing <-chan struct{}) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			stats := cur.Stats()
+			if policy.MaxSeriesN > 0 && stats.SeriesN > policy.MaxSeriesN {
+				return &PolicyViolationError{
+					Policy: policy.Name,
+					Reason: fmt.Sprintf("max-series limit exceeded: (%d/%d)", stats.SeriesN, policy.MaxSeriesN),
+				}
+			} else if policy.MaxPointN > 0 && stats.PointN > policy.MaxPointN {
+				return &PolicyViolationError{
+					Policy: policy.Name,
+					Reason: fmt.Sprintf("max-points limit exceeded: (%d/%d)", stats.PointN, policy.MaxPointN),
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-closing:
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// Priority classes of queries, from the highest to the lowest.
+const (
+	// PriorityInteractive is the class of the queries a user waits for,
+	// like the ones typed in a shell.
+	PriorityInteractive = "interactive"
+
+	// PriorityDashboard is the class of the queries refreshed by dashboards.
+	PriorityDashboard = "dashboard"
+
+	// PriorityBatch is the class of the long queries of exports and reports.
+	PriorityBatch = "batch"
+)
+
+// Priorities lists the priority classes from the highest to the lowest.
+var Priorities = []string{PriorityInteractive, PriorityDashboard, PriorityBatch}
+
+// Statistics for the priority classes.
+const (
+	statQueriesRunning  = "queriesRunning"  // Number of queries running in the class
+	statQueriesQueued   = "queriesQueued"   // Number of queries waiting for a slot of the class
+	statQueriesRejected = "queriesRejected" // Number of queries rejected because the queue was full
+	statQueriesTimedOut = "queriesTimedOut" // Number of queries that waited longer than the queue timeout
+)
+
+// ErrQueryQueueTimeout is returned when a query waited longer than the queue
+// timeout of its priority class to run.
+var ErrQueryQueueTimeout = errors.New("queue-timeout limit exceeded")
+
+// ErrQueryQueueFull is returned when a query cannot be queued because the
+// queue of its priority class is full.
+func ErrQueryQueueFull(priority string, limit int) error {
+	return fmt.Errorf("max-queued-queries limit exceeded for %s priority (%d)", priority, limit)
+}
+
+// ValidPriority returns true if p is the name of a priority class.
+func ValidPriority(p string) bool {
+	return priorityRank(p) >= 0
+}
+
+// LowerPriority returns the lower of two priority classes. An unknown or
+// empty priority is ignored.
+func LowerPriority(a, b string) string {
+	if priorityRank(a) < priorityRank(b) {
+		return b
+	}
+	return a
+}
+
+// priorityRank returns the position of the priority in Priorities, or -1 if
+// it is unknown.
+func priorityRank(p string) int {
+	for i, name := range Priorities {
+		if name == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// PriorityClass limits the queries of a priority class. The queries of each
+// class run in a pool of their own, so the queries of a lower class can't
+// starve the ones of a higher class.
+type PriorityClass struct {
+	// MaxConcurrentQueries is the maximum number of queries of the class
+	// running at the same time. Zero means no limit.
+	MaxConcurrentQueries int
+
+	// MaxQueuedQueries is the maximum number of queries waiting for a query
+	// of the class to finish. The queries are rejected once the queue is
+	// full. Zero means that no query waits.
+	MaxQueuedQueries int
+
+	// QueueTimeout is the maximum amount of time a query waits in the queue.
+	// Zero means no limit.
+	QueueTimeout time.Duration
+}
+
+// priorityPool tracks the queries running and waiting in a priority class.
+// It is guarded by the mutex of the TaskManager.
+type priorityPool struct {
+	name    string
+	class   PriorityClass
+	running int
+
+	// The queries waiting for a slot, in order. A slot is handed over to a
+	// waiting query by closing its channel.
+	waiters []chan struct{}
+
+	rejected int64
+	timedOut int64
+}
+
+// remove removes the waiting query and returns false if it was already
+// handed a slot.
+func (p *priorityPool) remove(ch chan struct{}) bool {
+	for i, w := range p.waiters {
+		if w == ch {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// release hands the slot of a finished query over to the first waiting one.
+func (p *priorityPool) release() {
+	if len(p.waiters) == 0 {
+		p.running--
+		return
+	}
+	ch := p.waiters[0]
+	p.waiters = p.waiters[1:]
+	close(ch)
+}
+
+// pool returns the pool of the priority class, or nil if the class has no
+// limits.
+func (t *TaskManager) pool(priority string) *priorityPool {
+	if priority == "" {
+		priority = t.DefaultPriority
+	}
+	if p := t.pools[priority]; p != nil {
+		return p
+	}
+
+	class, ok := t.PriorityClasses[priority]
+	if !ok {
+		return nil
+	}
+	if t.pools == nil {
+		t.pools = make(map[string]*priorityPool)
+	}
+	p := &priorityPool{name: priority, class: class}
+	t.pools[priority] = p
+	return p
+}
+
+// acquire takes a slot of the pool for a query, waiting in the queue of the
+// pool if all the slots are taken. It must be called with the lock held,
+// which is released while the query waits.
+func (t *TaskManager) acquire(p *priorityPool, opt ExecutionOptions, interrupt <-chan struct{}) error {
+	if p.class.MaxConcurrentQueries <= 0 || p.running < p.class.MaxConcurrentQueries {
+		p.running++
+		return nil
+	} else if len(p.waiters) >= p.class.MaxQueuedQueries {
+		p.rejected++
+		return ErrQueryQueueFull(p.name, p.class.MaxQueuedQueries)
+	}
+
+	ch := make(chan struct{})
+	p.waiters = append(p.waiters, ch)
+
+	var timerCh <-chan time.Time
+	if p.class.QueueTimeout > 0 {
+		timer := time.NewTimer(p.class.QueueTimeout)
+		timerCh = timer.C
+		defer timer.Stop()
+	}
+
+	t.mu.Unlock()
+	var err error
+	select {
+	case <-ch:
+	case <-interrupt:
+		err = ErrQueryInterrupted
+	case <-opt.AbortCh:
+		err = ErrQueryAborted
+	case <-timerCh:
+		err = ErrQueryQueueTimeout
+	}
+	t.mu.Lock()
+
+	if t.shutdown {
+		return ErrQueryEngineShutdown
+	} else if err == nil {
+		return nil
+	}
+
+	// The query may have been handed a slot while it stopped waiting.
+	if !p.remove(ch) {
+		p.release()
+	}
+	if err == ErrQueryQueueTimeout {
+		p.timedOut++
+	}
+	return err
+}
+
+// Statistics returns statistics of the priority classes for periodic
+// monitoring.
+func (t *TaskManager) Statistics(tags map[string]string) []models.Statistic {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var stats []models.Statistic
+	for _, name := range Priorities {
+		if _, ok := t.PriorityClasses[name]; !ok {
+			continue
+		}
+		p := t.pool(name)
+		stats = append(stats, models.Statistic{
+			Name: "queryPriority",
+			Tags: models.StatisticTags{"priority": name}.Merge(tags),
+			Values: map[string]interface{}{
+				statQueriesRunning:  int64(p.running),
+				statQueriesQueued:   int64(len(p.waiters)),
+				statQueriesRejected: p.rejected,
+				statQueriesTimedOut: p.timedOut,
+			},
+		})
+	}
+	return stats
+}
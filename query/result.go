@@ -91,6 +91,10 @@ type Result struct {
 	Messages    []*Message
 	Partial     bool
 	Err         error
+
+	// Next is the continuation token that resumes a paginated SHOW SERIES or
+	// SHOW TAG KEYS statement after the last row of this result.
+	Next string
 }
 
 // MarshalJSON encodes the result into JSON.
@@ -101,6 +105,7 @@ func (r *Result) MarshalJSON() ([]byte, error) {
 		Series      []*models.Row `json:"series,omitempty"`
 		Messages    []*Message    `json:"messages,omitempty"`
 		Partial     bool          `json:"partial,omitempty"`
+		Next        string        `json:"next,omitempty"`
 		Err         string        `json:"error,omitempty"`
 	}
 
@@ -109,6 +114,7 @@ func (r *Result) MarshalJSON() ([]byte, error) {
 	o.Series = r.Series
 	o.Messages = r.Messages
 	o.Partial = r.Partial
+	o.Next = r.Next
 	if r.Err != nil {
 		o.Err = r.Err.Error()
 	}
@@ -123,6 +129,7 @@ func (r *Result) UnmarshalJSON(b []byte) error {
 		Series      []*models.Row `json:"series,omitempty"`
 		Messages    []*Message    `json:"messages,omitempty"`
 		Partial     bool          `json:"partial,omitempty"`
+		Next        string        `json:"next,omitempty"`
 		Err         string        `json:"error,omitempty"`
 	}
 
@@ -134,6 +141,7 @@ func (r *Result) UnmarshalJSON(b []byte) error {
 	r.Series = o.Series
 	r.Messages = o.Messages
 	r.Partial = o.Partial
+	r.Next = o.Next
 	if o.Err != "" {
 		r.Err = errors.New(o.Err)
 	}
@@ -16,6 +16,7 @@ import (
 )
 
 var DefaultTypeMapper = influxql.MultiTypeMapper(
+	StringTypeMapper{},
 	FunctionTypeMapper{},
 	MathTypeMapper{},
 )
@@ -39,6 +40,10 @@ type SelectOptions struct {
 
 	// Maximum number of buckets for a statement.
 	MaxBucketsN int
+
+	// UserFunctions are the user-defined functions that can be called in
+	// the fields of the statement.
+	UserFunctions UserFunctions
 }
 
 // ShardMapper retrieves and maps shards into an IteratorCreator that can later be
@@ -77,9 +82,9 @@ type PreparedStatement interface {
 }
 
 // Prepare will compile the statement with the default compile options and
-// then prepare the query.
+// the user-defined functions of the select options and then prepare the query.
 func Prepare(stmt *influxql.SelectStatement, shardMapper ShardMapper, opt SelectOptions) (PreparedStatement, error) {
-	c, err := Compile(stmt, CompileOptions{})
+	c, err := Compile(stmt, CompileOptions{UserFunctions: opt.UserFunctions})
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +113,14 @@ type preparedStatement struct {
 	columns   []string
 	maxPointN int
 	now       time.Time
+
+	// having filters the rows produced by the cursor. When it is set, the
+	// limit and offset are applied after filtering instead of by the iterators.
+	having        influxql.Expr
+	limit, offset int
+
+	// join sets the columns read from the measurement of an ASOF JOIN.
+	join *asofJoin
 }
 
 func (p *preparedStatement) Select(ctx context.Context) (Cursor, error) {
@@ -122,6 +135,18 @@ func (p *preparedStatement) Select(ctx context.Context) (Cursor, error) {
 	if err != nil {
 		return nil, err
 	}
+	if p.join != nil {
+		if cur, err = p.join.Select(ctx, cur); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.having != nil {
+		cur = newFilterCursor(cur, p.having)
+		if p.limit > 0 || p.offset > 0 {
+			cur = newLimitCursor(cur, p.limit, p.offset)
+		}
+	}
 
 	// If a monitor exists and we are told there is a maximum number of points,
 	// register the monitor function.
@@ -130,11 +155,21 @@ func (p *preparedStatement) Select(ctx context.Context) (Cursor, error) {
 			monitor := PointLimitMonitor(cur, DefaultStatsInterval, p.maxPointN)
 			m.Monitor(monitor)
 		}
+
+		// Enforce the series and point limits of the policy of the query.
+		if task, ok := m.(*Task); ok && task.policy != nil {
+			if policy := task.policy; policy.MaxSeriesN > 0 || policy.MaxPointN > 0 {
+				m.Monitor(PolicyMonitor(cur, DefaultStatsInterval, policy))
+			}
+		}
 	}
 	return cur, nil
 }
 
 func (p *preparedStatement) Close() error {
+	if p.join != nil {
+		p.join.Close()
+	}
 	return p.ic.Close()
 }
 
@@ -397,17 +432,8 @@ func (b *exprIteratorBuilder) buildCallIterator(ctx context.Context, expr *influ
 		}
 
 		var input Iterator
-		if len(expr.Args) > 2 {
+		if dims := topBottomDimensions(expr, opt); dims != nil {
 			// Create a max iterator using the groupings in the arguments.
-			dims := make(map[string]struct{}, len(expr.Args)-2+len(opt.GroupBy))
-			for i := 1; i < len(expr.Args)-1; i++ {
-				ref := expr.Args[i].(*influxql.VarRef)
-				dims[ref.Val] = struct{}{}
-			}
-			for dim := range opt.GroupBy {
-				dims[dim] = struct{}{}
-			}
-
 			call := &influxql.Call{
 				Name: "max",
 				Args: expr.Args[:1],
@@ -428,8 +454,9 @@ func (b *exprIteratorBuilder) buildCallIterator(ctx context.Context, expr *influ
 			}
 			input = i
 		} else {
-			// There are no arguments so do not organize the points by tags.
+			// There are no tag arguments so do not organize the points by tags.
 			builder := *b
+			builder.opt = opt
 			builder.opt.Expr = expr.Args[0]
 			builder.selector = true
 			builder.writeMode = false
@@ -442,25 +469,15 @@ func (b *exprIteratorBuilder) buildCallIterator(ctx context.Context, expr *influ
 			input = i
 		}
 
-		n := expr.Args[len(expr.Args)-1].(*influxql.IntegerLiteral)
-		return newTopIterator(input, opt, int(n.Val), b.writeMode)
+		return newTopIterator(input, opt, topBottomLimit(expr, b.opt), b.writeMode)
 	case "bottom":
 		if len(expr.Args) < 2 {
 			return nil, fmt.Errorf("bottom() requires 2 or more arguments, got %d", len(expr.Args))
 		}
 
 		var input Iterator
-		if len(expr.Args) > 2 {
-			// Create a max iterator using the groupings in the arguments.
-			dims := make(map[string]struct{}, len(expr.Args)-2)
-			for i := 1; i < len(expr.Args)-1; i++ {
-				ref := expr.Args[i].(*influxql.VarRef)
-				dims[ref.Val] = struct{}{}
-			}
-			for dim := range opt.GroupBy {
-				dims[dim] = struct{}{}
-			}
-
+		if dims := topBottomDimensions(expr, opt); dims != nil {
+			// Create a min iterator using the groupings in the arguments.
 			call := &influxql.Call{
 				Name: "min",
 				Args: expr.Args[:1],
@@ -481,8 +498,9 @@ func (b *exprIteratorBuilder) buildCallIterator(ctx context.Context, expr *influ
 			}
 			input = i
 		} else {
-			// There are no arguments so do not organize the points by tags.
+			// There are no tag arguments so do not organize the points by tags.
 			builder := *b
+			builder.opt = opt
 			builder.opt.Expr = expr.Args[0]
 			builder.selector = true
 			builder.writeMode = false
@@ -495,8 +513,7 @@ func (b *exprIteratorBuilder) buildCallIterator(ctx context.Context, expr *influ
 			input = i
 		}
 
-		n := expr.Args[len(expr.Args)-1].(*influxql.IntegerLiteral)
-		return newBottomIterator(input, b.opt, int(n.Val), b.writeMode)
+		return newBottomIterator(input, b.opt, topBottomLimit(expr, b.opt), b.writeMode)
 	}
 
 	itr, err := func() (Iterator, error) {
@@ -513,8 +530,25 @@ func (b *exprIteratorBuilder) buildCallIterator(ctx context.Context, expr *influ
 				}
 			}
 			fallthrough
-		case "min", "max", "sum", "first", "last", "mean", "sum_hll", "merge_hll":
+		case "min", "max", "sum", "first", "last", "mean", "sum_hll", "merge_hll", "histogram_sum":
 			return b.callIterator(ctx, expr, opt)
+		case "histogram_quantile":
+			// Merge the histograms with histogram_sum() so the buckets are
+			// merged by the shards before the quantile is estimated.
+			call := &influxql.Call{Name: "histogram_sum", Args: expr.Args[:1]}
+			opt.Expr = call
+			input, err := b.callIterator(ctx, call, opt)
+			if err != nil {
+				return nil, err
+			}
+			var q float64
+			switch arg := expr.Args[1].(type) {
+			case *influxql.NumberLiteral:
+				q = arg.Val
+			case *influxql.IntegerLiteral:
+				q = float64(arg.Val)
+			}
+			return newHistogramQuantileIterator(input, opt, q)
 		case "median":
 			opt.Ordered = true
 			input, err := buildExprIterator(ctx, expr.Args[0].(*influxql.VarRef), b.ic, b.sources, opt, false, false)
@@ -576,6 +610,45 @@ func (b *exprIteratorBuilder) buildCallIterator(ctx context.Context, expr *influ
 	return itr, nil
 }
 
+// topBottomDimensions returns the dimensions that top() or bottom() selects
+// distinct points for. These are the tags listed in the arguments along with
+// the dimensions of the query. Fields listed in the arguments are only
+// returned as additional columns of the selected points. A nil map is returned
+// when no tags are listed.
+func topBottomDimensions(expr *influxql.Call, opt IteratorOptions) map[string]struct{} {
+	var dims map[string]struct{}
+	for _, arg := range expr.Args[1 : len(expr.Args)-1] {
+		ref := arg.(*influxql.VarRef)
+		if ref.Type != influxql.Tag && ref.Type != influxql.Unknown {
+			continue
+		}
+		if dims == nil {
+			dims = make(map[string]struct{}, len(expr.Args)-2+len(opt.GroupBy))
+		}
+		dims[ref.Val] = struct{}{}
+	}
+	if dims == nil {
+		return nil
+	}
+
+	for dim := range opt.GroupBy {
+		dims[dim] = struct{}{}
+	}
+	return dims
+}
+
+// topBottomLimit returns the number of points top() or bottom() selects in
+// each window. When the query has a LIMIT that is smaller than the number in
+// the call, only the highest or lowest points that can be returned within the
+// LIMIT of each group are selected.
+func topBottomLimit(expr *influxql.Call, opt IteratorOptions) int {
+	n := int(expr.Args[len(expr.Args)-1].(*influxql.IntegerLiteral).Val)
+	if opt.Limit > 0 && opt.Limit+opt.Offset < n {
+		n = opt.Limit + opt.Offset
+	}
+	return n
+}
+
 func (b *exprIteratorBuilder) callIterator(ctx context.Context, expr *influxql.Call, opt IteratorOptions) (Iterator, error) {
 	inputs := make([]Iterator, 0, len(b.sources))
 	if err := func() error {
@@ -638,7 +711,12 @@ func buildCursor(ctx context.Context, stmt *influxql.SelectStatement, ic Iterato
 			opt.FillValue = int64(v)
 		}
 	case influxql.PreviousFill:
-		opt.FillValue = SkipDefault
+		// The scanner keeps the last value for fields without a point.
+		// The fill iterator takes care of that when a maximum staleness is
+		// set so the values it leaves empty are not carried forward.
+		if opt.MaxStaleness == 0 {
+			opt.FillValue = SkipDefault
+		}
 	}
 
 	fields := make([]*influxql.Field, 0, len(stmt.Fields)+1)
@@ -654,6 +732,7 @@ func buildCursor(ctx context.Context, stmt *influxql.SelectStatement, ic Iterato
 
 	// Iterate through each of the fields to add them to the value mapper.
 	valueMapper := newValueMapper()
+	valueMapper.functions = opt.UserFunctions
 	for _, f := range stmt.Fields {
 		fields = append(fields, valueMapper.Map(f))
 
@@ -893,6 +972,10 @@ type valueMapper struct {
 	// A collection of all of the calls in the table.
 	refs map[*influxql.VarRef]struct{}
 	i    int
+
+	// functions are the user-defined functions. Their calls are evaluated
+	// by the cursor instead of the iterators.
+	functions UserFunctions
 }
 
 func newValueMapper() *valueMapper {
@@ -927,7 +1010,7 @@ func (v *valueMapper) Visit(n influxql.Node) influxql.Visitor {
 		// as stored in the symbol table.
 		switch n := n.(type) {
 		case *influxql.Call:
-			if isMathFunction(n) {
+			if isMathFunction(n) || isStringFunction(n) || lookupUserFunction(v.functions, n) != nil {
 				return v
 			}
 			v.calls[n] = struct{}{}
@@ -968,9 +1051,11 @@ func (v *valueMapper) rewriteExpr(expr influxql.Expr) influxql.Expr {
 	return &symbol
 }
 
-func validateTypes(stmt *influxql.SelectStatement) error {
+func validateTypes(stmt *influxql.SelectStatement, functions UserFunctions) error {
 	valuer := influxql.TypeValuerEval{
 		TypeMapper: influxql.MultiTypeMapper(
+			UserFunctionTypeMapper{Functions: functions},
+			StringTypeMapper{},
 			FunctionTypeMapper{},
 			MathTypeMapper{},
 		),
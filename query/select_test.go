@@ -38,6 +38,23 @@ func floatIterator(fSlice []float64, name, tags string, startTime, step int64) *
 	}
 }
 
+// histogramIterators returns iterators of histograms stored in string fields.
+func histogramIterators() []query.Iterator {
+	return []query.Iterator{
+		&StringIterator{Points: []query.StringPoint{
+			{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 0 * Second, Value: "1:1,2:3,+Inf:4"},
+			{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 11 * Second, Value: "1:0,2:1,4:2,+Inf:2"},
+		}},
+		&StringIterator{Points: []query.StringPoint{
+			{Name: "cpu", Tags: ParseTags("region=east,host=A"), Time: 5 * Second, Value: "1:2,2:2,+Inf:3"},
+		}},
+		&StringIterator{Points: []query.StringPoint{
+			{Name: "cpu", Tags: ParseTags("region=west,host=B"), Time: 5 * Second, Value: "1:0,+Inf:1"},
+			{Name: "cpu", Tags: ParseTags("region=west,host=B"), Time: 6 * Second, Value: "garbage"},
+		}},
+	}
+}
+
 func TestSelect(t *testing.T) {
 	for _, tt := range []struct {
 		name   string
@@ -100,6 +117,36 @@ func TestSelect(t *testing.T) {
 				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=B")}, Values: []interface{}{uint64(1)}},
 			},
 		},
+		{
+			name: "HistogramSum",
+			q:    `SELECT histogram_sum(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-02T00:00:00Z' GROUP BY time(10s), host fill(none)`,
+			typ:  influxql.String,
+			expr: `histogram_sum(value::string)`,
+			itrs: histogramIterators(),
+			rows: []query.Row{
+				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{"1:3,2:5,+Inf:7"}},
+				{Time: 10 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{"1:0,2:1,4:2,+Inf:2"}},
+				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=B")}, Values: []interface{}{"1:0,+Inf:1"}},
+			},
+		},
+		{
+			name: "HistogramQuantile",
+			q:    `SELECT histogram_quantile(value, 0.5) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-02T00:00:00Z' GROUP BY time(10s), host fill(none)`,
+			typ:  influxql.String,
+			expr: `histogram_sum(value::string)`,
+			itrs: histogramIterators(),
+			rows: []query.Row{
+				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{float64(1.25)}},
+				{Time: 10 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{float64(2)}},
+				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=B")}, Values: []interface{}{float64(1)}},
+			},
+		},
+		{
+			name: "HistogramQuantile_InvalidType",
+			q:    `SELECT histogram_quantile(value, 0.5) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-02T00:00:00Z' GROUP BY time(10s)`,
+			typ:  influxql.Float,
+			err:  `invalid argument type for the first argument in histogram_quantile(): float`,
+		},
 		{
 			name: "Distinct_Float",
 			q:    `SELECT distinct(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-02T00:00:00Z' GROUP BY time(10s), host fill(none)`,
@@ -1361,6 +1408,44 @@ func TestSelect(t *testing.T) {
 				{Time: 50 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=B")}, Values: []interface{}{float64(2)}},
 			},
 		},
+		{
+			name: "Fill_Previous_MaxStaleness_Float",
+			q:    `SELECT mean(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-01T00:01:00Z' GROUP BY host, time(10s) fill(previous, 20s)`,
+			typ:  influxql.Float,
+			expr: `mean(value::float)`,
+			itrs: []query.Iterator{
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("host=A"), Time: 12 * Second, Value: 2},
+				}},
+			},
+			rows: []query.Row{
+				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{nil}},
+				{Time: 10 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{float64(2)}},
+				{Time: 20 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{float64(2)}},
+				{Time: 30 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{float64(2)}},
+				{Time: 40 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{nil}},
+				{Time: 50 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{nil}},
+			},
+		},
+		{
+			name: "Fill_Previous_MaxStaleness_Float_Descending",
+			q:    `SELECT mean(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-01T00:01:00Z' GROUP BY host, time(10s) fill(previous, 20s) ORDER BY time DESC`,
+			typ:  influxql.Float,
+			expr: `mean(value::float)`,
+			itrs: []query.Iterator{
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("host=A"), Time: 12 * Second, Value: 2},
+				}},
+			},
+			rows: []query.Row{
+				{Time: 50 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{nil}},
+				{Time: 40 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{nil}},
+				{Time: 30 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{nil}},
+				{Time: 20 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{nil}},
+				{Time: 10 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{float64(2)}},
+				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{float64(2)}},
+			},
+		},
 		{
 			name: "Fill_Linear_Float_One",
 			q:    `SELECT mean(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-01T00:01:00Z' GROUP BY host, time(10s) fill(linear)`,
@@ -2879,6 +2964,124 @@ func TestSelect(t *testing.T) {
 			},
 			now: mustParseTime("1970-01-01T00:02:30Z"),
 		},
+		{
+			name: "Having",
+			q:    `SELECT mean(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-02T00:00:00Z' GROUP BY host HAVING mean(value) > 10`,
+			typ:  influxql.Float,
+			itrs: []query.Iterator{
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 0 * Second, Value: 20},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 11 * Second, Value: 10},
+				}},
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("region=west,host=B"), Time: 5 * Second, Value: 10},
+					{Name: "cpu", Tags: ParseTags("region=west,host=B"), Time: 6 * Second, Value: 4},
+				}},
+			},
+			rows: []query.Row{
+				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=A")}, Values: []interface{}{float64(15)}},
+			},
+		},
+		{
+			name: "Having_Alias",
+			q:    `SELECT max(value) AS peak FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-01T00:00:30Z' GROUP BY time(10s), host HAVING peak >= 10 AND host = 'B' fill(none)`,
+			typ:  influxql.Float,
+			itrs: []query.Iterator{
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 0 * Second, Value: 20},
+				}},
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("region=west,host=B"), Time: 5 * Second, Value: 10},
+					{Name: "cpu", Tags: ParseTags("region=west,host=B"), Time: 15 * Second, Value: 4},
+					{Name: "cpu", Tags: ParseTags("region=west,host=B"), Time: 25 * Second, Value: 11},
+				}},
+			},
+			rows: []query.Row{
+				{Time: 0 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=B")}, Values: []interface{}{float64(10)}},
+				{Time: 20 * Second, Series: query.Series{Name: "cpu", Tags: ParseTags("host=B")}, Values: []interface{}{float64(11)}},
+			},
+		},
+		{
+			name: "Having_Limit",
+			q:    `SELECT sum(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-01T00:01:00Z' GROUP BY time(10s) HAVING sum(value) > 5 fill(none) LIMIT 2 OFFSET 1`,
+			typ:  influxql.Float,
+			itrs: []query.Iterator{
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 0 * Second, Value: 6},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 10 * Second, Value: 1},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 20 * Second, Value: 7},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 30 * Second, Value: 2},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 40 * Second, Value: 8},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: 50 * Second, Value: 9},
+				}},
+			},
+			rows: []query.Row{
+				{Time: 20 * Second, Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(7)}},
+				{Time: 40 * Second, Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(8)}},
+			},
+		},
+		{
+			name: "Having_NotSelected",
+			q:    `SELECT mean(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-02T00:00:00Z' GROUP BY host HAVING max(value) > 10`,
+			typ:  influxql.Float,
+			err:  `max(value) must be selected to be used in HAVING`,
+		},
+		{
+			name: "CalendarInterval_Month",
+			q:    `SELECT sum(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-04-01T00:00:00Z' GROUP BY time(1mo) fill(0)`,
+			typ:  influxql.Float,
+			itrs: []query.Iterator{
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-01-15T00:00:00Z").UnixNano(), Value: 1},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-01-31T23:00:00Z").UnixNano(), Value: 2},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-03-01T00:00:00Z").UnixNano(), Value: 4},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-03-31T00:00:00Z").UnixNano(), Value: 8},
+				}},
+			},
+			rows: []query.Row{
+				{Time: mustParseTime("2000-01-01T00:00:00Z").UnixNano(), Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(3)}},
+				{Time: mustParseTime("2000-02-01T00:00:00Z").UnixNano(), Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(0)}},
+				{Time: mustParseTime("2000-03-01T00:00:00Z").UnixNano(), Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(12)}},
+			},
+		},
+		{
+			name: "CalendarInterval_Descending",
+			q:    `SELECT sum(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-04-01T00:00:00Z' GROUP BY time(1mo) fill(0) ORDER BY time DESC`,
+			typ:  influxql.Float,
+			itrs: []query.Iterator{
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-03-31T00:00:00Z").UnixNano(), Value: 8},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-01-15T00:00:00Z").UnixNano(), Value: 1},
+				}},
+			},
+			rows: []query.Row{
+				{Time: mustParseTime("2000-03-01T00:00:00Z").UnixNano(), Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(8)}},
+				{Time: mustParseTime("2000-02-01T00:00:00Z").UnixNano(), Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(0)}},
+				{Time: mustParseTime("2000-01-01T00:00:00Z").UnixNano(), Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(1)}},
+			},
+		},
+		{
+			name: "CalendarInterval_Location",
+			q:    `SELECT sum(value) FROM cpu WHERE time >= '2000-03-01T00:00:00-08:00' AND time < '2000-05-01T00:00:00-07:00' GROUP BY time(1mo) fill(none) tz('America/Los_Angeles')`,
+			typ:  influxql.Float,
+			itrs: []query.Iterator{
+				&FloatIterator{Points: []query.FloatPoint{
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-03-31T23:30:00-08:00").UnixNano(), Value: 1},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-04-01T00:30:00-08:00").UnixNano(), Value: 2},
+					{Name: "cpu", Tags: ParseTags("region=west,host=A"), Time: mustParseTime("2000-04-30T23:30:00-07:00").UnixNano(), Value: 4},
+				}},
+			},
+			rows: []query.Row{
+				{Time: mustParseTime("2000-03-01T00:00:00-08:00").UnixNano(), Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(1)}},
+				{Time: mustParseTime("2000-04-01T00:00:00-08:00").UnixNano(), Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(6)}},
+			},
+		},
+		{
+			name: "CalendarInterval_Invalid",
+			q:    `SELECT sum(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-04-01T00:00:00Z' GROUP BY time('1w')`,
+			typ:  influxql.Float,
+			err:  `time dimension must have duration argument`,
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			shardMapper := ShardMapper{
@@ -1,8 +1,11 @@
 package query
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/influxdata/influxql"
 )
@@ -169,8 +172,13 @@ func rewriteShowMeasurementCardinalityStatement(stmt *influxql.ShowMeasurementCa
 }
 
 func rewriteShowSeriesStatement(stmt *influxql.ShowSeriesStatement) (influxql.Statement, error) {
+	after, cond, err := SplitAfter(stmt.Condition, 1)
+	if err != nil {
+		return nil, err
+	}
+
 	s := &influxql.SelectStatement{
-		Condition:  stmt.Condition,
+		Condition:  cond,
 		Offset:     stmt.Offset,
 		Limit:      stmt.Limit,
 		SortFields: stmt.SortFields,
@@ -180,11 +188,22 @@ func rewriteShowSeriesStatement(stmt *influxql.ShowSeriesStatement) (influxql.St
 		IsRawQuery: true,
 	}
 	// Check if we can exclusively use the index.
-	if !influxql.HasTimeExpr(stmt.Condition) {
+	if !influxql.HasTimeExpr(cond) {
 		s.Fields = []*influxql.Field{{Expr: &influxql.VarRef{Val: "key"}}}
 		s.Sources = rewriteSources(stmt.Sources, "_series", stmt.Database)
 		s.Condition = rewriteSourcesCondition(s.Sources, s.Condition)
+
+		// The series iterator resumes after the series key of the token.
+		if after != nil {
+			s.Condition = conjunction(s.Condition, &influxql.BinaryExpr{
+				Op:  influxql.GT,
+				LHS: &influxql.VarRef{Val: "key"},
+				RHS: &influxql.StringLiteral{Val: after[0]},
+			})
+		}
 		return s, nil
+	} else if after != nil {
+		return nil, errors.New("SHOW SERIES doesn't support AFTER with time in WHERE clause")
 	}
 
 	// The query is bounded by time then it will have to query TSM data rather
@@ -559,3 +578,46 @@ func rewriteSources2(sources influxql.Sources, database string) influxql.Sources
 	}
 	return sources
 }
+
+// SplitAfter separates the after() call produced by Parser from the AFTER
+// clause of a SHOW SERIES or SHOW TAG KEYS statement from the rest of the
+// condition. It returns the n parts of the position encoded in the
+// continuation token, or nil if the condition has no after() call.
+func SplitAfter(cond influxql.Expr, n int) (pos []string, rest influxql.Expr, err error) {
+	calls, rest := splitDirectives(cond, "after", 1)
+	if len(calls) == 0 {
+		return nil, cond, nil
+	} else if len(calls) > 1 {
+		return nil, nil, errors.New("AFTER can only be used once")
+	}
+
+	lit, ok := calls[0].Args[0].(*influxql.StringLiteral)
+	if !ok {
+		return nil, nil, fmt.Errorf("expected continuation token, found %s", calls[0].Args[0])
+	}
+	pos, err = DecodeContinuationToken(lit.Val, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pos, rest, nil
+}
+
+// EncodeContinuationToken returns the continuation token that resumes a
+// paginated meta query after the given position.
+func EncodeContinuationToken(pos ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(pos, "\x00")))
+}
+
+// DecodeContinuationToken returns the n parts of the position encoded by
+// EncodeContinuationToken.
+func DecodeContinuationToken(token string, n int) ([]string, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid continuation token: %q", token)
+	}
+	pos := strings.Split(string(buf), "\x00")
+	if len(pos) != n {
+		return nil, fmt.Errorf("invalid continuation token: %q", token)
+	}
+	return pos, nil
+}
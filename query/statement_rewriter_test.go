@@ -104,6 +104,10 @@ func TestRewriteStatement(t *testing.T) {
 			stmt: `SHOW SERIES ON db0 FROM mydb.myrp1./c.*/`,
 			s:    `SELECT "key" FROM mydb.myrp1._series WHERE _name =~ /c.*/`,
 		},
+		{
+			stmt: `SHOW SERIES FROM cpu WHERE region = 'uswest' AND after('Y3B1LGhvc3Q9YQ') LIMIT 10`,
+			s:    `SELECT "key" FROM _series WHERE (_name = 'cpu') AND (region = 'uswest') AND "key" > 'cpu,host=a' LIMIT 10`,
+		},
 		{
 			stmt: `SHOW SERIES WHERE time > 0`,
 			s:    `SELECT _seriesKey AS "key" FROM /.+/ WHERE time > 0`,
@@ -0,0 +1,756 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxql"
+)
+
+// The statements in this file are implemented by this package on top of the
+// InfluxQL grammar and are only produced by Parser.
+//
+// Each statement embeds a nil influxql.Statement since the marker methods of
+// that interface are unexported. Only String and RequiredPrivileges are ever
+// called on a statement, and both are implemented here.
+
+// SetTimeZoneStatement represents a command for setting the time zone of the
+// SELECT statements that follow it in the same query and do not have a tz()
+// clause.
+type SetTimeZoneStatement struct {
+	influxql.Statement
+
+	// Time zone to use. If nil, the default time zone of the query is used.
+	Location *time.Location
+}
+
+// String returns a string representation of the set time zone statement.
+func (s *SetTimeZoneStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("SET TIME ZONE ")
+	writeTimeZone(&buf, s.Location)
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a SetTimeZoneStatement.
+func (s *SetTimeZoneStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: false, Name: "", Privilege: influxql.NoPrivileges}}, nil
+}
+
+// SetUserTimeZoneStatement represents a command for setting the default time
+// zone of the queries that are run by a user.
+type SetUserTimeZoneStatement struct {
+	influxql.Statement
+
+	// Name of the user.
+	Name string
+
+	// Default time zone of the user. If nil, the default is UTC.
+	Location *time.Location
+}
+
+// String returns a string representation of the set user time zone statement.
+func (s *SetUserTimeZoneStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("SET TIME ZONE FOR ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Name))
+	_, _ = buf.WriteString(" = ")
+	writeTimeZone(&buf, s.Location)
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a SetUserTimeZoneStatement.
+func (s *SetUserTimeZoneStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// SetUserQueryPriorityStatement represents a command for setting the priority
+// class of the queries that are run by a user.
+type SetUserQueryPriorityStatement struct {
+	influxql.Statement
+
+	// Name of the user.
+	Name string
+
+	// Priority class of the user's queries. If empty, the queries run with
+	// the default priority.
+	Priority string
+}
+
+// String returns a string representation of the set user query priority statement.
+func (s *SetUserQueryPriorityStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("SET QUERY PRIORITY FOR ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Name))
+	_, _ = buf.WriteString(" = ")
+	if s.Priority == "" {
+		_, _ = buf.WriteString("DEFAULT")
+	} else {
+		_, _ = buf.WriteString(influxql.QuoteString(s.Priority))
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a SetUserQueryPriorityStatement.
+func (s *SetUserQueryPriorityStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// SetUserWriteTargetStatement represents a command for setting the database
+// and retention policy the writes of a user default to and are restricted to.
+type SetUserWriteTargetStatement struct {
+	influxql.Statement
+
+	// Name of the user.
+	Name string
+
+	// Database and retention policy of the write target. The restriction is
+	// removed if the database is empty.
+	Database        string
+	RetentionPolicy string
+}
+
+// String returns a string representation of the set user write target statement.
+func (s *SetUserWriteTargetStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("SET WRITE TARGET FOR ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Name))
+	_, _ = buf.WriteString(" = ")
+	if s.Database == "" {
+		_, _ = buf.WriteString("DEFAULT")
+	} else {
+		writeWriteTarget(&buf, s.Database, s.RetentionPolicy)
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a SetUserWriteTargetStatement.
+func (s *SetUserWriteTargetStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+func writeWriteTarget(buf *strings.Builder, database, retentionPolicy string) {
+	_, _ = buf.WriteString(influxql.QuoteIdent(database))
+	if retentionPolicy != "" {
+		_, _ = buf.WriteString(".")
+		_, _ = buf.WriteString(influxql.QuoteIdent(retentionPolicy))
+	}
+}
+
+// GrantMeasurementStatement represents a command for granting a privilege on
+// the measurements of a database with a name or matching a regular
+// expression.
+type GrantMeasurementStatement struct {
+	influxql.Statement
+
+	// The privilege to be granted.
+	Privilege influxql.Privilege
+
+	// Database of the measurements.
+	On string
+
+	// Name of the measurement. It is empty if Regex is set.
+	Measurement string
+
+	// Regular expression matching the names of the measurements.
+	Regex *influxql.RegexLiteral
+
+	// Who will be granted the privilege.
+	User string
+}
+
+// String returns a string representation of the grant measurement statement.
+func (s *GrantMeasurementStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("GRANT ")
+	_, _ = buf.WriteString(s.Privilege.String())
+	writePrivilegeTarget(&buf, s.On, s.Measurement, s.Regex)
+	_, _ = buf.WriteString(" TO ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.User))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a GrantMeasurementStatement.
+func (s *GrantMeasurementStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// RevokeMeasurementStatement represents a command for revoking a privilege
+// on the measurements of a database with a name or matching a regular
+// expression.
+type RevokeMeasurementStatement struct {
+	influxql.Statement
+
+	// The privilege to be revoked.
+	Privilege influxql.Privilege
+
+	// Database of the measurements.
+	On string
+
+	// Name of the measurement. It is empty if Regex is set.
+	Measurement string
+
+	// Regular expression matching the names of the measurements.
+	Regex *influxql.RegexLiteral
+
+	// Who will be revoked the privilege.
+	User string
+}
+
+// String returns a string representation of the revoke measurement statement.
+func (s *RevokeMeasurementStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("REVOKE ")
+	_, _ = buf.WriteString(s.Privilege.String())
+	writePrivilegeTarget(&buf, s.On, s.Measurement, s.Regex)
+	_, _ = buf.WriteString(" FROM ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.User))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a RevokeMeasurementStatement.
+func (s *RevokeMeasurementStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// CreateRoleStatement represents a command for creating a new role.
+type CreateRoleStatement struct {
+	influxql.Statement
+
+	// Name of the role to be created.
+	Name string
+}
+
+// String returns a string representation of the create role statement.
+func (s *CreateRoleStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("CREATE ROLE ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Name))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a CreateRoleStatement.
+func (s *CreateRoleStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// DropRoleStatement represents a command for dropping a role.
+type DropRoleStatement struct {
+	influxql.Statement
+
+	// Name of the role to drop.
+	Name string
+}
+
+// String returns a string representation of the drop role statement.
+func (s *DropRoleStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("DROP ROLE ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Name))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a DropRoleStatement.
+func (s *DropRoleStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// ShowRolesStatement represents a command for listing the roles and their
+// members.
+type ShowRolesStatement struct {
+	influxql.Statement
+}
+
+// String returns a string representation of the show roles statement.
+func (s *ShowRolesStatement) String() string {
+	return "SHOW ROLES"
+}
+
+// RequiredPrivileges returns the privilege required to execute a ShowRolesStatement.
+func (s *ShowRolesStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// ShowGrantsForRoleStatement represents a command for listing the privileges
+// of a role.
+type ShowGrantsForRoleStatement struct {
+	influxql.Statement
+
+	// Name of the role to display privileges.
+	Name string
+}
+
+// String returns a string representation of the show grants for role statement.
+func (s *ShowGrantsForRoleStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("SHOW GRANTS FOR ROLE ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Name))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a ShowGrantsForRoleStatement.
+func (s *ShowGrantsForRoleStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// GrantRoleStatement represents a command for making a user a member of a
+// role.
+type GrantRoleStatement struct {
+	influxql.Statement
+
+	// Name of the role.
+	Role string
+
+	// Who will become a member of the role.
+	User string
+}
+
+// String returns a string representation of the grant role statement.
+func (s *GrantRoleStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("GRANT ROLE ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Role))
+	_, _ = buf.WriteString(" TO ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.User))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a GrantRoleStatement.
+func (s *GrantRoleStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// RevokeRoleStatement represents a command for removing a user from the
+// members of a role.
+type RevokeRoleStatement struct {
+	influxql.Statement
+
+	// Name of the role.
+	Role string
+
+	// Who will be removed from the members of the role.
+	User string
+}
+
+// String returns a string representation of the revoke role statement.
+func (s *RevokeRoleStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("REVOKE ROLE ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Role))
+	_, _ = buf.WriteString(" FROM ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.User))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a RevokeRoleStatement.
+func (s *RevokeRoleStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// GrantRolePrivilegeStatement represents a command for granting a privilege
+// on a database to a role.
+type GrantRolePrivilegeStatement struct {
+	influxql.Statement
+
+	// The privilege to be granted.
+	Privilege influxql.Privilege
+
+	// Database to grant the privilege to.
+	On string
+
+	// Role that will be granted the privilege.
+	Role string
+}
+
+// String returns a string representation of the grant role privilege statement.
+func (s *GrantRolePrivilegeStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("GRANT ")
+	_, _ = buf.WriteString(s.Privilege.String())
+	_, _ = buf.WriteString(" ON ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.On))
+	_, _ = buf.WriteString(" TO ROLE ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Role))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a GrantRolePrivilegeStatement.
+func (s *GrantRolePrivilegeStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// RevokeRolePrivilegeStatement represents a command for revoking a privilege
+// on a database from a role.
+type RevokeRolePrivilegeStatement struct {
+	influxql.Statement
+
+	// The privilege to be revoked.
+	Privilege influxql.Privilege
+
+	// Database to revoke the privilege from.
+	On string
+
+	// Role that will be revoked the privilege.
+	Role string
+}
+
+// String returns a string representation of the revoke role privilege statement.
+func (s *RevokeRolePrivilegeStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("REVOKE ")
+	_, _ = buf.WriteString(s.Privilege.String())
+	_, _ = buf.WriteString(" ON ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.On))
+	_, _ = buf.WriteString(" FROM ROLE ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Role))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a RevokeRolePrivilegeStatement.
+func (s *RevokeRolePrivilegeStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// CreateTokenStatement represents a command for creating an API token that
+// authenticates as a user.
+type CreateTokenStatement struct {
+	influxql.Statement
+
+	// Name of the user the token authenticates as.
+	User string
+
+	// Lifetime of the token. Zero means the token does not expire.
+	Duration time.Duration
+
+	// Write target of the token. The token has the write target of its user
+	// if the database is empty.
+	Database        string
+	RetentionPolicy string
+}
+
+// String returns a string representation of the create token statement.
+func (s *CreateTokenStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("CREATE TOKEN FOR ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.User))
+	if s.Duration > 0 {
+		_, _ = buf.WriteString(" WITH DURATION ")
+		_, _ = buf.WriteString(influxql.FormatDuration(s.Duration))
+	}
+	if s.Database != "" {
+		_, _ = buf.WriteString(" WRITE TARGET ")
+		writeWriteTarget(&buf, s.Database, s.RetentionPolicy)
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a CreateTokenStatement.
+func (s *CreateTokenStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// DropTokenStatement represents a command for revoking an API token.
+type DropTokenStatement struct {
+	influxql.Statement
+
+	// ID of the token to drop.
+	ID string
+}
+
+// String returns a string representation of the drop token statement.
+func (s *DropTokenStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("DROP TOKEN ")
+	_, _ = buf.WriteString(influxql.QuoteString(s.ID))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a DropTokenStatement.
+func (s *DropTokenStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// ShowTokensStatement represents a command for listing the API tokens.
+type ShowTokensStatement struct {
+	influxql.Statement
+
+	// Name of the user to list the tokens of. All the tokens are listed if
+	// it is empty.
+	User string
+}
+
+// String returns a string representation of the show tokens statement.
+func (s *ShowTokensStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("SHOW TOKENS")
+	if s.User != "" {
+		_, _ = buf.WriteString(" FOR ")
+		_, _ = buf.WriteString(influxql.QuoteIdent(s.User))
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a ShowTokensStatement.
+func (s *ShowTokensStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// SetQuotaStatement represents a command for setting the quota of a
+// database. Only the limits that are set are changed.
+type SetQuotaStatement struct {
+	influxql.Statement
+
+	// Name of the database.
+	Database string
+
+	// Limits to set. Nil leaves a limit unchanged and zero removes it.
+	MaxSeries           *int64
+	MaxMeasurements     *int64
+	MaxWriteRate        *int64
+	MaxConcurrentWrites *int64
+}
+
+// String returns a string representation of the set quota statement.
+func (s *SetQuotaStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("SET QUOTA ON ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Database))
+	for _, limit := range []struct {
+		name string
+		n    *int64
+	}{
+		{"SERIES", s.MaxSeries},
+		{"MEASUREMENTS", s.MaxMeasurements},
+		{"WRITE RATE", s.MaxWriteRate},
+		{"CONCURRENT WRITES", s.MaxConcurrentWrites},
+	} {
+		if limit.n != nil {
+			_, _ = buf.WriteString(" MAX ")
+			_, _ = buf.WriteString(limit.name)
+			_, _ = buf.WriteString(" ")
+			_, _ = buf.WriteString(strconv.FormatInt(*limit.n, 10))
+		}
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a SetQuotaStatement.
+func (s *SetQuotaStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// DropQuotaStatement represents a command for removing the quota of a
+// database.
+type DropQuotaStatement struct {
+	influxql.Statement
+
+	// Name of the database.
+	Database string
+}
+
+// String returns a string representation of the drop quota statement.
+func (s *DropQuotaStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("DROP QUOTA ON ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.Database))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a DropQuotaStatement.
+func (s *DropQuotaStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// ShowQuotasStatement represents a command for listing the quotas of the
+// databases.
+type ShowQuotasStatement struct {
+	influxql.Statement
+}
+
+// String returns a string representation of the show quotas statement.
+func (s *ShowQuotasStatement) String() string {
+	return "SHOW QUOTAS"
+}
+
+// RequiredPrivileges returns the privilege required to execute a ShowQuotasStatement.
+func (s *ShowQuotasStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// ShowShardGroupsStatement represents a command for listing the shard groups
+// with their time bounds.
+type ShowShardGroupsStatement struct {
+	influxql.Statement
+
+	// Database and retention policy the shard groups are listed of. All
+	// the shard groups are listed if the database is empty, and all the
+	// shard groups of the database if the retention policy is empty.
+	Database        string
+	RetentionPolicy string
+}
+
+// String returns a string representation of the show shard groups statement.
+func (s *ShowShardGroupsStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("SHOW SHARD GROUPS")
+	writeShardGroupsTarget(&buf, s.Database, s.RetentionPolicy)
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a ShowShardGroupsStatement.
+func (s *ShowShardGroupsStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// CreateShardGroupStatement represents a command for creating the shard
+// group that contains a time ahead of the writes.
+type CreateShardGroupStatement struct {
+	influxql.Statement
+
+	// Database and retention policy of the shard group. The default
+	// retention policy of the database is used if it is empty.
+	Database        string
+	RetentionPolicy string
+
+	// Time the shard group contains. Zero means the current time.
+	Time time.Time
+}
+
+// String returns a string representation of the create shard group statement.
+func (s *CreateShardGroupStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("CREATE SHARD GROUP")
+	writeShardGroupsTarget(&buf, s.Database, s.RetentionPolicy)
+	writeShardGroupsTime(&buf, s.Time)
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a CreateShardGroupStatement.
+func (s *CreateShardGroupStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// TruncateShardGroupsStatement represents a command for truncating the shard
+// groups that could contain times after a time, so the points written after
+// it go to new shard groups.
+type TruncateShardGroupsStatement struct {
+	influxql.Statement
+
+	// Database and retention policy the shard groups are truncated of. All
+	// the shard groups are truncated if the database is empty, and all the
+	// shard groups of the database if the retention policy is empty.
+	Database        string
+	RetentionPolicy string
+
+	// Time the shard groups are truncated at. Zero means the current time.
+	Time time.Time
+}
+
+// String returns a string representation of the truncate shard groups statement.
+func (s *TruncateShardGroupsStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("TRUNCATE SHARD GROUPS")
+	writeShardGroupsTarget(&buf, s.Database, s.RetentionPolicy)
+	writeShardGroupsTime(&buf, s.Time)
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a TruncateShardGroupsStatement.
+func (s *TruncateShardGroupsStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// RenameDatabaseStatement represents a command for renaming a database.
+type RenameDatabaseStatement struct {
+	influxql.Statement
+
+	OldName string
+	NewName string
+}
+
+// String returns a string representation of the rename database statement.
+func (s *RenameDatabaseStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("RENAME DATABASE ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.OldName))
+	_, _ = buf.WriteString(" TO ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.NewName))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a RenameDatabaseStatement.
+func (s *RenameDatabaseStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+// RenameMeasurementStatement represents a command for renaming a
+// measurement.
+type RenameMeasurementStatement struct {
+	influxql.Statement
+
+	// Database of the measurement. The database of the query is used if it
+	// is empty.
+	Database string
+
+	OldName string
+	NewName string
+}
+
+// String returns a string representation of the rename measurement statement.
+func (s *RenameMeasurementStatement) String() string {
+	var buf strings.Builder
+	_, _ = buf.WriteString("RENAME MEASUREMENT ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.OldName))
+	_, _ = buf.WriteString(" TO ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.NewName))
+	if s.Database != "" {
+		_, _ = buf.WriteString(" ON ")
+		_, _ = buf.WriteString(influxql.QuoteIdent(s.Database))
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a RenameMeasurementStatement.
+func (s *RenameMeasurementStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}, nil
+}
+
+func writePrivilegeTarget(buf *strings.Builder, on, measurement string, re *influxql.RegexLiteral) {
+	_, _ = buf.WriteString(" ON ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(on))
+	_, _ = buf.WriteString(" MEASUREMENT ")
+	if re != nil {
+		_, _ = buf.WriteString(re.String())
+	} else {
+		_, _ = buf.WriteString(influxql.QuoteIdent(measurement))
+	}
+}
+
+func writeTimeZone(buf *strings.Builder, loc *time.Location) {
+	if loc == nil {
+		_, _ = buf.WriteString("DEFAULT")
+		return
+	}
+	_, _ = buf.WriteString(influxql.QuoteString(loc.String()))
+}
+
+func writeShardGroupsTarget(buf *strings.Builder, database, retentionPolicy string) {
+	if database == "" {
+		return
+	}
+	_, _ = buf.WriteString(" ON ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(database))
+	if retentionPolicy != "" {
+		_, _ = buf.WriteString(".")
+		_, _ = buf.WriteString(influxql.QuoteIdent(retentionPolicy))
+	}
+}
+
+func writeShardGroupsTime(buf *strings.Builder, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	_, _ = buf.WriteString(" AT ")
+	_, _ = buf.WriteString(influxql.QuoteString(t.UTC().Format(time.RFC3339Nano)))
+}
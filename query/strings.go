@@ -0,0 +1,205 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/influxql"
+)
+
+func isStringFunction(call *influxql.Call) bool {
+	switch call.Name {
+	case "match", "replace", "substr", "lower", "upper":
+		return true
+	}
+	return false
+}
+
+// compileStringPatterns replaces the string patterns passed to match() and
+// replace() with regular expressions so they are only compiled once.
+func compileStringPatterns(expr influxql.Expr) (influxql.Expr, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	var err error
+	expr = influxql.RewriteExpr(expr, func(expr influxql.Expr) influxql.Expr {
+		call, ok := expr.(*influxql.Call)
+		if !ok || (call.Name != "match" && call.Name != "replace") || len(call.Args) < 2 {
+			return expr
+		}
+		lit, ok := call.Args[1].(*influxql.StringLiteral)
+		if !ok {
+			return expr
+		}
+
+		re, e := regexp.Compile(lit.Val)
+		if e != nil {
+			if err == nil {
+				err = fmt.Errorf("invalid pattern in %s(): %s", call.Name, e)
+			}
+			return expr
+		}
+		args := make([]influxql.Expr, len(call.Args))
+		copy(args, call.Args)
+		args[1] = &influxql.RegexLiteral{Val: re}
+		return &influxql.Call{Name: call.Name, Args: args}
+	})
+	return expr, err
+}
+
+// rewriteMatchConditions compares the calls to match() that are used as the
+// operands of AND and OR in a condition to true because a call cannot be a
+// condition by itself.
+func rewriteMatchConditions(expr influxql.Expr) influxql.Expr {
+	switch e := expr.(type) {
+	case *influxql.BinaryExpr:
+		if e.Op == influxql.AND || e.Op == influxql.OR {
+			e.LHS = rewriteMatchConditions(e.LHS)
+			e.RHS = rewriteMatchConditions(e.RHS)
+		}
+	case *influxql.ParenExpr:
+		e.Expr = rewriteMatchConditions(e.Expr)
+	case *influxql.Call:
+		if e.Name == "match" {
+			return &influxql.BinaryExpr{
+				Op:  influxql.EQ,
+				LHS: e,
+				RHS: &influxql.BooleanLiteral{Val: true},
+			}
+		}
+	default:
+	}
+	return expr
+}
+
+type StringTypeMapper struct{}
+
+func (StringTypeMapper) MapType(measurement *influxql.Measurement, field string) influxql.DataType {
+	return influxql.Unknown
+}
+
+func (StringTypeMapper) CallType(name string, args []influxql.DataType) (influxql.DataType, error) {
+	switch name {
+	case "match", "replace", "substr", "lower", "upper":
+		var arg0 influxql.DataType
+		if len(args) > 0 {
+			arg0 = args[0]
+		}
+		switch arg0 {
+		case influxql.String, influxql.Tag, influxql.Unknown:
+		default:
+			return influxql.Unknown, fmt.Errorf("invalid argument type for the first argument in %s(): %s", name, arg0)
+		}
+
+		switch name {
+		case "match":
+			return influxql.Boolean, nil
+		case "substr":
+			for i := 1; i < len(args); i++ {
+				switch args[i] {
+				case influxql.Integer, influxql.Unknown:
+				default:
+					return influxql.Unknown, fmt.Errorf("invalid argument type for argument %d in %s(): %s", i+1, name, args[i])
+				}
+			}
+		default:
+		}
+		return influxql.String, nil
+	}
+	return influxql.Unknown, nil
+}
+
+type StringValuer struct{}
+
+var _ influxql.CallValuer = StringValuer{}
+
+func (StringValuer) Value(key string) (interface{}, bool) {
+	return nil, false
+}
+
+func (v StringValuer) Call(name string, args []interface{}) (interface{}, bool) {
+	if !isStringFunction(&influxql.Call{Name: name}) {
+		return nil, false
+	} else if len(args) == 0 {
+		return nil, true
+	}
+
+	arg0, ok := args[0].(string)
+	if !ok {
+		return nil, true
+	}
+	switch name {
+	case "match":
+		if len(args) == 2 {
+			if re := asRegexp(args[1]); re != nil {
+				return re.MatchString(arg0), true
+			}
+		}
+	case "replace":
+		if len(args) == 3 {
+			repl, ok := args[2].(string)
+			if re := asRegexp(args[1]); re != nil && ok {
+				return re.ReplaceAllString(arg0, repl), true
+			}
+		}
+	case "substr":
+		if len(args) == 2 || len(args) == 3 {
+			return substr(arg0, args[1:]), true
+		}
+	case "lower":
+		if len(args) == 1 {
+			return strings.ToLower(arg0), true
+		}
+	case "upper":
+		if len(args) == 1 {
+			return strings.ToUpper(arg0), true
+		}
+	default:
+	}
+	return nil, true
+}
+
+// asRegexp returns the regular expression of a pattern. Patterns that have
+// not been compiled by compileStringPatterns are compiled on each call.
+func asRegexp(x interface{}) *regexp.Regexp {
+	switch x := x.(type) {
+	case *regexp.Regexp:
+		return x
+	case string:
+		re, _ := regexp.Compile(x)
+		return re
+	default:
+		return nil
+	}
+}
+
+// substr returns the characters of s that start at the zero-based offset
+// and span the optional length. A negative offset counts from the end of
+// the string. Nil is returned if the arguments are not integers.
+func substr(s string, args []interface{}) interface{} {
+	runes := []rune(s)
+	start, ok := args[0].(int64)
+	if !ok {
+		return nil
+	} else if start < 0 {
+		start += int64(len(runes))
+		if start < 0 {
+			start = 0
+		}
+	} else if start > int64(len(runes)) {
+		start = int64(len(runes))
+	}
+
+	end := int64(len(runes))
+	if len(args) > 1 {
+		n, ok := args[1].(int64)
+		if !ok || n < 0 {
+			return nil
+		} else if start+n < end {
+			end = start + n
+		}
+	}
+	return string(runes[start:end])
+}
@@ -0,0 +1,86 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+func TestString_TypeMapper(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		typ influxql.DataType
+		err bool
+	}{
+		{s: `match(s::string, 'a')`, typ: influxql.Boolean},
+		{s: `match(t::tag, 'a')`, typ: influxql.Boolean},
+		{s: `match(f::float, 'a')`, err: true},
+		{s: `replace(s::string, 'a', 'b')`, typ: influxql.String},
+		{s: `replace(i::integer, 'a', 'b')`, err: true},
+		{s: `substr(s::string, 1)`, typ: influxql.String},
+		{s: `substr(s::string, 1, 2)`, typ: influxql.String},
+		{s: `substr(s::string, 1.5)`, err: true},
+		{s: `substr(b::boolean, 1)`, err: true},
+		{s: `lower(s::string)`, typ: influxql.String},
+		{s: `lower(u::unsigned)`, err: true},
+		{s: `upper(s::string)`, typ: influxql.String},
+		{s: `upper(b::boolean)`, err: true},
+	} {
+		t.Run(tt.s, func(t *testing.T) {
+			expr := MustParseExpr(tt.s)
+
+			typmap := influxql.TypeValuerEval{
+				TypeMapper: query.StringTypeMapper{},
+			}
+			if got, err := typmap.EvalType(expr); err != nil {
+				if !tt.err {
+					t.Errorf("unexpected error: %s", err)
+				}
+			} else if tt.err {
+				t.Error("expected error")
+			} else if want := tt.typ; got != want {
+				t.Errorf("unexpected type:\n\t-: \"%s\"\n\t+: \"%s\"", want, got)
+			}
+		})
+	}
+}
+
+func TestStringValuer_Call(t *testing.T) {
+	type values map[string]interface{}
+	for _, tt := range []struct {
+		s      string
+		values values
+		exp    interface{}
+	}{
+		{s: `match(s, '^con')`, values: values{"s": "connection refused"}, exp: true},
+		{s: `match(s, '^con')`, values: values{"s": "request served"}, exp: false},
+		{s: `match(s, '^con')`, values: values{"s": int64(2)}, exp: nil},
+		{s: `replace(s, 'o+', '0')`, values: values{"s": "foo bar"}, exp: "f0 bar"},
+		{s: `replace(s, '(\\w+) (\\w+)', '$2 $1')`, values: values{"s": "foo bar"}, exp: "bar foo"},
+		{s: `substr(s, 1)`, values: values{"s": "héllo"}, exp: "éllo"},
+		{s: `substr(s, 1, 3)`, values: values{"s": "héllo"}, exp: "éll"},
+		{s: `substr(s, 1, 10)`, values: values{"s": "héllo"}, exp: "éllo"},
+		{s: `substr(s, -2)`, values: values{"s": "héllo"}, exp: "lo"},
+		{s: `substr(s, -10, 2)`, values: values{"s": "héllo"}, exp: "hé"},
+		{s: `substr(s, 10)`, values: values{"s": "héllo"}, exp: ""},
+		{s: `substr(s, 1, -1)`, values: values{"s": "héllo"}, exp: nil},
+		{s: `lower(s)`, values: values{"s": "Hello"}, exp: "hello"},
+		{s: `upper(s)`, values: values{"s": "Hello"}, exp: "HELLO"},
+		{s: `upper(f)`, values: values{"f": float64(1)}, exp: nil},
+	} {
+		t.Run(tt.s, func(t *testing.T) {
+			expr := MustParseExpr(tt.s)
+
+			valuer := influxql.ValuerEval{
+				Valuer: influxql.MultiValuer(
+					influxql.MapValuer(tt.values),
+					query.StringValuer{},
+				),
+			}
+			if got, want := valuer.Eval(expr), tt.exp; got != want {
+				t.Errorf("unexpected value: %v != %v", want, got)
+			}
+		})
+	}
+}
@@ -78,12 +78,29 @@ type TaskManager struct {
 	// Maximum number of concurrent queries.
 	MaxConcurrentQueries int
 
+	// Policies limit the resources of queries. The first policy matching
+	// the user and database of a query applies to it.
+	Policies []QueryPolicy
+
+	// OnPolicyViolation is called for each query that is killed because
+	// it exceeded a limit of its policy.
+	OnPolicyViolation func(KilledQuery)
+
+	// PriorityClasses limit the queries of each priority class. The
+	// queries of a class without limits run as soon as they are attached.
+	PriorityClasses map[string]PriorityClass
+
+	// DefaultPriority is the priority class of the queries that don't
+	// select one.
+	DefaultPriority string
+
 	// Logger to use for all logging.
 	// Defaults to discarding all log output.
 	Logger *zap.Logger
 
 	// Used for managing and tracking running queries.
 	queries  map[uint64]*Task
+	pools    map[string]*priorityPool
 	nextID   uint64
 	mu       sync.RWMutex
 	shutdown bool
@@ -200,7 +217,18 @@ func (t *TaskManager) AttachQuery(q *influxql.Query, opt ExecutionOptions, inter
 		return nil, nil, ErrQueryEngineShutdown
 	}
 
+	// Wait for a slot of the priority class of the query.
+	pool := t.pool(opt.Priority)
+	if pool != nil {
+		if err := t.acquire(pool, opt, interrupt); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if t.MaxConcurrentQueries > 0 && len(t.queries) >= t.MaxConcurrentQueries {
+		if pool != nil {
+			pool.release()
+		}
 		return nil, nil, ErrMaxConcurrentQueriesLimitExceeded(len(t.queries), t.MaxConcurrentQueries)
 	}
 
@@ -208,6 +236,9 @@ func (t *TaskManager) AttachQuery(q *influxql.Query, opt ExecutionOptions, inter
 	query := &Task{
 		query:     q.String(),
 		database:  opt.Database,
+		user:      opt.User,
+		policy:    t.policy(opt.User, opt.Database),
+		pool:      pool,
 		status:    RunningTask,
 		startTime: time.Now(),
 		closing:   make(chan struct{}),
@@ -230,6 +261,22 @@ func (t *TaskManager) AttachQuery(q *influxql.Query, opt ExecutionOptions, inter
 			return nil
 		})
 	}
+	if p := query.policy; p != nil && p.MaxRuntime > 0 {
+		go query.monitor(func(closing <-chan struct{}) error {
+			timer := time.NewTimer(p.MaxRuntime)
+			defer timer.Stop()
+
+			select {
+			case <-timer.C:
+				return &PolicyViolationError{
+					Policy: p.Name,
+					Reason: fmt.Sprintf("max-runtime limit exceeded: (%s)", p.MaxRuntime),
+				}
+			case <-closing:
+				return nil
+			}
+		})
+	}
 	t.nextID++
 
 	ctx := &ExecutionContext{
@@ -242,6 +289,16 @@ func (t *TaskManager) AttachQuery(q *influxql.Query, opt ExecutionOptions, inter
 	return ctx, func() { t.DetachQuery(qid) }, nil
 }
 
+// policy returns the first policy matching the user and database.
+func (t *TaskManager) policy(user, database string) *QueryPolicy {
+	for i := range t.Policies {
+		if t.Policies[i].Matches(user, database) {
+			return &t.Policies[i]
+		}
+	}
+	return nil
+}
+
 // KillQuery enters a query into the killed state and closes the channel
 // from the TaskManager. This method can be used to forcefully terminate a
 // running query.
@@ -269,6 +326,9 @@ func (t *TaskManager) DetachQuery(qid uint64) error {
 
 	query.close()
 	delete(t.queries, qid)
+	if query.pool != nil {
+		query.pool.release()
+	}
 	return nil
 }
 
@@ -316,6 +376,9 @@ func (t *TaskManager) waitForQuery(qid uint64, interrupt <-chan struct{}, closin
 			break
 		}
 
+		if perr, ok := err.(*PolicyViolationError); ok {
+			t.policyViolation(qid, perr)
+		}
 		t.queryError(qid, err)
 	case <-timerCh:
 		if t.LogTimedoutQueries {
@@ -334,6 +397,35 @@ func (t *TaskManager) waitForQuery(qid uint64, interrupt <-chan struct{}, closin
 	t.KillQuery(qid)
 }
 
+// policyViolation logs and reports a query that is killed by its policy.
+func (t *TaskManager) policyViolation(qid uint64, err *PolicyViolationError) {
+	t.mu.RLock()
+	query := t.queries[qid]
+	t.mu.RUnlock()
+	if query == nil {
+		return
+	}
+
+	t.Logger.Warn("Query killed by query policy",
+		zap.Uint64("qid", qid),
+		zap.String("query", query.query),
+		zap.String("database", query.database),
+		zap.String("policy", err.Policy),
+		zap.String("reason", err.Reason),
+	)
+	if t.OnPolicyViolation != nil {
+		t.OnPolicyViolation(KilledQuery{
+			ID:       qid,
+			Query:    query.query,
+			Database: query.database,
+			User:     query.user,
+			Policy:   err.Policy,
+			Reason:   err.Reason,
+			Duration: time.Since(query.startTime),
+		})
+	}
+}
+
 // Close kills all running queries and prevents new queries from being attached.
 func (t *TaskManager) Close() error {
 	t.mu.Lock()
@@ -345,5 +437,13 @@ func (t *TaskManager) Close() error {
 		query.close()
 	}
 	t.queries = nil
+
+	// Wake up the queued queries so they see the shutdown.
+	for _, p := range t.pools {
+		for _, ch := range p.waiters {
+			close(ch)
+		}
+		p.waiters = nil
+	}
 	return nil
 }
@@ -0,0 +1,128 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxql"
+)
+
+// UserFunction is a function defined by a user that can be called in the
+// fields of a SELECT statement. It is called once for every row.
+type UserFunction interface {
+	// CallType returns the type of the result for the types of the
+	// arguments. An error is returned if the arguments are not valid.
+	CallType(args []influxql.DataType) (influxql.DataType, error)
+
+	// Call calls the function with the values of the arguments. The
+	// arguments are nil for the fields without a value.
+	Call(args []interface{}) (interface{}, error)
+}
+
+// UserFunctions looks up the user-defined functions by their name.
+type UserFunctions interface {
+	// UserFunction returns the function with the name or nil if no such
+	// function exists.
+	UserFunction(name string) UserFunction
+}
+
+// builtinFunctions are the names of the functions implemented by the query
+// engine. User-defined functions cannot use these names.
+var builtinFunctions = map[string]struct{}{}
+
+func init() {
+	for _, name := range []string{
+		// Aggregates, selectors and transformations.
+		"count", "distinct", "integral", "mean", "median", "mode", "spread", "stddev", "sum",
+		"bottom", "first", "last", "max", "min", "percentile", "sample", "top",
+		"cumulative_sum", "derivative", "difference", "elapsed", "moving_average",
+		"non_negative_derivative", "non_negative_difference", "holt_winters", "holt_winters_with_fit",
+		"exponential_moving_average", "double_exponential_moving_average", "triple_exponential_moving_average",
+		"relative_strength_index", "triple_exponential_derivative",
+		"kaufmans_efficiency_ratio", "kaufmans_adaptive_moving_average", "chande_momentum_oscillator",
+		"count_hll", "sum_hll", "histogram_quantile", "histogram_sum",
+		// Math functions.
+		"abs", "sin", "cos", "tan", "asin", "acos", "atan", "atan2", "exp", "log", "ln", "log2", "log10",
+		"sqrt", "pow", "floor", "ceil", "round",
+		// String functions.
+		"match", "replace", "substr", "lower", "upper",
+		// Functions of conditions and clauses lowered by the parser.
+		"now", "time", "having", "fill", "asof_join",
+	} {
+		builtinFunctions[name] = struct{}{}
+	}
+}
+
+// IsBuiltinFunction returns true if the function is implemented by the query
+// engine.
+func IsBuiltinFunction(name string) bool {
+	_, ok := builtinFunctions[name]
+	return ok
+}
+
+// lookupUserFunction returns the user-defined function of the call.
+func lookupUserFunction(functions UserFunctions, call *influxql.Call) UserFunction {
+	if functions == nil || IsBuiltinFunction(call.Name) {
+		return nil
+	}
+	return functions.UserFunction(call.Name)
+}
+
+func (c *compiledField) compileUserFunction(expr *influxql.Call) error {
+	// Compile all the argument expressions that are not just literals.
+	for _, arg := range expr.Args {
+		if _, ok := arg.(influxql.Literal); ok {
+			continue
+		}
+		if err := c.compileExpr(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UserFunctionTypeMapper maps the types of the calls to user-defined
+// functions.
+type UserFunctionTypeMapper struct {
+	Functions UserFunctions
+}
+
+func (UserFunctionTypeMapper) MapType(measurement *influxql.Measurement, field string) influxql.DataType {
+	return influxql.Unknown
+}
+
+func (m UserFunctionTypeMapper) CallType(name string, args []influxql.DataType) (influxql.DataType, error) {
+	fn := lookupUserFunction(m.Functions, &influxql.Call{Name: name})
+	if fn == nil {
+		return influxql.Unknown, nil
+	}
+	return fn.CallType(args)
+}
+
+// userFunctionValuer calls the user-defined functions. The first error
+// returned by a function is recorded and the calls after it return nil.
+type userFunctionValuer struct {
+	functions UserFunctions
+	err       error
+}
+
+var _ influxql.CallValuer = (*userFunctionValuer)(nil)
+
+func (*userFunctionValuer) Value(key string) (interface{}, bool) {
+	return nil, false
+}
+
+func (v *userFunctionValuer) Call(name string, args []interface{}) (interface{}, bool) {
+	fn := lookupUserFunction(v.functions, &influxql.Call{Name: name})
+	if fn == nil {
+		return nil, false
+	} else if v.err != nil {
+		return nil, true
+	}
+
+	value, err := fn.Call(args)
+	if err != nil {
+		v.err = fmt.Errorf("error calling %s(): %s", name, err)
+		return nil, true
+	}
+	return value, true
+}
@@ -0,0 +1,158 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+// UserFunctions is a set of user-defined functions for tests.
+type UserFunctions map[string]*UserFunction
+
+func (m UserFunctions) UserFunction(name string) query.UserFunction {
+	if fn, ok := m[name]; ok {
+		return fn
+	}
+	return nil
+}
+
+// UserFunction is a user-defined function with a float argument.
+type UserFunction struct {
+	Fn func(v float64) (float64, error)
+}
+
+func (fn *UserFunction) CallType(args []influxql.DataType) (influxql.DataType, error) {
+	if len(args) != 1 {
+		return influxql.Unknown, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+	switch args[0] {
+	case influxql.Float, influxql.Integer, influxql.Unknown:
+		return influxql.Float, nil
+	default:
+		return influxql.Unknown, fmt.Errorf("invalid argument type: %s", args[0])
+	}
+}
+
+func (fn *UserFunction) Call(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return fn.Fn(v)
+	case int64:
+		return fn.Fn(float64(v))
+	default:
+		return nil, nil
+	}
+}
+
+func TestSelect_UserFunction(t *testing.T) {
+	functions := UserFunctions{
+		"double": {Fn: func(v float64) (float64, error) { return v * 2, nil }},
+		"fail": {Fn: func(v float64) (float64, error) {
+			if v > 15 {
+				return 0, errors.New("too large")
+			}
+			return v, nil
+		}},
+		// A user-defined function cannot replace a built-in function.
+		"abs": {Fn: func(v float64) (float64, error) { return 0, nil }},
+	}
+	shardMapper := ShardMapper{
+		MapShardsFn: func(sources influxql.Sources, _ influxql.TimeRange) query.ShardGroup {
+			return &ShardGroup{
+				Fields: map[string]influxql.DataType{
+					"f": influxql.Float,
+					"s": influxql.String,
+				},
+				CreateIteratorFn: func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+					if opt.Expr != nil {
+						return &FloatIterator{Points: []query.FloatPoint{
+							{Name: "cpu", Time: 0 * Second, Value: 15},
+						}}, nil
+					}
+					return &FloatIterator{Points: []query.FloatPoint{
+						{Name: "cpu", Time: 0 * Second, Aux: []interface{}{float64(-10)}},
+						{Name: "cpu", Time: 5 * Second, Aux: []interface{}{float64(20)}},
+					}}, nil
+				},
+			}
+		},
+	}
+
+	for _, tt := range []struct {
+		name    string
+		q       string
+		rows    []query.Row
+		err     string
+		scanErr string
+	}{
+		{
+			name: "Raw",
+			q:    `SELECT double(f) + 1, abs(f) FROM cpu`,
+			rows: []query.Row{
+				{Time: 0 * Second, Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(-19), float64(10)}},
+				{Time: 5 * Second, Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(41), float64(20)}},
+			},
+		},
+		{
+			name: "Aggregate",
+			q:    `SELECT double(mean(f)) FROM cpu`,
+			rows: []query.Row{
+				{Time: 0 * Second, Series: query.Series{Name: "cpu"}, Values: []interface{}{float64(30)}},
+			},
+		},
+		{
+			name:    "CallError",
+			q:       `SELECT fail(f) FROM cpu`,
+			scanErr: "error calling fail(): too large",
+		},
+		{
+			name: "InvalidArgumentType",
+			q:    `SELECT double(s) FROM cpu`,
+			err:  "invalid argument type: string",
+		},
+		{
+			name: "Condition",
+			q:    `SELECT f FROM cpu WHERE double(f) > 0`,
+			err:  "invalid function call in condition: double(f)",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := MustParseSelectStatement(tt.q)
+			stmt.OmitTime = true
+			cur, err := query.Select(context.Background(), stmt, &shardMapper, query.SelectOptions{UserFunctions: functions})
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("unexpected error: exp=%s got=%v", tt.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			a, err := ReadCursor(cur)
+			if tt.scanErr != "" {
+				if err == nil || err.Error() != tt.scanErr {
+					t.Fatalf("unexpected error: exp=%s got=%v", tt.scanErr, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.rows, a); diff != "" {
+				t.Fatalf("unexpected points:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelect_UserFunction_Undefined(t *testing.T) {
+	stmt := MustParseSelectStatement(`SELECT double(f) FROM cpu`)
+	if _, err := query.Compile(stmt, query.CompileOptions{}); err == nil || err.Error() != "undefined function double()" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
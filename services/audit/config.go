@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"errors"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+)
+
+// DefaultPath is the default path of the audit log file.
+const DefaultPath = "/var/log/influxdb/audit.log"
+
+// Config represents the configuration of the audit trail.
+type Config struct {
+	Enabled bool `toml:"enabled"`
+
+	// Path of the append-only file the events are written to. No file is
+	// written if it is empty.
+	Path string `toml:"path"`
+
+	// StoreEnabled writes the events to the audit measurement of the
+	// monitor database. It requires the monitor store to be enabled.
+	StoreEnabled bool `toml:"store-enabled"`
+}
+
+// NewConfig returns a new Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Enabled:      false,
+		Path:         DefaultPath,
+		StoreEnabled: true,
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Path == "" && !c.StoreEnabled {
+		return errors.New("path must be specified if store-enabled is false")
+	}
+	return nil
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Enabled {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+		}), nil
+	}
+
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":       true,
+		"path":          c.Path,
+		"store-enabled": c.StoreEnabled,
+	}), nil
+}
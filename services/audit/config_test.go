@@ -0,0 +1,47 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/services/audit"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c audit.Config
+	if _, err := toml.Decode(`
+enabled = true
+path = "/tmp/audit.log"
+store-enabled = false
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled state: %v", c.Enabled)
+	} else if c.Path != "/tmp/audit.log" {
+		t.Fatalf("unexpected path: %s", c.Path)
+	} else if c.StoreEnabled {
+		t.Fatalf("unexpected store enabled state: %v", c.StoreEnabled)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := audit.NewConfig()
+	c.Enabled = true
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.Path = ""
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.StoreEnabled = false
+	if err := c.Validate(); err == nil || err.Error() != "path must be specified if store-enabled is false" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+// Package audit provides the service that records the administrative
+// changes, such as the creation of users or the dropping of databases.
+package audit // import "github.com/influxdata/influxdb/services/audit"
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"go.uber.org/zap"
+)
+
+// Measurement is the name of the measurement of the monitor database the
+// events are written to.
+const Measurement = "audit"
+
+// Event is an administrative change.
+type Event struct {
+	Time time.Time `json:"time"`
+
+	// Name of the user that made the change. It is empty if authentication
+	// is disabled.
+	User string `json:"user,omitempty"`
+
+	// Address of the client that made the change, if known.
+	Source string `json:"source,omitempty"`
+
+	// Action is the kind of change, like DROP DATABASE.
+	Action string `json:"action"`
+
+	// Database that is changed, if any.
+	Database string `json:"database,omitempty"`
+
+	// Statement that made the change, with the passwords redacted. It is
+	// the HTTP request for the changes made with the bucket API.
+	Statement string `json:"statement,omitempty"`
+}
+
+// point returns the point of the event in the audit measurement.
+func (e *Event) point() (models.Point, error) {
+	tags := map[string]string{"action": e.Action}
+	if e.User != "" {
+		tags["user"] = e.User
+	}
+	if e.Database != "" {
+		tags["database"] = e.Database
+	}
+	fields := map[string]interface{}{
+		"source":    e.Source,
+		"statement": e.Statement,
+	}
+	return models.NewPoint(Measurement, models.NewTags(tags), fields, e.Time)
+}
+
+// Service records the events to an append-only file and to the monitor
+// database.
+type Service struct {
+	config Config
+
+	mu   sync.Mutex
+	file *os.File
+
+	// Monitor stores the events in the monitor database.
+	Monitor interface {
+		WritePoints(models.Points) error
+	}
+
+	Logger *zap.Logger
+
+	now func() time.Time
+}
+
+// NewService returns an instance of the audit service.
+func NewService(c Config) *Service {
+	return &Service{
+		config: c,
+		Logger: zap.NewNop(),
+		now:    time.Now,
+	}
+}
+
+// WithLogger sets the logger for the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "audit"))
+}
+
+// Open opens the audit log file.
+func (s *Service) Open() error {
+	if !s.config.Enabled || s.config.Path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.config.Path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	s.Logger.Info("Recording administrative changes", zap.String("path", s.config.Path))
+
+	s.mu.Lock()
+	s.file = f
+	s.mu.Unlock()
+	return nil
+}
+
+// Close closes the audit log file.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Record records an event. The time of the event is set if it is zero.
+// Errors are logged rather than returned since the change was already made.
+func (s *Service) Record(e Event) {
+	if !s.config.Enabled {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = s.now()
+	}
+	e.Time = e.Time.UTC()
+
+	s.mu.Lock()
+	if s.file != nil {
+		b, err := json.Marshal(e)
+		if err == nil {
+			_, err = s.file.Write(append(b, '\n'))
+		}
+		if err != nil {
+			s.Logger.Info("Failed to write to the audit log", zap.String("action", e.Action), zap.Error(err))
+		}
+	}
+	s.mu.Unlock()
+
+	if !s.config.StoreEnabled || s.Monitor == nil {
+		return
+	}
+	p, err := e.point()
+	if err == nil {
+		err = s.Monitor.WritePoints(models.Points{p})
+	}
+	if err != nil {
+		s.Logger.Info("Failed to store the audit event", zap.String("action", e.Action), zap.Error(err))
+	}
+}
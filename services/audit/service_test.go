@@ -0,0 +1,104 @@
+package audit_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/audit"
+)
+
+type monitor struct {
+	points models.Points
+}
+
+func (m *monitor) WritePoints(p models.Points) error {
+	m.points = append(m.points, p...)
+	return nil
+}
+
+func TestService_Record(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log", "audit.log")
+	c := audit.NewConfig()
+	c.Enabled = true
+	c.Path = path
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := []audit.Event{
+		{Time: now, User: "admin", Source: "127.0.0.1:5000", Action: "DROP DATABASE", Database: "db0", Statement: "DROP DATABASE db0"},
+		{Time: now.Add(time.Second), Action: "CREATE USER", Statement: "CREATE USER bob WITH PASSWORD [REDACTED]"},
+	}
+
+	// The events are appended to the file when the service is reopened.
+	var m monitor
+	for _, e := range events {
+		s := audit.NewService(c)
+		s.Monitor = &m
+		if err := s.Open(); err != nil {
+			t.Fatal(err)
+		}
+		s.Record(e)
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var got []audit.Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e audit.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	} else if len(got) != len(events) {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	for i := range events {
+		if got[i] != events[i] {
+			t.Fatalf("unexpected event %d: got %v, exp %v", i, got[i], events[i])
+		}
+	}
+
+	// The events are stored in the audit measurement.
+	if len(m.points) != 2 {
+		t.Fatalf("unexpected points: %v", m.points)
+	} else if got, exp := m.points[0].String(), `audit,action=DROP\ DATABASE,database=db0,user=admin source="127.0.0.1:5000",statement="DROP DATABASE db0" 1577934245000000000`; got != exp {
+		t.Fatalf("unexpected point:\ngot: %s\nexp: %s", got, exp)
+	} else if got, exp := m.points[1].String(), `audit,action=CREATE\ USER source="",statement="CREATE USER bob WITH PASSWORD [REDACTED]" 1577934246000000000`; got != exp {
+		t.Fatalf("unexpected point:\ngot: %s\nexp: %s", got, exp)
+	}
+}
+
+func TestService_Record_Disabled(t *testing.T) {
+	c := audit.NewConfig()
+	c.Path = filepath.Join(t.TempDir(), "audit.log")
+
+	var m monitor
+	s := audit.NewService(c)
+	s.Monitor = &m
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Record(audit.Event{Action: "DROP DATABASE", Database: "db0"})
+	if _, err := os.Stat(c.Path); !os.IsNotExist(err) {
+		t.Fatalf("unexpected audit log: %v", err)
+	} else if len(m.points) != 0 {
+		t.Fatalf("unexpected points: %v", m.points)
+	}
+}
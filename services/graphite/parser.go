@@ -125,11 +125,8 @@ func (p *Parser) Parse(line string) (models.Point, error) {
 		return nil, &UnsupportedValueError{Field: fields[0], Value: v}
 	}
 
-	fieldValues := map[string]interface{}{}
-	if field != "" {
-		fieldValues[field] = v
-	} else {
-		fieldValues["value"] = v
+	if field == "" {
+		field = "value"
 	}
 
 	// If no 3rd field, use now as timestamp
@@ -153,13 +150,21 @@ func (p *Parser) Parse(line string) (models.Point, error) {
 		}
 	}
 
+	b := models.GetPointBuilder()
+	defer models.PutPointBuilder(b)
+	b.SetName(measurement)
+	for k, v := range tags {
+		b.AddTag(k, v)
+	}
 	// Set the default tags on the point if they are not already set
 	for _, t := range p.tags {
 		if _, ok := tags[string(t.Key)]; !ok {
-			tags[string(t.Key)] = string(t.Value)
+			b.AddTag(string(t.Key), string(t.Value))
 		}
 	}
-	return models.NewPoint(measurement, models.NewTags(tags), fieldValues, timestamp)
+	b.AddFloatField(field, v)
+	b.SetTime(timestamp)
+	return b.Point()
 }
 
 // ApplyTemplate extracts the template fields from the given line and
@@ -38,6 +38,7 @@ type Config struct {
 	LogEnabled              bool              `toml:"log-enabled"`
 	SuppressWriteLog        bool              `toml:"suppress-write-log"`
 	WriteTracing            bool              `toml:"write-tracing"`
+	StrictLineProtocol      bool              `toml:"strict-line-protocol"`
 	FluxEnabled             bool              `toml:"flux-enabled"`
 	FluxLogEnabled          bool              `toml:"flux-log-enabled"`
 	FluxTesting             bool              `toml:"flux-testing"`
@@ -0,0 +1,119 @@
+package httpd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+	"time"
+
+	"github.com/influxdata/influxdb/services/meta"
+	"go.uber.org/zap"
+)
+
+// maxDumpCPUDuration is the maximum time the CPU profile of a debug dump is
+// collected for.
+const maxDumpCPUDuration = 5 * time.Minute
+
+// serveDebugDump serves a bundle of the diagnostics of the server for
+// support, as a gzipped tar archive of:
+//   - the goroutine, heap, allocs, block, mutex and threadcreate profiles,
+//     and the stacks of the goroutines as text
+//   - (optionally) a CPU profile
+//   - the results of SHOW SHARDS, SHOW STATS and SHOW DIAGNOSTICS
+//   - the configuration, with its secrets redacted
+//   - the recent logs
+//
+// The CPU profile is collected if the requester provides its duration as the
+// `cpu` query parameter, like:
+//
+//	http://localhost:8086/debug/dump?cpu=30s
+//
+// Only an admin can request a dump when authentication is enabled.
+func (h *Handler) serveDebugDump(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if h.Config.AuthEnabled && (user == nil || !user.AuthorizeUnrestricted()) {
+		if user != nil {
+			h.Logger.Info("Unauthorized request", zap.String("user", user.ID()), zap.String("path", r.URL.Path))
+		}
+		h.httpError(w, "error authorizing admin access", http.StatusForbidden)
+		return
+	}
+
+	var cpu time.Duration
+	if s := r.URL.Query().Get("cpu"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			h.httpError(w, fmt.Sprintf("invalid cpu profile duration %q", s), http.StatusBadRequest)
+			return
+		}
+		if d > maxDumpCPUDuration {
+			d = maxDumpCPUDuration
+		}
+		cpu = d
+	}
+
+	now := time.Now().UTC()
+	dir := "influxdb-dump-" + now.Format("20060102T150405Z")
+	h.Logger.Info("Collecting debug dump", zap.Duration("cpu", cpu))
+
+	archive := &bytes.Buffer{}
+	if err := h.writeDebugDump(archive, r, dir, cpu); err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.tar.gz", dir))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Write(archive.Bytes())
+}
+
+// writeDebugDump writes a debug dump as a gzipped tar archive of the files in
+// dir.
+func (h *Handler) writeDebugDump(w *bytes.Buffer, r *http.Request, dir string, cpu time.Duration) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	profiles := []string{"goroutine", "heap", "allocs", "block", "mutex", "threadcreate"}
+	if cpu > 0 {
+		// The CPU profile is collected first, so it isn't skewed by the
+		// collection of the others.
+		profiles = append([]string{"cpu"}, profiles...)
+	}
+	for _, name := range profiles {
+		if err := writeProfile(tw, r, dir+"/profiles", name, cpu); err != nil {
+			return err
+		}
+	}
+
+	var stacks bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&stacks, 2); err != nil {
+		return err
+	}
+	if err := writeArchiveFile(tw, dir+"/profiles/goroutine.txt", stacks.Bytes()); err != nil {
+		return err
+	}
+
+	if err := h.writeQueryResults(tw, dir); err != nil {
+		return err
+	}
+
+	if h.Dump != nil {
+		config, err := h.Dump.Config()
+		if err != nil {
+			return err
+		}
+		if err := writeArchiveFile(tw, dir+"/config.toml", config); err != nil {
+			return err
+		}
+		if err := writeArchiveFile(tw, dir+"/logs.txt", h.Dump.Logs()); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -1,11 +1,15 @@
 package httpd_test
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"log"
 	"math"
@@ -42,6 +46,7 @@ import (
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
 	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
 )
 
 // Ensure the handler returns results from a query (including nil results).
@@ -133,6 +138,13 @@ func TestHandler_Query_Auth(t *testing.T) {
 		return h.MetaClient.User(u)
 	}
 
+	h.MetaClient.AuthenticateTokenFn = func(token string) (meta.User, error) {
+		if token != "0a1b2c3d.secret" {
+			return nil, meta.ErrAuthenticate
+		}
+		return h.MetaClient.User("user1")
+	}
+
 	// Set mock query authorizer for handler to use.
 	h.QueryAuthorizer.AuthorizeQueryFn = func(u meta.User, query *influxql.Query, database string) error {
 		return nil
@@ -256,6 +268,26 @@ func TestHandler_Query_Auth(t *testing.T) {
 	}
 	h.Config.SharedSecret = origSecret
 
+	// Test the handler with a valid API token.
+	req.Header.Set("Authorization", "Token 0a1b2c3d.secret")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"results":[{"statement_id":1,"series":[{"name":"series0"}]},{"statement_id":2,"series":[{"name":"series1"}]}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	// Test the handler with an invalid API token.
+	req.Header.Set("Authorization", "Token 0a1b2c3d.wrong")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"authorization failed"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
 	// Test the handler with valid user and password in the url and invalid in
 	// basic auth (prioritize url).
 	w = httptest.NewRecorder()
@@ -413,6 +445,72 @@ func TestHandler_Query_Async(t *testing.T) {
 	}
 }
 
+// Ensure the handler runs a query with the priority of the request, which
+// may only lower the priority of the user.
+func TestHandler_Query_Priority(t *testing.T) {
+	h := NewHandler(true)
+	h.MetaClient.AdminUserExistsFn = func() bool { return true }
+	h.MetaClient.UserFn = func(username string) (meta.User, error) {
+		switch username {
+		case "user1":
+			return &meta.UserInfo{Name: "user1", Admin: true}, nil
+		case "user2":
+			return &meta.UserInfo{Name: "user2", Admin: true, QueryPriority: query.PriorityDashboard}, nil
+		default:
+			return nil, meta.ErrUserNotFound
+		}
+	}
+	h.MetaClient.AuthenticateFn = func(u, p string) (meta.User, error) {
+		return h.MetaClient.User(u)
+	}
+	h.QueryAuthorizer.AuthorizeQueryFn = func(u meta.User, query *influxql.Query, database string) error {
+		return nil
+	}
+
+	var priority string
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+		priority = ctx.Priority
+		return nil
+	}
+
+	for _, tt := range []struct {
+		user   string
+		header string
+		exp    string
+	}{
+		{user: "user1", header: "", exp: ""},
+		{user: "user1", header: query.PriorityBatch, exp: query.PriorityBatch},
+		{user: "user2", header: "", exp: query.PriorityDashboard},
+		{user: "user2", header: query.PriorityInteractive, exp: query.PriorityDashboard},
+		{user: "user2", header: query.PriorityBatch, exp: query.PriorityBatch},
+	} {
+		priority = "unset"
+		w := httptest.NewRecorder()
+		r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+		r.SetBasicAuth(tt.user, "abcd")
+		if tt.header != "" {
+			r.Header.Set("X-Influxdb-Query-Priority", tt.header)
+		}
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+		} else if priority != tt.exp {
+			t.Errorf("%s with %q: unexpected priority: got %q, exp %q", tt.user, tt.header, priority, tt.exp)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+	r.SetBasicAuth("user1", "abcd")
+	r.Header.Set("X-Influxdb-Query-Priority", "urgent")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"unknown query priority urgent, expected one of interactive, dashboard, batch"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
 // Ensure the handler returns a status 400 if the query is not passed in.
 func TestHandler_Query_ErrQueryRequired(t *testing.T) {
 	h := NewHandler(false)
@@ -1079,6 +1177,124 @@ func TestHandler_PromRead(t *testing.T) {
 	}
 }
 
+func TestHandler_PromRead_Streamed(t *testing.T) {
+	req := &prompb.ReadRequest{
+		Queries: []*prompb.Query{{
+			Matchers: []*prompb.LabelMatcher{
+				{
+					Type:  prompb.LabelMatcher_EQ,
+					Name:  "__name__",
+					Value: "value",
+				},
+			},
+			StartTimestampMs: 0,
+			EndTimestampMs:   1000000,
+		}},
+		AcceptedResponseTypes: []prompb.ReadRequest_ResponseType{prompb.ReadRequest_STREAMED_XOR_CHUNKS, prompb.ReadRequest_SAMPLES},
+	}
+	data, err := req.Marshal()
+	if err != nil {
+		t.Fatal("couldn't marshal prometheus request")
+	}
+	h := NewHandler(false)
+	w := httptest.NewRecorder()
+
+	// A float series of 300 samples, in batches of 100, and a string series.
+	var i int64
+	h.Store.ResultSet.NextFn = func() bool {
+		i++
+		return i <= 2
+	}
+	h.Store.ResultSet.CursorFn = func() tsdb.Cursor {
+		if i == 2 {
+			return internal.NewStringArrayCursorMock()
+		}
+		cursor := internal.NewFloatArrayCursorMock()
+		var batch int64
+		cursor.NextFn = func() *tsdb.FloatArray {
+			a := &tsdb.FloatArray{}
+			for j := int64(0); batch < 3 && j < 100; j++ {
+				n := batch*100 + j
+				a.Timestamps = append(a.Timestamps, n*int64(time.Second))
+				a.Values = append(a.Values, float64(n)/2)
+			}
+			batch++
+			return a
+		}
+		return cursor
+	}
+	h.Store.ResultSet.TagsFn = func() models.Tags {
+		return models.NewTags(map[string]string{
+			"host":         fmt.Sprintf("server-%d", i),
+			"_measurement": "mem",
+		})
+	}
+
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/prom/read?db=foo&rp=bar", bytes.NewReader(snappy.Encode(nil, data))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if got, exp := w.Header().Get("Content-Type"), "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse"; got != exp {
+		t.Fatalf("unexpected content type: %s", got)
+	}
+
+	// Decode the frames: uvarint size, CRC32C and a ChunkedReadResponse.
+	body := bytes.NewReader(w.Body.Bytes())
+	var frames []prompb.ChunkedReadResponse
+	for body.Len() > 0 {
+		size, err := binary.ReadUvarint(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sum uint32
+		if err := binary.Read(body, binary.BigEndian, &sum); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(body, buf); err != nil {
+			t.Fatal(err)
+		} else if crc32.Checksum(buf, crc32.MakeTable(crc32.Castagnoli)) != sum {
+			t.Fatal("checksum mismatch")
+		}
+		var resp prompb.ChunkedReadResponse
+		if err := resp.Unmarshal(buf); err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, resp)
+	}
+	if len(frames) != 1 || len(frames[0].ChunkedSeries) != 1 {
+		t.Fatalf("unexpected frames: %v", frames)
+	}
+
+	series := frames[0].ChunkedSeries[0]
+	if exp := []prompb.Label{{Name: "host", Value: "server-1"}}; !reflect.DeepEqual(series.Labels, exp) {
+		t.Fatalf("unexpected labels: %v", series.Labels)
+	} else if len(series.Chunks) != 3 {
+		t.Fatalf("unexpected chunks: %d", len(series.Chunks))
+	}
+	var n int64
+	for _, c := range series.Chunks {
+		chunk, err := chunkenc.FromData(chunkenc.EncXOR, c.Data)
+		if err != nil {
+			t.Fatal(err)
+		} else if c.Type != prompb.Chunk_XOR || c.MinTimeMs != n*1000 {
+			t.Fatalf("unexpected chunk: %+v", c)
+		}
+		it := chunk.Iterator(nil)
+		for it.Next() {
+			if ts, v := it.At(); ts != n*1000 || v != float64(n)/2 {
+				t.Fatalf("unexpected sample %d: %d %v", n, ts, v)
+			}
+			n++
+		}
+		if c.MaxTimeMs != (n-1)*1000 {
+			t.Fatalf("unexpected chunk max time: %+v", c)
+		}
+	}
+	if n != 300 {
+		t.Fatalf("unexpected samples: %d", n)
+	}
+}
+
 func TestHandler_PromRead_NoResults(t *testing.T) {
 	req := &prompb.ReadRequest{Queries: []*prompb.Query{&prompb.Query{
 		Matchers: []*prompb.LabelMatcher{
@@ -1633,6 +1849,261 @@ func TestHandler_Write_NegativeMaxBodySize(t *testing.T) {
 	}
 }
 
+// Ensure the request ID and the trace of a write are passed to the points
+// writer.
+func TestHandler_Write_RequestID(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	var writeCtx tsdb.WriteContext
+	h.PointsWriter.WritePointsWithContextFn = func(ctx tsdb.WriteContext, _, _ string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+		writeCtx = ctx
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewRequest("POST", "/write?db=foo", strings.NewReader(`foo n=1`))
+	r.Header.Set("X-Request-Id", "0a1b2c3d")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if writeCtx.RequestID != "0a1b2c3d" {
+		t.Fatalf("unexpected request id: %q", writeCtx.RequestID)
+	} else if writeCtx.Trace == nil {
+		t.Fatalf("unexpected trace: %+v", writeCtx.Trace)
+	}
+}
+
+// Ensure the acknowledgment level of a write is passed to the points writer.
+func TestHandler_Write_Ack(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	var writeCtx tsdb.WriteContext
+	h.PointsWriter.WritePointsWithContextFn = func(ctx tsdb.WriteContext, _, _ string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+		writeCtx = ctx
+		return nil
+	}
+
+	for _, tt := range []struct {
+		query string
+		ack   tsdb.WriteAck
+	}{
+		{query: "", ack: tsdb.WriteAckApplied},
+		{query: "&ack=applied", ack: tsdb.WriteAckApplied},
+		{query: "&ack=queued", ack: tsdb.WriteAckQueued},
+	} {
+		writeCtx = tsdb.WriteContext{}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo"+tt.query, strings.NewReader(`foo n=1`)))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("%q: unexpected status: %d", tt.query, w.Code)
+		} else if writeCtx.Ack != tt.ack {
+			t.Fatalf("%q: unexpected ack: %v", tt.query, writeCtx.Ack)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo&ack=all", strings.NewReader(`foo n=1`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := w.Body.String(); !strings.Contains(body, `invalid ack \"all\" (use queued or applied)`) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure a write beyond the write rate of the database is rejected with 429
+// and the time to wait before retrying it.
+func TestHandler_Write_QuotaExceeded(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+		return &tsdb.QuotaExceededError{Database: "foo", Quota: tsdb.QuotaMaxWriteRate, Limit: 10, RetryAfter: 1500 * time.Millisecond}
+	}
+
+	for _, path := range []string{"/write?db=foo", "/api/v2/write?bucket=foo"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("POST", path, strings.NewReader(`foo n=1`)))
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("%s: unexpected status: %d", path, w.Code)
+		} else if body := w.Body.String(); !strings.Contains(body, "quota exceeded: max-write-rate database=foo limit=10") {
+			t.Fatalf("%s: unexpected body: %s", path, body)
+		} else if got := w.Header().Get("Retry-After"); got != "2" {
+			t.Fatalf("%s: unexpected Retry-After: %q", path, got)
+		}
+	}
+
+	// A write that can't be retried as is has no Retry-After.
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+		return &tsdb.QuotaExceededError{Database: "foo", Quota: tsdb.QuotaMaxWriteRate, Limit: 10}
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader(`foo n=1`)))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if got := w.Header().Get("Retry-After"); got != "" {
+		t.Fatalf("unexpected Retry-After: %q", got)
+	}
+}
+
+// Ensure a partial write reports the lines of the dropped points and why they
+// were dropped.
+func TestHandler_Write_PartialWrite(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, points []models.Point) error {
+		var err tsdb.PartialWriteError
+		err.DropPoint(points[2], tsdb.DropReasonTypeConflict, "field type conflict")
+		err.DropPoint(points[0], tsdb.DropReasonFutureTimestamp, "points too far in the future")
+		return err
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader("foo n=1\nfoo n=\nfoo n=2\n\nfoo n=3i")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	var resp httpd.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	} else if resp.Err == nil || resp.Err.Error() != "partial write: field type conflict dropped=2" {
+		t.Fatalf("unexpected error: %v", resp.Err)
+	} else if exp := (&httpd.PartialWriteResult{
+		Dropped: 3,
+		Points: []httpd.PartialWritePoint{
+			{Line: 1, Reason: tsdb.DropReasonFutureTimestamp, Message: "points too far in the future"},
+			{Line: 2, Reason: tsdb.DropReasonParseError, Code: models.ParseErrorSyntax, Offset: intPtr(8), Message: "unable to parse 'foo n=': missing field value"},
+			{Line: 5, Reason: tsdb.DropReasonTypeConflict, Message: "field type conflict"},
+		},
+	}); !reflect.DeepEqual(resp.PartialWrite, exp) {
+		t.Fatalf("unexpected partial write: %+v", resp.PartialWrite)
+	}
+}
+
+// Ensure a strict write reports the reason and offset of the lines it rejects.
+func TestHandler_Write_Strict(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	var n int
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, points []models.Point) error {
+		n = len(points)
+		return nil
+	}
+
+	body := "foo n=1\nfo\to n=2\nfoo s=\"\xff\" 3\n"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader(body)))
+	if w.Code != http.StatusNoContent || n != 3 {
+		t.Fatalf("unexpected status %d with %d points", w.Code, n)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo&strict=true", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest || n != 1 {
+		t.Fatalf("unexpected status %d with %d points", w.Code, n)
+	}
+	var resp httpd.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	} else if resp.PartialWrite == nil || len(resp.PartialWrite.Points) != 2 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+	for i, exp := range []httpd.PartialWritePoint{
+		{Line: 2, Code: models.ParseErrorControlCharacter, Offset: intPtr(10)},
+		{Line: 3, Code: models.ParseErrorInvalidUTF8, Offset: intPtr(24)},
+	} {
+		if got := resp.PartialWrite.Points[i]; got.Line != exp.Line || got.Code != exp.Code || got.Offset == nil || *got.Offset != *exp.Offset {
+			t.Fatalf("unexpected dropped point %d: %+v", i, got)
+		}
+	}
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestHandler_Write_JSON(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	var points []models.Point
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, pts []models.Point) error {
+		points = pts
+		return nil
+	}
+
+	write := func(body string) *httptest.ResponseRecorder {
+		points = nil
+		req := MustNewRequest("POST", "/write?db=foo&precision=s", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := write(`[
+		{"measurement": "cpu", "tags": {"host": "a b", "region": "us"}, "fields": {"value": 1.5, "n": {"type": "integer", "value": 2}, "on": true, "msg": "say \"hi\""}, "time": 10},
+		{"measurement": "mem", "fields": {"free": {"type": "unsigned", "value": 3}}, "time": "2020-01-01T00:00:00Z"}
+	]`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	} else if len(points) != 2 {
+		t.Fatalf("unexpected points: %v", points)
+	}
+	for i, exp := range []string{
+		`cpu,host=a\ b,region=us msg="say \"hi\"",n=2i,on=true,value=1.5 10000000000`,
+		`mem free=3u 1577836800000000000`,
+	} {
+		if got := points[i].String(); got != exp {
+			t.Errorf("unexpected point %d:\ngot  %s\nexp  %s", i, got, exp)
+		}
+	}
+
+	// Any point that doesn't match the schema rejects the whole body.
+	for _, tt := range []struct {
+		body, err string
+	}{
+		{body: `{"measurement": "cpu"}`, err: "must be an array"},
+		{body: `[{"fields": {"value": 1}}]`, err: "point 1: measurement is required"},
+		{body: `[{"measurement": "cpu", "fields": {"value": 1}}, {"measurement": "cpu"}]`, err: "point 2: fields are required"},
+		{body: `[{"measurement": "cpu", "fields": {"value": 1}, "unit": "s"}]`, err: "unknown field"},
+		{body: `[{"measurement": "cpu", "tags": {"host": 1}, "fields": {"value": 1}}]`, err: "point 1:"},
+		{body: `[{"measurement": "cpu", "fields": {"value": null}}]`, err: "null value"},
+		{body: `[{"measurement": "cpu", "fields": {"value": {"type": "integer", "value": 1.5}}}]`, err: "invalid syntax"},
+		{body: `[{"measurement": "cpu", "fields": {"value": 1}, "time": "yesterday"}]`, err: "point 1: time:"},
+		{body: `[{"measurement": "cpu", "fields": {"value": 1}}] []`, err: "unexpected data"},
+	} {
+		w := write(tt.body)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.err) {
+			t.Errorf("%s: unexpected status %d: %s", tt.body, w.Code, w.Body.String())
+		} else if points != nil {
+			t.Errorf("%s: unexpected points written: %v", tt.body, points)
+		}
+	}
+
+	// The points are numbered like lines in partial writes.
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, pts []models.Point) error {
+		var perr tsdb.PartialWriteError
+		perr.DropPoint(pts[1], tsdb.DropReasonFutureTimestamp, "points too far in the future")
+		return perr
+	}
+	w = write(`[{"measurement": "cpu", "fields": {"value": 1}}, {"measurement": "cpu", "fields": {"value": 2}}]`)
+	var resp httpd.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	} else if resp.PartialWrite == nil || len(resp.PartialWrite.Points) != 1 || resp.PartialWrite.Points[0].Line != 2 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
 // TestHandler_Write_V1_Precision verifies v1 writes validate precision.
 func TestHandler_Write_V1_Precision(t *testing.T) {
 	h := NewHandler(false)
@@ -2773,6 +3244,106 @@ func TestHandlerDebugVars(t *testing.T) {
 
 }
 
+// Ensure the internal statistics are served in Prometheus exposition format
+// along with the metrics of the Go client.
+func TestHandler_Metrics(t *testing.T) {
+	h := NewHandler(false)
+	h.Monitor.StatisticsFn = func(_ map[string]string) ([]*monitor.Statistic, error) {
+		return []*monitor.Statistic{
+			{Statistic: models.Statistic{Name: "write", Tags: map[string]string{"hostname": "host0"}, Values: map[string]interface{}{"pointReq": int64(10)}}},
+			{Statistic: models.Statistic{Name: "shard", Tags: map[string]string{"id": "1"}, Values: map[string]interface{}{"diskBytes": int64(100)}}},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, exp := range []string{
+		"# TYPE influxdb_write_point_req_total counter\n",
+		`influxdb_write_point_req_total{hostname="host0"} 10` + "\n",
+		"# TYPE influxdb_shard_disk_bytes gauge\n",
+		`influxdb_shard_disk_bytes{id="1"} 100` + "\n",
+		"# TYPE go_goroutines gauge\n",
+	} {
+		if !strings.Contains(body, exp) {
+			t.Errorf("missing %q in:\n%s", exp, body)
+		}
+	}
+}
+
+func TestHandler_DebugDump(t *testing.T) {
+	h := NewHandlerWithConfig(NewHandlerConfig(WithAuthentication(), WithPprofAuthEnabled()))
+	h.MetaClient.AdminUserExistsFn = func() bool { return true }
+	h.MetaClient.DatabasesFn = func() []meta.DatabaseInfo { return nil }
+	h.MetaClient.AuthenticateFn = func(u, p string) (meta.User, error) {
+		if u == "admin" {
+			return &meta.UserInfo{Name: "admin", Admin: true}, nil
+		}
+		return &meta.UserInfo{Name: u}, nil
+	}
+	h.Handler.Dump = &HandlerDump{config: "[monitor]\n  alert-webhook = \"[REDACTED]\"\n", logs: "lvl=info msg=\"Listening on HTTP\"\n"}
+
+	// Only an admin can request a dump.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/debug/dump?u=user1&p=abcd", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/debug/dump?u=admin&p=admin&cpu=-1s", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/debug/dump?u=admin&p=admin", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if cd := w.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment; filename=influxdb-dump-") {
+		t.Fatalf("unexpected content disposition: %s", cd)
+	}
+
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Strip the directory of the dump.
+		files[hdr.Name[strings.Index(hdr.Name, "/")+1:]] = string(b)
+	}
+
+	for _, name := range []string{"profiles/goroutine.pb.gz", "profiles/heap.pb.gz", "profiles/goroutine.txt", "shards.txt", "stats.txt", "diagnostics.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if _, ok := files["profiles/cpu.pb.gz"]; ok {
+		t.Error("unexpected cpu profile")
+	}
+	if got, exp := files["config.toml"], h.Handler.Dump.(*HandlerDump).config; got != exp {
+		t.Errorf("unexpected config: got=%q exp=%q", got, exp)
+	}
+	if got, exp := files["logs.txt"], h.Handler.Dump.(*HandlerDump).logs; got != exp {
+		t.Errorf("unexpected logs: got=%q exp=%q", got, exp)
+	}
+}
+
 // NewHandler represents a test wrapper for httpd.Handler.
 type Handler struct {
 	*httpd.Handler
@@ -2901,6 +3472,15 @@ func (m *HandlerMonitor) Diagnostics() (map[string]*diagnostics.Diagnostics, err
 	return m.DiagnosticsFn()
 }
 
+// HandlerDump is a mock implementation of Handler.Dump.
+type HandlerDump struct {
+	config string
+	logs   string
+}
+
+func (d *HandlerDump) Config() ([]byte, error) { return []byte(d.config), nil }
+func (d *HandlerDump) Logs() []byte            { return []byte(d.logs) }
+
 // HandlerStatementExecutor is a mock implementation of Handler.StatementExecutor.
 type HandlerStatementExecutor struct {
 	ExecuteStatementFn func(stmt influxql.Statement, ctx *query.ExecutionContext) error
@@ -2939,10 +3519,16 @@ func (a *HandlerQueryAuthorizer) AuthorizeDeleteRetentionPolicy(u meta.User, db
 }
 
 type HandlerPointsWriter struct {
-	WritePointsFn func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, user meta.User, points []models.Point) error
+	WritePointsFn            func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, user meta.User, points []models.Point) error
+	WritePointsWithContextFn func(writeCtx tsdb.WriteContext, database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, user meta.User, points []models.Point) error
 }
 
-func (h *HandlerPointsWriter) WritePoints(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, user meta.User, points []models.Point) error {
+// WritePointsWithContext calls WritePointsWithContextFn if it is set, or
+// WritePointsFn for the tests that ignore the write context.
+func (h *HandlerPointsWriter) WritePointsWithContext(writeCtx tsdb.WriteContext, database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, user meta.User, points []models.Point) error {
+	if h.WritePointsWithContextFn != nil {
+		return h.WritePointsWithContextFn(writeCtx, database, retentionPolicy, consistencyLevel, user, points)
+	}
 	return h.WritePointsFn(database, retentionPolicy, consistencyLevel, user, points)
 }
 
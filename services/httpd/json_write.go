@@ -0,0 +1,208 @@
+package httpd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// isJSONWrite returns true if the body of a write request is a JSON array of
+// points rather than line protocol.
+func isJSONWrite(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// jsonPoint is a point of a JSON write body:
+//
+//	[{"measurement": "cpu", "tags": {"host": "a"}, "fields": {"value": 1.5}, "time": 1600000000}]
+//
+// A field value is a float for a JSON number, a string or a boolean, or an
+// object such as {"type": "integer", "value": 1} for the integer and
+// unsigned types. The time is an integer in the precision of the write, or
+// an RFC3339 string, and defaults to the time of the write.
+type jsonPoint struct {
+	Measurement string                     `json:"measurement"`
+	Tags        map[string]string          `json:"tags"`
+	Fields      map[string]json.RawMessage `json:"fields"`
+	Time        json.RawMessage            `json:"time"`
+}
+
+// jsonTypedValue is a field value of an explicit type.
+type jsonTypedValue struct {
+	Type  string      `json:"type"`
+	Value json.Number `json:"value"`
+}
+
+// parseJSONPoints decodes the points of a JSON write body one at a time. The
+// whole body is rejected if any point doesn't match the schema. The error
+// numbers the points from 1, like the lines of line protocol.
+func parseJSONPoints(buf []byte, defaultTime time.Time, precision string) ([]models.Point, error) {
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if tok != json.Delim('[') {
+		return nil, errors.New("JSON write body must be an array of points")
+	}
+
+	b := models.GetPointBuilder()
+	defer models.PutPointBuilder(b)
+
+	var points []models.Point
+	for n := 1; dec.More(); n++ {
+		var jp jsonPoint
+		if err := dec.Decode(&jp); err != nil {
+			return nil, fmt.Errorf("point %d: %s", n, err)
+		}
+		pt, err := jp.point(b, defaultTime, precision)
+		if err != nil {
+			return nil, fmt.Errorf("point %d: %s", n, err)
+		}
+		points = append(points, pt)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	} else if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the array of points")
+	}
+	return points, nil
+}
+
+// point returns the point built from jp with b.
+func (jp *jsonPoint) point(b *models.PointBuilder, defaultTime time.Time, precision string) (models.Point, error) {
+	if jp.Measurement == "" {
+		return nil, errors.New("measurement is required")
+	} else if len(jp.Fields) == 0 {
+		return nil, errors.New("fields are required")
+	}
+
+	b.Reset()
+	b.SetName(jp.Measurement)
+	for k, v := range jp.Tags {
+		if k == "" {
+			return nil, errors.New("tag keys must not be empty")
+		}
+		b.AddTag(k, v)
+	}
+
+	// The fields are encoded in the order of their keys.
+	keys := make([]string, 0, len(jp.Fields))
+	for k := range jp.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := addJSONField(b, k, jp.Fields[k]); err != nil {
+			return nil, fmt.Errorf("field %q: %s", k, err)
+		}
+	}
+
+	t, err := parseJSONTime(jp.Time, defaultTime, precision)
+	if err != nil {
+		return nil, fmt.Errorf("time: %s", err)
+	}
+	b.SetTime(t)
+	return b.Point()
+}
+
+// addJSONField adds the field of a JSON value to b.
+func addJSONField(b *models.PointBuilder, key string, raw json.RawMessage) error {
+	if len(raw) == 0 {
+		return errors.New("missing value")
+	}
+
+	switch raw[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		b.AddStringField(key, s)
+	case 't', 'f':
+		var v bool
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		b.AddBooleanField(key, v)
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		var v jsonTypedValue
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		switch v.Type {
+		case "integer":
+			i, err := strconv.ParseInt(string(v.Value), 10, 64)
+			if err != nil {
+				return err
+			}
+			b.AddIntegerField(key, i)
+		case "unsigned":
+			u, err := strconv.ParseUint(string(v.Value), 10, 64)
+			if err != nil {
+				return err
+			}
+			b.AddUnsignedField(key, u)
+		case "float":
+			f, err := strconv.ParseFloat(string(v.Value), 64)
+			if err != nil {
+				return err
+			}
+			b.AddFloatField(key, f)
+		default:
+			return fmt.Errorf("invalid type %q, expected integer, unsigned or float", v.Type)
+		}
+	case 'n':
+		return errors.New("null value")
+	case '[':
+		return errors.New("arrays are not supported")
+	default:
+		f, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			return err
+		}
+		b.AddFloatField(key, f)
+	}
+	return nil
+}
+
+// parseJSONTime returns the time of a JSON value.
+func parseJSONTime(raw json.RawMessage, defaultTime time.Time, precision string) (time.Time, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return defaultTime, nil
+	}
+
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return time.Time{}, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.UTC(), models.CheckTime(t)
+	}
+
+	ts, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %s", raw)
+	}
+	return models.SafeCalcTime(ts, precision)
+}
@@ -202,61 +202,69 @@ func (h *Handler) archiveProfilesAndQueries(w http.ResponseWriter, r *http.Reque
 	}
 
 	tarball := &bytes.Buffer{}
-	buf := &bytes.Buffer{} // Temporary buffer for each profile/query result.
-
 	tw := tar.NewWriter(tarball)
+
 	// Collect and write out profiles.
 	for _, profile := range profiles {
-		switch profile.Name {
-		case "cpu":
-			if err := pprof.StartCPUProfile(buf); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			sleep(r, profile.Duration)
-			pprof.StopCPUProfile()
+		if err := writeProfile(tw, r, "profiles", profile.Name, profile.Duration); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
 
-		case "trace":
-			if err := trace.Start(buf); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			sleep(r, profile.Duration)
-			trace.Stop()
-
-		default:
-			prof := pprof.Lookup(profile.Name)
-			if prof == nil {
-				http.Error(w, "unable to find profile "+profile.Name, http.StatusInternalServerError)
-				return
-			}
+	// Collect and write out the queries.
+	if err := h.writeQueryResults(tw, "profiles"); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-			if err := prof.WriteTo(buf, 0); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
+	// Close the tar writer.
+	if err := tw.Close(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return the gzipped archive.
+	w.Header().Set("Content-Disposition", "attachment; filename=profiles.tar")
+	w.Header().Set("Content-Type", "application/x-tar")
+	io.Copy(w, tarball)
+}
+
+// writeProfile collects a profile and writes it to a tar archive as
+// dir/name.pb.gz. The CPU profile and the trace are collected for d.
+func writeProfile(tw *tar.Writer, r *http.Request, dir, name string, d time.Duration) error {
+	buf := &bytes.Buffer{}
+	switch name {
+	case "cpu":
+		if err := pprof.StartCPUProfile(buf); err != nil {
+			return err
 		}
+		sleep(r, d)
+		pprof.StopCPUProfile()
 
-		// Write the profile file's header.
-		err := tw.WriteHeader(&tar.Header{
-			Name: path.Join("profiles", profile.Name+".pb.gz"),
-			Mode: 0600,
-			Size: int64(buf.Len()),
-		})
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	case "trace":
+		if err := trace.Start(buf); err != nil {
+			return err
 		}
+		sleep(r, d)
+		trace.Stop()
 
-		// Write the profile file's data.
-		if _, err := tw.Write(buf.Bytes()); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		prof := pprof.Lookup(name)
+		if prof == nil {
+			return fmt.Errorf("unable to find profile %s", name)
 		}
 
-		// Reset the buffer for the next profile.
-		buf.Reset()
+		if err := prof.WriteTo(buf, 0); err != nil {
+			return err
+		}
 	}
+	return writeArchiveFile(tw, path.Join(dir, name+".pb.gz"), buf.Bytes())
+}
 
-	// Collect and write out the queries.
+// writeQueryResults writes the results of SHOW SHARDS, SHOW STATS and SHOW
+// DIAGNOSTICS to a tar archive as tables in dir.
+func (h *Handler) writeQueryResults(tw *tar.Writer, dir string) error {
 	var allQueries = []struct {
 		name string
 		fn   func() ([]*models.Row, error)
@@ -266,11 +274,12 @@ func (h *Handler) archiveProfilesAndQueries(w http.ResponseWriter, r *http.Reque
 		{"diagnostics", h.showDiagnostics},
 	}
 
+	buf := &bytes.Buffer{} // Temporary buffer for each query result.
 	tabW := tabwriter.NewWriter(buf, 8, 8, 1, '\t', 0)
 	for _, query := range allQueries {
 		rows, err := query.fn()
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
 		}
 
 		for i, row := range rows {
@@ -281,7 +290,7 @@ func (h *Handler) archiveProfilesAndQueries(w http.ResponseWriter, r *http.Reque
 			}
 			out = append(out, '\n')
 			if _, err := tabW.Write(out); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return err
 			}
 
 			// Write all the values
@@ -292,50 +301,43 @@ func (h *Handler) archiveProfilesAndQueries(w http.ResponseWriter, r *http.Reque
 				}
 				out = append(out, '\n')
 				if _, err := tabW.Write(out); err != nil {
-					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return err
 				}
 			}
 
 			// Write a final newline
 			if i < len(rows)-1 {
 				if _, err := tabW.Write([]byte("\n")); err != nil {
-					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return err
 				}
 			}
 		}
 
 		if err := tabW.Flush(); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-
-		err = tw.WriteHeader(&tar.Header{
-			Name: path.Join("profiles", query.name+".txt"),
-			Mode: 0600,
-			Size: int64(buf.Len()),
-		})
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
 		}
-
-		// Write the query file's data.
-		if _, err := tw.Write(buf.Bytes()); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err := writeArchiveFile(tw, path.Join(dir, query.name+".txt"), buf.Bytes()); err != nil {
+			return err
 		}
 
 		// Reset the buffer for the next query.
 		buf.Reset()
 	}
+	return nil
+}
 
-	// Close the tar writer.
-	if err := tw.Close(); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+// writeArchiveFile writes a file to a tar archive.
+func writeArchiveFile(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
 	}
-
-	// Return the gzipped archive.
-	w.Header().Set("Content-Disposition", "attachment; filename=profiles.tar")
-	w.Header().Set("Content-Type", "application/x-tar")
-	io.Copy(w, tarball)
+	_, err := tw.Write(data)
+	return err
 }
 
 // showShards generates the same values that a StatementExecutor would if a
@@ -0,0 +1,198 @@
+package httpd
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/prometheus"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"go.uber.org/zap"
+)
+
+const (
+	// promChunkSamples is the number of samples of an XOR chunk, as in the
+	// chunks of Prometheus.
+	promChunkSamples = 120
+
+	// promMaxFrameBytes is the size of the chunks of a series that are sent
+	// in a frame of their own.
+	promMaxFrameBytes = 1024 * 1024
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// acceptsStreamedChunks returns true if the remote read request prefers a
+// streamed response of XOR chunks. Prometheus lists the response types in
+// its order of preference.
+func acceptsStreamedChunks(req *prompb.ReadRequest) bool {
+	for _, t := range req.AcceptedResponseTypes {
+		switch t {
+		case prompb.ReadRequest_STREAMED_XOR_CHUNKS:
+			return true
+		case prompb.ReadRequest_SAMPLES:
+			return false
+		default:
+		}
+	}
+	return false
+}
+
+// servePromReadStreamed streams the series of rs as ChunkedReadResponse
+// frames, so only the chunks of a frame are held in memory. The response
+// can't report an error once it started, it is cut short instead.
+func (h *Handler) servePromReadStreamed(w http.ResponseWriter, rs reads.ResultSet) {
+	w.Header().Set("Content-Type", "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse")
+	h.writeHeader(w, http.StatusOK)
+	if rs == nil {
+		return
+	}
+	defer rs.Close()
+
+	cw := &promChunkedWriter{w: w}
+	defer func() {
+		atomic.AddInt64(&h.stats.QueryRequestBytesTransmitted, cw.n)
+	}()
+	for rs.Next() {
+		cur := rs.Cursor()
+		if cur == nil {
+			// no data for series key + field combination
+			continue
+		}
+
+		tags := prometheus.RemoveInfluxSystemTags(rs.Tags())
+		fcur, ok := cur.(tsdb.FloatArrayCursor)
+		if !ok {
+			cur.Close()
+			h.Logger.Info("Prometheus can't read cursor",
+				zap.String("cursor_type", promCursorType(cur)),
+				zap.Stringer("series", tags),
+			)
+			continue
+		}
+
+		err := cw.writeSeries(prometheus.ModelTagsToLabelPairs(tags), fcur)
+		fcur.Close()
+		if err != nil {
+			h.Logger.Info("Error streaming Prometheus remote read response", zap.Error(err))
+			return
+		}
+	}
+}
+
+// promCursorType returns the name of the type of the values of a cursor.
+func promCursorType(cur interface{}) string {
+	switch cur.(type) {
+	case tsdb.FloatArrayCursor:
+		return "float"
+	case tsdb.IntegerArrayCursor:
+		return "int64"
+	case tsdb.UnsignedArrayCursor:
+		return "uint"
+	case tsdb.BooleanArrayCursor:
+		return "bool"
+	case tsdb.StringArrayCursor:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// promChunkedWriter writes the frames of a streamed remote read response:
+// the uvarint size of a message, its big-endian CRC32C checksum and the
+// message.
+type promChunkedWriter struct {
+	w io.Writer
+	n int64 // bytes written
+}
+
+// writeSeries writes the samples of cur as the XOR chunks of a series, in
+// as many frames as needed.
+func (cw *promChunkedWriter) writeSeries(labels []prompb.Label, cur tsdb.FloatArrayCursor) error {
+	var (
+		chunks     []prompb.Chunk
+		size       int
+		c          *chunkenc.XORChunk
+		app        chunkenc.Appender
+		minT, maxT int64
+	)
+	endChunk := func() {
+		if c == nil {
+			return
+		}
+		chunks = append(chunks, prompb.Chunk{MinTimeMs: minT, MaxTimeMs: maxT, Type: prompb.Chunk_XOR, Data: c.Bytes()})
+		size += len(c.Bytes())
+		c = nil
+	}
+
+	for {
+		a := cur.Next()
+		if a.Len() == 0 {
+			break
+		}
+
+		for i, ts := range a.Timestamps {
+			t := ts / int64(time.Millisecond)
+			if c == nil {
+				c = chunkenc.NewXORChunk()
+				var err error
+				if app, err = c.Appender(); err != nil {
+					return err
+				}
+				minT = t
+			}
+			maxT = t
+			app.Append(t, a.Values[i])
+
+			if c.NumSamples() < promChunkSamples {
+				continue
+			}
+			endChunk()
+			if size >= promMaxFrameBytes {
+				if err := cw.writeFrame(labels, chunks); err != nil {
+					return err
+				}
+				chunks, size = nil, 0
+			}
+		}
+	}
+
+	endChunk()
+	if len(chunks) == 0 {
+		return nil
+	}
+	return cw.writeFrame(labels, chunks)
+}
+
+// writeFrame writes a frame of chunks of a series and flushes it.
+func (cw *promChunkedWriter) writeFrame(labels []prompb.Label, chunks []prompb.Chunk) error {
+	resp := prompb.ChunkedReadResponse{
+		ChunkedSeries: []*prompb.ChunkedSeries{{Labels: labels, Chunks: chunks}},
+	}
+	data, err := resp.Marshal()
+	if err != nil {
+		return err
+	}
+
+	var hdr [binary.MaxVarintLen64 + 4]byte
+	n := binary.PutUvarint(hdr[:], uint64(len(data)))
+	binary.BigEndian.PutUint32(hdr[n:], crc32.Checksum(data, castagnoliTable))
+	n += 4
+	if _, err := cw.w.Write(hdr[:n]); err != nil {
+		return err
+	} else if _, err := cw.w.Write(data); err != nil {
+		return err
+	}
+	cw.n += int64(n + len(data))
+
+	if f, ok := cw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -299,6 +299,9 @@ func (f *msgpackFormatter) WriteResponse(w io.Writer, resp Response) (err error)
 			if result.Partial {
 				sz++
 			}
+			if result.Next != "" {
+				sz++
+			}
 			enc.WriteMapHeader(uint32(sz))
 			enc.WriteString("statement_id")
 			enc.WriteInt(result.StatementID)
@@ -361,6 +364,10 @@ func (f *msgpackFormatter) WriteResponse(w io.Writer, resp Response) (err error)
 				enc.WriteString("partial")
 				enc.WriteBool(true)
 			}
+			if result.Next != "" {
+				enc.WriteString("next")
+				enc.WriteString(result.Next)
+			}
 		}
 	}
 	return nil
@@ -6,6 +6,9 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"io"
 	"math/rand"
@@ -13,6 +16,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +32,16 @@ const (
 	// SaltBytes is the number of bytes used for salts.
 	SaltBytes = 32
 
+	// TokenIDBytes and TokenSecretBytes are the number of random bytes of
+	// the ID and of the secret of an API token.
+	TokenIDBytes     = 8
+	TokenSecretBytes = 32
+
+	// TokenLastUsedResolution is the resolution of the last use of a token
+	// that is recorded, so that authenticating with a token doesn't commit
+	// the meta data on every request.
+	TokenLastUsedResolution = time.Minute
+
 	metaFile = "meta.db"
 
 	// ShardGroupDeletedExpiration is the amount of time before a shard group info will be removed from cached
@@ -56,6 +70,12 @@ type Client struct {
 	// Authentication cache.
 	authCache map[string]authUser
 
+	// Authenticates the users that are not stored in the meta data.
+	ldap *ldapAuthenticator
+
+	// Hashes the passwords of the users stored in the meta data.
+	passwords *passwordHasher
+
 	path string
 
 	retentionAutoCreate bool
@@ -69,7 +89,7 @@ type authUser struct {
 
 // NewClient returns a new *Client.
 func NewClient(config *Config) *Client {
-	return &Client{
+	c := &Client{
 		cacheData: &Data{
 			ClusterID: uint64(rand.Int63()),
 			Index:     1,
@@ -80,7 +100,12 @@ func NewClient(config *Config) *Client {
 		authCache:           make(map[string]authUser),
 		path:                config.Dir,
 		retentionAutoCreate: config.RetentionAutoCreate,
+		passwords:           newPasswordHasher(config.Password),
+	}
+	if config.LDAP.Enabled {
+		c.ldap = newLDAPAuthenticator(config.LDAP, c.saltedHash, c.hashWithSalt)
 	}
+	return c
 }
 
 // Open a connection to a meta service cluster.
@@ -119,6 +144,10 @@ func (c *Client) Close() error {
 		close(c.closing)
 	}
 
+	if c.ldap != nil {
+		c.ldap.close()
+	}
+
 	return nil
 }
 
@@ -264,6 +293,62 @@ func (c *Client) CreateDatabaseWithRetentionPolicy(name string, spec *RetentionP
 	return db, nil
 }
 
+// SetDatabaseQuota replaces the quota of a database. A zero quota removes
+// every limit.
+func (c *Client) SetDatabaseQuota(name string, q QuotaInfo) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetDatabaseQuota(name, q); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameDatabase renames a database.
+func (c *Client) RenameDatabase(oldName, newName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.RenameDatabase(oldName, newName); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameMeasurement moves the privileges on a measurement of a database to a
+// new name.
+func (c *Client) RenameMeasurement(database, oldName, newName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.RenameMeasurement(database, oldName, newName); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // DropDatabase deletes a database.
 func (c *Client) DropDatabase(name string) error {
 	c.mu.Lock()
@@ -367,14 +452,21 @@ func (c *Client) Users() []UserInfo {
 	return users
 }
 
-// User returns the user with the given name, or ErrUserNotFound.
+// User returns the user with the given name, or ErrUserNotFound. Users
+// authenticated with LDAP are returned while their authentication is cached.
 func (c *Client) User(name string) (User, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	for _, u := range c.cacheData.Users {
 		if u.Name == name {
-			return &u, nil
+			return c.cacheData.withRoles(&u), nil
+		}
+	}
+
+	if c.ldap != nil {
+		if u := c.ldap.user(name); u != nil {
+			return u, nil
 		}
 	}
 
@@ -412,19 +504,25 @@ func (c *Client) CreateUser(name, password string, admin bool) (User, error) {
 
 	// See if the user already exists.
 	if u := data.user(name); u != nil {
-		if err := bcrypt.CompareHashAndPassword([]byte(u.Hash), []byte(password)); err != nil || u.Admin != admin {
+		if err := c.passwords.compare(u.Hash, password); err != nil || u.Admin != admin {
 			return nil, ErrUserExists
 		}
 		return u, nil
 	}
 
+	if err := c.passwords.checkPolicy(password); err != nil {
+		return nil, err
+	}
+
 	// Hash the password before serializing it.
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	hash, err := c.passwords.hash(password)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := data.CreateUser(name, string(hash), admin); err != nil {
+	if err := data.CreateUser(name, hash, admin); err != nil {
+		return nil, err
+	} else if err := data.SetUserPasswordChangedAt(name, time.Now().UTC()); err != nil {
 		return nil, err
 	}
 
@@ -444,13 +542,19 @@ func (c *Client) UpdateUser(name, password string) error {
 
 	data := c.cacheData.Clone()
 
+	if err := c.passwords.checkPolicy(password); err != nil {
+		return err
+	}
+
 	// Hash the password before serializing it.
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	hash, err := c.passwords.hash(password)
 	if err != nil {
 		return err
 	}
 
-	if err := data.UpdateUser(name, string(hash)); err != nil {
+	if err := data.UpdateUser(name, hash); err != nil {
+		return err
+	} else if err := data.SetUserPasswordChangedAt(name, time.Now().UTC()); err != nil {
 		return err
 	}
 
@@ -495,6 +599,83 @@ func (c *Client) SetPrivilege(username, database string, p influxql.Privilege) e
 	return nil
 }
 
+// SetUserWriteTarget sets the database and retention policy the given user's
+// writes default to and are restricted to. An empty database removes the
+// restriction.
+func (c *Client) SetUserWriteTarget(username, database, rp string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetUserWriteTarget(username, database, rp); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetUserTimeZone sets the default time zone of the given user's queries.
+// An empty time zone resets it to UTC.
+func (c *Client) SetUserTimeZone(username, tz string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetUserTimeZone(username, tz); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetUserQueryPriority sets the priority class of the given user's queries.
+// An empty priority resets it to the default.
+func (c *Client) SetUserQueryPriority(username, priority string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetUserQueryPriority(username, priority); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetMeasurementPrivilege sets a privilege for the given user on the given
+// measurements of a database.
+func (c *Client) SetMeasurementPrivilege(username string, mp MeasurementPrivilege) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetMeasurementPrivilege(username, mp); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // SetAdminPrivilege sets or unsets admin privilege to the given username.
 func (c *Client) SetAdminPrivilege(username string, admin bool) error {
 	c.mu.Lock()
@@ -513,7 +694,8 @@ func (c *Client) SetAdminPrivilege(username string, admin bool) error {
 	return nil
 }
 
-// UserPrivileges returns the privileges for a user mapped by database name.
+// UserPrivileges returns the privileges for a user mapped by database name,
+// including the privileges of its roles.
 func (c *Client) UserPrivileges(username string) (map[string]influxql.Privilege, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -525,6 +707,18 @@ func (c *Client) UserPrivileges(username string) (map[string]influxql.Privilege,
 	return p, nil
 }
 
+// UserMeasurementPrivileges returns the privileges of a user on measurements.
+func (c *Client) UserMeasurementPrivileges(username string) ([]MeasurementPrivilege, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p, err := c.cacheData.UserMeasurementPrivileges(username)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
 // UserPrivilege returns the privilege for the given user on the given database.
 func (c *Client) UserPrivilege(username, database string) (*influxql.Privilege, error) {
 	c.mu.RLock()
@@ -537,6 +731,268 @@ func (c *Client) UserPrivilege(username, database string) (*influxql.Privilege,
 	return p, nil
 }
 
+// Roles returns a list of all roles.
+func (c *Client) Roles() []RoleInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cacheData.CloneRoles()
+}
+
+// CreateRole adds a role with the given name.
+func (c *Client) CreateRole(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.CreateRole(name); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DropRole removes the role with the given name and its members.
+func (c *Client) DropRole(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.DropRole(name); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetRolePrivilege sets a privilege for the given role on the given database.
+func (c *Client) SetRolePrivilege(role, database string, p influxql.Privilege) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetRolePrivilege(role, database, p); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RolePrivileges returns the privileges for a role mapped by database name.
+func (c *Client) RolePrivileges(role string) (map[string]influxql.Privilege, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p, err := c.cacheData.RolePrivileges(role)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// AddUserToRole makes the given user a member of the given role.
+func (c *Client) AddUserToRole(username, role string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.AddUserToRole(username, role); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveUserFromRole removes the given user from the members of the given role.
+func (c *Client) RemoveUserFromRole(username, role string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.RemoveUserFromRole(username, role); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UserRoles returns the names of the roles the given user is a member of.
+func (c *Client) UserRoles(username string) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ui := c.cacheData.user(username)
+	if ui == nil {
+		return nil, ErrUserNotFound
+	}
+	return append([]string(nil), ui.Roles...), nil
+}
+
+// CreateToken creates an API token that authenticates as the given user. The
+// token expires after ttl unless ttl is zero. The writes of the token are
+// restricted to the given database and retention policy if the database is
+// not empty. Only a hash of the token is stored so the token can't be
+// retrieved again.
+func (c *Client) CreateToken(username string, ttl time.Duration, database, rp string) (string, *TokenInfo, error) {
+	id := make([]byte, TokenIDBytes)
+	if _, err := io.ReadFull(crand.Reader, id); err != nil {
+		return "", nil, err
+	}
+	secret := make([]byte, TokenSecretBytes)
+	if _, err := io.ReadFull(crand.Reader, secret); err != nil {
+		return "", nil, err
+	}
+	token := hex.EncodeToString(id) + "." + base64.RawURLEncoding.EncodeToString(secret)
+
+	now := time.Now().UTC()
+	ti := TokenInfo{
+		ID:        hex.EncodeToString(id),
+		User:      username,
+		Hash:      hashToken(token),
+		CreatedAt: now,
+
+		WriteDatabase:        database,
+		WriteRetentionPolicy: rp,
+	}
+	if ttl > 0 {
+		ti.ExpiresAt = now.Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.CreateToken(ti); err != nil {
+		return "", nil, err
+	}
+
+	if err := c.commit(data); err != nil {
+		return "", nil, err
+	}
+
+	return token, &ti, nil
+}
+
+// Tokens returns a list of all API tokens.
+func (c *Client) Tokens() []TokenInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cacheData.CloneTokens()
+}
+
+// DropToken revokes the API token with the given ID.
+func (c *Client) DropToken(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.DropToken(id); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AuthenticateToken returns the user of the API token if the token exists
+// and is not expired. The write target of the token, if any, replaces the
+// one of the user.
+func (c *Client) AuthenticateToken(token string) (User, error) {
+	id, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrAuthenticate
+	}
+	now := time.Now().UTC()
+
+	c.mu.RLock()
+	ti := c.cacheData.Token(id)
+	if ti == nil || subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(ti.Hash)) != 1 {
+		c.mu.RUnlock()
+		return nil, ErrAuthenticate
+	} else if ti.Expired(now) {
+		c.mu.RUnlock()
+		return nil, ErrTokenExpired
+	}
+	lastUsed := ti.LastUsedAt
+	userInfo := c.cacheData.user(ti.User)
+	if userInfo != nil {
+		userInfo = c.cacheData.withRoles(userInfo)
+		if ti.WriteDatabase != "" {
+			other := *userInfo
+			other.WriteDatabase, other.WriteRetentionPolicy = ti.WriteDatabase, ti.WriteRetentionPolicy
+			userInfo = &other
+		}
+	}
+	c.mu.RUnlock()
+	if userInfo == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if now.Sub(lastUsed) >= TokenLastUsedResolution {
+		if err := c.setTokenLastUsed(id, now); err != nil {
+			c.logger.Info("Failed to record the last use of a token", zap.String("id", id), zap.Error(err))
+		}
+	}
+	return userInfo, nil
+}
+
+// setTokenLastUsed records the last use of a token unless another request
+// recorded it in the meantime.
+func (c *Client) setTokenLastUsed(id string, t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ti := c.cacheData.Token(id); ti == nil || t.Sub(ti.LastUsedAt) < TokenLastUsedResolution {
+		return nil
+	}
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetTokenLastUsed(id, t); err != nil {
+		return err
+	}
+
+	return c.commit(data)
+}
+
+// hashToken returns the hex encoded SHA-256 hash of an API token. Unlike
+// passwords, tokens are random so they don't need a slow salted hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // AdminUserExists returns true if any user has admin privilege.
 func (c *Client) AdminUserExists() bool {
 	c.mu.RLock()
@@ -545,12 +1001,23 @@ func (c *Client) AdminUserExists() bool {
 }
 
 // Authenticate returns a UserInfo if the username and password match an existing entry.
+// Users that do not exist locally are authenticated with LDAP if it is enabled.
 func (c *Client) Authenticate(username, password string) (User, error) {
 	// Find user.
 	c.mu.RLock()
 	userInfo := c.cacheData.user(username)
+	if userInfo != nil {
+		userInfo = c.cacheData.withRoles(userInfo)
+	}
 	c.mu.RUnlock()
 	if userInfo == nil {
+		if c.ldap != nil {
+			u, err := c.ldap.authenticate(username, password)
+			if err != nil {
+				return nil, err
+			}
+			return u, nil
+		}
 		return nil, ErrUserNotFound
 	}
 
@@ -558,19 +1025,27 @@ func (c *Client) Authenticate(username, password string) (User, error) {
 	c.mu.RLock()
 	au, ok := c.authCache[username]
 	c.mu.RUnlock()
-	if ok {
+	// The cache is stale if the hash was replaced without changing the
+	// password through the client, such as by SetData.
+	if ok && au.bhash == userInfo.Hash {
 		// verify the password using the cached salt and hash
 		if bytes.Equal(c.hashWithSalt(au.salt, password), au.hash) {
+			if c.passwords.expired(userInfo.PasswordChangedAt, time.Now()) {
+				return nil, ErrPasswordExpired
+			}
 			return userInfo, nil
 		}
 
-		// fall through to requiring a full bcrypt hash for invalid passwords
+		// fall through to requiring a full hash comparison for invalid passwords
 	}
 
 	// Compare password with user hash.
-	if err := bcrypt.CompareHashAndPassword([]byte(userInfo.Hash), []byte(password)); err != nil {
+	if err := c.passwords.compare(userInfo.Hash, password); err != nil {
 		return nil, ErrAuthenticate
+	} else if c.passwords.expired(userInfo.PasswordChangedAt, time.Now()) {
+		return nil, ErrPasswordExpired
 	}
+	c.rehashPassword(userInfo, password)
 
 	// generate a salt and hash of the password for the cache
 	salt, hashed, err := c.saltedHash(password)
@@ -583,6 +1058,46 @@ func (c *Client) Authenticate(username, password string) (User, error) {
 	return userInfo, nil
 }
 
+// rehashPassword replaces the hash of the password of the user if the
+// algorithm or its parameters changed since the password was set. It also
+// records when the password was set if passwords expire and the time is
+// unknown. Errors are only logged as the user is authenticated anyway.
+func (c *Client) rehashPassword(u *UserInfo, password string) {
+	rehash := c.passwords.needsRehash(u.Hash)
+	record := c.passwords.config.Expiry > 0 && u.PasswordChangedAt.IsZero()
+	if !rehash && !record {
+		return
+	}
+
+	hash := u.Hash
+	if rehash {
+		var err error
+		if hash, err = c.passwords.hash(password); err != nil {
+			c.logger.Info("Failed to rehash password", zap.String("user", u.Name), zap.Error(err))
+			return
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The password may have been changed in the meantime.
+	data := c.cacheData.Clone()
+	if other := data.user(u.Name); other == nil || other.Hash != u.Hash {
+		return
+	}
+	if err := data.UpdateUser(u.Name, hash); err != nil {
+		return
+	} else if record {
+		if err := data.SetUserPasswordChangedAt(u.Name, time.Now().UTC()); err != nil {
+			return
+		}
+	}
+	if err := c.commit(data); err != nil {
+		c.logger.Info("Failed to rehash password", zap.String("user", u.Name), zap.Error(err))
+	}
+}
+
 // UserCount returns the number of users stored.
 func (c *Client) UserCount() int {
 	c.mu.RLock()
@@ -676,6 +1191,19 @@ func (c *Client) TruncateShardGroups(t time.Time) error {
 	return c.commit(data)
 }
 
+// TruncateDatabaseShardGroups truncates the shard groups of a database, or
+// of one of its retention policies, that could contain timestamps beyond t.
+func (c *Client) TruncateDatabaseShardGroups(database, policy string, t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+	if err := data.TruncateDatabaseShardGroups(database, policy, t); err != nil {
+		return err
+	}
+	return c.commit(data)
+}
+
 // PruneShardGroups remove deleted shard groups from the data store.
 func (c *Client) PruneShardGroups() error {
 	expiration := time.Now().Add(ShardGroupDeletedExpiration)
@@ -927,6 +1455,29 @@ func (c *Client) SetData(data *Data) error {
 	return nil
 }
 
+// Schema returns the declarative description of the databases, retention
+// policies, continuous queries, subscriptions and users.
+func (c *Client) Schema() *Schema {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cacheData.Schema()
+}
+
+// SchemaStatements returns the statements that change the meta data to match
+// the schema. The objects that are not in the schema are dropped if prune is
+// true. The statements are not executed.
+func (c *Client) SchemaStatements(s *Schema, prune bool) ([]influxql.Statement, error) {
+	// Passwords are compared without holding the lock as hashing is slow.
+	c.mu.RLock()
+	data := c.cacheData.Clone()
+	c.mu.RUnlock()
+
+	return data.schemaStatements(s, prune, func(u *UserInfo, password string) bool {
+		return c.passwords.compare(u.Hash, password) == nil
+	})
+}
+
 // Data returns a clone of the underlying data in the meta store.
 func (c *Client) Data() Data {
 	c.mu.RLock()
@@ -975,6 +1526,9 @@ func (c *Client) WithLogger(log *zap.Logger) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.logger = log.With(zap.String("service", "metaclient"))
+	if c.ldap != nil {
+		c.ldap.logger = c.logger
+	}
 }
 
 // snapshot saves the current meta data to disk.
@@ -10,7 +10,10 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkg/ldap"
+	"github.com/influxdata/influxdb/pkg/ldap/ldaptest"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxql"
 )
 
@@ -756,6 +759,354 @@ func TestMetaClient_UpdateUser(t *testing.T) {
 	}
 }
 
+func TestMetaClient_SetUserTimeZone(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// SetUserTimeZone for a user that doesn't exist should return an error.
+	if err := c.SetUserTimeZone("fred", "America/New_York"); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.CreateUser("fred", "supersecure", false); err != nil {
+		t.Fatal(err)
+	} else if err := c.SetUserTimeZone("fred", "America/New_York"); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	// The time zone should be persisted.
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	u, err := c.User("fred")
+	if err != nil {
+		t.Fatal(err)
+	} else if got, exp := u.(*meta.UserInfo).TimeZone, "America/New_York"; got != exp {
+		t.Fatalf("unexpected time zone: got %q, exp %q", got, exp)
+	}
+
+	// An empty time zone resets the user to UTC.
+	if err := c.SetUserTimeZone("fred", ""); err != nil {
+		t.Fatal(err)
+	}
+	u, err = c.User("fred")
+	if err != nil {
+		t.Fatal(err)
+	} else if got := u.(*meta.UserInfo).TimeZone; got != "" {
+		t.Fatalf("unexpected time zone: %q", got)
+	}
+}
+
+func TestMetaClient_SetUserQueryPriority(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SetUserQueryPriority("fred", "batch"); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.CreateUser("fred", "supersecure", false); err != nil {
+		t.Fatal(err)
+	} else if err := c.SetUserQueryPriority("fred", "batch"); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	// The priority should be persisted.
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	u, err := c.User("fred")
+	if err != nil {
+		t.Fatal(err)
+	} else if got, exp := u.(*meta.UserInfo).QueryPriority, "batch"; got != exp {
+		t.Fatalf("unexpected query priority: got %q, exp %q", got, exp)
+	}
+}
+
+func TestMetaClient_LDAP(t *testing.T) {
+	t.Parallel()
+
+	s, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.AddEntry(&ldap.Entry{DN: "cn=influxdb,dc=example,dc=com"}, "secret")
+	s.AddEntry(&ldap.Entry{
+		DN: "uid=alice,ou=people,dc=example,dc=com",
+		Attributes: map[string][]string{
+			"uid":      {"alice"},
+			"memberOf": {"cn=Admins,ou=groups,dc=example,dc=com"},
+		},
+	}, "alicepw")
+	s.AddEntry(&ldap.Entry{
+		DN: "uid=bob,ou=people,dc=example,dc=com",
+		Attributes: map[string][]string{
+			"uid":      {"bob"},
+			"memberOf": {"cn=ops,ou=groups,dc=example,dc=com", "cn=dev,ou=groups,dc=example,dc=com"},
+		},
+	}, "bobpw")
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+	cfg.LDAP.Enabled = true
+	cfg.LDAP.URL = s.URL()
+	cfg.LDAP.BindDN = "cn=influxdb,dc=example,dc=com"
+	cfg.LDAP.BindPassword = "secret"
+	cfg.LDAP.SearchBaseDN = "ou=people,dc=example,dc=com"
+	cfg.LDAP.Groups = []meta.LDAPGroup{
+		{DN: "cn=admins,ou=groups,dc=example,dc=com", Admin: true},
+		{DN: "cn=ops,ou=groups,dc=example,dc=com", Privileges: map[string]string{"db0": "READ"}},
+		{DN: "cn=dev,ou=groups,dc=example,dc=com", Privileges: map[string]string{"db0": "WRITE", "db1": "ALL"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Local users take precedence over the directory.
+	if _, err := c.CreateUser("admin", "adminpw", true); err != nil {
+		t.Fatal(err)
+	} else if u, err := c.Authenticate("admin", "adminpw"); err != nil {
+		t.Fatal(err)
+	} else if !isAdmin(u) {
+		t.Fatal("expected local admin user")
+	} else if got := s.Searches(); got != 0 {
+		t.Fatalf("unexpected number of searches: %d", got)
+	}
+
+	// Members of an admin group are admins.
+	if u, err := c.Authenticate("alice", "alicepw"); err != nil {
+		t.Fatal(err)
+	} else if !isAdmin(u) {
+		t.Fatal("expected alice to be admin")
+	}
+
+	// The privileges of the groups are merged.
+	u, err := c.Authenticate("bob", "bobpw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := u.(*meta.UserInfo)
+	if ui.Admin {
+		t.Fatal("expected bob not to be admin")
+	} else if got, exp := ui.Privileges["db0"], influxql.AllPrivileges; got != exp {
+		t.Fatalf("unexpected privilege on db0: got %s, exp %s", got, exp)
+	} else if got, exp := ui.Privileges["db1"], influxql.AllPrivileges; got != exp {
+		t.Fatalf("unexpected privilege on db1: got %s, exp %s", got, exp)
+	}
+
+	// Authentications are cached.
+	searches := s.Searches()
+	if _, err := c.Authenticate("bob", "bobpw"); err != nil {
+		t.Fatal(err)
+	} else if got := s.Searches(); got != searches {
+		t.Fatalf("unexpected number of searches: got %d, exp %d", got, searches)
+	}
+
+	// The cached users can be looked up.
+	if u, err := c.User("bob"); err != nil {
+		t.Fatal(err)
+	} else if u.ID() != "bob" {
+		t.Fatalf("unexpected user: %s", u.ID())
+	}
+
+	// A wrong password is checked against the directory.
+	if _, err := c.Authenticate("bob", "wrong"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if got := s.Searches(); got != searches+1 {
+		t.Fatalf("unexpected number of searches: got %d, exp %d", got, searches+1)
+	}
+
+	// Unknown users are neither in the meta data nor in the directory.
+	if _, err := c.Authenticate("carol", "carolpw"); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.User("carol"); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Empty passwords are rejected without querying the directory.
+	searches = s.Searches()
+	if _, err := c.Authenticate("alice", ""); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if got := s.Searches(); got != searches {
+		t.Fatalf("unexpected number of searches: got %d, exp %d", got, searches)
+	}
+}
+
+func TestMetaClient_Tokens(t *testing.T) {
+	t.Parallel()
+
+	d, c := newClient()
+	defer os.RemoveAll(d)
+	defer c.Close()
+
+	if _, err := c.CreateUser("bob", "password", false); err != nil {
+		t.Fatal(err)
+	} else if _, _, err := c.CreateToken("alice", 0, "", ""); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, ti, err := c.CreateToken("bob", 0, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.HasPrefix(token, ti.ID+".") || strings.Contains(token, ":") {
+		t.Fatalf("unexpected token: %s", token)
+	} else if ti.Hash == "" || strings.Contains(ti.Hash, token) {
+		t.Fatalf("unexpected hash: %s", ti.Hash)
+	} else if !ti.ExpiresAt.IsZero() {
+		t.Fatalf("unexpected expiry: %v", ti.ExpiresAt)
+	}
+
+	// The token authenticates as its user and records its last use.
+	if u, err := c.AuthenticateToken(token); err != nil {
+		t.Fatal(err)
+	} else if u.ID() != "bob" {
+		t.Fatalf("unexpected user: %s", u.ID())
+	} else if tokens := c.Tokens(); len(tokens) != 1 || tokens[0].LastUsedAt.IsZero() {
+		t.Fatalf("unexpected tokens: %v", tokens)
+	}
+
+	for _, s := range []string{"", "nodot", ti.ID, ti.ID + ".wrong", "0000000000000000" + token[len(ti.ID):]} {
+		if u, err := c.AuthenticateToken(s); u != nil || err != meta.ErrAuthenticate {
+			t.Fatalf("authentication with %q should fail with %s: %v", s, meta.ErrAuthenticate, err)
+		}
+	}
+
+	// An expired token is rejected.
+	expired, _, err := c.CreateToken("bob", time.Nanosecond, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := c.AuthenticateToken(expired); err != meta.ErrTokenExpired {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A rotated token keeps working until the previous token is dropped.
+	rotated, _, err := c.CreateToken("bob", time.Hour, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if err := c.DropToken(ti.ID); err != nil {
+		t.Fatal(err)
+	} else if err := c.DropToken(ti.ID); err != meta.ErrTokenNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.AuthenticateToken(token); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.AuthenticateToken(rotated); err != nil {
+		t.Fatal(err)
+	}
+
+	// Dropping the user revokes its tokens.
+	if err := c.DropUser("bob"); err != nil {
+		t.Fatal(err)
+	} else if tokens := c.Tokens(); len(tokens) != 0 {
+		t.Fatalf("unexpected tokens: %v", tokens)
+	}
+}
+
+func TestMetaClient_WriteTargets(t *testing.T) {
+	t.Parallel()
+
+	d, c := newClient()
+	defer os.RemoveAll(d)
+	defer c.Close()
+
+	if _, err := c.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.CreateUser("bob", "password", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SetUserWriteTarget("alice", "db0", ""); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.SetUserWriteTarget("bob", "db1", ""); err == nil || err.Error() != "database not found: db1" {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.SetUserWriteTarget("bob", "db0", "rp1"); err == nil || err.Error() != "retention policy not found: rp1" {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, _, err := c.CreateToken("bob", 0, "db1", ""); err == nil || err.Error() != "database not found: db1" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := c.SetUserWriteTarget("bob", "db0", "autogen"); err != nil {
+		t.Fatal(err)
+	} else if u, err := c.User("bob"); err != nil {
+		t.Fatal(err)
+	} else if ui := u.(*meta.UserInfo); ui.WriteDatabase != "db0" || ui.WriteRetentionPolicy != "autogen" {
+		t.Fatalf("unexpected write target: %s.%s", ui.WriteDatabase, ui.WriteRetentionPolicy)
+	}
+
+	// A token without a write target has the one of its user.
+	userToken, _, err := c.CreateToken("bob", 0, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if u, err := c.AuthenticateToken(userToken); err != nil {
+		t.Fatal(err)
+	} else if ui := u.(*meta.UserInfo); ui.WriteDatabase != "db0" || ui.WriteRetentionPolicy != "autogen" {
+		t.Fatalf("unexpected write target: %s.%s", ui.WriteDatabase, ui.WriteRetentionPolicy)
+	}
+
+	// The write target of a token replaces the one of its user.
+	if _, err := c.CreateDatabase("db1"); err != nil {
+		t.Fatal(err)
+	}
+	token, ti, err := c.CreateToken("bob", 0, "db1", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if ti.WriteDatabase != "db1" {
+		t.Fatalf("unexpected write database: %s", ti.WriteDatabase)
+	} else if u, err := c.AuthenticateToken(token); err != nil {
+		t.Fatal(err)
+	} else if ui := u.(*meta.UserInfo); ui.WriteDatabase != "db1" || ui.WriteRetentionPolicy != "" {
+		t.Fatalf("unexpected write target: %s.%s", ui.WriteDatabase, ui.WriteRetentionPolicy)
+	} else if u, err := c.User("bob"); err != nil {
+		t.Fatal(err)
+	} else if ui := u.(*meta.UserInfo); ui.WriteDatabase != "db0" {
+		t.Fatalf("unexpected write database of the user: %s", ui.WriteDatabase)
+	}
+
+	// Renaming a database moves the write targets.
+	if err := c.RenameDatabase("db1", "db2"); err != nil {
+		t.Fatal(err)
+	} else if tokens := c.Tokens(); tokens[1].WriteDatabase != "db2" {
+		t.Fatalf("unexpected write database of the token: %s", tokens[1].WriteDatabase)
+	}
+
+	if err := c.SetUserWriteTarget("bob", "", ""); err != nil {
+		t.Fatal(err)
+	} else if u, err := c.User("bob"); err != nil {
+		t.Fatal(err)
+	} else if ui := u.(*meta.UserInfo); ui.WriteDatabase != "" || ui.WriteRetentionPolicy != "" {
+		t.Fatalf("unexpected write target: %s.%s", ui.WriteDatabase, ui.WriteRetentionPolicy)
+	}
+}
+
 func TestMetaClient_ContinuousQueries(t *testing.T) {
 	t.Parallel()
 
@@ -1168,3 +1519,334 @@ func isAdmin(u meta.User) bool {
 	ui := u.(*meta.UserInfo)
 	return ui.Admin
 }
+
+func TestMetaClient_Schema(t *testing.T) {
+	t.Parallel()
+
+	d, c := newClient()
+	defer os.RemoveAll(d)
+	defer c.Close()
+
+	duration := 24 * time.Hour
+	if _, err := c.CreateDatabaseWithRetentionPolicy("db0", &meta.RetentionPolicySpec{Name: "rp0", Duration: &duration}); err != nil {
+		t.Fatal(err)
+	} else if err := c.CreateContinuousQuery("db0", "cq0", `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT count(value) INTO db0.rp0.m1 FROM db0.rp0.m0 GROUP BY time(1h) END`); err != nil {
+		t.Fatal(err)
+	} else if err := c.CreateSubscription("db0", "rp0", "sub0", "ALL", []string{"udp://h0:9093"}); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.CreateUser("bob", "password", false); err != nil {
+		t.Fatal(err)
+	} else if err := c.SetPrivilege("bob", "db0", influxql.ReadPrivilege); err != nil {
+		t.Fatal(err)
+	}
+
+	s := c.Schema()
+	exp := &meta.Schema{
+		Databases: []meta.SchemaDatabase{{
+			Name:                   "db0",
+			DefaultRetentionPolicy: "rp0",
+			RetentionPolicies: []meta.SchemaRetentionPolicy{{
+				Name:               "rp0",
+				Duration:           toml.Duration(24 * time.Hour),
+				ShardGroupDuration: toml.Duration(time.Hour),
+				ReplicaN:           1,
+				Subscriptions:      []meta.SchemaSubscription{{Name: "sub0", Mode: "ALL", Destinations: []string{"udp://h0:9093"}}},
+			}},
+			ContinuousQueries: []meta.SchemaContinuousQuery{{
+				Name:  "cq0",
+				Query: `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT count(value) INTO db0.rp0.m1 FROM db0.rp0.m0 GROUP BY time(1h) END`,
+			}},
+		}},
+		Users: []meta.SchemaUser{{Name: "bob", Privileges: map[string]string{"db0": "READ"}}},
+	}
+	if !reflect.DeepEqual(s, exp) {
+		t.Fatalf("unexpected schema:\ngot: %#v\nexp: %#v", s, exp)
+	}
+
+	// Applying the schema of the meta data does not change anything.
+	if stmts, err := c.SchemaStatements(s, true); err != nil {
+		t.Fatal(err)
+	} else if len(stmts) != 0 {
+		t.Fatalf("unexpected statements: %v", stmts)
+	}
+
+	// Change the schema.
+	s.Databases[0].DefaultRetentionPolicy = "rp1"
+	s.Databases[0].RetentionPolicies[0].Duration = toml.Duration(48 * time.Hour)
+	s.Databases[0].RetentionPolicies[0].Subscriptions[0].Destinations = []string{"udp://h1:9093"}
+	s.Databases[0].RetentionPolicies = append(s.Databases[0].RetentionPolicies, meta.SchemaRetentionPolicy{Name: "rp1"})
+	s.Databases[0].ContinuousQueries = nil
+	s.Databases = append(s.Databases, meta.SchemaDatabase{
+		Name:                   "db1",
+		DefaultRetentionPolicy: "autogen",
+		RetentionPolicies:      []meta.SchemaRetentionPolicy{{Name: "autogen", ShardGroupDuration: toml.Duration(7 * 24 * time.Hour)}},
+	})
+	s.Users[0].Privileges = map[string]string{"db1": "ALL"}
+	s.Users[0].Password = "password"
+	s.Users = append(s.Users, meta.SchemaUser{Name: "alice", Password: "secret", Admin: true})
+
+	for _, tt := range []struct {
+		prune bool
+		exp   []string
+	}{
+		{
+			exp: []string{
+				`ALTER RETENTION POLICY rp0 ON db0 DURATION 2d`,
+				`DROP SUBSCRIPTION sub0 ON db0.rp0`,
+				`CREATE SUBSCRIPTION sub0 ON db0.rp0 DESTINATIONS ALL 'udp://h1:9093'`,
+				`CREATE RETENTION POLICY rp1 ON db0 DURATION 0s REPLICATION 1 DEFAULT`,
+				`CREATE DATABASE db1 WITH DURATION 0s REPLICATION 1 SHARD DURATION 168h0m0s NAME autogen`,
+				`REVOKE ALL PRIVILEGES ON db0 FROM bob`,
+				`GRANT ALL PRIVILEGES ON db1 TO bob`,
+				`CREATE USER alice WITH PASSWORD [REDACTED] WITH ALL PRIVILEGES`,
+			},
+		},
+		{
+			prune: true,
+			exp: []string{
+				`ALTER RETENTION POLICY rp0 ON db0 DURATION 2d`,
+				`DROP SUBSCRIPTION sub0 ON db0.rp0`,
+				`CREATE SUBSCRIPTION sub0 ON db0.rp0 DESTINATIONS ALL 'udp://h1:9093'`,
+				`CREATE RETENTION POLICY rp1 ON db0 DURATION 0s REPLICATION 1 DEFAULT`,
+				`DROP CONTINUOUS QUERY cq0 ON db0`,
+				`CREATE DATABASE db1 WITH DURATION 0s REPLICATION 1 SHARD DURATION 168h0m0s NAME autogen`,
+				`REVOKE ALL PRIVILEGES ON db0 FROM bob`,
+				`GRANT ALL PRIVILEGES ON db1 TO bob`,
+				`CREATE USER alice WITH PASSWORD [REDACTED] WITH ALL PRIVILEGES`,
+			},
+		},
+	} {
+		stmts, err := c.SchemaStatements(s, tt.prune)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(stmts))
+		for i, stmt := range stmts {
+			got[i] = stmt.String()
+		}
+		if !reflect.DeepEqual(got, tt.exp) {
+			t.Fatalf("unexpected statements (prune=%v):\ngot: %s\nexp: %s", tt.prune, strings.Join(got, "\n"), strings.Join(tt.exp, "\n"))
+		}
+	}
+
+	// A new user requires a password and the queries must be continuous queries.
+	for _, tt := range []struct {
+		s   *meta.Schema
+		err string
+	}{
+		{
+			s:   &meta.Schema{Users: []meta.SchemaUser{{Name: "carol"}}},
+			err: `user "carol": password is required to create the user`,
+		},
+		{
+			s:   &meta.Schema{Users: []meta.SchemaUser{{Name: "bob", Privileges: map[string]string{"db0": "MAYBE"}}}},
+			err: `user "bob": database "db0": invalid privilege "MAYBE", must be READ, WRITE or ALL`,
+		},
+		{
+			s:   &meta.Schema{Databases: []meta.SchemaDatabase{{Name: "db0", DefaultRetentionPolicy: "rp9"}}},
+			err: `database "db0": default retention policy "rp9": retention policy not found`,
+		},
+		{
+			s:   &meta.Schema{Databases: []meta.SchemaDatabase{{Name: "db0", ContinuousQueries: []meta.SchemaContinuousQuery{{Name: "cq0", Query: "SELECT * FROM m0"}}}}},
+			err: `database "db0": continuous query "cq0": query must be a CREATE CONTINUOUS QUERY statement`,
+		},
+	} {
+		if _, err := c.SchemaStatements(tt.s, false); err == nil || err.Error() != tt.err {
+			t.Fatalf("unexpected error:\ngot: %v\nexp: %s", err, tt.err)
+		}
+	}
+}
+
+func TestMetaClient_PasswordHash(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.CreateUser("bob", "password", false); err != nil {
+		t.Fatal(err)
+	} else if hash := c.Data().Users[0].Hash; !strings.HasPrefix(hash, "$2a$") {
+		t.Fatalf("unexpected hash: %s", hash)
+	}
+	c.Close()
+
+	// The hash is replaced with an argon2id hash at the next authentication.
+	cfg.Password.Hash = meta.PasswordHashArgon2id
+	cfg.Password.Argon2Time, cfg.Password.Argon2Memory, cfg.Password.Argon2Threads = 1, 64*1024, 1
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Authenticate("bob", "wrong"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if hash := c.Data().Users[0].Hash; !strings.HasPrefix(hash, "$2a$") {
+		t.Fatalf("unexpected hash: %s", hash)
+	} else if _, err := c.Authenticate("bob", "password"); err != nil {
+		t.Fatal(err)
+	}
+	hash := c.Data().Users[0].Hash
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected hash: %s", hash)
+	}
+	c.Close()
+
+	// The argon2id hash authenticates the user and is replaced when its
+	// parameters change.
+	cfg.Password.Argon2Time = 2
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Authenticate("bob", "wrong"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.Authenticate("bob", "password"); err != nil {
+		t.Fatal(err)
+	} else if hash := c.Data().Users[0].Hash; !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=2,p=1$") {
+		t.Fatalf("unexpected hash: %s", hash)
+	}
+
+	// New passwords are hashed with argon2id.
+	if err := c.UpdateUser("bob", "secret"); err != nil {
+		t.Fatal(err)
+	} else if hash := c.Data().Users[0].Hash; !strings.HasPrefix(hash, "$argon2id$") {
+		t.Fatalf("unexpected hash: %s", hash)
+	} else if _, err := c.Authenticate("bob", "secret"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMetaClient_PasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+	cfg.Password.MinLength = 8
+	cfg.Password.RequireUppercase = true
+	cfg.Password.RequireDigit = true
+	cfg.Password.RequireSymbol = true
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for _, tt := range []struct {
+		password string
+		err      string
+	}{
+		{password: "Ab1!", err: "password must be at least 8 characters long"},
+		{password: "abcdefg1!", err: "password must contain an uppercase letter"},
+		{password: "Abcdefgh!", err: "password must contain a digit"},
+		{password: "Abcdefgh1", err: "password must contain a symbol"},
+	} {
+		if _, err := c.CreateUser("bob", tt.password, false); err == nil || err.Error() != tt.err {
+			t.Fatalf("unexpected error for %q: got %v, exp %s", tt.password, err, tt.err)
+		}
+	}
+
+	if _, err := c.CreateUser("bob", "Abcdefg1!", false); err != nil {
+		t.Fatal(err)
+	} else if err := c.UpdateUser("bob", "abc"); err == nil || err.Error() != "password must be at least 8 characters long" {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.UpdateUser("bob", "Hijklmn2?"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMetaClient_PasswordExpiry(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+	cfg.Password.Expiry = toml.Duration(time.Hour)
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	if _, err := c.CreateUser("bob", "password", false); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.Authenticate("bob", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	// setPasswordChangedAt changes the time the password was set.
+	setPasswordChangedAt := func(changedAt time.Time) {
+		data := c.Data()
+		if err := data.SetUserPasswordChangedAt("bob", changedAt); err != nil {
+			t.Fatal(err)
+		} else if err := c.SetData(&data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The password expires even if the authentication is cached.
+	setPasswordChangedAt(time.Now().Add(-2 * time.Hour))
+	if _, err := c.Authenticate("bob", "password"); err != meta.ErrPasswordExpired {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.Authenticate("bob", "wrong"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Changing the password renews it.
+	if err := c.UpdateUser("bob", "secret"); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.Authenticate("bob", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The time is recorded at the next authentication that isn't cached if it
+	// is unknown.
+	setPasswordChangedAt(time.Time{})
+	c.Close()
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Authenticate("bob", "secret"); err != nil {
+		t.Fatal(err)
+	} else if changedAt := c.Data().Users[0].PasswordChangedAt; changedAt.IsZero() {
+		t.Fatal("expected the time the password was set to be recorded")
+	}
+}
+
+func TestMetaClient_Authenticate_SetData(t *testing.T) {
+	t.Parallel()
+
+	d, c := newClient()
+	defer os.RemoveAll(d)
+	defer c.Close()
+
+	if _, err := c.CreateUser("bob", "password", false); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.Authenticate("bob", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the meta data with the data of another client, which changed the
+	// password.
+	od, other := newClient()
+	defer os.RemoveAll(od)
+	defer other.Close()
+	if _, err := other.CreateUser("bob", "secret", false); err != nil {
+		t.Fatal(err)
+	}
+	data := other.Data()
+	if err := c.SetData(&data); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cached authentication with the old password is not used.
+	if _, err := c.Authenticate("bob", "password"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.Authenticate("bob", "secret"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,9 +2,15 @@ package meta
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxql"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -13,6 +19,46 @@ const (
 
 	// DefaultLoggingEnabled determines if log messages are printed for the meta service.
 	DefaultLoggingEnabled = true
+
+	// DefaultLDAPSearchFilter is the default filter used to find the entry of
+	// a user in the directory.
+	DefaultLDAPSearchFilter = "(uid=%s)"
+
+	// DefaultLDAPGroupAttribute is the default attribute of the entry of a
+	// user that lists the groups the user is a member of.
+	DefaultLDAPGroupAttribute = "memberOf"
+
+	// DefaultLDAPPoolSize is the default number of idle connections kept
+	// open to the directory.
+	DefaultLDAPPoolSize = 4
+
+	// DefaultLDAPTimeout is the default timeout of the operations sent to
+	// the directory.
+	DefaultLDAPTimeout = 5 * time.Second
+
+	// DefaultLDAPCacheTTL is the default duration a successful
+	// authentication is cached for.
+	DefaultLDAPCacheTTL = 5 * time.Minute
+
+	// DefaultPasswordHash is the default algorithm of the hashes of the
+	// passwords of users.
+	DefaultPasswordHash = PasswordHashBcrypt
+
+	// DefaultArgon2Time is the default number of passes over the memory of
+	// argon2id.
+	DefaultArgon2Time = 3
+
+	// DefaultArgon2Memory is the default amount of memory used by argon2id.
+	DefaultArgon2Memory = 64 * 1024 * 1024
+
+	// DefaultArgon2Threads is the default number of threads used by argon2id.
+	DefaultArgon2Threads = 4
+)
+
+// Algorithms of the hashes of the passwords of users.
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
 )
 
 // Config represents the meta configuration.
@@ -21,6 +67,173 @@ type Config struct {
 
 	RetentionAutoCreate bool `toml:"retention-autocreate"`
 	LoggingEnabled      bool `toml:"logging-enabled"`
+
+	LDAP     LDAPConfig     `toml:"ldap"`
+	Password PasswordConfig `toml:"password"`
+}
+
+// PasswordConfig represents the configuration of the hashes of the passwords
+// of users and of the policy the passwords must satisfy.
+type PasswordConfig struct {
+	// Hash is the algorithm of the hashes of new passwords: bcrypt or
+	// argon2id. The hashes of the other algorithm, or with other
+	// parameters, are replaced when their user authenticates.
+	Hash string `toml:"hash"`
+
+	// BcryptCost is the cost of bcrypt. Zero is the default cost.
+	BcryptCost int `toml:"bcrypt-cost"`
+
+	Argon2Time    int       `toml:"argon2-time"`
+	Argon2Memory  toml.Size `toml:"argon2-memory"`
+	Argon2Threads int       `toml:"argon2-threads"`
+
+	// Policy of the passwords set for users.
+	MinLength        int  `toml:"min-length"`
+	RequireUppercase bool `toml:"require-uppercase"`
+	RequireLowercase bool `toml:"require-lowercase"`
+	RequireDigit     bool `toml:"require-digit"`
+	RequireSymbol    bool `toml:"require-symbol"`
+
+	// Expiry is the duration after which a password must be changed before
+	// its user can authenticate again. Zero disables the expiry.
+	Expiry toml.Duration `toml:"expiry"`
+}
+
+// NewPasswordConfig returns a password configuration with default values.
+func NewPasswordConfig() PasswordConfig {
+	return PasswordConfig{
+		Hash:          DefaultPasswordHash,
+		Argon2Time:    DefaultArgon2Time,
+		Argon2Memory:  DefaultArgon2Memory,
+		Argon2Threads: DefaultArgon2Threads,
+	}
+}
+
+// Validate returns an error if the config is invalid.
+func (c *PasswordConfig) Validate() error {
+	switch c.Hash {
+	case PasswordHashBcrypt:
+		if c.BcryptCost != 0 && (c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost) {
+			return fmt.Errorf("password bcrypt-cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case PasswordHashArgon2id:
+		if c.Argon2Time < 1 {
+			return errors.New("password argon2-time must be at least 1")
+		} else if c.Argon2Threads < 1 || c.Argon2Threads > 255 {
+			return errors.New("password argon2-threads must be between 1 and 255")
+		} else if c.Argon2Memory < toml.Size(8*1024*c.Argon2Threads) {
+			return errors.New("password argon2-memory must be at least 8KiB per thread")
+		}
+	default:
+		return fmt.Errorf("invalid password hash %q, must be bcrypt or argon2id", c.Hash)
+	}
+
+	if c.MinLength < 0 {
+		return errors.New("password min-length must not be negative")
+	} else if c.Expiry < 0 {
+		return errors.New("password expiry must not be negative")
+	}
+	return nil
+}
+
+// LDAPConfig represents the configuration of the authentication of users
+// against an LDAP directory, such as Active Directory.
+type LDAPConfig struct {
+	Enabled bool `toml:"enabled"`
+
+	// URL of the directory. The scheme is ldap or ldaps.
+	URL                string `toml:"url"`
+	StartTLS           bool   `toml:"start-tls"`
+	InsecureSkipVerify bool   `toml:"insecure-skip-verify"`
+
+	// Credentials of the account used to search for the entries of users.
+	BindDN       string `toml:"bind-dn"`
+	BindPassword string `toml:"bind-password"`
+
+	// The entry of a user is found in the subtree of the base DN with the
+	// filter, where %s is replaced by the escaped user name.
+	SearchBaseDN string `toml:"search-base-dn"`
+	SearchFilter string `toml:"search-filter"`
+
+	// GroupAttribute lists the DNs of the groups of a user.
+	GroupAttribute string      `toml:"group-attribute"`
+	Groups         []LDAPGroup `toml:"groups"`
+
+	PoolSize int           `toml:"pool-size"`
+	Timeout  toml.Duration `toml:"timeout"`
+	CacheTTL toml.Duration `toml:"cache-ttl"`
+}
+
+// LDAPGroup maps the members of a directory group to privileges.
+type LDAPGroup struct {
+	DN    string `toml:"dn"`
+	Admin bool   `toml:"admin"`
+
+	// Privileges maps database names to READ, WRITE or ALL.
+	Privileges map[string]string `toml:"privileges"`
+}
+
+// NewLDAPConfig returns an LDAP configuration with default values.
+func NewLDAPConfig() LDAPConfig {
+	return LDAPConfig{
+		SearchFilter:   DefaultLDAPSearchFilter,
+		GroupAttribute: DefaultLDAPGroupAttribute,
+		PoolSize:       DefaultLDAPPoolSize,
+		Timeout:        toml.Duration(DefaultLDAPTimeout),
+		CacheTTL:       toml.Duration(DefaultLDAPCacheTTL),
+	}
+}
+
+// Validate returns an error if the config is invalid.
+func (c *LDAPConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("invalid ldap url: %s", err)
+	} else if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return fmt.Errorf("invalid ldap url scheme %q, must be ldap or ldaps", u.Scheme)
+	} else if u.Host == "" {
+		return errors.New("ldap url must have a host")
+	} else if u.Scheme == "ldaps" && c.StartTLS {
+		return errors.New("ldap start-tls cannot be used with an ldaps url")
+	}
+
+	if c.SearchBaseDN == "" {
+		return errors.New("ldap search-base-dn must be specified")
+	} else if strings.Count(c.SearchFilter, "%s") != 1 {
+		return errors.New("ldap search-filter must contain %s exactly once")
+	} else if c.PoolSize < 0 {
+		return errors.New("ldap pool-size must not be negative")
+	}
+
+	for _, g := range c.Groups {
+		if g.DN == "" {
+			return errors.New("ldap group dn must be specified")
+		}
+		for db, p := range g.Privileges {
+			if _, err := parseLDAPPrivilege(p); err != nil {
+				return fmt.Errorf("ldap group %q: database %q: %s", g.DN, db, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseLDAPPrivilege parses the privilege of an LDAP group on a database.
+func parseLDAPPrivilege(s string) (influxql.Privilege, error) {
+	switch strings.ToUpper(s) {
+	case "READ":
+		return influxql.ReadPrivilege, nil
+	case "WRITE":
+		return influxql.WritePrivilege, nil
+	case "ALL", "ALL PRIVILEGES":
+		return influxql.AllPrivileges, nil
+	default:
+		return influxql.NoPrivileges, fmt.Errorf("invalid privilege %q, must be READ, WRITE or ALL", s)
+	}
 }
 
 // NewConfig builds a new configuration with default values.
@@ -28,6 +241,8 @@ func NewConfig() *Config {
 	return &Config{
 		RetentionAutoCreate: true,
 		LoggingEnabled:      DefaultLoggingEnabled,
+		LDAP:                NewLDAPConfig(),
+		Password:            NewPasswordConfig(),
 	}
 }
 
@@ -36,12 +251,17 @@ func (c *Config) Validate() error {
 	if c.Dir == "" {
 		return errors.New("Meta.Dir must be specified")
 	}
-	return nil
+	if err := c.LDAP.Validate(); err != nil {
+		return err
+	}
+	return c.Password.Validate()
 }
 
 // Diagnostics returns a diagnostics representation of a subset of the Config.
 func (c *Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
-		"dir": c.Dir,
+		"dir":           c.Dir,
+		"ldap-enabled":  c.LDAP.Enabled,
+		"password-hash": c.Password.Hash,
 	}), nil
 }
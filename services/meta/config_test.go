@@ -1,10 +1,13 @@
 package meta_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/influxdata/influxdb/services/meta"
+	itoml "github.com/influxdata/influxdb/toml"
 )
 
 func TestConfig_Parse(t *testing.T) {
@@ -24,3 +27,142 @@ logging-enabled = false
 		t.Fatalf("unexpected logging enabled: %v", c.LoggingEnabled)
 	}
 }
+
+func TestConfig_Parse_LDAP(t *testing.T) {
+	c := meta.NewConfig()
+	if _, err := toml.Decode(`
+dir = "/tmp/foo"
+
+[ldap]
+enabled = true
+url = "ldaps://ldap.example.com"
+bind-dn = "cn=influxdb,dc=example,dc=com"
+bind-password = "secret"
+search-base-dn = "ou=people,dc=example,dc=com"
+search-filter = "(sAMAccountName=%s)"
+
+[[ldap.groups]]
+dn = "cn=admins,ou=groups,dc=example,dc=com"
+admin = true
+
+[[ldap.groups]]
+dn = "cn=ops,ou=groups,dc=example,dc=com"
+privileges = { telegraf = "WRITE", metrics = "ALL" }
+`, c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	} else if !c.LDAP.Enabled {
+		t.Fatal("expected ldap to be enabled")
+	} else if got, exp := c.LDAP.SearchFilter, "(sAMAccountName=%s)"; got != exp {
+		t.Fatalf("unexpected search filter: got %s, exp %s", got, exp)
+	} else if got, exp := c.LDAP.GroupAttribute, meta.DefaultLDAPGroupAttribute; got != exp {
+		t.Fatalf("unexpected group attribute: got %s, exp %s", got, exp)
+	} else if got, exp := len(c.LDAP.Groups), 2; got != exp {
+		t.Fatalf("unexpected number of groups: got %d, exp %d", got, exp)
+	} else if !c.LDAP.Groups[0].Admin {
+		t.Fatal("expected first group to be admin")
+	} else if got, exp := c.LDAP.Groups[1].Privileges["telegraf"], "WRITE"; got != exp {
+		t.Fatalf("unexpected privilege: got %s, exp %s", got, exp)
+	}
+}
+
+func TestConfig_Validate_LDAP(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		fn   func(c *meta.LDAPConfig)
+		err  string
+	}{
+		{name: "Scheme", fn: func(c *meta.LDAPConfig) { c.URL = "http://ldap.example.com" }, err: "invalid ldap url scheme"},
+		{name: "Host", fn: func(c *meta.LDAPConfig) { c.URL = "ldap://" }, err: "must have a host"},
+		{name: "StartTLS", fn: func(c *meta.LDAPConfig) { c.URL, c.StartTLS = "ldaps://ldap.example.com", true }, err: "start-tls"},
+		{name: "SearchBaseDN", fn: func(c *meta.LDAPConfig) { c.SearchBaseDN = "" }, err: "search-base-dn"},
+		{name: "SearchFilter", fn: func(c *meta.LDAPConfig) { c.SearchFilter = "(uid=bob)" }, err: "search-filter"},
+		{name: "PoolSize", fn: func(c *meta.LDAPConfig) { c.PoolSize = -1 }, err: "pool-size"},
+		{name: "GroupDN", fn: func(c *meta.LDAPConfig) { c.Groups = []meta.LDAPGroup{{Admin: true}} }, err: "group dn"},
+		{
+			name: "Privilege",
+			fn: func(c *meta.LDAPConfig) {
+				c.Groups = []meta.LDAPGroup{{DN: "cn=ops", Privileges: map[string]string{"db0": "DELETE"}}}
+			},
+			err: `database "db0"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := meta.NewLDAPConfig()
+			c.Enabled = true
+			c.URL = "ldap://ldap.example.com"
+			c.SearchBaseDN = "dc=example,dc=com"
+			if err := c.Validate(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			tt.fn(&c)
+			if err := c.Validate(); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("unexpected error: got %v, exp %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConfig_Parse_Password(t *testing.T) {
+	c := meta.NewConfig()
+	if _, err := toml.Decode(`
+dir = "/tmp/foo"
+
+[password]
+hash = "argon2id"
+argon2-memory = "32m"
+min-length = 12
+require-digit = true
+expiry = "2160h"
+`, c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	} else if got, exp := c.Password.Hash, meta.PasswordHashArgon2id; got != exp {
+		t.Fatalf("unexpected hash: got %s, exp %s", got, exp)
+	} else if got, exp := c.Password.Argon2Memory, itoml.Size(32*1024*1024); got != exp {
+		t.Fatalf("unexpected argon2 memory: got %d, exp %d", got, exp)
+	} else if got, exp := c.Password.Argon2Time, meta.DefaultArgon2Time; got != exp {
+		t.Fatalf("unexpected argon2 time: got %d, exp %d", got, exp)
+	} else if got, exp := c.Password.MinLength, 12; got != exp {
+		t.Fatalf("unexpected min length: got %d, exp %d", got, exp)
+	} else if !c.Password.RequireDigit || c.Password.RequireSymbol {
+		t.Fatalf("unexpected complexity: %+v", c.Password)
+	} else if got, exp := time.Duration(c.Password.Expiry), 90*24*time.Hour; got != exp {
+		t.Fatalf("unexpected expiry: got %s, exp %s", got, exp)
+	}
+}
+
+func TestConfig_Validate_Password(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		fn   func(c *meta.PasswordConfig)
+		err  string
+	}{
+		{name: "Hash", fn: func(c *meta.PasswordConfig) { c.Hash = "md5" }, err: "invalid password hash"},
+		{name: "BcryptCost", fn: func(c *meta.PasswordConfig) { c.BcryptCost = 99 }, err: "bcrypt-cost"},
+		{name: "Argon2Time", fn: func(c *meta.PasswordConfig) { c.Hash, c.Argon2Time = "argon2id", 0 }, err: "argon2-time"},
+		{name: "Argon2Threads", fn: func(c *meta.PasswordConfig) { c.Hash, c.Argon2Threads = "argon2id", 0 }, err: "argon2-threads"},
+		{name: "Argon2Memory", fn: func(c *meta.PasswordConfig) { c.Hash, c.Argon2Memory = "argon2id", 1024 }, err: "argon2-memory"},
+		{name: "MinLength", fn: func(c *meta.PasswordConfig) { c.MinLength = -1 }, err: "min-length"},
+		{name: "Expiry", fn: func(c *meta.PasswordConfig) { c.Expiry = -1 }, err: "expiry"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := meta.NewPasswordConfig()
+			if err := c.Validate(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			tt.fn(&c)
+			if err := c.Validate(); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("unexpected error: got %v, exp %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -46,6 +47,8 @@ type Data struct {
 	ClusterID uint64
 	Databases []DatabaseInfo
 	Users     []UserInfo
+	Roles     []RoleInfo
+	Tokens    []TokenInfo
 
 	// adminUserExists provides a constant time mechanism for determining
 	// if there is at least one admin user.
@@ -104,6 +107,12 @@ func (data *Data) DropDatabase(name string) error {
 			// Remove all user privileges associated with this database.
 			for i := range data.Users {
 				delete(data.Users[i].Privileges, name)
+				data.Users[i].removeMeasurementPrivileges(func(mp *MeasurementPrivilege) bool {
+					return mp.Database == name
+				})
+			}
+			for i := range data.Roles {
+				delete(data.Roles[i].Privileges, name)
 			}
 			break
 		}
@@ -111,6 +120,130 @@ func (data *Data) DropDatabase(name string) error {
 	return nil
 }
 
+// RenameDatabase renames a database. The privileges of the users and roles
+// on the database and the write targets of the users and tokens are moved to
+// the new name, and the continuous queries of the database are rewritten to
+// refer to the new name.
+func (data *Data) RenameDatabase(oldName, newName string) error {
+	if newName == "" {
+		return ErrDatabaseNameRequired
+	} else if len(newName) > MaxNameLen {
+		return ErrNameTooLong
+	}
+
+	di := data.Database(oldName)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(oldName)
+	} else if data.Database(newName) != nil {
+		return ErrDatabaseExists
+	}
+	di.Name = newName
+
+	for i := range di.ContinuousQueries {
+		cqi := &di.ContinuousQueries[i]
+		cqi.Query = renameContinuousQueryDatabase(cqi.Query, oldName, newName)
+	}
+
+	for i := range data.Users {
+		ui := &data.Users[i]
+		if p, ok := ui.Privileges[oldName]; ok {
+			delete(ui.Privileges, oldName)
+			ui.Privileges[newName] = p
+		}
+		if ui.WriteDatabase == oldName {
+			ui.WriteDatabase = newName
+		}
+		for j := range ui.MeasurementPrivileges {
+			if mp := &ui.MeasurementPrivileges[j]; mp.Database == oldName {
+				mp.Database = newName
+			}
+		}
+	}
+	for i := range data.Roles {
+		ri := &data.Roles[i]
+		if p, ok := ri.Privileges[oldName]; ok {
+			delete(ri.Privileges, oldName)
+			ri.Privileges[newName] = p
+		}
+	}
+	for i := range data.Tokens {
+		if ti := &data.Tokens[i]; ti.WriteDatabase == oldName {
+			ti.WriteDatabase = newName
+		}
+	}
+	return nil
+}
+
+// renameContinuousQueryDatabase returns the query of a continuous query with
+// the references to a database renamed. The query is returned unchanged if it
+// can't be parsed.
+func renameContinuousQueryDatabase(q, oldName, newName string) string {
+	stmt, err := influxql.ParseStatement(q)
+	if err != nil {
+		return q
+	}
+	cq, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+	if !ok {
+		return q
+	}
+
+	cq.Database = newName
+	influxql.WalkFunc(cq.Source, func(n influxql.Node) {
+		if m, ok := n.(*influxql.Measurement); ok && m.Database == oldName {
+			m.Database = newName
+		}
+	})
+	return cq.String()
+}
+
+// RenameMeasurement moves the privileges of the users on a measurement of a
+// database to a new name. The privileges on measurements matching a regular
+// expression are unchanged.
+func (data *Data) RenameMeasurement(database, oldName, newName string) error {
+	if data.Database(database) == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
+
+	for i := range data.Users {
+		ui := &data.Users[i]
+
+		var moved bool
+		for j := range ui.MeasurementPrivileges {
+			if mp := &ui.MeasurementPrivileges[j]; mp.Database == database && mp.Regex == nil && mp.Name == oldName {
+				moved = true
+			}
+		}
+		if !moved {
+			continue
+		}
+
+		// The privileges on the old name replace those on the new name.
+		ui.removeMeasurementPrivileges(func(mp *MeasurementPrivilege) bool {
+			return mp.Database == database && mp.Regex == nil && mp.Name == newName
+		})
+		for j := range ui.MeasurementPrivileges {
+			if mp := &ui.MeasurementPrivileges[j]; mp.Database == database && mp.Regex == nil && mp.Name == oldName {
+				mp.Name = newName
+			}
+		}
+	}
+	return nil
+}
+
+// SetDatabaseQuota replaces the quota of a database.
+func (data *Data) SetDatabaseQuota(name string, q QuotaInfo) error {
+	if q.MaxSeries < 0 || q.MaxMeasurements < 0 || q.MaxWriteRate < 0 || q.MaxConcurrentWrites < 0 {
+		return ErrInvalidQuota
+	}
+
+	di := data.Database(name)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	}
+	di.Quota = q
+	return nil
+}
+
 // RetentionPolicy returns a retention policy for a database by name.
 func (data *Data) RetentionPolicy(database, name string) (*RetentionPolicyInfo, error) {
 	di := data.Database(database)
@@ -638,6 +771,15 @@ func (data *Data) DropUser(name string) error {
 			if wasAdmin {
 				data.adminUserExists = data.hasAdminUser()
 			}
+
+			// The tokens of the user can't authenticate anymore.
+			tokens := data.Tokens[:0]
+			for _, ti := range data.Tokens {
+				if ti.User != name {
+					tokens = append(tokens, ti)
+				}
+			}
+			data.Tokens = tokens
 			return nil
 		}
 	}
@@ -656,6 +798,72 @@ func (data *Data) UpdateUser(name, hash string) error {
 	return ErrUserNotFound
 }
 
+// SetUserPasswordChangedAt sets the time the password of an existing user
+// was last set.
+func (data *Data) SetUserPasswordChangedAt(name string, t time.Time) error {
+	ui := data.user(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+	ui.PasswordChangedAt = t
+	return nil
+}
+
+// SetUserTimeZone sets the default time zone of an existing user.
+func (data *Data) SetUserTimeZone(name, tz string) error {
+	ui := data.user(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+	ui.TimeZone = tz
+	return nil
+}
+
+// SetUserQueryPriority sets the priority class of the queries of an
+// existing user.
+func (data *Data) SetUserQueryPriority(name, priority string) error {
+	ui := data.user(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+	ui.QueryPriority = priority
+	return nil
+}
+
+// SetUserWriteTarget sets the database and retention policy the writes of
+// an existing user default to and are restricted to. An empty database
+// removes the restriction.
+func (data *Data) SetUserWriteTarget(name, database, rp string) error {
+	ui := data.user(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+	if err := data.checkWriteTarget(database, rp); err != nil {
+		return err
+	}
+	ui.WriteDatabase, ui.WriteRetentionPolicy = database, rp
+	return nil
+}
+
+// checkWriteTarget returns an error if a write target refers to a database
+// or a retention policy that doesn't exist.
+func (data *Data) checkWriteTarget(database, rp string) error {
+	if database == "" {
+		if rp != "" {
+			return ErrDatabaseNameRequired
+		}
+		return nil
+	}
+
+	di := data.Database(database)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	} else if rp != "" && di.RetentionPolicy(rp) == nil {
+		return influxdb.ErrRetentionPolicyNotFound(rp)
+	}
+	return nil
+}
+
 // CloneUsers returns a copy of the user infos.
 func (data *Data) CloneUsers() []UserInfo {
 	if len(data.Users) == 0 {
@@ -688,6 +896,26 @@ func (data *Data) SetPrivilege(name, database string, p influxql.Privilege) erro
 	return nil
 }
 
+// SetMeasurementPrivilege sets a privilege for a user on the measurements of
+// a database with the name or matching the regular expression of mp. The
+// privilege is removed if it is influxql.NoPrivileges.
+func (data *Data) SetMeasurementPrivilege(name string, mp MeasurementPrivilege) error {
+	ui := data.user(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+
+	if data.Database(mp.Database) == nil {
+		return influxdb.ErrDatabaseNotFound(mp.Database)
+	}
+
+	ui.removeMeasurementPrivileges(mp.SameMeasurements)
+	if mp.Privilege != influxql.NoPrivileges {
+		ui.MeasurementPrivileges = append(ui.MeasurementPrivileges, mp)
+	}
+	return nil
+}
+
 // SetAdminPrivilege sets the admin privilege for a user.
 func (data *Data) SetAdminPrivilege(name string, admin bool) error {
 	ui := data.user(name)
@@ -708,14 +936,25 @@ func (data Data) AdminUserExists() bool {
 	return data.adminUserExists
 }
 
-// UserPrivileges gets the privileges for a user.
+// UserPrivileges gets the privileges for a user, including the privileges
+// of its roles.
 func (data *Data) UserPrivileges(name string) (map[string]influxql.Privilege, error) {
 	ui := data.user(name)
 	if ui == nil {
 		return nil, ErrUserNotFound
 	}
 
-	return ui.Privileges, nil
+	return data.withRoles(ui).Privileges, nil
+}
+
+// UserMeasurementPrivileges gets the privileges of a user on measurements.
+func (data *Data) UserMeasurementPrivileges(name string) ([]MeasurementPrivilege, error) {
+	ui := data.user(name)
+	if ui == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return ui.MeasurementPrivileges, nil
 }
 
 // UserPrivilege gets the privilege for a user on a database.
@@ -734,12 +973,209 @@ func (data *Data) UserPrivilege(name, database string) (*influxql.Privilege, err
 	return influxql.NewPrivilege(influxql.NoPrivileges), nil
 }
 
+// withRoles returns the user with the privileges of its roles added to its
+// own privileges. The user is returned as is if it has no roles.
+func (data *Data) withRoles(ui *UserInfo) *UserInfo {
+	if len(ui.Roles) == 0 {
+		return ui
+	}
+
+	other := ui.clone()
+	if other.Privileges == nil {
+		other.Privileges = make(map[string]influxql.Privilege)
+	}
+	for _, name := range ui.Roles {
+		ri := data.Role(name)
+		if ri == nil {
+			continue
+		}
+		for db, p := range ri.Privileges {
+			other.Privileges[db] |= p
+		}
+	}
+	return &other
+}
+
+// Role returns a role by name.
+func (data *Data) Role(name string) *RoleInfo {
+	for i := range data.Roles {
+		if data.Roles[i].Name == name {
+			return &data.Roles[i]
+		}
+	}
+	return nil
+}
+
+// CreateRole creates a new role.
+func (data *Data) CreateRole(name string) error {
+	if name == "" {
+		return ErrRoleNameRequired
+	} else if data.Role(name) != nil {
+		return ErrRoleExists
+	}
+
+	data.Roles = append(data.Roles, RoleInfo{Name: name})
+	return nil
+}
+
+// DropRole removes an existing role by name. The users that are members of
+// the role lose its privileges.
+func (data *Data) DropRole(name string) error {
+	for i := range data.Roles {
+		if data.Roles[i].Name == name {
+			data.Roles = append(data.Roles[:i], data.Roles[i+1:]...)
+
+			for j := range data.Users {
+				data.Users[j].removeRole(name)
+			}
+			return nil
+		}
+	}
+	return ErrRoleNotFound
+}
+
+// SetRolePrivilege sets a privilege for a role on a database.
+func (data *Data) SetRolePrivilege(name, database string, p influxql.Privilege) error {
+	ri := data.Role(name)
+	if ri == nil {
+		return ErrRoleNotFound
+	}
+
+	if data.Database(database) == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
+
+	if ri.Privileges == nil {
+		ri.Privileges = make(map[string]influxql.Privilege)
+	}
+	if p == influxql.NoPrivileges {
+		delete(ri.Privileges, database)
+	} else {
+		ri.Privileges[database] = p
+	}
+
+	return nil
+}
+
+// RolePrivileges gets the privileges for a role.
+func (data *Data) RolePrivileges(name string) (map[string]influxql.Privilege, error) {
+	ri := data.Role(name)
+	if ri == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	return ri.Privileges, nil
+}
+
+// AddUserToRole makes a user a member of a role. It does not return an error
+// if the user is already a member of the role.
+func (data *Data) AddUserToRole(username, role string) error {
+	ui := data.user(username)
+	if ui == nil {
+		return ErrUserNotFound
+	} else if data.Role(role) == nil {
+		return ErrRoleNotFound
+	}
+
+	for _, name := range ui.Roles {
+		if name == role {
+			return nil
+		}
+	}
+	ui.Roles = append(ui.Roles, role)
+	return nil
+}
+
+// RemoveUserFromRole removes a user from the members of a role. It does not
+// return an error if the user is not a member of the role.
+func (data *Data) RemoveUserFromRole(username, role string) error {
+	ui := data.user(username)
+	if ui == nil {
+		return ErrUserNotFound
+	} else if data.Role(role) == nil {
+		return ErrRoleNotFound
+	}
+
+	ui.removeRole(role)
+	return nil
+}
+
+// CloneRoles returns a copy of the role infos.
+func (data *Data) CloneRoles() []RoleInfo {
+	if len(data.Roles) == 0 {
+		return nil
+	}
+	roles := make([]RoleInfo, len(data.Roles))
+	for i := range data.Roles {
+		roles[i] = data.Roles[i].clone()
+	}
+	return roles
+}
+
+// Token returns a token by ID.
+func (data *Data) Token(id string) *TokenInfo {
+	for i := range data.Tokens {
+		if data.Tokens[i].ID == id {
+			return &data.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// CreateToken adds a token for an existing user.
+func (data *Data) CreateToken(ti TokenInfo) error {
+	if data.user(ti.User) == nil {
+		return ErrUserNotFound
+	} else if data.Token(ti.ID) != nil {
+		return ErrTokenExists
+	} else if err := data.checkWriteTarget(ti.WriteDatabase, ti.WriteRetentionPolicy); err != nil {
+		return err
+	}
+
+	data.Tokens = append(data.Tokens, ti)
+	return nil
+}
+
+// DropToken removes an existing token by ID.
+func (data *Data) DropToken(id string) error {
+	for i := range data.Tokens {
+		if data.Tokens[i].ID == id {
+			data.Tokens = append(data.Tokens[:i], data.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// SetTokenLastUsed records the last time a token authenticated a request.
+func (data *Data) SetTokenLastUsed(id string, t time.Time) error {
+	ti := data.Token(id)
+	if ti == nil {
+		return ErrTokenNotFound
+	}
+
+	ti.LastUsedAt = t.UTC()
+	return nil
+}
+
+// CloneTokens returns a copy of the token infos.
+func (data *Data) CloneTokens() []TokenInfo {
+	if len(data.Tokens) == 0 {
+		return nil
+	}
+	tokens := make([]TokenInfo, len(data.Tokens))
+	copy(tokens, data.Tokens)
+	return tokens
+}
+
 // Clone returns a copy of data with a new version.
 func (data *Data) Clone() *Data {
 	other := *data
 
 	other.Databases = data.CloneDatabases()
 	other.Users = data.CloneUsers()
+	other.Roles = data.CloneRoles()
+	other.Tokens = data.CloneTokens()
 
 	return &other
 }
@@ -768,6 +1204,16 @@ func (data *Data) marshal() *internal.Data {
 		pb.Users[i] = data.Users[i].marshal()
 	}
 
+	pb.Roles = make([]*internal.RoleInfo, len(data.Roles))
+	for i := range data.Roles {
+		pb.Roles[i] = data.Roles[i].marshal()
+	}
+
+	pb.Tokens = make([]*internal.TokenInfo, len(data.Tokens))
+	for i := range data.Tokens {
+		pb.Tokens[i] = data.Tokens[i].marshal()
+	}
+
 	return pb
 }
 
@@ -790,6 +1236,22 @@ func (data *Data) unmarshal(pb *internal.Data) {
 		data.Users[i].unmarshal(x)
 	}
 
+	data.Roles = nil
+	if len(pb.GetRoles()) > 0 {
+		data.Roles = make([]RoleInfo, len(pb.GetRoles()))
+		for i, x := range pb.GetRoles() {
+			data.Roles[i].unmarshal(x)
+		}
+	}
+
+	data.Tokens = nil
+	if len(pb.GetTokens()) > 0 {
+		data.Tokens = make([]TokenInfo, len(pb.GetTokens()))
+		for i, x := range pb.GetTokens() {
+			data.Tokens[i].unmarshal(x)
+		}
+	}
+
 	// Exhaustively determine if there is an admin user. The marshalled cache
 	// value may not be correct.
 	data.adminUserExists = data.hasAdminUser()
@@ -816,24 +1278,33 @@ func (data *Data) TruncateShardGroups(t time.Time) {
 		dbi := &data.Databases[i]
 
 		for j := range dbi.RetentionPolicies {
-			rpi := &dbi.RetentionPolicies[j]
-
-			for k := range rpi.ShardGroups {
-				sgi := &rpi.ShardGroups[k]
+			dbi.RetentionPolicies[j].truncateShardGroups(t)
+		}
+	}
+}
 
-				if !t.Before(sgi.EndTime) || sgi.Deleted() || (sgi.Truncated() && sgi.TruncatedAt.Before(t)) {
-					continue
-				}
+// TruncateDatabaseShardGroups truncates the shard groups of a database that
+// could contain timestamps beyond t. Only the shard groups of the retention
+// policy are truncated if it is not empty.
+func (data *Data) TruncateDatabaseShardGroups(database, policy string, t time.Time) error {
+	dbi := data.Database(database)
+	if dbi == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
 
-				if !t.After(sgi.StartTime) {
-					// future shardgroup
-					sgi.TruncatedAt = sgi.StartTime
-				} else {
-					sgi.TruncatedAt = t
-				}
-			}
+	if policy != "" {
+		rpi := dbi.RetentionPolicy(policy)
+		if rpi == nil {
+			return influxdb.ErrRetentionPolicyNotFound(policy)
 		}
+		rpi.truncateShardGroups(t)
+		return nil
 	}
+
+	for i := range dbi.RetentionPolicies {
+		dbi.RetentionPolicies[i].truncateShardGroups(t)
+	}
+	return nil
 }
 
 // hasAdminUser exhaustively checks for the presence of at least one admin
@@ -972,6 +1443,7 @@ type DatabaseInfo struct {
 	DefaultRetentionPolicy string
 	RetentionPolicies      []RetentionPolicyInfo
 	ContinuousQueries      []ContinuousQueryInfo
+	Quota                  QuotaInfo
 }
 
 // RetentionPolicy returns a retention policy by name.
@@ -1053,6 +1525,10 @@ func (di DatabaseInfo) marshal() *internal.DatabaseInfo {
 	for i := range di.ContinuousQueries {
 		pb.ContinuousQueries[i] = di.ContinuousQueries[i].marshal()
 	}
+
+	if !di.Quota.IsZero() {
+		pb.Quota = di.Quota.marshal()
+	}
 	return pb
 }
 
@@ -1074,6 +1550,51 @@ func (di *DatabaseInfo) unmarshal(pb *internal.DatabaseInfo) {
 			di.ContinuousQueries[i].unmarshal(x)
 		}
 	}
+
+	if pb.Quota != nil {
+		di.Quota.unmarshal(pb.GetQuota())
+	}
+}
+
+// QuotaInfo represents the limits of a database that are enforced by the
+// write path. A zero limit is unlimited.
+type QuotaInfo struct {
+	// MaxSeries is the maximum number of series in the database.
+	MaxSeries int64
+
+	// MaxMeasurements is the maximum number of measurements in the database.
+	MaxMeasurements int64
+
+	// MaxWriteRate is the maximum number of points written to the database
+	// per second.
+	MaxWriteRate int64
+
+	// MaxConcurrentWrites is the maximum number of writes to the database
+	// in progress at once.
+	MaxConcurrentWrites int64
+}
+
+// IsZero returns true if the quota has no limits.
+func (q QuotaInfo) IsZero() bool {
+	return q == QuotaInfo{}
+}
+
+// marshal serializes to a protobuf representation.
+func (q QuotaInfo) marshal() *internal.QuotaInfo {
+	return &internal.QuotaInfo{
+		MaxSeries:           proto.Int64(q.MaxSeries),
+		MaxMeasurements:     proto.Int64(q.MaxMeasurements),
+		MaxWriteRate:        proto.Int64(q.MaxWriteRate),
+		MaxConcurrentWrites: proto.Int64(q.MaxConcurrentWrites),
+	}
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (q *QuotaInfo) unmarshal(pb *internal.QuotaInfo) {
+	q.MaxSeries = pb.GetMaxSeries()
+	q.MaxMeasurements = pb.GetMaxMeasurements()
+	q.MaxWriteRate = pb.GetMaxWriteRate()
+	q.MaxConcurrentWrites = pb.GetMaxConcurrentWrites()
 }
 
 // RetentionPolicySpec represents the specification for a new retention policy.
@@ -1245,6 +1766,25 @@ func (rpi *RetentionPolicyInfo) DeletedShardGroups() []*ShardGroupInfo {
 	return groups
 }
 
+// truncateShardGroups truncates any shard group that could contain
+// timestamps beyond t.
+func (rpi *RetentionPolicyInfo) truncateShardGroups(t time.Time) {
+	for i := range rpi.ShardGroups {
+		sgi := &rpi.ShardGroups[i]
+
+		if !t.Before(sgi.EndTime) || sgi.Deleted() || (sgi.Truncated() && sgi.TruncatedAt.Before(t)) {
+			continue
+		}
+
+		if !t.After(sgi.StartTime) {
+			// future shardgroup
+			sgi.TruncatedAt = sgi.StartTime
+		} else {
+			sgi.TruncatedAt = t
+		}
+	}
+}
+
 // marshal serializes to a protobuf representation.
 func (rpi *RetentionPolicyInfo) marshal() *internal.RetentionPolicyInfo {
 	pb := &internal.RetentionPolicyInfo{
@@ -1643,6 +2183,31 @@ type UserInfo struct {
 
 	// Map of database name to granted privilege.
 	Privileges map[string]influxql.Privilege
+
+	// Default time zone of the user's queries. An empty string is UTC.
+	TimeZone string
+
+	// Privileges granted on the measurements of databases. They are in
+	// addition to the privileges granted on the whole databases.
+	MeasurementPrivileges []MeasurementPrivilege
+
+	// Names of the roles the user is a member of. The user is granted the
+	// privileges of its roles.
+	Roles []string
+
+	// Time the password was last set. It is zero for the passwords set
+	// before it was recorded.
+	PasswordChangedAt time.Time
+
+	// Database and retention policy of the user's writes that don't specify
+	// them. If the database is set, the user can only write to it, and to
+	// the retention policy if it is set too.
+	WriteDatabase        string
+	WriteRetentionPolicy string
+
+	// Priority class of the user's queries. An empty string is the default
+	// priority.
+	QueryPriority string
 }
 
 type User interface {
@@ -1664,9 +2229,43 @@ func (ui *UserInfo) AuthorizeDatabase(privilege influxql.Privilege, database str
 	return ok && (p == privilege || p == influxql.AllPrivileges)
 }
 
-// AuthorizeSeriesRead is used to limit access per-series (enterprise only)
+// AuthorizeMeasurements returns true if the user is authorized for the given
+// privilege on the given database or on some of its measurements.
+func (ui *UserInfo) AuthorizeMeasurements(privilege influxql.Privilege, database string) bool {
+	if ui.AuthorizeDatabase(privilege, database) {
+		return true
+	}
+	for i := range ui.MeasurementPrivileges {
+		mp := &ui.MeasurementPrivileges[i]
+		if mp.Database == database && (mp.Privilege == privilege || mp.Privilege == influxql.AllPrivileges) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizeMeasurement returns true if the user is authorized for the given
+// privilege on the given measurement of the given database.
+func (ui *UserInfo) AuthorizeMeasurement(privilege influxql.Privilege, database string, measurement []byte) bool {
+	if ui.AuthorizeDatabase(privilege, database) {
+		return true
+	}
+	for i := range ui.MeasurementPrivileges {
+		mp := &ui.MeasurementPrivileges[i]
+		if mp.Privilege != privilege && mp.Privilege != influxql.AllPrivileges {
+			continue
+		}
+		if mp.Matches(database, measurement) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizeSeriesRead returns true if the user is authorized to read the
+// measurement of the series.
 func (u *UserInfo) AuthorizeSeriesRead(database string, measurement []byte, tags models.Tags) bool {
-	return true
+	return u.AuthorizeMeasurement(influxql.ReadPrivilege, database, measurement)
 }
 
 // OptimizeSeriesRead optimizes series read based on the authorizer.
@@ -1674,14 +2273,16 @@ func (u *UserInfo) OptimizeSeriesRead(database string, measurement []byte, expr
 	return expr, u, nil
 }
 
-// AuthorizeSeriesWrite is used to limit access per-series (enterprise only)
+// AuthorizeSeriesWrite returns true if the user is authorized to write to the
+// measurement of the series.
 func (u *UserInfo) AuthorizeSeriesWrite(database string, measurement []byte, tags models.Tags) bool {
-	return true
+	return u.AuthorizeMeasurement(influxql.WritePrivilege, database, measurement)
 }
 
 // IsOpen is a method on FineAuthorizer to indicate all fine auth is permitted and short circuit some checks.
+// It is only false for the users with privileges on measurements.
 func (u *UserInfo) IsOpen() bool {
-	return true
+	return u.Admin || len(u.MeasurementPrivileges) == 0
 }
 
 // IsVoid is a method on FineAuthorizer to indicate all fine auth is permitted and short circuit some checks.
@@ -1707,9 +2308,47 @@ func (ui UserInfo) clone() UserInfo {
 		}
 	}
 
+	if ui.MeasurementPrivileges != nil {
+		other.MeasurementPrivileges = make([]MeasurementPrivilege, len(ui.MeasurementPrivileges))
+		copy(other.MeasurementPrivileges, ui.MeasurementPrivileges)
+	}
+
+	if ui.Roles != nil {
+		other.Roles = make([]string, len(ui.Roles))
+		copy(other.Roles, ui.Roles)
+	}
+
 	return other
 }
 
+// removeRole removes the role from the roles of the user.
+func (ui *UserInfo) removeRole(role string) {
+	for i, name := range ui.Roles {
+		if name == role {
+			ui.Roles = append(ui.Roles[:i], ui.Roles[i+1:]...)
+			break
+		}
+	}
+	if len(ui.Roles) == 0 {
+		ui.Roles = nil
+	}
+}
+
+// removeMeasurementPrivileges removes the measurement privileges for which fn
+// returns true.
+func (ui *UserInfo) removeMeasurementPrivileges(fn func(mp *MeasurementPrivilege) bool) {
+	privileges := ui.MeasurementPrivileges[:0]
+	for i := range ui.MeasurementPrivileges {
+		if !fn(&ui.MeasurementPrivileges[i]) {
+			privileges = append(privileges, ui.MeasurementPrivileges[i])
+		}
+	}
+	if len(privileges) == 0 {
+		privileges = nil
+	}
+	ui.MeasurementPrivileges = privileges
+}
+
 // marshal serializes to a protobuf representation.
 func (ui UserInfo) marshal() *internal.UserInfo {
 	pb := &internal.UserInfo{
@@ -1717,6 +2356,9 @@ func (ui UserInfo) marshal() *internal.UserInfo {
 		Hash:  proto.String(ui.Hash),
 		Admin: proto.Bool(ui.Admin),
 	}
+	if ui.TimeZone != "" {
+		pb.TimeZone = proto.String(ui.TimeZone)
+	}
 
 	for database, privilege := range ui.Privileges {
 		pb.Privileges = append(pb.Privileges, &internal.UserPrivilege{
@@ -1725,6 +2367,26 @@ func (ui UserInfo) marshal() *internal.UserInfo {
 		})
 	}
 
+	for _, mp := range ui.MeasurementPrivileges {
+		pb.MeasurementPrivileges = append(pb.MeasurementPrivileges, mp.marshal())
+	}
+
+	pb.Roles = ui.Roles
+
+	if !ui.PasswordChangedAt.IsZero() {
+		pb.PasswordChangedAt = proto.Int64(MarshalTime(ui.PasswordChangedAt))
+	}
+
+	if ui.WriteDatabase != "" {
+		pb.WriteDatabase = proto.String(ui.WriteDatabase)
+	}
+	if ui.WriteRetentionPolicy != "" {
+		pb.WriteRetentionPolicy = proto.String(ui.WriteRetentionPolicy)
+	}
+	if ui.QueryPriority != "" {
+		pb.QueryPriority = proto.String(ui.QueryPriority)
+	}
+
 	return pb
 }
 
@@ -1733,11 +2395,221 @@ func (ui *UserInfo) unmarshal(pb *internal.UserInfo) {
 	ui.Name = pb.GetName()
 	ui.Hash = pb.GetHash()
 	ui.Admin = pb.GetAdmin()
+	ui.TimeZone = pb.GetTimeZone()
+	ui.WriteDatabase = pb.GetWriteDatabase()
+	ui.WriteRetentionPolicy = pb.GetWriteRetentionPolicy()
+	ui.QueryPriority = pb.GetQueryPriority()
 
 	ui.Privileges = make(map[string]influxql.Privilege)
 	for _, p := range pb.GetPrivileges() {
 		ui.Privileges[p.GetDatabase()] = influxql.Privilege(p.GetPrivilege())
 	}
+
+	ui.MeasurementPrivileges = nil
+	for _, p := range pb.GetMeasurementPrivileges() {
+		var mp MeasurementPrivilege
+		if err := mp.unmarshal(p); err != nil {
+			// The pattern was compiled when the privilege was granted so
+			// this only happens if the meta data is corrupted.
+			continue
+		}
+		ui.MeasurementPrivileges = append(ui.MeasurementPrivileges, mp)
+	}
+
+	ui.Roles = nil
+	if len(pb.GetRoles()) > 0 {
+		ui.Roles = append([]string(nil), pb.GetRoles()...)
+	}
+
+	ui.PasswordChangedAt = UnmarshalTime(pb.GetPasswordChangedAt())
+}
+
+// MeasurementPrivilege represents a privilege granted on the measurements of
+// a database with a name or matching a regular expression.
+type MeasurementPrivilege struct {
+	// Database of the measurements.
+	Database string
+
+	// Name of the measurement. It is empty if Regex is set.
+	Name string
+
+	// Regular expression matching the names of the measurements.
+	Regex *regexp.Regexp
+
+	// Privilege granted on the measurements.
+	Privilege influxql.Privilege
+}
+
+// Matches returns true if the privilege applies to the measurement.
+func (mp *MeasurementPrivilege) Matches(database string, measurement []byte) bool {
+	if mp.Database != database {
+		return false
+	} else if mp.Regex != nil {
+		return mp.Regex.Match(measurement)
+	}
+	return mp.Name == string(measurement)
+}
+
+// Measurement returns the name of the measurements or their regular
+// expression formatted as an InfluxQL regex literal.
+func (mp *MeasurementPrivilege) Measurement() string {
+	if mp.Regex != nil {
+		return (&influxql.RegexLiteral{Val: mp.Regex}).String()
+	}
+	return mp.Name
+}
+
+// SameMeasurements returns true if other is a privilege on the same
+// measurements.
+func (mp *MeasurementPrivilege) SameMeasurements(other *MeasurementPrivilege) bool {
+	if mp.Database != other.Database || mp.Name != other.Name || (mp.Regex == nil) != (other.Regex == nil) {
+		return false
+	}
+	return mp.Regex == nil || mp.Regex.String() == other.Regex.String()
+}
+
+// marshal serializes to a protobuf representation.
+func (mp *MeasurementPrivilege) marshal() *internal.MeasurementPrivilege {
+	pb := &internal.MeasurementPrivilege{
+		Database:  proto.String(mp.Database),
+		Privilege: proto.Int32(int32(mp.Privilege)),
+	}
+	if mp.Regex != nil {
+		pb.Regex = proto.String(mp.Regex.String())
+	} else {
+		pb.Name = proto.String(mp.Name)
+	}
+	return pb
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (mp *MeasurementPrivilege) unmarshal(pb *internal.MeasurementPrivilege) error {
+	mp.Database = pb.GetDatabase()
+	mp.Name = pb.GetName()
+	mp.Privilege = influxql.Privilege(pb.GetPrivilege())
+	if pb.Regex != nil {
+		re, err := regexp.Compile(pb.GetRegex())
+		if err != nil {
+			return err
+		}
+		mp.Regex = re
+	}
+	return nil
+}
+
+// RoleInfo represents metadata about a role. The privileges of a role are
+// granted to its members.
+type RoleInfo struct {
+	// Name of the role.
+	Name string
+
+	// Map of database name to granted privilege.
+	Privileges map[string]influxql.Privilege
+}
+
+// clone returns a deep copy of ri.
+func (ri RoleInfo) clone() RoleInfo {
+	other := ri
+
+	if ri.Privileges != nil {
+		other.Privileges = make(map[string]influxql.Privilege)
+		for k, v := range ri.Privileges {
+			other.Privileges[k] = v
+		}
+	}
+
+	return other
+}
+
+// marshal serializes to a protobuf representation.
+func (ri RoleInfo) marshal() *internal.RoleInfo {
+	pb := &internal.RoleInfo{
+		Name: proto.String(ri.Name),
+	}
+
+	for database, privilege := range ri.Privileges {
+		pb.Privileges = append(pb.Privileges, &internal.UserPrivilege{
+			Database:  proto.String(database),
+			Privilege: proto.Int32(int32(privilege)),
+		})
+	}
+
+	return pb
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ri *RoleInfo) unmarshal(pb *internal.RoleInfo) {
+	ri.Name = pb.GetName()
+
+	ri.Privileges = make(map[string]influxql.Privilege)
+	for _, p := range pb.GetPrivileges() {
+		ri.Privileges[p.GetDatabase()] = influxql.Privilege(p.GetPrivilege())
+	}
+}
+
+// TokenInfo represents metadata about an API token. A token authenticates as
+// its user, with the privileges of the user.
+type TokenInfo struct {
+	// ID of the token. It is the part of the token before the dot and
+	// identifies the token without revealing it.
+	ID string
+
+	// Name of the user the token authenticates as.
+	User string
+
+	// Hex encoded SHA-256 hash of the token.
+	Hash string
+
+	CreatedAt time.Time
+
+	// Time after which the token is rejected. The token does not expire if
+	// it is zero.
+	ExpiresAt time.Time
+
+	// Last time the token authenticated a request, to a resolution of
+	// TokenLastUsedResolution.
+	LastUsedAt time.Time
+
+	// Write target of the token. It overrides the write target of the user
+	// if the database is set.
+	WriteDatabase        string
+	WriteRetentionPolicy string
+}
+
+// Expired returns true if the token is expired at the given time.
+func (ti *TokenInfo) Expired(now time.Time) bool {
+	return !ti.ExpiresAt.IsZero() && !now.Before(ti.ExpiresAt)
+}
+
+// marshal serializes to a protobuf representation.
+func (ti TokenInfo) marshal() *internal.TokenInfo {
+	pb := &internal.TokenInfo{
+		ID:         proto.String(ti.ID),
+		User:       proto.String(ti.User),
+		Hash:       proto.String(ti.Hash),
+		CreatedAt:  proto.Int64(MarshalTime(ti.CreatedAt)),
+		ExpiresAt:  proto.Int64(MarshalTime(ti.ExpiresAt)),
+		LastUsedAt: proto.Int64(MarshalTime(ti.LastUsedAt)),
+	}
+	if ti.WriteDatabase != "" {
+		pb.WriteDatabase = proto.String(ti.WriteDatabase)
+	}
+	if ti.WriteRetentionPolicy != "" {
+		pb.WriteRetentionPolicy = proto.String(ti.WriteRetentionPolicy)
+	}
+	return pb
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ti *TokenInfo) unmarshal(pb *internal.TokenInfo) {
+	ti.ID = pb.GetID()
+	ti.User = pb.GetUser()
+	ti.Hash = pb.GetHash()
+	ti.CreatedAt = UnmarshalTime(pb.GetCreatedAt())
+	ti.ExpiresAt = UnmarshalTime(pb.GetExpiresAt())
+	ti.LastUsedAt = UnmarshalTime(pb.GetLastUsedAt())
+	ti.WriteDatabase = pb.GetWriteDatabase()
+	ti.WriteRetentionPolicy = pb.GetWriteRetentionPolicy()
 }
 
 // Lease represents a lease held on a resource.
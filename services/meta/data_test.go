@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"regexp"
 	"testing"
 	"time"
 
@@ -246,6 +247,321 @@ func TestData_SetPrivilege(t *testing.T) {
 	}
 }
 
+func TestData_SetMeasurementPrivilege(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateUser("user1", "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	mp := meta.MeasurementPrivilege{Database: "db0", Name: "cpu", Privilege: influxql.ReadPrivilege}
+	if got, exp := data.SetMeasurementPrivilege("not a user", mp), meta.ErrUserNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	if got, exp := data.SetMeasurementPrivilege("user1", meta.MeasurementPrivilege{Database: "db1", Name: "cpu"}), influxdb.ErrDatabaseNotFound("db1"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	// Setting a privilege on the same measurements replaces it.
+	re := meta.MeasurementPrivilege{Database: "db0", Regex: regexp.MustCompile(`^disk`), Privilege: influxql.WritePrivilege}
+	for _, mp := range []meta.MeasurementPrivilege{mp, re, {Database: "db0", Name: "cpu", Privilege: influxql.AllPrivileges}} {
+		if err := data.SetMeasurementPrivilege("user1", mp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mps, err := data.UserMeasurementPrivileges("user1")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(mps) != 2 {
+		t.Fatalf("unexpected privileges: %v", mps)
+	} else if mps[0].Measurement() != "/^disk/" || mps[0].Privilege != influxql.WritePrivilege {
+		t.Fatalf("unexpected privilege: %v", mps[0])
+	} else if mps[1].Measurement() != "cpu" || mps[1].Privilege != influxql.AllPrivileges {
+		t.Fatalf("unexpected privilege: %v", mps[1])
+	}
+
+	// The privileges survive a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	} else if mps, _ := other.UserMeasurementPrivileges("user1"); len(mps) != 2 || mps[0].Measurement() != "/^disk/" || mps[1].Measurement() != "cpu" {
+		t.Fatalf("unexpected privileges: %v", mps)
+	}
+
+	// NoPrivileges removes the privilege.
+	re.Privilege = influxql.NoPrivileges
+	if err := data.SetMeasurementPrivilege("user1", re); err != nil {
+		t.Fatal(err)
+	} else if mps, _ := data.UserMeasurementPrivileges("user1"); len(mps) != 1 {
+		t.Fatalf("unexpected privileges: %v", mps)
+	}
+
+	// Dropping the database removes its privileges.
+	if err := data.DropDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if mps, _ := data.UserMeasurementPrivileges("user1"); len(mps) != 0 {
+		t.Fatalf("unexpected privileges: %v", mps)
+	}
+}
+
+func TestData_Roles(t *testing.T) {
+	data := meta.Data{}
+	for _, db := range []string{"db0", "db1"} {
+		if err := data.CreateDatabase(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := data.CreateUser("user1", "", false); err != nil {
+		t.Fatal(err)
+	} else if err := data.SetPrivilege("user1", "db0", influxql.ReadPrivilege); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := data.CreateRole(""), meta.ErrRoleNameRequired; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	for _, name := range []string{"writers", "db1"} {
+		if err := data.CreateRole(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, exp := data.CreateRole("writers"), meta.ErrRoleExists; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.SetRolePrivilege("not a role", "db0", influxql.ReadPrivilege), meta.ErrRoleNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.AddUserToRole("not a user", "writers"), meta.ErrUserNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.AddUserToRole("user1", "not a role"), meta.ErrRoleNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	if err := data.SetRolePrivilege("writers", "db0", influxql.WritePrivilege); err != nil {
+		t.Fatal(err)
+	} else if err := data.SetRolePrivilege("db1", "db1", influxql.AllPrivileges); err != nil {
+		t.Fatal(err)
+	}
+
+	// The privileges of the roles are added to the privileges of the user.
+	for _, role := range []string{"writers", "db1", "writers"} {
+		if err := data.AddUserToRole("user1", role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exp := map[string]influxql.Privilege{"db0": influxql.AllPrivileges, "db1": influxql.AllPrivileges}
+	if privs, err := data.UserPrivileges("user1"); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(privs, exp) {
+		t.Fatalf("unexpected privileges: %v", privs)
+	} else if p, _ := data.UserPrivilege("user1", "db0"); *p != influxql.ReadPrivilege {
+		t.Fatalf("unexpected privilege of the user itself: %v", *p)
+	}
+
+	// The roles survive a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	} else if privs, _ := other.UserPrivileges("user1"); !reflect.DeepEqual(privs, exp) {
+		t.Fatalf("unexpected privileges: %v", privs)
+	} else if got := len(other.Roles); got != 2 {
+		t.Fatalf("unexpected number of roles: %d", got)
+	}
+
+	// Dropping a database removes the privileges of the roles on it.
+	if err := data.DropDatabase("db1"); err != nil {
+		t.Fatal(err)
+	} else if privs, _ := data.RolePrivileges("db1"); len(privs) != 0 {
+		t.Fatalf("unexpected privileges: %v", privs)
+	}
+
+	// Removing the user from a role or dropping the role revokes its privileges.
+	if err := data.RemoveUserFromRole("user1", "db1"); err != nil {
+		t.Fatal(err)
+	} else if err := data.DropRole("writers"); err != nil {
+		t.Fatal(err)
+	} else if got, exp := data.DropRole("writers"), meta.ErrRoleNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if privs, _ := data.UserPrivileges("user1"); !reflect.DeepEqual(privs, map[string]influxql.Privilege{"db0": influxql.ReadPrivilege}) {
+		t.Fatalf("unexpected privileges: %v", privs)
+	} else if roles := data.Users[0].Roles; len(roles) != 0 {
+		t.Fatalf("unexpected roles: %v", roles)
+	}
+}
+
+func TestData_Tokens(t *testing.T) {
+	data := meta.Data{}
+	for _, name := range []string{"user1", "user2"} {
+		if err := data.CreateUser(name, "", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, ti := range []meta.TokenInfo{
+		{ID: "a", User: "user1", Hash: "h1", CreatedAt: now},
+		{ID: "b", User: "user2", Hash: "h2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "c", User: "user1", Hash: "h3", CreatedAt: now},
+	} {
+		if err := data.CreateToken(ti); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, exp := data.CreateToken(meta.TokenInfo{ID: "d", User: "not a user"}), meta.ErrUserNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.CreateToken(meta.TokenInfo{ID: "a", User: "user2"}), meta.ErrTokenExists; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if err := data.SetTokenLastUsed("b", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	} else if got, exp := data.SetTokenLastUsed("d", now), meta.ErrTokenNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	if ti := data.Token("b"); ti.Expired(now) {
+		t.Fatal("unexpected expired token")
+	} else if !ti.Expired(now.Add(time.Hour)) {
+		t.Fatal("expected expired token")
+	} else if data.Token("a").Expired(now.Add(24 * time.Hour)) {
+		t.Fatal("unexpected expired token without expiry")
+	}
+
+	// The tokens survive a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(other.Tokens, data.Tokens) {
+		t.Fatalf("unexpected tokens: %v", other.Tokens)
+	}
+
+	// Dropping a user drops its tokens.
+	if err := data.DropToken("c"); err != nil {
+		t.Fatal(err)
+	} else if got, exp := data.DropToken("c"), meta.ErrTokenNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if err := data.DropUser("user1"); err != nil {
+		t.Fatal(err)
+	} else if len(data.Tokens) != 1 || data.Tokens[0].ID != "b" {
+		t.Fatalf("unexpected tokens: %v", data.Tokens)
+	}
+}
+
+func TestData_SetDatabaseQuota(t *testing.T) {
+	var data meta.Data
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+
+	q := meta.QuotaInfo{MaxSeries: 1000, MaxMeasurements: 10, MaxWriteRate: 5000, MaxConcurrentWrites: 4}
+	if err := data.SetDatabaseQuota("db0", q); err != nil {
+		t.Fatal(err)
+	} else if got, exp := data.SetDatabaseQuota("db0", meta.QuotaInfo{MaxSeries: -1}), meta.ErrInvalidQuota; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.SetDatabaseQuota("db1", q), influxdb.ErrDatabaseNotFound("db1"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	// The quota survives a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	} else if got := other.Database("db0").Quota; got != q {
+		t.Fatalf("unexpected quota: %+v", got)
+	}
+
+	if err := data.SetDatabaseQuota("db0", meta.QuotaInfo{}); err != nil {
+		t.Fatal(err)
+	} else if !data.Database("db0").Quota.IsZero() {
+		t.Fatalf("unexpected quota: %+v", data.Database("db0").Quota)
+	}
+}
+
+func TestData_RenameDatabase(t *testing.T) {
+	var data meta.Data
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(data.CreateDatabase("db0"))
+	must(data.CreateDatabase("db1"))
+	must(data.CreateContinuousQuery("db0", "cq0", `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`))
+	must(data.CreateUser("user0", "hash", false))
+	must(data.SetPrivilege("user0", "db0", influxql.ReadPrivilege))
+	must(data.SetMeasurementPrivilege("user0", meta.MeasurementPrivilege{Database: "db0", Name: "cpu", Privilege: influxql.WritePrivilege}))
+	must(data.CreateRole("role0"))
+	must(data.SetRolePrivilege("role0", "db0", influxql.AllPrivileges))
+
+	if got, exp := data.RenameDatabase("db0", "db1"), meta.ErrDatabaseExists; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.RenameDatabase("db2", "db3"), influxdb.ErrDatabaseNotFound("db2"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	must(data.RenameDatabase("db0", "db2"))
+
+	if data.Database("db0") != nil || data.Database("db2") == nil {
+		t.Fatalf("unexpected databases: %+v", data.Databases)
+	}
+	if got, exp := data.Database("db2").ContinuousQueries[0].Query, `CREATE CONTINUOUS QUERY cq0 ON db2 BEGIN SELECT mean(value) INTO db2.rp0.cpu_1h FROM db2.rp0.cpu GROUP BY time(1h) END`; got != exp {
+		t.Fatalf("unexpected query: %s", got)
+	}
+
+	ui := data.Users[0]
+	if got, exp := ui.Privileges, map[string]influxql.Privilege{"db2": influxql.ReadPrivilege}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected privileges: %v", got)
+	} else if got := ui.MeasurementPrivileges[0].Database; got != "db2" {
+		t.Fatalf("unexpected measurement privilege database: %s", got)
+	}
+	if got, exp := data.Role("role0").Privileges, map[string]influxql.Privilege{"db2": influxql.AllPrivileges}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected role privileges: %v", got)
+	}
+}
+
+func TestData_RenameMeasurement(t *testing.T) {
+	var data meta.Data
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(data.CreateDatabase("db0"))
+	must(data.CreateUser("user0", "hash", false))
+	must(data.SetMeasurementPrivilege("user0", meta.MeasurementPrivilege{Database: "db0", Name: "cpu", Privilege: influxql.WritePrivilege}))
+	must(data.SetMeasurementPrivilege("user0", meta.MeasurementPrivilege{Database: "db0", Name: "load", Privilege: influxql.ReadPrivilege}))
+	must(data.SetMeasurementPrivilege("user0", meta.MeasurementPrivilege{Database: "db0", Regex: regexp.MustCompile(`^cpu`), Privilege: influxql.ReadPrivilege}))
+
+	if got, exp := data.RenameMeasurement("db1", "cpu", "load"), influxdb.ErrDatabaseNotFound("db1"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	must(data.RenameMeasurement("db0", "cpu", "load"))
+
+	// The privilege on the old name replaces the one on the new name, and
+	// the regular expression is unchanged.
+	var got []string
+	for _, mp := range data.Users[0].MeasurementPrivileges {
+		got = append(got, fmt.Sprintf("%s %s", mp.Measurement(), mp.Privilege))
+	}
+	if exp := []string{"load WRITE", "/^cpu/ READ"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected measurement privileges: %v", got)
+	}
+}
+
 func TestData_TruncateShardGroups(t *testing.T) {
 	data := &meta.Data{}
 
@@ -399,6 +715,53 @@ func TestData_TruncateShardGroups(t *testing.T) {
 	}
 }
 
+func TestData_TruncateDatabaseShardGroups(t *testing.T) {
+	data := &meta.Data{}
+
+	must := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, db := range []string{"db0", "db1"} {
+		must(data.CreateDatabase(db))
+		for _, name := range []string{"rp0", "rp1"} {
+			rp := meta.NewRetentionPolicyInfo(name)
+			rp.ShardGroupDuration = 24 * time.Hour
+			must(data.CreateRetentionPolicy(db, rp, false))
+			must(data.CreateShardGroup(db, name, time.Unix(0, 0)))
+		}
+	}
+
+	truncateTime := time.Unix(0, 0).Add(time.Hour)
+	must(data.TruncateDatabaseShardGroups("db0", "rp1", truncateTime))
+	must(data.TruncateDatabaseShardGroups("db1", "", truncateTime))
+
+	for _, tc := range []struct {
+		db, rp    string
+		truncated bool
+	}{
+		{"db0", "rp0", false},
+		{"db0", "rp1", true},
+		{"db1", "rp0", true},
+		{"db1", "rp1", true},
+	} {
+		sgi := data.Database(tc.db).RetentionPolicy(tc.rp).ShardGroups[0]
+		if tc.truncated && !sgi.TruncatedAt.Equal(truncateTime) {
+			t.Fatalf("%s.%s: unexpected truncation: %v", tc.db, tc.rp, sgi.TruncatedAt)
+		} else if !tc.truncated && sgi.Truncated() {
+			t.Fatalf("%s.%s: unexpected truncation: %v", tc.db, tc.rp, sgi.TruncatedAt)
+		}
+	}
+
+	if got, exp := data.TruncateDatabaseShardGroups("db2", "", truncateTime), influxdb.ErrDatabaseNotFound("db2"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.TruncateDatabaseShardGroups("db0", "rp2", truncateTime), influxdb.ErrRetentionPolicyNotFound("rp2"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+}
+
 func TestUserInfo_AuthorizeDatabase(t *testing.T) {
 	emptyUser := &meta.UserInfo{}
 	if !emptyUser.AuthorizeDatabase(influxql.NoPrivileges, "anydb") {
@@ -414,6 +777,46 @@ func TestUserInfo_AuthorizeDatabase(t *testing.T) {
 	}
 }
 
+func TestUserInfo_AuthorizeMeasurement(t *testing.T) {
+	user := &meta.UserInfo{
+		Privileges: map[string]influxql.Privilege{"db1": influxql.WritePrivilege},
+		MeasurementPrivileges: []meta.MeasurementPrivilege{
+			{Database: "db0", Name: "cpu", Privilege: influxql.ReadPrivilege},
+			{Database: "db0", Regex: regexp.MustCompile(`^disk`), Privilege: influxql.AllPrivileges},
+		},
+	}
+	if user.IsOpen() {
+		t.Fatal("expected user with measurement privileges not to be open")
+	}
+
+	for _, tt := range []struct {
+		privilege   influxql.Privilege
+		database    string
+		measurement string
+		exp         bool
+	}{
+		{influxql.ReadPrivilege, "db0", "cpu", true},
+		{influxql.WritePrivilege, "db0", "cpu", false},
+		{influxql.ReadPrivilege, "db0", "disk_io", true},
+		{influxql.WritePrivilege, "db0", "disk_io", true},
+		{influxql.ReadPrivilege, "db0", "mem", false},
+		{influxql.ReadPrivilege, "db2", "cpu", false},
+		{influxql.WritePrivilege, "db1", "mem", true},
+	} {
+		if got := user.AuthorizeMeasurement(tt.privilege, tt.database, []byte(tt.measurement)); got != tt.exp {
+			t.Errorf("AuthorizeMeasurement(%s, %s, %s) = %v, expected %v", tt.privilege, tt.database, tt.measurement, got, tt.exp)
+		}
+	}
+
+	if !user.AuthorizeMeasurements(influxql.ReadPrivilege, "db0") {
+		t.Fatal("expected user to be authorized to read measurements of db0")
+	} else if user.AuthorizeMeasurements(influxql.ReadPrivilege, "db1") {
+		t.Fatal("expected user not to be authorized to read measurements of db1")
+	} else if user.AuthorizeDatabase(influxql.ReadPrivilege, "db0") {
+		t.Fatal("expected user not to be authorized to read db0")
+	}
+}
+
 func TestShardGroupInfo_Contains(t *testing.T) {
 	sgi := &meta.ShardGroupInfo{StartTime: time.Unix(10, 0), EndTime: time.Unix(20, 0)}
 
@@ -23,6 +23,9 @@ var (
 	// ErrDatabaseNameRequired is returned when creating a database without a name.
 	ErrDatabaseNameRequired = errors.New("database name required")
 
+	// ErrInvalidQuota is returned when setting a quota with a negative limit.
+	ErrInvalidQuota = errors.New("quota limits must not be negative")
+
 	// ErrNameTooLong is returned when attempting to create a database or
 	// retention policy with a name that is too long.
 	ErrNameTooLong = errors.New("name too long")
@@ -116,4 +119,31 @@ var (
 
 	// ErrAuthenticate is returned when authentication fails.
 	ErrAuthenticate = errors.New("authentication failed")
+
+	// ErrPasswordExpired is returned when authenticating with a password
+	// that must be changed.
+	ErrPasswordExpired = errors.New("password expired")
+)
+
+var (
+	// ErrRoleExists is returned when creating an already existing role.
+	ErrRoleExists = errors.New("role already exists")
+
+	// ErrRoleNotFound is returned when mutating a role that doesn't exist.
+	ErrRoleNotFound = errors.New("role not found")
+
+	// ErrRoleNameRequired is returned when creating a role without a name.
+	ErrRoleNameRequired = errors.New("role name required")
+)
+
+var (
+	// ErrTokenExists is returned when creating a token with the ID of an
+	// existing token.
+	ErrTokenExists = errors.New("token already exists")
+
+	// ErrTokenNotFound is returned when dropping a token that doesn't exist.
+	ErrTokenNotFound = errors.New("token not found")
+
+	// ErrTokenExpired is returned when authenticating with an expired token.
+	ErrTokenExpired = errors.New("token expired")
 )
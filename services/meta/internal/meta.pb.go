@@ -154,7 +154,7 @@ func (x *Command_Type) UnmarshalJSON(b []byte) error {
 
 // Deprecated: Use Command_Type.Descriptor instead.
 func (Command_Type) EnumDescriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{12, 0}
+	return file_internal_meta_proto_rawDescGZIP(), []int{16, 0}
 }
 
 type Data struct {
@@ -172,8 +172,10 @@ type Data struct {
 	MaxShardGroupID *uint64         `protobuf:"varint,8,req,name=MaxShardGroupID" json:"MaxShardGroupID,omitempty"`
 	MaxShardID      *uint64         `protobuf:"varint,9,req,name=MaxShardID" json:"MaxShardID,omitempty"`
 	// added for 0.10.0
-	DataNodes []*NodeInfo `protobuf:"bytes,10,rep,name=DataNodes" json:"DataNodes,omitempty"`
-	MetaNodes []*NodeInfo `protobuf:"bytes,11,rep,name=MetaNodes" json:"MetaNodes,omitempty"`
+	DataNodes []*NodeInfo  `protobuf:"bytes,10,rep,name=DataNodes" json:"DataNodes,omitempty"`
+	MetaNodes []*NodeInfo  `protobuf:"bytes,11,rep,name=MetaNodes" json:"MetaNodes,omitempty"`
+	Roles     []*RoleInfo  `protobuf:"bytes,12,rep,name=Roles" json:"Roles,omitempty"`
+	Tokens    []*TokenInfo `protobuf:"bytes,13,rep,name=Tokens" json:"Tokens,omitempty"`
 }
 
 func (x *Data) Reset() {
@@ -285,6 +287,20 @@ func (x *Data) GetMetaNodes() []*NodeInfo {
 	return nil
 }
 
+func (x *Data) GetRoles() []*RoleInfo {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *Data) GetTokens() []*TokenInfo {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
 type NodeInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -357,6 +373,7 @@ type DatabaseInfo struct {
 	DefaultRetentionPolicy *string                `protobuf:"bytes,2,req,name=DefaultRetentionPolicy" json:"DefaultRetentionPolicy,omitempty"`
 	RetentionPolicies      []*RetentionPolicyInfo `protobuf:"bytes,3,rep,name=RetentionPolicies" json:"RetentionPolicies,omitempty"`
 	ContinuousQueries      []*ContinuousQueryInfo `protobuf:"bytes,4,rep,name=ContinuousQueries" json:"ContinuousQueries,omitempty"`
+	Quota                  *QuotaInfo             `protobuf:"bytes,5,opt,name=Quota" json:"Quota,omitempty"`
 }
 
 func (x *DatabaseInfo) Reset() {
@@ -419,6 +436,13 @@ func (x *DatabaseInfo) GetContinuousQueries() []*ContinuousQueryInfo {
 	return nil
 }
 
+func (x *DatabaseInfo) GetQuota() *QuotaInfo {
+	if x != nil {
+		return x.Quota
+	}
+	return nil
+}
+
 type RetentionPolicySpec struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -899,10 +923,17 @@ type UserInfo struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name       *string          `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
-	Hash       *string          `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
-	Admin      *bool            `protobuf:"varint,3,req,name=Admin" json:"Admin,omitempty"`
-	Privileges []*UserPrivilege `protobuf:"bytes,4,rep,name=Privileges" json:"Privileges,omitempty"`
+	Name                  *string                 `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Hash                  *string                 `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
+	Admin                 *bool                   `protobuf:"varint,3,req,name=Admin" json:"Admin,omitempty"`
+	Privileges            []*UserPrivilege        `protobuf:"bytes,4,rep,name=Privileges" json:"Privileges,omitempty"`
+	TimeZone              *string                 `protobuf:"bytes,5,opt,name=TimeZone" json:"TimeZone,omitempty"`
+	MeasurementPrivileges []*MeasurementPrivilege `protobuf:"bytes,6,rep,name=MeasurementPrivileges" json:"MeasurementPrivileges,omitempty"`
+	Roles                 []string                `protobuf:"bytes,7,rep,name=Roles" json:"Roles,omitempty"`
+	PasswordChangedAt     *int64                  `protobuf:"varint,8,opt,name=PasswordChangedAt" json:"PasswordChangedAt,omitempty"`
+	WriteDatabase         *string                 `protobuf:"bytes,9,opt,name=WriteDatabase" json:"WriteDatabase,omitempty"`
+	WriteRetentionPolicy  *string                 `protobuf:"bytes,10,opt,name=WriteRetentionPolicy" json:"WriteRetentionPolicy,omitempty"`
+	QueryPriority         *string                 `protobuf:"bytes,11,opt,name=QueryPriority" json:"QueryPriority,omitempty"`
 }
 
 func (x *UserInfo) Reset() {
@@ -965,6 +996,55 @@ func (x *UserInfo) GetPrivileges() []*UserPrivilege {
 	return nil
 }
 
+func (x *UserInfo) GetTimeZone() string {
+	if x != nil && x.TimeZone != nil {
+		return *x.TimeZone
+	}
+	return ""
+}
+
+func (x *UserInfo) GetMeasurementPrivileges() []*MeasurementPrivilege {
+	if x != nil {
+		return x.MeasurementPrivileges
+	}
+	return nil
+}
+
+func (x *UserInfo) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *UserInfo) GetPasswordChangedAt() int64 {
+	if x != nil && x.PasswordChangedAt != nil {
+		return *x.PasswordChangedAt
+	}
+	return 0
+}
+
+func (x *UserInfo) GetWriteDatabase() string {
+	if x != nil && x.WriteDatabase != nil {
+		return *x.WriteDatabase
+	}
+	return ""
+}
+
+func (x *UserInfo) GetWriteRetentionPolicy() string {
+	if x != nil && x.WriteRetentionPolicy != nil {
+		return *x.WriteRetentionPolicy
+	}
+	return ""
+}
+
+func (x *UserInfo) GetQueryPriority() string {
+	if x != nil && x.QueryPriority != nil {
+		return *x.QueryPriority
+	}
+	return ""
+}
+
 type UserPrivilege struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1020,6 +1100,306 @@ func (x *UserPrivilege) GetPrivilege() int32 {
 	return 0
 }
 
+type MeasurementPrivilege struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Database  *string `protobuf:"bytes,1,req,name=Database" json:"Database,omitempty"`
+	Name      *string `protobuf:"bytes,2,opt,name=Name" json:"Name,omitempty"`
+	Regex     *string `protobuf:"bytes,3,opt,name=Regex" json:"Regex,omitempty"`
+	Privilege *int32  `protobuf:"varint,4,req,name=Privilege" json:"Privilege,omitempty"`
+}
+
+func (x *MeasurementPrivilege) Reset() {
+	*x = MeasurementPrivilege{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_meta_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MeasurementPrivilege) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MeasurementPrivilege) ProtoMessage() {}
+
+func (x *MeasurementPrivilege) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_meta_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MeasurementPrivilege.ProtoReflect.Descriptor instead.
+func (*MeasurementPrivilege) Descriptor() ([]byte, []int) {
+	return file_internal_meta_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MeasurementPrivilege) GetDatabase() string {
+	if x != nil && x.Database != nil {
+		return *x.Database
+	}
+	return ""
+}
+
+func (x *MeasurementPrivilege) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *MeasurementPrivilege) GetRegex() string {
+	if x != nil && x.Regex != nil {
+		return *x.Regex
+	}
+	return ""
+}
+
+func (x *MeasurementPrivilege) GetPrivilege() int32 {
+	if x != nil && x.Privilege != nil {
+		return *x.Privilege
+	}
+	return 0
+}
+
+type RoleInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name       *string          `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Privileges []*UserPrivilege `protobuf:"bytes,2,rep,name=Privileges" json:"Privileges,omitempty"`
+}
+
+func (x *RoleInfo) Reset() {
+	*x = RoleInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_meta_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoleInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoleInfo) ProtoMessage() {}
+
+func (x *RoleInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_meta_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoleInfo.ProtoReflect.Descriptor instead.
+func (*RoleInfo) Descriptor() ([]byte, []int) {
+	return file_internal_meta_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RoleInfo) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *RoleInfo) GetPrivileges() []*UserPrivilege {
+	if x != nil {
+		return x.Privileges
+	}
+	return nil
+}
+
+type TokenInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID                   *string `protobuf:"bytes,1,req,name=ID" json:"ID,omitempty"`
+	User                 *string `protobuf:"bytes,2,req,name=User" json:"User,omitempty"`
+	Hash                 *string `protobuf:"bytes,3,req,name=Hash" json:"Hash,omitempty"`
+	CreatedAt            *int64  `protobuf:"varint,4,opt,name=CreatedAt" json:"CreatedAt,omitempty"`
+	ExpiresAt            *int64  `protobuf:"varint,5,opt,name=ExpiresAt" json:"ExpiresAt,omitempty"`
+	LastUsedAt           *int64  `protobuf:"varint,6,opt,name=LastUsedAt" json:"LastUsedAt,omitempty"`
+	WriteDatabase        *string `protobuf:"bytes,7,opt,name=WriteDatabase" json:"WriteDatabase,omitempty"`
+	WriteRetentionPolicy *string `protobuf:"bytes,8,opt,name=WriteRetentionPolicy" json:"WriteRetentionPolicy,omitempty"`
+}
+
+func (x *TokenInfo) Reset() {
+	*x = TokenInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_meta_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenInfo) ProtoMessage() {}
+
+func (x *TokenInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_meta_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenInfo.ProtoReflect.Descriptor instead.
+func (*TokenInfo) Descriptor() ([]byte, []int) {
+	return file_internal_meta_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TokenInfo) GetID() string {
+	if x != nil && x.ID != nil {
+		return *x.ID
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetUser() string {
+	if x != nil && x.User != nil {
+		return *x.User
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetHash() string {
+	if x != nil && x.Hash != nil {
+		return *x.Hash
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetCreatedAt() int64 {
+	if x != nil && x.CreatedAt != nil {
+		return *x.CreatedAt
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetExpiresAt() int64 {
+	if x != nil && x.ExpiresAt != nil {
+		return *x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetLastUsedAt() int64 {
+	if x != nil && x.LastUsedAt != nil {
+		return *x.LastUsedAt
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetWriteDatabase() string {
+	if x != nil && x.WriteDatabase != nil {
+		return *x.WriteDatabase
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetWriteRetentionPolicy() string {
+	if x != nil && x.WriteRetentionPolicy != nil {
+		return *x.WriteRetentionPolicy
+	}
+	return ""
+}
+
+type QuotaInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaxSeries           *int64 `protobuf:"varint,1,opt,name=MaxSeries" json:"MaxSeries,omitempty"`
+	MaxMeasurements     *int64 `protobuf:"varint,2,opt,name=MaxMeasurements" json:"MaxMeasurements,omitempty"`
+	MaxWriteRate        *int64 `protobuf:"varint,3,opt,name=MaxWriteRate" json:"MaxWriteRate,omitempty"`
+	MaxConcurrentWrites *int64 `protobuf:"varint,4,opt,name=MaxConcurrentWrites" json:"MaxConcurrentWrites,omitempty"`
+}
+
+func (x *QuotaInfo) Reset() {
+	*x = QuotaInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_meta_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QuotaInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaInfo) ProtoMessage() {}
+
+func (x *QuotaInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_meta_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaInfo.ProtoReflect.Descriptor instead.
+func (*QuotaInfo) Descriptor() ([]byte, []int) {
+	return file_internal_meta_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *QuotaInfo) GetMaxSeries() int64 {
+	if x != nil && x.MaxSeries != nil {
+		return *x.MaxSeries
+	}
+	return 0
+}
+
+func (x *QuotaInfo) GetMaxMeasurements() int64 {
+	if x != nil && x.MaxMeasurements != nil {
+		return *x.MaxMeasurements
+	}
+	return 0
+}
+
+func (x *QuotaInfo) GetMaxWriteRate() int64 {
+	if x != nil && x.MaxWriteRate != nil {
+		return *x.MaxWriteRate
+	}
+	return 0
+}
+
+func (x *QuotaInfo) GetMaxConcurrentWrites() int64 {
+	if x != nil && x.MaxConcurrentWrites != nil {
+		return *x.MaxConcurrentWrites
+	}
+	return 0
+}
+
 type Command struct {
 	state           protoimpl.MessageState
 	sizeCache       protoimpl.SizeCache
@@ -1032,7 +1412,7 @@ type Command struct {
 func (x *Command) Reset() {
 	*x = Command{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[12]
+		mi := &file_internal_meta_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1045,7 +1425,7 @@ func (x *Command) String() string {
 func (*Command) ProtoMessage() {}
 
 func (x *Command) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[12]
+	mi := &file_internal_meta_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1058,7 +1438,7 @@ func (x *Command) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Command.ProtoReflect.Descriptor instead.
 func (*Command) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{12}
+	return file_internal_meta_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *Command) GetType() Command_Type {
@@ -1082,7 +1462,7 @@ type CreateNodeCommand struct {
 func (x *CreateNodeCommand) Reset() {
 	*x = CreateNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[13]
+		mi := &file_internal_meta_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1095,7 +1475,7 @@ func (x *CreateNodeCommand) String() string {
 func (*CreateNodeCommand) ProtoMessage() {}
 
 func (x *CreateNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[13]
+	mi := &file_internal_meta_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1108,7 +1488,7 @@ func (x *CreateNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateNodeCommand.ProtoReflect.Descriptor instead.
 func (*CreateNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{13}
+	return file_internal_meta_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *CreateNodeCommand) GetHost() string {
@@ -1137,7 +1517,7 @@ type DeleteNodeCommand struct {
 func (x *DeleteNodeCommand) Reset() {
 	*x = DeleteNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[14]
+		mi := &file_internal_meta_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1150,7 +1530,7 @@ func (x *DeleteNodeCommand) String() string {
 func (*DeleteNodeCommand) ProtoMessage() {}
 
 func (x *DeleteNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[14]
+	mi := &file_internal_meta_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1163,7 +1543,7 @@ func (x *DeleteNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteNodeCommand.ProtoReflect.Descriptor instead.
 func (*DeleteNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{14}
+	return file_internal_meta_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *DeleteNodeCommand) GetID() uint64 {
@@ -1192,7 +1572,7 @@ type CreateDatabaseCommand struct {
 func (x *CreateDatabaseCommand) Reset() {
 	*x = CreateDatabaseCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[15]
+		mi := &file_internal_meta_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1205,7 +1585,7 @@ func (x *CreateDatabaseCommand) String() string {
 func (*CreateDatabaseCommand) ProtoMessage() {}
 
 func (x *CreateDatabaseCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[15]
+	mi := &file_internal_meta_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1218,7 +1598,7 @@ func (x *CreateDatabaseCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateDatabaseCommand.ProtoReflect.Descriptor instead.
 func (*CreateDatabaseCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{15}
+	return file_internal_meta_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *CreateDatabaseCommand) GetName() string {
@@ -1246,7 +1626,7 @@ type DropDatabaseCommand struct {
 func (x *DropDatabaseCommand) Reset() {
 	*x = DropDatabaseCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[16]
+		mi := &file_internal_meta_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1259,7 +1639,7 @@ func (x *DropDatabaseCommand) String() string {
 func (*DropDatabaseCommand) ProtoMessage() {}
 
 func (x *DropDatabaseCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[16]
+	mi := &file_internal_meta_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1272,7 +1652,7 @@ func (x *DropDatabaseCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DropDatabaseCommand.ProtoReflect.Descriptor instead.
 func (*DropDatabaseCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{16}
+	return file_internal_meta_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *DropDatabaseCommand) GetName() string {
@@ -1294,7 +1674,7 @@ type CreateRetentionPolicyCommand struct {
 func (x *CreateRetentionPolicyCommand) Reset() {
 	*x = CreateRetentionPolicyCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[17]
+		mi := &file_internal_meta_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1307,7 +1687,7 @@ func (x *CreateRetentionPolicyCommand) String() string {
 func (*CreateRetentionPolicyCommand) ProtoMessage() {}
 
 func (x *CreateRetentionPolicyCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[17]
+	mi := &file_internal_meta_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1320,7 +1700,7 @@ func (x *CreateRetentionPolicyCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateRetentionPolicyCommand.ProtoReflect.Descriptor instead.
 func (*CreateRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{17}
+	return file_internal_meta_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *CreateRetentionPolicyCommand) GetDatabase() string {
@@ -1349,7 +1729,7 @@ type DropRetentionPolicyCommand struct {
 func (x *DropRetentionPolicyCommand) Reset() {
 	*x = DropRetentionPolicyCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[18]
+		mi := &file_internal_meta_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1362,7 +1742,7 @@ func (x *DropRetentionPolicyCommand) String() string {
 func (*DropRetentionPolicyCommand) ProtoMessage() {}
 
 func (x *DropRetentionPolicyCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[18]
+	mi := &file_internal_meta_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1375,7 +1755,7 @@ func (x *DropRetentionPolicyCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DropRetentionPolicyCommand.ProtoReflect.Descriptor instead.
 func (*DropRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{18}
+	return file_internal_meta_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *DropRetentionPolicyCommand) GetDatabase() string {
@@ -1404,7 +1784,7 @@ type SetDefaultRetentionPolicyCommand struct {
 func (x *SetDefaultRetentionPolicyCommand) Reset() {
 	*x = SetDefaultRetentionPolicyCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[19]
+		mi := &file_internal_meta_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1417,7 +1797,7 @@ func (x *SetDefaultRetentionPolicyCommand) String() string {
 func (*SetDefaultRetentionPolicyCommand) ProtoMessage() {}
 
 func (x *SetDefaultRetentionPolicyCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[19]
+	mi := &file_internal_meta_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1430,7 +1810,7 @@ func (x *SetDefaultRetentionPolicyCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetDefaultRetentionPolicyCommand.ProtoReflect.Descriptor instead.
 func (*SetDefaultRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{19}
+	return file_internal_meta_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *SetDefaultRetentionPolicyCommand) GetDatabase() string {
@@ -1462,7 +1842,7 @@ type UpdateRetentionPolicyCommand struct {
 func (x *UpdateRetentionPolicyCommand) Reset() {
 	*x = UpdateRetentionPolicyCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[20]
+		mi := &file_internal_meta_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1475,7 +1855,7 @@ func (x *UpdateRetentionPolicyCommand) String() string {
 func (*UpdateRetentionPolicyCommand) ProtoMessage() {}
 
 func (x *UpdateRetentionPolicyCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[20]
+	mi := &file_internal_meta_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1488,7 +1868,7 @@ func (x *UpdateRetentionPolicyCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateRetentionPolicyCommand.ProtoReflect.Descriptor instead.
 func (*UpdateRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{20}
+	return file_internal_meta_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *UpdateRetentionPolicyCommand) GetDatabase() string {
@@ -1539,7 +1919,7 @@ type CreateShardGroupCommand struct {
 func (x *CreateShardGroupCommand) Reset() {
 	*x = CreateShardGroupCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[21]
+		mi := &file_internal_meta_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1552,7 +1932,7 @@ func (x *CreateShardGroupCommand) String() string {
 func (*CreateShardGroupCommand) ProtoMessage() {}
 
 func (x *CreateShardGroupCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[21]
+	mi := &file_internal_meta_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1565,7 +1945,7 @@ func (x *CreateShardGroupCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateShardGroupCommand.ProtoReflect.Descriptor instead.
 func (*CreateShardGroupCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{21}
+	return file_internal_meta_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *CreateShardGroupCommand) GetDatabase() string {
@@ -1602,7 +1982,7 @@ type DeleteShardGroupCommand struct {
 func (x *DeleteShardGroupCommand) Reset() {
 	*x = DeleteShardGroupCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[22]
+		mi := &file_internal_meta_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1615,7 +1995,7 @@ func (x *DeleteShardGroupCommand) String() string {
 func (*DeleteShardGroupCommand) ProtoMessage() {}
 
 func (x *DeleteShardGroupCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[22]
+	mi := &file_internal_meta_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1628,7 +2008,7 @@ func (x *DeleteShardGroupCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteShardGroupCommand.ProtoReflect.Descriptor instead.
 func (*DeleteShardGroupCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{22}
+	return file_internal_meta_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *DeleteShardGroupCommand) GetDatabase() string {
@@ -1665,7 +2045,7 @@ type CreateContinuousQueryCommand struct {
 func (x *CreateContinuousQueryCommand) Reset() {
 	*x = CreateContinuousQueryCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[23]
+		mi := &file_internal_meta_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1678,7 +2058,7 @@ func (x *CreateContinuousQueryCommand) String() string {
 func (*CreateContinuousQueryCommand) ProtoMessage() {}
 
 func (x *CreateContinuousQueryCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[23]
+	mi := &file_internal_meta_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1691,7 +2071,7 @@ func (x *CreateContinuousQueryCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateContinuousQueryCommand.ProtoReflect.Descriptor instead.
 func (*CreateContinuousQueryCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{23}
+	return file_internal_meta_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *CreateContinuousQueryCommand) GetDatabase() string {
@@ -1727,7 +2107,7 @@ type DropContinuousQueryCommand struct {
 func (x *DropContinuousQueryCommand) Reset() {
 	*x = DropContinuousQueryCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[24]
+		mi := &file_internal_meta_proto_msgTypes[28]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1740,7 +2120,7 @@ func (x *DropContinuousQueryCommand) String() string {
 func (*DropContinuousQueryCommand) ProtoMessage() {}
 
 func (x *DropContinuousQueryCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[24]
+	mi := &file_internal_meta_proto_msgTypes[28]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1753,7 +2133,7 @@ func (x *DropContinuousQueryCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DropContinuousQueryCommand.ProtoReflect.Descriptor instead.
 func (*DropContinuousQueryCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{24}
+	return file_internal_meta_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *DropContinuousQueryCommand) GetDatabase() string {
@@ -1783,7 +2163,7 @@ type CreateUserCommand struct {
 func (x *CreateUserCommand) Reset() {
 	*x = CreateUserCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[25]
+		mi := &file_internal_meta_proto_msgTypes[29]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1796,7 +2176,7 @@ func (x *CreateUserCommand) String() string {
 func (*CreateUserCommand) ProtoMessage() {}
 
 func (x *CreateUserCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[25]
+	mi := &file_internal_meta_proto_msgTypes[29]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1809,7 +2189,7 @@ func (x *CreateUserCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateUserCommand.ProtoReflect.Descriptor instead.
 func (*CreateUserCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{25}
+	return file_internal_meta_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *CreateUserCommand) GetName() string {
@@ -1844,7 +2224,7 @@ type DropUserCommand struct {
 func (x *DropUserCommand) Reset() {
 	*x = DropUserCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[26]
+		mi := &file_internal_meta_proto_msgTypes[30]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1857,7 +2237,7 @@ func (x *DropUserCommand) String() string {
 func (*DropUserCommand) ProtoMessage() {}
 
 func (x *DropUserCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[26]
+	mi := &file_internal_meta_proto_msgTypes[30]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1870,7 +2250,7 @@ func (x *DropUserCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DropUserCommand.ProtoReflect.Descriptor instead.
 func (*DropUserCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{26}
+	return file_internal_meta_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *DropUserCommand) GetName() string {
@@ -1892,7 +2272,7 @@ type UpdateUserCommand struct {
 func (x *UpdateUserCommand) Reset() {
 	*x = UpdateUserCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[27]
+		mi := &file_internal_meta_proto_msgTypes[31]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1905,7 +2285,7 @@ func (x *UpdateUserCommand) String() string {
 func (*UpdateUserCommand) ProtoMessage() {}
 
 func (x *UpdateUserCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[27]
+	mi := &file_internal_meta_proto_msgTypes[31]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1918,7 +2298,7 @@ func (x *UpdateUserCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateUserCommand.ProtoReflect.Descriptor instead.
 func (*UpdateUserCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{27}
+	return file_internal_meta_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *UpdateUserCommand) GetName() string {
@@ -1948,7 +2328,7 @@ type SetPrivilegeCommand struct {
 func (x *SetPrivilegeCommand) Reset() {
 	*x = SetPrivilegeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[28]
+		mi := &file_internal_meta_proto_msgTypes[32]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1961,7 +2341,7 @@ func (x *SetPrivilegeCommand) String() string {
 func (*SetPrivilegeCommand) ProtoMessage() {}
 
 func (x *SetPrivilegeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[28]
+	mi := &file_internal_meta_proto_msgTypes[32]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1974,7 +2354,7 @@ func (x *SetPrivilegeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetPrivilegeCommand.ProtoReflect.Descriptor instead.
 func (*SetPrivilegeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{28}
+	return file_internal_meta_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *SetPrivilegeCommand) GetUsername() string {
@@ -2009,7 +2389,7 @@ type SetDataCommand struct {
 func (x *SetDataCommand) Reset() {
 	*x = SetDataCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[29]
+		mi := &file_internal_meta_proto_msgTypes[33]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2022,7 +2402,7 @@ func (x *SetDataCommand) String() string {
 func (*SetDataCommand) ProtoMessage() {}
 
 func (x *SetDataCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[29]
+	mi := &file_internal_meta_proto_msgTypes[33]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2035,7 +2415,7 @@ func (x *SetDataCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetDataCommand.ProtoReflect.Descriptor instead.
 func (*SetDataCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{29}
+	return file_internal_meta_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *SetDataCommand) GetData() *Data {
@@ -2057,7 +2437,7 @@ type SetAdminPrivilegeCommand struct {
 func (x *SetAdminPrivilegeCommand) Reset() {
 	*x = SetAdminPrivilegeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[30]
+		mi := &file_internal_meta_proto_msgTypes[34]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2070,7 +2450,7 @@ func (x *SetAdminPrivilegeCommand) String() string {
 func (*SetAdminPrivilegeCommand) ProtoMessage() {}
 
 func (x *SetAdminPrivilegeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[30]
+	mi := &file_internal_meta_proto_msgTypes[34]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2083,7 +2463,7 @@ func (x *SetAdminPrivilegeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetAdminPrivilegeCommand.ProtoReflect.Descriptor instead.
 func (*SetAdminPrivilegeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{30}
+	return file_internal_meta_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *SetAdminPrivilegeCommand) GetUsername() string {
@@ -2112,7 +2492,7 @@ type UpdateNodeCommand struct {
 func (x *UpdateNodeCommand) Reset() {
 	*x = UpdateNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[31]
+		mi := &file_internal_meta_proto_msgTypes[35]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2125,7 +2505,7 @@ func (x *UpdateNodeCommand) String() string {
 func (*UpdateNodeCommand) ProtoMessage() {}
 
 func (x *UpdateNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[31]
+	mi := &file_internal_meta_proto_msgTypes[35]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2138,7 +2518,7 @@ func (x *UpdateNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateNodeCommand.ProtoReflect.Descriptor instead.
 func (*UpdateNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{31}
+	return file_internal_meta_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *UpdateNodeCommand) GetID() uint64 {
@@ -2170,7 +2550,7 @@ type CreateSubscriptionCommand struct {
 func (x *CreateSubscriptionCommand) Reset() {
 	*x = CreateSubscriptionCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[32]
+		mi := &file_internal_meta_proto_msgTypes[36]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2183,7 +2563,7 @@ func (x *CreateSubscriptionCommand) String() string {
 func (*CreateSubscriptionCommand) ProtoMessage() {}
 
 func (x *CreateSubscriptionCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[32]
+	mi := &file_internal_meta_proto_msgTypes[36]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2196,7 +2576,7 @@ func (x *CreateSubscriptionCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateSubscriptionCommand.ProtoReflect.Descriptor instead.
 func (*CreateSubscriptionCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{32}
+	return file_internal_meta_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *CreateSubscriptionCommand) GetName() string {
@@ -2247,7 +2627,7 @@ type DropSubscriptionCommand struct {
 func (x *DropSubscriptionCommand) Reset() {
 	*x = DropSubscriptionCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[33]
+		mi := &file_internal_meta_proto_msgTypes[37]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2260,7 +2640,7 @@ func (x *DropSubscriptionCommand) String() string {
 func (*DropSubscriptionCommand) ProtoMessage() {}
 
 func (x *DropSubscriptionCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[33]
+	mi := &file_internal_meta_proto_msgTypes[37]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2273,7 +2653,7 @@ func (x *DropSubscriptionCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DropSubscriptionCommand.ProtoReflect.Descriptor instead.
 func (*DropSubscriptionCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{33}
+	return file_internal_meta_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *DropSubscriptionCommand) GetName() string {
@@ -2309,7 +2689,7 @@ type RemovePeerCommand struct {
 func (x *RemovePeerCommand) Reset() {
 	*x = RemovePeerCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[34]
+		mi := &file_internal_meta_proto_msgTypes[38]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2322,7 +2702,7 @@ func (x *RemovePeerCommand) String() string {
 func (*RemovePeerCommand) ProtoMessage() {}
 
 func (x *RemovePeerCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[34]
+	mi := &file_internal_meta_proto_msgTypes[38]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2335,7 +2715,7 @@ func (x *RemovePeerCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RemovePeerCommand.ProtoReflect.Descriptor instead.
 func (*RemovePeerCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{34}
+	return file_internal_meta_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *RemovePeerCommand) GetID() uint64 {
@@ -2365,7 +2745,7 @@ type CreateMetaNodeCommand struct {
 func (x *CreateMetaNodeCommand) Reset() {
 	*x = CreateMetaNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[35]
+		mi := &file_internal_meta_proto_msgTypes[39]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2378,7 +2758,7 @@ func (x *CreateMetaNodeCommand) String() string {
 func (*CreateMetaNodeCommand) ProtoMessage() {}
 
 func (x *CreateMetaNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[35]
+	mi := &file_internal_meta_proto_msgTypes[39]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2391,7 +2771,7 @@ func (x *CreateMetaNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateMetaNodeCommand.ProtoReflect.Descriptor instead.
 func (*CreateMetaNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{35}
+	return file_internal_meta_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *CreateMetaNodeCommand) GetHTTPAddr() string {
@@ -2427,7 +2807,7 @@ type CreateDataNodeCommand struct {
 func (x *CreateDataNodeCommand) Reset() {
 	*x = CreateDataNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[36]
+		mi := &file_internal_meta_proto_msgTypes[40]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2440,7 +2820,7 @@ func (x *CreateDataNodeCommand) String() string {
 func (*CreateDataNodeCommand) ProtoMessage() {}
 
 func (x *CreateDataNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[36]
+	mi := &file_internal_meta_proto_msgTypes[40]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2453,7 +2833,7 @@ func (x *CreateDataNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateDataNodeCommand.ProtoReflect.Descriptor instead.
 func (*CreateDataNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{36}
+	return file_internal_meta_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *CreateDataNodeCommand) GetHTTPAddr() string {
@@ -2483,7 +2863,7 @@ type UpdateDataNodeCommand struct {
 func (x *UpdateDataNodeCommand) Reset() {
 	*x = UpdateDataNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[37]
+		mi := &file_internal_meta_proto_msgTypes[41]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2496,7 +2876,7 @@ func (x *UpdateDataNodeCommand) String() string {
 func (*UpdateDataNodeCommand) ProtoMessage() {}
 
 func (x *UpdateDataNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[37]
+	mi := &file_internal_meta_proto_msgTypes[41]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2509,7 +2889,7 @@ func (x *UpdateDataNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateDataNodeCommand.ProtoReflect.Descriptor instead.
 func (*UpdateDataNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{37}
+	return file_internal_meta_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *UpdateDataNodeCommand) GetID() uint64 {
@@ -2544,7 +2924,7 @@ type DeleteMetaNodeCommand struct {
 func (x *DeleteMetaNodeCommand) Reset() {
 	*x = DeleteMetaNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[38]
+		mi := &file_internal_meta_proto_msgTypes[42]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2557,7 +2937,7 @@ func (x *DeleteMetaNodeCommand) String() string {
 func (*DeleteMetaNodeCommand) ProtoMessage() {}
 
 func (x *DeleteMetaNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[38]
+	mi := &file_internal_meta_proto_msgTypes[42]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2570,7 +2950,7 @@ func (x *DeleteMetaNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteMetaNodeCommand.ProtoReflect.Descriptor instead.
 func (*DeleteMetaNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{38}
+	return file_internal_meta_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *DeleteMetaNodeCommand) GetID() uint64 {
@@ -2591,7 +2971,7 @@ type DeleteDataNodeCommand struct {
 func (x *DeleteDataNodeCommand) Reset() {
 	*x = DeleteDataNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[39]
+		mi := &file_internal_meta_proto_msgTypes[43]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2604,7 +2984,7 @@ func (x *DeleteDataNodeCommand) String() string {
 func (*DeleteDataNodeCommand) ProtoMessage() {}
 
 func (x *DeleteDataNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[39]
+	mi := &file_internal_meta_proto_msgTypes[43]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2617,7 +2997,7 @@ func (x *DeleteDataNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteDataNodeCommand.ProtoReflect.Descriptor instead.
 func (*DeleteDataNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{39}
+	return file_internal_meta_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *DeleteDataNodeCommand) GetID() uint64 {
@@ -2640,7 +3020,7 @@ type Response struct {
 func (x *Response) Reset() {
 	*x = Response{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[40]
+		mi := &file_internal_meta_proto_msgTypes[44]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2653,7 +3033,7 @@ func (x *Response) String() string {
 func (*Response) ProtoMessage() {}
 
 func (x *Response) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[40]
+	mi := &file_internal_meta_proto_msgTypes[44]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2666,7 +3046,7 @@ func (x *Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Response.ProtoReflect.Descriptor instead.
 func (*Response) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{40}
+	return file_internal_meta_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *Response) GetOK() bool {
@@ -2705,7 +3085,7 @@ type SetMetaNodeCommand struct {
 func (x *SetMetaNodeCommand) Reset() {
 	*x = SetMetaNodeCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[41]
+		mi := &file_internal_meta_proto_msgTypes[45]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2718,7 +3098,7 @@ func (x *SetMetaNodeCommand) String() string {
 func (*SetMetaNodeCommand) ProtoMessage() {}
 
 func (x *SetMetaNodeCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[41]
+	mi := &file_internal_meta_proto_msgTypes[45]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2731,7 +3111,7 @@ func (x *SetMetaNodeCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetMetaNodeCommand.ProtoReflect.Descriptor instead.
 func (*SetMetaNodeCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{41}
+	return file_internal_meta_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *SetMetaNodeCommand) GetHTTPAddr() string {
@@ -2766,7 +3146,7 @@ type DropShardCommand struct {
 func (x *DropShardCommand) Reset() {
 	*x = DropShardCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_meta_proto_msgTypes[42]
+		mi := &file_internal_meta_proto_msgTypes[46]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2779,7 +3159,7 @@ func (x *DropShardCommand) String() string {
 func (*DropShardCommand) ProtoMessage() {}
 
 func (x *DropShardCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_meta_proto_msgTypes[42]
+	mi := &file_internal_meta_proto_msgTypes[46]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2792,7 +3172,7 @@ func (x *DropShardCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DropShardCommand.ProtoReflect.Descriptor instead.
 func (*DropShardCommand) Descriptor() ([]byte, []int) {
-	return file_internal_meta_proto_rawDescGZIP(), []int{42}
+	return file_internal_meta_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *DropShardCommand) GetID() uint64 {
@@ -3103,7 +3483,7 @@ var File_internal_meta_proto protoreflect.FileDescriptor
 
 var file_internal_meta_proto_rawDesc = []byte{
 	0x0a, 0x13, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x6d, 0x65, 0x74, 0x61, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x22, 0x90, 0x03, 0x0a, 0x04,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x22, 0xdf, 0x03, 0x0a, 0x04,
 	0x44, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x65, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x02,
 	0x28, 0x04, 0x52, 0x04, 0x54, 0x65, 0x72, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x49, 0x6e, 0x64, 0x65,
 	0x78, 0x18, 0x02, 0x20, 0x02, 0x28, 0x04, 0x52, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1c,
@@ -3128,436 +3508,503 @@ var file_internal_meta_proto_rawDesc = []byte{
 	0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x73,
 	0x12, 0x2c, 0x0a, 0x09, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x0b, 0x20,
 	0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x49,
-	0x6e, 0x66, 0x6f, 0x52, 0x09, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x48,
-	0x0a, 0x08, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44,
-	0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x6f,
-	0x73, 0x74, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x18,
-	0x0a, 0x07, 0x54, 0x43, 0x50, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x07, 0x54, 0x43, 0x50, 0x48, 0x6f, 0x73, 0x74, 0x22, 0xec, 0x01, 0x0a, 0x0c, 0x44, 0x61, 0x74,
-	0x61, 0x62, 0x61, 0x73, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x36, 0x0a,
-	0x16, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f,
-	0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x16, 0x44,
-	0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50,
-	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x47, 0x0a, 0x11, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69,
-	0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f,
-	0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x11, 0x52, 0x65, 0x74,
-	0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x12, 0x47,
-	0x0a, 0x11, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72,
-	0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61,
-	0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79,
-	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x11, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73,
-	0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x22, 0x91, 0x01, 0x0a, 0x13, 0x52, 0x65, 0x74, 0x65,
-	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x53, 0x70, 0x65, 0x63, 0x12,
-	0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e,
-	0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
-	0x2e, 0x0a, 0x12, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x75, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x12, 0x53, 0x68, 0x61,
-	0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
-	0x1a, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4e, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4e, 0x22, 0x87, 0x02, 0x0a, 0x13,
-	0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x09, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x24,
+	0x0a, 0x05, 0x52, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x52,
+	0x6f, 0x6c, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x06, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x0d,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x06, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x22, 0x48, 0x0a,
+	0x08, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x6f, 0x73,
+	0x74, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x54, 0x43, 0x50, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x54, 0x43, 0x50, 0x48, 0x6f, 0x73, 0x74, 0x22, 0x93, 0x02, 0x0a, 0x0c, 0x44, 0x61, 0x74, 0x61,
+	0x62, 0x61, 0x73, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x36, 0x0a, 0x16,
+	0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x16, 0x44, 0x65,
+	0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x12, 0x47, 0x0a, 0x11, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f,
+	0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x11, 0x52, 0x65, 0x74, 0x65,
+	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x12, 0x47, 0x0a,
+	0x11, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x69,
+	0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e,
+	0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x11, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51,
+	0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x05, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x22, 0x91, 0x01,
+	0x0a, 0x13, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x53, 0x70, 0x65, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x12, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x12, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x4e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x4e, 0x22, 0x87, 0x02, 0x0a, 0x13, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x02, 0x28, 0x03, 0x52,
+	0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x12, 0x53, 0x68, 0x61,
+	0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x03, 0x20, 0x02, 0x28, 0x03, 0x52, 0x12, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x4e, 0x18, 0x04, 0x20, 0x02, 0x28, 0x0d, 0x52, 0x08, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x4e, 0x12, 0x36, 0x0a, 0x0b, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x65, 0x74,
+	0x61, 0x2e, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x0b, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x3c, 0x0a,
+	0x0d, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0d, 0x53, 0x75,
+	0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xc1, 0x01, 0x0a, 0x0e,
+	0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e,
+	0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x1c,
+	0x0a, 0x09, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28,
+	0x03, 0x52, 0x09, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x45, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x02, 0x28, 0x03, 0x52, 0x07, 0x45,
+	0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x18, 0x04, 0x20, 0x02, 0x28, 0x03, 0x52, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x27, 0x0a, 0x06, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x68, 0x61, 0x72,
+	0x64, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x06, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x12, 0x20, 0x0a,
+	0x0b, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22,
+	0x65, 0x0a, 0x09, 0x53, 0x68, 0x61, 0x72, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02,
+	0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x1e, 0x0a, 0x08,
+	0x4f, 0x77, 0x6e, 0x65, 0x72, 0x49, 0x44, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x42, 0x02,
+	0x18, 0x01, 0x52, 0x08, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x49, 0x44, 0x73, 0x12, 0x28, 0x0a, 0x06,
+	0x4f, 0x77, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x53, 0x68, 0x61, 0x72, 0x64, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x52, 0x06,
+	0x4f, 0x77, 0x6e, 0x65, 0x72, 0x73, 0x22, 0x5e, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4d, 0x6f,
+	0x64, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x24, 0x0a, 0x0a, 0x53, 0x68, 0x61, 0x72, 0x64, 0x4f,
+	0x77, 0x6e, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x44, 0x18, 0x01,
+	0x20, 0x02, 0x28, 0x04, 0x52, 0x06, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x44, 0x22, 0x3f, 0x0a, 0x13,
+	0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49,
 	0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28,
-	0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x02, 0x28, 0x03, 0x52, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x12, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75,
-	0x70, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x02, 0x28, 0x03, 0x52,
-	0x12, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x75, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4e, 0x18,
-	0x04, 0x20, 0x02, 0x28, 0x0d, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4e, 0x12,
-	0x36, 0x0a, 0x0b, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x05,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x68, 0x61, 0x72,
-	0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0b, 0x53, 0x68, 0x61, 0x72,
-	0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x3c, 0x0a, 0x0d, 0x53, 0x75, 0x62, 0x73, 0x63,
-	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69,
-	0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0d, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xc1, 0x01, 0x0a, 0x0e, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47,
-	0x72, 0x6f, 0x75, 0x70, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01,
-	0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x72,
-	0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x03, 0x52, 0x09, 0x53, 0x74, 0x61,
-	0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x45, 0x6e, 0x64, 0x54, 0x69, 0x6d,
-	0x65, 0x18, 0x03, 0x20, 0x02, 0x28, 0x03, 0x52, 0x07, 0x45, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65,
-	0x12, 0x1c, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x04, 0x20,
-	0x02, 0x28, 0x03, 0x52, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x27,
-	0x0a, 0x06, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x68, 0x61, 0x72, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x52,
-	0x06, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x54, 0x72, 0x75, 0x6e, 0x63,
-	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x54, 0x72,
-	0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x65, 0x0a, 0x09, 0x53, 0x68, 0x61,
-	0x72, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02,
-	0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x1e, 0x0a, 0x08, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x49,
-	0x44, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x42, 0x02, 0x18, 0x01, 0x52, 0x08, 0x4f, 0x77,
-	0x6e, 0x65, 0x72, 0x49, 0x44, 0x73, 0x12, 0x28, 0x0a, 0x06, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x73,
-	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x68,
-	0x61, 0x72, 0x64, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x52, 0x06, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x73,
-	0x22, 0x5e, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02,
-	0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65,
-	0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x22, 0x0a, 0x0c,
-	0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x0c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x22, 0x24, 0x0a, 0x0a, 0x53, 0x68, 0x61, 0x72, 0x64, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x16,
-	0x0a, 0x06, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x06,
-	0x4e, 0x6f, 0x64, 0x65, 0x49, 0x44, 0x22, 0x3f, 0x0a, 0x13, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e,
-	0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a,
-	0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d,
-	0x65, 0x12, 0x14, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09,
-	0x52, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x22, 0x7d, 0x0a, 0x08, 0x55, 0x73, 0x65, 0x72, 0x49,
-	0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28,
-	0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18,
-	0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x41,
-	0x64, 0x6d, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x02, 0x28, 0x08, 0x52, 0x05, 0x41, 0x64, 0x6d, 0x69,
-	0x6e, 0x12, 0x33, 0x0a, 0x0a, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x73, 0x18,
-	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x55, 0x73, 0x65,
-	0x72, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x52, 0x0a, 0x50, 0x72, 0x69, 0x76,
-	0x69, 0x6c, 0x65, 0x67, 0x65, 0x73, 0x22, 0x49, 0x0a, 0x0d, 0x55, 0x73, 0x65, 0x72, 0x50, 0x72,
-	0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62,
-	0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62,
-	0x61, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65,
-	0x18, 0x02, 0x20, 0x02, 0x28, 0x05, 0x52, 0x09, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67,
-	0x65, 0x22, 0xd9, 0x06, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x26, 0x0a,
-	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x65,
-	0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52,
-	0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x9b, 0x06, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x15,
-	0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e,
-	0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x02, 0x12, 0x19, 0x0a, 0x15,
-	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x44, 0x72, 0x6f, 0x70, 0x44,
-	0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x04,
-	0x12, 0x20, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74,
-	0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x10, 0x05, 0x12, 0x1e, 0x0a, 0x1a, 0x44, 0x72, 0x6f, 0x70, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74,
-	0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x10, 0x06, 0x12, 0x24, 0x0a, 0x20, 0x53, 0x65, 0x74, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74,
-	0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x07, 0x12, 0x20, 0x0a, 0x1c, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63,
-	0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x08, 0x12, 0x1b, 0x0a, 0x17, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x09, 0x12, 0x1b, 0x0a, 0x17, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x10, 0x0a, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f,
-	0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0b, 0x12, 0x1e, 0x0a, 0x1a, 0x44, 0x72, 0x6f, 0x70, 0x43, 0x6f,
-	0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0c, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0d, 0x12, 0x13, 0x0a,
-	0x0f, 0x44, 0x72, 0x6f, 0x70, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x10, 0x0e, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0f, 0x12, 0x17, 0x0a, 0x13, 0x53, 0x65, 0x74,
-	0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x10, 0x10, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x10, 0x11, 0x12, 0x1c, 0x0a, 0x18, 0x53, 0x65, 0x74, 0x41, 0x64, 0x6d,
-	0x69, 0x6e, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x10, 0x12, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x6f,
-	0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x13, 0x12, 0x1d, 0x0a, 0x19, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x15, 0x12, 0x1b, 0x0a, 0x17, 0x44, 0x72,
-	0x6f, 0x70, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x16, 0x12, 0x15, 0x0a, 0x11, 0x52, 0x65, 0x6d, 0x6f, 0x76,
-	0x65, 0x50, 0x65, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x17, 0x12, 0x19,
-	0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x18, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x72, 0x65,
-	0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x10, 0x19, 0x12, 0x19, 0x0a, 0x15, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x44, 0x61,
-	0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x1a, 0x12,
-	0x19, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64,
-	0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x1b, 0x12, 0x19, 0x0a, 0x15, 0x44, 0x65,
-	0x6c, 0x65, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x10, 0x1c, 0x12, 0x16, 0x0a, 0x12, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x61,
-	0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x1d, 0x12, 0x14, 0x0a,
-	0x10, 0x44, 0x72, 0x6f, 0x70, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x10, 0x1e, 0x2a, 0x08, 0x08, 0x64, 0x10, 0x80, 0x80, 0x80, 0x80, 0x02, 0x22, 0x7d, 0x0a,
-	0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09,
-	0x52, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x18, 0x02,
-	0x20, 0x02, 0x28, 0x04, 0x52, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x65, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74,
-	0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x7b, 0x0a, 0x11,
-	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49,
-	0x44, 0x12, 0x14, 0x0a, 0x05, 0x46, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x08,
-	0x52, 0x05, 0x46, 0x6f, 0x72, 0x63, 0x65, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x18, 0x66, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xb6, 0x01, 0x0a, 0x15, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28,
-	0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x43, 0x0a, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e,
-	0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f,
-	0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0f, 0x52, 0x65, 0x74,
-	0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x32, 0x44, 0x0a, 0x07,
-	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x67, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d,
-	0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61,
-	0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x22, 0x6d, 0x0a, 0x13, 0x44, 0x72, 0x6f, 0x70, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61,
-	0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x32, 0x42, 0x0a,
-	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x68, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
-	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73,
-	0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x22, 0xcc, 0x01, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65,
+	0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x22, 0xaf, 0x03,
+	0x0a, 0x08, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x61,
+	0x73, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x02, 0x28,
+	0x08, 0x52, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x33, 0x0a, 0x0a, 0x50, 0x72, 0x69, 0x76,
+	0x69, 0x6c, 0x65, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67,
+	0x65, 0x52, 0x0a, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x73, 0x12, 0x1a, 0x0a,
+	0x08, 0x54, 0x69, 0x6d, 0x65, 0x5a, 0x6f, 0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x54, 0x69, 0x6d, 0x65, 0x5a, 0x6f, 0x6e, 0x65, 0x12, 0x50, 0x0a, 0x15, 0x4d, 0x65, 0x61,
+	0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67,
+	0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e,
+	0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x69, 0x76, 0x69,
+	0x6c, 0x65, 0x67, 0x65, 0x52, 0x15, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x52,
+	0x6f, 0x6c, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x52, 0x6f, 0x6c, 0x65,
+	0x73, 0x12, 0x2c, 0x0a, 0x11, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x64, 0x41, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x50, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x41, 0x74, 0x12,
+	0x24, 0x0a, 0x0d, 0x57, 0x72, 0x69, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x57, 0x72, 0x69, 0x74, 0x65, 0x44, 0x61, 0x74,
+	0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x14, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65,
+	0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x14, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74,
+	0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x24, 0x0a, 0x0d, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22,
+	0x49, 0x0a, 0x0d, 0x55, 0x73, 0x65, 0x72, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x02,
+	0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x05, 0x52,
+	0x09, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x22, 0x7a, 0x0a, 0x14, 0x4d, 0x65,
+	0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65,
+	0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01,
+	0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x52, 0x65, 0x67, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x52, 0x65, 0x67, 0x65, 0x78, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x72, 0x69, 0x76,
+	0x69, 0x6c, 0x65, 0x67, 0x65, 0x18, 0x04, 0x20, 0x02, 0x28, 0x05, 0x52, 0x09, 0x50, 0x72, 0x69,
+	0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x22, 0x53, 0x0a, 0x08, 0x52, 0x6f, 0x6c, 0x65, 0x49, 0x6e,
+	0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09,
+	0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x33, 0x0a, 0x0a, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c,
+	0x65, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d, 0x65, 0x74,
+	0x61, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x52,
+	0x0a, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x73, 0x22, 0xf9, 0x01, 0x0a, 0x09,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x55, 0x73, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x55, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a,
+	0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x61, 0x73,
+	0x68, 0x12, 0x1c, 0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x1e, 0x0a,
+	0x0a, 0x4c, 0x61, 0x73, 0x74, 0x55, 0x73, 0x65, 0x64, 0x41, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x4c, 0x61, 0x73, 0x74, 0x55, 0x73, 0x65, 0x64, 0x41, 0x74, 0x12, 0x24, 0x0a,
+	0x0d, 0x57, 0x72, 0x69, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x57, 0x72, 0x69, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62,
+	0x61, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x14, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65,
+	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x14, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f,
+	0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x22, 0xa9, 0x01, 0x0a, 0x09, 0x51, 0x75, 0x6f, 0x74,
+	0x61, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x4d, 0x61, 0x78, 0x53, 0x65, 0x72, 0x69,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x4d, 0x61, 0x78, 0x53, 0x65, 0x72,
+	0x69, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x4d, 0x61,
+	0x78, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x22, 0x0a,
+	0x0c, 0x4d, 0x61, 0x78, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0c, 0x4d, 0x61, 0x78, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x61, 0x74,
+	0x65, 0x12, 0x30, 0x0a, 0x13, 0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x74, 0x57, 0x72, 0x69, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13,
+	0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x73, 0x22, 0xd9, 0x06, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
+	0x26, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0e, 0x32, 0x12, 0x2e,
+	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x9b, 0x06, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x15, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x02, 0x12, 0x19,
+	0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x44, 0x72, 0x6f,
+	0x70, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x10, 0x04, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65,
 	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01,
-	0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x43,
-	0x0a, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63,
-	0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x52,
-	0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e,
-	0x66, 0x6f, 0x52, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c,
-	0x69, 0x63, 0x79, 0x32, 0x4b, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x69, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x22, 0x97, 0x01, 0x0a, 0x1a, 0x44, 0x72, 0x6f, 0x70, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69,
-	0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
-	0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28,
-	0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x4e,
-	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x32,
-	0x49, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74,
-	0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6a, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x20, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x52, 0x65, 0x74, 0x65, 0x6e,
+	0x6e, 0x64, 0x10, 0x05, 0x12, 0x1e, 0x0a, 0x1a, 0x44, 0x72, 0x6f, 0x70, 0x52, 0x65, 0x74, 0x65,
+	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x10, 0x06, 0x12, 0x24, 0x0a, 0x20, 0x53, 0x65, 0x74, 0x44, 0x65, 0x66, 0x61, 0x75,
+	0x6c, 0x74, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x07, 0x12, 0x20, 0x0a, 0x1c, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x08, 0x12, 0x1b, 0x0a, 0x17,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x09, 0x12, 0x1b, 0x0a, 0x17, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0a, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0b, 0x12, 0x1e, 0x0a, 0x1a, 0x44, 0x72, 0x6f, 0x70,
+	0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0c, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0d, 0x12,
+	0x13, 0x0a, 0x0f, 0x44, 0x72, 0x6f, 0x70, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x10, 0x0e, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x73,
+	0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x0f, 0x12, 0x17, 0x0a, 0x13, 0x53,
+	0x65, 0x74, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x10, 0x10, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x11, 0x12, 0x1c, 0x0a, 0x18, 0x53, 0x65, 0x74, 0x41,
+	0x64, 0x6d, 0x69, 0x6e, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x10, 0x12, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x13, 0x12, 0x1d, 0x0a,
+	0x19, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x15, 0x12, 0x1b, 0x0a, 0x17,
+	0x44, 0x72, 0x6f, 0x70, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x16, 0x12, 0x15, 0x0a, 0x11, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x50, 0x65, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x17,
+	0x12, 0x19, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f,
+	0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x18, 0x12, 0x19, 0x0a, 0x15, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x10, 0x19, 0x12, 0x19, 0x0a, 0x15, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10,
+	0x1a, 0x12, 0x19, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x4e,
+	0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x1b, 0x12, 0x19, 0x0a, 0x15,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x1c, 0x12, 0x16, 0x0a, 0x12, 0x53, 0x65, 0x74, 0x4d, 0x65,
+	0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x1d, 0x12,
+	0x14, 0x0a, 0x10, 0x44, 0x72, 0x6f, 0x70, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x10, 0x1e, 0x2a, 0x08, 0x08, 0x64, 0x10, 0x80, 0x80, 0x80, 0x80, 0x02, 0x22,
+	0x7d, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x01, 0x20, 0x02,
+	0x28, 0x09, 0x52, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x52, 0x61, 0x6e, 0x64,
+	0x18, 0x02, 0x20, 0x02, 0x28, 0x04, 0x52, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x32, 0x40, 0x0a, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x65, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x7b,
+	0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52,
+	0x02, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x46, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x02,
+	0x28, 0x08, 0x52, 0x05, 0x46, 0x6f, 0x72, 0x63, 0x65, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x18, 0x66, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61,
+	0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xb6, 0x01, 0x0a, 0x15,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x43, 0x0a, 0x0f, 0x52, 0x65, 0x74,
+	0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74,
+	0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0f, 0x52,
+	0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x32, 0x44,
+	0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61,
+	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x67, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61,
+	0x62, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x22, 0x6d, 0x0a, 0x13, 0x44, 0x72, 0x6f, 0x70, 0x44, 0x61, 0x74, 0x61,
+	0x62, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x32,
+	0x42, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74,
+	0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x68, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x44, 0x61, 0x74, 0x61, 0x62,
+	0x61, 0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x22, 0xcc, 0x01, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
+	0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
+	0x12, 0x43, 0x0a, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x65, 0x74, 0x61,
+	0x2e, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x32, 0x4b, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
+	0x69, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x22, 0x97, 0x01, 0x0a, 0x1a, 0x44, 0x72, 0x6f, 0x70, 0x52, 0x65, 0x74, 0x65, 0x6e,
 	0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xa3, 0x01, 0x0a, 0x20, 0x53,
-	0x65, 0x74, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69,
-	0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
-	0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28,
-	0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x4e,
-	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x32,
-	0x4f, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74,
-	0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6b, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x26, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c,
-	0x74, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x22, 0xed, 0x01, 0x0a, 0x1c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65, 0x6e,
+	0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20,
+	0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d,
+	0x65, 0x32, 0x49, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x52, 0x65, 0x74,
+	0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xa3, 0x01, 0x0a,
+	0x20, 0x53, 0x65, 0x74, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x74, 0x65, 0x6e,
 	0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
 	0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20,
 	0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x12, 0x0a,
 	0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d,
-	0x65, 0x12, 0x18, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x07, 0x4e, 0x65, 0x77, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44,
-	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x44,
-	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69,
-	0x63, 0x61, 0x4e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69,
-	0x63, 0x61, 0x4e, 0x32, 0x4b, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6c, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74,
-	0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x22, 0xb3, 0x01, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64,
-	0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08,
-	0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08,
-	0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69,
-	0x63, 0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x12, 0x1c, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20,
-	0x02, 0x28, 0x03, 0x52, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x32, 0x46,
-	0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61,
-	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72,
-	0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xb9, 0x01, 0x0a, 0x17, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01,
-	0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x16,
-	0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x06,
-	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x22, 0x0a, 0x0c, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47,
-	0x72, 0x6f, 0x75, 0x70, 0x49, 0x44, 0x18, 0x03, 0x20, 0x02, 0x28, 0x04, 0x52, 0x0c, 0x53, 0x68,
-	0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x44, 0x32, 0x46, 0x0a, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6d, 0x65, 0x74,
-	0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f,
-	0x75, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x22, 0xb1, 0x01, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e,
-	0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x6d, 0x6d,
+	0x65, 0x32, 0x4f, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6b, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x26, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x44, 0x65, 0x66, 0x61,
+	0x75, 0x6c, 0x74, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x22, 0xed, 0x01, 0x0a, 0x1c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x74,
+	0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d,
 	0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18,
 	0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12,
 	0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e,
-	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x18, 0x03, 0x20, 0x02,
-	0x28, 0x09, 0x52, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x32, 0x4b, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x18, 0x6f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6d, 0x65, 0x74, 0x61,
-	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75,
-	0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x97, 0x01, 0x0a, 0x1a, 0x44, 0x72, 0x6f, 0x70, 0x43,
+	0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4e, 0x65, 0x77, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x4e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x4e, 0x32, 0x4b, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
+	0x6c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x22, 0xb3, 0x01, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x61,
+	0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09,
+	0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x06, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18,
+	0x03, 0x20, 0x02, 0x28, 0x03, 0x52, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x32, 0x46, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65,
+	0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6d, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68,
+	0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xb9, 0x01, 0x0a, 0x17, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
+	0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09,
+	0x52, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x22, 0x0a, 0x0c, 0x53, 0x68, 0x61, 0x72,
+	0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x44, 0x18, 0x03, 0x20, 0x02, 0x28, 0x04, 0x52, 0x0c,
+	0x53, 0x68, 0x61, 0x72, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x44, 0x32, 0x46, 0x0a, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x47,
+	0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x22, 0xb1, 0x01, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43,
 	0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x43, 0x6f,
 	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73,
 	0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73,
 	0x65, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52,
-	0x04, 0x4e, 0x61, 0x6d, 0x65, 0x32, 0x49, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
-	0x70, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f,
+	0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x18, 0x03,
+	0x20, 0x02, 0x28, 0x09, 0x52, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x32, 0x4b, 0x0a, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x6f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6d, 0x65,
+	0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75,
+	0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x97, 0x01, 0x0a, 0x1a, 0x44, 0x72, 0x6f,
 	0x70, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62,
+	0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62,
+	0x61, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28,
+	0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x32, 0x49, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x18, 0x70, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44,
+	0x72, 0x6f, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x6f, 0x75, 0x73, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x22, 0x93, 0x01, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65,
+	0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68,
+	0x12, 0x14, 0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x02, 0x28, 0x08, 0x52,
+	0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x18, 0x71, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x65, 0x0a, 0x0f, 0x44, 0x72, 0x6f, 0x70,
+	0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x32,
+	0x3e, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74,
+	0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x72, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x55, 0x73, 0x65, 0x72, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22,
+	0x7d, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02,
+	0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68,
+	0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x32, 0x40, 0x0a, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x73, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xaf,
+	0x01, 0x0a, 0x13, 0x53, 0x65, 0x74, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x55, 0x73, 0x65, 0x72, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x55, 0x73, 0x65, 0x72, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x02,
+	0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x18, 0x03, 0x20, 0x02, 0x28,
+	0x05, 0x52, 0x09, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x32, 0x42, 0x0a, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x74, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65,
 	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x22, 0x93, 0x01, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61,
-	0x73, 0x68, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x14,
-	0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x02, 0x28, 0x08, 0x52, 0x05, 0x41,
-	0x64, 0x6d, 0x69, 0x6e, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
-	0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x71,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x65, 0x0a, 0x0f, 0x44, 0x72, 0x6f, 0x70, 0x55, 0x73,
-	0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x32, 0x3e, 0x0a,
-	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x72, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
-	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x7d, 0x0a,
-	0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x22, 0x6f, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x12, 0x1e, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x44, 0x61,
+	0x74, 0x61, 0x32, 0x3d, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e,
+	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x75, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74,
+	0x61, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x22, 0x95, 0x01, 0x0a, 0x18, 0x53, 0x65, 0x74, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x50, 0x72,
+	0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x55, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09,
+	0x52, 0x08, 0x55, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x41, 0x64,
+	0x6d, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x02, 0x28, 0x08, 0x52, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e,
+	0x32, 0x47, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65,
+	0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x76, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x64, 0x6d, 0x69, 0x6e,
+	0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x79, 0x0a, 0x11, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e,
+	0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x12,
+	0x0a, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x6f,
+	0x73, 0x74, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e,
+	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x77, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x22, 0xf7, 0x01, 0x0a, 0x19, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
 	0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09,
-	0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02,
-	0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x73, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74,
-	0x61, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xaf, 0x01, 0x0a,
-	0x13, 0x53, 0x65, 0x74, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x55, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65,
-	0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x55, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65,
-	0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x02, 0x20, 0x02,
-	0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09,
-	0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x18, 0x03, 0x20, 0x02, 0x28, 0x05, 0x52,
-	0x09, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x32, 0x42, 0x0a, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x74, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x65, 0x74,
-	0x61, 0x2e, 0x53, 0x65, 0x74, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x6f,
-	0x0a, 0x0e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x12, 0x1e, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0b, 0x32, 0x0a,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61,
-	0x32, 0x3d, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65,
-	0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x75, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x14, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22,
-	0x95, 0x01, 0x0a, 0x18, 0x53, 0x65, 0x74, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x50, 0x72, 0x69, 0x76,
-	0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08,
-	0x55, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08,
-	0x55, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69,
-	0x6e, 0x18, 0x02, 0x20, 0x02, 0x28, 0x08, 0x52, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x32, 0x47,
-	0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61,
-	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x76, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x50, 0x72,
-	0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07,
-	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x79, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74,
-	0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02,
-	0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04,
-	0x48, 0x6f, 0x73, 0x74, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48, 0x6f, 0x73, 0x74,
-	0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65,
-	0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x77, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x6f,
-	0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x22, 0xf7, 0x01, 0x0a, 0x19, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62,
-	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04,
-	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
-	0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
-	0x12, 0x28, 0x0a, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c,
-	0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x02, 0x28, 0x09, 0x52, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e,
-	0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x4d, 0x6f,
-	0x64, 0x65, 0x18, 0x04, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x22,
-	0x0a, 0x0c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x73, 0x32, 0x48, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e,
-	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x79, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61,
+	0x73, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61,
+	0x73, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x02, 0x28, 0x09, 0x52, 0x0f, 0x52, 0x65, 0x74,
+	0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x12, 0x0a, 0x04,
+	0x4d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x22, 0x0a, 0x0c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x32, 0x48, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
+	0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x79,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xbb,
+	0x01, 0x0a, 0x17, 0x44, 0x72, 0x6f, 0x70, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09,
+	0x52, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x52, 0x65,
+	0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03, 0x20,
+	0x02, 0x28, 0x09, 0x52, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x32, 0x46, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
+	0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7a,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70,
 	0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xbb, 0x01, 0x0a,
-	0x17, 0x44, 0x72, 0x6f, 0x70, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65,
-	0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08,
-	0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08,
-	0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x52, 0x65, 0x74, 0x65,
-	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x02, 0x28,
-	0x09, 0x52, 0x0f, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69,
-	0x63, 0x79, 0x32, 0x46, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e,
-	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7a, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x53, 0x75,
-	0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x79, 0x0a, 0x11, 0x52, 0x65,
-	0x6d, 0x6f, 0x76, 0x65, 0x50, 0x65, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
-	0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12,
-	0x12, 0x0a, 0x04, 0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x41,
-	0x64, 0x64, 0x72, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7b, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76,
-	0x65, 0x50, 0x65, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xa7, 0x01, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
-	0x1a, 0x0a, 0x08, 0x48, 0x54, 0x54, 0x50, 0x41, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x02, 0x28,
-	0x09, 0x52, 0x08, 0x48, 0x54, 0x54, 0x50, 0x41, 0x64, 0x64, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x54,
-	0x43, 0x50, 0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x07, 0x54, 0x43,
-	0x50, 0x41, 0x64, 0x64, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20,
-	0x02, 0x28, 0x04, 0x52, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x32, 0x44, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x18, 0x7c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d, 0x65, 0x74, 0x61,
-	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22,
-	0x93, 0x01, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f,
-	0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x48, 0x54, 0x54,
-	0x50, 0x41, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x48, 0x54, 0x54,
-	0x50, 0x41, 0x64, 0x64, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x54, 0x43, 0x50, 0x41, 0x64, 0x64, 0x72,
-	0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x07, 0x54, 0x43, 0x50, 0x41, 0x64, 0x64, 0x72, 0x32,
-	0x44, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74,
-	0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7d, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1b, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74,
-	0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x9b, 0x01, 0x0a, 0x15, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
-	0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
-	0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12,
-	0x12, 0x0a, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x48,
-	0x6f, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x54, 0x43, 0x50, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x03,
-	0x20, 0x02, 0x28, 0x09, 0x52, 0x07, 0x54, 0x43, 0x50, 0x48, 0x6f, 0x73, 0x74, 0x32, 0x44, 0x0a,
-	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
-	0x6d, 0x65, 0x74, 0x61, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4e,
-	0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x22, 0x6d, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x74,
-	0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02,
-	0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x32, 0x44, 0x0a, 0x07,
-	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d,
-	0x65, 0x74, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f,
-	0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x22, 0x6e, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61,
-	0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49,
-	0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x32, 0x45, 0x0a, 0x07, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x80, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d,
-	0x65, 0x74, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f,
-	0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x22, 0x46, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e,
-	0x0a, 0x02, 0x4f, 0x4b, 0x18, 0x01, 0x20, 0x02, 0x28, 0x08, 0x52, 0x02, 0x4f, 0x4b, 0x12, 0x14,
-	0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x45,
-	0x72, 0x72, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0xa2, 0x01, 0x0a, 0x12, 0x53,
-	0x65, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x79, 0x0a, 0x11,
+	0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x65, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x49,
+	0x44, 0x12, 0x12, 0x0a, 0x04, 0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52,
+	0x04, 0x41, 0x64, 0x64, 0x72, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
+	0x7b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x50, 0x65, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xa7, 0x01, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
 	0x64, 0x12, 0x1a, 0x0a, 0x08, 0x48, 0x54, 0x54, 0x50, 0x41, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20,
 	0x02, 0x28, 0x09, 0x52, 0x08, 0x48, 0x54, 0x54, 0x50, 0x41, 0x64, 0x64, 0x72, 0x12, 0x18, 0x0a,
 	0x07, 0x54, 0x43, 0x50, 0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x07,
 	0x54, 0x43, 0x50, 0x41, 0x64, 0x64, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x18,
-	0x03, 0x20, 0x02, 0x28, 0x04, 0x52, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x32, 0x42, 0x0a, 0x07, 0x63,
+	0x03, 0x20, 0x02, 0x28, 0x04, 0x52, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x32, 0x44, 0x0a, 0x07, 0x63,
 	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x81, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6d,
-	0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22,
-	0x64, 0x0a, 0x10, 0x44, 0x72, 0x6f, 0x70, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52,
-	0x02, 0x49, 0x44, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d,
-	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x82, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72, 0x6f, 0x70,
-	0x53, 0x68, 0x61, 0x72, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x42, 0x08, 0x5a, 0x06, 0x2e, 0x3b, 0x6d, 0x65, 0x74, 0x61,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d, 0x65,
+	0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64,
+	0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x22, 0x93, 0x01, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61,
+	0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x48,
+	0x54, 0x54, 0x50, 0x41, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x48,
+	0x54, 0x54, 0x50, 0x41, 0x64, 0x64, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x54, 0x43, 0x50, 0x41, 0x64,
+	0x64, 0x72, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x07, 0x54, 0x43, 0x50, 0x41, 0x64, 0x64,
+	0x72, 0x32, 0x44, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d,
+	0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7d, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44,
+	0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x9b, 0x01, 0x0a, 0x15, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49,
+	0x44, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09, 0x52,
+	0x04, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x54, 0x43, 0x50, 0x48, 0x6f, 0x73, 0x74,
+	0x18, 0x03, 0x20, 0x02, 0x28, 0x09, 0x52, 0x07, 0x54, 0x43, 0x50, 0x48, 0x6f, 0x73, 0x74, 0x32,
+	0x44, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74,
+	0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7e, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74,
+	0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x6d, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d,
+	0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e,
+	0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x32, 0x44,
+	0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61,
+	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x7f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61,
+	0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x22, 0x6e, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x61,
+	0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a,
+	0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x32, 0x45, 0x0a,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x80, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61,
+	0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x22, 0x46, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x4f, 0x4b, 0x18, 0x01, 0x20, 0x02, 0x28, 0x08, 0x52, 0x02, 0x4f, 0x4b,
+	0x12, 0x14, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0xa2, 0x01, 0x0a,
+	0x12, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x48, 0x54, 0x54, 0x50, 0x41, 0x64, 0x64, 0x72, 0x18,
+	0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x08, 0x48, 0x54, 0x54, 0x50, 0x41, 0x64, 0x64, 0x72, 0x12,
+	0x18, 0x0a, 0x07, 0x54, 0x43, 0x50, 0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x02, 0x28, 0x09,
+	0x52, 0x07, 0x54, 0x43, 0x50, 0x41, 0x64, 0x64, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x52, 0x61, 0x6e,
+	0x64, 0x18, 0x03, 0x20, 0x02, 0x28, 0x04, 0x52, 0x04, 0x52, 0x61, 0x6e, 0x64, 0x32, 0x42, 0x0a,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x81, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
+	0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x4e, 0x6f, 0x64,
+	0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x22, 0x64, 0x0a, 0x10, 0x44, 0x72, 0x6f, 0x70, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x02, 0x28,
+	0x04, 0x52, 0x02, 0x49, 0x44, 0x32, 0x40, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x12, 0x0d, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
+	0x82, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x44, 0x72,
+	0x6f, 0x70, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x42, 0x08, 0x5a, 0x06, 0x2e, 0x3b, 0x6d, 0x65, 0x74,
+	0x61,
 }
 
 var (
@@ -3573,7 +4020,7 @@ func file_internal_meta_proto_rawDescGZIP() []byte {
 }
 
 var file_internal_meta_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_internal_meta_proto_msgTypes = make([]protoimpl.MessageInfo, 43)
+var file_internal_meta_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_internal_meta_proto_goTypes = []interface{}{
 	(Command_Type)(0),                        // 0: meta.Command.Type
 	(*Data)(nil),                             // 1: meta.Data
@@ -3588,37 +4035,41 @@ var file_internal_meta_proto_goTypes = []interface{}{
 	(*ContinuousQueryInfo)(nil),              // 10: meta.ContinuousQueryInfo
 	(*UserInfo)(nil),                         // 11: meta.UserInfo
 	(*UserPrivilege)(nil),                    // 12: meta.UserPrivilege
-	(*Command)(nil),                          // 13: meta.Command
-	(*CreateNodeCommand)(nil),                // 14: meta.CreateNodeCommand
-	(*DeleteNodeCommand)(nil),                // 15: meta.DeleteNodeCommand
-	(*CreateDatabaseCommand)(nil),            // 16: meta.CreateDatabaseCommand
-	(*DropDatabaseCommand)(nil),              // 17: meta.DropDatabaseCommand
-	(*CreateRetentionPolicyCommand)(nil),     // 18: meta.CreateRetentionPolicyCommand
-	(*DropRetentionPolicyCommand)(nil),       // 19: meta.DropRetentionPolicyCommand
-	(*SetDefaultRetentionPolicyCommand)(nil), // 20: meta.SetDefaultRetentionPolicyCommand
-	(*UpdateRetentionPolicyCommand)(nil),     // 21: meta.UpdateRetentionPolicyCommand
-	(*CreateShardGroupCommand)(nil),          // 22: meta.CreateShardGroupCommand
-	(*DeleteShardGroupCommand)(nil),          // 23: meta.DeleteShardGroupCommand
-	(*CreateContinuousQueryCommand)(nil),     // 24: meta.CreateContinuousQueryCommand
-	(*DropContinuousQueryCommand)(nil),       // 25: meta.DropContinuousQueryCommand
-	(*CreateUserCommand)(nil),                // 26: meta.CreateUserCommand
-	(*DropUserCommand)(nil),                  // 27: meta.DropUserCommand
-	(*UpdateUserCommand)(nil),                // 28: meta.UpdateUserCommand
-	(*SetPrivilegeCommand)(nil),              // 29: meta.SetPrivilegeCommand
-	(*SetDataCommand)(nil),                   // 30: meta.SetDataCommand
-	(*SetAdminPrivilegeCommand)(nil),         // 31: meta.SetAdminPrivilegeCommand
-	(*UpdateNodeCommand)(nil),                // 32: meta.UpdateNodeCommand
-	(*CreateSubscriptionCommand)(nil),        // 33: meta.CreateSubscriptionCommand
-	(*DropSubscriptionCommand)(nil),          // 34: meta.DropSubscriptionCommand
-	(*RemovePeerCommand)(nil),                // 35: meta.RemovePeerCommand
-	(*CreateMetaNodeCommand)(nil),            // 36: meta.CreateMetaNodeCommand
-	(*CreateDataNodeCommand)(nil),            // 37: meta.CreateDataNodeCommand
-	(*UpdateDataNodeCommand)(nil),            // 38: meta.UpdateDataNodeCommand
-	(*DeleteMetaNodeCommand)(nil),            // 39: meta.DeleteMetaNodeCommand
-	(*DeleteDataNodeCommand)(nil),            // 40: meta.DeleteDataNodeCommand
-	(*Response)(nil),                         // 41: meta.Response
-	(*SetMetaNodeCommand)(nil),               // 42: meta.SetMetaNodeCommand
-	(*DropShardCommand)(nil),                 // 43: meta.DropShardCommand
+	(*MeasurementPrivilege)(nil),             // 13: meta.MeasurementPrivilege
+	(*RoleInfo)(nil),                         // 14: meta.RoleInfo
+	(*TokenInfo)(nil),                        // 15: meta.TokenInfo
+	(*QuotaInfo)(nil),                        // 16: meta.QuotaInfo
+	(*Command)(nil),                          // 17: meta.Command
+	(*CreateNodeCommand)(nil),                // 18: meta.CreateNodeCommand
+	(*DeleteNodeCommand)(nil),                // 19: meta.DeleteNodeCommand
+	(*CreateDatabaseCommand)(nil),            // 20: meta.CreateDatabaseCommand
+	(*DropDatabaseCommand)(nil),              // 21: meta.DropDatabaseCommand
+	(*CreateRetentionPolicyCommand)(nil),     // 22: meta.CreateRetentionPolicyCommand
+	(*DropRetentionPolicyCommand)(nil),       // 23: meta.DropRetentionPolicyCommand
+	(*SetDefaultRetentionPolicyCommand)(nil), // 24: meta.SetDefaultRetentionPolicyCommand
+	(*UpdateRetentionPolicyCommand)(nil),     // 25: meta.UpdateRetentionPolicyCommand
+	(*CreateShardGroupCommand)(nil),          // 26: meta.CreateShardGroupCommand
+	(*DeleteShardGroupCommand)(nil),          // 27: meta.DeleteShardGroupCommand
+	(*CreateContinuousQueryCommand)(nil),     // 28: meta.CreateContinuousQueryCommand
+	(*DropContinuousQueryCommand)(nil),       // 29: meta.DropContinuousQueryCommand
+	(*CreateUserCommand)(nil),                // 30: meta.CreateUserCommand
+	(*DropUserCommand)(nil),                  // 31: meta.DropUserCommand
+	(*UpdateUserCommand)(nil),                // 32: meta.UpdateUserCommand
+	(*SetPrivilegeCommand)(nil),              // 33: meta.SetPrivilegeCommand
+	(*SetDataCommand)(nil),                   // 34: meta.SetDataCommand
+	(*SetAdminPrivilegeCommand)(nil),         // 35: meta.SetAdminPrivilegeCommand
+	(*UpdateNodeCommand)(nil),                // 36: meta.UpdateNodeCommand
+	(*CreateSubscriptionCommand)(nil),        // 37: meta.CreateSubscriptionCommand
+	(*DropSubscriptionCommand)(nil),          // 38: meta.DropSubscriptionCommand
+	(*RemovePeerCommand)(nil),                // 39: meta.RemovePeerCommand
+	(*CreateMetaNodeCommand)(nil),            // 40: meta.CreateMetaNodeCommand
+	(*CreateDataNodeCommand)(nil),            // 41: meta.CreateDataNodeCommand
+	(*UpdateDataNodeCommand)(nil),            // 42: meta.UpdateDataNodeCommand
+	(*DeleteMetaNodeCommand)(nil),            // 43: meta.DeleteMetaNodeCommand
+	(*DeleteDataNodeCommand)(nil),            // 44: meta.DeleteDataNodeCommand
+	(*Response)(nil),                         // 45: meta.Response
+	(*SetMetaNodeCommand)(nil),               // 46: meta.SetMetaNodeCommand
+	(*DropShardCommand)(nil),                 // 47: meta.DropShardCommand
 }
 var file_internal_meta_proto_depIdxs = []int32{
 	2,  // 0: meta.Data.Nodes:type_name -> meta.NodeInfo
@@ -3626,80 +4077,85 @@ var file_internal_meta_proto_depIdxs = []int32{
 	11, // 2: meta.Data.Users:type_name -> meta.UserInfo
 	2,  // 3: meta.Data.DataNodes:type_name -> meta.NodeInfo
 	2,  // 4: meta.Data.MetaNodes:type_name -> meta.NodeInfo
-	5,  // 5: meta.DatabaseInfo.RetentionPolicies:type_name -> meta.RetentionPolicyInfo
-	10, // 6: meta.DatabaseInfo.ContinuousQueries:type_name -> meta.ContinuousQueryInfo
-	6,  // 7: meta.RetentionPolicyInfo.ShardGroups:type_name -> meta.ShardGroupInfo
-	8,  // 8: meta.RetentionPolicyInfo.Subscriptions:type_name -> meta.SubscriptionInfo
-	7,  // 9: meta.ShardGroupInfo.Shards:type_name -> meta.ShardInfo
-	9,  // 10: meta.ShardInfo.Owners:type_name -> meta.ShardOwner
-	12, // 11: meta.UserInfo.Privileges:type_name -> meta.UserPrivilege
-	0,  // 12: meta.Command.type:type_name -> meta.Command.Type
-	5,  // 13: meta.CreateDatabaseCommand.RetentionPolicy:type_name -> meta.RetentionPolicyInfo
-	5,  // 14: meta.CreateRetentionPolicyCommand.RetentionPolicy:type_name -> meta.RetentionPolicyInfo
-	1,  // 15: meta.SetDataCommand.Data:type_name -> meta.Data
-	13, // 16: meta.CreateNodeCommand.command:extendee -> meta.Command
-	13, // 17: meta.DeleteNodeCommand.command:extendee -> meta.Command
-	13, // 18: meta.CreateDatabaseCommand.command:extendee -> meta.Command
-	13, // 19: meta.DropDatabaseCommand.command:extendee -> meta.Command
-	13, // 20: meta.CreateRetentionPolicyCommand.command:extendee -> meta.Command
-	13, // 21: meta.DropRetentionPolicyCommand.command:extendee -> meta.Command
-	13, // 22: meta.SetDefaultRetentionPolicyCommand.command:extendee -> meta.Command
-	13, // 23: meta.UpdateRetentionPolicyCommand.command:extendee -> meta.Command
-	13, // 24: meta.CreateShardGroupCommand.command:extendee -> meta.Command
-	13, // 25: meta.DeleteShardGroupCommand.command:extendee -> meta.Command
-	13, // 26: meta.CreateContinuousQueryCommand.command:extendee -> meta.Command
-	13, // 27: meta.DropContinuousQueryCommand.command:extendee -> meta.Command
-	13, // 28: meta.CreateUserCommand.command:extendee -> meta.Command
-	13, // 29: meta.DropUserCommand.command:extendee -> meta.Command
-	13, // 30: meta.UpdateUserCommand.command:extendee -> meta.Command
-	13, // 31: meta.SetPrivilegeCommand.command:extendee -> meta.Command
-	13, // 32: meta.SetDataCommand.command:extendee -> meta.Command
-	13, // 33: meta.SetAdminPrivilegeCommand.command:extendee -> meta.Command
-	13, // 34: meta.UpdateNodeCommand.command:extendee -> meta.Command
-	13, // 35: meta.CreateSubscriptionCommand.command:extendee -> meta.Command
-	13, // 36: meta.DropSubscriptionCommand.command:extendee -> meta.Command
-	13, // 37: meta.RemovePeerCommand.command:extendee -> meta.Command
-	13, // 38: meta.CreateMetaNodeCommand.command:extendee -> meta.Command
-	13, // 39: meta.CreateDataNodeCommand.command:extendee -> meta.Command
-	13, // 40: meta.UpdateDataNodeCommand.command:extendee -> meta.Command
-	13, // 41: meta.DeleteMetaNodeCommand.command:extendee -> meta.Command
-	13, // 42: meta.DeleteDataNodeCommand.command:extendee -> meta.Command
-	13, // 43: meta.SetMetaNodeCommand.command:extendee -> meta.Command
-	13, // 44: meta.DropShardCommand.command:extendee -> meta.Command
-	14, // 45: meta.CreateNodeCommand.command:type_name -> meta.CreateNodeCommand
-	15, // 46: meta.DeleteNodeCommand.command:type_name -> meta.DeleteNodeCommand
-	16, // 47: meta.CreateDatabaseCommand.command:type_name -> meta.CreateDatabaseCommand
-	17, // 48: meta.DropDatabaseCommand.command:type_name -> meta.DropDatabaseCommand
-	18, // 49: meta.CreateRetentionPolicyCommand.command:type_name -> meta.CreateRetentionPolicyCommand
-	19, // 50: meta.DropRetentionPolicyCommand.command:type_name -> meta.DropRetentionPolicyCommand
-	20, // 51: meta.SetDefaultRetentionPolicyCommand.command:type_name -> meta.SetDefaultRetentionPolicyCommand
-	21, // 52: meta.UpdateRetentionPolicyCommand.command:type_name -> meta.UpdateRetentionPolicyCommand
-	22, // 53: meta.CreateShardGroupCommand.command:type_name -> meta.CreateShardGroupCommand
-	23, // 54: meta.DeleteShardGroupCommand.command:type_name -> meta.DeleteShardGroupCommand
-	24, // 55: meta.CreateContinuousQueryCommand.command:type_name -> meta.CreateContinuousQueryCommand
-	25, // 56: meta.DropContinuousQueryCommand.command:type_name -> meta.DropContinuousQueryCommand
-	26, // 57: meta.CreateUserCommand.command:type_name -> meta.CreateUserCommand
-	27, // 58: meta.DropUserCommand.command:type_name -> meta.DropUserCommand
-	28, // 59: meta.UpdateUserCommand.command:type_name -> meta.UpdateUserCommand
-	29, // 60: meta.SetPrivilegeCommand.command:type_name -> meta.SetPrivilegeCommand
-	30, // 61: meta.SetDataCommand.command:type_name -> meta.SetDataCommand
-	31, // 62: meta.SetAdminPrivilegeCommand.command:type_name -> meta.SetAdminPrivilegeCommand
-	32, // 63: meta.UpdateNodeCommand.command:type_name -> meta.UpdateNodeCommand
-	33, // 64: meta.CreateSubscriptionCommand.command:type_name -> meta.CreateSubscriptionCommand
-	34, // 65: meta.DropSubscriptionCommand.command:type_name -> meta.DropSubscriptionCommand
-	35, // 66: meta.RemovePeerCommand.command:type_name -> meta.RemovePeerCommand
-	36, // 67: meta.CreateMetaNodeCommand.command:type_name -> meta.CreateMetaNodeCommand
-	37, // 68: meta.CreateDataNodeCommand.command:type_name -> meta.CreateDataNodeCommand
-	38, // 69: meta.UpdateDataNodeCommand.command:type_name -> meta.UpdateDataNodeCommand
-	39, // 70: meta.DeleteMetaNodeCommand.command:type_name -> meta.DeleteMetaNodeCommand
-	40, // 71: meta.DeleteDataNodeCommand.command:type_name -> meta.DeleteDataNodeCommand
-	42, // 72: meta.SetMetaNodeCommand.command:type_name -> meta.SetMetaNodeCommand
-	43, // 73: meta.DropShardCommand.command:type_name -> meta.DropShardCommand
-	74, // [74:74] is the sub-list for method output_type
-	74, // [74:74] is the sub-list for method input_type
-	45, // [45:74] is the sub-list for extension type_name
-	16, // [16:45] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	14, // 5: meta.Data.Roles:type_name -> meta.RoleInfo
+	15, // 6: meta.Data.Tokens:type_name -> meta.TokenInfo
+	5,  // 7: meta.DatabaseInfo.RetentionPolicies:type_name -> meta.RetentionPolicyInfo
+	10, // 8: meta.DatabaseInfo.ContinuousQueries:type_name -> meta.ContinuousQueryInfo
+	16, // 9: meta.DatabaseInfo.Quota:type_name -> meta.QuotaInfo
+	6,  // 10: meta.RetentionPolicyInfo.ShardGroups:type_name -> meta.ShardGroupInfo
+	8,  // 11: meta.RetentionPolicyInfo.Subscriptions:type_name -> meta.SubscriptionInfo
+	7,  // 12: meta.ShardGroupInfo.Shards:type_name -> meta.ShardInfo
+	9,  // 13: meta.ShardInfo.Owners:type_name -> meta.ShardOwner
+	12, // 14: meta.UserInfo.Privileges:type_name -> meta.UserPrivilege
+	13, // 15: meta.UserInfo.MeasurementPrivileges:type_name -> meta.MeasurementPrivilege
+	12, // 16: meta.RoleInfo.Privileges:type_name -> meta.UserPrivilege
+	0,  // 17: meta.Command.type:type_name -> meta.Command.Type
+	5,  // 18: meta.CreateDatabaseCommand.RetentionPolicy:type_name -> meta.RetentionPolicyInfo
+	5,  // 19: meta.CreateRetentionPolicyCommand.RetentionPolicy:type_name -> meta.RetentionPolicyInfo
+	1,  // 20: meta.SetDataCommand.Data:type_name -> meta.Data
+	17, // 21: meta.CreateNodeCommand.command:extendee -> meta.Command
+	17, // 22: meta.DeleteNodeCommand.command:extendee -> meta.Command
+	17, // 23: meta.CreateDatabaseCommand.command:extendee -> meta.Command
+	17, // 24: meta.DropDatabaseCommand.command:extendee -> meta.Command
+	17, // 25: meta.CreateRetentionPolicyCommand.command:extendee -> meta.Command
+	17, // 26: meta.DropRetentionPolicyCommand.command:extendee -> meta.Command
+	17, // 27: meta.SetDefaultRetentionPolicyCommand.command:extendee -> meta.Command
+	17, // 28: meta.UpdateRetentionPolicyCommand.command:extendee -> meta.Command
+	17, // 29: meta.CreateShardGroupCommand.command:extendee -> meta.Command
+	17, // 30: meta.DeleteShardGroupCommand.command:extendee -> meta.Command
+	17, // 31: meta.CreateContinuousQueryCommand.command:extendee -> meta.Command
+	17, // 32: meta.DropContinuousQueryCommand.command:extendee -> meta.Command
+	17, // 33: meta.CreateUserCommand.command:extendee -> meta.Command
+	17, // 34: meta.DropUserCommand.command:extendee -> meta.Command
+	17, // 35: meta.UpdateUserCommand.command:extendee -> meta.Command
+	17, // 36: meta.SetPrivilegeCommand.command:extendee -> meta.Command
+	17, // 37: meta.SetDataCommand.command:extendee -> meta.Command
+	17, // 38: meta.SetAdminPrivilegeCommand.command:extendee -> meta.Command
+	17, // 39: meta.UpdateNodeCommand.command:extendee -> meta.Command
+	17, // 40: meta.CreateSubscriptionCommand.command:extendee -> meta.Command
+	17, // 41: meta.DropSubscriptionCommand.command:extendee -> meta.Command
+	17, // 42: meta.RemovePeerCommand.command:extendee -> meta.Command
+	17, // 43: meta.CreateMetaNodeCommand.command:extendee -> meta.Command
+	17, // 44: meta.CreateDataNodeCommand.command:extendee -> meta.Command
+	17, // 45: meta.UpdateDataNodeCommand.command:extendee -> meta.Command
+	17, // 46: meta.DeleteMetaNodeCommand.command:extendee -> meta.Command
+	17, // 47: meta.DeleteDataNodeCommand.command:extendee -> meta.Command
+	17, // 48: meta.SetMetaNodeCommand.command:extendee -> meta.Command
+	17, // 49: meta.DropShardCommand.command:extendee -> meta.Command
+	18, // 50: meta.CreateNodeCommand.command:type_name -> meta.CreateNodeCommand
+	19, // 51: meta.DeleteNodeCommand.command:type_name -> meta.DeleteNodeCommand
+	20, // 52: meta.CreateDatabaseCommand.command:type_name -> meta.CreateDatabaseCommand
+	21, // 53: meta.DropDatabaseCommand.command:type_name -> meta.DropDatabaseCommand
+	22, // 54: meta.CreateRetentionPolicyCommand.command:type_name -> meta.CreateRetentionPolicyCommand
+	23, // 55: meta.DropRetentionPolicyCommand.command:type_name -> meta.DropRetentionPolicyCommand
+	24, // 56: meta.SetDefaultRetentionPolicyCommand.command:type_name -> meta.SetDefaultRetentionPolicyCommand
+	25, // 57: meta.UpdateRetentionPolicyCommand.command:type_name -> meta.UpdateRetentionPolicyCommand
+	26, // 58: meta.CreateShardGroupCommand.command:type_name -> meta.CreateShardGroupCommand
+	27, // 59: meta.DeleteShardGroupCommand.command:type_name -> meta.DeleteShardGroupCommand
+	28, // 60: meta.CreateContinuousQueryCommand.command:type_name -> meta.CreateContinuousQueryCommand
+	29, // 61: meta.DropContinuousQueryCommand.command:type_name -> meta.DropContinuousQueryCommand
+	30, // 62: meta.CreateUserCommand.command:type_name -> meta.CreateUserCommand
+	31, // 63: meta.DropUserCommand.command:type_name -> meta.DropUserCommand
+	32, // 64: meta.UpdateUserCommand.command:type_name -> meta.UpdateUserCommand
+	33, // 65: meta.SetPrivilegeCommand.command:type_name -> meta.SetPrivilegeCommand
+	34, // 66: meta.SetDataCommand.command:type_name -> meta.SetDataCommand
+	35, // 67: meta.SetAdminPrivilegeCommand.command:type_name -> meta.SetAdminPrivilegeCommand
+	36, // 68: meta.UpdateNodeCommand.command:type_name -> meta.UpdateNodeCommand
+	37, // 69: meta.CreateSubscriptionCommand.command:type_name -> meta.CreateSubscriptionCommand
+	38, // 70: meta.DropSubscriptionCommand.command:type_name -> meta.DropSubscriptionCommand
+	39, // 71: meta.RemovePeerCommand.command:type_name -> meta.RemovePeerCommand
+	40, // 72: meta.CreateMetaNodeCommand.command:type_name -> meta.CreateMetaNodeCommand
+	41, // 73: meta.CreateDataNodeCommand.command:type_name -> meta.CreateDataNodeCommand
+	42, // 74: meta.UpdateDataNodeCommand.command:type_name -> meta.UpdateDataNodeCommand
+	43, // 75: meta.DeleteMetaNodeCommand.command:type_name -> meta.DeleteMetaNodeCommand
+	44, // 76: meta.DeleteDataNodeCommand.command:type_name -> meta.DeleteDataNodeCommand
+	46, // 77: meta.SetMetaNodeCommand.command:type_name -> meta.SetMetaNodeCommand
+	47, // 78: meta.DropShardCommand.command:type_name -> meta.DropShardCommand
+	79, // [79:79] is the sub-list for method output_type
+	79, // [79:79] is the sub-list for method input_type
+	50, // [50:79] is the sub-list for extension type_name
+	21, // [21:50] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_internal_meta_proto_init() }
@@ -3853,6 +4309,54 @@ func file_internal_meta_proto_init() {
 			}
 		}
 		file_internal_meta_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MeasurementPrivilege); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_meta_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoleInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_meta_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TokenInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_meta_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QuotaInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_meta_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Command); i {
 			case 0:
 				return &v.state
@@ -3866,7 +4370,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateNodeCommand); i {
 			case 0:
 				return &v.state
@@ -3878,7 +4382,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DeleteNodeCommand); i {
 			case 0:
 				return &v.state
@@ -3890,7 +4394,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateDatabaseCommand); i {
 			case 0:
 				return &v.state
@@ -3902,7 +4406,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DropDatabaseCommand); i {
 			case 0:
 				return &v.state
@@ -3914,7 +4418,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateRetentionPolicyCommand); i {
 			case 0:
 				return &v.state
@@ -3926,7 +4430,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DropRetentionPolicyCommand); i {
 			case 0:
 				return &v.state
@@ -3938,7 +4442,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SetDefaultRetentionPolicyCommand); i {
 			case 0:
 				return &v.state
@@ -3950,7 +4454,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*UpdateRetentionPolicyCommand); i {
 			case 0:
 				return &v.state
@@ -3962,7 +4466,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateShardGroupCommand); i {
 			case 0:
 				return &v.state
@@ -3974,7 +4478,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DeleteShardGroupCommand); i {
 			case 0:
 				return &v.state
@@ -3986,7 +4490,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateContinuousQueryCommand); i {
 			case 0:
 				return &v.state
@@ -3998,7 +4502,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DropContinuousQueryCommand); i {
 			case 0:
 				return &v.state
@@ -4010,7 +4514,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateUserCommand); i {
 			case 0:
 				return &v.state
@@ -4022,7 +4526,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DropUserCommand); i {
 			case 0:
 				return &v.state
@@ -4034,7 +4538,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*UpdateUserCommand); i {
 			case 0:
 				return &v.state
@@ -4046,7 +4550,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SetPrivilegeCommand); i {
 			case 0:
 				return &v.state
@@ -4058,7 +4562,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SetDataCommand); i {
 			case 0:
 				return &v.state
@@ -4070,7 +4574,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SetAdminPrivilegeCommand); i {
 			case 0:
 				return &v.state
@@ -4082,7 +4586,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*UpdateNodeCommand); i {
 			case 0:
 				return &v.state
@@ -4094,7 +4598,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateSubscriptionCommand); i {
 			case 0:
 				return &v.state
@@ -4106,7 +4610,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DropSubscriptionCommand); i {
 			case 0:
 				return &v.state
@@ -4118,7 +4622,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RemovePeerCommand); i {
 			case 0:
 				return &v.state
@@ -4130,7 +4634,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateMetaNodeCommand); i {
 			case 0:
 				return &v.state
@@ -4142,7 +4646,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateDataNodeCommand); i {
 			case 0:
 				return &v.state
@@ -4154,7 +4658,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*UpdateDataNodeCommand); i {
 			case 0:
 				return &v.state
@@ -4166,7 +4670,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DeleteMetaNodeCommand); i {
 			case 0:
 				return &v.state
@@ -4178,7 +4682,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DeleteDataNodeCommand); i {
 			case 0:
 				return &v.state
@@ -4190,7 +4694,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Response); i {
 			case 0:
 				return &v.state
@@ -4202,7 +4706,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[45].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SetMetaNodeCommand); i {
 			case 0:
 				return &v.state
@@ -4214,7 +4718,7 @@ func file_internal_meta_proto_init() {
 				return nil
 			}
 		}
-		file_internal_meta_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_meta_proto_msgTypes[46].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DropShardCommand); i {
 			case 0:
 				return &v.state
@@ -4233,7 +4737,7 @@ func file_internal_meta_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_internal_meta_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   43,
+			NumMessages:   47,
 			NumExtensions: 29,
 			NumServices:   0,
 		},
@@ -0,0 +1,278 @@
+package meta
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/ldap"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+)
+
+// ldapAuthenticator authenticates the users that are not stored in the meta
+// data against an LDAP directory.
+//
+// A successful authentication is cached for the configured TTL so the
+// directory is not queried for every request. The user is known to the
+// client while it is cached, which lets the authorizers look it up by name.
+type ldapAuthenticator struct {
+	config LDAPConfig
+	addr   string
+	tls    *tls.Config
+	groups []ldapGroup
+	logger *zap.Logger
+
+	// Idle connections to the directory.
+	pool chan *ldap.Conn
+
+	mu    sync.RWMutex
+	cache map[string]*ldapUser
+
+	// Hashes the passwords of the cached authentications.
+	saltedHash   func(password string) (salt, hash []byte, err error)
+	hashWithSalt func(salt []byte, password string) []byte
+
+	now func() time.Time
+}
+
+// ldapUser is a cached authentication.
+type ldapUser struct {
+	user    *UserInfo
+	salt    []byte
+	hash    []byte
+	expires time.Time
+}
+
+type ldapGroup struct {
+	dn         string
+	admin      bool
+	privileges map[string]influxql.Privilege
+}
+
+// newLDAPAuthenticator returns an authenticator for the directory. The
+// configuration must be valid.
+func newLDAPAuthenticator(c LDAPConfig, saltedHash func(string) ([]byte, []byte, error), hashWithSalt func([]byte, string) []byte) *ldapAuthenticator {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		u = &url.URL{}
+	}
+	a := &ldapAuthenticator{
+		config:       c,
+		addr:         u.Host,
+		logger:       zap.NewNop(),
+		pool:         make(chan *ldap.Conn, c.PoolSize),
+		cache:        make(map[string]*ldapUser),
+		saltedHash:   saltedHash,
+		hashWithSalt: hashWithSalt,
+		now:          time.Now,
+	}
+	if u.Port() == "" {
+		port := "389"
+		if u.Scheme == "ldaps" {
+			port = "636"
+		}
+		a.addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	if u.Scheme == "ldaps" || c.StartTLS {
+		a.tls = &tls.Config{
+			ServerName:         u.Hostname(),
+			InsecureSkipVerify: c.InsecureSkipVerify,
+		}
+	}
+
+	for _, g := range c.Groups {
+		group := ldapGroup{dn: g.DN, admin: g.Admin, privileges: make(map[string]influxql.Privilege)}
+		for db, s := range g.Privileges {
+			p, _ := parseLDAPPrivilege(s)
+			group.privileges[db] = p
+		}
+		a.groups = append(a.groups, group)
+	}
+	return a
+}
+
+// authenticate returns the user if the password is the password of its
+// entry in the directory.
+func (a *ldapAuthenticator) authenticate(username, password string) (*UserInfo, error) {
+	if username == "" || password == "" {
+		return nil, ErrAuthenticate
+	}
+
+	// Check the cache first.
+	a.mu.RLock()
+	lu, ok := a.cache[username]
+	a.mu.RUnlock()
+	if ok && a.now().Before(lu.expires) && bytes.Equal(a.hashWithSalt(lu.salt, password), lu.hash) {
+		return lu.user, nil
+	}
+
+	user, err := a.lookup(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, hash, err := a.saltedHash(password)
+	if err != nil {
+		return nil, err
+	}
+	if ttl := time.Duration(a.config.CacheTTL); ttl > 0 {
+		a.mu.Lock()
+		a.cache[username] = &ldapUser{user: user, salt: salt, hash: hash, expires: a.now().Add(ttl)}
+		a.mu.Unlock()
+	}
+	return user, nil
+}
+
+// user returns the user if its authentication is cached.
+func (a *ldapAuthenticator) user(username string) *UserInfo {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if lu, ok := a.cache[username]; ok && a.now().Before(lu.expires) {
+		return lu.user
+	}
+	return nil
+}
+
+// forget removes the cached authentication of the user.
+func (a *ldapAuthenticator) forget(username string) {
+	a.mu.Lock()
+	delete(a.cache, username)
+	a.mu.Unlock()
+}
+
+// lookup finds the entry of the user in the directory and binds with it to
+// verify the password.
+func (a *ldapAuthenticator) lookup(username, password string) (*UserInfo, error) {
+	conn, err := a.conn()
+	if err != nil {
+		a.logger.Info("Failed to connect to LDAP server", zap.String("url", a.config.URL), zap.Error(err))
+		return nil, ErrAuthenticate
+	}
+
+	user, err := a.lookupWithConn(conn, username, password)
+	if err != nil {
+		// The connection can be reused after an unsuccessful operation but
+		// its state is unknown after any other error.
+		var lerr *ldap.Error
+		switch {
+		case err == ErrUserNotFound || err == ErrAuthenticate:
+			a.release(conn)
+			return nil, err
+		case errors.As(err, &lerr):
+			a.release(conn)
+		default:
+			conn.Close()
+		}
+		if !ldap.IsInvalidCredentials(err) {
+			a.logger.Info("Failed to authenticate with LDAP server", zap.String("user", username), zap.Error(err))
+		}
+		return nil, ErrAuthenticate
+	}
+	a.release(conn)
+	return user, nil
+}
+
+func (a *ldapAuthenticator) lookupWithConn(conn *ldap.Conn, username, password string) (*UserInfo, error) {
+	// Bind with the service account to search for the user. The connection
+	// may still be bound as the previous user otherwise.
+	if a.config.BindDN != "" {
+		if err := conn.Bind(a.config.BindDN, a.config.BindPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     a.config.SearchBaseDN,
+		Filter:     fmt.Sprintf(a.config.SearchFilter, ldap.EscapeFilter(username)),
+		Attributes: []string{a.config.GroupAttribute},
+		SizeLimit:  2,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(entries) == 0 {
+		return nil, ErrUserNotFound
+	} else if len(entries) > 1 {
+		// A user name matching several entries is ambiguous.
+		return nil, ErrAuthenticate
+	}
+	entry := entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		return nil, err
+	}
+	return a.newUser(username, entry.Attribute(a.config.GroupAttribute)), nil
+}
+
+// newUser returns a user with the privileges of the groups it is a member of.
+func (a *ldapAuthenticator) newUser(username string, groups []string) *UserInfo {
+	user := &UserInfo{Name: username, Privileges: make(map[string]influxql.Privilege)}
+	for _, g := range a.groups {
+		member := false
+		for _, dn := range groups {
+			if strings.EqualFold(dn, g.dn) {
+				member = true
+				break
+			}
+		}
+		if !member {
+			continue
+		}
+
+		user.Admin = user.Admin || g.admin
+		for db, p := range g.privileges {
+			user.Privileges[db] |= p
+		}
+	}
+	return user
+}
+
+// conn returns an idle connection or a new connection to the directory.
+func (a *ldapAuthenticator) conn() (*ldap.Conn, error) {
+	select {
+	case conn := <-a.pool:
+		return conn, nil
+	default:
+	}
+
+	timeout := time.Duration(a.config.Timeout)
+	if a.config.StartTLS {
+		conn, err := ldap.Dial(a.addr, nil, timeout)
+		if err != nil {
+			return nil, err
+		} else if err := conn.StartTLS(a.tls); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+	return ldap.Dial(a.addr, a.tls, timeout)
+}
+
+// release returns the connection to the pool or closes it if the pool is
+// full.
+func (a *ldapAuthenticator) release(conn *ldap.Conn) {
+	select {
+	case a.pool <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// close closes the idle connections.
+func (a *ldapAuthenticator) close() {
+	for {
+		select {
+		case conn := <-a.pool:
+			conn.Close()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package meta
+
+import (
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// argon2SaltBytes is the number of bytes of the salts of argon2id hashes.
+	argon2SaltBytes = 16
+
+	// argon2KeyBytes is the number of bytes of argon2id hashes.
+	argon2KeyBytes = 32
+
+	argon2idPrefix = "$argon2id$"
+)
+
+// passwordHasher hashes the passwords of users with the configured
+// algorithm and enforces the password policy.
+type passwordHasher struct {
+	config PasswordConfig
+}
+
+func newPasswordHasher(c PasswordConfig) *passwordHasher {
+	if c.Hash == "" {
+		c.Hash = DefaultPasswordHash
+	}
+	return &passwordHasher{config: c}
+}
+
+// hash returns the hash of the password.
+func (h *passwordHasher) hash(password string) (string, error) {
+	switch h.config.Hash {
+	case PasswordHashArgon2id:
+		salt := make([]byte, argon2SaltBytes)
+		if _, err := io.ReadFull(crand.Reader, salt); err != nil {
+			return "", err
+		}
+		p := h.argon2Params()
+		p.salt = salt
+		p.key = argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, argon2KeyBytes)
+		return p.String(), nil
+	default:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost())
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+}
+
+// compare returns ErrAuthenticate if the password does not match the hash.
+// The hash can be a hash of either algorithm.
+func (h *passwordHasher) compare(hash, password string) error {
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			return ErrAuthenticate
+		}
+		return nil
+	}
+
+	p, err := parseArgon2Hash(hash)
+	if err != nil {
+		return ErrAuthenticate
+	}
+	key := argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, uint32(len(p.key)))
+	if subtle.ConstantTimeCompare(key, p.key) != 1 {
+		return ErrAuthenticate
+	}
+	return nil
+}
+
+// needsRehash returns true if the hash was not computed with the configured
+// algorithm and parameters.
+func (h *passwordHasher) needsRehash(hash string) bool {
+	switch h.config.Hash {
+	case PasswordHashArgon2id:
+		p, err := parseArgon2Hash(hash)
+		if err != nil {
+			return true
+		}
+		want := h.argon2Params()
+		return p.time != want.time || p.memory != want.memory || p.threads != want.threads || len(p.key) != argon2KeyBytes
+	default:
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.bcryptCost()
+	}
+}
+
+// checkPolicy returns an error if the password does not satisfy the policy.
+func (h *passwordHasher) checkPolicy(password string) error {
+	if n := h.config.MinLength; len([]rune(password)) < n {
+		return fmt.Errorf("password must be at least %d characters long", n)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		default:
+		}
+	}
+	if h.config.RequireUppercase && !upper {
+		return errors.New("password must contain an uppercase letter")
+	} else if h.config.RequireLowercase && !lower {
+		return errors.New("password must contain a lowercase letter")
+	} else if h.config.RequireDigit && !digit {
+		return errors.New("password must contain a digit")
+	} else if h.config.RequireSymbol && !symbol {
+		return errors.New("password must contain a symbol")
+	}
+	return nil
+}
+
+// expired returns true if the password set at changedAt must be changed at
+// now. Passwords set before the time was recorded don't expire.
+func (h *passwordHasher) expired(changedAt, now time.Time) bool {
+	expiry := time.Duration(h.config.Expiry)
+	return expiry > 0 && !changedAt.IsZero() && !now.Before(changedAt.Add(expiry))
+}
+
+func (h *passwordHasher) bcryptCost() int {
+	if h.config.BcryptCost != 0 {
+		return h.config.BcryptCost
+	}
+	return bcryptCost
+}
+
+func (h *passwordHasher) argon2Params() argon2Hash {
+	return argon2Hash{
+		time:    uint32(h.config.Argon2Time),
+		memory:  uint32(h.config.Argon2Memory / 1024),
+		threads: uint8(h.config.Argon2Threads),
+	}
+}
+
+// argon2Hash is an argon2id hash with its parameters.
+type argon2Hash struct {
+	time    uint32
+	memory  uint32 // in KiB
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// String returns the hash in the PHC string format:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+func (p argon2Hash) String() string {
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(p.salt),
+		base64.RawStdEncoding.EncodeToString(p.key),
+	)
+}
+
+// parseArgon2Hash parses an argon2id hash in the PHC string format.
+func parseArgon2Hash(s string) (argon2Hash, error) {
+	var p argon2Hash
+	parts := strings.Split(s, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, fmt.Errorf("invalid argon2id hash version: %s", err)
+	} else if version != argon2.Version {
+		return p, fmt.Errorf("unsupported argon2id hash version %d", version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, fmt.Errorf("invalid argon2id hash parameters: %s", err)
+	}
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, fmt.Errorf("invalid argon2id hash salt: %s", err)
+	} else if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return p, fmt.Errorf("invalid argon2id hash key: %s", err)
+	} else if len(p.key) == 0 {
+		return p, errors.New("invalid argon2id hash key")
+	}
+	return p, nil
+}
@@ -59,6 +59,10 @@ func (a *QueryAuthorizer) AuthorizeQuery(u User, q *influxql.Query, database str
 			return query.OpenAuthorizer, nil
 		}
 
+		// The statements reading the measurements a user has been granted
+		// privileges on are filtered by the user's fine authorizations.
+		fine := false
+
 		// Check each statement in the query.
 		for _, stmt := range q.Statements {
 			// Get the privileges required to execute the statement.
@@ -89,6 +93,10 @@ func (a *QueryAuthorizer) AuthorizeQuery(u User, q *influxql.Query, database str
 					db = database
 				}
 				if !user.AuthorizeDatabase(p.Privilege, db) {
+					if p.Privilege == influxql.ReadPrivilege && readsMeasurements(stmt) && user.AuthorizeMeasurements(p.Privilege, db) {
+						fine = true
+						continue
+					}
 					return nil, &ErrAuthorize{
 						Query:    q,
 						User:     user.Name,
@@ -98,6 +106,9 @@ func (a *QueryAuthorizer) AuthorizeQuery(u User, q *influxql.Query, database str
 				}
 			}
 		}
+		if fine {
+			return user, nil
+		}
 		return query.OpenAuthorizer, nil
 	default:
 	}
@@ -109,6 +120,22 @@ func (a *QueryAuthorizer) AuthorizeQuery(u User, q *influxql.Query, database str
 	}
 }
 
+// readsMeasurements returns true if the statement only reads series that are
+// filtered by the FineAuthorizer of the query.
+func readsMeasurements(stmt influxql.Statement) bool {
+	switch stmt.(type) {
+	case *influxql.SelectStatement,
+		*influxql.ShowMeasurementsStatement,
+		*influxql.ShowSeriesStatement,
+		*influxql.ShowTagKeysStatement,
+		*influxql.ShowTagValuesStatement,
+		*influxql.ShowFieldKeysStatement:
+		return true
+	default:
+		return false
+	}
+}
+
 func (a *QueryAuthorizer) AuthorizeDatabase(u User, priv influxql.Privilege, database string) error {
 	if u == nil {
 		return &ErrAuthorize{
@@ -221,3 +248,9 @@ func (e ErrAuthorize) Error() string {
 	}
 	return fmt.Sprintf("%s not authorized to execute %s", e.User, e.Message)
 }
+
+// AuthorizationFailed returns true to indicate the error is an authorization
+// failure.
+func (e ErrAuthorize) AuthorizationFailed() bool {
+	return true
+}
@@ -0,0 +1,458 @@
+package meta
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxql"
+)
+
+// Schema is a declarative description of the databases, retention policies,
+// continuous queries, subscriptions and users of the meta data.
+//
+// The measurement privileges, roles and tokens are not part of the schema
+// and are left untouched when a schema is applied.
+type Schema struct {
+	Databases []SchemaDatabase `json:"databases"`
+	Users     []SchemaUser     `json:"users"`
+}
+
+// SchemaDatabase describes a database.
+type SchemaDatabase struct {
+	Name                   string                  `json:"name"`
+	DefaultRetentionPolicy string                  `json:"default_retention_policy,omitempty"`
+	RetentionPolicies      []SchemaRetentionPolicy `json:"retention_policies,omitempty"`
+	ContinuousQueries      []SchemaContinuousQuery `json:"continuous_queries,omitempty"`
+}
+
+// SchemaRetentionPolicy describes a retention policy. A zero duration keeps
+// the data forever. A zero shard group duration or replication factor is
+// the default of the server and matches any value when compared.
+type SchemaRetentionPolicy struct {
+	Name               string               `json:"name"`
+	Duration           toml.Duration        `json:"duration"`
+	ShardGroupDuration toml.Duration        `json:"shard_group_duration,omitempty"`
+	ReplicaN           int                  `json:"replication,omitempty"`
+	Subscriptions      []SchemaSubscription `json:"subscriptions,omitempty"`
+}
+
+// SchemaSubscription describes a subscription.
+type SchemaSubscription struct {
+	Name         string   `json:"name"`
+	Mode         string   `json:"mode"`
+	Destinations []string `json:"destinations"`
+}
+
+// SchemaContinuousQuery describes a continuous query with its CREATE
+// CONTINUOUS QUERY statement.
+type SchemaContinuousQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// SchemaUser describes a user and its privileges on databases. The password
+// is never exported and is only required to create the user.
+type SchemaUser struct {
+	Name       string            `json:"name"`
+	Password   string            `json:"password,omitempty"`
+	Admin      bool              `json:"admin,omitempty"`
+	Privileges map[string]string `json:"privileges,omitempty"`
+}
+
+// Schema returns the schema of the meta data. The _internal database is
+// managed by the monitor and is not part of the schema.
+func (data *Data) Schema() *Schema {
+	s := &Schema{
+		Databases: []SchemaDatabase{},
+		Users:     []SchemaUser{},
+	}
+	for _, di := range data.Databases {
+		if di.Name == "_internal" {
+			continue
+		}
+		db := SchemaDatabase{
+			Name:                   di.Name,
+			DefaultRetentionPolicy: di.DefaultRetentionPolicy,
+		}
+		for _, rpi := range di.RetentionPolicies {
+			rp := SchemaRetentionPolicy{
+				Name:               rpi.Name,
+				Duration:           toml.Duration(rpi.Duration),
+				ShardGroupDuration: toml.Duration(rpi.ShardGroupDuration),
+				ReplicaN:           rpi.ReplicaN,
+			}
+			for _, si := range rpi.Subscriptions {
+				rp.Subscriptions = append(rp.Subscriptions, SchemaSubscription{
+					Name:         si.Name,
+					Mode:         si.Mode,
+					Destinations: append([]string(nil), si.Destinations...),
+				})
+			}
+			db.RetentionPolicies = append(db.RetentionPolicies, rp)
+		}
+		for _, cqi := range di.ContinuousQueries {
+			db.ContinuousQueries = append(db.ContinuousQueries, SchemaContinuousQuery{Name: cqi.Name, Query: cqi.Query})
+		}
+		s.Databases = append(s.Databases, db)
+	}
+
+	for _, ui := range data.Users {
+		u := SchemaUser{Name: ui.Name, Admin: ui.Admin}
+		for db, p := range ui.Privileges {
+			if p == influxql.NoPrivileges {
+				continue
+			}
+			if u.Privileges == nil {
+				u.Privileges = make(map[string]string)
+			}
+			u.Privileges[db] = p.String()
+		}
+		s.Users = append(s.Users, u)
+	}
+	return s
+}
+
+// schemaStatements returns the statements that change the meta data to match
+// the schema. The databases, retention policies, continuous queries and
+// subscriptions that are not in the schema are dropped if prune is true, and
+// kept otherwise. The privileges of a user in the schema always match the
+// schema exactly.
+//
+// checkPassword reports whether the password is the password of the user.
+func (data *Data) schemaStatements(s *Schema, prune bool, checkPassword func(u *UserInfo, password string) bool) ([]influxql.Statement, error) {
+	var stmts []influxql.Statement
+
+	databases := make(map[string]bool)
+	for _, db := range s.Databases {
+		if !ValidName(db.Name) {
+			return nil, fmt.Errorf("database %q: %s", db.Name, ErrInvalidName)
+		} else if databases[db.Name] {
+			return nil, fmt.Errorf("database %q: %s", db.Name, ErrDatabaseExists)
+		}
+		databases[db.Name] = true
+
+		dbStmts, err := data.databaseStatements(&db, prune)
+		if err != nil {
+			return nil, fmt.Errorf("database %q: %s", db.Name, err)
+		}
+		stmts = append(stmts, dbStmts...)
+	}
+	if prune {
+		for _, di := range data.Databases {
+			if !databases[di.Name] && di.Name != "_internal" {
+				stmts = append(stmts, &influxql.DropDatabaseStatement{Name: di.Name})
+			}
+		}
+	}
+
+	users := make(map[string]bool)
+	for _, u := range s.Users {
+		if u.Name == "" {
+			return nil, fmt.Errorf("user %q: %s", u.Name, ErrUsernameRequired)
+		} else if users[u.Name] {
+			return nil, fmt.Errorf("user %q: %s", u.Name, ErrUserExists)
+		}
+		users[u.Name] = true
+
+		userStmts, err := data.userStatements(&u, checkPassword)
+		if err != nil {
+			return nil, fmt.Errorf("user %q: %s", u.Name, err)
+		}
+		stmts = append(stmts, userStmts...)
+	}
+	if prune {
+		for _, ui := range data.Users {
+			if !users[ui.Name] {
+				stmts = append(stmts, &influxql.DropUserStatement{Name: ui.Name})
+			}
+		}
+	}
+	return stmts, nil
+}
+
+func (data *Data) databaseStatements(db *SchemaDatabase, prune bool) ([]influxql.Statement, error) {
+	var stmts []influxql.Statement
+
+	// Validate the retention policies first so a database is not created
+	// with an invalid default retention policy.
+	policies := make(map[string]*SchemaRetentionPolicy, len(db.RetentionPolicies))
+	for i := range db.RetentionPolicies {
+		rp := &db.RetentionPolicies[i]
+		if !ValidName(rp.Name) {
+			return nil, fmt.Errorf("retention policy %q: %s", rp.Name, ErrInvalidName)
+		} else if policies[rp.Name] != nil {
+			return nil, fmt.Errorf("retention policy %q: %s", rp.Name, ErrRetentionPolicyExists)
+		} else if rp.ReplicaN < 0 {
+			return nil, fmt.Errorf("retention policy %q: %s", rp.Name, ErrReplicationFactorTooLow)
+		}
+		policies[rp.Name] = rp
+	}
+	if db.DefaultRetentionPolicy != "" && policies[db.DefaultRetentionPolicy] == nil {
+		return nil, fmt.Errorf("default retention policy %q: %s", db.DefaultRetentionPolicy, ErrRetentionPolicyNotFound)
+	}
+
+	// A new database is created with its default retention policy.
+	di := data.Database(db.Name)
+	created := di == nil
+	if created {
+		di = &DatabaseInfo{Name: db.Name}
+		stmt := &influxql.CreateDatabaseStatement{Name: db.Name}
+		if rp := policies[db.DefaultRetentionPolicy]; rp != nil {
+			duration, replicaN := time.Duration(rp.Duration), schemaReplicaN(rp)
+			stmt.RetentionPolicyCreate = true
+			stmt.RetentionPolicyName = rp.Name
+			stmt.RetentionPolicyDuration = &duration
+			stmt.RetentionPolicyReplication = &replicaN
+			stmt.RetentionPolicyShardGroupDuration = time.Duration(rp.ShardGroupDuration)
+		}
+		stmts = append(stmts, stmt)
+	}
+
+	for _, rp := range db.RetentionPolicies {
+		rpi := di.RetentionPolicy(rp.Name)
+		isDefault := rp.Name == db.DefaultRetentionPolicy
+		if rpi == nil && isDefault && created {
+			rpi = &RetentionPolicyInfo{Name: rp.Name}
+		} else if rpi == nil {
+			stmts = append(stmts, &influxql.CreateRetentionPolicyStatement{
+				Name:               rp.Name,
+				Database:           db.Name,
+				Duration:           time.Duration(rp.Duration),
+				Replication:        schemaReplicaN(&rp),
+				Default:            isDefault,
+				ShardGroupDuration: time.Duration(rp.ShardGroupDuration),
+			})
+			rpi = &RetentionPolicyInfo{Name: rp.Name}
+		} else {
+			stmt := &influxql.AlterRetentionPolicyStatement{Name: rp.Name, Database: db.Name}
+			changed := false
+			if d := time.Duration(rp.Duration); d != rpi.Duration {
+				stmt.Duration, changed = &d, true
+			}
+			if replicaN := rp.ReplicaN; replicaN != 0 && replicaN != rpi.ReplicaN {
+				stmt.Replication, changed = &replicaN, true
+			}
+			if d := time.Duration(rp.ShardGroupDuration); d != 0 && d != rpi.ShardGroupDuration {
+				stmt.ShardGroupDuration, changed = &d, true
+			}
+			if isDefault && di.DefaultRetentionPolicy != rp.Name {
+				stmt.Default, changed = true, true
+			}
+			if changed {
+				stmts = append(stmts, stmt)
+			}
+		}
+
+		subStmts, err := subscriptionStatements(db.Name, &rp, rpi, prune)
+		if err != nil {
+			return nil, fmt.Errorf("retention policy %q: %s", rp.Name, err)
+		}
+		stmts = append(stmts, subStmts...)
+	}
+
+	cqStmts, err := continuousQueryStatements(db, di, prune)
+	if err != nil {
+		return nil, err
+	}
+	stmts = append(stmts, cqStmts...)
+
+	if prune {
+		for _, rpi := range di.RetentionPolicies {
+			if policies[rpi.Name] == nil {
+				stmts = append(stmts, &influxql.DropRetentionPolicyStatement{Name: rpi.Name, Database: db.Name})
+			}
+		}
+	}
+	return stmts, nil
+}
+
+func subscriptionStatements(database string, rp *SchemaRetentionPolicy, rpi *RetentionPolicyInfo, prune bool) ([]influxql.Statement, error) {
+	var stmts []influxql.Statement
+
+	subscriptions := make(map[string]bool, len(rp.Subscriptions))
+	for _, sub := range rp.Subscriptions {
+		if sub.Name == "" {
+			return nil, fmt.Errorf("subscription %q: %s", sub.Name, ErrInvalidName)
+		} else if subscriptions[sub.Name] {
+			return nil, fmt.Errorf("subscription %q: %s", sub.Name, ErrSubscriptionExists)
+		} else if sub.Mode != "ALL" && sub.Mode != "ANY" {
+			return nil, fmt.Errorf("subscription %q: invalid mode %q, must be ALL or ANY", sub.Name, sub.Mode)
+		} else if len(sub.Destinations) == 0 {
+			return nil, fmt.Errorf("subscription %q: at least one destination is required", sub.Name)
+		}
+		subscriptions[sub.Name] = true
+
+		create := &influxql.CreateSubscriptionStatement{
+			Name:            sub.Name,
+			Database:        database,
+			RetentionPolicy: rp.Name,
+			Destinations:    sub.Destinations,
+			Mode:            sub.Mode,
+		}
+
+		var si *SubscriptionInfo
+		for i := range rpi.Subscriptions {
+			if rpi.Subscriptions[i].Name == sub.Name {
+				si = &rpi.Subscriptions[i]
+				break
+			}
+		}
+		if si == nil {
+			stmts = append(stmts, create)
+		} else if si.Mode != sub.Mode || !stringsEqual(si.Destinations, sub.Destinations) {
+			// A subscription can't be altered so it is replaced.
+			stmts = append(stmts,
+				&influxql.DropSubscriptionStatement{Name: sub.Name, Database: database, RetentionPolicy: rp.Name},
+				create,
+			)
+		}
+	}
+
+	if prune {
+		for _, si := range rpi.Subscriptions {
+			if !subscriptions[si.Name] {
+				stmts = append(stmts, &influxql.DropSubscriptionStatement{Name: si.Name, Database: database, RetentionPolicy: rp.Name})
+			}
+		}
+	}
+	return stmts, nil
+}
+
+func continuousQueryStatements(db *SchemaDatabase, di *DatabaseInfo, prune bool) ([]influxql.Statement, error) {
+	var stmts []influxql.Statement
+
+	queries := make(map[string]bool, len(db.ContinuousQueries))
+	for _, cq := range db.ContinuousQueries {
+		if queries[cq.Name] {
+			return nil, fmt.Errorf("continuous query %q: %s", cq.Name, ErrContinuousQueryExists)
+		}
+		queries[cq.Name] = true
+
+		stmt, err := influxql.ParseStatement(cq.Query)
+		if err != nil {
+			return nil, fmt.Errorf("continuous query %q: %s", cq.Name, err)
+		}
+		create, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+		if !ok {
+			return nil, fmt.Errorf("continuous query %q: query must be a CREATE CONTINUOUS QUERY statement", cq.Name)
+		} else if create.Name != cq.Name || create.Database != db.Name {
+			return nil, fmt.Errorf("continuous query %q: query must create the continuous query on database %q", cq.Name, db.Name)
+		}
+
+		var cqi *ContinuousQueryInfo
+		for i := range di.ContinuousQueries {
+			if di.ContinuousQueries[i].Name == cq.Name {
+				cqi = &di.ContinuousQueries[i]
+				break
+			}
+		}
+		if cqi == nil {
+			stmts = append(stmts, create)
+		} else if !sameContinuousQuery(cqi.Query, create) {
+			// A continuous query can't be altered so it is replaced.
+			stmts = append(stmts,
+				&influxql.DropContinuousQueryStatement{Name: cq.Name, Database: db.Name},
+				create,
+			)
+		}
+	}
+
+	if prune {
+		for _, cqi := range di.ContinuousQueries {
+			if !queries[cqi.Name] {
+				stmts = append(stmts, &influxql.DropContinuousQueryStatement{Name: cqi.Name, Database: db.Name})
+			}
+		}
+	}
+	return stmts, nil
+}
+
+func (data *Data) userStatements(u *SchemaUser, checkPassword func(u *UserInfo, password string) bool) ([]influxql.Statement, error) {
+	var stmts []influxql.Statement
+
+	privileges := make(map[string]influxql.Privilege, len(u.Privileges))
+	for db, s := range u.Privileges {
+		p, err := parseLDAPPrivilege(s)
+		if err != nil {
+			return nil, fmt.Errorf("database %q: %s", db, err)
+		}
+		privileges[db] = p
+	}
+
+	var current map[string]influxql.Privilege
+	if ui := data.user(u.Name); ui == nil {
+		if u.Password == "" {
+			return nil, fmt.Errorf("password is required to create the user")
+		}
+		stmts = append(stmts, &influxql.CreateUserStatement{Name: u.Name, Password: u.Password, Admin: u.Admin})
+	} else {
+		if u.Password != "" && !checkPassword(ui, u.Password) {
+			stmts = append(stmts, &influxql.SetPasswordUserStatement{Name: u.Name, Password: u.Password})
+		}
+		if u.Admin && !ui.Admin {
+			stmts = append(stmts, &influxql.GrantAdminStatement{User: u.Name})
+		} else if !u.Admin && ui.Admin {
+			stmts = append(stmts, &influxql.RevokeAdminStatement{User: u.Name})
+		}
+		current = ui.Privileges
+	}
+
+	// The privileges are granted and revoked in the order of the databases
+	// so the statements are the same every time.
+	for _, db := range sortedPrivilegeDatabases(privileges, current) {
+		p, ok := privileges[db]
+		if ok && p != current[db] {
+			stmts = append(stmts, &influxql.GrantStatement{Privilege: p, On: db, User: u.Name})
+		} else if !ok && current[db] != influxql.NoPrivileges {
+			stmts = append(stmts, &influxql.RevokeStatement{Privilege: influxql.AllPrivileges, On: db, User: u.Name})
+		}
+	}
+	return stmts, nil
+}
+
+// schemaReplicaN returns the replication factor of the retention policy or
+// the default replication factor if it is not set.
+func schemaReplicaN(rp *SchemaRetentionPolicy) int {
+	if rp.ReplicaN == 0 {
+		return DefaultRetentionPolicyReplicaN
+	}
+	return rp.ReplicaN
+}
+
+// sameContinuousQuery returns true if the stored query is the same
+// continuous query as stmt.
+func sameContinuousQuery(query string, stmt *influxql.CreateContinuousQueryStatement) bool {
+	if query == stmt.String() {
+		return true
+	}
+	other, err := influxql.ParseStatement(query)
+	return err == nil && other.String() == stmt.String()
+}
+
+func sortedPrivilegeDatabases(a, b map[string]influxql.Privilege) []string {
+	names := make([]string, 0, len(a)+len(b))
+	for db := range a {
+		names = append(names, db)
+	}
+	for db := range b {
+		if _, ok := a[db]; !ok {
+			names = append(names, db)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
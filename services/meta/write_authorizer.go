@@ -18,6 +18,17 @@ func NewWriteAuthorizer(c *Client) *WriteAuthorizer {
 
 // AuthorizeWrite returns nil if the user has permission to write to the database.
 func (a WriteAuthorizer) AuthorizeWrite(username, database string) error {
+	return a.authorize(username, database, (*UserInfo).AuthorizeDatabase)
+}
+
+// AuthorizeWritePoints returns nil if the user has permission to write to the
+// database or to some of its measurements. The points that are written must
+// still be authorized one by one with the FineAuthorizer of the user.
+func (a WriteAuthorizer) AuthorizeWritePoints(username, database string) error {
+	return a.authorize(username, database, (*UserInfo).AuthorizeMeasurements)
+}
+
+func (a WriteAuthorizer) authorize(username, database string, fn func(*UserInfo, influxql.Privilege, string) bool) error {
 	u, err := a.Client.User(username)
 	if err != nil || u == nil {
 		return &ErrAuthorize{
@@ -30,7 +41,7 @@ func (a WriteAuthorizer) AuthorizeWrite(username, database string) error {
 	// Enterprise UserInfo in closed-source code.
 	switch user := u.(type) {
 	case *UserInfo:
-		if !user.AuthorizeDatabase(influxql.WritePrivilege, database) {
+		if !fn(user, influxql.WritePrivilege, database) {
 			return &ErrAuthorize{
 				Database: database,
 				Message:  fmt.Sprintf("%s not authorized to write to %s", username, database),
@@ -0,0 +1,107 @@
+package metamirror
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultRetryInterval is the time to wait before mirroring the meta data
+	// to a destination again after a failure.
+	DefaultRetryInterval = 5 * time.Second
+
+	// DefaultSyncInterval is the interval at which the meta data is mirrored
+	// even if it did not change.
+	DefaultSyncInterval = 10 * time.Minute
+
+	// DefaultTimeout is the timeout of mirroring the meta data to a
+	// destination.
+	DefaultTimeout = 30 * time.Second
+)
+
+// Config represents the configuration of the meta data mirror.
+type Config struct {
+	Enabled bool `toml:"enabled"`
+
+	// Destinations the meta data is mirrored to. A destination is the bind
+	// address of a standby server, such as "tcp://standby:8088", a
+	// directory, such as "file:///mnt/backup/meta", or a URL the snapshot
+	// is uploaded to with PUT, such as "https://bucket.example.com/meta.db".
+	Destinations []string `toml:"destinations"`
+
+	RetryInterval toml.Duration `toml:"retry-interval"`
+	SyncInterval  toml.Duration `toml:"sync-interval"`
+	Timeout       toml.Duration `toml:"timeout"`
+
+	// Standby accepts the meta data mirrored by a primary server on the
+	// bind address. The meta data of this server is replaced by every
+	// mirrored snapshot.
+	Standby bool `toml:"standby"`
+}
+
+// NewConfig returns a new Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Enabled:       false,
+		RetryInterval: toml.Duration(DefaultRetryInterval),
+		SyncInterval:  toml.Duration(DefaultSyncInterval),
+		Timeout:       toml.Duration(DefaultTimeout),
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if len(c.Destinations) == 0 {
+		return errors.New("at least one destination must be specified")
+	}
+	for _, d := range c.Destinations {
+		if _, err := parseDestination(d); err != nil {
+			return fmt.Errorf("invalid destination %q: %s", d, err)
+		}
+	}
+	if c.RetryInterval <= 0 {
+		return errors.New("retry-interval must be positive")
+	}
+	if c.SyncInterval < 0 {
+		return errors.New("sync-interval must not be negative")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	return nil
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Enabled {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+			"standby": c.Standby,
+		}), nil
+	}
+
+	destinations := make([]string, 0, len(c.Destinations))
+	for _, d := range c.Destinations {
+		if u, err := url.Parse(d); err == nil {
+			d = u.Redacted()
+		}
+		destinations = append(destinations, d)
+	}
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":        true,
+		"destinations":   destinations,
+		"retry-interval": c.RetryInterval,
+		"sync-interval":  c.SyncInterval,
+		"timeout":        c.Timeout,
+		"standby":        c.Standby,
+	}), nil
+}
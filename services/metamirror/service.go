@@ -0,0 +1,297 @@
+// Package metamirror provides the service that continuously mirrors the meta
+// data to warm standby servers or to storage.
+package metamirror // import "github.com/influxdata/influxdb/services/metamirror"
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/file"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/snapshotter"
+	"go.uber.org/zap"
+)
+
+// Statistics for the meta mirror service.
+const (
+	statMirrorOK   = "mirrorOk"
+	statMirrorFail = "mirrorFail"
+	statIndex      = "index"
+)
+
+// metaFile is the name of the file the meta data is written to in a
+// directory, which is the name of the file of the meta store.
+const metaFile = "meta.db"
+
+// Service mirrors a snapshot of the meta data to every destination each time
+// it changes. A destination that fails is retried with the latest snapshot,
+// so intermediate changes may be skipped but the snapshots are always
+// consistent.
+type Service struct {
+	config  Config
+	mirrors []*mirror
+
+	MetaClient interface {
+		Data() meta.Data
+		WaitForDataChanged() chan struct{}
+	}
+
+	Logger *zap.Logger
+
+	wg      sync.WaitGroup
+	closing chan struct{}
+}
+
+// mirror is a destination and the statistics of mirroring to it.
+type mirror struct {
+	name string
+	dest destination
+
+	ok    int64
+	fail  int64
+	index uint64
+}
+
+// NewService returns a new instance of the meta mirror service. The
+// configuration must be valid.
+func NewService(c Config) *Service {
+	s := &Service{
+		config: c,
+		Logger: zap.NewNop(),
+	}
+	for _, d := range c.Destinations {
+		dest, err := parseDestination(d)
+		if err != nil {
+			continue
+		}
+		name := d
+		if u, err := url.Parse(d); err == nil {
+			name = u.Redacted()
+		}
+		s.mirrors = append(s.mirrors, &mirror{name: name, dest: dest})
+	}
+	return s
+}
+
+// WithLogger sets the logger for the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "meta-mirror"))
+}
+
+// Open starts mirroring the meta data.
+func (s *Service) Open() error {
+	if !s.config.Enabled || s.closing != nil {
+		return nil
+	}
+	s.Logger.Info("Starting meta mirror service", zap.Int("destinations", len(s.mirrors)))
+
+	s.closing = make(chan struct{})
+	for _, m := range s.mirrors {
+		s.wg.Add(1)
+		go s.run(m)
+	}
+	return nil
+}
+
+// Close stops mirroring the meta data.
+func (s *Service) Close() error {
+	if s.closing == nil {
+		return nil
+	}
+	close(s.closing)
+	s.wg.Wait()
+	s.closing = nil
+	return nil
+}
+
+// run mirrors the meta data to a destination until the service is closed.
+func (s *Service) run(m *mirror) {
+	defer s.wg.Done()
+
+	var resync <-chan time.Time
+	if d := time.Duration(s.config.SyncInterval); d > 0 {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		resync = ticker.C
+	}
+
+	var lastIndex uint64
+	for {
+		// Get the channel before the data so a change in between is not
+		// missed.
+		changed := s.MetaClient.WaitForDataChanged()
+		data := s.MetaClient.Data()
+
+		if data.Index != lastIndex {
+			if err := s.mirror(m, &data); err != nil {
+				atomic.AddInt64(&m.fail, 1)
+				s.Logger.Info("Failed to mirror meta data", zap.String("destination", m.name), zap.Uint64("index", data.Index), zap.Error(err))
+
+				select {
+				case <-s.closing:
+					return
+				case <-time.After(time.Duration(s.config.RetryInterval)):
+					continue
+				}
+			}
+			atomic.AddInt64(&m.ok, 1)
+			atomic.StoreUint64(&m.index, data.Index)
+			lastIndex = data.Index
+		}
+
+		select {
+		case <-s.closing:
+			return
+		case <-changed:
+		case <-resync:
+			// Mirror the unchanged data again in case the destination was
+			// replaced or lost it.
+			lastIndex = 0
+		}
+	}
+}
+
+func (s *Service) mirror(m *mirror, data *meta.Data) error {
+	b, err := data.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return m.dest.mirror(b, time.Duration(s.config.Timeout))
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (s *Service) Statistics(tags map[string]string) []models.Statistic {
+	statistics := make([]models.Statistic, 0, len(s.mirrors))
+	for _, m := range s.mirrors {
+		statistics = append(statistics, models.Statistic{
+			Name: "metaMirror",
+			Tags: models.StatisticTags{"destination": m.name}.Merge(tags),
+			Values: map[string]interface{}{
+				statMirrorOK:   atomic.LoadInt64(&m.ok),
+				statMirrorFail: atomic.LoadInt64(&m.fail),
+				statIndex:      int64(atomic.LoadUint64(&m.index)),
+			},
+		})
+	}
+	return statistics
+}
+
+// destination is a place the meta data is mirrored to.
+type destination interface {
+	// mirror replaces the meta data at the destination with data, the
+	// binary representation of the meta data.
+	mirror(data []byte, timeout time.Duration) error
+}
+
+// parseDestination returns the destination of a URL.
+func parseDestination(s string) (destination, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "tcp":
+		if u.Host == "" {
+			return nil, errors.New("address of standby server required")
+		}
+		return &standbyDestination{client: snapshotter.NewClient(u.Host)}, nil
+	case "file":
+		if u.Path == "" {
+			return nil, errors.New("directory required")
+		}
+		return &fileDestination{dir: filepath.FromSlash(u.Path)}, nil
+	case "http", "https":
+		if u.Host == "" {
+			return nil, errors.New("host required")
+		}
+		return &httpDestination{url: u}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, expected tcp, file, http or https", u.Scheme)
+	}
+}
+
+// standbyDestination replaces the meta data of a standby server through its
+// snapshot service.
+type standbyDestination struct {
+	client *snapshotter.Client
+}
+
+func (d *standbyDestination) mirror(data []byte, timeout time.Duration) error {
+	return d.client.MirrorMeta(data, timeout)
+}
+
+// fileDestination writes the meta data to a file in a directory. The file is
+// replaced atomically so it can be copied at any time.
+type fileDestination struct {
+	dir string
+}
+
+func (d *fileDestination) mirror(data []byte, timeout time.Duration) error {
+	if err := os.MkdirAll(d.dir, 0700); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(d.dir, metaFile)
+	tmpFile := filename + "tmp"
+
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	} else if err := f.Sync(); err != nil {
+		return err
+	}
+
+	//close file handle before renaming to support Windows
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return file.RenameFile(tmpFile, filename)
+}
+
+// httpDestination uploads the meta data with PUT, which most object storages
+// support. The user and password of the URL are sent with basic
+// authentication.
+type httpDestination struct {
+	url *url.URL
+}
+
+func (d *httpDestination) mirror(data []byte, timeout time.Duration) error {
+	u := *d.url
+	u.User = nil
+	req, err := http.NewRequest("PUT", u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if user := d.url.User; user != nil {
+		password, _ := user.Password()
+		req.SetBasicAuth(user.Username(), password)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package metamirror_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/metamirror"
+	"github.com/influxdata/influxdb/services/snapshotter"
+	"github.com/influxdata/influxdb/tcp"
+	itoml "github.com/influxdata/influxdb/toml"
+)
+
+func TestService_Mirror(t *testing.T) {
+	primary := newMetaClient(t)
+	standby := newMetaClient(t)
+
+	// The standby accepts the meta data on its snapshot service.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	mux := tcp.NewMux(tcp.MuxLogger(io.Discard))
+	go mux.Serve(l)
+
+	srv := snapshotter.NewService()
+	srv.MetaClient = standby
+	srv.AcceptMetaMirror = true
+	srv.Listener = mux.Listen(snapshotter.MuxHeader)
+	if err := srv.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	// The object storage fails the first upload, which is retried.
+	var mu sync.Mutex
+	var uploads [][]byte
+	failed := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); r.Method != "PUT" || !ok || user != "user" || password != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		uploads = append(uploads, b)
+	}))
+	defer ts.Close()
+
+	dir := filepath.Join(t.TempDir(), "mirror")
+	c := metamirror.NewConfig()
+	c.Enabled = true
+	c.RetryInterval = itoml.Duration(10 * time.Millisecond)
+	c.Destinations = []string{
+		"tcp://" + l.Addr().String(),
+		"file://" + filepath.ToSlash(dir),
+		"http://user:secret@" + ts.Listener.Addr().String() + "/meta.db",
+	}
+
+	s := metamirror.NewService(c)
+	s.MetaClient = primary
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if _, err := primary.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+	index := primary.Data().Index
+
+	// Wait until the meta data is mirrored to every destination.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		mirrored := 0
+		for _, stat := range s.Statistics(nil) {
+			if stat.Values["index"] == int64(index) {
+				mirrored++
+			}
+		}
+		if mirrored == len(c.Destinations) {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("meta data not mirrored: %v", s.Statistics(nil))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if standby.Database("db0") == nil {
+		t.Fatal("expected database on standby")
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "meta.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data meta.Data
+	if err := data.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	} else if data.Index != index || data.Database("db0") == nil {
+		t.Fatalf("unexpected mirrored data: index=%d databases=%v", data.Index, data.Databases)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(uploads) == 0 {
+		t.Fatal("expected upload")
+	} else if err := data.UnmarshalBinary(uploads[len(uploads)-1]); err != nil {
+		t.Fatal(err)
+	} else if data.Index != index {
+		t.Fatalf("unexpected uploaded index: %d", data.Index)
+	}
+	for _, stat := range s.Statistics(nil) {
+		if stat.Tags["destination"] == "http://user:xxxxx@"+ts.Listener.Addr().String()+"/meta.db" && stat.Values["mirrorFail"] != int64(1) {
+			t.Fatalf("unexpected statistics: %v", stat)
+		}
+	}
+}
+
+func newMetaClient(t *testing.T) *meta.Client {
+	t.Helper()
+
+	c := meta.NewConfig()
+	c.Dir = t.TempDir()
+	client := meta.NewClient(c)
+	if err := client.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
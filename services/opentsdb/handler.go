@@ -102,6 +102,8 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 
 	// Convert points into TSDB points.
 	points := make([]models.Point, 0, len(dps))
+	b := models.GetPointBuilder()
+	defer models.PutPointBuilder(b)
 	for i := range dps {
 		p := dps[i]
 
@@ -114,7 +116,14 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 			ts = time.Unix(p.Time/1000, (p.Time%1000)*1000)
 		}
 
-		pt, err := models.NewPoint(p.Metric, models.NewTags(p.Tags), map[string]interface{}{"value": p.Value}, ts)
+		b.Reset()
+		b.SetName(p.Metric)
+		for k, v := range p.Tags {
+			b.AddTag(k, v)
+		}
+		b.AddFloatField("value", p.Value)
+		b.SetTime(ts)
+		pt, err := b.Point()
 		if err != nil {
 			h.Logger.Info("Dropping point", zap.String("name", p.Metric), zap.Error(err))
 			if h.stats != nil {
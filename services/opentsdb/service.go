@@ -413,7 +413,8 @@ func (s *Service) handleTelnetConn(conn net.Conn) {
 			continue
 		}
 
-		tags := make(map[string]string)
+		b := models.GetPointBuilder()
+		b.SetName(measurement)
 		for t := range tagStrs {
 			parts := strings.SplitN(tagStrs[t], "=", 2)
 			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
@@ -423,23 +424,23 @@ func (s *Service) handleTelnetConn(conn net.Conn) {
 				}
 				continue
 			}
-			k := parts[0]
-
-			tags[k] = parts[1]
+			b.AddTag(parts[0], parts[1])
 		}
 
-		fields := make(map[string]interface{})
 		fv, err := strconv.ParseFloat(valueStr, 64)
 		if err != nil {
+			models.PutPointBuilder(b)
 			atomic.AddInt64(&s.stats.TelnetBadFloat, 1)
 			if s.LogPointErrors {
 				s.Logger.Info("Bad float", zap.String("value", valueStr), zap.String("remote_addr", remoteAddr))
 			}
 			continue
 		}
-		fields["value"] = fv
+		b.AddFloatField("value", fv)
+		b.SetTime(t)
 
-		pt, err := models.NewPoint(measurement, models.NewTags(tags), fields, t)
+		pt, err := b.Point()
+		models.PutPointBuilder(b)
 		if err != nil {
 			atomic.AddInt64(&s.stats.TelnetBadFloat, 1)
 			if s.LogPointErrors {
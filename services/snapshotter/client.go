@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tcp"
@@ -90,6 +91,40 @@ func (c *Client) UpdateMeta(req *Request, upStream io.Reader) (map[uint64]uint64
 	return shardIDMap, nil
 }
 
+// MirrorMeta replaces the meta data of a standby server with data, the
+// binary representation of the meta data. The server must accept mirrored
+// meta data.
+func (c *Client) MirrorMeta(data []byte, timeout time.Duration) error {
+	conn, err := tcp.Dial("tcp", c.host, MuxHeader)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			return err
+		}
+	}
+
+	req := Request{Type: RequestMetaStoreMirror, UploadSize: int64(len(data))}
+	if _, err := conn.Write([]byte{byte(req.Type)}); err != nil {
+		return err
+	} else if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("encode snapshot request: %s", err)
+	} else if _, err := conn.Write(data); err != nil {
+		return err
+	}
+
+	var resp MirrorResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return fmt.Errorf("decode mirror response: %s", err)
+	} else if resp.Err != "" {
+		return errors.New(resp.Err)
+	}
+	return nil
+}
+
 func decodeUintPair(bits []byte) (uint64, uint64, error) {
 	if len(bits) != 16 {
 		return 0, 0, errors.New("slice must have exactly 16 bytes")
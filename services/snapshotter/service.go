@@ -6,6 +6,7 @@ import (
 	"encoding"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -47,6 +48,10 @@ type Service struct {
 
 	MetaClient MetaClient
 
+	// AcceptMetaMirror allows a primary server to replace the meta data with
+	// its own, which makes this server a warm standby of the primary.
+	AcceptMetaMirror bool
+
 	TSDBStore interface {
 		BackupShard(id uint64, since time.Time, w io.Writer) error
 		ExportShard(id uint64, ExportStart time.Time, ExportEnd time.Time, w io.Writer) error
@@ -156,6 +161,8 @@ func (s *Service) handleConn(conn net.Conn) error {
 		return s.writeRetentionPolicyInfo(conn, r.BackupDatabase, r.BackupRetentionPolicy)
 	case RequestMetaStoreUpdate:
 		return s.updateMetaStore(conn, bytes, r.BackupDatabase, r.RestoreDatabase, r.BackupRetentionPolicy, r.RestoreRetentionPolicy)
+	case RequestMetaStoreMirror:
+		return s.mirrorMetaStore(conn, bytes)
 	default:
 		return fmt.Errorf("request type unknown: %v", r.Type)
 	}
@@ -220,6 +227,37 @@ func (s *Service) updateMetaStore(conn net.Conn, bits []byte, backupDBName, rest
 	return err
 }
 
+// mirrorMetaStore replaces the meta data with the meta data mirrored by a
+// primary server and responds with the error, if any.
+func (s *Service) mirrorMetaStore(conn net.Conn, bits []byte) error {
+	var resp MirrorResponse
+	err := s.replaceMetaStore(bits)
+	if err != nil {
+		resp.Err = err.Error()
+	}
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		return fmt.Errorf("encode response: %s", err)
+	}
+	return err
+}
+
+func (s *Service) replaceMetaStore(bits []byte) error {
+	if !s.AcceptMetaMirror {
+		return errors.New("meta mirror rejected: this server is not a standby")
+	}
+
+	ossClient, ok := s.MetaClient.(OSSMetaClient)
+	if !ok {
+		return errors.New("meta mirror only supported for OSS")
+	}
+
+	var md meta.Data
+	if err := md.UnmarshalBinary(bits); err != nil {
+		return fmt.Errorf("failed to decode meta: %s", err)
+	}
+	return ossClient.SetData(&md)
+}
+
 // iterate over a list of newDB's that should have just been added to the metadata
 // If the db was not created in the metadata return an error.
 // None of the shards should exist on a new DB, and CreateShard protects against double-creation.
@@ -468,6 +506,10 @@ const (
 	// RequestShardUpdate will initiate the upload of a shard data tar file
 	// and have the engine import the data.
 	RequestShardUpdate
+
+	// RequestMetaStoreMirror represents a request to replace the metastore
+	// of a standby with the uploaded metafile.
+	RequestMetaStoreMirror
 )
 
 // Request represents a request for a specific backup or for information
@@ -485,6 +527,11 @@ type Request struct {
 	UploadSize             int64
 }
 
+// MirrorResponse is the response to a RequestMetaStoreMirror request.
+type MirrorResponse struct {
+	Err string `json:",omitempty"`
+}
+
 // Response contains the relative paths for all the shards on this server
 // that are in the requested database or retention policy.
 type Response struct {
@@ -415,6 +415,42 @@ func TestSnapshotter_RequestUpdateMeta(t *testing.T) {
 	}
 }
 
+func TestSnapshotter_RequestMetaStoreMirror(t *testing.T) {
+	s, l, err := NewTestService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	m := &MetaClient{}
+	s.MetaClient = m
+	if err := s.Open(); err != nil {
+		t.Fatalf("unexpected open error: %s", err)
+	}
+	defer s.Close()
+
+	metaBytes, _ := data.MarshalBinary()
+
+	// The meta data is only replaced by a standby.
+	c := snapshotter.NewClient(l.Addr().String())
+	if err := c.MirrorMeta(metaBytes, time.Second); err == nil || err.Error() != "meta mirror rejected: this server is not a standby" {
+		t.Fatalf("unexpected error: %v", err)
+	} else if m.Database("db0") != nil {
+		t.Fatal("unexpected database")
+	}
+
+	s.AcceptMetaMirror = true
+	if err := c.MirrorMeta(metaBytes, time.Second); err != nil {
+		t.Fatal(err)
+	} else if m.Database("db0") == nil {
+		t.Fatal("expected database")
+	}
+
+	if err := c.MirrorMeta([]byte("invalid"), time.Second); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 func TestSnapshotter_InvalidRequest(t *testing.T) {
 	s, l, err := NewTestService()
 	if err != nil {
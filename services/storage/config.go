@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"crypto/tls"
+	"errors"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+)
+
+const (
+	// DefaultBindAddress is the default address of the storage read service.
+	DefaultBindAddress = ":8082"
+
+	// DefaultHTTPSCertificate is the default certificate used when HTTPS is enabled.
+	DefaultHTTPSCertificate = "/etc/ssl/influxdb.pem"
+)
+
+// Config represents the configuration of the gRPC storage read service.
+type Config struct {
+	Enabled          bool        `toml:"enabled"`
+	BindAddress      string      `toml:"bind-address"`
+	HTTPSEnabled     bool        `toml:"https-enabled"`
+	HTTPSCertificate string      `toml:"https-certificate"`
+	HTTPSPrivateKey  string      `toml:"https-private-key"`
+	TLS              *tls.Config `toml:"-"`
+}
+
+// NewConfig returns a new Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Enabled:          false,
+		BindAddress:      DefaultBindAddress,
+		HTTPSEnabled:     false,
+		HTTPSCertificate: DefaultHTTPSCertificate,
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.BindAddress == "" {
+		return errors.New("bind-address must be specified")
+	}
+	if c.HTTPSEnabled && c.HTTPSCertificate == "" {
+		return errors.New("https-certificate must be specified when https is enabled")
+	}
+	return nil
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Enabled {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+		}), nil
+	}
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":       true,
+		"bind-address":  c.BindAddress,
+		"https-enabled": c.HTTPSEnabled,
+	}), nil
+}
@@ -0,0 +1,401 @@
+package storage
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// stringValuesBatchSize is the number of values of a StringValuesResponse.
+const stringValuesBatchSize = 1000
+
+// supportedAggregates are the aggregates pushed down by ReadGroup and
+// ReadWindowAggregate, as advertised by Capabilities. A window aggregate
+// also accepts mean with count.
+const supportedAggregates = "count,sum,min,max,first,last,mean"
+
+// Service serves the Storage gRPC service of storage_common.proto, so that
+// external query engines read series, groups and window aggregates computed
+// by the storage engine instead of the raw points.
+type Service struct {
+	config Config
+	ln     net.Listener
+	server *grpc.Server
+
+	Store reads.Store
+
+	MetaClient interface {
+		Authenticate(username, password string) (meta.User, error)
+		AuthenticateToken(token string) (meta.User, error)
+	}
+	Authorizer interface {
+		AuthorizeDatabase(u meta.User, priv influxql.Privilege, database string) error
+	}
+	AuthEnabled bool
+
+	Logger *zap.Logger
+}
+
+// NewService returns a new instance of the storage read service.
+func NewService(c Config) *Service {
+	return &Service{
+		config: c,
+		Logger: zap.NewNop(),
+	}
+}
+
+// WithLogger sets the logger for the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "storage"))
+}
+
+// Open starts serving the reads on the bind address, over TLS if HTTPS is
+// enabled.
+func (s *Service) Open() error {
+	if s.server != nil {
+		return nil
+	}
+
+	var opts []grpc.ServerOption
+	if s.config.HTTPSEnabled {
+		key := s.config.HTTPSPrivateKey
+		if key == "" {
+			key = s.config.HTTPSCertificate
+		}
+		cert, err := tls.LoadX509KeyPair(s.config.HTTPSCertificate, key)
+		if err != nil {
+			return err
+		}
+		tlsConfig := new(tls.Config)
+		if s.config.TLS != nil {
+			tlsConfig = s.config.TLS.Clone()
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	ln, err := net.Listen("tcp", s.config.BindAddress)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	s.Logger.Info("Starting storage read service", zap.Stringer("addr", ln.Addr()), zap.Bool("https", s.config.HTTPSEnabled), zap.Bool("auth_enabled", s.AuthEnabled))
+
+	s.server = grpc.NewServer(opts...)
+	s.server.RegisterService(&storageServiceDesc, s)
+	go func() {
+		if err := s.server.Serve(ln); err != nil {
+			s.Logger.Info("Storage read service stopped", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Close stops the service and the reads in progress.
+func (s *Service) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	s.server.Stop()
+	s.server = nil
+	return nil
+}
+
+// Addr returns the address the service listens on.
+func (s *Service) Addr() net.Addr {
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// authorize returns an error if the user of the request may not read the
+// database of source.
+func (s *Service) authorize(ctx context.Context, source *anypb.Any) error {
+	if source == nil {
+		return status.Error(codes.InvalidArgument, ErrMissingReadSource.Error())
+	}
+	src, err := GetReadSource(source)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !s.AuthEnabled {
+		return nil
+	}
+
+	// The credentials are sent like the Authorization header of the HTTP
+	// API: "Token username:password" or "Token <API token>".
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, v := range md.Get("authorization") {
+		if t, found := strings.CutPrefix(v, "Token "); found {
+			token = t
+			break
+		}
+	}
+	if token == "" {
+		return status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	var user meta.User
+	if username, password, ok := strings.Cut(token, ":"); ok {
+		user, err = s.MetaClient.Authenticate(username, password)
+	} else {
+		// API tokens never contain a colon.
+		user, err = s.MetaClient.AuthenticateToken(token)
+	}
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err := s.Authorizer.AuthorizeDatabase(user, influxql.ReadPrivilege, src.Database); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	// The reads aren't filtered by measurement, so the users with
+	// privileges on measurements would read all the series of the database.
+	if !user.IsOpen() {
+		return status.Errorf(codes.PermissionDenied, "%q user has privileges on measurements, which are not supported by the storage read service", user.ID())
+	}
+	return nil
+}
+
+// readStream is a reads.ResponseStream of a gRPC stream.
+type readStream struct {
+	grpc.ServerStream
+}
+
+func (s readStream) Send(r *datatypes.ReadResponse) error {
+	return s.SendMsg(r)
+}
+
+// ReadFilter streams the series matching the request.
+func (s *Service) ReadFilter(req *datatypes.ReadFilterRequest, stream grpc.ServerStream) error {
+	if err := s.authorize(stream.Context(), req.ReadSource); err != nil {
+		return err
+	}
+	rs, err := s.Store.ReadFilter(stream.Context(), req)
+	if err != nil {
+		return err
+	} else if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	w := reads.NewResponseWriter(readStream{stream}, 0)
+	if err := w.WriteResultSet(rs); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Err()
+}
+
+// ReadGroup streams the series matching the request in groups, aggregated
+// by the storage engine if the request has an aggregate.
+func (s *Service) ReadGroup(req *datatypes.ReadGroupRequest, stream grpc.ServerStream) error {
+	if err := s.authorize(stream.Context(), req.ReadSource); err != nil {
+		return err
+	}
+	rs, err := s.Store.ReadGroup(stream.Context(), req)
+	if err != nil {
+		return err
+	} else if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	w := reads.NewResponseWriter(readStream{stream}, datatypes.HintFlags(req.Hints))
+	if err := w.WriteGroupResultSet(rs); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Err()
+}
+
+// ReadWindowAggregate streams the aggregates of the windows of the series
+// matching the request.
+func (s *Service) ReadWindowAggregate(req *datatypes.ReadWindowAggregateRequest, stream grpc.ServerStream) error {
+	if err := s.authorize(stream.Context(), req.ReadSource); err != nil {
+		return err
+	}
+	if len(req.Aggregate) == 0 {
+		return status.Error(codes.InvalidArgument, "missing aggregate")
+	}
+	rs, err := s.Store.WindowAggregate(stream.Context(), req)
+	if err != nil {
+		return err
+	} else if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	w := reads.NewResponseWriter(readStream{stream}, 0)
+	if err := w.WriteResultSet(rs); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Err()
+}
+
+// TagKeys streams the tag keys of the series matching the request.
+func (s *Service) TagKeys(req *datatypes.TagKeysRequest, stream grpc.ServerStream) error {
+	if err := s.authorize(stream.Context(), req.TagsSource); err != nil {
+		return err
+	}
+	iter, err := s.Store.TagKeys(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	return sendStringValues(stream, iter)
+}
+
+// TagValues streams the values of a tag key of the series matching the
+// request.
+func (s *Service) TagValues(req *datatypes.TagValuesRequest, stream grpc.ServerStream) error {
+	if err := s.authorize(stream.Context(), req.TagsSource); err != nil {
+		return err
+	}
+	iter, err := s.Store.TagValues(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	return sendStringValues(stream, iter)
+}
+
+// sendStringValues streams the values of iter in batches.
+func sendStringValues(stream grpc.ServerStream, iter cursors.StringIterator) error {
+	if iter == nil {
+		return nil
+	}
+	resp := &datatypes.StringValuesResponse{}
+	for iter.Next() {
+		resp.Values = append(resp.Values, []byte(iter.Value()))
+		if len(resp.Values) < stringValuesBatchSize {
+			continue
+		}
+		if err := stream.SendMsg(resp); err != nil {
+			return err
+		}
+		resp = &datatypes.StringValuesResponse{}
+	}
+	if len(resp.Values) == 0 {
+		return nil
+	}
+	return stream.SendMsg(resp)
+}
+
+// Capabilities returns the aggregates pushed down by the storage engine.
+func (s *Service) Capabilities(context.Context, *emptypb.Empty) (*datatypes.CapabilitiesResponse, error) {
+	return &datatypes.CapabilitiesResponse{
+		Caps: map[string]string{
+			"ReadGroup":           supportedAggregates,
+			"ReadWindowAggregate": supportedAggregates,
+		},
+	}, nil
+}
+
+// storageServer is the server of the Storage service.
+type storageServer interface {
+	ReadFilter(*datatypes.ReadFilterRequest, grpc.ServerStream) error
+	ReadGroup(*datatypes.ReadGroupRequest, grpc.ServerStream) error
+	ReadWindowAggregate(*datatypes.ReadWindowAggregateRequest, grpc.ServerStream) error
+	TagKeys(*datatypes.TagKeysRequest, grpc.ServerStream) error
+	TagValues(*datatypes.TagValuesRequest, grpc.ServerStream) error
+	Capabilities(context.Context, *emptypb.Empty) (*datatypes.CapabilitiesResponse, error)
+}
+
+// storageServiceDesc describes the Storage service of storage_common.proto,
+// as the gRPC plugin of protoc would.
+var storageServiceDesc = grpc.ServiceDesc{
+	ServiceName: "influxdata.platform.storage.Storage",
+	HandlerType: (*storageServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Capabilities",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(storageServer).Capabilities(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/influxdata.platform.storage.Storage/Capabilities"}
+				return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(storageServer).Capabilities(ctx, req.(*emptypb.Empty))
+				})
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReadFilter",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := new(datatypes.ReadFilterRequest)
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(storageServer).ReadFilter(req, stream)
+			},
+		},
+		{
+			StreamName:    "ReadGroup",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := new(datatypes.ReadGroupRequest)
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(storageServer).ReadGroup(req, stream)
+			},
+		},
+		{
+			StreamName:    "ReadWindowAggregate",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := new(datatypes.ReadWindowAggregateRequest)
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(storageServer).ReadWindowAggregate(req, stream)
+			},
+		},
+		{
+			StreamName:    "TagKeys",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := new(datatypes.TagKeysRequest)
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(storageServer).TagKeys(req, stream)
+			},
+		},
+		{
+			StreamName:    "TagValues",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := new(datatypes.TagValuesRequest)
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(storageServer).TagValues(req, stream)
+			},
+		},
+	},
+	Metadata: "storage_common.proto",
+}
@@ -0,0 +1,330 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/storage"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"github.com/influxdata/influxql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Ensure the window aggregates of the store are streamed to the client.
+func TestService_ReadWindowAggregate(t *testing.T) {
+	store := mock.NewStoreReader()
+	store.WindowAggregateFunc = func(ctx context.Context, req *datatypes.ReadWindowAggregateRequest) (reads.ResultSet, error) {
+		if req.WindowEvery != 10 || len(req.Aggregate) != 1 || req.Aggregate[0].Type != datatypes.Aggregate_AggregateTypeMean {
+			return nil, errors.New("unexpected request")
+		}
+		return newFloatResultSet(models.NewTags(map[string]string{"_measurement": "cpu", "host": "a"}), []int64{10, 20}, []float64{1.5, 2.5}), nil
+	}
+	s := mustOpenService(t, store)
+
+	conn := mustDial(t, s)
+	req := &datatypes.ReadWindowAggregateRequest{
+		ReadSource:  mustReadSource(t, "db0"),
+		Range:       &datatypes.TimestampRange{Start: 0, End: 30},
+		WindowEvery: 10,
+		Aggregate:   []*datatypes.Aggregate{{Type: datatypes.Aggregate_AggregateTypeMean}},
+	}
+	frames, err := readFrames(context.Background(), conn, "ReadWindowAggregate", req)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(frames) != 2 {
+		t.Fatalf("unexpected frames: %v", frames)
+	}
+	if series := frames[0].GetSeries(); series == nil || len(series.Tags) != 2 || string(series.Tags[1].Value) != "a" {
+		t.Fatalf("unexpected series frame: %v", frames[0])
+	}
+	if points := frames[1].GetFloatPoints(); points == nil || len(points.Values) != 2 || points.Values[1] != 2.5 || points.Timestamps[1] != 20 {
+		t.Fatalf("unexpected points frame: %v", frames[1])
+	}
+
+	// A window aggregate needs an aggregate.
+	req.Aggregate = nil
+	if _, err := readFrames(context.Background(), conn, "ReadWindowAggregate", req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unexpected error without an aggregate: %v", err)
+	}
+}
+
+// Ensure the reads are authenticated and authorized when auth is enabled.
+func TestService_ReadGroup_Auth(t *testing.T) {
+	store := mock.NewStoreReader()
+	store.ReadGroupFunc = func(ctx context.Context, req *datatypes.ReadGroupRequest) (reads.GroupResultSet, error) {
+		return mock.NewGroupResultSet(), nil
+	}
+	s := storage.NewService(storage.Config{Enabled: true, BindAddress: "127.0.0.1:0"})
+	s.Store = store
+	s.AuthEnabled = true
+	s.MetaClient = &authMetaClient{users: map[string]*meta.UserInfo{
+		"reader": {Name: "reader", Privileges: map[string]influxql.Privilege{"db0": influxql.ReadPrivilege}},
+		"fine": {
+			Name:       "fine",
+			Privileges: map[string]influxql.Privilege{"db0": influxql.ReadPrivilege},
+			MeasurementPrivileges: []meta.MeasurementPrivilege{
+				{Database: "db0", Name: "cpu", Privilege: influxql.WritePrivilege},
+			},
+		},
+	}}
+	s.Authorizer = meta.NewQueryAuthorizer(nil)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	conn := mustDial(t, s)
+	req := &datatypes.ReadGroupRequest{
+		ReadSource: mustReadSource(t, "db0"),
+		Range:      &datatypes.TimestampRange{Start: 0, End: 30},
+		Group:      datatypes.ReadGroupRequest_GroupBy,
+		GroupKeys:  []string{"host"},
+		Aggregate:  &datatypes.Aggregate{Type: datatypes.Aggregate_AggregateTypeCount},
+	}
+	for _, tt := range []struct {
+		token string
+		code  codes.Code
+	}{
+		{"", codes.Unauthenticated},
+		{"Token reader:wrong", codes.Unauthenticated},
+		{"Token fine:password", codes.PermissionDenied},
+		{"Token reader:password", codes.OK},
+		{"Token wrong", codes.Unauthenticated},
+		{"Token reader-token", codes.OK},
+	} {
+		ctx := context.Background()
+		if tt.token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.token)
+		}
+		if _, err := readFrames(ctx, conn, "ReadGroup", req); status.Code(err) != tt.code {
+			t.Errorf("%q: unexpected error: %v", tt.token, err)
+		}
+	}
+
+	// The user may only read the databases it is authorized for.
+	req.ReadSource = mustReadSource(t, "db1")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Token reader:password")
+	if _, err := readFrames(ctx, conn, "ReadGroup", req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("unexpected error of another database: %v", err)
+	}
+}
+
+func TestService_Capabilities(t *testing.T) {
+	s := mustOpenService(t, mock.NewStoreReader())
+	conn := mustDial(t, s)
+
+	var resp datatypes.CapabilitiesResponse
+	if err := conn.Invoke(context.Background(), "/influxdata.platform.storage.Storage/Capabilities", &emptypb.Empty{}, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := resp.Caps["ReadWindowAggregate"], "count,sum,min,max,first,last,mean"; got != exp {
+		t.Fatalf("unexpected window aggregates: got %q, exp %q", got, exp)
+	}
+}
+
+// Ensure the reads are served over TLS when HTTPS is enabled.
+func TestService_HTTPS(t *testing.T) {
+	dir := t.TempDir()
+	cert := mustWriteCert(t, filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+
+	s := storage.NewService(storage.Config{
+		Enabled:          true,
+		BindAddress:      "127.0.0.1:0",
+		HTTPSEnabled:     true,
+		HTTPSCertificate: filepath.Join(dir, "cert.pem"),
+		HTTPSPrivateKey:  filepath.Join(dir, "key.pem"),
+	})
+	s.Store = mock.NewStoreReader()
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	conn, err := grpc.Dial(s.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var resp datatypes.CapabilitiesResponse
+	if err := conn.Invoke(context.Background(), "/influxdata.platform.storage.Storage/Capabilities", &emptypb.Empty{}, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// The plaintext clients are refused.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := mustDial(t, s).Invoke(ctx, "/influxdata.platform.storage.Storage/Capabilities", &emptypb.Empty{}, &resp); err == nil {
+		t.Fatal("expected an error without TLS")
+	}
+}
+
+func mustOpenService(t *testing.T, store reads.Store) *storage.Service {
+	t.Helper()
+	s := storage.NewService(storage.Config{Enabled: true, BindAddress: "127.0.0.1:0"})
+	s.Store = store
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func mustDial(t *testing.T, s *storage.Service) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.Dial(s.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func mustReadSource(t *testing.T, database string) *anypb.Any {
+	t.Helper()
+	src, err := anypb.New(&storage.ReadSource{Database: database})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+// readFrames calls a streaming method of the Storage service and returns the
+// frames of all its responses.
+func readFrames(ctx context.Context, conn *grpc.ClientConn, method string, req proto.Message) ([]*datatypes.ReadResponse_Frame, error) {
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, "/influxdata.platform.storage.Storage/"+method)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	} else if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var frames []*datatypes.ReadResponse_Frame
+	for {
+		var resp datatypes.ReadResponse
+		if err := stream.RecvMsg(&resp); err == io.EOF {
+			return frames, nil
+		} else if err != nil {
+			return nil, err
+		}
+		frames = append(frames, resp.Frames...)
+	}
+}
+
+// newFloatResultSet returns a result set of a single series of float values.
+func newFloatResultSet(tags models.Tags, timestamps []int64, values []float64) reads.ResultSet {
+	rs := mock.NewResultSet()
+	next := true
+	rs.NextFunc = func() bool {
+		n := next
+		next = false
+		return n
+	}
+	rs.TagsFunc = func() models.Tags { return tags }
+	rs.CursorFunc = func() cursors.Cursor {
+		cur := mock.NewFloatArrayCursor()
+		a := &cursors.FloatArray{Timestamps: timestamps, Values: values}
+		cur.NextFunc = func() *cursors.FloatArray {
+			next := a
+			a = &cursors.FloatArray{}
+			return next
+		}
+		return cur
+	}
+	return rs
+}
+
+type authMetaClient struct {
+	users map[string]*meta.UserInfo
+}
+
+func (c *authMetaClient) Authenticate(username, password string) (meta.User, error) {
+	u, ok := c.users[username]
+	if !ok || password != "password" {
+		return nil, meta.ErrAuthenticate
+	}
+	return u, nil
+}
+
+// AuthenticateToken authenticates the API token "<username>-token".
+func (c *authMetaClient) AuthenticateToken(token string) (meta.User, error) {
+	username, ok := strings.CutSuffix(token, "-token")
+	if !ok {
+		return nil, meta.ErrAuthenticate
+	}
+	u, ok := c.users[username]
+	if !ok {
+		return nil, meta.ErrAuthenticate
+	}
+	return u, nil
+}
+
+// mustWriteCert writes a self-signed certificate for 127.0.0.1 and its key to
+// certPath and keyPath.
+func mustWriteCert(t *testing.T, certPath, keyPath string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "storage"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
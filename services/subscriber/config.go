@@ -48,6 +48,18 @@ type Config struct {
 	// Each named subscription will receive an even division of the total.
 	TotalBufferBytes int `toml:"total-buffer-bytes"`
 
+	// HintedHandoffEnabled queues the writes of each subscription on disk in
+	// HintedHandoffDir rather than in memory, so they survive an outage of
+	// the destinations and a restart. The writes are dropped once the queue
+	// of a subscription reaches HintedHandoffMaxSize, and are sent at most at
+	// HintedHandoffMaxDrainRate points per second. A zero limit or duration
+	// is the default.
+	HintedHandoffEnabled       bool          `toml:"hinted-handoff-enabled"`
+	HintedHandoffDir           string        `toml:"hinted-handoff-dir"`
+	HintedHandoffMaxSize       toml.Size     `toml:"hinted-handoff-max-size"`
+	HintedHandoffRetryInterval toml.Duration `toml:"hinted-handoff-retry-interval"`
+	HintedHandoffMaxDrainRate  int           `toml:"hinted-handoff-max-drain-rate"`
+
 	// TLS is a base tls config to use for https clients.
 	TLS *tls.Config `toml:"-"`
 }
@@ -86,6 +98,14 @@ func (c Config) Validate() error {
 		return errors.New("write-concurrency must be greater than 0")
 	}
 
+	if c.HintedHandoffEnabled && c.HintedHandoffDir == "" {
+		return errors.New("hinted-handoff-dir must be specified")
+	}
+
+	if c.HintedHandoffRetryInterval < 0 || c.HintedHandoffMaxDrainRate < 0 {
+		return errors.New("hinted-handoff limits must not be negative")
+	}
+
 	return nil
 }
 
@@ -107,5 +127,9 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 		"http-timeout":      c.HTTPTimeout,
 		"write-concurrency": c.WriteConcurrency,
 		"write-buffer-size": c.WriteBufferSize,
+
+		"hinted-handoff-enabled":        c.HintedHandoffEnabled,
+		"hinted-handoff-max-size":       c.HintedHandoffMaxSize,
+		"hinted-handoff-max-drain-rate": c.HintedHandoffMaxDrainRate,
 	}), nil
 }
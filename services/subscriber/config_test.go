@@ -108,3 +108,17 @@ write-concurrency = 10
 		t.Errorf("Expected Validation to succeed. Instead was: %v", err)
 	}
 }
+
+func TestConfig_Validate_HintedHandoff(t *testing.T) {
+	c := subscriber.NewConfig()
+	c.HintedHandoffEnabled = true
+	if err := c.Validate(); err == nil || err.Error() != "hinted-handoff-dir must be specified" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.HintedHandoffDir = t.TempDir()
+	c.HintedHandoffMaxDrainRate = -1
+	if err := c.Validate(); err == nil || err.Error() != "hinted-handoff limits must not be negative" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
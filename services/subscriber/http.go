@@ -5,15 +5,19 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"time"
-
-	"github.com/influxdata/influxdb/client/v2"
 )
 
 // HTTP supports writing points over HTTP using the line protocol.
 type HTTP struct {
-	c client.HTTPClient
+	url    url.URL
+	client *http.Client
 }
 
 // NewHTTP returns a new HTTP points writer with default options.
@@ -28,27 +32,63 @@ func NewHTTPS(addr string, timeout time.Duration, unsafeSsl bool, caCerts string
 		return nil, err
 	}
 
-	conf := client.HTTPConfig{
-		Addr:               addr,
-		Timeout:            timeout,
-		InsecureSkipVerify: unsafeSsl,
-		TLSConfig:          tlsConfig,
-	}
-
-	c, err := client.NewHTTPClient(conf)
+	u, err := url.Parse(addr)
 	if err != nil {
 		return nil, err
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported protocol scheme: %s, the address must start with http:// or https://", u.Scheme)
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = new(tls.Config)
 	}
-	return &HTTP{c: c}, nil
+	tlsConfig.InsecureSkipVerify = unsafeSsl
+	return &HTTP{
+		url: *u,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
 }
 
-// WritePoints writes points over HTTP transport.
+// WritePoints writes points over HTTP transport. The points that the
+// destination rejects, with a 4xx status like a partial write, are reported
+// by an error that matches coordinator.ErrHandoffRejected.
 func (h *HTTP) WritePointsContext(ctx context.Context, request WriteRequest) (err error) {
-	bp, _ := client.NewBatchPoints(client.BatchPointsConfig{
-		Database:        request.Database,
-		RetentionPolicy: request.RetentionPolicy,
-	})
-	return h.c.WriteRawCtx(ctx, bp, bytes.NewReader(request.lineProtocol))
+	u := h.url
+	u.Path = path.Join(u.Path, "write")
+	params := url.Values{"db": []string{request.Database}}
+	if request.RetentionPolicy != "" {
+		params.Set("rp", request.RetentionPolicy)
+	}
+	if request.precision != "" {
+		params.Set("precision", request.precision)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(request.lineProtocol))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "InfluxDBClient")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode/100 == 4:
+		// Sending the points again would be rejected again.
+		return &rejectedError{err: fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))}
+	default:
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
 }
 
 func createTLSConfig(caCerts string, tlsConfig *tls.Config) (*tls.Config, error) {
@@ -3,10 +3,14 @@
 package subscriber // import "github.com/influxdata/influxdb/services/subscriber"
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -34,6 +38,9 @@ type WriteRequest struct {
 	RetentionPolicy string
 	// lineProtocol must be valid newline-separated line protocol.
 	lineProtocol []byte
+	// precision is the precision of the timestamps of lineProtocol. They
+	// are in nanoseconds if it is empty.
+	precision string
 	// pointOffsets gives the starting index within lineProtocol of each point,
 	// for splitting batches if required.
 	pointOffsets []int
@@ -48,6 +55,7 @@ func NewWriteRequest(r *coordinator.WritePointsRequest, log *zap.Logger) (wr Wri
 		RetentionPolicy: r.RetentionPolicy,
 		pointOffsets:    make([]int, 0, len(r.Points)),
 		lineProtocol:    make([]byte, 0, len(r.Points)*smallPointSize),
+		precision:       requestPrecision(r.Points),
 	}
 	numInvalid = 0
 	for _, p := range r.Points {
@@ -59,13 +67,55 @@ func NewWriteRequest(r *coordinator.WritePointsRequest, log *zap.Logger) (wr Wri
 		// We are about to append a point of line protocol, so the new point's start index
 		// is the current length.
 		writeReq.pointOffsets = append(writeReq.pointOffsets, len(writeReq.lineProtocol))
-		// Append the new point and a newline
-		writeReq.lineProtocol = p.AppendString(writeReq.lineProtocol)
+		// Append the new point and a newline. The precision is lossless for
+		// all the points.
+		writeReq.lineProtocol, _ = p.AppendPrecisionString(writeReq.lineProtocol, writeReq.precision)
 		writeReq.lineProtocol = append(writeReq.lineProtocol, byte('\n'))
 	}
 	return writeReq, numInvalid
 }
 
+// requestPrecision returns the coarsest precision that keeps the timestamps
+// of points exact, so that fewer bytes are forwarded. The points without a
+// timestamp are forwarded in nanoseconds, as their line can't be converted
+// to nanoseconds.
+func requestPrecision(points []models.Point) string {
+	for _, p := range points {
+		if p.Time().IsZero() {
+			return "n"
+		}
+	}
+	return models.LosslessPrecision(points)
+}
+
+// nanoseconds returns the request with its timestamps in nanoseconds, the
+// precision of the UDP destinations and of the hinted handoff queue.
+func (w *WriteRequest) nanoseconds() WriteRequest {
+	if w.precision == "" || w.precision == "n" {
+		return *w
+	}
+	zeros := len(strconv.FormatInt(models.GetPrecisionMultiplier(w.precision), 10)) - 1
+	ns := WriteRequest{
+		Database:        w.Database,
+		RetentionPolicy: w.RetentionPolicy,
+		pointOffsets:    make([]int, 0, len(w.pointOffsets)),
+		lineProtocol:    make([]byte, 0, len(w.lineProtocol)+len(w.pointOffsets)*zeros),
+	}
+	for i := range w.pointOffsets {
+		line := w.PointAt(i)
+		ns.pointOffsets = append(ns.pointOffsets, len(ns.lineProtocol))
+		ns.lineProtocol = append(ns.lineProtocol, line[:len(line)-1]...)
+		// The timestamp is the last element of the line.
+		if !bytes.HasSuffix(line, []byte(" 0\n")) {
+			for j := 0; j < zeros; j++ {
+				ns.lineProtocol = append(ns.lineProtocol, '0')
+			}
+		}
+		ns.lineProtocol = append(ns.lineProtocol, '\n')
+	}
+	return ns
+}
+
 // pointAt uses pointOffsets to slice the lineProtocol buffer and retrieve the i_th point in the request.
 // It includes the trailing newline.
 func (w *WriteRequest) PointAt(i int) []byte {
@@ -79,6 +129,24 @@ func (w *WriteRequest) PointAt(i int) []byte {
 	return w.lineProtocol[start:end]
 }
 
+// newWriteRequestFromLines returns the write request of valid line protocol.
+func newWriteRequestFromLines(database, retentionPolicy string, lineProtocol []byte) WriteRequest {
+	wr := WriteRequest{
+		Database:        database,
+		RetentionPolicy: retentionPolicy,
+		lineProtocol:    lineProtocol,
+	}
+	for i := 0; i < len(lineProtocol); {
+		wr.pointOffsets = append(wr.pointOffsets, i)
+		n := bytes.IndexByte(lineProtocol[i:], '\n')
+		if n < 0 {
+			break
+		}
+		i += n + 1
+	}
+	return wr
+}
+
 func (w *WriteRequest) Length() int {
 	return len(w.pointOffsets)
 }
@@ -350,7 +418,13 @@ func (s *Service) updateSubs() {
 					s.Logger.Info("Subscription creation failed", zap.String("name", si.Name), zap.Error(err))
 					continue
 				}
-				s.subs[se] = newChanWriter(s, sub)
+				cw, err := newChanWriter(s, se, sub)
+				if err != nil {
+					atomic.AddInt64(&s.stats.CreateFailures, 1)
+					s.Logger.Info("Subscription creation failed", zap.String("name", si.Name), zap.Error(err))
+					continue
+				}
+				s.subs[se] = cw
 				s.Logger.Info("Added new subscription",
 					logger.Database(se.db),
 					logger.RetentionPolicy(se.rp))
@@ -408,7 +482,8 @@ func (s *Service) newPointsWriter(u url.URL) (PointsWriter, error) {
 	}
 }
 
-// chanWriter sends WritePointsRequest to a PointsWriter received over a channel.
+// chanWriter sends WritePointsRequest to a PointsWriter received over a channel,
+// or through a hinted handoff queue if it is enabled.
 type chanWriter struct {
 	writeRequests chan WriteRequest
 	ctx           context.Context
@@ -420,9 +495,15 @@ type chanWriter struct {
 	queueSize     int64
 	queueLimit    int64
 	wg            sync.WaitGroup
+
+	// handoff queues the writes on disk while the destinations are down.
+	handoff     *coordinator.HandoffQueue
+	handoffDir  string
+	handoffTags models.StatisticTags
+	closing     chan struct{}
 }
 
-func newChanWriter(s *Service, sub PointsWriter) *chanWriter {
+func newChanWriter(s *Service, se subEntry, sub PointsWriter) (*chanWriter, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cw := &chanWriter{
 		writeRequests: make(chan WriteRequest, s.conf.WriteBufferSize),
@@ -433,6 +514,31 @@ func newChanWriter(s *Service, sub PointsWriter) *chanWriter {
 		failures:      &s.stats.WriteFailures,
 		logger:        s.Logger,
 	}
+
+	if s.conf.HintedHandoffEnabled {
+		// The names are escaped as they may contain path separators.
+		cw.handoffDir = filepath.Join(s.conf.HintedHandoffDir, url.PathEscape(se.db), url.PathEscape(se.rp), url.PathEscape(se.name))
+		cw.handoffTags = models.StatisticTags{"database": se.db, "retention_policy": se.rp, "name": se.name}
+		h, err := coordinator.OpenHandoffQueue(coordinator.HandoffConfig{
+			Dir:           cw.handoffDir,
+			MaxSize:       int64(s.conf.HintedHandoffMaxSize),
+			RetryInterval: time.Duration(s.conf.HintedHandoffRetryInterval),
+			MaxDrainRate:  s.conf.HintedHandoffMaxDrainRate,
+		}, s.Logger.With(zap.String("subscription", se.name)))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		cw.handoff = h
+		cw.closing = make(chan struct{})
+		cw.wg.Add(1)
+		go func() {
+			defer cw.wg.Done()
+			cw.handoff.Drain(cw.closing, cw.send)
+		}()
+		return cw, nil
+	}
+
 	for i := 0; i < s.conf.WriteConcurrency; i++ {
 		cw.wg.Add(1)
 		go func() {
@@ -440,12 +546,20 @@ func newChanWriter(s *Service, sub PointsWriter) *chanWriter {
 			cw.Run()
 		}()
 	}
-	return cw
+	return cw, nil
 }
 
 // Write is on the hot path for data ingest (to the whole database, not just subscriptions).
 // Be extra careful about latency.
 func (c *chanWriter) Write(wr WriteRequest) {
+	if c.handoff != nil {
+		wr = wr.nanoseconds()
+		if err := c.handoff.Append(wr.Database, wr.RetentionPolicy, wr.Length(), wr.lineProtocol); err != nil {
+			atomic.AddInt64(c.failures, 1)
+		}
+		return
+	}
+
 	sz := wr.SizeOf()
 	newSize := atomic.AddInt64(&c.queueSize, int64(sz))
 	limit := atomic.LoadInt64(&c.queueLimit)
@@ -472,16 +586,65 @@ func (c *chanWriter) limitTo(newLimit int64) {
 	// since they should be shortly evicted in normal operation.
 }
 
+// CancelAndClose cancels the in-flight write requests and closes the chanWriter.
+// The writes queued for the subscription, which was dropped, are removed.
 func (c *chanWriter) CancelAndClose() {
-	close(c.writeRequests)
 	c.cancel()
-	c.wg.Wait()
+	c.Close()
+	if c.handoff != nil {
+		if err := os.RemoveAll(c.handoffDir); err != nil {
+			c.logger.Info("Failed to remove the hinted handoff queue", zap.String("path", c.handoffDir), zap.Error(err))
+		}
+	}
 }
 
 // Close closes the chanWriter. It blocks until all the in-flight write requests are finished.
+// The writes left in the hinted handoff queue are sent once the subscription is created again.
 func (c *chanWriter) Close() {
 	close(c.writeRequests)
+	if c.handoff != nil {
+		close(c.closing)
+	}
 	c.wg.Wait()
+	if c.handoff != nil {
+		c.handoff.Close()
+	}
+}
+
+// send sends the writes of the hinted handoff queue, which are all to the
+// database and retention policy of the subscription, in a single request.
+func (c *chanWriter) send(writes []coordinator.HandoffWrite) error {
+	var lineProtocol []byte
+	for _, w := range writes {
+		lineProtocol = append(lineProtocol, w.Data...)
+	}
+	wr := newWriteRequestFromLines(writes[0].Database, writes[0].RetentionPolicy, lineProtocol)
+	if err := c.pw.WritePointsContext(c.ctx, wr); err != nil {
+		atomic.AddInt64(c.failures, 1)
+		return err
+	}
+	atomic.AddInt64(c.pointsWritten, int64(wr.Length()))
+	return nil
+}
+
+// rejectedError is returned by the points writers when the destination
+// rejects the points of a write. The hinted handoff queue drops the write
+// rather than sending it again.
+type rejectedError struct {
+	err error
+}
+
+func (e *rejectedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rejectedError) Unwrap() error {
+	return e.err
+}
+
+// Is reports that the write is rejected to the hinted handoff queue.
+func (e *rejectedError) Is(target error) bool {
+	return target == coordinator.ErrHandoffRejected
 }
 
 func (c *chanWriter) Run() {
@@ -499,10 +662,14 @@ func (c *chanWriter) Run() {
 
 // Statistics returns statistics for periodic monitoring.
 func (c *chanWriter) Statistics(tags map[string]string) []models.Statistic {
+	statistics := []models.Statistic{}
 	if m, ok := c.pw.(monitor.Reporter); ok {
-		return m.Statistics(tags)
+		statistics = m.Statistics(tags)
+	}
+	if c.handoff != nil {
+		statistics = append(statistics, c.handoff.Statistic("subscriberHintedHandoff", c.handoffTags.Merge(tags)))
 	}
-	return []models.Statistic{}
+	return statistics
 }
 
 // BalanceMode specifies what balance mode to use on a subscription.
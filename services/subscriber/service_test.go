@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/influxdata/influxdb/pkg/testing/assert"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/services/subscriber"
+	"github.com/influxdata/influxdb/toml"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
@@ -669,3 +671,229 @@ func verifyNonUTF8Removal(t *testing.T, pointString string, s *subscriber.Servic
 		}
 	}
 }
+
+// Ensure the writes of a subscription are queued on disk while its
+// destination is down and sent once it is back.
+func TestService_HintedHandoff(t *testing.T) {
+	dataChanged := make(chan struct{})
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return dataChanged
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ANY", Destinations: []string{"udp://h0:9093"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	down := make(chan struct{})
+	prs := make(chan subscriber.WriteRequest, 2)
+	newPointsWriter := func(u url.URL) (subscriber.PointsWriter, error) {
+		sub := Subscription{}
+		sub.WritePointsFn = func(p subscriber.WriteRequest) error {
+			select {
+			case <-down:
+			default:
+				return fmt.Errorf("destination down")
+			}
+			prs <- p
+			return nil
+		}
+		return sub, nil
+	}
+
+	c := subscriber.NewConfig()
+	c.HintedHandoffEnabled = true
+	c.HintedHandoffDir = t.TempDir()
+	c.HintedHandoffRetryInterval = toml.Duration(10 * time.Millisecond)
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = newPointsWriter
+	s.Open()
+	defer s.Close()
+
+	pt := models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0}, time.Unix(0, 1))
+	for i := 0; i < 2; i++ {
+		s.Send(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: []models.Point{pt}})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(down)
+
+	// The writes queued while the destination was down are sent together.
+	var pr subscriber.WriteRequest
+	select {
+	case pr = <-prs:
+	case <-time.After(testTimeout):
+		t.Fatal("expected points request")
+	}
+	if pr.Database != "db0" || pr.RetentionPolicy != "rp0" || pr.Length() != 2 || string(pr.PointAt(1)) != "cpu value=1 1\n" {
+		t.Fatalf("unexpected points request: %+v", pr)
+	}
+
+	var found bool
+	for _, st := range s.Statistics(nil) {
+		if st.Name == "subscriberHintedHandoff" {
+			found = true
+			if st.Tags["name"] != "s0" || st.Values["writeError"].(int64) == 0 {
+				t.Fatalf("unexpected statistics: %v", st)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("missing hinted handoff statistics")
+	}
+	close(dataChanged)
+}
+
+// Ensure the writes that the destination rejects are dropped from the hinted
+// handoff queue instead of blocking the later writes.
+func TestService_HintedHandoff_Rejected(t *testing.T) {
+	bodies := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		if strings.Contains(string(b), "bad") {
+			http.Error(w, `{"error":"partial write: field type conflict"}`, http.StatusBadRequest)
+			return
+		}
+		bodies <- string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	dataChanged := make(chan struct{})
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return dataChanged
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ANY", Destinations: []string{server.URL}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	c := subscriber.NewConfig()
+	c.HintedHandoffEnabled = true
+	c.HintedHandoffDir = t.TempDir()
+	c.HintedHandoffRetryInterval = toml.Duration(10 * time.Millisecond)
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.Open()
+	defer s.Close()
+
+	s.Send(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: []models.Point{
+		models.MustNewPoint("bad", nil, models.Fields{"value": 1.0}, time.Unix(0, 1)),
+	}})
+	time.Sleep(50 * time.Millisecond)
+	s.Send(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: []models.Point{
+		models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0}, time.Unix(2, 0)),
+	}})
+
+	// The writes are queued in nanoseconds.
+	select {
+	case b := <-bodies:
+		if b != "cpu value=1 2000000000\n" {
+			t.Fatalf("unexpected body: %q", b)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("expected the write after the rejected write")
+	}
+
+	for _, st := range s.Statistics(nil) {
+		if st.Name == "subscriberHintedHandoff" && st.Values["writeDropped"].(int64) != 1 {
+			t.Fatalf("unexpected statistics: %v", st)
+		}
+	}
+	close(dataChanged)
+}
+
+// Ensure the points are forwarded in the coarsest precision that keeps their
+// timestamps exact.
+func TestService_Precision(t *testing.T) {
+	type write struct {
+		precision string
+		body      string
+	}
+	writes := make(chan write, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		writes <- write{precision: req.URL.Query().Get("precision"), body: string(b)}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	dataChanged := make(chan struct{})
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return dataChanged
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ANY", Destinations: []string{server.URL}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	s := subscriber.NewService(subscriber.NewConfig())
+	s.MetaClient = ms
+	s.Open()
+	defer s.Close()
+
+	for _, tt := range []struct {
+		times []time.Time
+		exp   write
+	}{
+		{
+			times: []time.Time{time.Unix(60, 0), time.Unix(1, 500_000_000)},
+			exp:   write{precision: "ms", body: "cpu value=1 60000\ncpu value=1 1500\n"},
+		},
+		{
+			times: []time.Time{time.Unix(0, 1), time.Unix(7200, 0)},
+			exp:   write{precision: "n", body: "cpu value=1 1\ncpu value=1 7200000000000\n"},
+		},
+	} {
+		var points []models.Point
+		for _, tm := range tt.times {
+			points = append(points, models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0}, tm))
+		}
+		s.Send(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points})
+
+		select {
+		case w := <-writes:
+			if w != tt.exp {
+				t.Fatalf("unexpected write: got %+v, exp %+v", w, tt.exp)
+			}
+		case <-time.After(testTimeout):
+			t.Fatal("expected write")
+		}
+	}
+	close(dataChanged)
+}
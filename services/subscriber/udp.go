@@ -2,6 +2,7 @@ package subscriber
 
 import (
 	"context"
+	"fmt"
 	"net"
 )
 
@@ -30,11 +31,16 @@ func (u *UDP) WritePointsContext(_ context.Context, request WriteRequest) (err e
 	}
 	defer con.Close()
 
+	request = request.nanoseconds()
 	for i := range request.pointOffsets {
 		// write the point without the trailing newline
 		pointRaw := request.PointAt(i)
 		_, err = con.Write(pointRaw[:pointRaw[len(pointRaw)-1]])
-		if err != nil {
+		if err != nil && i > 0 {
+			// The points before were sent, so sending the request again
+			// would write them twice.
+			return &rejectedError{err: fmt.Errorf("partial write: %d of %d points sent: %w", i, len(request.pointOffsets), err)}
+		} else if err != nil {
 			return
 		}
 	}
@@ -0,0 +1,97 @@
+package udf
+
+import (
+	"errors"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/pkg/wasm"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultDir is the default directory of the WebAssembly modules.
+	DefaultDir = "/var/lib/influxdb/udf"
+
+	// DefaultMaxInstructions is the default maximum number of instructions
+	// executed by one call of a function.
+	DefaultMaxInstructions = wasm.DefaultFuel
+
+	// DefaultMaxMemorySize is the default maximum size of the memory of a
+	// module.
+	DefaultMaxMemorySize = wasm.DefaultMaxMemoryPages * wasm.PageSize
+
+	// DefaultMaxCallDepth is the default maximum depth of nested calls.
+	DefaultMaxCallDepth = wasm.DefaultMaxCallDepth
+)
+
+// maxMemorySize is the size of the largest memory a module can address.
+const maxMemorySize = 1 << 32
+
+// Config represents the configuration of the user-defined functions.
+type Config struct {
+	Enabled         bool      `toml:"enabled"`
+	Dir             string    `toml:"dir"`
+	MaxInstructions int64     `toml:"max-instructions"`
+	MaxMemorySize   toml.Size `toml:"max-memory-size"`
+	MaxCallDepth    int       `toml:"max-call-depth"`
+}
+
+// NewConfig returns a new Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Enabled:         false,
+		Dir:             DefaultDir,
+		MaxInstructions: DefaultMaxInstructions,
+		MaxMemorySize:   toml.Size(DefaultMaxMemorySize),
+		MaxCallDepth:    DefaultMaxCallDepth,
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Dir == "" {
+		return errors.New("dir must be specified")
+	}
+	if c.MaxInstructions <= 0 {
+		return errors.New("max-instructions must be positive")
+	}
+	if c.MaxMemorySize < wasm.PageSize {
+		return errors.New("max-memory-size must be at least 64KiB")
+	} else if c.MaxMemorySize > maxMemorySize {
+		return errors.New("max-memory-size must not exceed 4GiB")
+	}
+	if c.MaxCallDepth <= 0 {
+		return errors.New("max-call-depth must be positive")
+	}
+	return nil
+}
+
+// limits returns the limits of the instances of the modules.
+func (c Config) limits() wasm.Limits {
+	return wasm.Limits{
+		Fuel:           c.MaxInstructions,
+		MaxMemoryPages: uint32(c.MaxMemorySize / wasm.PageSize),
+		MaxCallDepth:   c.MaxCallDepth,
+	}
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Enabled {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+		}), nil
+	}
+
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":          true,
+		"dir":              c.Dir,
+		"max-instructions": c.MaxInstructions,
+		"max-memory-size":  c.MaxMemorySize,
+		"max-call-depth":   c.MaxCallDepth,
+	}), nil
+}
@@ -0,0 +1,76 @@
+package udf_test
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/services/udf"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c udf.Config
+	if _, err := toml.Decode(`
+enabled = true
+dir = "/tmp/udf"
+max-instructions = 1000
+max-memory-size = "2m"
+max-call-depth = 10
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled state: %v", c.Enabled)
+	} else if c.Dir != "/tmp/udf" {
+		t.Fatalf("unexpected dir: %s", c.Dir)
+	} else if c.MaxInstructions != 1000 {
+		t.Fatalf("unexpected max instructions: %d", c.MaxInstructions)
+	} else if c.MaxMemorySize != 2<<20 {
+		t.Fatalf("unexpected max memory size: %d", c.MaxMemorySize)
+	} else if c.MaxCallDepth != 10 {
+		t.Fatalf("unexpected max call depth: %d", c.MaxCallDepth)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := udf.NewConfig()
+	c.Enabled = true
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation fail from NewConfig: %s", err)
+	}
+
+	c = udf.NewConfig()
+	c.Enabled = true
+	c.Dir = ""
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for empty dir, got nil")
+	}
+
+	c = udf.NewConfig()
+	c.Enabled = true
+	c.MaxInstructions = 0
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for max-instructions = 0, got nil")
+	}
+
+	c = udf.NewConfig()
+	c.Enabled = true
+	c.MaxMemorySize = 1024
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for max-memory-size smaller than a page, got nil")
+	}
+
+	c = udf.NewConfig()
+	c.Enabled = true
+	c.MaxCallDepth = -1
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for negative max-call-depth, got nil")
+	}
+
+	c.Enabled = false
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation fail from disabled config: %s", err)
+	}
+}
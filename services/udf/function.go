@@ -0,0 +1,153 @@
+package udf
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/influxdata/influxdb/pkg/wasm"
+	"github.com/influxdata/influxql"
+)
+
+// Function is a function exported by a WebAssembly module.
+//
+// Integer parameters accept integers and booleans and float parameters
+// accept floats and integers. Integer results are returned as integers and
+// float results are returned as floats. The result is nil if an argument is
+// nil or has an unsupported type.
+type Function struct {
+	name string
+	typ  wasm.FuncType
+	pool *instancePool
+}
+
+// Name returns the name of the function.
+func (fn *Function) Name() string {
+	return fn.name
+}
+
+// CallType returns the type of the result of the function.
+func (fn *Function) CallType(args []influxql.DataType) (influxql.DataType, error) {
+	if exp, got := len(fn.typ.Params), len(args); exp != got {
+		return influxql.Unknown, fmt.Errorf("invalid number of arguments for %s, expected %d, got %d", fn.name, exp, got)
+	}
+	for i, typ := range fn.typ.Params {
+		switch args[i] {
+		case influxql.Unknown, influxql.Integer, influxql.Unsigned:
+			continue
+		case influxql.Boolean:
+			if typ == wasm.I32 || typ == wasm.I64 {
+				continue
+			}
+		case influxql.Float:
+			if typ == wasm.F32 || typ == wasm.F64 {
+				continue
+			}
+		default:
+		}
+		return influxql.Unknown, fmt.Errorf("invalid argument type for argument %d in %s(): %s", i+1, fn.name, args[i])
+	}
+
+	switch fn.typ.Results[0] {
+	case wasm.F32, wasm.F64:
+		return influxql.Float, nil
+	default:
+		return influxql.Integer, nil
+	}
+}
+
+// Call calls the function with the arguments.
+func (fn *Function) Call(args []interface{}) (interface{}, error) {
+	if len(args) != len(fn.typ.Params) {
+		return nil, nil
+	}
+	values := make([]uint64, len(args))
+	for i, typ := range fn.typ.Params {
+		v, ok := encode(typ, args[i])
+		if !ok {
+			return nil, nil
+		}
+		values[i] = v
+	}
+
+	inst, err := fn.pool.get()
+	if err != nil {
+		return nil, err
+	}
+	result, err := inst.Call(fn.name, values...)
+	if err != nil {
+		// The state of the instance is undefined after a trap.
+		return nil, err
+	}
+	fn.pool.put(inst)
+	return decode(fn.typ.Results[0], result), nil
+}
+
+// encode returns the bits of the WebAssembly value of the argument.
+func encode(typ wasm.ValueType, arg interface{}) (uint64, bool) {
+	switch typ {
+	case wasm.I32, wasm.I64:
+		switch arg := arg.(type) {
+		case int64:
+			return uint64(arg), true
+		case uint64:
+			return arg, true
+		case bool:
+			if arg {
+				return 1, true
+			}
+			return 0, true
+		default:
+			return 0, false
+		}
+	default:
+		var f float64
+		switch arg := arg.(type) {
+		case float64:
+			f = arg
+		case int64:
+			f = float64(arg)
+		case uint64:
+			f = float64(arg)
+		default:
+			return 0, false
+		}
+		if typ == wasm.F32 {
+			return uint64(math.Float32bits(float32(f))), true
+		}
+		return math.Float64bits(f), true
+	}
+}
+
+// decode returns the value of the result.
+func decode(typ wasm.ValueType, v uint64) interface{} {
+	switch typ {
+	case wasm.I32:
+		return int64(int32(v))
+	case wasm.I64:
+		return int64(v)
+	case wasm.F32:
+		return float64(math.Float32frombits(uint32(v)))
+	default:
+		return math.Float64frombits(v)
+	}
+}
+
+// instancePool holds the idle instances of a module. An instance is only
+// used by one call at a time.
+type instancePool struct {
+	module *wasm.Module
+	limits wasm.Limits
+	pool   sync.Pool
+}
+
+func (p *instancePool) get() (*wasm.Instance, error) {
+	if inst, ok := p.pool.Get().(*wasm.Instance); ok {
+		return inst, nil
+	}
+	return wasm.NewInstance(p.module, p.limits)
+}
+
+func (p *instancePool) put(inst *wasm.Instance) {
+	p.pool.Put(inst)
+}
@@ -0,0 +1,157 @@
+// Package udf provides the service that loads the user-defined functions
+// called by queries.
+package udf // import "github.com/influxdata/influxdb/services/udf"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb/pkg/wasm"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+)
+
+// Service loads the functions exported by the WebAssembly modules in a
+// directory so they can be called in the fields of SELECT statements.
+type Service struct {
+	config Config
+
+	mu        sync.RWMutex
+	functions map[string]*Function
+
+	Logger *zap.Logger
+}
+
+// NewService returns an instance of the user-defined functions service.
+func NewService(c Config) *Service {
+	return &Service{
+		config: c,
+		Logger: zap.NewNop(),
+	}
+}
+
+// WithLogger sets the logger for the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "udf"))
+}
+
+// Open loads the modules in the directory of the service.
+func (s *Service) Open() error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	s.Logger.Info("Loading user-defined functions", zap.String("path", s.config.Dir))
+	functions, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.functions = functions
+	s.mu.Unlock()
+	return nil
+}
+
+// Close closes the service.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	s.functions = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// load loads the *.wasm files in the directory. A missing directory does not
+// define any functions.
+func (s *Service) load() (map[string]*Function, error) {
+	paths, err := filepath.Glob(filepath.Join(s.config.Dir, "*.wasm"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	functions := make(map[string]*Function)
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fns, err := NewFunctions(b, s.config.limits())
+		if err != nil {
+			return nil, fmt.Errorf("udf: %s: %s", filepath.Base(path), err)
+		}
+		for _, fn := range fns {
+			if _, ok := functions[fn.Name()]; ok {
+				return nil, fmt.Errorf("udf: %s: function %s() is already defined", filepath.Base(path), fn.Name())
+			}
+			functions[fn.Name()] = fn
+			s.Logger.Info("Loaded user-defined function",
+				zap.String("function", fn.Name()),
+				zap.String("path", path))
+		}
+	}
+	return functions, nil
+}
+
+// UserFunction returns the function with the name.
+func (s *Service) UserFunction(name string) query.UserFunction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if fn, ok := s.functions[name]; ok {
+		return fn
+	}
+	return nil
+}
+
+// Functions returns the names of the loaded functions.
+func (s *Service) Functions() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.functions))
+	for name := range s.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewFunctions returns the functions exported by the module. The functions
+// must have parameters and a result of numeric types and their names must
+// not be the names of the functions of the query engine.
+func NewFunctions(b []byte, limits wasm.Limits) ([]*Function, error) {
+	m, err := wasm.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	// Instantiate the module once so modules that cannot be instantiated
+	// within the limits are rejected when they are loaded.
+	inst, err := wasm.NewInstance(m, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &instancePool{module: m, limits: limits}
+	pool.put(inst)
+
+	names := m.Functions()
+	sort.Strings(names)
+	fns := make([]*Function, 0, len(names))
+	for _, name := range names {
+		typ, _ := m.Func(name)
+		if query.IsBuiltinFunction(name) {
+			return nil, fmt.Errorf("function %s() is a built-in function", name)
+		} else if name != strings.ToLower(name) {
+			return nil, fmt.Errorf("function %s() must have a lowercase name", name)
+		} else if len(typ.Results) != 1 {
+			return nil, fmt.Errorf("function %s() must return one value", name)
+		}
+
+		fns = append(fns, &Function{name: name, typ: typ, pool: pool})
+	}
+	return fns, nil
+}
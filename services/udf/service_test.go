@@ -0,0 +1,106 @@
+package udf_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/wasm"
+	"github.com/influxdata/influxdb/services/udf"
+	"github.com/influxdata/influxql"
+)
+
+// module exports double(f64) f64, which doubles its argument, and
+// spin(i64) i64, which never returns.
+var module = []byte("\x00\x61\x73\x6d\x01\x00\x00\x00\x01\x0b\x02\x60\x01\x7c\x01\x7c\x60\x01\x7e\x01\x7e" +
+	"\x03\x03\x02\x00\x01\x07\x11\x02\x06\x64\x6f\x75\x62\x6c\x65\x00\x00\x04\x73\x70\x69\x6e\x00\x01" +
+	"\x0a\x13\x02\x07\x00\x20\x00\x20\x00\xa0\x0b\x09\x00\x03\x40\x0c\x00\x0b\x42\x00\x0b")
+
+func NewService(t *testing.T, files map[string][]byte) *udf.Service {
+	t.Helper()
+	dir := t.TempDir()
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := udf.NewConfig()
+	c.Enabled = true
+	c.Dir = dir
+	c.MaxInstructions = 1000
+	return udf.NewService(c)
+}
+
+func TestService_Open(t *testing.T) {
+	s := NewService(t, map[string][]byte{"math.wasm": module, "README": []byte("ignored")})
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if got, exp := s.Functions(), []string{"double", "spin"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected functions: exp=%v got=%v", exp, got)
+	}
+	if fn := s.UserFunction("missing"); fn != nil {
+		t.Fatalf("unexpected function: %v", fn)
+	}
+
+	fn := s.UserFunction("double")
+	if typ, err := fn.CallType([]influxql.DataType{influxql.Integer}); err != nil || typ != influxql.Float {
+		t.Fatalf("unexpected type: %s, %v", typ, err)
+	}
+	if _, err := fn.CallType([]influxql.DataType{influxql.String}); err == nil || err.Error() != "invalid argument type for argument 1 in double(): string" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := fn.CallType(nil); err == nil || err.Error() != "invalid number of arguments for double, expected 1, got 0" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		arg interface{}
+		exp interface{}
+	}{
+		{arg: 1.5, exp: 3.0},
+		{arg: int64(2), exp: 4.0},
+		{arg: nil, exp: nil},
+		{arg: "x", exp: nil},
+	} {
+		if got, err := fn.Call([]interface{}{tt.arg}); err != nil {
+			t.Errorf("double(%v): unexpected error: %s", tt.arg, err)
+		} else if got != tt.exp {
+			t.Errorf("double(%v): unexpected result: exp=%v got=%v", tt.arg, tt.exp, got)
+		}
+	}
+
+	spin := s.UserFunction("spin")
+	if typ, err := spin.CallType([]influxql.DataType{influxql.Boolean}); err != nil || typ != influxql.Integer {
+		t.Fatalf("unexpected type: %s, %v", typ, err)
+	}
+	if _, err := spin.Call([]interface{}{int64(1)}); err != wasm.ErrFuelExhausted {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestService_Open_Duplicate(t *testing.T) {
+	s := NewService(t, map[string][]byte{"a.wasm": module, "b.wasm": module})
+	if err := s.Open(); err == nil || err.Error() != "udf: b.wasm: function double() is already defined" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestService_Open_Invalid(t *testing.T) {
+	s := NewService(t, map[string][]byte{"bad.wasm": []byte("not wasm")})
+	if err := s.Open(); err == nil || err.Error() != "udf: bad.wasm: wasm: invalid magic number" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewFunctions_Builtin(t *testing.T) {
+	// The module exports round(f64) f64.
+	b := []byte("\x00\x61\x73\x6d\x01\x00\x00\x00\x01\x06\x01\x60\x01\x7c\x01\x7c\x03\x02\x01\x00\x07\x09\x01\x05\x72\x6f\x75\x6e\x64\x00\x00\x0a\x06\x01\x04\x00\x20\x00\x0b")
+	if _, err := udf.NewFunctions(b, wasm.Limits{}); err == nil || err.Error() != "function round() is a built-in function" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
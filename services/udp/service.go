@@ -216,12 +216,16 @@ func (s *Service) serve() {
 func (s *Service) parser() {
 	defer s.wg.Done()
 
+	// The points are handed over to the batcher one by one, so the slice
+	// holding them is reused between packets.
+	var points []models.Point
 	for {
 		select {
 		case <-s.done:
 			return
 		case buf := <-s.parserChan:
-			points, err := models.ParsePointsWithPrecision(buf, time.Now().UTC(), s.config.Precision)
+			var err error
+			points, err = models.ParsePointsAppend(points[:0], buf, time.Now().UTC(), s.config.Precision)
 			if err != nil {
 				atomic.AddInt64(&s.stats.PointsParseFail, 1)
 				s.Logger.Info("Failed to parse points", zap.Error(err))
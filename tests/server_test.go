@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -27,10 +28,16 @@ import (
 	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/flux/stdlib"
 	"github.com/influxdata/influxdb/cmd/influx/cli"
+	"github.com/influxdata/influxdb/cmd/influxd/schema"
 	"github.com/influxdata/influxdb/coordinator"
 	fluxClient "github.com/influxdata/influxdb/flux/client"
 	_ "github.com/influxdata/influxdb/flux/init/static"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/ldap"
+	"github.com/influxdata/influxdb/pkg/ldap/ldaptest"
+	"github.com/influxdata/influxdb/services/httpd"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/assert"
@@ -817,6 +824,60 @@ func TestServer_Write_LineProtocol_Partial(t *testing.T) {
 	}
 }
 
+// Ensure a partial write reports the lines of the dropped points and why they
+// were dropped.
+func TestServer_Write_PartialWriteResult(t *testing.T) {
+	t.Parallel()
+	c := NewConfig()
+	c.Coordinator.MaxFutureWrite = toml.Duration(time.Hour)
+	s := OpenServer(c)
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	now := now()
+	if _, err := s.Write("db0", "rp0", "cpu value=0i "+strconv.FormatInt(now.Add(-time.Second).UnixNano(), 10), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		"cpu value=1i " + strconv.FormatInt(now.UnixNano(), 10),
+		"cpu value=2 " + strconv.FormatInt(now.UnixNano(), 10),
+		"cpu value= " + strconv.FormatInt(now.UnixNano(), 10),
+		"cpu value=4i " + strconv.FormatInt(now.Add(2*time.Hour).UnixNano(), 10),
+		"cpu value=5i " + strconv.FormatInt(now.Add(time.Second).UnixNano(), 10),
+	}
+	_, err := s.Write("db0", "rp0", strings.Join(writes, "\n"), nil)
+	wr, ok := err.(WriteError)
+	if !ok || wr.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var resp httpd.Response
+	if err := json.Unmarshal([]byte(wr.Body()), &resp); err != nil {
+		t.Fatal(err)
+	} else if resp.PartialWrite == nil || resp.PartialWrite.Dropped != 3 || len(resp.PartialWrite.Points) != 3 {
+		t.Fatalf("unexpected body: %s", wr.Body())
+	}
+	for i, exp := range []httpd.PartialWritePoint{
+		{Line: 2, Reason: tsdb.DropReasonTypeConflict},
+		{Line: 3, Reason: tsdb.DropReasonParseError},
+		{Line: 4, Reason: tsdb.DropReasonFutureTimestamp},
+	} {
+		if got := resp.PartialWrite.Points[i]; got.Line != exp.Line || got.Reason != exp.Reason || got.Message == "" {
+			t.Fatalf("unexpected dropped point %d: %+v", i, got)
+		}
+	}
+
+	if res, err := s.Query(`SELECT count(value) FROM db0.rp0.cpu`); err != nil {
+		t.Fatal(err)
+	} else if exp := `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["1970-01-01T00:00:00Z",3]]}]}]}`; exp != res {
+		t.Fatalf("unexpected results\nexp: %s\ngot: %s\n", exp, res)
+	}
+}
+
 // Ensure the server can query with default databases (via param) and default retention policy
 func TestServer_Query_DefaultDBAndRP(t *testing.T) {
 	t.Parallel()
@@ -4346,6 +4407,123 @@ func TestServer_Query_Aggregates_Math(t *testing.T) {
 	}
 }
 
+// Ensure the server drops a cached result when points are written into the
+// time range of the statement.
+func TestServer_Query_QueryCache(t *testing.T) {
+	if RemoteEnabled() {
+		t.Skip("Skipping.  Cannot enable the query cache of a remote server")
+	}
+	t.Parallel()
+	c := NewConfig()
+	c.Coordinator.QueryCacheDatabases = []string{"db0"}
+	s := OpenServer(c)
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	params := url.Values{"db": []string{"db0"}}
+	command := `SELECT sum(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T01:00:00Z'`
+	for i, tt := range []struct {
+		write string
+		exp   string
+	}{
+		{
+			write: fmt.Sprintf(`cpu value=1 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+			exp:   `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","sum"],"values":[["2000-01-01T00:00:00Z",1]]}]}]}`,
+		},
+		{
+			exp: `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","sum"],"values":[["2000-01-01T00:00:00Z",1]]}]}]}`,
+		},
+		{
+			write: fmt.Sprintf(`cpu value=2 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:30:00Z").UnixNano()),
+			exp:   `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","sum"],"values":[["2000-01-01T00:00:00Z",3]]}]}]}`,
+		},
+	} {
+		if tt.write != "" {
+			s.MustWrite("db0", "rp0", tt.write, nil)
+		}
+		if got, err := s.QueryWithParams(command, params); err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		} else if got != tt.exp {
+			t.Fatalf("%d. unexpected results\nexp: %s\ngot: %s", i, tt.exp, got)
+		}
+	}
+
+	// The second query is answered from the cache.
+	exp := `{"results":[{"statement_id":0,"series":[{"name":"queryCache","columns":["entries","evictions","hits","invalidations","misses"],"values":[[1,0,1,1,2]]}]}]}`
+	if got, err := s.Query(`SHOW STATS FOR 'queryCache'`); err != nil {
+		t.Fatal(err)
+	} else if got != exp {
+		t.Fatalf("unexpected statistics\nexp: %s\ngot: %s", exp, got)
+	}
+}
+
+func TestServer_Query_Aggregates_Having(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`cpu,host=server01 value=90 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server01 value=80 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server02 value=20 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server02 value=30 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server03 value=85 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server03 value=95 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "having on aggregate",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT mean(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:01:00Z' GROUP BY host HAVING mean(value) > 80`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":"server01"},"columns":["time","mean"],"values":[["2000-01-01T00:00:00Z",85]]},{"name":"cpu","tags":{"host":"server03"},"columns":["time","mean"],"values":[["2000-01-01T00:00:00Z",90]]}]}]}`,
+		},
+		&Query{
+			name:    "having on alias with bound parameter",
+			params:  url.Values{"db": []string{"db0"}, "params": []string{`{"threshold": 90}`}},
+			command: `SELECT max(value) AS peak FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:01:00Z' GROUP BY host HAVING peak >= $threshold`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":"server01"},"columns":["time","peak"],"values":[["2000-01-01T00:00:00Z",90]]},{"name":"cpu","tags":{"host":"server03"},"columns":["time","peak"],"values":[["2000-01-01T00:00:10Z",95]]}]}]}`,
+		},
+		&Query{
+			name:    "having filters every group",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT mean(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:01:00Z' GROUP BY host HAVING mean(value) > 100`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
 func TestServer_Query_AggregateSelectors(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
@@ -4888,13 +5066,13 @@ func TestServer_Query_TopBottomInt(t *testing.T) {
 			name:    "top - cpu - 3 values with limit 2",
 			params:  url.Values{"db": []string{"db0"}},
 			command: `SELECT TOP(value, 3) FROM cpu limit 2`,
-			exp:     `{"results":[{"statement_id":0,"error":"limit (3) in top function can not be larger than the LIMIT (2) in the select statement"}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","top"],"values":[["2000-01-01T01:00:10Z",7],["2000-01-01T02:00:10Z",9]]}]}]}`,
 		},
 		&Query{
 			name:    "bottom - cpu - 3 values with limit 2",
 			params:  url.Values{"db": []string{"db0"}},
 			command: `SELECT BOTTOM(value, 3) FROM cpu limit 2`,
-			exp:     `{"results":[{"statement_id":0,"error":"limit (3) in bottom function can not be larger than the LIMIT (2) in the select statement"}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","bottom"],"values":[["2000-01-01T00:00:00Z",2],["2000-01-01T00:00:10Z",3]]}]}]}`,
 		},
 		&Query{
 			name:    "top - cpu - hourly",
@@ -5114,6 +5292,90 @@ func TestServer_Query_TopBottomWriteTags(t *testing.T) {
 	}
 }
 
+// Ensure top() and bottom() return the other fields of the selected points and
+// apply the LIMIT to each group.
+func TestServer_Query_TopBottomFields(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`cpu,host=a,region=west value=1,other=10 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=a,region=west value=5,other=50 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=b,region=east value=3,other=30 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=b,region=east value=4,other=40 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=c,region=east value=9,other=90 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=c,region=east value=2,other=20 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:30Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "top - field argument",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT top(value, other, 2) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","top","other"],"values":[["2000-01-01T00:00:10Z",5,50],["2000-01-01T00:00:20Z",9,90]]}]}]}`,
+		},
+		&Query{
+			name:    "bottom - field argument",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT bottom(value, other, 2) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","bottom","other"],"values":[["2000-01-01T00:00:00Z",1,10],["2000-01-01T00:00:30Z",2,20]]}]}]}`,
+		},
+		&Query{
+			name:    "top - tag and field arguments",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT top(value, host, other, 2), region FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","top","host","other","region"],"values":[["2000-01-01T00:00:10Z",5,"a",50,"west"],["2000-01-01T00:00:20Z",9,"c",90,"east"]]}]}]}`,
+		},
+		&Query{
+			name:    "top - limit per group",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT top(value, 3), other FROM cpu GROUP BY region LIMIT 2`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"region":"east"},"columns":["time","top","other"],"values":[["2000-01-01T00:00:10Z",4,40],["2000-01-01T00:00:20Z",9,90]]},{"name":"cpu","tags":{"region":"west"},"columns":["time","top","other"],"values":[["2000-01-01T00:00:00Z",1,10],["2000-01-01T00:00:10Z",5,50]]}]}]}`,
+		},
+		&Query{
+			name:    "bottom - limit per group",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT bottom(value, 3), other FROM cpu GROUP BY region LIMIT 1`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"region":"east"},"columns":["time","bottom","other"],"values":[["2000-01-01T00:00:30Z",2,20]]},{"name":"cpu","tags":{"region":"west"},"columns":["time","bottom","other"],"values":[["2000-01-01T00:00:00Z",1,10]]}]}]}`,
+		},
+		&Query{
+			name:    "top - tag argument with limit per group",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT top(value, host, 3), other FROM cpu GROUP BY region LIMIT 1`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"region":"east"},"columns":["time","top","host","other"],"values":[["2000-01-01T00:00:20Z",9,"c",90]]},{"name":"cpu","tags":{"region":"west"},"columns":["time","top","host","other"],"values":[["2000-01-01T00:00:10Z",5,"a",50]]}]}]}`,
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP: %s", query.name)
+			}
+
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
 // Test various aggregates when different series only have data for the same timestamp.
 func TestServer_Query_Aggregates_IdenticalTime(t *testing.T) {
 	t.Parallel()
@@ -5570,7 +5832,7 @@ func TestServer_Query_SubqueryWithGroupBy(t *testing.T) {
 	}
 }
 
-func TestServer_Query_SubqueryMath(t *testing.T) {
+func TestServer_Query_WhereIn_ScalarSubquery(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -5580,9 +5842,11 @@ func TestServer_Query_SubqueryMath(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf("m0 f2=4,f3=2 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf("m0 f1=5,f3=8 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
-		fmt.Sprintf("m0 f1=5,f2=3,f3=6 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server01 value=1 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server01 value=2 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:01Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server02 value=3 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:02Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server03 value=6 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:03Z").UnixNano()),
+		fmt.Sprintf(`limits max=2.5 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
 	}
 	test := NewTest("db0", "rp0")
 	test.writes = Writes{
@@ -5591,19 +5855,56 @@ func TestServer_Query_SubqueryMath(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "SumThreeValues",
+			name:    "tag IN list",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT sum FROM (SELECT f1 + f2 + f3 AS sum FROM m0)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"m0","columns":["time","sum"],"values":[["2000-01-01T00:00:00Z",null],["2000-01-01T00:00:10Z",null],["2000-01-01T00:00:20Z",14]]}]}]}`,
+			command: `SELECT value FROM cpu WHERE host IN ('server01', 'server03')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2000-01-01T00:00:00Z",1],["2000-01-01T00:00:01Z",2],["2000-01-01T00:00:03Z",6]]}]}]}`,
+		},
+		&Query{
+			name:    "tag NOT IN list",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT value FROM cpu WHERE host NOT IN ('server01', 'server03')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2000-01-01T00:00:02Z",3]]}]}]}`,
+		},
+		&Query{
+			name:    "field IN list",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT value FROM cpu WHERE value IN (2, 3) AND time < '2000-01-01T00:00:10Z'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2000-01-01T00:00:01Z",2],["2000-01-01T00:00:02Z",3]]}]}]}`,
+		},
+		&Query{
+			name:    "scalar subquery",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT value FROM cpu WHERE value > (SELECT mean(value) FROM cpu)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2000-01-01T00:00:03Z",6]]}]}]}`,
+		},
+		&Query{
+			name:    "scalar subquery from another measurement",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT value FROM cpu WHERE value > (SELECT last(max) FROM limits) AND host IN ('server01', 'server02')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2000-01-01T00:00:02Z",3]]}]}]}`,
+		},
+		&Query{
+			name:    "scalar subquery with no rows",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT value FROM cpu WHERE value > (SELECT mean(value) FROM cpu WHERE host = 'server04')`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "scalar subquery with more than one value",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT value FROM cpu WHERE value > (SELECT mean(value) FROM cpu GROUP BY host)`,
+			exp:     `{"results":[{"statement_id":0,"error":"scalar subquery returned more than one value"}]}`,
 		},
 	}...)
 
-	if err := test.init(s); err != nil {
-		t.Fatalf("test init failed: %s", err)
-	}
-
-	for _, query := range test.queries {
+	for i, query := range test.queries {
 		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
 			if query.skip {
 				t.Skipf("SKIP:: %s", query.name)
 			}
@@ -5616,7 +5917,7 @@ func TestServer_Query_SubqueryMath(t *testing.T) {
 	}
 }
 
-func TestServer_Query_PercentileDerivative(t *testing.T) {
+func TestServer_Query_StringFunctions(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -5626,11 +5927,9 @@ func TestServer_Query_PercentileDerivative(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`counter value=12 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`counter value=34 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
-		fmt.Sprintf(`counter value=78 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
-		fmt.Sprintf(`counter value=89 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:30Z").UnixNano()),
-		fmt.Sprintf(`counter value=101 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:40Z").UnixNano()),
+		fmt.Sprintf(`logs,host=Server01 message="connection refused",code=1i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`logs,host=Server01 message="request served in 12ms",code=2i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:01Z").UnixNano()),
+		fmt.Sprintf(`logs,host=Server02 message="connection timeout",code=3i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:02Z").UnixNano()),
 	}
 	test := NewTest("db0", "rp0")
 	test.writes = Writes{
@@ -5639,19 +5938,62 @@ func TestServer_Query_PercentileDerivative(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "nth percentile of derivative",
+			name:    "upper and lower",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT percentile(derivative, 95) FROM (SELECT derivative(value, 1s) FROM counter) WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:00:50Z'`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"counter","columns":["time","percentile"],"values":[["2000-01-01T00:00:20Z",4.4]]}]}]}`,
+			command: `SELECT upper(message), lower(host) FROM logs WHERE code = 3`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"logs","columns":["time","upper","lower"],"values":[["2000-01-01T00:00:02Z","CONNECTION TIMEOUT","server02"]]}]}]}`,
+		},
+		&Query{
+			name:    "substr",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT substr(message, 0, 10) AS head, substr(message, -4) AS tail FROM logs`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"logs","columns":["time","head","tail"],"values":[["2000-01-01T00:00:00Z","connection","used"],["2000-01-01T00:00:01Z","request se","12ms"],["2000-01-01T00:00:02Z","connection","eout"]]}]}]}`,
+		},
+		&Query{
+			name:    "replace",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT replace(message, 'in (\\d+)ms', '($1 ms)') FROM logs WHERE code = 2`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"logs","columns":["time","replace"],"values":[["2000-01-01T00:00:01Z","request served (12 ms)"]]}]}]}`,
+		},
+		&Query{
+			name:    "match in projection",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT match(message, '^connection') FROM logs`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"logs","columns":["time","match"],"values":[["2000-01-01T00:00:00Z",true],["2000-01-01T00:00:01Z",false],["2000-01-01T00:00:02Z",true]]}]}]}`,
+		},
+		&Query{
+			name:    "match in condition",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT code FROM logs WHERE match(message, 'refused|timeout') AND time < '2000-01-01T00:00:02Z'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"logs","columns":["time","code"],"values":[["2000-01-01T00:00:00Z",1]]}]}]}`,
+		},
+		&Query{
+			name:    "lower on a tag in condition",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT code FROM logs WHERE lower(host) = 'server02'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"logs","columns":["time","code"],"values":[["2000-01-01T00:00:02Z",3]]}]}]}`,
+		},
+		&Query{
+			name:    "invalid pattern",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT code FROM logs WHERE match(message, '(')`,
+			exp:     `{"results":[{"statement_id":0,"error":"invalid pattern in match(): error parsing regexp: missing closing ): ` + "`(`" + `"}]}`,
+		},
+		&Query{
+			name:    "wrong number of arguments",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT substr(message) FROM logs`,
+			exp:     `{"results":[{"statement_id":0,"error":"invalid number of arguments for substr, expected at least 2 but no more than 3, got 1"}]}`,
 		},
 	}...)
 
-	if err := test.init(s); err != nil {
-		t.Fatalf("test init failed: %s", err)
-	}
-
-	for _, query := range test.queries {
+	for i, query := range test.queries {
 		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
 			if query.skip {
 				t.Skipf("SKIP:: %s", query.name)
 			}
@@ -5664,7 +6006,7 @@ func TestServer_Query_PercentileDerivative(t *testing.T) {
 	}
 }
 
-func TestServer_Query_UnderscoreMeasurement(t *testing.T) {
+func TestServer_Query_Histogram(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -5674,7 +6016,10 @@ func TestServer_Query_UnderscoreMeasurement(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`_cpu value=1i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`http,host=server01 latency="1:1,2:3,+Inf:4" %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`http,host=server02 latency="1:2,2:2,+Inf:3" %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`http,host=server01 latency="1:0,2:1,+Inf:2" %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:01:10Z").UnixNano()),
+		fmt.Sprintf(`http,host=server01 elapsed_ms=1.5 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:01:20Z").UnixNano()),
 	}
 	test := NewTest("db0", "rp0")
 	test.writes = Writes{
@@ -5683,134 +6028,44 @@ func TestServer_Query_UnderscoreMeasurement(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "select underscore with underscore prefix",
+			name:    "histogram_sum",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM _cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"_cpu","columns":["time","value"],"values":[["2000-01-01T00:00:00Z",1]]}]}]}`,
-		},
-	}...)
-
-	if err := test.init(s); err != nil {
-		t.Fatalf("test init failed: %s", err)
-	}
-
-	for _, query := range test.queries {
-		t.Run(query.name, func(t *testing.T) {
-			if query.skip {
-				t.Skipf("SKIP:: %s", query.name)
-			}
-			if err := query.Execute(s); err != nil {
-				t.Error(query.Error(err))
-			} else if !query.success() {
-				t.Error(query.failureMessage())
-			}
-		})
-	}
-}
-
-func TestServer_Write_Precision(t *testing.T) {
-	t.Parallel()
-	s := OpenServer(NewConfig())
-	defer s.Close()
-
-	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
-		t.Fatal(err)
-	}
-
-	writes := []struct {
-		write  string
-		params url.Values
-	}{
-		{
-			write: fmt.Sprintf("cpu_n0_precision value=1 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").UnixNano()),
-		},
-		{
-			write:  fmt.Sprintf("cpu_n1_precision value=1.1 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").UnixNano()),
-			params: url.Values{"precision": []string{"n"}},
-		},
-		{
-			write:  fmt.Sprintf("cpu_u_precision value=100 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Microsecond).UnixNano()/int64(time.Microsecond)),
-			params: url.Values{"precision": []string{"u"}},
-		},
-		{
-			write:  fmt.Sprintf("cpu_ms_precision value=200 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Millisecond).UnixNano()/int64(time.Millisecond)),
-			params: url.Values{"precision": []string{"ms"}},
-		},
-		{
-			write:  fmt.Sprintf("cpu_s_precision value=300 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Second).UnixNano()/int64(time.Second)),
-			params: url.Values{"precision": []string{"s"}},
-		},
-		{
-			write:  fmt.Sprintf("cpu_m_precision value=400 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Minute).UnixNano()/int64(time.Minute)),
-			params: url.Values{"precision": []string{"m"}},
-		},
-		{
-			write:  fmt.Sprintf("cpu_h_precision value=500 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Hour).UnixNano()/int64(time.Hour)),
-			params: url.Values{"precision": []string{"h"}},
-		},
-	}
-
-	test := NewTest("db0", "rp0")
-
-	test.addQueries([]*Query{
-		&Query{
-			name:    "point with nanosecond precision time - no precision specified on write",
-			command: `SELECT * FROM cpu_n0_precision`,
-			params:  url.Values{"db": []string{"db0"}},
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_n0_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56.789012345Z",1]]}]}]}`,
-		},
-		&Query{
-			name:    "point with nanosecond precision time",
-			command: `SELECT * FROM cpu_n1_precision`,
-			params:  url.Values{"db": []string{"db0"}},
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_n1_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56.789012345Z",1.1]]}]}]}`,
-		},
-		&Query{
-			name:    "point with microsecond precision time",
-			command: `SELECT * FROM cpu_u_precision`,
-			params:  url.Values{"db": []string{"db0"}},
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_u_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56.789012Z",100]]}]}]}`,
+			command: `SELECT histogram_sum(latency) FROM http WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:02:00Z' GROUP BY time(1m)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"http","columns":["time","histogram_sum"],"values":[["2000-01-01T00:00:00Z","1:3,2:5,+Inf:7"],["2000-01-01T00:01:00Z","1:0,2:1,+Inf:2"]]}]}]}`,
 		},
 		&Query{
-			name:    "point with millisecond precision time",
-			command: `SELECT * FROM cpu_ms_precision`,
+			name:    "histogram_quantile",
 			params:  url.Values{"db": []string{"db0"}},
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_ms_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56.789Z",200]]}]}]}`,
+			command: `SELECT histogram_quantile(latency, 0.5) AS p50, histogram_quantile(latency, 0.99) AS p99 FROM http WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:02:00Z' GROUP BY time(1m)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"http","columns":["time","p50","p99"],"values":[["2000-01-01T00:00:00Z",1.25,2],["2000-01-01T00:01:00Z",2,2]]}]}]}`,
 		},
 		&Query{
-			name:    "point with second precision time",
-			command: `SELECT * FROM cpu_s_precision`,
+			name:    "roll up histograms",
 			params:  url.Values{"db": []string{"db0"}},
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_s_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56Z",300]]}]}]}`,
+			command: `SELECT histogram_sum(latency) AS latency INTO http_1m FROM http WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:02:00Z' GROUP BY time(1m), *`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"result","columns":["time","written"],"values":[["1970-01-01T00:00:00Z",3]]}]}]}`,
 		},
 		&Query{
-			name:    "point with minute precision time",
-			command: `SELECT * FROM cpu_m_precision`,
+			name:    "histogram_quantile of the rollup",
 			params:  url.Values{"db": []string{"db0"}},
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_m_precision","columns":["time","value"],"values":[["2000-01-01T12:34:00Z",400]]}]}]}`,
+			command: `SELECT histogram_quantile(latency, 0.5) FROM http_1m WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:02:00Z' GROUP BY time(1m)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"http_1m","columns":["time","histogram_quantile"],"values":[["2000-01-01T00:00:00Z",1.25],["2000-01-01T00:01:00Z",2]]}]}]}`,
 		},
 		&Query{
-			name:    "point with hour precision time",
-			command: `SELECT * FROM cpu_h_precision`,
+			name:    "invalid field type",
 			params:  url.Values{"db": []string{"db0"}},
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_h_precision","columns":["time","value"],"values":[["2000-01-01T12:00:00Z",500]]}]}]}`,
+			command: `SELECT histogram_quantile(elapsed_ms, 0.5) FROM http`,
+			exp:     `{"results":[{"statement_id":0,"error":"invalid argument type for the first argument in histogram_quantile(): float"}]}`,
 		},
 	}...)
 
-	// we are doing writes that require parameter changes, so we are fighting the test harness a little to make this happen properly
-	for _, w := range writes {
-		test.writes = Writes{
-			&Write{data: w.write},
-		}
-		test.params = w.params
-		test.initialized = false
-		if err := test.init(s); err != nil {
-			t.Fatalf("test init failed: %s", err)
-		}
-	}
-
-	for _, query := range test.queries {
+	for i, query := range test.queries {
 		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
 			if query.skip {
 				t.Skipf("SKIP:: %s", query.name)
 			}
@@ -5823,7 +6078,7 @@ func TestServer_Write_Precision(t *testing.T) {
 	}
 }
 
-func TestServer_Query_Wildcards(t *testing.T) {
+func TestServer_Query_AsofJoin(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -5833,18 +6088,15 @@ func TestServer_Query_Wildcards(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`wildcard,region=us-east value=10 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`wildcard,region=us-east valx=20 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
-		fmt.Sprintf(`wildcard,region=us-east value=30,valx=40 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
-
-		fmt.Sprintf(`wgroup,region=us-east value=10.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`wgroup,region=us-east value=20.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
-		fmt.Sprintf(`wgroup,region=us-west value=30.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
-
-		fmt.Sprintf(`m1,region=us-east value=10.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`m2,host=server01 field=20.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:01Z").UnixNano()),
+		fmt.Sprintf(`status,host=server01 state="idle",version=1i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`status,host=server01 state="busy",version=2i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`status,host=server02 state="down",version=1i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:05Z").UnixNano()),
+		fmt.Sprintf(`events,host=server01 value=1 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:01Z").UnixNano()),
+		fmt.Sprintf(`events,host=server01 value=2 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`events,host=server01 value=3 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:15Z").UnixNano()),
+		fmt.Sprintf(`events,host=server02 value=4 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:02Z").UnixNano()),
+		fmt.Sprintf(`events,host=server02 value=5 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:06Z").UnixNano()),
 	}
-
 	test := NewTest("db0", "rp0")
 	test.writes = Writes{
 		&Write{data: strings.Join(writes, "\n")},
@@ -5852,86 +6104,41 @@ func TestServer_Query_Wildcards(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "wildcard",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM wildcard`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","region","value","valx"],"values":[["2000-01-01T00:00:00Z","us-east",10,null],["2000-01-01T00:00:10Z","us-east",null,20],["2000-01-01T00:00:20Z","us-east",30,40]]}]}]}`,
-		},
-		&Query{
-			name:    "wildcard with group by",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM wildcard GROUP BY *`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","tags":{"region":"us-east"},"columns":["time","value","valx"],"values":[["2000-01-01T00:00:00Z",10,null],["2000-01-01T00:00:10Z",null,20],["2000-01-01T00:00:20Z",30,40]]}]}]}`,
-		},
-		&Query{
-			name:    "GROUP BY queries",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT mean(value) FROM wgroup GROUP BY *`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wgroup","tags":{"region":"us-east"},"columns":["time","mean"],"values":[["1970-01-01T00:00:00Z",15]]},{"name":"wgroup","tags":{"region":"us-west"},"columns":["time","mean"],"values":[["1970-01-01T00:00:00Z",30]]}]}]}`,
-		},
-		&Query{
-			name:    "GROUP BY queries with time",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT mean(value) FROM wgroup WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:01:00Z' GROUP BY *,TIME(1m)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wgroup","tags":{"region":"us-east"},"columns":["time","mean"],"values":[["2000-01-01T00:00:00Z",15]]},{"name":"wgroup","tags":{"region":"us-west"},"columns":["time","mean"],"values":[["2000-01-01T00:00:00Z",30]]}]}]}`,
-		},
-		&Query{
-			name:    "wildcard and field in select",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT value, * FROM wildcard`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","value","region","value_1","valx"],"values":[["2000-01-01T00:00:00Z",10,"us-east",10,null],["2000-01-01T00:00:10Z",null,"us-east",null,20],["2000-01-01T00:00:20Z",30,"us-east",30,40]]}]}]}`,
-		},
-		&Query{
-			name:    "field and wildcard in select",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT value, * FROM wildcard`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","value","region","value_1","valx"],"values":[["2000-01-01T00:00:00Z",10,"us-east",10,null],["2000-01-01T00:00:10Z",null,"us-east",null,20],["2000-01-01T00:00:20Z",30,"us-east",30,40]]}]}]}`,
-		},
-		&Query{
-			name:    "field and wildcard in group by",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM wildcard GROUP BY region, *`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","tags":{"region":"us-east"},"columns":["time","value","valx"],"values":[["2000-01-01T00:00:00Z",10,null],["2000-01-01T00:00:10Z",null,20],["2000-01-01T00:00:20Z",30,40]]}]}]}`,
-		},
-		&Query{
-			name:    "wildcard and field in group by",
+			name:    "join on a tag",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM wildcard GROUP BY *, region`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","tags":{"region":"us-east"},"columns":["time","value","valx"],"values":[["2000-01-01T00:00:00Z",10,null],["2000-01-01T00:00:10Z",null,20],["2000-01-01T00:00:20Z",30,40]]}]}]}`,
+			command: `SELECT value, state FROM events ASOF JOIN status ON host`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"events","tags":{"host":"server01"},"columns":["time","value","state"],"values":[["2000-01-01T00:00:01Z",1,"idle"],["2000-01-01T00:00:10Z",2,"busy"],["2000-01-01T00:00:15Z",3,"busy"]]},{"name":"events","tags":{"host":"server02"},"columns":["time","value","state"],"values":[["2000-01-01T00:00:02Z",4,null],["2000-01-01T00:00:06Z",5,"down"]]}]}]}`,
 		},
 		&Query{
-			name:    "wildcard with multiple measurements",
+			name:    "join with a time range",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM m1, m2`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"m1","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:00Z",null,null,"us-east",10]]},{"name":"m2","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:01Z",20,"server01",null,null]]}]}]}`,
+			command: `SELECT value, state AS s, version FROM events ASOF JOIN db0.rp0.status ON host WHERE time >= '2000-01-01T00:00:05Z' AND time < '2000-01-01T00:00:12Z' AND host = 'server01'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"events","tags":{"host":"server01"},"columns":["time","value","s","version"],"values":[["2000-01-01T00:00:10Z",2,"busy",2]]}]}]}`,
 		},
 		&Query{
-			name:    "wildcard with multiple measurements via regex",
+			name:    "join without tags",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM /^m.*/`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"m1","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:00Z",null,null,"us-east",10]]},{"name":"m2","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:01Z",20,"server01",null,null]]}]}]}`,
+			command: `SELECT value, state FROM events ASOF JOIN status WHERE host = 'server02'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"events","columns":["time","value","state"],"values":[["2000-01-01T00:00:02Z",4,"idle"],["2000-01-01T00:00:06Z",5,"down"]]}]}]}`,
 		},
 		&Query{
-			name:    "wildcard with multiple measurements via regex and limit",
+			name:    "aggregate",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM db0../^m.*/ LIMIT 2`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"m1","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:00Z",null,null,"us-east",10]]},{"name":"m2","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:01Z",20,"server01",null,null]]}]}]}`,
+			command: `SELECT max(value) FROM events ASOF JOIN status ON host`,
+			exp:     `{"results":[{"statement_id":0,"error":"ASOF JOIN is only supported with raw queries"}]}`,
 		},
 	}...)
 
-	var once sync.Once
-	for _, query := range test.queries {
+	for i, query := range test.queries {
 		t.Run(query.name, func(t *testing.T) {
-			once.Do(func() {
+			if i == 0 {
 				if err := test.init(s); err != nil {
 					t.Fatalf("test init failed: %s", err)
 				}
-			})
-
+			}
 			if query.skip {
 				t.Skipf("SKIP:: %s", query.name)
 			}
-
 			if err := query.Execute(s); err != nil {
 				t.Error(query.Error(err))
 			} else if !query.success() {
@@ -5941,9 +6148,23 @@ func TestServer_Query_Wildcards(t *testing.T) {
 	}
 }
 
-func TestServer_Query_WildcardExpansion(t *testing.T) {
+func TestServer_Query_UserFunctions(t *testing.T) {
 	t.Parallel()
-	s := OpenServer(NewConfig())
+	c := NewConfig()
+	c.UDF.Enabled = true
+	c.UDF.Dir = c.rootPath
+	c.UDF.MaxInstructions = 10000
+
+	// The module exports double(f64) f64, which doubles its argument, and
+	// spin(i64) i64, which never returns.
+	module := []byte("\x00\x61\x73\x6d\x01\x00\x00\x00\x01\x0b\x02\x60\x01\x7c\x01\x7c\x60\x01\x7e\x01\x7e" +
+		"\x03\x03\x02\x00\x01\x07\x11\x02\x06\x64\x6f\x75\x62\x6c\x65\x00\x00\x04\x73\x70\x69\x6e\x00\x01" +
+		"\x0a\x13\x02\x07\x00\x20\x00\x20\x00\xa0\x0b\x09\x00\x03\x40\x0c\x00\x0b\x42\x00\x0b")
+	if err := os.WriteFile(filepath.Join(c.rootPath, "math.wasm"), module, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := OpenServer(c)
 	defer s.Close()
 
 	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
@@ -5951,16 +6172,9 @@ func TestServer_Query_WildcardExpansion(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`wildcard,region=us-east,host=A value=10,cpu=80 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`wildcard,region=us-east,host=B value=20,cpu=90 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
-		fmt.Sprintf(`wildcard,region=us-west,host=B value=30,cpu=70 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
-		fmt.Sprintf(`wildcard,region=us-east,host=A value=40,cpu=60 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:30Z").UnixNano()),
-
-		fmt.Sprintf(`dupnames,region=us-east,day=1 value=10,day=3i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`dupnames,region=us-east,day=2 value=20,day=2i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
-		fmt.Sprintf(`dupnames,region=us-west,day=3 value=30,day=1i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server01 value=1.5,count=2i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server01 value=4,count=3i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
 	}
-
 	test := NewTest("db0", "rp0")
 	test.writes = Writes{
 		&Write{data: strings.Join(writes, "\n")},
@@ -5968,35 +6182,28 @@ func TestServer_Query_WildcardExpansion(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "wildcard",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM wildcard`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","cpu","host","region","value"],"values":[["2000-01-01T00:00:00Z",80,"A","us-east",10],["2000-01-01T00:00:10Z",90,"B","us-east",20],["2000-01-01T00:00:20Z",70,"B","us-west",30],["2000-01-01T00:00:30Z",60,"A","us-east",40]]}]}]}`,
-		},
-		&Query{
-			name:    "no wildcard in select",
+			name:    "raw",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT cpu, host, region, value  FROM wildcard`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","cpu","host","region","value"],"values":[["2000-01-01T00:00:00Z",80,"A","us-east",10],["2000-01-01T00:00:10Z",90,"B","us-east",20],["2000-01-01T00:00:20Z",70,"B","us-west",30],["2000-01-01T00:00:30Z",60,"A","us-east",40]]}]}]}`,
+			command: `SELECT double(value), double(count) + 1 FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","double","double_1"],"values":[["2000-01-01T00:00:00Z",3,5],["2000-01-01T00:00:10Z",8,7]]}]}]}`,
 		},
 		&Query{
-			name:    "no wildcard in select, preserve column order",
+			name:    "aggregate",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT host, cpu, region, value  FROM wildcard`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","host","cpu","region","value"],"values":[["2000-01-01T00:00:00Z","A",80,"us-east",10],["2000-01-01T00:00:10Z","B",90,"us-east",20],["2000-01-01T00:00:20Z","B",70,"us-west",30],["2000-01-01T00:00:30Z","A",60,"us-east",40]]}]}]}`,
+			command: `SELECT double(max(value)) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","double"],"values":[["2000-01-01T00:00:10Z",8]]}]}]}`,
 		},
-
 		&Query{
-			name:    "no wildcard with alias",
+			name:    "fuel exhausted",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT cpu as c, host as h, region, value  FROM wildcard`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","c","h","region","value"],"values":[["2000-01-01T00:00:00Z",80,"A","us-east",10],["2000-01-01T00:00:10Z",90,"B","us-east",20],["2000-01-01T00:00:20Z",70,"B","us-west",30],["2000-01-01T00:00:30Z",60,"A","us-east",40]]}]}]}`,
+			command: `SELECT spin(count) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"error":"error calling spin(): wasm: fuel exhausted"}]}`,
 		},
 		&Query{
-			name:    "duplicate tag and field key",
-			command: `SELECT * FROM dupnames`,
+			name:    "invalid argument type",
 			params:  url.Values{"db": []string{"db0"}},
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"dupnames","columns":["time","day","day_1","region","value"],"values":[["2000-01-01T00:00:00Z",3,"1","us-east",10],["2000-01-01T00:00:10Z",2,"2","us-east",20],["2000-01-01T00:00:20Z",1,"3","us-west",30]]}]}]}`,
+			command: `SELECT spin(value) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"error":"invalid argument type for argument 1 in spin(): float"}]}`,
 		},
 	}...)
 
@@ -6019,7 +6226,7 @@ func TestServer_Query_WildcardExpansion(t *testing.T) {
 	}
 }
 
-func TestServer_Query_AcrossShardsAndFields(t *testing.T) {
+func TestServer_Query_SubqueryMath(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -6029,11 +6236,10 @@ func TestServer_Query_AcrossShardsAndFields(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`cpu load=100 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`cpu load=200 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`cpu core=4 %d`, mustParseTime(time.RFC3339Nano, "2015-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf("m0 f2=4,f3=2 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf("m0 f1=5,f3=8 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf("m0 f1=5,f2=3,f3=6 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
 	}
-
 	test := NewTest("db0", "rp0")
 	test.writes = Writes{
 		&Write{data: strings.Join(writes, "\n")},
@@ -6041,44 +6247,19 @@ func TestServer_Query_AcrossShardsAndFields(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "two results for cpu",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT load FROM cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2000-01-01T00:00:00Z",100],["2010-01-01T00:00:00Z",200]]}]}]}`,
-		},
-		&Query{
-			name:    "two results for cpu, multi-select",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT core,load FROM cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core","load"],"values":[["2000-01-01T00:00:00Z",null,100],["2010-01-01T00:00:00Z",null,200],["2015-01-01T00:00:00Z",4,null]]}]}]}`,
-		},
-		&Query{
-			name:    "two results for cpu, wildcard select",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT * FROM cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core","load"],"values":[["2000-01-01T00:00:00Z",null,100],["2010-01-01T00:00:00Z",null,200],["2015-01-01T00:00:00Z",4,null]]}]}]}`,
-		},
-		&Query{
-			name:    "one result for core",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT core FROM cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2015-01-01T00:00:00Z",4]]}]}]}`,
-		},
-		&Query{
-			name:    "empty result set from non-existent field",
+			name:    "SumThreeValues",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT foo FROM cpu`,
-			exp:     `{"results":[{"statement_id":0}]}`,
+			command: `SELECT sum FROM (SELECT f1 + f2 + f3 AS sum FROM m0)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"m0","columns":["time","sum"],"values":[["2000-01-01T00:00:00Z",null],["2000-01-01T00:00:10Z",null],["2000-01-01T00:00:20Z",14]]}]}]}`,
 		},
 	}...)
 
-	for i, query := range test.queries {
-		t.Run(query.name, func(t *testing.T) {
-			if i == 0 {
-				if err := test.init(s); err != nil {
-					t.Fatalf("test init failed: %s", err)
-				}
-			}
+	if err := test.init(s); err != nil {
+		t.Fatalf("test init failed: %s", err)
+	}
+
+	for _, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
 			if query.skip {
 				t.Skipf("SKIP:: %s", query.name)
 			}
@@ -6091,7 +6272,7 @@ func TestServer_Query_AcrossShardsAndFields(t *testing.T) {
 	}
 }
 
-func TestServer_Query_OrderedAcrossShards(t *testing.T) {
+func TestServer_Query_PercentileDerivative(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -6101,13 +6282,12 @@ func TestServer_Query_OrderedAcrossShards(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`cpu value=7 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`cpu value=14 %d`, mustParseTime(time.RFC3339Nano, "2010-01-08T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`cpu value=28 %d`, mustParseTime(time.RFC3339Nano, "2010-01-15T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`cpu value=56 %d`, mustParseTime(time.RFC3339Nano, "2010-01-22T00:00:00Z").UnixNano()),
-		fmt.Sprintf(`cpu value=112 %d`, mustParseTime(time.RFC3339Nano, "2010-01-29T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`counter value=12 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`counter value=34 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`counter value=78 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
+		fmt.Sprintf(`counter value=89 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:30Z").UnixNano()),
+		fmt.Sprintf(`counter value=101 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:40Z").UnixNano()),
 	}
-
 	test := NewTest("db0", "rp0")
 	test.writes = Writes{
 		&Write{data: strings.Join(writes, "\n")},
@@ -6115,38 +6295,19 @@ func TestServer_Query_OrderedAcrossShards(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "derivative",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT derivative(value, 24h) FROM cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","derivative"],"values":[["2010-01-08T00:00:00Z",1],["2010-01-15T00:00:00Z",2],["2010-01-22T00:00:00Z",4],["2010-01-29T00:00:00Z",8]]}]}]}`,
-		},
-		&Query{
-			name:    "non_negative_derivative",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT non_negative_derivative(value, 24h) FROM cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","non_negative_derivative"],"values":[["2010-01-08T00:00:00Z",1],["2010-01-15T00:00:00Z",2],["2010-01-22T00:00:00Z",4],["2010-01-29T00:00:00Z",8]]}]}]}`,
-		},
-		&Query{
-			name:    "difference",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT difference(value) FROM cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","difference"],"values":[["2010-01-08T00:00:00Z",7],["2010-01-15T00:00:00Z",14],["2010-01-22T00:00:00Z",28],["2010-01-29T00:00:00Z",56]]}]}]}`,
-		},
-		&Query{
-			name:    "cumulative_sum",
+			name:    "nth percentile of derivative",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT cumulative_sum(value) FROM cpu`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","cumulative_sum"],"values":[["2010-01-01T00:00:00Z",7],["2010-01-08T00:00:00Z",21],["2010-01-15T00:00:00Z",49],["2010-01-22T00:00:00Z",105],["2010-01-29T00:00:00Z",217]]}]}]}`,
+			command: `SELECT percentile(derivative, 95) FROM (SELECT derivative(value, 1s) FROM counter) WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:00:50Z'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"counter","columns":["time","percentile"],"values":[["2000-01-01T00:00:20Z",4.4]]}]}]}`,
 		},
 	}...)
 
-	for i, query := range test.queries {
+	if err := test.init(s); err != nil {
+		t.Fatalf("test init failed: %s", err)
+	}
+
+	for _, query := range test.queries {
 		t.Run(query.name, func(t *testing.T) {
-			if i == 0 {
-				if err := test.init(s); err != nil {
-					t.Fatalf("test init failed: %s", err)
-				}
-			}
 			if query.skip {
 				t.Skipf("SKIP:: %s", query.name)
 			}
@@ -6159,7 +6320,7 @@ func TestServer_Query_OrderedAcrossShards(t *testing.T) {
 	}
 }
 
-func TestServer_Query_Where_Fields(t *testing.T) {
+func TestServer_Query_UnderscoreMeasurement(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -6169,207 +6330,146 @@ func TestServer_Query_Where_Fields(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`cpu alert_id="alert",tenant_id="tenant",_cust="johnson brothers" %d`, mustParseTime(time.RFC3339Nano, "2015-02-28T01:03:36.703820946Z").UnixNano()),
-		fmt.Sprintf(`cpu alert_id="alert",tenant_id="tenant",_cust="johnson brothers" %d`, mustParseTime(time.RFC3339Nano, "2015-02-28T01:03:36.703820946Z").UnixNano()),
-
-		fmt.Sprintf(`cpu load=100.0,core=4 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
-		fmt.Sprintf(`cpu load=80.0,core=2 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:01:02Z").UnixNano()),
-
-		fmt.Sprintf(`clicks local=true %d`, mustParseTime(time.RFC3339Nano, "2014-11-10T23:00:01Z").UnixNano()),
-		fmt.Sprintf(`clicks local=false %d`, mustParseTime(time.RFC3339Nano, "2014-11-10T23:00:02Z").UnixNano()),
+		fmt.Sprintf(`_cpu value=1i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
 	}
-
 	test := NewTest("db0", "rp0")
 	test.writes = Writes{
 		&Write{data: strings.Join(writes, "\n")},
 	}
 
 	test.addQueries([]*Query{
-		// non type specific
 		&Query{
-			name:    "missing measurement with group by",
+			name:    "select underscore with underscore prefix",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT load from missing group by *`,
-			exp:     `{"results":[{"statement_id":0}]}`,
+			command: `SELECT * FROM _cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"_cpu","columns":["time","value"],"values":[["2000-01-01T00:00:00Z",1]]}]}]}`,
 		},
+	}...)
 
-		// string
-		&Query{
-			name:    "single string field",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT alert_id FROM cpu WHERE alert_id='alert'`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","alert_id"],"values":[["2015-02-28T01:03:36.703820946Z","alert"]]}]}]}`,
-		},
-		&Query{
-			name:    "string AND query, all fields in SELECT",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT alert_id,tenant_id,_cust FROM cpu WHERE alert_id='alert' AND tenant_id='tenant'`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","alert_id","tenant_id","_cust"],"values":[["2015-02-28T01:03:36.703820946Z","alert","tenant","johnson brothers"]]}]}]}`,
-		},
-		&Query{
-			name:    "string AND query, all fields in SELECT, one in parenthesis",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT alert_id,tenant_id FROM cpu WHERE alert_id='alert' AND (tenant_id='tenant')`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","alert_id","tenant_id"],"values":[["2015-02-28T01:03:36.703820946Z","alert","tenant"]]}]}]}`,
-		},
-		&Query{
-			name:    "string underscored field",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT alert_id FROM cpu WHERE _cust='johnson brothers'`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","alert_id"],"values":[["2015-02-28T01:03:36.703820946Z","alert"]]}]}]}`,
-		},
-		&Query{
-			name:    "string no match",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `SELECT alert_id FROM cpu WHERE _cust='acme'`,
-			exp:     `{"results":[{"statement_id":0}]}`,
-		},
+	if err := test.init(s); err != nil {
+		t.Fatalf("test init failed: %s", err)
+	}
 
-		// float64
-		&Query{
-			name:    "float64 GT no match",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load > 100`,
-			exp:     `{"results":[{"statement_id":0}]}`,
-		},
-		&Query{
-			name:    "float64 GTE match one",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load >= 100`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:00:02Z",100]]}]}]}`,
-		},
-		&Query{
-			name:    "float64 EQ match upper bound",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load = 100`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:00:02Z",100]]}]}]}`,
+	for _, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Write_Precision(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []struct {
+		write  string
+		params url.Values
+	}{
+		{
+			write: fmt.Sprintf("cpu_n0_precision value=1 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").UnixNano()),
 		},
-		&Query{
-			name:    "float64 LTE match two",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load <= 100`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:00:02Z",100],["2009-11-10T23:01:02Z",80]]}]}]}`,
+		{
+			write:  fmt.Sprintf("cpu_n1_precision value=1.1 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").UnixNano()),
+			params: url.Values{"precision": []string{"n"}},
 		},
-		&Query{
-			name:    "float64 GT match one",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load > 99`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:00:02Z",100]]}]}]}`,
+		{
+			write:  fmt.Sprintf("cpu_u_precision value=100 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Microsecond).UnixNano()/int64(time.Microsecond)),
+			params: url.Values{"precision": []string{"u"}},
 		},
-		&Query{
-			name:    "float64 EQ no match",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load = 99`,
-			exp:     `{"results":[{"statement_id":0}]}`,
+		{
+			write:  fmt.Sprintf("cpu_ms_precision value=200 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Millisecond).UnixNano()/int64(time.Millisecond)),
+			params: url.Values{"precision": []string{"ms"}},
 		},
-		&Query{
-			name:    "float64 LT match one",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load < 99`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:01:02Z",80]]}]}]}`,
+		{
+			write:  fmt.Sprintf("cpu_s_precision value=300 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Second).UnixNano()/int64(time.Second)),
+			params: url.Values{"precision": []string{"s"}},
 		},
-		&Query{
-			name:    "float64 LT no match",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load < 80`,
-			exp:     `{"results":[{"statement_id":0}]}`,
+		{
+			write:  fmt.Sprintf("cpu_m_precision value=400 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Minute).UnixNano()/int64(time.Minute)),
+			params: url.Values{"precision": []string{"m"}},
 		},
-		&Query{
-			name:    "float64 NE match one",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select load from cpu where load != 100`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:01:02Z",80]]}]}]}`,
+		{
+			write:  fmt.Sprintf("cpu_h_precision value=500 %d", mustParseTime(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z").Truncate(time.Hour).UnixNano()/int64(time.Hour)),
+			params: url.Values{"precision": []string{"h"}},
 		},
+	}
 
-		// int64
+	test := NewTest("db0", "rp0")
+
+	test.addQueries([]*Query{
 		&Query{
-			name:    "int64 GT no match",
+			name:    "point with nanosecond precision time - no precision specified on write",
+			command: `SELECT * FROM cpu_n0_precision`,
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core > 4`,
-			exp:     `{"results":[{"statement_id":0}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_n0_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56.789012345Z",1]]}]}]}`,
 		},
 		&Query{
-			name:    "int64 GTE match one",
+			name:    "point with nanosecond precision time",
+			command: `SELECT * FROM cpu_n1_precision`,
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core >= 4`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:00:02Z",4]]}]}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_n1_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56.789012345Z",1.1]]}]}]}`,
 		},
 		&Query{
-			name:    "int64 EQ match upper bound",
+			name:    "point with microsecond precision time",
+			command: `SELECT * FROM cpu_u_precision`,
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core = 4`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:00:02Z",4]]}]}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_u_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56.789012Z",100]]}]}]}`,
 		},
 		&Query{
-			name:    "int64 LTE match two ",
+			name:    "point with millisecond precision time",
+			command: `SELECT * FROM cpu_ms_precision`,
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core <= 4`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:00:02Z",4],["2009-11-10T23:01:02Z",2]]}]}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_ms_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56.789Z",200]]}]}]}`,
 		},
 		&Query{
-			name:    "int64 GT match one",
+			name:    "point with second precision time",
+			command: `SELECT * FROM cpu_s_precision`,
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core > 3`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:00:02Z",4]]}]}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_s_precision","columns":["time","value"],"values":[["2000-01-01T12:34:56Z",300]]}]}]}`,
 		},
 		&Query{
-			name:    "int64 EQ no match",
+			name:    "point with minute precision time",
+			command: `SELECT * FROM cpu_m_precision`,
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core = 3`,
-			exp:     `{"results":[{"statement_id":0}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_m_precision","columns":["time","value"],"values":[["2000-01-01T12:34:00Z",400]]}]}]}`,
 		},
 		&Query{
-			name:    "int64 LT match one",
+			name:    "point with hour precision time",
+			command: `SELECT * FROM cpu_h_precision`,
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core < 3`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:01:02Z",2]]}]}]}`,
-		},
-		&Query{
-			name:    "int64 LT no match",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core < 2`,
-			exp:     `{"results":[{"statement_id":0}]}`,
-		},
-		&Query{
-			name:    "int64 NE match one",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select core from cpu where core != 4`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:01:02Z",2]]}]}]}`,
-		},
-
-		// bool
-		&Query{
-			name:    "bool EQ match true",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select local from clicks where local = true`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"clicks","columns":["time","local"],"values":[["2014-11-10T23:00:01Z",true]]}]}]}`,
-		},
-		&Query{
-			name:    "bool EQ match false",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select local from clicks where local = false`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"clicks","columns":["time","local"],"values":[["2014-11-10T23:00:02Z",false]]}]}]}`,
-		},
-
-		&Query{
-			name:    "bool NE match one",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select local from clicks where local != true`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"clicks","columns":["time","local"],"values":[["2014-11-10T23:00:02Z",false]]}]}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu_h_precision","columns":["time","value"],"values":[["2000-01-01T12:00:00Z",500]]}]}]}`,
 		},
 	}...)
 
-	for i, query := range test.queries {
+	// we are doing writes that require parameter changes, so we are fighting the test harness a little to make this happen properly
+	for _, w := range writes {
+		test.writes = Writes{
+			&Write{data: w.write},
+		}
+		test.params = w.params
+		test.initialized = false
+		if err := test.init(s); err != nil {
+			t.Fatalf("test init failed: %s", err)
+		}
+	}
+
+	for _, query := range test.queries {
 		t.Run(query.name, func(t *testing.T) {
-			if i == 0 {
-				if err := test.init(s); err != nil {
-					t.Fatalf("test init failed: %s", err)
-				}
-			}
 			if query.skip {
 				t.Skipf("SKIP:: %s", query.name)
 			}
-
 			if err := query.Execute(s); err != nil {
 				t.Error(query.Error(err))
 			} else if !query.success() {
@@ -6379,7 +6479,7 @@ func TestServer_Query_Where_Fields(t *testing.T) {
 	}
 }
 
-func TestServer_Query_Where_With_Tags(t *testing.T) {
+func TestServer_Query_Wildcards(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -6389,11 +6489,16 @@ func TestServer_Query_Where_With_Tags(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`where_events,tennant=paul foo="bar" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
-		fmt.Sprintf(`where_events,tennant=paul foo="baz" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:03Z").UnixNano()),
-		fmt.Sprintf(`where_events,tennant=paul foo="bat" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:04Z").UnixNano()),
-		fmt.Sprintf(`where_events,tennant=todd foo="bar" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:05Z").UnixNano()),
-		fmt.Sprintf(`where_events,tennant=david foo="bap" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:06Z").UnixNano()),
+		fmt.Sprintf(`wildcard,region=us-east value=10 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`wildcard,region=us-east valx=20 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`wildcard,region=us-east value=30,valx=40 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
+
+		fmt.Sprintf(`wgroup,region=us-east value=10.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`wgroup,region=us-east value=20.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`wgroup,region=us-west value=30.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
+
+		fmt.Sprintf(`m1,region=us-east value=10.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`m2,host=server01 field=20.0 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:01Z").UnixNano()),
 	}
 
 	test := NewTest("db0", "rp0")
@@ -6403,40 +6508,70 @@ func TestServer_Query_Where_With_Tags(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "tag field and time",
+			name:    "wildcard",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from where_events where (tennant = 'paul' OR tennant = 'david') AND time > 1s AND (foo = 'bar' OR foo = 'baz' OR foo = 'bap')`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:06Z","bap"]]}]}]}`,
+			command: `SELECT * FROM wildcard`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","region","value","valx"],"values":[["2000-01-01T00:00:00Z","us-east",10,null],["2000-01-01T00:00:10Z","us-east",null,20],["2000-01-01T00:00:20Z","us-east",30,40]]}]}]}`,
 		},
 		&Query{
-			name:    "tag or field",
+			name:    "wildcard with group by",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from where_events where tennant = 'paul' OR foo = 'bar'`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:04Z","bat"],["2009-11-10T23:00:05Z","bar"]]}]}]}`,
+			command: `SELECT * FROM wildcard GROUP BY *`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","tags":{"region":"us-east"},"columns":["time","value","valx"],"values":[["2000-01-01T00:00:00Z",10,null],["2000-01-01T00:00:10Z",null,20],["2000-01-01T00:00:20Z",30,40]]}]}]}`,
 		},
 		&Query{
-			name:    "non-existent tag and field",
+			name:    "GROUP BY queries",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from where_events where tenant != 'paul' AND foo = 'bar'`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:05Z","bar"]]}]}]}`,
+			command: `SELECT mean(value) FROM wgroup GROUP BY *`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wgroup","tags":{"region":"us-east"},"columns":["time","mean"],"values":[["1970-01-01T00:00:00Z",15]]},{"name":"wgroup","tags":{"region":"us-west"},"columns":["time","mean"],"values":[["1970-01-01T00:00:00Z",30]]}]}]}`,
 		},
 		&Query{
-			name:    "non-existent tag or field",
+			name:    "GROUP BY queries with time",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from where_events where tenant != 'paul' OR foo = 'bar'`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:04Z","bat"],["2009-11-10T23:00:05Z","bar"],["2009-11-10T23:00:06Z","bap"]]}]}]}`,
+			command: `SELECT mean(value) FROM wgroup WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-01T00:01:00Z' GROUP BY *,TIME(1m)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wgroup","tags":{"region":"us-east"},"columns":["time","mean"],"values":[["2000-01-01T00:00:00Z",15]]},{"name":"wgroup","tags":{"region":"us-west"},"columns":["time","mean"],"values":[["2000-01-01T00:00:00Z",30]]}]}]}`,
 		},
 		&Query{
-			name:    "where comparing tag and field",
+			name:    "wildcard and field in select",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from where_events where tennant != foo`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:04Z","bat"],["2009-11-10T23:00:05Z","bar"],["2009-11-10T23:00:06Z","bap"]]}]}]}`,
+			command: `SELECT value, * FROM wildcard`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","value","region","value_1","valx"],"values":[["2000-01-01T00:00:00Z",10,"us-east",10,null],["2000-01-01T00:00:10Z",null,"us-east",null,20],["2000-01-01T00:00:20Z",30,"us-east",30,40]]}]}]}`,
 		},
 		&Query{
-			name:    "where comparing tag and tag",
+			name:    "field and wildcard in select",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from where_events where tennant = tennant`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:04Z","bat"],["2009-11-10T23:00:05Z","bar"],["2009-11-10T23:00:06Z","bap"]]}]}]}`,
+			command: `SELECT value, * FROM wildcard`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","value","region","value_1","valx"],"values":[["2000-01-01T00:00:00Z",10,"us-east",10,null],["2000-01-01T00:00:10Z",null,"us-east",null,20],["2000-01-01T00:00:20Z",30,"us-east",30,40]]}]}]}`,
+		},
+		&Query{
+			name:    "field and wildcard in group by",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT * FROM wildcard GROUP BY region, *`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","tags":{"region":"us-east"},"columns":["time","value","valx"],"values":[["2000-01-01T00:00:00Z",10,null],["2000-01-01T00:00:10Z",null,20],["2000-01-01T00:00:20Z",30,40]]}]}]}`,
+		},
+		&Query{
+			name:    "wildcard and field in group by",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT * FROM wildcard GROUP BY *, region`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","tags":{"region":"us-east"},"columns":["time","value","valx"],"values":[["2000-01-01T00:00:00Z",10,null],["2000-01-01T00:00:10Z",null,20],["2000-01-01T00:00:20Z",30,40]]}]}]}`,
+		},
+		&Query{
+			name:    "wildcard with multiple measurements",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT * FROM m1, m2`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"m1","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:00Z",null,null,"us-east",10]]},{"name":"m2","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:01Z",20,"server01",null,null]]}]}]}`,
+		},
+		&Query{
+			name:    "wildcard with multiple measurements via regex",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT * FROM /^m.*/`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"m1","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:00Z",null,null,"us-east",10]]},{"name":"m2","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:01Z",20,"server01",null,null]]}]}]}`,
+		},
+		&Query{
+			name:    "wildcard with multiple measurements via regex and limit",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT * FROM db0../^m.*/ LIMIT 2`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"m1","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:00Z",null,null,"us-east",10]]},{"name":"m2","columns":["time","field","host","region","value"],"values":[["2000-01-01T00:00:01Z",20,"server01",null,null]]}]}]}`,
 		},
 	}...)
 
@@ -6448,9 +6583,11 @@ func TestServer_Query_Where_With_Tags(t *testing.T) {
 					t.Fatalf("test init failed: %s", err)
 				}
 			})
+
 			if query.skip {
 				t.Skipf("SKIP:: %s", query.name)
 			}
+
 			if err := query.Execute(s); err != nil {
 				t.Error(query.Error(err))
 			} else if !query.success() {
@@ -6460,7 +6597,7 @@ func TestServer_Query_Where_With_Tags(t *testing.T) {
 	}
 }
 
-func TestServer_Query_With_EmptyTags(t *testing.T) {
+func TestServer_Query_WildcardExpansion(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -6470,8 +6607,14 @@ func TestServer_Query_With_EmptyTags(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`cpu value=1 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
-		fmt.Sprintf(`cpu,host=server01 value=2 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:03Z").UnixNano()),
+		fmt.Sprintf(`wildcard,region=us-east,host=A value=10,cpu=80 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`wildcard,region=us-east,host=B value=20,cpu=90 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`wildcard,region=us-west,host=B value=30,cpu=70 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
+		fmt.Sprintf(`wildcard,region=us-east,host=A value=40,cpu=60 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:30Z").UnixNano()),
+
+		fmt.Sprintf(`dupnames,region=us-east,day=1 value=10,day=3i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`dupnames,region=us-east,day=2 value=20,day=2i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:10Z").UnixNano()),
+		fmt.Sprintf(`dupnames,region=us-west,day=3 value=30,day=1i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:20Z").UnixNano()),
 	}
 
 	test := NewTest("db0", "rp0")
@@ -6481,64 +6624,35 @@ func TestServer_Query_With_EmptyTags(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "where empty tag",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu where host = ''`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1]]}]}]}`,
-		},
-		&Query{
-			name:    "where not empty tag",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu where host != ''`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
-		},
-		&Query{
-			name:    "where regex none",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu where host !~ /.*/`,
-			exp:     `{"results":[{"statement_id":0}]}`,
-		},
-		&Query{
-			name:    "where regex exact",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu where host =~ /^server01$/`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
-		},
-		&Query{
-			name:    "where regex exact (case insensitive)",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu where host =~ /(?i)^SeRvEr01$/`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
-		},
-		&Query{
-			name:    "where regex exact (not)",
+			name:    "wildcard",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu where host !~ /^server01$/`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1]]}]}]}`,
+			command: `SELECT * FROM wildcard`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","cpu","host","region","value"],"values":[["2000-01-01T00:00:00Z",80,"A","us-east",10],["2000-01-01T00:00:10Z",90,"B","us-east",20],["2000-01-01T00:00:20Z",70,"B","us-west",30],["2000-01-01T00:00:30Z",60,"A","us-east",40]]}]}]}`,
 		},
 		&Query{
-			name:    "where regex at least one char",
+			name:    "no wildcard in select",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu where host =~ /.+/`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
+			command: `SELECT cpu, host, region, value  FROM wildcard`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","cpu","host","region","value"],"values":[["2000-01-01T00:00:00Z",80,"A","us-east",10],["2000-01-01T00:00:10Z",90,"B","us-east",20],["2000-01-01T00:00:20Z",70,"B","us-west",30],["2000-01-01T00:00:30Z",60,"A","us-east",40]]}]}]}`,
 		},
 		&Query{
-			name:    "where regex not at least one char",
+			name:    "no wildcard in select, preserve column order",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu where host !~ /.+/`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1]]}]}]}`,
+			command: `SELECT host, cpu, region, value  FROM wildcard`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","host","cpu","region","value"],"values":[["2000-01-01T00:00:00Z","A",80,"us-east",10],["2000-01-01T00:00:10Z","B",90,"us-east",20],["2000-01-01T00:00:20Z","B",70,"us-west",30],["2000-01-01T00:00:30Z","A",60,"us-east",40]]}]}]}`,
 		},
+
 		&Query{
-			name:    "group by empty tag",
+			name:    "no wildcard with alias",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu group by host`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":""},"columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1]]},{"name":"cpu","tags":{"host":"server01"},"columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
+			command: `SELECT cpu as c, host as h, region, value  FROM wildcard`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"wildcard","columns":["time","c","h","region","value"],"values":[["2000-01-01T00:00:00Z",80,"A","us-east",10],["2000-01-01T00:00:10Z",90,"B","us-east",20],["2000-01-01T00:00:20Z",70,"B","us-west",30],["2000-01-01T00:00:30Z",60,"A","us-east",40]]}]}]}`,
 		},
 		&Query{
-			name:    "group by missing tag",
+			name:    "duplicate tag and field key",
+			command: `SELECT * FROM dupnames`,
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select value from cpu group by region`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"region":""},"columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1],["2009-11-10T23:00:03Z",2]]}]}]}`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"dupnames","columns":["time","day","day_1","region","value"],"values":[["2000-01-01T00:00:00Z",3,"1","us-east",10],["2000-01-01T00:00:10Z",2,"2","us-east",20],["2000-01-01T00:00:20Z",1,"3","us-west",30]]}]}]}`,
 		},
 	}...)
 
@@ -6561,7 +6675,7 @@ func TestServer_Query_With_EmptyTags(t *testing.T) {
 	}
 }
 
-func TestServer_Query_LimitAndOffset(t *testing.T) {
+func TestServer_Query_AcrossShardsAndFields(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -6571,10 +6685,9 @@ func TestServer_Query_LimitAndOffset(t *testing.T) {
 	}
 
 	writes := []string{
-		fmt.Sprintf(`limited,tennant=paul foo=2 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
-		fmt.Sprintf(`limited,tennant=paul foo=3 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:03Z").UnixNano()),
-		fmt.Sprintf(`limited,tennant=paul foo=4 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:04Z").UnixNano()),
-		fmt.Sprintf(`limited,tennant=todd foo=5 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:05Z").UnixNano()),
+		fmt.Sprintf(`cpu load=100 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu load=200 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu core=4 %d`, mustParseTime(time.RFC3339Nano, "2015-01-01T00:00:00Z").UnixNano()),
 	}
 
 	test := NewTest("db0", "rp0")
@@ -6584,99 +6697,1641 @@ func TestServer_Query_LimitAndOffset(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "limit on points",
-			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from "limited" LIMIT 2`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z",2],["2009-11-10T23:00:03Z",3]]}]}]}`,
-		},
-		&Query{
-			name:    "limit higher than the number of data points",
+			name:    "two results for cpu",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from "limited" LIMIT 20`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z",2],["2009-11-10T23:00:03Z",3],["2009-11-10T23:00:04Z",4],["2009-11-10T23:00:05Z",5]]}]}]}`,
+			command: `SELECT load FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2000-01-01T00:00:00Z",100],["2010-01-01T00:00:00Z",200]]}]}]}`,
 		},
 		&Query{
-			name:    "limit and offset",
+			name:    "two results for cpu, multi-select",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from "limited" LIMIT 2 OFFSET 1`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","foo"],"values":[["2009-11-10T23:00:03Z",3],["2009-11-10T23:00:04Z",4]]}]}]}`,
+			command: `SELECT core,load FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core","load"],"values":[["2000-01-01T00:00:00Z",null,100],["2010-01-01T00:00:00Z",null,200],["2015-01-01T00:00:00Z",4,null]]}]}]}`,
 		},
 		&Query{
-			name:    "limit + offset equal to total number of points",
+			name:    "two results for cpu, wildcard select",
 			params:  url.Values{"db": []string{"db0"}},
-			command: `select foo from "limited" LIMIT 3 OFFSET 3`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","foo"],"values":[["2009-11-10T23:00:05Z",5]]}]}]}`,
+			command: `SELECT * FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core","load"],"values":[["2000-01-01T00:00:00Z",null,100],["2010-01-01T00:00:00Z",null,200],["2015-01-01T00:00:00Z",4,null]]}]}]}`,
 		},
 		&Query{
-			name:    "limit - offset higher than number of points",
-			command: `select foo from "limited" LIMIT 2 OFFSET 20`,
-			exp:     `{"results":[{"statement_id":0}]}`,
+			name:    "one result for core",
 			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT core FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2015-01-01T00:00:00Z",4]]}]}]}`,
 		},
 		&Query{
-			name:    "limit on points with group by time",
-			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 2`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","mean"],"values":[["2009-11-10T23:00:02Z",2],["2009-11-10T23:00:03Z",3]]}]}]}`,
+			name:    "empty result set from non-existent field",
 			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT foo FROM cpu`,
+			exp:     `{"results":[{"statement_id":0}]}`,
 		},
-		&Query{
-			name:    "limit higher than the number of data points with group by time",
-			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 20`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","mean"],"values":[["2009-11-10T23:00:02Z",2],["2009-11-10T23:00:03Z",3],["2009-11-10T23:00:04Z",4],["2009-11-10T23:00:05Z",5]]}]}]}`,
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_OrderedAcrossShards(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`cpu value=7 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu value=14 %d`, mustParseTime(time.RFC3339Nano, "2010-01-08T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu value=28 %d`, mustParseTime(time.RFC3339Nano, "2010-01-15T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu value=56 %d`, mustParseTime(time.RFC3339Nano, "2010-01-22T00:00:00Z").UnixNano()),
+		fmt.Sprintf(`cpu value=112 %d`, mustParseTime(time.RFC3339Nano, "2010-01-29T00:00:00Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "derivative",
 			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT derivative(value, 24h) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","derivative"],"values":[["2010-01-08T00:00:00Z",1],["2010-01-15T00:00:00Z",2],["2010-01-22T00:00:00Z",4],["2010-01-29T00:00:00Z",8]]}]}]}`,
 		},
 		&Query{
-			name:    "limit and offset with group by time",
-			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 2 OFFSET 1`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","mean"],"values":[["2009-11-10T23:00:03Z",3],["2009-11-10T23:00:04Z",4]]}]}]}`,
+			name:    "non_negative_derivative",
 			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT non_negative_derivative(value, 24h) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","non_negative_derivative"],"values":[["2010-01-08T00:00:00Z",1],["2010-01-15T00:00:00Z",2],["2010-01-22T00:00:00Z",4],["2010-01-29T00:00:00Z",8]]}]}]}`,
 		},
 		&Query{
-			name:    "limit + offset equal to the number of points with group by time",
-			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 3 OFFSET 3`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","mean"],"values":[["2009-11-10T23:00:05Z",5]]}]}]}`,
+			name:    "difference",
 			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT difference(value) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","difference"],"values":[["2010-01-08T00:00:00Z",7],["2010-01-15T00:00:00Z",14],["2010-01-22T00:00:00Z",28],["2010-01-29T00:00:00Z",56]]}]}]}`,
 		},
 		&Query{
-			name:    "limit - offset higher than number of points with group by time",
-			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 2 OFFSET 20`,
+			name:    "cumulative_sum",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT cumulative_sum(value) FROM cpu`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","cumulative_sum"],"values":[["2010-01-01T00:00:00Z",7],["2010-01-08T00:00:00Z",21],["2010-01-15T00:00:00Z",49],["2010-01-22T00:00:00Z",105],["2010-01-29T00:00:00Z",217]]}]}]}`,
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_Where_Fields(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`cpu alert_id="alert",tenant_id="tenant",_cust="johnson brothers" %d`, mustParseTime(time.RFC3339Nano, "2015-02-28T01:03:36.703820946Z").UnixNano()),
+		fmt.Sprintf(`cpu alert_id="alert",tenant_id="tenant",_cust="johnson brothers" %d`, mustParseTime(time.RFC3339Nano, "2015-02-28T01:03:36.703820946Z").UnixNano()),
+
+		fmt.Sprintf(`cpu load=100.0,core=4 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
+		fmt.Sprintf(`cpu load=80.0,core=2 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:01:02Z").UnixNano()),
+
+		fmt.Sprintf(`clicks local=true %d`, mustParseTime(time.RFC3339Nano, "2014-11-10T23:00:01Z").UnixNano()),
+		fmt.Sprintf(`clicks local=false %d`, mustParseTime(time.RFC3339Nano, "2014-11-10T23:00:02Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		// non type specific
+		&Query{
+			name:    "missing measurement with group by",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT load from missing group by *`,
 			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+
+		// string
+		&Query{
+			name:    "single string field",
 			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT alert_id FROM cpu WHERE alert_id='alert'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","alert_id"],"values":[["2015-02-28T01:03:36.703820946Z","alert"]]}]}]}`,
 		},
 		&Query{
-			name:    "limit - group by tennant",
-			command: `select foo from "limited" group by tennant limit 1`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","tags":{"tennant":"paul"},"columns":["time","foo"],"values":[["2009-11-10T23:00:02Z",2]]},{"name":"limited","tags":{"tennant":"todd"},"columns":["time","foo"],"values":[["2009-11-10T23:00:05Z",5]]}]}]}`,
+			name:    "string AND query, all fields in SELECT",
 			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT alert_id,tenant_id,_cust FROM cpu WHERE alert_id='alert' AND tenant_id='tenant'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","alert_id","tenant_id","_cust"],"values":[["2015-02-28T01:03:36.703820946Z","alert","tenant","johnson brothers"]]}]}]}`,
 		},
 		&Query{
-			name:    "limit and offset - group by tennant",
-			command: `select foo from "limited" group by tennant limit 1 offset 1`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","tags":{"tennant":"paul"},"columns":["time","foo"],"values":[["2009-11-10T23:00:03Z",3]]}]}]}`,
+			name:    "string AND query, all fields in SELECT, one in parenthesis",
 			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT alert_id,tenant_id FROM cpu WHERE alert_id='alert' AND (tenant_id='tenant')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","alert_id","tenant_id"],"values":[["2015-02-28T01:03:36.703820946Z","alert","tenant"]]}]}]}`,
 		},
-	}...)
+		&Query{
+			name:    "string underscored field",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT alert_id FROM cpu WHERE _cust='johnson brothers'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","alert_id"],"values":[["2015-02-28T01:03:36.703820946Z","alert"]]}]}]}`,
+		},
+		&Query{
+			name:    "string no match",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `SELECT alert_id FROM cpu WHERE _cust='acme'`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+
+		// float64
+		&Query{
+			name:    "float64 GT no match",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load > 100`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "float64 GTE match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load >= 100`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:00:02Z",100]]}]}]}`,
+		},
+		&Query{
+			name:    "float64 EQ match upper bound",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load = 100`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:00:02Z",100]]}]}]}`,
+		},
+		&Query{
+			name:    "float64 LTE match two",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load <= 100`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:00:02Z",100],["2009-11-10T23:01:02Z",80]]}]}]}`,
+		},
+		&Query{
+			name:    "float64 GT match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load > 99`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:00:02Z",100]]}]}]}`,
+		},
+		&Query{
+			name:    "float64 EQ no match",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load = 99`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "float64 LT match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load < 99`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:01:02Z",80]]}]}]}`,
+		},
+		&Query{
+			name:    "float64 LT no match",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load < 80`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "float64 NE match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select load from cpu where load != 100`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","load"],"values":[["2009-11-10T23:01:02Z",80]]}]}]}`,
+		},
+
+		// int64
+		&Query{
+			name:    "int64 GT no match",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core > 4`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "int64 GTE match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core >= 4`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:00:02Z",4]]}]}]}`,
+		},
+		&Query{
+			name:    "int64 EQ match upper bound",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core = 4`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:00:02Z",4]]}]}]}`,
+		},
+		&Query{
+			name:    "int64 LTE match two ",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core <= 4`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:00:02Z",4],["2009-11-10T23:01:02Z",2]]}]}]}`,
+		},
+		&Query{
+			name:    "int64 GT match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core > 3`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:00:02Z",4]]}]}]}`,
+		},
+		&Query{
+			name:    "int64 EQ no match",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core = 3`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "int64 LT match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core < 3`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:01:02Z",2]]}]}]}`,
+		},
+		&Query{
+			name:    "int64 LT no match",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core < 2`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "int64 NE match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select core from cpu where core != 4`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","core"],"values":[["2009-11-10T23:01:02Z",2]]}]}]}`,
+		},
+
+		// bool
+		&Query{
+			name:    "bool EQ match true",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select local from clicks where local = true`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"clicks","columns":["time","local"],"values":[["2014-11-10T23:00:01Z",true]]}]}]}`,
+		},
+		&Query{
+			name:    "bool EQ match false",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select local from clicks where local = false`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"clicks","columns":["time","local"],"values":[["2014-11-10T23:00:02Z",false]]}]}]}`,
+		},
+
+		&Query{
+			name:    "bool NE match one",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select local from clicks where local != true`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"clicks","columns":["time","local"],"values":[["2014-11-10T23:00:02Z",false]]}]}]}`,
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_Where_With_Tags(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`where_events,tennant=paul foo="bar" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
+		fmt.Sprintf(`where_events,tennant=paul foo="baz" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:03Z").UnixNano()),
+		fmt.Sprintf(`where_events,tennant=paul foo="bat" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:04Z").UnixNano()),
+		fmt.Sprintf(`where_events,tennant=todd foo="bar" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:05Z").UnixNano()),
+		fmt.Sprintf(`where_events,tennant=david foo="bap" %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:06Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "tag field and time",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from where_events where (tennant = 'paul' OR tennant = 'david') AND time > 1s AND (foo = 'bar' OR foo = 'baz' OR foo = 'bap')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:06Z","bap"]]}]}]}`,
+		},
+		&Query{
+			name:    "tag or field",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from where_events where tennant = 'paul' OR foo = 'bar'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:04Z","bat"],["2009-11-10T23:00:05Z","bar"]]}]}]}`,
+		},
+		&Query{
+			name:    "non-existent tag and field",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from where_events where tenant != 'paul' AND foo = 'bar'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:05Z","bar"]]}]}]}`,
+		},
+		&Query{
+			name:    "non-existent tag or field",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from where_events where tenant != 'paul' OR foo = 'bar'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:04Z","bat"],["2009-11-10T23:00:05Z","bar"],["2009-11-10T23:00:06Z","bap"]]}]}]}`,
+		},
+		&Query{
+			name:    "where comparing tag and field",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from where_events where tennant != foo`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:04Z","bat"],["2009-11-10T23:00:05Z","bar"],["2009-11-10T23:00:06Z","bap"]]}]}]}`,
+		},
+		&Query{
+			name:    "where comparing tag and tag",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from where_events where tennant = tennant`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"where_events","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z","bar"],["2009-11-10T23:00:03Z","baz"],["2009-11-10T23:00:04Z","bat"],["2009-11-10T23:00:05Z","bar"],["2009-11-10T23:00:06Z","bap"]]}]}]}`,
+		},
+	}...)
+
+	var once sync.Once
+	for _, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			once.Do(func() {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			})
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_With_EmptyTags(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`cpu value=1 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
+		fmt.Sprintf(`cpu,host=server01 value=2 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:03Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "where empty tag",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu where host = ''`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1]]}]}]}`,
+		},
+		&Query{
+			name:    "where not empty tag",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu where host != ''`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
+		},
+		&Query{
+			name:    "where regex none",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu where host !~ /.*/`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "where regex exact",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu where host =~ /^server01$/`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
+		},
+		&Query{
+			name:    "where regex exact (case insensitive)",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu where host =~ /(?i)^SeRvEr01$/`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
+		},
+		&Query{
+			name:    "where regex exact (not)",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu where host !~ /^server01$/`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1]]}]}]}`,
+		},
+		&Query{
+			name:    "where regex at least one char",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu where host =~ /.+/`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
+		},
+		&Query{
+			name:    "where regex not at least one char",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu where host !~ /.+/`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1]]}]}]}`,
+		},
+		&Query{
+			name:    "group by empty tag",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu group by host`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":""},"columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1]]},{"name":"cpu","tags":{"host":"server01"},"columns":["time","value"],"values":[["2009-11-10T23:00:03Z",2]]}]}]}`,
+		},
+		&Query{
+			name:    "group by missing tag",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select value from cpu group by region`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"region":""},"columns":["time","value"],"values":[["2009-11-10T23:00:02Z",1],["2009-11-10T23:00:03Z",2]]}]}]}`,
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_LimitAndOffset(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`limited,tennant=paul foo=2 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
+		fmt.Sprintf(`limited,tennant=paul foo=3 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:03Z").UnixNano()),
+		fmt.Sprintf(`limited,tennant=paul foo=4 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:04Z").UnixNano()),
+		fmt.Sprintf(`limited,tennant=todd foo=5 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:05Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "limit on points",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from "limited" LIMIT 2`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z",2],["2009-11-10T23:00:03Z",3]]}]}]}`,
+		},
+		&Query{
+			name:    "limit higher than the number of data points",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from "limited" LIMIT 20`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","foo"],"values":[["2009-11-10T23:00:02Z",2],["2009-11-10T23:00:03Z",3],["2009-11-10T23:00:04Z",4],["2009-11-10T23:00:05Z",5]]}]}]}`,
+		},
+		&Query{
+			name:    "limit and offset",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from "limited" LIMIT 2 OFFSET 1`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","foo"],"values":[["2009-11-10T23:00:03Z",3],["2009-11-10T23:00:04Z",4]]}]}]}`,
+		},
+		&Query{
+			name:    "limit + offset equal to total number of points",
+			params:  url.Values{"db": []string{"db0"}},
+			command: `select foo from "limited" LIMIT 3 OFFSET 3`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","foo"],"values":[["2009-11-10T23:00:05Z",5]]}]}]}`,
+		},
+		&Query{
+			name:    "limit - offset higher than number of points",
+			command: `select foo from "limited" LIMIT 2 OFFSET 20`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "limit on points with group by time",
+			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 2`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","mean"],"values":[["2009-11-10T23:00:02Z",2],["2009-11-10T23:00:03Z",3]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "limit higher than the number of data points with group by time",
+			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 20`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","mean"],"values":[["2009-11-10T23:00:02Z",2],["2009-11-10T23:00:03Z",3],["2009-11-10T23:00:04Z",4],["2009-11-10T23:00:05Z",5]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "limit and offset with group by time",
+			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 2 OFFSET 1`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","mean"],"values":[["2009-11-10T23:00:03Z",3],["2009-11-10T23:00:04Z",4]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "limit + offset equal to the number of points with group by time",
+			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 3 OFFSET 3`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","columns":["time","mean"],"values":[["2009-11-10T23:00:05Z",5]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "limit - offset higher than number of points with group by time",
+			command: `select mean(foo) from "limited" WHERE time >= '2009-11-10T23:00:02Z' AND time < '2009-11-10T23:00:06Z' GROUP BY TIME(1s) LIMIT 2 OFFSET 20`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "limit - group by tennant",
+			command: `select foo from "limited" group by tennant limit 1`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","tags":{"tennant":"paul"},"columns":["time","foo"],"values":[["2009-11-10T23:00:02Z",2]]},{"name":"limited","tags":{"tennant":"todd"},"columns":["time","foo"],"values":[["2009-11-10T23:00:05Z",5]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "limit and offset - group by tennant",
+			command: `select foo from "limited" group by tennant limit 1 offset 1`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"limited","tags":{"tennant":"paul"},"columns":["time","foo"],"values":[["2009-11-10T23:00:03Z",3]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_Fill(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`fills val=3 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
+		fmt.Sprintf(`fills val=5 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:03Z").UnixNano()),
+		fmt.Sprintf(`fills val=4 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:06Z").UnixNano()),
+		fmt.Sprintf(`fills val=10 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:16Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "fill with value",
+			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) FILL(1)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",1],["2009-11-10T23:00:15Z",10]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill with value, WHERE all values match condition",
+			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' and val < 50 group by time(5s) FILL(1)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",1],["2009-11-10T23:00:15Z",10]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill with value, WHERE no values match condition",
+			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' and val > 50 group by time(5s) FILL(1)`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill with previous",
+			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) FILL(previous)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",4],["2009-11-10T23:00:15Z",10]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill with previous and a maximum staleness",
+			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(2s) FILL(previous, 4s)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",null],["2009-11-10T23:00:02Z",4],["2009-11-10T23:00:04Z",4],["2009-11-10T23:00:06Z",4],["2009-11-10T23:00:08Z",4],["2009-11-10T23:00:10Z",4],["2009-11-10T23:00:12Z",null],["2009-11-10T23:00:14Z",null],["2009-11-10T23:00:16Z",10],["2009-11-10T23:00:18Z",10]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill with none, i.e. clear out nulls",
+			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) FILL(none)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:15Z",10]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill defaults to null",
+			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",null],["2009-11-10T23:00:15Z",10]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill defaults to 0 for count",
+			command: `select count(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","count"],"values":[["2009-11-10T23:00:00Z",2],["2009-11-10T23:00:05Z",1],["2009-11-10T23:00:10Z",0],["2009-11-10T23:00:15Z",1]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill none drops 0s for count",
+			command: `select count(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) fill(none)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","count"],"values":[["2009-11-10T23:00:00Z",2],["2009-11-10T23:00:05Z",1],["2009-11-10T23:00:15Z",1]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill previous overwrites 0s for count",
+			command: `select count(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) fill(previous)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","count"],"values":[["2009-11-10T23:00:00Z",2],["2009-11-10T23:00:05Z",1],["2009-11-10T23:00:10Z",1],["2009-11-10T23:00:15Z",1]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill with implicit start time",
+			command: `select mean(val) from fills where time < '2009-11-10T23:00:20Z' group by time(5s)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",null],["2009-11-10T23:00:15Z",10]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_ImplicitFill(t *testing.T) {
+	t.Parallel()
+	config := NewConfig()
+	config.Coordinator.MaxSelectBucketsN = 5
+	s := OpenServer(config)
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []string{
+		fmt.Sprintf(`fills val=1 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T11:30:00Z").UnixNano()),
+		fmt.Sprintf(`fills val=3 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T12:00:00Z").UnixNano()),
+		fmt.Sprintf(`fills val=5 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T16:30:00Z").UnixNano()),
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "fill with implicit start",
+			command: `select mean(val) from fills where time < '2010-01-01T18:00:00Z' group by time(1h)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2010-01-01T16:00:00Z",5],["2010-01-01T17:00:00Z",null]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "fill with implicit start - max select buckets",
+			command: `select mean(val) from fills where time < '2010-01-01T17:00:00Z' group by time(1h)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2010-01-01T12:00:00Z",3],["2010-01-01T13:00:00Z",null],["2010-01-01T14:00:00Z",null],["2010-01-01T15:00:00Z",null],["2010-01-01T16:00:00Z",5]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_TimeZone(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	var writes []string
+	for _, start := range []time.Time{
+		// One day before DST starts.
+		time.Date(2000, 4, 1, 0, 0, 0, 0, LosAngeles),
+		// Middle of DST. No change.
+		time.Date(2000, 6, 1, 0, 0, 0, 0, LosAngeles),
+		// One day before DST ends.
+		time.Date(2000, 10, 28, 0, 0, 0, 0, LosAngeles),
+	} {
+		ts := start
+		// Write every hour for 4 days.
+		for i := 0; i < 24*4; i++ {
+			writes = append(writes, fmt.Sprintf(`cpu,interval=daily value=0 %d`, ts.UnixNano()))
+			ts = ts.Add(time.Hour)
+		}
+
+		// Write every 5 minutes for 3 hours. Start at 1 on the day with DST.
+		ts = start.Add(25 * time.Hour)
+		for i := 0; i < 12*3; i++ {
+			writes = append(writes, fmt.Sprintf(`cpu,interval=hourly value=0 %d`, ts.UnixNano()))
+			ts = ts.Add(5 * time.Minute)
+		}
+	}
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join(writes, "\n")},
+	}
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "timezone offset - dst start - daily",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-04-02T00:00:00-08:00' AND time < '2000-04-04T00:00:00-07:00' AND interval = 'daily' GROUP BY time(1d) TZ('America/Los_Angeles')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-04-02T00:00:00-08:00",23],["2000-04-03T00:00:00-07:00",24]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "timezone offset - no change - daily",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-06-01T00:00:00-07:00' AND time < '2000-06-03T00:00:00-07:00' AND interval = 'daily' GROUP BY time(1d) TZ('America/Los_Angeles')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-06-01T00:00:00-07:00",24],["2000-06-02T00:00:00-07:00",24]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "timezone offset - dst end - daily",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-10-29T00:00:00-07:00' AND time < '2000-10-31T00:00:00-08:00' AND interval = 'daily' GROUP BY time(1d) TZ('America/Los_Angeles')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-10-29T00:00:00-07:00",25],["2000-10-30T00:00:00-08:00",24]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "timezone offset - dst start - hourly",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-04-02T01:00:00-08:00' AND time < '2000-04-02T04:00:00-07:00' AND interval = 'hourly' GROUP BY time(1h) TZ('America/Los_Angeles')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-04-02T01:00:00-08:00",12],["2000-04-02T03:00:00-07:00",12]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "timezone offset - no change - hourly",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-06-02T01:00:00-07:00' AND time < '2000-06-02T03:00:00-07:00' AND interval = 'hourly' GROUP BY time(1h) TZ('America/Los_Angeles')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-06-02T01:00:00-07:00",12],["2000-06-02T02:00:00-07:00",12]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "timezone offset - dst end - hourly",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-10-29T01:00:00-07:00' AND time < '2000-10-29T02:00:00-08:00' AND interval = 'hourly' GROUP BY time(1h) TZ('America/Los_Angeles')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-10-29T01:00:00-07:00",12],["2000-10-29T01:00:00-08:00",12]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "default timezone - set time zone",
+			command: `SET TIME ZONE 'America/Los_Angeles'; SELECT count(value) FROM cpu WHERE time >= '2000-04-02' AND time < '2000-04-04' AND interval = 'daily' GROUP BY time(1d)`,
+			exp:     `{"results":[{"statement_id":0},{"statement_id":1,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-04-02T00:00:00-08:00",23],["2000-04-03T00:00:00-07:00",24]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "default timezone - request parameter",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-04-02T00:00:00-08:00' AND time < '2000-04-04T00:00:00-07:00' AND interval = 'daily' GROUP BY time(1d)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-04-02T00:00:00-08:00",23],["2000-04-03T00:00:00-07:00",24]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}, "tz": []string{"America/Los_Angeles"}},
+		},
+		&Query{
+			name:    "default timezone - tz clause takes precedence",
+			command: `SET TIME ZONE 'Asia/Tokyo'; SELECT count(value) FROM cpu WHERE time >= '2000-04-02T00:00:00-08:00' AND time < '2000-04-04T00:00:00-07:00' AND interval = 'daily' GROUP BY time(1d) TZ('America/Los_Angeles')`,
+			exp:     `{"results":[{"statement_id":0},{"statement_id":1,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-04-02T00:00:00-08:00",23],["2000-04-03T00:00:00-07:00",24]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}, "tz": []string{"Europe/Paris"}},
+		},
+	}...)
+
+	for i, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if i == 0 {
+				if err := test.init(s); err != nil {
+					t.Fatalf("test init failed: %s", err)
+				}
+			}
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+
+	if _, err := s.QueryWithParams(`SELECT count(value) FROM cpu GROUP BY time(1d)`, url.Values{"db": []string{"db0"}, "tz": []string{"Mars/Olympus_Mons"}}); err == nil || !strings.Contains(err.Error(), "unable to find time zone Mars/Olympus_Mons") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServer_Query_UserTimeZone(t *testing.T) {
+	t.Parallel()
+	c := NewConfig()
+	c.HTTPD.AuthEnabled = true
+	s := OpenServer(c)
+	defer s.Close()
+
+	adminParams := url.Values{"u": []string{"admin"}, "p": []string{"admin"}}
+	readerParams := url.Values{"db": []string{"db0"}, "u": []string{"reader"}, "p": []string{"r"}}
+
+	setup := []*Query{
+		&Query{
+			name:    "create admin",
+			command: `CREATE USER admin WITH PASSWORD 'admin' WITH ALL PRIVILEGES`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "create database and user",
+			command: `CREATE DATABASE db0; CREATE USER reader WITH PASSWORD 'r'; GRANT READ ON db0 TO reader; SET TIME ZONE FOR reader = 'America/Los_Angeles'`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0},{"statement_id":1},{"statement_id":2},{"statement_id":3}]}`,
+		},
+	}
+	for _, query := range setup {
+		if err := query.Execute(s); err != nil {
+			t.Fatal(query.Error(err))
+		} else if !query.success() {
+			t.Fatal(query.failureMessage())
+		}
+	}
+
+	var writes []string
+	for ts := time.Date(2000, 6, 1, 0, 0, 0, 0, LosAngeles); ts.Before(time.Date(2000, 6, 3, 0, 0, 0, 0, LosAngeles)); ts = ts.Add(time.Hour) {
+		writes = append(writes, fmt.Sprintf(`cpu value=0 %d`, ts.UnixNano()))
+	}
+	s.MustWrite("db0", "", strings.Join(writes, "\n"), adminParams)
+
+	if _, err := s.QueryWithParams(`SET TIME ZONE FOR reader = DEFAULT`, readerParams); err == nil || !strings.Contains(err.Error(), "requires admin privilege") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, query := range []*Query{
+		&Query{
+			name:    "user time zone",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-06-01' AND time < '2000-06-03' GROUP BY time(1d)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-06-01T00:00:00-07:00",24],["2000-06-02T00:00:00-07:00",24]]}]}]}`,
+			params:  readerParams,
+		},
+		&Query{
+			name:    "request parameter takes precedence",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-06-01T07:00:00Z' AND time < '2000-06-03T07:00:00Z' GROUP BY time(1d)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-06-01T00:00:00Z",17],["2000-06-02T00:00:00Z",24],["2000-06-03T00:00:00Z",7]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}, "u": []string{"reader"}, "p": []string{"r"}, "tz": []string{"UTC"}},
+		},
+		&Query{
+			name:    "reset user time zone",
+			command: `SET TIME ZONE FOR reader = DEFAULT`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+			params:  adminParams,
+		},
+		&Query{
+			name:    "user time zone reset",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-06-01T07:00:00Z' AND time < '2000-06-03T07:00:00Z' GROUP BY time(1d)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-06-01T00:00:00Z",17],["2000-06-02T00:00:00Z",24],["2000-06-03T00:00:00Z",7]]}]}]}`,
+			params:  readerParams,
+		},
+	} {
+		t.Run(query.name, func(t *testing.T) {
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_MeasurementPrivileges(t *testing.T) {
+	t.Parallel()
+	c := NewConfig()
+	c.HTTPD.AuthEnabled = true
+	s := OpenServer(c)
+	defer s.Close()
+
+	adminParams := url.Values{"u": []string{"admin"}, "p": []string{"admin"}}
+	bobParams := func() url.Values {
+		return url.Values{"db": []string{"db0"}, "u": []string{"bob"}, "p": []string{"b"}}
+	}
+
+	setup := []*Query{
+		&Query{
+			name:    "create admin",
+			command: `CREATE USER admin WITH PASSWORD 'admin' WITH ALL PRIVILEGES`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "create database and user",
+			command: `CREATE DATABASE db0; CREATE USER bob WITH PASSWORD 'b'; GRANT READ ON db0 MEASUREMENT cpu TO bob; GRANT ALL ON db0 MEASUREMENT /^disk/ TO bob`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0},{"statement_id":1},{"statement_id":2},{"statement_id":3}]}`,
+		},
+	}
+	for _, query := range setup {
+		if err := query.Execute(s); err != nil {
+			t.Fatal(query.Error(err))
+		} else if !query.success() {
+			t.Fatal(query.failureMessage())
+		}
+	}
+	s.MustWrite("db0", "", "cpu,host=a value=1 0\nmem,host=a value=2 0\ndisk_io,host=a value=3 0", adminParams)
+
+	// Writes are only accepted for the measurements bob can write to.
+	if _, err := s.Write("db0", "", "disk_io,host=a value=4 1000000000", bobParams()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.Write("db0", "", "disk_io,host=a value=5 2000000000\ncpu,host=a value=6 2000000000", bobParams()); err == nil || !strings.Contains(err.Error(), "code=403") || !strings.Contains(err.Error(), "not authorized to write to measurement") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Statements that are not filtered by measurement still require a
+	// privilege on the database.
+	if _, err := s.QueryWithParams(`DELETE FROM cpu`, bobParams()); err == nil || !strings.Contains(err.Error(), "requires WRITE on db0") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, query := range []*Query{
+		&Query{
+			name:    "show grants",
+			command: `SHOW GRANTS FOR bob`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["database","privilege"]},{"columns":["database","measurement","privilege"],"values":[["db0","cpu","READ"],["db0","/^disk/","ALL PRIVILEGES"]]}]}]}`,
+			params:  adminParams,
+		},
+		&Query{
+			name:    "show measurements",
+			command: `SHOW MEASUREMENTS`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["disk_io"]]}]}]}`,
+			params:  bobParams(),
+		},
+		&Query{
+			name:    "show field keys",
+			command: `SHOW FIELD KEYS`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["value","float"]]},{"name":"disk_io","columns":["fieldKey","fieldType"],"values":[["value","float"]]}]}]}`,
+			params:  bobParams(),
+		},
+		&Query{
+			name:    "select authorized measurement",
+			command: `SELECT value FROM disk_io`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"disk_io","columns":["time","value"],"values":[["1970-01-01T00:00:00Z",3],["1970-01-01T00:00:01Z",4]]}]}]}`,
+			params:  bobParams(),
+		},
+		&Query{
+			name:    "select unauthorized measurement",
+			command: `SELECT value FROM mem`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+			params:  bobParams(),
+		},
+		&Query{
+			name:    "select regex",
+			command: `SELECT count(value) FROM /.*/`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["1970-01-01T00:00:00Z",1]]},{"name":"disk_io","columns":["time","count"],"values":[["1970-01-01T00:00:00Z",2]]}]}]}`,
+			params:  bobParams(),
+		},
+		&Query{
+			name:    "revoke",
+			command: `REVOKE READ ON db0 MEASUREMENT cpu FROM bob`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+			params:  adminParams,
+		},
+		&Query{
+			name:    "show measurements after revoke",
+			command: `SHOW MEASUREMENTS`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["disk_io"]]}]}]}`,
+			params:  bobParams(),
+		},
+	} {
+		t.Run(query.name, func(t *testing.T) {
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
+func TestServer_Query_LDAP(t *testing.T) {
+	t.Parallel()
+
+	ls, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ls.Close()
+	ls.AddEntry(&ldap.Entry{
+		DN:         "uid=alice,ou=people,dc=example,dc=com",
+		Attributes: map[string][]string{"uid": {"alice"}, "memberOf": {"cn=ops,ou=groups,dc=example,dc=com"}},
+	}, "alicepw")
+	ls.AddEntry(&ldap.Entry{
+		DN:         "uid=bob,ou=people,dc=example,dc=com",
+		Attributes: map[string][]string{"uid": {"bob"}, "memberOf": {"cn=dev,ou=groups,dc=example,dc=com"}},
+	}, "bobpw")
+
+	c := NewConfig()
+	c.HTTPD.AuthEnabled = true
+	c.Meta.LDAP.Enabled = true
+	c.Meta.LDAP.URL = ls.URL()
+	c.Meta.LDAP.SearchBaseDN = "ou=people,dc=example,dc=com"
+	c.Meta.LDAP.Groups = []meta.LDAPGroup{
+		{DN: "cn=ops,ou=groups,dc=example,dc=com", Privileges: map[string]string{"db0": "ALL"}},
+		{DN: "cn=dev,ou=groups,dc=example,dc=com", Privileges: map[string]string{"db0": "READ"}},
+	}
+	s := OpenServer(c)
+	defer s.Close()
+
+	adminParams := url.Values{"u": []string{"admin"}, "p": []string{"admin"}}
+	for _, query := range []*Query{
+		&Query{
+			name:    "create admin",
+			command: `CREATE USER admin WITH PASSWORD 'admin' WITH ALL PRIVILEGES`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "create database",
+			command: `CREATE DATABASE db0`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+	} {
+		if err := query.Execute(s); err != nil {
+			t.Fatal(query.Error(err))
+		} else if !query.success() {
+			t.Fatal(query.failureMessage())
+		}
+	}
+
+	aliceParams := url.Values{"u": []string{"alice"}, "p": []string{"alicepw"}}
+	bobParams := url.Values{"u": []string{"bob"}, "p": []string{"bobpw"}}
+	if _, err := s.Write("db0", "", `cpu value=1 1000000000`, aliceParams); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := s.Write("db0", "", `cpu value=2 2000000000`, bobParams); err == nil || !strings.Contains(err.Error(), "code=403") {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := s.Write("db0", "", `cpu value=2 2000000000`, url.Values{"u": []string{"alice"}, "p": []string{"wrong"}}); err == nil || !strings.Contains(err.Error(), "code=401") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, query := range []*Query{
+		&Query{
+			name:    "member of a group with read privilege",
+			command: `SELECT value FROM cpu`,
+			params:  url.Values{"db": []string{"db0"}, "u": []string{"bob"}, "p": []string{"bobpw"}},
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["1970-01-01T00:00:01Z",1]]}]}]}`,
+		},
+	} {
+		t.Run(query.name, func(t *testing.T) {
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+
+	if _, err := s.QueryWithParams(`SHOW USERS`, bobParams); err == nil || !strings.Contains(err.Error(), "requires admin privilege") {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := s.QueryWithParams(`SHOW DATABASES`, url.Values{"u": []string{"carol"}, "p": []string{"carolpw"}}); err == nil || !strings.Contains(err.Error(), "code=401") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServer_Query_Roles(t *testing.T) {
+	t.Parallel()
+	c := NewConfig()
+	c.HTTPD.AuthEnabled = true
+	s := OpenServer(c)
+	defer s.Close()
+
+	adminParams := url.Values{"u": []string{"admin"}, "p": []string{"admin"}}
+	bobParams := url.Values{"u": []string{"bob"}, "p": []string{"b"}}
+
+	setup := []*Query{
+		&Query{
+			name:    "create admin",
+			command: `CREATE USER admin WITH PASSWORD 'admin' WITH ALL PRIVILEGES`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "create database, user and role",
+			command: `CREATE DATABASE db0; CREATE USER bob WITH PASSWORD 'b'; CREATE ROLE writers; GRANT WRITE ON db0 TO ROLE writers`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0},{"statement_id":1},{"statement_id":2},{"statement_id":3}]}`,
+		},
+	}
+	for _, query := range setup {
+		if err := query.Execute(s); err != nil {
+			t.Fatal(query.Error(err))
+		} else if !query.success() {
+			t.Fatal(query.failureMessage())
+		}
+	}
+
+	// The user is granted the privileges of the role once it is a member.
+	if _, err := s.Write("db0", "", `cpu value=1 1000000000`, bobParams); err == nil || !strings.Contains(err.Error(), "code=403") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.QueryWithParams(`GRANT ROLE writers TO bob; GRANT READ ON db0 TO bob`, adminParams); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write("db0", "", `cpu value=1 1000000000`, bobParams); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, query := range []*Query{
+		&Query{
+			name:    "show roles",
+			command: `SHOW ROLES`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["role","users"],"values":[["writers","bob"]]}]}]}`,
+		},
+		&Query{
+			name:    "show grants of the user reflect its roles",
+			command: `SHOW GRANTS FOR bob`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["database","privilege"],"values":[["db0","ALL PRIVILEGES"]]},{"columns":["role"],"values":[["writers"]]}]}]}`,
+		},
+		&Query{
+			name:    "show grants of the role",
+			command: `SHOW GRANTS FOR ROLE writers`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["database","privilege"],"values":[["db0","WRITE"]]}]}]}`,
+		},
+		&Query{
+			name:    "revoke privilege from role",
+			command: `REVOKE WRITE ON db0 FROM ROLE writers; SHOW GRANTS FOR bob`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0},{"statement_id":1,"series":[{"columns":["database","privilege"],"values":[["db0","READ"]]},{"columns":["role"],"values":[["writers"]]}]}]}`,
+		},
+		&Query{
+			name:    "drop role",
+			command: `GRANT ALL ON db0 TO ROLE writers; DROP ROLE writers; SHOW GRANTS FOR bob; SHOW ROLES`,
+			params:  adminParams,
+			exp:     `{"results":[{"statement_id":0},{"statement_id":1},{"statement_id":2,"series":[{"columns":["database","privilege"],"values":[["db0","READ"]]}]},{"statement_id":3,"series":[{"columns":["role","users"]}]}]}`,
+		},
+	} {
+		t.Run(query.name, func(t *testing.T) {
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+
+	if _, err := s.Write("db0", "", `cpu value=2 2000000000`, bobParams); err == nil || !strings.Contains(err.Error(), "code=403") {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := s.QueryWithParams(`CREATE ROLE readers`, bobParams); err == nil || !strings.Contains(err.Error(), "requires admin privilege") {
+		t.Fatalf("unexpected error: %v", err)
+	} else if res, err := s.QueryWithParams(`GRANT ROLE readers TO bob`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if exp := `{"results":[{"statement_id":0,"error":"role not found"}]}`; res != exp {
+		t.Fatalf("unexpected results: %s", res)
+	}
+}
+
+// Ensure the schema of the meta data can be exported and applied.
+func TestServer_Schema(t *testing.T) {
+	t.Parallel()
+	c := NewConfig()
+	c.HTTPD.AuthEnabled = true
+	s := OpenServer(c)
+	defer s.Close()
+
+	adminParams := url.Values{"u": []string{"admin"}, "p": []string{"admin"}}
+	for _, q := range []string{
+		`CREATE USER admin WITH PASSWORD 'admin' WITH ALL PRIVILEGES`,
+		`CREATE DATABASE db0 WITH DURATION 1d NAME rp0; CREATE USER bob WITH PASSWORD 'b'; GRANT READ ON db0 TO bob`,
+	} {
+		if _, err := s.QueryWithParams(q, adminParams); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// runSchema runs the schema command against the server as the user.
+	host := strings.TrimPrefix(s.URL(), "http://")
+	runSchema := func(username, password, stdin string, args ...string) (string, string, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := schema.NewCommand()
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = strings.NewReader(stdin), &stdout, &stderr
+		args = append([]string{args[0], "-host", host, "-username", username, "-password", password}, args[1:]...)
+		err := cmd.Run(args...)
+		return stdout.String(), stderr.String(), err
+	}
+
+	if _, _, err := runSchema("bob", "b", "", "export"); err == nil || err.Error() != "error authorizing admin access" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, _, err := runSchema("admin", "admin", "", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc meta.Schema
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatal(err)
+	} else if len(doc.Databases) != 1 || doc.Databases[0].Name != "db0" || doc.Databases[0].DefaultRetentionPolicy != "rp0" {
+		t.Fatalf("unexpected schema: %s", out)
+	} else if len(doc.Users) != 2 || doc.Users[1].Name != "bob" || doc.Users[1].Privileges["db0"] != "READ" {
+		t.Fatalf("unexpected schema: %s", out)
+	}
+
+	// Add a database and a user, and remove bob.
+	doc.Databases = append(doc.Databases, meta.SchemaDatabase{
+		Name:                   "db1",
+		DefaultRetentionPolicy: "rp1",
+		RetentionPolicies:      []meta.SchemaRetentionPolicy{{Name: "rp1", Duration: toml.Duration(7 * 24 * time.Hour)}},
+		ContinuousQueries: []meta.SchemaContinuousQuery{{
+			Name:  "cq0",
+			Query: `CREATE CONTINUOUS QUERY cq0 ON db1 BEGIN SELECT mean(value) INTO db1.rp1.m1 FROM db1.rp1.m0 GROUP BY time(1h) END`,
+		}},
+	})
+	doc.Users = []meta.SchemaUser{doc.Users[0], {Name: "carol", Password: "c", Privileges: map[string]string{"db1": "WRITE"}}}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := strings.Join([]string{
+		`CREATE DATABASE db1 WITH DURATION 168h0m0s REPLICATION 1 NAME rp1`,
+		`CREATE CONTINUOUS QUERY cq0 ON db1 BEGIN SELECT mean(value) INTO db1.rp1.m1 FROM db1.rp1.m0 GROUP BY time(1h) END`,
+		`CREATE USER carol WITH PASSWORD [REDACTED]`,
+		`GRANT WRITE ON db1 TO carol`,
+		`DROP USER bob`,
+	}, "\n") + "\n"
+
+	// A dry run does not change the meta data.
+	if out, _, err := runSchema("admin", "admin", string(b), "apply", "-prune", "-dry-run", "-"); err != nil {
+		t.Fatal(err)
+	} else if out != exp {
+		t.Fatalf("unexpected statements:\ngot: %s\nexp: %s", out, exp)
+	} else if res, err := s.QueryWithParams(`SHOW DATABASES`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if exp := `{"results":[{"statement_id":0,"series":[{"name":"databases","columns":["name"],"values":[["db0"]]}]}]}`; res != exp {
+		t.Fatalf("unexpected results:\ngot: %s\nexp: %s", res, exp)
+	}
+
+	if out, _, err := runSchema("admin", "admin", string(b), "apply", "-prune", "-"); err != nil {
+		t.Fatal(err)
+	} else if out != exp {
+		t.Fatalf("unexpected statements:\ngot: %s\nexp: %s", out, exp)
+	} else if res, err := s.QueryWithParams(`SHOW DATABASES; SHOW USERS; SHOW GRANTS FOR carol`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if exp := `{"results":[{"statement_id":0,"series":[{"name":"databases","columns":["name"],"values":[["db0"],["db1"]]}]},{"statement_id":1,"series":[{"columns":["user","admin"],"values":[["admin",true],["carol",false]]}]},{"statement_id":2,"series":[{"columns":["database","privilege"],"values":[["db1","WRITE"]]}]}]}`; res != exp {
+		t.Fatalf("unexpected results:\ngot: %s\nexp: %s", res, exp)
+	}
+
+	// Applying the schema again does not change anything.
+	if out, stderr, err := runSchema("admin", "admin", string(b), "apply", "-prune", "-"); err != nil {
+		t.Fatal(err)
+	} else if out != "" || stderr != "schema is up to date\n" {
+		t.Fatalf("unexpected output: %q %q", out, stderr)
+	}
+}
+
+func TestServer_Query_Tokens(t *testing.T) {
+	t.Parallel()
+	c := NewConfig()
+	c.HTTPD.AuthEnabled = true
+	s := OpenServer(c)
+	defer s.Close()
+
+	adminParams := url.Values{"u": []string{"admin"}, "p": []string{"admin"}}
+	for _, q := range []string{
+		`CREATE USER admin WITH PASSWORD 'admin' WITH ALL PRIVILEGES`,
+		`CREATE DATABASE db0; CREATE USER bob WITH PASSWORD 'b'; GRANT READ ON db0 TO bob`,
+	} {
+		if _, err := s.QueryWithParams(q, adminParams); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := s.QueryWithParams(`CREATE TOKEN FOR bob WITH DURATION 1h`, adminParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created struct {
+		Results []struct {
+			Series []struct {
+				Values [][]string `json:"values"`
+			} `json:"series"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(res), &created); err != nil {
+		t.Fatal(err)
+	} else if len(created.Results) != 1 || len(created.Results[0].Series) != 1 || len(created.Results[0].Series[0].Values) != 1 {
+		t.Fatalf("unexpected results: %s", res)
+	}
+	id, token := created.Results[0].Series[0].Values[0][0], created.Results[0].Series[0].Values[0][1]
+
+	// queryWithToken executes the query authenticated with an API token.
+	queryWithToken := func(token, command string) (int, string) {
+		u := s.URL() + "/query?db=db0&q=" + url.QueryEscape(command)
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Token "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, strings.TrimSpace(string(b))
+	}
+
+	// The token has the privileges of its user.
+	if code, body := queryWithToken(token, `SHOW MEASUREMENTS`); code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", code, body)
+	} else if code, body := queryWithToken(token, `CREATE DATABASE db1`); code != http.StatusForbidden {
+		t.Fatalf("unexpected status: %d: %s", code, body)
+	} else if code, body := queryWithToken(id+".wrong", `SHOW MEASUREMENTS`); code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status: %d: %s", code, body)
+	}
+
+	// The token can be listed, without its secret, and revoked.
+	if res, err := s.QueryWithParams(`SHOW TOKENS FOR bob`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(res, `"columns":["id","user","created","expires","last_used","write_target"]`) || !strings.Contains(res, `["`+id+`","bob",`) {
+		t.Fatalf("unexpected results: %s", res)
+	} else if strings.Contains(res, token) {
+		t.Fatalf("token should not be listed: %s", res)
+	}
+	if res, err := s.QueryWithParams(`DROP TOKEN '`+id+`'`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if exp := `{"results":[{"statement_id":0}]}`; res != exp {
+		t.Fatalf("unexpected results: %s", res)
+	} else if code, body := queryWithToken(token, `SHOW MEASUREMENTS`); code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status: %d: %s", code, body)
+	}
+}
+
+func TestServer_Write_Targets(t *testing.T) {
+	t.Parallel()
+	c := NewConfig()
+	c.HTTPD.AuthEnabled = true
+	s := OpenServer(c)
+	defer s.Close()
+
+	adminParams := url.Values{"u": []string{"admin"}, "p": []string{"admin"}}
+	for _, q := range []string{
+		`CREATE USER admin WITH PASSWORD 'admin' WITH ALL PRIVILEGES`,
+		`CREATE DATABASE db0; CREATE DATABASE db1; CREATE RETENTION POLICY rp1 ON db1 DURATION INF REPLICATION 1`,
+		`CREATE USER bob WITH PASSWORD 'b'; GRANT ALL ON db0 TO bob; GRANT ALL ON db1 TO bob`,
+		`SET WRITE TARGET FOR bob = db0`,
+	} {
+		if res, err := s.QueryWithParams(q, adminParams); err != nil {
+			t.Fatal(err)
+		} else if strings.Contains(res, `"error"`) {
+			t.Fatalf("%s: unexpected results: %s", q, res)
+		}
+	}
+
+	if res, err := s.QueryWithParams(`SET WRITE TARGET FOR bob = db2`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if exp := `{"results":[{"statement_id":0,"error":"database not found: db2"}]}`; res != exp {
+		t.Fatalf("unexpected results: %s", res)
+	}
+
+	res, err := s.QueryWithParams(`CREATE TOKEN FOR bob WRITE TARGET db1.rp1`, adminParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created struct {
+		Results []struct {
+			Series []struct {
+				Values [][]string `json:"values"`
+			} `json:"series"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(res), &created); err != nil {
+		t.Fatal(err)
+	} else if len(created.Results) != 1 || len(created.Results[0].Series) != 1 || len(created.Results[0].Series[0].Values) != 1 {
+		t.Fatalf("unexpected results: %s", res)
+	}
+	token := created.Results[0].Series[0].Values[0][1]
+
+	// write writes a point as bob, or with the token if basic is false.
+	write := func(path, point string, basic bool) (int, string) {
+		req, err := http.NewRequest("POST", s.URL()+path, strings.NewReader(point))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if basic {
+			req.SetBasicAuth("bob", "b")
+		} else {
+			req.Header.Set("Authorization", "Token "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, strings.TrimSpace(string(b))
+	}
+
+	for _, tt := range []struct {
+		path  string
+		point string
+		basic bool
+		code  int
+	}{
+		// The writes without a target go to the target of the user, or of
+		// the token which overrides it.
+		{path: "/write", point: "cpu value=1 0", basic: true, code: http.StatusNoContent},
+		{path: "/api/v2/write", point: "cpu value=2 1", basic: true, code: http.StatusNoContent},
+		{path: "/write?db=db0&rp=autogen", point: "cpu value=3 2", basic: true, code: http.StatusNoContent},
+		{path: "/write", point: "mem value=1 0", code: http.StatusNoContent},
+		{path: "/write?db=db1", point: "mem value=2 1", code: http.StatusNoContent},
+
+		// The writes to another target are rejected.
+		{path: "/write?db=db1", point: "cpu value=4 3", basic: true, code: http.StatusForbidden},
+		{path: "/api/v2/write?bucket=db1", point: "cpu value=4 3", basic: true, code: http.StatusForbidden},
+		{path: "/write?db=db0", point: "mem value=3 2", code: http.StatusForbidden},
+		{path: "/write?db=db1&rp=autogen", point: "mem value=3 2", code: http.StatusForbidden},
+	} {
+		if code, body := write(tt.path, tt.point, tt.basic); code != tt.code {
+			t.Fatalf("%s: unexpected status: %d: %s", tt.path, code, body)
+		} else if code == http.StatusForbidden && !strings.Contains(body, "user is restricted to writing to") {
+			t.Fatalf("%s: unexpected body: %s", tt.path, body)
+		}
+	}
+
+	if res, err := s.QueryWithParams(`SELECT * FROM db0.autogen.cpu; SELECT * FROM db1.rp1.mem; SELECT * FROM db1.autogen.cpu`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if exp := `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["1970-01-01T00:00:00Z",1],["1970-01-01T00:00:00.000000001Z",2],["1970-01-01T00:00:00.000000002Z",3]]}]},{"statement_id":1,"series":[{"name":"mem","columns":["time","value"],"values":[["1970-01-01T00:00:00Z",1],["1970-01-01T00:00:00.000000001Z",2]]}]},{"statement_id":2}]}`; res != exp {
+		t.Fatalf("unexpected results:\ngot: %s\nexp: %s", res, exp)
+	} else if res, err := s.QueryWithParams(`SHOW TOKENS FOR bob`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(res, `"db1.rp1"]`) {
+		t.Fatalf("unexpected results: %s", res)
+	}
+
+	// Without a write target, the writes need a database again.
+	if _, err := s.QueryWithParams(`SET WRITE TARGET FOR bob = DEFAULT`, adminParams); err != nil {
+		t.Fatal(err)
+	} else if code, body := write("/write", "cpu value=5 4", true); code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d: %s", code, body)
+	} else if code, body := write("/write?db=db1", "cpu value=5 4", true); code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", code, body)
+	}
+}
+
+func TestServer_Query_Quotas(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		command string
+		exp     string
+	}{
+		{
+			command: `SET QUOTA ON db0 MAX SERIES 2 MAX WRITE RATE 100`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		{
+			command: `SET QUOTA ON db0 MAX MEASUREMENTS 5 MAX CONCURRENT WRITES 10`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		{
+			command: `SHOW QUOTAS`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["database","max_series","max_measurements","max_write_rate","max_concurrent_writes"],"values":[["db0",2,5,100,10]]}]}]}`,
+		},
+		{
+			command: `SET QUOTA ON db1 MAX SERIES 2`,
+			exp:     `{"results":[{"statement_id":0,"error":"database not found: db1"}]}`,
+		},
+	} {
+		if res, err := s.Query(tt.command); err != nil {
+			t.Fatal(err)
+		} else if res != tt.exp {
+			t.Fatalf("%s: unexpected results\nexp: %s\ngot: %s", tt.command, tt.exp, res)
+		}
+	}
+
+	// The points of the series beyond the quota are dropped.
+	_, err := s.Write("db0", "", "cpu,host=a value=1\ncpu,host=b value=1\ncpu,host=c value=1", nil)
+	if wr, ok := err.(WriteError); !ok || wr.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("unexpected error: %v", err)
+	} else if exp := "partial write: quota exceeded: max-series database=db0 limit=2 dropped=1"; !strings.Contains(wr.Body(), exp) {
+		t.Fatalf("unexpected body: %s", wr.Body())
+	}
+
+	// The writes beyond the write rate are rejected.
+	var points []string
+	for i := 0; i < 101; i++ {
+		points = append(points, "cpu,host=a value=1")
+	}
+	_, err = s.Write("db0", "", strings.Join(points, "\n"), nil)
+	if wr, ok := err.(WriteError); !ok || wr.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-	for i, query := range test.queries {
-		t.Run(query.name, func(t *testing.T) {
-			if i == 0 {
-				if err := test.init(s); err != nil {
-					t.Fatalf("test init failed: %s", err)
-				}
-			}
-			if query.skip {
-				t.Skipf("SKIP:: %s", query.name)
-			}
-			if err := query.Execute(s); err != nil {
-				t.Error(query.Error(err))
-			} else if !query.success() {
-				t.Error(query.failureMessage())
-			}
-		})
+	if res, err := s.Query(`DROP QUOTA ON db0; SHOW QUOTAS`); err != nil {
+		t.Fatal(err)
+	} else if exp := `{"results":[{"statement_id":0},{"statement_id":1,"series":[{"columns":["database","max_series","max_measurements","max_write_rate","max_concurrent_writes"]}]}]}`; res != exp {
+		t.Fatalf("unexpected results: %s", res)
+	} else if _, err := s.Write("db0", "", "cpu,host=c value=1", nil); err != nil {
+		t.Fatal(err)
 	}
 }
 
-func TestServer_Query_Fill(t *testing.T) {
+func TestServer_Query_ShardGroups(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -6685,157 +8340,112 @@ func TestServer_Query_Fill(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	writes := []string{
-		fmt.Sprintf(`fills val=3 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:02Z").UnixNano()),
-		fmt.Sprintf(`fills val=5 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:03Z").UnixNano()),
-		fmt.Sprintf(`fills val=4 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:06Z").UnixNano()),
-		fmt.Sprintf(`fills val=10 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:16Z").UnixNano()),
-	}
-
-	test := NewTest("db0", "rp0")
-	test.writes = Writes{
-		&Write{data: strings.Join(writes, "\n")},
-	}
-
-	test.addQueries([]*Query{
-		&Query{
-			name:    "fill with value",
-			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) FILL(1)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",1],["2009-11-10T23:00:15Z",10]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
+	for _, tt := range []struct {
+		command string
+		exp     string
+	}{
+		{
+			command: `CREATE SHARD GROUP ON db0 AT '2000-01-01T00:00:00Z'`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["id","database","retention_policy","start_time","end_time"],"values":[[1,"db0","rp0","1999-12-27T00:00:00Z","2000-01-03T00:00:00Z"]]}]}]}`,
 		},
-		&Query{
-			name:    "fill with value, WHERE all values match condition",
-			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' and val < 50 group by time(5s) FILL(1)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",1],["2009-11-10T23:00:15Z",10]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
+		{
+			command: `SHOW SHARD GROUPS ON db0.rp0`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"shard groups","columns":["id","database","retention_policy","start_time","end_time","expiry_time","truncated_time"],"values":[[1,"db0","rp0","1999-12-27T00:00:00Z","2000-01-03T00:00:00Z","2000-01-03T00:00:00Z",""]]}]}]}`,
 		},
-		&Query{
-			name:    "fill with value, WHERE no values match condition",
-			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' and val > 50 group by time(5s) FILL(1)`,
+		{
+			command: `TRUNCATE SHARD GROUPS ON db0 AT '2000-01-01T12:00:00Z'`,
 			exp:     `{"results":[{"statement_id":0}]}`,
-			params:  url.Values{"db": []string{"db0"}},
-		},
-		&Query{
-			name:    "fill with previous",
-			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) FILL(previous)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",4],["2009-11-10T23:00:15Z",10]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
-		},
-		&Query{
-			name:    "fill with none, i.e. clear out nulls",
-			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) FILL(none)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:15Z",10]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
 		},
-		&Query{
-			name:    "fill defaults to null",
-			command: `select mean(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",null],["2009-11-10T23:00:15Z",10]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
-		},
-		&Query{
-			name:    "fill defaults to 0 for count",
-			command: `select count(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","count"],"values":[["2009-11-10T23:00:00Z",2],["2009-11-10T23:00:05Z",1],["2009-11-10T23:00:10Z",0],["2009-11-10T23:00:15Z",1]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
-		},
-		&Query{
-			name:    "fill none drops 0s for count",
-			command: `select count(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) fill(none)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","count"],"values":[["2009-11-10T23:00:00Z",2],["2009-11-10T23:00:05Z",1],["2009-11-10T23:00:15Z",1]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
+		{
+			command: `SHOW SHARD GROUPS ON db0`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"shard groups","columns":["id","database","retention_policy","start_time","end_time","expiry_time","truncated_time"],"values":[[1,"db0","rp0","1999-12-27T00:00:00Z","2000-01-03T00:00:00Z","2000-01-03T00:00:00Z","2000-01-01T12:00:00Z"]]}]}]}`,
 		},
-		&Query{
-			name:    "fill previous overwrites 0s for count",
-			command: `select count(val) from fills where time >= '2009-11-10T23:00:00Z' and time < '2009-11-10T23:00:20Z' group by time(5s) fill(previous)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","count"],"values":[["2009-11-10T23:00:00Z",2],["2009-11-10T23:00:05Z",1],["2009-11-10T23:00:10Z",1],["2009-11-10T23:00:15Z",1]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
+		{
+			command: `SHOW SHARD GROUPS ON db1`,
+			exp:     `{"results":[{"statement_id":0,"error":"database not found: db1"}]}`,
 		},
-		&Query{
-			name:    "fill with implicit start time",
-			command: `select mean(val) from fills where time < '2009-11-10T23:00:20Z' group by time(5s)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2009-11-10T23:00:00Z",4],["2009-11-10T23:00:05Z",4],["2009-11-10T23:00:10Z",null],["2009-11-10T23:00:15Z",10]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
+		{
+			command: `CREATE SHARD GROUP ON db0.rp1`,
+			exp:     `{"results":[{"statement_id":0,"error":"retention policy not found: rp1"}]}`,
 		},
-	}...)
-
-	for i, query := range test.queries {
-		t.Run(query.name, func(t *testing.T) {
-			if i == 0 {
-				if err := test.init(s); err != nil {
-					t.Fatalf("test init failed: %s", err)
-				}
-			}
-			if query.skip {
-				t.Skipf("SKIP:: %s", query.name)
-			}
-			if err := query.Execute(s); err != nil {
-				t.Error(query.Error(err))
-			} else if !query.success() {
-				t.Error(query.failureMessage())
-			}
-		})
+	} {
+		if res, err := s.Query(tt.command); err != nil {
+			t.Fatal(err)
+		} else if res != tt.exp {
+			t.Fatalf("%s: unexpected results\nexp: %s\ngot: %s", tt.command, tt.exp, res)
+		}
 	}
 }
 
-func TestServer_Query_ImplicitFill(t *testing.T) {
+func TestServer_Query_Rename(t *testing.T) {
 	t.Parallel()
-	config := NewConfig()
-	config.Coordinator.MaxSelectBucketsN = 5
-	s := OpenServer(config)
+	s := OpenServer(NewConfig())
 	defer s.Close()
 
 	if err := s.CreateDatabaseAndRetentionPolicy("db0", NewRetentionPolicySpec("rp0", 1, 0), true); err != nil {
 		t.Fatal(err)
+	} else if _, err := s.CreateDatabase("db1"); err != nil {
+		t.Fatal(err)
 	}
-
-	writes := []string{
-		fmt.Sprintf(`fills val=1 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T11:30:00Z").UnixNano()),
-		fmt.Sprintf(`fills val=3 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T12:00:00Z").UnixNano()),
-		fmt.Sprintf(`fills val=5 %d`, mustParseTime(time.RFC3339Nano, "2010-01-01T16:30:00Z").UnixNano()),
-	}
-
-	test := NewTest("db0", "rp0")
-	test.writes = Writes{
-		&Write{data: strings.Join(writes, "\n")},
+	if _, err := s.Write("db0", "rp0", "cpu,host=a value=1 1000000000\ncpu,host=b value=2 2000000000\nmem,host=a value=3 1000000000", nil); err != nil {
+		t.Fatal(err)
 	}
 
-	test.addQueries([]*Query{
-		&Query{
-			name:    "fill with implicit start",
-			command: `select mean(val) from fills where time < '2010-01-01T18:00:00Z' group by time(1h)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2010-01-01T16:00:00Z",5],["2010-01-01T17:00:00Z",null]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
+	for _, tt := range []struct {
+		command string
+		exp     string
+	}{
+		{
+			command: `RENAME MEASUREMENT cpu TO mem ON db0`,
+			exp:     `{"results":[{"statement_id":0,"error":"measurement already exists"}]}`,
 		},
-		&Query{
-			name:    "fill with implicit start - max select buckets",
-			command: `select mean(val) from fills where time < '2010-01-01T17:00:00Z' group by time(1h)`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"fills","columns":["time","mean"],"values":[["2010-01-01T12:00:00Z",3],["2010-01-01T13:00:00Z",null],["2010-01-01T14:00:00Z",null],["2010-01-01T15:00:00Z",null],["2010-01-01T16:00:00Z",5]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
+		{
+			command: `RENAME MEASUREMENT cpu TO load ON db0`,
+			exp:     `{"results":[{"statement_id":0}]}`,
 		},
-	}...)
+		{
+			command: `SHOW MEASUREMENTS ON db0`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["load"],["mem"]]}]}]}`,
+		},
+		{
+			command: `SELECT * FROM db0.rp0.load`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"load","columns":["time","host","value"],"values":[["1970-01-01T00:00:01Z","a",1],["1970-01-01T00:00:02Z","b",2]]}]}]}`,
+		},
+		{
+			command: `RENAME DATABASE db0 TO db1`,
+			exp:     `{"results":[{"statement_id":0,"error":"database already exists"}]}`,
+		},
+		{
+			command: `RENAME DATABASE db0 TO db2`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		{
+			command: `SHOW DATABASES`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"databases","columns":["name"],"values":[["db2"],["db1"]]}]}]}`,
+		},
+		{
+			command: `SELECT * FROM db2.rp0.load`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"load","columns":["time","host","value"],"values":[["1970-01-01T00:00:01Z","a",1],["1970-01-01T00:00:02Z","b",2]]}]}]}`,
+		},
+		{
+			command: `RENAME DATABASE db0 TO db3`,
+			exp:     `{"results":[{"statement_id":0,"error":"database not found: db0"}]}`,
+		},
+	} {
+		if res, err := s.Query(tt.command); err != nil {
+			t.Fatal(err)
+		} else if res != tt.exp {
+			t.Fatalf("%s: unexpected results\nexp: %s\ngot: %s", tt.command, tt.exp, res)
+		}
+	}
 
-	for i, query := range test.queries {
-		t.Run(query.name, func(t *testing.T) {
-			if i == 0 {
-				if err := test.init(s); err != nil {
-					t.Fatalf("test init failed: %s", err)
-				}
-			}
-			if query.skip {
-				t.Skipf("SKIP:: %s", query.name)
-			}
-			if err := query.Execute(s); err != nil {
-				t.Error(query.Error(err))
-			} else if !query.success() {
-				t.Error(query.failureMessage())
-			}
-		})
+	// The renamed database accepts writes.
+	if _, err := s.Write("db2", "rp0", "load,host=c value=4 3000000000", nil); err != nil {
+		t.Fatal(err)
 	}
 }
 
-func TestServer_Query_TimeZone(t *testing.T) {
+func TestServer_Query_CalendarInterval(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
 	defer s.Close()
@@ -6844,28 +8454,10 @@ func TestServer_Query_TimeZone(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// Write a point at noon UTC on every day of 2000.
 	var writes []string
-	for _, start := range []time.Time{
-		// One day before DST starts.
-		time.Date(2000, 4, 1, 0, 0, 0, 0, LosAngeles),
-		// Middle of DST. No change.
-		time.Date(2000, 6, 1, 0, 0, 0, 0, LosAngeles),
-		// One day before DST ends.
-		time.Date(2000, 10, 28, 0, 0, 0, 0, LosAngeles),
-	} {
-		ts := start
-		// Write every hour for 4 days.
-		for i := 0; i < 24*4; i++ {
-			writes = append(writes, fmt.Sprintf(`cpu,interval=daily value=0 %d`, ts.UnixNano()))
-			ts = ts.Add(time.Hour)
-		}
-
-		// Write every 5 minutes for 3 hours. Start at 1 on the day with DST.
-		ts = start.Add(25 * time.Hour)
-		for i := 0; i < 12*3; i++ {
-			writes = append(writes, fmt.Sprintf(`cpu,interval=hourly value=0 %d`, ts.UnixNano()))
-			ts = ts.Add(5 * time.Minute)
-		}
+	for ts := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC); ts.Year() == 2000; ts = ts.AddDate(0, 0, 1) {
+		writes = append(writes, fmt.Sprintf(`cpu value=1 %d`, ts.UnixNano()))
 	}
 
 	test := NewTest("db0", "rp0")
@@ -6875,39 +8467,33 @@ func TestServer_Query_TimeZone(t *testing.T) {
 
 	test.addQueries([]*Query{
 		&Query{
-			name:    "timezone offset - dst start - daily",
-			command: `SELECT count(value) FROM cpu WHERE time >= '2000-04-02T00:00:00-08:00' AND time < '2000-04-04T00:00:00-07:00' AND interval = 'daily' GROUP BY time(1d) TZ('America/Los_Angeles')`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-04-02T00:00:00-08:00",23],["2000-04-03T00:00:00-07:00",24]]}]}]}`,
-			params:  url.Values{"db": []string{"db0"}},
-		},
-		&Query{
-			name:    "timezone offset - no change - daily",
-			command: `SELECT count(value) FROM cpu WHERE time >= '2000-06-01T00:00:00-07:00' AND time < '2000-06-03T00:00:00-07:00' AND interval = 'daily' GROUP BY time(1d) TZ('America/Los_Angeles')`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-06-01T00:00:00-07:00",24],["2000-06-02T00:00:00-07:00",24]]}]}]}`,
+			name:    "month",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-05-01T00:00:00Z' GROUP BY time(1mo)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-01-01T00:00:00Z",31],["2000-02-01T00:00:00Z",29],["2000-03-01T00:00:00Z",31],["2000-04-01T00:00:00Z",30]]}]}]}`,
 			params:  url.Values{"db": []string{"db0"}},
 		},
 		&Query{
-			name:    "timezone offset - dst end - daily",
-			command: `SELECT count(value) FROM cpu WHERE time >= '2000-10-29T00:00:00-07:00' AND time < '2000-10-31T00:00:00-08:00' AND interval = 'daily' GROUP BY time(1d) TZ('America/Los_Angeles')`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-10-29T00:00:00-07:00",25],["2000-10-30T00:00:00-08:00",24]]}]}]}`,
+			name:    "quarter",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2001-01-01T00:00:00Z' GROUP BY time(1q)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-01-01T00:00:00Z",91],["2000-04-01T00:00:00Z",91],["2000-07-01T00:00:00Z",92],["2000-10-01T00:00:00Z",92]]}]}]}`,
 			params:  url.Values{"db": []string{"db0"}},
 		},
 		&Query{
-			name:    "timezone offset - dst start - hourly",
-			command: `SELECT count(value) FROM cpu WHERE time >= '2000-04-02T01:00:00-08:00' AND time < '2000-04-02T04:00:00-07:00' AND interval = 'hourly' GROUP BY time(1h) TZ('America/Los_Angeles')`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-04-02T01:00:00-08:00",12],["2000-04-02T03:00:00-07:00",12]]}]}]}`,
+			name:    "year",
+			command: `SELECT count(value) FROM cpu WHERE time >= '1999-01-01T00:00:00Z' AND time < '2001-01-01T00:00:00Z' GROUP BY time(1y) fill(none)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-01-01T00:00:00Z",366]]}]}]}`,
 			params:  url.Values{"db": []string{"db0"}},
 		},
 		&Query{
-			name:    "timezone offset - no change - hourly",
-			command: `SELECT count(value) FROM cpu WHERE time >= '2000-06-02T01:00:00-07:00' AND time < '2000-06-02T03:00:00-07:00' AND interval = 'hourly' GROUP BY time(1h) TZ('America/Los_Angeles')`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-06-02T01:00:00-07:00",12],["2000-06-02T02:00:00-07:00",12]]}]}]}`,
+			name:    "month with offset",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-01-15T00:00:00Z' AND time < '2000-03-15T00:00:00Z' GROUP BY time(1mo, 14d)`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-01-15T00:00:00Z",31],["2000-02-15T00:00:00Z",29]]}]}]}`,
 			params:  url.Values{"db": []string{"db0"}},
 		},
 		&Query{
-			name:    "timezone offset - dst end - hourly",
-			command: `SELECT count(value) FROM cpu WHERE time >= '2000-10-29T01:00:00-07:00' AND time < '2000-10-29T02:00:00-08:00' AND interval = 'hourly' GROUP BY time(1h) TZ('America/Los_Angeles')`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-10-29T01:00:00-07:00",12],["2000-10-29T01:00:00-08:00",12]]}]}]}`,
+			name:    "month with time zone",
+			command: `SELECT count(value) FROM cpu WHERE time >= '2000-03-01T00:00:00-08:00' AND time < '2000-06-01T00:00:00-07:00' GROUP BY time(1mo) TZ('America/Los_Angeles')`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count"],"values":[["2000-03-01T00:00:00-08:00",31],["2000-04-01T00:00:00-08:00",30],["2000-05-01T00:00:00-07:00",31]]}]}]}`,
 			params:  url.Values{"db": []string{"db0"}},
 		},
 	}...)
@@ -7328,6 +8914,42 @@ func TestServer_Query_ShowSeries(t *testing.T) {
 			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["key"],"values":[["cpu,host=server01,region=useast"],["cpu,host=server02,region=useast"]]}]}]}`,
 			params:  url.Values{"db": []string{"db0"}},
 		},
+		&Query{
+			name:    `show series with limit`,
+			command: "SHOW SERIES LIMIT 3",
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["key"],"values":[["cpu,host=server01"],["cpu,host=server01,region=useast"],["cpu,host=server01,region=uswest"]]}],"next":"Y3B1LGhvc3Q9c2VydmVyMDEscmVnaW9uPXVzd2VzdA"}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    `show series after continuation token`,
+			command: "SHOW SERIES LIMIT 3 AFTER 'Y3B1LGhvc3Q9c2VydmVyMDEscmVnaW9uPXVzd2VzdA'",
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["key"],"values":[["cpu,host=server02,region=useast"],["disk,host=server03,region=caeast"],["gpu,host=server02,region=useast"]]}],"next":"Z3B1LGhvc3Q9c2VydmVyMDIscmVnaW9uPXVzZWFzdA"}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    `show series after last continuation token`,
+			command: "SHOW SERIES LIMIT 3 AFTER 'Z3B1LGhvc3Q9c2VydmVyMDIscmVnaW9uPXVzZWFzdA'",
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["key"],"values":[["gpu,host=server03,region=caeast"]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    `show series after continuation token with where tag`,
+			command: "SHOW SERIES FROM cpu WHERE region = 'useast' AFTER 'Y3B1LGhvc3Q9c2VydmVyMDEscmVnaW9uPXVzd2VzdA'",
+			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["key"],"values":[["cpu,host=server02,region=useast"]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    `show series after continuation token with time`,
+			command: "SHOW SERIES WHERE time > 0 AFTER 'Y3B1LGhvc3Q9c2VydmVyMDEscmVnaW9uPXVzd2VzdA'",
+			exp:     `{"results":[{"statement_id":0,"error":"SHOW SERIES doesn't support AFTER with time in WHERE clause"}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    `show series after invalid continuation token`,
+			command: "SHOW SERIES AFTER 'cpu,host=a'",
+			exp:     `{"results":[{"statement_id":0,"error":"invalid continuation token: \"cpu,host=a\""}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
 	}...)
 
 	var once sync.Once
@@ -8188,6 +9810,24 @@ func TestServer_Query_ShowTagKeys(t *testing.T) {
 			exp:     `{"results":[{"statement_id":0}]}`,
 			params:  url.Values{"db": []string{"db0"}},
 		},
+		&Query{
+			name:    "show tag keys with limit",
+			command: "SHOW TAG KEYS LIMIT 1",
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["tagKey"],"values":[["host"]]}],"next":"Y3B1AGhvc3Q"}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "show tag keys after continuation token",
+			command: "SHOW TAG KEYS LIMIT 1 AFTER 'Y3B1AGhvc3Q'",
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["tagKey"],"values":[["region"]]},{"name":"disk","columns":["tagKey"],"values":[["host"]]}],"next":"ZGlzawBob3N0"}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "show tag keys after last continuation token",
+			command: "SHOW TAG KEYS FROM cpu WHERE host = 'server01' LIMIT 2 AFTER 'Y3B1AGhvc3Q'",
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["tagKey"],"values":[["region"]]}]}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
 	}...)
 
 	var initialized bool
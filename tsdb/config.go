@@ -3,6 +3,8 @@ package tsdb
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
@@ -73,18 +75,42 @@ const (
 	// DefaultSeriesIDSetCacheSize is the default number of series ID sets to cache in the TSI index.
 	DefaultSeriesIDSetCacheSize = 100
 
+	// DefaultShardDirPolicy is the default policy placing new shards in the
+	// data directories.
+	DefaultShardDirPolicy = ShardDirPolicyMostFree
+
 	// DefaultSeriesFileMaxConcurrentSnapshotCompactions is the maximum number of concurrent series
 	// partition snapshot compactions that can run at one time.
 	// A value of 0 results in runtime.GOMAXPROCS(0).
 	DefaultSeriesFileMaxConcurrentSnapshotCompactions = 0
 )
 
+// The policies placing new shards in the data directories.
+const (
+	// ShardDirPolicyMostFree places a new shard in the directory with the
+	// most free disk space.
+	ShardDirPolicyMostFree = "most-free"
+
+	// ShardDirPolicyFewestShards places a new shard in the directory with
+	// the fewest shards.
+	ShardDirPolicyFewestShards = "fewest-shards"
+)
+
 // Config holds the configuration for the tsbd package.
 type Config struct {
 	Dir    string `toml:"dir"`
 	Engine string `toml:"-"`
 	Index  string `toml:"index-version"`
 
+	// ShardDirs are additional directories, like directories on other disks,
+	// that new shards are placed in along with Dir. The series files of the
+	// databases always stay in Dir.
+	ShardDirs []string `toml:"shard-dirs"`
+
+	// ShardDirPolicy determines the directory a new shard is placed in when
+	// there are ShardDirs.
+	ShardDirPolicy string `toml:"shard-dir-policy"`
+
 	// General WAL configuration options
 	WALDir string `toml:"wal-dir"`
 
@@ -129,6 +155,19 @@ type Config struct {
 	// A value of 0 disables the limit.
 	MaxValuesPerTag int `toml:"max-values-per-tag"`
 
+	// MaxTagsPerPoint is the maximum number of tags of a point. MaxTagKeyLength
+	// and MaxFieldKeyLength are the maximum lengths, in bytes, of the tag and
+	// field keys of a point, and MaxStringFieldLength is the maximum length of
+	// its string field values. The points that exceed them are dropped from
+	// writes. A value of 0 disables the limit.
+	MaxTagsPerPoint      int `toml:"max-tags-per-point"`
+	MaxTagKeyLength      int `toml:"max-tag-key-length"`
+	MaxFieldKeyLength    int `toml:"max-field-key-length"`
+	MaxStringFieldLength int `toml:"max-string-field-length"`
+
+	// DatabasePointLimits override the point limits above for some databases.
+	DatabasePointLimits []DatabasePointLimitsConfig `toml:"database-point-limits"`
+
 	// MaxConcurrentCompactions is the maximum number of concurrent level and full compactions
 	// that can be running at one time across all shards.  Compactions scheduled to run when the
 	// limit is reached are blocked until a running compaction completes.  Snapshot compactions are
@@ -166,12 +205,25 @@ type Config struct {
 	TSMWillNeed bool `toml:"tsm-use-madv-willneed"`
 }
 
+// DatabasePointLimitsConfig represents the point limits of a database. A
+// limit of 0 is the limit of the data section, and a negative limit disables
+// it for the database.
+type DatabasePointLimitsConfig struct {
+	Database             string `toml:"database"`
+	MaxTagsPerPoint      int    `toml:"max-tags-per-point"`
+	MaxTagKeyLength      int    `toml:"max-tag-key-length"`
+	MaxFieldKeyLength    int    `toml:"max-field-key-length"`
+	MaxStringFieldLength int    `toml:"max-string-field-length"`
+}
+
 // NewConfig returns the default configuration for tsdb.
 func NewConfig() Config {
 	return Config{
 		Engine: DefaultEngine,
 		Index:  DefaultIndex,
 
+		ShardDirPolicy: DefaultShardDirPolicy,
+
 		StrictErrorHandling: false,
 		QueryLogEnabled:     true,
 
@@ -205,6 +257,22 @@ func (c *Config) Validate() error {
 		return errors.New("Data.WALDir must be specified")
 	}
 
+	dirs := map[string]struct{}{filepath.Clean(c.Dir): {}}
+	for _, dir := range c.ShardDirs {
+		if dir == "" {
+			return errors.New("shard-dirs must not contain empty directories")
+		} else if _, ok := dirs[filepath.Clean(dir)]; ok {
+			return fmt.Errorf("shard-dirs: duplicate directory %s", dir)
+		}
+		dirs[filepath.Clean(dir)] = struct{}{}
+	}
+
+	switch c.ShardDirPolicy {
+	case "", ShardDirPolicyMostFree, ShardDirPolicyFewestShards:
+	default:
+		return fmt.Errorf("unrecognized shard-dir-policy %q", c.ShardDirPolicy)
+	}
+
 	if c.MaxConcurrentCompactions < 0 {
 		return errors.New("max-concurrent-compactions must be non-negative")
 	}
@@ -213,6 +281,20 @@ func (c *Config) Validate() error {
 		return errors.New("max-concurrent-deletes must be positive")
 	}
 
+	if c.MaxTagsPerPoint < 0 || c.MaxTagKeyLength < 0 || c.MaxFieldKeyLength < 0 || c.MaxStringFieldLength < 0 {
+		return errors.New("point limits must be non-negative")
+	}
+
+	databases := make(map[string]struct{}, len(c.DatabasePointLimits))
+	for _, l := range c.DatabasePointLimits {
+		if l.Database == "" {
+			return errors.New("database-point-limits: database must be specified")
+		} else if _, ok := databases[l.Database]; ok {
+			return fmt.Errorf("database-point-limits: duplicate database %s", l.Database)
+		}
+		databases[l.Database] = struct{}{}
+	}
+
 	if c.SeriesIDSetCacheSize < 0 {
 		return errors.New("series-id-set-cache-size must be non-negative")
 	}
@@ -246,10 +328,40 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// PointLimits returns the point limits of a database.
+func (c *Config) PointLimits(database string) PointLimits {
+	l := PointLimits{
+		MaxTagsPerPoint:      c.MaxTagsPerPoint,
+		MaxTagKeyLength:      c.MaxTagKeyLength,
+		MaxFieldKeyLength:    c.MaxFieldKeyLength,
+		MaxStringFieldLength: c.MaxStringFieldLength,
+	}
+	for _, o := range c.DatabasePointLimits {
+		if o.Database != database {
+			continue
+		}
+		override := func(limit *int, v int) {
+			if v < 0 {
+				*limit = 0
+			} else if v > 0 {
+				*limit = v
+			}
+		}
+		override(&l.MaxTagsPerPoint, o.MaxTagsPerPoint)
+		override(&l.MaxTagKeyLength, o.MaxTagKeyLength)
+		override(&l.MaxFieldKeyLength, o.MaxFieldKeyLength)
+		override(&l.MaxStringFieldLength, o.MaxStringFieldLength)
+		break
+	}
+	return l
+}
+
 // Diagnostics returns a diagnostics representation of a subset of the Config.
 func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
 		"dir":                                    c.Dir,
+		"shard-dirs":                             strings.Join(c.ShardDirs, ", "),
+		"shard-dir-policy":                       c.ShardDirPolicy,
 		"wal-dir":                                c.WALDir,
 		"wal-fsync-delay":                        c.WALFsyncDelay,
 		"strict-error-handling":                  c.StrictErrorHandling,
@@ -259,6 +371,10 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 		"compact-full-write-cold-duration":       c.CompactFullWriteColdDuration,
 		"max-series-per-database":                c.MaxSeriesPerDatabase,
 		"max-values-per-tag":                     c.MaxValuesPerTag,
+		"max-tags-per-point":                     c.MaxTagsPerPoint,
+		"max-tag-key-length":                     c.MaxTagKeyLength,
+		"max-field-key-length":                   c.MaxFieldKeyLength,
+		"max-string-field-length":                c.MaxStringFieldLength,
 		"max-concurrent-compactions":             c.MaxConcurrentCompactions,
 		"max-index-log-file-size":                c.MaxIndexLogFileSize,
 		"series-id-set-cache-size":               c.SeriesIDSetCacheSize,
@@ -16,6 +16,14 @@ dir = "/var/lib/influxdb/data"
 wal-dir = "/var/lib/influxdb/wal"
 wal-fsync-delay = "10s"
 tsm-use-madv-willneed = true
+shard-dirs = ["/mnt/disk1/influxdb/data", "/mnt/disk2/influxdb/data"]
+shard-dir-policy = "fewest-shards"
+max-tags-per-point = 20
+
+[[database-point-limits]]
+database = "telegraf"
+max-tags-per-point = 50
+max-string-field-length = 1024
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -36,6 +44,18 @@ tsm-use-madv-willneed = true
 	if got, exp := c.TSMWillNeed, true; got != exp {
 		t.Errorf("unexpected tsm-madv-willneed:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
 	}
+	if got, exp := len(c.ShardDirs), 2; got != exp || c.ShardDirs[1] != "/mnt/disk2/influxdb/data" {
+		t.Errorf("unexpected shard-dirs: %v", c.ShardDirs)
+	}
+	if got, exp := c.ShardDirPolicy, tsdb.ShardDirPolicyFewestShards; got != exp {
+		t.Errorf("unexpected shard-dir-policy:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
+	if got, exp := c.PointLimits("telegraf"), (tsdb.PointLimits{MaxTagsPerPoint: 50, MaxStringFieldLength: 1024}); got != exp {
+		t.Errorf("unexpected point limits of telegraf: %+v", got)
+	}
+	if got, exp := c.PointLimits("db0"), (tsdb.PointLimits{MaxTagsPerPoint: 20}); got != exp {
+		t.Errorf("unexpected point limits of db0: %+v", got)
+	}
 }
 
 func TestConfig_Validate_Error(t *testing.T) {
@@ -75,6 +95,30 @@ func TestConfig_Validate_Error(t *testing.T) {
 	if err := c.Validate(); err == nil || err.Error() != "series-id-set-cache-size must be non-negative" {
 		t.Errorf("unexpected error: %s", err)
 	}
+
+	c.SeriesIDSetCacheSize = tsdb.DefaultSeriesIDSetCacheSize
+	c.ShardDirs = []string{"/mnt/disk1/influxdb/data", "/var/lib/influxdb/data/"}
+	if err := c.Validate(); err == nil || err.Error() != "shard-dirs: duplicate directory /var/lib/influxdb/data/" {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	c.ShardDirs = []string{"/mnt/disk1/influxdb/data"}
+	c.ShardDirPolicy = "random"
+	if err := c.Validate(); err == nil || err.Error() != `unrecognized shard-dir-policy "random"` {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	c.ShardDirPolicy = ""
+	c.MaxTagsPerPoint = -1
+	if err := c.Validate(); err == nil || err.Error() != "point limits must be non-negative" {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	c.MaxTagsPerPoint = 0
+	c.DatabasePointLimits = []tsdb.DatabasePointLimitsConfig{{Database: "db0"}, {Database: "db0"}}
+	if err := c.Validate(); err == nil || err.Error() != "database-point-limits: duplicate database db0" {
+		t.Errorf("unexpected error: %s", err)
+	}
 }
 
 func TestConfig_ByteSizes(t *testing.T) {
@@ -81,6 +81,7 @@ type Engine interface {
 	Statistics(tags map[string]string) []models.Statistic
 	LastModified() time.Time
 	DiskSize() int64
+	Usage() EngineUsage
 	IsIdle() (bool, string)
 	Free() error
 
@@ -161,6 +162,22 @@ func NewEngine(id uint64, i Index, path string, walPath string, sfile *SeriesFil
 	return engine, nil
 }
 
+// EngineUsage represents the resources used by the data of a shard.
+type EngineUsage struct {
+	// Size of the TSM files and number of them.
+	TSMBytes int64
+	TSMFiles int64
+
+	// Size of the WAL segments and of the values in the cache.
+	WALBytes   int64
+	CacheBytes int64
+
+	// Number of compactions running, including the snapshots of the cache,
+	// and of the compactions waiting to run.
+	CompactionsActive int64
+	CompactionsQueued int64
+}
+
 // EngineOptions represents the options used to initialize the engine.
 type EngineOptions struct {
 	EngineVersion string
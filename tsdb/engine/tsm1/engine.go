@@ -39,7 +39,7 @@ import (
 )
 
 //go:generate -command tmpl go run github.com/benbjohnson/tmpl
-//go:generate tmpl -data=@iterator.gen.go.tmpldata iterator.gen.go.tmpl engine.gen.go.tmpl array_cursor.gen.go.tmpl array_cursor_iterator.gen.go.tmpl
+//go:generate tmpl -data=@iterator.gen.go.tmpldata iterator.gen.go.tmpl engine.gen.go.tmpl array_cursor.gen.go.tmpl array_cursor_iterator.gen.go.tmpl engine_aggregate.gen.go.tmpl
 // The file store generate uses a custom modified tmpl
 // to support adding templated data from the command line.
 // This can probably be worked into the upstream tmpl
@@ -740,6 +740,29 @@ func (e *Engine) DiskSize() int64 {
 	return e.FileStore.DiskSizeBytes() + walDiskSizeBytes
 }
 
+// Usage returns the resources used by the data of the engine.
+func (e *Engine) Usage() tsdb.EngineUsage {
+	u := tsdb.EngineUsage{
+		TSMBytes:   e.FileStore.DiskSizeBytes(),
+		TSMFiles:   int64(e.FileStore.Count()),
+		CacheBytes: int64(e.Cache.Size()),
+	}
+	if e.WALEnabled {
+		u.WALBytes = e.WAL.DiskSizeBytes()
+	}
+
+	u.CompactionsActive = atomic.LoadInt64(&e.stats.CacheCompactionsActive) +
+		atomic.LoadInt64(&e.stats.TSMOptimizeCompactionsActive) +
+		atomic.LoadInt64(&e.stats.TSMFullCompactionsActive)
+	u.CompactionsQueued = atomic.LoadInt64(&e.stats.TSMOptimizeCompactionsQueue) +
+		atomic.LoadInt64(&e.stats.TSMFullCompactionsQueue)
+	for i := range e.stats.TSMCompactionsActive {
+		u.CompactionsActive += atomic.LoadInt64(&e.stats.TSMCompactionsActive[i])
+		u.CompactionsQueued += atomic.LoadInt64(&e.stats.TSMCompactionsQueue[i])
+	}
+	return u
+}
+
 // Open opens and initializes the engine.
 func (e *Engine) Open() error {
 	if err := os.MkdirAll(e.path, 0777); err != nil {
@@ -1419,14 +1442,22 @@ func (e *Engine) WritePoints(points []models.Point, tracker tsdb.StatsTracker) e
 	defer e.mu.RUnlock()
 
 	// first try to write to the cache
+	start := time.Now()
 	if err := e.Cache.WriteMulti(values); err != nil {
 		return err
 	}
+	if tracker.ObservedStage != nil {
+		tracker.ObservedStage(tsdb.WriteStageEngineWrite, time.Since(start))
+	}
 
 	if e.WALEnabled {
+		start = time.Now()
 		if _, err := e.WAL.WriteMulti(values); err != nil {
 			return err
 		}
+		if tracker.ObservedStage != nil {
+			tracker.ObservedStage(tsdb.WriteStageWALFsync, time.Since(start))
+		}
 	}
 
 	if tracker.AddedPoints != nil {
@@ -2647,10 +2678,17 @@ func (e *Engine) createCallIterator(ctx context.Context, measurement string, cal
 
 			// Wrap each series in a call iterator.
 			for i, input := range inputs {
+				_, aggregated := input.(blockAggregateIterator)
 				if opt.InterruptCh != nil {
 					input = query.NewInterruptIterator(input, opt.InterruptCh)
 				}
 
+				// Series read from TSM blocks are already aggregated.
+				if aggregated {
+					inputs[i] = input
+					continue
+				}
+
 				itr, err := query.NewCallIterator(input, opt)
 				if err != nil {
 					query.Iterators(inputs).Close()
@@ -2828,13 +2866,25 @@ func (e *Engine) createTagSetIterators(ctx context.Context, ref *influxql.VarRef
 func (e *Engine) createTagSetGroupIterators(ctx context.Context, ref *influxql.VarRef, name string, seriesKeys []string, t *query.TagSet, filters []influxql.Expr, opt query.IteratorOptions) ([]query.Iterator, error) {
 	itrs := make([]query.Iterator, 0, len(seriesKeys))
 	for i, seriesKey := range seriesKeys {
-		var conditionFields []influxql.VarRef
-		if filters[i] != nil {
-			// Retrieve non-time fields from this series filter and filter out tags.
-			conditionFields = influxql.ExprNames(filters[i])
+		var (
+			itr query.Iterator
+			err error
+		)
+
+		// Compute the call from the TSM blocks of the series if possible.
+		if call, ok := opt.Expr.(*influxql.Call); ok && filters[i] == nil {
+			itr, err = e.createBlockAggregateIterator(ctx, call, name, seriesKey, opt)
 		}
 
-		itr, err := e.createVarRefSeriesIterator(ctx, ref, name, seriesKey, t, filters[i], conditionFields, opt)
+		if err == nil && itr == nil {
+			var conditionFields []influxql.VarRef
+			if filters[i] != nil {
+				// Retrieve non-time fields from this series filter and filter out tags.
+				conditionFields = influxql.ExprNames(filters[i])
+			}
+
+			itr, err = e.createVarRefSeriesIterator(ctx, ref, name, seriesKey, t, filters[i], conditionFields, opt)
+		}
 		if err != nil {
 			return itrs, err
 		} else if itr == nil {
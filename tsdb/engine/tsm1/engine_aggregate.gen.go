@@ -0,0 +1,1129 @@
+// Generated by tmpl
+// https://github.com/benbjohnson/tmpl
+//
+// DO NOT EDIT!
+// Source: engine_aggregate.gen.go.tmpl
+
+package tsm1
+
+import (
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// floatBlockAggregateIterator computes an aggregate of a float field for a
+// single series directly from the TSM blocks of the series.
+type floatBlockAggregateIterator struct {
+	cur *KeyCursor
+	fn  blockAggregateFunc
+	opt query.IteratorOptions
+
+	// values holds the decoded values of the current block and pos is the
+	// index of the next value to aggregate.
+	values tsdb.FloatArray
+	pos    int
+	done   bool
+
+	// buf is used to decode blocks that are aggregated as a whole.
+	buf tsdb.FloatArray
+
+	window struct {
+		start int64
+		n     int64
+		time  int64
+		value float64
+
+		// last is the block holding the last value of the window when
+		// that block has not been decoded.
+		last *location
+	}
+
+	point query.FloatPoint
+	stats query.IteratorStats
+}
+
+func newFloatBlockAggregateIterator(name string, tags query.Tags, fn blockAggregateFunc, opt query.IteratorOptions, cur *KeyCursor) *floatBlockAggregateIterator {
+	itr := &floatBlockAggregateIterator{
+		cur:   cur,
+		fn:    fn,
+		opt:   opt,
+		stats: query.IteratorStats{SeriesN: 1},
+	}
+	itr.point.Name = name
+	itr.point.Tags = tags
+	return itr
+}
+
+// Next returns the aggregate of the next window.
+func (itr *floatBlockAggregateIterator) Next() (*query.FloatPoint, error) {
+	if ok, err := itr.nextWindow(); err != nil || !ok {
+		return nil, err
+	}
+
+	p := &itr.point
+	p.Time, p.Value, p.Aggregated = itr.window.time, itr.window.value, uint32(itr.window.n)
+	if itr.fn == aggregateSum {
+		p.Time = itr.window.start
+	}
+	return p, nil
+}
+
+// Stats returns stats on the points processed.
+func (itr *floatBlockAggregateIterator) Stats() query.IteratorStats { return itr.stats }
+
+// Close closes the iterator.
+func (itr *floatBlockAggregateIterator) Close() error {
+	itr.cur.Close()
+	return nil
+}
+
+// windowCount returns the start time and the number of points of the window
+// read by the last call to nextWindow.
+func (itr *floatBlockAggregateIterator) windowCount() (int64, int64) {
+	return itr.window.start, itr.window.n
+}
+
+// nextWindow aggregates the points of the next window that has points.
+// It returns false when there are no more windows.
+func (itr *floatBlockAggregateIterator) nextWindow() (bool, error) {
+	itr.window.n, itr.window.last = 0, nil
+	for {
+		// Aggregate the decoded values that are left from the last block.
+		for ; itr.pos < itr.values.Len(); itr.pos++ {
+			t := itr.values.Timestamps[itr.pos]
+			if t < itr.opt.StartTime {
+				continue
+			} else if t > itr.opt.EndTime {
+				// Blocks are read in ascending order so the remaining
+				// values are outside of the time range too.
+				itr.pos, itr.done = itr.values.Len(), true
+				break
+			}
+
+			if start, _ := itr.opt.Window(t); itr.window.n == 0 {
+				itr.window.start = start
+			} else if start != itr.window.start {
+				return true, itr.finishWindow()
+			}
+			itr.aggregate(t, itr.values.Values[itr.pos])
+		}
+		if itr.done {
+			return itr.window.n > 0, itr.finishWindow()
+		}
+
+		// Aggregate the next block as a whole if it does not overlap another
+		// block and all of its points belong to a single window.
+		if loc := itr.cur.peekBlock(); loc != nil && loc.entry.MinTime >= itr.opt.StartTime && loc.entry.MaxTime <= itr.opt.EndTime {
+			start, end := itr.opt.Window(loc.entry.MinTime)
+			if loc.entry.MaxTime < end {
+				if itr.window.n > 0 && start != itr.window.start {
+					return true, itr.finishWindow()
+				} else if itr.window.n == 0 {
+					itr.window.start = start
+				}
+				if err := itr.aggregateBlock(loc); err != nil {
+					return false, err
+				}
+				loc.markRead(loc.entry.MinTime, loc.entry.MaxTime)
+				itr.cur.Next()
+				continue
+			}
+		}
+
+		// Otherwise decode the block and aggregate its values one at a time.
+		if _, err := itr.cur.ReadFloatArrayBlock(&itr.values); err != nil {
+			return false, err
+		}
+		itr.cur.Next()
+		itr.pos = 0
+		if itr.values.Len() == 0 {
+			itr.done = true
+		}
+	}
+}
+
+// aggregate adds a single point to the current window.
+func (itr *floatBlockAggregateIterator) aggregate(t int64, v float64) {
+	itr.window.n++
+	itr.stats.PointN++
+
+	switch itr.fn {
+	case aggregateSum:
+		if itr.window.n == 1 {
+			itr.window.value = v
+		} else {
+			itr.window.value += v
+		}
+	case aggregateMin:
+		if itr.window.n == 1 || v < itr.window.value {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateMax:
+		if itr.window.n == 1 || v > itr.window.value {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateFirst:
+		if itr.window.n == 1 {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateLast:
+		itr.window.time, itr.window.value, itr.window.last = t, v, nil
+	default:
+	}
+}
+
+// aggregateBlock adds all of the points of the block to the current window.
+// The block is only decoded if the aggregate needs its values.
+func (itr *floatBlockAggregateIterator) aggregateBlock(loc *location) error {
+	var decode bool
+	switch itr.fn {
+	case aggregateCount:
+	case aggregateFirst:
+		decode = itr.window.n == 0
+	case aggregateLast:
+		// The block is decoded once the window is finished unless a later
+		// point of the window has been read.
+		itr.window.last = loc
+	default:
+		decode = true
+	}
+
+	if !decode {
+		_, b, err := loc.r.ReadBytes(&loc.entry, nil)
+		if err != nil {
+			return err
+		}
+		n, err := BlockCount(b)
+		if err != nil {
+			return err
+		}
+		itr.window.n += int64(n)
+		itr.stats.PointN += n
+		return nil
+	}
+
+	if err := itr.decodeBlock(loc); err != nil {
+		return err
+	}
+	for i, t := range itr.buf.Timestamps {
+		itr.aggregate(t, itr.buf.Values[i])
+	}
+	return nil
+}
+
+// finishWindow decodes the block holding the last value of the window if
+// the aggregate needs it.
+func (itr *floatBlockAggregateIterator) finishWindow() error {
+	if itr.window.last == nil {
+		return nil
+	}
+	if err := itr.decodeBlock(itr.window.last); err != nil {
+		return err
+	}
+	itr.window.last = nil
+
+	if n := itr.buf.Len(); n > 0 {
+		itr.window.time, itr.window.value = itr.buf.Timestamps[n-1], itr.buf.Values[n-1]
+	}
+	return nil
+}
+
+// decodeBlock decodes the block at loc into buf.
+func (itr *floatBlockAggregateIterator) decodeBlock(loc *location) error {
+	if err := loc.r.ReadFloatArrayBlockAt(&loc.entry, &itr.buf); err != nil {
+		return err
+	}
+	if itr.cur.col != nil {
+		itr.cur.col.GetCounter(floatBlocksDecodedCounter).Add(1)
+		itr.cur.col.GetCounter(floatBlocksSizeCounter).Add(int64(loc.entry.Size))
+	}
+	return nil
+}
+
+// integerBlockAggregateIterator computes an aggregate of a integer field for a
+// single series directly from the TSM blocks of the series.
+type integerBlockAggregateIterator struct {
+	cur *KeyCursor
+	fn  blockAggregateFunc
+	opt query.IteratorOptions
+
+	// values holds the decoded values of the current block and pos is the
+	// index of the next value to aggregate.
+	values tsdb.IntegerArray
+	pos    int
+	done   bool
+
+	// buf is used to decode blocks that are aggregated as a whole.
+	buf tsdb.IntegerArray
+
+	window struct {
+		start int64
+		n     int64
+		time  int64
+		value int64
+
+		// last is the block holding the last value of the window when
+		// that block has not been decoded.
+		last *location
+	}
+
+	point query.IntegerPoint
+	stats query.IteratorStats
+}
+
+func newIntegerBlockAggregateIterator(name string, tags query.Tags, fn blockAggregateFunc, opt query.IteratorOptions, cur *KeyCursor) *integerBlockAggregateIterator {
+	itr := &integerBlockAggregateIterator{
+		cur:   cur,
+		fn:    fn,
+		opt:   opt,
+		stats: query.IteratorStats{SeriesN: 1},
+	}
+	itr.point.Name = name
+	itr.point.Tags = tags
+	return itr
+}
+
+// Next returns the aggregate of the next window.
+func (itr *integerBlockAggregateIterator) Next() (*query.IntegerPoint, error) {
+	if ok, err := itr.nextWindow(); err != nil || !ok {
+		return nil, err
+	}
+
+	p := &itr.point
+	p.Time, p.Value, p.Aggregated = itr.window.time, itr.window.value, uint32(itr.window.n)
+	if itr.fn == aggregateSum {
+		p.Time = itr.window.start
+	}
+	return p, nil
+}
+
+// Stats returns stats on the points processed.
+func (itr *integerBlockAggregateIterator) Stats() query.IteratorStats { return itr.stats }
+
+// Close closes the iterator.
+func (itr *integerBlockAggregateIterator) Close() error {
+	itr.cur.Close()
+	return nil
+}
+
+// windowCount returns the start time and the number of points of the window
+// read by the last call to nextWindow.
+func (itr *integerBlockAggregateIterator) windowCount() (int64, int64) {
+	return itr.window.start, itr.window.n
+}
+
+// nextWindow aggregates the points of the next window that has points.
+// It returns false when there are no more windows.
+func (itr *integerBlockAggregateIterator) nextWindow() (bool, error) {
+	itr.window.n, itr.window.last = 0, nil
+	for {
+		// Aggregate the decoded values that are left from the last block.
+		for ; itr.pos < itr.values.Len(); itr.pos++ {
+			t := itr.values.Timestamps[itr.pos]
+			if t < itr.opt.StartTime {
+				continue
+			} else if t > itr.opt.EndTime {
+				// Blocks are read in ascending order so the remaining
+				// values are outside of the time range too.
+				itr.pos, itr.done = itr.values.Len(), true
+				break
+			}
+
+			if start, _ := itr.opt.Window(t); itr.window.n == 0 {
+				itr.window.start = start
+			} else if start != itr.window.start {
+				return true, itr.finishWindow()
+			}
+			itr.aggregate(t, itr.values.Values[itr.pos])
+		}
+		if itr.done {
+			return itr.window.n > 0, itr.finishWindow()
+		}
+
+		// Aggregate the next block as a whole if it does not overlap another
+		// block and all of its points belong to a single window.
+		if loc := itr.cur.peekBlock(); loc != nil && loc.entry.MinTime >= itr.opt.StartTime && loc.entry.MaxTime <= itr.opt.EndTime {
+			start, end := itr.opt.Window(loc.entry.MinTime)
+			if loc.entry.MaxTime < end {
+				if itr.window.n > 0 && start != itr.window.start {
+					return true, itr.finishWindow()
+				} else if itr.window.n == 0 {
+					itr.window.start = start
+				}
+				if err := itr.aggregateBlock(loc); err != nil {
+					return false, err
+				}
+				loc.markRead(loc.entry.MinTime, loc.entry.MaxTime)
+				itr.cur.Next()
+				continue
+			}
+		}
+
+		// Otherwise decode the block and aggregate its values one at a time.
+		if _, err := itr.cur.ReadIntegerArrayBlock(&itr.values); err != nil {
+			return false, err
+		}
+		itr.cur.Next()
+		itr.pos = 0
+		if itr.values.Len() == 0 {
+			itr.done = true
+		}
+	}
+}
+
+// aggregate adds a single point to the current window.
+func (itr *integerBlockAggregateIterator) aggregate(t int64, v int64) {
+	itr.window.n++
+	itr.stats.PointN++
+
+	switch itr.fn {
+	case aggregateSum:
+		if itr.window.n == 1 {
+			itr.window.value = v
+		} else {
+			itr.window.value += v
+		}
+	case aggregateMin:
+		if itr.window.n == 1 || v < itr.window.value {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateMax:
+		if itr.window.n == 1 || v > itr.window.value {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateFirst:
+		if itr.window.n == 1 {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateLast:
+		itr.window.time, itr.window.value, itr.window.last = t, v, nil
+	default:
+	}
+}
+
+// aggregateBlock adds all of the points of the block to the current window.
+// The block is only decoded if the aggregate needs its values.
+func (itr *integerBlockAggregateIterator) aggregateBlock(loc *location) error {
+	var decode bool
+	switch itr.fn {
+	case aggregateCount:
+	case aggregateFirst:
+		decode = itr.window.n == 0
+	case aggregateLast:
+		// The block is decoded once the window is finished unless a later
+		// point of the window has been read.
+		itr.window.last = loc
+	default:
+		decode = true
+	}
+
+	if !decode {
+		_, b, err := loc.r.ReadBytes(&loc.entry, nil)
+		if err != nil {
+			return err
+		}
+		n, err := BlockCount(b)
+		if err != nil {
+			return err
+		}
+		itr.window.n += int64(n)
+		itr.stats.PointN += n
+		return nil
+	}
+
+	if err := itr.decodeBlock(loc); err != nil {
+		return err
+	}
+	for i, t := range itr.buf.Timestamps {
+		itr.aggregate(t, itr.buf.Values[i])
+	}
+	return nil
+}
+
+// finishWindow decodes the block holding the last value of the window if
+// the aggregate needs it.
+func (itr *integerBlockAggregateIterator) finishWindow() error {
+	if itr.window.last == nil {
+		return nil
+	}
+	if err := itr.decodeBlock(itr.window.last); err != nil {
+		return err
+	}
+	itr.window.last = nil
+
+	if n := itr.buf.Len(); n > 0 {
+		itr.window.time, itr.window.value = itr.buf.Timestamps[n-1], itr.buf.Values[n-1]
+	}
+	return nil
+}
+
+// decodeBlock decodes the block at loc into buf.
+func (itr *integerBlockAggregateIterator) decodeBlock(loc *location) error {
+	if err := loc.r.ReadIntegerArrayBlockAt(&loc.entry, &itr.buf); err != nil {
+		return err
+	}
+	if itr.cur.col != nil {
+		itr.cur.col.GetCounter(integerBlocksDecodedCounter).Add(1)
+		itr.cur.col.GetCounter(integerBlocksSizeCounter).Add(int64(loc.entry.Size))
+	}
+	return nil
+}
+
+// unsignedBlockAggregateIterator computes an aggregate of a unsigned field for a
+// single series directly from the TSM blocks of the series.
+type unsignedBlockAggregateIterator struct {
+	cur *KeyCursor
+	fn  blockAggregateFunc
+	opt query.IteratorOptions
+
+	// values holds the decoded values of the current block and pos is the
+	// index of the next value to aggregate.
+	values tsdb.UnsignedArray
+	pos    int
+	done   bool
+
+	// buf is used to decode blocks that are aggregated as a whole.
+	buf tsdb.UnsignedArray
+
+	window struct {
+		start int64
+		n     int64
+		time  int64
+		value uint64
+
+		// last is the block holding the last value of the window when
+		// that block has not been decoded.
+		last *location
+	}
+
+	point query.UnsignedPoint
+	stats query.IteratorStats
+}
+
+func newUnsignedBlockAggregateIterator(name string, tags query.Tags, fn blockAggregateFunc, opt query.IteratorOptions, cur *KeyCursor) *unsignedBlockAggregateIterator {
+	itr := &unsignedBlockAggregateIterator{
+		cur:   cur,
+		fn:    fn,
+		opt:   opt,
+		stats: query.IteratorStats{SeriesN: 1},
+	}
+	itr.point.Name = name
+	itr.point.Tags = tags
+	return itr
+}
+
+// Next returns the aggregate of the next window.
+func (itr *unsignedBlockAggregateIterator) Next() (*query.UnsignedPoint, error) {
+	if ok, err := itr.nextWindow(); err != nil || !ok {
+		return nil, err
+	}
+
+	p := &itr.point
+	p.Time, p.Value, p.Aggregated = itr.window.time, itr.window.value, uint32(itr.window.n)
+	if itr.fn == aggregateSum {
+		p.Time = itr.window.start
+	}
+	return p, nil
+}
+
+// Stats returns stats on the points processed.
+func (itr *unsignedBlockAggregateIterator) Stats() query.IteratorStats { return itr.stats }
+
+// Close closes the iterator.
+func (itr *unsignedBlockAggregateIterator) Close() error {
+	itr.cur.Close()
+	return nil
+}
+
+// windowCount returns the start time and the number of points of the window
+// read by the last call to nextWindow.
+func (itr *unsignedBlockAggregateIterator) windowCount() (int64, int64) {
+	return itr.window.start, itr.window.n
+}
+
+// nextWindow aggregates the points of the next window that has points.
+// It returns false when there are no more windows.
+func (itr *unsignedBlockAggregateIterator) nextWindow() (bool, error) {
+	itr.window.n, itr.window.last = 0, nil
+	for {
+		// Aggregate the decoded values that are left from the last block.
+		for ; itr.pos < itr.values.Len(); itr.pos++ {
+			t := itr.values.Timestamps[itr.pos]
+			if t < itr.opt.StartTime {
+				continue
+			} else if t > itr.opt.EndTime {
+				// Blocks are read in ascending order so the remaining
+				// values are outside of the time range too.
+				itr.pos, itr.done = itr.values.Len(), true
+				break
+			}
+
+			if start, _ := itr.opt.Window(t); itr.window.n == 0 {
+				itr.window.start = start
+			} else if start != itr.window.start {
+				return true, itr.finishWindow()
+			}
+			itr.aggregate(t, itr.values.Values[itr.pos])
+		}
+		if itr.done {
+			return itr.window.n > 0, itr.finishWindow()
+		}
+
+		// Aggregate the next block as a whole if it does not overlap another
+		// block and all of its points belong to a single window.
+		if loc := itr.cur.peekBlock(); loc != nil && loc.entry.MinTime >= itr.opt.StartTime && loc.entry.MaxTime <= itr.opt.EndTime {
+			start, end := itr.opt.Window(loc.entry.MinTime)
+			if loc.entry.MaxTime < end {
+				if itr.window.n > 0 && start != itr.window.start {
+					return true, itr.finishWindow()
+				} else if itr.window.n == 0 {
+					itr.window.start = start
+				}
+				if err := itr.aggregateBlock(loc); err != nil {
+					return false, err
+				}
+				loc.markRead(loc.entry.MinTime, loc.entry.MaxTime)
+				itr.cur.Next()
+				continue
+			}
+		}
+
+		// Otherwise decode the block and aggregate its values one at a time.
+		if _, err := itr.cur.ReadUnsignedArrayBlock(&itr.values); err != nil {
+			return false, err
+		}
+		itr.cur.Next()
+		itr.pos = 0
+		if itr.values.Len() == 0 {
+			itr.done = true
+		}
+	}
+}
+
+// aggregate adds a single point to the current window.
+func (itr *unsignedBlockAggregateIterator) aggregate(t int64, v uint64) {
+	itr.window.n++
+	itr.stats.PointN++
+
+	switch itr.fn {
+	case aggregateSum:
+		if itr.window.n == 1 {
+			itr.window.value = v
+		} else {
+			itr.window.value += v
+		}
+	case aggregateMin:
+		if itr.window.n == 1 || v < itr.window.value {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateMax:
+		if itr.window.n == 1 || v > itr.window.value {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateFirst:
+		if itr.window.n == 1 {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateLast:
+		itr.window.time, itr.window.value, itr.window.last = t, v, nil
+	default:
+	}
+}
+
+// aggregateBlock adds all of the points of the block to the current window.
+// The block is only decoded if the aggregate needs its values.
+func (itr *unsignedBlockAggregateIterator) aggregateBlock(loc *location) error {
+	var decode bool
+	switch itr.fn {
+	case aggregateCount:
+	case aggregateFirst:
+		decode = itr.window.n == 0
+	case aggregateLast:
+		// The block is decoded once the window is finished unless a later
+		// point of the window has been read.
+		itr.window.last = loc
+	default:
+		decode = true
+	}
+
+	if !decode {
+		_, b, err := loc.r.ReadBytes(&loc.entry, nil)
+		if err != nil {
+			return err
+		}
+		n, err := BlockCount(b)
+		if err != nil {
+			return err
+		}
+		itr.window.n += int64(n)
+		itr.stats.PointN += n
+		return nil
+	}
+
+	if err := itr.decodeBlock(loc); err != nil {
+		return err
+	}
+	for i, t := range itr.buf.Timestamps {
+		itr.aggregate(t, itr.buf.Values[i])
+	}
+	return nil
+}
+
+// finishWindow decodes the block holding the last value of the window if
+// the aggregate needs it.
+func (itr *unsignedBlockAggregateIterator) finishWindow() error {
+	if itr.window.last == nil {
+		return nil
+	}
+	if err := itr.decodeBlock(itr.window.last); err != nil {
+		return err
+	}
+	itr.window.last = nil
+
+	if n := itr.buf.Len(); n > 0 {
+		itr.window.time, itr.window.value = itr.buf.Timestamps[n-1], itr.buf.Values[n-1]
+	}
+	return nil
+}
+
+// decodeBlock decodes the block at loc into buf.
+func (itr *unsignedBlockAggregateIterator) decodeBlock(loc *location) error {
+	if err := loc.r.ReadUnsignedArrayBlockAt(&loc.entry, &itr.buf); err != nil {
+		return err
+	}
+	if itr.cur.col != nil {
+		itr.cur.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
+		itr.cur.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(loc.entry.Size))
+	}
+	return nil
+}
+
+// stringBlockAggregateIterator computes an aggregate of a string field for a
+// single series directly from the TSM blocks of the series.
+type stringBlockAggregateIterator struct {
+	cur *KeyCursor
+	fn  blockAggregateFunc
+	opt query.IteratorOptions
+
+	// values holds the decoded values of the current block and pos is the
+	// index of the next value to aggregate.
+	values tsdb.StringArray
+	pos    int
+	done   bool
+
+	// buf is used to decode blocks that are aggregated as a whole.
+	buf tsdb.StringArray
+
+	window struct {
+		start int64
+		n     int64
+		time  int64
+		value string
+
+		// last is the block holding the last value of the window when
+		// that block has not been decoded.
+		last *location
+	}
+
+	point query.StringPoint
+	stats query.IteratorStats
+}
+
+func newStringBlockAggregateIterator(name string, tags query.Tags, fn blockAggregateFunc, opt query.IteratorOptions, cur *KeyCursor) *stringBlockAggregateIterator {
+	itr := &stringBlockAggregateIterator{
+		cur:   cur,
+		fn:    fn,
+		opt:   opt,
+		stats: query.IteratorStats{SeriesN: 1},
+	}
+	itr.point.Name = name
+	itr.point.Tags = tags
+	return itr
+}
+
+// Next returns the aggregate of the next window.
+func (itr *stringBlockAggregateIterator) Next() (*query.StringPoint, error) {
+	if ok, err := itr.nextWindow(); err != nil || !ok {
+		return nil, err
+	}
+
+	p := &itr.point
+	p.Time, p.Value, p.Aggregated = itr.window.time, itr.window.value, uint32(itr.window.n)
+	if itr.fn == aggregateSum {
+		p.Time = itr.window.start
+	}
+	return p, nil
+}
+
+// Stats returns stats on the points processed.
+func (itr *stringBlockAggregateIterator) Stats() query.IteratorStats { return itr.stats }
+
+// Close closes the iterator.
+func (itr *stringBlockAggregateIterator) Close() error {
+	itr.cur.Close()
+	return nil
+}
+
+// windowCount returns the start time and the number of points of the window
+// read by the last call to nextWindow.
+func (itr *stringBlockAggregateIterator) windowCount() (int64, int64) {
+	return itr.window.start, itr.window.n
+}
+
+// nextWindow aggregates the points of the next window that has points.
+// It returns false when there are no more windows.
+func (itr *stringBlockAggregateIterator) nextWindow() (bool, error) {
+	itr.window.n, itr.window.last = 0, nil
+	for {
+		// Aggregate the decoded values that are left from the last block.
+		for ; itr.pos < itr.values.Len(); itr.pos++ {
+			t := itr.values.Timestamps[itr.pos]
+			if t < itr.opt.StartTime {
+				continue
+			} else if t > itr.opt.EndTime {
+				// Blocks are read in ascending order so the remaining
+				// values are outside of the time range too.
+				itr.pos, itr.done = itr.values.Len(), true
+				break
+			}
+
+			if start, _ := itr.opt.Window(t); itr.window.n == 0 {
+				itr.window.start = start
+			} else if start != itr.window.start {
+				return true, itr.finishWindow()
+			}
+			itr.aggregate(t, itr.values.Values[itr.pos])
+		}
+		if itr.done {
+			return itr.window.n > 0, itr.finishWindow()
+		}
+
+		// Aggregate the next block as a whole if it does not overlap another
+		// block and all of its points belong to a single window.
+		if loc := itr.cur.peekBlock(); loc != nil && loc.entry.MinTime >= itr.opt.StartTime && loc.entry.MaxTime <= itr.opt.EndTime {
+			start, end := itr.opt.Window(loc.entry.MinTime)
+			if loc.entry.MaxTime < end {
+				if itr.window.n > 0 && start != itr.window.start {
+					return true, itr.finishWindow()
+				} else if itr.window.n == 0 {
+					itr.window.start = start
+				}
+				if err := itr.aggregateBlock(loc); err != nil {
+					return false, err
+				}
+				loc.markRead(loc.entry.MinTime, loc.entry.MaxTime)
+				itr.cur.Next()
+				continue
+			}
+		}
+
+		// Otherwise decode the block and aggregate its values one at a time.
+		if _, err := itr.cur.ReadStringArrayBlock(&itr.values); err != nil {
+			return false, err
+		}
+		itr.cur.Next()
+		itr.pos = 0
+		if itr.values.Len() == 0 {
+			itr.done = true
+		}
+	}
+}
+
+// aggregate adds a single point to the current window.
+func (itr *stringBlockAggregateIterator) aggregate(t int64, v string) {
+	itr.window.n++
+	itr.stats.PointN++
+
+	switch itr.fn {
+	case aggregateFirst:
+		if itr.window.n == 1 {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateLast:
+		itr.window.time, itr.window.value, itr.window.last = t, v, nil
+	default:
+	}
+}
+
+// aggregateBlock adds all of the points of the block to the current window.
+// The block is only decoded if the aggregate needs its values.
+func (itr *stringBlockAggregateIterator) aggregateBlock(loc *location) error {
+	var decode bool
+	switch itr.fn {
+	case aggregateCount:
+	case aggregateFirst:
+		decode = itr.window.n == 0
+	case aggregateLast:
+		// The block is decoded once the window is finished unless a later
+		// point of the window has been read.
+		itr.window.last = loc
+	default:
+		decode = true
+	}
+
+	if !decode {
+		_, b, err := loc.r.ReadBytes(&loc.entry, nil)
+		if err != nil {
+			return err
+		}
+		n, err := BlockCount(b)
+		if err != nil {
+			return err
+		}
+		itr.window.n += int64(n)
+		itr.stats.PointN += n
+		return nil
+	}
+
+	if err := itr.decodeBlock(loc); err != nil {
+		return err
+	}
+	for i, t := range itr.buf.Timestamps {
+		itr.aggregate(t, itr.buf.Values[i])
+	}
+	return nil
+}
+
+// finishWindow decodes the block holding the last value of the window if
+// the aggregate needs it.
+func (itr *stringBlockAggregateIterator) finishWindow() error {
+	if itr.window.last == nil {
+		return nil
+	}
+	if err := itr.decodeBlock(itr.window.last); err != nil {
+		return err
+	}
+	itr.window.last = nil
+
+	if n := itr.buf.Len(); n > 0 {
+		itr.window.time, itr.window.value = itr.buf.Timestamps[n-1], itr.buf.Values[n-1]
+	}
+	return nil
+}
+
+// decodeBlock decodes the block at loc into buf.
+func (itr *stringBlockAggregateIterator) decodeBlock(loc *location) error {
+	if err := loc.r.ReadStringArrayBlockAt(&loc.entry, &itr.buf); err != nil {
+		return err
+	}
+	if itr.cur.col != nil {
+		itr.cur.col.GetCounter(stringBlocksDecodedCounter).Add(1)
+		itr.cur.col.GetCounter(stringBlocksSizeCounter).Add(int64(loc.entry.Size))
+	}
+	return nil
+}
+
+// booleanBlockAggregateIterator computes an aggregate of a boolean field for a
+// single series directly from the TSM blocks of the series.
+type booleanBlockAggregateIterator struct {
+	cur *KeyCursor
+	fn  blockAggregateFunc
+	opt query.IteratorOptions
+
+	// values holds the decoded values of the current block and pos is the
+	// index of the next value to aggregate.
+	values tsdb.BooleanArray
+	pos    int
+	done   bool
+
+	// buf is used to decode blocks that are aggregated as a whole.
+	buf tsdb.BooleanArray
+
+	window struct {
+		start int64
+		n     int64
+		time  int64
+		value bool
+
+		// last is the block holding the last value of the window when
+		// that block has not been decoded.
+		last *location
+	}
+
+	point query.BooleanPoint
+	stats query.IteratorStats
+}
+
+func newBooleanBlockAggregateIterator(name string, tags query.Tags, fn blockAggregateFunc, opt query.IteratorOptions, cur *KeyCursor) *booleanBlockAggregateIterator {
+	itr := &booleanBlockAggregateIterator{
+		cur:   cur,
+		fn:    fn,
+		opt:   opt,
+		stats: query.IteratorStats{SeriesN: 1},
+	}
+	itr.point.Name = name
+	itr.point.Tags = tags
+	return itr
+}
+
+// Next returns the aggregate of the next window.
+func (itr *booleanBlockAggregateIterator) Next() (*query.BooleanPoint, error) {
+	if ok, err := itr.nextWindow(); err != nil || !ok {
+		return nil, err
+	}
+
+	p := &itr.point
+	p.Time, p.Value, p.Aggregated = itr.window.time, itr.window.value, uint32(itr.window.n)
+	if itr.fn == aggregateSum {
+		p.Time = itr.window.start
+	}
+	return p, nil
+}
+
+// Stats returns stats on the points processed.
+func (itr *booleanBlockAggregateIterator) Stats() query.IteratorStats { return itr.stats }
+
+// Close closes the iterator.
+func (itr *booleanBlockAggregateIterator) Close() error {
+	itr.cur.Close()
+	return nil
+}
+
+// windowCount returns the start time and the number of points of the window
+// read by the last call to nextWindow.
+func (itr *booleanBlockAggregateIterator) windowCount() (int64, int64) {
+	return itr.window.start, itr.window.n
+}
+
+// nextWindow aggregates the points of the next window that has points.
+// It returns false when there are no more windows.
+func (itr *booleanBlockAggregateIterator) nextWindow() (bool, error) {
+	itr.window.n, itr.window.last = 0, nil
+	for {
+		// Aggregate the decoded values that are left from the last block.
+		for ; itr.pos < itr.values.Len(); itr.pos++ {
+			t := itr.values.Timestamps[itr.pos]
+			if t < itr.opt.StartTime {
+				continue
+			} else if t > itr.opt.EndTime {
+				// Blocks are read in ascending order so the remaining
+				// values are outside of the time range too.
+				itr.pos, itr.done = itr.values.Len(), true
+				break
+			}
+
+			if start, _ := itr.opt.Window(t); itr.window.n == 0 {
+				itr.window.start = start
+			} else if start != itr.window.start {
+				return true, itr.finishWindow()
+			}
+			itr.aggregate(t, itr.values.Values[itr.pos])
+		}
+		if itr.done {
+			return itr.window.n > 0, itr.finishWindow()
+		}
+
+		// Aggregate the next block as a whole if it does not overlap another
+		// block and all of its points belong to a single window.
+		if loc := itr.cur.peekBlock(); loc != nil && loc.entry.MinTime >= itr.opt.StartTime && loc.entry.MaxTime <= itr.opt.EndTime {
+			start, end := itr.opt.Window(loc.entry.MinTime)
+			if loc.entry.MaxTime < end {
+				if itr.window.n > 0 && start != itr.window.start {
+					return true, itr.finishWindow()
+				} else if itr.window.n == 0 {
+					itr.window.start = start
+				}
+				if err := itr.aggregateBlock(loc); err != nil {
+					return false, err
+				}
+				loc.markRead(loc.entry.MinTime, loc.entry.MaxTime)
+				itr.cur.Next()
+				continue
+			}
+		}
+
+		// Otherwise decode the block and aggregate its values one at a time.
+		if _, err := itr.cur.ReadBooleanArrayBlock(&itr.values); err != nil {
+			return false, err
+		}
+		itr.cur.Next()
+		itr.pos = 0
+		if itr.values.Len() == 0 {
+			itr.done = true
+		}
+	}
+}
+
+// aggregate adds a single point to the current window.
+func (itr *booleanBlockAggregateIterator) aggregate(t int64, v bool) {
+	itr.window.n++
+	itr.stats.PointN++
+
+	switch itr.fn {
+	case aggregateFirst:
+		if itr.window.n == 1 {
+			itr.window.time, itr.window.value = t, v
+		}
+	case aggregateLast:
+		itr.window.time, itr.window.value, itr.window.last = t, v, nil
+	default:
+	}
+}
+
+// aggregateBlock adds all of the points of the block to the current window.
+// The block is only decoded if the aggregate needs its values.
+func (itr *booleanBlockAggregateIterator) aggregateBlock(loc *location) error {
+	var decode bool
+	switch itr.fn {
+	case aggregateCount:
+	case aggregateFirst:
+		decode = itr.window.n == 0
+	case aggregateLast:
+		// The block is decoded once the window is finished unless a later
+		// point of the window has been read.
+		itr.window.last = loc
+	default:
+		decode = true
+	}
+
+	if !decode {
+		_, b, err := loc.r.ReadBytes(&loc.entry, nil)
+		if err != nil {
+			return err
+		}
+		n, err := BlockCount(b)
+		if err != nil {
+			return err
+		}
+		itr.window.n += int64(n)
+		itr.stats.PointN += n
+		return nil
+	}
+
+	if err := itr.decodeBlock(loc); err != nil {
+		return err
+	}
+	for i, t := range itr.buf.Timestamps {
+		itr.aggregate(t, itr.buf.Values[i])
+	}
+	return nil
+}
+
+// finishWindow decodes the block holding the last value of the window if
+// the aggregate needs it.
+func (itr *booleanBlockAggregateIterator) finishWindow() error {
+	if itr.window.last == nil {
+		return nil
+	}
+	if err := itr.decodeBlock(itr.window.last); err != nil {
+		return err
+	}
+	itr.window.last = nil
+
+	if n := itr.buf.Len(); n > 0 {
+		itr.window.time, itr.window.value = itr.buf.Timestamps[n-1], itr.buf.Values[n-1]
+	}
+	return nil
+}
+
+// decodeBlock decodes the block at loc into buf.
+func (itr *booleanBlockAggregateIterator) decodeBlock(loc *location) error {
+	if err := loc.r.ReadBooleanArrayBlockAt(&loc.entry, &itr.buf); err != nil {
+		return err
+	}
+	if itr.cur.col != nil {
+		itr.cur.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
+		itr.cur.col.GetCounter(booleanBlocksSizeCounter).Add(int64(loc.entry.Size))
+	}
+	return nil
+}
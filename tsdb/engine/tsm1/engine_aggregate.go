@@ -0,0 +1,160 @@
+package tsm1
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/metrics"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+var blockAggregateIteratorsCounter = metrics.MustRegisterCounter("iterators_block_aggregate", metrics.WithGroup(tsmGroup))
+
+// blockAggregateFunc is an aggregate that can be computed from TSM blocks.
+type blockAggregateFunc int
+
+const (
+	aggregateCount blockAggregateFunc = iota
+	aggregateSum
+	aggregateMin
+	aggregateMax
+	aggregateFirst
+	aggregateLast
+)
+
+// blockAggregateFuncs maps the supported calls to their aggregate.
+var blockAggregateFuncs = map[string]blockAggregateFunc{
+	"count": aggregateCount,
+	"sum":   aggregateSum,
+	"min":   aggregateMin,
+	"max":   aggregateMax,
+	"first": aggregateFirst,
+	"last":  aggregateLast,
+}
+
+// blockAggregateIterator is implemented by the iterators that compute the
+// aggregate of a call themselves so they must not be wrapped in a call iterator.
+type blockAggregateIterator interface {
+	query.Iterator
+
+	// nextWindow aggregates the points of the next window.
+	nextWindow() (bool, error)
+
+	// windowCount returns the start time and point count of the window.
+	windowCount() (int64, int64)
+}
+
+// createBlockAggregateIterator returns an iterator that computes the call for
+// a series from its TSM blocks. Blocks that do not overlap other blocks and
+// that fall within a single window are counted from their encoded timestamps
+// and are only decoded when the call needs their values.
+//
+// A nil iterator is returned if the call cannot be computed this way, which is
+// the case when the series has values in the cache within the time range.
+func (e *Engine) createBlockAggregateIterator(ctx context.Context, call *influxql.Call, name string, seriesKey string, opt query.IteratorOptions) (query.Iterator, error) {
+	fn, ok := blockAggregateFuncs[call.Name]
+	if !ok || len(call.Args) != 1 || !opt.Ascending || len(opt.Aux) > 0 {
+		return nil, nil
+	}
+	ref, ok := call.Args[0].(*influxql.VarRef)
+	if !ok {
+		return nil, nil
+	}
+
+	mf := e.fieldset.FieldsByString(name)
+	if mf == nil {
+		return nil, nil
+	}
+	f := mf.Field(ref.Val)
+	if f == nil {
+		return nil, nil
+	} else if ref.Type != influxql.Unknown && ref.Type != influxql.AnyField && ref.Type != f.Type {
+		return nil, nil
+	}
+
+	switch fn {
+	case aggregateSum, aggregateMin, aggregateMax:
+		if f.Type != influxql.Float && f.Type != influxql.Integer && f.Type != influxql.Unsigned {
+			return nil, nil
+		}
+	default:
+	}
+
+	// Values in the cache would have to be merged with the blocks.
+	key := SeriesFieldKeyBytes(seriesKey, ref.Val)
+	for _, v := range e.Cache.Values(key) {
+		if t := v.UnixNano(); t >= opt.StartTime && t <= opt.EndTime {
+			return nil, nil
+		}
+	}
+
+	_, tfs := models.ParseKey([]byte(seriesKey))
+	tags := query.NewTags(tfs.Map())
+	tags = tags.Subset(opt.GetDimensions())
+	if opt.StripName {
+		name = ""
+	}
+
+	if col := metrics.GroupFromContext(ctx); col != nil {
+		col.GetCounter(blockAggregateIteratorsCounter).Add(1)
+	}
+
+	var itr blockAggregateIterator
+	cur := e.KeyCursor(ctx, key, opt.StartTime, true)
+	switch f.Type {
+	case influxql.Float:
+		itr = newFloatBlockAggregateIterator(name, tags, fn, opt, cur)
+	case influxql.Integer:
+		itr = newIntegerBlockAggregateIterator(name, tags, fn, opt, cur)
+	case influxql.Unsigned:
+		itr = newUnsignedBlockAggregateIterator(name, tags, fn, opt, cur)
+	case influxql.String:
+		itr = newStringBlockAggregateIterator(name, tags, fn, opt, cur)
+	case influxql.Boolean:
+		itr = newBooleanBlockAggregateIterator(name, tags, fn, opt, cur)
+	default:
+		cur.Close()
+		return nil, nil
+	}
+
+	if fn == aggregateCount {
+		return newBlockCountIterator(itr, name, tags), nil
+	}
+	return itr, nil
+}
+
+// blockCountIterator returns the number of points in each window of a
+// block aggregate iterator.
+type blockCountIterator struct {
+	input blockAggregateIterator
+	point query.IntegerPoint
+}
+
+func newBlockCountIterator(input blockAggregateIterator, name string, tags query.Tags) *blockCountIterator {
+	itr := &blockCountIterator{input: input}
+	itr.point.Name = name
+	itr.point.Tags = tags
+	return itr
+}
+
+// Next returns the count of the next window.
+func (itr *blockCountIterator) Next() (*query.IntegerPoint, error) {
+	if ok, err := itr.input.nextWindow(); err != nil || !ok {
+		return nil, err
+	}
+
+	start, n := itr.input.windowCount()
+	p := &itr.point
+	p.Time, p.Value, p.Aggregated = start, n, uint32(n)
+	return p, nil
+}
+
+func (itr *blockCountIterator) nextWindow() (bool, error)   { return itr.input.nextWindow() }
+func (itr *blockCountIterator) windowCount() (int64, int64) { return itr.input.windowCount() }
+
+// Stats returns stats on the points processed.
+func (itr *blockCountIterator) Stats() query.IteratorStats { return itr.input.Stats() }
+
+// Close closes the iterator.
+func (itr *blockCountIterator) Close() error { return itr.input.Close() }
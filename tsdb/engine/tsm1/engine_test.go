@@ -22,6 +22,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/deep"
+	"github.com/influxdata/influxdb/pkg/metrics"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
@@ -1113,6 +1114,183 @@ func TestEngine_CreateIterator_Condition(t *testing.T) {
 	}
 }
 
+// Ensure engine can compute aggregates directly from TSM blocks.
+func TestEngine_CreateIterator_BlockAggregate(t *testing.T) {
+	t.Parallel()
+
+	type point struct {
+		time  int64
+		value interface{}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			e := MustOpenEngine(index)
+			defer e.Close()
+
+			e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float)
+			e.CreateSeriesIfNotExists([]byte("cpu,host=A"), []byte("cpu"), models.NewTags(map[string]string{"host": "A"}), notrack)
+
+			// Write three files. The last one overwrites a point of the
+			// first so their blocks have to be merged.
+			for _, r := range [][2]int{{0, 10}, {10, 20}} {
+				var a []string
+				for i := r[0]; i < r[1]; i++ {
+					a = append(a, fmt.Sprintf(`cpu,host=A value=%d %d`, i, i*int(time.Second)))
+				}
+				if err := e.WritePointsString(a...); err != nil {
+					t.Fatalf("failed to write points: %s", err.Error())
+				}
+				e.MustWriteSnapshot()
+			}
+			if err := e.WritePointsString(`cpu,host=A value=100 5000000000`); err != nil {
+				t.Fatalf("failed to write points: %s", err.Error())
+			}
+			e.MustWriteSnapshot()
+
+			for _, tt := range []struct {
+				expr     string
+				interval time.Duration
+				start    int64
+				exp      []point
+			}{
+				{expr: `count(value)`, exp: []point{{0, int64(20)}}},
+				{expr: `sum(value)`, exp: []point{{0, 285.0}}},
+				{expr: `min(value)`, exp: []point{{0, 0.0}}},
+				{expr: `max(value)`, exp: []point{{5, 100.0}}},
+				{expr: `first(value)`, exp: []point{{0, 0.0}}},
+				{expr: `last(value)`, exp: []point{{19, 19.0}}},
+				{expr: `count(value)`, interval: 10 * time.Second, exp: []point{{0, int64(10)}, {10, int64(10)}}},
+				{expr: `sum(value)`, interval: 10 * time.Second, exp: []point{{0, 140.0}, {10, 145.0}}},
+				{expr: `min(value)`, interval: 10 * time.Second, exp: []point{{0, 0.0}, {10, 10.0}}},
+				{expr: `max(value)`, interval: 10 * time.Second, exp: []point{{5, 100.0}, {19, 19.0}}},
+				{expr: `first(value)`, interval: 10 * time.Second, exp: []point{{0, 0.0}, {10, 10.0}}},
+				{expr: `last(value)`, interval: 10 * time.Second, exp: []point{{9, 9.0}, {19, 19.0}}},
+				{expr: `count(value)`, interval: 15 * time.Second, exp: []point{{0, int64(15)}, {15, int64(5)}}},
+				{expr: `sum(value)`, start: 12, exp: []point{{12, 124.0}}},
+			} {
+				ctx := tsm1.NewContextWithMetricsGroup(context.Background())
+				itr, err := e.CreateIterator(ctx, "cpu", query.IteratorOptions{
+					Expr:      influxql.MustParseExpr(tt.expr),
+					Interval:  query.Interval{Duration: tt.interval},
+					StartTime: tt.start * int64(time.Second),
+					EndTime:   19 * int64(time.Second),
+					Ascending: true,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var got []point
+				for {
+					var p point
+					switch itr := itr.(type) {
+					case query.FloatIterator:
+						fp, err := itr.Next()
+						if err != nil {
+							t.Fatal(err)
+						} else if fp == nil {
+							break
+						}
+						p = point{fp.Time / int64(time.Second), fp.Value}
+					case query.IntegerIterator:
+						ip, err := itr.Next()
+						if err != nil {
+							t.Fatal(err)
+						} else if ip == nil {
+							break
+						}
+						p = point{ip.Time / int64(time.Second), ip.Value}
+					}
+					if p.value == nil {
+						break
+					}
+					got = append(got, p)
+				}
+				itr.Close()
+
+				if !reflect.DeepEqual(got, tt.exp) {
+					t.Errorf("%s (interval %s, start %ds): unexpected points: got %v, exp %v", tt.expr, tt.interval, tt.start, got, tt.exp)
+				}
+
+				// first() and last() without an interval read a single point.
+				exp := int64(1)
+				if tt.interval == 0 && (tt.expr == `first(value)` || tt.expr == `last(value)`) {
+					exp = 0
+				}
+				if n := counterValue(ctx, "iterators_block_aggregate"); n != exp {
+					t.Errorf("%s: unexpected block aggregate iterators: %d", tt.expr, n)
+				}
+			}
+
+			// The block of the second file has a window of its own and does
+			// not have to be decoded to be counted.
+			ctx := tsm1.NewContextWithMetricsGroup(context.Background())
+			itr, err := e.CreateIterator(ctx, "cpu", query.IteratorOptions{
+				Expr:      influxql.MustParseExpr(`count(value)`),
+				Interval:  query.Interval{Duration: 10 * time.Second},
+				StartTime: 0,
+				EndTime:   19 * int64(time.Second),
+				Ascending: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for p, err := itr.(query.IntegerIterator).Next(); p != nil || err != nil; p, err = itr.(query.IntegerIterator).Next() {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			itr.Close()
+			if n := counterValue(ctx, "float_blocks_decoded"); n != 2 {
+				t.Fatalf("unexpected blocks decoded: %d", n)
+			}
+
+			// Points in the cache are merged by the regular iterators.
+			if err := e.WritePointsString(`cpu,host=A value=50 15000000000`); err != nil {
+				t.Fatalf("failed to write points: %s", err.Error())
+			}
+			ctx = tsm1.NewContextWithMetricsGroup(context.Background())
+			itr, err = e.CreateIterator(ctx, "cpu", query.IteratorOptions{
+				Expr:      influxql.MustParseExpr(`max(value)`),
+				Interval:  query.Interval{Duration: 10 * time.Second},
+				StartTime: 0,
+				EndTime:   19 * int64(time.Second),
+				Ascending: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer itr.Close()
+			fitr := itr.(query.FloatIterator)
+			if p, err := fitr.Next(); err != nil {
+				t.Fatal(err)
+			} else if p == nil || p.Time != 5000000000 || p.Value != 100 {
+				t.Fatalf("unexpected point(0): %v", p)
+			}
+			if p, err := fitr.Next(); err != nil {
+				t.Fatal(err)
+			} else if p == nil || p.Time != 15000000000 || p.Value != 50 {
+				t.Fatalf("unexpected point(1): %v", p)
+			}
+			if n := counterValue(ctx, "iterators_block_aggregate"); n != 0 {
+				t.Fatalf("unexpected block aggregate iterators: %d", n)
+			}
+		})
+	}
+}
+
+// counterValue returns the value of the tsm1 counter in the metrics group of ctx.
+func counterValue(ctx context.Context, name string) int64 {
+	var n int64
+	tsm1.MetricsGroupFromContext(ctx).ForEach(func(m metrics.Metric) {
+		if c, ok := m.(*metrics.Counter); ok && c.Name() == name {
+			n = c.Value()
+		}
+	})
+	return n
+}
+
 // Test that series id set gets updated and returned appropriately.
 func TestIndex_SeriesIDSet(t *testing.T) {
 	test := func(index string) error {
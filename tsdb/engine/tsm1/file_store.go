@@ -61,6 +61,9 @@ type TSMFile interface {
 	ReadBooleanBlockAt(entry *IndexEntry, values *[]BooleanValue) ([]BooleanValue, error)
 	ReadBooleanArrayBlockAt(entry *IndexEntry, values *tsdb.BooleanArray) error
 
+	// ReadBytes returns the checksum and the encoded bytes of the block identified by entry.
+	ReadBytes(entry *IndexEntry, b []byte) (uint32, []byte, error)
+
 	// Entries returns the index entries for all blocks for the given key.
 	Entries(key []byte) []IndexEntry
 	ReadEntries(key []byte, entries *[]IndexEntry) []IndexEntry
@@ -1560,6 +1563,32 @@ func (c *KeyCursor) nextDescending() {
 	}
 }
 
+// peekBlock returns the location of the next block of an ascending cursor if
+// all of its values can be returned without merging or filtering them: none
+// of its values have been read, it does not overlap any other unread block
+// and none of its values have been deleted. Otherwise nil is returned.
+func (c *KeyCursor) peekBlock() *location {
+	if !c.ascending || len(c.current) == 0 {
+		return nil
+	}
+
+	first := c.current[0]
+	if first.readMin <= first.entry.MaxTime && first.readMax >= first.entry.MinTime {
+		return nil
+	}
+	for _, cur := range c.current[1:] {
+		if cur.entry.MinTime <= first.entry.MaxTime && !cur.read() {
+			return nil
+		}
+	}
+	for _, ts := range first.r.TombstoneRange(c.key) {
+		if ts.Overlaps(first.entry.MinTime, first.entry.MaxTime) {
+			return nil
+		}
+	}
+	return first
+}
+
 type purger struct {
 	mu        sync.RWMutex
 	fileStore *FileStore
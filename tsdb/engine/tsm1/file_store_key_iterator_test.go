@@ -153,6 +153,9 @@ func (*mockTSMFile) Path() string                                              {
 func (*mockTSMFile) Read(key []byte, t int64) ([]Value, error)                 { panic("implement me") }
 func (*mockTSMFile) ReadAt(entry *IndexEntry, values []Value) ([]Value, error) { panic("implement me") }
 func (*mockTSMFile) Entries(key []byte) []IndexEntry                           { panic("implement me") }
+func (*mockTSMFile) ReadBytes(*IndexEntry, []byte) (uint32, []byte, error) {
+	panic("implement me")
+}
 func (*mockTSMFile) ReadEntries(key []byte, entries *[]IndexEntry) []IndexEntry {
 	panic("implement me")
 }
@@ -227,6 +227,7 @@ func newFloatIterator(name string, tags query.Tags, opt query.IteratorOptions, c
 	itr.valuer = influxql.ValuerEval{
 		Valuer: influxql.MultiValuer(
 			query.MathValuer{},
+			query.StringValuer{},
 			influxql.MapValuer(itr.m),
 		),
 	}
@@ -707,6 +708,7 @@ func newIntegerIterator(name string, tags query.Tags, opt query.IteratorOptions,
 	itr.valuer = influxql.ValuerEval{
 		Valuer: influxql.MultiValuer(
 			query.MathValuer{},
+			query.StringValuer{},
 			influxql.MapValuer(itr.m),
 		),
 	}
@@ -1187,6 +1189,7 @@ func newUnsignedIterator(name string, tags query.Tags, opt query.IteratorOptions
 	itr.valuer = influxql.ValuerEval{
 		Valuer: influxql.MultiValuer(
 			query.MathValuer{},
+			query.StringValuer{},
 			influxql.MapValuer(itr.m),
 		),
 	}
@@ -1667,6 +1670,7 @@ func newStringIterator(name string, tags query.Tags, opt query.IteratorOptions,
 	itr.valuer = influxql.ValuerEval{
 		Valuer: influxql.MultiValuer(
 			query.MathValuer{},
+			query.StringValuer{},
 			influxql.MapValuer(itr.m),
 		),
 	}
@@ -2147,6 +2151,7 @@ func newBooleanIterator(name string, tags query.Tags, opt query.IteratorOptions,
 	itr.valuer = influxql.ValuerEval{
 		Valuer: influxql.MultiValuer(
 			query.MathValuer{},
+			query.StringValuer{},
 			influxql.MapValuer(itr.m),
 		),
 	}
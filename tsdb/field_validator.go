@@ -23,12 +23,11 @@ func ValidateFields(mf *MeasurementFields, point models.Point, skipSizeValidatio
 			// unescape the string, and must at least parse the string)
 			if pointSize > MaxFieldValueLength && iter.Type() == models.String {
 				if sz := len(iter.StringValue()); sz > MaxFieldValueLength {
-					return PartialWriteError{
-						Reason: fmt.Sprintf(
-							"input field \"%s\" on measurement \"%s\" is too long, %d > %d",
-							iter.FieldKey(), point.Name(), sz, MaxFieldValueLength),
-						Dropped: 1,
-					}
+					var err PartialWriteError
+					err.DropPoint(point, DropReasonInvalid, fmt.Sprintf(
+						"input field \"%s\" on measurement \"%s\" is too long, %d > %d",
+						iter.FieldKey(), point.Name(), sz, MaxFieldValueLength))
+					return err
 				}
 			}
 		}
@@ -51,12 +50,11 @@ func ValidateFields(mf *MeasurementFields, point models.Point, skipSizeValidatio
 
 		// If the types are not the same, there is a conflict.
 		if f.Type != dataType {
-			return PartialWriteError{
-				Reason: fmt.Sprintf(
-					"%s: input field \"%s\" on measurement \"%s\" is type %s, already exists as type %s",
-					ErrFieldTypeConflict, iter.FieldKey(), point.Name(), dataType, f.Type),
-				Dropped: 1,
-			}
+			var err PartialWriteError
+			err.DropPoint(point, DropReasonTypeConflict, fmt.Sprintf(
+				"%s: input field \"%s\" on measurement \"%s\" is type %s, already exists as type %s",
+				ErrFieldTypeConflict, iter.FieldKey(), point.Name(), dataType, f.Type))
+			return err
 		}
 	}
 
@@ -804,11 +804,23 @@ type seriesPointIterator struct {
 	keys     [][]byte
 	opt      query.IteratorOptions
 
+	// after is the series key the iterator resumes after, if any.
+	after     []byte
+	afterName []byte
+
 	point query.FloatPoint // reusable point
 }
 
 // newSeriesPointIterator returns a new instance of seriesPointIterator.
 func NewSeriesPointIterator(indexSet IndexSet, opt query.IteratorOptions) (_ query.Iterator, err error) {
+	// A comparison on the key sets the position the iterator resumes after.
+	var after, afterName []byte
+	if key, cond := splitSeriesKeyBound(opt.Condition); key != "" {
+		name, tags := models.ParseKeyBytes([]byte(key))
+		after, afterName = AppendSeriesKey(nil, name, tags), name
+		opt.Condition = cond
+	}
+
 	// Only equality operators are allowed.
 	influxql.WalkFunc(opt.Condition, func(n influxql.Node) {
 		switch n := n.(type) {
@@ -831,8 +843,10 @@ func NewSeriesPointIterator(indexSet IndexSet, opt query.IteratorOptions) (_ que
 	}
 
 	return &seriesPointIterator{
-		indexSet: indexSet,
-		mitr:     mitr,
+		indexSet:  indexSet,
+		mitr:      mitr,
+		after:     after,
+		afterName: afterName,
 		point: query.FloatPoint{
 			Aux: make([]interface{}, len(opt.Aux)),
 		},
@@ -840,6 +854,50 @@ func NewSeriesPointIterator(indexSet IndexSet, opt query.IteratorOptions) (_ que
 	}, nil
 }
 
+// splitSeriesKeyBound separates a `key > '<series key>'` comparison in the
+// top level conjunction of the condition from the rest of the condition.
+// SHOW SERIES is rewritten into such a comparison when it has an AFTER clause.
+func splitSeriesKeyBound(cond influxql.Expr) (key string, rest influxql.Expr) {
+	switch expr := cond.(type) {
+	case *influxql.ParenExpr:
+		key, rest := splitSeriesKeyBound(expr.Expr)
+		if key == "" {
+			return "", cond
+		} else if rest != nil {
+			rest = &influxql.ParenExpr{Expr: rest}
+		}
+		return key, rest
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.GT:
+			ref, ok := expr.LHS.(*influxql.VarRef)
+			lit, ok2 := expr.RHS.(*influxql.StringLiteral)
+			if ok && ok2 && ref.Val == "key" && lit.Val != "" {
+				return lit.Val, nil
+			}
+		case influxql.AND:
+			if key, rest := splitSeriesKeyBound(expr.LHS); key != "" {
+				return key, conjunction(rest, expr.RHS)
+			} else if key, rest := splitSeriesKeyBound(expr.RHS); key != "" {
+				return key, conjunction(expr.LHS, rest)
+			}
+		default:
+		}
+	}
+	return "", cond
+}
+
+// conjunction combines the two expressions using AND. Either of the
+// expressions may be nil.
+func conjunction(lhs, rhs influxql.Expr) influxql.Expr {
+	if lhs == nil {
+		return rhs
+	} else if rhs == nil {
+		return lhs
+	}
+	return &influxql.BinaryExpr{Op: influxql.AND, LHS: lhs, RHS: rhs}
+}
+
 // Stats returns stats about the points processed.
 func (itr *seriesPointIterator) Stats() query.IteratorStats { return query.IteratorStats{} }
 
@@ -864,6 +922,9 @@ func (itr *seriesPointIterator) Next() (*query.FloatPoint, error) {
 				return nil, err
 			} else if m == nil {
 				return nil, nil
+			} else if itr.after != nil && bytes.Compare(m, itr.afterName) < 0 {
+				// Every series of the measurement precedes the position.
+				continue
 			}
 
 			if err := itr.readSeriesKeys(m); err != nil {
@@ -938,6 +999,14 @@ func (itr *seriesPointIterator) readSeriesKeys(name []byte) error {
 
 	// Sort keys.
 	sort.Sort(seriesKeys(itr.keys))
+
+	// Skip the keys up to the position the iterator resumes after.
+	if itr.after != nil && bytes.Equal(name, itr.afterName) {
+		i := sort.Search(len(itr.keys), func(i int) bool {
+			return CompareSeriesKeys(itr.keys[i], itr.after) > 0
+		})
+		itr.keys = itr.keys[i:]
+	}
 	return nil
 }
 
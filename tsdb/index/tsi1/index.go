@@ -128,6 +128,14 @@ var WithSeriesIDCacheSize = func(sz int) IndexOption {
 	}
 }
 
+// WithMaximumConcurrency sets the maximum number of goroutines used to work on
+// the partitions of the Index. If set to 0, then GOMAXPROCS is used.
+var WithMaximumConcurrency = func(n int) IndexOption {
+	return func(i *Index) {
+		i.maxConcurrency = n
+	}
+}
+
 // Index represents a collection of layered index files and WAL.
 type Index struct {
 	mu         sync.RWMutex
@@ -144,6 +152,7 @@ type Index struct {
 	maxLogFileAge      time.Duration // Maximum age of a LogFile before it's compacted
 	logfileBufferSize  int           // The size of the buffer used by the LogFile.
 	disableFsync       bool          // Disables flushing buffers and fsyning files. Used when working with indexes offline.
+	maxConcurrency     int           // Maximum number of goroutines working on partitions, GOMAXPROCS if 0.
 	logger             *zap.Logger   // Index's logger.
 
 	// The following must be set when initializing an Index.
@@ -383,10 +392,13 @@ func (i *Index) partitionIdx(key []byte) int {
 	return int(xxhash.Sum64(key) & (i.PartitionN - 1))
 }
 
-// availableThreads returns the minimum of GOMAXPROCS and the number of
-// partitions in the Index.
+// availableThreads returns the minimum of the maximum concurrency, GOMAXPROCS
+// by default, and the number of partitions in the Index.
 func (i *Index) availableThreads() int {
 	n := runtime.GOMAXPROCS(0)
+	if i.maxConcurrency > 0 {
+		n = i.maxConcurrency
+	}
 	if len(i.partitions) < n {
 		return len(i.partitions)
 	}
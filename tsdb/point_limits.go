@@ -0,0 +1,105 @@
+package tsdb
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// Names of the point limits of a database.
+const (
+	PointLimitMaxTagsPerPoint      = "max-tags-per-point"
+	PointLimitMaxTagKeyLength      = "max-tag-key-length"
+	PointLimitMaxFieldKeyLength    = "max-field-key-length"
+	PointLimitMaxStringFieldLength = "max-string-field-length"
+)
+
+// PointLimits holds the limits of the size of each point written to a
+// database. A zero limit is unlimited.
+type PointLimits struct {
+	MaxTagsPerPoint      int
+	MaxTagKeyLength      int
+	MaxFieldKeyLength    int
+	MaxStringFieldLength int
+}
+
+// IsZero returns true if there are no limits.
+func (l PointLimits) IsZero() bool {
+	return l.MaxTagsPerPoint <= 0 && l.MaxTagKeyLength <= 0 && l.MaxFieldKeyLength <= 0 && l.MaxStringFieldLength <= 0
+}
+
+// check returns the name and the value of the first limit exceeded by p, or
+// an empty name if p is within the limits.
+func (l PointLimits) check(p models.Point) (string, int) {
+	if l.MaxTagsPerPoint > 0 || l.MaxTagKeyLength > 0 {
+		tags := p.Tags()
+		if l.MaxTagsPerPoint > 0 && len(tags) > l.MaxTagsPerPoint {
+			return PointLimitMaxTagsPerPoint, l.MaxTagsPerPoint
+		}
+		if l.MaxTagKeyLength > 0 {
+			for _, t := range tags {
+				if len(t.Key) > l.MaxTagKeyLength {
+					return PointLimitMaxTagKeyLength, l.MaxTagKeyLength
+				}
+			}
+		}
+	}
+
+	if l.MaxFieldKeyLength > 0 || l.MaxStringFieldLength > 0 {
+		iter := p.FieldIterator()
+		for iter.Next() {
+			if l.MaxFieldKeyLength > 0 && len(iter.FieldKey()) > l.MaxFieldKeyLength {
+				return PointLimitMaxFieldKeyLength, l.MaxFieldKeyLength
+			}
+			if l.MaxStringFieldLength > 0 && iter.Type() == models.String && len(iter.StringValue()) > l.MaxStringFieldLength {
+				return PointLimitMaxStringFieldLength, l.MaxStringFieldLength
+			}
+		}
+	}
+	return "", 0
+}
+
+// enforcePointLimits returns the points that are within the limits. The
+// other points are dropped and reported by the returned PartialWriteError,
+// and counted by limit in the state of the database.
+func enforcePointLimits(database string, state *databaseState, l PointLimits, points []models.Point) ([]models.Point, error) {
+	var (
+		perr PartialWriteError
+		kept []models.Point
+	)
+	for i, p := range points {
+		name, limit := l.check(p)
+		if name == "" {
+			if kept != nil {
+				kept = append(kept, p)
+			}
+			continue
+		}
+
+		if kept == nil {
+			kept = make([]models.Point, i, len(points))
+			copy(kept, points[:i])
+		}
+		perr.DropPoint(p, DropReasonPointLimit, fmt.Sprintf("point limit exceeded: %s database=%s limit=%d measurement=%q", name, database, limit, p.Name()))
+		if state == nil {
+			continue
+		}
+		switch name {
+		case PointLimitMaxTagsPerPoint:
+			atomic.AddInt64(&state.pointLimitTagsDropped, 1)
+		case PointLimitMaxTagKeyLength:
+			atomic.AddInt64(&state.pointLimitTagKeyDropped, 1)
+		case PointLimitMaxFieldKeyLength:
+			atomic.AddInt64(&state.pointLimitFieldKeyDropped, 1)
+		case PointLimitMaxStringFieldLength:
+			atomic.AddInt64(&state.pointLimitStringFieldDropped, 1)
+		default:
+		}
+	}
+
+	if kept == nil {
+		return points, nil
+	}
+	return kept, perr
+}
@@ -0,0 +1,166 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// Names of the quotas of a database.
+const (
+	QuotaMaxSeries           = "max-series"
+	QuotaMaxMeasurements     = "max-measurements"
+	QuotaMaxWriteRate        = "max-write-rate"
+	QuotaMaxConcurrentWrites = "max-concurrent-writes"
+)
+
+// Quota holds the limits of a database that are enforced by the store when
+// points are written. A zero limit is unlimited.
+type Quota struct {
+	MaxSeries       int64
+	MaxMeasurements int64
+}
+
+// IsZero returns true if the quota has no limits.
+func (q Quota) IsZero() bool {
+	return q.MaxSeries <= 0 && q.MaxMeasurements <= 0
+}
+
+// QuotaExceededError is returned when a write exceeds a quota of a database.
+type QuotaExceededError struct {
+	Database string
+	Quota    string
+	Limit    int64
+
+	// RetryAfter is how long to wait before the write can be retried, or
+	// zero if it can't succeed as is, like a write of more points at once
+	// than the write rate.
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded: %s database=%s limit=%d", e.Quota, e.Database, e.Limit)
+}
+
+// enforceQuota returns the points that can be written to the shard without
+// exceeding the quota of its database. The points that would create a series
+// or a measurement beyond the limits are dropped and reported by the
+// returned error, a PartialWriteError wrapping a QuotaExceededError.
+//
+// The number of series includes the series that were deleted but not yet
+// compacted from the series file, so the limit may be reached early after a
+// drop.
+//
+// The caller must hold quotaMu if any new series may be created.
+func (s *Store) enforceQuota(sh *Shard, state *databaseState, q Quota, points []models.Point) ([]models.Point, error) {
+	sfile := s.seriesFile(sh.database)
+	if sfile == nil {
+		return points, nil
+	}
+
+	series := int64(sfile.SeriesCount())
+	measurements := int64(-1) // counted on the first new measurement
+
+	var (
+		qerr                               *QuotaExceededError
+		seriesDropped, measurementsDropped int
+		dropped                            []DroppedPoint
+		buf                                []byte
+		kept                               = make([]models.Point, 0, len(points))
+		newSeries                          = make(map[string]struct{})
+		newMeasurements                    = make(map[string]struct{})
+	)
+	for _, p := range points {
+		name, tags := p.Name(), p.Tags()
+		key := string(models.MakeKey(name, tags))
+		if _, ok := newSeries[key]; ok || sfile.HasSeries(name, tags, buf) {
+			kept = append(kept, p)
+			continue
+		}
+
+		if q.MaxSeries > 0 && series >= q.MaxSeries {
+			if qerr == nil {
+				qerr = &QuotaExceededError{Database: sh.database, Quota: QuotaMaxSeries, Limit: q.MaxSeries}
+			}
+			seriesDropped++
+			dropped = append(dropped, DroppedPoint{Point: p, Reason: DropReasonCardinalityLimit, Message: qerr.Error()})
+			continue
+		}
+
+		if _, ok := newMeasurements[string(name)]; q.MaxMeasurements > 0 && !ok {
+			exists, err := s.measurementExists(sh.database, name)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				if measurements < 0 {
+					names, err := s.MeasurementNames(context.Background(), nil, sh.database, "", nil)
+					if err != nil {
+						return nil, err
+					}
+					measurements = int64(len(names))
+				}
+				if measurements >= q.MaxMeasurements {
+					if qerr == nil {
+						qerr = &QuotaExceededError{Database: sh.database, Quota: QuotaMaxMeasurements, Limit: q.MaxMeasurements}
+					}
+					measurementsDropped++
+					dropped = append(dropped, DroppedPoint{Point: p, Reason: DropReasonCardinalityLimit, Message: qerr.Error()})
+					continue
+				}
+				newMeasurements[string(name)] = struct{}{}
+				measurements++
+			}
+		}
+
+		newSeries[key] = struct{}{}
+		series++
+		kept = append(kept, p)
+	}
+
+	if qerr == nil {
+		return points, nil
+	}
+	if state != nil {
+		atomic.AddInt64(&state.quotaSeriesDropped, int64(seriesDropped))
+		atomic.AddInt64(&state.quotaMeasurementsDropped, int64(measurementsDropped))
+	}
+	return kept, PartialWriteError{Reason: qerr.Error(), Dropped: seriesDropped + measurementsDropped, Err: qerr, DroppedPoints: dropped}
+}
+
+// hasNewSeries returns true if any of the points creates a series in the
+// database of the shard.
+func (s *Store) hasNewSeries(sh *Shard, points []models.Point) bool {
+	sfile := s.seriesFile(sh.database)
+	if sfile == nil {
+		return true
+	}
+
+	var buf []byte
+	for _, p := range points {
+		if !sfile.HasSeries(p.Name(), p.Tags(), buf) {
+			return true
+		}
+	}
+	return false
+}
+
+// measurementExists returns true if any shard of the database contains the
+// measurement.
+func (s *Store) measurementExists(database string, name []byte) (bool, error) {
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(database))
+	s.mu.RUnlock()
+
+	for _, sh := range shards {
+		if ok, err := sh.MeasurementExists(name); err != nil {
+			return false, err
+		} else if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
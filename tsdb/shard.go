@@ -46,6 +46,13 @@ const (
 	statWriteValuesOK      = "writeValuesOk"
 	statWriteBytes         = "writeBytes"
 	statDiskBytes          = "diskBytes"
+	statNumSeries          = "numSeries"
+	statTSMBytes           = "tsmBytes"
+	statNumFiles           = "numFiles"
+	statWALBytes           = "walBytes"
+	statCacheBytes         = "cacheBytes"
+	statCompactionsActive  = "compactionsActive"
+	statCompactionsQueued  = "compactionsQueued"
 
 	FieldsChangeFile = "fields.idxl"
 	bytesInInt64     = 8
@@ -123,12 +130,103 @@ type PartialWriteError struct {
 
 	// A sorted slice of series keys that were dropped.
 	DroppedKeys [][]byte
+
+	// The error that caused the points to be dropped, if any, such as a
+	// QuotaExceededError.
+	Err error
+
+	// The points that were dropped and why, if they are known.
+	DroppedPoints []DroppedPoint
+}
+
+// DropReason is the category of the reason a point is dropped from a write,
+// that lets a client decide whether to retry the point.
+type DropReason string
+
+const (
+	// DropReasonParseError is a line of line protocol that failed to parse.
+	DropReasonParseError DropReason = "parse_error"
+
+	// DropReasonInvalid is a point with an invalid key or field.
+	DropReasonInvalid DropReason = "invalid"
+
+	// DropReasonTypeConflict is a field of a different type than the
+	// existing field.
+	DropReasonTypeConflict DropReason = "type_conflict"
+
+	// DropReasonCardinalityLimit is a point that would exceed a series
+	// limit or the quota of the database.
+	DropReasonCardinalityLimit DropReason = "cardinality_limit"
+
+	// DropReasonPointLimit is a point with too many tags, or a key or a
+	// string field value that is too long.
+	DropReasonPointLimit DropReason = "point_limit"
+
+	// DropReasonRetentionPolicy is a point older than the retention policy.
+	DropReasonRetentionPolicy DropReason = "retention_policy"
+
+	// DropReasonFutureTimestamp is a point too far in the future.
+	DropReasonFutureTimestamp DropReason = "future_timestamp"
+
+	// DropReasonShardDeletion is a point of a shard being deleted.
+	DropReasonShardDeletion DropReason = "shard_deletion"
+)
+
+// DroppedPoint is a point dropped from a write.
+type DroppedPoint struct {
+	Point   models.Point
+	Reason  DropReason
+	Message string
+}
+
+// DropPoint records a dropped point in the error. The message of the first
+// dropped point is the reason of the error if it has none.
+func (e *PartialWriteError) DropPoint(p models.Point, reason DropReason, msg string) {
+	if e.Reason == "" {
+		e.Reason = msg
+	}
+	e.Dropped++
+	e.DroppedPoints = append(e.DroppedPoints, DroppedPoint{Point: p, Reason: reason, Message: msg})
+}
+
+// MergePartialWriteErrors merges the errors of the writes of a request to
+// several shards. The reason of the first error is kept. Errors that are not
+// partial write errors take precedence.
+func MergePartialWriteErrors(err, other error) error {
+	if err == nil {
+		return other
+	} else if other == nil {
+		return err
+	}
+
+	pwe, ok := err.(PartialWriteError)
+	if !ok {
+		return err
+	}
+	opwe, ok := other.(PartialWriteError)
+	if !ok {
+		return other
+	}
+	if pwe.Reason == "" {
+		pwe.Reason = opwe.Reason
+	}
+	if pwe.Err == nil {
+		pwe.Err = opwe.Err
+	}
+	pwe.Dropped += opwe.Dropped
+	pwe.DroppedPoints = append(pwe.DroppedPoints[:len(pwe.DroppedPoints):len(pwe.DroppedPoints)], opwe.DroppedPoints...)
+	return pwe
 }
 
 func (e PartialWriteError) Error() string {
 	return fmt.Sprintf("partial write: %s dropped=%d", e.Reason, e.Dropped)
 }
 
+// Unwrap returns the error that caused the points to be dropped.
+func (e PartialWriteError) Unwrap() error {
+	return e.Err
+}
+
 // Shard represents a self-contained time series database. An inverted index of
 // the measurement and tag data is kept along with the raw time series data.
 // Data can be split across many shards. The query engine in TSDB is responsible
@@ -292,6 +390,7 @@ func (s *Shard) Statistics(tags map[string]string) []models.Statistic {
 		return nil
 	}
 	seriesN := engine.SeriesN()
+	usage := engine.Usage()
 
 	tags = s.defaultTags.Merge(tags)
 
@@ -316,6 +415,13 @@ func (s *Shard) Statistics(tags map[string]string) []models.Statistic {
 			statWriteValuesOK:      atomic.LoadInt64(&s.stats.WriteValuesOK),
 			statWriteBytes:         atomic.LoadInt64(&s.stats.BytesWritten),
 			statDiskBytes:          atomic.LoadInt64(&s.stats.DiskBytes),
+			statNumSeries:          seriesN,
+			statTSMBytes:           usage.TSMBytes,
+			statNumFiles:           usage.TSMFiles,
+			statWALBytes:           usage.WALBytes,
+			statCacheBytes:         usage.CacheBytes,
+			statCompactionsActive:  usage.CompactionsActive,
+			statCompactionsQueued:  usage.CompactionsQueued,
 		},
 	}}
 
@@ -523,6 +629,17 @@ func (s *Shard) DiskSize() (int64, error) {
 	return size, nil
 }
 
+// Usage returns the resources used by the data of the shard.
+func (s *Shard) Usage() (EngineUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	// Like DiskSize, report the usage of a disabled shard too.
+	if s._engine == nil {
+		return EngineUsage{}, ErrEngineClosed
+	}
+	return s._engine.Usage(), nil
+}
+
 // FieldCreate holds information for a field to create on a measurement.
 type FieldCreate struct {
 	Measurement []byte
@@ -534,6 +651,10 @@ type StatsTracker struct {
 	AddedMeasurementPoints func(measurement []byte, points, values int64)
 	AddedSeries            func(newSeries int64)
 	AddedMeasurementSeries func(measurement []byte, newSeries int64)
+
+	// ObservedStage is called with the time the engine spent in a stage of
+	// the write, if it is set.
+	ObservedStage func(stage WriteStage, d time.Duration)
 }
 
 func NoopStatsTracker() StatsTracker {
@@ -596,9 +717,7 @@ func (s *Shard) WritePoints(points []models.Point, tracker StatsTracker) error {
 func (s *Shard) validateSeriesAndFields(points []models.Point, tracker StatsTracker) ([]models.Point, []*FieldCreate, error) {
 	var (
 		fieldsToCreate []*FieldCreate
-		err            error
-		dropped        int
-		reason         string // only first error reason is set unless returned from CreateSeriesListIfNotExists
+		perr           PartialWriteError // only first error reason is set unless returned from CreateSeriesListIfNotExists
 	)
 
 	// Create all series against the index in bulk.
@@ -615,21 +734,15 @@ func (s *Shard) validateSeriesAndFields(points []models.Point, tracker StatsTrac
 
 		// Drop any series w/ a "time" tag, these are illegal
 		if v := tags.Get(timeBytes); v != nil {
-			dropped++
-			if reason == "" {
-				reason = fmt.Sprintf(
-					"invalid tag key: input tag \"%s\" on measurement \"%s\" is invalid",
-					"time", string(p.Name()))
-			}
+			perr.DropPoint(p, DropReasonInvalid, fmt.Sprintf(
+				"invalid tag key: input tag \"%s\" on measurement \"%s\" is invalid",
+				"time", string(p.Name())))
 			continue
 		}
 
 		// Drop any series with invalid unicode characters in the key.
 		if validateKeys && !models.ValidKeyTokens(string(p.Name()), tags) {
-			dropped++
-			if reason == "" {
-				reason = fmt.Sprintf("key contains invalid unicode: %q", makePrintable(string(p.Key())))
-			}
+			perr.DropPoint(p, DropReasonInvalid, fmt.Sprintf("key contains invalid unicode: %q", makePrintable(string(p.Key()))))
 			continue
 		}
 
@@ -647,23 +760,25 @@ func (s *Shard) validateSeriesAndFields(points []models.Point, tracker StatsTrac
 	}
 
 	// Add new series. Check for partial writes.
-	var droppedKeys [][]byte
+	var (
+		droppedKeys   [][]byte
+		droppedReason string
+	)
 	if err := engine.CreateSeriesListIfNotExists(keys, names, tagsSlice, tracker); err != nil {
 		switch err := err.(type) {
 		// (DSB) This was previously *PartialWriteError. Now catch pointer and value types.
 		case PartialWriteError:
-			reason = err.Reason
-			dropped += err.Dropped
+			droppedReason = err.Reason
 			droppedKeys = err.DroppedKeys
 			atomic.AddInt64(&s.stats.WritePointsDropped, int64(err.Dropped))
 		case *PartialWriteError:
-			reason = err.Reason
-			dropped += err.Dropped
+			droppedReason = err.Reason
 			droppedKeys = err.DroppedKeys
 			atomic.AddInt64(&s.stats.WritePointsDropped, int64(err.Dropped))
 		default:
 			return nil, nil, err
 		}
+		perr.Reason = droppedReason
 	}
 
 	j = 0
@@ -679,17 +794,15 @@ func (s *Shard) validateSeriesAndFields(points []models.Point, tracker StatsTrac
 			break
 		}
 		if !validField {
-			if reason == "" {
-				reason = fmt.Sprintf(
-					"invalid field name: input field \"%s\" on measurement \"%s\" is invalid",
-					"time", string(p.Name()))
-			}
-			dropped++
+			perr.DropPoint(p, DropReasonInvalid, fmt.Sprintf(
+				"invalid field name: input field \"%s\" on measurement \"%s\" is invalid",
+				"time", string(p.Name())))
 			continue
 		}
 
-		// Skip any points whos keys have been dropped. Dropped has already been incremented for them.
+		// Skip any points whos keys have been dropped.
 		if len(droppedKeys) > 0 && bytesutil.Contains(droppedKeys, keys[i]) {
+			perr.DropPoint(p, DropReasonCardinalityLimit, droppedReason)
 			continue
 		}
 
@@ -700,10 +813,9 @@ func (s *Shard) validateSeriesAndFields(points []models.Point, tracker StatsTrac
 		if err := ValidateFields(mf, p, s.options.Config.SkipFieldSizeValidation); err != nil {
 			switch err := err.(type) {
 			case PartialWriteError:
-				if reason == "" {
-					reason = err.Reason
+				for _, d := range err.DroppedPoints {
+					perr.DropPoint(d.Point, d.Reason, d.Message)
 				}
-				dropped += err.Dropped
 				atomic.AddInt64(&s.stats.WritePointsDropped, int64(err.Dropped))
 			default:
 				return nil, nil, err
@@ -743,11 +855,10 @@ func (s *Shard) validateSeriesAndFields(points []models.Point, tracker StatsTrac
 		}
 	}
 
-	if dropped > 0 {
-		err = PartialWriteError{Reason: reason, Dropped: dropped}
+	if perr.Dropped > 0 {
+		return points[:j], fieldsToCreate, perr
 	}
-
-	return points[:j], fieldsToCreate, err
+	return points[:j], fieldsToCreate, nil
 }
 
 const unPrintReplRune = '?'
@@ -827,6 +938,152 @@ func (s *Shard) DeleteMeasurement(name []byte) error {
 	return engine.DeleteMeasurement(name)
 }
 
+// renameBatchSize is the number of points written at once when copying the
+// series of a renamed measurement.
+const renameBatchSize = 10000
+
+// RenameMeasurement renames a measurement by copying the values of its
+// series to series with the new name and deleting the measurement. The
+// caller must prevent writes to the measurement during the rename.
+func (s *Shard) RenameMeasurement(oldName, newName []byte) error {
+	engine, err := s.Engine()
+	if err != nil {
+		return err
+	}
+	index, err := s.Index()
+	if err != nil {
+		return err
+	}
+
+	mf := engine.MeasurementFields(oldName)
+	if mf == nil || mf.FieldN() == 0 {
+		return nil
+	}
+	fields := mf.FieldKeys()
+
+	itr, err := index.MeasurementSeriesIDIterator(oldName)
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	ctx := context.Background()
+	citr, err := engine.CreateCursorIterator(ctx)
+	if err != nil {
+		return err
+	}
+
+	points := make([]models.Point, 0, renameBatchSize)
+	add := func(tags models.Tags, field string, ts int64, v interface{}) error {
+		pt, err := models.NewPoint(string(newName), tags, models.Fields{field: v}, time.Unix(0, ts))
+		if err != nil {
+			return err
+		}
+		points = append(points, pt)
+		if len(points) < renameBatchSize {
+			return nil
+		}
+		err = s.WritePoints(points, NoopStatsTracker())
+		points = points[:0]
+		return err
+	}
+
+	for {
+		elem, err := itr.Next()
+		if err != nil {
+			return err
+		} else if elem.SeriesID == 0 {
+			break
+		}
+
+		_, tags := s.sfile.Series(elem.SeriesID)
+		if tags == nil {
+			continue
+		}
+
+		for _, field := range fields {
+			cur, err := citr.Next(ctx, &CursorRequest{
+				Name:      oldName,
+				Tags:      tags,
+				Field:     field,
+				Ascending: true,
+				StartTime: influxql.MinTime,
+				EndTime:   influxql.MaxTime,
+			})
+			if err != nil {
+				return err
+			} else if cur == nil {
+				continue
+			}
+			if err := copyCursor(cur, func(ts int64, v interface{}) error {
+				return add(tags, field, ts, v)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(points) > 0 {
+		if err := s.WritePoints(points, NoopStatsTracker()); err != nil {
+			return err
+		}
+	}
+	return engine.DeleteMeasurement(oldName)
+}
+
+// copyCursor calls fn with each value of the cursor and closes it.
+func copyCursor(cur Cursor, fn func(ts int64, v interface{}) error) error {
+	defer cur.Close()
+
+	switch cur := cur.(type) {
+	case FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, ts := range a.Timestamps {
+				if err := fn(ts, a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, ts := range a.Timestamps {
+				if err := fn(ts, a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, ts := range a.Timestamps {
+				if err := fn(ts, a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case StringArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, ts := range a.Timestamps {
+				if err := fn(ts, a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case BooleanArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, ts := range a.Timestamps {
+				if err := fn(ts, a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported cursor type: %T", cur)
+	}
+	return cur.Err()
+}
+
 // SeriesN returns the unique number of series in the shard.
 func (s *Shard) SeriesN() int64 {
 	engine, err := s.Engine()
@@ -2358,9 +2615,10 @@ func NewFieldKeysIterator(sh *Shard, opt query.IteratorOptions) (query.Iterator,
 
 	// Retrieve measurements from shard. Filter if condition specified.
 	//
-	// FGA is currently not supported when retrieving field keys.
+	// Only the measurements with a series the user is authorized to read
+	// are included.
 	indexSet := IndexSet{Indexes: []Index{index}, SeriesFile: sh.sfile}
-	names, err := indexSet.MeasurementNamesByExpr(query.OpenAuthorizer, opt.Condition)
+	names, err := indexSet.MeasurementNamesByExpr(opt.Authorizer, opt.Condition)
 	if err != nil {
 		return nil, err
 	}
@@ -291,11 +291,56 @@ func TestShard_MaxTagValuesLimit(t *testing.T) {
 		t.Fatal("expected error")
 	} else if exp, got := `partial write: max-values-per-tag limit exceeded (1000/1000): measurement="cpu" tag="host" value="server9999" dropped=1`, err.Error(); exp != got {
 		t.Fatalf("unexpected error message:\n\texp = %s\n\tgot = %s", exp, got)
+	} else if d := err.(tsdb.PartialWriteError).DroppedPoints; len(d) != 1 || d[0].Point != pt || d[0].Reason != tsdb.DropReasonCardinalityLimit {
+		t.Fatalf("unexpected dropped points: %+v", d)
 	}
 
 	sh.Close()
 }
 
+// Ensure the points dropped by a write are reported with their reasons.
+func TestShard_WritePoints_DroppedPoints(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpShard := filepath.Join(tmpDir, "shard")
+	tmpWal := filepath.Join(tmpDir, "wal")
+
+	sfile := MustOpenSeriesFile()
+	defer sfile.Close()
+
+	opts := tsdb.NewEngineOptions()
+	opts.Config.WALDir = filepath.Join(tmpDir, "wal")
+	opts.InmemIndex = inmem.NewIndex(filepath.Base(tmpDir), sfile.SeriesFile)
+
+	sh := tsdb.NewShard(1, tmpShard, tmpWal, sfile.SeriesFile, opts)
+	if err := sh.Open(); err != nil {
+		t.Fatalf("error opening shard: %s", err.Error())
+	}
+	defer sh.Close()
+
+	if err := sh.WritePoints([]models.Point{models.MustNewPoint("cpu", nil, map[string]interface{}{"value": 1.0}, time.Unix(1, 0))}, tsdb.NoopStatsTracker()); err != nil {
+		t.Fatal(err)
+	}
+
+	points := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"time": "a"}), map[string]interface{}{"value": 2.0}, time.Unix(2, 0)),
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"value": 3.0}, time.Unix(3, 0)),
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"value": "4"}, time.Unix(4, 0)),
+	}
+	// The points are filtered in place.
+	exp := append([]models.Point(nil), points...)
+	err := sh.WritePoints(points, tsdb.NoopStatsTracker())
+	perr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if perr.Dropped != 2 || len(perr.DroppedPoints) != 2 {
+		t.Fatalf("unexpected dropped points: %+v", perr)
+	} else if d := perr.DroppedPoints[0]; d.Point != exp[0] || d.Reason != tsdb.DropReasonInvalid {
+		t.Fatalf("unexpected dropped point: %+v", d)
+	} else if d := perr.DroppedPoints[1]; d.Point != exp[2] || d.Reason != tsdb.DropReasonTypeConflict || !strings.HasPrefix(d.Message, "field type conflict") {
+		t.Fatalf("unexpected dropped point: %+v", d)
+	}
+}
+
 func TestWriteTimeTag(t *testing.T) {
 	tmpDir, _ := os.MkdirTemp("", "shard_test")
 	defer os.RemoveAll(tmpDir)
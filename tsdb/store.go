@@ -36,6 +36,12 @@ var (
 	// ErrMultipleIndexTypes is returned when trying to do deletes on a database with
 	// multiple index types.
 	ErrMultipleIndexTypes = errors.New("cannot delete data. DB contains shards using both inmem and tsi1 indexes. Please convert all shards to use the same index type to delete data.")
+	// ErrMeasurementExists is returned when renaming a measurement to the name
+	// of an existing measurement.
+	ErrMeasurementExists = errors.New("measurement already exists")
+	// ErrMeasurementNotFound is returned when renaming a measurement that
+	// doesn't exist.
+	ErrMeasurementNotFound = errors.New("measurement not found")
 )
 
 // Statistics gathered by the store.
@@ -45,14 +51,87 @@ const (
 	statPointsWritten        = "pointsWritten"   // number of points parsed by engines successfully
 	statValuesWritten        = "valuesWritten"   // number of values parsed by engines successfully
 	statSeriesCreated        = "seriesCreated"   // number of series created since startup
+
+	statDatabaseQuotaSeriesDropped       = "quotaSeriesDropped"       // number of points dropped by the max-series quota
+	statDatabaseQuotaMeasurementsDropped = "quotaMeasurementsDropped" // number of points dropped by the max-measurements quota
+
+	statDatabasePointLimitTagsDropped        = "pointLimitTagsDropped"        // number of points dropped by the max-tags-per-point limit
+	statDatabasePointLimitTagKeyDropped      = "pointLimitTagKeyDropped"      // number of points dropped by the max-tag-key-length limit
+	statDatabasePointLimitFieldKeyDropped    = "pointLimitFieldKeyDropped"    // number of points dropped by the max-field-key-length limit
+	statDatabasePointLimitStringFieldDropped = "pointLimitStringFieldDropped" // number of points dropped by the max-string-field-length limit
+
+	statRetentionPolicyShards = "numShards" // number of shards of a retention policy on this node
 )
 
+// retentionPolicyStatistics returns the statistics of the shards of each
+// retention policy, summed up.
+func retentionPolicyStatistics(shards []*Shard, tags map[string]string) []models.Statistic {
+	type key struct{ database, retentionPolicy string }
+	var keys []key
+	usages := make(map[key]*EngineUsage)
+	counts := make(map[key]int64)
+	for _, sh := range shards {
+		u, err := sh.Usage()
+		if err != nil {
+			continue
+		}
+
+		k := key{sh.Database(), sh.RetentionPolicy()}
+		sum := usages[k]
+		if sum == nil {
+			sum = &EngineUsage{}
+			usages[k] = sum
+			keys = append(keys, k)
+		}
+		counts[k]++
+		sum.TSMBytes += u.TSMBytes
+		sum.TSMFiles += u.TSMFiles
+		sum.WALBytes += u.WALBytes
+		sum.CacheBytes += u.CacheBytes
+		sum.CompactionsActive += u.CompactionsActive
+		sum.CompactionsQueued += u.CompactionsQueued
+	}
+
+	statistics := make([]models.Statistic, 0, len(keys))
+	for _, k := range keys {
+		u := usages[k]
+		statistics = append(statistics, models.Statistic{
+			Name: "retentionPolicy",
+			Tags: models.StatisticTags{"database": k.database, "retentionPolicy": k.retentionPolicy}.Merge(tags),
+			Values: map[string]interface{}{
+				statRetentionPolicyShards: counts[k],
+				statDiskBytes:             u.TSMBytes + u.WALBytes,
+				statTSMBytes:              u.TSMBytes,
+				statNumFiles:              u.TSMFiles,
+				statWALBytes:              u.WALBytes,
+				statCacheBytes:            u.CacheBytes,
+				statCompactionsActive:     u.CompactionsActive,
+				statCompactionsQueued:     u.CompactionsQueued,
+			},
+		})
+	}
+	return statistics
+}
+
 // SeriesFileDirectory is the name of the directory containing series files for
 // a database.
 const SeriesFileDirectory = "_series"
 
 // databaseState keeps track of the state of a database.
-type databaseState struct{ indexTypes map[string]int }
+type databaseState struct {
+	indexTypes map[string]int
+
+	// Number of points dropped as they exceeded the quota of the database.
+	quotaSeriesDropped       int64
+	quotaMeasurementsDropped int64
+
+	// Number of points dropped as they exceeded the point limits of the
+	// database.
+	pointLimitTagsDropped        int64
+	pointLimitTagKeyDropped      int64
+	pointLimitFieldKeyDropped    int64
+	pointLimitStringFieldDropped int64
+}
 
 // addIndexType records that the database has a shard with the given index type.
 func (d *databaseState) addIndexType(indexType string) {
@@ -119,6 +198,9 @@ type Store struct {
 	// This prevents new shards from being created while old ones are being deleted.
 	pendingShardDeletes map[uint64]struct{}
 
+	// Maintains a set of shards that are being moved to another data directory.
+	pendingShardMoves map[uint64]struct{}
+
 	// Maintains a set of shards that failed to open
 	badShards shardErrorMap
 
@@ -126,6 +208,10 @@ type Store struct {
 	// is stored by shard.
 	epochs map[uint64]*epochTracker
 
+	// Serializes the writes that may create series in databases with a
+	// quota, so the limits can't be exceeded by concurrent writes.
+	quotaMu sync.Mutex
+
 	// Statistics for the store
 	stats          StoreStatistics
 	ingressMetrics IngressMetrics
@@ -150,6 +236,7 @@ func NewStore(path string) *Store {
 		sfiles:              make(map[string]*SeriesFile),
 		indexes:             make(map[string]interface{}),
 		pendingShardDeletes: make(map[uint64]struct{}),
+		pendingShardMoves:   make(map[uint64]struct{}),
 		badShards:           shardErrorMap{shardErrors: make(map[uint64]error)},
 		epochs:              make(map[uint64]*epochTracker),
 		EngineOptions:       NewEngineOptions(),
@@ -189,13 +276,26 @@ func (s *Store) Statistics(tags map[string]string) []models.Statistic {
 			continue
 		}
 
+		values := map[string]interface{}{
+			statDatabaseSeries:       sc,
+			statDatabaseMeasurements: mc,
+		}
+		s.mu.RLock()
+		state := s.databases[database]
+		s.mu.RUnlock()
+		if state != nil {
+			values[statDatabaseQuotaSeriesDropped] = atomic.LoadInt64(&state.quotaSeriesDropped)
+			values[statDatabaseQuotaMeasurementsDropped] = atomic.LoadInt64(&state.quotaMeasurementsDropped)
+			values[statDatabasePointLimitTagsDropped] = atomic.LoadInt64(&state.pointLimitTagsDropped)
+			values[statDatabasePointLimitTagKeyDropped] = atomic.LoadInt64(&state.pointLimitTagKeyDropped)
+			values[statDatabasePointLimitFieldKeyDropped] = atomic.LoadInt64(&state.pointLimitFieldKeyDropped)
+			values[statDatabasePointLimitStringFieldDropped] = atomic.LoadInt64(&state.pointLimitStringFieldDropped)
+		}
+
 		statistics = append(statistics, models.Statistic{
-			Name: "database",
-			Tags: models.StatisticTags{"database": database}.Merge(tags),
-			Values: map[string]interface{}{
-				statDatabaseSeries:       sc,
-				statDatabaseMeasurements: mc,
-			},
+			Name:   "database",
+			Tags:   models.StatisticTags{"database": database}.Merge(tags),
+			Values: values,
 		})
 	}
 
@@ -203,6 +303,8 @@ func (s *Store) Statistics(tags map[string]string) []models.Statistic {
 	for _, shard := range shards {
 		statistics = append(statistics, shard.Statistics(tags)...)
 	}
+	statistics = append(statistics, retentionPolicyStatistics(shards, tags)...)
+	statistics = append(statistics, s.dataDirStatistics(tags)...)
 
 	statistics = append(statistics, models.Statistic{
 		Name: "localStore",
@@ -285,11 +387,12 @@ func (s *Store) Open() error {
 	s.closing = make(chan struct{})
 	s.shards = map[uint64]*Shard{}
 
-	s.Logger.Info("Using data dir", zap.String("path", s.Path()))
-
-	// Create directory.
-	if err := os.MkdirAll(s.path, 0777); err != nil {
-		return err
+	// Create directories.
+	for _, dir := range s.dataDirs() {
+		s.Logger.Info("Using data dir", zap.String("path", dir))
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			return err
+		}
 	}
 
 	if err := s.loadShards(); err != nil {
@@ -367,14 +470,13 @@ func (s *Store) loadShards() error {
 	resC := make(chan *res)
 	var n int
 
-	// Determine how many shards we need to open by checking the store path.
-	dbDirs, err := os.ReadDir(s.path)
+	// Determine how many shards we need to open by checking the data dirs.
+	dbDirs, err := s.readDataDirs("")
 	if err != nil {
 		return err
 	}
 
 	for _, db := range dbDirs {
-		dbPath := filepath.Join(s.path, db.Name())
 		if !db.IsDir() {
 			log.Info("Skipping database dir", zap.String("name", db.Name()), zap.String("reason", "not a directory"))
 			continue
@@ -397,14 +499,13 @@ func (s *Store) loadShards() error {
 			return err
 		}
 
-		// Load each retention policy within the database directory.
-		rpDirs, err := os.ReadDir(dbPath)
+		// Load each retention policy within the database directories.
+		rpDirs, err := s.readDataDirs(db.Name())
 		if err != nil {
 			return err
 		}
 
 		for _, rp := range rpDirs {
-			rpPath := filepath.Join(s.path, db.Name(), rp.Name())
 			if !rp.IsDir() {
 				log.Info("Skipping retention policy dir", zap.String("name", rp.Name()), zap.String("reason", "not a directory"))
 				continue
@@ -420,25 +521,19 @@ func (s *Store) loadShards() error {
 				continue
 			}
 
-			shardDirs, err := os.ReadDir(rpPath)
+			shardDirs, err := s.shardDirs(log, db.Name(), rp.Name())
 			if err != nil {
 				return err
 			}
 
-			for _, sh := range shardDirs {
-				// Series file should not be in a retention policy but skip just in case.
-				if sh.Name() == SeriesFileDirectory {
-					log.Warn("Skipping series file in retention policy dir", zap.String("path", filepath.Join(s.path, db.Name(), rp.Name())))
-					continue
-				}
-
+			for _, path := range shardDirs {
 				n++
-				go func(db, rp, sh string) {
+				go func(db, rp, path string) {
 					t.Take()
 					defer t.Release()
 
 					start := time.Now()
-					path := filepath.Join(s.path, db, rp, sh)
+					sh := filepath.Base(path)
 					walPath := filepath.Join(s.EngineOptions.Config.WALDir, db, rp, sh)
 
 					// Shard file names are numeric shardIDs
@@ -484,7 +579,7 @@ func (s *Store) loadShards() error {
 
 					resC <- &res{s: shard}
 					log.Info("Opened shard", zap.String("index_version", shard.IndexType()), zap.String("path", path), zap.Duration("duration", time.Since(start)))
-				}(db.Name(), rp.Name(), sh.Name())
+				}(db.Name(), rp.Name(), path)
 			}
 		}
 	}
@@ -730,7 +825,8 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64, en
 	}
 
 	// Create the db and retention policy directories if they don't exist.
-	if err := os.MkdirAll(filepath.Join(s.path, database, retentionPolicy), 0700); err != nil {
+	dir := s.newShardDataDir()
+	if err := os.MkdirAll(filepath.Join(dir, database, retentionPolicy), 0700); err != nil {
 		return err
 	}
 
@@ -757,7 +853,7 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64, en
 	opt.InmemIndex = idx
 	opt.SeriesIDSets = shardSet{store: s, db: database}
 
-	path := filepath.Join(s.path, database, retentionPolicy, strconv.FormatUint(shardID, 10))
+	path := filepath.Join(dir, database, retentionPolicy, strconv.FormatUint(shardID, 10))
 	shard := NewShard(shardID, path, walPath, sfile, opt)
 	shard.WithLogger(s.baseLogger)
 	shard.EnableOnOpen = enabled
@@ -1019,8 +1115,10 @@ func (s *Store) DeleteDatabase(name string) error {
 		return fmt.Errorf("invalid database directory location for database '%s': %s", name, dbPath)
 	}
 
-	if err := os.RemoveAll(dbPath); err != nil {
-		return err
+	for _, dir := range s.dataDirs() {
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return err
+		}
 	}
 	if err := os.RemoveAll(filepath.Join(s.EngineOptions.Config.WALDir, name)); err != nil {
 		return err
@@ -1040,6 +1138,166 @@ func (s *Store) DeleteDatabase(name string) error {
 	return nil
 }
 
+// RenameDatabase renames a database by moving its directories on disk. The
+// shards of the database are closed while the directories are moved and are
+// reopened under the new name. It does nothing if the database has no local
+// files.
+func (s *Store) RenameDatabase(oldName, newName string) error {
+	oldPath := filepath.Clean(filepath.Join(s.path, oldName))
+	newPath := filepath.Clean(filepath.Join(s.path, newName))
+
+	// Same sanity check as DeleteDatabase, so that a name such as "../.."
+	// can't move the directories outside of the store.
+	if filepath.Clean(s.path) != filepath.Dir(oldPath) || filepath.Clean(s.path) != filepath.Dir(newPath) {
+		return fmt.Errorf("invalid database directory location for renaming database '%s' to '%s'", oldName, newName)
+	}
+
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, dir := range s.dataDirs() {
+		path := filepath.Join(dir, newName)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("database directory already exists: %s", path)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(oldName))
+	epochs := s.epochsForShards(shards)
+	s.mu.RUnlock()
+
+	if err := s.walkShards(shards, func(sh *Shard) error {
+		epoch := epochs[sh.id]
+		// enter the epoch tracker
+		guards, gen := epoch.StartWrite()
+		defer epoch.EndWrite(gen)
+
+		// wait for any guards before closing the shard
+		for _, guard := range guards {
+			guard.Wait()
+		}
+
+		return sh.Close()
+	}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sfile := s.sfiles[oldName]; sfile != nil {
+		if err := sfile.Close(); err != nil {
+			return err
+		}
+		delete(s.sfiles, oldName)
+	}
+	delete(s.indexes, oldName)
+
+	// If the directories can't be moved, the shards are reopened under the
+	// old name so the database remains usable.
+	name := newName
+	err := s.moveDatabase(oldName, newName)
+	if err != nil {
+		name = oldName
+	}
+	if rerr := s.reopenShards(name, shards); err == nil {
+		err = rerr
+	}
+
+	if state, ok := s.databases[oldName]; ok && name != oldName {
+		s.databases[name] = state
+		delete(s.databases, oldName)
+	}
+	return err
+}
+
+// moveDatabase moves the data and WAL directories of a database. The moved
+// directories are moved back if one of them can't be moved. It must be
+// called under a full lock.
+func (s *Store) moveDatabase(oldName, newName string) error {
+	dirs := append(s.dataDirs(), s.EngineOptions.Config.WALDir)
+
+	var moved []string
+	for _, dir := range dirs {
+		oldPath, newPath := filepath.Join(dir, oldName), filepath.Join(dir, newName)
+		if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(oldPath, newPath); err != nil {
+			for _, dir := range moved {
+				oldPath, newPath := filepath.Join(dir, oldName), filepath.Join(dir, newName)
+				if rerr := os.Rename(newPath, oldPath); rerr != nil {
+					s.Logger.Error("Failed to move database directory back", zap.String("path", newPath), zap.Error(rerr))
+				}
+			}
+			return err
+		}
+		moved = append(moved, dir)
+	}
+	return nil
+}
+
+// reopenShards opens the closed shards of a database from the directories of
+// the database with the given name. The shards that fail to open are removed
+// from the store, as they are when the store is opened. It must be called
+// under a full lock.
+func (s *Store) reopenShards(database string, shards []*Shard) error {
+	sfile, err := s.openSeriesFile(database)
+	if err != nil {
+		return err
+	}
+	idx, err := s.createIndexIfNotExists(database)
+	if err != nil {
+		return err
+	}
+
+	for _, sh := range shards {
+		path := filepath.Join(shardDataDir(sh.path), database, sh.retentionPolicy, strconv.FormatUint(sh.id, 10))
+		shard, oerr := s.openExistingShard(sh.id, database, sh.retentionPolicy, path, sfile, idx)
+		if oerr != nil {
+			s.Logger.Error("Failed to open shard", logger.Shard(sh.id), zap.Error(oerr))
+			delete(s.shards, sh.id)
+			delete(s.epochs, sh.id)
+			if err == nil {
+				err = fmt.Errorf("failed to open shard: %d: %w", sh.id, oerr)
+			}
+			continue
+		}
+		s.shards[sh.id] = shard
+	}
+	return err
+}
+
+// openExistingShard opens a shard from the files in path. It must be called
+// under a full lock.
+func (s *Store) openExistingShard(id uint64, database, retentionPolicy, path string, sfile *SeriesFile, idx interface{}) (*Shard, error) {
+	walPath := filepath.Join(s.EngineOptions.Config.WALDir, database, retentionPolicy, strconv.FormatUint(id, 10))
+
+	// Copy options and assign shared index.
+	opt := s.EngineOptions
+	opt.InmemIndex = idx
+	opt.SeriesIDSets = shardSet{store: s, db: database}
+
+	// Existing shards should continue to use inmem index.
+	if _, err := os.Stat(filepath.Join(path, "index")); os.IsNotExist(err) {
+		opt.IndexVersion = InmemIndexName
+	}
+
+	shard := NewShard(id, path, walPath, sfile, opt)
+	shard.CompactionDisabled = s.EngineOptions.CompactionDisabled
+	shard.WithLogger(s.baseLogger)
+
+	if err := s.OpenShard(shard, false); err != nil {
+		return nil, err
+	}
+	return shard, nil
+}
+
 // DeleteRetentionPolicy will close all shards associated with the
 // provided retention policy, remove the retention policy directories on
 // both the DB and WAL, and remove all shard files from disk.
@@ -1073,9 +1331,11 @@ func (s *Store) DeleteRetentionPolicy(database, name string) error {
 		return fmt.Errorf("invalid path for database '%s', retention policy '%s': %s", database, name, rpPath)
 	}
 
-	// Remove the retention policy folder.
-	if err := os.RemoveAll(filepath.Join(s.path, database, name)); err != nil {
-		return err
+	// Remove the retention policy folders.
+	for _, dir := range s.dataDirs() {
+		if err := os.RemoveAll(filepath.Join(dir, database, name)); err != nil {
+			return err
+		}
 	}
 
 	// Remove the retention policy folder from the the WAL.
@@ -1122,6 +1382,49 @@ func (s *Store) DeleteMeasurement(database, name string) error {
 	})
 }
 
+// RenameMeasurement renames a measurement of a database. The series of the
+// measurement are copied to series with the new name in each shard, then the
+// measurement is deleted. The writes to the measurement wait until it is
+// renamed, after which they create it again.
+func (s *Store) RenameMeasurement(database, oldName, newName string) error {
+	s.mu.RLock()
+	if s.databases[database].hasMultipleIndexTypes() {
+		s.mu.RUnlock()
+		return ErrMultipleIndexTypes
+	}
+	shards := s.filterShards(byDatabase(database))
+	epochs := s.epochsForShards(shards)
+	s.mu.RUnlock()
+
+	if exists, err := s.measurementExists(database, []byte(oldName)); err != nil {
+		return err
+	} else if !exists {
+		return ErrMeasurementNotFound
+	}
+	if exists, err := s.measurementExists(database, []byte(newName)); err != nil {
+		return err
+	} else if exists {
+		return ErrMeasurementExists
+	}
+
+	// Limit renames for each shard like deletes, since copying the series
+	// of a measurement can be as memory intensive.
+	limit := limiter.NewFixed(s.EngineOptions.Config.MaxConcurrentDeletes)
+	return s.walkShards(shards, func(sh *Shard) error {
+		limit.Take()
+		defer limit.Release()
+
+		// The guard makes the writes to the measurement wait until the
+		// measurement is deleted.
+		guard := newGuard(influxql.MinTime, influxql.MaxTime, []string{oldName}, nil)
+		waiter := epochs[sh.id].WaitDelete(guard)
+		waiter.Wait()
+		defer waiter.Done()
+
+		return sh.RenameMeasurement([]byte(oldName), []byte(newName))
+	})
+}
+
 // filterShards returns a slice of shards where fn returns true
 // for the shard. If the provided predicate is nil then all shards are returned.
 // filterShards should be called under a lock.
@@ -1415,7 +1718,7 @@ func (s *Store) BackupShard(id uint64, since time.Time, w io.Writer) error {
 		return fmt.Errorf("shard %d doesn't exist on this server", id)
 	}
 
-	path, err := relativePath(s.path, shard.path)
+	path, err := relativePath(shardDataDir(shard.path), shard.path)
 	if err != nil {
 		return err
 	}
@@ -1429,7 +1732,7 @@ func (s *Store) ExportShard(id uint64, start time.Time, end time.Time, w io.Writ
 		return fmt.Errorf("shard %d doesn't exist on this server", id)
 	}
 
-	path, err := relativePath(s.path, shard.path)
+	path, err := relativePath(shardDataDir(shard.path), shard.path)
 	if err != nil {
 		return err
 	}
@@ -1445,7 +1748,7 @@ func (s *Store) RestoreShard(id uint64, r io.Reader) error {
 		return fmt.Errorf("shard %d doesn't exist on this server", id)
 	}
 
-	path, err := relativePath(s.path, shard.path)
+	path, err := relativePath(shardDataDir(shard.path), shard.path)
 	if err != nil {
 		return err
 	}
@@ -1463,7 +1766,7 @@ func (s *Store) ImportShard(id uint64, r io.Reader) error {
 		return fmt.Errorf("shard %d doesn't exist on this server", id)
 	}
 
-	path, err := relativePath(s.path, shard.path)
+	path, err := relativePath(shardDataDir(shard.path), shard.path)
 	if err != nil {
 		return err
 	}
@@ -1478,7 +1781,7 @@ func (s *Store) ShardRelativePath(id uint64) (string, error) {
 	if shard == nil {
 		return "", fmt.Errorf("shard %d doesn't exist on this server", id)
 	}
-	return relativePath(s.path, shard.path)
+	return relativePath(shardDataDir(shard.path), shard.path)
 }
 
 // DeleteSeries loops through the local shards and deletes the series data for
@@ -1670,11 +1973,50 @@ const (
 )
 
 // WriteContext holds some request-scoped details about the write,
-// for metrics and logging. Currently just the UserId login name.
+// for metrics and logging, and the quota of the database.
 // Eventually it could also hold a context.Context for cancellation.
 type WriteContext struct {
 	// Could be a system UserId, e.g. HintedHandoffUser
 	UserId string
+
+	// Quota of the database that is enforced by the write.
+	Quota Quota
+
+	// RequestID identifies the request of the write in the logs, if any.
+	RequestID string
+
+	// Ack is the level at which the write is acknowledged.
+	Ack WriteAck
+
+	// Trace records the time spent in the stages of the write. The stages
+	// are not timed if it is nil.
+	Trace *WriteTrace
+}
+
+// WriteAck is the level at which a write is acknowledged.
+type WriteAck int
+
+const (
+	// WriteAckApplied acknowledges a write once its points are written to
+	// the shards.
+	WriteAckApplied WriteAck = iota
+
+	// WriteAckQueued acknowledges a write once it is durably queued, before
+	// its points are written to the shards.
+	WriteAckQueued
+)
+
+// ParseWriteAck returns the acknowledgment level of its name. An empty name
+// is WriteAckApplied.
+func ParseWriteAck(s string) (WriteAck, error) {
+	switch s {
+	case "", "applied":
+		return WriteAckApplied, nil
+	case "queued":
+		return WriteAckQueued, nil
+	default:
+		return WriteAckApplied, fmt.Errorf("invalid ack %q (use queued or applied)", s)
+	}
 }
 
 // WriteToShard writes a list of points to a shard identified by its ID.
@@ -1695,9 +2037,34 @@ func (s *Store) WriteToShard(writeCtx WriteContext, shardID uint64, points []mod
 	}
 
 	epoch := s.epochs[shardID]
+	state := s.databases[sh.database]
 
 	s.mu.RUnlock()
 
+	// Drop the points that exceed the point limits of the database.
+	var limitErr error
+	if limits := s.EngineOptions.Config.PointLimits(sh.database); !limits.IsZero() {
+		points, limitErr = enforcePointLimits(sh.database, state, limits, points)
+	}
+
+	// Drop the points beyond the quota of the database. The writes that
+	// create series are serialized so the series they create are counted
+	// by the writes that follow.
+	var quotaErr error
+	if !writeCtx.Quota.IsZero() && s.hasNewSeries(sh, points) {
+		s.quotaMu.Lock()
+		defer s.quotaMu.Unlock()
+
+		var err error
+		if points, err = s.enforceQuota(sh, state, writeCtx.Quota, points); err != nil {
+			if _, ok := err.(PartialWriteError); !ok {
+				return err
+			}
+			quotaErr = err
+		}
+	}
+	quotaErr = MergePartialWriteErrors(limitErr, quotaErr)
+
 	// enter the epoch tracker
 	guards, gen := epoch.StartWrite()
 	defer epoch.EndWrite(gen)
@@ -1715,7 +2082,15 @@ func (s *Store) WriteToShard(writeCtx WriteContext, shardID uint64, points []mod
 		sh.SetCompactionsEnabled(true)
 	}
 
-	return sh.WritePoints(points, s.statsTracker(sh.database, sh.retentionPolicy, writeCtx.UserId))
+	tracker := s.statsTracker(sh.database, sh.retentionPolicy, writeCtx.UserId)
+	if writeCtx.Trace != nil {
+		tracker.ObservedStage = writeCtx.Trace.Observe
+		defer func(start time.Time) {
+			writeCtx.Trace.Observe(WriteStageShardWrite, time.Since(start))
+		}(time.Now())
+	}
+	err := sh.WritePoints(points, tracker)
+	return MergePartialWriteErrors(quotaErr, err)
 }
 
 // MeasurementNames returns a slice of all measurements. Measurements accepts an
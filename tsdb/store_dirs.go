@@ -0,0 +1,432 @@
+package tsdb
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/file"
+	"go.uber.org/zap"
+)
+
+// Statistics gathered for the data directories of the store.
+const (
+	statDataDirShards     = "numShards"  // number of shards in a data directory
+	statDataDirDiskBytes  = "diskBytes"  // size of the shards in a data directory
+	statDataDirFreeBytes  = "freeBytes"  // free space of the disk of a data directory
+	statDataDirTotalBytes = "totalBytes" // total space of the disk of a data directory
+)
+
+// shardMoveSuffix is the suffix of the directory a shard is copied to while
+// it's moved to another data directory.
+const shardMoveSuffix = ".moving"
+
+// DataDir describes a directory the store places shards in.
+type DataDir struct {
+	Path string
+
+	// Number of shards in the directory and their size on disk.
+	Shards    int
+	DiskBytes int64
+
+	// Free and total space of the disk of the directory, zero if they can't
+	// be determined.
+	FreeBytes  uint64
+	TotalBytes uint64
+}
+
+// dataDirs returns the directories the shards are placed in, the store path
+// first.
+func (s *Store) dataDirs() []string {
+	dirs := make([]string, 0, 1+len(s.EngineOptions.Config.ShardDirs))
+	dirs = append(dirs, filepath.Clean(s.path))
+	for _, dir := range s.EngineOptions.Config.ShardDirs {
+		dirs = append(dirs, filepath.Clean(dir))
+	}
+	return dirs
+}
+
+// shardDataDir returns the data directory of a shard path, that is the path
+// without the <database>/<retention>/<id> suffix.
+func shardDataDir(path string) string {
+	return filepath.Dir(filepath.Dir(filepath.Dir(filepath.Clean(path))))
+}
+
+// DataDirs returns the directories the store places shards in, along with
+// the shards and disk space of each. It can be used to decide which shards
+// to move with MoveShard to rebalance the directories.
+func (s *Store) DataDirs() []DataDir {
+	dirs := s.dataDirs()
+	infos := make([]DataDir, len(dirs))
+	index := make(map[string]int, len(dirs))
+	for i, dir := range dirs {
+		infos[i].Path = dir
+		index[dir] = i
+		if free, total, err := file.DiskSpace(dir); err == nil {
+			infos[i].FreeBytes, infos[i].TotalBytes = free, total
+		}
+	}
+
+	s.mu.RLock()
+	shards := s.shardsSlice()
+	s.mu.RUnlock()
+	for _, sh := range shards {
+		i, ok := index[shardDataDir(sh.path)]
+		if !ok {
+			continue
+		}
+		infos[i].Shards++
+		if size, err := sh.DiskSize(); err == nil {
+			infos[i].DiskBytes += size
+		}
+	}
+	return infos
+}
+
+// dataDirStatistics returns the statistics of the data directories if there
+// are several of them.
+func (s *Store) dataDirStatistics(tags map[string]string) []models.Statistic {
+	if len(s.EngineOptions.Config.ShardDirs) == 0 {
+		return nil
+	}
+
+	dirs := s.DataDirs()
+	statistics := make([]models.Statistic, 0, len(dirs))
+	for _, dir := range dirs {
+		statistics = append(statistics, models.Statistic{
+			Name: "dataDir",
+			Tags: models.StatisticTags{"path": dir.Path}.Merge(tags),
+			Values: map[string]interface{}{
+				statDataDirShards:     dir.Shards,
+				statDataDirDiskBytes:  dir.DiskBytes,
+				statDataDirFreeBytes:  int64(dir.FreeBytes),
+				statDataDirTotalBytes: int64(dir.TotalBytes),
+			},
+		})
+	}
+	return statistics
+}
+
+// newShardDataDir returns the data directory to place a new shard in,
+// according to the shard directory policy. It must be called under a lock.
+func (s *Store) newShardDataDir() string {
+	dirs := s.dataDirs()
+	if len(dirs) == 1 {
+		return dirs[0]
+	}
+
+	switch s.EngineOptions.Config.ShardDirPolicy {
+	case ShardDirPolicyFewestShards:
+		return s.fewestShardsDataDir(dirs)
+	default:
+		var (
+			best     string
+			bestFree uint64
+		)
+		for _, dir := range dirs {
+			free, _, err := file.DiskSpace(dir)
+			if err != nil {
+				s.Logger.Info("Cannot retrieve free disk space", zap.String("path", dir), zap.Error(err))
+				continue
+			} else if best == "" || free > bestFree {
+				best, bestFree = dir, free
+			}
+		}
+		if best != "" {
+			return best
+		}
+		// Spread the shards evenly if the free space is unknown.
+		return s.fewestShardsDataDir(dirs)
+	}
+}
+
+// fewestShardsDataDir returns the first of dirs with the fewest shards. It
+// must be called under a lock.
+func (s *Store) fewestShardsDataDir(dirs []string) string {
+	counts := make(map[string]int, len(dirs))
+	for _, sh := range s.shards {
+		counts[shardDataDir(sh.path)]++
+	}
+
+	best := dirs[0]
+	for _, dir := range dirs[1:] {
+		if counts[dir] < counts[best] {
+			best = dir
+		}
+	}
+	return best
+}
+
+// ShardDataDir returns the data directory a shard is placed in.
+func (s *Store) ShardDataDir(shardID uint64) (string, error) {
+	sh := s.Shard(shardID)
+	if sh == nil {
+		return "", ErrShardNotFound
+	}
+	return shardDataDir(sh.path), nil
+}
+
+// MoveShard moves the files of a shard to another data directory, like to
+// rebalance the disk usage of the directories. The shard is closed while its
+// files are copied, so its writes and queries fail until it's reopened from
+// the new directory.
+func (s *Store) MoveShard(shardID uint64, dir string) error {
+	dir = filepath.Clean(dir)
+	valid := false
+	for _, d := range s.dataDirs() {
+		valid = valid || d == dir
+	}
+	if !valid {
+		return fmt.Errorf("%s is not a data directory", dir)
+	}
+
+	s.mu.Lock()
+	select {
+	case <-s.closing:
+		s.mu.Unlock()
+		return ErrStoreClosed
+	default:
+	}
+	sh := s.shards[shardID]
+	if sh == nil {
+		s.mu.Unlock()
+		return ErrShardNotFound
+	} else if _, ok := s.pendingShardMoves[shardID]; ok {
+		s.mu.Unlock()
+		return fmt.Errorf("shard %d is already being moved", shardID)
+	} else if shardDataDir(sh.path) == dir {
+		s.mu.Unlock()
+		return nil
+	}
+	s.pendingShardMoves[shardID] = struct{}{}
+	epoch := s.epochs[shardID]
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.pendingShardMoves, shardID)
+	}()
+
+	// Close the shard once the writes in progress are done.
+	guards, gen := epoch.StartWrite()
+	for _, guard := range guards {
+		guard.Wait()
+	}
+	err := sh.Close()
+	epoch.EndWrite(gen)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, sh.database, sh.retentionPolicy, strconv.FormatUint(shardID, 10))
+	log := s.Logger.With(logger.Shard(shardID), zap.String("path", path))
+	log.Info("Moving shard", zap.String("from", sh.path))
+
+	if err := copyShardDir(sh.path, path); err != nil {
+		// Leave the shard where it is, unless it was deleted meanwhile.
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.shards[shardID] == sh {
+			if rerr := s.reopenShardLocked(sh, sh.path); rerr != nil {
+				log.Error("Failed to reopen shard", zap.Error(rerr))
+			}
+		}
+		return fmt.Errorf("move shard %d: %w", shardID, err)
+	}
+
+	s.mu.Lock()
+	if s.shards[shardID] != sh {
+		// The shard was deleted meanwhile.
+		s.mu.Unlock()
+		return os.RemoveAll(path)
+	}
+	err = s.reopenShardLocked(sh, path)
+	if err != nil {
+		if rerr := s.reopenShardLocked(sh, sh.path); rerr != nil {
+			log.Error("Failed to reopen shard", zap.Error(rerr))
+		}
+		s.mu.Unlock()
+		if rerr := os.RemoveAll(path); rerr != nil {
+			log.Error("Failed to remove moved shard", zap.Error(rerr))
+		}
+		return fmt.Errorf("move shard %d: %w", shardID, err)
+	}
+	s.mu.Unlock()
+
+	// Rename the old copy before removing it, so that it's not loaded when
+	// the store is opened if it can't be removed entirely.
+	old := sh.path + shardMoveSuffix
+	if err := os.Rename(sh.path, old); err != nil {
+		log.Error("Failed to remove shard from the previous data directory", zap.String("from", sh.path), zap.Error(err))
+	} else if err := os.RemoveAll(old); err != nil {
+		log.Warn("Failed to remove shard from the previous data directory", zap.String("from", old), zap.Error(err))
+	}
+	return nil
+}
+
+// reopenShardLocked replaces the closed shard sh with a shard opened from
+// path. The shard is removed from the store if it fails to open. It must be
+// called under a full lock.
+func (s *Store) reopenShardLocked(sh *Shard, path string) error {
+	sfile, err := s.openSeriesFile(sh.database)
+	if err != nil {
+		return err
+	}
+	idx, err := s.createIndexIfNotExists(sh.database)
+	if err != nil {
+		return err
+	}
+
+	shard, err := s.openExistingShard(sh.id, sh.database, sh.retentionPolicy, path, sfile, idx)
+	if err != nil {
+		delete(s.shards, sh.id)
+		delete(s.epochs, sh.id)
+		return err
+	}
+	s.shards[sh.id] = shard
+	return nil
+}
+
+// copyShardDir copies the files of a shard to dst. The files are copied to a
+// temporary directory that is renamed once they are all synced, so a shard
+// is never loaded from a partial copy.
+func copyShardDir(src, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("shard directory already exists: %s", dst)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp := dst + shardMoveSuffix
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(tmp, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		return copyShardFile(path, target)
+	})
+	if err == nil {
+		err = file.SyncDir(tmp)
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err == nil {
+		err = file.SyncDir(filepath.Dir(dst))
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	return nil
+}
+
+func copyShardFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	} else if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// readDataDirs returns the entries of a path relative to the data
+// directories, merged across all of them and sorted by name. An entry found
+// in several directories is returned once.
+func (s *Store) readDataDirs(rel string) ([]os.DirEntry, error) {
+	var (
+		entries []os.DirEntry
+		seen    = make(map[string]struct{})
+	)
+	for _, dir := range s.dataDirs() {
+		des, err := os.ReadDir(filepath.Join(dir, rel))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, de := range des {
+			if _, ok := seen[de.Name()]; !ok {
+				seen[de.Name()] = struct{}{}
+				entries = append(entries, de)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// shardDirs returns the paths of the shard directories of a retention policy
+// in all the data directories. A shard found in several
+// directories, like when the store stopped while the shard was moved, is
+// loaded from the first one. The leftovers of interrupted moves are removed.
+func (s *Store) shardDirs(log *zap.Logger, db, rp string) ([]string, error) {
+	var (
+		paths []string
+		seen  = make(map[string]string)
+	)
+	for _, dir := range s.dataDirs() {
+		rpPath := filepath.Join(dir, db, rp)
+		des, err := os.ReadDir(rpPath)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, de := range des {
+			path := filepath.Join(rpPath, de.Name())
+			switch {
+			case de.Name() == SeriesFileDirectory:
+				// Series file should not be in a retention policy but skip just in case.
+				log.Warn("Skipping series file in retention policy dir", zap.String("path", rpPath))
+				continue
+			case filepath.Ext(de.Name()) == shardMoveSuffix:
+				log.Info("Removing incomplete shard move", zap.String("path", path))
+				if err := os.RemoveAll(path); err != nil {
+					return nil, err
+				}
+				continue
+			default:
+			}
+
+			if prev, ok := seen[de.Name()]; ok {
+				log.Warn("Skipping duplicate shard dir", zap.String("path", path), zap.String("loaded", prev))
+				continue
+			}
+			seen[de.Name()] = path
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
@@ -2426,6 +2426,459 @@ func TestStore_TagValues_ConcurrentDropShard(t *testing.T) {
 	}
 }
 
+// Ensure the points that create series or measurements beyond the quota of
+// the database are dropped.
+func TestStore_WriteToShard_Quota(t *testing.T) {
+	test := func(t *testing.T, index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 0, `cpu,host=a value=1 0`)
+		if err := s.CreateShard("db0", "rp0", 1, true); err != nil {
+			t.Fatal(err)
+		}
+
+		write := func(shardID uint64, q tsdb.Quota, data string) error {
+			points, err := models.ParsePointsString(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s.WriteToShard(tsdb.WriteContext{Quota: q}, shardID, points)
+		}
+
+		// The series of the database are counted across its shards and the
+		// existing series are always written.
+		q := tsdb.Quota{MaxSeries: 2}
+		err := write(1, q, "cpu,host=a value=2 10\ncpu,host=b value=1 10\ncpu,host=c value=1 10")
+		pwe, ok := err.(tsdb.PartialWriteError)
+		if !ok {
+			t.Fatalf("unexpected error: %v", err)
+		} else if pwe.Dropped != 1 {
+			t.Fatalf("unexpected dropped: %d", pwe.Dropped)
+		}
+		var qerr *tsdb.QuotaExceededError
+		if !errors.As(err, &qerr) {
+			t.Fatalf("expected quota error: %v", err)
+		} else if exp := (tsdb.QuotaExceededError{Database: "db0", Quota: tsdb.QuotaMaxSeries, Limit: 2}); *qerr != exp {
+			t.Fatalf("unexpected quota error: %+v", *qerr)
+		}
+		if err := write(0, q, "cpu,host=b value=2 20"); err != nil {
+			t.Fatal(err)
+		}
+
+		// Only the series of the existing measurements are created.
+		q = tsdb.Quota{MaxMeasurements: 2}
+		err = write(0, q, "mem,host=a value=1 30\ndisk,host=a value=1 30\ncpu,host=d value=1 30")
+		if pwe, ok := err.(tsdb.PartialWriteError); !ok || pwe.Dropped != 1 {
+			t.Fatalf("unexpected error: %v", err)
+		} else if !errors.As(err, &qerr) || qerr.Quota != tsdb.QuotaMaxMeasurements {
+			t.Fatalf("expected max-measurements error: %v", err)
+		}
+		names, err := s.MeasurementNames(context.Background(), nil, "db0", "", nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, exp := names, [][]byte{[]byte("cpu"), []byte("mem")}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected measurements: %s", got)
+		}
+
+		// The dropped points are reported by the statistics of the database.
+		for _, stat := range s.Statistics(nil) {
+			if stat.Name != "database" {
+				continue
+			}
+			if got := stat.Values["quotaSeriesDropped"]; got != int64(1) {
+				t.Fatalf("unexpected quotaSeriesDropped: %v", got)
+			} else if got := stat.Values["quotaMeasurementsDropped"]; got != int64(1) {
+				t.Fatalf("unexpected quotaMeasurementsDropped: %v", got)
+			}
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(t, index) })
+	}
+}
+
+// Ensure the points that exceed the point limits of their database are
+// dropped.
+func TestStore_WriteToShard_PointLimits(t *testing.T) {
+	s := MustOpenStore(tsdb.TSI1IndexName)
+	defer s.Close()
+
+	s.EngineOptions.Config.MaxTagsPerPoint = 2
+	s.EngineOptions.Config.MaxFieldKeyLength = 5
+	s.EngineOptions.Config.DatabasePointLimits = []tsdb.DatabasePointLimitsConfig{
+		{Database: "db1", MaxTagsPerPoint: -1, MaxStringFieldLength: 3},
+	}
+	for id, db := range []string{"db0", "db1"} {
+		if err := s.CreateShard(db, "rp0", uint64(id), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write := func(shardID uint64, data string) error {
+		points, err := models.ParsePointsString(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.WriteToShard(tsdb.WriteContext{}, shardID, points)
+	}
+
+	err := write(0, "cpu,a=1,b=2,c=3 value=1 10\ncpu,a=1 value=1 10\ncpu,a=1 toolong=1 10\ncpu,a=1 s=\"abcd\" 10")
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if pwe.Dropped != 2 || len(pwe.DroppedPoints) != 2 {
+		t.Fatalf("unexpected dropped: %d", pwe.Dropped)
+	} else if d := pwe.DroppedPoints[0]; d.Reason != tsdb.DropReasonPointLimit || !strings.Contains(d.Message, tsdb.PointLimitMaxTagsPerPoint) {
+		t.Fatalf("unexpected dropped point: %+v", d)
+	} else if d := pwe.DroppedPoints[1]; !strings.Contains(d.Message, tsdb.PointLimitMaxFieldKeyLength) {
+		t.Fatalf("unexpected dropped point: %+v", d)
+	}
+
+	// The limits of db1 override the ones of the data section.
+	err = write(1, "cpu,a=1,b=2,c=3 value=1 10\ncpu,a=1 s=\"abcd\" 10\ncpu,a=1 s=\"abc\" 10")
+	if pwe, ok := err.(tsdb.PartialWriteError); !ok || pwe.Dropped != 1 {
+		t.Fatalf("unexpected error: %v", err)
+	} else if d := pwe.DroppedPoints[0]; !strings.Contains(d.Message, tsdb.PointLimitMaxStringFieldLength) {
+		t.Fatalf("unexpected dropped point: %+v", d)
+	}
+
+	// The dropped points are reported by the statistics of the database.
+	exp := map[string]map[string]int64{
+		"db0": {"pointLimitTagsDropped": 1, "pointLimitFieldKeyDropped": 1, "pointLimitStringFieldDropped": 0},
+		"db1": {"pointLimitTagsDropped": 0, "pointLimitFieldKeyDropped": 0, "pointLimitStringFieldDropped": 1},
+	}
+	for _, stat := range s.Statistics(nil) {
+		if stat.Name != "database" {
+			continue
+		}
+		for name, v := range exp[stat.Tags["database"]] {
+			if got := stat.Values[name]; got != v {
+				t.Errorf("unexpected %s of %s: %v", name, stat.Tags["database"], got)
+			}
+		}
+	}
+}
+
+// Ensure the statistics report the usage of each shard and of the shards of
+// each retention policy.
+func TestStore_Statistics_Usage(t *testing.T) {
+	test := func(t *testing.T, index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1, "cpu,host=a value=1 10", "cpu,host=b value=1 20")
+		s.MustCreateShardWithData("db0", "rp0", 2, "cpu,host=a value=1 30")
+		s.MustCreateShardWithData("db0", "rp1", 3, "cpu,host=a value=1 40")
+
+		shards := make(map[string]models.Statistic)
+		rps := make(map[string]models.Statistic)
+		for _, stat := range s.Statistics(nil) {
+			switch stat.Name {
+			case "shard":
+				shards[stat.Tags["id"]] = stat
+			case "retentionPolicy":
+				rps[stat.Tags["database"]+"."+stat.Tags["retentionPolicy"]] = stat
+			default:
+			}
+		}
+
+		if stat, ok := shards["1"]; !ok {
+			t.Fatal("statistics for shard 1 not found")
+		} else if stat.Tags["database"] != "db0" || stat.Tags["retentionPolicy"] != "rp0" {
+			t.Fatalf("unexpected tags: %v", stat.Tags)
+		} else if got := stat.Values["cacheBytes"].(int64); got <= 0 {
+			t.Fatalf("unexpected cacheBytes: %d", got)
+		} else if got := stat.Values["walBytes"].(int64); got <= 0 {
+			t.Fatalf("unexpected walBytes: %d", got)
+		}
+
+		if len(rps) != 2 {
+			t.Fatalf("unexpected retention policy statistics: %v", rps)
+		}
+		stat := rps["db0.rp0"]
+		checkInt64Stat(t, stat, "numShards", 2)
+		exp := shards["1"].Values["cacheBytes"].(int64) + shards["2"].Values["cacheBytes"].(int64)
+		checkInt64Stat(t, stat, "cacheBytes", exp)
+		checkInt64Stat(t, rps["db0.rp1"], "numShards", 1)
+		checkInt64Stat(t, rps["db0.rp1"], "numFiles", 0)
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(t, index) })
+	}
+}
+
+// Ensure a write to a shard records the time of its stages in the trace.
+func TestStore_WriteToShard_Trace(t *testing.T) {
+	test := func(t *testing.T, index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		if err := s.CreateShard("db0", "rp0", 0, true); err != nil {
+			t.Fatal(err)
+		}
+		trace := new(tsdb.WriteTrace)
+		points := []models.Point{models.MustNewPoint("cpu", nil, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))}
+		if err := s.WriteToShard(tsdb.WriteContext{Trace: trace}, 0, points); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, stage := range []tsdb.WriteStage{tsdb.WriteStageShardWrite, tsdb.WriteStageEngineWrite, tsdb.WriteStageWALFsync} {
+			if trace.Duration(stage) <= 0 {
+				t.Errorf("missing %s time", stage)
+			}
+		}
+		if d := trace.Duration(tsdb.WriteStageParse); d != 0 {
+			t.Errorf("unexpected parse time: %s", d)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(t, index) })
+	}
+}
+
+func TestStore_RenameDatabase(t *testing.T) {
+	t.Parallel()
+
+	test := func(t *testing.T, index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 0, `cpu,host=a value=1 0`)
+		s.MustCreateShardWithData("db0", "rp1", 1, `mem,host=a value=2 0`)
+		s.MustCreateShardWithData("db1", "rp0", 2, `disk,host=a value=3 0`)
+
+		if err := s.RenameDatabase("db0", "db1"); err == nil {
+			t.Fatal("expected error renaming to an existing database")
+		}
+		if err := s.RenameDatabase("db0", "db2"); err != nil {
+			t.Fatal(err)
+		}
+
+		check := func() {
+			t.Helper()
+			dbs := s.Databases()
+			sort.Strings(dbs)
+			if got, exp := dbs, []string{"db1", "db2"}; !reflect.DeepEqual(got, exp) {
+				t.Fatalf("unexpected databases: %v", got)
+			}
+			for _, id := range []uint64{0, 1} {
+				if sh := s.Shard(id); sh == nil {
+					t.Fatalf("shard %d not found", id)
+				} else if sh.Database() != "db2" {
+					t.Fatalf("unexpected database of shard %d: %s", id, sh.Database())
+				}
+			}
+			names, err := s.MeasurementNames(context.Background(), nil, "db2", "", nil)
+			if err != nil {
+				t.Fatal(err)
+			} else if got, exp := names, [][]byte{[]byte("cpu"), []byte("mem")}; !reflect.DeepEqual(got, exp) {
+				t.Fatalf("unexpected measurements: %s", got)
+			}
+		}
+		check()
+
+		// The renamed shards are writable and are loaded under the new name.
+		s.MustWriteToShardString(0, `cpu,host=b value=4 10`)
+		if _, err := os.Stat(filepath.Join(s.Path(), "db0")); !os.IsNotExist(err) {
+			t.Fatalf("expected old database directory to be removed: %v", err)
+		}
+		if err := s.Reopen(); err != nil {
+			t.Fatal(err)
+		}
+		check()
+		if n, err := s.SeriesCardinality(context.Background(), "db2"); err != nil {
+			t.Fatal(err)
+		} else if n != 3 {
+			t.Fatalf("unexpected series cardinality: %d", n)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(t, index) })
+	}
+}
+
+func TestStore_ShardDirs(t *testing.T) {
+	t.Parallel()
+
+	test := func(t *testing.T, index string) {
+		s := NewStore(index)
+		defer s.Close()
+		dir := t.TempDir()
+		s.EngineOptions.Config.ShardDirs = []string{dir}
+		s.EngineOptions.Config.ShardDirPolicy = tsdb.ShardDirPolicyFewestShards
+		if err := s.Open(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The shards are spread across the directories.
+		s.MustCreateShardWithData("db0", "rp0", 0, `cpu,host=a value=1 0`)
+		s.MustCreateShardWithData("db0", "rp0", 1, `cpu,host=b value=2 10`)
+		s.MustCreateShardWithData("db0", "rp1", 2, `mem,host=a value=3 20`)
+		s.MustCreateShardWithData("db1", "rp0", 3, `disk,host=a value=4 30`)
+
+		check := func(exp ...string) {
+			t.Helper()
+			for id, exp := range exp {
+				if got, err := s.ShardDataDir(uint64(id)); err != nil {
+					t.Fatal(err)
+				} else if got != exp {
+					t.Fatalf("unexpected data dir of shard %d: got=%s exp=%s", id, got, exp)
+				}
+			}
+			names, err := s.MeasurementNames(context.Background(), nil, "db0", "", nil)
+			if err != nil {
+				t.Fatal(err)
+			} else if got, exp := names, [][]byte{[]byte("cpu"), []byte("mem")}; !reflect.DeepEqual(got, exp) {
+				t.Fatalf("unexpected measurements: %s", got)
+			}
+			if n, err := s.SeriesCardinality(context.Background(), "db0"); err != nil {
+				t.Fatal(err)
+			} else if n != 3 {
+				t.Fatalf("unexpected series cardinality: %d", n)
+			}
+		}
+		check(s.Path(), dir, s.Path(), dir)
+
+		dirs := s.DataDirs()
+		if len(dirs) != 2 || dirs[0].Path != s.Path() || dirs[1].Path != dir {
+			t.Fatalf("unexpected data dirs: %+v", dirs)
+		} else if dirs[0].Shards != 2 || dirs[1].Shards != 2 || dirs[1].DiskBytes == 0 {
+			t.Fatalf("unexpected data dirs: %+v", dirs)
+		}
+		var found bool
+		for _, stat := range s.Statistics(nil) {
+			if stat.Name == "dataDir" && stat.Tags["path"] == dir {
+				found = stat.Values["numShards"] == 2
+			}
+		}
+		if !found {
+			t.Fatal("expected statistics of the data dir")
+		}
+
+		// A moved shard keeps its data and is loaded from its new directory.
+		if err := s.MoveShard(1, s.Path()); err != nil {
+			t.Fatal(err)
+		} else if err := s.MoveShard(1, t.TempDir()); err == nil {
+			t.Fatal("expected error moving a shard outside of the data dirs")
+		}
+		check(s.Path(), s.Path(), s.Path(), dir)
+		if _, err := os.Stat(filepath.Join(dir, "db0", "rp0", "1")); !os.IsNotExist(err) {
+			t.Fatalf("expected old shard directory to be removed: %v", err)
+		}
+		s.MustWriteToShardString(1, `cpu,host=c value=5 40`)
+
+		// The leftovers of an interrupted move are removed on open.
+		moving := filepath.Join(dir, "db0", "rp0", "9.moving")
+		if err := os.MkdirAll(moving, 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Reopen(); err != nil {
+			t.Fatal(err)
+		} else if _, err := os.Stat(moving); !os.IsNotExist(err) {
+			t.Fatalf("expected incomplete move to be removed: %v", err)
+		}
+		if n, err := s.SeriesCardinality(context.Background(), "db0"); err != nil {
+			t.Fatal(err)
+		} else if n != 4 {
+			t.Fatalf("unexpected series cardinality: %d", n)
+		}
+
+		// The databases and retention policies are removed from all the
+		// directories.
+		if err := s.RenameDatabase("db1", "db2"); err != nil {
+			t.Fatal(err)
+		} else if got, err := s.ShardDataDir(3); err != nil || got != dir {
+			t.Fatalf("unexpected data dir of shard 3: %s %v", got, err)
+		} else if err := s.DeleteRetentionPolicy("db0", "rp1"); err != nil {
+			t.Fatal(err)
+		} else if err := s.DeleteDatabase("db2"); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{filepath.Join(dir, "db0", "rp1"), filepath.Join(dir, "db1"), filepath.Join(dir, "db2")} {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Fatalf("expected %s to be removed: %v", path, err)
+			}
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(t, index) })
+	}
+}
+
+func TestStore_RenameMeasurement(t *testing.T) {
+	t.Parallel()
+
+	test := func(t *testing.T, index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 0,
+			`cpu,host=a value=1,status="ok",count=3i,up=true 0`,
+			`cpu,host=b value=2 10`,
+			`mem,host=a value=5 10`,
+		)
+		s.MustCreateShardWithData("db0", "rp0", 1, `cpu,host=a value=3 20`)
+
+		if err := s.RenameMeasurement("db0", "cpu", "mem"); err != tsdb.ErrMeasurementExists {
+			t.Fatalf("unexpected error: %v", err)
+		} else if err := s.RenameMeasurement("db0", "disk", "load"); err != tsdb.ErrMeasurementNotFound {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.RenameMeasurement("db0", "cpu", "load"); err != nil {
+			t.Fatal(err)
+		}
+
+		names, err := s.MeasurementNames(context.Background(), nil, "db0", "", nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, exp := names, [][]byte{[]byte("load"), []byte("mem")}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected measurements: %s", got)
+		}
+
+		// The fields keep their types.
+		exp := map[string]influxql.DataType{"value": influxql.Float, "status": influxql.String, "count": influxql.Integer, "up": influxql.Boolean}
+		if got := s.Shard(0).MeasurementFields([]byte("load")).FieldSet(); !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected fields: %v", got)
+		}
+
+		itr, err := s.ShardGroup([]uint64{0, 1}).CreateIterator(context.Background(), &influxql.Measurement{Name: "load"}, query.IteratorOptions{
+			Expr:       influxql.MustParseExpr(`value`),
+			Dimensions: []string{"host"},
+			Ascending:  true,
+			StartTime:  influxql.MinTime,
+			EndTime:    influxql.MaxTime,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer itr.Close()
+		fitr := itr.(query.FloatIterator)
+
+		for i, exp := range []*query.FloatPoint{
+			{Name: "load", Tags: ParseTags("host=a"), Time: time.Unix(0, 0).UnixNano(), Value: 1},
+			{Name: "load", Tags: ParseTags("host=a"), Time: time.Unix(20, 0).UnixNano(), Value: 3},
+			{Name: "load", Tags: ParseTags("host=b"), Time: time.Unix(10, 0).UnixNano(), Value: 2},
+			nil,
+		} {
+			if p, err := fitr.Next(); err != nil {
+				t.Fatalf("unexpected error(%d): %s", i, err)
+			} else if !deep.Equal(p, exp) {
+				t.Fatalf("unexpected point(%d): %s", i, spew.Sdump(p))
+			}
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(t, index) })
+	}
+}
+
 func BenchmarkStore_SeriesCardinality_100_Shards(b *testing.B) {
 	for _, index := range tsdb.RegisteredIndexes() {
 		store := NewStore(index)
@@ -2671,10 +3124,13 @@ func (s *Store) Reopen() error {
 		return err
 	}
 
+	config := s.EngineOptions.Config
 	s.Store = tsdb.NewStore(s.Path())
 	s.EngineOptions.IndexVersion = s.index
 	s.EngineOptions.Config.WALDir = filepath.Join(s.Path(), "wal")
 	s.EngineOptions.Config.TraceLoggingEnabled = true
+	s.EngineOptions.Config.ShardDirs = config.ShardDirs
+	s.EngineOptions.Config.ShardDirPolicy = config.ShardDirPolicy
 
 	if testing.Verbose() {
 		s.WithLogger(logger.New(os.Stdout))
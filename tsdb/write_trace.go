@@ -0,0 +1,81 @@
+package tsdb
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WriteStage is a stage of a write that is timed by a WriteTrace.
+type WriteStage int
+
+const (
+	// WriteStageParse is the parsing of the points of the write.
+	WriteStageParse WriteStage = iota
+
+	// WriteStageMapShards is the mapping of the points to the shards,
+	// including the creation of the missing shard groups.
+	WriteStageMapShards
+
+	// WriteStageShardWrite is the write to a shard, including the quota,
+	// the index and the engine.
+	WriteStageShardWrite
+
+	// WriteStageEngineWrite is the write of the values to the cache of the
+	// engine of a shard.
+	WriteStageEngineWrite
+
+	// WriteStageWALFsync is the write of the values to the WAL of a shard
+	// and its fsync.
+	WriteStageWALFsync
+
+	numWriteStages
+)
+
+// WriteStages lists the stages of a write in order.
+var WriteStages = []WriteStage{WriteStageParse, WriteStageMapShards, WriteStageShardWrite, WriteStageEngineWrite, WriteStageWALFsync}
+
+// String returns the name of the stage in logs.
+func (s WriteStage) String() string {
+	switch s {
+	case WriteStageParse:
+		return "parse"
+	case WriteStageMapShards:
+		return "map_shards"
+	case WriteStageShardWrite:
+		return "shard_write"
+	case WriteStageEngineWrite:
+		return "engine_write"
+	case WriteStageWALFsync:
+		return "wal_fsync"
+	default:
+		return "unknown"
+	}
+}
+
+// WriteTrace records the time a write spends in each stage. The shards of a
+// write are written concurrently, so the time of a stage that is observed
+// several times is the longest one. A nil trace records nothing.
+type WriteTrace struct {
+	durations [numWriteStages]int64
+}
+
+// Observe records that the write spent d in the stage.
+func (t *WriteTrace) Observe(stage WriteStage, d time.Duration) {
+	if t == nil || stage < 0 || stage >= numWriteStages {
+		return
+	}
+	for {
+		old := atomic.LoadInt64(&t.durations[stage])
+		if int64(d) <= old || atomic.CompareAndSwapInt64(&t.durations[stage], old, int64(d)) {
+			return
+		}
+	}
+}
+
+// Duration returns the time the write spent in the stage.
+func (t *WriteTrace) Duration(stage WriteStage) time.Duration {
+	if t == nil || stage < 0 || stage >= numWriteStages {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&t.durations[stage]))
+}